@@ -95,7 +95,8 @@ func main() {
 	err = domainerrors.NewFamilyTooManyParentsError("family cannot have more than two parents", nil)
 
 	// Check if it's a domain error
-	if _, ok := err.(*errors.DomainError); ok {
+	var domainErr *errors.DomainError
+	if errors.As(err, &domainErr) {
 		fmt.Println("Error is a domain error")
 	}
 
@@ -103,7 +104,8 @@ func main() {
 	err = errors.NewValidationError("id is required", "id", nil)
 
 	// Check if it's a validation error
-	if _, ok := err.(*errors.ValidationError); ok {
+	var valErr *errors.ValidationError
+	if errors.As(err, &valErr) {
 		fmt.Println("Error is a validation error")
 	}
 }
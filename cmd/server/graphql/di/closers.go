@@ -0,0 +1,120 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package di
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ShutdownPhase orders the cleanup of the components of the container. The closers of an
+// earlier phase run before those of a later phase, so that a component is closed after
+// everything that uses it.
+type ShutdownPhase int
+
+const (
+	// PhaseProducers is the phase of the components that produce work or write to other
+	// components, such as relays, schedulers, and write-behind buffers
+	PhaseProducers ShutdownPhase = iota
+
+	// PhaseServices is the phase of the components used while serving requests, such as
+	// caches and clients of remote services
+	PhaseServices
+
+	// PhaseStorage is the phase of the connection pools, files, and other storage, closed
+	// after every component that may still write to them
+	PhaseStorage
+)
+
+// String returns the name of the phase
+func (p ShutdownPhase) String() string {
+	switch p {
+	case PhaseProducers:
+		return "producers"
+	case PhaseServices:
+		return "services"
+	case PhaseStorage:
+		return "storage"
+	default:
+		return fmt.Sprintf("phase-%d", int(p))
+	}
+}
+
+// CloseFunc releases the resources of a component when the container closes. It should
+// return ctx.Err() if it gives up waiting for the component.
+type CloseFunc func(ctx context.Context) error
+
+// closer is a registered CloseFunc
+type closer struct {
+	name     string
+	phase    ShutdownPhase
+	priority int
+	order    int // Order of registration
+	close    CloseFunc
+}
+
+// RegisterCloser registers the cleanup of a component created by or for the container, run
+// when the container closes. Closers run one at a time: by phase, then by descending
+// priority within a phase, then in the reverse order of their registration, so that a
+// component registered after its dependencies is closed before them. Every closer runs,
+// even if an earlier one fails; Close reports the errors of all of them.
+//
+// Background workers that must stop before the server exits are registered with the worker
+// coordinator instead, which drains them concurrently before the container closes.
+//
+// Parameters:
+//   - name: The name of the component, used in errors
+//   - phase: The phase of the component
+//   - priority: The priority of the component within its phase; higher runs first
+//   - close: The function that releases the resources of the component
+func (c *Container) RegisterCloser(name string, phase ShutdownPhase, priority int, close CloseFunc) {
+	c.closersMu.Lock()
+	defer c.closersMu.Unlock()
+	c.closers = append(c.closers, closer{
+		name:     name,
+		phase:    phase,
+		priority: priority,
+		order:    len(c.closers),
+		close:    close,
+	})
+}
+
+// runClosers runs the registered closers in order, each within the close timeout of the
+// container, and returns their errors. Closers run at most once.
+func (c *Container) runClosers() []error {
+	c.closersMu.Lock()
+	closers := c.closers
+	c.closers = nil
+	c.closersMu.Unlock()
+
+	sort.SliceStable(closers, func(i, j int) bool {
+		a, b := closers[i], closers[j]
+		if a.phase != b.phase {
+			return a.phase < b.phase
+		}
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		return a.order > b.order
+	})
+
+	var errs []error
+	for _, cl := range closers {
+		if err := c.runCloser(cl); err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", cl.name, cl.phase, err))
+		}
+	}
+	return errs
+}
+
+// runCloser runs a closer within the close timeout of the container, if it has one
+func (c *Container) runCloser(cl closer) error {
+	ctx := context.Background()
+	if c.closeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.closeTimeout)
+		defer cancel()
+	}
+	return cl.close(ctx)
+}
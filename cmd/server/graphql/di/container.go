@@ -5,22 +5,50 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	appports "github.com/abitofhelp/family-service/core/application/ports"
 	application "github.com/abitofhelp/family-service/core/application/services"
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	"github.com/abitofhelp/family-service/core/domain/entity"
 	domainports "github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/reporting"
 	domainservices "github.com/abitofhelp/family-service/core/domain/services"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/alerting"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/audit"
+	authwrapper "github.com/abitofhelp/family-service/infrastructure/adapters/authwrapper"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/cachewrapper"
+	circuit "github.com/abitofhelp/family-service/infrastructure/adapters/circuitwrapper"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
 	adaptdi "github.com/abitofhelp/family-service/infrastructure/adapters/diwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/eventschema"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/integrity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/kafka"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/loggingwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/migration"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/mongo"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/outbox"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/postgres"
+	rate "github.com/abitofhelp/family-service/infrastructure/adapters/ratewrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/reportstore"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/shadow"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/sqlite"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/staleread"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/tenancy"
+	"github.com/abitofhelp/family-service/infrastructure/workers"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/authz"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/capture"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/dto"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/maintenance"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/slo"
 	"github.com/abitofhelp/servicelib/auth"
 	basedi "github.com/abitofhelp/servicelib/di"
+	"github.com/abitofhelp/servicelib/logging"
 	"go.uber.org/zap"
 )
 
@@ -31,9 +59,23 @@ type Container struct {
 	familyDomainService *domainservices.FamilyDomainService
 	familyAppService    appports.FamilyApplicationService
 	familyMapper        dto.FamilyMapper
-	authService         *auth.Auth
+	authKeys            *authwrapper.Keys
+	auditLog            *audit.Log
+	schemaPlanner       migration.Planner
+	outboxStore         domainports.OutboxRepository
+	maintenanceMode     *maintenance.Mode
+	captureRecorder     *capture.Recorder
+	tenantRouter        *tenancy.Router
+	authorizer          *authz.Authorizer
 	dbType              string
 	cache               *cache.Cache
+	workerCoordinator   *workers.Coordinator
+	sloTracker          *slo.Tracker
+	httpRateLimiter     *rate.SubjectLimiter
+
+	closersMu    sync.Mutex
+	closers      []closer
+	closeTimeout time.Duration // Timeout of each closer (0 for none)
 }
 
 // NewContainer creates a new dependency injection container for the GraphQL server
@@ -49,32 +91,67 @@ func NewContainer(ctx context.Context, logger *zap.Logger, cfg *config.Config) (
 	postgres.SetGlobalConfig(cfg)
 	sqlite.SetGlobalConfig(cfg)
 
+	// Configure validation of external reference IDs
+	externalIDRules, err := cfg.ExternalIDs.Rules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure external IDs: %w", err)
+	}
+	entity.ConfigureExternalIDs(externalIDRules)
+
 	// Create GraphQL-specific container
 	container := &Container{
-		Container: baseContainer,
-		dbType:    cfg.Database.Type,
+		Container:         baseContainer,
+		dbType:            cfg.Database.Type,
+		workerCoordinator: workers.NewCoordinator(cfg.Server.WorkerDrainTimeout, logger),
+		closeTimeout:      cfg.Server.ShutdownTimeout,
+	}
+
+	// Create the SLO tracker, retaining outcomes for the longest reported window
+	if cfg.Telemetry.SLO.Enabled {
+		container.sloTracker = slo.NewTracker(cfg.Telemetry.SLO.Objective, time.Minute, 24*time.Hour)
 	}
 
-	// Initialize repository based on database type
+	// Create the per-subject limiter for GraphQL requests (nil when disabled)
+	container.httpRateLimiter = rate.NewSubjectLimiter("graphql", &cfg.Server.RateLimit, logger)
+
+	// Initialize repository based on database type, logging at the level of repositories
+	repoLogger := loggingwrapper.Component(logger, loggingwrapper.ComponentRepository)
 	dbType := cfg.Database.Type
 	switch dbType {
 	case "mongodb":
 		// Initialize MongoDB repository
-		repo, err := adaptdi.InitMongoRepository(ctx, cfg.Database.MongoDB.URI, logger)
+		repo, err := adaptdi.InitMongoRepository(ctx, cfg.Database.MongoDB.URI, repoLogger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize MongoDB repository: %w", err)
 		}
 		container.familyRepo = repo
 	case "postgres":
 		// Initialize PostgreSQL repository
-		repo, err := adaptdi.InitPostgresRepository(ctx, cfg.Database.Postgres.DSN, logger)
+		// Use a tenant-aware pool when row-level security is enabled
+		var initializer adaptdi.PostgresInitializerFunc
+		if cfg.Database.Postgres.RowLevelSecurity {
+			initializer = postgres.TenantAwarePostgresInitializer
+		}
+		repo, err := adaptdi.InitPostgresRepository(ctx, cfg.Database.Postgres.DSN, repoLogger, initializer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize PostgreSQL repository: %w", err)
 		}
+		// Read analytics work, such as reports, through a separate read-only pool
+		if cfg.Database.Postgres.Analytics.Enabled {
+			pool, err := postgres.NewAnalyticsPool(ctx, cfg.Database.Postgres, repoLogger)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize PostgreSQL analytics pool: %w", err)
+			}
+			repo.SetAnalyticsPool(pool)
+			container.RegisterCloser("analytics-pool", PhaseStorage, 0, func(context.Context) error {
+				pool.Close()
+				return nil
+			})
+		}
 		container.familyRepo = repo
 	case "sqlite":
 		// Initialize SQLite repository
-		repo, err := adaptdi.InitSQLiteRepository(ctx, cfg.Database.SQLite.URI, logger)
+		repo, err := adaptdi.InitSQLiteRepository(ctx, cfg.Database.SQLite.URI, repoLogger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize SQLite repository: %w", err)
 		}
@@ -83,12 +160,77 @@ func NewContainer(ctx context.Context, logger *zap.Logger, cfg *config.Config) (
 		return nil, fmt.Errorf("unsupported database type: %s", dbType)
 	}
 
+	// Quarantines placed by administrators are kept in the primary database
+	quarantines, _ := container.familyRepo.(domainports.QuarantineRepository)
+
+	// The outbox of domain events is kept in the primary database, with the families, and
+	// events are stored in its units of work
+	outboxStore, _ := container.familyRepo.(domainports.OutboxRepository)
+	outboxUnit, _ := container.familyRepo.(domainports.UnitOfWork)
+
+	// The admin API reindexes the primary database
+	container.schemaPlanner, _ = container.familyRepo.(migration.Planner)
+
+	// Alerts report the integrity of the primary database
+	scanner, _ := container.familyRepo.(integrity.Scanner)
+
+	// Shadow reads, and with dual writes also saves, to a second repository to validate
+	// parity before switching backends
+	if cfg.Database.Shadow.Enabled {
+		shadowRepo, err := initShadowRepository(ctx, cfg.Database.Shadow, repoLogger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize shadow repository: %w", err)
+		}
+		repo := shadow.NewRepository(container.familyRepo, shadowRepo, cfg.Database.Shadow, logging.NewContextLogger(repoLogger))
+		container.workerCoordinator.RegisterFunc("shadow-repository", 0, repo.Stop)
+		container.familyRepo = repo
+	}
+
+	// Route the tenants with dedicated databases to them; the shadow, quarantines, and
+	// reindex apply to the primary database only
+	if cfg.Database.Tenancy.Enabled {
+		router, err := tenancy.NewRouter(container.familyRepo, cfg.Database.Tenancy, func(ctx context.Context, name string, dsCfg config.DatasourceConfig) (*tenancy.Datasource, error) {
+			return openTenantDatasource(ctx, dsCfg, cfg.Database.Postgres.RowLevelSecurity, repoLogger.With(zap.String("datasource", name)))
+		}, logging.NewContextLogger(repoLogger))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize tenant routing: %w", err)
+		}
+		router.Start()
+		container.workerCoordinator.RegisterFunc("tenant-datasources", 0, router.Stop)
+		container.tenantRouter = router
+		container.familyRepo = router
+	}
+
+	// Serve queries from snapshots of the families read most recently while the circuit
+	// breaker of the database is open; writes still fail fast
+	if cfg.Database.StaleReads.Enabled {
+		container.familyRepo = staleread.NewRepository(container.familyRepo, cfg.Database.StaleReads, logging.NewContextLogger(repoLogger))
+	}
+
 	// Initialize cache
 	cacheInstance, err := cache.NewCache(cfg, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize cache: %w", err)
 	}
 	container.cache = cacheInstance
+	container.RegisterCloser("cache", PhaseServices, 0, func(context.Context) error {
+		cacheInstance.Shutdown()
+		return nil
+	})
+
+	// Serve the families read by ID from the cache, in front of every other wrapper; in
+	// Redis, the cached families are shared by all replicas
+	if cacheInstance != nil && cfg.Cache.ReadThrough {
+		var backend cache.Backend = cacheInstance
+		if cfg.Cache.Backend == cache.BackendRedis {
+			redisBackend := cache.NewRedisBackend(cfg.Cache, &cfg.Circuit, &cfg.Rate, logger)
+			container.RegisterCloser("cache-redis", PhaseServices, 0, func(context.Context) error {
+				return redisBackend.Close()
+			})
+			backend = redisBackend
+		}
+		container.familyRepo = cache.NewRepository(container.familyRepo, backend, logging.NewContextLogger(repoLogger))
+	}
 
 	// Create a wrapper logger for the domain service
 	wrapperLogger := loggingwrapper.NewContextLogger(logger)
@@ -96,35 +238,414 @@ func NewContainer(ctx context.Context, logger *zap.Logger, cfg *config.Config) (
 	// Initialize domain service
 	container.familyDomainService = domainservices.NewFamilyDomainService(container.familyRepo, wrapperLogger)
 
+	// Configure the parent-child age plausibility policy
+	agePolicy, err := cfg.Policy.Age.Policy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure age policy: %w", err)
+	}
+	container.familyDomainService.SetAgePolicy(agePolicy)
+
+	// Detect new families with the same members as stored families
+	duplicatePolicy, err := cfg.Policy.Duplicates.Policy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure duplicate policy: %w", err)
+	}
+	container.familyDomainService.SetDuplicatePolicy(duplicatePolicy)
+
+	// Withhold the data of minors without consent, and flag stale consents as expired
+	consentPolicy := cfg.Policy.Consent.Policy()
+	container.familyDomainService.SetConsentPolicy(consentPolicy)
+	if consentPolicy.Enabled() && cfg.Policy.Consent.CheckInterval > 0 {
+		expiry := workers.NewPeriodic("consent-expiry", cfg.Policy.Consent.CheckInterval, func(ctx context.Context) error {
+			_, err := container.familyDomainService.ExpireStaleConsents(ctx)
+			return err
+		}, logger)
+		expiry.Start()
+		container.workerCoordinator.Register(expiry)
+	}
+
+	// Purge the soft deleted families of every tenant once their retention has passed. Under
+	// row-level security the purge needs a role that bypasses it, or it would purge nothing.
+	if cfg.Database.Deletion.Retention > 0 && cfg.Database.Deletion.PurgeInterval > 0 {
+		if usesPostgres(cfg) && cfg.Database.Postgres.RowLevelSecurity && cfg.Database.Postgres.MaintenanceRole == "" {
+			return nil, fmt.Errorf("failed to schedule the purge of deleted families: %w", postgres.ErrNoMaintenanceRole)
+		}
+		retention := cfg.Database.Deletion.Retention
+		purge := workers.NewPeriodic("deleted-family-purge", cfg.Database.Deletion.PurgeInterval, func(ctx context.Context) error {
+			_, err := container.familyDomainService.PurgeDeletedFamilies(ctx, clock.Now(ctx).Add(-retention), true)
+			return err
+		}, logger)
+		purge.Start()
+		container.workerCoordinator.Register(purge)
+	}
+
+	// Generate the aggregate reports for statistical agencies on a schedule
+	if cfg.Reporting.Enabled && cfg.Reporting.Interval > 0 {
+		reportOptions, err := cfg.Reporting.Options()
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure reports: %w", err)
+		}
+		store, err := reportstore.New(cfg.Reporting.Directory, cfg.Reporting.Formats, cfg.Reporting.Retention, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure reports: %w", err)
+		}
+		reports := workers.NewPeriodic("family-reports", cfg.Reporting.Interval, store.Job(func(ctx context.Context) (*reporting.Report, error) {
+			return container.familyDomainService.GenerateReport(ctx, reportOptions)
+		}), logger)
+		reports.Start()
+		container.workerCoordinator.Register(reports)
+	}
+
+	// Notify operators of circuits that stay open and of corrupt families
+	if cfg.Alerts.Enabled {
+		if err := container.startAlerts(cfg.Alerts, scanner, logger); err != nil {
+			return nil, fmt.Errorf("failed to configure alerts: %w", err)
+		}
+	}
+
+	// Refuse to start with event schemas that are incompatible with the published ones
+	eventRegistry := eventschema.NewDefaultRegistry("family-service/" + cfg.App.Version)
+	if err := eventRegistry.Check(eventschema.Published()); err != nil {
+		return nil, fmt.Errorf("incompatible event schemas: %w", err)
+	}
+
+	// Record domain events, such as children moving between families and access to
+	// quarantined families, as audit entries
+	auditLog := audit.NewLog(logger, eventRegistry)
+	container.auditLog = auditLog
+
+	// Keep the audit entries in a journal, pruned by the retention policy
+	if cfg.Audit.Journal.Enabled {
+		if err := container.startAuditJournal(cfg.Audit, logger); err != nil {
+			return nil, fmt.Errorf("failed to configure audit journal: %w", err)
+		}
+	}
+	var events domainports.EventPublisher = auditLog
+	var eventOutbox domainports.EventPublisher
+
+	// Publish domain events to Kafka as well, for the data lake
+	if cfg.Kafka.Enabled {
+		publisher, err := kafka.NewPublisher(cfg.Kafka, eventRegistry, &cfg.Circuit, cfg.Retry, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure kafka publisher: %w", err)
+		}
+
+		// Store the events in the outbox with the changes that raised them, and relay them
+		// to Kafka from there, rather than publishing them once the changes are saved
+		if cfg.Kafka.Outbox.Enabled {
+			if outboxStore == nil {
+				return nil, fmt.Errorf("failed to configure outbox: the %s repository has no outbox", dbType)
+			}
+			if err := checkOutboxUnitOfWork(ctx, outboxUnit, cfg); err != nil {
+				return nil, fmt.Errorf("failed to configure outbox: %w", err)
+			}
+			eventOutbox = outbox.NewOutbox(outboxStore, eventRegistry)
+			relay := outbox.NewRelay(outboxStore, publisher, cfg.Kafka.Outbox.BatchSize, logger)
+			relayWorker := workers.NewPeriodic("outbox-relay", cfg.Kafka.Outbox.Interval, relay.Run, logger)
+			relayWorker.Start()
+			container.workerCoordinator.Register(relayWorker)
+			container.outboxStore = outboxStore
+		} else {
+			events = domainports.EventPublishers{auditLog, publisher}
+		}
+	}
+	container.familyDomainService.SetEventPublisher(events)
+	container.familyDomainService.SetEventOutbox(eventOutbox)
+
+	// Let administrators quarantine families
+	container.familyDomainService.SetQuarantineRepository(quarantines)
+
+	// Build the alternate domain rules of the canary strategies, which share the repository,
+	// events, outbox, and quarantines of the default domain rules
+	strategies := make(map[string]*domainservices.FamilyDomainService)
+	if cfg.Canary.Enabled {
+		for name, strategyCfg := range cfg.Canary.Strategies {
+			strategyService := domainservices.NewFamilyDomainService(container.familyRepo, wrapperLogger)
+			strategyAgePolicy, err := strategyCfg.Policy.Age.Policy()
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure age policy of canary strategy %s: %w", name, err)
+			}
+			strategyService.SetAgePolicy(strategyAgePolicy)
+			strategyService.SetConsentPolicy(strategyCfg.Policy.Consent.Policy())
+			strategyDuplicatePolicy, err := strategyCfg.Policy.Duplicates.Policy()
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure duplicate policy of canary strategy %s: %w", name, err)
+			}
+			strategyService.SetDuplicatePolicy(strategyDuplicatePolicy)
+			strategyService.SetEventPublisher(events)
+			strategyService.SetEventOutbox(eventOutbox)
+			strategyService.SetQuarantineRepository(quarantines)
+			strategies[name] = strategyService
+		}
+	}
+
 	// Initialize application service
-	container.familyAppService = application.NewFamilyApplicationService(
+	appService := application.NewFamilyApplicationService(
 		container.familyDomainService,
 		container.familyRepo,
 		wrapperLogger.ToServiceLibLogger(),
 		container.cache,
 	)
+	appService.SetStrategies(strategies)
+	appService.SetMutationTimeout(cfg.Mutations.Timeout)
+	container.familyAppService = appService
 
 	// Initialize family mapper
-	container.familyMapper = dto.NewFamilyMapper()
+	container.familyMapper = dto.NewFamilyMapperWithConsentPolicy(consentPolicy)
 
 	// Initialize auth service
-	// Note: In the future, this should be configured to use a remote authorization server
-	// instead of local token validation for improved security and centralized management.
 	authConfig := auth.DefaultConfig()
-	authConfig.JWT.SecretKey = cfg.Auth.JWT.SecretKey
 	authConfig.JWT.Issuer = cfg.Auth.JWT.Issuer
 	authConfig.JWT.TokenDuration = cfg.Auth.JWT.TokenDuration
-	authConfig.Middleware.SkipPaths = []string{"/health", "/metrics", "/playground", "/graphql/health"}
+	authConfig.Middleware.SkipPaths = authSkipPaths(cfg)
+	if cfg.Server.QuitEndpoint != "" {
+		// The quit endpoint only accepts requests from the loopback interface
+		authConfig.Middleware.SkipPaths = append(authConfig.Middleware.SkipPaths, cfg.Server.QuitEndpoint)
+	}
+	if cfg.Telemetry.SLO.Enabled {
+		authConfig.Middleware.SkipPaths = append(authConfig.Middleware.SkipPaths, cfg.Telemetry.SLO.Path)
+	}
 
-	authService, err := auth.New(ctx, authConfig, logger)
+	// Build an auth service for each JWT secret key, so that the keys can be rotated
+	authLogger := loggingwrapper.Component(logger, loggingwrapper.ComponentAuth)
+	authKeys, err := authwrapper.NewKeys(ctx, cfg.Auth.JWT, func(ctx context.Context, secretKey string) (*auth.Auth, error) {
+		keyConfig := authConfig
+		keyConfig.JWT.SecretKey = secretKey
+		return auth.New(ctx, keyConfig, authLogger)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize auth service: %w", err)
 	}
-	container.authService = authService
+	if cfg.Auth.Mode == authwrapper.ModeOIDC {
+		// Validate tokens with the signing keys of the remote authorization server
+		remote, err := authwrapper.NewRemote(ctx, cfg.Auth.OIDC, cfg.Auth.OIDCTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize remote token validation: %w", err)
+		}
+		acceptLocal := cfg.Auth.OIDC.LocalTokensIn(os.Getenv("APP_ENV"))
+		authKeys.UseRemote(remote, acceptLocal, authConfig.Middleware.SkipPaths)
+		authLogger.Info("Validating tokens with a remote authorization server",
+			zap.String("issuer", remote.Issuer()), zap.Bool("acceptLocalTokens", acceptLocal))
+	}
+	container.authKeys = authKeys
+
+	// Initialize the authorizer for per-operation scopes
+	container.authorizer = authz.NewAuthorizer(cfg.Auth.AcceptCoarseScopes)
+
+	// Let operators refuse mutations during maintenance
+	container.maintenanceMode = maintenance.NewMode()
+
+	// Record sanitized operations for administrators debugging hard-to-reproduce bugs
+	if cfg.Capture.Enabled {
+		container.captureRecorder = capture.NewRecorder(cfg.Capture.Capacity, cfg.Capture.MaskFields)
+	}
 
 	return container, nil
 }
 
+// authSkipPaths returns the paths served without authentication: the health and metrics
+// endpoints, the pages of the routes, and the OpenAPI document of the REST gateway. Paths are matched as prefixes, so unset paths
+// are left out rather than skipping authentication everywhere.
+func authSkipPaths(cfg *config.Config) []string {
+	var paths []string
+	for _, path := range []string{cfg.Server.HealthEndpoint, cfg.Telemetry.Exporters.Metrics.Prometheus.Path} {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	routes := cfg.Server.Routes
+	for _, route := range []string{routes.Playground, routes.Docs, routes.Login, routes.Lifecycle} {
+		if route != "" {
+			paths = append(paths, routes.Path(route))
+		}
+	}
+	if routes.GraphQL != "" {
+		paths = append(paths, routes.Path(routes.GraphQL)+"/health")
+	}
+	if cfg.REST.Enabled {
+		paths = append(paths, strings.TrimSuffix(cfg.REST.Path, "/")+"/openapi.json")
+	}
+	return paths
+}
+
+// initShadowRepository initializes the repository that reads are shadowed to
+func initShadowRepository(ctx context.Context, cfg config.ShadowConfig, logger *zap.Logger) (domainports.FamilyRepository, error) {
+	if cfg.URI == "" {
+		return nil, fmt.Errorf("shadow repository URI is required")
+	}
+
+	var (
+		repo domainports.FamilyRepository
+		err  error
+	)
+	switch cfg.Type {
+	case "mongodb":
+		repo, err = adaptdi.InitMongoRepository(ctx, cfg.URI, logger)
+	case "postgres":
+		repo, err = adaptdi.InitPostgresRepository(ctx, cfg.URI, logger)
+	case "sqlite":
+		repo, err = adaptdi.InitSQLiteRepository(ctx, cfg.URI, logger)
+	default:
+		return nil, fmt.Errorf("unsupported shadow database type: %q", cfg.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// usesPostgres reports whether the primary database, or the dedicated database of a tenant,
+// is a PostgreSQL database
+func usesPostgres(cfg *config.Config) bool {
+	if cfg.Database.Type == "postgres" {
+		return true
+	}
+	if cfg.Database.Tenancy.Enabled {
+		for _, datasource := range cfg.Database.Tenancy.Datasources {
+			if datasource.Type == "postgres" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkOutboxUnitOfWork checks that every event is stored in the outbox in the unit of work
+// of the change that raised it. The primary database must run units of work, which a
+// standalone MongoDB server cannot, and no tenant may have a dedicated database, whose
+// units of work cannot store events in the outbox of the primary database.
+func checkOutboxUnitOfWork(ctx context.Context, unit domainports.UnitOfWork, cfg *config.Config) error {
+	if cfg.Database.Tenancy.Enabled && len(cfg.Database.Tenancy.Datasources) > 0 {
+		return fmt.Errorf("tenants with dedicated databases cannot store their events in the outbox of the primary database")
+	}
+	if unit == nil {
+		return fmt.Errorf("the %s repository cannot run units of work", cfg.Database.Type)
+	}
+
+	unitCtx, err := unit.Begin(ctx)
+	if errors.Is(err, domainports.ErrNoUnitOfWork) {
+		return fmt.Errorf("the %s database cannot run units of work; MongoDB must be a replica set or a sharded cluster", cfg.Database.Type)
+	}
+	if err != nil {
+		return err
+	}
+	return unit.Rollback(unitCtx)
+}
+
+// openTenantDatasource opens the dedicated database of a tenant datasource
+func openTenantDatasource(ctx context.Context, cfg config.DatasourceConfig, rowLevelSecurity bool, logger *zap.Logger) (*tenancy.Datasource, error) {
+	switch cfg.Type {
+	case "mongodb":
+		repo, err := adaptdi.InitMongoRepository(ctx, cfg.URI, logger)
+		if err != nil {
+			return nil, err
+		}
+		client := repo.Collection.Database().Client()
+		return &tenancy.Datasource{
+			Repository: repo,
+			Ping:       func(ctx context.Context) error { return client.Ping(ctx, nil) },
+			Close:      client.Disconnect,
+		}, nil
+	case "postgres":
+		var initializer adaptdi.PostgresInitializerFunc
+		if rowLevelSecurity {
+			initializer = postgres.TenantAwarePostgresInitializer
+		}
+		repo, err := adaptdi.InitPostgresRepository(ctx, cfg.URI, logger, initializer)
+		if err != nil {
+			return nil, err
+		}
+		return &tenancy.Datasource{
+			Repository: repo,
+			Ping:       repo.DB.Ping,
+			Close: func(ctx context.Context) error {
+				repo.DB.Close()
+				return nil
+			},
+		}, nil
+	case "sqlite":
+		repo, err := adaptdi.InitSQLiteRepository(ctx, cfg.URI, logger)
+		if err != nil {
+			return nil, err
+		}
+		return &tenancy.Datasource{
+			Repository: repo,
+			Ping:       repo.DB.PingContext,
+			Close: func(ctx context.Context) error {
+				return repo.DB.Close()
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported tenant database type: %q", cfg.Type)
+	}
+}
+
+// startAuditJournal appends the audit entries to a journal, closed with the container, and starts
+// the worker that enforces its retention policy, if it is enabled
+func (c *Container) startAuditJournal(cfg config.AuditConfig, logger *zap.Logger) error {
+	journal, err := audit.NewJournal(cfg.Journal.Directory, cfg.Journal.SegmentBytes)
+	if err != nil {
+		return err
+	}
+	c.auditLog.SetJournal(journal)
+	c.RegisterCloser("audit-journal", PhaseStorage, 0, func(context.Context) error {
+		return journal.Close()
+	})
+
+	retention := cfg.Retention
+	if !retention.Enabled || retention.Interval <= 0 {
+		return nil
+	}
+	policy := audit.Retention{
+		MaxAge:     retention.MaxAge,
+		MaxBytes:   retention.MaxBytes,
+		LegalHolds: retention.LegalHolds,
+	}
+	if retention.Archive.URL != "" {
+		archive, err := audit.NewArchiver(retention.Archive.URL, retention.Archive.Token, retention.Archive.Timeout)
+		if err != nil {
+			return err
+		}
+		policy.Archive = archive
+	}
+	pruning := workers.NewPeriodic("audit-retention", retention.Interval, journal.PruneJob(policy, logger), logger)
+	pruning.Start()
+	c.workerCoordinator.Register(pruning)
+	return nil
+}
+
+// startAlerts starts the workers that evaluate the critical conditions of the service and
+// check the integrity of the families of the scanner, if it is not nil
+func (c *Container) startAlerts(cfg config.AlertsConfig, scanner integrity.Scanner, logger *zap.Logger) error {
+	if cfg.CheckInterval <= 0 {
+		return fmt.Errorf("alerts.check_interval must be positive")
+	}
+	notifiers, err := alerting.NewNotifiers(cfg)
+	if err != nil {
+		return err
+	}
+	monitor := alerting.NewMonitor(notifiers, cfg.RepeatInterval, cfg.RateLimit, logger)
+	monitor.AddCondition(alerting.CircuitOpen(cfg.CircuitOpenAfter, circuit.OpenCircuits))
+
+	if cfg.IntegrityInterval > 0 {
+		if scanner == nil {
+			logger.Warn("Integrity checks are not supported by the database, so corrupt families are not alerted")
+		} else {
+			check := alerting.NewIntegrityCheck(integrity.NewChecker(scanner, integrity.Options{}, logger))
+			monitor.AddCondition(check)
+			checks := workers.NewPeriodic("integrity-checks", cfg.IntegrityInterval, check.Run, logger)
+			checks.Start()
+			c.workerCoordinator.Register(checks)
+		}
+	}
+
+	alerts := workers.NewPeriodic("alerts", cfg.CheckInterval, monitor.Evaluate, logger)
+	alerts.Start()
+	c.workerCoordinator.Register(alerts)
+	return nil
+}
+
 // GetFamilyRepository returns the family repository
 func (c *Container) GetFamilyRepository() domainports.FamilyRepository {
 	return c.familyRepo
@@ -152,12 +673,61 @@ func (c *Container) GetFamilyService() interface{} {
 
 // For backward compatibility with the test file
 func (c *Container) GetAuthorizationService() interface{} {
-	return c.authService
+	return c.authKeys.Current()
 }
 
-// GetAuthService returns the auth service
+// GetAuthService returns the auth service of the current JWT secret key
 func (c *Container) GetAuthService() *auth.Auth {
-	return c.authService
+	return c.authKeys.Current()
+}
+
+// GetAuthKeys returns the JWT secret keys, which validate tokens and can be rotated
+func (c *Container) GetAuthKeys() *authwrapper.Keys {
+	return c.authKeys
+}
+
+// GetMaintenanceMode returns the maintenance mode of the GraphQL API
+func (c *Container) GetMaintenanceMode() *maintenance.Mode {
+	return c.maintenanceMode
+}
+
+// GetCaptureRecorder returns the recorder of captured GraphQL operations, or nil if
+// operation capture is disabled
+func (c *Container) GetCaptureRecorder() *capture.Recorder {
+	return c.captureRecorder
+}
+
+// GetTenantRouter returns the router of the tenants with dedicated databases, or nil if
+// tenant routing is disabled
+func (c *Container) GetTenantRouter() *tenancy.Router {
+	return c.tenantRouter
+}
+
+// GetAuditLog returns the audit log
+func (c *Container) GetAuditLog() *audit.Log {
+	return c.auditLog
+}
+
+// GetCache returns the cache, or nil if caching is disabled
+func (c *Container) GetCache() *cache.Cache {
+	return c.cache
+}
+
+// GetSchemaPlanner returns the schema planner of the primary repository, or nil if its
+// backend has none
+func (c *Container) GetSchemaPlanner() migration.Planner {
+	return c.schemaPlanner
+}
+
+// GetOutboxStore returns the repository whose outbox stores the domain events, or nil if
+// events are not stored in an outbox
+func (c *Container) GetOutboxStore() domainports.OutboxRepository {
+	return c.outboxStore
+}
+
+// GetAuthorizer returns the authorizer that enforces per-operation scopes
+func (c *Container) GetAuthorizer() *authz.Authorizer {
+	return c.authorizer
 }
 
 // GetFamilyMapper returns the family mapper
@@ -165,21 +735,30 @@ func (c *Container) GetFamilyMapper() dto.FamilyMapper {
 	return c.familyMapper
 }
 
-// Close closes all resources
-func (c *Container) Close() error {
-	var errs []error
+// GetWorkerCoordinator returns the coordinator that drains background workers on shutdown
+func (c *Container) GetWorkerCoordinator() *workers.Coordinator {
+	return c.workerCoordinator
+}
 
-	// Shutdown cache if it exists
-	if c.cache != nil {
-		c.cache.Shutdown()
-	}
+// GetSLOTracker returns the GraphQL SLO tracker, or nil if SLO tracking is disabled
+func (c *Container) GetSLOTracker() *slo.Tracker {
+	return c.sloTracker
+}
 
-	// Add resource cleanup here as needed
-	// For example, close database connections if they implement a Close method
+// GetHTTPRateLimiter returns the per-subject GraphQL rate limiter, or nil if rate limiting is disabled
+func (c *Container) GetHTTPRateLimiter() *rate.SubjectLimiter {
+	return c.httpRateLimiter
+}
+
+// Close closes all resources: the registered closers, by phase, then the base container
+func (c *Container) Close() error {
+	errs := c.runClosers()
 
 	// Close base container
-	if err := c.Container.Close(); err != nil {
-		errs = append(errs, err)
+	if c.Container != nil {
+		if err := c.Container.Close(); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	// Return a combined error if any occurred
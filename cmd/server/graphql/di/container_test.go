@@ -4,8 +4,10 @@
 
 import (
 	"context"
+	"errors"
 	"github.com/abitofhelp/family-service/cmd/server/graphql/di"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -237,6 +239,62 @@ func TestNewContainer_UnsupportedDB(t *testing.T) {
 	assert.Contains(t, err.Error(), "unsupported database type")
 }
 
+// TestNewContainer_OutboxUnitOfWork tests that the outbox is configured only where every
+// event is stored in the unit of work of its change
+func TestNewContainer_OutboxUnitOfWork(t *testing.T) {
+	newConfig := func(t *testing.T) *config.Config {
+		return &config.Config{
+			App: config.AppConfig{Version: "test"},
+			Auth: config.AuthConfig{
+				OIDCTimeout: 5 * time.Second,
+				JWT: config.JWTConfig{
+					SecretKey:     "test-secret-key-of-at-least-32-characters",
+					TokenDuration: 24 * time.Hour,
+					Issuer:        "test-issuer",
+				},
+			},
+			Database: config.DatabaseConfig{
+				Type: "sqlite",
+				SQLite: config.SQLiteConfig{
+					URI:               "file:" + filepath.Join(t.TempDir(), "families.db"),
+					ConnectionTimeout: 5 * time.Second,
+					DisconnectTimeout: 5 * time.Second,
+					MigrationTimeout:  5 * time.Second,
+					PingTimeout:       5 * time.Second,
+				},
+			},
+			Kafka: config.KafkaConfig{
+				Enabled: true,
+				URL:     "http://localhost:8082",
+				Topic:   "family-service.events",
+				Timeout: time.Second,
+				Outbox:  config.OutboxConfig{Enabled: true, Interval: time.Hour, BatchSize: 100},
+			},
+			Log: config.LogConfig{Level: "info", Development: true},
+		}
+	}
+
+	t.Run("primary database with units of work", func(t *testing.T) {
+		container, err := di.NewContainer(context.Background(), zaptest.NewLogger(t), newConfig(t))
+		require.NoError(t, err)
+		assert.NotNil(t, container.GetOutboxStore())
+		require.NoError(t, container.Close())
+	})
+
+	t.Run("tenants with dedicated databases", func(t *testing.T) {
+		cfg := newConfig(t)
+		cfg.Database.Tenancy = config.TenancyConfig{
+			Enabled:     true,
+			Datasources: map[string]config.DatasourceConfig{"acme": {Type: "sqlite", URI: "file:" + filepath.Join(t.TempDir(), "acme.db")}},
+			Tenants:     map[string]string{"acme": "acme"},
+		}
+		container, err := di.NewContainer(context.Background(), zaptest.NewLogger(t), cfg)
+		assert.Nil(t, container)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to configure outbox: tenants with dedicated databases")
+	})
+}
+
 // TestContainer_Getters tests the getter methods of the container
 func TestContainer_Getters(t *testing.T) {
 	// This test uses a mock repository factory to avoid external dependencies
@@ -345,3 +403,35 @@ func TestContainer_Close_Error(t *testing.T) {
 	// 2. Set it as the container's repository factory
 	// 3. Call Close and verify that it returns an error
 }
+
+// TestContainer_RegisterCloser tests that the closers run by phase, priority, and reverse
+// registration order, and that a failing closer does not stop the others
+func TestContainer_RegisterCloser(t *testing.T) {
+	// Setup
+	container := &di.Container{}
+	var order []string
+	record := func(name string, err error) di.CloseFunc {
+		return func(ctx context.Context) error {
+			order = append(order, name)
+			return err
+		}
+	}
+	container.RegisterCloser("pool", di.PhaseStorage, 0, record("pool", nil))
+	container.RegisterCloser("cache", di.PhaseServices, 0, record("cache", errors.New("boom")))
+	container.RegisterCloser("relay", di.PhaseProducers, 0, record("relay", nil))
+	container.RegisterCloser("client", di.PhaseServices, 0, record("client", nil))
+	container.RegisterCloser("buffer", di.PhaseServices, 10, record("buffer", nil))
+
+	// Act
+	err := container.Close()
+
+	// Assert
+	assert.Equal(t, []string{"relay", "buffer", "client", "cache", "pool"}, order)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache (services): boom")
+
+	// Closers run once
+	order = nil
+	assert.NoError(t, container.Close())
+	assert.Empty(t, order)
+}
@@ -58,6 +58,10 @@ func NewFamilyContainer[T domainports.FamilyRepository](
 		return nil, fmt.Errorf("failed to initialize cache: %w", err)
 	}
 	container.cache = cacheInstance
+	container.RegisterCloser("family-cache", PhaseServices, 0, func(context.Context) error {
+		cacheInstance.Shutdown()
+		return nil
+	})
 
 	// Initialize domain service
 	contextLogger := loggingwrapper.NewContextLogger(logger)
@@ -103,32 +107,3 @@ func (c *FamilyContainer[T]) GetFamilyService() interface{} {
 func (c *FamilyContainer[T]) GetAuthorizationService() interface{} {
 	return c.Container.GetAuthService()
 }
-
-// Close closes all resources
-func (c *FamilyContainer[T]) Close() error {
-	var errs []error
-
-	// Shutdown cache if it exists
-	if c.cache != nil {
-		c.cache.Shutdown()
-	}
-
-	// Add resource cleanup here as needed
-	// For example, close database connections if they implement a Close method
-
-	// Close base container
-	if err := c.Container.Close(); err != nil {
-		errs = append(errs, err)
-	}
-
-	// Return a combined error if any occurred
-	if len(errs) > 0 {
-		errMsg := "failed to close one or more resources:"
-		for _, err := range errs {
-			errMsg += " " + err.Error()
-		}
-		return fmt.Errorf("%s", errMsg)
-	}
-
-	return nil
-}
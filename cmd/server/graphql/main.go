@@ -22,20 +22,56 @@
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/abitofhelp/family-service/cmd/server/graphql/di"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/loggingwrapper"
 	infratelemetry "github.com/abitofhelp/family-service/infrastructure/adapters/telemetrywrapper"
+	"github.com/abitofhelp/family-service/infrastructure/readiness"
 	"github.com/abitofhelp/family-service/infrastructure/server"
+	"github.com/abitofhelp/family-service/infrastructure/workers"
+	"github.com/abitofhelp/family-service/interface/adapters/admin"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/canary"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/capture"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/compat"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/contract"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/cost"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/docs"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/etag"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/generated"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/landing"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/lifecycle"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/loader"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/locale"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/login"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/maintenance"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/quarantine"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/ratelimit"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/redaction"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/resolver"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/resolverlimit"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/resultsize"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/retryafter"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/session"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/slo"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/staleness"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/strict"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/testclock"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/veto"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/websocket"
+	"github.com/abitofhelp/family-service/interface/adapters/rest"
 	pkgconfig "github.com/abitofhelp/servicelib/config"
 	"github.com/abitofhelp/servicelib/graphql"
 	"github.com/abitofhelp/servicelib/health"
 	"github.com/abitofhelp/servicelib/logging"
 	"github.com/abitofhelp/servicelib/shutdown"
 	"github.com/abitofhelp/servicelib/telemetry"
+	"github.com/vektah/gqlparser/v2/ast"
 	"go.uber.org/zap"
 )
 
@@ -103,9 +139,11 @@ func initLogger(cfg *config.Config, basicLogger *zap.Logger) (*zap.Logger, error
 	basicLogger.Info("Initializing application logger",
 		zap.String("version", cfg.App.Version),
 		zap.String("level", cfg.Log.Level),
-		zap.Bool("development", cfg.Log.Development))
+		zap.Bool("development", cfg.Log.Development),
+		zap.String("format", cfg.Log.Format),
+		zap.Bool("sampling", cfg.Log.Sampling.Enabled))
 
-	logger, err := logging.NewLogger(cfg.Log.Level, cfg.Log.Development)
+	logger, err := loggingwrapper.Build(cfg.Log)
 	if err != nil {
 		basicLogger.Error("Failed to initialize logger", zap.Error(err))
 		return nil, err
@@ -152,7 +190,9 @@ func initContainer(ctx context.Context, logger *zap.Logger, cfg *config.Config)
 // This function configures all the HTTP routes that the server will handle, including:
 // - GraphQL API endpoint
 // - GraphQL Playground for interactive API exploration
-// - Health check endpoint for monitoring
+// - Health check endpoint for monitoring, which reports NOT_READY while the server drains
+// - Quit endpoint for requesting a graceful shutdown from a preStop hook
+// - REST gateway to the family operations, when enabled
 // - Telemetry endpoints for metrics and tracing
 //
 // It also applies middleware and sets up telemetry (metrics and tracing) based on
@@ -161,6 +201,7 @@ func initContainer(ctx context.Context, logger *zap.Logger, cfg *config.Config)
 // Parameters:
 //   - ctx: The context for the setup process
 //   - container: The dependency injection container with application services
+//   - gate: The readiness gate that tracks whether the server accepts traffic
 //   - logger: The logger to use for logging the setup process
 //   - cfg: The application configuration
 //
@@ -168,7 +209,7 @@ func initContainer(ctx context.Context, logger *zap.Logger, cfg *config.Config)
 //   - An HTTP handler with all routes configured
 //   - A shutdown function for telemetry components
 //   - An error if route setup fails
-func setupRoutes(ctx context.Context, container *di.Container, logger *zap.Logger, cfg *config.Config) (http.Handler, func(), error) {
+func setupRoutes(ctx context.Context, container *di.Container, gate *readiness.Gate, logger *zap.Logger, cfg *config.Config) (http.Handler, func(), error) {
 	logger.Info("Setting up HTTP routes")
 
 	// Create a container adapter for health checks
@@ -184,13 +225,74 @@ func setupRoutes(ctx context.Context, container *di.Container, logger *zap.Logge
 	}
 
 	// Set up GraphQL endpoints
-	setupGraphQLEndpoints(mux, container)
+	if err := setupGraphQLEndpoints(mux, container, cfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to set up GraphQL endpoints: %w", err)
+	}
 
 	// Health check endpoint
 	healthEndpoint := cfg.Server.HealthEndpoint
 	logger.Info("Setting up health check endpoint", zap.String("endpoint", healthEndpoint))
 	configAdapter := pkgconfig.NewGenericConfigAdapter(cfg)
-	mux.Handle(healthEndpoint, health.NewHandler(adapter, logger, configAdapter))
+	mux.Handle(healthEndpoint, gate.HealthMiddleware(health.NewHandler(adapter, logger, configAdapter)))
+
+	// Quit endpoint for preStop hooks
+	logger.Info("Setting up quit endpoint", zap.String("endpoint", cfg.Server.QuitEndpoint))
+	mux.Handle(cfg.Server.QuitEndpoint, gate.QuitHandler())
+
+	// Admin API for runbook actions, authorized per action and audited
+	if cfg.Admin.Enabled {
+		logger.Info("Setting up admin API", zap.String("path", cfg.Admin.Path))
+		deps := admin.Dependencies{
+			Keys:        container.GetAuthKeys(),
+			Schema:      container.GetSchemaPlanner(),
+			Families:    container.GetFamilyApplicationService(),
+			Maintenance: container.GetMaintenanceMode(),
+			Config:      cfg,
+			Authorizer:  container.GetAuthorizer(),
+			Audit:       container.GetAuditLog(),
+			Logger:      logger.Named("admin"),
+		}
+		if c := container.GetCache(); c != nil {
+			deps.Cache = c
+		}
+		if router := container.GetTenantRouter(); router != nil {
+			deps.Tenants = router
+		}
+		if recorder := container.GetCaptureRecorder(); recorder != nil {
+			deps.Captures = recorder
+		}
+		if store, ok := container.GetOutboxStore().(admin.Outbox); ok {
+			deps.Outbox = store
+		}
+		if cfg.Admin.ExportRetention > 0 {
+			exports, err := admin.NewExportStore(cfg.Database.MemoryBudget.SpillDir, cfg.Admin.ExportRetention)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create the export store: %w", err)
+			}
+			container.GetWorkerCoordinator().RegisterFunc("admin-exports", 0, exports.Close)
+			deps.Exports = exports
+		}
+		adminHandler := admin.NewHandler(cfg.Admin.Path, deps)
+		container.GetWorkerCoordinator().RegisterFunc("admin-reindex", 0, adminHandler.Stop)
+		mux.Handle(adminHandler.Path(), adminHandler)
+		mux.Handle(adminHandler.Path()+"/", adminHandler)
+	}
+
+	// REST gateway to the family operations, authorized as their GraphQL operations
+	if cfg.REST.Enabled {
+		logger.Info("Setting up REST gateway", zap.String("path", cfg.REST.Path))
+		restHandler, err := rest.NewHandler(cfg.REST.Path, rest.Dependencies{
+			Families:    container.GetFamilyApplicationService(),
+			Mapper:      container.GetFamilyMapper(),
+			Authorizer:  container.GetAuthorizer(),
+			Maintenance: container.GetMaintenanceMode(),
+			Logger:      logger.Named("rest"),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to set up REST gateway: %w", err)
+		}
+		mux.Handle(restHandler.Path()+"/", restHandler)
+	}
 
 	logger.Info("HTTP routes set up successfully")
 	return mux, telemetryShutdown, nil
@@ -252,7 +354,16 @@ func setupTelemetry(ctx context.Context, mux *http.ServeMux, cfg *config.Config,
 // - The main GraphQL API endpoint for handling queries and mutations
 // - GraphQL Playground for interactive API exploration
 // - GraphiQL interface for a more feature-rich API exploration experience
-// - A landing page at the root URL
+// - A read-only documentation portal generated from the schema
+// - Sign-in to the playground and portal with the identity provider, outside production
+// - A landing page at the root of the routes, linking to the endpoint and pages
+// - An SLO compliance endpoint when SLO tracking is enabled
+// - Per-subject rate limiting with advisory headers and response extensions
+// - Rejection of deprecated mutations once the legacy mutations feature is turned off
+// - Review of operations by a veto webhook when configured
+// - Capture of sanitized operations for debugging when capture is enabled
+// - Websocket connections authenticated at connection_init, closed when their token expires
+// - A check of the schema against the operations of the consumers in the contract registry
 //
 // It uses the resolver from the dependency injection container to handle
 // GraphQL operations and sets up authorization directives for securing
@@ -261,9 +372,29 @@ func setupTelemetry(ctx context.Context, mux *http.ServeMux, cfg *config.Config,
 // Parameters:
 //   - mux: The HTTP ServeMux to register GraphQL endpoints on
 //   - container: The dependency injection container with application services
-func setupGraphQLEndpoints(mux *http.ServeMux, container *di.Container) {
+//   - cfg: The application configuration with SLO tracking and feature settings
+//
+// Returns:
+//   - An error if the documentation portal cannot be rendered, login, the veto webhook, the
+//     name templates, or the redaction policy are misconfigured, or the schema breaks a
+//     consumer contract that is enforced
+func setupGraphQLEndpoints(mux *http.ServeMux, container *di.Container, cfg *config.Config) error {
 	// Get the resolver
-	resolverInstance := resolver.NewResolver(container.GetFamilyApplicationService(), container.GetFamilyMapper())
+	resolverInstance := resolver.NewResolver(container.GetFamilyApplicationService(), container.GetFamilyMapper(), container.GetAuthorizer())
+
+	// Format the names of parents and children with the configured templates
+	templates := make(map[string]locale.Template, len(cfg.Names.Templates))
+	for tag, t := range cfg.Names.Templates {
+		templates[tag] = locale.Template{Display: t.Display, Sort: t.Sort}
+	}
+	names, err := locale.NewFormatter(cfg.Names.DefaultLocale, templates)
+	if err != nil {
+		return err
+	}
+	resolverInstance.SetNameFormatter(names)
+
+	// Count the dependents of families with the age of majority of the consent policy
+	resolverInstance.SetMajorityAge(cfg.Policy.Consent.MinorAge)
 
 	// Initialize GraphQL schema
 	schema := generated.NewExecutableSchema(generated.Config{
@@ -275,27 +406,200 @@ func setupGraphQLEndpoints(mux *http.ServeMux, container *di.Container) {
 
 	// Create GraphQL server with configuration
 	gqlServerConfig := graphql.NewDefaultServerConfig()
-	gqlServer := graphql.NewServer(schema, container.GetContextLogger(), gqlServerConfig)
+	resolverLogger := loggingwrapper.Component(container.GetLogger(), loggingwrapper.ComponentResolver)
+	gqlServer := graphql.NewServer(schema, logging.NewContextLogger(resolverLogger), gqlServerConfig)
+
+	// Flag the schema fields whose resolvers are stubs, which fail with NOT_IMPLEMENTED
+	if stubs := resolver.StubbedFields(); len(stubs) > 0 {
+		resolverLogger.Warn("GraphQL fields are not implemented", zap.Strings("fields", stubs))
+	}
 
-	// Serve the landing page at the root
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/" {
-			http.NotFound(w, r)
-			return
+	// Check that the schema serves the operations of the registered consumers
+	if err := checkContracts(schema.Schema(), cfg.Contracts, resolverLogger); err != nil {
+		return err
+	}
+
+	// Let clients estimate the cost of queries against the server's complexity limit
+	resolverInstance.SetCostEstimator(cost.NewEstimator(schema, cost.MaxComplexity(gqlServerConfig)))
+
+	// Serve websocket connections, which carry subscriptions, with a server of their own:
+	// the operation timeout of the HTTP server would end subscriptions, and its default
+	// websocket transport neither authenticates connections nor bounds their lifetime
+	wsServer := handler.New(schema)
+	wsServer.AddTransport(websocket.NewManager(cfg.Server.Websocket, container.GetAuthKeys()).Transport())
+	websocket.HandleErrors(wsServer, logging.NewContextLogger(resolverLogger))
+	wsServer.Use(extension.FixedComplexityLimit(gqlServerConfig.MaxQueryComplexity))
+	wsServer.Use(websocket.Operations{})
+
+	// Apply the extensions of the service to both servers
+	use := func(ext gqlgen.HandlerExtension) {
+		gqlServer.Use(ext)
+		wsServer.Use(ext)
+	}
+
+	// Classify operation results for SLO tracking and expose current compliance
+	if tracker := container.GetSLOTracker(); tracker != nil {
+		use(slo.NewExtension(tracker))
+		mux.Handle(cfg.Telemetry.SLO.Path, slo.NewHandler(tracker))
+	}
+
+	// Capture sanitized operations for debugging, with the responses of the other extensions
+	if recorder := container.GetCaptureRecorder(); recorder != nil {
+		use(capture.NewExtension(recorder, cfg.Capture.ErrorsOnly))
+	}
+
+	// Serve deprecated mutations only while clients migrate to payload mutations
+	use(compat.NewExtension(cfg.Features.LegacyMutations))
+
+	// Report or reject requests with undeclared variables or unknown extensions, which are
+	// otherwise ignored
+	if cfg.Strict.Requests != strict.ModeOff {
+		use(strict.NewExtension(cfg.Strict.Requests, resolverLogger))
+	}
+
+	// Advise clients of their rate limit state in response extensions
+	use(ratelimit.Extension{})
+
+	// Flag the queries served from snapshots while the database is unavailable
+	if cfg.Database.StaleReads.Enabled {
+		use(staleness.NewExtension())
+	}
+
+	// Let a webhook veto operations before they run, for example to block exports during an incident
+	if cfg.Veto.Enabled {
+		hook, err := veto.NewWebhook(cfg.Veto)
+		if err != nil {
+			return err
 		}
-		http.ServeFile(w, r, "interface/adapters/graphql/static/index.html")
-	})
+		use(veto.NewExtension(hook, cfg.Veto.FailOpen, resolverLogger))
+	}
+
+	// Limit the resolvers running at once, so that large queries cannot stampede the database
+	if cfg.Concurrency.Enabled {
+		use(resolverlimit.NewExtension(cfg.Concurrency))
+	}
 
-	// GraphQL endpoint
-	mux.Handle("/graphql", gqlServer)
+	// Route operations to the canary strategies of the domain rules, and compare the strategies
+	if cfg.Canary.Enabled {
+		selector, err := canary.NewSelector(cfg.Canary)
+		if err != nil {
+			return err
+		}
+		use(canary.NewExtension(selector))
+	}
+
+	// Let the reads of each operation see the writes made earlier in the operation
+	use(session.Extension{})
+
+	// Read the families of the fields of each query together, once per query
+	use(loader.NewExtension(container.GetFamilyApplicationService()))
+
+	// Refuse access to quarantined families to anyone but administrators
+	use(quarantine.Extension{})
+
+	// Redact the fields that the roles of the caller may not see, and list them in the response
+	if len(cfg.Redaction.Roles) > 0 {
+		redactions, err := redaction.NewExtension(schema.Schema(), cfg.Redaction.Roles)
+		if err != nil {
+			return err
+		}
+		use(redactions)
+	}
+
+	// Report reads whose result exceeds the memory budget, so that clients paginate them
+	use(resultsize.Extension{})
+
+	// Report operations rejected by a rate limiter or an open circuit breaker, with the delay
+	// after which clients may retry them
+	use(retryafter.Extension{})
+
+	// Refuse mutations while operators have the service in maintenance mode
+	use(maintenance.NewExtension(container.GetMaintenanceMode()))
+
+	// Routes of the endpoint and pages, below the configured prefix
+	routes := cfg.Server.Routes
+
+	// GraphQL endpoint, rate limited per subject, with ETags for queries sent with GET,
+	// websocket upgrades served by the websocket server, and names formatted for the
+	// Accept-Language of each request
+	graphqlHandler := locale.Middleware(websocket.Upgrades(wsServer, etag.Middleware(gqlServer)))
+	mux.Handle(routes.Path(routes.GraphQL), ratelimit.Middleware(container.GetHTTPRateLimiter())(graphqlHandler))
+
+	// Let developers sign in to the playground and documentation portal, outside production
+	pages := func(next http.Handler) http.Handler { return next }
+	loginActive := cfg.Auth.PlaygroundLogin.ActiveIn(os.Getenv("APP_ENV"))
+	if loginActive {
+		loginHandler, err := login.NewHandler(cfg.Auth.PlaygroundLogin, routes)
+		if err != nil {
+			return err
+		}
+		mux.Handle(loginHandler.Path(), loginHandler)
+		mux.Handle(loginHandler.Path()+"/", loginHandler)
+		pages = loginHandler.Inject
+	}
 
 	// GraphQL Playground
-	mux.Handle("/playground", playground.Handler("GraphQL Playground", "/query"))
+	mux.Handle(routes.Path(routes.Playground), pages(playground.Handler("GraphQL Playground", routes.Path(routes.GraphQL))))
 
 	// Custom GraphiQL interface
-	mux.HandleFunc("/graphiql", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc(routes.Path(routes.GraphiQL), func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, "interface/adapters/graphql/static/graphiql.html")
 	})
+
+	// Read-only documentation portal generated from the schema
+	docsHandler, err := docs.NewHandler("Family Service GraphQL API", routes.Path(routes.GraphQL), schema.Schema())
+	if err != nil {
+		return err
+	}
+	mux.Handle(routes.Path(routes.Docs), pages(docsHandler))
+
+	// State machine of the status of a family, generated from the domain rules
+	lifecycleHandler, err := lifecycle.NewHandler()
+	if err != nil {
+		return err
+	}
+	mux.Handle(routes.Path(routes.Lifecycle), lifecycleHandler)
+
+	// Landing page linking to the endpoint and pages, at the root of the routes
+	landingHandler, err := landing.NewHandler(routes, loginActive)
+	if err != nil {
+		return err
+	}
+	mux.Handle(routes.Root(), landingHandler)
+
+	return nil
+}
+
+// checkContracts validates the operations of the consumers registered in the contract
+// registry against the schema. Violations are logged, and fail the startup when the
+// contracts are enforced; so does a registry that cannot be read.
+func checkContracts(schema *ast.Schema, cfg config.ContractsConfig, logger *zap.Logger) error {
+	if cfg.Dir == "" {
+		return nil
+	}
+	registry, err := contract.LoadRegistry(cfg.Dir)
+	if err != nil {
+		if cfg.Enforce {
+			return err
+		}
+		logger.Warn("Consumer contracts not checked", zap.Error(err))
+		return nil
+	}
+
+	violations := contract.Check(schema, registry)
+	if len(violations) == 0 {
+		logger.Info("Schema serves the consumer contracts",
+			zap.Int("consumers", len(registry.Consumers)), zap.Int("operations", registry.Operations()))
+		return nil
+	}
+	for _, v := range violations {
+		logger.Error("Schema breaks a consumer contract",
+			zap.String("consumer", v.Consumer), zap.String("document", v.Document), zap.Int("line", v.Line), zap.String("error", v.Message))
+	}
+	if cfg.Enforce {
+		return fmt.Errorf("the schema breaks the contracts of %s", strings.Join(contract.Consumers(violations), ", "))
+	}
+	return nil
 }
 
 // startServer creates and starts the HTTP server with the configured handler.
@@ -338,24 +642,33 @@ func startServer(handler http.Handler, cfg *config.Config, logger *zap.Logger, c
 // This function creates a shutdown function that will be called when the
 // application receives a termination signal (e.g., SIGINT or SIGTERM).
 // The shutdown function:
-// 1. Cancels the root context to signal all operations to stop
-// 2. Creates a separate context with a timeout for server shutdown
-// 3. Calls the server's Shutdown method to gracefully close all connections
+// 1. Reports NOT_READY and waits the drain delay so load balancers stop routing requests
+// 2. Cancels the root context to signal all operations to stop
+// 3. Creates a separate context with a timeout for server shutdown
+// 4. Calls the server's Shutdown method to gracefully close all connections
+// 5. Drains registered background workers, each within its own deadline
 //
 // Graceful shutdown ensures that in-flight requests are allowed to complete
 // (up to the shutdown timeout) before the server exits, preventing abrupt
-// connection termination that could lead to errors for clients.
+// connection termination that could lead to errors for clients. Workers are
+// drained after the server stops so that no new work is handed to them.
 //
 // Parameters:
 //   - rootCtx: The root context for the application
 //   - rootCancel: The cancel function for the root context
 //   - srv: The HTTP server to shut down
+//   - gate: The readiness gate to drain before the server shuts down
+//   - coordinator: The coordinator for background workers
 //   - cfg: The application configuration with shutdown timeout settings
+//   - logger: The logger to use for reporting workers that failed to stop
 //
 // Returns:
 //   - A function that will perform the graceful shutdown when called
-func setupGracefulShutdown(rootCtx context.Context, rootCancel context.CancelFunc, srv *server.Server, cfg *config.Config) func() error {
+func setupGracefulShutdown(rootCtx context.Context, rootCancel context.CancelFunc, srv *server.Server, gate *readiness.Gate, coordinator *workers.Coordinator, cfg *config.Config, logger *zap.Logger) func() error {
 	return func() error {
+		// Stop receiving new traffic before the server stops accepting connections
+		gate.Drain(context.Background())
+
 		// Cancel the root context to signal all operations to stop
 		rootCancel()
 
@@ -364,7 +677,21 @@ func setupGracefulShutdown(rootCtx context.Context, rootCancel context.CancelFun
 		defer shutdownCancel()
 
 		// Shutdown the server
-		return srv.Shutdown(shutdownCtx)
+		srvErr := srv.Shutdown(shutdownCtx)
+
+		// Drain background workers within their per-component deadlines
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer drainCancel()
+
+		report := coordinator.Drain(drainCtx)
+		if err := report.Err(); err != nil {
+			logger.Error("Background workers did not drain cleanly", zap.Error(err))
+			if srvErr == nil {
+				return err
+			}
+		}
+
+		return srvErr
 	}
 }
 
@@ -379,8 +706,8 @@ func setupGracefulShutdown(rootCtx context.Context, rootCancel context.CancelFun
 // 6. Set up HTTP routes including GraphQL and health check endpoints
 // 7. Apply authentication middleware
 // 8. Start the HTTP server
-// 9. Set up graceful shutdown
-// 10. Wait for termination signal and perform graceful shutdown
+// 9. Set up graceful shutdown, including draining background workers
+// 10. Wait for a termination signal or quit request and perform graceful shutdown
 //
 // The function follows a "fail fast" approach, exiting immediately if any
 // critical initialization step fails. This ensures that the application
@@ -421,8 +748,11 @@ func main() {
 		}
 	}()
 
+	// Track readiness so that termination drains load balancers first
+	gate := readiness.NewGate(cfg.Server.DrainDelay, logger)
+
 	// Set up HTTP routes
-	handler, telemetryShutdown, err := setupRoutes(rootCtx, container, logger, cfg)
+	handler, telemetryShutdown, err := setupRoutes(rootCtx, container, gate, logger, cfg)
 	if err != nil {
 		logger.Error("Failed to set up HTTP routes", zap.Error(err))
 		os.Exit(1)
@@ -432,20 +762,29 @@ func main() {
 	defer telemetryShutdown()
 
 	// Apply auth middleware to all routes
-	// Note: The auth middleware will validate JWT tokens locally.
-	// In the future, this should be configured to use a remote authorization server
-	// for improved security and centralized management.
-	handler = container.GetAuthService().Middleware()(handler)
+	// Tokens are validated with the local secret keys, or with the signing keys of the
+	// remote authorization server when auth.mode is oidc.
+	// Websocket upgrades to the GraphQL endpoint are authenticated by their connection_init payload.
+	graphqlPath := cfg.Server.Routes.Path(cfg.Server.Routes.GraphQL)
+	handler = websocket.Authenticate(graphqlPath, container.GetAuthKeys().Middleware())(handler)
+
+	// Let integration tests pin the clock of a request, outside production
+	if cfg.Server.TestClock.ActiveIn(os.Getenv("APP_ENV")) {
+		handler = testclock.Middleware(cfg.Server.TestClock.Header)(handler)
+		logger.Warn("Test clock enabled; requests can pin their clock", zap.String("header", cfg.Server.TestClock.Header))
+	}
 
 	// Start the server
 	srv := startServer(handler, cfg, logger, container.GetContextLogger())
 
 	// Set up graceful shutdown
-	shutdownFunc := setupGracefulShutdown(rootCtx, rootCancel, srv, cfg)
+	shutdownFunc := setupGracefulShutdown(rootCtx, rootCancel, srv, gate, container.GetWorkerCoordinator(), cfg, logger)
 
-	// Wait for a shutdown signal
+	// Wait for a shutdown signal or a request to the quit endpoint
+	quitCtx, quitCancel := gate.WithQuit(rootCtx)
+	defer quitCancel()
 	logger.Info("HTTP server is running. Press Ctrl+C to stop")
-	if err := shutdown.GracefulShutdown(rootCtx, container.GetContextLogger(), shutdownFunc); err != nil {
+	if err := shutdown.GracefulShutdown(quitCtx, container.GetContextLogger(), shutdownFunc); err != nil {
 		logger.Error("Failed to gracefully shutdown the HTTP server", zap.Error(err))
 		os.Exit(1)
 	}
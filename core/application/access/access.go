@@ -0,0 +1,64 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package access carries the caller of an operation to the application services, for the
+// decisions that depend on who is calling rather than on what the caller may do, such as
+// access to quarantined families, which only administrators have, and access to the
+// families of other users, which only administrators have as well.
+//
+// The interface layer records the caller with WithCaller once the caller is authenticated.
+// Without a recorded caller, the caller is anonymous and not an administrator, so that
+// operations started outside of an authenticated request are never given more access to
+// quarantined families. Anonymous callers are not restricted to families they own, since
+// they own none: they are the jobs and tools of the service, and requests served without
+// authentication, which is only disabled in development.
+package access
+
+import "context"
+
+// callerKey is the context key for the caller of an operation
+type callerKey struct{}
+
+// Caller is the authenticated caller of an operation
+type Caller struct {
+	ID    string // ID of the caller (empty if unknown)
+	Admin bool   // Whether the caller is an administrator
+}
+
+// WithCaller records the caller of an operation
+//
+// Parameters:
+//   - ctx: The context of the operation
+//   - caller: The authenticated caller
+//
+// Returns:
+//   - A context that carries the caller
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom returns the caller of an operation, which is anonymous if none was recorded
+func CallerFrom(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
+}
+
+// IsAdmin reports whether the caller of an operation is an administrator
+func IsAdmin(ctx context.Context) bool {
+	return CallerFrom(ctx).Admin
+}
+
+// OwnerOf returns the ID of the caller of an operation, and whether the caller may only
+// access the families it owns, which holds for authenticated callers that are not
+// administrators
+func OwnerOf(ctx context.Context) (string, bool) {
+	caller := CallerFrom(ctx)
+	return caller.ID, caller.ID != "" && !caller.Admin
+}
+
+// MayAccess reports whether the caller of an operation may access a family with the given
+// owner: administrators and anonymous callers may access every family, other callers only
+// the families they own. A family without an owner is accessible to administrators only.
+func MayAccess(ctx context.Context, ownerID string) bool {
+	caller, restricted := OwnerOf(ctx)
+	return !restricted || caller == ownerID
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package access
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCaller(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, Caller{}, CallerFrom(ctx))
+	assert.False(t, IsAdmin(ctx))
+
+	ctx = WithCaller(ctx, Caller{ID: "admin-1", Admin: true})
+	assert.Equal(t, "admin-1", CallerFrom(ctx).ID)
+	assert.True(t, IsAdmin(ctx))
+}
+
+func TestOwnerOf(t *testing.T) {
+	ctx := context.Background()
+	_, restricted := OwnerOf(ctx)
+	assert.False(t, restricted)
+	assert.True(t, MayAccess(ctx, "user-1"))
+	assert.True(t, MayAccess(ctx, ""))
+
+	user := WithCaller(ctx, Caller{ID: "user-1"})
+	owner, restricted := OwnerOf(user)
+	assert.Equal(t, "user-1", owner)
+	assert.True(t, restricted)
+	assert.True(t, MayAccess(user, "user-1"))
+	assert.False(t, MayAccess(user, "user-2"))
+	assert.False(t, MayAccess(user, ""))
+
+	admin := WithCaller(ctx, Caller{ID: "admin-1", Admin: true})
+	_, restricted = OwnerOf(admin)
+	assert.False(t, restricted)
+	assert.True(t, MayAccess(admin, "user-2"))
+	assert.True(t, MayAccess(admin, ""))
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package consistency gives the reads of an operation a consistent view of the writes the
+// same operation made earlier, such as a query for a family after a mutation that changed it
+// in the same GraphQL request.
+//
+// An operation starts tracking with Track. Application services record the aggregates they
+// change with MarkWritten, and reads of an aggregate that was written in the operation skip
+// caches, and replicas where a repository has them, by checking Written. Without tracking,
+// MarkWritten does nothing and Written reports false, so reads outside of tracked operations
+// behave as before.
+package consistency
+
+import (
+	"context"
+	"sync"
+)
+
+// writesKey is the context key for the writes of an operation
+type writesKey struct{}
+
+// writes is the set of aggregates written by an operation.
+// Resolvers may run concurrently, so access is synchronized.
+type writes struct {
+	mu  sync.Mutex
+	ids map[string]struct{}
+}
+
+// Track starts tracking the writes of an operation.
+// If the context already tracks writes, it is returned unchanged, so that nested
+// operations share the writes of the enclosing one.
+//
+// Parameters:
+//   - ctx: The context of the operation
+//
+// Returns:
+//   - A context that tracks the writes of the operation
+func Track(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(writesKey{}).(*writes); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, writesKey{}, &writes{ids: make(map[string]struct{})})
+}
+
+// MarkWritten records that the aggregates with the given IDs were written by the operation
+func MarkWritten(ctx context.Context, ids ...string) {
+	w, ok := ctx.Value(writesKey{}).(*writes)
+	if !ok {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range ids {
+		if id != "" {
+			w.ids[id] = struct{}{}
+		}
+	}
+}
+
+// Written reports whether the aggregate with the given ID was written earlier in the operation
+func Written(ctx context.Context, id string) bool {
+	w, ok := ctx.Value(writesKey{}).(*writes)
+	if !ok {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, written := w.ids[id]
+	return written
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package consistency
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritten(t *testing.T) {
+	ctx := Track(context.Background())
+	assert.False(t, Written(ctx, "family-1"))
+
+	MarkWritten(ctx, "family-1", "family-2", "")
+	assert.True(t, Written(ctx, "family-1"))
+	assert.True(t, Written(ctx, "family-2"))
+	assert.False(t, Written(ctx, "family-3"))
+	assert.False(t, Written(ctx, ""))
+
+	// Nested tracking shares the writes of the enclosing operation
+	nested := Track(ctx)
+	MarkWritten(nested, "family-3")
+	assert.True(t, Written(ctx, "family-3"))
+
+	// Other operations do not see the writes
+	assert.False(t, Written(Track(context.Background()), "family-1"))
+}
+
+func TestWritten_Untracked(t *testing.T) {
+	ctx := context.Background()
+	MarkWritten(ctx, "family-1")
+	assert.False(t, Written(ctx, "family-1"))
+}
+
+func TestMarkWritten_Concurrent(t *testing.T) {
+	ctx := Track(context.Background())
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"family-1", "family-2", "family-3", "family-4"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			MarkWritten(ctx, id)
+			Written(ctx, id)
+		}()
+	}
+	wg.Wait()
+
+	for _, id := range []string{"family-1", "family-2", "family-3", "family-4"} {
+		assert.True(t, Written(ctx, id))
+	}
+}
@@ -6,7 +6,10 @@
 	"context"
 	"time"
 
+	"github.com/abitofhelp/family-service/core/domain/diff"
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/policy"
+	"github.com/abitofhelp/family-service/core/domain/query"
 	"github.com/abitofhelp/servicelib/di"
 )
 
@@ -25,6 +28,25 @@ type ApplicationService[T any, D any] interface {
 	GetAll(ctx context.Context) ([]D, error)
 }
 
+// MaxFamilyPageSize is the largest number of families that ListFamilies returns at once
+const MaxFamilyPageSize = 100
+
+// MaxSearchTerms is the largest number of words in a search of SearchFamilies
+const MaxSearchTerms = 10
+
+// FamilyPage is a page of families, in ascending ID order
+type FamilyPage struct {
+	Families    []*entity.FamilyDTO
+	HasNextPage bool // Whether families follow the last family of the page
+}
+
+// FamilyComparison is the comparison of two families
+type FamilyComparison struct {
+	Left    *entity.FamilyDTO
+	Right   *entity.FamilyDTO
+	Changes []diff.Change // Changes that turn the left family into the right one
+}
+
 // FamilyApplicationService defines the interface for family application services
 // This interface represents a port in the Hexagonal Architecture pattern
 // It's defined in the application layer but implemented in the application layer
@@ -42,14 +64,48 @@ type FamilyApplicationService interface {
 	// GetFamily retrieves a family by ID (alias for GetByID)
 	GetFamily(ctx context.Context, id string) (*entity.FamilyDTO, error)
 
+	// GetFamilies retrieves the families with the given IDs in ascending ID order, leaving
+	// out the IDs of missing families
+	GetFamilies(ctx context.Context, ids []string) ([]*entity.FamilyDTO, error)
+
 	// GetAllFamilies retrieves all families (alias for GetAll)
 	GetAllFamilies(ctx context.Context) ([]*entity.FamilyDTO, error)
 
+	// FindFamilies retrieves the families that match a filter, in the given order
+	FindFamilies(ctx context.Context, filter query.Filter, order query.Order) ([]*entity.FamilyDTO, error)
+
+	// SearchFamilies retrieves at most first families with members whose names contain
+	// every word of a search and whose IDs sort after the given ID, in ascending ID order
+	SearchFamilies(ctx context.Context, search, after string, first int) ([]*entity.FamilyDTO, error)
+
+	// CountFamilies counts the families in the database, without reading them
+	CountFamilies(ctx context.Context) (int, error)
+
+	// CountParents counts the distinct parents in the database
+	CountParents(ctx context.Context) (int, error)
+
+	// CountChildren counts the distinct children in the database
+	CountChildren(ctx context.Context) (int, error)
+
+	// ListFamilies returns a page of at most first families whose IDs sort after the given
+	// ID, in ascending ID order; an empty after starts with the first family
+	ListFamilies(ctx context.Context, after string, first int) (*FamilyPage, error)
+
+	// CompareFamilies compares two families field by field and member by member
+	CompareFamilies(ctx context.Context, leftID, rightID string) (*FamilyComparison, error)
+
 	// UpdateFamily updates an existing family
 	UpdateFamily(ctx context.Context, dto entity.FamilyDTO) (*entity.FamilyDTO, error)
 
-	// DeleteFamily deletes a family by ID
-	DeleteFamily(ctx context.Context, id string) error
+	// DeleteFamily deletes a family by ID, marking it as deleted or, if hard, removing it for good
+	DeleteFamily(ctx context.Context, id string, hard bool) error
+
+	// RestoreFamily restores a soft deleted family and returns it
+	RestoreFamily(ctx context.Context, id string) (*entity.FamilyDTO, error)
+
+	// PurgeDeletedFamilies removes for good the families soft deleted more than olderThan
+	// ago and returns their IDs
+	PurgeDeletedFamilies(ctx context.Context, olderThan time.Duration) ([]string, error)
 
 	// AddParent adds a parent to a family
 	AddParent(ctx context.Context, familyID string, parentDTO entity.ParentDTO) (*entity.FamilyDTO, error)
@@ -60,9 +116,39 @@ type FamilyApplicationService interface {
 	// RemoveChild removes a child from a family
 	RemoveChild(ctx context.Context, familyID string, childID string) (*entity.FamilyDTO, error)
 
+	// MoveChild moves a child from one family to another
+	MoveChild(ctx context.Context, childID string, fromFamilyID string, toFamilyID string) (*entity.FamilyDTO, error)
+
+	// Remarry marries the parent of a single, divorced, or widowed family to a new spouse
+	Remarry(ctx context.Context, familyID string, spouseDTO entity.ParentDTO) (*entity.FamilyDTO, error)
+
+	// MergeFamilies merges the family of a spouse, with the given children of it, into a family whose parent remarries
+	MergeFamilies(ctx context.Context, familyID string, spouseFamilyID string, childIDs []string) (*entity.FamilyDTO, error)
+
 	// MarkParentDeceased marks a parent as deceased
 	MarkParentDeceased(ctx context.Context, familyID string, parentID string, deathDate time.Time) (*entity.FamilyDTO, error)
 
+	// MarkChildDeceased marks a child as deceased
+	MarkChildDeceased(ctx context.Context, familyID string, childID string, deathDate time.Time) (*entity.FamilyDTO, error)
+
+	// ChangeMemberName records a name change of a parent or child of a family
+	ChangeMemberName(ctx context.Context, familyID string, memberID string, change entity.NameChange) (*entity.FamilyDTO, error)
+
+	// SetMemberPreferredName sets or, if preferredName is empty, clears the preferred name of a parent or child
+	SetMemberPreferredName(ctx context.Context, familyID string, memberID string, preferredName string) (*entity.FamilyDTO, error)
+
+	// UpdateParent corrects the recorded first name, last name, or birth date of a parent of a family
+	UpdateParent(ctx context.Context, familyID string, parentID string, details entity.MemberDetails) (*entity.FamilyDTO, error)
+
+	// UpdateChild corrects the recorded first name, last name, or birth date of a child of a family
+	UpdateChild(ctx context.Context, familyID string, childID string, details entity.MemberDetails) (*entity.FamilyDTO, error)
+
+	// GrantChildConsent records a consent granted by a parent of a family for one of its children
+	GrantChildConsent(ctx context.Context, familyID string, childID string, consent entity.Consent) (*entity.FamilyDTO, error)
+
+	// GetMemberNameAsOf returns the name of a parent or child that applied on the given date
+	GetMemberNameAsOf(ctx context.Context, familyID string, memberID string, date time.Time) (*entity.NameChange, error)
+
 	// Divorce handles the divorce process
 	Divorce(ctx context.Context, familyID string, custodialParentID string) (*entity.FamilyDTO, error)
 
@@ -71,4 +157,25 @@ type FamilyApplicationService interface {
 
 	// FindFamilyByChild finds the family that contains a specific child
 	FindFamilyByChild(ctx context.Context, childID string) (*entity.FamilyDTO, error)
+
+	// FindFamiliesByExternalID finds families in which an entity of the given owner kind
+	// (any kind if owner is empty) has the given external ID in the given external system
+	FindFamiliesByExternalID(ctx context.Context, system, externalID string, owner entity.ExternalIDOwner) ([]*entity.FamilyDTO, error)
+
+	// GetAgePolicyViolations reports the parent-child age plausibility violations in all families
+	GetAgePolicyViolations(ctx context.Context) ([]policy.Violation, error)
+
+	// ExportFamilies calls fn with every family whose ID sorts after the given ID, in
+	// ascending ID order, without holding them all in memory, and returns the number of
+	// families exported. An empty after exports every family.
+	ExportFamilies(ctx context.Context, after string, fn func(*entity.FamilyDTO) error) (int, error)
+
+	// QuarantineFamily quarantines a family, so that only administrators can read or change it
+	QuarantineFamily(ctx context.Context, familyID string, reason string) (*entity.Quarantine, error)
+
+	// UnquarantineFamily removes the quarantine of a family and returns the removed quarantine
+	UnquarantineFamily(ctx context.Context, familyID string) (*entity.Quarantine, error)
+
+	// GetFamilyQuarantines returns every family quarantine, in ascending family ID order
+	GetFamilyQuarantines(ctx context.Context) ([]entity.Quarantine, error)
 }
@@ -22,12 +22,24 @@
 	"context"
 	"fmt"
 	"github.com/abitofhelp/servicelib/di"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/abitofhelp/family-service/core/application/access"
+	"github.com/abitofhelp/family-service/core/application/ports"
+	"github.com/abitofhelp/family-service/core/application/consistency"
+	"github.com/abitofhelp/family-service/core/application/strategy"
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	"github.com/abitofhelp/family-service/core/domain/diff"
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/core/domain/policy"
+	"github.com/abitofhelp/family-service/core/domain/query"
 	domainports "github.com/abitofhelp/family-service/core/domain/ports"
 	domainservices "github.com/abitofhelp/family-service/core/domain/services"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/cachewrapper"
+	servicecontext "github.com/abitofhelp/servicelib/context"
 	"github.com/abitofhelp/servicelib/errors"
 	"github.com/abitofhelp/servicelib/logging"
 	"go.uber.org/zap"
@@ -67,6 +79,12 @@ type FamilyApplicationService struct {
 	familyRepo    domainports.FamilyRepository        // Repository for persisting and retrieving families
 	logger        *logging.ContextLogger              // Logger for recording operations and errors
 	cache         *cache.Cache                        // Optional cache for improving performance
+
+	// Alternate domain services by strategy, for canary releases of new domain rules
+	strategies map[string]*domainservices.FamilyDomainService
+
+	// Maximum duration of a mutation; zero does not bound mutations
+	mutationTimeout time.Duration
 }
 
 // Ensure FamilyApplicationService implements di.ApplicationService
@@ -147,13 +165,32 @@ func NewFamilyApplicationService(
 func (s *FamilyApplicationService) Create(ctx context.Context, dto *entity.FamilyDTO) (*entity.FamilyDTO, error) {
 	s.logger.Info(ctx, "Creating new family", zap.String("family_id", dto.ID), zap.String("status", dto.Status))
 
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// A new family must not take the ID of a stored family, which may be another owner's
+	if err := s.checkNewFamilyID(ctx, dto.ID); err != nil {
+		return nil, err
+	}
+
+	// The family is owned by the authenticated caller who creates it
+	owned := *dto
+	if caller := access.CallerFrom(ctx); caller.ID != "" {
+		owned.OwnerID = caller.ID
+	}
+
 	// Delegate to domain service
-	family, err := s.familyService.CreateFamily(ctx, *dto)
+	family, err := s.domain(ctx).CreateFamily(ctx, owned)
 	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
 		s.logger.Error(ctx, "Failed to create family", zap.Error(err), zap.String("family_id", dto.ID))
 		return nil, err
 	}
 
+	// Let later reads see the change
+	s.written(ctx, family.ID)
+
 	s.logger.Info(ctx, "Successfully created family", zap.String("family_id", family.ID), zap.Int("parent_count", family.ParentCount), zap.Int("children_count", family.ChildrenCount))
 	return family, nil
 }
@@ -182,14 +219,29 @@ func (s *FamilyApplicationService) Create(ctx context.Context, dto *entity.Famil
 func (s *FamilyApplicationService) GetByID(ctx context.Context, id string) (*entity.FamilyDTO, error) {
 	s.logger.Info(ctx, "Retrieving family by ID", zap.String("family_id", id))
 
+	// Only administrators may read a quarantined family, which is checked before the cache
+	if err := s.checkQuarantine(ctx, "GetByID", id); err != nil {
+		return nil, err
+	}
+
 	// Create cache key
-	cacheKey := fmt.Sprintf("family:%s", id)
+	cacheKey := familyCacheKey(ctx, id)
 
-	// Try to get from cache or call the domain service
-	result, err := cache.WithContextCache(ctx, s.cache, cacheKey, func(ctx context.Context) (interface{}, error) {
-		// Delegate to domain service
-		return s.familyService.GetFamily(ctx, id)
-	})
+	// Delegate to domain service
+	load := func(ctx context.Context) (interface{}, error) {
+		return s.domain(ctx).GetFamily(ctx, id)
+	}
+
+	// Try to get from cache or call the domain service. A family written earlier in the same
+	// operation is always read from the repository, so that the operation sees its own writes.
+	var result interface{}
+	var err error
+	if consistency.Written(ctx, id) {
+		s.logger.Debug(ctx, "Family was written earlier in the operation, bypassing cache", zap.String("family_id", id))
+		result, err = load(ctx)
+	} else {
+		result, err = cache.WithContextCache(ctx, s.cache, cacheKey, load)
+	}
 	if err != nil {
 		s.logger.Error(ctx, "Failed to retrieve family", zap.Error(err), zap.String("family_id", id))
 		return nil, err
@@ -202,6 +254,11 @@ func (s *FamilyApplicationService) GetByID(ctx context.Context, id string) (*ent
 		return nil, errors.NewApplicationError(errors.InternalErrorCode, "failed to cast cached result to FamilyDTO", nil)
 	}
 
+	// Only the owner and administrators may read the family, which is checked after the cache
+	if err := s.checkOwner(ctx, "GetByID", family); err != nil {
+		return nil, err
+	}
+
 	s.logger.Info(ctx, "Successfully retrieved family", zap.String("family_id", family.ID), zap.String("status", family.Status))
 	return family, nil
 }
@@ -217,6 +274,13 @@ func (s *FamilyApplicationService) GetAll(ctx context.Context) ([]*entity.Family
 		return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to get all families", err)
 	}
 
+	// Only administrators see quarantined families
+	families, err = s.domain(ctx).FilterQuarantinedFamilies(ctx, "GetAll", access.IsAdmin(ctx), families)
+	if err != nil {
+		return nil, err
+	}
+	families = ownedFamilies(ctx, families)
+
 	// Convert domain entities to DTOs
 	dtos := make([]*entity.FamilyDTO, 0, len(families))
 	for _, fam := range families {
@@ -236,9 +300,26 @@ func (s *FamilyApplicationService) AddParent(ctx context.Context, familyID strin
 		zap.String("parent_first_name", parentDTO.FirstName),
 		zap.String("parent_last_name", parentDTO.LastName))
 
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "AddParent", familyID); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	// Delegate to domain service
-	family, err := s.familyService.AddParent(ctx, familyID, parentDTO)
+	family, err := s.domain(ctx).AddParent(ctx, familyID, parentDTO)
 	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
 		s.logger.Error(ctx, "Failed to add parent to family", 
 			zap.Error(err), 
 			zap.String("family_id", familyID), 
@@ -246,6 +327,9 @@ func (s *FamilyApplicationService) AddParent(ctx context.Context, familyID strin
 		return nil, err
 	}
 
+	// Let later reads see the change
+	s.written(ctx, familyID)
+
 	s.logger.Info(ctx, "Successfully added parent to family", 
 		zap.String("family_id", family.ID), 
 		zap.Int("parent_count", family.ParentCount))
@@ -260,9 +344,26 @@ func (s *FamilyApplicationService) AddChild(ctx context.Context, familyID string
 		zap.String("child_first_name", childDTO.FirstName),
 		zap.String("child_last_name", childDTO.LastName))
 
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "AddChild", familyID); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	// Delegate to domain service
-	family, err := s.familyService.AddChild(ctx, familyID, childDTO)
+	family, err := s.domain(ctx).AddChild(ctx, familyID, childDTO)
 	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
 		s.logger.Error(ctx, "Failed to add child to family", 
 			zap.Error(err), 
 			zap.String("family_id", familyID), 
@@ -270,6 +371,9 @@ func (s *FamilyApplicationService) AddChild(ctx context.Context, familyID string
 		return nil, err
 	}
 
+	// Let later reads see the change
+	s.written(ctx, familyID)
+
 	s.logger.Info(ctx, "Successfully added child to family", 
 		zap.String("family_id", family.ID), 
 		zap.Int("children_count", family.ChildrenCount))
@@ -282,9 +386,26 @@ func (s *FamilyApplicationService) RemoveChild(ctx context.Context, familyID str
 		zap.String("family_id", familyID), 
 		zap.String("child_id", childID))
 
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "RemoveChild", familyID); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	// Delegate to domain service
-	family, err := s.familyService.RemoveChild(ctx, familyID, childID)
+	family, err := s.domain(ctx).RemoveChild(ctx, familyID, childID)
 	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
 		s.logger.Error(ctx, "Failed to remove child from family", 
 			zap.Error(err), 
 			zap.String("family_id", familyID), 
@@ -292,12 +413,145 @@ func (s *FamilyApplicationService) RemoveChild(ctx context.Context, familyID str
 		return nil, err
 	}
 
+	// Let later reads see the change
+	s.written(ctx, familyID)
+
 	s.logger.Info(ctx, "Successfully removed child from family", 
 		zap.String("family_id", family.ID), 
 		zap.Int("children_count", family.ChildrenCount))
 	return family, nil
 }
 
+// MoveChild moves a child from one family to another
+func (s *FamilyApplicationService) MoveChild(ctx context.Context, childID string, fromFamilyID string, toFamilyID string) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Moving child between families",
+		zap.String("child_id", childID),
+		zap.String("from_family_id", fromFamilyID),
+		zap.String("to_family_id", toFamilyID))
+
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "MoveChild", fromFamilyID, toFamilyID); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the families
+	unlock, err := s.lockFamilies(ctx, fromFamilyID, toFamilyID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Delegate to domain service
+	family, err := s.domain(ctx).MoveChild(ctx, childID, fromFamilyID, toFamilyID)
+	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to move child between families",
+			zap.Error(err),
+			zap.String("child_id", childID),
+			zap.String("from_family_id", fromFamilyID),
+			zap.String("to_family_id", toFamilyID))
+		return nil, err
+	}
+
+	// Both families changed
+	s.written(ctx, fromFamilyID, toFamilyID)
+
+	s.logger.Info(ctx, "Successfully moved child between families",
+		zap.String("child_id", childID),
+		zap.String("family_id", family.ID),
+		zap.Int("children_count", family.ChildrenCount))
+	return family, nil
+}
+
+// Remarry marries the parent of a single, divorced, or widowed family to a new spouse
+func (s *FamilyApplicationService) Remarry(ctx context.Context, familyID string, spouseDTO entity.ParentDTO) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Remarrying family",
+		zap.String("family_id", familyID),
+		zap.String("spouse_id", spouseDTO.ID))
+
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "Remarry", familyID); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Delegate to domain service
+	family, err := s.domain(ctx).Remarry(ctx, familyID, spouseDTO)
+	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to remarry family",
+			zap.Error(err),
+			zap.String("family_id", familyID))
+		return nil, err
+	}
+
+	// Let later reads see the change
+	s.written(ctx, familyID)
+
+	s.logger.Info(ctx, "Successfully remarried family",
+		zap.String("family_id", family.ID),
+		zap.String("status", family.Status))
+	return family, nil
+}
+
+// MergeFamilies merges the family of a spouse, with the given children of it, into a family whose parent remarries
+func (s *FamilyApplicationService) MergeFamilies(ctx context.Context, familyID string, spouseFamilyID string, childIDs []string) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Merging families",
+		zap.String("family_id", familyID),
+		zap.String("spouse_family_id", spouseFamilyID),
+		zap.Strings("child_ids", childIDs))
+
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "MergeFamilies", familyID, spouseFamilyID); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the families
+	unlock, err := s.lockFamilies(ctx, familyID, spouseFamilyID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Delegate to domain service
+	family, err := s.domain(ctx).MergeFamilies(ctx, familyID, spouseFamilyID, childIDs)
+	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to merge families",
+			zap.Error(err),
+			zap.String("family_id", familyID),
+			zap.String("spouse_family_id", spouseFamilyID))
+		return nil, err
+	}
+
+	// Both families changed
+	s.written(ctx, familyID, spouseFamilyID)
+
+	s.logger.Info(ctx, "Successfully merged families",
+		zap.String("family_id", family.ID),
+		zap.String("spouse_family_id", spouseFamilyID),
+		zap.Int("children_count", family.ChildrenCount))
+	return family, nil
+}
+
 // MarkParentDeceased marks a parent as deceased
 func (s *FamilyApplicationService) MarkParentDeceased(ctx context.Context, familyID string, parentID string, deathDate time.Time) (*entity.FamilyDTO, error) {
 	s.logger.Info(ctx, "Marking parent as deceased", 
@@ -305,9 +559,26 @@ func (s *FamilyApplicationService) MarkParentDeceased(ctx context.Context, famil
 		zap.String("parent_id", parentID),
 		zap.Time("death_date", deathDate))
 
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "MarkParentDeceased", familyID); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	// Delegate to domain service
-	family, err := s.familyService.MarkParentDeceased(ctx, familyID, parentID, deathDate)
+	family, err := s.domain(ctx).MarkParentDeceased(ctx, familyID, parentID, deathDate)
 	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
 		s.logger.Error(ctx, "Failed to mark parent as deceased", 
 			zap.Error(err), 
 			zap.String("family_id", familyID), 
@@ -315,194 +586,1219 @@ func (s *FamilyApplicationService) MarkParentDeceased(ctx context.Context, famil
 		return nil, err
 	}
 
+	// Let later reads see the change
+	s.written(ctx, familyID)
+
 	s.logger.Info(ctx, "Successfully marked parent as deceased", 
 		zap.String("family_id", family.ID), 
 		zap.String("status", family.Status))
 	return family, nil
 }
 
-// Divorce handles the divorce process
-func (s *FamilyApplicationService) Divorce(ctx context.Context, familyID string, custodialParentID string) (*entity.FamilyDTO, error) {
-	s.logger.Info(ctx, "Processing divorce", 
-		zap.String("family_id", familyID), 
-		zap.String("custodial_parent_id", custodialParentID))
+// MarkChildDeceased marks a child as deceased
+func (s *FamilyApplicationService) MarkChildDeceased(ctx context.Context, familyID string, childID string, deathDate time.Time) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Marking child as deceased",
+		zap.String("family_id", familyID),
+		zap.String("child_id", childID),
+		zap.Time("death_date", deathDate))
+
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "MarkChildDeceased", familyID); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	// Delegate to domain service
-	family, err := s.familyService.Divorce(ctx, familyID, custodialParentID)
+	family, err := s.domain(ctx).MarkChildDeceased(ctx, familyID, childID, deathDate)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to process divorce", 
-			zap.Error(err), 
-			zap.String("family_id", familyID), 
-			zap.String("custodial_parent_id", custodialParentID))
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to mark child as deceased",
+			zap.Error(err),
+			zap.String("family_id", familyID),
+			zap.String("child_id", childID))
 		return nil, err
 	}
 
-	s.logger.Info(ctx, "Successfully processed divorce", 
-		zap.String("family_id", family.ID), 
-		zap.String("status", family.Status))
+	// Let later reads see the change
+	s.written(ctx, familyID)
+
+	s.logger.Info(ctx, "Successfully marked child as deceased",
+		zap.String("family_id", family.ID),
+		zap.String("child_id", childID))
 	return family, nil
 }
 
-// FindFamiliesByParent finds families that contain a specific parent
-func (s *FamilyApplicationService) FindFamiliesByParent(ctx context.Context, parentID string) ([]*entity.FamilyDTO, error) {
-	s.logger.Info(ctx, "Finding families by parent ID", zap.String("parent_id", parentID))
+// ChangeMemberName records a name change of a parent or child of a family
+func (s *FamilyApplicationService) ChangeMemberName(ctx context.Context, familyID string, memberID string, change entity.NameChange) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Changing member name",
+		zap.String("family_id", familyID),
+		zap.String("member_id", memberID),
+		zap.Time("effective_date", change.EffectiveDate))
 
-	if parentID == "" {
-		s.logger.Warn(ctx, "Parent ID is required for FindFamiliesByParent")
-		return nil, errors.NewValidationError("parent ID is required", "parentID", nil)
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "ChangeMemberName", familyID); err != nil {
+		return nil, err
 	}
 
-	// Use repository to find families by parent ID
-	families, err := s.familyRepo.FindByParentID(ctx, parentID)
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to find families by parent ID", 
-			zap.Error(err), 
-			zap.String("parent_id", parentID))
-		return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to find families by parent ID", err)
+		return nil, err
 	}
+	defer unlock()
 
-	// Convert domain entities to DTOs
-	dtos := make([]*entity.FamilyDTO, 0, len(families))
-	for _, fam := range families {
-		dto := fam.ToDTO()
-		dtos = append(dtos, &dto)
+	// Delegate to domain service
+	family, err := s.domain(ctx).ChangeMemberName(ctx, familyID, memberID, change)
+	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to change member name",
+			zap.Error(err),
+			zap.String("family_id", familyID),
+			zap.String("member_id", memberID))
+		return nil, err
 	}
 
-	s.logger.Info(ctx, "Successfully found families by parent ID", 
-		zap.String("parent_id", parentID), 
-		zap.Int("family_count", len(dtos)))
-	return dtos, nil
+	// Let later reads see the change
+	s.written(ctx, familyID)
+
+	s.logger.Info(ctx, "Successfully changed member name",
+		zap.String("family_id", family.ID),
+		zap.String("member_id", memberID))
+	return family, nil
 }
 
-// FindFamilyByChild finds the family that contains a specific child
-func (s *FamilyApplicationService) FindFamilyByChild(ctx context.Context, childID string) (*entity.FamilyDTO, error) {
-	s.logger.Info(ctx, "Finding family by child ID", zap.String("child_id", childID))
+// SetMemberPreferredName sets or, if preferredName is empty, clears the preferred name of a parent or child
+func (s *FamilyApplicationService) SetMemberPreferredName(ctx context.Context, familyID string, memberID string, preferredName string) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Setting member preferred name",
+		zap.String("family_id", familyID),
+		zap.String("member_id", memberID))
 
-	if childID == "" {
-		s.logger.Warn(ctx, "Child ID is required for FindFamilyByChild")
-		return nil, errors.NewValidationError("child ID is required", "childID", nil)
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "SetMemberPreferredName", familyID); err != nil {
+		return nil, err
 	}
 
-	// Use repository to find family by child ID
-	fam, err := s.familyRepo.FindByChildID(ctx, childID)
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
 	if err != nil {
-		if _, ok := err.(*errors.NotFoundError); ok {
-			s.logger.Info(ctx, "No family found for child ID", zap.String("child_id", childID))
-			return nil, err // Pass through not found errors
-		}
-		s.logger.Error(ctx, "Failed to find family by child ID", 
-			zap.Error(err), 
-			zap.String("child_id", childID))
-		return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to find family by child ID", err)
+		return nil, err
 	}
+	defer unlock()
 
-	// Convert domain entity to DTO
-	dto := fam.ToDTO()
-	s.logger.Info(ctx, "Successfully found family by child ID", 
-		zap.String("child_id", childID), 
-		zap.String("family_id", dto.ID))
-	return &dto, nil
-}
-
-// CreateFamily creates a new family (alias for Create for backward compatibility)
-func (s *FamilyApplicationService) CreateFamily(ctx context.Context, dto entity.FamilyDTO) (*entity.FamilyDTO, error) {
-	s.logger.Info(ctx, "CreateFamily called (alias for Create)", zap.String("family_id", dto.ID))
-	return s.Create(ctx, &dto)
-}
+	// Delegate to domain service
+	family, err := s.domain(ctx).SetMemberPreferredName(ctx, familyID, memberID, preferredName)
+	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to set member preferred name",
+			zap.Error(err),
+			zap.String("family_id", familyID),
+			zap.String("member_id", memberID))
+		return nil, err
+	}
 
-// GetFamily retrieves a family by ID (alias for GetByID for backward compatibility)
-func (s *FamilyApplicationService) GetFamily(ctx context.Context, id string) (*entity.FamilyDTO, error) {
-	s.logger.Info(ctx, "GetFamily called (alias for GetByID)", zap.String("family_id", id))
-	return s.GetByID(ctx, id)
-}
+	// Let later reads see the change
+	s.written(ctx, familyID)
 
-// GetAllFamilies retrieves all families (alias for GetAll for backward compatibility)
-func (s *FamilyApplicationService) GetAllFamilies(ctx context.Context) ([]*entity.FamilyDTO, error) {
-	s.logger.Info(ctx, "GetAllFamilies called (alias for GetAll)")
-	return s.GetAll(ctx)
+	s.logger.Info(ctx, "Successfully set member preferred name",
+		zap.String("family_id", family.ID),
+		zap.String("member_id", memberID))
+	return family, nil
 }
 
-// UpdateFamily updates an existing family
-func (s *FamilyApplicationService) UpdateFamily(ctx context.Context, dto entity.FamilyDTO) (*entity.FamilyDTO, error) {
-	s.logger.Info(ctx, "Updating family", zap.String("family_id", dto.ID))
+// UpdateParent corrects the recorded first name, last name, or birth date of a parent of a family
+func (s *FamilyApplicationService) UpdateParent(ctx context.Context, familyID string, parentID string, details entity.MemberDetails) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Updating parent",
+		zap.String("family_id", familyID),
+		zap.String("parent_id", parentID))
 
-	// Check if the family exists
-	_, err := s.familyRepo.GetByID(ctx, dto.ID)
-	if err != nil {
-		s.logger.Error(ctx, "Failed to get family for update", zap.Error(err), zap.String("family_id", dto.ID))
-		return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to get family for update", err)
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "UpdateParent", familyID); err != nil {
+		return nil, err
 	}
 
-	// Convert DTO to domain entity
-	family, err := entity.FamilyFromDTO(dto)
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to convert DTO to domain entity", zap.Error(err), zap.String("family_id", dto.ID))
-		return nil, errors.NewApplicationError(errors.ValidationErrorCode, "failed to convert DTO to domain entity", err)
+		return nil, err
 	}
+	defer unlock()
 
-	// Save the updated family
-	err = s.familyRepo.Save(ctx, family)
+	// Delegate to domain service
+	family, err := s.domain(ctx).UpdateParent(ctx, familyID, parentID, details)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to save updated family", zap.Error(err), zap.String("family_id", dto.ID))
-		return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to save updated family", err)
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to update parent",
+			zap.Error(err),
+			zap.String("family_id", familyID),
+			zap.String("parent_id", parentID))
+		return nil, err
 	}
 
-	// Clear cache if using caching
-	if s.cache != nil {
-		cacheKey := fmt.Sprintf("family:%s", dto.ID)
-		s.cache.Delete(cacheKey)
-	}
+	// Let later reads see the change
+	s.written(ctx, familyID)
 
-	// Convert the updated entity back to DTO
-	resultDTO := family.ToDTO()
-	s.logger.Info(ctx, "Successfully updated family", zap.String("family_id", dto.ID))
-	return &resultDTO, nil
+	s.logger.Info(ctx, "Successfully updated parent",
+		zap.String("family_id", family.ID),
+		zap.String("parent_id", parentID))
+	return family, nil
 }
 
-// DeleteFamily deletes a family by ID
-func (s *FamilyApplicationService) DeleteFamily(ctx context.Context, id string) error {
-	s.logger.Info(ctx, "Deleting family", zap.String("family_id", id))
+// UpdateChild corrects the recorded first name, last name, or birth date of a child of a family
+func (s *FamilyApplicationService) UpdateChild(ctx context.Context, familyID string, childID string, details entity.MemberDetails) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Updating child",
+		zap.String("family_id", familyID),
+		zap.String("child_id", childID))
 
-	// Check if the family exists
-	family, err := s.familyRepo.GetByID(ctx, id)
-	if err != nil {
-		s.logger.Error(ctx, "Failed to get family for deletion", zap.Error(err), zap.String("family_id", id))
-		return errors.NewApplicationError(errors.DatabaseErrorCode, "failed to get family for deletion", err)
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "UpdateChild", familyID); err != nil {
+		return nil, err
 	}
 
-	// Create a new family with the same data but with a "DELETED" status
-	// We can't directly modify the status field because it's private
-	parents := family.Parents()
-	children := family.Children()
-
-	// Create a new family with the same ID but with a "DELETED" status
-	deletedFamily, err := entity.NewFamily(
-		family.ID(),
-		entity.Status("DELETED"), // Use a custom status for deleted families
-		parents,
-		children,
-	)
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
 
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to create deleted family", zap.Error(err), zap.String("family_id", id))
-		return errors.NewApplicationError(errors.InternalErrorCode, "failed to create deleted family", err)
+		return nil, err
 	}
+	defer unlock()
 
-	// Save the updated family
-	err = s.familyRepo.Save(ctx, deletedFamily)
+	// Delegate to domain service
+	family, err := s.domain(ctx).UpdateChild(ctx, familyID, childID, details)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to save deleted family", zap.Error(err), zap.String("family_id", id))
-		return errors.NewApplicationError(errors.DatabaseErrorCode, "failed to save deleted family", err)
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to update child",
+			zap.Error(err),
+			zap.String("family_id", familyID),
+			zap.String("child_id", childID))
+		return nil, err
 	}
 
-	// Clear cache if using caching
-	if s.cache != nil {
-		cacheKey := fmt.Sprintf("family:%s", id)
-		s.cache.Delete(cacheKey)
-	}
+	// Let later reads see the change
+	s.written(ctx, familyID)
 
-	s.logger.Info(ctx, "Successfully deleted family", zap.String("family_id", id))
-	return nil
+	s.logger.Info(ctx, "Successfully updated child",
+		zap.String("family_id", family.ID),
+		zap.String("child_id", childID))
+	return family, nil
+}
+
+// GrantChildConsent records a consent granted by a parent of a family for one of its children
+func (s *FamilyApplicationService) GrantChildConsent(ctx context.Context, familyID string, childID string, consent entity.Consent) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Granting child consent",
+		zap.String("family_id", familyID),
+		zap.String("child_id", childID),
+		zap.String("granted_by", consent.GrantedBy))
+
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "GrantChildConsent", familyID); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Delegate to domain service
+	family, err := s.domain(ctx).GrantChildConsent(ctx, familyID, childID, consent)
+	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to grant child consent",
+			zap.Error(err),
+			zap.String("family_id", familyID),
+			zap.String("child_id", childID))
+		return nil, err
+	}
+
+	// Let later reads see the change
+	s.written(ctx, familyID)
+
+	s.logger.Info(ctx, "Successfully granted child consent",
+		zap.String("family_id", family.ID),
+		zap.String("child_id", childID))
+	return family, nil
+}
+
+// GetMemberNameAsOf returns the name of a parent or child that applied on the given date
+func (s *FamilyApplicationService) GetMemberNameAsOf(ctx context.Context, familyID string, memberID string, date time.Time) (*entity.NameChange, error) {
+	s.logger.Info(ctx, "Getting member name as of date",
+		zap.String("family_id", familyID),
+		zap.String("member_id", memberID),
+		zap.Time("date", date))
+
+	// Only administrators may read a quarantined family
+	if err := s.checkAccess(ctx, "GetMemberNameAsOf", familyID); err != nil {
+		return nil, err
+	}
+
+	// Delegate to domain service
+	name, err := s.domain(ctx).GetMemberNameAsOf(ctx, familyID, memberID, date)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get member name as of date",
+			zap.Error(err),
+			zap.String("family_id", familyID),
+			zap.String("member_id", memberID))
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Successfully got member name as of date",
+		zap.String("family_id", familyID),
+		zap.String("member_id", memberID))
+	return name, nil
+}
+
+// Divorce handles the divorce process
+func (s *FamilyApplicationService) Divorce(ctx context.Context, familyID string, custodialParentID string) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Processing divorce", 
+		zap.String("family_id", familyID), 
+		zap.String("custodial_parent_id", custodialParentID))
+
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "Divorce", familyID); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Delegate to domain service
+	family, err := s.domain(ctx).Divorce(ctx, familyID, custodialParentID)
+	if err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to process divorce", 
+			zap.Error(err), 
+			zap.String("family_id", familyID), 
+			zap.String("custodial_parent_id", custodialParentID))
+		return nil, err
+	}
+
+	// Let later reads see the change
+	s.written(ctx, familyID, family.ID)
+
+	s.logger.Info(ctx, "Successfully processed divorce", 
+		zap.String("family_id", family.ID), 
+		zap.String("status", family.Status))
+	return family, nil
+}
+
+// FindFamiliesByParent finds families that contain a specific parent
+func (s *FamilyApplicationService) FindFamiliesByParent(ctx context.Context, parentID string) ([]*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Finding families by parent ID", zap.String("parent_id", parentID))
+
+	if parentID == "" {
+		s.logger.Warn(ctx, "Parent ID is required for FindFamiliesByParent")
+		return nil, errors.NewValidationError("parent ID is required", "parentID", nil)
+	}
+
+	// Use repository to find families by parent ID
+	families, err := s.familyRepo.FindByParentID(ctx, parentID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to find families by parent ID", 
+			zap.Error(err), 
+			zap.String("parent_id", parentID))
+		return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to find families by parent ID", err)
+	}
+
+	// Only administrators see quarantined families
+	families, err = s.domain(ctx).FilterQuarantinedFamilies(ctx, "FindFamiliesByParent", access.IsAdmin(ctx), families)
+	if err != nil {
+		return nil, err
+	}
+	families = ownedFamilies(ctx, families)
+
+	// Convert domain entities to DTOs
+	dtos := make([]*entity.FamilyDTO, 0, len(families))
+	for _, fam := range families {
+		dto := fam.ToDTO()
+		dtos = append(dtos, &dto)
+	}
+
+	s.logger.Info(ctx, "Successfully found families by parent ID", 
+		zap.String("parent_id", parentID), 
+		zap.Int("family_count", len(dtos)))
+	return dtos, nil
+}
+
+// FindFamilyByChild finds the family that contains a specific child
+func (s *FamilyApplicationService) FindFamilyByChild(ctx context.Context, childID string) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Finding family by child ID", zap.String("child_id", childID))
+
+	if childID == "" {
+		s.logger.Warn(ctx, "Child ID is required for FindFamilyByChild")
+		return nil, errors.NewValidationError("child ID is required", "childID", nil)
+	}
+
+	// Use repository to find family by child ID
+	fam, err := s.familyRepo.FindByChildID(ctx, childID)
+	if err != nil {
+		if errors.IsNotFoundError(err) {
+			s.logger.Info(ctx, "No family found for child ID", zap.String("child_id", childID))
+			return nil, err // Pass through not found errors
+		}
+		s.logger.Error(ctx, "Failed to find family by child ID", 
+			zap.Error(err), 
+			zap.String("child_id", childID))
+		return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to find family by child ID", err)
+	}
+
+	// Only administrators may read a quarantined family
+	if err := s.checkQuarantine(ctx, "FindFamilyByChild", fam.ID()); err != nil {
+		return nil, err
+	}
+
+	// Convert domain entity to DTO
+	dto := fam.ToDTO()
+	if err := s.checkOwner(ctx, "FindFamilyByChild", &dto); err != nil {
+		return nil, err
+	}
+	s.logger.Info(ctx, "Successfully found family by child ID", 
+		zap.String("child_id", childID), 
+		zap.String("family_id", dto.ID))
+	return &dto, nil
+}
+
+// FindFamiliesByExternalID finds families in which an entity of the given owner kind
+// (any kind if owner is empty) has the given external ID in the given external system
+func (s *FamilyApplicationService) FindFamiliesByExternalID(ctx context.Context, system, externalID string, owner entity.ExternalIDOwner) ([]*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Finding families by external ID",
+		zap.String("system", system),
+		zap.String("external_id", externalID),
+		zap.String("owner", string(owner)))
+
+	if system == "" {
+		s.logger.Warn(ctx, "System is required for FindFamiliesByExternalID")
+		return nil, errors.NewValidationError("system is required", "system", nil)
+	}
+	if externalID == "" {
+		s.logger.Warn(ctx, "External ID is required for FindFamiliesByExternalID")
+		return nil, errors.NewValidationError("external ID is required", "externalID", nil)
+	}
+
+	// Use repository to find families in which the family or any member has the external ID
+	families, err := s.familyRepo.FindByExternalID(ctx, system, externalID)
+	if err != nil {
+		if errors.IsValidationError(err) {
+			return nil, err // Pass through validation errors
+		}
+		s.logger.Error(ctx, "Failed to find families by external ID",
+			zap.Error(err),
+			zap.String("system", system),
+			zap.String("external_id", externalID))
+		return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to find families by external ID", err)
+	}
+
+	// Only administrators see quarantined families
+	families, err = s.domain(ctx).FilterQuarantinedFamilies(ctx, "FindFamiliesByExternalID", access.IsAdmin(ctx), families)
+	if err != nil {
+		return nil, err
+	}
+	families = ownedFamilies(ctx, families)
+
+	// Keep only families in which an entity of the requested kind holds the external ID,
+	// and convert them to DTOs
+	dtos := make([]*entity.FamilyDTO, 0, len(families))
+	for _, fam := range families {
+		if !fam.HasExternalID(owner, system, externalID) {
+			continue
+		}
+		dto := fam.ToDTO()
+		dtos = append(dtos, &dto)
+	}
+
+	s.logger.Info(ctx, "Successfully found families by external ID",
+		zap.String("system", system),
+		zap.Int("family_count", len(dtos)))
+	return dtos, nil
+}
+
+// GetAgePolicyViolations reports the parent-child age plausibility violations in all families
+func (s *FamilyApplicationService) GetAgePolicyViolations(ctx context.Context) ([]policy.Violation, error) {
+	s.logger.Info(ctx, "Getting age policy violations")
+
+	// Delegate to domain service
+	violations, err := s.domain(ctx).FindAgePolicyViolations(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get age policy violations", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Successfully got age policy violations", zap.Int("violation_count", len(violations)))
+	return violations, nil
+}
+
+// CountFamilies counts the families in the database, without reading them; quarantined
+// families are left out for callers that are not administrators. Callers who may only
+// access their own families count their own families, which are read.
+func (s *FamilyApplicationService) CountFamilies(ctx context.Context) (int, error) {
+	var count int
+	var err error
+	if owner, restricted := access.OwnerOf(ctx); restricted {
+		count, err = s.domain(ctx).CountOwnedFamilies(ctx, owner)
+	} else {
+		count, err = s.domain(ctx).CountFamilies(ctx, access.IsAdmin(ctx))
+	}
+	if err != nil {
+		s.logger.Error(ctx, "Failed to count families", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountParents counts the distinct parents in the database; parents who belong only to
+// quarantined families are left out for callers that are not administrators. Callers who
+// may only access their own families count the parents of their own families.
+func (s *FamilyApplicationService) CountParents(ctx context.Context) (int, error) {
+	var count int
+	var err error
+	if owner, restricted := access.OwnerOf(ctx); restricted {
+		count, err = s.domain(ctx).CountOwnedParents(ctx, owner)
+	} else {
+		count, err = s.domain(ctx).CountParents(ctx, access.IsAdmin(ctx))
+	}
+	if err != nil {
+		s.logger.Error(ctx, "Failed to count parents", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountChildren counts the distinct children in the database; children who belong only to
+// quarantined families are left out for callers that are not administrators. Callers who
+// may only access their own families count the children of their own families.
+func (s *FamilyApplicationService) CountChildren(ctx context.Context) (int, error) {
+	var count int
+	var err error
+	if owner, restricted := access.OwnerOf(ctx); restricted {
+		count, err = s.domain(ctx).CountOwnedChildren(ctx, owner)
+	} else {
+		count, err = s.domain(ctx).CountChildren(ctx, access.IsAdmin(ctx))
+	}
+	if err != nil {
+		s.logger.Error(ctx, "Failed to count children", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// ExportFamilies calls fn with every family whose ID sorts after the given ID, in ascending
+// ID order, and returns the number of families exported; an empty after exports every
+// family. The families are streamed from the repository rather than read at once;
+// quarantined families are left out for callers that are not administrators, and the
+// families of other owners for callers who may only access their own families.
+func (s *FamilyApplicationService) ExportFamilies(ctx context.Context, after string, fn func(*entity.FamilyDTO) error) (int, error) {
+	s.logger.Info(ctx, "Exporting families", zap.String("after", after))
+
+	count := 0
+	var fnErr error
+	_, err := s.domain(ctx).ExportFamilies(ctx, access.IsAdmin(ctx), after, func(fam *entity.Family) error {
+		if !access.MayAccess(ctx, fam.OwnerID()) {
+			return nil
+		}
+		dto := fam.ToDTO()
+		if fnErr = fn(&dto); fnErr != nil {
+			return fnErr
+		}
+		count++
+		return nil
+	})
+	if fnErr != nil {
+		// The caller stopped the export, for example at the end of a chunk
+		s.logger.Info(ctx, "Export stopped by its caller", zap.Error(fnErr), zap.Int("count", count))
+		return count, fnErr
+	}
+	if err != nil {
+		s.logger.Error(ctx, "Failed to export families", zap.Error(err), zap.Int("count", count))
+		return count, err
+	}
+
+	s.logger.Info(ctx, "Successfully exported families", zap.Int("count", count))
+	return count, nil
+}
+
+// CreateFamily creates a new family (alias for Create for backward compatibility)
+func (s *FamilyApplicationService) CreateFamily(ctx context.Context, dto entity.FamilyDTO) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "CreateFamily called (alias for Create)", zap.String("family_id", dto.ID))
+	return s.Create(ctx, &dto)
+}
+
+// GetFamily retrieves a family by ID (alias for GetByID for backward compatibility)
+func (s *FamilyApplicationService) GetFamily(ctx context.Context, id string) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "GetFamily called (alias for GetByID)", zap.String("family_id", id))
+	return s.GetByID(ctx, id)
+}
+
+// GetFamilies retrieves the families with the given IDs in ascending ID order, reading them
+// from the repository together. The IDs of missing families are left out, as are
+// quarantined families for callers that are not administrators.
+func (s *FamilyApplicationService) GetFamilies(ctx context.Context, ids []string) ([]*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Retrieving families by ID", zap.Int("count", len(ids)))
+
+	families, err := s.domain(ctx).GetFamilies(ctx, ids)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to retrieve families", zap.Error(err))
+		return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to get families", err)
+	}
+
+	// Only administrators see quarantined families
+	families, err = s.domain(ctx).FilterQuarantinedFamilies(ctx, "GetFamilies", access.IsAdmin(ctx), families)
+	if err != nil {
+		return nil, err
+	}
+	families = ownedFamilies(ctx, families)
+
+	dtos := make([]*entity.FamilyDTO, 0, len(families))
+	for _, fam := range families {
+		dto := fam.ToDTO()
+		dtos = append(dtos, &dto)
+	}
+
+	s.logger.Info(ctx, "Successfully retrieved families by ID", zap.Int("count", len(dtos)))
+	return dtos, nil
+}
+
+// GetAllFamilies retrieves all families (alias for GetAll for backward compatibility)
+func (s *FamilyApplicationService) GetAllFamilies(ctx context.Context) ([]*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "GetAllFamilies called (alias for GetAll)")
+	return s.GetAll(ctx)
+}
+
+// FindFamilies retrieves the families that match a filter, in the given order. The filter
+// is evaluated by the repository; quarantined families are left out for callers that are
+// not administrators.
+func (s *FamilyApplicationService) FindFamilies(ctx context.Context, filter query.Filter, order query.Order) ([]*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Finding families by filter", zap.String("sort", string(order.Field)), zap.Bool("descending", order.Descending))
+
+	// Invalid filters and orders are the caller's error, not the repository's
+	if err := query.Validate(filter); err != nil {
+		s.logger.Warn(ctx, "Invalid filter for FindFamilies", zap.Error(err))
+		return nil, err
+	}
+	if err := query.ValidateOrder(order); err != nil {
+		s.logger.Warn(ctx, "Invalid order for FindFamilies", zap.Error(err))
+		return nil, err
+	}
+
+	// Callers who may only access their own families find only their own families
+	if owner, restricted := access.OwnerOf(ctx); restricted {
+		ownerFilter := query.Eq(query.FieldOwnerID, owner)
+		if filter == nil {
+			filter = ownerFilter
+		} else {
+			filter = query.And{filter, ownerFilter}
+		}
+	}
+
+	families, err := s.domain(ctx).FindFamilies(ctx, filter, order)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to find families", zap.Error(err))
+		return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to find families", err)
+	}
+
+	// Only administrators see quarantined families
+	families, err = s.domain(ctx).FilterQuarantinedFamilies(ctx, "FindFamilies", access.IsAdmin(ctx), families)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]*entity.FamilyDTO, 0, len(families))
+	for _, fam := range families {
+		dto := fam.ToDTO()
+		dtos = append(dtos, &dto)
+	}
+
+	s.logger.Info(ctx, "Successfully found families by filter", zap.Int("count", len(dtos)))
+	return dtos, nil
+}
+
+// SearchFamilies returns at most first families, in ascending ID order, in which every
+// word of the search is part of the first, last, or preferred name of a parent or child,
+// ignoring case. The words may be matched by different members, so that "smith em" finds
+// the Smith family with a child named Emma. The search is made by the database, which
+// reads only the families of the page, whose IDs sort after the given ID; an empty after
+// starts with the first family. Quarantined families are left out for callers that are not
+// administrators, so a page is read again after the last family read until it is full or
+// no families are left.
+func (s *FamilyApplicationService) SearchFamilies(ctx context.Context, search, after string, first int) ([]*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Searching families", zap.String("search", search), zap.String("after", after), zap.Int("first", first))
+
+	terms := strings.Fields(search)
+	if len(terms) == 0 {
+		s.logger.Warn(ctx, "Search is empty for SearchFamilies")
+		return nil, errors.NewValidationError("search must contain a name", "query", nil)
+	}
+	if len(terms) > ports.MaxSearchTerms {
+		s.logger.Warn(ctx, "Search has too many words for SearchFamilies", zap.Int("words", len(terms)))
+		return nil, errors.NewValidationError(fmt.Sprintf("search must contain at most %d words", ports.MaxSearchTerms), "query", nil)
+	}
+	if first < 1 || first > ports.MaxFamilyPageSize {
+		s.logger.Warn(ctx, "Result size is out of range for SearchFamilies", zap.Int("first", first))
+		return nil, errors.NewValidationError(fmt.Sprintf("first must be between 1 and %d", ports.MaxFamilyPageSize), "first", nil)
+	}
+
+	filter := make(query.And, len(terms), len(terms)+1)
+	for i, term := range terms {
+		filter[i] = query.Contains(query.FieldMemberName, term)
+	}
+	// Callers who may only access their own families find only their own families
+	if owner, restricted := access.OwnerOf(ctx); restricted {
+		filter = append(filter, query.Eq(query.FieldOwnerID, owner))
+	}
+
+	dtos := make([]*entity.FamilyDTO, 0, first)
+	for len(dtos) < first {
+		limit := first - len(dtos)
+		families, err := s.domain(ctx).FindFamiliesPage(ctx, filter, after, limit)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to search families", zap.Error(err))
+			return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to search families", err)
+		}
+		exhausted := len(families) < limit
+		if len(families) > 0 {
+			after = families[len(families)-1].ID()
+		}
+
+		// Only administrators see quarantined families
+		visible, err := s.domain(ctx).FilterQuarantinedFamilies(ctx, "SearchFamilies", access.IsAdmin(ctx), families)
+		if err != nil {
+			return nil, err
+		}
+		for _, fam := range visible {
+			dto := fam.ToDTO()
+			dtos = append(dtos, &dto)
+		}
+
+		if exhausted {
+			break
+		}
+	}
+
+	s.logger.Info(ctx, "Successfully searched families", zap.Int("count", len(dtos)))
+	return dtos, nil
+}
+
+// ListFamilies returns a page of at most first families whose IDs sort after the given ID,
+// in ascending ID order. Pages are read from the repository one at a time; quarantined
+// families are left out of the pages of callers that are not administrators, and the
+// families of other owners out of the pages of callers who may only access their own
+// families, so a page is read again after the last family read until it is full or no
+// families are left.
+func (s *FamilyApplicationService) ListFamilies(ctx context.Context, after string, first int) (*ports.FamilyPage, error) {
+	s.logger.Info(ctx, "Listing families", zap.String("after", after), zap.Int("first", first))
+
+	if first < 1 || first > ports.MaxFamilyPageSize {
+		s.logger.Warn(ctx, "Page size is out of range for ListFamilies", zap.Int("first", first))
+		return nil, errors.NewValidationError(fmt.Sprintf("first must be between 1 and %d", ports.MaxFamilyPageSize), "first", nil)
+	}
+
+	// Callers who may only access their own families page through those alone
+	list := s.domain(ctx).ListFamilies
+	if owner, restricted := access.OwnerOf(ctx); restricted {
+		list = func(ctx context.Context, after string, limit int) ([]*entity.Family, error) {
+			return s.domain(ctx).ListOwnedFamilies(ctx, owner, after, limit)
+		}
+	}
+
+	page := &ports.FamilyPage{Families: make([]*entity.FamilyDTO, 0, first)}
+	for {
+		// Read one family more than the page needs, to know whether another page follows
+		families, err := list(ctx, after, first-len(page.Families)+1)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to list families", zap.Error(err))
+			return nil, errors.NewApplicationError(errors.DatabaseErrorCode, "failed to list families", err)
+		}
+		exhausted := len(families) <= first-len(page.Families)
+		if len(families) > 0 {
+			after = families[len(families)-1].ID()
+		}
+
+		// Only administrators see quarantined families
+		visible, err := s.domain(ctx).FilterQuarantinedFamilies(ctx, "ListFamilies", access.IsAdmin(ctx), families)
+		if err != nil {
+			return nil, err
+		}
+		for _, fam := range visible {
+			if len(page.Families) == first {
+				page.HasNextPage = true
+				break
+			}
+			dto := fam.ToDTO()
+			page.Families = append(page.Families, &dto)
+		}
+
+		if page.HasNextPage || exhausted {
+			break
+		}
+	}
+
+	s.logger.Info(ctx, "Successfully listed families",
+		zap.Int("count", len(page.Families)),
+		zap.Bool("has_next_page", page.HasNextPage))
+	return page, nil
+}
+
+// CompareFamilies compares two families field by field and member by member. The families
+// are read like GetByID reads them, so only administrators can compare quarantined families.
+func (s *FamilyApplicationService) CompareFamilies(ctx context.Context, leftID, rightID string) (*ports.FamilyComparison, error) {
+	s.logger.Info(ctx, "Comparing families", zap.String("left_family_id", leftID), zap.String("right_family_id", rightID))
+
+	left, err := s.GetByID(ctx, leftID)
+	if err != nil {
+		return nil, err
+	}
+	right, err := s.GetByID(ctx, rightID)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &ports.FamilyComparison{
+		Left:    left,
+		Right:   right,
+		Changes: diff.Families(*left, *right),
+	}
+
+	s.logger.Info(ctx, "Successfully compared families", zap.Int("change_count", len(comparison.Changes)))
+	return comparison, nil
+}
+
+// UpdateFamily updates an existing family
+func (s *FamilyApplicationService) UpdateFamily(ctx context.Context, dto entity.FamilyDTO) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Updating family", zap.String("family_id", dto.ID))
+
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "UpdateFamily", dto.ID); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Check if the family exists
+	existing, err := s.familyRepo.GetByID(ctx, dto.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get family for update", zap.Error(err), zap.String("family_id", dto.ID))
+		return nil, databaseError(ctx, "failed to get family for update", err)
+	}
+
+	// Preferred names, name histories, and consents are changed by their own operations, so keep them
+	carryOverMemberRecords(&dto, existing.ToDTO())
+
+	// The owner of a family is set when it is created and kept by updates
+	dto.OwnerID = existing.OwnerID()
+
+	// Convert DTO to domain entity
+	family, err := entity.FamilyFromDTO(dto)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to convert DTO to domain entity", zap.Error(err), zap.String("family_id", dto.ID))
+		return nil, errors.NewApplicationError(errors.ValidationErrorCode, "failed to convert DTO to domain entity", err)
+	}
+
+	// Replace the version that was read, so that the save fails if the family changes meanwhile
+	family.SetVersion(existing.Version())
+
+	// Check the age plausibility policy
+	if err := s.domain(ctx).EnforceAgePolicy(ctx, family, "update_family"); err != nil {
+		return nil, err
+	}
+
+	// Save the updated family
+	err = s.familyRepo.Save(ctx, family)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to save updated family", zap.Error(err), zap.String("family_id", dto.ID))
+		return nil, databaseError(ctx, "failed to save updated family", err)
+	}
+
+	// Let later reads see the change
+	s.written(ctx, dto.ID)
+
+	// Convert the updated entity back to DTO
+	resultDTO := family.ToDTO()
+	s.logger.Info(ctx, "Successfully updated family", zap.String("family_id", dto.ID))
+	return &resultDTO, nil
+}
+
+// carryOverMemberRecords copies the preferred names and name histories of the existing members
+// into the members of dto that have none, and the consents of the existing children, which
+// are only changed by granting consent
+func carryOverMemberRecords(dto *entity.FamilyDTO, existing entity.FamilyDTO) {
+	for i := range dto.Parents {
+		for _, p := range existing.Parents {
+			if p.ID != dto.Parents[i].ID {
+				continue
+			}
+			if dto.Parents[i].PreferredName == "" {
+				dto.Parents[i].PreferredName = p.PreferredName
+			}
+			if len(dto.Parents[i].NameHistory) == 0 {
+				dto.Parents[i].NameHistory = p.NameHistory
+			}
+		}
+	}
+	for i := range dto.Children {
+		for _, c := range existing.Children {
+			if c.ID != dto.Children[i].ID {
+				continue
+			}
+			if dto.Children[i].PreferredName == "" {
+				dto.Children[i].PreferredName = c.PreferredName
+			}
+			if len(dto.Children[i].NameHistory) == 0 {
+				dto.Children[i].NameHistory = c.NameHistory
+			}
+			if len(dto.Children[i].Consents) == 0 {
+				dto.Children[i].Consents = c.Consents
+			}
+		}
+	}
+}
+
+// DeleteFamily deletes a family by ID. A soft delete marks the family as deleted, which
+// leaves it out of every read; a hard delete removes it for good.
+func (s *FamilyApplicationService) DeleteFamily(ctx context.Context, id string, hard bool) error {
+	s.logger.Info(ctx, "Deleting family", zap.String("family_id", id), zap.Bool("hard", hard))
+
+	// Only administrators may change a quarantined family
+	if err := s.checkAccess(ctx, "DeleteFamily", id); err != nil {
+		return err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Delegate to domain service
+	if _, err := s.domain(ctx).DeleteFamily(ctx, id, hard); err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to delete family", zap.Error(err), zap.String("family_id", id), zap.Bool("hard", hard))
+		return err
+	}
+
+	// Let later reads see the change
+	s.written(ctx, id)
+
+	s.logger.Info(ctx, "Successfully deleted family", zap.String("family_id", id), zap.Bool("hard", hard))
+	return nil
+}
+
+// RestoreFamily restores a soft deleted family, so that it is read again, and returns it
+func (s *FamilyApplicationService) RestoreFamily(ctx context.Context, id string) (*entity.FamilyDTO, error) {
+	s.logger.Info(ctx, "Restoring family", zap.String("family_id", id))
+
+	// Only administrators may change a quarantined family
+	if err := s.checkQuarantine(ctx, "RestoreFamily", id); err != nil {
+		return nil, err
+	}
+
+	// Roll back the change if it does not complete within the mutation timeout
+	ctx, cancel := domainservices.WithMutationTimeout(ctx, s.mutationTimeout)
+	defer cancel()
+
+	// Serialize the change with other changes of the family
+	unlock, err := s.lockFamilies(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Delegate to domain service
+	if _, err := s.domain(ctx).RestoreFamily(ctx, id); err != nil {
+		err = domainservices.MutationTimedOut(ctx, err)
+		s.logger.Error(ctx, "Failed to restore family", zap.Error(err), zap.String("family_id", id))
+		return nil, err
+	}
+
+	// Let later reads see the change
+	s.written(ctx, id)
+
+	family, err := s.domain(ctx).GetFamily(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to retrieve restored family", zap.Error(err), zap.String("family_id", id))
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Successfully restored family", zap.String("family_id", id))
+	return family, nil
+}
+
+// PurgeDeletedFamilies removes for good the families of the tenant of the caller that were
+// soft deleted more than olderThan ago, and returns their IDs
+func (s *FamilyApplicationService) PurgeDeletedFamilies(ctx context.Context, olderThan time.Duration) ([]string, error) {
+	s.logger.Info(ctx, "Purging deleted families", zap.Duration("older_than", olderThan))
+
+	if olderThan < 0 {
+		return nil, errors.NewValidationError("olderThan must not be negative", "olderThan", nil)
+	}
+
+	// Delegate to domain service
+	ids, err := s.domain(ctx).PurgeDeletedFamilies(ctx, clock.Now(ctx).Add(-olderThan), false)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to purge deleted families", zap.Error(err))
+		return nil, err
+	}
+
+	// Let later reads see the change
+	s.written(ctx, ids...)
+
+	s.logger.Info(ctx, "Successfully purged deleted families", zap.Int("family_count", len(ids)))
+	return ids, nil
+}
+
+// QuarantineFamily quarantines a family, so that only administrators can read or change it.
+// The caller of the operation is recorded as the administrator who quarantined the family.
+func (s *FamilyApplicationService) QuarantineFamily(ctx context.Context, familyID string, reason string) (*entity.Quarantine, error) {
+	s.logger.Info(ctx, "Quarantining family", zap.String("family_id", familyID))
+
+	// Delegate to domain service
+	q, err := s.domain(ctx).QuarantineFamily(ctx, familyID, reason, access.CallerFrom(ctx).ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to quarantine family", zap.Error(err), zap.String("family_id", familyID))
+		return nil, err
+	}
+
+	// Cached copies must not be served past the new access check
+	s.written(ctx, familyID)
+
+	s.logger.Info(ctx, "Successfully quarantined family", zap.String("family_id", familyID))
+	return q, nil
+}
+
+// UnquarantineFamily removes the quarantine of a family and returns the removed quarantine
+func (s *FamilyApplicationService) UnquarantineFamily(ctx context.Context, familyID string) (*entity.Quarantine, error) {
+	s.logger.Info(ctx, "Removing family quarantine", zap.String("family_id", familyID))
+
+	// Delegate to domain service
+	q, err := s.domain(ctx).UnquarantineFamily(ctx, familyID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to remove family quarantine", zap.Error(err), zap.String("family_id", familyID))
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Successfully removed family quarantine", zap.String("family_id", familyID))
+	return q, nil
+}
+
+// GetFamilyQuarantines returns every family quarantine, in ascending family ID order
+func (s *FamilyApplicationService) GetFamilyQuarantines(ctx context.Context) ([]entity.Quarantine, error) {
+	s.logger.Info(ctx, "Getting family quarantines")
+
+	// Delegate to domain service
+	quarantines, err := s.domain(ctx).ListFamilyQuarantines(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get family quarantines", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Successfully got family quarantines", zap.Int("quarantine_count", len(quarantines)))
+	return quarantines, nil
+}
+
+// SetStrategies registers alternate domain services by the name of their strategy, for
+// canary releases of new domain rules. Operations whose strategy has a domain service use
+// it; others use the default domain service. It must be called before the service is used.
+func (s *FamilyApplicationService) SetStrategies(strategies map[string]*domainservices.FamilyDomainService) {
+	s.strategies = strategies
+}
+
+// SetMutationTimeout sets the maximum duration of a mutation. A mutation that does not
+// complete in time fails with a MutationTimedOutError, and the saves it completed are
+// rolled back; zero does not bound mutations.
+func (s *FamilyApplicationService) SetMutationTimeout(timeout time.Duration) {
+	s.mutationTimeout = timeout
+}
+
+// domain returns the domain service of the strategy of an operation
+func (s *FamilyApplicationService) domain(ctx context.Context) *domainservices.FamilyDomainService {
+	if service, ok := s.strategies[strategy.From(ctx)]; ok {
+		return service
+	}
+	return s.familyService
+}
+
+// checkAccess denies callers other than administrators access to quarantined families, and
+// callers who may only access their own families access to the families of other owners,
+// which are read to learn their owners.
+func (s *FamilyApplicationService) checkAccess(ctx context.Context, operation string, familyIDs ...string) error {
+	if err := s.checkQuarantine(ctx, operation, familyIDs...); err != nil {
+		return err
+	}
+	if owner, restricted := access.OwnerOf(ctx); restricted {
+		return s.domain(ctx).CheckFamilyOwner(ctx, operation, owner, familyIDs...)
+	}
+	return nil
+}
+
+// checkQuarantine denies callers other than administrators access to quarantined families.
+// Every attempt to access a quarantined family is logged and audited by the domain service.
+func (s *FamilyApplicationService) checkQuarantine(ctx context.Context, operation string, familyIDs ...string) error {
+	return s.domain(ctx).CheckFamilyAccess(ctx, operation, access.IsAdmin(ctx), familyIDs...)
+}
+
+// checkOwner denies callers who may only access their own families access to a family of
+// another owner that was read. The family is reported as not found, so that the caller
+// cannot tell it apart from a family that does not exist.
+func (s *FamilyApplicationService) checkOwner(ctx context.Context, operation string, family *entity.FamilyDTO) error {
+	if access.MayAccess(ctx, family.OwnerID) {
+		return nil
+	}
+	s.logger.Warn(ctx, "Refused access to a family of another owner",
+		zap.String("family_id", family.ID),
+		zap.String("operation", operation),
+		zap.String("caller", access.CallerFrom(ctx).ID))
+	return errors.NewNotFoundError("Family", family.ID, nil)
+}
+
+// checkNewFamilyID checks that no stored family has the ID chosen by the client for a new
+// family, so that creating a family can never replace another, or give the caller the
+// family of another owner. The repositories reject such saves as well; the check reports
+// the conflict before the family is validated and its duplicates are looked up.
+func (s *FamilyApplicationService) checkNewFamilyID(ctx context.Context, familyID string) error {
+	if familyID == "" {
+		return nil
+	}
+	_, err := s.familyRepo.GetByID(ctx, familyID)
+	switch {
+	case err == nil:
+		s.logger.Warn(ctx, "Refused to create a family with the ID of a stored family",
+			zap.String("family_id", familyID),
+			zap.String("caller", access.CallerFrom(ctx).ID))
+		return domainerrors.NewConcurrencyError(fmt.Sprintf("family %s already exists", familyID), nil)
+	case errors.IsNotFoundError(err):
+		return nil
+	default:
+		s.logger.Error(ctx, "Failed to check the ID of a new family", zap.Error(err), zap.String("family_id", familyID))
+		return databaseError(ctx, "failed to check the ID of the new family", err)
+	}
+}
+
+// ownedFamilies leaves the families of other owners out of a list of families, for callers
+// who may only access their own families
+func ownedFamilies(ctx context.Context, families []*entity.Family) []*entity.Family {
+	if _, restricted := access.OwnerOf(ctx); !restricted {
+		return families
+	}
+	owned := make([]*entity.Family, 0, len(families))
+	for _, fam := range families {
+		if access.MayAccess(ctx, fam.OwnerID()) {
+			owned = append(owned, fam)
+		}
+	}
+	return owned
+}
+
+// lockFamilies locks the families with the given IDs for a change, if the repository can
+// lock families, and returns a function that releases the locks. Families are locked in ID
+// order, so that changes of several families cannot deadlock each other.
+func (s *FamilyApplicationService) lockFamilies(ctx context.Context, familyIDs ...string) (func(), error) {
+	locker, ok := s.familyRepo.(domainports.FamilyLocker)
+	if !ok {
+		return func() {}, nil
+	}
+
+	ids := append([]string(nil), familyIDs...)
+	sort.Strings(ids)
+
+	var unlocks []func()
+	unlock := func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+	for i, id := range ids {
+		if id == "" || (i > 0 && id == ids[i-1]) {
+			continue
+		}
+		release, err := locker.LockFamily(ctx, id)
+		if err != nil {
+			unlock()
+			s.logger.Warn(ctx, "Failed to lock family for a change", zap.Error(err), zap.String("family_id", id))
+			return nil, err
+		}
+		unlocks = append(unlocks, release)
+	}
+	return unlock, nil
+}
+
+// written records that the families with the given IDs were changed by the operation and
+// removes them from the caches of all replicas, so that later reads, in this operation or
+// others, see the changes
+func (s *FamilyApplicationService) written(ctx context.Context, familyIDs ...string) {
+	consistency.MarkWritten(ctx, familyIDs...)
+	if s.cache != nil {
+		keys := make([]string, len(familyIDs))
+		for i, id := range familyIDs {
+			keys[i] = familyCacheKey(ctx, id)
+		}
+		// A completed change must reach the caches even if its mutation timeout has passed
+		s.cache.Invalidate(context.WithoutCancel(ctx), keys...)
+	}
+}
+
+// databaseError returns the error of a failed read or save of a mutation: a
+// MutationTimedOutError if the mutation timeout has passed, else a database error
+func databaseError(ctx context.Context, message string, err error) error {
+	if timedOutErr := domainservices.MutationTimedOut(ctx, err); timedOutErr != err {
+		return timedOutErr
+	}
+	return errors.NewApplicationError(errors.DatabaseErrorCode, message, err)
+}
+
+// familyCacheKey returns the cache key of a family of the tenant of the context, so that
+// the families of one tenant are never served to another
+func familyCacheKey(ctx context.Context, id string) string {
+	return fmt.Sprintf("family:%s:%s", servicecontext.GetTenantID(ctx), id)
 }
 
 // GetID returns the service ID (implements di.ApplicationService)
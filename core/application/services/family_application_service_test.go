@@ -0,0 +1,157 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package application
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/application/access"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	domainservices "github.com/abitofhelp/family-service/core/domain/services"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/loggingwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/sqlite"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// newTestService returns an application service over an in-memory SQLite repository
+func newTestService(t *testing.T) *FamilyApplicationService {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	logger := zaptest.NewLogger(t)
+	repo := sqlite.NewSQLiteFamilyRepository(db, logging.NewContextLogger(logger))
+	domain := domainservices.NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(logger))
+	return NewFamilyApplicationService(domain, repo, logging.NewContextLogger(logger), nil)
+}
+
+// TestCreate_TakenID creates families with the ID of a stored family, which must neither
+// replace the family nor give it to another owner
+func TestCreate_TakenID(t *testing.T) {
+	service := newTestService(t)
+
+	const familyID = "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	family := func(parentID, lastName string) *entity.FamilyDTO {
+		return &entity.FamilyDTO{
+			ID:     familyID,
+			Status: string(entity.Single),
+			Parents: []entity.ParentDTO{
+				{ID: parentID, FirstName: "Pat", LastName: lastName, BirthDate: time.Date(1980, time.January, 2, 0, 0, 0, 0, time.UTC)},
+			},
+		}
+	}
+	first := access.WithCaller(context.Background(), access.Caller{ID: "editor-a"})
+	second := access.WithCaller(context.Background(), access.Caller{ID: "editor-b"})
+
+	created, err := service.Create(first, family("a47ac10b-58cc-4372-a567-0e02b2c3d479", "Original"))
+	require.NoError(t, err)
+	assert.Equal(t, "editor-a", created.OwnerID)
+
+	// Another editor cannot take the family over by creating one with its ID
+	_, err = service.Create(second, family("b47ac10b-58cc-4372-a567-0e02b2c3d479", "Takeover"))
+	assert.True(t, stderrors.Is(err, domainerrors.ErrConcurrencyConflict), "expected a concurrency conflict, got %v", err)
+
+	// Nor can its owner replace it
+	_, err = service.Create(first, family("c47ac10b-58cc-4372-a567-0e02b2c3d479", "Replaced"))
+	assert.True(t, stderrors.Is(err, domainerrors.ErrConcurrencyConflict), "expected a concurrency conflict, got %v", err)
+
+	stored, err := service.GetByID(first, familyID)
+	require.NoError(t, err)
+	assert.Equal(t, "editor-a", stored.OwnerID)
+	require.Len(t, stored.Parents, 1)
+	assert.Equal(t, "Original", stored.Parents[0].LastName)
+}
+
+// TestListFamilies_Owned pages through the families of an owner, which are the only
+// families listed and counted for a caller who is not an administrator
+func TestListFamilies_Owned(t *testing.T) {
+	service := newTestService(t)
+	first := access.WithCaller(context.Background(), access.Caller{ID: "editor-a"})
+	second := access.WithCaller(context.Background(), access.Caller{ID: "editor-b"})
+
+	var owned []string
+	for i, ctx := range []context.Context{first, second, first, second, first} {
+		created, err := service.Create(ctx, &entity.FamilyDTO{
+			ID:     uuid.NewString(),
+			Status: string(entity.Single),
+			Parents: []entity.ParentDTO{
+				{ID: uuid.NewString(), FirstName: "Pat", LastName: "Owned", BirthDate: time.Date(1980+i, time.January, 2, 0, 0, 0, 0, time.UTC)},
+			},
+		})
+		require.NoError(t, err)
+		if ctx == first {
+			owned = append(owned, created.ID)
+		}
+	}
+	sort.Strings(owned)
+
+	page, err := service.ListFamilies(first, "", 2)
+	require.NoError(t, err)
+	require.Len(t, page.Families, 2)
+	assert.True(t, page.HasNextPage)
+	assert.Equal(t, owned[:2], []string{page.Families[0].ID, page.Families[1].ID})
+
+	page, err = service.ListFamilies(first, page.Families[1].ID, 2)
+	require.NoError(t, err)
+	require.Len(t, page.Families, 1)
+	assert.False(t, page.HasNextPage)
+	assert.Equal(t, owned[2], page.Families[0].ID)
+
+	count, err := service.CountFamilies(first)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	count, err = service.CountParents(second)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+// TestSearchFamilies_Pages searches families a page at a time, each page starting after the
+// last family of the previous one
+func TestSearchFamilies_Pages(t *testing.T) {
+	service := newTestService(t)
+	ctx := access.WithCaller(context.Background(), access.Caller{ID: "editor-a"})
+
+	var smiths []string
+	for i, lastName := range []string{"Smith", "Jones", "Smith", "Jones", "Smith"} {
+		created, err := service.Create(ctx, &entity.FamilyDTO{
+			ID:     uuid.NewString(),
+			Status: string(entity.Single),
+			Parents: []entity.ParentDTO{
+				{ID: uuid.NewString(), FirstName: "Pat", LastName: lastName, BirthDate: time.Date(1980+i, time.January, 2, 0, 0, 0, 0, time.UTC)},
+			},
+		})
+		require.NoError(t, err)
+		if lastName == "Smith" {
+			smiths = append(smiths, created.ID)
+		}
+	}
+	sort.Strings(smiths)
+
+	found, err := service.SearchFamilies(ctx, "pat smi", "", 2)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, smiths[:2], []string{found[0].ID, found[1].ID})
+
+	found, err = service.SearchFamilies(ctx, "pat smi", found[1].ID, 2)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, smiths[2], found[0].ID)
+
+	found, err = service.SearchFamilies(ctx, "pat smi", smiths[2], 2)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package strategy carries the strategy of the domain rules selected for an operation to the
+// application services, for canary releases that try new domain rules on part of the traffic.
+//
+// The interface layer selects the strategy of each operation, for example by a request header
+// or by the caller's tenant, and records it with WithStrategy. The application services then
+// use the domain service registered for that strategy. Without a recorded strategy, or with a
+// strategy that has no domain service, operations use the default domain rules.
+package strategy
+
+import "context"
+
+// Default is the strategy of operations that use the default domain rules
+const Default = "default"
+
+// strategyKey is the context key for the strategy of an operation
+type strategyKey struct{}
+
+// WithStrategy records the strategy of an operation
+//
+// Parameters:
+//   - ctx: The context of the operation
+//   - name: The name of the strategy
+//
+// Returns:
+//   - A context that carries the strategy
+func WithStrategy(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, strategyKey{}, name)
+}
+
+// From returns the strategy of an operation, which is Default if none was recorded
+func From(ctx context.Context) string {
+	if name, ok := ctx.Value(strategyKey{}).(string); ok && name != "" {
+		return name
+	}
+	return Default
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithStrategy(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, Default, From(ctx))
+
+	assert.Equal(t, "strict-age", From(WithStrategy(ctx, "strict-age")))
+
+	// An empty strategy is the default one
+	assert.Equal(t, Default, From(WithStrategy(ctx, "")))
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package chronology calculates ages and anniversaries of calendar dates.
+//
+// Birth and death dates are calendar dates, so ages are counted in calendar years rather
+// than derived from durations, which drift with leap years and daylight saving time. Each
+// time is read as the calendar date of its own location: a birth date stored as midnight
+// UTC is the date it was recorded as, and the current time is today's date where the
+// service runs, whatever the offset between the two.
+//
+// A person born on 29 February has their birthday on 1 March in common years, so they
+// turn 18 on 1 March of the year they are 18, and not a day before.
+package chronology
+
+import "time"
+
+// Date returns midnight UTC at the start of the calendar date of t in its own location,
+// the form in which calendar dates are stored and compared
+func Date(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Anniversary returns the anniversary of a date in the given year, as midnight UTC.
+// The anniversary of 29 February is 1 March in common years.
+func Anniversary(date time.Time, year int) time.Time {
+	_, month, day := date.Date()
+	// time.Date normalizes 29 February of a common year to 1 March
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Years returns the number of whole years from one date to another. The result is
+// negative if to is before from.
+func Years(from, to time.Time) int {
+	from, to = Date(from), Date(to)
+	if to.Before(from) {
+		return -Years(to, from)
+	}
+
+	years := to.Year() - from.Year()
+	if to.Before(Anniversary(from, to.Year())) {
+		years--
+	}
+	return years
+}
+
+// Age returns the age in whole years at the given time of a person born on birthDate.
+// The result is negative if the time is before the birth date.
+func Age(birthDate, at time.Time) int {
+	return Years(birthDate, at)
+}
+
+// AgeAtDeath returns the age in whole years at which a person born on birthDate died
+func AgeAtDeath(birthDate, deathDate time.Time) int {
+	return Years(birthDate, deathDate)
+}
+
+// IsDependent reports whether a person born on birthDate is younger than the age of
+// majority at the given time. A person who has died is no longer a dependent after their
+// death date; pass nil as deathDate for a living person.
+func IsDependent(birthDate time.Time, deathDate *time.Time, at time.Time, majority int) bool {
+	if deathDate != nil && Date(at).After(Date(*deathDate)) {
+		return false
+	}
+	return Age(birthDate, at) < majority
+}
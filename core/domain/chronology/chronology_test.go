@@ -0,0 +1,167 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package chronology
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAge(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	tests := []struct {
+		name      string
+		birthDate time.Time
+		at        time.Time
+		want      int
+	}{
+		{"on the day of birth", date(2000, time.June, 15), date(2000, time.June, 15), 0},
+		{"day before the first birthday", date(2000, time.June, 15), date(2001, time.June, 14), 0},
+		{"on the first birthday", date(2000, time.June, 15), date(2001, time.June, 15), 1},
+		{"day before the birthday", date(2000, time.June, 15), date(2018, time.June, 14), 17},
+		{"on the birthday", date(2000, time.June, 15), date(2018, time.June, 15), 18},
+		{"later in the birth month", date(2000, time.June, 15), date(2018, time.June, 30), 18},
+		{"earlier month", date(2000, time.June, 15), date(2018, time.May, 31), 17},
+		{"new year's eve", date(2000, time.January, 1), date(2017, time.December, 31), 17},
+		{"new year's day", date(2000, time.January, 1), date(2018, time.January, 1), 18},
+		{"before birth", date(2000, time.June, 15), date(1999, time.June, 14), -1},
+		{"day before birth", date(2000, time.June, 15), date(2000, time.June, 14), 0},
+
+		// Leap days
+		{"leap day, 28 February of a common year", date(2000, time.February, 29), date(2018, time.February, 28), 17},
+		{"leap day, 1 March of a common year", date(2000, time.February, 29), date(2018, time.March, 1), 18},
+		{"leap day, 28 February of a leap year", date(2000, time.February, 29), date(2016, time.February, 28), 15},
+		{"leap day, 29 February of a leap year", date(2000, time.February, 29), date(2016, time.February, 29), 16},
+		{"leap day, century common year", date(1896, time.February, 29), date(1900, time.March, 1), 4},
+		{"28 February in a leap year", date(2000, time.February, 28), date(2004, time.February, 28), 4},
+		{"1 March after a leap day", date(2000, time.March, 1), date(2004, time.February, 29), 3},
+		{"1 March after a leap day, on the birthday", date(2000, time.March, 1), date(2004, time.March, 1), 4},
+
+		// End of month
+		{"31 January, on 30 January", date(2000, time.January, 31), date(2010, time.January, 30), 9},
+		{"31 January, on 31 January", date(2000, time.January, 31), date(2010, time.January, 31), 10},
+		{"31 December, on 30 December", date(2000, time.December, 31), date(2010, time.December, 30), 9},
+		{"30 April, on 1 May", date(2000, time.April, 30), date(2010, time.May, 1), 10},
+
+		// Time zones: each time is read as the calendar date of its own location
+		{"late evening before the birthday west of UTC", date(2000, time.June, 15), time.Date(2018, time.June, 14, 23, 30, 0, 0, newYork), 17},
+		{"early morning of the birthday east of UTC", date(2000, time.June, 15), time.Date(2018, time.June, 15, 0, 30, 0, 0, tokyo), 18},
+		{"birth date recorded with an offset", time.Date(2000, time.June, 15, 0, 0, 0, 0, tokyo), date(2018, time.June, 15), 18},
+		{"time of birth late in the day", time.Date(2000, time.June, 15, 23, 59, 0, 0, time.UTC), date(2018, time.June, 15), 18},
+
+		// Daylight saving time
+		{"birthday on the day clocks go forward", date(2000, time.March, 12), time.Date(2017, time.March, 12, 3, 0, 0, 0, newYork), 17},
+		{"birthday on the day clocks go back", date(2000, time.November, 5), time.Date(2017, time.November, 5, 1, 30, 0, 0, newYork), 17},
+		{"day before a birthday on which clocks go back", date(2000, time.November, 5), time.Date(2017, time.November, 4, 23, 59, 0, 0, newYork), 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Age(tt.birthDate, tt.at))
+		})
+	}
+}
+
+// TestAge_EveryDay checks the age of every birth date of four years on every day of the
+// following eight years against the anniversaries of the birth date
+func TestAge_EveryDay(t *testing.T) {
+	for birth := date(2000, time.January, 1); birth.Year() < 2004; birth = birth.AddDate(0, 0, 1) {
+		age := 0
+		next := Anniversary(birth, birth.Year()+1)
+		for at := birth; at.Year() < 2012; at = at.AddDate(0, 0, 1) {
+			if !at.Before(next) {
+				age++
+				next = Anniversary(birth, birth.Year()+age+1)
+			}
+			if got := Age(birth, at); got != age {
+				t.Fatalf("Age(%s, %s) = %d, want %d", birth.Format(time.DateOnly), at.Format(time.DateOnly), got, age)
+			}
+			if got := Age(at, birth); got != -age {
+				t.Fatalf("Age(%s, %s) = %d, want %d", at.Format(time.DateOnly), birth.Format(time.DateOnly), got, -age)
+			}
+		}
+	}
+}
+
+func TestAnniversary(t *testing.T) {
+	tests := []struct {
+		name string
+		date time.Time
+		year int
+		want time.Time
+	}{
+		{"ordinary date", date(2000, time.June, 15), 2010, date(2010, time.June, 15)},
+		{"leap day in a leap year", date(2000, time.February, 29), 2004, date(2004, time.February, 29)},
+		{"leap day in a common year", date(2000, time.February, 29), 2001, date(2001, time.March, 1)},
+		{"leap day in a century common year", date(1896, time.February, 29), 1900, date(1900, time.March, 1)},
+		{"leap day in a century leap year", date(1996, time.February, 29), 2000, date(2000, time.February, 29)},
+		{"end of month", date(2000, time.January, 31), 2001, date(2001, time.January, 31)},
+		{"date with an offset", time.Date(2000, time.June, 15, 0, 0, 0, 0, time.FixedZone("", -5*60*60)), 2001, date(2001, time.June, 15)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Anniversary(tt.date, tt.year))
+		})
+	}
+}
+
+func TestDate(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	// The calendar date is read in the time's own location, not in UTC
+	assert.Equal(t, date(2018, time.June, 15), Date(time.Date(2018, time.June, 15, 1, 0, 0, 0, tokyo)))
+	assert.Equal(t, date(2018, time.June, 15), Date(time.Date(2018, time.June, 15, 23, 59, 59, 0, time.UTC)))
+}
+
+func TestAgeAtDeath(t *testing.T) {
+	tests := []struct {
+		name      string
+		birthDate time.Time
+		deathDate time.Time
+		want      int
+	}{
+		{"day before the birthday", date(1920, time.May, 10), date(2001, time.May, 9), 80},
+		{"on the birthday", date(1920, time.May, 10), date(2001, time.May, 10), 81},
+		{"leap day, 28 February of a common year", date(1920, time.February, 29), date(2001, time.February, 28), 80},
+		{"leap day, 1 March of a common year", date(1920, time.February, 29), date(2001, time.March, 1), 81},
+		{"on the day of birth", date(2001, time.May, 10), date(2001, time.May, 10), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, AgeAtDeath(tt.birthDate, tt.deathDate))
+		})
+	}
+}
+
+func TestIsDependent(t *testing.T) {
+	deathDate := date(2010, time.March, 1)
+
+	tests := []struct {
+		name      string
+		birthDate time.Time
+		deathDate *time.Time
+		at        time.Time
+		want      bool
+	}{
+		{"minor", date(2000, time.June, 15), nil, date(2018, time.June, 14), true},
+		{"turns of age on the birthday", date(2000, time.June, 15), nil, date(2018, time.June, 15), false},
+		{"leap day, 28 February of the year of majority", date(2000, time.February, 29), nil, date(2018, time.February, 28), true},
+		{"leap day, 1 March of the year of majority", date(2000, time.February, 29), nil, date(2018, time.March, 1), false},
+		{"on the day of death", date(2000, time.June, 15), &deathDate, date(2010, time.March, 1), true},
+		{"after death", date(2000, time.June, 15), &deathDate, date(2010, time.March, 2), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsDependent(tt.birthDate, tt.deathDate, tt.at, 18))
+		})
+	}
+}
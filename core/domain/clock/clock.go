@@ -0,0 +1,37 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package clock tells the time of an operation, which integration tests can pin.
+//
+// Operations take the current time from their context with Now. A context frozen with
+// Freeze reports the frozen time instead of the time of the system, so that timestamps
+// and age-derived values computed for a request are deterministic. Contexts are frozen
+// only by the test clock middleware, which is never served in production.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// frozenKey is the context key of the frozen time
+type frozenKey struct{}
+
+// Freeze returns a context whose time is frozen at the given time
+func Freeze(ctx context.Context, at time.Time) context.Context {
+	return context.WithValue(ctx, frozenKey{}, at)
+}
+
+// Frozen returns the time a context is frozen at, if it is frozen
+func Frozen(ctx context.Context) (time.Time, bool) {
+	at, ok := ctx.Value(frozenKey{}).(time.Time)
+	return at, ok
+}
+
+// Now returns the current time of a context: the frozen time if the context is frozen, and
+// the time of the system otherwise
+func Now(ctx context.Context) time.Time {
+	if at, ok := Frozen(ctx); ok {
+		return at
+	}
+	return time.Now()
+}
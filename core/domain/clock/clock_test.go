@@ -0,0 +1,25 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNow(t *testing.T) {
+	ctx := context.Background()
+	_, ok := Frozen(ctx)
+	assert.False(t, ok)
+	assert.WithinDuration(t, time.Now(), Now(ctx), time.Second)
+
+	at := time.Date(2030, time.March, 4, 5, 6, 7, 0, time.UTC)
+	frozen := Freeze(ctx, at)
+	assert.Equal(t, at, Now(frozen))
+	assert.Equal(t, at, Now(frozen), "the time of a frozen context does not advance")
+	_, ok = Frozen(ctx)
+	assert.False(t, ok, "the parent context is not frozen")
+}
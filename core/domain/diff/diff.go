@@ -0,0 +1,200 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package diff compares two versions of a family field by field and member by member.
+//
+// Families returns the changes that turn one family into another as structured values,
+// each with the path of the changed value relative to the family, the member it belongs
+// to, and its value before and after. Members, name histories, and consents are matched
+// by position, so reordering them is a change. The same engine reports the differences
+// found by the shadow repository and the comparisons requested by clients.
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+)
+
+// Kind is the kind of a change
+type Kind string
+
+const (
+	// KindChanged is a value that differs between the two versions
+	KindChanged Kind = "CHANGED"
+
+	// KindAdded is a value, such as a member, that only the second version has
+	KindAdded Kind = "ADDED"
+
+	// KindRemoved is a value, such as a member, that only the first version has
+	KindRemoved Kind = "REMOVED"
+)
+
+// Change is a difference between two versions of a family
+type Change struct {
+	Path   string // Path of the value in the family, such as "parents[0].firstName"
+	Member string // ID of the parent or child the value belongs to (empty for the family)
+	Field  string // Name of the changed field, such as "firstName" (empty for a whole member)
+	Kind   Kind   // Kind of the change
+	Before string // Value in the first version (empty if added)
+	After  string // Value in the second version (empty if removed)
+}
+
+// Families returns the changes between two versions of a family, in the order of the
+// fields of the family. The IDs of the families are not compared, so that two different
+// families can be compared as well as two versions of the same family.
+//
+// Parameters:
+//   - before: The first version of the family
+//   - after: The second version of the family
+//
+// Returns:
+//   - The changes, which are empty if the versions are equal
+func Families(before, after entity.FamilyDTO) []Change {
+	d := &differ{}
+	d.value("status", "", "status", before.Status, after.Status)
+	d.externalIDs("externalIds", "", before.ExternalIDs, after.ExternalIDs)
+
+	for i := 0; i < len(before.Parents) || i < len(after.Parents); i++ {
+		path := fmt.Sprintf("parents[%d]", i)
+		switch {
+		case i >= len(after.Parents):
+			p := before.Parents[i]
+			d.add(Change{Path: path, Member: p.ID, Kind: KindRemoved, Before: fullName(p.FirstName, p.LastName)})
+		case i >= len(before.Parents):
+			p := after.Parents[i]
+			d.add(Change{Path: path, Member: p.ID, Kind: KindAdded, After: fullName(p.FirstName, p.LastName)})
+		default:
+			b, a := before.Parents[i], after.Parents[i]
+			d.member(path, b.ID, a.ID, b.FirstName, a.FirstName, b.LastName, a.LastName, b.BirthDate, a.BirthDate, b.DeathDate, a.DeathDate)
+			d.externalIDs(path+".externalIds", b.ID, b.ExternalIDs, a.ExternalIDs)
+			d.value(path+".preferredName", b.ID, "preferredName", b.PreferredName, a.PreferredName)
+			d.nameHistory(path+".nameHistory", b.ID, b.NameHistory, a.NameHistory)
+		}
+	}
+
+	for i := 0; i < len(before.Children) || i < len(after.Children); i++ {
+		path := fmt.Sprintf("children[%d]", i)
+		switch {
+		case i >= len(after.Children):
+			c := before.Children[i]
+			d.add(Change{Path: path, Member: c.ID, Kind: KindRemoved, Before: fullName(c.FirstName, c.LastName)})
+		case i >= len(before.Children):
+			c := after.Children[i]
+			d.add(Change{Path: path, Member: c.ID, Kind: KindAdded, After: fullName(c.FirstName, c.LastName)})
+		default:
+			b, a := before.Children[i], after.Children[i]
+			d.member(path, b.ID, a.ID, b.FirstName, a.FirstName, b.LastName, a.LastName, b.BirthDate, a.BirthDate, b.DeathDate, a.DeathDate)
+			d.externalIDs(path+".externalIds", b.ID, b.ExternalIDs, a.ExternalIDs)
+			d.value(path+".preferredName", b.ID, "preferredName", b.PreferredName, a.PreferredName)
+			d.nameHistory(path+".nameHistory", b.ID, b.NameHistory, a.NameHistory)
+			d.consents(path+".consents", b.ID, b.Consents, a.Consents)
+		}
+	}
+	return d.changes
+}
+
+// differ collects the changes between two versions of a family
+type differ struct {
+	changes []Change
+}
+
+// add records a change
+func (d *differ) add(c Change) {
+	d.changes = append(d.changes, c)
+}
+
+// member records the changes between two versions of a parent or child
+func (d *differ) member(path, beforeID, afterID, beforeFirst, afterFirst, beforeLast, afterLast string, beforeBirth, afterBirth time.Time, beforeDeath, afterDeath *time.Time) {
+	d.value(path+".id", beforeID, "id", beforeID, afterID)
+	d.value(path+".firstName", beforeID, "firstName", beforeFirst, afterFirst)
+	d.value(path+".lastName", beforeID, "lastName", beforeLast, afterLast)
+	if !beforeBirth.Equal(afterBirth) {
+		d.add(Change{Path: path + ".birthDate", Member: beforeID, Field: "birthDate", Kind: KindChanged, Before: beforeBirth.Format(time.RFC3339), After: afterBirth.Format(time.RFC3339)})
+	}
+	d.value(path+".deathDate", beforeID, "deathDate", formatTime(beforeDeath), formatTime(afterDeath))
+}
+
+// value records a change of a string value. An empty value is missing, so a change from
+// or to it is an addition or removal.
+func (d *differ) value(path, member, field, before, after string) {
+	switch {
+	case before == after:
+	case before == "":
+		d.add(Change{Path: path, Member: member, Field: field, Kind: KindAdded, After: after})
+	case after == "":
+		d.add(Change{Path: path, Member: member, Field: field, Kind: KindRemoved, Before: before})
+	default:
+		d.add(Change{Path: path, Member: member, Field: field, Kind: KindChanged, Before: before, After: after})
+	}
+}
+
+// externalIDs records the changes between two sets of external IDs. Missing and empty
+// sets are equal.
+func (d *differ) externalIDs(path, member string, before, after map[string]string) {
+	systems := make([]string, 0, len(before)+len(after))
+	for system := range before {
+		systems = append(systems, system)
+	}
+	for system := range after {
+		if _, ok := before[system]; !ok {
+			systems = append(systems, system)
+		}
+	}
+	sort.Strings(systems)
+	for _, system := range systems {
+		d.value(path+"."+system, member, "externalIds", before[system], after[system])
+	}
+}
+
+// nameHistory records the changes between two name histories
+func (d *differ) nameHistory(path, member string, before, after []entity.NameChange) {
+	for i := 0; i < len(before) || i < len(after); i++ {
+		var b, a string
+		if i < len(before) {
+			b = formatNameChange(before[i])
+		}
+		if i < len(after) {
+			a = formatNameChange(after[i])
+		}
+		d.value(fmt.Sprintf("%s[%d]", path, i), member, "nameHistory", b, a)
+	}
+}
+
+// consents records the changes between two versions of a child's consents
+func (d *differ) consents(path, member string, before, after []entity.Consent) {
+	for i := 0; i < len(before) || i < len(after); i++ {
+		var b, a string
+		if i < len(before) {
+			b = formatConsent(before[i])
+		}
+		if i < len(after) {
+			a = formatConsent(after[i])
+		}
+		d.value(fmt.Sprintf("%s[%d]", path, i), member, "consents", b, a)
+	}
+}
+
+// fullName formats the name of a member that was added or removed
+func fullName(first, last string) string {
+	return first + " " + last
+}
+
+// formatConsent formats a consent for comparison
+func formatConsent(c entity.Consent) string {
+	return fmt.Sprintf("%v by %s at %s (expired %s)", c.Scopes, c.GrantedBy, c.GrantedAt.UTC().Format(time.RFC3339), formatTime(c.ExpiredAt))
+}
+
+// formatNameChange formats a name change for comparison
+func formatNameChange(n entity.NameChange) string {
+	return fmt.Sprintf("%s from %s (%s)", n.FullName(), n.EffectiveDate.UTC().Format(time.DateOnly), n.Reason)
+}
+
+// formatTime formats an optional time, such as a death date, for comparison
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package diff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFamilies(t *testing.T) {
+	birth := time.Date(1980, time.January, 2, 0, 0, 0, 0, time.UTC)
+	fam := entity.FamilyDTO{
+		ID:     "f1",
+		Status: "MARRIED",
+		Parents: []entity.ParentDTO{
+			{ID: "p1", FirstName: "Ada", LastName: "Lovelace", BirthDate: birth},
+			{ID: "p2", FirstName: "Bea", LastName: "Lovelace", BirthDate: birth},
+		},
+		Children: []entity.ChildDTO{
+			{ID: "c1", FirstName: "Cy", LastName: "Lovelace", BirthDate: birth.AddDate(20, 0, 0)},
+		},
+	}
+	assert.Empty(t, Families(fam, fam))
+
+	// The IDs of the families are not compared
+	other := fam
+	other.ID = "f2"
+	assert.Empty(t, Families(fam, other))
+
+	// Missing and empty external IDs are equal
+	withEmpty := fam
+	withEmpty.ExternalIDs = map[string]string{}
+	assert.Empty(t, Families(fam, withEmpty))
+
+	changed := fam
+	changed.Status = "DIVORCED"
+	changed.ExternalIDs = map[string]string{"crm": "42"}
+	changed.Parents = []entity.ParentDTO{fam.Parents[0]}
+	changed.Parents[0].FirstName = "Ann"
+	changed.Children = append([]entity.ChildDTO(nil), fam.Children...)
+	changed.Children[0].NameHistory = []entity.NameChange{{FirstName: "Cy", LastName: "Byron", EffectiveDate: birth}}
+	changed.Children = append(changed.Children, entity.ChildDTO{ID: "c2", FirstName: "Di", LastName: "Lovelace"})
+
+	assert.Equal(t, []Change{
+		{Path: "status", Field: "status", Kind: KindChanged, Before: "MARRIED", After: "DIVORCED"},
+		{Path: "externalIds.crm", Field: "externalIds", Kind: KindAdded, After: "42"},
+		{Path: "parents[0].firstName", Member: "p1", Field: "firstName", Kind: KindChanged, Before: "Ada", After: "Ann"},
+		{Path: "parents[1]", Member: "p2", Kind: KindRemoved, Before: "Bea Lovelace"},
+		{Path: "children[0].nameHistory[0]", Member: "c1", Field: "nameHistory", Kind: KindAdded, After: "Cy Byron from 1980-01-02 ()"},
+		{Path: "children[1]", Member: "c2", Kind: KindAdded, After: "Di Lovelace"},
+	}, Families(fam, changed))
+
+	// Members are matched by position
+	swapped := fam
+	swapped.Parents = []entity.ParentDTO{fam.Parents[1], fam.Parents[0]}
+	changes := Families(fam, swapped)
+	assert.Len(t, changes, 4)
+	assert.Equal(t, Change{Path: "parents[0].id", Member: "p1", Field: "id", Kind: KindChanged, Before: "p1", After: "p2"}, changes[0])
+}
@@ -7,6 +7,7 @@
 	"regexp"
 	"time"
 
+	"github.com/abitofhelp/family-service/core/domain/chronology"
 	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/identificationwrapper"
@@ -30,11 +31,15 @@
 // All fields are private to enforce that changes must go through methods that can
 // validate business rules, maintaining data integrity.
 type Child struct {
-	id        identificationwrapper.ID            // Unique identifier for the child
-	firstName identificationwrapper.Name          // First name of the child
-	lastName  identificationwrapper.Name          // Last name of the child
-	birthDate identificationwrapper.DateOfBirth   // Birth date of the child
-	deathDate *identificationwrapper.DateOfDeath  // Death date of the child (nil if alive)
+	id            identificationwrapper.ID           // Unique identifier for the child
+	firstName     identificationwrapper.Name         // First name of the child
+	lastName      identificationwrapper.Name         // Last name of the child
+	birthDate     identificationwrapper.DateOfBirth  // Birth date of the child
+	deathDate     *identificationwrapper.DateOfDeath // Death date of the child (nil if alive)
+	externalIDs   ExternalIDs                        // IDs of the child in external systems
+	preferredName string                             // Preferred name, such as a nickname (empty if none)
+	nameHistory   NameHistory                        // Names of the child over time (nil if the name never changed)
+	consents      Consents                           // Consents granted for the child's data (nil if none)
 }
 
 // NewChild creates a new Child entity with validation.
@@ -84,9 +89,9 @@ func NewChild(id, firstName, lastName string, birthDate time.Time, deathDate *ti
 		return nil, errorswrapper.NewValidationError("invalid LastName: "+err.Error(), "LastName", err)
 	}
 
-	// Create BirthDate value object
-	year, month, day := birthDate.Date()
-	birthDateVO, err := identificationwrapper.NewDateOfBirth(year, int(month), day)
+	// Create BirthDate value object from the calendar date, in the time zone it was given in
+	birth := DateOf(birthDate)
+	birthDateVO, err := identificationwrapper.NewDateOfBirth(birth.Year(), int(birth.Month()), birth.Day())
 	if err != nil {
 		return nil, errorswrapper.NewValidationError("invalid BirthDate: "+err.Error(), "BirthDate", err)
 	}
@@ -94,8 +99,8 @@ func NewChild(id, firstName, lastName string, birthDate time.Time, deathDate *ti
 	// Create DeathDate value object if provided
 	var deathDateVO *identificationwrapper.DateOfDeath
 	if deathDate != nil {
-		year, month, day := deathDate.Date()
-		dod, err := identificationwrapper.NewDateOfDeath(year, int(month), day)
+		death := DateOf(*deathDate)
+		dod, err := identificationwrapper.NewDateOfDeath(death.Year(), int(death.Month()), death.Day())
 		if err != nil {
 			return nil, errorswrapper.NewValidationError("invalid DeathDate: "+err.Error(), "DeathDate", err)
 		}
@@ -169,14 +174,7 @@ func (c *Child) Validate() error {
 
 	// Enhanced validation: Validate maximum age (e.g., 150 years)
 	maxAge := 150
-	now := time.Now()
-	birthDate := c.birthDate.Date()
-	age := now.Year() - birthDate.Year()
-
-	// Adjust age if birthday hasn't occurred yet this year
-	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
-		age--
-	}
+	age := chronology.Age(c.birthDate.Date(), time.Now())
 
 	if age > maxAge {
 		result.AddError(fmt.Sprintf("age cannot exceed %d years", maxAge), "BirthDate")
@@ -277,14 +275,14 @@ func (c *Child) MarkDeceased(deathDate time.Time) error {
 		return domainerrors.NewChildAlreadyDeceasedError("child is already marked as deceased", nil)
 	}
 
-	// Validate death date is after birth date
-	if !deathDate.After(c.birthDate.Date()) {
+	// Validate death date is after birth date, comparing calendar dates
+	death := DateOf(deathDate)
+	if !death.After(DateOf(c.birthDate.Date())) {
 		return errorswrapper.NewValidationError("death date must be after birth date", "DeathDate", nil)
 	}
 
 	// Create DateOfDeath value object
-	year, month, day := deathDate.Date()
-	dod, err := identificationwrapper.NewDateOfDeath(year, int(month), day)
+	dod, err := identificationwrapper.NewDateOfDeath(death.Year(), int(death.Month()), death.Day())
 	if err != nil {
 		return errorswrapper.NewValidationError("invalid death date: "+err.Error(), "DeathDate", err)
 	}
@@ -293,6 +291,164 @@ func (c *Child) MarkDeceased(deathDate time.Time) error {
 	return nil
 }
 
+// ExternalIDs returns the child's IDs in external systems, keyed by system name.
+//
+// It returns a copy so that callers cannot modify the child's internal state.
+// The result is nil if the child has no external IDs.
+func (c *Child) ExternalIDs() map[string]string {
+	return c.externalIDs.Copy()
+}
+
+// SetExternalIDs replaces the child's external IDs after validating them.
+//
+// Uniqueness of each external ID per system is enforced by the repository when
+// the family is saved, because it spans families.
+//
+// Parameters:
+//   - ids: Map of external system name to the child's ID in that system (nil clears them)
+//
+// Returns:
+//   - nil if the external IDs were set
+//   - ValidationError if a system name or ID is invalid
+func (c *Child) SetExternalIDs(ids map[string]string) error {
+	externalIDs, err := NewExternalIDs(ids)
+	if err != nil {
+		return err
+	}
+	c.externalIDs = externalIDs
+	return nil
+}
+
+// PreferredName returns the name the child prefers to be called, such as a
+// nickname, or an empty string if the child has none.
+func (c *Child) PreferredName() string {
+	return c.preferredName
+}
+
+// SetPreferredName sets the child's preferred name after validating it.
+//
+// Parameters:
+//   - name: The preferred name (empty clears it)
+//
+// Returns:
+//   - nil if the preferred name was set
+//   - ValidationError if the name is invalid
+func (c *Child) SetPreferredName(name string) error {
+	preferredName, err := NewPreferredName(name)
+	if err != nil {
+		return err
+	}
+	c.preferredName = preferredName
+	return nil
+}
+
+// NameHistory returns the child's names over time, oldest first.
+//
+// It returns a copy so that callers cannot modify the child's internal state.
+// The result is nil if the child's name never changed.
+func (c *Child) NameHistory() []NameChange {
+	return c.nameHistory.Copy()
+}
+
+// SetNameHistory replaces the child's name history after validating it.
+// It is used to restore the history of a stored child; ChangeName records new changes.
+//
+// Parameters:
+//   - changes: The names of the child over time, oldest first (nil clears the history)
+//
+// Returns:
+//   - nil if the name history was set
+//   - ValidationError if an entry is invalid or the latest name is not the current name
+func (c *Child) SetNameHistory(changes []NameChange) error {
+	history, err := NewNameHistory(changes, c.BirthDate(), c.DeathDate())
+	if err != nil {
+		return err
+	}
+	if err := checkCurrentName(history, c.FirstName(), c.LastName()); err != nil {
+		return err
+	}
+	c.nameHistory = history
+	return nil
+}
+
+// ChangeName records a change of the child's name, for example on marriage or by
+// legal process, and makes the new name the current name.
+//
+// The first change also records the previous name as the name from birth. Changes
+// must be recorded in chronological order and cannot take effect in the future.
+//
+// Parameters:
+//   - change: The new name and the date from which it applies
+//
+// Returns:
+//   - nil if the name change was recorded
+//   - ValidationError if the name, reason, or effective date is invalid
+func (c *Child) ChangeName(change NameChange) error {
+	history, err := c.nameHistory.withChange(change, c.FirstName(), c.LastName(), c.BirthDate(), c.DeathDate())
+	if err != nil {
+		return err
+	}
+
+	latest := history[len(history)-1]
+	firstName, err := identificationwrapper.NewName(latest.FirstName)
+	if err != nil {
+		return errorswrapper.NewValidationError("invalid FirstName: "+err.Error(), "FirstName", err)
+	}
+	lastName, err := identificationwrapper.NewName(latest.LastName)
+	if err != nil {
+		return errorswrapper.NewValidationError("invalid LastName: "+err.Error(), "LastName", err)
+	}
+
+	c.firstName = firstName
+	c.lastName = lastName
+	c.nameHistory = history
+	return nil
+}
+
+// CorrectDetails corrects the recorded first name, last name, or birth date of the child,
+// for example to fix a typo made when the child was added. See MemberDetails.
+//
+// Parameters:
+//   - details: The corrected details
+//
+// Returns:
+//   - nil if the child was corrected
+//   - ValidationError if no detail is corrected, a detail is invalid, or the corrected
+//     child violates a business rule; the child is then not changed
+func (c *Child) CorrectDetails(details MemberDetails) error {
+	deathDate := c.DeathDate()
+	corrected, err := details.apply(correctedDetails{
+		firstName:   c.firstName,
+		lastName:    c.lastName,
+		birthDate:   c.birthDate,
+		nameHistory: c.nameHistory,
+	}, deathDate)
+	if err != nil {
+		return err
+	}
+
+	candidate := *c
+	candidate.firstName = corrected.firstName
+	candidate.lastName = corrected.lastName
+	candidate.birthDate = corrected.birthDate
+	candidate.nameHistory = corrected.nameHistory
+	if err := candidate.Validate(); err != nil {
+		return err
+	}
+
+	*c = candidate
+	return nil
+}
+
+// NameAsOf returns the child's name that applied on the given date.
+//
+// Returns:
+//   - The name and the date from which it applied
+//   - ValidationError if the date is before the child's birth date
+func (c *Child) NameAsOf(date time.Time) (NameChange, error) {
+	return nameAsOf(c.nameHistory, c.FirstName(), c.LastName(), c.BirthDate(), date)
+}
+
 // Equals checks if two children are the same based on ID.
 //
 // In Domain-Driven Design, entities are distinguished by their identity, not their
@@ -337,11 +493,15 @@ func (c *Child) ToDTO() ChildDTO {
 	}
 
 	dto := ChildDTO{
-		ID:        c.id.String(),
-		FirstName: c.firstName.String(),
-		LastName:  c.lastName.String(),
-		BirthDate: c.birthDate.Date(),
-		DeathDate: deathDate,
+		ID:            c.id.String(),
+		FirstName:     c.firstName.String(),
+		LastName:      c.lastName.String(),
+		BirthDate:     c.birthDate.Date(),
+		DeathDate:     deathDate,
+		ExternalIDs:   c.externalIDs.Copy(),
+		PreferredName: c.preferredName,
+		NameHistory:   c.nameHistory.Copy(),
+		Consents:      c.consents.Copy(),
 	}
 	return dto
 }
@@ -356,11 +516,15 @@ func (c *Child) ToDTO() ChildDTO {
 // the domain model and external interfaces, preventing domain logic
 // from leaking into other layers.
 type ChildDTO struct {
-	ID        string     // Unique identifier for the child
-	FirstName string     // First name of the child
-	LastName  string     // Last name of the child
-	BirthDate time.Time  // Birth date of the child
-	DeathDate *time.Time // Death date of the child (nil if alive)
+	ID            string            // Unique identifier for the child
+	FirstName     string            // First name of the child
+	LastName      string            // Last name of the child
+	BirthDate     time.Time         // Birth date of the child
+	DeathDate     *time.Time        // Death date of the child (nil if alive)
+	ExternalIDs   map[string]string // IDs of the child in external systems (system -> ID)
+	PreferredName string            // Preferred name of the child, such as a nickname (empty if none)
+	NameHistory   []NameChange      // Names of the child over time, oldest first (nil if the name never changed)
+	Consents      []Consent         // Consents granted for the child's data, oldest first (nil if none)
 }
 
 // ChildFromDTO creates a Child entity from a data transfer object.
@@ -379,5 +543,26 @@ type ChildDTO struct {
 //   - A pointer to the new Child if valid
 //   - An error if validation fails
 func ChildFromDTO(dto ChildDTO) (*Child, error) {
-	return NewChild(dto.ID, dto.FirstName, dto.LastName, dto.BirthDate, dto.DeathDate)
+	c, err := NewChild(dto.ID, dto.FirstName, dto.LastName, dto.BirthDate, dto.DeathDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.SetExternalIDs(dto.ExternalIDs); err != nil {
+		return nil, err
+	}
+
+	if err := c.SetPreferredName(dto.PreferredName); err != nil {
+		return nil, err
+	}
+
+	if err := c.SetNameHistory(dto.NameHistory); err != nil {
+		return nil, err
+	}
+
+	if err := c.SetConsents(dto.Consents); err != nil {
+		return nil, err
+	}
+
+	return c, nil
 }
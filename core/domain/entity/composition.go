@@ -0,0 +1,56 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/chronology"
+)
+
+// Composition summarizes the members of a family for dashboard views
+type Composition struct {
+	NumberOfDependents  int  // Number of living children younger than the age of majority
+	HasDeceasedMembers  bool // Whether a parent or a child of the family has died
+	GenerationSpanYears int  // Whole years between the births of the oldest and the youngest member
+}
+
+// Composition computes the composition of a family at the given time in a single pass over
+// its members. Children are dependents until they reach the age of majority, as in
+// chronology.IsDependent.
+//
+// Parameters:
+//   - at: The time at which dependents are counted
+//   - majority: The age of majority, in years
+//
+// Returns:
+//   - The composition of the family
+func (dto FamilyDTO) Composition(at time.Time, majority int) Composition {
+	var c Composition
+	var oldest, youngest time.Time
+	visit := func(birthDate time.Time, deathDate *time.Time) {
+		if deathDate != nil {
+			c.HasDeceasedMembers = true
+		}
+		if oldest.IsZero() || birthDate.Before(oldest) {
+			oldest = birthDate
+		}
+		if youngest.IsZero() || birthDate.After(youngest) {
+			youngest = birthDate
+		}
+	}
+
+	for _, p := range dto.Parents {
+		visit(p.BirthDate, p.DeathDate)
+	}
+	for _, ch := range dto.Children {
+		visit(ch.BirthDate, ch.DeathDate)
+		if chronology.IsDependent(ch.BirthDate, ch.DeathDate, at, majority) {
+			c.NumberOfDependents++
+		}
+	}
+	if !oldest.IsZero() {
+		c.GenerationSpanYears = chronology.Years(oldest, youngest)
+	}
+	return c
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFamilyDTO_Composition(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	died := date(2020, time.May, 1)
+	at := date(2025, time.June, 1)
+
+	family := FamilyDTO{
+		Parents: []ParentDTO{
+			{BirthDate: date(1970, time.June, 2)},
+			{BirthDate: date(1972, time.January, 15), DeathDate: &died},
+		},
+		Children: []ChildDTO{
+			{BirthDate: date(2007, time.June, 1)}, // Turns 18 on the day of the count
+			{BirthDate: date(2010, time.March, 3)},
+			{BirthDate: date(2012, time.April, 4), DeathDate: &died},
+			{BirthDate: date(2015, time.June, 1)},
+		},
+	}
+	assert.Equal(t, Composition{
+		NumberOfDependents:  2,
+		HasDeceasedMembers:  true,
+		GenerationSpanYears: 44, // The youngest was born a day before the oldest turned 45
+	}, family.Composition(at, 18))
+
+	assert.Equal(t, Composition{}, FamilyDTO{}.Composition(at, 18))
+	assert.Equal(t, Composition{}, FamilyDTO{Parents: []ParentDTO{{BirthDate: date(1970, time.June, 2)}}}.Composition(at, 18))
+}
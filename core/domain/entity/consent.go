@@ -0,0 +1,294 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/identificationwrapper"
+)
+
+// ConsentScope identifies the data of a child that a parent has consented to the
+// service storing and returning.
+type ConsentScope string
+
+const (
+	// ConsentScopeExternalIDs covers the child's IDs in external systems, such as
+	// school or health records
+	ConsentScopeExternalIDs ConsentScope = "EXTERNAL_IDS"
+
+	// ConsentScopeNames covers the child's preferred name and name history
+	ConsentScopeNames ConsentScope = "NAMES"
+)
+
+// ConsentScopes lists every consent scope
+var ConsentScopes = []ConsentScope{
+	ConsentScopeExternalIDs,
+	ConsentScopeNames,
+}
+
+// IsValid reports whether the scope is one of the defined scopes
+func (s ConsentScope) IsValid() bool {
+	switch s {
+	case ConsentScopeExternalIDs, ConsentScopeNames:
+		return true
+	default:
+		return false
+	}
+}
+
+// Consent records that a parent consented to the data of a child in some scopes being
+// stored and returned.
+//
+// Consents are value objects: they have no identity of their own and are recorded in
+// the order in which they were granted. A consent is never deleted; when it becomes
+// stale it is flagged as expired, so that the record of who consented and when is kept.
+type Consent struct {
+	GrantedBy string         // ID of the parent who granted the consent
+	GrantedAt time.Time      // When the consent was granted
+	Scopes    []ConsentScope // Data of the child covered by the consent
+	ExpiredAt *time.Time     // When the consent was flagged as stale (nil while current)
+}
+
+// NewConsent validates a consent granted by a parent at the given time for the given scopes.
+//
+// Duplicate scopes are removed. The grant time is converted to UTC and truncated to the
+// second, the precision with which consents are stored.
+//
+// Returns:
+//   - The validated Consent
+//   - A ValidationError if the parent ID, grant time, or a scope is invalid
+func NewConsent(grantedBy string, grantedAt time.Time, scopes []ConsentScope) (Consent, error) {
+	idVO, err := identificationwrapper.NewIDFromString(grantedBy)
+	if err != nil {
+		return Consent{}, errorswrapper.NewValidationError("invalid GrantedBy: "+err.Error(), "Consent.GrantedBy", err)
+	}
+	if grantedAt.IsZero() {
+		return Consent{}, errorswrapper.NewValidationError("consent grant time is required", "Consent.GrantedAt", nil)
+	}
+	if grantedAt.After(time.Now()) {
+		return Consent{}, errorswrapper.NewValidationError("consent cannot be granted in the future", "Consent.GrantedAt", nil)
+	}
+	if len(scopes) == 0 {
+		return Consent{}, errorswrapper.NewValidationError("consent must cover at least one scope", "Consent.Scopes", nil)
+	}
+
+	unique := make([]ConsentScope, 0, len(scopes))
+	seen := make(map[ConsentScope]bool, len(scopes))
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return Consent{}, errorswrapper.NewValidationError(fmt.Sprintf("invalid consent scope %q", scope), "Consent.Scopes", nil)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+
+	return Consent{
+		GrantedBy: idVO.String(),
+		GrantedAt: grantedAt.UTC().Truncate(time.Second),
+		Scopes:    unique,
+	}, nil
+}
+
+// Covers reports whether the consent covers the scope
+func (c Consent) Covers(scope ConsentScope) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired reports whether the consent has been flagged as stale
+func (c Consent) IsExpired() bool {
+	return c.ExpiredAt != nil
+}
+
+// copyConsent returns a copy of a consent that shares no state with it
+func copyConsent(c Consent) Consent {
+	result := c
+	result.Scopes = append([]ConsentScope(nil), c.Scopes...)
+	if c.ExpiredAt != nil {
+		expiredAt := *c.ExpiredAt
+		result.ExpiredAt = &expiredAt
+	}
+	return result
+}
+
+// Consents is the list of the consents granted for a child, in the order in which
+// they were granted.
+type Consents []Consent
+
+// NewConsents validates the stored consents of a child born on birthDate and returns
+// them as Consents.
+//
+// Returns:
+//   - The validated Consents (nil if consents is empty)
+//   - A ValidationError if a consent is invalid, was granted before the birth date, or
+//     expired before it was granted
+func NewConsents(consents []Consent, birthDate time.Time) (Consents, error) {
+	if len(consents) == 0 {
+		return nil, nil
+	}
+
+	result := make(Consents, 0, len(consents))
+	for _, c := range consents {
+		consent, err := newChildConsent(c, birthDate)
+		if err != nil {
+			return nil, err
+		}
+		if c.ExpiredAt != nil {
+			if c.ExpiredAt.Before(consent.GrantedAt) {
+				return nil, errorswrapper.NewValidationError("consent cannot expire before it was granted", "Consent.ExpiredAt", nil)
+			}
+			expiredAt := c.ExpiredAt.UTC().Truncate(time.Second)
+			consent.ExpiredAt = &expiredAt
+		}
+		result = append(result, consent)
+	}
+	return result, nil
+}
+
+// newChildConsent validates a consent for a child born on birthDate
+func newChildConsent(c Consent, birthDate time.Time) (Consent, error) {
+	consent, err := NewConsent(c.GrantedBy, c.GrantedAt, c.Scopes)
+	if err != nil {
+		return Consent{}, err
+	}
+	if dateOnly(consent.GrantedAt).Before(dateOnly(birthDate)) {
+		return Consent{}, errorswrapper.NewValidationError("consent cannot be granted before the birth date", "Consent.GrantedAt", nil)
+	}
+	return consent, nil
+}
+
+// Copy returns a copy of the consents, or nil if there are none.
+// Entities return copies to prevent modification of their internal state.
+func (cs Consents) Copy() []Consent {
+	if len(cs) == 0 {
+		return nil
+	}
+	result := make([]Consent, 0, len(cs))
+	for _, c := range cs {
+		result = append(result, copyConsent(c))
+	}
+	return result
+}
+
+// Grants reports whether a consent that has not expired, and that was granted no
+// earlier than staleBefore, covers the scope
+func (cs Consents) Grants(scope ConsentScope, staleBefore time.Time) bool {
+	for _, c := range cs {
+		if !c.IsExpired() && !c.GrantedAt.Before(staleBefore) && c.Covers(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// Consents returns the consents granted for the child, in the order in which they
+// were granted.
+//
+// It returns a copy so that callers cannot modify the child's internal state.
+// The result is nil if no consent was ever granted.
+func (c *Child) Consents() []Consent {
+	return c.consents.Copy()
+}
+
+// SetConsents replaces the child's consents after validating them.
+// It is used to restore the consents of a stored child; GrantConsent records new consents.
+//
+// Parameters:
+//   - consents: The consents granted for the child, in the order in which they were granted (nil clears them)
+//
+// Returns:
+//   - nil if the consents were set
+//   - ValidationError if a consent is invalid
+func (c *Child) SetConsents(consents []Consent) error {
+	validated, err := NewConsents(consents, c.BirthDate())
+	if err != nil {
+		return err
+	}
+	c.consents = validated
+	return nil
+}
+
+// GrantConsent records a consent granted for the child.
+//
+// Earlier consents are kept, so that the record of consents is complete; the data of
+// the child is covered while any current consent covers it.
+//
+// Returns:
+//   - nil if the consent was recorded
+//   - ValidationError if the consent is invalid or was granted before the child's birth
+func (c *Child) GrantConsent(consent Consent) error {
+	validated, err := newChildConsent(consent, c.BirthDate())
+	if err != nil {
+		return err
+	}
+	c.consents = append(c.consents.Copy(), validated)
+	return nil
+}
+
+// ExpireConsents flags the child's consents granted before staleBefore as expired at
+// the given time, and returns how many were flagged. Consents already flagged are not
+// changed.
+func (c *Child) ExpireConsents(staleBefore, now time.Time) int {
+	expired := 0
+	for i := range c.consents {
+		if c.consents[i].IsExpired() || !c.consents[i].GrantedAt.Before(staleBefore) {
+			continue
+		}
+		expiredAt := now.UTC().Truncate(time.Second)
+		c.consents[i].ExpiredAt = &expiredAt
+		expired++
+	}
+	return expired
+}
+
+// GrantChildConsent records a consent granted by a parent of the family for one of
+// its children.
+//
+// Returns:
+//   - nil if the consent was recorded
+//   - NotFoundError if no child with the given ID exists in the family
+//   - ValidationError if the consent is invalid or was not granted by a parent of the family
+func (f *Family) GrantChildConsent(childID string, consent Consent) error {
+	var child *Child
+	for _, c := range f.children {
+		if c.ID() == childID {
+			child = c
+			break
+		}
+	}
+	if child == nil {
+		return errorswrapper.NewNotFoundError("Child", childID, nil)
+	}
+
+	isParent := false
+	for _, p := range f.parents {
+		if p.ID() == consent.GrantedBy {
+			isParent = true
+			break
+		}
+	}
+	if !isParent {
+		return errorswrapper.NewValidationError("consent must be granted by a parent of the family", "Consent.GrantedBy", nil)
+	}
+
+	return child.GrantConsent(consent)
+}
+
+// ExpireChildConsents flags the consents of the children of the family granted before
+// staleBefore as expired at the given time, and returns how many were flagged.
+func (f *Family) ExpireChildConsents(staleBefore, now time.Time) int {
+	expired := 0
+	for _, c := range f.children {
+		expired += c.ExpireConsents(staleBefore, now)
+	}
+	return expired
+}
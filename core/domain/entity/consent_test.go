@@ -0,0 +1,88 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConsent(t *testing.T) {
+	parentID := "38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f"
+	grantedAt := time.Date(2024, time.May, 1, 10, 30, 15, 500, time.FixedZone("EST", -5*60*60))
+
+	consent, err := NewConsent(parentID, grantedAt, []ConsentScope{ConsentScopeNames, ConsentScopeNames})
+	require.NoError(t, err)
+	assert.Equal(t, []ConsentScope{ConsentScopeNames}, consent.Scopes)
+	assert.Equal(t, time.Date(2024, time.May, 1, 15, 30, 15, 0, time.UTC), consent.GrantedAt)
+	assert.True(t, consent.Covers(ConsentScopeNames))
+	assert.False(t, consent.Covers(ConsentScopeExternalIDs))
+	assert.False(t, consent.IsExpired())
+
+	for name, tc := range map[string]struct {
+		grantedBy string
+		grantedAt time.Time
+		scopes    []ConsentScope
+	}{
+		"invalid parent": {"", grantedAt, ConsentScopes},
+		"no grant time":  {parentID, time.Time{}, ConsentScopes},
+		"future":         {parentID, time.Now().Add(time.Hour), ConsentScopes},
+		"no scopes":      {parentID, grantedAt, nil},
+		"invalid scope":  {parentID, grantedAt, []ConsentScope{"MEDICAL"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewConsent(tc.grantedBy, tc.grantedAt, tc.scopes)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFamily_GrantAndExpireChildConsents(t *testing.T) {
+	parent, err := NewParent(generateTestUUID(), "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	child, err := NewChild(generateTestUUID(), "Jimmy", "Doe", time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := NewFamily(generateTestUUID(), Single, []*Parent{parent}, []*Child{child})
+	require.NoError(t, err)
+	parentID, childID := fam.Parents()[0].ID(), fam.Children()[0].ID()
+
+	old, err := NewConsent(parentID, time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC), ConsentScopes)
+	require.NoError(t, err)
+	recent, err := NewConsent(parentID, time.Now().Add(-time.Hour), []ConsentScope{ConsentScopeNames})
+	require.NoError(t, err)
+	require.NoError(t, fam.GrantChildConsent(childID, old))
+	require.NoError(t, fam.GrantChildConsent(childID, recent))
+
+	// Consents must be granted by a parent of the family for one of its children
+	assert.Error(t, fam.GrantChildConsent(childID, Consent{GrantedBy: childID, GrantedAt: recent.GrantedAt, Scopes: ConsentScopes}))
+	assert.Error(t, fam.GrantChildConsent(parentID, recent))
+
+	now := time.Now()
+	staleBefore := now.AddDate(-1, 0, 0)
+	assert.Equal(t, 1, fam.ExpireChildConsents(staleBefore, now))
+	assert.Equal(t, 0, fam.ExpireChildConsents(staleBefore, now), "expired consents are not flagged again")
+
+	consents := fam.Children()[0].Consents()
+	require.Len(t, consents, 2)
+	assert.True(t, consents[0].IsExpired())
+	assert.False(t, consents[1].IsExpired())
+	assert.False(t, Consents(consents).Grants(ConsentScopeExternalIDs, staleBefore))
+	assert.True(t, Consents(consents).Grants(ConsentScopeNames, staleBefore))
+
+	// Consents survive a round trip through the DTO, and copies do not share state
+	dto := fam.Children()[0].ToDTO()
+	dto.Consents[0].Scopes[0] = "CHANGED"
+	assert.Equal(t, ConsentScopes, fam.Children()[0].Consents()[0].Scopes)
+	restored, err := ChildFromDTO(fam.Children()[0].ToDTO())
+	require.NoError(t, err)
+	assert.Equal(t, consents, restored.Consents())
+
+	// Stored consents that expired before they were granted are rejected
+	expiredAt := old.GrantedAt.Add(-time.Hour)
+	invalid := old
+	invalid.ExpiredAt = &expiredAt
+	assert.Error(t, restored.SetConsents([]Consent{invalid}))
+}
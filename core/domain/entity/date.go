@@ -0,0 +1,116 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+)
+
+// DateLayout is the layout of a calendar date, as in "1980-01-31"
+const DateLayout = "2006-01-02"
+
+// Date is a calendar date, such as a birth or death date, without a time of day or
+// time zone. A person born on 31 January was born on 31 January wherever the date is
+// read, so dates are never converted between time zones.
+//
+// Dates are value objects. The zero Date is not a valid date and reports IsZero.
+type Date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// NewDate creates a date from its year, month, and day.
+// Returns a ValidationError if the day does not exist, such as 30 February.
+func NewDate(year int, month time.Month, day int) (Date, error) {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if t.Year() != year || t.Month() != month || t.Day() != day {
+		return Date{}, errorswrapper.NewValidationError(
+			fmt.Sprintf("invalid date: %04d-%02d-%02d does not exist", year, month, day), "Date", nil)
+	}
+	return Date{year: year, month: month, day: day}, nil
+}
+
+// DateOf returns the calendar date of a time in its own location, so that midnight of
+// 1 January in any time zone is 1 January
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{year: year, month: month, day: day}
+}
+
+// ParseDate parses a calendar date written as "2006-01-02". For compatibility with
+// clients and data that write dates as timestamps, an RFC3339 timestamp is also
+// accepted, and its date is taken as written, in its own offset, rather than in UTC:
+// "1980-01-01T00:00:00-05:00" is 1 January 1980.
+//
+// Returns a ValidationError if the value is neither.
+func ParseDate(value string) (Date, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(DateLayout, value); err == nil {
+		return DateOf(t), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return DateOf(t), nil
+	}
+	return Date{}, errorswrapper.NewValidationError(
+		fmt.Sprintf("invalid date %q: expected YYYY-MM-DD", value), "Date", nil)
+}
+
+// Year returns the year of the date
+func (d Date) Year() int {
+	return d.year
+}
+
+// Month returns the month of the date
+func (d Date) Month() time.Month {
+	return d.month
+}
+
+// Day returns the day of the month of the date
+func (d Date) Day() int {
+	return d.day
+}
+
+// IsZero reports whether the date is the zero Date
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Time returns midnight UTC at the start of the date, the form in which dates are stored
+func (d Date) Time() time.Time {
+	return time.Date(d.year, d.month, d.day, 0, 0, 0, 0, time.UTC)
+}
+
+// String returns the date as "2006-01-02"
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.year, d.month, d.day)
+}
+
+// Before reports whether the date is before another date
+func (d Date) Before(other Date) bool {
+	return d.Time().Before(other.Time())
+}
+
+// After reports whether the date is after another date
+func (d Date) After(other Date) bool {
+	return d.Time().After(other.Time())
+}
+
+// MarshalText encodes the date as "2006-01-02"
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a date as ParseDate does
+func (d *Date) UnmarshalText(text []byte) error {
+	parsed, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
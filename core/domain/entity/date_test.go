@@ -0,0 +1,70 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDate(t *testing.T) {
+	d, err := NewDate(2024, time.February, 29)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-29", d.String())
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), d.Time())
+	assert.False(t, d.IsZero())
+
+	_, err = NewDate(2023, time.February, 29)
+	assert.True(t, errorswrapper.IsValidationError(err))
+	assert.True(t, Date{}.IsZero())
+}
+
+func TestParseDate(t *testing.T) {
+	for value, expected := range map[string]string{
+		"1980-01-01":                "1980-01-01",
+		" 1980-01-01 ":              "1980-01-01",
+		"1980-01-01T00:00:00Z":      "1980-01-01",
+		"1980-01-01T00:00:00-05:00": "1980-01-01", // 05:00 UTC
+		"1980-01-01T00:00:00+02:00": "1980-01-01", // 31 December 22:00 UTC
+		"1980-01-01T23:30:00-08:00": "1980-01-01", // 2 January 07:30 UTC
+	} {
+		d, err := ParseDate(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, d.String(), value)
+	}
+
+	for _, value := range []string{"", "01/01/1980", "1980-02-30", "1980-01-01T25:00:00Z"} {
+		_, err := ParseDate(value)
+		assert.True(t, errorswrapper.IsValidationError(err), value)
+	}
+}
+
+func TestDateOf(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	assert.Equal(t, "1980-01-01", DateOf(time.Date(1980, 1, 1, 0, 30, 0, 0, tokyo)).String())
+	assert.Equal(t, "1979-12-31", DateOf(time.Date(1980, 1, 1, 0, 30, 0, 0, tokyo).UTC()).String())
+}
+
+func TestDate_Compare(t *testing.T) {
+	earlier, _ := NewDate(1980, time.January, 1)
+	later, _ := NewDate(1980, time.January, 2)
+	assert.True(t, earlier.Before(later))
+	assert.True(t, later.After(earlier))
+	assert.False(t, earlier.After(earlier))
+}
+
+func TestDate_JSON(t *testing.T) {
+	d, _ := NewDate(1980, time.January, 31)
+	data, err := json.Marshal(struct{ BirthDate Date }{d})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"BirthDate":"1980-01-31"}`, string(data))
+
+	var decoded struct{ BirthDate Date }
+	require.NoError(t, json.Unmarshal([]byte(`{"BirthDate":"1980-01-31T00:00:00-05:00"}`), &decoded))
+	assert.Equal(t, d, decoded.BirthDate)
+}
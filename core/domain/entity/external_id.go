@@ -0,0 +1,200 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+)
+
+// ExternalIDs maps an external system name (for example "crm") to the identifier
+// that system uses for a family or family member.
+//
+// External IDs let clients correlate our entities with records in their own systems.
+// They are not used for identity within the domain; the entity ID remains authoritative.
+// Each external ID is unique per system and entity kind, which is enforced by the repository.
+type ExternalIDs map[string]string
+
+// ExternalIDOwner identifies the kind of entity that owns an external ID.
+type ExternalIDOwner string
+
+const (
+	// ExternalIDOwnerFamily is an external ID assigned to a family
+	ExternalIDOwnerFamily ExternalIDOwner = "FAMILY"
+
+	// ExternalIDOwnerParent is an external ID assigned to a parent
+	ExternalIDOwnerParent ExternalIDOwner = "PARENT"
+
+	// ExternalIDOwnerChild is an external ID assigned to a child
+	ExternalIDOwnerChild ExternalIDOwner = "CHILD"
+)
+
+// ExternalIDRules configures how external IDs are validated.
+//
+// The zero value accepts any system name matching the default system pattern and
+// any non-empty value up to the default maximum length.
+type ExternalIDRules struct {
+	// AllowedSystems restricts external IDs to the listed systems (empty allows any system)
+	AllowedSystems []string
+
+	// Patterns maps a system name to a regular expression its IDs must match
+	Patterns map[string]*regexp.Regexp
+
+	// MaxLength is the maximum length of an external ID (0 uses DefaultExternalIDMaxLength)
+	MaxLength int
+}
+
+// DefaultExternalIDMaxLength is the maximum length of an external ID when none is configured
+const DefaultExternalIDMaxLength = 128
+
+// systemNamePattern restricts system names to short lowercase identifiers
+var systemNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+var (
+	externalIDRules   ExternalIDRules
+	externalIDRulesMu sync.RWMutex
+)
+
+// ConfigureExternalIDs sets the rules used to validate external IDs.
+//
+// It is called once at startup from configuration. Entities created before the
+// rules are configured are validated with the defaults.
+func ConfigureExternalIDs(rules ExternalIDRules) {
+	externalIDRulesMu.Lock()
+	defer externalIDRulesMu.Unlock()
+	externalIDRules = rules
+}
+
+// currentExternalIDRules returns the configured external ID rules
+func currentExternalIDRules() ExternalIDRules {
+	externalIDRulesMu.RLock()
+	defer externalIDRulesMu.RUnlock()
+	return externalIDRules
+}
+
+// NewExternalIDs validates the given system -> ID mappings and returns them as ExternalIDs.
+//
+// Parameters:
+//   - ids: Map of external system name to the ID in that system (nil or empty is allowed)
+//
+// Returns:
+//   - The validated ExternalIDs (nil if ids is empty)
+//   - A ValidationError if a system name or ID is invalid
+func NewExternalIDs(ids map[string]string) (ExternalIDs, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rules := currentExternalIDRules()
+	maxLength := rules.MaxLength
+	if maxLength <= 0 {
+		maxLength = DefaultExternalIDMaxLength
+	}
+
+	result := make(ExternalIDs, len(ids))
+	for _, system := range sortedSystems(ids) {
+		id := ids[system]
+
+		if !systemNamePattern.MatchString(system) {
+			return nil, errorswrapper.NewValidationError(
+				fmt.Sprintf("invalid external system name %q: must be lowercase letters, digits, '-' or '_' and at most 32 characters", system),
+				"ExternalIDs", nil)
+		}
+		if len(rules.AllowedSystems) > 0 && !containsString(rules.AllowedSystems, system) {
+			return nil, errorswrapper.NewValidationError(
+				fmt.Sprintf("external system %q is not allowed", system), "ExternalIDs", nil)
+		}
+		if id == "" {
+			return nil, errorswrapper.NewValidationError(
+				fmt.Sprintf("external ID for system %q is required", system), "ExternalIDs", nil)
+		}
+		if len(id) > maxLength {
+			return nil, errorswrapper.NewValidationError(
+				fmt.Sprintf("external ID for system %q cannot exceed %d characters", system, maxLength), "ExternalIDs", nil)
+		}
+		if pattern, ok := rules.Patterns[system]; ok && pattern != nil && !pattern.MatchString(id) {
+			return nil, errorswrapper.NewValidationError(
+				fmt.Sprintf("external ID for system %q does not match the required format", system), "ExternalIDs", nil)
+		}
+
+		result[system] = id
+	}
+	return result, nil
+}
+
+// Get returns the ID for the given system and whether it is present
+func (e ExternalIDs) Get(system string) (string, bool) {
+	id, ok := e[system]
+	return id, ok
+}
+
+// Copy returns a copy of the external IDs, or nil if there are none.
+// Entities return copies to prevent modification of their internal state.
+func (e ExternalIDs) Copy() map[string]string {
+	if len(e) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(e))
+	for system, id := range e {
+		result[system] = id
+	}
+	return result
+}
+
+// Systems returns the system names in sorted order
+func (e ExternalIDs) Systems() []string {
+	return sortedSystems(e)
+}
+
+// sortedSystems returns the keys of ids in sorted order for deterministic iteration
+func sortedSystems(ids map[string]string) []string {
+	systems := make([]string, 0, len(ids))
+	for system := range ids {
+		systems = append(systems, system)
+	}
+	sort.Strings(systems)
+	return systems
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ExternalIDRef is an external ID together with the entity that holds it.
+// Repositories use references to enforce uniqueness and to index external IDs.
+type ExternalIDRef struct {
+	Owner      ExternalIDOwner // Kind of entity holding the external ID
+	EntityID   string          // ID of the family, parent, or child holding the external ID
+	System     string          // External system name
+	ExternalID string          // ID in the external system
+}
+
+// ExternalIDRefs returns references to the external IDs held by the family and its members,
+// ordered by owner, then member, then system
+func (f *Family) ExternalIDRefs() []ExternalIDRef {
+	var refs []ExternalIDRef
+	add := func(owner ExternalIDOwner, entityID string, ids ExternalIDs) {
+		for _, system := range ids.Systems() {
+			refs = append(refs, ExternalIDRef{Owner: owner, EntityID: entityID, System: system, ExternalID: ids[system]})
+		}
+	}
+
+	add(ExternalIDOwnerFamily, f.ID(), f.externalIDs)
+	for _, p := range f.parents {
+		add(ExternalIDOwnerParent, p.ID(), p.externalIDs)
+	}
+	for _, c := range f.children {
+		add(ExternalIDOwnerChild, c.ID(), c.externalIDs)
+	}
+	return refs
+}
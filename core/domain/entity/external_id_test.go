@@ -0,0 +1,94 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExternalIDs(t *testing.T) {
+	defer ConfigureExternalIDs(ExternalIDRules{})
+
+	ids, err := NewExternalIDs(nil)
+	require.NoError(t, err)
+	assert.Nil(t, ids)
+
+	ids, err = NewExternalIDs(map[string]string{"crm": "CRM-1", "billing_v2": "42"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing_v2", "crm"}, ids.Systems())
+
+	invalid := map[string]map[string]string{
+		"uppercase system": {"CRM": "1"},
+		"empty system":     {"": "1"},
+		"empty ID":         {"crm": ""},
+		"ID too long":      {"crm": strings.Repeat("x", DefaultExternalIDMaxLength+1)},
+	}
+	for name, input := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewExternalIDs(input)
+			assert.Error(t, err)
+		})
+	}
+
+	// Configured rules restrict systems, formats, and length
+	ConfigureExternalIDs(ExternalIDRules{
+		AllowedSystems: []string{"crm"},
+		Patterns:       map[string]*regexp.Regexp{"crm": regexp.MustCompile(`^CRM-[0-9]+$`)},
+		MaxLength:      8,
+	})
+
+	_, err = NewExternalIDs(map[string]string{"crm": "CRM-1"})
+	assert.NoError(t, err)
+	_, err = NewExternalIDs(map[string]string{"erp": "1"})
+	assert.Error(t, err)
+	_, err = NewExternalIDs(map[string]string{"crm": "1"})
+	assert.Error(t, err)
+	_, err = NewExternalIDs(map[string]string{"crm": "CRM-123456"})
+	assert.Error(t, err)
+}
+
+func TestFamilyExternalIDs(t *testing.T) {
+	birthDate := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	dto := FamilyDTO{
+		ID:          generateTestUUID(),
+		Status:      string(Married),
+		ExternalIDs: map[string]string{"crm": "F-1"},
+		Parents: []ParentDTO{
+			{ID: generateTestUUID(), FirstName: "John", LastName: "Doe", BirthDate: birthDate, ExternalIDs: map[string]string{"crm": "P-1"}},
+			{ID: generateTestUUID(), FirstName: "Jane", LastName: "Doe", BirthDate: birthDate},
+		},
+		Children: []ChildDTO{
+			{ID: generateTestUUID(), FirstName: "Jimmy", LastName: "Doe", BirthDate: time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), ExternalIDs: map[string]string{"crm": "C-1"}},
+		},
+	}
+
+	fam, err := FamilyFromDTO(dto)
+	require.NoError(t, err)
+
+	assert.True(t, fam.HasExternalID("", "crm", "P-1"))
+	assert.True(t, fam.HasExternalID(ExternalIDOwnerParent, "crm", "P-1"))
+	assert.False(t, fam.HasExternalID(ExternalIDOwnerChild, "crm", "P-1"))
+	assert.True(t, fam.HasExternalID(ExternalIDOwnerFamily, "crm", "F-1"))
+	assert.False(t, fam.HasExternalID("", "erp", "F-1"))
+
+	refs := fam.ExternalIDRefs()
+	require.Len(t, refs, 3)
+	assert.Equal(t, ExternalIDRef{Owner: ExternalIDOwnerFamily, EntityID: dto.ID, System: "crm", ExternalID: "F-1"}, refs[0])
+	assert.Equal(t, ExternalIDOwnerParent, refs[1].Owner)
+	assert.Equal(t, ExternalIDOwnerChild, refs[2].Owner)
+
+	// The DTO round-trips external IDs
+	assert.Equal(t, dto.ExternalIDs, fam.ToDTO().ExternalIDs)
+	assert.Equal(t, "P-1", fam.ToDTO().Parents[0].ExternalIDs["crm"])
+
+	// Two members of the same kind cannot share an external ID
+	dto.Parents[1].ExternalIDs = map[string]string{"crm": "P-1"}
+	_, err = FamilyFromDTO(dto)
+	assert.Error(t, err)
+}
@@ -6,9 +6,13 @@
 package entity
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/abitofhelp/family-service/core/domain/chronology"
 	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/identificationwrapper"
@@ -49,10 +53,13 @@
 // The Family struct uses private fields to enforce that all changes must go through
 // methods that can validate business rules, maintaining data integrity.
 type Family struct {
-	id       identificationwrapper.ID // Unique identifier for the family
-	status   Status                   // Current relationship status of the family
-	parents  []*Parent                // List of parents in the family (0-2)
-	children []*Child                 // List of children in the family
+	id          identificationwrapper.ID // Unique identifier for the family
+	status      Status                   // Current relationship status of the family
+	parents     []*Parent                // List of parents in the family (0-2)
+	children    []*Child                 // List of children in the family
+	externalIDs ExternalIDs              // IDs of the family in external systems
+	ownerID     string                   // ID of the user who owns the family, empty if it has no owner
+	version     int                      // Version of the stored family, 0 if it was not read from a repository
 }
 
 // generateID creates a new unique identifier for a family.
@@ -155,6 +162,7 @@ func (f *Family) Validate() error {
 
 	// Check for duplicate parents
 	seen := make(map[string]bool)
+	parentExternalIDs := make(map[string]string)
 	for i, p := range f.parents {
 		if p == nil {
 			result.AddError(fmt.Sprintf("parent at index %d is nil", i), "Parents")
@@ -173,15 +181,11 @@ func (f *Family) Validate() error {
 		}
 		seen[key] = true
 
-		// Enhanced validation: Validate parent age (minimum 18 years)
-		now := time.Now()
-		birthDate := p.BirthDate()
-		age := now.Year() - birthDate.Year()
+		// Check that no two parents share an external ID
+		addExternalIDConflicts(result, "Parents", p.ID(), p.externalIDs, parentExternalIDs)
 
-		// Adjust age if birthday hasn't occurred yet this year
-		if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
-			age--
-		}
+		// Enhanced validation: Validate parent age (minimum 18 years)
+		age := chronology.Age(p.BirthDate(), time.Now())
 
 		if age < 18 {
 			result.AddError(fmt.Sprintf("parent at index %d does not meet minimum age requirement (18 years)", i), "Parents")
@@ -189,12 +193,16 @@ func (f *Family) Validate() error {
 	}
 
 	// Validate children
+	childExternalIDs := make(map[string]string)
 	for i, c := range f.children {
 		if c == nil {
 			result.AddError(fmt.Sprintf("child at index %d is nil", i), "Children")
 			continue
 		}
 
+		// Check that no two children share an external ID
+		addExternalIDConflicts(result, "Children", c.ID(), c.externalIDs, childExternalIDs)
+
 		// Validate each child
 		if err := c.Validate(); err != nil {
 			result.AddError(fmt.Sprintf("child at index %d is invalid: %v", i, err), "Children")
@@ -253,14 +261,8 @@ func (f *Family) Validate() error {
 		for j, parent := range f.parents {
 			parentBirthDate := parent.BirthDate()
 
-			// Calculate the age difference in years
-			ageGap := childBirthDate.Year() - parentBirthDate.Year()
-
-			// Adjust for partial years
-			if childBirthDate.Month() < parentBirthDate.Month() || 
-			   (childBirthDate.Month() == parentBirthDate.Month() && childBirthDate.Day() < parentBirthDate.Day()) {
-				ageGap--
-			}
+			// Calculate the age of the parent in whole years when the child was born
+			ageGap := chronology.Years(parentBirthDate, childBirthDate)
 
 			// Minimum 12 years between parent and child
 			if ageGap < 12 {
@@ -436,6 +438,46 @@ func (f *Family) RemoveChild(childID string) error {
 	return errorswrapper.NewNotFoundError("Child", childID, nil)
 }
 
+// MoveChild moves a child from one family to another, enforcing the rules of both families.
+//
+// The child is added to the target family, which must still be valid with the child,
+// for example because the child was born after its new parents, and is then removed
+// from the source family. If any rule is violated, neither family is changed.
+//
+// Persisting both families is the responsibility of the caller.
+//
+// Returns:
+//   - nil if the child was moved
+//   - ValidationError if the families are the same or the target family is not valid with the child
+//   - NotFoundError if no child with the given ID exists in the source family
+//   - FamilyChildExistsError if the child already exists in the target family
+func MoveChild(from, to *Family, childID string) error {
+	if from.ID() == to.ID() {
+		return errorswrapper.NewValidationError("a child can only be moved to a different family", "ToFamilyID", nil)
+	}
+
+	var child *Child
+	for _, c := range from.children {
+		if c.ID() == childID {
+			child = c
+			break
+		}
+	}
+	if child == nil {
+		return errorswrapper.NewNotFoundError("Child", childID, nil)
+	}
+
+	if err := to.AddChild(child); err != nil {
+		return err
+	}
+	if err := to.Validate(); err != nil {
+		to.children = to.children[:len(to.children)-1]
+		return err
+	}
+
+	return from.RemoveChild(childID)
+}
+
 // RemoveParent removes a parent from the family by ID.
 //
 // This method maintains the integrity of the Family aggregate by:
@@ -462,8 +504,8 @@ func (f *Family) RemoveParent(parentID string) error {
 			f.parents = append(f.parents[:i], f.parents[i+1:]...)
 
 			// Update status if needed
-			if len(f.parents) == 1 && f.status == Married {
-				f.status = Single
+			if t, ok := allowedTransition(OperationRemoveParent, f.status); ok && len(f.parents) == 1 {
+				f.status = t.To
 			}
 
 			return nil
@@ -477,7 +519,7 @@ func (f *Family) RemoveParent(parentID string) error {
 // This method handles the important life event of a parent's death by:
 // 1. Finding the parent by ID
 // 2. Marking that parent as deceased with the provided death date
-// 3. Updating the family status if appropriate (e.g., from Married to Widowed)
+// 3. Widowing the family if it was married (see Widow)
 //
 // This is an example of how domain logic encapsulates real-world events and
 // ensures that all related state changes happen consistently.
@@ -504,15 +546,242 @@ func (f *Family) MarkParentDeceased(parentID string, deathDate time.Time) error
 		return errorswrapper.NewNotFoundError("Parent", parentID, nil)
 	}
 
-	if err := foundParent.MarkDeceased(deathDate); err != nil {
+	// If this was a married family with two parents, and one died, the family is widowed
+	if _, ok := allowedTransition(OperationWidow, f.status); ok && len(f.parents) == 2 {
+		return f.Widow(parentID, deathDate)
+	}
+
+	return foundParent.MarkDeceased(deathDate)
+}
+
+// MarkChildDeceased marks a child of the family as deceased.
+//
+// The child stays in the family, so that the family's record of its children is complete.
+//
+// Parameters:
+//   - childID: The ID of the child to mark as deceased
+//   - deathDate: The date when the child died
+//
+// Returns:
+//   - nil if the child was successfully marked as deceased
+//   - NotFoundError if no child with the given ID exists in the family
+//   - ChildAlreadyDeceasedError if the child is already marked as deceased
+//   - ValidationError if the death date is invalid (from the Child.MarkDeceased method)
+func (f *Family) MarkChildDeceased(childID string, deathDate time.Time) error {
+	for _, c := range f.children {
+		if c.ID() == childID {
+			return c.MarkDeceased(deathDate)
+		}
+	}
+	return errorswrapper.NewNotFoundError("Child", childID, nil)
+}
+
+// Marry changes the status of the family to Married.
+//
+// A family can marry once it has two living parents, for example after a second
+// parent was added to a single, divorced, or widowed family. The status is changed
+// in place, so the family keeps its ID, children, and external IDs.
+//
+// Returns:
+//   - nil if the family is now married
+//   - FamilyInvalidTransitionError if the family is already married or abandoned,
+//     does not have exactly two parents, or has a deceased parent
+//   - ValidationError if the married family would be invalid
+func (f *Family) Marry() error {
+	t, ok := allowedTransition(OperationMarry, f.status)
+	if !ok {
+		return domainerrors.NewFamilyInvalidTransitionError(fmt.Sprintf("a family with status %s cannot marry", f.status), nil)
+	}
+
+	if len(f.parents) != 2 {
+		return domainerrors.NewFamilyInvalidTransitionError("a married family must have exactly two parents", nil)
+	}
+	for _, p := range f.parents {
+		if p.IsDeceased() {
+			return domainerrors.NewFamilyInvalidTransitionError("a family with a deceased parent cannot marry", nil)
+		}
+	}
+
+	return f.transition(t.To)
+}
+
+// Remarry marries the parent of a single, divorced, or widowed family to a new spouse.
+//
+// The spouse joins the family, which then marries (see Marry), so the family keeps its
+// ID, children, and external IDs. If any rule is violated, the family is not changed.
+//
+// Parameters:
+//   - spouse: The new parent of the family
+//
+// Returns:
+//   - nil if the family is now married
+//   - ValidationError if the spouse is nil or the married family would be invalid
+//   - FamilyInvalidTransitionError if the family cannot marry, does not have exactly
+//     one parent, or either parent is deceased
+//   - FamilyParentExistsError if the spouse is already a parent of the family
+func (f *Family) Remarry(spouse *Parent) error {
+	if spouse == nil {
+		return errorswrapper.NewValidationError("spouse cannot be nil", "Spouse", nil)
+	}
+	if _, ok := allowedTransition(OperationMarry, f.status); !ok {
+		return domainerrors.NewFamilyInvalidTransitionError(fmt.Sprintf("a family with status %s cannot remarry", f.status), nil)
+	}
+	if len(f.parents) != 1 {
+		return domainerrors.NewFamilyInvalidTransitionError("a family must have exactly one parent to remarry", nil)
+	}
+
+	parents := f.parents
+	if err := f.AddParent(spouse); err != nil {
+		return err
+	}
+	if err := f.Marry(); err != nil {
+		f.parents = parents
+		return err
+	}
+	return nil
+}
+
+// MergeFamilies merges the family of a spouse into a family whose parent remarries.
+//
+// The parent of the spouse family joins the family, which then marries (see Remarry),
+// and the children of the spouse family with the given IDs, for whom the spouse has
+// custody, move along with their parent. Both families must have exactly one parent.
+// The spouse family keeps its other children, so it is left without any children when
+// all of them move; the caller then dissolves it. If any rule is violated, neither
+// family is changed.
+//
+// Persisting both families is the responsibility of the caller.
+//
+// Parameters:
+//   - family: The family that remarries and keeps its ID
+//   - spouseFamily: The family of the spouse
+//   - childIDs: IDs of the children of the spouse family that move to the family
+//
+// Returns:
+//   - nil if the families were merged
+//   - ValidationError if the families are the same or the merged family would be invalid
+//   - FamilyInvalidTransitionError if either family cannot marry, does not have exactly
+//     one parent, or has a deceased parent
+//   - NotFoundError if a child is not in the spouse family
+//   - FamilyChildExistsError if a child is already in the family
+func MergeFamilies(family, spouseFamily *Family, childIDs []string) error {
+	if family.ID() == spouseFamily.ID() {
+		return errorswrapper.NewValidationError("a family can only be merged with a different family", "SpouseFamilyID", nil)
+	}
+	if _, ok := allowedTransition(OperationMarry, spouseFamily.status); !ok {
+		return domainerrors.NewFamilyInvalidTransitionError(fmt.Sprintf("a spouse family with status %s cannot be merged", spouseFamily.status), nil)
+	}
+	if len(spouseFamily.parents) != 1 {
+		return domainerrors.NewFamilyInvalidTransitionError("a spouse family must have exactly one parent", nil)
+	}
+
+	moving := make(map[string]bool, len(childIDs))
+	for _, childID := range childIDs {
+		found := false
+		for _, c := range spouseFamily.children {
+			if c.ID() == childID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errorswrapper.NewNotFoundError("Child", childID, nil)
+		}
+		moving[childID] = true
+	}
+
+	parents, children, status := family.parents, family.children, family.status
+	restore := func() {
+		family.parents, family.children, family.status = parents, children, status
+	}
+
+	if err := family.Remarry(spouseFamily.parents[0]); err != nil {
+		return err
+	}
+
+	var staying []*Child
+	for _, c := range spouseFamily.children {
+		if !moving[c.ID()] {
+			staying = append(staying, c)
+			continue
+		}
+		if err := family.AddChild(c); err != nil {
+			restore()
+			return err
+		}
+	}
+	if err := family.Validate(); err != nil {
+		restore()
+		return err
+	}
+
+	spouseFamily.children = staying
+	return nil
+}
+
+// Widow records the death of one of the parents of a married family.
+//
+// The deceased parent is marked as deceased and leaves the family, and the family
+// is widowed: it keeps its ID, children, and external IDs with the surviving parent.
+//
+// Parameters:
+//   - deceasedParentID: The ID of the parent who died
+//   - deathDate: The date when the parent died
+//
+// Returns:
+//   - nil if the family is now widowed
+//   - FamilyNotMarriedError if the family is not married
+//   - FamilyInvalidTransitionError if the family does not have two living parents
+//   - NotFoundError if no parent with the given ID exists in the family
+//   - ValidationError if the death date is invalid (from the Parent.MarkDeceased method)
+func (f *Family) Widow(deceasedParentID string, deathDate time.Time) error {
+	t, ok := allowedTransition(OperationWidow, f.status)
+	if !ok {
+		return domainerrors.NewFamilyNotMarriedError("only married families can be widowed", nil)
+	}
+
+	if len(f.parents) != 2 {
+		return domainerrors.NewFamilyInvalidTransitionError("a married family must have exactly two parents", nil)
+	}
+
+	var deceasedParent, survivingParent *Parent
+	for _, p := range f.parents {
+		if p.ID() == deceasedParentID {
+			deceasedParent = p
+		} else {
+			survivingParent = p
+		}
+	}
+
+	if deceasedParent == nil {
+		return errorswrapper.NewNotFoundError("Parent", deceasedParentID, nil)
+	}
+	if survivingParent.IsDeceased() {
+		return domainerrors.NewFamilyInvalidTransitionError("a widowed family must have a living parent", nil)
+	}
+
+	if err := deceasedParent.MarkDeceased(deathDate); err != nil {
 		return err
 	}
 
-	// If this was a married family with two parents, and one died, update status
-	if f.status == Married && len(f.parents) == 2 {
-		f.status = Widowed
+	parents := f.parents
+	f.parents = []*Parent{survivingParent}
+	if err := f.transition(t.To); err != nil {
+		f.parents = parents
+		return err
 	}
+	return nil
+}
 
+// transition changes the status of the family and validates the result.
+// The previous status is restored if the family would be invalid.
+func (f *Family) transition(status Status) error {
+	previous := f.status
+	f.status = status
+	if err := f.Validate(); err != nil {
+		f.status = previous
+		return err
+	}
 	return nil
 }
 
@@ -548,7 +817,8 @@ func (f *Family) MarkParentDeceased(parentID string, deathDate time.Time) error
 //	// 1. The original family with parent-123 and all children, status = Divorced
 //	// 2. newFamily with the other parent, no children, status = Divorced
 func (f *Family) Divorce(custodialParentID string) (*Family, error) {
-	if f.status != Married {
+	t, ok := allowedTransition(OperationDivorce, f.status)
+	if !ok {
 		return nil, domainerrors.NewFamilyNotMarriedError("only married families can divorce", nil)
 	}
 
@@ -575,7 +845,7 @@ func (f *Family) Divorce(custodialParentID string) (*Family, error) {
 	// The original family ID will stay with the custodial parent and children
 	remainingFamily, err := NewFamily(
 		"", // Empty ID will cause a new ID to be generated
-		Divorced,
+		t.To,
 		[]*Parent{remainingParent},
 		[]*Child{}, // No children with the remaining parent
 	)
@@ -584,15 +854,122 @@ func (f *Family) Divorce(custodialParentID string) (*Family, error) {
 		return nil, domainerrors.NewFamilyCreateFailedError("failed to create new family for remaining parent", err)
 	}
 
+	// The family of the remaining parent has the owner of the original family
+	remainingFamily.ownerID = f.ownerID
+
 	// Update the original family to keep only the custodial parent
 	f.parents = []*Parent{custodialParent}
-	f.status = Divorced
+	f.status = t.To
 
 	// Return the new family with the remaining parent
 	// The original family (with custodial parent and children) is modified in place
 	return remainingFamily, nil
 }
 
+// ExternalIDs returns the family's IDs in external systems, keyed by system name.
+//
+// It returns a copy so that callers cannot modify the family's internal state.
+// The result is nil if the family has no external IDs.
+func (f *Family) ExternalIDs() map[string]string {
+	return f.externalIDs.Copy()
+}
+
+// SetExternalIDs replaces the family's external IDs after validating them.
+//
+// Uniqueness of each external ID per system across families is enforced by the
+// repository when the family is saved.
+//
+// Parameters:
+//   - ids: Map of external system name to the family's ID in that system (nil clears them)
+//
+// Returns:
+//   - nil if the external IDs were set
+//   - ValidationError if a system name or ID is invalid
+func (f *Family) SetExternalIDs(ids map[string]string) error {
+	externalIDs, err := NewExternalIDs(ids)
+	if err != nil {
+		return err
+	}
+	f.externalIDs = externalIDs
+	return nil
+}
+
+// OwnerID returns the ID of the user who owns the family, or an empty string if the
+// family has no owner.
+//
+// The owner is the user who created the family. Users other than administrators can
+// only read and change the families they own; families without an owner, such as those
+// created before families had owners, can only be accessed by administrators.
+func (f *Family) OwnerID() string {
+	return f.ownerID
+}
+
+// SetOwnerID sets the ID of the user who owns the family; an empty ID removes the owner.
+// The application service sets it when the family is created, and repositories when they
+// read the family.
+func (f *Family) SetOwnerID(ownerID string) {
+	f.ownerID = ownerID
+}
+
+// Version returns the version of the family in the repository it was read from.
+//
+// Repositories count the saves of a family in its version and reject the save of a family
+// whose version is no longer the stored version with a ConcurrencyError, so that a change
+// made to a stale copy of the family cannot overwrite a change saved in the meantime. A
+// family that was not read from a repository has version 0 and is saved as a new family,
+// which is rejected if a family with its ID is stored.
+func (f *Family) Version() int {
+	return f.version
+}
+
+// SetVersion sets the version of the family. Repositories set it when they read or save
+// the family; a version of 0 makes the next save insert the family as a new one.
+func (f *Family) SetVersion(version int) {
+	f.version = version
+}
+
+// HasExternalID reports whether the family, or one of its members of the given
+// kind, has the external ID in the given system. An empty owner matches the
+// family and all of its members.
+func (f *Family) HasExternalID(owner ExternalIDOwner, system, externalID string) bool {
+	matches := func(ids ExternalIDs) bool {
+		id, ok := ids.Get(system)
+		return ok && id == externalID
+	}
+
+	if (owner == "" || owner == ExternalIDOwnerFamily) && matches(f.externalIDs) {
+		return true
+	}
+	if owner == "" || owner == ExternalIDOwnerParent {
+		for _, p := range f.parents {
+			if matches(p.externalIDs) {
+				return true
+			}
+		}
+	}
+	if owner == "" || owner == ExternalIDOwnerChild {
+		for _, c := range f.children {
+			if matches(c.externalIDs) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addExternalIDConflicts records the external IDs of a family member in owners, which maps
+// "system:id" to the ID of the member that holds it, and adds an error to result for each
+// external ID already held by a different member of the same kind
+func addExternalIDConflicts(result validationwrapper.ValidationResult, field, memberID string, ids ExternalIDs, owners map[string]string) {
+	for _, system := range ids.Systems() {
+		key := system + ":" + ids[system]
+		if owner, ok := owners[key]; ok && owner != memberID {
+			result.AddError(fmt.Sprintf("external ID %s is assigned to more than one member", key), field)
+		}
+		owners[key] = memberID
+	}
+}
+
 // ToDTO converts the Family aggregate to a data transfer object for external use.
 //
 // This method creates a DTO (Data Transfer Object) that can be safely passed
@@ -625,6 +1002,9 @@ func (f *Family) ToDTO() FamilyDTO {
 		Children:      childDTOs,
 		ParentCount:   f.CountParents(),
 		ChildrenCount: f.CountChildren(),
+		ExternalIDs:   f.externalIDs.Copy(),
+		OwnerID:       f.ownerID,
+		Version:       f.version,
 	}
 }
 
@@ -638,12 +1018,37 @@ func (f *Family) ToDTO() FamilyDTO {
 // the domain model and external interfaces, preventing domain logic
 // from leaking into other layers.
 type FamilyDTO struct {
-	ID            string      // Unique identifier for the family
-	Status        string      // Current relationship status as a string
-	Parents       []ParentDTO // List of parent DTOs
-	Children      []ChildDTO  // List of child DTOs
-	ParentCount   int         // Number of parents in the family
-	ChildrenCount int         // Number of children in the family
+	ID            string            // Unique identifier for the family
+	Status        string            // Current relationship status as a string
+	Parents       []ParentDTO       // List of parent DTOs
+	Children      []ChildDTO        // List of child DTOs
+	ParentCount   int               // Number of parents in the family
+	ChildrenCount int               // Number of children in the family
+	ExternalIDs   map[string]string // IDs of the family in external systems (system -> ID)
+	OwnerID       string            // ID of the user who owns the family, empty if it has no owner
+	Version       int               // Version of the stored family, 0 if it was not read from a repository
+}
+
+// Checksum returns a checksum of the state of the family.
+//
+// The checksum is computed from the contents of the aggregate rather than its version and
+// owner: it changes whenever the status, a member, or an external ID changes, and stays the
+// same otherwise, even if the family is saved again unchanged. Clients can use it as an
+// ETag to validate cached copies.
+//
+// Returns:
+//   - The checksum as a hexadecimal string
+func (dto FamilyDTO) Checksum() string {
+	// Maps are encoded with sorted keys, so equal families have equal encodings
+	dto.Version = 0
+	dto.OwnerID = ""
+	encoded, err := json.Marshal(dto)
+	if err != nil {
+		// The DTO consists of plain data, which always encodes
+		panic(fmt.Sprintf("failed to encode family %s: %v", dto.ID, err))
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:16])
 }
 
 // FamilyFromDTO creates a Family aggregate from a data transfer object.
@@ -683,5 +1088,16 @@ func FamilyFromDTO(dto FamilyDTO) (*Family, error) {
 		children[i] = c
 	}
 
-	return NewFamily(dto.ID, Status(dto.Status), parents, children)
+	f, err := NewFamily(dto.ID, Status(dto.Status), parents, children)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.SetExternalIDs(dto.ExternalIDs); err != nil {
+		return nil, err
+	}
+	f.ownerID = dto.OwnerID
+	f.version = dto.Version
+
+	return f, nil
 }
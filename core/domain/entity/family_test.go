@@ -7,8 +7,11 @@
 	"testing"
 	"time"
 
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // generateTestUUID generates a UUID for testing
@@ -150,3 +153,204 @@ func TestFamilyValidationWidowedStatus(t *testing.T) {
 	assert.True(t, strings.Contains(err.Error(), "widowed family cannot have a deceased parent"), 
 		"error should mention validation failure")
 }
+
+func TestFamilyMarry(t *testing.T) {
+	parent1, err := NewParent(generateTestUUID(), "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	parent2, err := NewParent(generateTestUUID(), "Jane", "Doe", time.Date(1982, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+
+	fam, err := NewFamily(generateTestUUID(), Single, []*Parent{parent1}, nil)
+	require.NoError(t, err)
+	require.NoError(t, fam.SetExternalIDs(map[string]string{"crm": "CRM-1"}))
+
+	err = fam.Marry()
+	var invalidTransition *domainerrors.FamilyInvalidTransitionError
+	assert.ErrorAs(t, err, &invalidTransition, "a married family needs two parents")
+	assert.Equal(t, Single, fam.Status())
+
+	require.NoError(t, fam.AddParent(parent2))
+	require.NoError(t, fam.Marry())
+	assert.Equal(t, Married, fam.Status())
+	assert.Equal(t, map[string]string{"crm": "CRM-1"}, fam.ExternalIDs(), "the family is changed in place")
+
+	assert.ErrorAs(t, fam.Marry(), &invalidTransition, "a married family cannot marry again")
+}
+
+func TestFamilyWidow(t *testing.T) {
+	parent1, err := NewParent(generateTestUUID(), "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	parent2, err := NewParent(generateTestUUID(), "Jane", "Doe", time.Date(1982, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	child, err := NewChild(generateTestUUID(), "Jimmy", "Doe", time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+
+	fam, err := NewFamily(generateTestUUID(), Married, []*Parent{parent1, parent2}, []*Child{child})
+	require.NoError(t, err)
+
+	assert.True(t, errorswrapper.IsNotFoundError(fam.Widow(generateTestUUID(), time.Now())))
+	assert.Equal(t, Married, fam.Status())
+
+	deathDate := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, fam.MarkParentDeceased(parent1.ID(), deathDate))
+	assert.Equal(t, Widowed, fam.Status())
+	require.Len(t, fam.Parents(), 1, "the deceased parent leaves the family")
+	assert.Equal(t, parent2.ID(), fam.Parents()[0].ID())
+	assert.True(t, parent1.IsDeceased())
+	assert.Equal(t, 1, fam.CountChildren())
+	assert.NoError(t, fam.Validate(), "a widowed family can be saved")
+
+	var notMarried *domainerrors.FamilyNotMarriedError
+	assert.ErrorAs(t, fam.Widow(parent2.ID(), deathDate), &notMarried)
+}
+
+func TestFamilyDTOChecksum(t *testing.T) {
+	parent, err := NewParent(generateTestUUID(), "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := NewFamily(generateTestUUID(), Single, []*Parent{parent}, nil)
+	require.NoError(t, err)
+	require.NoError(t, fam.SetExternalIDs(map[string]string{"crm": "CRM-1", "erp": "ERP-1"}))
+
+	checksum := fam.ToDTO().Checksum()
+	assert.Len(t, checksum, 32)
+	assert.Equal(t, checksum, fam.ToDTO().Checksum(), "the checksum is stable")
+
+	reloaded, err := FamilyFromDTO(fam.ToDTO())
+	require.NoError(t, err)
+	assert.Equal(t, checksum, reloaded.ToDTO().Checksum(), "equal families have equal checksums")
+
+	reloaded.SetVersion(3)
+	assert.Equal(t, 3, reloaded.ToDTO().Version)
+	assert.Equal(t, checksum, reloaded.ToDTO().Checksum(), "the checksum does not depend on the version")
+
+	reloaded.SetOwnerID("user-1")
+	assert.Equal(t, "user-1", reloaded.ToDTO().OwnerID)
+	assert.Equal(t, checksum, reloaded.ToDTO().Checksum(), "the checksum does not depend on the owner")
+	owned, err := FamilyFromDTO(reloaded.ToDTO())
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", owned.OwnerID())
+
+	child, err := NewChild(generateTestUUID(), "Jimmy", "Doe", time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	require.NoError(t, fam.AddChild(child))
+	assert.NotEqual(t, checksum, fam.ToDTO().Checksum(), "the checksum changes with the family")
+}
+
+func TestMoveChild(t *testing.T) {
+	parent1, err := NewParent(generateTestUUID(), "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	parent2, err := NewParent(generateTestUUID(), "Mary", "Roe", time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	child, err := NewChild(generateTestUUID(), "Jimmy", "Doe", time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+
+	from, err := NewFamily(generateTestUUID(), Single, []*Parent{parent1}, []*Child{child})
+	require.NoError(t, err)
+	to, err := NewFamily(generateTestUUID(), Single, []*Parent{parent2}, nil)
+	require.NoError(t, err)
+
+	// The child cannot join a family whose parent is younger than the child
+	assert.Error(t, MoveChild(from, to, child.ID()))
+	assert.Equal(t, 1, from.CountChildren(), "the source family is unchanged")
+	assert.Equal(t, 0, to.CountChildren(), "the target family is unchanged")
+
+	assert.True(t, errorswrapper.IsNotFoundError(MoveChild(from, to, generateTestUUID())))
+	assert.True(t, errorswrapper.IsValidationError(MoveChild(from, from, child.ID())))
+
+	other, err := NewParent(generateTestUUID(), "Ann", "Poe", time.Date(1978, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	to, err = NewFamily(generateTestUUID(), Single, []*Parent{other}, nil)
+	require.NoError(t, err)
+	require.NoError(t, MoveChild(from, to, child.ID()))
+	assert.Equal(t, 0, from.CountChildren())
+	require.Equal(t, 1, to.CountChildren())
+	assert.Equal(t, child.ID(), to.Children()[0].ID())
+
+	// A child cannot be moved into a family that already has it
+	require.NoError(t, from.AddChild(child))
+	var exists *domainerrors.FamilyChildExistsError
+	assert.ErrorAs(t, MoveChild(from, to, child.ID()), &exists)
+}
+
+func TestFamilyMarkChildDeceased(t *testing.T) {
+	parent, err := NewParent(generateTestUUID(), "Jane", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	child, err := NewChild(generateTestUUID(), "Jimmy", "Doe", time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := NewFamily(generateTestUUID(), Single, []*Parent{parent}, []*Child{child})
+	require.NoError(t, err)
+
+	assert.True(t, errorswrapper.IsNotFoundError(fam.MarkChildDeceased(parent.ID(), time.Now())))
+	assert.True(t, errorswrapper.IsValidationError(fam.MarkChildDeceased(child.ID(), time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC))),
+		"the death date must be after the birth date")
+
+	deathDate := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, fam.MarkChildDeceased(child.ID(), deathDate))
+	require.Len(t, fam.Children(), 1, "the deceased child stays in the family")
+	assert.True(t, fam.Children()[0].IsDeceased())
+	assert.Equal(t, Single, fam.Status())
+	assert.NoError(t, fam.Validate())
+
+	var alreadyDeceased *domainerrors.ChildAlreadyDeceasedError
+	assert.ErrorAs(t, fam.MarkChildDeceased(child.ID(), deathDate), &alreadyDeceased)
+}
+
+func TestFamilyRemarry(t *testing.T) {
+	parent, err := NewParent(generateTestUUID(), "Jane", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	spouse, err := NewParent(generateTestUUID(), "John", "Roe", time.Date(1978, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	child, err := NewChild(generateTestUUID(), "Jimmy", "Doe", time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := NewFamily(generateTestUUID(), Divorced, []*Parent{parent}, []*Child{child})
+	require.NoError(t, err)
+
+	assert.True(t, errorswrapper.IsValidationError(fam.Remarry(nil)))
+
+	require.NoError(t, fam.Remarry(spouse))
+	assert.Equal(t, Married, fam.Status())
+	assert.Len(t, fam.Parents(), 2)
+	assert.Len(t, fam.Children(), 1, "the family keeps its children")
+
+	// A married family cannot remarry
+	other, err := NewParent(generateTestUUID(), "Ann", "Poe", time.Date(1979, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	var invalid *domainerrors.FamilyInvalidTransitionError
+	assert.ErrorAs(t, fam.Remarry(other), &invalid)
+	assert.Len(t, fam.Parents(), 2)
+}
+
+func TestMergeFamilies(t *testing.T) {
+	parent, err := NewParent(generateTestUUID(), "Jane", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	spouse, err := NewParent(generateTestUUID(), "John", "Roe", time.Date(1978, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	child1, err := NewChild(generateTestUUID(), "Jimmy", "Roe", time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	child2, err := NewChild(generateTestUUID(), "Sally", "Roe", time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+
+	fam, err := NewFamily(generateTestUUID(), Widowed, []*Parent{parent}, nil)
+	require.NoError(t, err)
+	spouseFam, err := NewFamily(generateTestUUID(), Divorced, []*Parent{spouse}, []*Child{child1, child2})
+	require.NoError(t, err)
+
+	assert.True(t, errorswrapper.IsValidationError(MergeFamilies(fam, fam, nil)))
+	assert.True(t, errorswrapper.IsNotFoundError(MergeFamilies(fam, spouseFam, []string{generateTestUUID()})))
+	assert.Equal(t, Widowed, fam.Status(), "the family is unchanged")
+
+	require.NoError(t, MergeFamilies(fam, spouseFam, []string{child1.ID()}))
+	assert.Equal(t, Married, fam.Status())
+	assert.Len(t, fam.Parents(), 2)
+	require.Len(t, fam.Children(), 1)
+	assert.Equal(t, child1.ID(), fam.Children()[0].ID())
+	require.Len(t, spouseFam.Children(), 1, "the spouse family keeps the children that do not move")
+	assert.Equal(t, child2.ID(), spouseFam.Children()[0].ID())
+
+	// A married family cannot be merged into another family
+	single, err := NewFamily(generateTestUUID(), Single, []*Parent{spouse}, nil)
+	require.NoError(t, err)
+	var invalid *domainerrors.FamilyInvalidTransitionError
+	assert.ErrorAs(t, MergeFamilies(single, fam, nil), &invalid)
+	assert.Equal(t, Single, single.Status())
+}
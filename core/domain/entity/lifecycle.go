@@ -0,0 +1,64 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+// Operations of the Family aggregate that change its status
+const (
+	// OperationMarry is Family.Marry
+	OperationMarry = "marry"
+
+	// OperationDivorce is Family.Divorce
+	OperationDivorce = "divorce"
+
+	// OperationWidow is Family.Widow, which Family.MarkParentDeceased also makes for a
+	// married family
+	OperationWidow = "widow"
+
+	// OperationRemoveParent is Family.RemoveParent
+	OperationRemoveParent = "removeParent"
+)
+
+// Transition is a change of the status of a family that the domain allows
+type Transition struct {
+	Operation string // Operation of the Family aggregate that makes the transition
+	From      Status
+	To        Status
+	Condition string // What the family must also satisfy, beyond its status
+}
+
+// statuses lists every status of a family, in lifecycle order
+var statuses = []Status{Single, Married, Divorced, Widowed, Abandoned}
+
+// lifecycle lists the status transitions of a family. The operations that change the
+// status of a family look their transitions up here, so the list describes exactly the
+// transitions the domain allows.
+var lifecycle = []Transition{
+	{Operation: OperationMarry, From: Single, To: Married, Condition: "the family has exactly two living parents"},
+	{Operation: OperationMarry, From: Divorced, To: Married, Condition: "the family has exactly two living parents"},
+	{Operation: OperationMarry, From: Widowed, To: Married, Condition: "the family has exactly two living parents"},
+	{Operation: OperationDivorce, From: Married, To: Divorced, Condition: "the family has exactly two parents; the non-custodial parent leaves for a new DIVORCED family"},
+	{Operation: OperationWidow, From: Married, To: Widowed, Condition: "the family has two parents and the surviving parent is alive; the deceased parent leaves the family"},
+	{Operation: OperationRemoveParent, From: Married, To: Single, Condition: "the family has two parents"},
+}
+
+// Statuses returns every status of a family, in lifecycle order
+func Statuses() []Status {
+	return append([]Status(nil), statuses...)
+}
+
+// Lifecycle returns the status transitions of a family that the domain allows. Families
+// can be created in any status that their members satisfy; ABANDONED has no transitions.
+func Lifecycle() []Transition {
+	return append([]Transition(nil), lifecycle...)
+}
+
+// allowedTransition returns the transition that an operation makes from a status, if the
+// operation is allowed in that status
+func allowedTransition(operation string, from Status) (Transition, bool) {
+	for _, t := range lifecycle {
+		if t.Operation == operation && t.From == from {
+			return t, true
+		}
+	}
+	return Transition{}, false
+}
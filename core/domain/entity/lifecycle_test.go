@@ -0,0 +1,73 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// familyWithStatus returns a family with two living parents in the given status
+func familyWithStatus(t *testing.T, status Status) *Family {
+	t.Helper()
+	p1, err := NewParent(generateTestUUID(), "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	p2, err := NewParent(generateTestUUID(), "Jane", "Doe", time.Date(1982, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := NewFamily(generateTestUUID(), Married, []*Parent{p1, p2}, []*Child{})
+	require.NoError(t, err)
+	fam.status = status
+	return fam
+}
+
+func TestLifecycle_CopiesTheTable(t *testing.T) {
+	transitions := Lifecycle()
+	require.NotEmpty(t, transitions)
+	transitions[0].To = Abandoned
+	assert.Equal(t, Married, Lifecycle()[0].To)
+
+	assert.Equal(t, []Status{Single, Married, Divorced, Widowed, Abandoned}, Statuses())
+}
+
+func TestLifecycle_ListedTransitionsAreAllowed(t *testing.T) {
+	for _, tr := range Lifecycle() {
+		tr := tr
+		t.Run(tr.Operation+"/"+string(tr.From), func(t *testing.T) {
+			fam := familyWithStatus(t, tr.From)
+			switch tr.Operation {
+			case OperationMarry:
+				require.NoError(t, fam.Marry())
+			case OperationDivorce:
+				_, err := fam.Divorce(fam.parents[0].ID())
+				require.NoError(t, err)
+			case OperationWidow:
+				require.NoError(t, fam.Widow(fam.parents[0].ID(), time.Now().UTC().Add(-time.Hour)))
+			case OperationRemoveParent:
+				require.NoError(t, fam.RemoveParent(fam.parents[0].ID()))
+			default:
+				t.Fatalf("unknown operation %q", tr.Operation)
+			}
+			assert.Equal(t, tr.To, fam.Status())
+		})
+	}
+}
+
+func TestLifecycle_UnlistedTransitionsAreRejected(t *testing.T) {
+	for _, status := range Statuses() {
+		if _, ok := allowedTransition(OperationMarry, status); !ok {
+			assert.Error(t, familyWithStatus(t, status).Marry(), "marry from %s", status)
+		}
+		if _, ok := allowedTransition(OperationDivorce, status); !ok {
+			fam := familyWithStatus(t, status)
+			_, err := fam.Divorce(fam.parents[0].ID())
+			assert.Error(t, err, "divorce from %s", status)
+		}
+		if _, ok := allowedTransition(OperationWidow, status); !ok {
+			fam := familyWithStatus(t, status)
+			assert.Error(t, fam.Widow(fam.parents[0].ID(), time.Now().UTC().Add(-time.Hour)), "widow from %s", status)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/identificationwrapper"
+)
+
+// MemberDetails are corrections of the recorded details of a parent or child, such as a
+// misspelled name or a mistyped birth date.
+//
+// A correction is not a change of name: the member's name was always the corrected name,
+// so no name change is recorded. If the member has a name history, its latest entry is
+// corrected too, and the name from birth moves with a corrected birth date.
+type MemberDetails struct {
+	FirstName string    // Corrected first name (empty keeps the first name)
+	LastName  string    // Corrected last name (empty keeps the last name)
+	BirthDate time.Time // Corrected birth date (zero keeps the birth date)
+}
+
+// IsEmpty reports whether the corrections change nothing
+func (d MemberDetails) IsEmpty() bool {
+	return d.FirstName == "" && d.LastName == "" && d.BirthDate.IsZero()
+}
+
+// correctedDetails are the details of a member with corrections applied
+type correctedDetails struct {
+	firstName   identificationwrapper.Name
+	lastName    identificationwrapper.Name
+	birthDate   identificationwrapper.DateOfBirth
+	nameHistory NameHistory
+}
+
+// apply returns the details of a member with the corrections applied. The member's other
+// details are validated by the member once the corrections are applied.
+func (d MemberDetails) apply(current correctedDetails, deathDate *time.Time) (correctedDetails, error) {
+	if d.IsEmpty() {
+		return correctedDetails{}, errorswrapper.NewValidationError("at least one of the first name, last name, or birth date is required", "Details", nil)
+	}
+
+	corrected := current
+	if d.FirstName != "" {
+		firstName, err := identificationwrapper.NewName(d.FirstName)
+		if err != nil {
+			return correctedDetails{}, errorswrapper.NewValidationError("invalid FirstName: "+err.Error(), "FirstName", err)
+		}
+		corrected.firstName = firstName
+	}
+	if d.LastName != "" {
+		lastName, err := identificationwrapper.NewName(d.LastName)
+		if err != nil {
+			return correctedDetails{}, errorswrapper.NewValidationError("invalid LastName: "+err.Error(), "LastName", err)
+		}
+		corrected.lastName = lastName
+	}
+	if !d.BirthDate.IsZero() {
+		birth := DateOf(d.BirthDate)
+		birthDate, err := identificationwrapper.NewDateOfBirth(birth.Year(), int(birth.Month()), birth.Day())
+		if err != nil {
+			return correctedDetails{}, errorswrapper.NewValidationError("invalid BirthDate: "+err.Error(), "BirthDate", err)
+		}
+		corrected.birthDate = birthDate
+	}
+
+	if len(current.nameHistory) > 0 {
+		changes := current.nameHistory.Copy()
+		if changes[0].EffectiveDate.Equal(dateOnly(current.birthDate.Date())) {
+			changes[0].EffectiveDate = corrected.birthDate.Date()
+		}
+		changes[len(changes)-1].FirstName = corrected.firstName.String()
+		changes[len(changes)-1].LastName = corrected.lastName.String()
+
+		history, err := NewNameHistory(changes, corrected.birthDate.Date(), deathDate)
+		if err != nil {
+			return correctedDetails{}, err
+		}
+		corrected.nameHistory = history
+	}
+	return corrected, nil
+}
+
+// UpdateParent corrects the recorded details of a parent of the family.
+//
+// The family must still be valid with the corrected parent, for example because the
+// parent is still older than the children. If any rule is violated, the parent is not
+// changed.
+//
+// Parameters:
+//   - parentID: The ID of the parent to correct
+//   - details: The corrected details
+//
+// Returns:
+//   - nil if the parent was corrected
+//   - NotFoundError if no parent with the given ID exists in the family
+//   - ValidationError if no detail is corrected, a detail is invalid, or the family is not
+//     valid with the corrected parent
+func (f *Family) UpdateParent(parentID string, details MemberDetails) error {
+	for _, p := range f.parents {
+		if p.ID() != parentID {
+			continue
+		}
+		original := *p
+		if err := p.CorrectDetails(details); err != nil {
+			return err
+		}
+		if err := f.Validate(); err != nil {
+			*p = original
+			return err
+		}
+		return nil
+	}
+	return errorswrapper.NewNotFoundError("Parent", parentID, nil)
+}
+
+// UpdateChild corrects the recorded details of a child of the family.
+//
+// The family must still be valid with the corrected child, for example because the
+// child was still born after its parents. If any rule is violated, the child is not
+// changed.
+//
+// Parameters:
+//   - childID: The ID of the child to correct
+//   - details: The corrected details
+//
+// Returns:
+//   - nil if the child was corrected
+//   - NotFoundError if no child with the given ID exists in the family
+//   - ValidationError if no detail is corrected, a detail is invalid, or the family is not
+//     valid with the corrected child
+func (f *Family) UpdateChild(childID string, details MemberDetails) error {
+	for _, c := range f.children {
+		if c.ID() != childID {
+			continue
+		}
+		original := *c
+		if err := c.CorrectDetails(details); err != nil {
+			return err
+		}
+		if err := f.Validate(); err != nil {
+			*c = original
+			return err
+		}
+		return nil
+	}
+	return errorswrapper.NewNotFoundError("Child", childID, nil)
+}
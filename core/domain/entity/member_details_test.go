@@ -0,0 +1,90 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParentCorrectDetails(t *testing.T) {
+	birthDate := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	parent, err := NewParent(generateTestUUID(), "Jnae", "Doe", birthDate, nil)
+	require.NoError(t, err)
+
+	// Details that are not given are kept
+	require.NoError(t, parent.CorrectDetails(MemberDetails{FirstName: "Jane"}))
+	assert.Equal(t, "Jane Doe", parent.FullName())
+	assert.Equal(t, birthDate, parent.BirthDate())
+	assert.Nil(t, parent.NameHistory(), "a correction is not a change of name")
+
+	corrected := time.Date(1981, 2, 3, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, parent.CorrectDetails(MemberDetails{LastName: "Roe", BirthDate: corrected}))
+	assert.Equal(t, "Jane Roe", parent.FullName())
+	assert.Equal(t, corrected, parent.BirthDate())
+
+	// A rejected correction leaves the parent unchanged
+	for name, details := range map[string]MemberDetails{
+		"nothing to correct": {},
+		"invalid name":       {FirstName: "J4ne"},
+		"too young":          {BirthDate: time.Now().AddDate(-10, 0, 0)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := parent.CorrectDetails(details)
+			assert.True(t, errorswrapper.IsValidationError(err), "expected a validation error, got %v", err)
+			assert.Equal(t, "Jane Roe", parent.FullName())
+			assert.Equal(t, corrected, parent.BirthDate())
+		})
+	}
+}
+
+func TestChildCorrectDetails_NameHistory(t *testing.T) {
+	birthDate := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	child, err := NewChild(generateTestUUID(), "Jimmy", "Doe", birthDate, nil)
+	require.NoError(t, err)
+	require.NoError(t, child.ChangeName(NameChange{FirstName: "Jmaes", LastName: "Doe", EffectiveDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Reason: NameChangeLegal}))
+
+	// The latest name is corrected, and the name from birth moves with the birth date
+	corrected := time.Date(2010, 1, 2, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, child.CorrectDetails(MemberDetails{FirstName: "James", BirthDate: corrected}))
+	history := child.NameHistory()
+	require.Len(t, history, 2)
+	assert.Equal(t, NameChange{FirstName: "Jimmy", LastName: "Doe", EffectiveDate: corrected}, history[0])
+	assert.Equal(t, "James", history[1].FirstName)
+
+	// A birth date after a name change is rejected
+	err = child.CorrectDetails(MemberDetails{BirthDate: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)})
+	assert.True(t, errorswrapper.IsValidationError(err))
+	assert.Equal(t, corrected, child.BirthDate())
+}
+
+func TestFamilyUpdateMembers(t *testing.T) {
+	parent, err := NewParent(generateTestUUID(), "Jane", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	childBirthDate := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	child, err := NewChild(generateTestUUID(), "Jimmy", "Doe", childBirthDate, nil)
+	require.NoError(t, err)
+	fam, err := NewFamily(generateTestUUID(), Single, []*Parent{parent}, []*Child{child})
+	require.NoError(t, err)
+
+	require.NoError(t, fam.UpdateParent(parent.ID(), MemberDetails{LastName: "Smith"}))
+	require.NoError(t, fam.UpdateChild(child.ID(), MemberDetails{LastName: "Smith"}))
+	dto := fam.ToDTO()
+	assert.Equal(t, "Smith", dto.Parents[0].LastName)
+	assert.Equal(t, "Smith", dto.Children[0].LastName)
+
+	// The family must still be valid with the corrected member
+	err = fam.UpdateChild(child.ID(), MemberDetails{BirthDate: time.Date(1979, 1, 1, 0, 0, 0, 0, time.UTC)})
+	assert.True(t, errorswrapper.IsValidationError(err))
+	assert.Equal(t, childBirthDate, fam.Children()[0].BirthDate(), "a rejected correction leaves the child unchanged")
+	err = fam.UpdateParent(parent.ID(), MemberDetails{BirthDate: time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC)})
+	assert.True(t, errorswrapper.IsValidationError(err))
+
+	// Parents and children are looked up separately
+	assert.True(t, errorswrapper.IsNotFoundError(fam.UpdateParent(child.ID(), MemberDetails{LastName: "Roe"})))
+	assert.True(t, errorswrapper.IsNotFoundError(fam.UpdateChild(parent.ID(), MemberDetails{LastName: "Roe"})))
+}
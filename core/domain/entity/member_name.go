@@ -0,0 +1,286 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/identificationwrapper"
+)
+
+// NameChangeReason classifies why a family member's name changed.
+type NameChangeReason string
+
+const (
+	// NameChangeMarriage is a name taken on marriage
+	NameChangeMarriage NameChangeReason = "MARRIAGE"
+
+	// NameChangeDivorce is a name taken on divorce, usually a previous name
+	NameChangeDivorce NameChangeReason = "DIVORCE"
+
+	// NameChangeLegal is a name change made by legal process, such as a deed poll or court order
+	NameChangeLegal NameChangeReason = "LEGAL"
+
+	// NameChangeOther is a name change for any other reason
+	NameChangeOther NameChangeReason = "OTHER"
+)
+
+// IsValid reports whether the reason is one of the defined reasons
+func (r NameChangeReason) IsValid() bool {
+	switch r {
+	case NameChangeMarriage, NameChangeDivorce, NameChangeLegal, NameChangeOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// NameChange is a name of a family member and the date from which it applies.
+//
+// Name changes are value objects: they have no identity of their own and are
+// recorded in a member's name history in the order in which they took effect.
+type NameChange struct {
+	FirstName     string           // First name from the effective date
+	LastName      string           // Last name from the effective date
+	EffectiveDate time.Time        // Date from which the name applies
+	Reason        NameChangeReason // Reason for the change (empty for the name from birth)
+}
+
+// FullName returns the first name and last name of the name change
+func (n NameChange) FullName() string {
+	return n.FirstName + " " + n.LastName
+}
+
+// NameHistory is the chronological list of the names of a family member.
+//
+// The first entry is the member's name from birth, recorded when the name first
+// changes; each later entry is a name taken on its effective date. A member whose
+// name never changed has no history.
+type NameHistory []NameChange
+
+// memberNamePattern restricts names to letters, spaces, and hyphens, as for the
+// names of parents and children
+var memberNamePattern = regexp.MustCompile(`^[a-zA-Z\s-]+$`)
+
+// validateMemberName validates a name with the same rules as the names of parents and children
+func validateMemberName(name, field string) (string, error) {
+	nameVO, err := identificationwrapper.NewName(name)
+	if err != nil {
+		return "", errorswrapper.NewValidationError("invalid "+field+": "+err.Error(), field, err)
+	}
+	if len(nameVO.String()) < 2 {
+		return "", errorswrapper.NewValidationError(field+" must be at least 2 characters long", field, nil)
+	}
+	if !memberNamePattern.MatchString(nameVO.String()) {
+		return "", errorswrapper.NewValidationError(field+" must contain only letters, spaces, and hyphens", field, nil)
+	}
+	return nameVO.String(), nil
+}
+
+// NewPreferredName validates a preferred name, such as a nickname. An empty name
+// means that the member has no preferred name.
+func NewPreferredName(name string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", nil
+	}
+	return validateMemberName(name, "PreferredName")
+}
+
+// NewNameHistory validates the name changes of a member born on birthDate, who died
+// on deathDate if it is not nil, and returns them as a NameHistory.
+//
+// Effective dates are truncated to the day in UTC. They must be in strictly
+// increasing order, no earlier than the birth date, and no later than today or the
+// death date.
+//
+// Returns:
+//   - The validated NameHistory (nil if changes is empty)
+//   - A ValidationError if a name, reason, or effective date is invalid
+func NewNameHistory(changes []NameChange, birthDate time.Time, deathDate *time.Time) (NameHistory, error) {
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	birth := dateOnly(birthDate)
+	today := dateOnly(time.Now())
+
+	history := make(NameHistory, 0, len(changes))
+	for i, change := range changes {
+		firstName, err := validateMemberName(change.FirstName, "NameHistory.FirstName")
+		if err != nil {
+			return nil, err
+		}
+		lastName, err := validateMemberName(change.LastName, "NameHistory.LastName")
+		if err != nil {
+			return nil, err
+		}
+		if change.Reason != "" && !change.Reason.IsValid() {
+			return nil, errorswrapper.NewValidationError(fmt.Sprintf("invalid name change reason %q", change.Reason), "NameHistory.Reason", nil)
+		}
+
+		effective := dateOnly(change.EffectiveDate)
+		if effective.Before(birth) {
+			return nil, errorswrapper.NewValidationError("name change cannot take effect before the birth date", "NameHistory.EffectiveDate", nil)
+		}
+		if effective.After(today) {
+			return nil, errorswrapper.NewValidationError("name change cannot take effect in the future", "NameHistory.EffectiveDate", nil)
+		}
+		if deathDate != nil && effective.After(dateOnly(*deathDate)) {
+			return nil, errorswrapper.NewValidationError("name change cannot take effect after the death date", "NameHistory.EffectiveDate", nil)
+		}
+		if i > 0 && !effective.After(history[i-1].EffectiveDate) {
+			return nil, errorswrapper.NewValidationError("name changes must take effect in chronological order on different dates", "NameHistory.EffectiveDate", nil)
+		}
+
+		history = append(history, NameChange{
+			FirstName:     firstName,
+			LastName:      lastName,
+			EffectiveDate: effective,
+			Reason:        change.Reason,
+		})
+	}
+	return history, nil
+}
+
+// Copy returns a copy of the name history, or nil if it is empty.
+// Entities return copies to prevent modification of their internal state.
+func (h NameHistory) Copy() []NameChange {
+	if len(h) == 0 {
+		return nil
+	}
+	result := make([]NameChange, len(h))
+	copy(result, h)
+	return result
+}
+
+// AsOf returns the name that applied on the given date and whether the history
+// records one. It reports false if the history is empty or starts after the date.
+func (h NameHistory) AsOf(date time.Time) (NameChange, bool) {
+	day := dateOnly(date)
+	for i := len(h) - 1; i >= 0; i-- {
+		if !h[i].EffectiveDate.After(day) {
+			return h[i], true
+		}
+	}
+	return NameChange{}, false
+}
+
+// withChange returns the history with a name change appended. If the history is
+// empty, the current name is first recorded as the name from birth.
+func (h NameHistory) withChange(change NameChange, firstName, lastName string, birthDate time.Time, deathDate *time.Time) (NameHistory, error) {
+	changes := h.Copy()
+	if len(changes) == 0 {
+		changes = []NameChange{{FirstName: firstName, LastName: lastName, EffectiveDate: birthDate}}
+	}
+	if change.Reason == "" {
+		change.Reason = NameChangeOther
+	}
+	return NewNameHistory(append(changes, change), birthDate, deathDate)
+}
+
+// nameAsOf returns the name of a member that applied on the given date
+func nameAsOf(history NameHistory, firstName, lastName string, birthDate, date time.Time) (NameChange, error) {
+	if dateOnly(date).Before(dateOnly(birthDate)) {
+		return NameChange{}, errorswrapper.NewValidationError("date cannot be before the birth date", "Date", nil)
+	}
+	if len(history) == 0 {
+		return NameChange{FirstName: firstName, LastName: lastName, EffectiveDate: dateOnly(birthDate)}, nil
+	}
+	if name, ok := history.AsOf(date); ok {
+		return name, nil
+	}
+	// The history starts after the birth date, so the earliest recorded name is the best known
+	return history[0], nil
+}
+
+// checkCurrentName validates that the latest entry of a name history is the current name
+func checkCurrentName(history NameHistory, firstName, lastName string) error {
+	if len(history) == 0 {
+		return nil
+	}
+	latest := history[len(history)-1]
+	if latest.FirstName != firstName || latest.LastName != lastName {
+		return errorswrapper.NewValidationError("the latest name in the name history must be the current name", "NameHistory", nil)
+	}
+	return nil
+}
+
+// dateOnly truncates a time to midnight UTC of its date
+func dateOnly(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// namedMember is a family member whose names can change
+type namedMember interface {
+	ChangeName(change NameChange) error
+	SetPreferredName(name string) error
+	NameAsOf(date time.Time) (NameChange, error)
+}
+
+// findNamedMember returns the parent or child with the given ID
+func (f *Family) findNamedMember(memberID string) (namedMember, error) {
+	for _, p := range f.parents {
+		if p.ID() == memberID {
+			return p, nil
+		}
+	}
+	for _, c := range f.children {
+		if c.ID() == memberID {
+			return c, nil
+		}
+	}
+	return nil, errorswrapper.NewNotFoundError("Member", memberID, nil)
+}
+
+// ChangeMemberName records a name change of a parent or child of the family.
+//
+// Parameters:
+//   - memberID: The ID of the parent or child whose name changed
+//   - change: The new name and the date from which it applies
+//
+// Returns:
+//   - nil if the name change was recorded
+//   - NotFoundError if no parent or child with the given ID exists in the family
+//   - ValidationError if the name or effective date is invalid
+func (f *Family) ChangeMemberName(memberID string, change NameChange) error {
+	member, err := f.findNamedMember(memberID)
+	if err != nil {
+		return err
+	}
+	return member.ChangeName(change)
+}
+
+// SetMemberPreferredName sets or, if name is empty, clears the preferred name of a
+// parent or child of the family.
+//
+// Returns:
+//   - nil if the preferred name was set
+//   - NotFoundError if no parent or child with the given ID exists in the family
+//   - ValidationError if the name is invalid
+func (f *Family) SetMemberPreferredName(memberID, name string) error {
+	member, err := f.findNamedMember(memberID)
+	if err != nil {
+		return err
+	}
+	return member.SetPreferredName(name)
+}
+
+// MemberNameAsOf returns the name of a parent or child of the family that applied on
+// the given date.
+//
+// Returns:
+//   - The name and the date from which it applied
+//   - NotFoundError if no parent or child with the given ID exists in the family
+//   - ValidationError if the date is before the member's birth date
+func (f *Family) MemberNameAsOf(memberID string, date time.Time) (NameChange, error) {
+	member, err := f.findNamedMember(memberID)
+	if err != nil {
+		return NameChange{}, err
+	}
+	return member.NameAsOf(date)
+}
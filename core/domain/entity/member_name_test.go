@@ -0,0 +1,142 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNameHistory(t *testing.T) {
+	birthDate := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	deathDate := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	history, err := NewNameHistory(nil, birthDate, nil)
+	require.NoError(t, err)
+	assert.Nil(t, history)
+
+	history, err = NewNameHistory([]NameChange{
+		{FirstName: "Jane", LastName: "Doe", EffectiveDate: birthDate},
+		{FirstName: "Jane", LastName: "Smith", EffectiveDate: time.Date(2005, 6, 1, 15, 30, 0, 0, time.UTC), Reason: NameChangeMarriage},
+	}, birthDate, &deathDate)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, time.Date(2005, 6, 1, 0, 0, 0, 0, time.UTC), history[1].EffectiveDate, "effective dates are truncated to the day")
+
+	invalid := map[string][]NameChange{
+		"invalid name":        {{FirstName: "J4ne", LastName: "Doe", EffectiveDate: birthDate}},
+		"invalid reason":      {{FirstName: "Jane", LastName: "Doe", EffectiveDate: birthDate, Reason: "WHIM"}},
+		"before birth":        {{FirstName: "Jane", LastName: "Doe", EffectiveDate: birthDate.AddDate(0, 0, -1)}},
+		"after death":         {{FirstName: "Jane", LastName: "Doe", EffectiveDate: deathDate.AddDate(0, 0, 1)}},
+		"not chronological":   {{FirstName: "Jane", LastName: "Doe", EffectiveDate: birthDate.AddDate(1, 0, 0)}, {FirstName: "Jane", LastName: "Roe", EffectiveDate: birthDate}},
+		"same effective date": {{FirstName: "Jane", LastName: "Doe", EffectiveDate: birthDate}, {FirstName: "Jane", LastName: "Roe", EffectiveDate: birthDate}},
+	}
+	for name, changes := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewNameHistory(changes, birthDate, &deathDate)
+			assert.True(t, errorswrapper.IsValidationError(err), "expected a validation error, got %v", err)
+		})
+	}
+
+	_, err = NewNameHistory([]NameChange{{FirstName: "Jane", LastName: "Doe", EffectiveDate: time.Now().AddDate(0, 0, 2)}}, birthDate, nil)
+	assert.Error(t, err, "name changes cannot take effect in the future")
+}
+
+func TestParentChangeName(t *testing.T) {
+	birthDate := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	parent, err := NewParent(generateTestUUID(), "Jane", "Doe", birthDate, nil)
+	require.NoError(t, err)
+
+	// A parent whose name never changed has no history, and its current name always applied
+	assert.Nil(t, parent.NameHistory())
+	name, err := parent.NameAsOf(time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", name.FullName())
+
+	marriage := time.Date(2005, 6, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, parent.ChangeName(NameChange{FirstName: "Jane", LastName: "Smith", EffectiveDate: marriage, Reason: NameChangeMarriage}))
+	assert.Equal(t, "Smith", parent.LastName())
+
+	history := parent.NameHistory()
+	require.Len(t, history, 2)
+	assert.Equal(t, NameChange{FirstName: "Jane", LastName: "Doe", EffectiveDate: birthDate}, history[0], "the first change records the name from birth")
+
+	// A reason defaults to OTHER
+	legal := time.Date(2010, 3, 15, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, parent.ChangeName(NameChange{FirstName: "Janet", LastName: "Smith", EffectiveDate: legal}))
+	assert.Equal(t, NameChangeOther, parent.NameHistory()[2].Reason)
+
+	for date, want := range map[time.Time]string{
+		birthDate:                  "Jane Doe",
+		marriage.AddDate(0, 0, -1): "Jane Doe",
+		marriage:                   "Jane Smith",
+		legal.AddDate(0, 0, -1):    "Jane Smith",
+		time.Now():                 "Janet Smith",
+	} {
+		name, err := parent.NameAsOf(date)
+		require.NoError(t, err)
+		assert.Equal(t, want, name.FullName(), "name as of %s", date)
+	}
+
+	_, err = parent.NameAsOf(birthDate.AddDate(0, 0, -1))
+	assert.Error(t, err, "there is no name before the birth date")
+
+	// Changes must be recorded in chronological order
+	err = parent.ChangeName(NameChange{FirstName: "Jane", LastName: "Doe", EffectiveDate: marriage})
+	assert.Error(t, err)
+	assert.Equal(t, "Janet", parent.FirstName(), "a rejected change leaves the name unchanged")
+}
+
+func TestMemberNamesRoundTripThroughDTO(t *testing.T) {
+	birthDate := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	child, err := NewChild(generateTestUUID(), "Jimmy", "Doe", birthDate, nil)
+	require.NoError(t, err)
+	require.NoError(t, child.SetPreferredName("Jim"))
+	require.NoError(t, child.ChangeName(NameChange{FirstName: "James", LastName: "Doe", EffectiveDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Reason: NameChangeLegal}))
+
+	restored, err := ChildFromDTO(child.ToDTO())
+	require.NoError(t, err)
+	assert.Equal(t, "Jim", restored.PreferredName())
+	assert.Equal(t, child.NameHistory(), restored.NameHistory())
+
+	// The latest name in the history must be the current name
+	dto := child.ToDTO()
+	dto.FirstName = "Jimmy"
+	_, err = ChildFromDTO(dto)
+	assert.Error(t, err)
+
+	assert.Error(t, child.SetPreferredName("J"), "preferred names follow the name rules")
+	require.NoError(t, child.SetPreferredName(""))
+	assert.Empty(t, child.PreferredName())
+}
+
+func TestFamilyMemberNames(t *testing.T) {
+	birthDate := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	parent, err := NewParent(generateTestUUID(), "Jane", "Doe", birthDate, nil)
+	require.NoError(t, err)
+	fam, err := NewFamily(generateTestUUID(), Single, []*Parent{parent}, nil)
+	require.NoError(t, err)
+
+	marriage := time.Date(2005, 6, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, fam.ChangeMemberName(parent.ID(), NameChange{FirstName: "Jane", LastName: "Smith", EffectiveDate: marriage, Reason: NameChangeMarriage}))
+	require.NoError(t, fam.SetMemberPreferredName(parent.ID(), "Janie"))
+
+	dto := fam.ToDTO()
+	assert.Equal(t, "Smith", dto.Parents[0].LastName)
+	assert.Equal(t, "Janie", dto.Parents[0].PreferredName)
+	assert.Len(t, dto.Parents[0].NameHistory, 2)
+
+	name, err := fam.MemberNameAsOf(parent.ID(), time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", name.FullName())
+
+	missing := generateTestUUID()
+	assert.True(t, errorswrapper.IsNotFoundError(fam.ChangeMemberName(missing, NameChange{FirstName: "Jo", LastName: "Doe", EffectiveDate: marriage})))
+	assert.True(t, errorswrapper.IsNotFoundError(fam.SetMemberPreferredName(missing, "Jo")))
+	_, err = fam.MemberNameAsOf(missing, marriage)
+	assert.True(t, errorswrapper.IsNotFoundError(err))
+}
@@ -7,6 +7,7 @@
 	"regexp"
 	"time"
 
+	"github.com/abitofhelp/family-service/core/domain/chronology"
 	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/identificationwrapper"
@@ -26,11 +27,14 @@
 // All fields are private to enforce that changes must go through methods that can
 // validate business rules, maintaining data integrity.
 type Parent struct {
-	id        identificationwrapper.ID            // Unique identifier for the parent
-	firstName identificationwrapper.Name          // First name of the parent
-	lastName  identificationwrapper.Name          // Last name of the parent
-	birthDate identificationwrapper.DateOfBirth   // Birth date of the parent
-	deathDate *identificationwrapper.DateOfDeath  // Death date of the parent (nil if alive)
+	id            identificationwrapper.ID           // Unique identifier for the parent
+	firstName     identificationwrapper.Name         // First name of the parent
+	lastName      identificationwrapper.Name         // Last name of the parent
+	birthDate     identificationwrapper.DateOfBirth  // Birth date of the parent
+	deathDate     *identificationwrapper.DateOfDeath // Death date of the parent (nil if alive)
+	externalIDs   ExternalIDs                        // IDs of the parent in external systems
+	preferredName string                             // Preferred name, such as a nickname (empty if none)
+	nameHistory   NameHistory                        // Names of the parent over time (nil if the name never changed)
 }
 
 // NewParent creates a new Parent entity with validation.
@@ -84,9 +88,9 @@ func NewParent(id, firstName, lastName string, birthDate time.Time, deathDate *t
 		return nil, errorswrapper.NewValidationError("invalid LastName: "+err.Error(), "LastName", err)
 	}
 
-	// Create BirthDate value object
-	year, month, day := birthDate.Date()
-	birthDateVO, err := identificationwrapper.NewDateOfBirth(year, int(month), day)
+	// Create BirthDate value object from the calendar date, in the time zone it was given in
+	birth := DateOf(birthDate)
+	birthDateVO, err := identificationwrapper.NewDateOfBirth(birth.Year(), int(birth.Month()), birth.Day())
 	if err != nil {
 		return nil, errorswrapper.NewValidationError("invalid BirthDate: "+err.Error(), "BirthDate", err)
 	}
@@ -94,8 +98,8 @@ func NewParent(id, firstName, lastName string, birthDate time.Time, deathDate *t
 	// Create DeathDate value object if provided
 	var deathDateVO *identificationwrapper.DateOfDeath
 	if deathDate != nil {
-		year, month, day := deathDate.Date()
-		dod, err := identificationwrapper.NewDateOfDeath(year, int(month), day)
+		death := DateOf(*deathDate)
+		dod, err := identificationwrapper.NewDateOfDeath(death.Year(), int(death.Month()), death.Day())
 		if err != nil {
 			return nil, errorswrapper.NewValidationError("invalid DeathDate: "+err.Error(), "DeathDate", err)
 		}
@@ -171,14 +175,7 @@ func (p *Parent) Validate() error {
 
 	// Enhanced validation: Validate minimum age for a parent (18 years)
 	minAge := 18
-	now := time.Now()
-	birthDate := p.birthDate.Date()
-	age := now.Year() - birthDate.Year()
-
-	// Adjust age if birthday hasn't occurred yet this year
-	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
-		age--
-	}
+	age := chronology.Age(p.birthDate.Date(), time.Now())
 
 	if age < minAge {
 		result.AddError(fmt.Sprintf("parent does not meet minimum age requirement (%d years)", minAge), "BirthDate")
@@ -285,14 +282,14 @@ func (p *Parent) MarkDeceased(deathDate time.Time) error {
 		return domainerrors.NewParentAlreadyDeceasedError("parent is already marked as deceased", nil)
 	}
 
-	// Validate death date is after birth date
-	if !deathDate.After(p.birthDate.Date()) {
+	// Validate death date is after birth date, comparing calendar dates
+	death := DateOf(deathDate)
+	if !death.After(DateOf(p.birthDate.Date())) {
 		return errorswrapper.NewValidationError("death date must be after birth date", "DeathDate", nil)
 	}
 
 	// Create DateOfDeath value object
-	year, month, day := deathDate.Date()
-	dod, err := identificationwrapper.NewDateOfDeath(year, int(month), day)
+	dod, err := identificationwrapper.NewDateOfDeath(death.Year(), int(death.Month()), death.Day())
 	if err != nil {
 		return errorswrapper.NewValidationError("invalid death date: "+err.Error(), "DeathDate", err)
 	}
@@ -301,6 +298,164 @@ func (p *Parent) MarkDeceased(deathDate time.Time) error {
 	return nil
 }
 
+// ExternalIDs returns the parent's IDs in external systems, keyed by system name.
+//
+// It returns a copy so that callers cannot modify the parent's internal state.
+// The result is nil if the parent has no external IDs.
+func (p *Parent) ExternalIDs() map[string]string {
+	return p.externalIDs.Copy()
+}
+
+// SetExternalIDs replaces the parent's external IDs after validating them.
+//
+// Uniqueness of each external ID per system is enforced by the repository when
+// the family is saved, because it spans families.
+//
+// Parameters:
+//   - ids: Map of external system name to the parent's ID in that system (nil clears them)
+//
+// Returns:
+//   - nil if the external IDs were set
+//   - ValidationError if a system name or ID is invalid
+func (p *Parent) SetExternalIDs(ids map[string]string) error {
+	externalIDs, err := NewExternalIDs(ids)
+	if err != nil {
+		return err
+	}
+	p.externalIDs = externalIDs
+	return nil
+}
+
+// PreferredName returns the name the parent prefers to be called, such as a
+// nickname, or an empty string if the parent has none.
+func (p *Parent) PreferredName() string {
+	return p.preferredName
+}
+
+// SetPreferredName sets the parent's preferred name after validating it.
+//
+// Parameters:
+//   - name: The preferred name (empty clears it)
+//
+// Returns:
+//   - nil if the preferred name was set
+//   - ValidationError if the name is invalid
+func (p *Parent) SetPreferredName(name string) error {
+	preferredName, err := NewPreferredName(name)
+	if err != nil {
+		return err
+	}
+	p.preferredName = preferredName
+	return nil
+}
+
+// NameHistory returns the parent's names over time, oldest first.
+//
+// It returns a copy so that callers cannot modify the parent's internal state.
+// The result is nil if the parent's name never changed.
+func (p *Parent) NameHistory() []NameChange {
+	return p.nameHistory.Copy()
+}
+
+// SetNameHistory replaces the parent's name history after validating it.
+// It is used to restore the history of a stored parent; ChangeName records new changes.
+//
+// Parameters:
+//   - changes: The names of the parent over time, oldest first (nil clears the history)
+//
+// Returns:
+//   - nil if the name history was set
+//   - ValidationError if an entry is invalid or the latest name is not the current name
+func (p *Parent) SetNameHistory(changes []NameChange) error {
+	history, err := NewNameHistory(changes, p.BirthDate(), p.DeathDate())
+	if err != nil {
+		return err
+	}
+	if err := checkCurrentName(history, p.FirstName(), p.LastName()); err != nil {
+		return err
+	}
+	p.nameHistory = history
+	return nil
+}
+
+// ChangeName records a change of the parent's name, for example on marriage or by
+// legal process, and makes the new name the current name.
+//
+// The first change also records the previous name as the name from birth. Changes
+// must be recorded in chronological order and cannot take effect in the future.
+//
+// Parameters:
+//   - change: The new name and the date from which it applies
+//
+// Returns:
+//   - nil if the name change was recorded
+//   - ValidationError if the name, reason, or effective date is invalid
+func (p *Parent) ChangeName(change NameChange) error {
+	history, err := p.nameHistory.withChange(change, p.FirstName(), p.LastName(), p.BirthDate(), p.DeathDate())
+	if err != nil {
+		return err
+	}
+
+	latest := history[len(history)-1]
+	firstName, err := identificationwrapper.NewName(latest.FirstName)
+	if err != nil {
+		return errorswrapper.NewValidationError("invalid FirstName: "+err.Error(), "FirstName", err)
+	}
+	lastName, err := identificationwrapper.NewName(latest.LastName)
+	if err != nil {
+		return errorswrapper.NewValidationError("invalid LastName: "+err.Error(), "LastName", err)
+	}
+
+	p.firstName = firstName
+	p.lastName = lastName
+	p.nameHistory = history
+	return nil
+}
+
+// CorrectDetails corrects the recorded first name, last name, or birth date of the parent,
+// for example to fix a typo made when the parent was added. See MemberDetails.
+//
+// Parameters:
+//   - details: The corrected details
+//
+// Returns:
+//   - nil if the parent was corrected
+//   - ValidationError if no detail is corrected, a detail is invalid, or the corrected
+//     parent violates a business rule; the parent is then not changed
+func (p *Parent) CorrectDetails(details MemberDetails) error {
+	deathDate := p.DeathDate()
+	corrected, err := details.apply(correctedDetails{
+		firstName:   p.firstName,
+		lastName:    p.lastName,
+		birthDate:   p.birthDate,
+		nameHistory: p.nameHistory,
+	}, deathDate)
+	if err != nil {
+		return err
+	}
+
+	candidate := *p
+	candidate.firstName = corrected.firstName
+	candidate.lastName = corrected.lastName
+	candidate.birthDate = corrected.birthDate
+	candidate.nameHistory = corrected.nameHistory
+	if err := candidate.Validate(); err != nil {
+		return err
+	}
+
+	*p = candidate
+	return nil
+}
+
+// NameAsOf returns the parent's name that applied on the given date.
+//
+// Returns:
+//   - The name and the date from which it applied
+//   - ValidationError if the date is before the parent's birth date
+func (p *Parent) NameAsOf(date time.Time) (NameChange, error) {
+	return nameAsOf(p.nameHistory, p.FirstName(), p.LastName(), p.BirthDate(), date)
+}
+
 // Equals checks if two parents are the same based on ID.
 //
 // In Domain-Driven Design, entities are distinguished by their identity, not their
@@ -345,11 +500,14 @@ func (p *Parent) ToDTO() ParentDTO {
 	}
 
 	dto := ParentDTO{
-		ID:        string(p.id),
-		FirstName: p.firstName.String(),
-		LastName:  p.lastName.String(),
-		BirthDate: p.birthDate.Date(),
-		DeathDate: deathDate,
+		ID:            string(p.id),
+		FirstName:     p.firstName.String(),
+		LastName:      p.lastName.String(),
+		BirthDate:     p.birthDate.Date(),
+		DeathDate:     deathDate,
+		ExternalIDs:   p.externalIDs.Copy(),
+		PreferredName: p.preferredName,
+		NameHistory:   p.nameHistory.Copy(),
 	}
 	return dto
 }
@@ -364,11 +522,14 @@ func (p *Parent) ToDTO() ParentDTO {
 // the domain model and external interfaces, preventing domain logic
 // from leaking into other layers.
 type ParentDTO struct {
-	ID        string     // Unique identifier for the parent
-	FirstName string     // First name of the parent
-	LastName  string     // Last name of the parent
-	BirthDate time.Time  // Birth date of the parent
-	DeathDate *time.Time // Death date of the parent (nil if alive)
+	ID            string            // Unique identifier for the parent
+	FirstName     string            // First name of the parent
+	LastName      string            // Last name of the parent
+	BirthDate     time.Time         // Birth date of the parent
+	DeathDate     *time.Time        // Death date of the parent (nil if alive)
+	ExternalIDs   map[string]string // IDs of the parent in external systems (system -> ID)
+	PreferredName string            // Preferred name of the parent, such as a nickname (empty if none)
+	NameHistory   []NameChange      // Names of the parent over time, oldest first (nil if the name never changed)
 }
 
 // ParentFromDTO creates a Parent entity from a data transfer object.
@@ -387,5 +548,22 @@ type ParentDTO struct {
 //   - A pointer to the new Parent if valid
 //   - An error if validation fails
 func ParentFromDTO(dto ParentDTO) (*Parent, error) {
-	return NewParent(dto.ID, dto.FirstName, dto.LastName, dto.BirthDate, dto.DeathDate)
+	p, err := NewParent(dto.ID, dto.FirstName, dto.LastName, dto.BirthDate, dto.DeathDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.SetExternalIDs(dto.ExternalIDs); err != nil {
+		return nil, err
+	}
+
+	if err := p.SetPreferredName(dto.PreferredName); err != nil {
+		return nil, err
+	}
+
+	if err := p.SetNameHistory(dto.NameHistory); err != nil {
+		return nil, err
+	}
+
+	return p, nil
 }
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/identificationwrapper"
+)
+
+// MaxQuarantineReasonLength is the maximum length of the reason a family is quarantined
+const MaxQuarantineReasonLength = 500
+
+// Quarantine records that an administrator froze a family, for example during a fraud
+// investigation. While a family is quarantined, only administrators can read or change it.
+//
+// This is not the quarantine of the integrity check, which moves stored families that
+// cannot be decoded out of the families tables: a quarantined family stays where it is,
+// unchanged, and is released by removing its quarantine.
+type Quarantine struct {
+	FamilyID      string    // ID of the quarantined family
+	Reason        string    // Why the family was quarantined
+	QuarantinedBy string    // ID of the administrator who quarantined the family (empty if unknown)
+	QuarantinedAt time.Time // When the family was quarantined
+}
+
+// NewQuarantine validates the quarantine of a family.
+//
+// The reason is trimmed, and the time is converted to UTC and truncated to the second,
+// the precision with which quarantines are stored.
+//
+// Returns:
+//   - The validated Quarantine
+//   - A ValidationError if the family ID, reason, or time is invalid
+func NewQuarantine(familyID, reason, quarantinedBy string, quarantinedAt time.Time) (Quarantine, error) {
+	idVO, err := identificationwrapper.NewIDFromString(familyID)
+	if err != nil {
+		return Quarantine{}, errorswrapper.NewValidationError("invalid FamilyID: "+err.Error(), "Quarantine.FamilyID", err)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return Quarantine{}, errorswrapper.NewValidationError("quarantine reason is required", "Quarantine.Reason", nil)
+	}
+	if len(reason) > MaxQuarantineReasonLength {
+		return Quarantine{}, errorswrapper.NewValidationError("quarantine reason is too long", "Quarantine.Reason", nil)
+	}
+	if quarantinedAt.IsZero() {
+		return Quarantine{}, errorswrapper.NewValidationError("quarantine time is required", "Quarantine.QuarantinedAt", nil)
+	}
+
+	return Quarantine{
+		FamilyID:      idVO.String(),
+		Reason:        reason,
+		QuarantinedBy: quarantinedBy,
+		QuarantinedAt: quarantinedAt.UTC().Truncate(time.Second),
+	}, nil
+}
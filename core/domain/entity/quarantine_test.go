@@ -0,0 +1,40 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package entity
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewQuarantine(t *testing.T) {
+	familyID := generateTestUUID()
+	at := time.Date(2025, time.March, 3, 9, 0, 0, 700, time.FixedZone("EST", -5*60*60))
+
+	q, err := NewQuarantine(familyID, "  fraud investigation 42  ", "admin-1", at)
+	require.NoError(t, err)
+	assert.Equal(t, familyID, q.FamilyID)
+	assert.Equal(t, "fraud investigation 42", q.Reason)
+	assert.Equal(t, "admin-1", q.QuarantinedBy)
+	assert.Equal(t, time.Date(2025, time.March, 3, 14, 0, 0, 0, time.UTC), q.QuarantinedAt)
+
+	for name, tc := range map[string]struct {
+		familyID string
+		reason   string
+		at       time.Time
+	}{
+		"invalid family":  {"", "fraud", at},
+		"no reason":       {familyID, "   ", at},
+		"reason too long": {familyID, strings.Repeat("x", MaxQuarantineReasonLength+1), at},
+		"no time":         {familyID, "fraud", time.Time{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewQuarantine(tc.familyID, tc.reason, "", tc.at)
+			assert.Error(t, err)
+		})
+	}
+}
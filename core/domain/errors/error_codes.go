@@ -3,6 +3,11 @@
 // Package errors provides domain-specific error codes and error handling utilities.
 package errors
 
+import (
+	"errors"
+	"time"
+)
+
 // Domain-specific error codes for family-related errors
 const (
 	// Family structure errors
@@ -17,12 +22,33 @@
 	FamilyDivorceRequiresTwoCode = "FAMILY_DIVORCE_REQUIRES_TWO_PARENTS"
 	FamilyCreateFailedCode       = "FAMILY_CREATE_FAILED"
 	FamilyStatusUpdateFailedCode = "FAMILY_STATUS_UPDATE_FAILED"
+	FamilyInvalidTransitionCode  = "FAMILY_INVALID_STATUS_TRANSITION"
 
 	// Parent-related errors
 	ParentAlreadyDeceasedCode = "PARENT_ALREADY_DECEASED"
 
 	// Child-related errors
 	ChildAlreadyDeceasedCode = "CHILD_ALREADY_DECEASED"
+
+	// Access errors
+	FamilyQuarantinedCode = "FAMILY_QUARANTINED"
+
+	// Query errors
+	ResultTooLargeCode = "RESULT_TOO_LARGE"
+
+	// Concurrency errors
+	FamilyLockedCode        = "FAMILY_LOCKED"
+	ConcurrencyConflictCode = "CONCURRENCY_CONFLICT"
+
+	// Duplicate detection errors
+	FamilyDuplicateCode = "FAMILY_DUPLICATE"
+
+	// Deadline errors
+	MutationTimedOutCode = "MUTATION_TIMED_OUT"
+
+	// Availability errors
+	ThrottledCode           = "THROTTLED"
+	UpstreamUnavailableCode = "UPSTREAM_UNAVAILABLE"
 )
 
 // ParentAlreadyDeceasedError represents an error when a parent is already marked as deceased
@@ -72,3 +98,191 @@ func NewFamilyStatusUpdateFailedError(message string, cause error) error {
 		},
 	}
 }
+
+// FamilyInvalidTransitionError represents an error when a family cannot change to the requested status
+type FamilyInvalidTransitionError struct {
+	baseError
+}
+
+// NewFamilyInvalidTransitionError creates a new FamilyInvalidTransitionError
+func NewFamilyInvalidTransitionError(message string, cause error) error {
+	return &FamilyInvalidTransitionError{
+		baseError: baseError{
+			code:    FamilyInvalidTransitionCode,
+			message: message,
+			cause:   cause,
+		},
+	}
+}
+
+// FamilyQuarantinedError represents an error when a family is quarantined and cannot be
+// read or changed by the caller
+type FamilyQuarantinedError struct {
+	baseError
+}
+
+// NewFamilyQuarantinedError creates a new FamilyQuarantinedError
+func NewFamilyQuarantinedError(message string, cause error) error {
+	return &FamilyQuarantinedError{
+		baseError: baseError{
+			code:    FamilyQuarantinedCode,
+			message: message,
+			cause:   cause,
+		},
+	}
+}
+
+// ResultTooLargeError represents an error when the result of a query exceeds the memory
+// budget for assembling results, and the query must be paginated or narrowed
+type ResultTooLargeError struct {
+	baseError
+}
+
+// NewResultTooLargeError creates a new ResultTooLargeError
+func NewResultTooLargeError(message string, cause error) error {
+	return &ResultTooLargeError{
+		baseError: baseError{
+			code:    ResultTooLargeCode,
+			message: message,
+			cause:   cause,
+		},
+	}
+}
+
+// FamilyLockedError represents an error when a family is being changed by another operation
+// and the lock of the family could not be acquired in time
+type FamilyLockedError struct {
+	baseError
+}
+
+// NewFamilyLockedError creates a new FamilyLockedError
+func NewFamilyLockedError(message string, cause error) error {
+	return &FamilyLockedError{
+		baseError: baseError{
+			code:    FamilyLockedCode,
+			message: message,
+			cause:   cause,
+		},
+	}
+}
+
+// ConcurrencyError represents an error when a family was saved with a version that is no
+// longer the stored version, because another operation saved the family after it was read.
+// The operation may be retried at once, with the family read again.
+type ConcurrencyError struct {
+	baseError
+}
+
+// NewConcurrencyError creates a new ConcurrencyError
+func NewConcurrencyError(message string, cause error) error {
+	return &ConcurrencyError{
+		baseError: baseError{
+			code:    ConcurrencyConflictCode,
+			message: message,
+			cause:   cause,
+		},
+	}
+}
+
+// RetryAfter returns the delay after which the operation may be retried, which is zero: the
+// conflicting save has completed, so a retry reads the family it saved
+func (e *ConcurrencyError) RetryAfter() time.Duration {
+	return 0
+}
+
+// FamilyDuplicateError represents an error when a new family has the same members as a
+// stored family and the duplicate policy rejects it
+type FamilyDuplicateError struct {
+	baseError
+}
+
+// NewFamilyDuplicateError creates a new FamilyDuplicateError
+func NewFamilyDuplicateError(message string, cause error) error {
+	return &FamilyDuplicateError{
+		baseError: baseError{
+			code:    FamilyDuplicateCode,
+			message: message,
+			cause:   cause,
+		},
+	}
+}
+
+// MutationTimedOutError represents an error when a mutation did not complete within the
+// mutation timeout and its completed saves were rolled back
+type MutationTimedOutError struct {
+	baseError
+}
+
+// NewMutationTimedOutError creates a new MutationTimedOutError
+func NewMutationTimedOutError(message string, cause error) error {
+	return &MutationTimedOutError{
+		baseError: baseError{
+			code:    MutationTimedOutCode,
+			message: message,
+			cause:   cause,
+		},
+	}
+}
+
+// ThrottledError represents an error when an operation was rejected by a rate limiter of the
+// service, such as the limiter of the database, and may be retried after a delay
+type ThrottledError struct {
+	baseError
+	retryAfter time.Duration
+}
+
+// NewThrottledError creates a new ThrottledError that may be retried after the given delay
+func NewThrottledError(message string, retryAfter time.Duration, cause error) error {
+	return &ThrottledError{
+		baseError: baseError{
+			code:    ThrottledCode,
+			message: message,
+			cause:   cause,
+		},
+		retryAfter: retryAfter,
+	}
+}
+
+// RetryAfter returns the delay after which the operation may be retried
+func (e *ThrottledError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// UpstreamUnavailableError represents an error when an operation was rejected because the
+// circuit breaker of a dependency, such as the database, is open, and may be retried once
+// the circuit is expected to close
+type UpstreamUnavailableError struct {
+	baseError
+	retryAfter time.Duration
+}
+
+// NewUpstreamUnavailableError creates a new UpstreamUnavailableError that may be retried
+// after the given delay
+func NewUpstreamUnavailableError(message string, retryAfter time.Duration, cause error) error {
+	return &UpstreamUnavailableError{
+		baseError: baseError{
+			code:    UpstreamUnavailableCode,
+			message: message,
+			cause:   cause,
+		},
+		retryAfter: retryAfter,
+	}
+}
+
+// RetryAfter returns the delay after which the operation may be retried
+func (e *UpstreamUnavailableError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// RetryAfter returns the code of the first error in the chain of err that may be retried
+// after a delay, such as a ThrottledError, and the delay. It reports false if there is none.
+func RetryAfter(err error) (code string, retryAfter time.Duration, ok bool) {
+	var retryable interface {
+		Error
+		RetryAfter() time.Duration
+	}
+	if !errors.As(err, &retryable) {
+		return "", 0, false
+	}
+	return retryable.Code(), retryable.RetryAfter(), true
+}
@@ -71,6 +71,19 @@ func (e *baseError) Error() string {
 	return fmt.Sprintf("[%s] %s", e.code, e.message)
 }
 
+// Unwrap returns the underlying cause of the error, so that errors.Is and errors.As
+// examine it
+func (e *baseError) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether the error has the same code as target, so that errors.Is matches
+// domain errors against the sentinel errors of their codes
+func (e *baseError) Is(target error) bool {
+	t, ok := target.(Error)
+	return ok && e.code != "" && e.code == t.Code()
+}
+
 // validationError implements the ValidationError interface
 type validationError struct {
 	baseError
@@ -121,6 +134,48 @@ func (e *notFoundError) ResourceID() string {
 	return e.resourceID
 }
 
+// Codes of the generic domain errors
+const (
+	ValidationErrorCode = "VALIDATION_ERROR"
+	DatabaseErrorCode   = "DATABASE_ERROR"
+	NotFoundErrorCode   = "NOT_FOUND_ERROR"
+)
+
+// Sentinel errors for matching domain errors by code with errors.Is, including when they
+// are wrapped. For example, errors.Is(err, ErrNotFound) reports whether err or any error
+// it wraps is a not found error.
+var (
+	ErrValidation = sentinel(ValidationErrorCode, "validation failed")
+	ErrDatabase   = sentinel(DatabaseErrorCode, "database operation failed")
+	ErrNotFound   = sentinel(NotFoundErrorCode, "resource not found")
+
+	ErrFamilyTooManyParents            = sentinel(FamilyTooManyParentsCode, "family has too many parents")
+	ErrFamilyParentExists              = sentinel(FamilyParentExistsCode, "parent already exists in family")
+	ErrFamilyParentDuplicate           = sentinel(FamilyParentDuplicateCode, "duplicate parent in family")
+	ErrFamilyChildExists               = sentinel(FamilyChildExistsCode, "child already exists in family")
+	ErrFamilyCannotRemoveLastParent    = sentinel(FamilyCannotRemoveLastParent, "cannot remove the last parent of a family")
+	ErrFamilyNotMarried                = sentinel(FamilyNotMarriedCode, "family is not married")
+	ErrFamilyDivorceRequiresTwoParents = sentinel(FamilyDivorceRequiresTwoCode, "divorce requires two parents")
+	ErrFamilyCreateFailed              = sentinel(FamilyCreateFailedCode, "failed to create family")
+	ErrFamilyStatusUpdateFailed        = sentinel(FamilyStatusUpdateFailedCode, "failed to update family status")
+	ErrFamilyInvalidTransition         = sentinel(FamilyInvalidTransitionCode, "invalid family status transition")
+	ErrParentAlreadyDeceased           = sentinel(ParentAlreadyDeceasedCode, "parent is already deceased")
+	ErrChildAlreadyDeceased            = sentinel(ChildAlreadyDeceasedCode, "child is already deceased")
+	ErrFamilyQuarantined               = sentinel(FamilyQuarantinedCode, "family is quarantined")
+	ErrResultTooLarge                  = sentinel(ResultTooLargeCode, "result is too large")
+	ErrFamilyLocked                    = sentinel(FamilyLockedCode, "family is locked by another operation")
+	ErrConcurrencyConflict             = sentinel(ConcurrencyConflictCode, "family was changed by another operation")
+	ErrFamilyDuplicate                 = sentinel(FamilyDuplicateCode, "family duplicates a stored family")
+	ErrMutationTimedOut                = sentinel(MutationTimedOutCode, "mutation timed out")
+	ErrThrottled                       = sentinel(ThrottledCode, "operation was throttled")
+	ErrUpstreamUnavailable             = sentinel(UpstreamUnavailableCode, "upstream dependency is unavailable")
+)
+
+// sentinel creates a sentinel error that matches the domain errors with the given code
+func sentinel(code string, message string) error {
+	return &baseError{code: code, message: message}
+}
+
 // NewDomainError creates a new domain error with the given code, message, and cause
 func NewDomainError(code string, message string, cause error) error {
 	return &domainError{
@@ -136,7 +191,7 @@ func NewDomainError(code string, message string, cause error) error {
 func NewValidationError(message string, field string, cause error) error {
 	return &validationError{
 		baseError: baseError{
-			code:    "VALIDATION_ERROR",
+			code:    ValidationErrorCode,
 			message: message,
 			cause:   cause,
 		},
@@ -148,7 +203,7 @@ func NewValidationError(message string, field string, cause error) error {
 func NewDatabaseError(message string, operation string, table string, cause error) error {
 	return &databaseError{
 		baseError: baseError{
-			code:    "DATABASE_ERROR",
+			code:    DatabaseErrorCode,
 			message: message,
 			cause:   cause,
 		},
@@ -161,7 +216,7 @@ func NewDatabaseError(message string, operation string, table string, cause erro
 func NewNotFoundError(resourceType string, resourceID string, cause error) error {
 	return &notFoundError{
 		baseError: baseError{
-			code:    "NOT_FOUND_ERROR",
+			code:    NotFoundErrorCode,
 			message: fmt.Sprintf("%s with ID %s not found", resourceType, resourceID),
 			cause:   cause,
 		},
@@ -172,26 +227,26 @@ func NewNotFoundError(resourceType string, resourceID string, cause error) error
 
 // IsValidationError checks if the given error is a validation error
 func IsValidationError(err error) bool {
-	_, ok := err.(ValidationError)
-	return ok
+	var target ValidationError
+	return errors.As(err, &target)
 }
 
 // IsDomainError checks if the given error is a domain error
 func IsDomainError(err error) bool {
-	_, ok := err.(DomainError)
-	return ok
+	var target DomainError
+	return errors.As(err, &target)
 }
 
 // IsDatabaseError checks if the given error is a database error
 func IsDatabaseError(err error) bool {
-	_, ok := err.(DatabaseError)
-	return ok
+	var target DatabaseError
+	return errors.As(err, &target)
 }
 
 // IsNotFoundError checks if the given error is a not found error
 func IsNotFoundError(err error) bool {
-	_, ok := err.(NotFoundError)
-	return ok
+	var target NotFoundError
+	return errors.As(err, &target)
 }
 
 // GetErrorCode extracts the error code from an error if it implements the Code() method
@@ -200,16 +255,12 @@ func GetErrorCode(err error) string {
 		return ""
 	}
 
-	// Try to cast to our Error interface
-	if e, ok := err.(Error); ok {
+	// Find the first error in the chain that has a code
+	var e Error
+	if errors.As(err, &e) {
 		return e.Code()
 	}
 
-	// Try to extract from wrapped error
-	if cause := GetErrorCause(err); cause != nil && cause != err {
-		return GetErrorCode(cause)
-	}
-
 	return ""
 }
 
@@ -219,8 +270,9 @@ func GetErrorMessage(err error) string {
 		return ""
 	}
 
-	// Try to cast to our Error interface
-	if e, ok := err.(Error); ok {
+	// Find the first error in the chain that has a message
+	var e Error
+	if errors.As(err, &e) {
 		return e.Message()
 	}
 
@@ -234,8 +286,9 @@ func GetErrorCause(err error) error {
 		return nil
 	}
 
-	// Try to cast to our Error interface
-	if e, ok := err.(Error); ok {
+	// Find the first error in the chain that has a cause
+	var e Error
+	if errors.As(err, &e) {
 		return e.Cause()
 	}
 
@@ -399,4 +452,4 @@ func NewFamilyCreateFailedError(message string, cause error) error {
 			cause:   cause,
 		},
 	}
-}
\ No newline at end of file
+}
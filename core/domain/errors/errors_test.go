@@ -0,0 +1,93 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors_MatchWhenWrapped(t *testing.T) {
+	cause := errors.New("connection reset")
+	testCases := []struct {
+		name     string
+		err      error
+		sentinel error
+		is       func(error) bool
+	}{
+		{"validation", NewValidationError("name is required", "name", nil), ErrValidation, IsValidationError},
+		{"database", NewDatabaseError("query failed", "find", "families", cause), ErrDatabase, IsDatabaseError},
+		{"not found", NewNotFoundError("Family", "123", nil), ErrNotFound, IsNotFoundError},
+		{"domain", NewFamilyNotMarriedError("family is single", nil), ErrFamilyNotMarried, IsDomainError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("saving family: %w", tc.err)
+
+			assert.True(t, tc.is(wrapped))
+			assert.ErrorIs(t, wrapped, tc.sentinel)
+			assert.Equal(t, GetErrorCode(tc.err), GetErrorCode(wrapped))
+			assert.Equal(t, GetErrorMessage(tc.err), GetErrorMessage(wrapped))
+		})
+	}
+}
+
+func TestErrors_SentinelsMatchOnlyTheirCode(t *testing.T) {
+	err := fmt.Errorf("loading family: %w", NewNotFoundError("Family", "123", nil))
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrValidation)
+	assert.NotErrorIs(t, err, ErrDatabase)
+	assert.False(t, IsValidationError(err))
+}
+
+func TestErrors_UnwrapExposesCause(t *testing.T) {
+	cause := errors.New("connection reset")
+	err := fmt.Errorf("saving family: %w", NewDatabaseError("query failed", "save", "families", cause))
+
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, cause, GetErrorCause(err))
+
+	var dbErr DatabaseError
+	require.ErrorAs(t, err, &dbErr)
+	assert.Equal(t, "save", dbErr.Operation())
+	assert.Equal(t, "families", dbErr.Table())
+}
+
+func TestGetErrorCode_PlainError(t *testing.T) {
+	assert.Equal(t, "", GetErrorCode(nil))
+	assert.Equal(t, "", GetErrorCode(errors.New("plain")))
+	assert.Equal(t, "plain", GetErrorMessage(errors.New("plain")))
+}
+
+func TestRetryAfter(t *testing.T) {
+	throttled := fmt.Errorf("loading family: %w", NewThrottledError("rate limit exceeded", 2*time.Second, nil))
+	code, retryAfter, ok := RetryAfter(throttled)
+	require.True(t, ok)
+	assert.Equal(t, ThrottledCode, code)
+	assert.Equal(t, 2*time.Second, retryAfter)
+	assert.ErrorIs(t, throttled, ErrThrottled)
+
+	unavailable := NewDatabaseError("query failed", "find", "families", NewUpstreamUnavailableError("circuit breaker is open", time.Minute, nil))
+	code, retryAfter, ok = RetryAfter(unavailable)
+	require.True(t, ok)
+	assert.Equal(t, UpstreamUnavailableCode, code)
+	assert.Equal(t, time.Minute, retryAfter)
+	assert.ErrorIs(t, unavailable, ErrUpstreamUnavailable)
+
+	conflict := NewDatabaseError("save failed", "save", "families", NewConcurrencyError("family was changed by another operation", nil))
+	code, retryAfter, ok = RetryAfter(conflict)
+	require.True(t, ok)
+	assert.Equal(t, ConcurrencyConflictCode, code)
+	assert.Zero(t, retryAfter)
+	assert.ErrorIs(t, conflict, ErrConcurrencyConflict)
+
+	_, _, ok = RetryAfter(NewDatabaseError("query failed", "find", "families", errors.New("connection reset")))
+	assert.False(t, ok)
+}
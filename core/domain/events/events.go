@@ -0,0 +1,185 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package events defines the domain events raised by operations on family aggregates.
+//
+// Domain events record facts that other parts of the system may need to react to or
+// keep a record of, such as an audit trail. They are published through the
+// EventPublisher port once the operation that raised them has been persisted.
+//
+// The JSON encoding of an event is its payload when the event leaves the service, so
+// it is versioned: any change to the fields of an event, other than adding optional
+// fields, requires a new version, and an upcaster that converts payloads of the
+// previous version must be registered with the event schema registry.
+package events
+
+import "time"
+
+// Event is a fact about one or more family aggregates
+type Event interface {
+	// Name returns the name of the event, such as "child_moved"
+	Name() string
+
+	// Version returns the version of the event's payload, starting at 1
+	Version() int
+
+	// OccurredAt returns the time at which the event happened
+	OccurredAt() time.Time
+}
+
+// ChildMoved is raised when a child is moved from one family to another
+type ChildMoved struct {
+	ChildID      string    `json:"childId"`      // ID of the moved child
+	FromFamilyID string    `json:"fromFamilyId"` // ID of the family the child left
+	ToFamilyID   string    `json:"toFamilyId"`   // ID of the family the child joined
+	At           time.Time `json:"-"`            // Time of the move, carried by the event envelope
+}
+
+// Name returns the name of the event
+func (e ChildMoved) Name() string {
+	return "child_moved"
+}
+
+// Version returns the version of the event's payload
+func (e ChildMoved) Version() int {
+	return 1
+}
+
+// OccurredAt returns the time of the move
+func (e ChildMoved) OccurredAt() time.Time {
+	return e.At
+}
+
+// FamilyQuarantined is raised when an administrator quarantines a family
+type FamilyQuarantined struct {
+	FamilyID string    `json:"familyId"` // ID of the quarantined family
+	Reason   string    `json:"reason"`   // Why the family was quarantined
+	At       time.Time `json:"-"`        // Time of the quarantine, carried by the event envelope
+}
+
+// Name returns the name of the event
+func (e FamilyQuarantined) Name() string {
+	return "family_quarantined"
+}
+
+// Version returns the version of the event's payload
+func (e FamilyQuarantined) Version() int {
+	return 1
+}
+
+// OccurredAt returns the time of the quarantine
+func (e FamilyQuarantined) OccurredAt() time.Time {
+	return e.At
+}
+
+// FamilyUnquarantined is raised when an administrator removes the quarantine of a family
+type FamilyUnquarantined struct {
+	FamilyID string    `json:"familyId"` // ID of the released family
+	At       time.Time `json:"-"`        // Time of the release, carried by the event envelope
+}
+
+// Name returns the name of the event
+func (e FamilyUnquarantined) Name() string {
+	return "family_unquarantined"
+}
+
+// Version returns the version of the event's payload
+func (e FamilyUnquarantined) Version() int {
+	return 1
+}
+
+// OccurredAt returns the time of the release
+func (e FamilyUnquarantined) OccurredAt() time.Time {
+	return e.At
+}
+
+// QuarantinedFamilyAccessed is raised on every attempt to read or change a quarantined
+// family, whether it was allowed or denied
+type QuarantinedFamilyAccessed struct {
+	FamilyID  string    `json:"familyId"`  // ID of the quarantined family
+	Operation string    `json:"operation"` // Operation attempted, such as "GetFamily"
+	Allowed   bool      `json:"allowed"`   // Whether the attempt was allowed (only administrators are)
+	At        time.Time `json:"-"`         // Time of the attempt, carried by the event envelope
+}
+
+// Name returns the name of the event
+func (e QuarantinedFamilyAccessed) Name() string {
+	return "quarantined_family_accessed"
+}
+
+// Version returns the version of the event's payload
+func (e QuarantinedFamilyAccessed) Version() int {
+	return 1
+}
+
+// OccurredAt returns the time of the attempt
+func (e QuarantinedFamilyAccessed) OccurredAt() time.Time {
+	return e.At
+}
+
+// FamilyDuplicateSuspected is raised when a family is created with the same members as
+// stored families and the duplicate policy links it to them as a potential duplicate
+type FamilyDuplicateSuspected struct {
+	FamilyID    string    `json:"familyId"`    // ID of the created family
+	DuplicateOf []string  `json:"duplicateOf"` // IDs of the stored families with the same members
+	Fingerprint string    `json:"fingerprint"` // Fingerprint of the members shared by the families
+	At          time.Time `json:"-"`           // Time of the creation, carried by the event envelope
+}
+
+// Name returns the name of the event
+func (e FamilyDuplicateSuspected) Name() string {
+	return "family_duplicate_suspected"
+}
+
+// Version returns the version of the event's payload
+func (e FamilyDuplicateSuspected) Version() int {
+	return 1
+}
+
+// OccurredAt returns the time of the creation
+func (e FamilyDuplicateSuspected) OccurredAt() time.Time {
+	return e.At
+}
+
+// FamilyDeleted is raised when a family is deleted, either marked as deleted and kept
+// (soft) or removed for good (hard)
+type FamilyDeleted struct {
+	FamilyID string    `json:"familyId"` // ID of the deleted family
+	Hard     bool      `json:"hard"`     // Whether the family was removed for good
+	At       time.Time `json:"-"`        // Time of the deletion, carried by the event envelope
+}
+
+// Name returns the name of the event
+func (e FamilyDeleted) Name() string {
+	return "family_deleted"
+}
+
+// Version returns the version of the event's payload
+func (e FamilyDeleted) Version() int {
+	return 1
+}
+
+// OccurredAt returns the time of the deletion
+func (e FamilyDeleted) OccurredAt() time.Time {
+	return e.At
+}
+
+// FamilyRestored is raised when a soft deleted family is restored
+type FamilyRestored struct {
+	FamilyID string    `json:"familyId"` // ID of the restored family
+	At       time.Time `json:"-"`        // Time of the restore, carried by the event envelope
+}
+
+// Name returns the name of the event
+func (e FamilyRestored) Name() string {
+	return "family_restored"
+}
+
+// Version returns the version of the event's payload
+func (e FamilyRestored) Version() int {
+	return 1
+}
+
+// OccurredAt returns the time of the restore
+func (e FamilyRestored) OccurredAt() time.Time {
+	return e.At
+}
@@ -139,6 +139,53 @@
 		},
 		[]string{"operation", "error_type"},
 	)
+	// Repairs of inconsistent legacy data made when it is read
+	RepositoryReadRepairs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "repository_read_repairs_total",
+			Help: "Total number of repairs made to inconsistent legacy data when it is read, by backend and repair kind",
+		},
+		[]string{"backend", "repair"},
+	)
+	// Comparisons of reads shadowed to a second repository
+	RepositoryShadowComparisons = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "repository_shadow_comparisons_total",
+			Help: "Total number of reads shadowed to a second repository, by operation and comparison result",
+		},
+		[]string{"operation", "result"},
+	)
+	// Writes duplicated to the shadow repository during a backend migration
+	RepositoryDualWrites = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "repository_dual_writes_total",
+			Help: "Total number of writes duplicated to the shadow repository, by result",
+		},
+		[]string{"result"},
+	)
+	// Reads that failed while the circuit breaker was open and fell back to snapshots
+	RepositoryStaleReads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "repository_stale_reads_total",
+			Help: "Total number of reads rejected by an open circuit breaker that fell back to snapshots, by operation and result",
+		},
+		[]string{"operation", "result"},
+	)
+	// Consents of children flagged as stale by the consent expiry check
+	ChildConsentsExpired = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "child_consents_expired_total",
+			Help: "Total number of consents for children's data flagged as stale by the consent expiry check",
+		},
+	)
+	// Mutations whose completed saves were rolled back
+	MutationRollbacks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mutation_rollbacks_total",
+			Help: "Total number of mutations whose completed saves were rolled back, by operation and reason",
+		},
+		[]string{"operation", "reason"},
+	)
 )
 
 // Operation status constants
@@ -147,6 +194,12 @@
 	StatusFailure = "failure"
 )
 
+// Reasons of mutation rollbacks
+const (
+	RollbackFailure = "failure"
+	RollbackTimeout = "timeout"
+)
+
 // RegisterMetrics registers all metrics with the provided registry
 func RegisterMetrics(registry prometheus.Registerer) {
 	// Register metrics with the provided registry
@@ -165,6 +218,12 @@ func RegisterMetrics(registry prometheus.Registerer) {
 		RepositoryOperationsTotal,
 		RepositoryOperationsDuration,
 		RepositoryOperationErrors,
+		RepositoryReadRepairs,
+		RepositoryShadowComparisons,
+		RepositoryDualWrites,
+		RepositoryStaleReads,
+		ChildConsentsExpired,
+		MutationRollbacks,
 	)
 
 	// Pre-create metric labels to avoid runtime initialization
@@ -292,6 +351,53 @@ func ResetMetrics() {
 		},
 		[]string{"operation", "error_type"},
 	)
+
+	RepositoryReadRepairs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "repository_read_repairs_total",
+			Help: "Total number of repairs made to inconsistent legacy data when it is read, by backend and repair kind",
+		},
+		[]string{"backend", "repair"},
+	)
+
+	RepositoryShadowComparisons = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "repository_shadow_comparisons_total",
+			Help: "Total number of reads shadowed to a second repository, by operation and comparison result",
+		},
+		[]string{"operation", "result"},
+	)
+
+	RepositoryDualWrites = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "repository_dual_writes_total",
+			Help: "Total number of writes duplicated to the shadow repository, by result",
+		},
+		[]string{"result"},
+	)
+
+	RepositoryStaleReads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "repository_stale_reads_total",
+			Help: "Total number of reads rejected by an open circuit breaker that fell back to snapshots, by operation and result",
+		},
+		[]string{"operation", "result"},
+	)
+
+	ChildConsentsExpired = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "child_consents_expired_total",
+			Help: "Total number of consents for children's data flagged as stale by the consent expiry check",
+		},
+	)
+
+	MutationRollbacks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mutation_rollbacks_total",
+			Help: "Total number of mutations whose completed saves were rolled back, by operation and reason",
+		},
+		[]string{"operation", "reason"},
+	)
 }
 
 // Initialize metric labels to avoid runtime initialization
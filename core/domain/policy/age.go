@@ -0,0 +1,155 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package policy provides configurable plausibility policies for family data.
+//
+// Policies complement the invariants enforced by the entities. Invariants always reject
+// invalid data, while a policy flags data that is valid but implausible, and is either
+// reported (warn mode) or rejected (block mode) depending on configuration.
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/chronology"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+)
+
+// Mode determines how a policy handles violations
+type Mode string
+
+const (
+	// ModeOff disables enforcement of the policy
+	ModeOff Mode = "off"
+
+	// ModeWarn logs and counts violations but allows the operation
+	ModeWarn Mode = "warn"
+
+	// ModeBlock rejects operations that introduce violations
+	ModeBlock Mode = "block"
+)
+
+// ParseMode parses a policy mode, treating an empty string as ModeOff
+func ParseMode(s string) (Mode, error) {
+	switch Mode(strings.ToLower(s)) {
+	case "", ModeOff:
+		return ModeOff, nil
+	case ModeWarn:
+		return ModeWarn, nil
+	case ModeBlock:
+		return ModeBlock, nil
+	default:
+		return "", fmt.Errorf("invalid policy mode %q: must be off, warn, or block", s)
+	}
+}
+
+// Rule identifies the rule of the age policy that was violated
+type Rule string
+
+const (
+	// RuleParentYoungerThanChild is violated when a parent was born after one of the children
+	RuleParentYoungerThanChild Rule = "PARENT_YOUNGER_THAN_CHILD"
+
+	// RuleParentUnderMinimumAge is violated when a parent was younger than the minimum age
+	// when one of the children was born
+	RuleParentUnderMinimumAge Rule = "PARENT_UNDER_MINIMUM_AGE"
+)
+
+// DefaultMinParentAgeAtBirth is the minimum plausible age of a parent at a child's birth
+// when none is configured
+const DefaultMinParentAgeAtBirth = 16
+
+// Violation describes a parent-child pair that violates the age policy
+type Violation struct {
+	FamilyID         string // ID of the family containing the pair
+	ParentID         string // ID of the parent
+	ChildID          string // ID of the child
+	Rule             Rule   // Rule that was violated
+	ParentAgeAtBirth int    // Age of the parent in whole years when the child was born (negative if the parent is younger)
+	Message          string // Human-readable description of the violation
+}
+
+// AgePolicy flags parent-child pairs with implausible ages
+type AgePolicy struct {
+	mode                Mode
+	minParentAgeAtBirth int
+}
+
+// NewAgePolicy creates a new AgePolicy.
+//
+// Parameters:
+//   - mode: How violations are handled
+//   - minParentAgeAtBirth: Minimum age of a parent at a child's birth (0 uses DefaultMinParentAgeAtBirth)
+func NewAgePolicy(mode Mode, minParentAgeAtBirth int) *AgePolicy {
+	if minParentAgeAtBirth <= 0 {
+		minParentAgeAtBirth = DefaultMinParentAgeAtBirth
+	}
+	return &AgePolicy{
+		mode:                mode,
+		minParentAgeAtBirth: minParentAgeAtBirth,
+	}
+}
+
+// Mode returns how the policy handles violations
+func (p *AgePolicy) Mode() Mode {
+	return p.mode
+}
+
+// MinParentAgeAtBirth returns the minimum age of a parent at a child's birth
+func (p *AgePolicy) MinParentAgeAtBirth() int {
+	return p.minParentAgeAtBirth
+}
+
+// Evaluate returns the violations of the policy in the family, regardless of the mode
+func (p *AgePolicy) Evaluate(fam *entity.Family) []Violation {
+	var violations []Violation
+	for _, parent := range fam.Parents() {
+		for _, child := range fam.Children() {
+			age := chronology.Age(parent.BirthDate(), child.BirthDate())
+
+			var rule Rule
+			var message string
+			switch {
+			case parent.BirthDate().After(child.BirthDate()):
+				rule = RuleParentYoungerThanChild
+				message = fmt.Sprintf("parent %s was born after child %s", parent.ID(), child.ID())
+			case age < p.minParentAgeAtBirth:
+				rule = RuleParentUnderMinimumAge
+				message = fmt.Sprintf("parent %s was %d when child %s was born (minimum is %d)", parent.ID(), age, child.ID(), p.minParentAgeAtBirth)
+			default:
+				continue
+			}
+
+			violations = append(violations, Violation{
+				FamilyID:         fam.ID(),
+				ParentID:         parent.ID(),
+				ChildID:          child.ID(),
+				Rule:             rule,
+				ParentAgeAtBirth: age,
+				Message:          message,
+			})
+		}
+	}
+	return violations
+}
+
+// Check evaluates the family and returns a ValidationError if the policy is in block mode
+// and the family has violations. The violations are returned in every mode except off.
+func (p *AgePolicy) Check(fam *entity.Family) ([]Violation, error) {
+	if p == nil || p.mode == ModeOff {
+		return nil, nil
+	}
+
+	violations := p.Evaluate(fam)
+	if len(violations) == 0 || p.mode != ModeBlock {
+		return violations, nil
+	}
+
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.Message)
+	}
+	return violations, errorswrapper.NewValidationError(
+		"implausible parent-child ages: "+strings.Join(messages, "; "), "Children", nil)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFamily creates a single-parent family with one child born when the parent was the given age
+func newTestFamily(t *testing.T, parentAgeAtBirth int) *entity.Family {
+	parentBirthDate := time.Date(1980, time.March, 1, 0, 0, 0, 0, time.UTC)
+	parent, err := entity.NewParent("38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "Doe", parentBirthDate, nil)
+	require.NoError(t, err)
+
+	child, err := entity.NewChild("9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e", "Jimmy", "Doe", parentBirthDate.AddDate(parentAgeAtBirth, 6, 0), nil)
+	require.NoError(t, err)
+
+	fam, err := entity.NewFamily("f47ac10b-58cc-4372-a567-0e02b2c3d479", entity.Single, []*entity.Parent{parent}, []*entity.Child{child})
+	require.NoError(t, err)
+	return fam
+}
+
+func TestParseMode(t *testing.T) {
+	for input, expected := range map[string]Mode{"": ModeOff, "off": ModeOff, "warn": ModeWarn, "BLOCK": ModeBlock} {
+		mode, err := ParseMode(input)
+		require.NoError(t, err)
+		assert.Equal(t, expected, mode)
+	}
+
+	_, err := ParseMode("strict")
+	assert.Error(t, err)
+}
+
+func TestAgePolicy_Evaluate(t *testing.T) {
+	p := NewAgePolicy(ModeWarn, 16)
+
+	assert.Empty(t, p.Evaluate(newTestFamily(t, 25)))
+	assert.Empty(t, p.Evaluate(newTestFamily(t, 16)))
+
+	violations := p.Evaluate(newTestFamily(t, 14))
+	require.Len(t, violations, 1)
+	assert.Equal(t, RuleParentUnderMinimumAge, violations[0].Rule)
+	assert.Equal(t, 14, violations[0].ParentAgeAtBirth)
+	assert.Equal(t, "f47ac10b-58cc-4372-a567-0e02b2c3d479", violations[0].FamilyID)
+
+	// The minimum age defaults when not configured
+	assert.Equal(t, DefaultMinParentAgeAtBirth, NewAgePolicy(ModeWarn, 0).MinParentAgeAtBirth())
+}
+
+func TestAgePolicy_Check(t *testing.T) {
+	fam := newTestFamily(t, 14)
+
+	violations, err := NewAgePolicy(ModeOff, 16).Check(fam)
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+
+	violations, err = NewAgePolicy(ModeWarn, 16).Check(fam)
+	assert.NoError(t, err)
+	assert.Len(t, violations, 1)
+
+	violations, err = NewAgePolicy(ModeBlock, 16).Check(fam)
+	assert.Error(t, err)
+	assert.Len(t, violations, 1)
+
+	// A nil policy is disabled
+	var p *AgePolicy
+	violations, err = p.Check(fam)
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestAgePolicy_LeapDayParent(t *testing.T) {
+	// A parent born on 29 February turns 16 on 29 February 2016, a leap year
+	parentBirthDate := time.Date(2000, time.February, 29, 0, 0, 0, 0, time.UTC)
+	parent, err := entity.NewParent("38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "Doe", parentBirthDate, nil)
+	require.NoError(t, err)
+
+	p := NewAgePolicy(ModeWarn, 16)
+	for childBirthDate, want := range map[time.Time]int{
+		time.Date(2016, time.February, 28, 0, 0, 0, 0, time.UTC): 15,
+		time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC): 16,
+	} {
+		child, err := entity.NewChild("9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e", "Jimmy", "Doe", childBirthDate, nil)
+		require.NoError(t, err)
+		fam, err := entity.NewFamily("5d3c2b1a-0f9e-4d8c-b7a6-1e2f3a4b5c6d", entity.Single, []*entity.Parent{parent}, []*entity.Child{child})
+		require.NoError(t, err)
+
+		violations := p.Evaluate(fam)
+		if want < 16 {
+			require.Len(t, violations, 1)
+			assert.Equal(t, want, violations[0].ParentAgeAtBirth)
+		} else {
+			assert.Empty(t, violations)
+		}
+	}
+}
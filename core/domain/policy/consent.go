@@ -0,0 +1,107 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package policy
+
+import (
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/chronology"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+)
+
+// DefaultMinorAge is the age below which a child is a minor when none is configured
+const DefaultMinorAge = 18
+
+// DefaultConsentMaxAge is how long a consent stays current when no maximum age is configured
+const DefaultConsentMaxAge = 365 * 24 * time.Hour
+
+// ConsentPolicy withholds the data of minors that is not covered by a current consent.
+//
+// A consent is current if it has not been flagged as expired and was granted within the
+// maximum age. Stale consents stop covering data as soon as they reach the maximum age,
+// whether or not the background check has flagged them yet.
+type ConsentPolicy struct {
+	enabled  bool
+	minorAge int
+	maxAge   time.Duration
+}
+
+// NewConsentPolicy creates a new ConsentPolicy.
+//
+// Parameters:
+//   - enabled: Whether data of minors without consent is withheld
+//   - minorAge: Age below which a child is a minor (0 uses DefaultMinorAge)
+//   - maxAge: How long a consent stays current (0 uses DefaultConsentMaxAge)
+func NewConsentPolicy(enabled bool, minorAge int, maxAge time.Duration) *ConsentPolicy {
+	if minorAge <= 0 {
+		minorAge = DefaultMinorAge
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultConsentMaxAge
+	}
+	return &ConsentPolicy{
+		enabled:  enabled,
+		minorAge: minorAge,
+		maxAge:   maxAge,
+	}
+}
+
+// Enabled reports whether the policy withholds data
+func (p *ConsentPolicy) Enabled() bool {
+	return p != nil && p.enabled
+}
+
+// MinorAge returns the age below which a child is a minor
+func (p *ConsentPolicy) MinorAge() int {
+	return p.minorAge
+}
+
+// MaxAge returns how long a consent stays current
+func (p *ConsentPolicy) MaxAge() time.Duration {
+	return p.maxAge
+}
+
+// StaleBefore returns the time before which consents granted are stale at the given time
+func (p *ConsentPolicy) StaleBefore(now time.Time) time.Time {
+	return now.Add(-p.maxAge)
+}
+
+// IsMinor reports whether a child born on birthDate is a minor at the given time
+func (p *ConsentPolicy) IsMinor(birthDate, now time.Time) bool {
+	return chronology.IsDependent(birthDate, nil, now, p.minorAge)
+}
+
+// Withheld returns the consent scopes of the child's data that must be withheld at the
+// given time: the scopes not covered by a current consent if the child is a minor. The
+// result is nil if the policy is disabled or the child is not a minor.
+func (p *ConsentPolicy) Withheld(child entity.ChildDTO, now time.Time) []entity.ConsentScope {
+	if !p.Enabled() || !p.IsMinor(child.BirthDate, now) {
+		return nil
+	}
+
+	consents := entity.Consents(child.Consents)
+	staleBefore := p.StaleBefore(now)
+	var withheld []entity.ConsentScope
+	for _, scope := range entity.ConsentScopes {
+		if !consents.Grants(scope, staleBefore) {
+			withheld = append(withheld, scope)
+		}
+	}
+	return withheld
+}
+
+// Redact clears the data of the child that must be withheld at the given time, and
+// returns the consent scopes that were withheld.
+func (p *ConsentPolicy) Redact(child *entity.ChildDTO, now time.Time) []entity.ConsentScope {
+	withheld := p.Withheld(*child, now)
+	for _, scope := range withheld {
+		switch scope {
+		case entity.ConsentScopeExternalIDs:
+			child.ExternalIDs = nil
+		case entity.ConsentScopeNames:
+			child.PreferredName = ""
+			child.NameHistory = nil
+		}
+	}
+	return withheld
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+const consentParentID = "38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f"
+
+// newTestChild returns a child born on the given date with external IDs and names
+func newTestChild(birthDate time.Time, consents ...entity.Consent) entity.ChildDTO {
+	return entity.ChildDTO{
+		ID:            "9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e",
+		FirstName:     "Jimmy",
+		LastName:      "Doe",
+		BirthDate:     birthDate,
+		ExternalIDs:   map[string]string{"school": "S-1"},
+		PreferredName: "Jim",
+		NameHistory:   []entity.NameChange{{FirstName: "Jimmy", LastName: "Roe", EffectiveDate: birthDate}},
+		Consents:      consents,
+	}
+}
+
+func TestConsentPolicy_Withheld(t *testing.T) {
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	minorBirthDate := now.AddDate(-10, 0, 0)
+	p := NewConsentPolicy(true, 18, 30*24*time.Hour)
+
+	// Minors without consent have every scope withheld
+	assert.Equal(t, entity.ConsentScopes, p.Withheld(newTestChild(minorBirthDate), now))
+
+	// A current consent covers its scopes
+	current := entity.Consent{GrantedBy: consentParentID, GrantedAt: now.AddDate(0, 0, -1), Scopes: []entity.ConsentScope{entity.ConsentScopeNames}}
+	assert.Equal(t, []entity.ConsentScope{entity.ConsentScopeExternalIDs}, p.Withheld(newTestChild(minorBirthDate, current), now))
+
+	// Stale and expired consents cover nothing
+	stale := entity.Consent{GrantedBy: consentParentID, GrantedAt: now.AddDate(0, -2, 0), Scopes: entity.ConsentScopes}
+	expiredAt := now.AddDate(0, 0, -1)
+	expired := entity.Consent{GrantedBy: consentParentID, GrantedAt: now.AddDate(0, 0, -2), Scopes: entity.ConsentScopes, ExpiredAt: &expiredAt}
+	assert.Equal(t, entity.ConsentScopes, p.Withheld(newTestChild(minorBirthDate, stale, expired), now))
+
+	// Adults and disabled policies withhold nothing
+	assert.Nil(t, p.Withheld(newTestChild(now.AddDate(-18, 0, 0)), now))
+	assert.Nil(t, NewConsentPolicy(false, 18, 0).Withheld(newTestChild(minorBirthDate), now))
+	var nilPolicy *ConsentPolicy
+	assert.False(t, nilPolicy.Enabled())
+}
+
+func TestConsentPolicy_Redact(t *testing.T) {
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	p := NewConsentPolicy(true, 0, 0)
+	assert.Equal(t, DefaultMinorAge, p.MinorAge())
+	assert.Equal(t, DefaultConsentMaxAge, p.MaxAge())
+
+	consent := entity.Consent{GrantedBy: consentParentID, GrantedAt: now.AddDate(0, 0, -1), Scopes: []entity.ConsentScope{entity.ConsentScopeExternalIDs}}
+	child := newTestChild(now.AddDate(-10, 0, 0), consent)
+	withheld := p.Redact(&child, now)
+
+	assert.Equal(t, []entity.ConsentScope{entity.ConsentScopeNames}, withheld)
+	assert.Equal(t, map[string]string{"school": "S-1"}, child.ExternalIDs)
+	assert.Empty(t, child.PreferredName)
+	assert.Nil(t, child.NameHistory)
+	assert.Equal(t, "Jimmy", child.FirstName)
+}
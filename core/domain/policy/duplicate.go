@@ -0,0 +1,126 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+)
+
+// DuplicateAction determines how a new family that duplicates stored families is handled
+type DuplicateAction string
+
+const (
+	// DuplicateOff disables duplicate detection
+	DuplicateOff DuplicateAction = "off"
+
+	// DuplicateWarn logs and counts duplicates but creates the family
+	DuplicateWarn DuplicateAction = "warn"
+
+	// DuplicateReject refuses to create a family that duplicates a stored family
+	DuplicateReject DuplicateAction = "reject"
+
+	// DuplicateLink creates the family and records it as a potential duplicate of the
+	// stored families it matches
+	DuplicateLink DuplicateAction = "link"
+)
+
+// ParseDuplicateAction parses a duplicate action, treating an empty string as DuplicateOff
+func ParseDuplicateAction(s string) (DuplicateAction, error) {
+	switch DuplicateAction(strings.ToLower(s)) {
+	case "", DuplicateOff:
+		return DuplicateOff, nil
+	case DuplicateWarn:
+		return DuplicateWarn, nil
+	case DuplicateReject:
+		return DuplicateReject, nil
+	case DuplicateLink:
+		return DuplicateLink, nil
+	default:
+		return "", fmt.Errorf("invalid duplicate action %q: must be off, warn, reject, or link", s)
+	}
+}
+
+// DuplicatePolicy detects new families whose members are the same people as the members
+// of a stored family under different IDs.
+//
+// Two families are duplicates if they have the same fingerprint: a hash of the normalized
+// identities of their parents and children, which are their legal names and birth dates.
+// Member IDs, preferred names, and external IDs are not part of the identity, so families
+// created twice by clients that generate their own IDs are detected.
+type DuplicatePolicy struct {
+	action DuplicateAction
+}
+
+// NewDuplicatePolicy creates a new DuplicatePolicy
+func NewDuplicatePolicy(action DuplicateAction) *DuplicatePolicy {
+	return &DuplicatePolicy{action: action}
+}
+
+// Action returns how the policy handles duplicates; a nil policy is off
+func (p *DuplicatePolicy) Action() DuplicateAction {
+	if p == nil || p.action == "" {
+		return DuplicateOff
+	}
+	return p.action
+}
+
+// CandidateFilter returns a filter that matches the stored families that may duplicate the
+// family: those with the same numbers of parents and children and a parent born on the same
+// day as its first parent. Repositories translate the filter into an indexed query, and the
+// candidates are then compared by fingerprint.
+func (p *DuplicatePolicy) CandidateFilter(fam *entity.Family) query.Filter {
+	filter := query.And{
+		query.Eq(query.FieldParentCount, fam.CountParents()),
+		query.Eq(query.FieldChildCount, fam.CountChildren()),
+	}
+	if parents := fam.Parents(); len(parents) > 0 {
+		filter = append(filter, query.Eq(query.FieldParentBirthDate, parents[0].BirthDate()))
+	}
+	return filter
+}
+
+// Duplicates returns the IDs of the candidates that duplicate the family, in order.
+// The family itself is never its own duplicate.
+func (p *DuplicatePolicy) Duplicates(fam *entity.Family, candidates []*entity.Family) []string {
+	fingerprint := Fingerprint(fam)
+	var ids []string
+	for _, candidate := range candidates {
+		if candidate.ID() != fam.ID() && Fingerprint(candidate) == fingerprint {
+			ids = append(ids, candidate.ID())
+		}
+	}
+	return ids
+}
+
+// Fingerprint returns the hash of the normalized identities of the members of a family.
+// Names are compared without case and with runs of spaces collapsed, and birth dates by day.
+func Fingerprint(fam *entity.Family) string {
+	identities := make([]string, 0, fam.CountParents()+fam.CountChildren())
+	for _, parent := range fam.Parents() {
+		identities = append(identities, identity("parent", parent.FirstName(), parent.LastName(), parent.BirthDate().Format("2006-01-02")))
+	}
+	for _, child := range fam.Children() {
+		identities = append(identities, identity("child", child.FirstName(), child.LastName(), child.BirthDate().Format("2006-01-02")))
+	}
+	sort.Strings(identities)
+
+	sum := sha256.Sum256([]byte(strings.Join(identities, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// identity returns the normalized identity of a member
+func identity(role, firstName, lastName, birthDate string) string {
+	return strings.Join([]string{role, normalizeName(firstName), normalizeName(lastName), birthDate}, "\x1f")
+}
+
+// normalizeName lowercases a name and collapses its runs of spaces
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
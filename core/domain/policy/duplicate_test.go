@@ -0,0 +1,71 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNamedFamily creates a single-parent family with one child, with the given IDs and names
+func newNamedFamily(t *testing.T, familyID, parentID, parentFirstName, childID, childFirstName string) *entity.Family {
+	parentBirthDate := time.Date(1980, time.March, 1, 0, 0, 0, 0, time.UTC)
+	parent, err := entity.NewParent(parentID, parentFirstName, "Doe", parentBirthDate, nil)
+	require.NoError(t, err)
+
+	child, err := entity.NewChild(childID, childFirstName, "Doe", parentBirthDate.AddDate(30, 0, 0), nil)
+	require.NoError(t, err)
+
+	fam, err := entity.NewFamily(familyID, entity.Single, []*entity.Parent{parent}, []*entity.Child{child})
+	require.NoError(t, err)
+	return fam
+}
+
+func TestParseDuplicateAction(t *testing.T) {
+	for input, expected := range map[string]DuplicateAction{"": DuplicateOff, "off": DuplicateOff, "warn": DuplicateWarn, "Reject": DuplicateReject, "link": DuplicateLink} {
+		action, err := ParseDuplicateAction(input)
+		require.NoError(t, err)
+		assert.Equal(t, expected, action)
+	}
+
+	_, err := ParseDuplicateAction("merge")
+	assert.Error(t, err)
+}
+
+func TestFingerprint(t *testing.T) {
+	fam := newNamedFamily(t, "f47ac10b-58cc-4372-a567-0e02b2c3d479", "38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e", "Jimmy")
+
+	// The same people under other IDs, with names differing in case and spacing
+	same := newNamedFamily(t, "a1b2c3d4-58cc-4372-a567-0e02b2c3d479", "b2c3d4e5-1eb0-4a20-9f0e-7c3b3c3f3f3f", " JOHN ", "c3d4e5f6-7c1d-4f8e-a6b5-3d2c1b0a9f8e", "jimmy")
+	assert.Equal(t, Fingerprint(fam), Fingerprint(same))
+
+	other := newNamedFamily(t, "d4e5f6a7-58cc-4372-a567-0e02b2c3d479", "38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e", "Jenny")
+	assert.NotEqual(t, Fingerprint(fam), Fingerprint(other))
+}
+
+func TestDuplicatePolicy_Duplicates(t *testing.T) {
+	p := NewDuplicatePolicy(DuplicateReject)
+	fam := newNamedFamily(t, "f47ac10b-58cc-4372-a567-0e02b2c3d479", "38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e", "Jimmy")
+	same := newNamedFamily(t, "a1b2c3d4-58cc-4372-a567-0e02b2c3d479", "b2c3d4e5-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "c3d4e5f6-7c1d-4f8e-a6b5-3d2c1b0a9f8e", "Jimmy")
+	other := newNamedFamily(t, "d4e5f6a7-58cc-4372-a567-0e02b2c3d479", "38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e", "Jenny")
+
+	assert.Equal(t, []string{same.ID()}, p.Duplicates(fam, []*entity.Family{fam, same, other}))
+	assert.Empty(t, p.Duplicates(fam, []*entity.Family{fam, other}))
+
+	// The candidates of a family include the family itself and its duplicates
+	filter := p.CandidateFilter(fam)
+	require.NoError(t, query.Validate(filter))
+	assert.True(t, query.Match(filter, fam))
+	assert.True(t, query.Match(filter, same))
+}
+
+func TestDuplicatePolicy_Action(t *testing.T) {
+	var p *DuplicatePolicy
+	assert.Equal(t, DuplicateOff, p.Action())
+	assert.Equal(t, DuplicateLink, NewDuplicatePolicy(DuplicateLink).Action())
+}
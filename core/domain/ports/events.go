@@ -0,0 +1,33 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/abitofhelp/family-service/core/domain/events"
+)
+
+// EventPublisher defines the interface for publishing domain events
+// This interface represents a port in the Hexagonal Architecture pattern
+// It's defined in the domain layer but implemented in the infrastructure layer
+type EventPublisher interface {
+	// Publish publishes an event that has been persisted
+	Publish(ctx context.Context, event events.Event) error
+}
+
+// EventPublishers is an EventPublisher that publishes events to several publishers, such
+// as the audit log and a message broker
+type EventPublishers []EventPublisher
+
+// Publish publishes an event to every publisher, even if some of them fail
+func (p EventPublishers) Publish(ctx context.Context, event events.Event) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -11,6 +11,7 @@
 	reflect "reflect"
 
 	entity "github.com/abitofhelp/family-service/core/domain/entity"
+	query "github.com/abitofhelp/family-service/core/domain/query"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -37,6 +38,51 @@ func (m *MockFamilyRepository) EXPECT() *MockFamilyRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountChildren mocks base method.
+func (m *MockFamilyRepository) CountChildren(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountChildren", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountChildren indicates an expected call of CountChildren.
+func (mr *MockFamilyRepositoryMockRecorder) CountChildren(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountChildren", reflect.TypeOf((*MockFamilyRepository)(nil).CountChildren), ctx)
+}
+
+// CountFamilies mocks base method.
+func (m *MockFamilyRepository) CountFamilies(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFamilies", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFamilies indicates an expected call of CountFamilies.
+func (mr *MockFamilyRepositoryMockRecorder) CountFamilies(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFamilies", reflect.TypeOf((*MockFamilyRepository)(nil).CountFamilies), ctx)
+}
+
+// CountParents mocks base method.
+func (m *MockFamilyRepository) CountParents(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountParents", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountParents indicates an expected call of CountParents.
+func (mr *MockFamilyRepositoryMockRecorder) CountParents(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountParents", reflect.TypeOf((*MockFamilyRepository)(nil).CountParents), ctx)
+}
+
 // FindByChildID mocks base method.
 func (m *MockFamilyRepository) FindByChildID(ctx context.Context, childID string) (*entity.Family, error) {
 	m.ctrl.T.Helper()
@@ -52,6 +98,36 @@ func (mr *MockFamilyRepositoryMockRecorder) FindByChildID(ctx, childID interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByChildID", reflect.TypeOf((*MockFamilyRepository)(nil).FindByChildID), ctx, childID)
 }
 
+// Find mocks base method.
+func (m *MockFamilyRepository) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, filter)
+	ret0, _ := ret[0].([]*entity.Family)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockFamilyRepositoryMockRecorder) Find(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockFamilyRepository)(nil).Find), ctx, filter)
+}
+
+// FindByExternalID mocks base method.
+func (m *MockFamilyRepository) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByExternalID", ctx, system, externalID)
+	ret0, _ := ret[0].([]*entity.Family)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByExternalID indicates an expected call of FindByExternalID.
+func (mr *MockFamilyRepositoryMockRecorder) FindByExternalID(ctx, system, externalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByExternalID", reflect.TypeOf((*MockFamilyRepository)(nil).FindByExternalID), ctx, system, externalID)
+}
+
 // FindByParentID mocks base method.
 func (m *MockFamilyRepository) FindByParentID(ctx context.Context, parentID string) ([]*entity.Family, error) {
 	m.ctrl.T.Helper()
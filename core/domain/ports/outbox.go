@@ -0,0 +1,72 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxMessage is a domain event stored in the outbox until a relay has published it
+type OutboxMessage struct {
+	ID        string    // Deduplication key of the event, published with it every time it is sent
+	Type      string    // Type of the event, such as "child_moved"
+	Payload   []byte    // The event, sealed in its envelope and encoded as JSON
+	CreatedAt time.Time // Time at which the message was stored
+}
+
+// OutboxRepository defines the interface for the persistence of the outbox, in which domain
+// events are stored in the same transaction as the changes that raised them, so that they
+// are published after the transaction commits even if the service stops first. A relay
+// publishes the messages and then removes them, so that a message is published at least
+// once; consumers drop the events whose deduplication keys they have already received.
+type OutboxRepository interface {
+	// AddToOutbox stores messages in the outbox. It joins the unit of work of the context,
+	// like Save, so that the messages are stored if and only if the unit is committed.
+	AddToOutbox(ctx context.Context, messages ...OutboxMessage) error
+
+	// PendingOutboxMessages returns at most limit messages of the outbox, in the order in
+	// which they were stored
+	PendingOutboxMessages(ctx context.Context, limit int) ([]OutboxMessage, error)
+
+	// RemoveFromOutbox removes the messages with the given IDs from the outbox, once they
+	// have been published. IDs of messages that are not in the outbox are ignored.
+	RemoveFromOutbox(ctx context.Context, ids []string) error
+}
+
+// DeadLetter is a message that an operator has set aside from the outbox, such as a poison
+// message that the broker rejects every time, so that the relay publishes the messages
+// stored after it
+type DeadLetter struct {
+	OutboxMessage
+	Reason         string    // Why the message was set aside
+	DeadLetteredAt time.Time // Time at which the message was set aside
+}
+
+// OutboxAdministrator is implemented by outbox repositories whose messages operators can set
+// aside as dead letters, replay, and purge. Callers check for it; the outbox cannot be
+// repaired through the admin API of repositories that do not implement it.
+type OutboxAdministrator interface {
+	// DeadLetterOutboxMessages moves the messages with the given IDs from the outbox to the
+	// dead letters, with a reason, and returns the number of messages moved. IDs of
+	// messages that are not in the outbox are ignored.
+	DeadLetterOutboxMessages(ctx context.Context, ids []string, reason string, at time.Time) (int, error)
+
+	// OutboxMessages returns at most limit messages of the outbox of the given type, or of
+	// every type if it is empty, in the order in which they were stored. The type is matched
+	// by the query, so that the limit counts only the messages of the type.
+	OutboxMessages(ctx context.Context, eventType string, limit int) ([]OutboxMessage, error)
+
+	// DeadLetters returns at most limit dead letters of the given type, or of every type if
+	// it is empty, in the order in which they were set aside
+	DeadLetters(ctx context.Context, eventType string, limit int) ([]DeadLetter, error)
+
+	// ReplayDeadLetters moves the dead letters with the given IDs back to the end of the
+	// outbox, with their deduplication keys, and returns the number of messages moved. IDs of
+	// messages that are not dead letters are ignored.
+	ReplayDeadLetters(ctx context.Context, ids []string) (int, error)
+
+	// PurgeDeadLetters removes the dead letters with the given IDs for good and returns the
+	// number of messages removed. IDs of messages that are not dead letters are ignored.
+	PurgeDeadLetters(ctx context.Context, ids []string) (int, error)
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package ports
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+)
+
+// QuarantineRepository defines the interface for the persistence of the quarantines that
+// administrators place on families. Quarantines are stored apart from the families, so
+// that placing or removing one never changes a family.
+type QuarantineRepository interface {
+	// GetFamilyQuarantine returns the quarantine of a family, or nil if it is not quarantined
+	GetFamilyQuarantine(ctx context.Context, familyID string) (*entity.Quarantine, error)
+
+	// SaveFamilyQuarantine stores the quarantine of a family, replacing any previous one
+	SaveFamilyQuarantine(ctx context.Context, q entity.Quarantine) error
+
+	// DeleteFamilyQuarantine removes the quarantine of a family and reports whether it had one
+	DeleteFamilyQuarantine(ctx context.Context, familyID string) (bool, error)
+
+	// ListFamilyQuarantines returns every quarantine, in ascending family ID order
+	ListFamilyQuarantines(ctx context.Context) ([]entity.Quarantine, error)
+}
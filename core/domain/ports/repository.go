@@ -4,7 +4,13 @@
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"time"
+
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/repositorywrapper"
 )
 
@@ -20,4 +26,252 @@ type FamilyRepository interface {
 
 	// FindByChildID finds the family that contains a specific child
 	FindByChildID(ctx context.Context, childID string) (*entity.Family, error)
+
+	// FindByExternalID finds the families in which the family itself or one of its
+	// members has the given external ID in the given external system
+	FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error)
+
+	// Find finds the families that match a filter, in ascending ID order.
+	// A nil filter matches every family; an invalid filter is a validation error.
+	Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error)
+
+	// CountFamilies counts the families, in the database rather than by reading them
+	CountFamilies(ctx context.Context) (int, error)
+
+	// CountParents counts the distinct parents of the families; a parent of several
+	// families is counted once
+	CountParents(ctx context.Context) (int, error)
+
+	// CountChildren counts the distinct children of the families
+	CountChildren(ctx context.Context) (int, error)
+}
+
+// overwriteKey is the context key that marks saves that replace stored families
+type overwriteKey struct{}
+
+// WithOverwrite marks a context whose saves of families with version 0 replace the stored
+// family with the same ID, if there is one. Without the mark, such a save only inserts a new
+// family and is rejected with a ConcurrencyError if the ID is taken, so that creating a
+// family never replaces another. Copies of families made elsewhere, such as migrations,
+// imports, and shadow writes, mark their saves.
+func WithOverwrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, overwriteKey{}, true)
+}
+
+// IsOverwrite reports whether a context is marked by WithOverwrite
+func IsOverwrite(ctx context.Context) bool {
+	overwrite, _ := ctx.Value(overwriteKey{}).(bool)
+	return overwrite
+}
+
+// FamilyExporter is implemented by family repositories that can read every family for an
+// export, such as a report, without holding them all in memory. Callers check for it and
+// fall back to GetAll for repositories that do not implement it.
+type FamilyExporter interface {
+	// ExportAll reads every family and calls fn with each, in ascending ID order.
+	// Families beyond the memory budget are spilled to disk while they are read, and fn
+	// is called once the read has completed. An error from fn stops the export.
+	ExportAll(ctx context.Context, fn func(*entity.Family) error) error
+}
+
+// FamilyDeleter is implemented by family repositories that can delete families. A deleted
+// family is kept, marked with the time of its deletion, but is left out of every read of
+// the repository, as if it did not exist, until it is removed for good. Saving a family
+// clears its mark. Callers check for it; families cannot be deleted otherwise.
+type FamilyDeleter interface {
+	// SoftDeleteFamily marks the family with the given ID as deleted at the given time.
+	// It returns false if there is no such family, or if it is already deleted.
+	SoftDeleteFamily(ctx context.Context, familyID string, deletedAt time.Time) (bool, error)
+
+	// HardDeleteFamily removes the family with the given ID, whether it is marked as
+	// deleted or not. It returns false if there is no such family.
+	HardDeleteFamily(ctx context.Context, familyID string) (bool, error)
+}
+
+// FamilyRestorer is implemented by family repositories that can restore deleted families,
+// and purge them once they are no longer to be restored. Callers check for it; deleted
+// families cannot be restored otherwise.
+type FamilyRestorer interface {
+	// RestoreFamily clears the deletion mark of the family with the given ID, so that it is
+	// read again. It returns false if there is no such family, or if it is not deleted.
+	RestoreFamily(ctx context.Context, familyID string) (bool, error)
+
+	// PurgeDeletedFamilies removes for good the families deleted before the given time, and
+	// returns their IDs. It purges the families of the tenant of the context, or, if
+	// allTenants, the families of every tenant.
+	PurgeDeletedFamilies(ctx context.Context, deletedBefore time.Time, allTenants bool) ([]string, error)
+}
+
+// FamilyLocker is implemented by family repositories that can serialize the changes of a
+// family, so that concurrent read-modify-write operations on one family do not overwrite
+// each other. Callers check for it and change families without locks otherwise.
+type FamilyLocker interface {
+	// LockFamily waits until the caller holds the lock of the family and returns a function
+	// that releases it. It fails with a FamilyLockedError if the lock is not acquired in time.
+	LockFamily(ctx context.Context, familyID string) (unlock func(), err error)
+}
+
+// FamilyPager is implemented by family repositories that can read the families one page at
+// a time, so that a client paging through the families does not read them all for every
+// page. Callers check for it and fall back to GetAll otherwise.
+type FamilyPager interface {
+	// ListFamilies returns at most limit families whose IDs sort after the given ID, in
+	// ascending ID order. An empty after starts with the first family.
+	ListFamilies(ctx context.Context, after string, limit int) ([]*entity.Family, error)
+}
+
+// FamilyFilterPager is implemented by family repositories that can find one page of the
+// families that match a filter, so that a search that returns a few families does not read
+// every family that matches it. Callers check for it and fall back to Find otherwise, as
+// FindFamiliesPage does.
+type FamilyFilterPager interface {
+	// FindPage returns at most limit families that match a filter and whose IDs sort after
+	// the given ID, in ascending ID order. An empty after starts with the first family; a nil
+	// filter matches every family, and an invalid filter is a validation error.
+	FindPage(ctx context.Context, filter query.Filter, after string, limit int) ([]*entity.Family, error)
+}
+
+// FindFamiliesPage returns at most limit families that match a filter and whose IDs sort
+// after the given ID, in ascending ID order, with FindPage if the repository implements
+// FamilyFilterPager and with Find otherwise
+func FindFamiliesPage(ctx context.Context, repo FamilyRepository, filter query.Filter, after string, limit int) ([]*entity.Family, error) {
+	if pager, ok := repo.(FamilyFilterPager); ok {
+		return pager.FindPage(ctx, filter, after, limit)
+	}
+
+	families, err := repo.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return query.Page(families, after, limit), nil
+}
+
+// FamilyBatchReader is implemented by family repositories that can read many families by ID
+// at once, so that a request that needs many families, such as a GraphQL query that lists
+// the families of many parents, reads them in one round trip. Callers check for it and fall
+// back to GetByID for each family otherwise, as GetFamiliesByID does.
+type FamilyBatchReader interface {
+	// GetByIDs returns the families with the given IDs, with their versions, in ascending ID
+	// order. The IDs of families that do not exist are left out.
+	GetByIDs(ctx context.Context, ids []string) ([]*entity.Family, error)
+}
+
+// GetFamiliesByID reads the families with the given IDs in ascending ID order, with GetByIDs
+// if the repository implements FamilyBatchReader and with GetByID for each family otherwise.
+// The IDs of families that do not exist are left out.
+func GetFamiliesByID(ctx context.Context, repo FamilyRepository, ids []string) ([]*entity.Family, error) {
+	if reader, ok := repo.(FamilyBatchReader); ok {
+		return reader.GetByIDs(ctx, ids)
+	}
+
+	families := make([]*entity.Family, 0, len(ids))
+	for _, id := range ids {
+		fam, err := repo.GetByID(ctx, id)
+		if errorswrapper.IsNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		families = append(families, fam)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].ID() < families[j].ID() })
+	return families, nil
+}
+
+// FamilyCounts are the numbers of families and of the distinct parents and children in them
+type FamilyCounts struct {
+	Families int
+	Parents  int
+	Children int
+}
+
+// FamilyOwnerReader is implemented by family repositories that can count and list the
+// families of an owner in the database, so that a caller who may only access its own
+// families does not read every family of the owner, or every family, for a count or a page.
+// Callers check for it and fall back to Find and GetAll otherwise, as CountFamiliesByOwner
+// and ListFamiliesByOwner do.
+type FamilyOwnerReader interface {
+	// CountByOwner counts the families of an owner, and the distinct parents and children in
+	// them, leaving out the families with the excluded IDs
+	CountByOwner(ctx context.Context, ownerID string, excluded []string) (FamilyCounts, error)
+
+	// ListOwnedFamilies returns at most limit families of an owner whose IDs sort after the
+	// given ID, in ascending ID order. An empty after starts with the first family.
+	ListOwnedFamilies(ctx context.Context, ownerID, after string, limit int) ([]*entity.Family, error)
+}
+
+// CountFamiliesByOwner counts the families of an owner, and the distinct parents and children
+// in them, leaving out the families with the excluded IDs. It counts with CountByOwner if the
+// repository implements FamilyOwnerReader, and reads the families of the owner with Find
+// otherwise.
+func CountFamiliesByOwner(ctx context.Context, repo FamilyRepository, ownerID string, excluded []string) (FamilyCounts, error) {
+	if reader, ok := repo.(FamilyOwnerReader); ok {
+		return reader.CountByOwner(ctx, ownerID, excluded)
+	}
+
+	families, err := repo.Find(ctx, query.Eq(query.FieldOwnerID, ownerID))
+	if err != nil {
+		return FamilyCounts{}, err
+	}
+	skipped := make(map[string]bool, len(excluded))
+	for _, id := range excluded {
+		skipped[id] = true
+	}
+	var counts FamilyCounts
+	parents, children := make(map[string]bool), make(map[string]bool)
+	for _, fam := range families {
+		if skipped[fam.ID()] {
+			continue
+		}
+		counts.Families++
+		for _, p := range fam.Parents() {
+			parents[p.ID()] = true
+		}
+		for _, c := range fam.Children() {
+			children[c.ID()] = true
+		}
+	}
+	counts.Parents, counts.Children = len(parents), len(children)
+	return counts, nil
+}
+
+// ListFamiliesByOwner returns at most limit families of an owner whose IDs sort after the
+// given ID, in ascending ID order, with ListOwnedFamilies if the repository implements
+// FamilyOwnerReader and with Find otherwise
+func ListFamiliesByOwner(ctx context.Context, repo FamilyRepository, ownerID, after string, limit int) ([]*entity.Family, error) {
+	if reader, ok := repo.(FamilyOwnerReader); ok {
+		return reader.ListOwnedFamilies(ctx, ownerID, after, limit)
+	}
+
+	families, err := repo.Find(ctx, query.Eq(query.FieldOwnerID, ownerID))
+	if err != nil {
+		return nil, err
+	}
+	return query.Page(families, after, limit), nil
+}
+
+// ErrNoUnitOfWork is returned by Begin when the repository behind a wrapper that implements
+// UnitOfWork cannot run units of work, so that callers fall back as they do for repositories
+// that do not implement it
+var ErrNoUnitOfWork = errors.New("the repository cannot run units of work")
+
+// UnitOfWork is implemented by family repositories that can apply several changes in one
+// transaction, so that an operation that changes several families, such as a divorce, is
+// applied completely or not at all. Begin returns a context that carries the transaction:
+// the saves and deletions of the repository called with that context join it, and are
+// applied by Commit or discarded by Rollback. Reads do not join it; a save rejects a family
+// whose version has changed since it was read instead. Callers check for it and apply the
+// changes one at a time otherwise.
+type UnitOfWork interface {
+	// Begin begins a unit of work and returns the context of its operations. It fails with
+	// ErrNoUnitOfWork if the repository cannot run units of work.
+	Begin(ctx context.Context) (context.Context, error)
+
+	// Commit applies the changes of the unit of work of the context
+	Commit(ctx context.Context) error
+
+	// Rollback discards the changes of the unit of work of the context. It does nothing if
+	// the unit of work has already ended.
+	Rollback(ctx context.Context) error
 }
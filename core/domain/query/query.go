@@ -0,0 +1,479 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package query provides a backend-independent model of family filters.
+//
+// A Filter is a tree of conditions on the fields of a family, combined with And, Or,
+// and Not. Repository adapters translate filters into queries of their backend, such
+// as SQL or MongoDB filters, so that new combinations of conditions do not need
+// hand-written queries. Match evaluates a filter against a family in memory; it defines
+// the semantics that every translation must preserve.
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+)
+
+// Field is a field of a family that can be filtered on
+type Field string
+
+const (
+	// FieldID is the ID of the family
+	FieldID Field = "id"
+
+	// FieldStatus is the status of the family
+	FieldStatus Field = "status"
+
+	// FieldOwnerID is the ID of the user who owns the family, empty if it has no owner
+	FieldOwnerID Field = "ownerId"
+
+	// FieldParentCount is the number of parents in the family
+	FieldParentCount Field = "parentCount"
+
+	// FieldChildCount is the number of children in the family
+	FieldChildCount Field = "childCount"
+
+	// FieldParentID matches families with a parent with the given ID
+	FieldParentID Field = "parentId"
+
+	// FieldChildID matches families with a child with the given ID
+	FieldChildID Field = "childId"
+
+	// FieldParentLastName matches families with a parent with the given last name
+	FieldParentLastName Field = "parentLastName"
+
+	// FieldParentBirthDate matches families with a parent whose birth date satisfies the condition
+	FieldParentBirthDate Field = "parentBirthDate"
+
+	// FieldChildBirthDate matches families with a child whose birth date satisfies the condition
+	FieldChildBirthDate Field = "childBirthDate"
+
+	// FieldMemberName matches families with a parent or child whose first, last, or
+	// preferred name satisfies the condition
+	FieldMemberName Field = "memberName"
+)
+
+// Op is the operator of a condition
+type Op string
+
+const (
+	OpEq  Op = "eq"  // Equal to the value
+	OpNe  Op = "ne"  // Not equal to the value
+	OpIn  Op = "in"  // Equal to one of the values
+	OpLt  Op = "lt"  // Less than the value
+	OpLte Op = "lte" // Less than or equal to the value
+	OpGt  Op = "gt"  // Greater than the value
+	OpGte Op = "gte" // Greater than or equal to the value
+
+	OpBetween Op = "between" // Within the Range of the value, including its bounds
+
+	OpContains Op = "contains" // Contains the value, ignoring case
+)
+
+// Range is the value of an OpBetween condition. A member condition with a range matches
+// when a single member is within it, unlike two conditions on the bounds, which may be
+// satisfied by different members.
+type Range struct {
+	From time.Time // Earliest value in the range
+	To   time.Time // Latest value in the range
+}
+
+// kind is the type of the values of a field
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindTime
+)
+
+// fieldSpec describes how a field can be filtered on
+type fieldSpec struct {
+	kind   kind
+	member bool // The condition matches if any parent or child satisfies it
+	ops    []Op
+}
+
+// fields are the fields that can be filtered on.
+// Members are matched with "any" semantics, so negative operators would be ambiguous
+// on them; negate the whole condition with Not instead.
+var fields = map[Field]fieldSpec{
+	FieldID:              {kind: kindString, ops: []Op{OpEq, OpNe, OpIn}},
+	FieldStatus:          {kind: kindString, ops: []Op{OpEq, OpNe, OpIn}},
+	FieldOwnerID:         {kind: kindString, ops: []Op{OpEq, OpNe, OpIn}},
+	FieldParentCount:     {kind: kindInt, ops: []Op{OpEq, OpNe, OpIn, OpLt, OpLte, OpGt, OpGte}},
+	FieldChildCount:      {kind: kindInt, ops: []Op{OpEq, OpNe, OpIn, OpLt, OpLte, OpGt, OpGte}},
+	FieldParentID:        {kind: kindString, member: true, ops: []Op{OpEq, OpIn}},
+	FieldChildID:         {kind: kindString, member: true, ops: []Op{OpEq, OpIn}},
+	FieldParentLastName:  {kind: kindString, member: true, ops: []Op{OpEq, OpIn}},
+	FieldParentBirthDate: {kind: kindTime, member: true, ops: []Op{OpEq, OpLt, OpLte, OpGt, OpGte, OpBetween}},
+	FieldChildBirthDate:  {kind: kindTime, member: true, ops: []Op{OpEq, OpLt, OpLte, OpGt, OpGte, OpBetween}},
+	FieldMemberName:      {kind: kindString, member: true, ops: []Op{OpContains}},
+}
+
+// Filter is a node of a filter tree: And, Or, Not, or Condition
+type Filter interface {
+	filter()
+}
+
+// And matches families that match every filter. An empty And matches every family.
+type And []Filter
+
+// Or matches families that match at least one filter. An empty Or matches no family.
+type Or []Filter
+
+// Not matches families that do not match the filter
+type Not struct {
+	Filter Filter
+}
+
+// Condition compares a field of a family with a value.
+//
+// The value is a string for ID fields, names, and the status, an int for counts, and a
+// time.Time for dates. For OpIn, it is a slice of those: []string or []int. For
+// OpBetween, it is a Range.
+type Condition struct {
+	Field Field
+	Op    Op
+	Value interface{}
+}
+
+func (And) filter()       {}
+func (Or) filter()        {}
+func (Not) filter()       {}
+func (Condition) filter() {}
+
+// Eq returns a condition that a field equals a value
+func Eq(field Field, value interface{}) Condition {
+	return Condition{Field: field, Op: OpEq, Value: value}
+}
+
+// In returns a condition that a field equals one of a list of values, given as []string or []int
+func In(field Field, values interface{}) Condition {
+	return Condition{Field: field, Op: OpIn, Value: values}
+}
+
+// Contains returns a condition that a field contains a string, ignoring case
+func Contains(field Field, value string) Condition {
+	return Condition{Field: field, Op: OpContains, Value: value}
+}
+
+// IsMember reports whether a field is a field of the parents or children of a family
+func IsMember(field Field) bool {
+	return fields[field].member
+}
+
+// Validate checks that a filter only uses known fields, operators allowed for them,
+// and values of the right type. A nil filter is valid and matches every family.
+func Validate(f Filter) error {
+	switch f := f.(type) {
+	case nil:
+		return nil
+	case And:
+		for _, child := range f {
+			if err := Validate(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Or:
+		for _, child := range f {
+			if err := Validate(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Not:
+		if f.Filter == nil {
+			return errorswrapper.NewValidationError("not filter requires a filter", "filter", nil)
+		}
+		return Validate(f.Filter)
+	case Condition:
+		return validateCondition(f)
+	default:
+		return errorswrapper.NewValidationError(fmt.Sprintf("unsupported filter %T", f), "filter", nil)
+	}
+}
+
+// validateCondition checks a single condition
+func validateCondition(c Condition) error {
+	spec, ok := fields[c.Field]
+	if !ok {
+		return errorswrapper.NewValidationError(fmt.Sprintf("unknown field %q", c.Field), "filter", nil)
+	}
+	allowed := false
+	for _, op := range spec.ops {
+		allowed = allowed || op == c.Op
+	}
+	if !allowed {
+		return errorswrapper.NewValidationError(fmt.Sprintf("operator %q is not supported for field %s", c.Op, c.Field), "filter", nil)
+	}
+
+	valid := false
+	switch spec.kind {
+	case kindString:
+		if c.Op == OpIn {
+			_, valid = c.Value.([]string)
+		} else {
+			_, valid = c.Value.(string)
+		}
+	case kindInt:
+		if c.Op == OpIn {
+			_, valid = c.Value.([]int)
+		} else {
+			_, valid = c.Value.(int)
+		}
+	case kindTime:
+		if c.Op == OpBetween {
+			_, valid = c.Value.(Range)
+		} else {
+			_, valid = c.Value.(time.Time)
+		}
+	}
+	if !valid {
+		return errorswrapper.NewValidationError(fmt.Sprintf("value of type %T is not valid for %s %s", c.Value, c.Field, c.Op), "filter", nil)
+	}
+	return nil
+}
+
+// Match reports whether a family matches a filter. A nil filter matches every family.
+// The filter must be valid.
+func Match(f Filter, fam *entity.Family) bool {
+	switch f := f.(type) {
+	case nil:
+		return true
+	case And:
+		for _, child := range f {
+			if !Match(child, fam) {
+				return false
+			}
+		}
+		return true
+	case Or:
+		for _, child := range f {
+			if Match(child, fam) {
+				return true
+			}
+		}
+		return false
+	case Not:
+		return !Match(f.Filter, fam)
+	case Condition:
+		return matchCondition(f, fam)
+	default:
+		return false
+	}
+}
+
+// Page returns at most limit of the given families whose IDs sort after the given ID, in
+// ascending ID order; an empty after starts with the first family. It defines the pages
+// that repositories which read a page at a time must return, for those that cannot.
+func Page(families []*entity.Family, after string, limit int) []*entity.Family {
+	page := make([]*entity.Family, 0, len(families))
+	for _, fam := range families {
+		if fam.ID() > after {
+			page = append(page, fam)
+		}
+	}
+	sort.Slice(page, func(i, j int) bool { return page[i].ID() < page[j].ID() })
+	if len(page) > limit {
+		page = page[:limit]
+	}
+	return page
+}
+
+// Order is the order in which families are listed: by a field, then by ID. Families
+// can be ordered by their ID, status, and counts.
+type Order struct {
+	Field      Field
+	Descending bool // Whether the field is sorted in descending order; ties are sorted by ascending ID
+}
+
+// sortFields are the fields that families can be sorted by
+var sortFields = map[Field]bool{
+	FieldID:          true,
+	FieldStatus:      true,
+	FieldParentCount: true,
+	FieldChildCount:  true,
+}
+
+// ValidateOrder checks that families can be sorted by the field of an order. The zero
+// Order sorts by ID.
+func ValidateOrder(o Order) error {
+	if o.Field != "" && !sortFields[o.Field] {
+		return errorswrapper.NewValidationError(fmt.Sprintf("families cannot be sorted by %q", o.Field), "sort", nil)
+	}
+	return nil
+}
+
+// Sort sorts families in an order, which must be valid. Families with equal values of
+// the field are sorted by ID, so that the order is the same whichever repository the
+// families were read from.
+func Sort(families []*entity.Family, o Order) {
+	sort.Slice(families, func(i, j int) bool {
+		a, b := families[i], families[j]
+		var c int
+		switch o.Field {
+		case FieldStatus:
+			c = strings.Compare(string(a.Status()), string(b.Status()))
+		case FieldParentCount:
+			c = a.CountParents() - b.CountParents()
+		case FieldChildCount:
+			c = a.CountChildren() - b.CountChildren()
+		}
+		if c != 0 {
+			if o.Descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		if o.Field == FieldID && o.Descending {
+			return a.ID() > b.ID()
+		}
+		return a.ID() < b.ID()
+	})
+}
+
+// matchCondition evaluates a single condition
+func matchCondition(c Condition, fam *entity.Family) bool {
+	switch c.Field {
+	case FieldID:
+		return compareString(c, fam.ID())
+	case FieldStatus:
+		return compareString(c, string(fam.Status()))
+	case FieldOwnerID:
+		return compareString(c, fam.OwnerID())
+	case FieldParentCount:
+		return compareInt(c, fam.CountParents())
+	case FieldChildCount:
+		return compareInt(c, fam.CountChildren())
+	case FieldParentID:
+		for _, p := range fam.Parents() {
+			if compareString(c, p.ID()) {
+				return true
+			}
+		}
+	case FieldChildID:
+		for _, ch := range fam.Children() {
+			if compareString(c, ch.ID()) {
+				return true
+			}
+		}
+	case FieldParentLastName:
+		for _, p := range fam.Parents() {
+			if compareString(c, p.LastName()) {
+				return true
+			}
+		}
+	case FieldParentBirthDate:
+		for _, p := range fam.Parents() {
+			if compareTime(c, p.BirthDate()) {
+				return true
+			}
+		}
+	case FieldChildBirthDate:
+		for _, ch := range fam.Children() {
+			if compareTime(c, ch.BirthDate()) {
+				return true
+			}
+		}
+	case FieldMemberName:
+		for _, p := range fam.Parents() {
+			if compareNames(c, p.FirstName(), p.LastName(), p.PreferredName()) {
+				return true
+			}
+		}
+		for _, ch := range fam.Children() {
+			if compareNames(c, ch.FirstName(), ch.LastName(), ch.PreferredName()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compareNames reports whether one of the names of a member satisfies a condition. A name
+// is matched on its own, so a value never matches across two names; an empty preferred
+// name is not a name.
+func compareNames(c Condition, names ...string) bool {
+	for _, name := range names {
+		if name != "" && compareString(c, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareString compares a string field with the value of a condition
+func compareString(c Condition, actual string) bool {
+	switch c.Op {
+	case OpEq:
+		return actual == c.Value.(string)
+	case OpNe:
+		return actual != c.Value.(string)
+	case OpIn:
+		for _, v := range c.Value.([]string) {
+			if actual == v {
+				return true
+			}
+		}
+	case OpContains:
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(c.Value.(string)))
+	}
+	return false
+}
+
+// compareInt compares an int field with the value of a condition
+func compareInt(c Condition, actual int) bool {
+	if c.Op == OpIn {
+		for _, v := range c.Value.([]int) {
+			if actual == v {
+				return true
+			}
+		}
+		return false
+	}
+	v := c.Value.(int)
+	switch c.Op {
+	case OpEq:
+		return actual == v
+	case OpNe:
+		return actual != v
+	case OpLt:
+		return actual < v
+	case OpLte:
+		return actual <= v
+	case OpGt:
+		return actual > v
+	case OpGte:
+		return actual >= v
+	}
+	return false
+}
+
+// compareTime compares a date field with the value of a condition
+func compareTime(c Condition, actual time.Time) bool {
+	if c.Op == OpBetween {
+		r := c.Value.(Range)
+		return !actual.Before(r.From) && !actual.After(r.To)
+	}
+	v := c.Value.(time.Time)
+	switch c.Op {
+	case OpEq:
+		return actual.Equal(v)
+	case OpLt:
+		return actual.Before(v)
+	case OpLte:
+		return !actual.After(v)
+	case OpGt:
+		return actual.After(v)
+	case OpGte:
+		return !actual.Before(v)
+	}
+	return false
+}
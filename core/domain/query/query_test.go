@@ -0,0 +1,106 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	families := querytest.Families(t)
+	for _, tc := range querytest.Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.NoError(t, query.Validate(tc.Filter))
+
+			var got []string
+			for _, fam := range families {
+				if query.Match(tc.Filter, fam) {
+					got = append(got, fam.ID())
+				}
+			}
+			assert.Equal(t, tc.Want, got)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter query.Filter
+		errMsg string
+	}{
+		{"unknown field", query.Eq("tags", "x"), `unknown field "tags"`},
+		{"operator not allowed", query.Condition{Field: query.FieldStatus, Op: query.OpLt, Value: "SINGLE"}, `operator "lt" is not supported for field status`},
+		{"negative operator on member", query.Condition{Field: query.FieldParentID, Op: query.OpNe, Value: "x"}, `operator "ne" is not supported for field parentId`},
+		{"wrong value type", query.Eq(query.FieldChildCount, "2"), "value of type string is not valid for childCount eq"},
+		{"wrong list type", query.In(query.FieldID, []int{1}), "value of type []int is not valid for id in"},
+		{"date as string", query.Eq(query.FieldChildBirthDate, "2020-01-01"), "value of type string is not valid for childBirthDate eq"},
+		{"range for eq", query.Eq(query.FieldChildBirthDate, query.Range{}), "value of type query.Range is not valid for childBirthDate eq"},
+		{"date for between", query.Condition{Field: query.FieldChildBirthDate, Op: query.OpBetween, Value: time.Now()}, "value of type time.Time is not valid for childBirthDate between"},
+		{"nested", query.And{query.Or{query.Not{Filter: query.Eq("x", 1)}}}, `unknown field "x"`},
+		{"empty not", query.Not{}, "not filter requires a filter"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := query.Validate(tc.filter)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+
+	assert.NoError(t, query.Validate(nil))
+	assert.NoError(t, query.Validate(query.Eq(query.FieldParentBirthDate, time.Now())))
+}
+
+func TestPage(t *testing.T) {
+	families := querytest.Families(t)
+	ids := func(page []*entity.Family) []string {
+		var got []string
+		for _, fam := range page {
+			got = append(got, fam.ID())
+		}
+		return got
+	}
+	all := ids(query.Page(families, "", len(families)))
+	require.Len(t, all, len(families))
+	assert.IsIncreasing(t, all)
+
+	assert.Equal(t, all[:2], ids(query.Page(families, "", 2)))
+	assert.Equal(t, all[2:4], ids(query.Page(families, all[1], 2)))
+	assert.Equal(t, all[len(all)-1:], ids(query.Page(families, all[len(all)-2], 5)))
+	assert.Empty(t, query.Page(families, all[len(all)-1], 5))
+}
+
+func TestSort(t *testing.T) {
+	families := querytest.Families(t)
+	ids := func(order query.Order) []string {
+		query.Sort(families, order)
+		var got []string
+		for _, fam := range families {
+			got = append(got, fam.ID())
+		}
+		return got
+	}
+
+	f1, f2, f3, f4 := querytest.Family1, querytest.Family2, querytest.Family3, querytest.Family4
+	assert.Equal(t, []string{f1, f2, f3, f4}, ids(query.Order{}))
+	assert.Equal(t, []string{f4, f3, f2, f1}, ids(query.Order{Field: query.FieldID, Descending: true}))
+	assert.Equal(t, []string{f3, f2, f1, f4}, ids(query.Order{Field: query.FieldStatus}))
+	// Ties are sorted by ascending ID in either direction
+	assert.Equal(t, []string{f4, f2, f3, f1}, ids(query.Order{Field: query.FieldChildCount, Descending: true}))
+	assert.Equal(t, []string{f1, f3, f4, f2}, ids(query.Order{Field: query.FieldParentCount}))
+}
+
+func TestValidateOrder(t *testing.T) {
+	assert.NoError(t, query.ValidateOrder(query.Order{}))
+	assert.NoError(t, query.ValidateOrder(query.Order{Field: query.FieldChildCount, Descending: true}))
+	assert.Error(t, query.ValidateOrder(query.Order{Field: query.FieldParentID}))
+}
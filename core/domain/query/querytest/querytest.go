@@ -0,0 +1,153 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package querytest provides the families and filters that every translation of a
+// query.Filter is tested with, so that all repository adapters are held to the same
+// results as query.Match.
+package querytest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/stretchr/testify/require"
+)
+
+// IDs of the families, parents, and children of Families
+const (
+	Family1 = "f1000000-0000-4000-8000-000000000000" // Single, one parent, no children
+	Family2 = "f2000000-0000-4000-8000-000000000000" // Married, two parents, two children
+	Family3 = "f3000000-0000-4000-8000-000000000000" // Divorced, one parent named Roe, one child
+	Family4 = "f4000000-0000-4000-8000-000000000000" // Single, one parent, three children, one preferring Emmy
+
+	Parent1  = "a1000000-0000-4000-8000-000000000000"
+	Parent2A = "a2000000-0000-4000-8000-00000000000a"
+	Parent2B = "a2000000-0000-4000-8000-00000000000b"
+	Parent3  = "a3000000-0000-4000-8000-000000000000"
+	Parent4  = "a4000000-0000-4000-8000-000000000000"
+
+	Child2A = "c2000000-0000-4000-8000-00000000000a"
+	Child2B = "c2000000-0000-4000-8000-00000000000b"
+	Child3  = "c3000000-0000-4000-8000-000000000000"
+	Child4A = "c4000000-0000-4000-8000-00000000000a"
+	Child4B = "c4000000-0000-4000-8000-00000000000b"
+	Child4C = "c4000000-0000-4000-8000-00000000000c"
+
+	Owner1 = "user-1" // Owner of Family1 and Family3
+	Owner2 = "user-2" // Owner of Family2; Family4 has no owner
+)
+
+// Case is a filter and the IDs of the Families it matches, in ascending order
+type Case struct {
+	Name   string
+	Filter query.Filter
+	Want   []string
+}
+
+// date returns midnight UTC of a day, the form in which birth dates are stored
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Families returns the families the cases are evaluated against, in ascending ID order
+func Families(t *testing.T) []*entity.Family {
+	t.Helper()
+	parent := func(id, lastName string, birthDate time.Time) *entity.Parent {
+		p, err := entity.NewParent(id, "Pat", lastName, birthDate, nil)
+		require.NoError(t, err)
+		return p
+	}
+	child := func(id string, birthDate time.Time) *entity.Child {
+		c, err := entity.NewChild(id, "Sam", "Doe", birthDate, nil)
+		require.NoError(t, err)
+		return c
+	}
+	emmy := child(Child4C, date(2018, time.June, 3))
+	require.NoError(t, emmy.SetPreferredName("Emmy"))
+	family := func(id string, status entity.Status, parents []*entity.Parent, children []*entity.Child, ownerID string) *entity.Family {
+		f, err := entity.NewFamily(id, status, parents, children)
+		require.NoError(t, err)
+		f.SetOwnerID(ownerID)
+		return f
+	}
+
+	return []*entity.Family{
+		family(Family1, entity.Single,
+			[]*entity.Parent{parent(Parent1, "Doe", date(1980, time.January, 1))},
+			nil, Owner1),
+		family(Family2, entity.Married,
+			[]*entity.Parent{parent(Parent2A, "Doe", date(1975, time.May, 5)), parent(Parent2B, "Doe", date(1978, time.August, 8))},
+			[]*entity.Child{child(Child2A, date(2005, time.January, 1)), child(Child2B, date(2010, time.June, 15))}, Owner2),
+		family(Family3, entity.Divorced,
+			[]*entity.Parent{parent(Parent3, "Roe", date(1985, time.March, 3))},
+			[]*entity.Child{child(Child3, date(2012, time.December, 12))}, Owner1),
+		family(Family4, entity.Single,
+			[]*entity.Parent{parent(Parent4, "Doe", date(1990, time.September, 9))},
+			[]*entity.Child{child(Child4A, date(2015, time.April, 1)), child(Child4B, date(2016, time.May, 2)), emmy}, ""),
+	}
+}
+
+// Cases are the filters every translation must evaluate like query.Match
+var Cases = []Case{
+	{"no filter", nil, []string{Family1, Family2, Family3, Family4}},
+	{"empty and", query.And{}, []string{Family1, Family2, Family3, Family4}},
+	{"empty or", query.Or{}, nil},
+	{"not empty or", query.Not{Filter: query.Or{}}, []string{Family1, Family2, Family3, Family4}},
+
+	{"id eq", query.Eq(query.FieldID, Family3), []string{Family3}},
+	{"id in", query.In(query.FieldID, []string{Family1, Family4}), []string{Family1, Family4}},
+
+	{"owner eq", query.Eq(query.FieldOwnerID, Owner1), []string{Family1, Family3}},
+	{"owner eq no owner", query.Eq(query.FieldOwnerID, ""), []string{Family4}},
+	{"owner ne", query.Condition{Field: query.FieldOwnerID, Op: query.OpNe, Value: Owner1}, []string{Family2, Family4}},
+	{"owner in", query.In(query.FieldOwnerID, []string{Owner2, ""}), []string{Family2, Family4}},
+	{"status eq", query.Eq(query.FieldStatus, "MARRIED"), []string{Family2}},
+	{"status ne", query.Condition{Field: query.FieldStatus, Op: query.OpNe, Value: "SINGLE"}, []string{Family2, Family3}},
+	{"status in", query.In(query.FieldStatus, []string{"SINGLE", "DIVORCED"}), []string{Family1, Family3, Family4}},
+	{"status in nothing", query.In(query.FieldStatus, []string{}), nil},
+
+	{"child count eq", query.Eq(query.FieldChildCount, 0), []string{Family1}},
+	{"child count ne", query.Condition{Field: query.FieldChildCount, Op: query.OpNe, Value: 2}, []string{Family1, Family3, Family4}},
+	{"child count in", query.In(query.FieldChildCount, []int{1, 3}), []string{Family3, Family4}},
+	{"child count gt", query.Condition{Field: query.FieldChildCount, Op: query.OpGt, Value: 1}, []string{Family2, Family4}},
+	{"child count gte", query.Condition{Field: query.FieldChildCount, Op: query.OpGte, Value: 3}, []string{Family4}},
+	{"child count lt", query.Condition{Field: query.FieldChildCount, Op: query.OpLt, Value: 2}, []string{Family1, Family3}},
+	{"child count lte", query.Condition{Field: query.FieldChildCount, Op: query.OpLte, Value: 0}, []string{Family1}},
+	{"parent count eq", query.Eq(query.FieldParentCount, 2), []string{Family2}},
+	{"parent count gt negative", query.Condition{Field: query.FieldParentCount, Op: query.OpGt, Value: -1}, []string{Family1, Family2, Family3, Family4}},
+	{"parent count lt zero", query.Condition{Field: query.FieldParentCount, Op: query.OpLt, Value: 0}, nil},
+
+	{"parent id eq", query.Eq(query.FieldParentID, Parent2B), []string{Family2}},
+	{"parent id in", query.In(query.FieldParentID, []string{Parent1, Parent3}), []string{Family1, Family3}},
+	{"child id eq", query.Eq(query.FieldChildID, Child4B), []string{Family4}},
+	{"child id in nothing", query.In(query.FieldChildID, []string{}), nil},
+	{"parent last name eq", query.Eq(query.FieldParentLastName, "Roe"), []string{Family3}},
+	{"parent last name in", query.In(query.FieldParentLastName, []string{"Doe", "Poe"}), []string{Family1, Family2, Family4}},
+
+	{"child birth date gte", query.Condition{Field: query.FieldChildBirthDate, Op: query.OpGte, Value: date(2015, time.January, 1)}, []string{Family4}},
+	{"child birth date lt", query.Condition{Field: query.FieldChildBirthDate, Op: query.OpLt, Value: date(2006, time.January, 1)}, []string{Family2}},
+	{"child birth date eq", query.Eq(query.FieldChildBirthDate, date(2012, time.December, 12)), []string{Family3}},
+	{"parent birth date lte", query.Condition{Field: query.FieldParentBirthDate, Op: query.OpLte, Value: date(1978, time.August, 8)}, []string{Family2}},
+	{"parent birth date gt", query.Condition{Field: query.FieldParentBirthDate, Op: query.OpGt, Value: date(1985, time.March, 3)}, []string{Family4}},
+	{"child birth date between", query.Condition{Field: query.FieldChildBirthDate, Op: query.OpBetween, Value: query.Range{From: date(2010, time.June, 15), To: date(2015, time.April, 1)}}, []string{Family2, Family3, Family4}},
+	{"child birth date between needs one child", query.Condition{Field: query.FieldChildBirthDate, Op: query.OpBetween, Value: query.Range{From: date(2006, time.January, 1), To: date(2009, time.December, 31)}}, nil},
+	{"parent birth date between", query.Condition{Field: query.FieldParentBirthDate, Op: query.OpBetween, Value: query.Range{From: date(1976, time.January, 1), To: date(1985, time.December, 31)}}, []string{Family1, Family2, Family3}},
+
+	{"member name contains", query.Contains(query.FieldMemberName, "oe"), []string{Family1, Family2, Family3, Family4}},
+	{"member name contains last name", query.Contains(query.FieldMemberName, "Roe"), []string{Family3}},
+	{"member name contains ignores case", query.Contains(query.FieldMemberName, "sAM"), []string{Family2, Family3, Family4}},
+	{"member name contains preferred name", query.Contains(query.FieldMemberName, "emm"), []string{Family4}},
+	{"member name contains within one name", query.Contains(query.FieldMemberName, "Pat Roe"), nil},
+	{"member name contains wildcards literally", query.Contains(query.FieldMemberName, "%a_"), nil},
+	{"member name contains every term", query.And{query.Contains(query.FieldMemberName, "pat"), query.Contains(query.FieldMemberName, "roe")}, []string{Family3}},
+
+	{"and", query.And{query.Eq(query.FieldStatus, "SINGLE"), query.Condition{Field: query.FieldChildCount, Op: query.OpGt, Value: 0}}, []string{Family4}},
+	{"or", query.Or{query.Eq(query.FieldStatus, "MARRIED"), query.Eq(query.FieldChildID, Child3)}, []string{Family2, Family3}},
+	{"not member", query.Not{Filter: query.Eq(query.FieldParentID, Parent1)}, []string{Family2, Family3, Family4}},
+	{"not any child", query.Not{Filter: query.Condition{Field: query.FieldChildBirthDate, Op: query.OpLt, Value: date(2011, time.January, 1)}}, []string{Family1, Family3, Family4}},
+	{"nested", query.Or{
+		query.And{query.Eq(query.FieldStatus, "SINGLE"), query.Not{Filter: query.Eq(query.FieldChildCount, 0)}},
+		query.Condition{Field: query.FieldParentBirthDate, Op: query.OpLt, Value: date(1976, time.January, 1)},
+	}, []string{Family2, Family4}},
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package reporting
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Formats in which a report can be written
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// CSVHeader is the header row of a report written as CSV
+var CSVHeader = []string{
+	"generated_at", "dimension", "key", "families", "parents", "children",
+	"dependent_children", "dependency_ratio", "suppressed",
+}
+
+// Write writes the report to w in the given format
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatCSV:
+		return r.WriteCSV(w)
+	case FormatJSON:
+		return r.WriteJSON(w)
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
+
+// WriteJSON writes the report to w as an indented JSON document
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes the report to w as CSV, with a header row and one row per group.
+// The counts of suppressed groups are left empty.
+func (r *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVHeader); err != nil {
+		return err
+	}
+
+	generatedAt := r.GeneratedAt.Format(time.RFC3339)
+	for _, g := range r.Groups {
+		row := []string{generatedAt, g.Dimension, g.Key, "", "", "", "", "", strconv.FormatBool(g.Suppressed)}
+		if !g.Suppressed {
+			row[3] = strconv.Itoa(g.Families)
+			row[4] = strconv.Itoa(g.Parents)
+			row[5] = strconv.Itoa(g.Children)
+			row[6] = strconv.Itoa(g.DependentChildren)
+			row[7] = strconv.FormatFloat(g.DependencyRatio, 'f', 4, 64)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
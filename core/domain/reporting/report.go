@@ -0,0 +1,262 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package reporting produces aggregate reports of the stored families that can be shared
+// with statistical agencies.
+//
+// A report only holds counts and ratios: family counts by status and by region, and the
+// number of dependent children per parent. It never holds IDs, names, or dates, and groups
+// with fewer families than the minimum cell size are suppressed, so that no family can be
+// singled out from a published report.
+package reporting
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/policy"
+)
+
+// SchemaVersion is the version of the report format. It changes whenever a column or
+// field is added, removed, or changes meaning.
+const SchemaVersion = 1
+
+// Dimensions by which families are grouped in a report
+const (
+	DimensionTotal  = "total"  // All families, in a single group
+	DimensionStatus = "status" // Families by status
+	DimensionRegion = "region" // Families by region
+)
+
+// TotalKey is the key of the group of all families
+const TotalKey = "ALL"
+
+// UnknownRegion is the region of families whose region cannot be determined
+const UnknownRegion = "UNKNOWN"
+
+// statuses are the statuses reported, in order. Every status is reported, even without
+// families, so that reports from different runs have the same rows.
+var statuses = []entity.Status{entity.Single, entity.Married, entity.Divorced, entity.Widowed, entity.Abandoned}
+
+// RegionResolver returns the region of a family, or an empty string if it is unknown
+type RegionResolver func(fam *entity.Family) string
+
+// RegionFromExternalID returns a RegionResolver that takes the region of a family from
+// its external ID in the given system: the first submatch of pattern if it has one, or
+// else the whole match. Families without an ID in the system, or whose ID does not match,
+// have an unknown region.
+func RegionFromExternalID(system string, pattern *regexp.Regexp) RegionResolver {
+	return func(fam *entity.Family) string {
+		id, ok := fam.ExternalIDs()[system]
+		if !ok {
+			return ""
+		}
+		match := pattern.FindStringSubmatch(id)
+		switch {
+		case len(match) > 1:
+			return match[1]
+		case len(match) == 1:
+			return match[0]
+		default:
+			return ""
+		}
+	}
+}
+
+// Options configures how a report is generated
+type Options struct {
+	// MinorAge is the age below which a child is a dependent (0 uses policy.DefaultMinorAge)
+	MinorAge int
+
+	// MinCellSize is the smallest number of families a group may have and still be
+	// reported (0 disables suppression)
+	MinCellSize int
+
+	// Region resolves the region of each family (nil reports every family in UnknownRegion)
+	Region RegionResolver
+}
+
+// Group is the aggregate of the families in one group of a dimension. Counts only include
+// living members.
+type Group struct {
+	Dimension         string  `json:"dimension"`
+	Key               string  `json:"key"`
+	Families          int     `json:"families"`
+	Parents           int     `json:"parents"`
+	Children          int     `json:"children"`
+	DependentChildren int     `json:"dependent_children"`
+	DependencyRatio   float64 `json:"dependency_ratio"` // Dependent children per parent (0 without parents)
+	Suppressed        bool    `json:"suppressed"`       // Whether the counts are withheld because the group is too small
+}
+
+// Report is an aggregate report of the stored families
+type Report struct {
+	SchemaVersion int       `json:"schema_version"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	MinCellSize   int       `json:"min_cell_size"`
+	Groups        []Group   `json:"groups"`
+}
+
+// Generate aggregates families into a report.
+//
+// Groups are ordered by dimension (total, status, region); statuses in a fixed order and
+// regions alphabetically. Groups with at least one but fewer than MinCellSize families are
+// suppressed. When exactly one group of a dimension would be suppressed, the next smallest
+// group of the dimension is suppressed too, so that the suppressed counts cannot be
+// recovered by subtracting the other groups from the total.
+//
+// Parameters:
+//   - families: The families to report on
+//   - opts: How the report is generated
+//   - now: The time of the report, at which the ages of children are computed
+//
+// Returns:
+//   - The report
+func Generate(families []*entity.Family, opts Options, now time.Time) *Report {
+	b := NewBuilder(opts, now)
+	for _, fam := range families {
+		b.Add(fam)
+	}
+	return b.Report()
+}
+
+// Builder aggregates families into a report one at a time, so that a report can be
+// generated while the families are read, without holding them all in memory. The report
+// is the same as the one Generate returns for the same families.
+type Builder struct {
+	opts     Options
+	now      time.Time
+	minors   *policy.ConsentPolicy
+	total    *Group
+	byStatus map[entity.Status]*Group
+	byRegion map[string]*Group
+}
+
+// NewBuilder creates a builder of a report
+//
+// Parameters:
+//   - opts: How the report is generated
+//   - now: The time of the report, at which the ages of children are computed
+//
+// Returns:
+//   - A new builder without families
+func NewBuilder(opts Options, now time.Time) *Builder {
+	b := &Builder{
+		opts:     opts,
+		now:      now,
+		minors:   policy.NewConsentPolicy(false, opts.MinorAge, 0),
+		total:    &Group{Dimension: DimensionTotal, Key: TotalKey},
+		byStatus: make(map[entity.Status]*Group, len(statuses)),
+		byRegion: make(map[string]*Group),
+	}
+	for _, status := range statuses {
+		b.byStatus[status] = &Group{Dimension: DimensionStatus, Key: string(status)}
+	}
+	return b
+}
+
+// Add adds a family to the report
+func (b *Builder) Add(fam *entity.Family) {
+	region := UnknownRegion
+	if b.opts.Region != nil {
+		if r := b.opts.Region(fam); r != "" {
+			region = r
+		}
+	}
+	if b.byRegion[region] == nil {
+		b.byRegion[region] = &Group{Dimension: DimensionRegion, Key: region}
+	}
+
+	groups := []*Group{b.total, b.byRegion[region]}
+	if g, ok := b.byStatus[fam.Status()]; ok {
+		groups = append(groups, g)
+	}
+	for _, g := range groups {
+		add(g, fam, b.minors, b.now)
+	}
+}
+
+// Report returns the report of the families added so far, with small groups suppressed
+func (b *Builder) Report() *Report {
+	statusGroups := make([]*Group, 0, len(statuses))
+	for _, status := range statuses {
+		g := *b.byStatus[status]
+		statusGroups = append(statusGroups, &g)
+	}
+	regionGroups := make([]*Group, 0, len(b.byRegion))
+	for _, rg := range b.byRegion {
+		g := *rg
+		regionGroups = append(regionGroups, &g)
+	}
+	sort.Slice(regionGroups, func(i, j int) bool { return regionGroups[i].Key < regionGroups[j].Key })
+	total := *b.total
+
+	report := &Report{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   b.now.UTC(),
+		MinCellSize:   b.opts.MinCellSize,
+	}
+	for _, dimension := range [][]*Group{{&total}, statusGroups, regionGroups} {
+		suppress(dimension, b.opts.MinCellSize)
+		for _, g := range dimension {
+			if g.Suppressed {
+				*g = Group{Dimension: g.Dimension, Key: g.Key, Suppressed: true}
+			} else if g.Parents > 0 {
+				g.DependencyRatio = math.Round(float64(g.DependentChildren)/float64(g.Parents)*10000) / 10000
+			}
+			report.Groups = append(report.Groups, *g)
+		}
+	}
+	return report
+}
+
+// add adds the living members of a family to a group
+func add(g *Group, fam *entity.Family, minors *policy.ConsentPolicy, now time.Time) {
+	g.Families++
+	for _, p := range fam.Parents() {
+		if !p.IsDeceased() {
+			g.Parents++
+		}
+	}
+	for _, c := range fam.Children() {
+		if c.IsDeceased() {
+			continue
+		}
+		g.Children++
+		if minors.IsMinor(c.BirthDate(), now) {
+			g.DependentChildren++
+		}
+	}
+}
+
+// suppress marks the groups of a dimension that are too small to be reported, and the
+// complementary group that keeps a single suppressed group from being derived
+func suppress(groups []*Group, minCellSize int) {
+	small := func(g *Group) bool { return g.Families > 0 && g.Families < minCellSize }
+
+	suppressed := 0
+	for _, g := range groups {
+		if small(g) {
+			g.Suppressed = true
+			suppressed++
+		}
+	}
+	if suppressed != 1 {
+		return
+	}
+
+	var complement *Group
+	for _, g := range groups {
+		if g.Suppressed || g.Families == 0 {
+			continue
+		}
+		if complement == nil || g.Families < complement.Families {
+			complement = g
+		}
+	}
+	if complement != nil {
+		complement.Suppressed = true
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package reporting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+// newFamily creates a family with one parent per parent birth year and one child per child
+// birth year, registered in the given region (none if empty)
+func newFamily(t *testing.T, n int, status entity.Status, region string, parentYears []int, childYears []int) *entity.Family {
+	var parents []*entity.Parent
+	for i, year := range parentYears {
+		p, err := entity.NewParent(fmt.Sprintf("00000000-0000-4000-8000-%06d%06d", n, i), "Jane", "Doe", time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		require.NoError(t, err)
+		parents = append(parents, p)
+	}
+	var children []*entity.Child
+	for i, year := range childYears {
+		c, err := entity.NewChild(fmt.Sprintf("00000000-0000-4000-9000-%06d%06d", n, i), "Jim", "Doe", time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		require.NoError(t, err)
+		children = append(children, c)
+	}
+
+	fam, err := entity.NewFamily(fmt.Sprintf("00000000-0000-4000-a000-%012d", n), status, parents, children)
+	require.NoError(t, err)
+	if region != "" {
+		require.NoError(t, fam.SetExternalIDs(map[string]string{"registry": region + "-" + fmt.Sprint(n)}))
+	}
+	return fam
+}
+
+func group(t *testing.T, report *Report, dimension, key string) Group {
+	for _, g := range report.Groups {
+		if g.Dimension == dimension && g.Key == key {
+			return g
+		}
+	}
+	t.Fatalf("no %s group %s", dimension, key)
+	return Group{}
+}
+
+func TestGenerate(t *testing.T) {
+	families := []*entity.Family{
+		newFamily(t, 1, entity.Married, "CA", []int{1980, 1982}, []int{2015, 2000}),
+		newFamily(t, 2, entity.Single, "CA", []int{1985}, []int{2018}),
+		newFamily(t, 3, entity.Single, "", []int{1990}, nil),
+	}
+	opts := Options{Region: RegionFromExternalID("registry", regexp.MustCompile(`^([A-Z]{2})-`))}
+
+	report := Generate(families, opts, now)
+
+	assert.Equal(t, SchemaVersion, report.SchemaVersion)
+	assert.Equal(t, now, report.GeneratedAt)
+	assert.Equal(t, Group{Dimension: DimensionTotal, Key: TotalKey, Families: 3, Parents: 4, Children: 3, DependentChildren: 2, DependencyRatio: 0.5}, group(t, report, DimensionTotal, TotalKey))
+	assert.Equal(t, 2, group(t, report, DimensionStatus, string(entity.Single)).Families)
+	assert.Equal(t, 0, group(t, report, DimensionStatus, string(entity.Widowed)).Families)
+	assert.Equal(t, Group{Dimension: DimensionRegion, Key: "CA", Families: 2, Parents: 3, Children: 3, DependentChildren: 2, DependencyRatio: 0.6667}, group(t, report, DimensionRegion, "CA"))
+	assert.Equal(t, 1, group(t, report, DimensionRegion, UnknownRegion).Families)
+
+	// Every status is reported, in a fixed order
+	var keys []string
+	for _, g := range report.Groups {
+		if g.Dimension == DimensionStatus {
+			keys = append(keys, g.Key)
+		}
+	}
+	assert.Equal(t, []string{"SINGLE", "MARRIED", "DIVORCED", "WIDOWED", "ABANDONED"}, keys)
+}
+
+func TestBuilder(t *testing.T) {
+	families := []*entity.Family{
+		newFamily(t, 1, entity.Married, "CA", []int{1980, 1982}, []int{2015}),
+		newFamily(t, 2, entity.Single, "NY", []int{1985}, nil),
+		newFamily(t, 3, entity.Single, "CA", []int{1990}, []int{2020}),
+	}
+	opts := Options{MinCellSize: 2, Region: RegionFromExternalID("registry", regexp.MustCompile(`^([A-Z]{2})-`))}
+
+	b := NewBuilder(opts, now)
+	for _, fam := range families {
+		b.Add(fam)
+	}
+
+	// Families added one at a time give the same report as Generate
+	assert.Equal(t, Generate(families, opts, now), b.Report())
+
+	// Reporting does not change the builder, so more families can be added
+	first := b.Report()
+	assert.True(t, group(t, first, DimensionRegion, "NY").Suppressed)
+	b.Add(newFamily(t, 4, entity.Single, "NY", []int{1970}, nil))
+	assert.Equal(t, 2, group(t, b.Report(), DimensionRegion, "NY").Families)
+	assert.True(t, group(t, first, DimensionRegion, "NY").Suppressed)
+}
+
+func TestGenerate_SuppressesSmallGroups(t *testing.T) {
+	var families []*entity.Family
+	for n := 0; n < 6; n++ {
+		families = append(families, newFamily(t, n, entity.Single, "CA", []int{1980}, []int{2015}))
+	}
+	for n := 6; n < 10; n++ {
+		families = append(families, newFamily(t, n, entity.Single, "NY", []int{1980}, []int{2015}))
+	}
+	families = append(families, newFamily(t, 10, entity.Married, "WA", []int{1980, 1981}, []int{2015}))
+
+	report := Generate(families, Options{MinCellSize: 3, Region: RegionFromExternalID("registry", regexp.MustCompile(`^[A-Z]{2}`))}, now)
+
+	assert.False(t, group(t, report, DimensionTotal, TotalKey).Suppressed)
+	assert.Equal(t, Group{Dimension: DimensionRegion, Key: "WA", Suppressed: true}, group(t, report, DimensionRegion, "WA"))
+
+	// The smallest other region is suppressed too, so that WA cannot be derived from the total
+	assert.True(t, group(t, report, DimensionRegion, "NY").Suppressed)
+	assert.False(t, group(t, report, DimensionRegion, "CA").Suppressed)
+
+	// Statuses without families are not suppressed
+	assert.True(t, group(t, report, DimensionStatus, string(entity.Married)).Suppressed)
+	assert.True(t, group(t, report, DimensionStatus, string(entity.Single)).Suppressed)
+	assert.False(t, group(t, report, DimensionStatus, string(entity.Divorced)).Suppressed)
+}
+
+func TestReport_Write(t *testing.T) {
+	families := []*entity.Family{newFamily(t, 1, entity.Single, "", []int{1980}, []int{2015})}
+	report := Generate(families, Options{MinCellSize: 2}, now)
+
+	var csvOut bytes.Buffer
+	require.NoError(t, report.Write(&csvOut, FormatCSV))
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	require.Len(t, lines, 1+1+5+1)
+	assert.Equal(t, strings.Join(CSVHeader, ","), lines[0])
+	assert.Equal(t, "2025-06-01T00:00:00Z,total,ALL,,,,,,true", lines[1])
+	assert.Equal(t, "2025-06-01T00:00:00Z,status,MARRIED,0,0,0,0,0.0000,false", lines[3])
+
+	var jsonOut bytes.Buffer
+	require.NoError(t, report.Write(&jsonOut, FormatJSON))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(jsonOut.Bytes(), &decoded))
+	assert.Equal(t, *report, decoded)
+
+	// Reports never include the IDs or names of families and members
+	assert.NotContains(t, jsonOut.String(), "00000000-0000")
+	assert.NotContains(t, jsonOut.String(), "Doe")
+
+	assert.Error(t, report.Write(&jsonOut, "xml"))
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package services
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+)
+
+// CountFamilies counts the families in the repository, which counts them without reading
+// them. Quarantined families are left out unless the caller is an administrator.
+func (s *FamilyDomainService) CountFamilies(ctx context.Context, admin bool) (int, error) {
+	count, err := s.repo.CountFamilies(ctx)
+	if err != nil {
+		return 0, errorswrapper.NewDatabaseError("failed to count families", "query", "families", err)
+	}
+
+	hidden, err := s.hiddenFamilies(ctx, admin)
+	if err != nil {
+		return 0, err
+	}
+	return count - len(hidden), nil
+}
+
+// CountParents counts the distinct parents in the repository. Unless the caller is an
+// administrator, parents who belong only to quarantined families are left out.
+func (s *FamilyDomainService) CountParents(ctx context.Context, admin bool) (int, error) {
+	count, err := s.repo.CountParents(ctx)
+	if err != nil {
+		return 0, errorswrapper.NewDatabaseError("failed to count parents", "query", "families", err)
+	}
+
+	hidden, err := s.hiddenMembers(ctx, admin, query.FieldParentID, func(fam *entity.Family) []string {
+		ids := make([]string, 0, fam.CountParents())
+		for _, p := range fam.Parents() {
+			ids = append(ids, p.ID())
+		}
+		return ids
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count - hidden, nil
+}
+
+// CountChildren counts the distinct children in the repository. Unless the caller is an
+// administrator, children who belong only to quarantined families are left out.
+func (s *FamilyDomainService) CountChildren(ctx context.Context, admin bool) (int, error) {
+	count, err := s.repo.CountChildren(ctx)
+	if err != nil {
+		return 0, errorswrapper.NewDatabaseError("failed to count children", "query", "families", err)
+	}
+
+	hidden, err := s.hiddenMembers(ctx, admin, query.FieldChildID, func(fam *entity.Family) []string {
+		ids := make([]string, 0, fam.CountChildren())
+		for _, c := range fam.Children() {
+			ids = append(ids, c.ID())
+		}
+		return ids
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count - hidden, nil
+}
+
+// hiddenFamilies returns the quarantined families that exist, which are hidden from callers
+// that are not administrators. It returns none for administrators.
+func (s *FamilyDomainService) hiddenFamilies(ctx context.Context, admin bool) ([]*entity.Family, error) {
+	if admin || s.quarantines == nil {
+		return nil, nil
+	}
+	quarantines, err := s.quarantines.ListFamilyQuarantines(ctx)
+	if err != nil {
+		return nil, errorswrapper.NewDatabaseError("failed to list family quarantines", "query", "quarantines", err)
+	}
+	if len(quarantines) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(quarantines))
+	for i, q := range quarantines {
+		ids[i] = q.FamilyID
+	}
+	families, err := ports.GetFamiliesByID(ctx, s.repo, ids)
+	if err != nil {
+		return nil, errorswrapper.NewDatabaseError("failed to retrieve quarantined families", "query", "families", err)
+	}
+	return families, nil
+}
+
+// hiddenMembers counts the members of the hidden families that belong to no other family,
+// whose IDs are returned by members and matched by field. Only the families of those
+// members are read.
+func (s *FamilyDomainService) hiddenMembers(ctx context.Context, admin bool, field query.Field, members func(*entity.Family) []string) (int, error) {
+	hidden, err := s.hiddenFamilies(ctx, admin)
+	if err != nil || len(hidden) == 0 {
+		return 0, err
+	}
+
+	quarantined := make(map[string]bool, len(hidden))
+	candidates := make(map[string]bool)
+	for _, fam := range hidden {
+		quarantined[fam.ID()] = true
+		for _, id := range members(fam) {
+			candidates[id] = true
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+	families, err := s.repo.Find(ctx, query.In(field, ids))
+	if err != nil {
+		return 0, errorswrapper.NewDatabaseError("failed to find families of quarantined members", "query", "families", err)
+	}
+
+	// A member of a family that is not quarantined is visible
+	for _, fam := range families {
+		if quarantined[fam.ID()] {
+			continue
+		}
+		for _, id := range members(fam) {
+			delete(candidates, id)
+		}
+	}
+	return len(candidates), nil
+}
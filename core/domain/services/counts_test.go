@@ -0,0 +1,77 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports/mock"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/loggingwrapper"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestCounts_Quarantined leaves the quarantined families, and the members that belong only
+// to them, out of the counts of callers that are not administrators
+func TestCounts_Quarantined(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	birthDate := time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+	newParent := func(id, firstName string) *entity.Parent {
+		p, err := entity.NewParent(id, firstName, "Doe", birthDate, nil)
+		require.NoError(t, err)
+		return p
+	}
+	child, err := entity.NewChild("c1a2b3c4-0000-4000-8000-000000000001", "Ann", "Doe", birthDate.AddDate(30, 0, 0), nil)
+	require.NoError(t, err)
+
+	// The quarantined family shares a parent with a visible family
+	sharedID, hiddenID := "a1a2b3c4-0000-4000-8000-000000000001", "a1a2b3c4-0000-4000-8000-000000000002"
+	quarantinedID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	quarantined, err := entity.NewFamily(quarantinedID, entity.Married,
+		[]*entity.Parent{newParent(sharedID, "John"), newParent(hiddenID, "Jane")}, []*entity.Child{child})
+	require.NoError(t, err)
+	visible, err := entity.NewFamily("a47ac10b-58cc-4372-a567-0e02b2c3d479", entity.Single, []*entity.Parent{newParent(sharedID, "John")}, nil)
+	require.NoError(t, err)
+
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	mockRepo.EXPECT().CountFamilies(gomock.Any()).Return(5, nil).Times(2)
+	mockRepo.EXPECT().CountParents(gomock.Any()).Return(4, nil).Times(2)
+	mockRepo.EXPECT().CountChildren(gomock.Any()).Return(3, nil).Times(2)
+	mockRepo.EXPECT().GetByID(gomock.Any(), quarantinedID).Return(quarantined, nil).AnyTimes()
+	mockRepo.EXPECT().Find(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, filter query.Filter) ([]*entity.Family, error) {
+		var matched []*entity.Family
+		for _, fam := range []*entity.Family{quarantined, visible} {
+			if query.Match(filter, fam) {
+				matched = append(matched, fam)
+			}
+		}
+		return matched, nil
+	}).AnyTimes()
+
+	svc := NewFamilyDomainService(mockRepo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	quarantines := newMemoryQuarantines()
+	quarantines.quarantines[quarantinedID] = entity.Quarantine{FamilyID: quarantinedID}
+	svc.SetQuarantineRepository(quarantines)
+	ctx := context.Background()
+
+	counts := func(admin bool) []int {
+		families, err := svc.CountFamilies(ctx, admin)
+		require.NoError(t, err)
+		parents, err := svc.CountParents(ctx, admin)
+		require.NoError(t, err)
+		children, err := svc.CountChildren(ctx, admin)
+		require.NoError(t, err)
+		return []int{families, parents, children}
+	}
+
+	assert.Equal(t, []int{5, 4, 3}, counts(true))
+	assert.Equal(t, []int{4, 3, 2}, counts(false))
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/metrics"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"go.uber.org/zap"
+)
+
+// DeleteFamily deletes a family. A soft delete marks the family as deleted and keeps it,
+// left out of every read, so that it can be recovered from the database; a hard delete
+// removes it for good, with its quarantine. Either deletion stores a FamilyDeleted event in
+// the outbox with the deletion and publishes it.
+//
+// Parameters:
+//   - ctx: The context of the operation
+//   - familyID: ID of the family to delete
+//   - hard: Whether the family is removed for good rather than marked as deleted
+//
+// Returns:
+//   - The time of the deletion
+//   - A ValidationError if the ID is missing, a NotFoundError if the family does not exist
+//     or is already deleted, or a DatabaseError if the repository cannot delete families or
+//     the deletion fails
+func (s *FamilyDomainService) DeleteFamily(ctx context.Context, familyID string, hard bool) (time.Time, error) {
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.DeleteFamily")
+	defer span.End()
+
+	if familyID == "" {
+		return time.Time{}, errorswrapper.NewValidationError("family ID is required", "id", nil)
+	}
+
+	deleter, ok := s.repo.(ports.FamilyDeleter)
+	if !ok {
+		metrics.FamilyOperationsTotal.WithLabelValues("delete_family", metrics.StatusFailure).Inc()
+		return time.Time{}, errorswrapper.NewDatabaseError("the repository cannot delete families", "delete", "families", nil)
+	}
+
+	at := clock.Now(ctx).UTC()
+	remove := s.newSaga("delete_family",
+		sagaStep{
+			name: "delete family",
+			action: func(ctx context.Context) error {
+				var deleted bool
+				var err error
+				if hard {
+					deleted, err = deleter.HardDeleteFamily(ctx, familyID)
+				} else {
+					deleted, err = deleter.SoftDeleteFamily(ctx, familyID, at)
+				}
+				if err == nil && !deleted {
+					return errorswrapper.NewNotFoundError("Family", familyID, nil)
+				}
+				return err
+			},
+		},
+	).raise(events.FamilyDeleted{FamilyID: familyID, Hard: hard, At: at})
+	if _, err := remove.run(ctx); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("delete_family", metrics.StatusFailure).Inc()
+		if errorswrapper.IsNotFoundError(err) || errors.Is(err, domainerrors.ErrMutationTimedOut) {
+			return time.Time{}, err
+		}
+		s.logger.Error(ctx, "Failed to delete family", zap.Error(err), zap.String("family_id", familyID), zap.Bool("hard", hard))
+		return time.Time{}, errorswrapper.NewDatabaseError("failed to delete family", "delete", "families", err)
+	}
+
+	// A family removed for good has nothing left to quarantine
+	if hard && s.quarantines != nil {
+		if _, err := s.quarantines.DeleteFamilyQuarantine(ctx, familyID); err != nil {
+			s.logger.Warn(ctx, "Failed to delete the quarantine of a hard deleted family", zap.Error(err), zap.String("family_id", familyID))
+		}
+	}
+
+	metrics.FamilyOperationsTotal.WithLabelValues("delete_family", metrics.StatusSuccess).Inc()
+	s.logger.Info(ctx, "Deleted family", zap.String("family_id", familyID), zap.Bool("hard", hard))
+	s.publishStored(ctx, events.FamilyDeleted{FamilyID: familyID, Hard: hard, At: at})
+	return at, nil
+}
+
+// RestoreFamily restores a soft deleted family, so that it is read again as it was before
+// its deletion, and stores a FamilyRestored event in the outbox with the restore and
+// publishes it.
+//
+// Parameters:
+//   - ctx: The context of the operation
+//   - familyID: ID of the family to restore
+//
+// Returns:
+//   - The time of the restore
+//   - A ValidationError if the ID is missing, a NotFoundError if there is no deleted family
+//     with the ID, or a DatabaseError if the repository cannot restore families or the
+//     restore fails
+func (s *FamilyDomainService) RestoreFamily(ctx context.Context, familyID string) (time.Time, error) {
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.RestoreFamily")
+	defer span.End()
+
+	if familyID == "" {
+		return time.Time{}, errorswrapper.NewValidationError("family ID is required", "id", nil)
+	}
+
+	restorer, ok := s.repo.(ports.FamilyRestorer)
+	if !ok {
+		metrics.FamilyOperationsTotal.WithLabelValues("restore_family", metrics.StatusFailure).Inc()
+		return time.Time{}, errorswrapper.NewDatabaseError("the repository cannot restore families", "update", "families", nil)
+	}
+
+	at := clock.Now(ctx).UTC()
+	restore := s.newSaga("restore_family",
+		sagaStep{
+			name: "restore family",
+			action: func(ctx context.Context) error {
+				restored, err := restorer.RestoreFamily(ctx, familyID)
+				if err == nil && !restored {
+					return errorswrapper.NewNotFoundError("Family", familyID, nil)
+				}
+				return err
+			},
+		},
+	).raise(events.FamilyRestored{FamilyID: familyID, At: at})
+	if _, err := restore.run(ctx); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("restore_family", metrics.StatusFailure).Inc()
+		if errorswrapper.IsNotFoundError(err) || errors.Is(err, domainerrors.ErrMutationTimedOut) {
+			return time.Time{}, err
+		}
+		s.logger.Error(ctx, "Failed to restore family", zap.Error(err), zap.String("family_id", familyID))
+		return time.Time{}, errorswrapper.NewDatabaseError("failed to restore family", "update", "families", err)
+	}
+
+	metrics.FamilyOperationsTotal.WithLabelValues("restore_family", metrics.StatusSuccess).Inc()
+	s.logger.Info(ctx, "Restored family", zap.String("family_id", familyID))
+	s.publishStored(ctx, events.FamilyRestored{FamilyID: familyID, At: at})
+	return at, nil
+}
+
+// PurgeDeletedFamilies removes for good the families that were soft deleted before a time,
+// with their quarantines, and stores a FamilyDeleted event, as for a hard delete, in the
+// outbox with the purge for each of them and publishes it.
+//
+// Parameters:
+//   - ctx: The context of the operation
+//   - deletedBefore: The families deleted before this time are purged
+//   - allTenants: Whether the families of every tenant are purged, rather than those of
+//     the tenant of the context
+//
+// Returns:
+//   - The IDs of the purged families, in ascending order
+//   - A DatabaseError if the repository cannot purge families or the purge fails
+func (s *FamilyDomainService) PurgeDeletedFamilies(ctx context.Context, deletedBefore time.Time, allTenants bool) ([]string, error) {
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.PurgeDeletedFamilies")
+	defer span.End()
+
+	restorer, ok := s.repo.(ports.FamilyRestorer)
+	if !ok {
+		metrics.FamilyOperationsTotal.WithLabelValues("purge_deleted_families", metrics.StatusFailure).Inc()
+		return nil, errorswrapper.NewDatabaseError("the repository cannot purge families", "delete", "families", nil)
+	}
+
+	at := clock.Now(ctx).UTC()
+	var ids []string
+	var purge *saga
+	purge = s.newSaga("purge_deleted_families",
+		sagaStep{
+			name: "purge deleted families",
+			action: func(ctx context.Context) error {
+				var err error
+				if ids, err = restorer.PurgeDeletedFamilies(ctx, deletedBefore, allTenants); err != nil {
+					return err
+				}
+				for _, id := range ids {
+					purge.raise(events.FamilyDeleted{FamilyID: id, Hard: true, At: at})
+				}
+				return nil
+			},
+		},
+	)
+	if _, err := purge.run(ctx); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("purge_deleted_families", metrics.StatusFailure).Inc()
+		if errors.Is(err, domainerrors.ErrMutationTimedOut) {
+			return nil, err
+		}
+		s.logger.Error(ctx, "Failed to purge deleted families", zap.Error(err), zap.Time("deleted_before", deletedBefore))
+		return nil, errorswrapper.NewDatabaseError("failed to purge deleted families", "delete", "families", err)
+	}
+
+	for _, id := range ids {
+		// A family removed for good has nothing left to quarantine
+		if s.quarantines != nil {
+			if _, err := s.quarantines.DeleteFamilyQuarantine(ctx, id); err != nil {
+				s.logger.Warn(ctx, "Failed to delete the quarantine of a purged family", zap.Error(err), zap.String("family_id", id))
+			}
+		}
+	}
+	for _, event := range purge.events {
+		s.publishStored(ctx, event)
+	}
+
+	metrics.FamilyOperationsTotal.WithLabelValues("purge_deleted_families", metrics.StatusSuccess).Inc()
+	s.logger.Info(ctx, "Purged deleted families", zap.Int("family_count", len(ids)), zap.Time("deleted_before", deletedBefore))
+	return ids, nil
+}
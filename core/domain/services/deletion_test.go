@@ -0,0 +1,288 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/ports/mock"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/loggingwrapper"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// deletingRepository is a repository that deletes the families it keeps in memory
+type deletingRepository struct {
+	*mock.MockFamilyRepository
+	deletedAt map[string]*time.Time // Time of the deletion of each family, nil if not deleted
+	err       error
+}
+
+func (r *deletingRepository) SoftDeleteFamily(_ context.Context, familyID string, deletedAt time.Time) (bool, error) {
+	if r.err != nil {
+		return false, r.err
+	}
+	at, ok := r.deletedAt[familyID]
+	if !ok || at != nil {
+		return false, nil
+	}
+	r.deletedAt[familyID] = &deletedAt
+	return true, nil
+}
+
+func (r *deletingRepository) HardDeleteFamily(_ context.Context, familyID string) (bool, error) {
+	if r.err != nil {
+		return false, r.err
+	}
+	_, ok := r.deletedAt[familyID]
+	delete(r.deletedAt, familyID)
+	return ok, nil
+}
+
+func (r *deletingRepository) RestoreFamily(_ context.Context, familyID string) (bool, error) {
+	if r.err != nil {
+		return false, r.err
+	}
+	if at, ok := r.deletedAt[familyID]; !ok || at == nil {
+		return false, nil
+	}
+	r.deletedAt[familyID] = nil
+	return true, nil
+}
+
+func (r *deletingRepository) PurgeDeletedFamilies(_ context.Context, deletedBefore time.Time, _ bool) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var ids []string
+	for id, at := range r.deletedAt {
+		if at != nil && at.Before(deletedBefore) {
+			ids = append(ids, id)
+			delete(r.deletedAt, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// transactionalDeletingRepository is a deleting repository that records the units of work
+// it runs
+type transactionalDeletingRepository struct {
+	*deletingRepository
+	units *transactionalRepository
+}
+
+func (r *transactionalDeletingRepository) Begin(ctx context.Context) (context.Context, error) {
+	return r.units.Begin(ctx)
+}
+
+func (r *transactionalDeletingRepository) Commit(ctx context.Context) error {
+	return r.units.Commit(ctx)
+}
+
+func (r *transactionalDeletingRepository) Rollback(ctx context.Context) error {
+	return r.units.Rollback(ctx)
+}
+
+func TestDeleteFamily(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	repo := &deletingRepository{
+		MockFamilyRepository: mock.NewMockFamilyRepository(ctrl),
+		deletedAt:            map[string]*time.Time{familyID: nil},
+	}
+	svc := NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	quarantines := newMemoryQuarantines()
+	quarantines.quarantines[familyID] = entity.Quarantine{FamilyID: familyID, Reason: "fraud"}
+	svc.SetQuarantineRepository(quarantines)
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+	at := time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC)
+	ctx := clock.Freeze(context.Background(), at)
+
+	// A soft delete marks the family and keeps its quarantine
+	deletedAt, err := svc.DeleteFamily(ctx, familyID, false)
+	require.NoError(t, err)
+	assert.True(t, at.Equal(deletedAt))
+	require.NotNil(t, repo.deletedAt[familyID])
+	assert.True(t, at.Equal(*repo.deletedAt[familyID]))
+	assert.Contains(t, quarantines.quarantines, familyID)
+
+	// A deleted family cannot be soft deleted again, but can be removed for good
+	_, err = svc.DeleteFamily(ctx, familyID, false)
+	assert.True(t, errorswrapper.IsNotFoundError(err))
+	_, err = svc.DeleteFamily(ctx, familyID, true)
+	require.NoError(t, err)
+	assert.NotContains(t, repo.deletedAt, familyID)
+	assert.NotContains(t, quarantines.quarantines, familyID)
+
+	_, err = svc.DeleteFamily(ctx, familyID, true)
+	assert.True(t, errorswrapper.IsNotFoundError(err))
+
+	assert.Equal(t, []events.Event{
+		events.FamilyDeleted{FamilyID: familyID, Hard: false, At: at},
+		events.FamilyDeleted{FamilyID: familyID, Hard: true, At: at},
+	}, publisher.events)
+}
+
+func TestDeleteFamily_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	ctx := context.Background()
+	logger := loggingwrapper.NewContextLogger(zaptest.NewLogger(t))
+
+	// A repository that cannot delete families fails every deletion
+	svc := NewFamilyDomainService(mock.NewMockFamilyRepository(ctrl), logger)
+	_, err := svc.DeleteFamily(ctx, familyID, false)
+	assert.True(t, errorswrapper.IsDatabaseError(err))
+
+	repo := &deletingRepository{
+		MockFamilyRepository: mock.NewMockFamilyRepository(ctrl),
+		deletedAt:            map[string]*time.Time{familyID: nil},
+	}
+	svc = NewFamilyDomainService(repo, logger)
+	_, err = svc.DeleteFamily(ctx, "", false)
+	assert.True(t, errorswrapper.IsValidationError(err))
+
+	repo.err = errors.New("connection refused")
+	_, err = svc.DeleteFamily(ctx, familyID, true)
+	assert.True(t, errorswrapper.IsDatabaseError(err))
+	assert.Contains(t, repo.deletedAt, familyID)
+}
+
+func TestRestoreFamily(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	deletedAt := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+	repo := &deletingRepository{
+		MockFamilyRepository: mock.NewMockFamilyRepository(ctrl),
+		deletedAt:            map[string]*time.Time{familyID: &deletedAt},
+	}
+	svc := NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+	at := time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC)
+	ctx := clock.Freeze(context.Background(), at)
+
+	restoredAt, err := svc.RestoreFamily(ctx, familyID)
+	require.NoError(t, err)
+	assert.True(t, at.Equal(restoredAt))
+	assert.Nil(t, repo.deletedAt[familyID])
+
+	// A family that is not deleted, or does not exist, cannot be restored
+	_, err = svc.RestoreFamily(ctx, familyID)
+	assert.True(t, errorswrapper.IsNotFoundError(err))
+	_, err = svc.RestoreFamily(ctx, "missing")
+	assert.True(t, errorswrapper.IsNotFoundError(err))
+	_, err = svc.RestoreFamily(ctx, "")
+	assert.True(t, errorswrapper.IsValidationError(err))
+
+	assert.Equal(t, []events.Event{events.FamilyRestored{FamilyID: familyID, At: at}}, publisher.events)
+}
+
+func TestPurgeDeletedFamilies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	old := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, time.February, 28, 0, 0, 0, 0, time.UTC)
+	repo := &deletingRepository{
+		MockFamilyRepository: mock.NewMockFamilyRepository(ctrl),
+		deletedAt:            map[string]*time.Time{"old": &old, "recent": &recent, "kept": nil},
+	}
+	svc := NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	quarantines := newMemoryQuarantines()
+	quarantines.quarantines["old"] = entity.Quarantine{FamilyID: "old", Reason: "fraud"}
+	svc.SetQuarantineRepository(quarantines)
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+	at := time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC)
+	ctx := clock.Freeze(context.Background(), at)
+
+	// Only the families deleted before the time are purged, with their quarantines
+	ids, err := svc.PurgeDeletedFamilies(ctx, at.AddDate(0, 0, -30), true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old"}, ids)
+	assert.NotContains(t, repo.deletedAt, "old")
+	assert.Contains(t, repo.deletedAt, "recent")
+	assert.Contains(t, repo.deletedAt, "kept")
+	assert.NotContains(t, quarantines.quarantines, "old")
+	assert.Equal(t, []events.Event{events.FamilyDeleted{FamilyID: "old", Hard: true, At: at}}, publisher.events)
+
+	repo.err = errors.New("connection refused")
+	_, err = svc.PurgeDeletedFamilies(ctx, at, true)
+	assert.True(t, errorswrapper.IsDatabaseError(err))
+
+	// A repository that cannot purge families fails every purge
+	svc = NewFamilyDomainService(mock.NewMockFamilyRepository(ctrl), loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	_, err = svc.PurgeDeletedFamilies(ctx, at, true)
+	assert.True(t, errorswrapper.IsDatabaseError(err))
+}
+
+func TestDeletionStoresEventsInOutbox(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	units := &transactionalRepository{}
+	repo := &transactionalDeletingRepository{
+		deletingRepository: &deletingRepository{
+			MockFamilyRepository: mock.NewMockFamilyRepository(ctrl),
+			deletedAt:            map[string]*time.Time{familyID: nil},
+		},
+		units: units,
+	}
+	svc := NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	publisher := &recordingPublisher{}
+	outbox := &unitOutbox{}
+	svc.SetEventPublisher(publisher)
+	svc.SetEventOutbox(outbox)
+	at := time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC)
+	ctx := clock.Freeze(context.Background(), at)
+
+	// Each change stores its event in its unit of work
+	_, err := svc.DeleteFamily(ctx, familyID, false)
+	require.NoError(t, err)
+	_, err = svc.RestoreFamily(ctx, familyID)
+	require.NoError(t, err)
+	_, err = svc.DeleteFamily(ctx, familyID, false)
+	require.NoError(t, err)
+	_, err = svc.PurgeDeletedFamilies(ctx, at.Add(time.Hour), true)
+	require.NoError(t, err)
+
+	want := []events.Event{
+		events.FamilyDeleted{FamilyID: familyID, Hard: false, At: at},
+		events.FamilyRestored{FamilyID: familyID, At: at},
+		events.FamilyDeleted{FamilyID: familyID, Hard: false, At: at},
+		events.FamilyDeleted{FamilyID: familyID, Hard: true, At: at},
+	}
+	assert.Equal(t, want, outbox.events)
+	assert.Equal(t, []interface{}{1, 2, 3, 4}, outbox.units, "each event is stored in the unit of work of its change")
+	assert.Equal(t, 4, units.committed)
+	assert.Equal(t, want, publisher.events, "each event is published once, not stored again")
+
+	// A family that does not exist rolls back the unit of work and stores no event
+	_, err = svc.DeleteFamily(ctx, familyID, true)
+	assert.True(t, errorswrapper.IsNotFoundError(err))
+	_, err = svc.RestoreFamily(ctx, familyID)
+	assert.True(t, errorswrapper.IsNotFoundError(err))
+	assert.Len(t, outbox.events, 4)
+	assert.Len(t, publisher.events, 4)
+	assert.Equal(t, 2, units.rolledBack)
+}
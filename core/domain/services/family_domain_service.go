@@ -4,12 +4,20 @@
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/abitofhelp/family-service/core/domain/clock"
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/events"
 	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
 	"github.com/abitofhelp/family-service/core/domain/metrics"
+	"github.com/abitofhelp/family-service/core/domain/policy"
 	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/core/domain/reporting"
+	"github.com/abitofhelp/family-service/core/domain/workload"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/loggingwrapper"
 	"go.opentelemetry.io/otel"
@@ -19,9 +27,16 @@
 
 // FamilyDomainService is a domain service that coordinates operations on the Family aggregate
 type FamilyDomainService struct {
-	repo   ports.FamilyRepository
-	logger *loggingwrapper.ContextLogger
-	tracer trace.Tracer
+	repo      ports.FamilyRepository
+	logger    *loggingwrapper.ContextLogger
+	tracer    trace.Tracer
+	agePolicy *policy.AgePolicy
+	publisher ports.EventPublisher
+	outbox    ports.EventPublisher
+
+	consentPolicy   *policy.ConsentPolicy
+	duplicatePolicy *policy.DuplicatePolicy
+	quarantines     ports.QuarantineRepository
 }
 
 // NewFamilyDomainService creates a new FamilyDomainService
@@ -39,6 +54,341 @@ func NewFamilyDomainService(repo ports.FamilyRepository, logger *loggingwrapper.
 	}
 }
 
+// SetEventPublisher sets the publisher of domain events (nil disables publishing)
+func (s *FamilyDomainService) SetEventPublisher(publisher ports.EventPublisher) {
+	s.publisher = publisher
+}
+
+// SetEventOutbox sets the outbox of domain events (nil disables it). The events raised by
+// the saves of a saga are stored in the outbox in the unit of work of the saves, so that
+// they are stored if and only if the saves are; the other events, which change nothing
+// else, are stored in a unit of work of their own. A relay publishes the events of the
+// outbox.
+func (s *FamilyDomainService) SetEventOutbox(outbox ports.EventPublisher) {
+	s.outbox = outbox
+}
+
+// publish publishes a domain event that records an operation without changes, such as
+// an access, and stores it in the outbox in a unit of work of its own. The operation has
+// already happened, so a failure to publish is logged rather than returned.
+func (s *FamilyDomainService) publish(ctx context.Context, event events.Event) {
+	if s.outbox != nil {
+		if step, err := s.newSaga(event.Name()).raise(event).run(ctx); err != nil {
+			s.logger.Error(ctx, "Failed to store domain event in outbox",
+				zap.Error(err),
+				zap.String("event", event.Name()),
+				zap.String("step", step))
+		}
+	}
+	s.publishStored(ctx, event)
+}
+
+// publishStored publishes a domain event that a saga has stored in the outbox
+func (s *FamilyDomainService) publishStored(ctx context.Context, event events.Event) {
+	if s.publisher == nil {
+		return
+	}
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		s.logger.Error(ctx, "Failed to publish domain event",
+			zap.Error(err),
+			zap.String("event", event.Name()))
+	}
+}
+
+// eachFamily calls fn with every stored family and returns the number of families read.
+// Repositories that implement ports.FamilyExporter export the families without holding
+// them all in memory; the others read them with GetAll.
+func (s *FamilyDomainService) eachFamily(ctx context.Context, fn func(*entity.Family) error) (int, error) {
+	count := 0
+	visit := func(fam *entity.Family) error {
+		count++
+		return fn(fam)
+	}
+
+	if exporter, ok := s.repo.(ports.FamilyExporter); ok {
+		return count, exporter.ExportAll(ctx, visit)
+	}
+
+	families, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, fam := range families {
+		if err := visit(fam); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// exportPageSize is the number of families read at a time by an export resumed from a
+// repository that implements ports.FamilyPager
+const exportPageSize = 100
+
+// eachFamilyAfter calls fn with every stored family whose ID sorts after the given ID and
+// returns the number of families read; an empty after calls fn with every family, as
+// eachFamily does. Repositories that implement ports.FamilyPager read only the families
+// after the ID, a page at a time; the others read every family and skip those before it.
+func (s *FamilyDomainService) eachFamilyAfter(ctx context.Context, after string, fn func(*entity.Family) error) (int, error) {
+	if after == "" {
+		return s.eachFamily(ctx, fn)
+	}
+
+	if pager, ok := s.repo.(ports.FamilyPager); ok {
+		count := 0
+		for {
+			families, err := pager.ListFamilies(ctx, after, exportPageSize)
+			if err != nil {
+				return count, err
+			}
+			for _, fam := range families {
+				count++
+				if err := fn(fam); err != nil {
+					return count, err
+				}
+			}
+			if len(families) < exportPageSize {
+				return count, nil
+			}
+			after = families[len(families)-1].ID()
+		}
+	}
+
+	return s.eachFamily(ctx, func(fam *entity.Family) error {
+		if fam.ID() <= after {
+			return nil
+		}
+		return fn(fam)
+	})
+}
+
+// ListFamilies returns at most limit families whose IDs sort after the given ID, in
+// ascending ID order; an empty after starts with the first family. Repositories that
+// implement ports.FamilyPager read only the page; the others read every family with GetAll.
+func (s *FamilyDomainService) ListFamilies(ctx context.Context, after string, limit int) ([]*entity.Family, error) {
+	if pager, ok := s.repo.(ports.FamilyPager); ok {
+		return pager.ListFamilies(ctx, after, limit)
+	}
+
+	families, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return query.Page(families, after, limit), nil
+}
+
+// FindFamilies returns the families that match a filter, in the given order. The
+// repository evaluates the filter, so that only the matching families are read.
+func (s *FamilyDomainService) FindFamilies(ctx context.Context, filter query.Filter, order query.Order) ([]*entity.Family, error) {
+	if err := query.ValidateOrder(order); err != nil {
+		return nil, err
+	}
+
+	families, err := s.repo.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	query.Sort(families, order)
+	return families, nil
+}
+
+// FindFamiliesPage returns at most limit families that match a filter and whose IDs sort
+// after the given ID, in ascending ID order. Repositories that implement
+// ports.FamilyFilterPager read only the page; the others find every matching family.
+func (s *FamilyDomainService) FindFamiliesPage(ctx context.Context, filter query.Filter, after string, limit int) ([]*entity.Family, error) {
+	return ports.FindFamiliesPage(ctx, s.repo, filter, after, limit)
+}
+
+// GetFamilies returns the families with the given IDs in ascending ID order, leaving out the
+// IDs of missing families. Repositories that implement ports.FamilyBatchReader read them
+// together; the others read each family with GetByID.
+func (s *FamilyDomainService) GetFamilies(ctx context.Context, ids []string) ([]*entity.Family, error) {
+	return ports.GetFamiliesByID(ctx, s.repo, ids)
+}
+
+// SetAgePolicy sets the parent-child age plausibility policy (nil disables it)
+func (s *FamilyDomainService) SetAgePolicy(agePolicy *policy.AgePolicy) {
+	s.agePolicy = agePolicy
+}
+
+// SetConsentPolicy sets the policy for consents to store and return the data of minors
+// (nil uses the default maximum consent age when expiring consents)
+func (s *FamilyDomainService) SetConsentPolicy(consentPolicy *policy.ConsentPolicy) {
+	s.consentPolicy = consentPolicy
+}
+
+// SetDuplicatePolicy sets the policy for new families with the same members as stored
+// families (nil disables duplicate detection)
+func (s *FamilyDomainService) SetDuplicatePolicy(duplicatePolicy *policy.DuplicatePolicy) {
+	s.duplicatePolicy = duplicatePolicy
+}
+
+// EnforceDuplicatePolicy finds the stored families with the same members as a new family.
+// Duplicates are logged and counted unless the policy is off; when it rejects them, they are
+// also returned as a FamilyDuplicateError. The IDs of the duplicates are returned so that the
+// caller can link the family to them once it is created.
+func (s *FamilyDomainService) EnforceDuplicatePolicy(ctx context.Context, fam *entity.Family) ([]string, error) {
+	action := s.duplicatePolicy.Action()
+	if action == policy.DuplicateOff {
+		return nil, nil
+	}
+
+	candidates, err := s.repo.Find(ctx, s.duplicatePolicy.CandidateFilter(fam))
+	if err != nil {
+		if action == policy.DuplicateReject {
+			return nil, errorswrapper.NewDatabaseError("failed to check for duplicate families", "find", "families", err)
+		}
+		s.logger.Warn(ctx, "Failed to check for duplicate families, creating the family", zap.Error(err), zap.String("family_id", fam.ID()))
+		return nil, nil
+	}
+
+	duplicates := s.duplicatePolicy.Duplicates(fam, candidates)
+	if len(duplicates) == 0 {
+		return nil, nil
+	}
+
+	metrics.BusinessRuleViolations.WithLabelValues("duplicate_family").Inc()
+	s.logger.Warn(ctx, "Family duplicates stored families",
+		zap.String("action", string(action)),
+		zap.String("family_id", fam.ID()),
+		zap.Strings("duplicate_of", duplicates))
+
+	if action == policy.DuplicateReject {
+		return duplicates, domainerrors.NewFamilyDuplicateError(
+			"family has the same members as family "+strings.Join(duplicates, ", "), nil)
+	}
+	return duplicates, nil
+}
+
+// EnforceAgePolicy checks the family against the age plausibility policy.
+// Violations are logged and counted in warn mode; in block mode they are also returned as a ValidationError.
+func (s *FamilyDomainService) EnforceAgePolicy(ctx context.Context, fam *entity.Family, operation string) error {
+	violations, err := s.agePolicy.Check(fam)
+	for _, v := range violations {
+		metrics.BusinessRuleViolations.WithLabelValues(string(v.Rule)).Inc()
+		s.logger.Warn(ctx, "Age plausibility policy violated",
+			zap.String("operation", operation),
+			zap.String("mode", string(s.agePolicy.Mode())),
+			zap.String("family_id", v.FamilyID),
+			zap.String("parent_id", v.ParentID),
+			zap.String("child_id", v.ChildID),
+			zap.String("rule", string(v.Rule)),
+			zap.Int("parent_age_at_birth", v.ParentAgeAtBirth))
+	}
+	return err
+}
+
+// FindAgePolicyViolations reports the age plausibility policy violations in all stored families.
+// The report is produced whatever the policy mode, so that existing data can be reviewed before
+// the policy is enforced. If no policy is set, the default minimum age is used.
+func (s *FamilyDomainService) FindAgePolicyViolations(ctx context.Context) ([]policy.Violation, error) {
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.FindAgePolicyViolations")
+	defer span.End()
+
+	agePolicy := s.agePolicy
+	if agePolicy == nil {
+		agePolicy = policy.NewAgePolicy(policy.ModeOff, 0)
+	}
+
+	// The report reads every family, so it runs as analytics work
+	violations := []policy.Violation{}
+	count, err := s.eachFamily(workload.Analytics(ctx), func(fam *entity.Family) error {
+		violations = append(violations, agePolicy.Evaluate(fam)...)
+		return nil
+	})
+	if err != nil {
+		metrics.RepositoryOperationsTotal.WithLabelValues("get_all", metrics.StatusFailure).Inc()
+		s.logger.Error(ctx, "Failed to retrieve families for age policy report", zap.Error(err))
+		return nil, errorswrapper.NewDatabaseError("failed to retrieve families", "query", "families", err)
+	}
+	metrics.RepositoryOperationsTotal.WithLabelValues("get_all", metrics.StatusSuccess).Inc()
+
+	s.logger.Info(ctx, "Evaluated age plausibility policy",
+		zap.Int("family_count", count),
+		zap.Int("violation_count", len(violations)))
+	return violations, nil
+}
+
+// GenerateReport aggregates all stored families into a report that holds no personal data,
+// so that it can be shared with statistical agencies.
+func (s *FamilyDomainService) GenerateReport(ctx context.Context, opts reporting.Options) (*reporting.Report, error) {
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.GenerateReport")
+	defer span.End()
+
+	// The report reads every family, so it runs as analytics work, and aggregates the
+	// families as they are read
+	builder := reporting.NewBuilder(opts, clock.Now(ctx))
+	count, err := s.eachFamily(workload.Analytics(ctx), func(fam *entity.Family) error {
+		builder.Add(fam)
+		return nil
+	})
+	if err != nil {
+		metrics.RepositoryOperationsTotal.WithLabelValues("get_all", metrics.StatusFailure).Inc()
+		s.logger.Error(ctx, "Failed to retrieve families for report", zap.Error(err))
+		return nil, errorswrapper.NewDatabaseError("failed to retrieve families", "query", "families", err)
+	}
+	metrics.RepositoryOperationsTotal.WithLabelValues("get_all", metrics.StatusSuccess).Inc()
+
+	report := builder.Report()
+
+	s.logger.Info(ctx, "Generated family report",
+		zap.Int("family_count", count),
+		zap.Int("group_count", len(report.Groups)))
+	return report, nil
+}
+
+// ExportFamilies calls fn with every stored family whose ID sorts after the given ID, in
+// ascending ID order, and returns the number of families exported; an empty after exports
+// every family, so that an interrupted export is resumed after the last family it exported.
+// Repositories that implement ports.FamilyExporter stream the families without holding them
+// all in memory. Quarantined families are exported only to administrators, and every export
+// of one is recorded as for CheckFamilyAccess. An error from fn stops the export and is
+// returned as is.
+func (s *FamilyDomainService) ExportFamilies(ctx context.Context, admin bool, after string, fn func(*entity.Family) error) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.ExportFamilies")
+	defer span.End()
+
+	// The quarantines are read once, rather than for each family
+	quarantines, err := s.ListFamilyQuarantines(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list family quarantines for export", zap.Error(err))
+		return 0, err
+	}
+	quarantined := make(map[string]bool, len(quarantines))
+	for _, q := range quarantines {
+		quarantined[q.FamilyID] = true
+	}
+
+	// The export reads every family, so it runs as analytics work
+	exported := 0
+	var fnErr error
+	_, err = s.eachFamilyAfter(workload.Analytics(ctx), after, func(fam *entity.Family) error {
+		if quarantined[fam.ID()] {
+			s.recordQuarantinedAccess(ctx, fam.ID(), "ExportFamilies", admin)
+			if !admin {
+				return nil
+			}
+		}
+		if fnErr = fn(fam); fnErr != nil {
+			return fnErr
+		}
+		exported++
+		return nil
+	})
+	if fnErr != nil {
+		return exported, fnErr
+	}
+	if err != nil {
+		metrics.RepositoryOperationsTotal.WithLabelValues("get_all", metrics.StatusFailure).Inc()
+		s.logger.Error(ctx, "Failed to retrieve families for export", zap.Error(err))
+		return exported, errorswrapper.NewDatabaseError("failed to retrieve families", "query", "families", err)
+	}
+	metrics.RepositoryOperationsTotal.WithLabelValues("get_all", metrics.StatusSuccess).Inc()
+
+	s.logger.Info(ctx, "Exported families", zap.Int("family_count", exported))
+	return exported, nil
+}
+
 // CreateFamily creates a new family
 func (s *FamilyDomainService) CreateFamily(ctx context.Context, dto entity.FamilyDTO) (*entity.FamilyDTO, error) {
 	// Start a new span for this operation
@@ -61,11 +411,41 @@ func (s *FamilyDomainService) CreateFamily(ctx context.Context, dto entity.Famil
 		return nil, errorswrapper.NewValidationError("invalid family data", "family", err)
 	}
 
+	// Check the age plausibility policy
+	if err := s.EnforceAgePolicy(ctx, fam, "create_family"); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("create_family", metrics.StatusFailure).Inc()
+		return nil, err
+	}
+
+	// Check for stored families with the same members
+	duplicates, err := s.EnforceDuplicatePolicy(ctx, fam)
+	if err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("create_family", metrics.StatusFailure).Inc()
+		return nil, err
+	}
+
+	// Link the family to the stored families it may duplicate
+	var suspected []events.Event
+	if len(duplicates) > 0 && s.duplicatePolicy.Action() == policy.DuplicateLink {
+		suspected = append(suspected, events.FamilyDuplicateSuspected{
+			FamilyID:    fam.ID(),
+			DuplicateOf: duplicates,
+			Fingerprint: policy.Fingerprint(fam),
+			At:          clock.Now(ctx),
+		})
+	}
+
 	// Create a span for repository operation
 	ctx, saveSpan := s.tracer.Start(ctx, "Repository.Save")
 
-	// Save to repository
-	if err := s.repo.Save(ctx, fam); err != nil {
+	// Save to repository, with the events of the family in the outbox
+	create := s.newSaga("create_family",
+		sagaStep{
+			name:   "save family",
+			action: func(ctx context.Context) error { return s.repo.Save(ctx, fam) },
+		},
+	).raise(suspected...)
+	if _, err := create.run(ctx); err != nil {
 		// Record metrics for repository operation failure
 		metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusFailure).Inc()
 		saveSpan.End()
@@ -74,6 +454,9 @@ func (s *FamilyDomainService) CreateFamily(ctx context.Context, dto entity.Famil
 		metrics.FamilyOperationsTotal.WithLabelValues("create_family", metrics.StatusFailure).Inc()
 
 		s.logger.Error(ctx, "Failed to save family to repository", zap.Error(err), zap.String("family_id", fam.ID()))
+		if errors.Is(err, domainerrors.ErrMutationTimedOut) {
+			return nil, err
+		}
 		return nil, errorswrapper.NewDatabaseError("failed to create family", "save", "families", err)
 	}
 
@@ -89,6 +472,10 @@ func (s *FamilyDomainService) CreateFamily(ctx context.Context, dto entity.Famil
 	// Update family status counts
 	metrics.FamilyStatusCounts.WithLabelValues(string(fam.Status())).Inc()
 
+	for _, event := range suspected {
+		s.publishStored(ctx, event)
+	}
+
 	// Record metrics for operation success
 	metrics.FamilyOperationsTotal.WithLabelValues("create_family", metrics.StatusSuccess).Inc()
 	metrics.FamilyOperationsDuration.WithLabelValues("create_family").Observe(time.Since(startTime).Seconds())
@@ -253,9 +640,7 @@ func (s *FamilyDomainService) AddParent(ctx context.Context, familyID string, pa
 	if fam.Status() == entity.Single && len(fam.Parents()) == 2 {
 		statusChanged = true
 		s.logger.Info(ctx, "Updating family status from SINGLE to MARRIED", zap.String("family_id", familyID))
-		// Create a new family with updated status
-		updatedFam, err := entity.NewFamily(fam.ID(), entity.Married, fam.Parents(), fam.Children())
-		if err != nil {
+		if err := fam.Marry(); err != nil {
 			// Record metrics for operation failure
 			metrics.FamilyOperationsTotal.WithLabelValues("add_parent", metrics.StatusFailure).Inc()
 			updateStatusSpan.End()
@@ -265,11 +650,16 @@ func (s *FamilyDomainService) AddParent(ctx context.Context, familyID string, pa
 				zap.String("family_id", familyID))
 			return nil, domainerrors.NewFamilyStatusUpdateFailedError("failed to update family status", err)
 		}
-		fam = updatedFam
 	}
 
 	updateStatusSpan.End()
 
+	// Check the age plausibility policy
+	if err := s.EnforceAgePolicy(ctx, fam, "add_parent"); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("add_parent", metrics.StatusFailure).Inc()
+		return nil, err
+	}
+
 	// Create a span for saving the family
 	ctx, saveSpan := s.tracer.Start(ctx, "Repository.Save.AddParent")
 
@@ -402,6 +792,12 @@ func (s *FamilyDomainService) AddChild(ctx context.Context, familyID string, chi
 
 	addChildSpan.End()
 
+	// Check the age plausibility policy
+	if err := s.EnforceAgePolicy(ctx, fam, "add_child"); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("add_child", metrics.StatusFailure).Inc()
+		return nil, err
+	}
+
 	// Create a span for saving the family
 	ctx, saveSpan := s.tracer.Start(ctx, "Repository.Save.AddChild")
 
@@ -547,6 +943,153 @@ func (s *FamilyDomainService) RemoveChild(ctx context.Context, familyID string,
 	return &resultDTO, nil
 }
 
+// MoveChild moves a child from one family to another.
+//
+// The rules of both families are enforced before anything is saved. The two families
+// are then saved by a saga: the target family first, so that the child is never in no
+// family, then the source family. If the source family cannot be saved, the target
+// family is restored to its previous state, or, if the repository implements
+// ports.UnitOfWork, the unit of work in which both are saved is rolled back. A ChildMoved
+// event records the transfer; it is stored in the outbox with the saves.
+//
+// Returns:
+//   - The target family with the child
+//   - An error if the child could not be moved
+func (s *FamilyDomainService) MoveChild(ctx context.Context, childID string, fromFamilyID string, toFamilyID string) (*entity.FamilyDTO, error) {
+	// Start a new span for this operation
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.MoveChild")
+	defer span.End()
+
+	// Start timer for operation duration
+	startTime := time.Now()
+
+	s.logger.Info(ctx, "Moving child between families in domain service",
+		zap.String("child_id", childID),
+		zap.String("from_family_id", fromFamilyID),
+		zap.String("to_family_id", toFamilyID))
+
+	if childID == "" || fromFamilyID == "" || toFamilyID == "" {
+		metrics.FamilyOperationsTotal.WithLabelValues("move_child", metrics.StatusFailure).Inc()
+
+		s.logger.Warn(ctx, "Child ID and family IDs are required for MoveChild",
+			zap.String("child_id", childID),
+			zap.String("from_family_id", fromFamilyID),
+			zap.String("to_family_id", toFamilyID))
+		return nil, errorswrapper.NewValidationError("child ID and family IDs are required", "childID/fromFamilyID/toFamilyID", nil)
+	}
+
+	// Get both families
+	from, err := s.getFamilyForMove(ctx, fromFamilyID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.getFamilyForMove(ctx, toFamilyID)
+	if err != nil {
+		return nil, err
+	}
+	previousTo := to.ToDTO()
+
+	// Create a span for the domain logic of the move
+	ctx, moveSpan := s.tracer.Start(ctx, "Domain.MoveChild")
+	if err := entity.MoveChild(from, to, childID); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("move_child", metrics.StatusFailure).Inc()
+		moveSpan.End()
+
+		s.logger.Info(ctx, "Failed to move child between families",
+			zap.Error(err),
+			zap.String("child_id", childID),
+			zap.String("from_family_id", fromFamilyID),
+			zap.String("to_family_id", toFamilyID))
+		return nil, err
+	}
+	moveSpan.End()
+
+	if err := s.EnforceAgePolicy(ctx, to, "move_child"); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("move_child", metrics.StatusFailure).Inc()
+		return nil, err
+	}
+
+	moved := events.ChildMoved{
+		ChildID:      childID,
+		FromFamilyID: fromFamilyID,
+		ToFamilyID:   toFamilyID,
+		At:           clock.Now(ctx).UTC(),
+	}
+
+	// Create a span for saving both families
+	ctx, saveSpan := s.tracer.Start(ctx, "Repository.Save.MoveChild")
+	transfer := s.newSaga("move_child",
+		sagaStep{
+			name:   "save target family",
+			action: func(ctx context.Context) error { return s.repo.Save(ctx, to) },
+			compensate: restoreFamily(s.repo, previousTo, to),
+		},
+		sagaStep{
+			name:   "save source family",
+			action: func(ctx context.Context) error { return s.repo.Save(ctx, from) },
+		},
+	).raise(moved)
+	if step, err := transfer.run(ctx); err != nil {
+		metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusFailure).Inc()
+		saveSpan.End()
+		metrics.FamilyOperationsTotal.WithLabelValues("move_child", metrics.StatusFailure).Inc()
+
+		s.logger.Error(ctx, "Failed to save families after moving child",
+			zap.Error(err),
+			zap.String("step", step),
+			zap.String("child_id", childID),
+			zap.String("from_family_id", fromFamilyID),
+			zap.String("to_family_id", toFamilyID))
+		if errors.Is(err, domainerrors.ErrMutationTimedOut) {
+			return nil, err
+		}
+		return nil, errorswrapper.NewDatabaseError("failed to save families after moving child", "save", "families", err)
+	}
+	metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusSuccess).Add(2)
+	metrics.RepositoryOperationsDuration.WithLabelValues("save").Observe(time.Since(startTime).Seconds())
+	saveSpan.End()
+
+	s.publishStored(ctx, moved)
+
+	// Record metrics for operation success
+	metrics.FamilyOperationsTotal.WithLabelValues("move_child", metrics.StatusSuccess).Inc()
+	metrics.FamilyOperationsDuration.WithLabelValues("move_child").Observe(time.Since(startTime).Seconds())
+
+	resultDTO := to.ToDTO()
+	s.logger.Info(ctx, "Successfully moved child between families",
+		zap.String("child_id", childID),
+		zap.String("from_family_id", fromFamilyID),
+		zap.String("to_family_id", toFamilyID))
+	return &resultDTO, nil
+}
+
+// getFamilyForMove retrieves one of the families of a MoveChild operation
+func (s *FamilyDomainService) getFamilyForMove(ctx context.Context, familyID string) (*entity.Family, error) {
+	// Create a span for retrieving the family
+	ctx, getSpan := s.tracer.Start(ctx, "Repository.GetByID.MoveChild")
+	defer getSpan.End()
+
+	startTime := time.Now()
+	fam, err := s.repo.GetByID(ctx, familyID)
+	if err != nil {
+		metrics.RepositoryOperationsTotal.WithLabelValues("get_by_id", metrics.StatusFailure).Inc()
+		metrics.FamilyOperationsTotal.WithLabelValues("move_child", metrics.StatusFailure).Inc()
+
+		if errorswrapper.IsNotFoundError(err) {
+			s.logger.Info(ctx, "Family not found for MoveChild", zap.String("family_id", familyID))
+			return nil, err // Pass through not found errors
+		}
+		s.logger.Error(ctx, "Failed to retrieve family for MoveChild",
+			zap.Error(err),
+			zap.String("family_id", familyID))
+		return nil, errorswrapper.NewDatabaseError("failed to retrieve family", "query", "families", err)
+	}
+
+	metrics.RepositoryOperationsTotal.WithLabelValues("get_by_id", metrics.StatusSuccess).Inc()
+	metrics.RepositoryOperationsDuration.WithLabelValues("get_by_id").Observe(time.Since(startTime).Seconds())
+	return fam, nil
+}
+
 // MarkParentDeceased marks a parent as deceased
 func (s *FamilyDomainService) MarkParentDeceased(ctx context.Context, familyID string, parentID string, deathDate time.Time) (*entity.FamilyDTO, error) {
 	// Start a new span for this operation
@@ -647,6 +1190,8 @@ func (s *FamilyDomainService) MarkParentDeceased(ctx context.Context, familyID s
 	if originalStatus != fam.Status() {
 		// If status changed to widowed, update metrics
 		if fam.Status() == entity.Widowed {
+			// The deceased parent leaves the widowed family
+			metrics.FamilyMemberCounts.WithLabelValues("parents").Dec()
 			metrics.FamilyStatusCounts.WithLabelValues("widowed").Inc()
 			if originalStatus == entity.Married {
 				metrics.FamilyStatusCounts.WithLabelValues("married").Dec()
@@ -725,6 +1270,7 @@ func (s *FamilyDomainService) Divorce(ctx context.Context, familyID string, cust
 	// Process divorce
 	// Note: After our changes, fam.Divorce() now returns the new family with the remaining parent
 	// The original family (fam) is modified in place to keep the custodial parent and children
+	previous := fam.ToDTO()
 	remainingFam, err := fam.Divorce(custodialParentID)
 	if err != nil {
 		// Record metrics for operation failure
@@ -744,56 +1290,46 @@ func (s *FamilyDomainService) Divorce(ctx context.Context, familyID string, cust
 		zap.String("family_id", fam.ID()), 
 		zap.String("status", string(fam.Status())))
 
-	// Create a span for saving the custodial parent family
-	ctx, saveCustodialSpan := s.tracer.Start(ctx, "Repository.Save.CustodialFamily")
-
-	// Save both families in a transaction if possible
-	// For now, save them sequentially
-	if err := s.repo.Save(ctx, fam); err != nil {
-		// Record metrics for repository operation failure
-		metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusFailure).Inc()
-		saveCustodialSpan.End()
-
-		// Record metrics for operation failure
-		metrics.FamilyOperationsTotal.WithLabelValues("divorce", metrics.StatusFailure).Inc()
-
-		s.logger.Error(ctx, "Failed to save family with custodial parent after divorce", 
-			zap.Error(err), 
-			zap.String("family_id", fam.ID()))
-		return nil, errorswrapper.NewDatabaseError("failed to save family with custodial parent", "save", "families", err)
-	}
-
-	// Record metrics for repository operation success
-	metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusSuccess).Inc()
-	saveCustodialSpan.End()
-
-	s.logger.Info(ctx, "Family with custodial parent saved, saving family with remaining parent", 
-		zap.String("family_id", remainingFam.ID()), 
-		zap.String("status", string(remainingFam.Status())))
-
-	// Create a span for saving the remaining parent family
-	ctx, saveRemainingSpan := s.tracer.Start(ctx, "Repository.Save.RemainingFamily")
-
-	if err := s.repo.Save(ctx, remainingFam); err != nil {
+	// Create a span for saving both families
+	ctx, saveSpan := s.tracer.Start(ctx, "Repository.Save.Divorce")
+
+	// Save both families by a saga: the family with the custodial parent first, then the new
+	// family with the remaining parent. If the new family cannot be saved, the original
+	// family is restored to its state before the divorce, or the unit of work in which both
+	// are saved is rolled back if the repository can run one.
+	divorce := s.newSaga("divorce",
+		sagaStep{
+			name:   "save family with custodial parent",
+			action: func(ctx context.Context) error { return s.repo.Save(ctx, fam) },
+			compensate: restoreFamily(s.repo, previous, fam),
+		},
+		sagaStep{
+			name:   "save family with remaining parent",
+			action: func(ctx context.Context) error { return s.repo.Save(ctx, remainingFam) },
+		},
+	)
+	if step, err := divorce.run(ctx); err != nil {
 		// Record metrics for repository operation failure
 		metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusFailure).Inc()
-		saveRemainingSpan.End()
+		saveSpan.End()
 
 		// Record metrics for operation failure
 		metrics.FamilyOperationsTotal.WithLabelValues("divorce", metrics.StatusFailure).Inc()
 
-		// This is a critical error - we've already updated the family with custodial parent
-		// In a real system, we'd use transactions to ensure atomicity
-		s.logger.Error(ctx, "Failed to save family with remaining parent after divorce - CRITICAL ERROR", 
-			zap.Error(err), 
-			zap.String("family_id", remainingFam.ID()),
-			zap.String("custodial_parent_family_id", fam.ID()))
-		return nil, errorswrapper.NewDatabaseError("failed to save family with remaining parent", "save", "families", err)
+		s.logger.Error(ctx, "Failed to save families after divorce",
+			zap.Error(err),
+			zap.String("step", step),
+			zap.String("family_id", fam.ID()),
+			zap.String("remaining_family_id", remainingFam.ID()))
+		if errors.Is(err, domainerrors.ErrMutationTimedOut) {
+			return nil, err
+		}
+		return nil, errorswrapper.NewDatabaseError("failed to save families after divorce", "save", "families", err)
 	}
 
 	// Record metrics for repository operation success
-	metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusSuccess).Inc()
-	saveRemainingSpan.End()
+	metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusSuccess).Add(2)
+	saveSpan.End()
 
 	// Update family status counts - one family became divorced, one became single
 	metrics.FamilyStatusCounts.WithLabelValues("divorced").Inc()
@@ -814,4 +1350,223 @@ func (s *FamilyDomainService) Divorce(ctx context.Context, familyID string, cust
 		zap.String("status", resultDTO.Status),
 		zap.Int("children_count", resultDTO.ChildrenCount))
 	return &resultDTO, nil
-}
\ No newline at end of file
+}
+// ChangeMemberName records a name change of a parent or child of a family
+func (s *FamilyDomainService) ChangeMemberName(ctx context.Context, familyID string, memberID string, change entity.NameChange) (*entity.FamilyDTO, error) {
+	return s.updateMember(ctx, "ChangeMemberName", "change_member_name", familyID, memberID, func(fam *entity.Family) error {
+		return fam.ChangeMemberName(memberID, change)
+	})
+}
+
+// SetMemberPreferredName sets or, if preferredName is empty, clears the preferred name of a parent or child of a family
+func (s *FamilyDomainService) SetMemberPreferredName(ctx context.Context, familyID string, memberID string, preferredName string) (*entity.FamilyDTO, error) {
+	return s.updateMember(ctx, "SetMemberPreferredName", "set_member_preferred_name", familyID, memberID, func(fam *entity.Family) error {
+		return fam.SetMemberPreferredName(memberID, preferredName)
+	})
+}
+
+// MarkChildDeceased marks a child of a family as deceased
+func (s *FamilyDomainService) MarkChildDeceased(ctx context.Context, familyID string, childID string, deathDate time.Time) (*entity.FamilyDTO, error) {
+	return s.updateMember(ctx, "MarkChildDeceased", "mark_child_deceased", familyID, childID, func(fam *entity.Family) error {
+		return fam.MarkChildDeceased(childID, deathDate)
+	})
+}
+
+// UpdateParent corrects the recorded first name, last name, or birth date of a parent of a family
+func (s *FamilyDomainService) UpdateParent(ctx context.Context, familyID string, parentID string, details entity.MemberDetails) (*entity.FamilyDTO, error) {
+	return s.updateMember(ctx, "UpdateParent", "update_parent", familyID, parentID, func(fam *entity.Family) error {
+		return fam.UpdateParent(parentID, details)
+	})
+}
+
+// UpdateChild corrects the recorded first name, last name, or birth date of a child of a family
+func (s *FamilyDomainService) UpdateChild(ctx context.Context, familyID string, childID string, details entity.MemberDetails) (*entity.FamilyDTO, error) {
+	return s.updateMember(ctx, "UpdateChild", "update_child", familyID, childID, func(fam *entity.Family) error {
+		return fam.UpdateChild(childID, details)
+	})
+}
+
+// GrantChildConsent records a consent granted by a parent of a family for one of its children
+func (s *FamilyDomainService) GrantChildConsent(ctx context.Context, familyID string, childID string, consent entity.Consent) (*entity.FamilyDTO, error) {
+	return s.updateMember(ctx, "GrantChildConsent", "grant_child_consent", familyID, childID, func(fam *entity.Family) error {
+		return fam.GrantChildConsent(childID, consent)
+	})
+}
+
+// ExpireStaleConsents flags the consents of children in all stored families that are older
+// than the maximum consent age as expired, and saves the families that changed. It returns
+// the number of consents flagged. Families that fail to save are logged and skipped, so that
+// one failure does not stop the check; the first error is returned after every family is checked.
+func (s *FamilyDomainService) ExpireStaleConsents(ctx context.Context) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.ExpireStaleConsents")
+	defer span.End()
+
+	consentPolicy := s.consentPolicy
+	if consentPolicy == nil {
+		consentPolicy = policy.NewConsentPolicy(false, 0, 0)
+	}
+	now := clock.Now(ctx)
+	staleBefore := consentPolicy.StaleBefore(now)
+
+	expired := 0
+	var firstErr error
+	familyCount, err := s.eachFamily(ctx, func(fam *entity.Family) error {
+		count := fam.ExpireChildConsents(staleBefore, now)
+		if count == 0 {
+			return nil
+		}
+
+		if err := s.repo.Save(ctx, fam); err != nil {
+			metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusFailure).Inc()
+			s.logger.Error(ctx, "Failed to save family with expired consents",
+				zap.Error(err),
+				zap.String("family_id", fam.ID()))
+			if firstErr == nil {
+				firstErr = errorswrapper.NewDatabaseError("failed to save family", "save", "families", err)
+			}
+			return nil
+		}
+		metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusSuccess).Inc()
+		metrics.ChildConsentsExpired.Add(float64(count))
+
+		expired += count
+		s.logger.Warn(ctx, "Flagged stale consents for children's data",
+			zap.String("family_id", fam.ID()),
+			zap.Int("expired_count", count))
+		return nil
+	})
+	if err != nil {
+		metrics.RepositoryOperationsTotal.WithLabelValues("get_all", metrics.StatusFailure).Inc()
+		s.logger.Error(ctx, "Failed to retrieve families for consent expiry", zap.Error(err))
+		return expired, errorswrapper.NewDatabaseError("failed to retrieve families", "query", "families", err)
+	}
+	metrics.RepositoryOperationsTotal.WithLabelValues("get_all", metrics.StatusSuccess).Inc()
+
+	s.logger.Info(ctx, "Checked consents for expiry",
+		zap.Int("family_count", familyCount),
+		zap.Int("expired_count", expired),
+		zap.Time("stale_before", staleBefore))
+	return expired, firstErr
+}
+
+// GetMemberNameAsOf returns the name of a parent or child of a family that applied on the given date
+func (s *FamilyDomainService) GetMemberNameAsOf(ctx context.Context, familyID string, memberID string, date time.Time) (*entity.NameChange, error) {
+	// Start a new span for this operation
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.GetMemberNameAsOf")
+	defer span.End()
+
+	// Start timer for operation duration
+	startTime := time.Now()
+
+	fam, err := s.getMemberFamily(ctx, "GetMemberNameAsOf", "get_member_name_as_of", familyID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := fam.MemberNameAsOf(memberID, date)
+	if err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("get_member_name_as_of", metrics.StatusFailure).Inc()
+		s.logger.Info(ctx, "Failed to get member name as of date",
+			zap.Error(err),
+			zap.String("family_id", familyID),
+			zap.String("member_id", memberID),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	// Record metrics for operation success
+	metrics.FamilyOperationsTotal.WithLabelValues("get_member_name_as_of", metrics.StatusSuccess).Inc()
+	metrics.FamilyOperationsDuration.WithLabelValues("get_member_name_as_of").Observe(time.Since(startTime).Seconds())
+	return &name, nil
+}
+
+// updateMember retrieves a family, applies an update to one of its members, and saves the family.
+// The method names the spans and log messages; the operation labels the metrics.
+func (s *FamilyDomainService) updateMember(ctx context.Context, method, operation, familyID, memberID string, update func(*entity.Family) error) (*entity.FamilyDTO, error) {
+	// Start a new span for this operation
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService."+method)
+	defer span.End()
+
+	// Start timer for operation duration
+	startTime := time.Now()
+
+	fam, err := s.getMemberFamily(ctx, method, operation, familyID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a span for updating the member
+	ctx, updateSpan := s.tracer.Start(ctx, "Domain."+method)
+	if err := update(fam); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues(operation, metrics.StatusFailure).Inc()
+		updateSpan.End()
+
+		s.logger.Error(ctx, "Failed to update member for "+method,
+			zap.Error(err),
+			zap.String("family_id", familyID),
+			zap.String("member_id", memberID))
+		return nil, err
+	}
+	updateSpan.End()
+
+	// Create a span for saving the family
+	ctx, saveSpan := s.tracer.Start(ctx, "Repository.Save."+method)
+	if err := s.repo.Save(ctx, fam); err != nil {
+		metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusFailure).Inc()
+		saveSpan.End()
+		metrics.FamilyOperationsTotal.WithLabelValues(operation, metrics.StatusFailure).Inc()
+
+		s.logger.Error(ctx, "Failed to save family for "+method,
+			zap.Error(err),
+			zap.String("family_id", familyID))
+		return nil, errorswrapper.NewDatabaseError("failed to save family", "save", "families", err)
+	}
+	metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusSuccess).Inc()
+	metrics.RepositoryOperationsDuration.WithLabelValues("save").Observe(time.Since(startTime).Seconds())
+	saveSpan.End()
+
+	// Record metrics for operation success
+	metrics.FamilyOperationsTotal.WithLabelValues(operation, metrics.StatusSuccess).Inc()
+	metrics.FamilyOperationsDuration.WithLabelValues(operation).Observe(time.Since(startTime).Seconds())
+
+	resultDTO := fam.ToDTO()
+	s.logger.Info(ctx, "Successfully updated member for "+method,
+		zap.String("family_id", resultDTO.ID),
+		zap.String("member_id", memberID))
+	return &resultDTO, nil
+}
+
+// getMemberFamily validates the IDs of a member operation and retrieves the family
+func (s *FamilyDomainService) getMemberFamily(ctx context.Context, method, operation, familyID, memberID string) (*entity.Family, error) {
+	if familyID == "" || memberID == "" {
+		metrics.FamilyOperationsTotal.WithLabelValues(operation, metrics.StatusFailure).Inc()
+
+		s.logger.Warn(ctx, "Family ID and member ID are required for "+method,
+			zap.String("family_id", familyID),
+			zap.String("member_id", memberID))
+		return nil, errorswrapper.NewValidationError("family ID and member ID are required", "familyID/memberID", nil)
+	}
+
+	// Create a span for retrieving the family
+	ctx, getSpan := s.tracer.Start(ctx, "Repository.GetByID."+method)
+	defer getSpan.End()
+
+	startTime := time.Now()
+	fam, err := s.repo.GetByID(ctx, familyID)
+	if err != nil {
+		metrics.RepositoryOperationsTotal.WithLabelValues("get_by_id", metrics.StatusFailure).Inc()
+		metrics.FamilyOperationsTotal.WithLabelValues(operation, metrics.StatusFailure).Inc()
+
+		if errorswrapper.IsNotFoundError(err) {
+			s.logger.Info(ctx, "Family not found for "+method, zap.String("family_id", familyID))
+			return nil, err // Pass through not found errors
+		}
+		s.logger.Error(ctx, "Failed to retrieve family for "+method,
+			zap.Error(err),
+			zap.String("family_id", familyID))
+		return nil, errorswrapper.NewDatabaseError("failed to retrieve family", "query", "families", err)
+	}
+	metrics.RepositoryOperationsTotal.WithLabelValues("get_by_id", metrics.StatusSuccess).Inc()
+	metrics.RepositoryOperationsDuration.WithLabelValues("get_by_id").Observe(time.Since(startTime).Seconds())
+	return fam, nil
+}
@@ -4,13 +4,24 @@
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/metrics"
+	"github.com/abitofhelp/family-service/core/domain/policy"
+	"github.com/abitofhelp/family-service/core/domain/ports"
 	"github.com/abitofhelp/family-service/core/domain/ports/mock"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
+	"github.com/abitofhelp/family-service/core/domain/reporting"
+	"github.com/abitofhelp/family-service/core/domain/workload"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/loggingwrapper"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
@@ -82,6 +93,34 @@ func TestGetFamily(t *testing.T) {
 	assert.Equal(t, familyID, result.ID)
 }
 
+func TestFindFamilies(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	logger := zaptest.NewLogger(t)
+	contextLogger := loggingwrapper.NewContextLogger(logger)
+	svc := NewFamilyDomainService(mockRepo, contextLogger)
+
+	// The repository evaluates the filter and returns the families in ID order
+	filter := query.Eq(query.FieldParentLastName, "Doe")
+	families := querytest.Families(t)
+	mockRepo.EXPECT().Find(gomock.Any(), filter).Return(families, nil)
+
+	// Execute
+	result, err := svc.FindFamilies(context.Background(), filter, query.Order{Field: query.FieldChildCount, Descending: true})
+
+	// Verify
+	require.NoError(t, err)
+	require.Len(t, result, len(families))
+	assert.Equal(t, querytest.Family4, result[0].ID())
+
+	// An order by a field families cannot be sorted by is rejected before the repository is read
+	_, err = svc.FindFamilies(context.Background(), filter, query.Order{Field: query.FieldParentID})
+	assert.Error(t, err)
+}
+
 func TestAddParent(t *testing.T) {
 	// Setup
 	ctrl := gomock.NewController(t)
@@ -96,6 +135,7 @@ func TestAddParent(t *testing.T) {
 	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479" // Valid UUID
 	parent1, _ := entity.NewParent("38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
 	family, _ := entity.NewFamily(familyID, entity.Single, []*entity.Parent{parent1}, []*entity.Child{})
+	require.NoError(t, family.SetExternalIDs(map[string]string{"crm": "CRM-1"}))
 
 	parentDTO := entity.ParentDTO{
 		ID:        "a47ac10b-58cc-4372-a567-0e02b2c3d480", // Valid UUID
@@ -117,6 +157,7 @@ func TestAddParent(t *testing.T) {
 	assert.Equal(t, familyID, result.ID)
 	assert.Equal(t, 2, result.ParentCount)
 	assert.Equal(t, "MARRIED", result.Status) // Status should change to MARRIED when adding a second parent
+	assert.Equal(t, map[string]string{"crm": "CRM-1"}, result.ExternalIDs, "the married family keeps its external IDs")
 }
 
 func TestAddChild(t *testing.T) {
@@ -188,3 +229,563 @@ func TestDivorce(t *testing.T) {
 	assert.Equal(t, 1, result.ChildrenCount)
 	assert.Equal(t, "DIVORCED", result.Status)
 }
+
+func TestDivorceRestoresFamily(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	svc := NewFamilyDomainService(mockRepo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+
+	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479" // Valid UUID
+	parent1, _ := entity.NewParent("38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	parent2, _ := entity.NewParent("a47ac10b-58cc-4372-a567-0e02b2c3d480", "Jane", "Doe", time.Date(1982, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	family, _ := entity.NewFamily(familyID, entity.Married, []*entity.Parent{parent1, parent2}, nil)
+
+	// The new family with the remaining parent cannot be saved, so the original family is
+	// saved again as it was before the divorce
+	mockRepo.EXPECT().GetByID(gomock.Any(), familyID).Return(family, nil)
+	gomock.InOrder(
+		mockRepo.EXPECT().Save(gomock.Any(), family).Return(nil),
+		mockRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("connection lost")),
+		mockRepo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fam *entity.Family) error {
+			assert.Equal(t, familyID, fam.ID())
+			assert.Equal(t, entity.Married, fam.Status())
+			assert.Len(t, fam.Parents(), 2)
+			return nil
+		}),
+	)
+
+	result, err := svc.Divorce(context.Background(), familyID, "38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f")
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+}
+
+// unitOfWorkKey is the context key of the units of work of transactionalRepository
+type unitOfWorkKey struct{}
+
+// transactionalRepository is a repository that records the units of work it runs
+type transactionalRepository struct {
+	*mock.MockFamilyRepository
+	beginErr   error
+	began      int
+	committed  int
+	rolledBack int
+}
+
+func (r *transactionalRepository) Begin(ctx context.Context) (context.Context, error) {
+	if r.beginErr != nil {
+		return nil, r.beginErr
+	}
+	r.began++
+	return context.WithValue(ctx, unitOfWorkKey{}, r.began), nil
+}
+
+func (r *transactionalRepository) Commit(ctx context.Context) error {
+	r.committed++
+	return nil
+}
+
+func (r *transactionalRepository) Rollback(ctx context.Context) error {
+	r.rolledBack++
+	return nil
+}
+
+func TestDivorceInUnitOfWork(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479" // Valid UUID
+	custodialParentID := "38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f"
+	newFamily := func() *entity.Family {
+		parent1, _ := entity.NewParent(custodialParentID, "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		parent2, _ := entity.NewParent("a47ac10b-58cc-4372-a567-0e02b2c3d480", "Jane", "Doe", time.Date(1982, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		family, _ := entity.NewFamily(familyID, entity.Married, []*entity.Parent{parent1, parent2}, nil)
+		return family
+	}
+	inUnit := func(ctx context.Context, _ *entity.Family) error {
+		assert.Equal(t, 1, ctx.Value(unitOfWorkKey{}), "the family is saved in the unit of work")
+		return nil
+	}
+
+	t.Run("commits both families", func(t *testing.T) {
+		repo := &transactionalRepository{MockFamilyRepository: mock.NewMockFamilyRepository(ctrl)}
+		svc := NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+		repo.EXPECT().GetByID(gomock.Any(), familyID).Return(newFamily(), nil)
+		repo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(inUnit).Times(2)
+
+		result, err := svc.Divorce(context.Background(), familyID, custodialParentID)
+
+		require.NoError(t, err)
+		assert.Equal(t, "DIVORCED", result.Status)
+		assert.Equal(t, 1, repo.committed)
+		assert.Zero(t, repo.rolledBack)
+	})
+
+	t.Run("rolls back instead of restoring the family", func(t *testing.T) {
+		repo := &transactionalRepository{MockFamilyRepository: mock.NewMockFamilyRepository(ctrl)}
+		svc := NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+		repo.EXPECT().GetByID(gomock.Any(), familyID).Return(newFamily(), nil)
+		gomock.InOrder(
+			repo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(inUnit),
+			repo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("connection lost")),
+		)
+
+		result, err := svc.Divorce(context.Background(), familyID, custodialParentID)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Zero(t, repo.committed)
+		assert.Equal(t, 1, repo.rolledBack)
+	})
+
+	t.Run("compensates without a unit of work", func(t *testing.T) {
+		repo := &transactionalRepository{MockFamilyRepository: mock.NewMockFamilyRepository(ctrl), beginErr: ports.ErrNoUnitOfWork}
+		svc := NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+		family := newFamily()
+		repo.EXPECT().GetByID(gomock.Any(), familyID).Return(family, nil)
+		gomock.InOrder(
+			repo.EXPECT().Save(gomock.Any(), family).Return(nil),
+			repo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("connection lost")),
+			repo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fam *entity.Family) error {
+				assert.Equal(t, entity.Married, fam.Status())
+				return nil
+			}),
+		)
+
+		_, err := svc.Divorce(context.Background(), familyID, custodialParentID)
+
+		require.Error(t, err)
+		assert.Zero(t, repo.rolledBack)
+	})
+}
+
+func TestCreateFamilyAgePolicy(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	logger := zaptest.NewLogger(t)
+	contextLogger := loggingwrapper.NewContextLogger(logger)
+	svc := NewFamilyDomainService(mockRepo, contextLogger)
+
+	// Create test data with a parent who was 14 when the child was born
+	dto := entity.FamilyDTO{
+		ID:     "f47ac10b-58cc-4372-a567-0e02b2c3d479", // Valid UUID
+		Status: "SINGLE",
+		Parents: []entity.ParentDTO{
+			{
+				ID:        "38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", // Valid UUID
+				FirstName: "John",
+				LastName:  "Doe",
+				BirthDate: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		Children: []entity.ChildDTO{
+			{
+				ID:        "9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e", // Valid UUID
+				FirstName: "Jimmy",
+				LastName:  "Doe",
+				BirthDate: time.Date(1994, 6, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	t.Run("warn mode saves the family", func(t *testing.T) {
+		svc.SetAgePolicy(policy.NewAgePolicy(policy.ModeWarn, 16))
+		mockRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := svc.CreateFamily(context.Background(), dto)
+
+		require.NoError(t, err)
+		assert.Equal(t, dto.ID, result.ID)
+	})
+
+	t.Run("block mode rejects the family", func(t *testing.T) {
+		svc.SetAgePolicy(policy.NewAgePolicy(policy.ModeBlock, 16))
+
+		result, err := svc.CreateFamily(context.Background(), dto)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "implausible parent-child ages")
+	})
+
+	t.Run("violations are reported", func(t *testing.T) {
+		fam, err := entity.FamilyFromDTO(dto)
+		require.NoError(t, err)
+		mockRepo.EXPECT().GetAll(gomock.Any()).Return([]*entity.Family{fam}, nil)
+
+		violations, err := svc.FindAgePolicyViolations(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		assert.Equal(t, policy.RuleParentUnderMinimumAge, violations[0].Rule)
+	})
+}
+
+func TestCreateFamilyDuplicatePolicy(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	logger := zaptest.NewLogger(t)
+	contextLogger := loggingwrapper.NewContextLogger(logger)
+	svc := NewFamilyDomainService(mockRepo, contextLogger)
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+
+	// A stored family, and a new family with the same members under other IDs
+	parent, _ := entity.NewParent("38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	stored, err := entity.NewFamily("b47ac10b-58cc-4372-a567-0e02b2c3d481", entity.Single, []*entity.Parent{parent}, nil)
+	require.NoError(t, err)
+	dto := entity.FamilyDTO{
+		ID:     "f47ac10b-58cc-4372-a567-0e02b2c3d479", // Valid UUID
+		Status: "SINGLE",
+		Parents: []entity.ParentDTO{
+			{
+				ID:        "a47ac10b-58cc-4372-a567-0e02b2c3d480", // Valid UUID
+				FirstName: "john",
+				LastName:  "Doe",
+				BirthDate: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	t.Run("reject mode refuses the duplicate", func(t *testing.T) {
+		svc.SetDuplicatePolicy(policy.NewDuplicatePolicy(policy.DuplicateReject))
+		mockRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]*entity.Family{stored}, nil)
+
+		result, err := svc.CreateFamily(context.Background(), dto)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.True(t, errors.Is(err, domainerrors.ErrFamilyDuplicate))
+	})
+
+	t.Run("warn mode creates the duplicate", func(t *testing.T) {
+		svc.SetDuplicatePolicy(policy.NewDuplicatePolicy(policy.DuplicateWarn))
+		mockRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]*entity.Family{stored}, nil)
+		mockRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := svc.CreateFamily(context.Background(), dto)
+
+		require.NoError(t, err)
+		assert.Empty(t, publisher.events)
+	})
+
+	t.Run("link mode links the duplicate", func(t *testing.T) {
+		svc.SetDuplicatePolicy(policy.NewDuplicatePolicy(policy.DuplicateLink))
+		mockRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]*entity.Family{stored}, nil)
+		mockRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := svc.CreateFamily(context.Background(), dto)
+
+		require.NoError(t, err)
+		require.Len(t, publisher.events, 1)
+		suspected, ok := publisher.events[0].(events.FamilyDuplicateSuspected)
+		require.True(t, ok)
+		assert.Equal(t, dto.ID, suspected.FamilyID)
+		assert.Equal(t, []string{stored.ID()}, suspected.DuplicateOf)
+	})
+
+	t.Run("a failed check does not block creation unless duplicates are rejected", func(t *testing.T) {
+		svc.SetDuplicatePolicy(policy.NewDuplicatePolicy(policy.DuplicateWarn))
+		mockRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, errors.New("database unavailable"))
+		mockRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := svc.CreateFamily(context.Background(), dto)
+		require.NoError(t, err)
+
+		svc.SetDuplicatePolicy(policy.NewDuplicatePolicy(policy.DuplicateReject))
+		mockRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, errors.New("database unavailable"))
+
+		_, err = svc.CreateFamily(context.Background(), dto)
+		require.Error(t, err)
+	})
+}
+
+func TestGenerateReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	svc := NewFamilyDomainService(mockRepo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+
+	parent, err := entity.NewParent("38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := entity.NewFamily("f47ac10b-58cc-4372-a567-0e02b2c3d479", entity.Single, []*entity.Parent{parent}, nil)
+	require.NoError(t, err)
+
+	t.Run("families are aggregated", func(t *testing.T) {
+		mockRepo.EXPECT().GetAll(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]*entity.Family, error) {
+			assert.True(t, workload.IsAnalytics(ctx))
+			return []*entity.Family{fam}, nil
+		})
+
+		report, err := svc.GenerateReport(context.Background(), reporting.Options{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Groups[0].Families)
+		assert.Equal(t, 1, report.Groups[0].Parents)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		mockRepo.EXPECT().GetAll(gomock.Any()).Return(nil, errors.New("connection lost"))
+
+		report, err := svc.GenerateReport(context.Background(), reporting.Options{})
+
+		require.Error(t, err)
+		assert.Nil(t, report)
+	})
+}
+
+// recordingPublisher records the published events
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event events.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestMoveChild(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	logger := zaptest.NewLogger(t)
+	contextLogger := loggingwrapper.NewContextLogger(logger)
+	svc := NewFamilyDomainService(mockRepo, contextLogger)
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+
+	fromID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"  // Valid UUID
+	toID := "b47ac10b-58cc-4372-a567-0e02b2c3d481"    // Valid UUID
+	childID := "9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e" // Valid UUID
+
+	// newFamilies creates the source family with the child and the target family without it
+	newFamilies := func(t *testing.T) (*entity.Family, *entity.Family) {
+		parent1, _ := entity.NewParent("38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		parent2, _ := entity.NewParent("a47ac10b-58cc-4372-a567-0e02b2c3d480", "Mary", "Smith", time.Date(1975, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		child, _ := entity.NewChild(childID, "Jimmy", "Doe", time.Date(2010, 6, 1, 0, 0, 0, 0, time.UTC), nil)
+		from, err := entity.NewFamily(fromID, entity.Single, []*entity.Parent{parent1}, []*entity.Child{child})
+		require.NoError(t, err)
+		to, err := entity.NewFamily(toID, entity.Single, []*entity.Parent{parent2}, []*entity.Child{})
+		require.NoError(t, err)
+		return from, to
+	}
+
+	t.Run("moves the child and publishes an event", func(t *testing.T) {
+		from, to := newFamilies(t)
+		mockRepo.EXPECT().GetByID(gomock.Any(), fromID).Return(from, nil)
+		mockRepo.EXPECT().GetByID(gomock.Any(), toID).Return(to, nil)
+		gomock.InOrder(
+			mockRepo.EXPECT().Save(gomock.Any(), to).Return(nil),
+			mockRepo.EXPECT().Save(gomock.Any(), from).Return(nil),
+		)
+
+		result, err := svc.MoveChild(context.Background(), childID, fromID, toID)
+
+		require.NoError(t, err)
+		assert.Equal(t, toID, result.ID)
+		assert.Equal(t, 1, result.ChildrenCount)
+		assert.Empty(t, from.Children())
+		require.Len(t, publisher.events, 1)
+		moved, ok := publisher.events[0].(events.ChildMoved)
+		require.True(t, ok)
+		assert.Equal(t, childID, moved.ChildID)
+		assert.Equal(t, fromID, moved.FromFamilyID)
+		assert.Equal(t, toID, moved.ToFamilyID)
+	})
+
+	t.Run("restores the target family if the source family cannot be saved", func(t *testing.T) {
+		publisher.events = nil
+		from, to := newFamilies(t)
+		mockRepo.EXPECT().GetByID(gomock.Any(), fromID).Return(from, nil)
+		mockRepo.EXPECT().GetByID(gomock.Any(), toID).Return(to, nil)
+		gomock.InOrder(
+			mockRepo.EXPECT().Save(gomock.Any(), to).Return(nil),
+			mockRepo.EXPECT().Save(gomock.Any(), from).Return(errors.New("connection lost")),
+			mockRepo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fam *entity.Family) error {
+				assert.Equal(t, toID, fam.ID())
+				assert.Empty(t, fam.Children())
+				return nil
+			}),
+		)
+
+		result, err := svc.MoveChild(context.Background(), childID, fromID, toID)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Empty(t, publisher.events)
+	})
+
+	t.Run("restores the target family if the mutation times out", func(t *testing.T) {
+		publisher.events = nil
+		from, to := newFamilies(t)
+		rollbacks := testutil.ToFloat64(metrics.MutationRollbacks.WithLabelValues("move_child", metrics.RollbackTimeout))
+		ctx, cancel := WithMutationTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		mockRepo.EXPECT().GetByID(gomock.Any(), fromID).Return(from, nil)
+		mockRepo.EXPECT().GetByID(gomock.Any(), toID).Return(to, nil)
+		gomock.InOrder(
+			// The save of the target family completes after the timeout
+			mockRepo.EXPECT().Save(gomock.Any(), to).DoAndReturn(func(ctx context.Context, _ *entity.Family) error {
+				<-ctx.Done()
+				return nil
+			}),
+			mockRepo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fam *entity.Family) error {
+				assert.NoError(t, ctx.Err())
+				assert.Equal(t, toID, fam.ID())
+				assert.Empty(t, fam.Children())
+				return nil
+			}),
+		)
+
+		result, err := svc.MoveChild(ctx, childID, fromID, toID)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, domainerrors.ErrMutationTimedOut)
+		assert.Equal(t, err, MutationTimedOut(ctx, err))
+		assert.Nil(t, result)
+		assert.Empty(t, publisher.events)
+		assert.Equal(t, rollbacks+1, testutil.ToFloat64(metrics.MutationRollbacks.WithLabelValues("move_child", metrics.RollbackTimeout)))
+	})
+
+	t.Run("rejects a child that is not in the source family", func(t *testing.T) {
+		from, to := newFamilies(t)
+		mockRepo.EXPECT().GetByID(gomock.Any(), toID).Return(to, nil)
+		mockRepo.EXPECT().GetByID(gomock.Any(), fromID).Return(from, nil)
+
+		result, err := svc.MoveChild(context.Background(), childID, toID, fromID)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+// unitOutbox records the events stored in the outbox and the units of work in which they
+// were stored
+type unitOutbox struct {
+	events []events.Event
+	units  []interface{}
+}
+
+func (o *unitOutbox) Publish(ctx context.Context, event events.Event) error {
+	o.events = append(o.events, event)
+	o.units = append(o.units, ctx.Value(unitOfWorkKey{}))
+	return nil
+}
+
+func TestMoveChildStoresEventInOutbox(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fromID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"  // Valid UUID
+	toID := "b47ac10b-58cc-4372-a567-0e02b2c3d481"    // Valid UUID
+	childID := "9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e" // Valid UUID
+	newFamilies := func(t *testing.T) (*entity.Family, *entity.Family) {
+		parent1, _ := entity.NewParent("38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		parent2, _ := entity.NewParent("a47ac10b-58cc-4372-a567-0e02b2c3d480", "Mary", "Smith", time.Date(1975, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		child, _ := entity.NewChild(childID, "Jimmy", "Doe", time.Date(2010, 6, 1, 0, 0, 0, 0, time.UTC), nil)
+		from, err := entity.NewFamily(fromID, entity.Single, []*entity.Parent{parent1}, []*entity.Child{child})
+		require.NoError(t, err)
+		to, err := entity.NewFamily(toID, entity.Single, []*entity.Parent{parent2}, []*entity.Child{})
+		require.NoError(t, err)
+		return from, to
+	}
+	newService := func(t *testing.T) (*transactionalRepository, *FamilyDomainService, *recordingPublisher, *unitOutbox) {
+		repo := &transactionalRepository{MockFamilyRepository: mock.NewMockFamilyRepository(ctrl)}
+		svc := NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+		publisher := &recordingPublisher{}
+		outbox := &unitOutbox{}
+		svc.SetEventPublisher(publisher)
+		svc.SetEventOutbox(outbox)
+		return repo, svc, publisher, outbox
+	}
+
+	t.Run("stores the event in the unit of work of the saves", func(t *testing.T) {
+		repo, svc, publisher, outbox := newService(t)
+		from, to := newFamilies(t)
+		repo.EXPECT().GetByID(gomock.Any(), fromID).Return(from, nil)
+		repo.EXPECT().GetByID(gomock.Any(), toID).Return(to, nil)
+		repo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+		_, err := svc.MoveChild(context.Background(), childID, fromID, toID)
+
+		require.NoError(t, err)
+		require.Len(t, outbox.events, 1)
+		assert.IsType(t, events.ChildMoved{}, outbox.events[0])
+		assert.Equal(t, []interface{}{1}, outbox.units, "the event is stored in the unit of work")
+		assert.Equal(t, 1, repo.committed)
+		require.Len(t, publisher.events, 1, "the event is published once, not stored again")
+	})
+
+	t.Run("stores nothing if the unit of work is rolled back", func(t *testing.T) {
+		repo, svc, publisher, outbox := newService(t)
+		from, to := newFamilies(t)
+		repo.EXPECT().GetByID(gomock.Any(), fromID).Return(from, nil)
+		repo.EXPECT().GetByID(gomock.Any(), toID).Return(to, nil)
+		gomock.InOrder(
+			repo.EXPECT().Save(gomock.Any(), to).Return(nil),
+			repo.EXPECT().Save(gomock.Any(), from).Return(errors.New("connection lost")),
+		)
+
+		_, err := svc.MoveChild(context.Background(), childID, fromID, toID)
+
+		require.Error(t, err)
+		assert.Empty(t, outbox.events)
+		assert.Empty(t, publisher.events)
+		assert.Equal(t, 1, repo.rolledBack)
+	})
+}
+
+func TestExpireStaleConsents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	svc := NewFamilyDomainService(mockRepo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	svc.SetConsentPolicy(policy.NewConsentPolicy(true, 18, 30*24*time.Hour))
+
+	// newFamily creates a family whose child was granted consent at the given time
+	parentID := "38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f"
+	newFamily := func(familyID, childID string, grantedAt time.Time) *entity.Family {
+		parent, err := entity.NewParent(parentID, "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		require.NoError(t, err)
+		child, err := entity.NewChild(childID, "Jimmy", "Doe", time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		require.NoError(t, err)
+		fam, err := entity.NewFamily(familyID, entity.Single, []*entity.Parent{parent}, []*entity.Child{child})
+		require.NoError(t, err)
+		consent, err := entity.NewConsent(parentID, grantedAt, entity.ConsentScopes)
+		require.NoError(t, err)
+		require.NoError(t, fam.GrantChildConsent(childID, consent))
+		return fam
+	}
+	stale := newFamily("f47ac10b-58cc-4372-a567-0e02b2c3d479", "9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e", time.Now().AddDate(0, -2, 0))
+	current := newFamily("a47ac10b-58cc-4372-a567-0e02b2c3d479", "ab2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e", time.Now().AddDate(0, 0, -1))
+
+	// Only the family with a stale consent is saved
+	mockRepo.EXPECT().GetAll(gomock.Any()).Return([]*entity.Family{stale, current}, nil)
+	mockRepo.EXPECT().Save(gomock.Any(), stale).Return(nil)
+
+	expired, err := svc.ExpireStaleConsents(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	assert.True(t, stale.Children()[0].Consents()[0].IsExpired())
+	assert.False(t, current.Children()[0].Consents()[0].IsExpired())
+
+	// A family that fails to save does not stop the check
+	mockRepo.EXPECT().GetAll(gomock.Any()).Return([]*entity.Family{newFamily(stale.ID(), stale.Children()[0].ID(), time.Now().AddDate(0, -2, 0))}, nil)
+	mockRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+
+	expired, err = svc.ExpireStaleConsents(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 0, expired)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package services
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"go.uber.org/zap"
+)
+
+// CheckFamilyOwner checks whether the given families belong to an owner, for an operation
+// of a caller who may only access the families it owns.
+//
+// A family of another owner, or without an owner, is reported as not found, so that the
+// caller cannot tell it apart from a family that does not exist. Missing families are
+// left for the operation to report.
+//
+// Parameters:
+//   - ctx: The context of the operation
+//   - operation: Name of the operation, such as "AddChild"
+//   - ownerID: ID of the caller, who must own the families
+//   - familyIDs: IDs of the families the operation accesses
+//
+// Returns:
+//   - nil if the owner owns every family that exists
+//   - A NotFoundError if a family belongs to somebody else, or a DatabaseError if a
+//     family cannot be read
+func (s *FamilyDomainService) CheckFamilyOwner(ctx context.Context, operation, ownerID string, familyIDs ...string) error {
+	for _, familyID := range familyIDs {
+		if familyID == "" {
+			continue
+		}
+		fam, err := s.repo.GetByID(ctx, familyID)
+		if err != nil {
+			if errorswrapper.IsNotFoundError(err) {
+				continue
+			}
+			s.logger.Error(ctx, "Failed to check family owner", zap.Error(err), zap.String("family_id", familyID))
+			return errorswrapper.NewDatabaseError("failed to check family owner", "query", "families", err)
+		}
+		if fam.OwnerID() != ownerID {
+			s.logger.Warn(ctx, "Refused access to a family of another owner",
+				zap.String("family_id", familyID),
+				zap.String("operation", operation),
+				zap.String("caller", ownerID))
+			return errorswrapper.NewNotFoundError("Family", familyID, nil)
+		}
+	}
+	return nil
+}
+
+// CountOwnedFamilies counts the families of an owner. Quarantined families are left out,
+// since the owner is not an administrator.
+func (s *FamilyDomainService) CountOwnedFamilies(ctx context.Context, ownerID string) (int, error) {
+	counts, err := s.ownedCounts(ctx, ownerID)
+	return counts.Families, err
+}
+
+// CountOwnedParents counts the distinct parents of the families of an owner, leaving out
+// quarantined families
+func (s *FamilyDomainService) CountOwnedParents(ctx context.Context, ownerID string) (int, error) {
+	counts, err := s.ownedCounts(ctx, ownerID)
+	return counts.Parents, err
+}
+
+// CountOwnedChildren counts the distinct children of the families of an owner, leaving
+// out quarantined families
+func (s *FamilyDomainService) CountOwnedChildren(ctx context.Context, ownerID string) (int, error) {
+	counts, err := s.ownedCounts(ctx, ownerID)
+	return counts.Children, err
+}
+
+// ListOwnedFamilies returns at most limit families of an owner whose IDs sort after the
+// given ID, in ascending ID order. Repositories that implement ports.FamilyOwnerReader read
+// only the page; the others read the families of the owner with Find.
+func (s *FamilyDomainService) ListOwnedFamilies(ctx context.Context, ownerID, after string, limit int) ([]*entity.Family, error) {
+	return ports.ListFamiliesByOwner(ctx, s.repo, ownerID, after, limit)
+}
+
+// ownedCounts counts the families of an owner that are not quarantined, and the distinct
+// parents and children in them. Repositories that implement ports.FamilyOwnerReader count
+// them without reading them.
+func (s *FamilyDomainService) ownedCounts(ctx context.Context, ownerID string) (ports.FamilyCounts, error) {
+	var quarantined []string
+	if s.quarantines != nil {
+		quarantines, err := s.quarantines.ListFamilyQuarantines(ctx)
+		if err != nil {
+			return ports.FamilyCounts{}, errorswrapper.NewDatabaseError("failed to list family quarantines", "query", "quarantines", err)
+		}
+		quarantined = make([]string, len(quarantines))
+		for i, q := range quarantines {
+			quarantined[i] = q.FamilyID
+		}
+	}
+
+	counts, err := ports.CountFamiliesByOwner(ctx, s.repo, ownerID, quarantined)
+	if err != nil {
+		return ports.FamilyCounts{}, errorswrapper.NewDatabaseError("failed to count the families of the owner", "query", "families", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports/mock"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/loggingwrapper"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCheckFamilyOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owned := newQuarantineTestFamily(t, "a47ac10b-58cc-4372-a567-0e02b2c3d479")
+	owned.SetOwnerID("user-1")
+	other := newQuarantineTestFamily(t, "b47ac10b-58cc-4372-a567-0e02b2c3d479")
+	other.SetOwnerID("user-2")
+	unowned := newQuarantineTestFamily(t, "c47ac10b-58cc-4372-a567-0e02b2c3d479")
+	missingID, failingID := "d47ac10b-58cc-4372-a567-0e02b2c3d479", "e47ac10b-58cc-4372-a567-0e02b2c3d479"
+
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	for _, fam := range []*entity.Family{owned, other, unowned} {
+		mockRepo.EXPECT().GetByID(gomock.Any(), fam.ID()).Return(fam, nil).AnyTimes()
+	}
+	mockRepo.EXPECT().GetByID(gomock.Any(), missingID).Return(nil, errorswrapper.NewNotFoundError("Family", missingID, nil)).AnyTimes()
+	mockRepo.EXPECT().GetByID(gomock.Any(), failingID).Return(nil, errors.New("connection refused")).AnyTimes()
+
+	svc := NewFamilyDomainService(mockRepo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	ctx := context.Background()
+
+	// Missing families are left for the operation to report
+	assert.NoError(t, svc.CheckFamilyOwner(ctx, "MoveChild", "user-1", owned.ID(), missingID, ""))
+
+	// Families of other owners and families without an owner are not found
+	err := svc.CheckFamilyOwner(ctx, "MoveChild", "user-1", owned.ID(), other.ID())
+	assert.True(t, errorswrapper.IsNotFoundError(err), "expected a NotFoundError, got %v", err)
+	err = svc.CheckFamilyOwner(ctx, "AddChild", "user-1", unowned.ID())
+	assert.True(t, errorswrapper.IsNotFoundError(err), "expected a NotFoundError, got %v", err)
+
+	err = svc.CheckFamilyOwner(ctx, "AddChild", "user-1", failingID)
+	assert.True(t, errorswrapper.IsDatabaseError(err), "expected a DatabaseError, got %v", err)
+}
+
+// TestCounts_Owned counts the families of an owner and their distinct members, leaving out
+// quarantined families
+func TestCounts_Owned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	first := newQuarantineTestFamily(t, "a47ac10b-58cc-4372-a567-0e02b2c3d479")
+	first.SetOwnerID("user-1")
+	child, err := entity.NewChild("c1a2b3c4-0000-4000-8000-000000000001", "Ann", "Doe", first.Parents()[0].BirthDate().AddDate(30, 0, 0), nil)
+	require.NoError(t, err)
+	require.NoError(t, first.AddChild(child))
+	// The second family shares its parent with the first
+	second := newQuarantineTestFamily(t, "b47ac10b-58cc-4372-a567-0e02b2c3d479")
+	second.SetOwnerID("user-1")
+	frozen := newQuarantineTestFamily(t, "c47ac10b-58cc-4372-a567-0e02b2c3d479")
+	frozen.SetOwnerID("user-1")
+	other := newQuarantineTestFamily(t, "d47ac10b-58cc-4372-a567-0e02b2c3d479")
+	other.SetOwnerID("user-2")
+
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	mockRepo.EXPECT().GetByID(gomock.Any(), frozen.ID()).Return(frozen, nil).AnyTimes()
+	mockRepo.EXPECT().Find(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, filter query.Filter) ([]*entity.Family, error) {
+		var matched []*entity.Family
+		for _, fam := range []*entity.Family{first, second, frozen, other} {
+			if query.Match(filter, fam) {
+				matched = append(matched, fam)
+			}
+		}
+		return matched, nil
+	}).AnyTimes()
+
+	svc := NewFamilyDomainService(mockRepo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	quarantines := newMemoryQuarantines()
+	quarantines.quarantines[frozen.ID()] = entity.Quarantine{FamilyID: frozen.ID()}
+	svc.SetQuarantineRepository(quarantines)
+	ctx := context.Background()
+
+	families, err := svc.CountOwnedFamilies(ctx, "user-1")
+	require.NoError(t, err)
+	parents, err := svc.CountOwnedParents(ctx, "user-1")
+	require.NoError(t, err)
+	children, err := svc.CountOwnedChildren(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 1, 1}, []int{families, parents, children})
+
+	families, err = svc.CountOwnedFamilies(ctx, "user-3")
+	require.NoError(t, err)
+	assert.Zero(t, families)
+}
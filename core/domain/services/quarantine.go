@@ -0,0 +1,241 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"go.uber.org/zap"
+)
+
+// SetQuarantineRepository sets the repository of family quarantines (nil disables quarantines)
+func (s *FamilyDomainService) SetQuarantineRepository(quarantines ports.QuarantineRepository) {
+	s.quarantines = quarantines
+}
+
+// QuarantineFamily quarantines a family, so that only administrators can read or change it
+// until the quarantine is removed. Quarantining a quarantined family replaces its reason.
+// A FamilyQuarantined event is stored in the outbox with the quarantine and published.
+//
+// Parameters:
+//   - ctx: The context of the operation
+//   - familyID: ID of the family to quarantine
+//   - reason: Why the family is quarantined, such as the reference of a fraud investigation
+//   - quarantinedBy: ID of the administrator who quarantines the family
+//
+// Returns:
+//   - The quarantine of the family
+//   - A ValidationError if the reason is invalid, a NotFoundError if the family does not
+//     exist, or a DatabaseError if quarantines are not available or cannot be stored
+func (s *FamilyDomainService) QuarantineFamily(ctx context.Context, familyID, reason, quarantinedBy string) (*entity.Quarantine, error) {
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.QuarantineFamily")
+	defer span.End()
+
+	if s.quarantines == nil {
+		return nil, errorswrapper.NewDatabaseError("family quarantines are not available", "save", "quarantines", nil)
+	}
+
+	q, err := entity.NewQuarantine(familyID, reason, quarantinedBy, clock.Now(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetByID(ctx, familyID); err != nil {
+		if errorswrapper.IsNotFoundError(err) {
+			return nil, err
+		}
+		return nil, errorswrapper.NewDatabaseError("failed to retrieve family", "query", "families", err)
+	}
+
+	quarantined := events.FamilyQuarantined{FamilyID: q.FamilyID, Reason: q.Reason, At: q.QuarantinedAt}
+	quarantine := s.newSaga("quarantine_family",
+		sagaStep{
+			name:   "save quarantine",
+			action: func(ctx context.Context) error { return s.quarantines.SaveFamilyQuarantine(ctx, q) },
+		},
+	).raise(quarantined)
+	if _, err := quarantine.run(ctx); err != nil {
+		s.logger.Error(ctx, "Failed to save family quarantine", zap.Error(err), zap.String("family_id", familyID))
+		if errors.Is(err, domainerrors.ErrMutationTimedOut) {
+			return nil, err
+		}
+		return nil, errorswrapper.NewDatabaseError("failed to save family quarantine", "save", "quarantines", err)
+	}
+
+	s.logger.Warn(ctx, "Quarantined family",
+		zap.String("family_id", q.FamilyID),
+		zap.String("reason", q.Reason),
+		zap.String("quarantined_by", q.QuarantinedBy))
+	s.publishStored(ctx, quarantined)
+	return &q, nil
+}
+
+// UnquarantineFamily removes the quarantine of a family and returns the removed quarantine.
+// A FamilyUnquarantined event is stored in the outbox with the removal and published.
+//
+// Returns:
+//   - The removed quarantine
+//   - A NotFoundError if the family is not quarantined, or a DatabaseError if quarantines
+//     are not available or cannot be removed
+func (s *FamilyDomainService) UnquarantineFamily(ctx context.Context, familyID string) (*entity.Quarantine, error) {
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.UnquarantineFamily")
+	defer span.End()
+
+	if s.quarantines == nil {
+		return nil, errorswrapper.NewDatabaseError("family quarantines are not available", "delete", "quarantines", nil)
+	}
+
+	q, err := s.quarantines.GetFamilyQuarantine(ctx, familyID)
+	if err != nil {
+		return nil, errorswrapper.NewDatabaseError("failed to retrieve family quarantine", "query", "quarantines", err)
+	}
+	if q == nil {
+		return nil, errorswrapper.NewNotFoundError("Quarantine", familyID, nil)
+	}
+
+	unquarantined := events.FamilyUnquarantined{FamilyID: familyID, At: clock.Now(ctx).UTC()}
+	unquarantine := s.newSaga("unquarantine_family",
+		sagaStep{
+			name: "delete quarantine",
+			action: func(ctx context.Context) error {
+				deleted, err := s.quarantines.DeleteFamilyQuarantine(ctx, familyID)
+				if err == nil && !deleted {
+					// Removed concurrently
+					return errorswrapper.NewNotFoundError("Quarantine", familyID, nil)
+				}
+				return err
+			},
+		},
+	).raise(unquarantined)
+	if _, err := unquarantine.run(ctx); err != nil {
+		if errorswrapper.IsNotFoundError(err) || errors.Is(err, domainerrors.ErrMutationTimedOut) {
+			return nil, err
+		}
+		s.logger.Error(ctx, "Failed to delete family quarantine", zap.Error(err), zap.String("family_id", familyID))
+		return nil, errorswrapper.NewDatabaseError("failed to delete family quarantine", "delete", "quarantines", err)
+	}
+
+	s.logger.Warn(ctx, "Removed family quarantine", zap.String("family_id", familyID))
+	s.publishStored(ctx, unquarantined)
+	return q, nil
+}
+
+// CheckFamilyAccess checks whether an operation may read or change the given families.
+//
+// Administrators may access quarantined families; anybody else is denied. Every attempt
+// to access a quarantined family, allowed or denied, is logged and published as a
+// QuarantinedFamilyAccessed event. Without a quarantine repository, every access is allowed.
+// When quarantines cannot be read, access is denied, so that a frozen family is never
+// exposed by an outage.
+//
+// Parameters:
+//   - ctx: The context of the operation
+//   - operation: Name of the operation, such as "GetFamily"
+//   - admin: Whether the caller is an administrator
+//   - familyIDs: IDs of the families the operation accesses
+//
+// Returns:
+//   - nil if the operation may access every family
+//   - A FamilyQuarantinedError if a family is quarantined and the caller is not an
+//     administrator, or a DatabaseError if quarantines cannot be read
+func (s *FamilyDomainService) CheckFamilyAccess(ctx context.Context, operation string, admin bool, familyIDs ...string) error {
+	if s.quarantines == nil {
+		return nil
+	}
+
+	for _, familyID := range familyIDs {
+		if familyID == "" {
+			continue
+		}
+		q, err := s.quarantines.GetFamilyQuarantine(ctx, familyID)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to check family quarantine", zap.Error(err), zap.String("family_id", familyID))
+			return errorswrapper.NewDatabaseError("failed to check family quarantine", "query", "quarantines", err)
+		}
+		if q == nil {
+			continue
+		}
+		s.recordQuarantinedAccess(ctx, familyID, operation, admin)
+		if !admin {
+			return domainerrors.NewFamilyQuarantinedError("family "+familyID+" is quarantined", nil)
+		}
+	}
+	return nil
+}
+
+// FilterQuarantinedFamilies removes the quarantined families from the result of an
+// operation that lists families, unless the caller is an administrator. Access to each
+// quarantined family in the result is logged and published as for CheckFamilyAccess.
+//
+// Returns:
+//   - The families the caller may see, in their original order
+//   - A DatabaseError if quarantines cannot be read
+func (s *FamilyDomainService) FilterQuarantinedFamilies(ctx context.Context, operation string, admin bool, families []*entity.Family) ([]*entity.Family, error) {
+	if s.quarantines == nil || len(families) == 0 {
+		return families, nil
+	}
+
+	quarantines, err := s.quarantines.ListFamilyQuarantines(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list family quarantines", zap.Error(err))
+		return nil, errorswrapper.NewDatabaseError("failed to list family quarantines", "query", "quarantines", err)
+	}
+	if len(quarantines) == 0 {
+		return families, nil
+	}
+	quarantined := make(map[string]bool, len(quarantines))
+	for _, q := range quarantines {
+		quarantined[q.FamilyID] = true
+	}
+
+	visible := make([]*entity.Family, 0, len(families))
+	for _, fam := range families {
+		if quarantined[fam.ID()] {
+			s.recordQuarantinedAccess(ctx, fam.ID(), operation, admin)
+			if !admin {
+				continue
+			}
+		}
+		visible = append(visible, fam)
+	}
+	return visible, nil
+}
+
+// ListFamilyQuarantines returns every quarantine, in ascending family ID order
+func (s *FamilyDomainService) ListFamilyQuarantines(ctx context.Context) ([]entity.Quarantine, error) {
+	if s.quarantines == nil {
+		return nil, nil
+	}
+	quarantines, err := s.quarantines.ListFamilyQuarantines(ctx)
+	if err != nil {
+		return nil, errorswrapper.NewDatabaseError("failed to list family quarantines", "query", "quarantines", err)
+	}
+	return quarantines, nil
+}
+
+// recordQuarantinedAccess logs and publishes an attempt to access a quarantined family
+func (s *FamilyDomainService) recordQuarantinedAccess(ctx context.Context, familyID, operation string, allowed bool) {
+	fields := []zap.Field{
+		zap.String("family_id", familyID),
+		zap.String("operation", operation),
+		zap.Bool("allowed", allowed),
+	}
+	if allowed {
+		s.logger.Info(ctx, "Administrator accessed quarantined family", fields...)
+	} else {
+		s.logger.Warn(ctx, "Denied access to quarantined family", fields...)
+	}
+	s.publish(ctx, events.QuarantinedFamilyAccessed{
+		FamilyID:  familyID,
+		Operation: operation,
+		Allowed:   allowed,
+		At:        clock.Now(ctx).UTC(),
+	})
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/ports/mock"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/loggingwrapper"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// memoryQuarantines keeps quarantines in memory
+type memoryQuarantines struct {
+	quarantines map[string]entity.Quarantine
+	err         error
+}
+
+func newMemoryQuarantines() *memoryQuarantines {
+	return &memoryQuarantines{quarantines: make(map[string]entity.Quarantine)}
+}
+
+func (m *memoryQuarantines) GetFamilyQuarantine(_ context.Context, familyID string) (*entity.Quarantine, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	q, ok := m.quarantines[familyID]
+	if !ok {
+		return nil, nil
+	}
+	return &q, nil
+}
+
+func (m *memoryQuarantines) SaveFamilyQuarantine(_ context.Context, q entity.Quarantine) error {
+	m.quarantines[q.FamilyID] = q
+	return nil
+}
+
+func (m *memoryQuarantines) DeleteFamilyQuarantine(_ context.Context, familyID string) (bool, error) {
+	_, ok := m.quarantines[familyID]
+	delete(m.quarantines, familyID)
+	return ok, nil
+}
+
+func (m *memoryQuarantines) ListFamilyQuarantines(_ context.Context) ([]entity.Quarantine, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	list := make([]entity.Quarantine, 0, len(m.quarantines))
+	for _, q := range m.quarantines {
+		list = append(list, q)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].FamilyID < list[j].FamilyID })
+	return list, nil
+}
+
+func newQuarantineTestFamily(t *testing.T, id string) *entity.Family {
+	parent, err := entity.NewParent("38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := entity.NewFamily(id, entity.Single, []*entity.Parent{parent}, nil)
+	require.NoError(t, err)
+	return fam
+}
+
+func TestQuarantineFamily(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	svc := NewFamilyDomainService(mockRepo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	quarantines := newMemoryQuarantines()
+	svc.SetQuarantineRepository(quarantines)
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetByID(gomock.Any(), familyID).Return(newQuarantineTestFamily(t, familyID), nil)
+	q, err := svc.QuarantineFamily(ctx, familyID, "fraud investigation 42", "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, "fraud investigation 42", q.Reason)
+	assert.Equal(t, "admin-1", q.QuarantinedBy)
+	assert.Contains(t, quarantines.quarantines, familyID)
+
+	// Only administrators may access the family, and every attempt is published
+	err = svc.CheckFamilyAccess(ctx, "GetFamily", false, familyID)
+	assert.True(t, errors.Is(err, domainerrors.ErrFamilyQuarantined))
+	assert.NoError(t, svc.CheckFamilyAccess(ctx, "GetFamily", true, familyID))
+	assert.NoError(t, svc.CheckFamilyAccess(ctx, "GetFamily", false, "a47ac10b-58cc-4372-a567-0e02b2c3d479"))
+
+	require.Len(t, publisher.events, 3)
+	assert.Equal(t, familyID, publisher.events[0].(events.FamilyQuarantined).FamilyID)
+	denied := publisher.events[1].(events.QuarantinedFamilyAccessed)
+	assert.Equal(t, "GetFamily", denied.Operation)
+	assert.False(t, denied.Allowed)
+	assert.True(t, publisher.events[2].(events.QuarantinedFamilyAccessed).Allowed)
+
+	// Releasing the family allows access again
+	released, err := svc.UnquarantineFamily(ctx, familyID)
+	require.NoError(t, err)
+	assert.Equal(t, "fraud investigation 42", released.Reason)
+	assert.IsType(t, events.FamilyUnquarantined{}, publisher.events[3])
+	assert.NoError(t, svc.CheckFamilyAccess(ctx, "GetFamily", false, familyID))
+
+	_, err = svc.UnquarantineFamily(ctx, familyID)
+	assert.True(t, errorswrapper.IsNotFoundError(err))
+}
+
+func TestQuarantineFamilyStoresEventsInOutbox(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	repo := &transactionalRepository{MockFamilyRepository: mock.NewMockFamilyRepository(ctrl)}
+	svc := NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	quarantines := newMemoryQuarantines()
+	svc.SetQuarantineRepository(quarantines)
+	publisher := &recordingPublisher{}
+	outbox := &unitOutbox{}
+	svc.SetEventPublisher(publisher)
+	svc.SetEventOutbox(outbox)
+	ctx := context.Background()
+
+	repo.EXPECT().GetByID(gomock.Any(), familyID).Return(newQuarantineTestFamily(t, familyID), nil)
+	_, err := svc.QuarantineFamily(ctx, familyID, "fraud investigation 42", "admin-1")
+	require.NoError(t, err)
+	assert.Error(t, svc.CheckFamilyAccess(ctx, "GetFamily", false, familyID))
+	_, err = svc.UnquarantineFamily(ctx, familyID)
+	require.NoError(t, err)
+
+	require.Len(t, outbox.events, 3)
+	assert.IsType(t, events.FamilyQuarantined{}, outbox.events[0])
+	assert.IsType(t, events.QuarantinedFamilyAccessed{}, outbox.events[1])
+	assert.IsType(t, events.FamilyUnquarantined{}, outbox.events[2])
+	assert.Equal(t, []interface{}{1, 2, 3}, outbox.units, "each event is stored in a unit of work, with its change if it has one")
+	assert.Equal(t, 3, repo.committed)
+	assert.Equal(t, outbox.events, publisher.events, "each event is published once, not stored again")
+}
+
+func TestQuarantineFamily_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	svc := NewFamilyDomainService(mockRepo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	ctx := context.Background()
+
+	// Without a quarantine repository, quarantines are unavailable and every access is allowed
+	_, err := svc.QuarantineFamily(ctx, familyID, "fraud", "admin-1")
+	assert.True(t, errorswrapper.IsDatabaseError(err))
+	assert.NoError(t, svc.CheckFamilyAccess(ctx, "GetFamily", false, familyID))
+
+	quarantines := newMemoryQuarantines()
+	svc.SetQuarantineRepository(quarantines)
+
+	_, err = svc.QuarantineFamily(ctx, familyID, " ", "admin-1")
+	assert.True(t, errorswrapper.IsValidationError(err))
+
+	mockRepo.EXPECT().GetByID(gomock.Any(), familyID).Return(nil, errorswrapper.NewNotFoundError("Family", familyID, nil))
+	_, err = svc.QuarantineFamily(ctx, familyID, "fraud", "admin-1")
+	assert.True(t, errorswrapper.IsNotFoundError(err))
+	assert.Empty(t, quarantines.quarantines)
+
+	// Access is denied when quarantines cannot be read
+	quarantines.err = errors.New("connection refused")
+	assert.Error(t, svc.CheckFamilyAccess(ctx, "GetFamily", true, familyID))
+}
+
+func TestFilterQuarantinedFamilies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := NewFamilyDomainService(mock.NewMockFamilyRepository(ctrl), loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	quarantines := newMemoryQuarantines()
+	svc.SetQuarantineRepository(quarantines)
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+
+	open := newQuarantineTestFamily(t, "a47ac10b-58cc-4372-a567-0e02b2c3d479")
+	frozen := newQuarantineTestFamily(t, "b47ac10b-58cc-4372-a567-0e02b2c3d479")
+	quarantines.quarantines[frozen.ID()] = entity.Quarantine{FamilyID: frozen.ID(), Reason: "fraud"}
+	families := []*entity.Family{open, frozen}
+
+	visible, err := svc.FilterQuarantinedFamilies(context.Background(), "GetAllFamilies", false, families)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Family{open}, visible)
+
+	visible, err = svc.FilterQuarantinedFamilies(context.Background(), "GetAllFamilies", true, families)
+	require.NoError(t, err)
+	assert.Equal(t, families, visible)
+
+	assert.Equal(t, []events.Event{
+		events.QuarantinedFamilyAccessed{FamilyID: frozen.ID(), Operation: "GetAllFamilies", Allowed: false, At: publisher.events[0].OccurredAt()},
+		events.QuarantinedFamilyAccessed{FamilyID: frozen.ID(), Operation: "GetAllFamilies", Allowed: true, At: publisher.events[1].OccurredAt()},
+	}, publisher.events)
+}
+
+func TestExportFamilies_Quarantined(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	svc := NewFamilyDomainService(mockRepo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	quarantines := newMemoryQuarantines()
+	svc.SetQuarantineRepository(quarantines)
+
+	open := newQuarantineTestFamily(t, "a47ac10b-58cc-4372-a567-0e02b2c3d479")
+	frozen := newQuarantineTestFamily(t, "b47ac10b-58cc-4372-a567-0e02b2c3d479")
+	quarantines.quarantines[frozen.ID()] = entity.Quarantine{FamilyID: frozen.ID(), Reason: "fraud"}
+	mockRepo.EXPECT().GetAll(gomock.Any()).Return([]*entity.Family{open, frozen}, nil).Times(3)
+
+	export := func(admin bool) ([]*entity.Family, int, error) {
+		var exported []*entity.Family
+		count, err := svc.ExportFamilies(context.Background(), admin, "", func(fam *entity.Family) error {
+			exported = append(exported, fam)
+			return nil
+		})
+		return exported, count, err
+	}
+
+	exported, count, err := export(false)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Family{open}, exported)
+	assert.Equal(t, 1, count)
+
+	exported, count, err = export(true)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Family{open, frozen}, exported)
+	assert.Equal(t, 2, count)
+
+	// An error of the caller stops the export and is returned as is
+	stop := errors.New("client went away")
+	count, err = svc.ExportFamilies(context.Background(), true, "", func(*entity.Family) error { return stop })
+	assert.Same(t, stop, err)
+	assert.Zero(t, count)
+}
+
+// pagingRepository is a repository that reads its families a page at a time
+type pagingRepository struct {
+	*mock.MockFamilyRepository
+	families []*entity.Family // In ascending ID order
+	pages    int
+}
+
+func (r *pagingRepository) ListFamilies(_ context.Context, after string, limit int) ([]*entity.Family, error) {
+	r.pages++
+	return query.Page(r.families, after, limit), nil
+}
+
+func TestExportFamilies_After(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	families := make([]*entity.Family, exportPageSize+2)
+	for i := range families {
+		families[i] = newQuarantineTestFamily(t, fmt.Sprintf("%08d-58cc-4372-a567-0e02b2c3d479", i))
+	}
+	export := func(svc *FamilyDomainService, after string) []string {
+		var ids []string
+		_, err := svc.ExportFamilies(context.Background(), true, after, func(fam *entity.Family) error {
+			ids = append(ids, fam.ID())
+			return nil
+		})
+		require.NoError(t, err)
+		return ids
+	}
+	idsOf := func(families []*entity.Family) []string {
+		ids := make([]string, len(families))
+		for i, fam := range families {
+			ids[i] = fam.ID()
+		}
+		return ids
+	}
+
+	// A repository that pages reads only the families after the ID, a page at a time
+	repo := &pagingRepository{MockFamilyRepository: mock.NewMockFamilyRepository(ctrl), families: families}
+	assert.Equal(t, idsOf(families[1:]), export(NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t))), families[0].ID()))
+	assert.Equal(t, 2, repo.pages)
+
+	// The other repositories read every family and skip those before it
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	mockRepo.EXPECT().GetAll(gomock.Any()).Return(families, nil)
+	assert.Equal(t, idsOf(families[2:]), export(NewFamilyDomainService(mockRepo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t))), families[1].ID()))
+}
+
+func TestQuarantineFamily_FrozenClock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	mockRepo := mock.NewMockFamilyRepository(ctrl)
+	svc := NewFamilyDomainService(mockRepo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	svc.SetQuarantineRepository(newMemoryQuarantines())
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+	at := time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC)
+	ctx := clock.Freeze(context.Background(), at)
+
+	mockRepo.EXPECT().GetByID(gomock.Any(), familyID).Return(newQuarantineTestFamily(t, familyID), nil)
+	q, err := svc.QuarantineFamily(ctx, familyID, "fraud investigation 42", "admin-1")
+	require.NoError(t, err)
+	assert.True(t, at.Equal(q.QuarantinedAt))
+
+	_, err = svc.UnquarantineFamily(ctx, familyID)
+	require.NoError(t, err)
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, at, publisher.events[1].(events.FamilyUnquarantined).At)
+}
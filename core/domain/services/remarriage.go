@@ -0,0 +1,300 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/metrics"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"go.uber.org/zap"
+)
+
+// Remarry marries the parent of a single, divorced, or widowed family to a new spouse.
+// The family keeps its ID and children. A spouse who is the parent of another family
+// joins with MergeFamilies instead.
+//
+// Returns:
+//   - The married family
+//   - An error if the spouse is invalid or the family cannot remarry
+func (s *FamilyDomainService) Remarry(ctx context.Context, familyID string, spouseDTO entity.ParentDTO) (*entity.FamilyDTO, error) {
+	// Start a new span for this operation
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.Remarry")
+	defer span.End()
+
+	// Start timer for operation duration
+	startTime := time.Now()
+
+	s.logger.Info(ctx, "Remarrying family in domain service",
+		zap.String("family_id", familyID),
+		zap.String("spouse_id", spouseDTO.ID))
+
+	if familyID == "" {
+		metrics.FamilyOperationsTotal.WithLabelValues("remarry", metrics.StatusFailure).Inc()
+
+		s.logger.Warn(ctx, "Family ID is required for Remarry")
+		return nil, errorswrapper.NewValidationError("family ID is required", "familyID", nil)
+	}
+
+	fam, err := s.getFamilyForRemarriage(ctx, "Remarry", "remarry", familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	spouse, err := entity.ParentFromDTO(spouseDTO)
+	if err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("remarry", metrics.StatusFailure).Inc()
+
+		s.logger.Error(ctx, "Invalid spouse data for Remarry",
+			zap.Error(err),
+			zap.String("spouse_id", spouseDTO.ID))
+		return nil, errorswrapper.NewValidationError("invalid spouse data", "spouse", err)
+	}
+
+	// Create a span for the domain logic of the remarriage
+	ctx, remarrySpan := s.tracer.Start(ctx, "Domain.Remarry")
+	previousStatus := fam.Status()
+	if err := fam.Remarry(spouse); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("remarry", metrics.StatusFailure).Inc()
+		remarrySpan.End()
+
+		s.logger.Info(ctx, "Failed to remarry family",
+			zap.Error(err),
+			zap.String("family_id", familyID))
+		return nil, err
+	}
+	remarrySpan.End()
+
+	if err := s.EnforceAgePolicy(ctx, fam, "remarry"); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("remarry", metrics.StatusFailure).Inc()
+		return nil, err
+	}
+
+	// Create a span for saving the family
+	ctx, saveSpan := s.tracer.Start(ctx, "Repository.Save.Remarry")
+	if err := s.repo.Save(ctx, fam); err != nil {
+		metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusFailure).Inc()
+		saveSpan.End()
+		metrics.FamilyOperationsTotal.WithLabelValues("remarry", metrics.StatusFailure).Inc()
+
+		s.logger.Error(ctx, "Failed to save family after remarriage",
+			zap.Error(err),
+			zap.String("family_id", familyID))
+		return nil, errorswrapper.NewDatabaseError("failed to save family", "save", "families", err)
+	}
+	metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusSuccess).Inc()
+	metrics.RepositoryOperationsDuration.WithLabelValues("save").Observe(time.Since(startTime).Seconds())
+	saveSpan.End()
+
+	// Update family member and status counts
+	metrics.FamilyMemberCounts.WithLabelValues("parents").Inc()
+	metrics.FamilyStatusCounts.WithLabelValues(statusLabel(previousStatus)).Dec()
+	metrics.FamilyStatusCounts.WithLabelValues(statusLabel(entity.Married)).Inc()
+
+	// Record metrics for operation success
+	metrics.FamilyOperationsTotal.WithLabelValues("remarry", metrics.StatusSuccess).Inc()
+	metrics.FamilyOperationsDuration.WithLabelValues("remarry").Observe(time.Since(startTime).Seconds())
+
+	resultDTO := fam.ToDTO()
+	s.logger.Info(ctx, "Successfully remarried family",
+		zap.String("family_id", resultDTO.ID),
+		zap.String("spouse_id", spouse.ID()))
+	return &resultDTO, nil
+}
+
+// MergeFamilies merges the family of a spouse into a family whose parent remarries.
+//
+// The parent of the spouse family joins the family, which marries, and the children of
+// the spouse family with the given IDs move along with their parent. The business rules
+// are those of entity.MergeFamilies, enforced before anything is saved.
+//
+// The families are saved by a saga: the merged family first, so that the moving children
+// are never in no family, then the spouse family. A spouse family that is left without
+// children is dissolved, that is soft deleted, if the repository can delete families;
+// otherwise it is saved with its parent alone. If the spouse family cannot be saved or
+// dissolved, the merged family is restored to its previous state; if the repository
+// implements ports.UnitOfWork, both changes are made in one unit of work instead, which is
+// rolled back. A ChildMoved event
+// records each moved child and a FamilyDeleted event the dissolved spouse family; the
+// events are stored in the outbox with the changes.
+//
+// Returns:
+//   - The merged family
+//   - An error if the families could not be merged
+func (s *FamilyDomainService) MergeFamilies(ctx context.Context, familyID string, spouseFamilyID string, childIDs []string) (*entity.FamilyDTO, error) {
+	// Start a new span for this operation
+	ctx, span := s.tracer.Start(ctx, "FamilyDomainService.MergeFamilies")
+	defer span.End()
+
+	// Start timer for operation duration
+	startTime := time.Now()
+
+	s.logger.Info(ctx, "Merging families in domain service",
+		zap.String("family_id", familyID),
+		zap.String("spouse_family_id", spouseFamilyID),
+		zap.Strings("child_ids", childIDs))
+
+	if familyID == "" || spouseFamilyID == "" {
+		metrics.FamilyOperationsTotal.WithLabelValues("merge_families", metrics.StatusFailure).Inc()
+
+		s.logger.Warn(ctx, "Family IDs are required for MergeFamilies",
+			zap.String("family_id", familyID),
+			zap.String("spouse_family_id", spouseFamilyID))
+		return nil, errorswrapper.NewValidationError("family ID and spouse family ID are required", "familyID/spouseFamilyID", nil)
+	}
+
+	// Get both families
+	fam, err := s.getFamilyForRemarriage(ctx, "MergeFamilies", "merge_families", familyID)
+	if err != nil {
+		return nil, err
+	}
+	spouseFam, err := s.getFamilyForRemarriage(ctx, "MergeFamilies", "merge_families", spouseFamilyID)
+	if err != nil {
+		return nil, err
+	}
+	previous := fam.ToDTO()
+	previousStatus := fam.Status()
+
+	// Create a span for the domain logic of the merge
+	ctx, mergeSpan := s.tracer.Start(ctx, "Domain.MergeFamilies")
+	if err := entity.MergeFamilies(fam, spouseFam, childIDs); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("merge_families", metrics.StatusFailure).Inc()
+		mergeSpan.End()
+
+		s.logger.Info(ctx, "Failed to merge families",
+			zap.Error(err),
+			zap.String("family_id", familyID),
+			zap.String("spouse_family_id", spouseFamilyID))
+		return nil, err
+	}
+	mergeSpan.End()
+
+	if err := s.EnforceAgePolicy(ctx, fam, "merge_families"); err != nil {
+		metrics.FamilyOperationsTotal.WithLabelValues("merge_families", metrics.StatusFailure).Inc()
+		return nil, err
+	}
+
+	// A spouse family without children is dissolved, if the repository can delete families
+	deleter, canDelete := s.repo.(ports.FamilyDeleter)
+	dissolve := canDelete && len(spouseFam.Children()) == 0
+	at := clock.Now(ctx).UTC()
+
+	// The events of the merge
+	var raised []events.Event
+	for _, childID := range childIDs {
+		raised = append(raised, events.ChildMoved{
+			ChildID:      childID,
+			FromFamilyID: spouseFamilyID,
+			ToFamilyID:   familyID,
+			At:           at,
+		})
+	}
+	if dissolve {
+		raised = append(raised, events.FamilyDeleted{FamilyID: spouseFamilyID, At: at})
+	}
+
+	// Create a span for saving both families
+	ctx, saveSpan := s.tracer.Start(ctx, "Repository.Save.MergeFamilies")
+	spouseStep := sagaStep{
+		name:   "save spouse family",
+		action: func(ctx context.Context) error { return s.repo.Save(ctx, spouseFam) },
+	}
+	if dissolve {
+		spouseStep = sagaStep{
+			name: "dissolve spouse family",
+			action: func(ctx context.Context) error {
+				deleted, err := deleter.SoftDeleteFamily(ctx, spouseFamilyID, at)
+				if err == nil && !deleted {
+					err = errorswrapper.NewNotFoundError("Family", spouseFamilyID, nil)
+				}
+				return err
+			},
+		}
+	}
+	merge := s.newSaga("merge_families",
+		sagaStep{
+			name:       "save merged family",
+			action:     func(ctx context.Context) error { return s.repo.Save(ctx, fam) },
+			compensate: restoreFamily(s.repo, previous, fam),
+		},
+		spouseStep,
+	).raise(raised...)
+	if step, err := merge.run(ctx); err != nil {
+		metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusFailure).Inc()
+		saveSpan.End()
+		metrics.FamilyOperationsTotal.WithLabelValues("merge_families", metrics.StatusFailure).Inc()
+
+		s.logger.Error(ctx, "Failed to save families after merging them",
+			zap.Error(err),
+			zap.String("step", step),
+			zap.String("family_id", familyID),
+			zap.String("spouse_family_id", spouseFamilyID))
+		if errors.Is(err, domainerrors.ErrMutationTimedOut) {
+			return nil, err
+		}
+		return nil, errorswrapper.NewDatabaseError("failed to save families after merging them", "save", "families", err)
+	}
+	metrics.RepositoryOperationsTotal.WithLabelValues("save", metrics.StatusSuccess).Add(2)
+	metrics.RepositoryOperationsDuration.WithLabelValues("save").Observe(time.Since(startTime).Seconds())
+	saveSpan.End()
+
+	// Update family status counts
+	metrics.FamilyStatusCounts.WithLabelValues(statusLabel(previousStatus)).Dec()
+	metrics.FamilyStatusCounts.WithLabelValues(statusLabel(entity.Married)).Inc()
+
+	for _, event := range raised {
+		s.publishStored(ctx, event)
+	}
+
+	// Record metrics for operation success
+	metrics.FamilyOperationsTotal.WithLabelValues("merge_families", metrics.StatusSuccess).Inc()
+	metrics.FamilyOperationsDuration.WithLabelValues("merge_families").Observe(time.Since(startTime).Seconds())
+
+	resultDTO := fam.ToDTO()
+	s.logger.Info(ctx, "Successfully merged families",
+		zap.String("family_id", resultDTO.ID),
+		zap.String("spouse_family_id", spouseFamilyID),
+		zap.Bool("spouse_family_dissolved", dissolve),
+		zap.Int("children_count", resultDTO.ChildrenCount))
+	return &resultDTO, nil
+}
+
+// getFamilyForRemarriage retrieves one of the families of a Remarry or MergeFamilies operation
+func (s *FamilyDomainService) getFamilyForRemarriage(ctx context.Context, method, operation, familyID string) (*entity.Family, error) {
+	// Create a span for retrieving the family
+	ctx, getSpan := s.tracer.Start(ctx, "Repository.GetByID."+method)
+	defer getSpan.End()
+
+	startTime := time.Now()
+	fam, err := s.repo.GetByID(ctx, familyID)
+	if err != nil {
+		metrics.RepositoryOperationsTotal.WithLabelValues("get_by_id", metrics.StatusFailure).Inc()
+		metrics.FamilyOperationsTotal.WithLabelValues(operation, metrics.StatusFailure).Inc()
+
+		if errorswrapper.IsNotFoundError(err) {
+			s.logger.Info(ctx, "Family not found for "+method, zap.String("family_id", familyID))
+			return nil, err // Pass through not found errors
+		}
+		s.logger.Error(ctx, "Failed to retrieve family for "+method,
+			zap.Error(err),
+			zap.String("family_id", familyID))
+		return nil, errorswrapper.NewDatabaseError("failed to retrieve family", "query", "families", err)
+	}
+
+	metrics.RepositoryOperationsTotal.WithLabelValues("get_by_id", metrics.StatusSuccess).Inc()
+	metrics.RepositoryOperationsDuration.WithLabelValues("get_by_id").Observe(time.Since(startTime).Seconds())
+	return fam, nil
+}
+
+// statusLabel returns the label of a family status in the family status metrics
+func statusLabel(status entity.Status) string {
+	return strings.ToLower(string(status))
+}
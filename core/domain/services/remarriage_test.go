@@ -0,0 +1,115 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/ports/mock"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/loggingwrapper"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMergeFamilies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	familyID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	spouseFamilyID := "b47ac10b-58cc-4372-a567-0e02b2c3d481"
+	childID := "9b2f4e3a-7c1d-4f8e-a6b5-3d2c1b0a9f8e"
+
+	repo := &deletingRepository{MockFamilyRepository: mock.NewMockFamilyRepository(ctrl)}
+	svc := NewFamilyDomainService(repo, loggingwrapper.NewContextLogger(zaptest.NewLogger(t)))
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+
+	// newFamilies creates a widowed family and a divorced spouse family with a child
+	newFamilies := func(t *testing.T) (*entity.Family, *entity.Family) {
+		parent, _ := entity.NewParent("38f5b8ed-1eb0-4a20-9f0e-7c3b3c3f3f3f", "Jane", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		spouse, _ := entity.NewParent("a47ac10b-58cc-4372-a567-0e02b2c3d480", "John", "Roe", time.Date(1975, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		child, _ := entity.NewChild(childID, "Jimmy", "Roe", time.Date(2010, 6, 1, 0, 0, 0, 0, time.UTC), nil)
+		fam, err := entity.NewFamily(familyID, entity.Widowed, []*entity.Parent{parent}, nil)
+		require.NoError(t, err)
+		spouseFam, err := entity.NewFamily(spouseFamilyID, entity.Divorced, []*entity.Parent{spouse}, []*entity.Child{child})
+		require.NoError(t, err)
+		return fam, spouseFam
+	}
+
+	t.Run("merges the families and dissolves the empty spouse family", func(t *testing.T) {
+		publisher.events = nil
+		repo.deletedAt = map[string]*time.Time{spouseFamilyID: nil}
+		fam, spouseFam := newFamilies(t)
+		repo.EXPECT().GetByID(gomock.Any(), familyID).Return(fam, nil)
+		repo.EXPECT().GetByID(gomock.Any(), spouseFamilyID).Return(spouseFam, nil)
+		repo.EXPECT().Save(gomock.Any(), fam).Return(nil)
+
+		result, err := svc.MergeFamilies(context.Background(), familyID, spouseFamilyID, []string{childID})
+
+		require.NoError(t, err)
+		assert.Equal(t, string(entity.Married), result.Status)
+		assert.Equal(t, 2, result.ParentCount)
+		assert.Equal(t, 1, result.ChildrenCount)
+		assert.NotNil(t, repo.deletedAt[spouseFamilyID])
+		require.Len(t, publisher.events, 2)
+		moved, ok := publisher.events[0].(events.ChildMoved)
+		require.True(t, ok)
+		assert.Equal(t, spouseFamilyID, moved.FromFamilyID)
+		assert.Equal(t, familyID, moved.ToFamilyID)
+		deleted, ok := publisher.events[1].(events.FamilyDeleted)
+		require.True(t, ok)
+		assert.Equal(t, spouseFamilyID, deleted.FamilyID)
+	})
+
+	t.Run("keeps a spouse family with children that do not move", func(t *testing.T) {
+		publisher.events = nil
+		repo.deletedAt = map[string]*time.Time{spouseFamilyID: nil}
+		fam, spouseFam := newFamilies(t)
+		repo.EXPECT().GetByID(gomock.Any(), familyID).Return(fam, nil)
+		repo.EXPECT().GetByID(gomock.Any(), spouseFamilyID).Return(spouseFam, nil)
+		gomock.InOrder(
+			repo.EXPECT().Save(gomock.Any(), fam).Return(nil),
+			repo.EXPECT().Save(gomock.Any(), spouseFam).Return(nil),
+		)
+
+		result, err := svc.MergeFamilies(context.Background(), familyID, spouseFamilyID, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, string(entity.Married), result.Status)
+		assert.Equal(t, 0, result.ChildrenCount)
+		assert.Nil(t, repo.deletedAt[spouseFamilyID])
+		assert.Empty(t, publisher.events)
+	})
+
+	t.Run("restores the merged family if the spouse family cannot be dissolved", func(t *testing.T) {
+		publisher.events = nil
+		repo.deletedAt = map[string]*time.Time{spouseFamilyID: nil}
+		repo.err = errors.New("connection lost")
+		defer func() { repo.err = nil }()
+		fam, spouseFam := newFamilies(t)
+		repo.EXPECT().GetByID(gomock.Any(), familyID).Return(fam, nil)
+		repo.EXPECT().GetByID(gomock.Any(), spouseFamilyID).Return(spouseFam, nil)
+		gomock.InOrder(
+			repo.EXPECT().Save(gomock.Any(), fam).Return(nil),
+			repo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, restored *entity.Family) error {
+				assert.Equal(t, familyID, restored.ID())
+				assert.Equal(t, entity.Widowed, restored.Status())
+				assert.Empty(t, restored.Children())
+				return nil
+			}),
+		)
+
+		result, err := svc.MergeFamilies(context.Background(), familyID, spouseFamilyID, []string{childID})
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Empty(t, publisher.events)
+	})
+}
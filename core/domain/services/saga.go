@@ -0,0 +1,238 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/metrics"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/loggingwrapper"
+	"go.uber.org/zap"
+)
+
+// WithMutationTimeout bounds a mutation by a timeout. When the timeout passes, the context
+// is cancelled with ErrMutationTimedOut as its cause: pending reads and saves fail, a saga
+// rolls back its completed saves instead of running its next step, and MutationTimedOut
+// reports the failure. A timeout of zero or less does not bound the mutation.
+func WithMutationTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, domainerrors.ErrMutationTimedOut)
+}
+
+// MutationTimedOut returns the error of a mutation whose timeout has passed as a
+// MutationTimedOutError, and any other error unchanged
+func MutationTimedOut(ctx context.Context, err error) error {
+	if err == nil || !timedOut(ctx) {
+		return err
+	}
+	var timedOutErr *domainerrors.MutationTimedOutError
+	if errors.As(err, &timedOutErr) {
+		return timedOutErr
+	}
+	return domainerrors.NewMutationTimedOutError("the change did not complete in time and was not applied", err)
+}
+
+// timedOut reports whether the mutation timeout of the context has passed
+func timedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), domainerrors.ErrMutationTimedOut)
+}
+
+// sagaStep is a step of a saga: an action, and the compensation that undoes it
+// if a later step fails. The last step of a saga needs no compensation.
+type sagaStep struct {
+	name       string
+	action     func(ctx context.Context) error
+	compensate func(ctx context.Context) error
+}
+
+// restoreFamily returns the compensation of a step that saved a changed family: it saves
+// the family in its state before the change. The state is saved over the version that the
+// step saved, so the compensation fails, rather than overwrite it, if the family has been
+// changed again since.
+func restoreFamily(repo ports.FamilyRepository, previous entity.FamilyDTO, saved *entity.Family) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		original, err := entity.FamilyFromDTO(previous)
+		if err != nil {
+			return err
+		}
+		original.SetVersion(saved.Version())
+		return repo.Save(ctx, original)
+	}
+}
+
+// saga coordinates changes to several aggregates. If the repository implements
+// ports.UnitOfWork, the steps run in one unit of work, which is committed once they all
+// succeed and rolled back if one fails, so that the changes are applied together or not at
+// all. Otherwise the changes cannot be saved in one transaction: the steps run in order
+// and, if a step fails, the completed steps are compensated in reverse order, so that the
+// aggregates return to their state before the saga. A saga whose mutation timeout passes
+// is rolled back or compensated the same way before its next step, so that a slow
+// mutation is never left half-applied. The events that the saga raises are stored in the
+// outbox in its unit of work, before it is committed, or, without one, once all steps have
+// succeeded.
+type saga struct {
+	name   string
+	logger *loggingwrapper.ContextLogger
+	steps  []sagaStep
+	unit   ports.UnitOfWork     // Runs the steps in one transaction; nil to compensate them instead
+	outbox ports.EventPublisher // Stores the events of the saga; nil to store none
+	events []events.Event
+}
+
+// newSaga creates a new saga with the given steps
+func newSaga(name string, logger *loggingwrapper.ContextLogger, steps ...sagaStep) *saga {
+	return &saga{name: name, logger: logger, steps: steps}
+}
+
+// newSaga creates a new saga with the given steps, which runs in a unit of work if the
+// repository of the service implements ports.UnitOfWork and stores its events in the outbox
+// of the service
+func (s *FamilyDomainService) newSaga(name string, steps ...sagaStep) *saga {
+	sg := newSaga(name, s.logger, steps...)
+	sg.unit, _ = s.repo.(ports.UnitOfWork)
+	sg.outbox = s.outbox
+	return sg
+}
+
+// raise adds events to the events that the saga stores in the outbox. A step may raise the
+// events of the changes it makes, which are known only once it has made them.
+func (s *saga) raise(evts ...events.Event) *saga {
+	s.events = append(s.events, evts...)
+	return s
+}
+
+// run runs the steps of the saga, in a unit of work if the saga has one and the repository
+// can run it.
+//
+// Returns:
+//   - The name of the failed step, or an empty string if all steps succeeded
+//   - The error of the failed step, or a MutationTimedOutError if the mutation timeout passed
+func (s *saga) run(ctx context.Context) (string, error) {
+	if s.unit == nil {
+		return s.runCompensated(ctx)
+	}
+	unitCtx, err := s.unit.Begin(ctx)
+	if errors.Is(err, ports.ErrNoUnitOfWork) {
+		return s.runCompensated(ctx)
+	}
+	if err != nil {
+		return "begin unit of work", err
+	}
+
+	rollback := func(ctx context.Context, completed []sagaStep) { s.rollback(ctx) }
+	if step, err := s.runSteps(unitCtx, rollback); err != nil {
+		return step, err
+	}
+	if err := s.storeEvents(unitCtx); err != nil {
+		return "store events in outbox", s.fail(ctx, len(s.steps), err, rollback)
+	}
+	if err := s.unit.Commit(unitCtx); err != nil {
+		return "commit unit of work", s.fail(ctx, len(s.steps), err, rollback)
+	}
+	return "", nil
+}
+
+// runCompensated runs the steps of the saga without a unit of work and compensates the
+// completed steps if one fails. The steps have been saved when the events are stored, so a
+// failure to store them is logged rather than returned.
+func (s *saga) runCompensated(ctx context.Context) (string, error) {
+	if step, err := s.runSteps(ctx, s.compensate); err != nil {
+		return step, err
+	}
+	if err := s.storeEvents(ctx); err != nil {
+		s.logger.Error(ctx, "Failed to store events of saga in outbox",
+			zap.Error(err),
+			zap.String("saga", s.name))
+	}
+	return "", nil
+}
+
+// storeEvents stores the events of the saga in the outbox
+func (s *saga) storeEvents(ctx context.Context) error {
+	if s.outbox == nil {
+		return nil
+	}
+	for _, event := range s.events {
+		if err := s.outbox.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runSteps runs the steps of the saga and undoes the completed steps if one fails
+func (s *saga) runSteps(ctx context.Context, undo func(ctx context.Context, completed []sagaStep)) (string, error) {
+	for i, step := range s.steps {
+		var err error
+		if timedOut(ctx) {
+			err = context.Cause(ctx)
+		} else {
+			err = step.action(ctx)
+		}
+		if err == nil {
+			continue
+		}
+		return step.name, s.fail(ctx, i, err, undo)
+	}
+	return "", nil
+}
+
+// fail undoes the given number of completed steps after a failure and returns the error of
+// the failure
+func (s *saga) fail(ctx context.Context, completed int, err error, undo func(ctx context.Context, completed []sagaStep)) error {
+	reason := metrics.RollbackFailure
+	if timedOut(ctx) {
+		reason = metrics.RollbackTimeout
+		err = domainerrors.NewMutationTimedOutError("the change did not complete in time and was rolled back", err)
+	}
+	if completed > 0 {
+		metrics.MutationRollbacks.WithLabelValues(s.name, reason).Inc()
+	}
+	undo(ctx, s.steps[:completed])
+	return err
+}
+
+// rollback discards the changes of the unit of work of the saga.
+// The rollback runs even if the context has been cancelled, so that the transaction ends.
+func (s *saga) rollback(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	if err := s.unit.Rollback(ctx); err != nil {
+		s.logger.Error(ctx, "Failed to roll back unit of work of saga",
+			zap.Error(err),
+			zap.String("saga", s.name))
+		return
+	}
+	s.logger.Warn(ctx, "Rolled back unit of work of saga", zap.String("saga", s.name))
+}
+
+// compensate undoes the completed steps in reverse order.
+// Compensations run even if the context has been cancelled, since leaving the
+// aggregates half-changed is worse than finishing late.
+func (s *saga) compensate(ctx context.Context, completed []sagaStep) {
+	ctx = context.WithoutCancel(ctx)
+	for i := len(completed) - 1; i >= 0; i-- {
+		step := completed[i]
+		if step.compensate == nil {
+			continue
+		}
+		if err := step.compensate(ctx); err != nil {
+			// The aggregates are inconsistent and need manual repair
+			s.logger.Error(ctx, "Failed to compensate saga step - CRITICAL ERROR",
+				zap.Error(err),
+				zap.String("saga", s.name),
+				zap.String("step", step.name))
+			continue
+		}
+		s.logger.Warn(ctx, "Compensated saga step",
+			zap.String("saga", s.name),
+			zap.String("step", step.name))
+	}
+}
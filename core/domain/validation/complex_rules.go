@@ -5,6 +5,7 @@
 import (
 	"context"
 
+	"github.com/abitofhelp/family-service/core/domain/chronology"
 	"github.com/abitofhelp/family-service/core/domain/entity"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
 )
@@ -100,14 +101,8 @@ func (r *ParentChildAgeGapRule) Validate(ctx context.Context, e interface{}) err
 		for _, parent := range parents {
 			parentBirthDate := parent.BirthDate()
 
-			// Calculate the age difference in years
-			ageGap := childBirthDate.Year() - parentBirthDate.Year()
-
-			// Adjust for partial years
-			if childBirthDate.Month() < parentBirthDate.Month() || 
-			   (childBirthDate.Month() == parentBirthDate.Month() && childBirthDate.Day() < parentBirthDate.Day()) {
-				ageGap--
-			}
+			// Calculate the age of the parent in whole years when the child was born
+			ageGap := chronology.Years(parentBirthDate, childBirthDate)
 
 			if ageGap < r.minimumAgeGap {
 				return errorswrapper.NewValidationError("too small age gap between parent and child", "Family", nil)
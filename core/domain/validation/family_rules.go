@@ -4,8 +4,9 @@
 
 import (
 	"context"
-	"time"
 
+	"github.com/abitofhelp/family-service/core/domain/chronology"
+	"github.com/abitofhelp/family-service/core/domain/clock"
 	"github.com/abitofhelp/family-service/core/domain/entity"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
 )
@@ -29,15 +30,9 @@ func (r *ParentAgeRule) Validate(ctx context.Context, e interface{}) error {
 		return errorswrapper.NewValidationError("entity is not a Family", "entity", nil)
 	}
 
-	now := time.Now()
+	now := clock.Now(ctx)
 	for _, parent := range family.Parents() {
-		birthDate := parent.BirthDate()
-		age := now.Year() - birthDate.Year()
-
-		// Adjust age if birthday hasn't occurred yet this year
-		if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
-			age--
-		}
+		age := chronology.Age(parent.BirthDate(), now)
 
 		if age < r.minimumAge {
 			return errorswrapper.NewValidationError("parent does not meet minimum age requirement", "Parent", nil)
@@ -7,6 +7,7 @@
 	"testing"
 	"time"
 
+	"github.com/abitofhelp/family-service/core/domain/clock"
 	"github.com/abitofhelp/family-service/core/domain/entity"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -301,3 +302,12 @@ func TestFamilyStatusConsistencyRule(t *testing.T) {
 		assert.Contains(t, err.Error(), "abandoned family must have at least one child")
 	})
 }
+
+func TestParentAgeRule_UsesTheClockOfTheContext(t *testing.T) {
+	family := createValidSingleFamily() // parent born 30 years ago
+	rule := NewParentAgeRule(40)
+	ctx := context.Background()
+
+	assert.Error(t, rule.Validate(ctx, family))
+	assert.NoError(t, rule.Validate(clock.Freeze(ctx, time.Now().AddDate(15, 0, 0)), family))
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package workload classifies the work an operation does, so that adapters can run heavy
+// work on separate resources.
+//
+// Reports, statistics, and exports read many families and can tolerate slower, bounded
+// queries. They mark their context with Analytics, and repositories that have resources
+// dedicated to analytics, such as a separate PostgreSQL connection pool, use them for the
+// reads of a marked context. Unmarked operations are interactive and use the primary
+// resources, and so do marked operations in repositories without analytics resources.
+package workload
+
+import "context"
+
+// analyticsKey is the context key that marks analytics work
+type analyticsKey struct{}
+
+// Analytics marks a context as doing analytics work
+func Analytics(ctx context.Context) context.Context {
+	return context.WithValue(ctx, analyticsKey{}, true)
+}
+
+// IsAnalytics reports whether a context is marked as doing analytics work
+func IsAnalytics(ctx context.Context) bool {
+	analytics, _ := ctx.Value(analyticsKey{}).(bool)
+	return analytics
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package workload
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalytics(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, IsAnalytics(ctx))
+
+	analytics := Analytics(ctx)
+	assert.True(t, IsAnalytics(analytics))
+	assert.False(t, IsAnalytics(ctx), "the parent context is not marked")
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package alerting notifies operators of critical conditions of the service.
+//
+// Conditions, such as a circuit breaker that has not closed for several minutes or an
+// integrity check that found corrupt families, are evaluated on a schedule by a Monitor.
+// The alerts that fire are sent through the Notifier port to the configured adapters:
+// Alertmanager, Slack, or a generic webhook. Notifications are deduplicated: a firing
+// alert is sent when it starts, then again only once per repeat interval while it keeps
+// firing, and once more when it resolves. They are also rate limited, so that a flapping
+// condition cannot flood the receivers; a notification that is dropped by the rate limit
+// is sent at a later evaluation if its alert is still firing.
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Severity is the severity of an alert
+type Severity string
+
+const (
+	// SeverityCritical is a condition that needs the attention of an operator now
+	SeverityCritical Severity = "critical"
+
+	// SeverityWarning is a condition that needs the attention of an operator soon
+	SeverityWarning Severity = "warning"
+)
+
+// Outcomes of notifications, as counted by the notifications metric
+const (
+	OutcomeSent        = "sent"
+	OutcomeFailed      = "failed"
+	OutcomeRateLimited = "rate_limited"
+)
+
+// notificationsTotal counts notifications by alert and outcome
+var notificationsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "alert_notifications_total",
+		Help: "Total number of alert notifications, by alert and by whether they were sent, failed, or dropped by the rate limit",
+	},
+	[]string{"alert", "outcome"},
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(notificationsTotal)
+}
+
+// Alert is a critical condition of the service
+type Alert struct {
+	Name     string            `json:"name"`               // Name of the condition, such as "CircuitOpen"
+	Severity Severity          `json:"severity"`           // Severity of the condition
+	Summary  string            `json:"summary"`            // Description of the condition for operators
+	Labels   map[string]string `json:"labels,omitempty"`   // Labels that tell alerts of the same condition apart, such as the circuit
+	StartsAt time.Time         `json:"startsAt"`           // Time at which the condition started
+	EndsAt   time.Time         `json:"endsAt,omitzero"`    // Time at which the condition resolved (zero while it fires)
+	Resolved bool              `json:"resolved,omitempty"` // Whether the condition has resolved
+}
+
+// Key identifies an alert for deduplication: its name and labels
+func (a Alert) Key() string {
+	labels := make([]string, 0, len(a.Labels))
+	for name, value := range a.Labels {
+		labels = append(labels, name+"="+value)
+	}
+	sort.Strings(labels)
+	return a.Name + "{" + strings.Join(labels, ",") + "}"
+}
+
+// Notifier is the port through which alerts are sent to operators
+type Notifier interface {
+	// Notify sends an alert that fires or has resolved
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Condition is a critical condition that the monitor evaluates on a schedule
+type Condition interface {
+	// Check returns the alerts that fire at the given time
+	Check(ctx context.Context, now time.Time) []Alert
+}
+
+// ConditionFunc adapts a function to a Condition
+type ConditionFunc func(ctx context.Context, now time.Time) []Alert
+
+// Check calls the function
+func (f ConditionFunc) Check(ctx context.Context, now time.Time) []Alert {
+	return f(ctx, now)
+}
+
+// active is an alert that fires, with the time at which it was last sent
+type active struct {
+	alert    Alert
+	lastSent time.Time // Zero if it has not been sent yet
+}
+
+// Monitor evaluates conditions and sends the alerts that fire, deduplicated and rate limited
+type Monitor struct {
+	notifiers      []Notifier
+	repeatInterval time.Duration
+	rateLimit      int
+	logger         *zap.Logger
+	now            func() time.Time
+
+	mu         sync.Mutex
+	conditions []Condition
+	active     map[string]*active
+	sent       []time.Time // Times of the notifications sent in the last minute
+}
+
+// NewMonitor creates a monitor without conditions
+//
+// Parameters:
+//   - notifiers: The adapters the alerts are sent to
+//   - repeatInterval: Time after which an alert that keeps firing is sent again (zero sends it once)
+//   - rateLimit: Maximum number of notifications sent per minute (zero does not limit them)
+//   - logger: Logger for failed and dropped notifications
+//
+// Returns:
+//   - A new monitor
+func NewMonitor(notifiers []Notifier, repeatInterval time.Duration, rateLimit int, logger *zap.Logger) *Monitor {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Monitor{
+		notifiers:      notifiers,
+		repeatInterval: repeatInterval,
+		rateLimit:      rateLimit,
+		logger:         logger,
+		now:            time.Now,
+		active:         make(map[string]*active),
+	}
+}
+
+// AddCondition adds a condition to evaluate
+func (m *Monitor) AddCondition(c Condition) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conditions = append(m.conditions, c)
+}
+
+// Evaluate checks the conditions and sends the alerts that started firing, that keep
+// firing past the repeat interval, and that resolved. It is the job of a periodic worker.
+//
+// Returns:
+//   - An error if a notification failed; the others are still sent
+func (m *Monitor) Evaluate(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	firing := make(map[string]Alert)
+	for _, c := range m.conditions {
+		for _, alert := range c.Check(ctx, now) {
+			firing[alert.Key()] = alert
+		}
+	}
+
+	var errs []error
+	keys := make([]string, 0, len(firing))
+	for key := range firing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		a, ok := m.active[key]
+		if !ok {
+			a = &active{}
+			m.active[key] = a
+		}
+		a.alert = firing[key]
+		if !a.lastSent.IsZero() && (m.repeatInterval <= 0 || now.Sub(a.lastSent) < m.repeatInterval) {
+			continue
+		}
+		sent, err := m.send(ctx, now, a.alert)
+		if err != nil {
+			errs = append(errs, err)
+		} else if sent {
+			a.lastSent = now
+		}
+	}
+
+	// Alerts that no longer fire have resolved; those never sent are dropped silently
+	for key, a := range m.active {
+		if _, ok := firing[key]; ok {
+			continue
+		}
+		if a.lastSent.IsZero() {
+			delete(m.active, key)
+			continue
+		}
+		resolved := a.alert
+		resolved.Resolved = true
+		resolved.EndsAt = now
+		sent, err := m.send(ctx, now, resolved)
+		if err != nil {
+			errs = append(errs, err)
+		} else if sent {
+			delete(m.active, key)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send sends an alert to every notifier and reports whether it was sent, rather than
+// dropped because the rate limit is reached
+func (m *Monitor) send(ctx context.Context, now time.Time, alert Alert) (bool, error) {
+	window := now.Add(-time.Minute)
+	for len(m.sent) > 0 && !m.sent[0].After(window) {
+		m.sent = m.sent[1:]
+	}
+	if m.rateLimit > 0 && len(m.sent) >= m.rateLimit {
+		notificationsTotal.WithLabelValues(alert.Name, OutcomeRateLimited).Inc()
+		m.logger.Warn("Alert notification dropped by the rate limit",
+			zap.String("alert", alert.Key()),
+			zap.Int("rate_limit", m.rateLimit))
+		return false, nil
+	}
+	m.sent = append(m.sent, now)
+
+	var errs []error
+	for _, n := range m.notifiers {
+		if err := n.Notify(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		notificationsTotal.WithLabelValues(alert.Name, OutcomeFailed).Inc()
+		return true, fmt.Errorf("failed to send alert %s: %w", alert.Key(), err)
+	}
+	notificationsTotal.WithLabelValues(alert.Name, OutcomeSent).Inc()
+	return true, nil
+}
+
+// NewNotifiers creates the notifiers of the configured adapters
+//
+// Returns:
+//   - The notifiers
+//   - An error if no adapter is configured, or the URL of an adapter is invalid
+func NewNotifiers(cfg config.AlertsConfig) ([]Notifier, error) {
+	var notifiers []Notifier
+	if cfg.Alertmanager.URL != "" {
+		n, err := NewAlertmanager(cfg.Alertmanager.URL, cfg.Service, cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	if cfg.Slack.WebhookURL != "" {
+		n, err := NewSlack(cfg.Slack.WebhookURL, cfg.Service, cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	if cfg.Webhook.URL != "" {
+		n, err := NewWebhook(cfg.Webhook.URL, cfg.Webhook.Secret, cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	if len(notifiers) == 0 {
+		return nil, fmt.Errorf("alerts are enabled, but no alertmanager, slack, or webhook adapter is configured")
+	}
+	return notifiers, nil
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/integrity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a Notifier that records the alerts it is sent
+type recorder struct {
+	alerts []Alert
+	err    error
+}
+
+func (r *recorder) Notify(ctx context.Context, alert Alert) error {
+	r.alerts = append(r.alerts, alert)
+	return r.err
+}
+
+func TestMonitor_Evaluate(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	open := map[string]time.Time{}
+
+	notifier := &recorder{}
+	m := NewMonitor([]Notifier{notifier}, time.Hour, 0, nil)
+	m.now = func() time.Time { return now }
+	m.AddCondition(CircuitOpen(5*time.Minute, func() map[string]time.Time { return open }))
+
+	// A circuit that has not been open for long enough does not fire
+	open["postgres"] = start
+	now = start.Add(time.Minute)
+	require.NoError(t, m.Evaluate(context.Background()))
+	assert.Empty(t, notifier.alerts)
+
+	// It fires once it has, and is not sent again before the repeat interval
+	now = start.Add(5 * time.Minute)
+	require.NoError(t, m.Evaluate(context.Background()))
+	require.Len(t, notifier.alerts, 1)
+	assert.Equal(t, Alert{
+		Name:     AlertCircuitOpen,
+		Severity: SeverityCritical,
+		Summary:  "circuit breaker postgres has been open for 5m0s",
+		Labels:   map[string]string{"circuit": "postgres"},
+		StartsAt: start,
+	}, notifier.alerts[0])
+
+	now = start.Add(30 * time.Minute)
+	require.NoError(t, m.Evaluate(context.Background()))
+	assert.Len(t, notifier.alerts, 1)
+
+	now = start.Add(65 * time.Minute)
+	require.NoError(t, m.Evaluate(context.Background()))
+	assert.Len(t, notifier.alerts, 2)
+
+	// It is sent once more when it resolves
+	delete(open, "postgres")
+	now = start.Add(70 * time.Minute)
+	require.NoError(t, m.Evaluate(context.Background()))
+	require.Len(t, notifier.alerts, 3)
+	assert.True(t, notifier.alerts[2].Resolved)
+	assert.Equal(t, now, notifier.alerts[2].EndsAt)
+
+	require.NoError(t, m.Evaluate(context.Background()))
+	assert.Len(t, notifier.alerts, 3)
+}
+
+func TestMonitor_RateLimit(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var firing []Alert
+
+	notifier := &recorder{}
+	m := NewMonitor([]Notifier{notifier}, 0, 2, nil)
+	m.now = func() time.Time { return now }
+	m.AddCondition(ConditionFunc(func(ctx context.Context, now time.Time) []Alert { return firing }))
+
+	for _, name := range []string{"a", "b", "c"} {
+		firing = append(firing, Alert{Name: AlertCircuitOpen, Labels: map[string]string{"circuit": name}, StartsAt: now})
+	}
+	require.NoError(t, m.Evaluate(context.Background()))
+	assert.Len(t, notifier.alerts, 2)
+
+	// The alert dropped by the rate limit is sent once the window has passed, and the
+	// others are not sent again
+	now = now.Add(time.Minute)
+	require.NoError(t, m.Evaluate(context.Background()))
+	require.Len(t, notifier.alerts, 3)
+	assert.Equal(t, "c", notifier.alerts[2].Labels["circuit"])
+}
+
+func TestMonitor_FailedNotification(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	failing := &recorder{err: errors.New("receiver unavailable")}
+	working := &recorder{}
+	m := NewMonitor([]Notifier{failing, working}, time.Hour, 0, nil)
+	m.now = func() time.Time { return now }
+	m.AddCondition(ConditionFunc(func(ctx context.Context, now time.Time) []Alert {
+		return []Alert{{Name: AlertIntegrityFailures, StartsAt: now}}
+	}))
+
+	// Every notifier is sent the alert, and it is sent again until every notifier succeeds
+	assert.ErrorContains(t, m.Evaluate(context.Background()), "receiver unavailable")
+	assert.Len(t, working.alerts, 1)
+	failing.err = nil
+	require.NoError(t, m.Evaluate(context.Background()))
+	assert.Len(t, working.alerts, 2)
+	require.NoError(t, m.Evaluate(context.Background()))
+	assert.Len(t, working.alerts, 2)
+}
+
+// scanner is an integrity.Scanner of fixed records
+type scanner struct {
+	records []integrity.Record
+	err     error
+}
+
+func (s *scanner) ScanFamilies(ctx context.Context, fn func(integrity.Record) error) error {
+	for _, rec := range s.records {
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func (s *scanner) Quarantine(ctx context.Context, familyID, reason string) error {
+	return errors.New("families are not quarantined by alert checks")
+}
+
+func TestIntegrityCheck(t *testing.T) {
+	now := time.Now()
+	s := &scanner{}
+	check := NewIntegrityCheck(integrity.NewChecker(s, integrity.Options{}, nil))
+
+	// Nothing fires before the first check, or while the families are valid
+	assert.Empty(t, check.Check(context.Background(), now))
+	require.NoError(t, check.Run(context.Background()))
+	assert.Empty(t, check.Check(context.Background(), now))
+
+	s.records = []integrity.Record{{FamilyID: "f1", DecodeErr: errors.New("invalid JSON")}}
+	require.NoError(t, check.Run(context.Background()))
+	alerts := check.Check(context.Background(), now)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, AlertIntegrityFailures, alerts[0].Name)
+	assert.Equal(t, "integrity check found 1 corrupt of 1 families (1 decode_failed)", alerts[0].Summary)
+
+	s.records, s.err = nil, errors.New("database unavailable")
+	assert.Error(t, check.Run(context.Background()))
+	alerts = check.Check(context.Background(), now)
+	require.Len(t, alerts, 1)
+	assert.Contains(t, alerts[0].Summary, "database unavailable")
+}
+
+func TestNewNotifiers(t *testing.T) {
+	_, err := NewNotifiers(config.AlertsConfig{Enabled: true})
+	assert.ErrorContains(t, err, "no alertmanager, slack, or webhook adapter is configured")
+
+	_, err = NewNotifiers(config.AlertsConfig{Slack: config.SlackAlertsConfig{WebhookURL: "hooks.slack.com/services/T000"}})
+	assert.ErrorContains(t, err, "invalid slack URL")
+
+	notifiers, err := NewNotifiers(config.AlertsConfig{
+		Alertmanager: config.AlertmanagerConfig{URL: "http://alertmanager:9093"},
+		Webhook:      config.AlertsWebhookConfig{URL: "https://ops.example.com/alerts"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, notifiers, 2)
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/integrity"
+)
+
+// Names of the alerts of the conditions of the service
+const (
+	AlertCircuitOpen       = "CircuitOpen"
+	AlertIntegrityFailures = "IntegrityCheckFailed"
+)
+
+// CircuitOpen returns a condition that fires for each circuit breaker that has not closed
+// for at least the given duration, with the name of the circuit as its "circuit" label
+//
+// Parameters:
+//   - after: How long a circuit must stay open before the alert fires
+//   - open: The circuits that are not closed, with the times since which they have not
+//     closed, such as circuit.OpenCircuits
+func CircuitOpen(after time.Duration, open func() map[string]time.Time) Condition {
+	return ConditionFunc(func(ctx context.Context, now time.Time) []Alert {
+		var alerts []Alert
+		for name, since := range open() {
+			if now.Sub(since) < after {
+				continue
+			}
+			alerts = append(alerts, Alert{
+				Name:     AlertCircuitOpen,
+				Severity: SeverityCritical,
+				Summary:  fmt.Sprintf("circuit breaker %s has been open for %s", name, now.Sub(since).Truncate(time.Second)),
+				Labels:   map[string]string{"circuit": name},
+				StartsAt: since,
+			})
+		}
+		return alerts
+	})
+}
+
+// IntegrityCheck runs integrity checks of the stored families on a schedule, and is a
+// condition that fires while the last check found issues or failed. Corrupt families are
+// only reported, never quarantined, since an operator should review them first.
+type IntegrityCheck struct {
+	checker *integrity.Checker
+
+	mu     sync.Mutex
+	report *integrity.Report
+	err    error
+}
+
+// NewIntegrityCheck creates a check of the families of a repository
+func NewIntegrityCheck(checker *integrity.Checker) *IntegrityCheck {
+	return &IntegrityCheck{checker: checker}
+}
+
+// Run runs an integrity check and keeps its result; it is the job of a periodic worker
+func (c *IntegrityCheck) Run(ctx context.Context) error {
+	report, err := c.checker.Run(ctx)
+	if ctx.Err() != nil {
+		// A check cut short by shutdown says nothing about the data
+		return err
+	}
+	c.mu.Lock()
+	c.report, c.err = report, err
+	c.mu.Unlock()
+	return err
+}
+
+// Check fires while the last integrity check found issues or failed
+func (c *IntegrityCheck) Check(ctx context.Context, now time.Time) []Alert {
+	c.mu.Lock()
+	report, err := c.report, c.err
+	c.mu.Unlock()
+
+	switch {
+	case err != nil:
+		return []Alert{{
+			Name:     AlertIntegrityFailures,
+			Severity: SeverityCritical,
+			Summary:  fmt.Sprintf("integrity check failed: %v", err),
+			StartsAt: reportTime(report, now),
+		}}
+	case report != nil && len(report.Issues) > 0:
+		kinds := make([]string, 0, len(report.IssuesByKind))
+		for kind, count := range report.IssuesByKind {
+			kinds = append(kinds, fmt.Sprintf("%d %s", count, kind))
+		}
+		sort.Strings(kinds)
+		return []Alert{{
+			Name:     AlertIntegrityFailures,
+			Severity: SeverityCritical,
+			Summary:  fmt.Sprintf("integrity check found %d corrupt of %d families (%s)", report.Corrupt, report.Scanned, strings.Join(kinds, ", ")),
+			StartsAt: report.FinishedAt,
+		}}
+	default:
+		return nil
+	}
+}
+
+// reportTime returns the time at which a check finished, or now if there is no report
+func reportTime(report *integrity.Report, now time.Time) time.Time {
+	if report == nil || report.FinishedAt.IsZero() {
+		return now
+	}
+	return report.FinishedAt
+}
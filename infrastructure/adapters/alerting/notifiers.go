@@ -0,0 +1,187 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the header of the HMAC-SHA256 signature of the body of a webhook notification
+const SignatureHeader = "X-Signature-256"
+
+// defaultTimeout is the timeout of a notification if none is configured
+const defaultTimeout = 5 * time.Second
+
+// parseURL checks that a notification URL is an absolute http or https URL
+func parseURL(adapter, rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid %s URL: must be an absolute http or https URL", adapter)
+	}
+	return u, nil
+}
+
+// newClient returns an HTTP client for notifications with a timeout
+func newClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// post posts a JSON body and fails unless the receiver answers with a 2xx status
+func post(ctx context.Context, client *http.Client, adapter, url string, body []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", adapter, err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", adapter, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s answered with status %d", adapter, resp.StatusCode)
+	}
+	return nil
+}
+
+// Alertmanager is a Notifier that posts alerts to the v2 API of a Prometheus Alertmanager,
+// which groups, silences, and routes them to receivers
+type Alertmanager struct {
+	url     string
+	service string
+	client  *http.Client
+}
+
+// NewAlertmanager creates a notifier for the Alertmanager at a base URL, such as
+// "http://alertmanager:9093"; alerts are labeled with the name of the service
+func NewAlertmanager(baseURL, service string, timeout time.Duration) (*Alertmanager, error) {
+	u, err := parseURL("alertmanager", baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Alertmanager{
+		url:     strings.TrimSuffix(u.String(), "/") + "/api/v2/alerts",
+		service: service,
+		client:  newClient(timeout),
+	}, nil
+}
+
+// Notify posts an alert; a resolved alert is posted with its end time
+func (a *Alertmanager) Notify(ctx context.Context, alert Alert) error {
+	labels := map[string]string{
+		"alertname": alert.Name,
+		"severity":  string(alert.Severity),
+		"service":   a.service,
+	}
+	for name, value := range alert.Labels {
+		labels[name] = value
+	}
+	postable := map[string]interface{}{
+		"labels":      labels,
+		"annotations": map[string]string{"summary": alert.Summary},
+		"startsAt":    alert.StartsAt.UTC().Format(time.RFC3339),
+	}
+	if alert.Resolved {
+		postable["endsAt"] = alert.EndsAt.UTC().Format(time.RFC3339)
+	}
+
+	body, err := json.Marshal([]interface{}{postable})
+	if err != nil {
+		return fmt.Errorf("failed to encode alertmanager alert: %w", err)
+	}
+	return post(ctx, a.client, "alertmanager", a.url, body, nil)
+}
+
+// Slack is a Notifier that posts alerts to a Slack incoming webhook
+type Slack struct {
+	url     string
+	service string
+	client  *http.Client
+}
+
+// NewSlack creates a notifier for a Slack incoming webhook URL, which is a secret
+func NewSlack(webhookURL, service string, timeout time.Duration) (*Slack, error) {
+	u, err := parseURL("slack", webhookURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Slack{url: u.String(), service: service, client: newClient(timeout)}, nil
+}
+
+// Notify posts an alert as a message
+func (s *Slack) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(map[string]string{"text": SlackText(s.service, alert)})
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+	return post(ctx, s.client, "slack", s.url, body, nil)
+}
+
+// SlackText formats an alert as the text of a Slack message, such as
+// ":rotating_light: [CRITICAL] family-service CircuitOpen: circuit postgres has been open for 5m0s"
+func SlackText(service string, alert Alert) string {
+	status, icon := strings.ToUpper(string(alert.Severity)), ":rotating_light:"
+	if alert.Resolved {
+		status, icon = "RESOLVED", ":white_check_mark:"
+	}
+	return fmt.Sprintf("%s [%s] %s %s: %s", icon, status, service, alert.Name, alert.Summary)
+}
+
+// Webhook is a Notifier that posts alerts as JSON to an HTTP endpoint.
+//
+// The request body is a JSON Alert. If a secret is configured, the body is signed with
+// HMAC-SHA256 and the signature is sent as "sha256=<hex>" in the X-Signature-256 header.
+type Webhook struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhook creates a notifier for a webhook URL, whose notifications are signed with a
+// secret if it is not empty
+func NewWebhook(webhookURL, secret string, timeout time.Duration) (*Webhook, error) {
+	u, err := parseURL("webhook", webhookURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Webhook{url: u.String(), secret: []byte(secret), client: newClient(timeout)}, nil
+}
+
+// Notify posts an alert
+func (w *Webhook) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook alert: %w", err)
+	}
+	header := http.Header{}
+	if len(w.secret) > 0 {
+		header.Set(SignatureHeader, Sign(w.secret, body))
+	}
+	return post(ctx, w.client, "webhook", w.url, body, header)
+}
+
+// Sign returns the signature of a notification body, as sent in the X-Signature-256 header
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records the requests posted to it
+type receiver struct {
+	*httptest.Server
+	path   string
+	header http.Header
+	body   []byte
+	status int
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.path, r.header = req.URL.Path, req.Header
+		r.body, _ = io.ReadAll(req.Body)
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+var testAlert = Alert{
+	Name:     AlertCircuitOpen,
+	Severity: SeverityCritical,
+	Summary:  "circuit breaker postgres has been open for 5m0s",
+	Labels:   map[string]string{"circuit": "postgres"},
+	StartsAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+}
+
+func TestAlertmanager_Notify(t *testing.T) {
+	r := newReceiver(t)
+	n, err := NewAlertmanager(r.URL+"/", "family-service", time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, n.Notify(context.Background(), testAlert))
+	assert.Equal(t, "/api/v2/alerts", r.path)
+	assert.JSONEq(t, `[{
+		"labels": {"alertname": "CircuitOpen", "severity": "critical", "service": "family-service", "circuit": "postgres"},
+		"annotations": {"summary": "circuit breaker postgres has been open for 5m0s"},
+		"startsAt": "2025-06-01T12:00:00Z"
+	}]`, string(r.body))
+
+	resolved := testAlert
+	resolved.Resolved, resolved.EndsAt = true, testAlert.StartsAt.Add(time.Hour)
+	require.NoError(t, n.Notify(context.Background(), resolved))
+	var posted []map[string]interface{}
+	require.NoError(t, json.Unmarshal(r.body, &posted))
+	assert.Equal(t, "2025-06-01T13:00:00Z", posted[0]["endsAt"])
+
+	r.status = http.StatusBadRequest
+	assert.ErrorContains(t, n.Notify(context.Background(), testAlert), "alertmanager answered with status 400")
+}
+
+func TestSlack_Notify(t *testing.T) {
+	r := newReceiver(t)
+	n, err := NewSlack(r.URL, "family-service", time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, n.Notify(context.Background(), testAlert))
+	assert.JSONEq(t, `{"text": ":rotating_light: [CRITICAL] family-service CircuitOpen: circuit breaker postgres has been open for 5m0s"}`, string(r.body))
+
+	resolved := testAlert
+	resolved.Resolved = true
+	assert.Equal(t, ":white_check_mark: [RESOLVED] family-service CircuitOpen: circuit breaker postgres has been open for 5m0s", SlackText("family-service", resolved))
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	r := newReceiver(t)
+	n, err := NewWebhook(r.URL, "s3cret", time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, n.Notify(context.Background(), testAlert))
+	assert.JSONEq(t, `{
+		"name": "CircuitOpen",
+		"severity": "critical",
+		"summary": "circuit breaker postgres has been open for 5m0s",
+		"labels": {"circuit": "postgres"},
+		"startsAt": "2025-06-01T12:00:00Z"
+	}`, string(r.body))
+	assert.Equal(t, Sign([]byte("s3cret"), r.body), r.header.Get(SignatureHeader))
+
+	// Without a secret, notifications are not signed
+	unsigned, err := NewWebhook(r.URL, "", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, unsigned.Notify(context.Background(), testAlert))
+	assert.Empty(t, r.header.Get(SignatureHeader))
+
+	_, err = NewWebhook("ftp://ops.example.com", "", time.Second)
+	assert.ErrorContains(t, err, "invalid webhook URL")
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package assembly bounds the memory used to assemble the results of reads of many
+// families.
+//
+// Interactive reads, such as GetAll, collect their families with an Assembler, which
+// fails the read with a ResultTooLargeError once the estimated size of the families
+// exceeds the memory budget, so that a client cannot exhaust the memory of the service
+// with one query; the error advises paginating the query instead.
+//
+// Exports, such as reports, read every family with Export, which collects the families
+// in a Spool: the first families are held in memory up to the budget, and the rest are
+// spilled to a file on disk. The families are handed to the export after the read has
+// completed, so that a slow export does not hold a database cursor open.
+//
+// The size of a family is estimated from its members and their strings; it is not an
+// exact measure of the heap, but it grows with it.
+package assembly
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/integrity"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// rejectedTotal counts the interactive results that exceeded the memory budget
+	rejectedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "result_assembly_rejected_total",
+			Help: "Total number of interactive results that exceeded the memory budget, by operation",
+		},
+		[]string{"operation"},
+	)
+
+	// spilledTotal counts the families of exports that were spilled to disk
+	spilledTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "result_assembly_spilled_families_total",
+			Help: "Total number of families of exports spilled to disk because the memory budget was exceeded, by operation",
+		},
+		[]string{"operation"},
+	)
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(rejectedTotal, spilledTotal)
+}
+
+// Estimated sizes of the parts of a family, in bytes
+const (
+	familyOverhead     = 256 // The family, its slices and maps, and its place in a result
+	memberOverhead     = 256 // A parent or child, with its dates
+	stringOverhead     = 16  // A string header
+	mapEntryOverhead   = 48  // An entry of an external ID map
+	nameChangeOverhead = 96  // A name change, with its dates
+	consentOverhead    = 96  // A consent, with its dates
+)
+
+// budget returns the memory budget of a configuration, or 0 if memory is not bounded
+func budget(cfg config.MemoryBudgetConfig) int64 {
+	if !cfg.Enabled || cfg.MaxBytes <= 0 {
+		return 0
+	}
+	return cfg.MaxBytes
+}
+
+// EstimateSize returns the estimated size of a family in memory, in bytes
+func EstimateSize(fam *entity.Family) int64 {
+	size := int64(familyOverhead) + stringSize(fam.ID()) + mapSize(fam.ExternalIDs())
+	for _, p := range fam.Parents() {
+		size += memberSize(p.ID(), p.FirstName(), p.LastName(), p.PreferredName(), p.ExternalIDs(), p.NameHistory())
+	}
+	for _, c := range fam.Children() {
+		size += memberSize(c.ID(), c.FirstName(), c.LastName(), c.PreferredName(), c.ExternalIDs(), c.NameHistory())
+		for _, consent := range c.Consents() {
+			size += consentOverhead + stringSize(consent.GrantedBy)
+			for _, scope := range consent.Scopes {
+				size += stringSize(string(scope))
+			}
+		}
+	}
+	return size
+}
+
+// memberSize returns the estimated size of a parent or child
+func memberSize(id, firstName, lastName, preferredName string, externalIDs map[string]string, history []entity.NameChange) int64 {
+	size := int64(memberOverhead) + stringSize(id) + stringSize(firstName) + stringSize(lastName) +
+		stringSize(preferredName) + mapSize(externalIDs)
+	for _, change := range history {
+		size += nameChangeOverhead + stringSize(change.FirstName) + stringSize(change.LastName)
+	}
+	return size
+}
+
+// stringSize returns the estimated size of a string
+func stringSize(s string) int64 {
+	return int64(stringOverhead + len(s))
+}
+
+// mapSize returns the estimated size of a map of strings
+func mapSize(m map[string]string) int64 {
+	var size int64
+	for k, v := range m {
+		size += int64(mapEntryOverhead + len(k) + len(v))
+	}
+	return size
+}
+
+// Assembler collects the families of an interactive read within the memory budget.
+// An Assembler is not safe for concurrent use.
+type Assembler struct {
+	operation string
+	budget    int64
+	used      int64
+	families  []*entity.Family
+}
+
+// NewAssembler creates an assembler of the result of a read
+//
+// Parameters:
+//   - cfg: The memory budget
+//   - operation: The name of the read, used in the error and to label the metrics
+//
+// Returns:
+//   - A new assembler without families
+func NewAssembler(cfg config.MemoryBudgetConfig, operation string) *Assembler {
+	return &Assembler{operation: operation, budget: budget(cfg), families: make([]*entity.Family, 0)}
+}
+
+// Add adds a family to the result.
+//
+// Returns:
+//   - A ResultTooLargeError if the result exceeds the memory budget with the family; the
+//     read should then stop and return the error
+func (a *Assembler) Add(fam *entity.Family) error {
+	if a.budget > 0 {
+		a.used += EstimateSize(fam)
+		if a.used > a.budget {
+			rejectedTotal.WithLabelValues(a.operation).Inc()
+			return domainerrors.NewResultTooLargeError(fmt.Sprintf(
+				"the result of %s exceeds the memory budget of %d bytes after %d families; paginate or narrow the query",
+				a.operation, a.budget, len(a.families)), nil)
+		}
+	}
+	a.families = append(a.families, fam)
+	return nil
+}
+
+// AddAll adds families to the result, in order, stopping at the first that exceeds the
+// memory budget
+//
+// Returns:
+//   - A ResultTooLargeError if the result exceeds the memory budget
+func (a *Assembler) AddAll(families []*entity.Family) error {
+	for _, fam := range families {
+		if err := a.Add(fam); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Families returns the families added so far, in the order they were added
+func (a *Assembler) Families() []*entity.Family {
+	return a.families
+}
+
+// Export reads every family of a repository and calls fn with each, in ascending ID
+// order. The families are collected in a Spool, which spills them to disk beyond the
+// memory budget, and fn is called after the read has completed.
+//
+// Parameters:
+//   - ctx: Context for the read and the export
+//   - scanner: The repository
+//   - cfg: The memory budget
+//   - operation: The name of the export, used to label the metrics
+//   - fn: Called with each family; an error stops the export
+//
+// Returns:
+//   - An error if the read fails, if a stored family cannot be decoded, or if fn fails
+func Export(ctx context.Context, scanner integrity.Scanner, cfg config.MemoryBudgetConfig, operation string, fn func(*entity.Family) error) error {
+	spool := NewSpool(cfg, operation)
+	defer spool.Close()
+
+	err := scanner.ScanFamilies(ctx, func(rec integrity.Record) error {
+		if rec.DecodeErr != nil {
+			return fmt.Errorf("family %s cannot be decoded: %w", rec.FamilyID, rec.DecodeErr)
+		}
+		if rec.InvariantErr != nil {
+			return fmt.Errorf("family %s is invalid: %w", rec.FamilyID, rec.InvariantErr)
+		}
+		return spool.Add(rec.Family)
+	})
+	if err != nil {
+		return err
+	}
+
+	return spool.Each(ctx, fn)
+}
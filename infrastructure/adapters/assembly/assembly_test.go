@@ -0,0 +1,171 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package assembly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/integrity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScanner serves records from memory
+type fakeScanner struct {
+	records []integrity.Record
+}
+
+func (s *fakeScanner) ScanFamilies(ctx context.Context, fn func(integrity.Record) error) error {
+	for _, rec := range s.records {
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fakeScanner) Quarantine(ctx context.Context, familyID, reason string) error {
+	return nil
+}
+
+// families creates n single-parent families with a child each
+func families(t *testing.T, n int) []*entity.Family {
+	t.Helper()
+	var result []*entity.Family
+	for i := 0; i < n; i++ {
+		p, err := entity.NewParent(fmt.Sprintf("a%07d-0000-4000-8000-000000000000", i), "Pat", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		require.NoError(t, err)
+		c, err := entity.NewChild(fmt.Sprintf("c%07d-0000-4000-8000-000000000000", i), "Sam", "Doe", time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		require.NoError(t, err)
+		fam, err := entity.NewFamily(fmt.Sprintf("f%07d-0000-4000-8000-000000000000", i), entity.Single, []*entity.Parent{p}, []*entity.Child{c})
+		require.NoError(t, err)
+		result = append(result, fam)
+	}
+	return result
+}
+
+// ids returns the IDs of families
+func ids(families []*entity.Family) []string {
+	result := make([]string, 0, len(families))
+	for _, fam := range families {
+		result = append(result, fam.ID())
+	}
+	return result
+}
+
+func TestAssembler(t *testing.T) {
+	fams := families(t, 4)
+	size := EstimateSize(fams[0])
+	require.Positive(t, size)
+
+	// Families within the budget are collected in order
+	assembler := NewAssembler(config.MemoryBudgetConfig{Enabled: true, MaxBytes: 3 * size}, "GetAll")
+	require.NoError(t, assembler.AddAll(fams[:3]))
+	assert.Equal(t, ids(fams[:3]), ids(assembler.Families()))
+
+	// The family that exceeds the budget is rejected with a typed error
+	err := assembler.Add(fams[3])
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, domainerrors.ErrResultTooLarge))
+	assert.Contains(t, err.Error(), "paginate")
+	assert.Len(t, assembler.Families(), 3)
+
+	// A disabled budget does not limit the result
+	unbounded := NewAssembler(config.MemoryBudgetConfig{Enabled: false, MaxBytes: 1}, "GetAll")
+	require.NoError(t, unbounded.AddAll(fams))
+	assert.Len(t, unbounded.Families(), 4)
+
+	// An empty result is not nil
+	assert.NotNil(t, NewAssembler(config.MemoryBudgetConfig{}, "GetAll").Families())
+}
+
+func TestSpool(t *testing.T) {
+	fams := families(t, 5)
+	dir := t.TempDir()
+
+	spool := NewSpool(config.MemoryBudgetConfig{Enabled: true, MaxBytes: 2 * EstimateSize(fams[0]), SpillDir: dir}, "Export")
+	for _, fam := range fams {
+		require.NoError(t, spool.Add(fam))
+	}
+	assert.Equal(t, 5, spool.Len())
+	assert.Equal(t, 3, spool.Spilled())
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// Spilled families are read back in order, after the families in memory
+	var got []*entity.Family
+	require.NoError(t, spool.Each(context.Background(), func(fam *entity.Family) error {
+		got = append(got, fam)
+		return nil
+	}))
+	assert.Equal(t, ids(fams), ids(got))
+	assert.Equal(t, fams[4].ToDTO(), got[4].ToDTO())
+
+	// An error from fn stops the iteration
+	failure := errors.New("write failed")
+	calls := 0
+	err = spool.Each(context.Background(), func(fam *entity.Family) error {
+		calls++
+		return failure
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 1, calls)
+
+	// Close removes the spill file
+	require.NoError(t, spool.Close())
+	files, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestSpool_WithinBudget(t *testing.T) {
+	dir := t.TempDir()
+	spool := NewSpool(config.MemoryBudgetConfig{Enabled: true, MaxBytes: 1 << 20, SpillDir: dir}, "Export")
+	for _, fam := range families(t, 3) {
+		require.NoError(t, spool.Add(fam))
+	}
+	assert.Equal(t, 0, spool.Spilled())
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	require.NoError(t, spool.Close())
+}
+
+func TestExport(t *testing.T) {
+	fams := families(t, 4)
+	cfg := config.MemoryBudgetConfig{Enabled: true, MaxBytes: EstimateSize(fams[0]), SpillDir: t.TempDir()}
+
+	scanner := &fakeScanner{}
+	for _, fam := range fams {
+		scanner.records = append(scanner.records, integrity.Record{FamilyID: fam.ID(), Family: fam})
+	}
+
+	var got []*entity.Family
+	require.NoError(t, Export(context.Background(), scanner, cfg, "ExportAll", func(fam *entity.Family) error {
+		got = append(got, fam)
+		return nil
+	}))
+	assert.Equal(t, ids(fams), ids(got))
+
+	// A family that cannot be decoded fails the export before fn is called
+	scanner.records = append(scanner.records, integrity.Record{FamilyID: "broken", DecodeErr: errors.New("invalid JSON")})
+	calls := 0
+	err := Export(context.Background(), scanner, cfg, "ExportAll", func(fam *entity.Family) error {
+		calls++
+		return nil
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+	assert.Equal(t, 0, calls)
+}
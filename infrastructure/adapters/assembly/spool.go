@@ -0,0 +1,149 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package assembly
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+)
+
+// Spool collects the families of an export. Families are held in memory up to the memory
+// budget and spilled to a temporary file after that, one JSON-encoded family per line.
+// The file is readable only by the service and is removed by Close.
+// A Spool is not safe for concurrent use.
+type Spool struct {
+	operation string
+	budget    int64
+	dir       string
+	used      int64
+	families  []*entity.Family
+
+	file    *os.File
+	writer  *bufio.Writer
+	spilled int
+}
+
+// NewSpool creates a spool for the families of an export
+//
+// Parameters:
+//   - cfg: The memory budget and the directory of the spill file
+//   - operation: The name of the export, used to label the metrics
+//
+// Returns:
+//   - A new spool without families
+func NewSpool(cfg config.MemoryBudgetConfig, operation string) *Spool {
+	return &Spool{operation: operation, budget: budget(cfg), dir: cfg.SpillDir}
+}
+
+// Add adds a family to the spool, spilling it to disk if the families in memory would
+// exceed the memory budget
+//
+// Returns:
+//   - An error if the family cannot be written to the spill file
+func (s *Spool) Add(fam *entity.Family) error {
+	if s.file == nil {
+		size := EstimateSize(fam)
+		if s.budget == 0 || s.used+size <= s.budget {
+			s.used += size
+			s.families = append(s.families, fam)
+			return nil
+		}
+
+		// Spill this and every later family, so that the families stay in order
+		file, err := os.CreateTemp(s.dir, "family-export-*.jsonl")
+		if err != nil {
+			return fmt.Errorf("failed to create spill file: %w", err)
+		}
+		s.file = file
+		s.writer = bufio.NewWriter(file)
+	}
+
+	line, err := json.Marshal(fam.ToDTO())
+	if err != nil {
+		return fmt.Errorf("failed to encode family %s for the spill file: %w", fam.ID(), err)
+	}
+	if _, err := s.writer.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+	s.spilled++
+	spilledTotal.WithLabelValues(s.operation).Inc()
+	return nil
+}
+
+// Len returns the number of families in the spool
+func (s *Spool) Len() int {
+	return len(s.families) + s.spilled
+}
+
+// Spilled returns the number of families spilled to disk
+func (s *Spool) Spilled() int {
+	return s.spilled
+}
+
+// Each calls fn with each family of the spool, in the order they were added. Spilled
+// families are read back one at a time.
+//
+// Returns:
+//   - An error if the context is done, if the spill file cannot be read, or if fn fails
+func (s *Spool) Each(ctx context.Context, fn func(*entity.Family) error) error {
+	for _, fam := range s.families {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(fam); err != nil {
+			return err
+		}
+	}
+	if s.file == nil {
+		return nil
+	}
+
+	if err := s.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read spill file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(s.file))
+	for i := 0; i < s.spilled; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var dto entity.FamilyDTO
+		if err := decoder.Decode(&dto); err != nil {
+			return fmt.Errorf("failed to read spill file: %w", err)
+		}
+		fam, err := entity.FamilyFromDTO(dto)
+		if err != nil {
+			return fmt.Errorf("failed to decode family %s from the spill file: %w", dto.ID, err)
+		}
+		if err := fn(fam); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases the families in memory and removes the spill file
+func (s *Spool) Close() error {
+	s.families = nil
+	if s.file == nil {
+		return nil
+	}
+
+	name := s.file.Name()
+	closeErr := s.file.Close()
+	s.file = nil
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("failed to remove spill file: %w", err)
+	}
+	return closeErr
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package audit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Archiver copies expired segments of the audit journal to long-term storage
+type Archiver interface {
+	// Archive copies the segment at path to the archive under the given name. The segment
+	// is deleted from the journal only after Archive returns nil.
+	Archive(ctx context.Context, name, path string) error
+}
+
+// NewArchiver creates an archiver for an archive URL. Segments are compressed with gzip,
+// and stored as objects named after the segment with a .gz suffix.
+//
+//   - file:///var/archive/audit copies segments to a directory, such as an object storage
+//     bucket mounted with a FUSE driver
+//   - https://bucket.example.com/audit uploads segments with a PUT request per object to an
+//     object storage endpoint, such as a pre-authorized bucket URL; the token, if any, is
+//     sent as a bearer token
+//
+// Parameters:
+//   - rawURL: The URL of the archive
+//   - token: The bearer token of HTTP archives (empty for none)
+//   - timeout: The timeout of an upload (0 for 30 seconds)
+//
+// Returns:
+//   - A new archiver
+//   - An error if the URL is not a file or HTTP URL
+func NewArchiver(rawURL, token string, timeout time.Duration) (Archiver, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit archive URL: %w", err)
+	}
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" {
+			return nil, fmt.Errorf("audit archive URL %q has no path", rawURL)
+		}
+		return dirArchiver{dir: u.Path}, nil
+	case "http", "https":
+		if timeout <= 0 {
+			timeout = 30 * time.Second
+		}
+		return &httpArchiver{
+			base:   strings.TrimSuffix(u.String(), "/"),
+			token:  token,
+			client: &http.Client{Timeout: timeout},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported audit archive URL scheme %q", u.Scheme)
+	}
+}
+
+// compressSegment reads a segment and compresses it with gzip
+func compressSegment(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(zw, file); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// dirArchiver archives segments in a directory
+type dirArchiver struct {
+	dir string
+}
+
+// Archive writes the compressed segment to a temporary file of the directory and renames it
+func (a dirArchiver) Archive(_ context.Context, name, path string) error {
+	data, err := compressSegment(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(a.dir, 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(a.dir, "."+name+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(a.dir, name+".gz"))
+}
+
+// httpArchiver archives segments with PUT requests to an object storage endpoint
+type httpArchiver struct {
+	base   string
+	token  string
+	client *http.Client
+}
+
+// Archive uploads the compressed segment
+func (a *httpArchiver) Archive(ctx context.Context, name, path string) error {
+	data, err := compressSegment(path)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, a.base+"/"+url.PathEscape(name+".gz"), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("archive responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package audit records domain events as audit entries.
+//
+// Audit entries are written to a dedicated "audit" logger, with the envelope of the event
+// (its type, version, the time it occurred, the producer, and the payload) and the
+// authenticated user who caused it, so that they can be routed to an audit store by the
+// log pipeline and replayed with the event schema registry. Operational actions, such as
+// those of the admin API, are written to the same logger as action entries.
+//
+// When a journal is set, entries are also appended to its segment files, which are kept,
+// archived, and pruned by a retention policy that exempts families under legal hold.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/eventschema"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Log publishes domain events as audit entries in the log
+type Log struct {
+	logger   *zap.Logger
+	registry *eventschema.Registry
+	journal  *Journal // Journal the entries are also appended to (nil for none)
+}
+
+var _ ports.EventPublisher = (*Log)(nil)
+
+// NewLog creates a new audit log
+//
+// Parameters:
+//   - logger: The logger that the audit logger is derived from
+//   - registry: The registry of the event types, which seals events in envelopes
+//
+// Returns:
+//   - A new audit log
+func NewLog(logger *zap.Logger, registry *eventschema.Registry) *Log {
+	return &Log{logger: logger.Named("audit"), registry: registry}
+}
+
+// SetJournal makes the log append its entries to a journal as well
+func (l *Log) SetJournal(journal *Journal) {
+	l.journal = journal
+}
+
+// Publish writes an audit entry for the event
+func (l *Log) Publish(ctx context.Context, event events.Event) error {
+	actor := actorFrom(ctx)
+
+	env, err := l.registry.Seal(event)
+	if err != nil {
+		return err
+	}
+
+	l.logger.Info("Audit entry",
+		zap.String("event", env.Type),
+		zap.Int("version", env.Version),
+		zap.Time("occurred_at", env.OccurredAt),
+		zap.String("producer", env.Producer),
+		zap.String("actor", actor),
+		zap.Reflect("payload", env.Payload))
+
+	return l.append(Entry{
+		Time:     env.OccurredAt,
+		Kind:     KindEvent,
+		Name:     env.Type,
+		Version:  env.Version,
+		Actor:    actor,
+		Families: familiesOf(event),
+		Payload:  env.Payload,
+	})
+}
+
+// Action writes an audit entry for an operational action taken by the authenticated user,
+// such as flushing the cache, with its outcome and details
+//
+// Parameters:
+//   - ctx: The context of the request that took the action
+//   - action: The name of the action
+//   - outcome: The outcome of the action, such as "succeeded", "failed", or "denied"
+//   - details: Additional fields describing the action
+func (l *Log) Action(ctx context.Context, action, outcome string, details ...zap.Field) {
+	fields := append([]zap.Field{
+		zap.String("action", action),
+		zap.String("outcome", outcome),
+		zap.String("actor", actorFrom(ctx)),
+	}, details...)
+	l.logger.Info("Audit action", fields...)
+
+	// The details of an action are about a family if they have a family_id field
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range details {
+		field.AddTo(enc)
+	}
+	var families []string
+	if id, ok := enc.Fields["family_id"].(string); ok && id != "" {
+		families = []string{id}
+	}
+	entry := Entry{Time: time.Now().UTC(), Kind: KindAction, Name: action, Actor: actorFrom(ctx), Outcome: outcome, Families: families}
+	if len(enc.Fields) > 0 {
+		entry.Payload = enc.Fields
+	}
+	_ = l.append(entry)
+}
+
+// append appends an entry to the journal, if any. Failures are logged, since the entry
+// has been written to the audit logger.
+func (l *Log) append(entry Entry) error {
+	if l.journal == nil {
+		return nil
+	}
+	if err := l.journal.Append(entry); err != nil {
+		l.logger.Error("Failed to append audit entry to the journal", zap.String("name", entry.Name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// actorFrom returns the ID of the authenticated user of a context, or "anonymous"
+func actorFrom(ctx context.Context) string {
+	actor, ok := middleware.GetUserID(ctx)
+	if !ok || actor == "" {
+		return "anonymous"
+	}
+	return actor
+}
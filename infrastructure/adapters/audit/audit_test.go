@@ -0,0 +1,60 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/eventschema"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLog_Publish(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	at := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	err := NewLog(zap.New(core), eventschema.NewDefaultRegistry("family-service/1.0.0")).Publish(context.Background(), events.ChildMoved{
+		ChildID:      "child-1",
+		FromFamilyID: "family-1",
+		ToFamilyID:   "family-2",
+		At:           at,
+	})
+	require.NoError(t, err)
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "audit", entries[0].LoggerName)
+	assert.Equal(t, map[string]interface{}{
+		"event":       "child_moved",
+		"version":     int64(1),
+		"occurred_at": at,
+		"producer":    "family-service/1.0.0",
+		"actor":       "anonymous",
+		"payload":     json.RawMessage(`{"childId":"child-1","fromFamilyId":"family-1","toFamilyId":"family-2"}`),
+	}, entries[0].ContextMap())
+}
+
+func TestLog_Action(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := middleware.WithUserID(context.Background(), "admin-1")
+
+	NewLog(zap.New(core), eventschema.NewDefaultRegistry("family-service/1.0.0")).Action(ctx, "cache.flush", "succeeded", zap.Int("replicas", 3))
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "audit", entries[0].LoggerName)
+	assert.Equal(t, map[string]interface{}{
+		"action":   "cache.flush",
+		"outcome":  "succeeded",
+		"actor":    "admin-1",
+		"replicas": int64(3),
+	}, entries[0].ContextMap())
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/events"
+)
+
+// SegmentPrefix is the prefix of the names of journal segments
+const SegmentPrefix = "audit-"
+
+// Suffixes of the names of journal segments. A segment whose entries were pruned except
+// those of families under legal hold is renamed with the held suffix.
+const (
+	segmentSuffix     = ".jsonl"
+	heldSegmentSuffix = ".held.jsonl"
+)
+
+// segmentTimeLayout is the layout of the creation time in the names of journal segments
+const segmentTimeLayout = "20060102T150405.000000000Z"
+
+// defaultSegmentBytes is the size at which a segment is closed when it is not configured
+const defaultSegmentBytes = 64 << 20
+
+// Kinds of journal entries
+const (
+	KindEvent  = "event"
+	KindAction = "action"
+)
+
+// Entry is an entry of the audit journal
+type Entry struct {
+	Time     time.Time   `json:"time"`
+	Kind     string      `json:"kind"`              // KindEvent or KindAction
+	Name     string      `json:"name"`              // Type of the event, or name of the action
+	Version  int         `json:"version,omitempty"` // Version of the payload of an event
+	Actor    string      `json:"actor"`
+	Outcome  string      `json:"outcome,omitempty"` // Outcome of an action
+	Families []string    `json:"families,omitempty"`
+	Payload  interface{} `json:"payload,omitempty"` // Payload of an event, or details of an action
+}
+
+// Journal appends audit entries as JSON lines to segment files in a directory, so that
+// they can be kept, archived, and pruned by the retention policy. A segment is closed when
+// it reaches the segment size or at the first entry of a new UTC day; closed segments are
+// never written again, except to prune them. A Journal is safe for concurrent use.
+type Journal struct {
+	dir          string
+	segmentBytes int64
+
+	mu      sync.Mutex
+	file    *os.File
+	name    string    // Name of the open segment
+	size    int64     // Size of the open segment
+	created time.Time // Creation time of the open segment
+}
+
+// NewJournal creates a journal that writes segments to a directory
+//
+// Parameters:
+//   - dir: Directory of the segments, created if it does not exist
+//   - segmentBytes: Size at which a segment is closed (0 for 64 MiB)
+//
+// Returns:
+//   - A new journal
+//   - An error if the directory cannot be created
+func NewJournal(dir string, segmentBytes int64) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit journal directory: %w", err)
+	}
+	if segmentBytes <= 0 {
+		segmentBytes = defaultSegmentBytes
+	}
+	return &Journal{dir: dir, segmentBytes: segmentBytes}, nil
+}
+
+// Append writes an entry to the open segment, opening a new segment first if needed
+func (j *Journal) Append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().UTC()
+	if j.file == nil || (j.size > 0 && j.size+int64(len(line)) > j.segmentBytes) || !sameDay(j.created, now) {
+		if err := j.rotate(now); err != nil {
+			return err
+		}
+	}
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// rotate closes the open segment and opens a new one
+func (j *Journal) rotate(now time.Time) error {
+	if err := j.closeSegment(); err != nil {
+		return err
+	}
+	name := SegmentPrefix + now.Format(segmentTimeLayout) + segmentSuffix
+	file, err := os.OpenFile(filepath.Join(j.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open audit segment: %w", err)
+	}
+	j.file, j.name, j.size, j.created = file, name, 0, now
+	return nil
+}
+
+// closeSegment closes the open segment, if any
+func (j *Journal) closeSegment() error {
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file, j.name = nil, ""
+	if err != nil {
+		return fmt.Errorf("failed to close audit segment: %w", err)
+	}
+	return nil
+}
+
+// Close closes the open segment
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.closeSegment()
+}
+
+// segment is a closed segment of the journal
+type segment struct {
+	name     string
+	size     int64
+	modified time.Time // Time of the last entry of the segment
+	held     bool      // Whether the segment only has entries of families under legal hold
+}
+
+// closedSegments returns the closed segments of the journal, oldest first
+func (j *Journal) closedSegments() ([]segment, error) {
+	j.mu.Lock()
+	open := j.name
+	j.mu.Unlock()
+
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit journal directory: %w", err)
+	}
+	var segments []segment
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == open || !strings.HasPrefix(name, SegmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // Deleted since the directory was read
+		}
+		segments = append(segments, segment{
+			name:     name,
+			size:     info.Size(),
+			modified: info.ModTime(),
+			held:     strings.HasSuffix(name, heldSegmentSuffix),
+		})
+	}
+	// Names start with the creation time, so they sort oldest first
+	sort.Slice(segments, func(a, b int) bool { return segments[a].name < segments[b].name })
+	return segments, nil
+}
+
+// sameDay reports whether two times are on the same UTC day
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// familiesOf returns the IDs of the families an event is about
+func familiesOf(event events.Event) []string {
+	switch e := event.(type) {
+	case events.ChildMoved:
+		return []string{e.FromFamilyID, e.ToFamilyID}
+	case events.FamilyQuarantined:
+		return []string{e.FamilyID}
+	case events.FamilyUnquarantined:
+		return []string{e.FamilyID}
+	case events.QuarantinedFamilyAccessed:
+		return []string{e.FamilyID}
+	case events.FamilyDuplicateSuspected:
+		return append([]string{e.FamilyID}, e.DuplicateOf...)
+	case events.FamilyDeleted:
+		return []string{e.FamilyID}
+	case events.FamilyRestored:
+		return []string{e.FamilyID}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// archiveTimeLayout is the layout of the time of the pruning in the archive names of held
+// segments
+const archiveTimeLayout = "20060102T150405Z"
+
+// Outcomes of pruned segments
+const (
+	outcomeArchived = "archived" // Copied to the archive, then deleted
+	outcomeDeleted  = "deleted"  // Deleted without an archive
+)
+
+var (
+	// prunedBytes counts the bytes of audit entries removed from the journal
+	prunedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_retention_pruned_bytes_total",
+			Help: "Bytes of audit entries removed from the journal by the retention policy, by outcome (archived, deleted)",
+		},
+		[]string{"outcome"},
+	)
+
+	// prunedEntries counts the audit entries removed from the journal
+	prunedEntries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_retention_pruned_entries_total",
+			Help: "Audit entries removed from the journal by the retention policy, by outcome (archived, deleted)",
+		},
+		[]string{"outcome"},
+	)
+
+	// heldEntries is the number of expired entries kept for families under legal hold
+	heldEntries = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "audit_retention_held_entries",
+			Help: "Expired audit entries kept in the journal because their families are under legal hold, as of the last pruning",
+		},
+	)
+
+	// journalBytes is the size of the closed segments of the journal
+	journalBytes = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "audit_journal_size_bytes",
+			Help: "Size of the closed segments of the audit journal, as of the last pruning",
+		},
+	)
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(prunedBytes, prunedEntries, heldEntries, journalBytes)
+}
+
+// Retention is the retention policy of the audit journal
+type Retention struct {
+	MaxAge     time.Duration // Age of the last entry at which a segment expires (0 for no limit)
+	MaxBytes   int64         // Size of the closed segments beyond which the oldest expire (0 for no limit)
+	LegalHolds []string      // IDs of the families whose entries are never pruned
+	Archive    Archiver      // Archive of expired segments (nil to delete them)
+}
+
+// Prune removes the expired segments of the journal: segments whose last entry is older
+// than the maximum age, then the oldest segments until the journal fits in the maximum
+// size. An expired segment is archived first if there is an archive; a segment whose
+// archive fails is kept, and pruning stops so that it is retried. The entries of families
+// under legal hold are kept in a held segment instead of being pruned, and are archived
+// and deleted once their families are no longer held and they have expired.
+//
+// Parameters:
+//   - ctx: The context of the pruning
+//   - now: The time against which the age of segments is measured
+//
+// Returns:
+//   - The number of entries pruned
+//   - An error if a segment could not be archived or deleted
+func (j *Journal) Prune(ctx context.Context, policy Retention, now time.Time, logger *zap.Logger) (int, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	segments, err := j.closedSegments()
+	if err != nil {
+		return 0, err
+	}
+
+	holds := make(map[string]bool, len(policy.LegalHolds))
+	for _, id := range policy.LegalHolds {
+		holds[id] = true
+	}
+
+	// Held segments do not count against the maximum size, since they cannot be pruned
+	var total, heldBytes int64
+	for _, seg := range segments {
+		if seg.held {
+			heldBytes += seg.size
+		} else {
+			total += seg.size
+		}
+	}
+
+	pruned, held := 0, 0
+	defer func() {
+		heldEntries.Set(float64(held))
+		journalBytes.Set(float64(total + heldBytes))
+	}()
+	for _, seg := range segments {
+		if err := ctx.Err(); err != nil {
+			return pruned, err
+		}
+		expired := (policy.MaxAge > 0 && now.Sub(seg.modified) > policy.MaxAge) ||
+			(!seg.held && policy.MaxBytes > 0 && total > policy.MaxBytes)
+		if !expired {
+			// Segments are pruned oldest first; a held segment of expired entries that is
+			// still held does not stop the pruning of later segments
+			if seg.held {
+				continue
+			}
+			break
+		}
+
+		result, err := j.pruneSegment(ctx, seg, holds, policy.Archive, now)
+		if err != nil {
+			return pruned, err
+		}
+		if seg.held {
+			heldBytes -= result.removedBytes
+		} else {
+			total -= seg.size
+			heldBytes += seg.size - result.removedBytes
+		}
+		pruned += result.removed
+		held += result.kept
+		if result.removed > 0 {
+			logger.Info("Pruned audit segment",
+				zap.String("segment", seg.name),
+				zap.String("outcome", result.outcome),
+				zap.Int("pruned", result.removed),
+				zap.Int("held", result.kept))
+		}
+	}
+	return pruned, nil
+}
+
+// pruneResult is the result of the pruning of a segment
+type pruneResult struct {
+	outcome      string
+	removed      int   // Entries removed
+	kept         int   // Entries kept for families under legal hold
+	removedBytes int64 // Bytes by which the journal shrank
+}
+
+// pruneSegment archives and deletes an expired segment, keeping the entries of families
+// under legal hold in a held segment
+func (j *Journal) pruneSegment(ctx context.Context, seg segment, holds map[string]bool, archive Archiver, now time.Time) (pruneResult, error) {
+	path := filepath.Join(j.dir, seg.name)
+	lines, err := readLines(path)
+	if err != nil {
+		return pruneResult{}, err
+	}
+
+	var keep []string
+	for _, line := range lines {
+		if isHeld(line, holds) {
+			keep = append(keep, line)
+		}
+	}
+	result := pruneResult{outcome: outcomeDeleted, kept: len(keep)}
+	heldPath := filepath.Join(j.dir, strings.TrimSuffix(strings.TrimSuffix(seg.name, heldSegmentSuffix), segmentSuffix)+heldSegmentSuffix)
+	if len(keep) == len(lines) && len(lines) > 0 {
+		// Every entry is held: the segment becomes a held segment as it is
+		if !seg.held {
+			if err := os.Rename(path, heldPath); err != nil {
+				return pruneResult{}, fmt.Errorf("failed to hold audit segment %s: %w", seg.name, err)
+			}
+		}
+		return result, nil
+	}
+
+	if archive != nil {
+		// A held segment may be archived several times as its families are released, so
+		// each of its archives is named after the time of the pruning
+		name := seg.name
+		if seg.held {
+			name = strings.TrimSuffix(seg.name, heldSegmentSuffix) + ".held-" + now.UTC().Format(archiveTimeLayout) + segmentSuffix
+		}
+		if err := archive.Archive(ctx, name, path); err != nil {
+			return pruneResult{}, fmt.Errorf("failed to archive audit segment %s: %w", seg.name, err)
+		}
+		result.outcome = outcomeArchived
+	}
+
+	// Move the held entries to a held segment, which keeps the creation time of the
+	// segment in its name, so that it keeps its place in the order of pruning
+	var keptBytes int64
+	if len(keep) > 0 {
+		data := strings.Join(keep, "\n") + "\n"
+		if err := writeSegment(heldPath, data, seg.modified); err != nil {
+			return pruneResult{}, err
+		}
+		keptBytes = int64(len(data))
+	}
+	if len(keep) == 0 || heldPath != path {
+		if err := os.Remove(path); err != nil {
+			return pruneResult{}, fmt.Errorf("failed to delete audit segment %s: %w", seg.name, err)
+		}
+	}
+
+	result.removed = len(lines) - len(keep)
+	result.removedBytes = seg.size - keptBytes
+	prunedEntries.WithLabelValues(result.outcome).Add(float64(result.removed))
+	prunedBytes.WithLabelValues(result.outcome).Add(float64(result.removedBytes))
+	return result, nil
+}
+
+// isHeld reports whether an entry is about a family under legal hold. Entries that cannot
+// be decoded are not held.
+func isHeld(line string, holds map[string]bool) bool {
+	if len(holds) == 0 {
+		return false
+	}
+	var entry struct {
+		Families []string `json:"families"`
+	}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return false
+	}
+	for _, id := range entry.Families {
+		if holds[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// readLines reads the non-empty lines of a segment
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit segment: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit segment: %w", err)
+	}
+	return lines, nil
+}
+
+// writeSegment writes a segment to a temporary file and renames it to path, keeping the
+// time of its last entry
+func writeSegment(path, data string, modified time.Time) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".audit-*")
+	if err != nil {
+		return fmt.Errorf("failed to create held audit segment: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write held audit segment: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write held audit segment: %w", err)
+	}
+	if err := os.Chtimes(tmp.Name(), modified, modified); err != nil {
+		return fmt.Errorf("failed to write held audit segment: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write held audit segment: %w", err)
+	}
+	return nil
+}
+
+// PruneJob returns a job, for a periodic worker, that prunes the journal with a policy
+func (j *Journal) PruneJob(policy Retention, logger *zap.Logger) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := j.Prune(ctx, policy, time.Now(), logger)
+		return err
+	}
+}
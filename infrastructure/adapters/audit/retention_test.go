@@ -0,0 +1,173 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package audit
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/eventschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+// writeSegments writes one closed segment per family, each with a single event, and ages
+// the segments by a day each, the first being the oldest
+func writeSegments(t *testing.T, journal *Journal, families ...string) []string {
+	log := NewLog(zap.NewNop(), eventschema.NewDefaultRegistry("family-service/1.0.0"))
+	log.SetJournal(journal)
+	var names []string
+	for _, id := range families {
+		require.NoError(t, log.Publish(context.Background(), events.FamilyQuarantined{FamilyID: id, Reason: "corrupt", At: time.Now()}))
+		names = append(names, journal.name)
+		require.NoError(t, journal.Close())
+		time.Sleep(time.Millisecond) // Segments are named after their creation time
+	}
+	for i, name := range names {
+		modified := time.Now().AddDate(0, 0, i-len(names))
+		require.NoError(t, os.Chtimes(filepath.Join(journal.dir, name), modified, modified))
+	}
+	return names
+}
+
+// segmentNames returns the names of the segments of the journal
+func segmentNames(t *testing.T, journal *Journal) []string {
+	segments, err := journal.closedSegments()
+	require.NoError(t, err)
+	names := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		names = append(names, seg.name)
+	}
+	return names
+}
+
+func TestJournal_Append(t *testing.T) {
+	journal, err := NewJournal(filepath.Join(t.TempDir(), "audit"), 200)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, journal.Append(Entry{Time: time.Now(), Kind: KindAction, Name: "cache.flush", Actor: strings.Repeat("a", 100)}))
+	}
+	require.NoError(t, journal.Close())
+
+	// Each entry is larger than half a segment, so each has its own segment
+	names := segmentNames(t, journal)
+	require.Len(t, names, 3)
+	lines, err := readLines(filepath.Join(journal.dir, names[0]))
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"kind":"action","name":"cache.flush"`)
+}
+
+func TestJournal_PruneByAge(t *testing.T) {
+	journal, err := NewJournal(t.TempDir(), 0)
+	require.NoError(t, err)
+	names := writeSegments(t, journal, "family-1", "family-2", "family-3")
+
+	// The segments are 3, 2, and 1 days old
+	pruned, err := journal.Prune(context.Background(), Retention{MaxAge: 36 * time.Hour}, time.Now(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Equal(t, 2, pruned)
+	assert.Equal(t, names[2:], segmentNames(t, journal))
+}
+
+func TestJournal_PruneBySize(t *testing.T) {
+	journal, err := NewJournal(t.TempDir(), 0)
+	require.NoError(t, err)
+	names := writeSegments(t, journal, "family-1", "family-2", "family-3")
+	info, err := os.Stat(filepath.Join(journal.dir, names[0]))
+	require.NoError(t, err)
+
+	// The oldest segments are pruned until the journal fits
+	pruned, err := journal.Prune(context.Background(), Retention{MaxBytes: 2*info.Size() + 10}, time.Now(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+	assert.Equal(t, names[1:], segmentNames(t, journal))
+}
+
+func TestJournal_PruneLegalHold(t *testing.T) {
+	journal, err := NewJournal(t.TempDir(), 0)
+	require.NoError(t, err)
+	names := writeSegments(t, journal, "family-1", "family-2")
+	policy := Retention{MaxAge: time.Hour, LegalHolds: []string{"family-1"}}
+
+	// The segment of the held family is kept as a held segment
+	pruned, err := journal.Prune(context.Background(), policy, time.Now(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+	held := strings.TrimSuffix(names[0], segmentSuffix) + heldSegmentSuffix
+	assert.Equal(t, []string{held}, segmentNames(t, journal))
+
+	// Pruning again keeps it while the family is held
+	pruned, err = journal.Prune(context.Background(), policy, time.Now(), nil)
+	require.NoError(t, err)
+	assert.Zero(t, pruned)
+	assert.Equal(t, []string{held}, segmentNames(t, journal))
+
+	// Once the hold is lifted, the expired entries are pruned
+	archive := t.TempDir()
+	archiver, err := NewArchiver("file://"+archive, "", 0)
+	require.NoError(t, err)
+	pruned, err = journal.Prune(context.Background(), Retention{MaxAge: time.Hour, Archive: archiver}, time.Now(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+	assert.Empty(t, segmentNames(t, journal))
+	archived, err := filepath.Glob(filepath.Join(archive, "*.held-*.jsonl.gz"))
+	require.NoError(t, err)
+	assert.Len(t, archived, 1)
+}
+
+func TestJournal_PruneArchive(t *testing.T) {
+	var uploads []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		zr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"name":"family_quarantined"`)
+		uploads = append(uploads, r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	journal, err := NewJournal(t.TempDir(), 0)
+	require.NoError(t, err)
+	names := writeSegments(t, journal, "family-1", "family-2")
+	archiver, err := NewArchiver(server.URL+"/audit/", "secret", time.Second)
+	require.NoError(t, err)
+
+	// A segment whose upload fails is kept, and pruning stops
+	status = http.StatusServiceUnavailable
+	pruned, err := journal.Prune(context.Background(), Retention{MaxAge: time.Hour, Archive: archiver}, time.Now(), nil)
+	require.Error(t, err)
+	assert.Zero(t, pruned)
+	assert.Equal(t, names, segmentNames(t, journal))
+
+	status = http.StatusOK
+	uploads = nil
+	pruned, err = journal.Prune(context.Background(), Retention{MaxAge: time.Hour, Archive: archiver}, time.Now(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, pruned)
+	assert.Equal(t, []string{"/audit/" + names[0] + ".gz", "/audit/" + names[1] + ".gz"}, uploads)
+	assert.Empty(t, segmentNames(t, journal))
+}
+
+func TestNewArchiver(t *testing.T) {
+	_, err := NewArchiver("s3://bucket/audit", "", 0)
+	assert.Error(t, err)
+	_, err = NewArchiver("file://", "", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package auth rotates the keys that JSON Web Tokens are validated with.
+// This package is a wrapper around the servicelib auth package, whose services are
+// built with a fixed secret key: rotating the keys builds a service for the new key
+// and keeps the service of the previous key, so that tokens signed with the previous
+// key are accepted until the rotation grace period ends.
+// Tokens issued by a remote authorization server are validated with the signing keys of
+// its JSON Web Key Set instead, when the keys are set to use a remote validator.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/servicelib/auth"
+	autherrors "github.com/abitofhelp/servicelib/auth/errors"
+	"github.com/abitofhelp/servicelib/auth/jwt"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+)
+
+// ErrNoKeyFile is returned when keys are rotated without a secret key file to read the new key from
+var ErrNoKeyFile = errors.New("no secret key file is configured to rotate the keys from")
+
+// ErrKeyUnchanged is returned when keys are rotated while the secret key file holds the current key
+var ErrKeyUnchanged = errors.New("the secret key file holds the current key")
+
+// Builder builds the auth service that signs and validates tokens with a secret key
+type Builder func(ctx context.Context, secretKey string) (*auth.Auth, error)
+
+// Keys holds the auth services of the current secret key and of the previous key
+type Keys struct {
+	file  string
+	grace time.Duration
+	build Builder
+	now   func() time.Time
+
+	mu            sync.RWMutex
+	secretKey     string
+	current       *auth.Auth
+	previous      *auth.Auth
+	previousUntil time.Time
+	rotatedAt     time.Time
+
+	remote      *Remote
+	acceptLocal bool
+	skipPaths   []string
+}
+
+// NewKeys builds the auth service of the secret key, which is read from the secret key
+// file of the configuration if it is set
+//
+// Parameters:
+//   - ctx: The context of the initialization
+//   - cfg: The JWT configuration
+//   - build: The builder of the auth service of a secret key
+//
+// Returns:
+//   - The keys
+//   - An error if the secret key could not be read or the auth service could not be built
+func NewKeys(ctx context.Context, cfg config.JWTConfig, build Builder) (*Keys, error) {
+	k := &Keys{file: cfg.SecretKeyFile, grace: cfg.RotationGrace, build: build, now: time.Now}
+
+	secretKey := cfg.SecretKey
+	if k.file != "" {
+		var err error
+		if secretKey, err = k.readKey(); err != nil {
+			return nil, err
+		}
+	}
+	current, err := build(ctx, secretKey)
+	if err != nil {
+		return nil, err
+	}
+	k.secretKey, k.current = secretKey, current
+	return k, nil
+}
+
+// Current returns the auth service of the current secret key
+func (k *Keys) Current() *auth.Auth {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+// RotatedAt returns the time the keys were last rotated, or the zero time
+func (k *Keys) RotatedAt() time.Time {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.rotatedAt
+}
+
+// Rotate reads the new secret key from the secret key file and makes it the current key.
+// Tokens signed with the previous key are accepted for the rotation grace period.
+//
+// Returns:
+//   - ErrNoKeyFile if no secret key file is configured
+//   - ErrKeyUnchanged if the file holds the current key
+//   - Another error if the file could not be read or the auth service could not be built
+func (k *Keys) Rotate(ctx context.Context) error {
+	if k.file == "" {
+		return ErrNoKeyFile
+	}
+	secretKey, err := k.readKey()
+	if err != nil {
+		return err
+	}
+
+	k.mu.RLock()
+	unchanged := secretKey == k.secretKey
+	k.mu.RUnlock()
+	if unchanged {
+		return ErrKeyUnchanged
+	}
+
+	next, err := k.build(ctx, secretKey)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.now()
+	k.previous, k.previousUntil = k.current, now.Add(k.grace)
+	k.secretKey, k.current, k.rotatedAt = secretKey, next, now
+	return nil
+}
+
+// UseRemote makes the keys validate tokens with a remote authorization server, before the
+// keys are used. Tokens signed with the local secret key are only accepted with
+// acceptLocal. The middleware serves the paths with a prefix in skipPaths without
+// authentication, as the middleware of the local keys does.
+func (k *Keys) UseRemote(remote *Remote, acceptLocal bool, skipPaths []string) {
+	k.remote, k.acceptLocal, k.skipPaths = remote, acceptLocal, skipPaths
+}
+
+// ValidateToken validates a token with the remote authorization server, if the keys use
+// one, and otherwise with the current key, or with the previous key during the rotation
+// grace period. With a remote authorization server, tokens signed with the local keys are
+// only accepted if local tokens are.
+func (k *Keys) ValidateToken(ctx context.Context, tokenString string) (*jwt.Claims, error) {
+	if k.remote == nil {
+		return k.validateLocal(ctx, tokenString)
+	}
+	claims, err := k.remote.ValidateToken(ctx, tokenString)
+	if err != nil && k.acceptLocal {
+		if localClaims, localErr := k.validateLocal(ctx, tokenString); localErr == nil {
+			return localClaims, nil
+		}
+	}
+	return claims, err
+}
+
+// validateLocal validates a token with the current key, or with the previous key during
+// the rotation grace period
+func (k *Keys) validateLocal(ctx context.Context, tokenString string) (*jwt.Claims, error) {
+	current, previous := k.services()
+	claims, err := current.ValidateToken(ctx, tokenString)
+	if err != nil && previous != nil {
+		if previousClaims, previousErr := previous.ValidateToken(ctx, tokenString); previousErr == nil {
+			return previousClaims, nil
+		}
+	}
+	return claims, err
+}
+
+// Middleware returns the auth middleware of the current key. During the rotation grace
+// period, requests whose token is only valid with the previous key are authenticated by
+// the middleware of the previous key. With a remote authorization server, requests are
+// authenticated by the tokens the keys validate. The tenant claim of the token of an
+// authenticated request is recorded as the tenant of its context.
+func (k *Keys) Middleware() func(http.Handler) http.Handler {
+	if k.remote != nil {
+		return func(next http.Handler) http.Handler {
+			return k.remoteMiddleware(withTenant(next))
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		next = withTenant(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current, previous := k.services()
+			service := current
+			if previous != nil && k.onlyPrevious(r, current, previous) {
+				service = previous
+			}
+			service.Middleware()(next).ServeHTTP(w, r)
+		})
+	}
+}
+
+// remoteMiddleware authenticates requests by the bearer tokens the keys validate, and
+// answers like the servicelib middleware
+func (k *Keys) remoteMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, path := range k.skipPaths {
+			if strings.HasPrefix(r.URL.Path, path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			http.Error(w, "Authorization required", http.StatusUnauthorized)
+			return
+		}
+		token, err := jwt.ExtractTokenFromHeader(header)
+		if err != nil {
+			http.Error(w, "Invalid Authorization header format", http.StatusUnauthorized)
+			return
+		}
+		claims, err := k.ValidateToken(r.Context(), token)
+		if err != nil {
+			http.Error(w, authErrorMessage(err), http.StatusUnauthorized)
+			return
+		}
+
+		ctx := middleware.WithUserID(r.Context(), claims.UserID)
+		ctx = middleware.WithUserRoles(ctx, claims.Roles)
+		ctx = middleware.WithUserScopes(ctx, claims.Scopes)
+		ctx = middleware.WithUserResources(ctx, claims.Resources)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authErrorMessage returns the message the servicelib middleware answers an auth error with
+func authErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, autherrors.ErrExpiredToken):
+		return "Token expired"
+	case errors.Is(err, autherrors.ErrInvalidSignature):
+		return "Invalid token signature"
+	case errors.Is(err, autherrors.ErrInvalidClaims):
+		return "Invalid token claims"
+	default:
+		return "Invalid token"
+	}
+}
+
+// services returns the auth services of the current key and, during the rotation grace
+// period, of the previous key
+func (k *Keys) services() (current, previous *auth.Auth) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.previous != nil && k.now().Before(k.previousUntil) {
+		return k.current, k.previous
+	}
+	return k.current, nil
+}
+
+// onlyPrevious reports whether the bearer token of a request is valid with the previous
+// key but not with the current key
+func (k *Keys) onlyPrevious(r *http.Request, current, previous *auth.Auth) bool {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return false
+	}
+	token, err := jwt.ExtractTokenFromHeader(header)
+	if err != nil {
+		return false
+	}
+	if _, err := current.ValidateToken(r.Context(), token); err == nil {
+		return false
+	}
+	_, err = previous.ValidateToken(r.Context(), token)
+	return err == nil
+}
+
+// readKey reads the secret key from the secret key file
+func (k *Keys) readKey() (string, error) {
+	data, err := os.ReadFile(k.file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the secret key file: %w", err)
+	}
+	secretKey := strings.TrimSpace(string(data))
+	if secretKey == "" {
+		return "", fmt.Errorf("the secret key file %s is empty", k.file)
+	}
+	return secretKey, nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/servicelib/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// newKeys returns keys whose secret key is read from a temporary file
+func newKeys(t *testing.T, grace time.Duration) (*Keys, string) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "jwt.key")
+	require.NoError(t, os.WriteFile(file, []byte("first-secret-key-of-32-characters\n"), 0o600))
+
+	logger := zaptest.NewLogger(t)
+	build := func(ctx context.Context, secretKey string) (*auth.Auth, error) {
+		cfg := auth.DefaultConfig()
+		cfg.JWT.SecretKey = secretKey
+		cfg.JWT.Issuer = "family-service"
+		return auth.New(ctx, cfg, logger)
+	}
+	keys, err := NewKeys(context.Background(), config.JWTConfig{SecretKeyFile: file, RotationGrace: grace}, build)
+	require.NoError(t, err)
+	return keys, file
+}
+
+func TestKeys_Rotate(t *testing.T) {
+	ctx := context.Background()
+	keys, file := newKeys(t, time.Hour)
+
+	oldToken, err := keys.Current().GenerateToken(ctx, "user", []string{"ADMIN"}, nil, nil)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, keys.Rotate(ctx), ErrKeyUnchanged)
+
+	require.NoError(t, os.WriteFile(file, []byte("second-secret-key-of-32-characters"), 0o600))
+	require.NoError(t, keys.Rotate(ctx))
+	assert.False(t, keys.RotatedAt().IsZero())
+
+	newToken, err := keys.Current().GenerateToken(ctx, "user", []string{"ADMIN"}, nil, nil)
+	require.NoError(t, err)
+
+	// Both tokens are valid during the grace period
+	_, err = keys.ValidateToken(ctx, oldToken)
+	assert.NoError(t, err)
+	_, err = keys.ValidateToken(ctx, newToken)
+	assert.NoError(t, err)
+
+	var claimed []string
+	handler := keys.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claimed = append(claimed, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	for _, token := range []string{oldToken, newToken} {
+		req := httptest.NewRequest(http.MethodPost, "/query", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	assert.Len(t, claimed, 2)
+
+	// The previous key is rejected after the grace period
+	keys.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = keys.ValidateToken(ctx, oldToken)
+	assert.Error(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/query", nil)
+	req.Header.Set("Authorization", "Bearer "+oldToken)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestKeys_RotateWithoutFile(t *testing.T) {
+	build := func(ctx context.Context, secretKey string) (*auth.Auth, error) {
+		cfg := auth.DefaultConfig()
+		cfg.JWT.SecretKey = secretKey
+		return auth.New(ctx, cfg, zaptest.NewLogger(t))
+	}
+	keys, err := NewKeys(context.Background(), config.JWTConfig{SecretKey: "configured-secret-key-of-32-chars"}, build)
+	require.NoError(t, err)
+	assert.ErrorIs(t, keys.Rotate(context.Background()), ErrNoKeyFile)
+}
+
+func TestNewKeys_EmptyFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "jwt.key")
+	require.NoError(t, os.WriteFile(file, []byte("\n"), 0o600))
+	_, err := NewKeys(context.Background(), config.JWTConfig{SecretKeyFile: file}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,328 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	autherrors "github.com/abitofhelp/servicelib/auth/errors"
+	"github.com/abitofhelp/servicelib/auth/jwt"
+	"github.com/go-jose/go-jose/v4"
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// Auth modes, which say how tokens are validated
+const (
+	// ModeLocal validates tokens with the local secret keys
+	ModeLocal = "local"
+
+	// ModeOIDC validates tokens with the signing keys of a remote authorization server
+	ModeOIDC = "oidc"
+)
+
+// DiscoveryPath is the path of the OIDC discovery document below the issuer URL
+const DiscoveryPath = "/.well-known/openid-configuration"
+
+// minRefreshInterval is the least time between two reads of the signing keys that are
+// triggered by tokens signed with an unknown key, so that such tokens cannot flood the
+// authorization server with requests
+const minRefreshInterval = 30 * time.Second
+
+// remoteMethods are the signing methods accepted from a remote authorization server, which
+// signs with asymmetric keys
+var remoteMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// Remote validates tokens issued by a remote OIDC or OAuth2 authorization server with the
+// signing keys of its JSON Web Key Set. The keys are read again every refresh interval,
+// in the background while the current keys keep validating tokens, and when a token is
+// signed with an unknown key, so that the server can rotate its keys. Requests that need
+// the keys read again at the same time share one read.
+type Remote struct {
+	issuer   string
+	audience string
+	jwksURL  string
+	refresh  time.Duration
+	client   *http.Client
+	now      func() time.Time
+
+	// fetchMu serializes reads of the signing keys
+	fetchMu sync.Mutex
+
+	// refreshing is set while the keys are read again in the background
+	refreshing atomic.Bool
+
+	mu        sync.RWMutex
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+// remoteClaims are the claims of a remote token: the claims of local tokens, and the
+// space-separated scope claim of OAuth2 access tokens
+type remoteClaims struct {
+	jwt.Claims
+	Scope string `json:"scope,omitempty"`
+}
+
+// NewRemote discovers the JSON Web Key Set of the authorization server, unless its URL is
+// configured, and reads its signing keys
+//
+// Parameters:
+//   - ctx: The context of the initialization
+//   - cfg: The OIDC configuration
+//   - timeout: The timeout for requests to the authorization server
+//
+// Returns:
+//   - The remote validator
+//   - An error if the issuer is not set, or the signing keys could not be read
+func NewRemote(ctx context.Context, cfg config.OIDCConfig, timeout time.Duration) (*Remote, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("the OIDC issuer is required to validate remote tokens")
+	}
+	r := &Remote{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		jwksURL:  cfg.JWKSURL,
+		refresh:  cfg.RefreshInterval,
+		client:   &http.Client{Timeout: timeout},
+		now:      time.Now,
+	}
+	if r.jwksURL == "" {
+		var err error
+		if r.jwksURL, err = r.discover(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.fetch(ctx, 0); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Issuer returns the issuer whose tokens are validated
+func (r *Remote) Issuer() string {
+	return r.issuer
+}
+
+// ValidateToken validates the signature, issuer, audience and expiration of a token, and
+// returns its claims. The scopes of the scope claim are added to the scopes of the claims.
+func (r *Remote) ValidateToken(ctx context.Context, tokenString string) (*jwt.Claims, error) {
+	if r.stale() {
+		r.refreshInBackground()
+	}
+
+	options := []gojwt.ParserOption{
+		gojwt.WithIssuer(r.issuer),
+		gojwt.WithExpirationRequired(),
+		gojwt.WithValidMethods(remoteMethods),
+		gojwt.WithTimeFunc(r.now),
+	}
+	if r.audience != "" {
+		options = append(options, gojwt.WithAudience(r.audience))
+	}
+	var claims remoteClaims
+	if _, err := gojwt.NewParser(options...).ParseWithClaims(tokenString, &claims, r.keyFunc(ctx)); err != nil {
+		return nil, validationError(err)
+	}
+	if claims.UserID == "" {
+		return nil, fmt.Errorf("%w: the token has no subject", autherrors.ErrInvalidClaims)
+	}
+	for _, scope := range strings.Fields(claims.Scope) {
+		if !contains(claims.Scopes, scope) {
+			claims.Scopes = append(claims.Scopes, scope)
+		}
+	}
+	return &claims.Claims, nil
+}
+
+// keyFunc returns the function that selects the signing key of a token by its key ID. The
+// signing keys are read again once when no key has the key ID of the token.
+func (r *Remote) keyFunc(ctx context.Context) gojwt.Keyfunc {
+	return func(t *gojwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := r.key(kid)
+		if !ok && r.olderThan(minRefreshInterval) {
+			if err := r.fetch(ctx, minRefreshInterval); err != nil {
+				return nil, err
+			}
+			key, ok = r.key(kid)
+		}
+		if !ok {
+			return nil, fmt.Errorf("the JWKS has no single key with key ID %q", kid)
+		}
+		if key.Algorithm != "" && key.Algorithm != t.Method.Alg() {
+			return nil, fmt.Errorf("the token is signed with %s, but key %q is for %s", t.Method.Alg(), key.KeyID, key.Algorithm)
+		}
+
+		var matches bool
+		switch key.Key.(type) {
+		case *rsa.PublicKey:
+			_, matches = t.Method.(*gojwt.SigningMethodRSA)
+			if _, pss := t.Method.(*gojwt.SigningMethodRSAPSS); pss {
+				matches = true
+			}
+		case *ecdsa.PublicKey:
+			_, matches = t.Method.(*gojwt.SigningMethodECDSA)
+		case ed25519.PublicKey:
+			_, matches = t.Method.(*gojwt.SigningMethodEd25519)
+		}
+		if !matches {
+			return nil, fmt.Errorf("the token is signed with %s, which does not match the %T of key %q", t.Method.Alg(), key.Key, key.KeyID)
+		}
+		return key.Key, nil
+	}
+}
+
+// key returns the signing key with a key ID, or the only signing key if the token has no
+// key ID
+func (r *Remote) key(kid string) (jose.JSONWebKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := r.keys.Keys
+	if kid != "" {
+		keys = r.keys.Key(kid)
+	}
+	if len(keys) != 1 {
+		return jose.JSONWebKey{}, false
+	}
+	return keys[0], true
+}
+
+// stale reports whether the signing keys are older than the refresh interval
+func (r *Remote) stale() bool {
+	if r.refresh <= 0 {
+		return false
+	}
+	return r.olderThan(r.refresh)
+}
+
+// olderThan reports whether the signing keys were read at least age ago
+func (r *Remote) olderThan(age time.Duration) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.now().Sub(r.fetchedAt) >= age
+}
+
+// refreshInBackground reads the signing keys again in the background, unless they are
+// already being read. The previous keys are kept if they cannot be read again.
+func (r *Remote) refreshInBackground() {
+	if !r.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer r.refreshing.Store(false)
+		// The read outlives the request that triggered it, and is bounded by the timeout
+		// of the client
+		_ = r.fetch(context.Background(), r.refresh)
+	}()
+}
+
+// discover reads the JWKS URL from the discovery document of the issuer, whose issuer
+// must be the configured issuer
+func (r *Remote) discover(ctx context.Context) (string, error) {
+	var document struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	location := strings.TrimSuffix(r.issuer, "/") + DiscoveryPath
+	if err := r.get(ctx, location, &document); err != nil {
+		return "", fmt.Errorf("failed to discover the OIDC configuration: %w", err)
+	}
+	if document.Issuer != r.issuer {
+		return "", fmt.Errorf("the OIDC discovery document is for issuer %q instead of %q", document.Issuer, r.issuer)
+	}
+	if _, err := url.ParseRequestURI(document.JWKSURI); err != nil {
+		return "", fmt.Errorf("the OIDC discovery document has no valid jwks_uri: %q", document.JWKSURI)
+	}
+	return document.JWKSURI, nil
+}
+
+// fetch reads the signing keys from the JWKS URL if they were read at least age ago. The
+// age is checked again once the reads of other callers are done, so that callers who wait
+// for the same read do not each read the keys again.
+func (r *Remote) fetch(ctx context.Context, age time.Duration) error {
+	r.fetchMu.Lock()
+	defer r.fetchMu.Unlock()
+	if age > 0 && !r.olderThan(age) {
+		return nil
+	}
+
+	var set jose.JSONWebKeySet
+	err := r.get(ctx, r.jwksURL, &set)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// A failed read is not retried before the next refresh either
+	r.fetchedAt = r.now()
+	if err != nil {
+		return fmt.Errorf("failed to read the signing keys: %w", err)
+	}
+	r.keys = &set
+	return nil
+}
+
+// get reads a JSON document from a URL
+func (r *Remote) get(ctx context.Context, location string, value interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", location, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// validationError wraps an error of the token parser in the auth error it denotes, so that
+// the middleware answers with the same messages as for local tokens
+func validationError(err error) error {
+	switch {
+	case errors.Is(err, gojwt.ErrTokenExpired):
+		return fmt.Errorf("%w: %v", autherrors.ErrExpiredToken, err)
+	case errors.Is(err, gojwt.ErrTokenSignatureInvalid):
+		return fmt.Errorf("%w: %v", autherrors.ErrInvalidSignature, err)
+	case errors.Is(err, gojwt.ErrTokenInvalidIssuer), errors.Is(err, gojwt.ErrTokenInvalidAudience),
+		errors.Is(err, gojwt.ErrTokenNotValidYet), errors.Is(err, gojwt.ErrTokenRequiredClaimMissing):
+		return fmt.Errorf("%w: %v", autherrors.ErrInvalidClaims, err)
+	default:
+		return fmt.Errorf("%w: %v", autherrors.ErrInvalidToken, err)
+	}
+}
+
+// contains reports whether a slice holds a value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,317 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	autherrors "github.com/abitofhelp/servicelib/auth/errors"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/go-jose/go-jose/v4"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authorizationServer serves the discovery document and the JWKS of an authorization server
+type authorizationServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches int
+}
+
+// newAuthorizationServer returns an authorization server with a signing key
+func newAuthorizationServer(t *testing.T, kid string) *authorizationServer {
+	t.Helper()
+	s := &authorizationServer{keys: map[string]*rsa.PrivateKey{}}
+	s.rotate(t, kid)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(DiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": s.URL, "jwks_uri": s.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fetches++
+		var set jose.JSONWebKeySet
+		for kid, key := range s.keys {
+			set.Keys = append(set.Keys, jose.JSONWebKey{Key: &key.PublicKey, KeyID: kid, Algorithm: "RS256", Use: "sig"})
+		}
+		_ = json.NewEncoder(w).Encode(set)
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// rotate replaces the signing keys with a new key
+func (s *authorizationServer) rotate(t *testing.T, kid string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = map[string]*rsa.PrivateKey{kid: key}
+}
+
+// sign returns a token signed with the key with a key ID
+func (s *authorizationServer) sign(t *testing.T, kid string, claims gojwt.MapClaims) string {
+	t.Helper()
+	s.mu.Lock()
+	key := s.keys[kid]
+	s.mu.Unlock()
+	require.NotNil(t, key)
+	token := gojwt.NewWithClaims(gojwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+// fetched returns the number of reads of the JWKS
+func (s *authorizationServer) fetched() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+// claims returns valid claims of a token of the server
+func (s *authorizationServer) claims() gojwt.MapClaims {
+	return gojwt.MapClaims{
+		"iss":   s.URL,
+		"sub":   "user-1",
+		"aud":   "family-service",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"EDITOR"},
+		"scope": "openid family:read family:update",
+	}
+}
+
+func TestRemote_ValidateToken(t *testing.T) {
+	ctx := context.Background()
+	server := newAuthorizationServer(t, "key-1")
+	remote, err := NewRemote(ctx, config.OIDCConfig{Issuer: server.URL, Audience: "family-service"}, time.Second)
+	require.NoError(t, err)
+
+	claims, err := remote.ValidateToken(ctx, server.sign(t, "key-1", server.claims()))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, []string{"EDITOR"}, claims.Roles)
+	assert.Equal(t, []string{"openid", "family:read", "family:update"}, claims.Scopes)
+
+	tests := []struct {
+		name   string
+		modify func(gojwt.MapClaims)
+		want   error
+	}{
+		{"other issuer", func(c gojwt.MapClaims) { c["iss"] = "https://other.example.com" }, autherrors.ErrInvalidClaims},
+		{"other audience", func(c gojwt.MapClaims) { c["aud"] = "other-service" }, autherrors.ErrInvalidClaims},
+		{"expired", func(c gojwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, autherrors.ErrExpiredToken},
+		{"no expiration", func(c gojwt.MapClaims) { delete(c, "exp") }, autherrors.ErrInvalidClaims},
+		{"no subject", func(c gojwt.MapClaims) { delete(c, "sub") }, autherrors.ErrInvalidClaims},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := server.claims()
+			tt.modify(claims)
+			_, err := remote.ValidateToken(ctx, server.sign(t, "key-1", claims))
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	t.Run("forged signature", func(t *testing.T) {
+		forger := newAuthorizationServer(t, "key-1")
+		_, err := remote.ValidateToken(ctx, forger.sign(t, "key-1", server.claims()))
+		assert.ErrorIs(t, err, autherrors.ErrInvalidSignature)
+	})
+
+	t.Run("HMAC", func(t *testing.T) {
+		token := gojwt.NewWithClaims(gojwt.SigningMethodHS256, server.claims())
+		token.Header["kid"] = "key-1"
+		signed, err := token.SignedString([]byte("first-secret-key-of-32-characters"))
+		require.NoError(t, err)
+		_, err = remote.ValidateToken(ctx, signed)
+		assert.ErrorIs(t, err, autherrors.ErrInvalidSignature)
+	})
+}
+
+func TestRemote_KeyRotation(t *testing.T) {
+	ctx := context.Background()
+	server := newAuthorizationServer(t, "key-1")
+	remote, err := NewRemote(ctx, config.OIDCConfig{Issuer: server.URL, JWKSURL: server.URL + "/keys", RefreshInterval: time.Hour}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, server.fetched())
+
+	server.rotate(t, "key-2")
+	claims := server.claims()
+	claims["exp"] = time.Now().Add(24 * time.Hour).Unix()
+	token := server.sign(t, "key-2", claims)
+
+	// Unknown keys are not read again right after the keys were read
+	_, err = remote.ValidateToken(ctx, token)
+	assert.Error(t, err)
+	assert.Equal(t, 1, server.fetched())
+
+	clock := time.Now().Add(time.Minute)
+	remote.now = func() time.Time { return clock }
+	_, err = remote.ValidateToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, 2, server.fetched())
+
+	// Keys are read again in the background when they are older than the refresh interval,
+	// while the current keys validate tokens
+	clock = clock.Add(2 * time.Hour)
+	_, err = remote.ValidateToken(ctx, token)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return server.fetched() == 3 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRemote_ConcurrentRefresh(t *testing.T) {
+	ctx := context.Background()
+	server := newAuthorizationServer(t, "key-1")
+	remote, err := NewRemote(ctx, config.OIDCConfig{Issuer: server.URL, JWKSURL: server.URL + "/keys", RefreshInterval: time.Hour}, time.Second)
+	require.NoError(t, err)
+
+	// validate validates a token with many concurrent requests
+	validate := func(token string) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := remote.ValidateToken(ctx, token)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+	}
+
+	// Requests with a token signed with a new key share one read of the keys
+	server.rotate(t, "key-2")
+	claims := server.claims()
+	claims["exp"] = time.Now().Add(24 * time.Hour).Unix()
+	token := server.sign(t, "key-2", claims)
+	clock := time.Now().Add(time.Minute)
+	remote.now = func() time.Time { return clock }
+	validate(token)
+	assert.Equal(t, 2, server.fetched())
+
+	// Requests with stale keys share one read in the background
+	clock = clock.Add(2 * time.Hour)
+	validate(token)
+	assert.Eventually(t, func() bool { return !remote.olderThan(time.Hour) }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3, server.fetched())
+}
+
+func TestNewRemote_Errors(t *testing.T) {
+	ctx := context.Background()
+	server := newAuthorizationServer(t, "key-1")
+
+	_, err := NewRemote(ctx, config.OIDCConfig{}, time.Second)
+	assert.Error(t, err)
+
+	// The discovery document must be for the configured issuer
+	_, err = NewRemote(ctx, config.OIDCConfig{Issuer: server.URL + "/"}, time.Second)
+	assert.ErrorContains(t, err, "is for issuer")
+
+	_, err = NewRemote(ctx, config.OIDCConfig{Issuer: server.URL, JWKSURL: server.URL + "/missing"}, time.Second)
+	assert.ErrorContains(t, err, "failed to read the signing keys")
+}
+
+func TestKeys_UseRemote(t *testing.T) {
+	ctx := context.Background()
+	server := newAuthorizationServer(t, "key-1")
+	remote, err := NewRemote(ctx, config.OIDCConfig{Issuer: server.URL}, time.Second)
+	require.NoError(t, err)
+
+	keys, _ := newKeys(t, time.Hour)
+	localToken, err := keys.Current().GenerateToken(ctx, "local-user", []string{"ADMIN"}, nil, nil)
+	require.NoError(t, err)
+	remoteToken := server.sign(t, "key-1", server.claims())
+
+	keys.UseRemote(remote, false, []string{"/health"})
+	_, err = keys.ValidateToken(ctx, localToken)
+	assert.Error(t, err)
+
+	var userID string
+	handler := keys.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ = middleware.GetUserID(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/query", "Bearer "+remoteToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "user-1", userID)
+
+	rec = serve("/query", "Bearer "+localToken)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Invalid token")
+
+	rec = serve("/query", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Authorization required")
+
+	assert.Equal(t, http.StatusOK, serve("/health", "").Code)
+
+	// Local tokens are accepted as well for development
+	keys.UseRemote(remote, true, nil)
+	claims, err := keys.ValidateToken(ctx, localToken)
+	require.NoError(t, err)
+	assert.Equal(t, "local-user", claims.UserID)
+	_, err = keys.ValidateToken(ctx, remoteToken)
+	assert.NoError(t, err)
+}
+
+func TestKeys_MiddlewareTenant(t *testing.T) {
+	ctx := context.Background()
+	server := newAuthorizationServer(t, "key-1")
+	remote, err := NewRemote(ctx, config.OIDCConfig{Issuer: server.URL}, time.Second)
+	require.NoError(t, err)
+	keys, _ := newKeys(t, time.Hour)
+	keys.UseRemote(remote, false, []string{"/health"})
+
+	var tenant string
+	handler := keys.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = servicecontext.GetTenantID(r.Context())
+	}))
+	serve := func(path, token string) {
+		tenant = "unset"
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	claims := server.claims()
+	claims[TenantClaim] = "acme"
+	serve("/query", server.sign(t, "key-1", claims))
+	assert.Equal(t, "acme", tenant)
+
+	serve("/query", server.sign(t, "key-1", server.claims()))
+	assert.Empty(t, tenant)
+
+	// The token of a path served without authentication is not validated, so its tenant is ignored
+	serve("/health", server.sign(t, "key-1", claims))
+	assert.Empty(t, tenant)
+}
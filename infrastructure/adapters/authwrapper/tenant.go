@@ -0,0 +1,53 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package auth
+
+import (
+	"net/http"
+
+	"github.com/abitofhelp/servicelib/auth/jwt"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// TenantClaim is the claim of a token that holds the ID of the tenant of its user
+const TenantClaim = "tenant_id"
+
+// withTenant records the tenant of the token of an authenticated request in its context,
+// where the repositories read it to scope their queries to the families of the tenant.
+// The token has been validated by the auth middleware, which records its user; requests
+// without a user, such as requests to the paths served without authentication, get no
+// tenant, whatever token they carry.
+func withTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := middleware.GetUserID(r.Context()); ok {
+			if tenant := tenantOf(r.Header.Get("Authorization")); tenant != "" {
+				r = r.WithContext(servicecontext.WithTenantID(r.Context(), tenant))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tenantOf returns the tenant claim of the bearer token of an Authorization header, or ""
+// if the token has none. The token is not validated again.
+func tenantOf(header string) string {
+	token, err := jwt.ExtractTokenFromHeader(header)
+	if err != nil {
+		return ""
+	}
+	return TokenTenant(token)
+}
+
+// TokenTenant returns the tenant claim of a token, or "" if it has none. The token must
+// have been validated: its signature is not checked. Websocket connections, which are not
+// authenticated by the HTTP middleware, record the tenant of their token with it.
+func TokenTenant(token string) string {
+	claims := gojwt.MapClaims{}
+	if _, _, err := gojwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return ""
+	}
+	tenant, _ := claims[TenantClaim].(string)
+	return tenant
+}
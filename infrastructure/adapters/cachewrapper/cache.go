@@ -2,10 +2,14 @@
 
 // Package cache provides functionality for caching frequently accessed data.
 // This package is a wrapper around the servicelib cache package.
+//
+// Each replica of the service has its own cache. With an invalidation bus, the keys
+// invalidated by one replica are removed from the caches of all replicas.
 package cache
 
 import (
 	"context"
+	"time"
 
 	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
 	"github.com/abitofhelp/servicelib/cache"
@@ -15,7 +19,8 @@
 
 // Cache is a wrapper around servicelib's cache
 type Cache struct {
-	cache *cache.Cache[interface{}]
+	cache       *cache.Cache[interface{}]
+	invalidator *invalidator // nil without an invalidation bus
 }
 
 // NewCache creates a new cache with the given configuration
@@ -46,10 +51,34 @@ func NewCache(cfg *config.Config, logger *zap.Logger) (*Cache, error) {
 	// Create servicelib cache
 	serviceCache := cache.NewCache[interface{}](cacheConfig, options)
 
-	logger.Info("Cache initialized successfully")
-	return &Cache{
+	c := &Cache{
 		cache: serviceCache,
-	}, nil
+	}
+
+	// Broadcast invalidations to the caches of the other replicas
+	if cfg.Cache.Invalidation.Enabled {
+		logger.Info("Broadcasting cache invalidations through Redis",
+			zap.String("address", cfg.Cache.Invalidation.Redis.Address),
+			zap.String("channel", cfg.Cache.Invalidation.Channel))
+		c.startInvalidation(NewRedisBus(cfg.Cache.Invalidation), cfg.Cache.Invalidation.Redis.RetryInterval, logger)
+	}
+
+	logger.Info("Cache initialized successfully")
+	return c, nil
+}
+
+// startInvalidation publishes invalidations on a bus and listens to the invalidations of
+// the other replicas until the cache is shut down
+func (c *Cache) startInvalidation(bus Bus, retryInterval time.Duration, logger *zap.Logger) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.invalidator = &invalidator{
+		bus:           bus,
+		retryInterval: retryInterval,
+		logger:        logger,
+		cancel:        cancel,
+		done:          make(chan struct{}),
+	}
+	go c.listen(ctx)
 }
 
 // Set adds an item to the cache with the default expiration time
@@ -79,12 +108,16 @@ func (c *Cache) Delete(key string) {
 	c.cache.Delete(context.Background(), key)
 }
 
-// Shutdown stops the cleanup timer
+// Shutdown stops the cleanup timer and listening to invalidations
 func (c *Cache) Shutdown() {
 	if c == nil || c.cache == nil {
 		return
 	}
 
+	if c.invalidator != nil {
+		c.invalidator.cancel()
+		<-c.invalidator.done
+	}
 	c.cache.Shutdown()
 }
 
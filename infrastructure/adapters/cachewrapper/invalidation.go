@@ -0,0 +1,198 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Events of the invalidation bus, as counted in the invalidations metric
+const (
+	EventPublished     = "published"      // Invalidated keys were published to the other replicas
+	EventPublishFailed = "publish_failed" // Invalidated keys could not be published
+	EventReceived      = "received"       // Keys invalidated by a replica were removed from the cache
+	EventCleared       = "cleared"        // The cache was cleared after subscribing again
+)
+
+// InvalidationsTotal counts the events of the invalidation bus
+var InvalidationsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cache_invalidations_total",
+		Help: "Total number of events of the cache invalidation bus between replicas by event",
+	},
+	[]string{"event"},
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(InvalidationsTotal)
+}
+
+// Bus carries invalidation messages between the replicas of the service
+type Bus interface {
+	// Publish sends a message to the subscribers of all replicas
+	Publish(ctx context.Context, message string) error
+
+	// Subscribe subscribes to the messages published from now on
+	Subscribe(ctx context.Context) (Receiver, error)
+}
+
+// Receiver receives the messages of a subscription to a Bus
+type Receiver interface {
+	// Receive waits for the next message; it fails once the subscription is lost or closed
+	Receive() (string, error)
+
+	// Close ends the subscription
+	Close() error
+}
+
+// invalidation is the message of an invalidation, of some keys or of every item
+type invalidation struct {
+	Keys []string `json:"keys,omitempty"`
+	All  bool     `json:"all,omitempty"`
+}
+
+// invalidator publishes invalidated keys and removes the keys invalidated by other replicas
+type invalidator struct {
+	bus           Bus
+	retryInterval time.Duration
+	logger        *zap.Logger
+	cancel        context.CancelFunc
+	done          chan struct{}
+}
+
+// redisBus is a Bus over a Redis channel
+type redisBus struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisBus creates a Bus that publishes messages on a Redis channel
+func NewRedisBus(cfg config.CacheInvalidationConfig) Bus {
+	return &redisBus{client: redis.NewClient(&cfg.Redis), channel: cfg.Channel}
+}
+
+// Publish publishes a message on the channel
+func (b *redisBus) Publish(ctx context.Context, message string) error {
+	_, err := b.client.Publish(ctx, b.channel, message)
+	return err
+}
+
+// Subscribe subscribes to the channel
+func (b *redisBus) Subscribe(ctx context.Context) (Receiver, error) {
+	return b.client.Subscribe(ctx, b.channel)
+}
+
+// Invalidate removes items from the cache and, with an invalidation bus, from the caches
+// of the other replicas. Failing to publish is logged rather than returned, since the
+// items of the other replicas still expire with their time to live.
+func (c *Cache) Invalidate(ctx context.Context, keys ...string) {
+	if c == nil || c.cache == nil || len(keys) == 0 {
+		return
+	}
+
+	for _, key := range keys {
+		c.cache.Delete(ctx, key)
+	}
+	c.publish(ctx, invalidation{Keys: keys})
+}
+
+// Flush removes every item from the cache and, with an invalidation bus, from the caches
+// of the other replicas. It reports whether the other replicas were told to flush.
+func (c *Cache) Flush(ctx context.Context) bool {
+	if c == nil || c.cache == nil {
+		return false
+	}
+
+	c.cache.Clear(ctx)
+	return c.publish(ctx, invalidation{All: true})
+}
+
+// publish publishes an invalidation on the bus, if any, and reports whether it was published
+func (c *Cache) publish(ctx context.Context, inv invalidation) bool {
+	if c.invalidator == nil {
+		return false
+	}
+
+	message, err := json.Marshal(inv)
+	if err == nil {
+		err = c.invalidator.bus.Publish(context.WithoutCancel(ctx), string(message))
+	}
+	if err != nil {
+		InvalidationsTotal.WithLabelValues(EventPublishFailed).Inc()
+		c.invalidator.logger.Warn("Failed to publish cache invalidation", zap.Strings("keys", inv.Keys), zap.Bool("all", inv.All), zap.Error(err))
+		return false
+	}
+	InvalidationsTotal.WithLabelValues(EventPublished).Inc()
+	return true
+}
+
+// listen subscribes to the invalidation bus, and removes the keys invalidated by the
+// replicas from the cache, until ctx is cancelled. When the subscription is lost, it
+// subscribes again after the retry interval and clears the cache, whose items may have
+// been invalidated in the meantime.
+func (c *Cache) listen(ctx context.Context) {
+	inv := c.invalidator
+	defer close(inv.done)
+
+	subscribed := false
+	for {
+		receiver, err := inv.bus.Subscribe(ctx)
+		if err == nil {
+			if subscribed {
+				c.cache.Clear(ctx)
+				InvalidationsTotal.WithLabelValues(EventCleared).Inc()
+				inv.logger.Info("Subscribed to cache invalidations again, cleared the cache")
+			}
+			subscribed = true
+			err = c.receive(ctx, receiver)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		inv.logger.Warn("Cache invalidation subscription failed",
+			zap.Duration("retry_interval", inv.retryInterval),
+			zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(inv.retryInterval):
+		}
+	}
+}
+
+// receive removes the keys of the messages of a subscription from the cache, until the
+// subscription fails or ctx is cancelled
+func (c *Cache) receive(ctx context.Context, receiver Receiver) error {
+	stop := context.AfterFunc(ctx, func() { receiver.Close() })
+	defer stop()
+	defer receiver.Close()
+
+	for {
+		message, err := receiver.Receive()
+		if err != nil {
+			return err
+		}
+
+		var inv invalidation
+		if err := json.Unmarshal([]byte(message), &inv); err != nil {
+			c.invalidator.logger.Warn("Ignoring malformed cache invalidation", zap.String("message", message), zap.Error(err))
+			continue
+		}
+		if inv.All {
+			c.cache.Clear(ctx)
+		}
+		for _, key := range inv.Keys {
+			c.cache.Delete(ctx, key)
+		}
+		InvalidationsTotal.WithLabelValues(EventReceived).Inc()
+	}
+}
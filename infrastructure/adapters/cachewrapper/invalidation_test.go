@@ -0,0 +1,191 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeBus is an in-memory Bus shared by the caches of a test
+type fakeBus struct {
+	mu         sync.Mutex
+	receivers  []*fakeReceiver
+	subscribes int
+	publishErr error
+}
+
+func (b *fakeBus) Publish(_ context.Context, message string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.publishErr != nil {
+		return b.publishErr
+	}
+	for _, r := range b.receivers {
+		r.messages <- message
+	}
+	return nil
+}
+
+func (b *fakeBus) Subscribe(context.Context) (Receiver, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r := &fakeReceiver{messages: make(chan string, 10), closed: make(chan struct{})}
+	b.receivers = append(b.receivers, r)
+	b.subscribes++
+	return r, nil
+}
+
+// subscriptions returns the number of subscriptions made so far
+func (b *fakeBus) subscriptions() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.subscribes
+}
+
+// drop loses the current subscriptions
+func (b *fakeBus) drop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, r := range b.receivers {
+		r.Close()
+	}
+	b.receivers = nil
+}
+
+type fakeReceiver struct {
+	messages chan string
+	closed   chan struct{}
+	once     sync.Once
+}
+
+func (r *fakeReceiver) Receive() (string, error) {
+	select {
+	case message := <-r.messages:
+		return message, nil
+	case <-r.closed:
+		return "", io.EOF
+	}
+}
+
+func (r *fakeReceiver) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return nil
+}
+
+// newBusCache creates a cache that listens to invalidations on a bus
+func newBusCache(t *testing.T, bus Bus) *Cache {
+	logger := zaptest.NewLogger(t)
+	c, err := NewCache(&config.Config{
+		Cache: config.CacheConfig{
+			Enabled:       true,
+			TTL:           5 * time.Minute,
+			MaxSize:       1000,
+			PurgeInterval: 10 * time.Minute,
+		},
+	}, logger)
+	require.NoError(t, err)
+	c.startInvalidation(bus, 10*time.Millisecond, logger)
+	t.Cleanup(c.Shutdown)
+	return c
+}
+
+func TestCache_InvalidateBroadcasts(t *testing.T) {
+	bus := &fakeBus{}
+	a, b := newBusCache(t, bus), newBusCache(t, bus)
+	require.Eventually(t, func() bool { return bus.subscriptions() == 2 }, time.Second, time.Millisecond)
+	published := testutil.ToFloat64(InvalidationsTotal.WithLabelValues(EventPublished))
+
+	for _, c := range []*Cache{a, b} {
+		c.Set("family:1", "stale")
+		c.Set("family:2", "current")
+	}
+
+	a.Invalidate(context.Background(), "family:1")
+
+	// The replica that invalidated the key removes it at once, the others when they receive it
+	_, found := a.Get("family:1")
+	assert.False(t, found)
+	assert.Eventually(t, func() bool {
+		_, found := b.Get("family:1")
+		return !found
+	}, time.Second, time.Millisecond)
+	_, found = b.Get("family:2")
+	assert.True(t, found)
+	assert.Equal(t, published+1, testutil.ToFloat64(InvalidationsTotal.WithLabelValues(EventPublished)))
+}
+
+func TestCache_InvalidatePublishFailure(t *testing.T) {
+	bus := &fakeBus{publishErr: errors.New("redis is down")}
+	c := newBusCache(t, bus)
+	failed := testutil.ToFloat64(InvalidationsTotal.WithLabelValues(EventPublishFailed))
+
+	c.Set("family:1", "stale")
+	c.Invalidate(context.Background(), "family:1")
+
+	_, found := c.Get("family:1")
+	assert.False(t, found)
+	assert.Equal(t, failed+1, testutil.ToFloat64(InvalidationsTotal.WithLabelValues(EventPublishFailed)))
+}
+
+func TestCache_ClearsAfterResubscribing(t *testing.T) {
+	bus := &fakeBus{}
+	c := newBusCache(t, bus)
+	require.Eventually(t, func() bool { return bus.subscriptions() == 1 }, time.Second, time.Millisecond)
+	cleared := testutil.ToFloat64(InvalidationsTotal.WithLabelValues(EventCleared))
+
+	// Items cached while subscribed are kept
+	c.Set("family:1", "value")
+	_, found := c.Get("family:1")
+	require.True(t, found)
+
+	// Invalidations may be missed while the subscription is lost, so the cache is cleared
+	bus.drop()
+	require.Eventually(t, func() bool { return bus.subscriptions() == 2 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(InvalidationsTotal.WithLabelValues(EventCleared)) == cleared+1
+	}, time.Second, time.Millisecond)
+	_, found = c.Get("family:1")
+	assert.False(t, found)
+}
+
+func TestCache_FlushBroadcasts(t *testing.T) {
+	bus := &fakeBus{}
+	a, b := newBusCache(t, bus), newBusCache(t, bus)
+	require.Eventually(t, func() bool { return bus.subscriptions() == 2 }, time.Second, time.Millisecond)
+
+	for _, c := range []*Cache{a, b} {
+		c.Set("family:1", "value")
+		c.Set("family:2", "value")
+	}
+
+	assert.True(t, a.Flush(context.Background()))
+	_, found := a.Get("family:1")
+	assert.False(t, found)
+	assert.Eventually(t, func() bool {
+		_, found1 := b.Get("family:1")
+		_, found2 := b.Get("family:2")
+		return !found1 && !found2
+	}, time.Second, time.Millisecond)
+
+	// Without a bus, only the local cache is flushed
+	local, err := NewCache(&config.Config{
+		Cache: config.CacheConfig{Enabled: true, TTL: time.Minute, MaxSize: 10, PurgeInterval: time.Minute},
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer local.Shutdown()
+	local.Set("family:1", "value")
+	assert.False(t, local.Flush(context.Background()))
+	_, found = local.Get("family:1")
+	assert.False(t, found)
+}
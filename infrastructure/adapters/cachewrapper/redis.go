@@ -0,0 +1,203 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	circuit "github.com/abitofhelp/family-service/infrastructure/adapters/circuitwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	rate "github.com/abitofhelp/family-service/infrastructure/adapters/ratewrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Backend values for config.CacheConfig.Backend
+const (
+	// BackendMemory caches families in the memory of each replica
+	BackendMemory = "memory"
+
+	// BackendRedis caches families in Redis, where all replicas share them
+	BackendRedis = "redis"
+)
+
+// Operations of the Redis backend, as counted in the backend errors metric
+const (
+	OperationGet        = "get"
+	OperationSet        = "set"
+	OperationInvalidate = "invalidate"
+)
+
+// BackendErrorsTotal counts the operations of the Redis backend that failed or were
+// rejected by its circuit breaker or rate limiter
+var BackendErrorsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cache_backend_errors_total",
+		Help: "Total number of failed operations of the Redis cache backend by operation",
+	},
+	[]string{"operation"},
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(BackendErrorsTotal)
+}
+
+// Store is a store of string values with expiry, such as Redis
+type Store interface {
+	// Get returns the value of a key, or redis.ErrNil if the key does not exist
+	Get(ctx context.Context, key string) (string, error)
+
+	// Set sets the value of a key, which expires after ttl
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+
+	// Del deletes keys
+	Del(ctx context.Context, keys ...string) (int64, error)
+}
+
+// RedisBackend is a Backend that caches families in Redis, so that the replicas of the
+// service share the cached families and their invalidations. Families are stored as JSON
+// under the key prefix and expire after the time to live of the cache.
+//
+// Every operation runs behind a rate limiter and a circuit breaker, so that a slow or
+// failing Redis does not slow the reads it would speed up. An operation that fails or is
+// rejected is logged and counted: a read misses the cache, and a family that cannot be
+// invalidated expires with its time to live, while its saves fail with a concurrency
+// conflict until then.
+type RedisBackend struct {
+	store          Store
+	keyPrefix      string
+	ttl            time.Duration
+	circuitBreaker *circuit.CircuitBreaker
+	rateLimiter    *rate.RateLimiter
+	logger         *zap.Logger
+	close          func() error
+}
+
+// Ensure RedisBackend implements Backend
+var _ Backend = (*RedisBackend)(nil)
+
+// NewRedisBackend creates a Backend that caches families in Redis
+//
+// Parameters:
+//   - cfg: The cache configuration, whose Redis connection, key prefix, and time to live are used
+//   - circuitConfig: The configuration of the circuit breaker of Redis
+//   - rateConfig: The configuration of the rate limiter of Redis
+//   - logger: Logger for failed operations
+//
+// Returns:
+//   - A new Redis backend, which must be closed when it is no longer needed
+func NewRedisBackend(cfg config.CacheConfig, circuitConfig *config.CircuitConfig, rateConfig *config.RateConfig, logger *zap.Logger) *RedisBackend {
+	logger.Info("Caching families in Redis",
+		zap.String("address", cfg.Redis.Address),
+		zap.String("key_prefix", cfg.Redis.KeyPrefix),
+		zap.Duration("ttl", cfg.TTL),
+		zap.Int("pool_size", cfg.Redis.PoolSize))
+
+	client := redis.NewClient(&cfg.Redis)
+	b := newRedisBackend(client, cfg.Redis.KeyPrefix, cfg.TTL,
+		circuit.NewCircuitBreaker("redis-cache", circuitConfig, logger),
+		rate.NewRateLimiter("redis-cache", rateConfig, logger),
+		logger)
+	b.close = client.Close
+	return b
+}
+
+// newRedisBackend creates a Backend that caches families in a store
+func newRedisBackend(store Store, keyPrefix string, ttl time.Duration, cb *circuit.CircuitBreaker, rl *rate.RateLimiter, logger *zap.Logger) *RedisBackend {
+	return &RedisBackend{
+		store:          store,
+		keyPrefix:      keyPrefix,
+		ttl:            ttl,
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		logger:         logger,
+		close:          func() error { return nil },
+	}
+}
+
+// GetFamily returns the family cached under a key. A family that cannot be read is a miss.
+func (b *RedisBackend) GetFamily(ctx context.Context, key string) (entity.FamilyDTO, bool) {
+	var value string
+	found := false
+	err := b.protect(ctx, OperationGet, func(ctx context.Context) error {
+		v, err := b.store.Get(ctx, b.keyPrefix+key)
+		if stderrors.Is(err, redis.ErrNil) {
+			return nil
+		}
+		value, found = v, err == nil
+		return err
+	})
+	if err != nil || !found {
+		return entity.FamilyDTO{}, false
+	}
+
+	var family entity.FamilyDTO
+	if err := json.Unmarshal([]byte(value), &family); err != nil {
+		BackendErrorsTotal.WithLabelValues(OperationGet).Inc()
+		b.logger.Warn("Ignoring malformed cached family", zap.String("key", key), zap.Error(err))
+		return entity.FamilyDTO{}, false
+	}
+	return family, true
+}
+
+// SetFamily caches a family under a key until the time to live of the cache has passed
+func (b *RedisBackend) SetFamily(ctx context.Context, key string, family entity.FamilyDTO) {
+	value, err := json.Marshal(family)
+	if err != nil {
+		BackendErrorsTotal.WithLabelValues(OperationSet).Inc()
+		b.logger.Warn("Failed to encode family for the cache", zap.String("key", key), zap.Error(err))
+		return
+	}
+	_ = b.protect(ctx, OperationSet, func(ctx context.Context) error {
+		return b.store.Set(ctx, b.keyPrefix+key, string(value), b.ttl)
+	})
+}
+
+// Invalidate removes the families cached under keys. Keys are removed even if the caller
+// has given up, since the change that invalidated them may have been made.
+func (b *RedisBackend) Invalidate(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = b.keyPrefix + key
+	}
+	_ = b.protect(context.WithoutCancel(ctx), OperationInvalidate, func(ctx context.Context) error {
+		_, err := b.store.Del(ctx, prefixed...)
+		return err
+	})
+}
+
+// Close closes the connections to Redis
+func (b *RedisBackend) Close() error {
+	return b.close()
+}
+
+// protect runs an operation on the store behind the rate limiter and the circuit breaker,
+// and logs and counts the operations that fail. Rejections are logged by the rate limiter
+// and the circuit breaker.
+func (b *RedisBackend) protect(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	var fnErr error
+	err := b.rateLimiter.Execute(ctx, operation, func(ctx context.Context) error {
+		return b.circuitBreaker.Execute(ctx, operation, func(ctx context.Context) error {
+			fnErr = fn(ctx)
+			return fnErr
+		})
+	})
+	if err == nil {
+		return nil
+	}
+
+	BackendErrorsTotal.WithLabelValues(operation).Inc()
+	if fnErr != nil && ctx.Err() == nil {
+		b.logger.Warn("Redis cache operation failed", zap.String("operation", operation), zap.Error(fnErr))
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
+	circuit "github.com/abitofhelp/family-service/infrastructure/adapters/circuitwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/redis"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeStore is an in-memory Store whose operations fail with err
+type fakeStore struct {
+	mu     sync.Mutex
+	values map[string]string
+	ttls   map[string]time.Duration
+	calls  int
+	err    error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{values: make(map[string]string), ttls: make(map[string]time.Duration)}
+}
+
+func (s *fakeStore) Get(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return "", s.err
+	}
+	value, ok := s.values[key]
+	if !ok {
+		return "", redis.ErrNil
+	}
+	return value, nil
+}
+
+func (s *fakeStore) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return s.err
+	}
+	s.values[key] = value
+	s.ttls[key] = ttl
+	return nil
+}
+
+func (s *fakeStore) Del(_ context.Context, keys ...string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return 0, s.err
+	}
+	var n int64
+	for _, key := range keys {
+		if _, ok := s.values[key]; ok {
+			delete(s.values, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestRedisBackend(t *testing.T) {
+	store := newFakeStore()
+	backend := newRedisBackend(store, "test:", time.Minute, nil, nil, zaptest.NewLogger(t))
+	ctx := context.Background()
+	family := querytest.Families(t)[1].ToDTO()
+
+	_, ok := backend.GetFamily(ctx, "family:1")
+	assert.False(t, ok)
+
+	backend.SetFamily(ctx, "family:1", family)
+	assert.Contains(t, store.values, "test:family:1")
+	assert.Equal(t, time.Minute, store.ttls["test:family:1"])
+
+	cached, ok := backend.GetFamily(ctx, "family:1")
+	require.True(t, ok)
+	assert.Equal(t, family.ID, cached.ID)
+	assert.Len(t, cached.Parents, len(family.Parents))
+	assert.Len(t, cached.Children, len(family.Children))
+	assert.True(t, family.Parents[0].BirthDate.Equal(cached.Parents[0].BirthDate))
+
+	backend.Invalidate(ctx, "family:1", "family:2")
+	_, ok = backend.GetFamily(ctx, "family:1")
+	assert.False(t, ok)
+
+	// A malformed family is a miss
+	store.values["test:family:1"] = "{"
+	_, ok = backend.GetFamily(ctx, "family:1")
+	assert.False(t, ok)
+}
+
+func TestRedisBackend_Unavailable(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store := newFakeStore()
+	store.err = errors.New("connection refused")
+	cb := circuit.NewCircuitBreaker("redis-cache-test", &config.CircuitConfig{
+		Enabled:         true,
+		Timeout:         5 * time.Second,
+		MaxConcurrent:   100,
+		ErrorThreshold:  0.5,
+		VolumeThreshold: 2,
+		SleepWindow:     time.Minute,
+	}, logger)
+	backend := newRedisBackend(store, "test:", time.Minute, cb, nil, logger)
+	ctx := context.Background()
+	failed := testutil.ToFloat64(BackendErrorsTotal.WithLabelValues(OperationGet))
+
+	// Failures are misses, until the circuit opens and Redis is no longer called
+	for i := 0; i < 5; i++ {
+		_, ok := backend.GetFamily(ctx, "family:1")
+		assert.False(t, ok)
+	}
+	backend.Invalidate(ctx, "family:1")
+
+	assert.Equal(t, circuit.Open, cb.GetState())
+	assert.Equal(t, 2, store.calls)
+	assert.Equal(t, failed+5, testutil.ToFloat64(BackendErrorsTotal.WithLabelValues(OperationGet)))
+}
@@ -0,0 +1,421 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package cache
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/abitofhelp/servicelib/errors"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Results of a read of a family through the cache, as counted in the reads metric
+const (
+	ResultHit  = "hit"  // The family was served from the cache
+	ResultMiss = "miss" // The family was read from the repository and cached
+)
+
+// ReadsTotal counts the reads of families through the cache by result
+var ReadsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cache_family_reads_total",
+		Help: "Total number of reads of single families through the read-through cache by result",
+	},
+	[]string{"result"},
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(ReadsTotal)
+}
+
+// Backend stores the families cached by a Repository. Backends are caches: they may lose
+// any family at any time, so their failures are logged rather than returned.
+type Backend interface {
+	// GetFamily returns the family cached under a key, or false if none is
+	GetFamily(ctx context.Context, key string) (entity.FamilyDTO, bool)
+
+	// SetFamily caches a family under a key until it expires or is invalidated
+	SetFamily(ctx context.Context, key string, family entity.FamilyDTO)
+
+	// Invalidate removes the families cached under keys, in every replica that shares them
+	Invalidate(ctx context.Context, keys ...string)
+}
+
+// Ensure Cache implements Backend
+var _ Backend = (*Cache)(nil)
+
+// GetFamily returns the family cached under a key
+func (c *Cache) GetFamily(_ context.Context, key string) (entity.FamilyDTO, bool) {
+	value, ok := c.Get(key)
+	if !ok {
+		return entity.FamilyDTO{}, false
+	}
+	family, ok := value.(entity.FamilyDTO)
+	return family, ok
+}
+
+// SetFamily caches a family under a key with the default expiration time
+func (c *Cache) SetFamily(_ context.Context, key string, family entity.FamilyDTO) {
+	c.Set(key, family)
+}
+
+// Repository is a FamilyRepository that caches the families read by ID.
+//
+// GetByID and GetByIDs serve families from the backend and read the families they miss
+// from the repository it wraps, caching them. Every other read goes to the repository,
+// since the cache cannot tell whether a list is complete. A save or deletion of a family
+// invalidates it, whether or not it succeeds, and a save in a unit of work invalidates the
+// family again once the unit ends, since a read during the unit caches the family as it was
+// committed.
+// Families are cached as DTOs, so later changes of a family by the caller do not change the
+// cached family, and per tenant, so that the families of one tenant are never served to
+// another.
+//
+// Saves are checked against the version of the stored family, so a family served from a
+// cache that missed an invalidation, such as the cache of another replica without an
+// invalidation bus, or that was cached by a read that raced a save, fails to save with a
+// concurrency conflict and is invalidated.
+type Repository struct {
+	repo    ports.FamilyRepository
+	backend Backend
+	logger  *logging.ContextLogger
+}
+
+// Ensure Repository implements the FamilyRepository port
+var _ ports.FamilyRepository = (*Repository)(nil)
+
+// NewRepository creates a new repository that caches the families read by ID.
+//
+// Parameters:
+//   - repo: The repository that serves the reads the cache misses, and every write
+//   - backend: Where the families are cached
+//   - logger: Logger for the families that cannot be restored from the cache
+func NewRepository(repo ports.FamilyRepository, backend Backend, logger *logging.ContextLogger) *Repository {
+	return &Repository{repo: repo, backend: backend, logger: logger}
+}
+
+// GetByID retrieves a family from the cache, or from the repository if it is not cached
+func (r *Repository) GetByID(ctx context.Context, id string) (*entity.Family, error) {
+	if fam, ok := r.cached(ctx, id); ok {
+		return fam, nil
+	}
+
+	fam, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.backend.SetFamily(ctx, familyKey(ctx, id), fam.ToDTO())
+	return fam, nil
+}
+
+// Ensure Repository implements ports.FamilyBatchReader
+var _ ports.FamilyBatchReader = (*Repository)(nil)
+
+// GetByIDs retrieves families from the cache, and the families it misses from the
+// repository with one batch read, caching them. The batch read falls back to GetByID if the
+// repository does not implement ports.FamilyBatchReader.
+func (r *Repository) GetByIDs(ctx context.Context, ids []string) ([]*entity.Family, error) {
+	families := make([]*entity.Family, 0, len(ids))
+	var missed []string
+	for _, id := range ids {
+		if fam, ok := r.cached(ctx, id); ok {
+			families = append(families, fam)
+		} else {
+			missed = append(missed, id)
+		}
+	}
+
+	if len(missed) > 0 {
+		read, err := ports.GetFamiliesByID(ctx, r.repo, missed)
+		if err != nil {
+			return nil, err
+		}
+		for _, fam := range read {
+			r.backend.SetFamily(ctx, familyKey(ctx, fam.ID()), fam.ToDTO())
+		}
+		families = append(families, read...)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].ID() < families[j].ID() })
+	return families, nil
+}
+
+// cached returns the family cached with an ID, and counts the read as a hit or a miss. A
+// cached family that cannot be restored is invalidated and missed.
+func (r *Repository) cached(ctx context.Context, id string) (*entity.Family, bool) {
+	key := familyKey(ctx, id)
+	if cached, ok := r.backend.GetFamily(ctx, key); ok {
+		fam, err := entity.FamilyFromDTO(cached)
+		if err == nil {
+			ReadsTotal.WithLabelValues(ResultHit).Inc()
+			return fam, true
+		}
+		r.logger.Warn(ctx, "Failed to restore cached family", zap.String("family_id", id), zap.Error(err))
+		r.backend.Invalidate(ctx, key)
+	}
+
+	ReadsTotal.WithLabelValues(ResultMiss).Inc()
+	return nil, false
+}
+
+// GetAll retrieves all families from the repository
+func (r *Repository) GetAll(ctx context.Context) ([]*entity.Family, error) {
+	return r.repo.GetAll(ctx)
+}
+
+// Save persists a family and invalidates it
+func (r *Repository) Save(ctx context.Context, fam *entity.Family) error {
+	defer r.invalidate(ctx, fam.ID())
+	return r.repo.Save(ctx, fam)
+}
+
+// FindByParentID finds families by parent in the repository
+func (r *Repository) FindByParentID(ctx context.Context, parentID string) ([]*entity.Family, error) {
+	return r.repo.FindByParentID(ctx, parentID)
+}
+
+// FindByChildID finds a family by child in the repository
+func (r *Repository) FindByChildID(ctx context.Context, childID string) (*entity.Family, error) {
+	return r.repo.FindByChildID(ctx, childID)
+}
+
+// FindByExternalID finds families by external ID in the repository
+func (r *Repository) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	return r.repo.FindByExternalID(ctx, system, externalID)
+}
+
+// Find finds the families that match a filter in the repository
+func (r *Repository) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	return r.repo.Find(ctx, filter)
+}
+
+// CountFamilies counts the families in the repository
+func (r *Repository) CountFamilies(ctx context.Context) (int, error) {
+	return r.repo.CountFamilies(ctx)
+}
+
+// CountParents counts the distinct parents in the repository
+func (r *Repository) CountParents(ctx context.Context) (int, error) {
+	return r.repo.CountParents(ctx)
+}
+
+// CountChildren counts the distinct children in the repository
+func (r *Repository) CountChildren(ctx context.Context) (int, error) {
+	return r.repo.CountChildren(ctx)
+}
+
+// Ensure Repository implements ports.FamilyExporter
+var _ ports.FamilyExporter = (*Repository)(nil)
+
+// ExportAll exports all families from the repository without caching them. It falls back
+// to GetAll if the repository does not implement ports.FamilyExporter.
+func (r *Repository) ExportAll(ctx context.Context, fn func(*entity.Family) error) error {
+	if exporter, ok := r.repo.(ports.FamilyExporter); ok {
+		return exporter.ExportAll(ctx, fn)
+	}
+
+	families, err := r.repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, fam := range families {
+		if err := fn(fam); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ensure Repository implements ports.FamilyPager
+var _ ports.FamilyPager = (*Repository)(nil)
+
+// ListFamilies reads a page of families from the repository. It falls back to GetAll if
+// the repository does not implement ports.FamilyPager.
+func (r *Repository) ListFamilies(ctx context.Context, after string, limit int) ([]*entity.Family, error) {
+	if pager, ok := r.repo.(ports.FamilyPager); ok {
+		return pager.ListFamilies(ctx, after, limit)
+	}
+
+	families, err := r.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return query.Page(families, after, limit), nil
+}
+
+// Ensure Repository implements ports.FamilyOwnerReader
+var _ ports.FamilyOwnerReader = (*Repository)(nil)
+
+// CountByOwner counts the families of an owner in the repository. It falls back to Find if
+// the repository does not implement ports.FamilyOwnerReader.
+func (r *Repository) CountByOwner(ctx context.Context, ownerID string, excluded []string) (ports.FamilyCounts, error) {
+	return ports.CountFamiliesByOwner(ctx, r.repo, ownerID, excluded)
+}
+
+// ListOwnedFamilies reads a page of the families of an owner from the repository. It falls
+// back to Find if the repository does not implement ports.FamilyOwnerReader.
+func (r *Repository) ListOwnedFamilies(ctx context.Context, ownerID, after string, limit int) ([]*entity.Family, error) {
+	return ports.ListFamiliesByOwner(ctx, r.repo, ownerID, after, limit)
+}
+
+// Ensure Repository implements ports.FamilyFilterPager
+var _ ports.FamilyFilterPager = (*Repository)(nil)
+
+// FindPage finds a page of the families that match a filter in the repository. It falls
+// back to Find if the repository does not implement ports.FamilyFilterPager.
+func (r *Repository) FindPage(ctx context.Context, filter query.Filter, after string, limit int) ([]*entity.Family, error) {
+	return ports.FindFamiliesPage(ctx, r.repo, filter, after, limit)
+}
+
+// Ensure Repository implements ports.FamilyLocker
+var _ ports.FamilyLocker = (*Repository)(nil)
+
+// LockFamily locks a family in the repository. It does not lock anything if the
+// repository does not implement ports.FamilyLocker.
+func (r *Repository) LockFamily(ctx context.Context, familyID string) (func(), error) {
+	if locker, ok := r.repo.(ports.FamilyLocker); ok {
+		return locker.LockFamily(ctx, familyID)
+	}
+	return func() {}, nil
+}
+
+// Ensure Repository implements ports.FamilyDeleter
+var _ ports.FamilyDeleter = (*Repository)(nil)
+
+// SoftDeleteFamily marks a family as deleted in the repository and invalidates it. It
+// fails if the repository does not implement ports.FamilyDeleter.
+func (r *Repository) SoftDeleteFamily(ctx context.Context, familyID string, deletedAt time.Time) (bool, error) {
+	deleter, ok := r.repo.(ports.FamilyDeleter)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository cannot delete families", "delete", "families", nil)
+	}
+	defer r.invalidate(ctx, familyID)
+	return deleter.SoftDeleteFamily(ctx, familyID, deletedAt)
+}
+
+// HardDeleteFamily removes a family from the repository and invalidates it. It fails if
+// the repository does not implement ports.FamilyDeleter.
+func (r *Repository) HardDeleteFamily(ctx context.Context, familyID string) (bool, error) {
+	deleter, ok := r.repo.(ports.FamilyDeleter)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository cannot delete families", "delete", "families", nil)
+	}
+	defer r.invalidate(ctx, familyID)
+	return deleter.HardDeleteFamily(ctx, familyID)
+}
+
+// Ensure Repository implements ports.FamilyRestorer
+var _ ports.FamilyRestorer = (*Repository)(nil)
+
+// RestoreFamily restores a deleted family in the repository and invalidates it. It fails if
+// the repository does not implement ports.FamilyRestorer.
+func (r *Repository) RestoreFamily(ctx context.Context, familyID string) (bool, error) {
+	restorer, ok := r.repo.(ports.FamilyRestorer)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository cannot restore families", "update", "families", nil)
+	}
+	defer r.invalidate(ctx, familyID)
+	return restorer.RestoreFamily(ctx, familyID)
+}
+
+// PurgeDeletedFamilies purges the deleted families of the repository and invalidates them.
+// It fails if the repository does not implement ports.FamilyRestorer.
+func (r *Repository) PurgeDeletedFamilies(ctx context.Context, deletedBefore time.Time, allTenants bool) ([]string, error) {
+	restorer, ok := r.repo.(ports.FamilyRestorer)
+	if !ok {
+		return nil, errors.NewDatabaseError("the repository cannot purge families", "delete", "families", nil)
+	}
+	ids, err := restorer.PurgeDeletedFamilies(ctx, deletedBefore, allTenants)
+	for _, id := range ids {
+		r.invalidate(ctx, id)
+	}
+	return ids, err
+}
+
+// Ensure Repository implements ports.UnitOfWork
+var _ ports.UnitOfWork = (*Repository)(nil)
+
+// unitKey is the context key of the families changed in a unit of work
+type unitKey struct{ repo *Repository }
+
+// unitChanges are the IDs of the families changed in a unit of work
+type unitChanges struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// Begin begins a unit of work of the repository. It fails with ports.ErrNoUnitOfWork if
+// the repository does not implement ports.UnitOfWork.
+func (r *Repository) Begin(ctx context.Context) (context.Context, error) {
+	unit, ok := r.repo.(ports.UnitOfWork)
+	if !ok {
+		return nil, ports.ErrNoUnitOfWork
+	}
+	unitCtx, err := unit.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(unitCtx, unitKey{r}, &unitChanges{}), nil
+}
+
+// Commit applies the changes of the unit of work of the repository and invalidates the
+// families changed in it
+func (r *Repository) Commit(ctx context.Context) error {
+	unit, ok := r.repo.(ports.UnitOfWork)
+	if !ok {
+		return ports.ErrNoUnitOfWork
+	}
+	defer r.endUnit(ctx)
+	return unit.Commit(ctx)
+}
+
+// Rollback discards the changes of the unit of work of the repository and invalidates the
+// families changed in it
+func (r *Repository) Rollback(ctx context.Context) error {
+	unit, ok := r.repo.(ports.UnitOfWork)
+	if !ok {
+		return nil
+	}
+	defer r.endUnit(ctx)
+	return unit.Rollback(ctx)
+}
+
+// invalidate invalidates a family and records it in the unit of work of the context, if any
+func (r *Repository) invalidate(ctx context.Context, familyID string) {
+	if changes, ok := ctx.Value(unitKey{r}).(*unitChanges); ok {
+		changes.mu.Lock()
+		changes.ids = append(changes.ids, familyID)
+		changes.mu.Unlock()
+	}
+	r.backend.Invalidate(ctx, familyKey(ctx, familyID))
+}
+
+// endUnit invalidates the families changed in the unit of work of the context
+func (r *Repository) endUnit(ctx context.Context) {
+	changes, ok := ctx.Value(unitKey{r}).(*unitChanges)
+	if !ok {
+		return
+	}
+	changes.mu.Lock()
+	keys := make([]string, 0, len(changes.ids))
+	for _, id := range changes.ids {
+		keys = append(keys, familyKey(ctx, id))
+	}
+	changes.ids = nil
+	changes.mu.Unlock()
+	r.backend.Invalidate(ctx, keys...)
+}
+
+// familyKey returns the cache key of a family of the tenant of the context
+func familyKey(ctx context.Context, id string) string {
+	return "repository:family:" + servicecontext.GetTenantID(ctx) + ":" + id
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/sqlite"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// reads returns the number of reads through the cache with a result
+func reads(result string) float64 {
+	return testutil.ToFloat64(ReadsTotal.WithLabelValues(result))
+}
+
+// TestRepository serves the families read by ID from the cache until they are saved
+func TestRepository(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "families.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	store := sqlite.NewSQLiteFamilyRepository(db, logging.NewContextLogger(logger))
+
+	backend, err := NewCache(&config.Config{Cache: config.CacheConfig{
+		Enabled:       true,
+		TTL:           time.Minute,
+		MaxSize:       100,
+		PurgeInterval: time.Minute,
+	}}, logger)
+	require.NoError(t, err)
+	defer backend.Shutdown()
+	repo := NewRepository(store, backend, logging.NewContextLogger(logger))
+
+	ctx := servicecontext.WithTenantID(context.Background(), "tenant-a")
+	families := querytest.Families(t)
+	require.GreaterOrEqual(t, len(families), 2)
+	for _, fam := range families[:2] {
+		require.NoError(t, repo.Save(ctx, fam))
+	}
+	id := families[0].ID()
+
+	t.Run("a family is read from the repository once", func(t *testing.T) {
+		hits, misses := reads(ResultHit), reads(ResultMiss)
+
+		first, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		second, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+
+		assert.Equal(t, first.ToDTO(), second.ToDTO())
+		assert.NotSame(t, first, second)
+		assert.Equal(t, misses+1, reads(ResultMiss))
+		assert.Equal(t, hits+1, reads(ResultHit))
+	})
+
+	t.Run("a family is not served to another tenant", func(t *testing.T) {
+		misses := reads(ResultMiss)
+
+		// The repository keeps the family from the other tenant too
+		_, err := repo.GetByID(servicecontext.WithTenantID(context.Background(), "tenant-b"), id)
+		require.Error(t, err)
+
+		assert.Equal(t, misses+1, reads(ResultMiss))
+	})
+
+	t.Run("a save invalidates the family", func(t *testing.T) {
+		fam, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		version := fam.Version()
+		require.NoError(t, repo.Save(ctx, fam))
+
+		misses := reads(ResultMiss)
+		got, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+
+		assert.Equal(t, version+1, got.Version())
+		assert.Equal(t, misses+1, reads(ResultMiss))
+	})
+
+	t.Run("a unit of work invalidates its saves when it is committed", func(t *testing.T) {
+		fam, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		version := fam.Version()
+
+		unitCtx, err := repo.Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, repo.Save(unitCtx, fam))
+
+		// A read during the unit caches the family as it was committed
+		during, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, version, during.Version())
+
+		require.NoError(t, repo.Commit(unitCtx))
+		got, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, version+1, got.Version())
+	})
+
+	t.Run("a deletion invalidates the family", func(t *testing.T) {
+		_, err := repo.GetByID(ctx, families[1].ID())
+		require.NoError(t, err)
+
+		deleted, err := repo.HardDeleteFamily(ctx, families[1].ID())
+		require.NoError(t, err)
+		require.True(t, deleted)
+
+		got, err := repo.GetByID(ctx, families[1].ID())
+		require.Error(t, err)
+		assert.Nil(t, got)
+	})
+}
@@ -7,14 +7,81 @@
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/redis"
 	"github.com/abitofhelp/servicelib/circuit"
 	"github.com/abitofhelp/servicelib/errors/recovery"
 	"github.com/abitofhelp/servicelib/logging"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
+// Sources of circuit breaker trips
+const (
+	// TripLocal is a trip of the circuit breaker of this replica
+	TripLocal = "local"
+
+	// TripShared is a trip caused by another replica opening its circuit breaker
+	TripShared = "shared"
+)
+
+// tripsTotal counts the times a circuit was opened, by whether this replica or another one opened it
+var tripsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "circuit_breaker_trips_total",
+		Help: "Total number of circuit breaker trips, by circuit and by whether the circuit was opened locally or by another replica",
+	},
+	[]string{"name", "source"},
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(tripsTotal)
+}
+
+// registry holds the circuit breakers of the process, so that their health can be checked
+var registry struct {
+	sync.Mutex
+	breakers []*CircuitBreaker
+}
+
+// OpenCircuits returns the circuits of the process that are not closed, by name, with the
+// time since which each has not closed. A circuit that half-opens to let a trial request
+// through and opens again has not closed. Of circuits with the same name, such as those of
+// the primary and tenant databases, the one that has not closed for longest is returned.
+func OpenCircuits() map[string]time.Time {
+	registry.Lock()
+	breakers := append([]*CircuitBreaker(nil), registry.breakers...)
+	registry.Unlock()
+
+	open := make(map[string]time.Time)
+	for _, cb := range breakers {
+		since := cb.OpenSince()
+		if since.IsZero() {
+			continue
+		}
+		if earliest, ok := open[cb.name]; !ok || since.Before(earliest) {
+			open[cb.name] = since
+		}
+	}
+	return open
+}
+
+// SharedState is circuit state shared by all replicas of the service
+type SharedState interface {
+	// Open marks the named circuit as open for the given duration
+	Open(ctx context.Context, name string, duration time.Duration) error
+
+	// OpenFor returns how much longer the named circuit is open, or zero if it is closed
+	OpenFor(ctx context.Context, name string) (time.Duration, error)
+}
+
 // State represents the state of the circuit breaker
 type State int
 
@@ -29,10 +96,32 @@
 
 // CircuitBreaker implements the circuit breaker pattern to protect against
 // cascading failures when external dependencies are unavailable.
+//
+// With shared state, a circuit opened by this replica is also opened for the other
+// replicas, and a circuit opened by another replica rejects requests here until it expires.
 type CircuitBreaker struct {
 	name   string
 	cb     *circuit.CircuitBreaker
 	logger *zap.Logger
+
+	// lastState is the last observed state of the local circuit breaker
+	lastState atomic.Int32
+
+	// openedAt is the time in Unix nanoseconds at which the local circuit last opened
+	openedAt atomic.Int64
+
+	// trippedAt is the time in Unix nanoseconds at which the circuit opened after last being
+	// closed, locally or by another replica, or zero if it has closed since
+	trippedAt atomic.Int64
+
+	shared        SharedState
+	sleepWindow   time.Duration
+	checkInterval time.Duration
+	retryInterval time.Duration
+
+	mu              sync.Mutex
+	nextCheck       time.Time // Time before which the shared state is not checked again
+	sharedOpenUntil time.Time // Time until which the circuit is open for all replicas
 }
 
 // NewCircuitBreaker creates a new circuit breaker
@@ -68,11 +157,138 @@ func NewCircuitBreaker(name string, cfg *config.CircuitConfig, logger *zap.Logge
 	// Create servicelib circuit breaker
 	serviceCB := circuit.NewCircuitBreaker(circuitConfig, options)
 
-	return &CircuitBreaker{
-		name:   name,
-		cb:     serviceCB,
-		logger: logger,
+	cb := &CircuitBreaker{
+		name:          name,
+		cb:            serviceCB,
+		logger:        logger,
+		sleepWindow:   cfg.SleepWindow,
+		checkInterval: cfg.Shared.CheckInterval,
+		retryInterval: cfg.Shared.Redis.RetryInterval,
+	}
+
+	if cfg.Shared.Enabled {
+		logger.Info("Sharing circuit breaker state through Redis",
+			zap.String("name", name),
+			zap.String("address", cfg.Shared.Redis.Address))
+		cb.shared = redis.NewCircuitState(redis.NewClient(&cfg.Shared.Redis), cfg.Shared.Redis.KeyPrefix)
+	}
+
+	registry.Lock()
+	registry.breakers = append(registry.breakers, cb)
+	registry.Unlock()
+
+	return cb
+}
+
+// sharedOpen reports whether the circuit has been opened by any replica.
+// The shared state is checked at most once per check interval; if it cannot be
+// checked, the circuit is treated as closed and the check is retried later.
+func (cb *CircuitBreaker) sharedOpen(ctx context.Context) bool {
+	if cb.shared == nil {
+		return false
 	}
+
+	now := time.Now()
+	cb.mu.Lock()
+	if now.Before(cb.sharedOpenUntil) {
+		cb.mu.Unlock()
+		return true
+	}
+	if now.Before(cb.nextCheck) {
+		cb.mu.Unlock()
+		return false
+	}
+	cb.nextCheck = now.Add(cb.checkInterval)
+	cb.mu.Unlock()
+
+	remaining, err := cb.shared.OpenFor(ctx, cb.name)
+	if err != nil {
+		if ctx.Err() == nil {
+			cb.mu.Lock()
+			cb.nextCheck = now.Add(cb.retryInterval)
+			cb.mu.Unlock()
+			cb.logger.Warn("Shared circuit state is unavailable, using the local circuit breaker only",
+				zap.String("name", cb.name),
+				zap.Duration("retry_interval", cb.retryInterval),
+				zap.Error(err))
+		}
+		return false
+	}
+	if remaining <= 0 {
+		return false
+	}
+
+	cb.mu.Lock()
+	cb.sharedOpenUntil = now.Add(remaining)
+	cb.mu.Unlock()
+	cb.trippedAt.CompareAndSwap(0, now.UnixNano())
+	tripsTotal.WithLabelValues(cb.name, TripShared).Inc()
+	cb.logger.Warn("Circuit breaker opened by another replica",
+		zap.String("name", cb.name),
+		zap.Duration("open_for", remaining))
+	return true
+}
+
+// observe records a trip of the local circuit breaker, and shares it with the other replicas
+func (cb *CircuitBreaker) observe(ctx context.Context) {
+	state := cb.cb.GetState()
+	if state == circuit.Closed {
+		cb.trippedAt.Store(0)
+	}
+	if previous := circuit.State(cb.lastState.Swap(int32(state))); state != circuit.Open || previous == circuit.Open {
+		return
+	}
+	now := time.Now().UnixNano()
+	cb.openedAt.Store(now)
+	cb.trippedAt.CompareAndSwap(0, now)
+	tripsTotal.WithLabelValues(cb.name, TripLocal).Inc()
+
+	if cb.shared == nil {
+		return
+	}
+	// Requests are rejected locally for the sleep window, so this replica's own
+	// mark in the shared state does not need to be checked
+	cb.mu.Lock()
+	cb.sharedOpenUntil = time.Now().Add(cb.sleepWindow)
+	cb.mu.Unlock()
+
+	// The circuit is shared even if the caller has given up
+	if err := cb.shared.Open(context.WithoutCancel(ctx), cb.name, cb.sleepWindow); err != nil {
+		cb.logger.Warn("Failed to share open circuit with other replicas",
+			zap.String("name", cb.name),
+			zap.Error(err))
+	}
+}
+
+// openError returns the error for a request rejected because the circuit is open: an
+// UpstreamUnavailableError that may be retried once the circuit is expected to close
+func (cb *CircuitBreaker) openError(cause error) error {
+	return domainerrors.NewUpstreamUnavailableError(fmt.Sprintf("circuit breaker %s is open", cb.name), cb.RetryAfter(), cause)
+}
+
+// RetryAfter returns how much longer the circuit is expected to stay open: until the circuit
+// opened by another replica expires, or until the sleep window of the local circuit has
+// passed, after which a trial request is let through. It returns zero if the circuit is closed.
+func (cb *CircuitBreaker) RetryAfter() time.Duration {
+	if cb == nil || cb.cb == nil {
+		return 0
+	}
+
+	now := time.Now()
+	cb.mu.Lock()
+	remaining := cb.sharedOpenUntil.Sub(now)
+	cb.mu.Unlock()
+
+	if cb.cb.GetState() == circuit.Open {
+		openedAt := time.Unix(0, cb.openedAt.Load())
+		if local := openedAt.Add(cb.sleepWindow).Sub(now); local > remaining {
+			remaining = local
+		}
+	}
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
 }
 
 // Execute executes the given function with circuit breaking
@@ -91,12 +307,19 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, operation string, fn func
 		return nil, err
 	}
 
+	if cb.sharedOpen(ctx) {
+		return cb.openError(nil)
+	}
+
 	// Execute the function with circuit breaking
 	_, err := circuit.Execute(ctx, cb.cb, operation, wrapper)
+	cb.observe(ctx)
 
-	// Convert servicelib's circuit breaker error to our format if needed
+	// Convert servicelib's circuit breaker error to our format if needed. The error is
+	// compared by identity: errors.Is matches servicelib errors by code, and this error
+	// shares its code with every internal error.
 	if err == recovery.ErrCircuitBreakerOpen {
-		return fmt.Errorf("circuit breaker %s is open", cb.name)
+		return cb.openError(err)
 	}
 
 	return err
@@ -121,17 +344,23 @@ func (cb *CircuitBreaker) ExecuteWithFallback(ctx context.Context, operation str
 	}
 
 	fallbackWrapper := func(ctx context.Context, err error) (interface{}, error) {
-		// Convert servicelib's circuit breaker error to our format if needed
+		// Convert servicelib's circuit breaker error to our format if needed (compared by
+		// identity, as in Execute)
 		if err == recovery.ErrCircuitBreakerOpen {
-			err = fmt.Errorf("circuit breaker %s is open", cb.name)
+			err = cb.openError(err)
 		}
 
 		fallbackErr := fallback(ctx, err)
 		return nil, fallbackErr
 	}
 
+	if cb.sharedOpen(ctx) {
+		return fallback(ctx, cb.openError(nil))
+	}
+
 	// Execute the function with circuit breaking and fallback
 	_, err := circuit.ExecuteWithFallback(ctx, cb.cb, operation, wrapper, fallbackWrapper)
+	cb.observe(ctx)
 	return err
 }
 
@@ -141,6 +370,13 @@ func (cb *CircuitBreaker) GetState() State {
 		return Closed
 	}
 
+	cb.mu.Lock()
+	sharedOpen := time.Now().Before(cb.sharedOpenUntil)
+	cb.mu.Unlock()
+	if sharedOpen {
+		return Open
+	}
+
 	// Convert servicelib's circuit state to our state
 	switch cb.cb.GetState() {
 	case circuit.Closed:
@@ -154,6 +390,25 @@ func (cb *CircuitBreaker) GetState() State {
 	}
 }
 
+// OpenSince returns the time since which the circuit has not closed: the time at which it
+// opened, locally or by another replica, after last being closed. It returns the zero time
+// if the circuit is closed.
+func (cb *CircuitBreaker) OpenSince() time.Time {
+	if cb == nil || cb.cb == nil {
+		return time.Time{}
+	}
+	if cb.GetState() == Closed {
+		// A circuit that closed without being used since is not open any more
+		cb.trippedAt.Store(0)
+		return time.Time{}
+	}
+	trippedAt := cb.trippedAt.Load()
+	if trippedAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, trippedAt)
+}
+
 // Reset resets the circuit breaker to its initial state
 func (cb *CircuitBreaker) Reset() {
 	if cb == nil || cb.cb == nil {
@@ -161,6 +416,24 @@ func (cb *CircuitBreaker) Reset() {
 	}
 
 	cb.cb.Reset()
+	cb.lastState.Store(int32(circuit.Closed))
+	cb.trippedAt.Store(0)
+
+	cb.mu.Lock()
+	cb.sharedOpenUntil = time.Time{}
+	cb.nextCheck = time.Time{}
+	cb.mu.Unlock()
+}
+
+// IsOpenError reports whether an error reports that an operation was rejected because its
+// circuit is open. Repositories wrap the rejection in errors of their own, so it is
+// recognized by its message.
+func IsOpenError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	return strings.Contains(message, "circuit breaker") && strings.Contains(message, "is open")
 }
 
 // Execute is a package-level function that executes the given function with circuit breaking
@@ -172,6 +445,15 @@ func Execute(ctx context.Context, cb *CircuitBreaker, operation string, fn func(
 		return fn(ctx)
 	}
 
+	if cb.sharedOpen(ctx) {
+		return false, cb.openError(recovery.ErrCircuitBreakerOpen)
+	}
+
 	// Execute the function with circuit breaking using the servicelib circuit.Execute
-	return circuit.Execute(ctx, cb.cb, operation, fn)
+	result, err := circuit.Execute(ctx, cb.cb, operation, fn)
+	cb.observe(ctx)
+	if err == recovery.ErrCircuitBreakerOpen {
+		return result, cb.openError(err)
+	}
+	return result, err
 }
@@ -5,10 +5,14 @@
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
@@ -145,6 +149,14 @@ func TestCircuitBreaker_Execute_Failure(t *testing.T) {
 	})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "circuit breaker test is open")
+
+	// The rejection may be retried once the sleep window has passed
+	code, retryAfter, ok := domainerrors.RetryAfter(err)
+	require.True(t, ok)
+	assert.Equal(t, domainerrors.UpstreamUnavailableCode, code)
+	assert.Greater(t, retryAfter, time.Duration(0))
+	assert.LessOrEqual(t, retryAfter, cfg.SleepWindow)
+	assert.Equal(t, retryAfter.Round(time.Second), cb.RetryAfter().Round(time.Second))
 }
 
 func TestCircuitBreaker_ExecuteWithFallback(t *testing.T) {
@@ -301,3 +313,155 @@ func TestCircuitBreaker_NilSafety(t *testing.T) {
 		cb.Reset()
 	})
 }
+
+// fakeSharedState is shared circuit state kept in memory
+type fakeSharedState struct {
+	mu     sync.Mutex
+	open   map[string]time.Time
+	err    error
+	checks int
+}
+
+func (s *fakeSharedState) Open(ctx context.Context, name string, duration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.open[name] = time.Now().Add(duration)
+	return nil
+}
+
+func (s *fakeSharedState) OpenFor(ctx context.Context, name string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks++
+	if s.err != nil {
+		return 0, s.err
+	}
+	return max(0, time.Until(s.open[name])), nil
+}
+
+func TestCircuitBreaker_SharedState(t *testing.T) {
+	cfg := &config.CircuitConfig{
+		Enabled:         true,
+		Timeout:         5 * time.Second,
+		MaxConcurrent:   100,
+		ErrorThreshold:  0.5,
+		VolumeThreshold: 2,
+		SleepWindow:     time.Minute,
+		Shared: config.SharedCircuitConfig{
+			CheckInterval: 0,
+			Redis:         config.RedisConfig{RetryInterval: time.Minute},
+		},
+	}
+	state := &fakeSharedState{open: map[string]time.Time{}}
+	replica1 := NewCircuitBreaker("shared-test", cfg, zaptest.NewLogger(t))
+	replica1.shared = state
+	replica2 := NewCircuitBreaker("shared-test", cfg, zaptest.NewLogger(t))
+	replica2.shared = state
+	ctx := context.Background()
+	testErr := errors.New("connection refused")
+	local := testutil.ToFloat64(tripsTotal.WithLabelValues("shared-test", TripLocal))
+	shared := testutil.ToFloat64(tripsTotal.WithLabelValues("shared-test", TripShared))
+
+	// The first replica trips its circuit breaker and shares the open circuit
+	for i := 0; i < 3; i++ {
+		_ = replica1.Execute(ctx, "op", func(ctx context.Context) error { return testErr })
+	}
+	assert.Equal(t, Open, replica1.GetState())
+	assert.Equal(t, local+1, testutil.ToFloat64(tripsTotal.WithLabelValues("shared-test", TripLocal)))
+
+	// The second replica backs off without calling the dependency
+	_, err := Execute(ctx, replica2, "op", func(ctx context.Context) (bool, error) {
+		t.Fatal("the function must not run while the shared circuit is open")
+		return false, nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, Open, replica2.GetState())
+	assert.Equal(t, shared+1, testutil.ToFloat64(tripsTotal.WithLabelValues("shared-test", TripShared)))
+
+	// The shared state is not checked again while the circuit is known to be open
+	checks := state.checks
+	err = replica2.ExecuteWithFallback(ctx, "op", func(ctx context.Context) error { return nil }, func(ctx context.Context, err error) error {
+		assert.Contains(t, err.Error(), "circuit breaker shared-test is open")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, checks, state.checks)
+
+	// Once the shared circuit is closed, the second replica calls the dependency again
+	replica2.Reset()
+	state.open = map[string]time.Time{}
+	assert.NoError(t, replica2.Execute(ctx, "op", func(ctx context.Context) error { return nil }))
+	assert.Equal(t, shared+1, testutil.ToFloat64(tripsTotal.WithLabelValues("shared-test", TripShared)))
+}
+
+func TestCircuitBreaker_SharedStateUnavailable(t *testing.T) {
+	cfg := &config.CircuitConfig{
+		Enabled:         true,
+		Timeout:         5 * time.Second,
+		MaxConcurrent:   100,
+		ErrorThreshold:  0.5,
+		VolumeThreshold: 2,
+		SleepWindow:     time.Minute,
+		Shared: config.SharedCircuitConfig{
+			Redis: config.RedisConfig{RetryInterval: time.Minute},
+		},
+	}
+	state := &fakeSharedState{open: map[string]time.Time{}, err: errors.New("connection refused")}
+	cb := NewCircuitBreaker("unavailable-test", cfg, zaptest.NewLogger(t))
+	cb.shared = state
+	ctx := context.Background()
+
+	// The local circuit breaker keeps working, and the shared state is not retried before the retry interval
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, cb.Execute(ctx, "op", func(ctx context.Context) error { return nil }))
+	}
+	assert.Equal(t, Closed, cb.GetState())
+	assert.Equal(t, 1, state.checks)
+}
+
+func TestIsOpenError(t *testing.T) {
+	assert.True(t, IsOpenError(fmt.Errorf("query failed: %w", errors.New("circuit breaker postgres is open"))))
+	assert.True(t, IsOpenError(errors.New("circuit breaker is open")))
+	assert.False(t, IsOpenError(errors.New("connection refused")))
+	assert.False(t, IsOpenError(nil))
+}
+
+func TestCircuitBreaker_OpenSince(t *testing.T) {
+	cfg := &config.CircuitConfig{
+		Enabled:         true,
+		Timeout:         5 * time.Second,
+		MaxConcurrent:   100,
+		ErrorThreshold:  0.5,
+		VolumeThreshold: 2,
+		SleepWindow:     50 * time.Millisecond,
+	}
+	cb := NewCircuitBreaker("open-since-test", cfg, zaptest.NewLogger(t))
+	require.NotNil(t, cb)
+	ctx := context.Background()
+	fail := func(ctx context.Context) error { return errors.New("test error") }
+
+	assert.True(t, cb.OpenSince().IsZero())
+	assert.NotContains(t, OpenCircuits(), "open-since-test")
+
+	for i := 0; i < 3; i++ {
+		_ = cb.Execute(ctx, "test-operation", fail)
+	}
+	since := cb.OpenSince()
+	require.False(t, since.IsZero())
+	assert.Equal(t, since, OpenCircuits()["open-since-test"])
+
+	// A failed trial request opens the circuit again without closing it
+	time.Sleep(100 * time.Millisecond)
+	_ = cb.Execute(ctx, "test-operation", fail)
+	assert.Equal(t, Open, cb.GetState())
+	assert.Equal(t, since, cb.OpenSince())
+
+	// A successful trial request closes it
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, cb.Execute(ctx, "test-operation", func(ctx context.Context) error { return nil }))
+	assert.True(t, cb.OpenSince().IsZero())
+	assert.NotContains(t, OpenCircuits(), "open-since-test")
+}
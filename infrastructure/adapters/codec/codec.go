@@ -0,0 +1,220 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package codec provides serialization codecs for the parents and children blobs
+// that the relational repositories store alongside each family.
+//
+// Two codecs are available:
+//   - json: the original JSON encoding, which remains the default
+//   - protobuf: a compact protocol buffers encoding that is smaller and faster to decode
+//
+// Either codec can be wrapped with zstd or snappy compression for large blobs.
+//
+// Decoding is format-agnostic: binary and compressed blobs begin with a marker byte
+// that can never start a JSON document, so rows written in any format can be read
+// regardless of the configured codec. This allows switching codecs without a data
+// migration; rows are rewritten in the configured format the next time they are saved.
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+)
+
+const (
+	// JSON is the name of the JSON codec
+	JSON = "json"
+
+	// Protobuf is the name of the protocol buffers codec
+	Protobuf = "protobuf"
+)
+
+// binaryMarker is the first byte of every binary-encoded blob. JSON documents begin
+// with '[', 'n', or whitespace, so the marker unambiguously identifies binary data.
+const binaryMarker byte = 0x00
+
+// formatProtobuf identifies the protobuf encoding in the byte following the marker
+const formatProtobuf byte = 0x01
+
+// Codec encodes the parents and children of a family for storage.
+type Codec interface {
+	// Name returns the codec's configuration name
+	Name() string
+
+	// EncodeParents encodes parent DTOs for storage
+	EncodeParents(parents []entity.ParentDTO) ([]byte, error)
+
+	// EncodeChildren encodes child DTOs for storage
+	EncodeChildren(children []entity.ChildDTO) ([]byte, error)
+}
+
+// New returns the codec with the given name. An empty name selects the JSON codec.
+func New(name string) (Codec, error) {
+	switch name {
+	case "", JSON:
+		return jsonCodec{}, nil
+	case Protobuf:
+		return protobufCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported member codec: %s", name)
+	}
+}
+
+// member is the codec-neutral representation of a parent or child
+type member struct {
+	ID            string
+	FirstName     string
+	LastName      string
+	BirthDate     time.Time
+	DeathDate     *time.Time
+	ExternalIDs   map[string]string
+	PreferredName string
+	NameHistory   []entity.NameChange
+	Consents      []entity.Consent // Only children have consents
+}
+
+// parentMember converts a parent to a member
+func parentMember(p entity.ParentDTO) member {
+	return member{
+		ID:            p.ID,
+		FirstName:     p.FirstName,
+		LastName:      p.LastName,
+		BirthDate:     p.BirthDate,
+		DeathDate:     p.DeathDate,
+		ExternalIDs:   p.ExternalIDs,
+		PreferredName: p.PreferredName,
+		NameHistory:   p.NameHistory,
+	}
+}
+
+// childMember converts a child to a member
+func childMember(c entity.ChildDTO) member {
+	return member{
+		ID:            c.ID,
+		FirstName:     c.FirstName,
+		LastName:      c.LastName,
+		BirthDate:     c.BirthDate,
+		DeathDate:     c.DeathDate,
+		ExternalIDs:   c.ExternalIDs,
+		PreferredName: c.PreferredName,
+		NameHistory:   c.NameHistory,
+		Consents:      c.Consents,
+	}
+}
+
+// parent converts a member to a parent
+func (m member) parent() entity.ParentDTO {
+	return entity.ParentDTO{
+		ID:            m.ID,
+		FirstName:     m.FirstName,
+		LastName:      m.LastName,
+		BirthDate:     m.BirthDate,
+		DeathDate:     m.DeathDate,
+		ExternalIDs:   m.ExternalIDs,
+		PreferredName: m.PreferredName,
+		NameHistory:   m.NameHistory,
+	}
+}
+
+// child converts a member to a child
+func (m member) child() entity.ChildDTO {
+	return entity.ChildDTO{
+		ID:            m.ID,
+		FirstName:     m.FirstName,
+		LastName:      m.LastName,
+		BirthDate:     m.BirthDate,
+		DeathDate:     m.DeathDate,
+		ExternalIDs:   m.ExternalIDs,
+		PreferredName: m.PreferredName,
+		NameHistory:   m.NameHistory,
+		Consents:      m.Consents,
+	}
+}
+
+// DecodeParents decodes parents stored in any supported format
+func DecodeParents(data []byte, parents *[]entity.ParentDTO) error {
+	data, err := uncompress(data)
+	if err != nil {
+		return err
+	}
+	if !isBinary(data) {
+		return json.Unmarshal(data, parents)
+	}
+
+	members, err := decodeBinary(data)
+	if err != nil {
+		return err
+	}
+	result := make([]entity.ParentDTO, 0, len(members))
+	for _, m := range members {
+		result = append(result, m.parent())
+	}
+	*parents = result
+	return nil
+}
+
+// DecodeChildren decodes children stored in any supported format
+func DecodeChildren(data []byte, children *[]entity.ChildDTO) error {
+	data, err := uncompress(data)
+	if err != nil {
+		return err
+	}
+	if !isBinary(data) {
+		return json.Unmarshal(data, children)
+	}
+
+	members, err := decodeBinary(data)
+	if err != nil {
+		return err
+	}
+	result := make([]entity.ChildDTO, 0, len(members))
+	for _, m := range members {
+		result = append(result, m.child())
+	}
+	*children = result
+	return nil
+}
+
+// uncompress returns the uncompressed blob if data is compressed, otherwise data itself
+func uncompress(data []byte) ([]byte, error) {
+	if len(data) < 2 || !isBinary(data) || !isCompressed(data[1]) {
+		return data, nil
+	}
+	return decompress(data[1], data[2:])
+}
+
+// isBinary reports whether data was written by a binary codec
+func isBinary(data []byte) bool {
+	return len(data) > 0 && data[0] == binaryMarker
+}
+
+// decodeBinary decodes a binary blob according to its format byte
+func decodeBinary(data []byte) ([]member, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("binary member data is truncated")
+	}
+
+	switch data[1] {
+	case formatProtobuf:
+		return decodeProtobuf(data[2:])
+	default:
+		return nil, fmt.Errorf("unknown binary member format: %d", data[1])
+	}
+}
+
+// jsonCodec encodes members as JSON
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return JSON
+}
+
+func (jsonCodec) EncodeParents(parents []entity.ParentDTO) ([]byte, error) {
+	return json.Marshal(parents)
+}
+
+func (jsonCodec) EncodeChildren(children []entity.ChildDTO) ([]byte, error) {
+	return json.Marshal(children)
+}
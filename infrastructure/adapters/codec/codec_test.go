@@ -0,0 +1,189 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testParents returns parent DTOs with a mix of living and deceased members
+func testParents(n int) []entity.ParentDTO {
+	zone := time.FixedZone("EST", -5*60*60)
+	parents := make([]entity.ParentDTO, 0, n)
+	for i := 0; i < n; i++ {
+		p := entity.ParentDTO{
+			ID:        fmt.Sprintf("00000000-0000-4000-8000-%012d", i),
+			FirstName: "Parent",
+			LastName:  "Doe",
+			BirthDate: time.Date(1970+i%30, time.March, 1+i%28, 0, 0, 0, 0, zone),
+		}
+		if i%2 == 1 {
+			deathDate := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+			p.DeathDate = &deathDate
+		}
+		if i%3 == 0 {
+			p.ExternalIDs = map[string]string{"crm": fmt.Sprintf("CRM-%d", i), "billing": fmt.Sprintf("B-%d", i)}
+			p.PreferredName = "Pat"
+			p.NameHistory = []entity.NameChange{
+				{FirstName: "Parent", LastName: "Roe", EffectiveDate: p.BirthDate},
+				{FirstName: "Parent", LastName: "Doe", EffectiveDate: time.Date(2000, time.May, 6, 0, 0, 0, 0, time.UTC), Reason: entity.NameChangeMarriage},
+			}
+		}
+		parents = append(parents, p)
+	}
+	return parents
+}
+
+// testChildren returns child DTOs for n children, some with current and expired consents
+func testChildren(n int) []entity.ChildDTO {
+	children := make([]entity.ChildDTO, 0, n)
+	for i, p := range testParents(n) {
+		c := parentMember(p).child()
+		if i%3 == 0 {
+			expiredAt := time.Date(2021, time.July, 1, 0, 0, 0, 0, time.UTC)
+			c.Consents = []entity.Consent{
+				{GrantedBy: p.ID, GrantedAt: time.Date(2020, time.July, 1, 9, 30, 0, 0, time.UTC), Scopes: []entity.ConsentScope{entity.ConsentScopeNames}, ExpiredAt: &expiredAt},
+				{GrantedBy: p.ID, GrantedAt: time.Date(2021, time.July, 1, 9, 30, 0, 0, time.UTC), Scopes: entity.ConsentScopes},
+			}
+		}
+		children = append(children, c)
+	}
+	return children
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{"", JSON, Protobuf} {
+		c, err := New(name)
+		require.NoError(t, err)
+		assert.NotNil(t, c)
+	}
+
+	_, err := New("xml")
+	assert.Error(t, err)
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	for _, name := range []string{JSON, Protobuf} {
+		t.Run(name, func(t *testing.T) {
+			c, err := New(name)
+			require.NoError(t, err)
+
+			parents := testParents(2)
+			data, err := c.EncodeParents(parents)
+			require.NoError(t, err)
+			var decodedParents []entity.ParentDTO
+			require.NoError(t, DecodeParents(data, &decodedParents))
+			require.Len(t, decodedParents, 2)
+			for i := range parents {
+				assert.Equal(t, parents[i].ID, decodedParents[i].ID)
+				assert.True(t, parents[i].BirthDate.Equal(decodedParents[i].BirthDate))
+				assert.Equal(t, parents[i].DeathDate == nil, decodedParents[i].DeathDate == nil)
+				assert.Equal(t, parents[i].ExternalIDs, decodedParents[i].ExternalIDs)
+				assert.Equal(t, parents[i].PreferredName, decodedParents[i].PreferredName)
+				require.Len(t, decodedParents[i].NameHistory, len(parents[i].NameHistory))
+				for j, change := range parents[i].NameHistory {
+					assert.Equal(t, change.LastName, decodedParents[i].NameHistory[j].LastName)
+					assert.Equal(t, change.Reason, decodedParents[i].NameHistory[j].Reason)
+					assert.True(t, change.EffectiveDate.Equal(decodedParents[i].NameHistory[j].EffectiveDate))
+				}
+			}
+
+			children := testChildren(3)
+			data, err = c.EncodeChildren(children)
+			require.NoError(t, err)
+			var decodedChildren []entity.ChildDTO
+			require.NoError(t, DecodeChildren(data, &decodedChildren))
+			require.Len(t, decodedChildren, 3)
+			for i := range children {
+				require.Len(t, decodedChildren[i].Consents, len(children[i].Consents))
+				for j, consent := range children[i].Consents {
+					decoded := decodedChildren[i].Consents[j]
+					assert.Equal(t, consent.GrantedBy, decoded.GrantedBy)
+					assert.True(t, consent.GrantedAt.Equal(decoded.GrantedAt))
+					assert.Equal(t, consent.Scopes, decoded.Scopes)
+					assert.Equal(t, consent.ExpiredAt == nil, decoded.ExpiredAt == nil)
+				}
+			}
+		})
+	}
+}
+
+func TestProtobuf_PreservesZone(t *testing.T) {
+	c, _ := New(Protobuf)
+	parents := testParents(1)
+
+	data, err := c.EncodeParents(parents)
+	require.NoError(t, err)
+	var decoded []entity.ParentDTO
+	require.NoError(t, DecodeParents(data, &decoded))
+
+	_, offset := decoded[0].BirthDate.Zone()
+	assert.Equal(t, -5*60*60, offset)
+}
+
+func TestDecode_DualRead(t *testing.T) {
+	// Rows written before the protobuf codec was enabled are plain JSON
+	legacy, err := json.Marshal(testChildren(2))
+	require.NoError(t, err)
+
+	var children []entity.ChildDTO
+	require.NoError(t, DecodeChildren(legacy, &children))
+	assert.Len(t, children, 2)
+
+	var empty []entity.ChildDTO
+	c, _ := New(Protobuf)
+	data, err := c.EncodeChildren(nil)
+	require.NoError(t, err)
+	require.NoError(t, DecodeChildren(data, &empty))
+	assert.NotNil(t, empty)
+	assert.Empty(t, empty)
+}
+
+func TestDecode_InvalidBinary(t *testing.T) {
+	var parents []entity.ParentDTO
+	assert.Error(t, DecodeParents([]byte{binaryMarker}, &parents))
+	assert.Error(t, DecodeParents([]byte{binaryMarker, 0x7f}, &parents))
+	assert.Error(t, DecodeParents([]byte{binaryMarker, formatProtobuf, 0x0a, 0x05}, &parents))
+}
+
+// BenchmarkEncodeChildren compares the encode time and encoded size of each codec
+// for a large family. Run with: go test -bench . ./infrastructure/adapters/codec
+func BenchmarkEncodeChildren(b *testing.B) {
+	children := testChildren(50)
+	for _, name := range []string{JSON, Protobuf} {
+		b.Run(name, func(b *testing.B) {
+			c, _ := New(name)
+			var data []byte
+			for i := 0; i < b.N; i++ {
+				data, _ = c.EncodeChildren(children)
+			}
+			b.ReportMetric(float64(len(data)), "bytes/blob")
+		})
+	}
+}
+
+// BenchmarkDecodeChildren compares the decode time of each codec for a large family
+func BenchmarkDecodeChildren(b *testing.B) {
+	children := testChildren(50)
+	for _, name := range []string{JSON, Protobuf} {
+		b.Run(name, func(b *testing.B) {
+			c, _ := New(name)
+			data, _ := c.EncodeChildren(children)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var decoded []entity.ChildDTO
+				if err := DecodeChildren(data, &decoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(data)), "bytes/blob")
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package codec
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Zstd is the name of the zstd compression algorithm
+	Zstd = "zstd"
+
+	// Snappy is the name of the snappy compression algorithm
+	Snappy = "snappy"
+)
+
+// Format bytes for compressed blobs. The compressed payload is itself a blob in
+// any supported format (JSON or binary), so compression composes with every codec.
+const (
+	formatZstd   byte = 0x02
+	formatSnappy byte = 0x03
+)
+
+// maxDecompressedSize bounds the size of a decompressed blob to protect against corrupt data
+const maxDecompressedSize = 64 << 20
+
+var (
+	// compressionRatio records the ratio of compressed to uncompressed size for each compressed blob
+	compressionRatio = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "member_blob_compression_ratio",
+			Help:    "Ratio of compressed to uncompressed size of compressed member blobs",
+			Buckets: []float64{0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1},
+		},
+		[]string{"algorithm"},
+	)
+
+	// compressionDuration records the time spent compressing and decompressing member blobs
+	compressionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "member_blob_compression_duration_seconds",
+			Help:    "Time spent compressing and decompressing member blobs",
+			Buckets: prometheus.ExponentialBuckets(0.000001, 4, 10),
+		},
+		[]string{"algorithm", "operation"},
+	)
+
+	// compressionTotal counts member blob encodings by compression outcome
+	compressionTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "member_blob_compression_total",
+			Help: "Total number of member blobs encoded, by compression outcome",
+		},
+		[]string{"algorithm", "result"},
+	)
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(compressionRatio, compressionDuration, compressionTotal)
+}
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+// zstdCoders returns the shared zstd encoder and decoder, which are safe for concurrent use
+func zstdCoders() (*zstd.Encoder, *zstd.Decoder) {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil)
+		zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+	})
+	return zstdEncoder, zstdDecoder
+}
+
+// WithCompression returns a codec that compresses the output of c with the given
+// algorithm when it is at least threshold bytes long. Blobs that do not shrink are
+// stored uncompressed.
+func WithCompression(c Codec, algorithm string, threshold int) (Codec, error) {
+	var format byte
+	switch algorithm {
+	case Zstd:
+		format = formatZstd
+	case Snappy:
+		format = formatSnappy
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm: %s", algorithm)
+	}
+	return compressingCodec{inner: c, algorithm: algorithm, format: format, threshold: threshold}, nil
+}
+
+// compressingCodec compresses the blobs produced by another codec
+type compressingCodec struct {
+	inner     Codec
+	algorithm string
+	format    byte
+	threshold int
+}
+
+func (c compressingCodec) Name() string {
+	return c.inner.Name() + "+" + c.algorithm
+}
+
+func (c compressingCodec) EncodeParents(parents []entity.ParentDTO) ([]byte, error) {
+	data, err := c.inner.EncodeParents(parents)
+	if err != nil {
+		return nil, err
+	}
+	return c.compress(data), nil
+}
+
+func (c compressingCodec) EncodeChildren(children []entity.ChildDTO) ([]byte, error) {
+	data, err := c.inner.EncodeChildren(children)
+	if err != nil {
+		return nil, err
+	}
+	return c.compress(data), nil
+}
+
+// compress compresses data if it meets the threshold and compression reduces its size
+func (c compressingCodec) compress(data []byte) []byte {
+	if len(data) < c.threshold {
+		compressionTotal.WithLabelValues(c.algorithm, "below_threshold").Inc()
+		return data
+	}
+
+	start := time.Now()
+	out := []byte{binaryMarker, c.format}
+	switch c.format {
+	case formatZstd:
+		encoder, _ := zstdCoders()
+		out = encoder.EncodeAll(data, out)
+	case formatSnappy:
+		out = append(out, snappy.Encode(nil, data)...)
+	}
+	compressionDuration.WithLabelValues(c.algorithm, "compress").Observe(time.Since(start).Seconds())
+
+	if len(out) >= len(data) {
+		compressionTotal.WithLabelValues(c.algorithm, "not_beneficial").Inc()
+		return data
+	}
+
+	compressionTotal.WithLabelValues(c.algorithm, "compressed").Inc()
+	compressionRatio.WithLabelValues(c.algorithm).Observe(float64(len(out)) / float64(len(data)))
+	return out
+}
+
+// decompress returns the payload of a compressed blob
+func decompress(format byte, payload []byte) ([]byte, error) {
+	start := time.Now()
+
+	var algorithm string
+	var data []byte
+	var err error
+	switch format {
+	case formatZstd:
+		algorithm = Zstd
+		_, decoder := zstdCoders()
+		data, err = decoder.DecodeAll(payload, nil)
+	case formatSnappy:
+		algorithm = Snappy
+		var n int
+		if n, err = snappy.DecodedLen(payload); err == nil && n > maxDecompressedSize {
+			err = fmt.Errorf("decompressed size %d exceeds limit", n)
+		}
+		if err == nil {
+			data, err = snappy.Decode(nil, payload)
+		}
+	default:
+		return nil, fmt.Errorf("unknown compression format: %d", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s member data: %w", algorithm, err)
+	}
+
+	compressionDuration.WithLabelValues(algorithm, "decompress").Observe(time.Since(start).Seconds())
+	return data, nil
+}
+
+// isCompressed reports whether a binary format byte identifies a compressed blob
+func isCompressed(format byte) bool {
+	return format == formatZstd || format == formatSnappy
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package codec
+
+import (
+	"testing"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCompression_RoundTrip(t *testing.T) {
+	for _, inner := range []string{JSON, Protobuf} {
+		for _, algorithm := range []string{Zstd, Snappy} {
+			t.Run(inner+"+"+algorithm, func(t *testing.T) {
+				base, _ := New(inner)
+				c, err := WithCompression(base, algorithm, 256)
+				require.NoError(t, err)
+
+				children := testChildren(50)
+				uncompressed, err := base.EncodeChildren(children)
+				require.NoError(t, err)
+				data, err := c.EncodeChildren(children)
+				require.NoError(t, err)
+
+				assert.True(t, isCompressed(data[1]))
+				assert.Less(t, len(data), len(uncompressed))
+
+				var decoded []entity.ChildDTO
+				require.NoError(t, DecodeChildren(data, &decoded))
+				assert.Len(t, decoded, 50)
+			})
+		}
+	}
+}
+
+func TestWithCompression_BelowThreshold(t *testing.T) {
+	base, _ := New(JSON)
+	c, err := WithCompression(base, Zstd, 1<<20)
+	require.NoError(t, err)
+
+	parents := testParents(1)
+	data, err := c.EncodeParents(parents)
+	require.NoError(t, err)
+	assert.Equal(t, byte('['), data[0])
+
+	var decoded []entity.ParentDTO
+	require.NoError(t, DecodeParents(data, &decoded))
+	assert.Len(t, decoded, 1)
+}
+
+func TestWithCompression_UnsupportedAlgorithm(t *testing.T) {
+	base, _ := New(JSON)
+	_, err := WithCompression(base, "gzip", 0)
+	assert.Error(t, err)
+}
+
+func TestDecode_CorruptCompressed(t *testing.T) {
+	var children []entity.ChildDTO
+	assert.Error(t, DecodeChildren([]byte{binaryMarker, formatZstd, 0x01, 0x02}, &children))
+	assert.Error(t, DecodeChildren([]byte{binaryMarker, formatSnappy, 0xff, 0xff, 0xff, 0xff, 0x0f}, &children))
+}
+
+// BenchmarkCompressChildren measures the CPU cost and size reduction of each algorithm
+// on a large protobuf-encoded family
+func BenchmarkCompressChildren(b *testing.B) {
+	children := testChildren(200)
+	base, _ := New(Protobuf)
+	for _, algorithm := range []string{Zstd, Snappy} {
+		b.Run(algorithm, func(b *testing.B) {
+			c, _ := WithCompression(base, algorithm, 0)
+			var data []byte
+			for i := 0; i < b.N; i++ {
+				data, _ = c.EncodeChildren(children)
+			}
+			b.ReportMetric(float64(len(data)), "bytes/blob")
+		})
+	}
+}
@@ -0,0 +1,461 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package codec
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The protobuf codec writes the wire format of the following schema directly,
+// so no generated code is required:
+//
+//	message Members {
+//	  repeated Member members = 1;
+//	}
+//
+//	message Member {
+//	  string id = 1;
+//	  string first_name = 2;
+//	  string last_name = 3;
+//	  bytes birth_date = 4; // time.Time.MarshalBinary, which preserves the zone offset
+//	  bytes death_date = 5; // absent when the member is alive
+//	  map<string, string> external_ids = 6;
+//	  string preferred_name = 7; // absent when the member has none
+//	  repeated NameChange name_history = 8;
+//	  repeated Consent consents = 9; // children only
+//	}
+//
+//	message NameChange {
+//	  string first_name = 1;
+//	  string last_name = 2;
+//	  bytes effective_date = 3; // time.Time.MarshalBinary
+//	  string reason = 4; // absent for the name from birth
+//	}
+//
+//	message Consent {
+//	  string granted_by = 1;
+//	  bytes granted_at = 2; // time.Time.MarshalBinary
+//	  repeated string scopes = 3;
+//	  bytes expired_at = 4; // absent while the consent is current
+//	}
+//
+// Unknown fields are skipped when decoding, so fields can be added in the future
+// without breaking existing readers.
+const (
+	fieldMembers = 1
+
+	fieldID            = 1
+	fieldFirstName     = 2
+	fieldLastName      = 3
+	fieldBirthDate     = 4
+	fieldDeathDate     = 5
+	fieldExternalIDs   = 6
+	fieldPreferredName = 7
+	fieldNameHistory   = 8
+	fieldConsents      = 9
+
+	// Fields of the map entry messages of external_ids
+	fieldMapKey   = 1
+	fieldMapValue = 2
+
+	// Fields of the NameChange messages of name_history
+	fieldNameFirstName     = 1
+	fieldNameLastName      = 2
+	fieldNameEffectiveDate = 3
+	fieldNameReason        = 4
+
+	// Fields of the Consent messages of consents
+	fieldConsentGrantedBy = 1
+	fieldConsentGrantedAt = 2
+	fieldConsentScopes    = 3
+	fieldConsentExpiredAt = 4
+)
+
+// protobufCodec encodes members in the protocol buffers wire format
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string {
+	return Protobuf
+}
+
+func (protobufCodec) EncodeParents(parents []entity.ParentDTO) ([]byte, error) {
+	members := make([]member, 0, len(parents))
+	for _, p := range parents {
+		members = append(members, parentMember(p))
+	}
+	return encodeProtobuf(members)
+}
+
+func (protobufCodec) EncodeChildren(children []entity.ChildDTO) ([]byte, error) {
+	members := make([]member, 0, len(children))
+	for _, c := range children {
+		members = append(members, childMember(c))
+	}
+	return encodeProtobuf(members)
+}
+
+// encodeProtobuf encodes members as a marked Members message
+func encodeProtobuf(members []member) ([]byte, error) {
+	buf := []byte{binaryMarker, formatProtobuf}
+	for _, m := range members {
+		msg, err := encodeMember(m)
+		if err != nil {
+			return nil, err
+		}
+		buf = protowire.AppendTag(buf, fieldMembers, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, msg)
+	}
+	return buf, nil
+}
+
+// encodeMember encodes a single Member message
+func encodeMember(m member) ([]byte, error) {
+	var buf []byte
+	buf = protowire.AppendTag(buf, fieldID, protowire.BytesType)
+	buf = protowire.AppendString(buf, m.ID)
+	buf = protowire.AppendTag(buf, fieldFirstName, protowire.BytesType)
+	buf = protowire.AppendString(buf, m.FirstName)
+	buf = protowire.AppendTag(buf, fieldLastName, protowire.BytesType)
+	buf = protowire.AppendString(buf, m.LastName)
+
+	birthDate, err := m.BirthDate.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode birth date of member %s: %w", m.ID, err)
+	}
+	buf = protowire.AppendTag(buf, fieldBirthDate, protowire.BytesType)
+	buf = protowire.AppendBytes(buf, birthDate)
+
+	if m.DeathDate != nil {
+		deathDate, err := m.DeathDate.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode death date of member %s: %w", m.ID, err)
+		}
+		buf = protowire.AppendTag(buf, fieldDeathDate, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, deathDate)
+	}
+
+	// Map entries are written in key order so that encoding is deterministic
+	systems := make([]string, 0, len(m.ExternalIDs))
+	for system := range m.ExternalIDs {
+		systems = append(systems, system)
+	}
+	sort.Strings(systems)
+	for _, system := range systems {
+		var entry []byte
+		entry = protowire.AppendTag(entry, fieldMapKey, protowire.BytesType)
+		entry = protowire.AppendString(entry, system)
+		entry = protowire.AppendTag(entry, fieldMapValue, protowire.BytesType)
+		entry = protowire.AppendString(entry, m.ExternalIDs[system])
+		buf = protowire.AppendTag(buf, fieldExternalIDs, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, entry)
+	}
+
+	if m.PreferredName != "" {
+		buf = protowire.AppendTag(buf, fieldPreferredName, protowire.BytesType)
+		buf = protowire.AppendString(buf, m.PreferredName)
+	}
+
+	for _, change := range m.NameHistory {
+		msg, err := encodeNameChange(change)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode name history of member %s: %w", m.ID, err)
+		}
+		buf = protowire.AppendTag(buf, fieldNameHistory, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, msg)
+	}
+
+	for _, consent := range m.Consents {
+		msg, err := encodeConsent(consent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode consents of member %s: %w", m.ID, err)
+		}
+		buf = protowire.AppendTag(buf, fieldConsents, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, msg)
+	}
+
+	return buf, nil
+}
+
+// encodeNameChange encodes a single NameChange message
+func encodeNameChange(change entity.NameChange) ([]byte, error) {
+	var buf []byte
+	buf = protowire.AppendTag(buf, fieldNameFirstName, protowire.BytesType)
+	buf = protowire.AppendString(buf, change.FirstName)
+	buf = protowire.AppendTag(buf, fieldNameLastName, protowire.BytesType)
+	buf = protowire.AppendString(buf, change.LastName)
+
+	effectiveDate, err := change.EffectiveDate.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	buf = protowire.AppendTag(buf, fieldNameEffectiveDate, protowire.BytesType)
+	buf = protowire.AppendBytes(buf, effectiveDate)
+
+	if change.Reason != "" {
+		buf = protowire.AppendTag(buf, fieldNameReason, protowire.BytesType)
+		buf = protowire.AppendString(buf, string(change.Reason))
+	}
+	return buf, nil
+}
+
+// encodeConsent encodes a single Consent message
+func encodeConsent(consent entity.Consent) ([]byte, error) {
+	var buf []byte
+	buf = protowire.AppendTag(buf, fieldConsentGrantedBy, protowire.BytesType)
+	buf = protowire.AppendString(buf, consent.GrantedBy)
+
+	grantedAt, err := consent.GrantedAt.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	buf = protowire.AppendTag(buf, fieldConsentGrantedAt, protowire.BytesType)
+	buf = protowire.AppendBytes(buf, grantedAt)
+
+	for _, scope := range consent.Scopes {
+		buf = protowire.AppendTag(buf, fieldConsentScopes, protowire.BytesType)
+		buf = protowire.AppendString(buf, string(scope))
+	}
+
+	if consent.ExpiredAt != nil {
+		expiredAt, err := consent.ExpiredAt.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf = protowire.AppendTag(buf, fieldConsentExpiredAt, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, expiredAt)
+	}
+	return buf, nil
+}
+
+// decodeProtobuf decodes a Members message
+func decodeProtobuf(data []byte) ([]member, error) {
+	var members []member
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if num != fieldMembers || typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+
+		msg, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		m, err := decodeMember(msg)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	if members == nil {
+		members = []member{}
+	}
+	return members, nil
+}
+
+// decodeMember decodes a single Member message
+func decodeMember(data []byte) (member, error) {
+	var m member
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return m, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return m, protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return m, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		switch num {
+		case fieldID:
+			m.ID = string(value)
+		case fieldFirstName:
+			m.FirstName = string(value)
+		case fieldLastName:
+			m.LastName = string(value)
+		case fieldBirthDate:
+			if err := m.BirthDate.UnmarshalBinary(value); err != nil {
+				return m, fmt.Errorf("failed to decode birth date: %w", err)
+			}
+		case fieldDeathDate:
+			deathDate := new(time.Time)
+			if err := deathDate.UnmarshalBinary(value); err != nil {
+				return m, fmt.Errorf("failed to decode death date: %w", err)
+			}
+			m.DeathDate = deathDate
+		case fieldExternalIDs:
+			system, id, err := decodeMapEntry(value)
+			if err != nil {
+				return m, fmt.Errorf("failed to decode external ID: %w", err)
+			}
+			if m.ExternalIDs == nil {
+				m.ExternalIDs = make(map[string]string)
+			}
+			m.ExternalIDs[system] = id
+		case fieldPreferredName:
+			m.PreferredName = string(value)
+		case fieldNameHistory:
+			change, err := decodeNameChange(value)
+			if err != nil {
+				return m, fmt.Errorf("failed to decode name change: %w", err)
+			}
+			m.NameHistory = append(m.NameHistory, change)
+		case fieldConsents:
+			consent, err := decodeConsent(value)
+			if err != nil {
+				return m, fmt.Errorf("failed to decode consent: %w", err)
+			}
+			m.Consents = append(m.Consents, consent)
+		}
+	}
+	return m, nil
+}
+
+// decodeMapEntry decodes a map<string, string> entry message
+func decodeMapEntry(data []byte) (key, value string, err error) {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return "", "", protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return "", "", protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+
+		v, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return "", "", protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		switch num {
+		case fieldMapKey:
+			key = string(v)
+		case fieldMapValue:
+			value = string(v)
+		}
+	}
+	return key, value, nil
+}
+
+// decodeNameChange decodes a single NameChange message
+func decodeNameChange(data []byte) (entity.NameChange, error) {
+	var change entity.NameChange
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return change, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return change, protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return change, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		switch num {
+		case fieldNameFirstName:
+			change.FirstName = string(value)
+		case fieldNameLastName:
+			change.LastName = string(value)
+		case fieldNameEffectiveDate:
+			if err := change.EffectiveDate.UnmarshalBinary(value); err != nil {
+				return change, fmt.Errorf("failed to decode effective date: %w", err)
+			}
+		case fieldNameReason:
+			change.Reason = entity.NameChangeReason(value)
+		}
+	}
+	return change, nil
+}
+
+// decodeConsent decodes a single Consent message
+func decodeConsent(data []byte) (entity.Consent, error) {
+	var consent entity.Consent
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return consent, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return consent, protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return consent, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		switch num {
+		case fieldConsentGrantedBy:
+			consent.GrantedBy = string(value)
+		case fieldConsentGrantedAt:
+			if err := consent.GrantedAt.UnmarshalBinary(value); err != nil {
+				return consent, fmt.Errorf("failed to decode grant time: %w", err)
+			}
+		case fieldConsentScopes:
+			consent.Scopes = append(consent.Scopes, entity.ConsentScope(value))
+		case fieldConsentExpiredAt:
+			expiredAt := new(time.Time)
+			if err := expiredAt.UnmarshalBinary(value); err != nil {
+				return consent, fmt.Errorf("failed to decode expiry time: %w", err)
+			}
+			consent.ExpiredAt = expiredAt
+		}
+	}
+	return consent, nil
+}
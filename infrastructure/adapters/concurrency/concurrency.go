@@ -0,0 +1,136 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package concurrency limits the amount of concurrent work of each class, such as the
+// GraphQL resolvers of a field class or the repository operations of a fan-out, so that
+// large queries cannot stampede the database.
+//
+// Work waits for a slot of its class before it starts, and the time it waits is recorded
+// in the concurrency_queue_seconds metric, so that limits that are too tight show up as
+// queueing time rather than as unexplained latency.
+package concurrency
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+)
+
+// Classes of concurrent work
+const (
+	// ClassRoot is the resolution of root query fields
+	ClassRoot = "root"
+	// ClassField is the resolution of object fields with resolvers, such as the fields of each family in a list
+	ClassField = "field"
+	// ClassRepository is the repository operations of a fan-out
+	ClassRepository = "repository"
+)
+
+// QueueSeconds measures how long work waited for a slot of its class
+var QueueSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "concurrency_queue_seconds",
+		Help:    "Time work waited for a concurrency slot, by class",
+		Buckets: []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+	},
+	[]string{"class"},
+)
+
+func init() {
+	prometheus.MustRegister(QueueSeconds)
+}
+
+// Limiter limits the concurrent work of a class. A nil Limiter, or one with a limit
+// of 0, does not limit work. A Limiter is safe for concurrent use.
+type Limiter struct {
+	class string
+	slots chan struct{}
+}
+
+// NewLimiter creates a new limiter
+//
+// Parameters:
+//   - class: The class of the work, used to label the queueing time
+//   - limit: The maximum number of concurrent units of work, or 0 for no limit
+//
+// Returns:
+//   - A new limiter
+func NewLimiter(class string, limit int) *Limiter {
+	l := &Limiter{class: class}
+	if limit > 0 {
+		l.slots = make(chan struct{}, limit)
+	}
+	return l
+}
+
+// Limit returns the maximum number of concurrent units of work, or 0 if work is not limited
+func (l *Limiter) Limit() int {
+	if l == nil {
+		return 0
+	}
+	return cap(l.slots)
+}
+
+// Acquire waits for a slot, recording the time spent waiting.
+// Each successful Acquire must be followed by a Release.
+//
+// Returns:
+//   - An error if the context was done before a slot became free
+func (l *Limiter) Acquire(ctx context.Context) error {
+	return l.acquire(ctx, time.Now())
+}
+
+// Release frees a slot acquired by Acquire
+func (l *Limiter) Release() {
+	if l == nil || l.slots == nil {
+		return
+	}
+	<-l.slots
+}
+
+// acquire waits for a slot for work that was queued at the given time
+func (l *Limiter) acquire(ctx context.Context, queued time.Time) error {
+	if l == nil || l.slots == nil {
+		return nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			QueueSeconds.WithLabelValues(l.class).Observe(time.Since(queued).Seconds())
+			return ctx.Err()
+		}
+	}
+	QueueSeconds.WithLabelValues(l.class).Observe(time.Since(queued).Seconds())
+	return nil
+}
+
+// ForEach calls fn for each index in [0, n) concurrently, running at most as many calls
+// at once as the limiter allows, across all callers sharing the limiter.
+// The context passed to fn is cancelled once a call fails.
+//
+// Returns:
+//   - The first error returned by fn, or the context error if the context was done first
+func ForEach(ctx context.Context, l *Limiter, n int, fn func(ctx context.Context, i int) error) error {
+	g, ctx := errgroup.WithContext(ctx)
+	if limit := l.Limit(); limit > 0 {
+		// Do not start more goroutines than can run at once
+		g.SetLimit(limit)
+	}
+
+	for i := 0; i < n; i++ {
+		queued := time.Now()
+		g.Go(func() error {
+			if err := l.acquire(ctx, queued); err != nil {
+				return err
+			}
+			defer l.Release()
+			return fn(ctx, i)
+		})
+	}
+	return g.Wait()
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter("test-limiter", 1)
+	assert.Equal(t, 1, l.Limit())
+	before := testutil.CollectAndCount(QueueSeconds)
+
+	require.NoError(t, l.Acquire(context.Background()))
+
+	// A second unit of work waits until the first releases its slot
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Acquire(ctx), context.DeadlineExceeded)
+
+	l.Release()
+	require.NoError(t, l.Acquire(context.Background()))
+	l.Release()
+	assert.Equal(t, before+1, testutil.CollectAndCount(QueueSeconds), "queueing time is recorded by class")
+
+	// Limiters without a limit never wait
+	var unlimited *Limiter
+	assert.Equal(t, 0, unlimited.Limit())
+	require.NoError(t, unlimited.Acquire(ctx))
+	unlimited.Release()
+	require.NoError(t, NewLimiter("test-unlimited", 0).Acquire(ctx))
+}
+
+func TestForEach(t *testing.T) {
+	l := NewLimiter("test-foreach", 3)
+
+	var running, peak, calls atomic.Int32
+	err := ForEach(context.Background(), l, 20, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(20), calls.Load())
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+
+	// The first error is returned and cancels the remaining calls
+	failure := errors.New("failed")
+	err = ForEach(context.Background(), l, 10, func(ctx context.Context, i int) error {
+		if i == 0 {
+			return failure
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, failure)
+}
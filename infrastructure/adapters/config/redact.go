@@ -0,0 +1,86 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// Redacted replaces secret values in the redacted configuration
+const Redacted = "REDACTED"
+
+// secretKeys are the configuration keys whose values are secrets
+var secretKeys = map[string]bool{
+	"password":    true,
+	"secret":      true,
+	"secret_key":  true,
+	"webhook_url": true,
+}
+
+// dsnPassword matches the password of a key/value connection string, such as a PostgreSQL DSN
+var dsnPassword = regexp.MustCompile(`(?i)(password=)('[^']*'|\S+)`)
+
+// Redact returns the configuration as a map of its configuration keys, with durations as
+// duration strings and the values of secrets replaced by REDACTED: passwords, secrets, secret
+// keys, and webhook URLs, which carry their tokens, and the passwords of connection strings.
+func (c *Config) Redact() map[string]interface{} {
+	return redactValue(reflect.ValueOf(*c), "").(map[string]interface{})
+}
+
+// redactValue returns a configuration value with its secrets redacted
+func redactValue(v reflect.Value, key string) interface{} {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		m := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			name := fieldName(v.Type().Field(i))
+			if name == "" {
+				continue
+			}
+			m[name] = redactValue(v.Field(i), name)
+		}
+		return m
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			name := iter.Key().String()
+			m[name] = redactValue(iter.Value(), name)
+		}
+		return m
+	case reflect.Slice:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = redactValue(v.Index(i), key)
+		}
+		return items
+	case reflect.String:
+		return redactString(v.String(), key)
+	default:
+		return v.Interface()
+	}
+}
+
+// redactString redacts a string value, which is a secret if its key names one
+func redactString(s, key string) string {
+	if s == "" {
+		return s
+	}
+	if secretKeys[key] {
+		return Redacted
+	}
+	if u, err := url.Parse(s); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), Redacted)
+			return u.String()
+		}
+	}
+	return dsnPassword.ReplaceAllString(s, "${1}"+Redacted)
+}
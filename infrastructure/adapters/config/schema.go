@@ -0,0 +1,595 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaID is the identifier of the configuration JSON Schema
+const SchemaID = "https://github.com/abitofhelp/family-service/config.schema.json"
+
+// durationPattern matches the duration strings accepted by time.ParseDuration
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Schema returns a JSON Schema (draft 2020-12) describing the configuration file format.
+// Property names, types, and constraints are derived from the Config struct and its
+// validation tags, descriptions from fieldDescriptions, and defaults from getDefaultsMap.
+// Every property is optional because missing values are filled in from the defaults.
+func Schema() map[string]interface{} {
+	schema := structSchema(reflect.TypeOf(Config{}), "", getDefaultsMap())
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = SchemaID
+	schema["title"] = "Family Service configuration"
+	return schema
+}
+
+// SchemaJSON returns the configuration JSON Schema as indented JSON
+func SchemaJSON() ([]byte, error) {
+	data, err := json.MarshalIndent(Schema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// structSchema returns the schema of an object with a property for each mapstructure field
+func structSchema(t reflect.Type, path string, defaults map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := fieldName(field)
+		if name == "" {
+			continue
+		}
+		fieldPath := joinPath(path, name)
+		properties[name] = fieldSchema(field.Type, field.Tag.Get("validate"), fieldPath, defaults)
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if description, ok := fieldDescriptions[path]; ok {
+		schema["description"] = description
+	}
+	return schema
+}
+
+// fieldSchema returns the schema of a configuration value of the given type
+func fieldSchema(t reflect.Type, rules, path string, defaults map[string]interface{}) map[string]interface{} {
+	var schema map[string]interface{}
+	switch {
+	case t == durationType:
+		// Durations are strings such as "30s", or integers in seconds
+		schema = map[string]interface{}{
+			"type":    []string{"string", "integer"},
+			"pattern": durationPattern,
+			"minimum": 0,
+		}
+		rules = ""
+	case t.Kind() == reflect.Struct:
+		return structSchema(t, path, defaults)
+	case t.Kind() == reflect.Slice:
+		// Rules after dive apply to the items
+		var itemRules string
+		if before, after, ok := strings.Cut(rules, "dive"); ok {
+			rules, itemRules = strings.TrimSuffix(before, ","), strings.TrimPrefix(after, ",")
+		}
+		schema = map[string]interface{}{
+			"type":  "array",
+			"items": fieldSchema(t.Elem(), itemRules, path+"[]", defaults),
+		}
+	case t.Kind() == reflect.Map:
+		schema = map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": fieldSchema(t.Elem(), "", path+".*", defaults),
+		}
+	case t.Kind() == reflect.Bool:
+		schema = map[string]interface{}{"type": "boolean"}
+	case t.Kind() == reflect.String:
+		schema = map[string]interface{}{"type": "string"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema = map[string]interface{}{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema = map[string]interface{}{"type": "number"}
+	default:
+		schema = map[string]interface{}{}
+	}
+
+	applyRules(schema, t, rules)
+	if description, ok := fieldDescriptions[path]; ok {
+		schema["description"] = description
+	}
+	if value, ok := defaults[path]; ok {
+		schema["default"] = value
+	}
+	return schema
+}
+
+// applyRules adds the constraints of the validation rules that can be expressed in JSON Schema
+func applyRules(schema map[string]interface{}, t reflect.Type, rules string) {
+	if rules == "" {
+		return
+	}
+
+	omitEmpty := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "omitempty":
+			omitEmpty = true
+		case "oneof":
+			values := strings.Fields(param)
+			if omitEmpty {
+				values = append([]string{""}, values...)
+			}
+			schema["enum"] = values
+		case "min", "max", "gt", "lt":
+			number, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			if t.Kind() == reflect.String {
+				if name == "min" {
+					schema["minLength"] = number
+				} else if name == "max" {
+					schema["maxLength"] = number
+				}
+				continue
+			}
+			keyword := map[string]string{"min": "minimum", "max": "maximum", "gt": "exclusiveMinimum", "lt": "exclusiveMaximum"}[name]
+			schema[keyword] = number
+		case "numeric":
+			schema["pattern"] = `^[0-9]+$`
+		case "startswith":
+			if omitEmpty {
+				schema["pattern"] = "^($|" + regexpQuote(param) + ")"
+			} else {
+				schema["pattern"] = "^" + regexpQuote(param)
+			}
+		case "uri":
+			schema["format"] = "uri"
+		}
+	}
+}
+
+// regexpQuote escapes the characters of s that are special in regular expressions
+func regexpQuote(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\.+*?()|[]{}^$`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// fieldName returns the configuration key of a struct field
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// joinPath appends a key to a dotted configuration path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// fieldDescriptions describes the configuration keys, indexed by their dotted path
+var fieldDescriptions = map[string]string{
+	"": "Configuration of the Family Service. Every key is optional; missing keys take their default values, and APP_ prefixed environment variables override the file.",
+
+	"admin":                  "Admin API exposing runbook actions to operators; every action requires the ADMIN role and its ops scope, and is audited",
+	"admin.enabled":          "Whether the admin API is served",
+	"admin.path":             "Path of the admin API, which is not prefixed by the routes",
+	"admin.export_retention": "How long exports are kept on disk, in the spill directory, for their interrupted downloads to be resumed with Range requests; 0 does not keep them",
+
+	"alerts":                    "Notification of operators of critical conditions, deduplicated and rate limited",
+	"alerts.enabled":            "Whether critical conditions are evaluated and their alerts sent",
+	"alerts.service":            "Name of the service in the alerts",
+	"alerts.check_interval":     "Interval at which the conditions are evaluated",
+	"alerts.repeat_interval":    "Interval at which an alert that keeps firing is sent again; 0 sends it once",
+	"alerts.rate_limit":         "Maximum number of notifications sent per minute; 0 does not limit them",
+	"alerts.timeout":            "Timeout for sending a notification to an adapter",
+	"alerts.circuit_open_after": "Time a circuit breaker must stay open, without closing, before its alert fires; 0 alerts as soon as it opens",
+	"alerts.integrity_interval": "Interval at which the integrity of the stored families is checked, alerting while corrupt families are found; 0 disables the checks",
+	"alerts.alertmanager":       "Prometheus Alertmanager the alerts are posted to",
+	"alerts.alertmanager.url":   "Base URL of the Alertmanager, such as http://alertmanager:9093; empty does not post alerts",
+	"alerts.slack":              "Slack channel the alerts are posted to",
+	"alerts.slack.webhook_url":  "URL of the Slack incoming webhook, which is a secret and may be read from the environment as ${VAR}; empty does not post alerts",
+	"alerts.webhook":            "Webhook the alerts are posted to as JSON",
+	"alerts.webhook.url":        "URL of the webhook; empty does not post alerts",
+	"alerts.webhook.secret":     "Key of the HMAC-SHA256 signature of notifications, sent in the X-Signature-256 header, which may be read from the environment as ${VAR}; empty sends no signature",
+
+	"app":         "Application settings",
+	"app.version": "Version of the service reported in logs and telemetry",
+
+	"audit":                           "Audit trail of domain events and admin actions",
+	"audit.journal":                   "Journal the audit entries are appended to as JSON lines, in segment files",
+	"audit.journal.enabled":           "Whether audit entries are appended to the journal, in addition to being logged",
+	"audit.journal.directory":         "Directory of the segments of the journal",
+	"audit.journal.segment_bytes":     "Size in bytes at which a segment is closed and a new one opened; segments are also closed at the start of a UTC day",
+	"audit.retention":                 "Retention policy of the journal, which prunes expired segments",
+	"audit.retention.enabled":         "Whether the retention policy is enforced",
+	"audit.retention.max_age":         "Age of the last entry of a segment at which it expires; 0 does not limit the age",
+	"audit.retention.max_bytes":       "Size in bytes of the journal beyond which its oldest segments expire; 0 does not limit the size",
+	"audit.retention.interval":        "Interval at which expired segments are pruned",
+	"audit.retention.legal_holds":     "IDs of the families under legal hold, whose entries are kept in held segments instead of being pruned",
+	"audit.retention.archive":         "Object storage expired segments are copied to, compressed with gzip, before they are deleted",
+	"audit.retention.archive.url":     "URL of the archive: file:// for a directory, or http(s):// for an object storage endpoint accepting PUT requests; empty deletes expired segments",
+	"audit.retention.archive.token":   "Bearer token of an HTTP archive, which is a secret and may be read from the environment as ${VAR}; empty sends no token",
+	"audit.retention.archive.timeout": "Timeout for uploading a segment to an HTTP archive",
+
+	"auth":                      "Authentication settings",
+	"auth.mode":                 "How tokens are validated: local (with the JWT secret key) or oidc (with the signing keys of a remote OIDC or OAuth2 authorization server)",
+	"auth.oidc_timeout":         "Timeout for requests to the OIDC provider",
+	"auth.jwt":                  "JSON Web Token settings",
+	"auth.jwt.secret_key":       "Secret key used to sign and verify tokens; must be overridden in production",
+	"auth.jwt.secret_key_file":  "File the secret key is read from at startup and when the keys are rotated, in place of secret_key; empty uses secret_key",
+	"auth.jwt.rotation_grace":   "Time tokens signed with the previous secret key are still accepted after the keys are rotated",
+	"auth.jwt.token_duration":   "Lifetime of issued tokens",
+	"auth.jwt.issuer":           "Issuer claim of issued and accepted tokens",
+	"auth.accept_coarse_scopes": "Whether coarse scopes (READ, WRITE, CREATE, DELETE) and resources are accepted in place of per-operation scopes such as family:create",
+
+	"auth.oidc":                     "Validation of tokens issued by a remote authorization server, used when auth.mode is oidc",
+	"auth.oidc.issuer":              "Issuer URL of the authorization server, which must match the issuer claim of tokens and serve the OIDC discovery document unless jwks_url is set",
+	"auth.oidc.audience":            "Audience claim tokens must include; empty does not check the audience",
+	"auth.oidc.jwks_url":            "URL of the JSON Web Key Set the signing keys are read from; empty discovers it from the issuer",
+	"auth.oidc.refresh_interval":    "Interval at which the signing keys are read again; keys are also read again when a token is signed with an unknown key",
+	"auth.oidc.accept_local_tokens": "Whether tokens signed with the JWT secret key are accepted as well, for development; never accepted when APP_ENV is prod or production",
+
+	"auth.playground_login":           "Sign-in to the playground and documentation portal with OIDC (authorization code with PKCE); never served when APP_ENV is prod or production",
+	"auth.playground_login.enabled":   "Whether the playground and documentation portal offer sign-in with the identity provider",
+	"auth.playground_login.issuer":    "Issuer URL of the OIDC identity provider, whose discovery document and token endpoint must allow cross-origin requests",
+	"auth.playground_login.client_id": "Public client ID registered with the identity provider, with /login/callback of the service as a redirect URI",
+	"auth.playground_login.scopes":    "Scopes requested when signing in",
+
+	"cache":                                   "In-memory cache settings",
+	"cache.enabled":                           "Whether results are cached",
+	"cache.read_through":                      "Whether the families read by ID are cached in front of the repository and invalidated when they are saved or deleted",
+	"cache.backend":                           "Where the families read by ID are cached: memory (per replica) or redis (shared by all replicas)",
+	"cache.redis":                             "Redis caching the families read by ID in redis mode",
+	"cache.redis.address":                     "Host and port of the Redis server",
+	"cache.redis.password":                    "Password for authenticating with Redis; empty disables authentication",
+	"cache.redis.db":                          "Index of the Redis database",
+	"cache.redis.key_prefix":                  "Prefix of the keys of the cached families",
+	"cache.redis.timeout":                     "Timeout for connecting to Redis and for each command",
+	"cache.redis.pool_size":                   "Maximum number of connections open to Redis at once",
+	"cache.redis.pool_timeout":                "Time a command waits for a free connection to Redis when pool_size connections are in use",
+	"cache.redis.retry_interval":              "Unused; a failing Redis is skipped by the circuit breaker",
+	"cache.ttl":                               "Time to live of cached entries",
+	"cache.max_size":                          "Maximum number of cached entries",
+	"cache.purge_interval":                    "Interval at which expired entries are purged",
+	"cache.invalidation":                      "Broadcasting of cache invalidations between replicas through Redis",
+	"cache.invalidation.enabled":              "Whether a family changed by one replica is removed from the caches of all replicas",
+	"cache.invalidation.channel":              "Redis channel on which invalidated cache keys are published",
+	"cache.invalidation.redis":                "Redis carrying the invalidations",
+	"cache.invalidation.redis.address":        "Host and port of the Redis server",
+	"cache.invalidation.redis.password":       "Password for authenticating with Redis; empty disables authentication",
+	"cache.invalidation.redis.db":             "Index of the Redis database",
+	"cache.invalidation.redis.key_prefix":     "Unused; invalidations are published on the channel",
+	"cache.invalidation.redis.timeout":        "Timeout for connecting to Redis and for each command",
+	"cache.invalidation.redis.pool_size":      "Maximum number of connections open to Redis at once for publishing",
+	"cache.invalidation.redis.pool_timeout":   "Time a command waits for a free connection to Redis when pool_size connections are in use",
+	"cache.invalidation.redis.retry_interval": "Time to wait after the subscription fails before subscribing again",
+
+	"canary":                              "Canary releases of new domain rules to part of the traffic",
+	"canary.enabled":                      "Whether requests can be routed to the canary strategies",
+	"canary.header":                       "Request header naming the strategy of a request",
+	"canary.strategies":                   "Alternate domain rules by strategy name",
+	"canary.strategies.*":                 "Domain rules of the strategy",
+	"canary.strategies.*.policy":          "Data plausibility and privacy policies of the strategy, replacing the default policies",
+	"canary.strategies.*.policy.age":      "Parent-child age plausibility policy of the strategy",
+	"canary.strategies.*.policy.age.mode": "How violations are handled: off, warn (log and count), or block (reject)",
+	"canary.strategies.*.policy.age.min_parent_age_at_birth": "Minimum plausible age of a parent at a child's birth",
+	"canary.strategies.*.policy.consent":                     "Consent policy of the strategy",
+	"canary.strategies.*.policy.consent.enabled":             "Whether the external IDs and names of minors are withheld unless covered by a current consent",
+	"canary.strategies.*.policy.consent.minor_age":           "Age below which a child is a minor",
+	"canary.strategies.*.policy.consent.max_age":             "How long a consent stays current after it is granted",
+	"canary.strategies.*.policy.consent.check_interval":      "Ignored; stale consents are flagged at the check interval of the default consent policy",
+	"canary.strategies.*.policy.duplicates":                  "Duplicate family detection of the strategy",
+	"canary.strategies.*.policy.duplicates.action":           "How duplicates are handled: off, warn (log and count), reject, or link (create and record a family_duplicate_suspected event)",
+	"canary.tenants":             "Strategy by tenant ID, for requests without the header",
+	"canary.tenants.*":           "Name of the strategy of the tenant",
+	"canary.percentage":          "Percentage of the remaining requests routed to the percentage strategy",
+	"canary.percentage_strategy": "Name of the strategy of the percentage of requests",
+
+	"capture":             "Capture of sanitized GraphQL operations for debugging, downloadable through the admin API",
+	"capture.enabled":     "Whether queries and mutations are captured",
+	"capture.capacity":    "Number of the most recent operations kept",
+	"capture.errors_only": "Whether only the operations whose response has errors are captured",
+	"capture.mask_fields": "Names of the arguments, variables, and input fields, at any depth, whose values are masked; dates keep their year and other values are replaced by a pseudonym",
+
+	"circuit":                             "Circuit breaker settings for database operations",
+	"circuit.enabled":                     "Whether database operations run behind the circuit breaker; when disabled, they bypass it entirely",
+	"circuit.timeout":                     "Timeout of a single operation",
+	"circuit.max_concurrent":              "Maximum number of concurrent operations",
+	"circuit.error_threshold":             "Fraction of failed operations that opens the circuit",
+	"circuit.volume_threshold":            "Minimum number of operations before the circuit can open",
+	"circuit.sleep_window":                "Time the circuit stays open before operations are retried",
+	"circuit.shared":                      "Sharing of open circuits between replicas through Redis",
+	"circuit.shared.enabled":              "Whether a circuit opened by one replica is opened by all replicas",
+	"circuit.shared.check_interval":       "How often each replica checks Redis for circuits opened by other replicas",
+	"circuit.shared.redis":                "Redis holding the shared circuit state",
+	"circuit.shared.redis.address":        "Host and port of the Redis server",
+	"circuit.shared.redis.password":       "Password for authenticating with Redis; empty disables authentication",
+	"circuit.shared.redis.db":             "Index of the Redis database",
+	"circuit.shared.redis.key_prefix":     "Prefix of the keys of the open circuits",
+	"circuit.shared.redis.timeout":        "Timeout for connecting to Redis and for each command",
+	"circuit.shared.redis.pool_size":      "Maximum number of connections open to Redis at once",
+	"circuit.shared.redis.pool_timeout":   "Time a command waits for a free connection to Redis when pool_size connections are in use",
+	"circuit.shared.redis.retry_interval": "Time to ignore the shared state after Redis fails, before trying Redis again",
+
+	"concurrency":            "Limits on concurrent work of each class, to keep large queries from stampeding the database",
+	"concurrency.enabled":    "Whether concurrent work is limited",
+	"concurrency.root":       "Maximum number of root query fields resolved at once across all requests; 0 is unlimited",
+	"concurrency.field":      "Maximum number of object fields with resolvers, such as the fields of each family in a list, resolved at once; 0 is unlimited",
+	"concurrency.repository": "Maximum number of repository operations of a fan-out, such as writing back repaired families, run at once; 0 is unlimited. Fan-outs run one operation at a time while limits are disabled, and always on SQLite, which has a single writer",
+
+	"contracts":         "Check of the schema at startup against the operations of the consumers in the contract registry",
+	"contracts.dir":     "Directory of the contract registry, with a subdirectory of operation documents per consumer; empty skips the check",
+	"contracts.enforce": "Whether the service refuses to start when the schema breaks a consumer contract or the registry cannot be read",
+
+	"database":                                      "Database settings",
+	"database.type":                                 "Repository backend used by the service",
+	"database.compression":                          "Compression of the member blobs of large families (SQLite only)",
+	"database.compression.enabled":                  "Whether large member blobs are compressed; rejected unless database.type is sqlite",
+	"database.compression.algorithm":                "Compression algorithm: zstd is smaller, snappy is faster",
+	"database.compression.threshold_bytes":          "Minimum size in bytes of a blob to be compressed",
+	"database.shadow":                               "Shadowing of reads to a second repository to validate parity before switching backends",
+	"database.shadow.enabled":                       "Whether reads are shadowed to the shadow repository",
+	"database.shadow.type":                          "Backend of the shadow repository",
+	"database.shadow.uri":                           "Connection URI or DSN of the shadow repository; ${ENV_VAR} placeholders are expanded",
+	"database.shadow.sample_rate":                   "Fraction of reads sent to the shadow repository",
+	"database.shadow.diff_sample_rate":              "Fraction of mismatches whose differences are logged",
+	"database.shadow.max_concurrency":               "Maximum number of concurrent shadow reads; further reads are not shadowed",
+	"database.shadow.timeout":                       "Timeout of a shadow read or write",
+	"database.shadow.dual_write":                    "Whether saves are also written to the shadow repository after they succeed on the primary",
+	"database.stale_reads":                          "Serving of queries from snapshots of recently read families while the circuit breaker of the database is open",
+	"database.stale_reads.enabled":                  "Whether queries are served from snapshots, flagged as stale in the staleness response extension, while the circuit is open; writes still fail fast",
+	"database.stale_reads.max_staleness":            "Maximum age of a snapshot that is served",
+	"database.stale_reads.max_entries":              "Maximum number of family snapshots kept in memory; the least recently used are evicted",
+	"database.memory_budget":                        "Bound on the memory used to assemble the results of reads of many families",
+	"database.memory_budget.enabled":                "Whether the memory used to assemble results is bounded",
+	"database.memory_budget.max_bytes":              "Estimated size in bytes of the families held in memory for one result; larger interactive results fail with RESULT_TOO_LARGE, and exports spill the rest to disk. 0 is unlimited",
+	"database.memory_budget.spill_dir":              "Directory of the spill files of exports; empty uses the system temporary directory",
+	"database.locking":                              "Serialization of the changes of a family",
+	"database.locking.enabled":                      "Whether changes of a family wait for the changes of the family already in progress",
+	"database.locking.timeout":                      "Maximum time a change waits for the lock of its family before failing with FAMILY_LOCKED",
+	"database.locking.lease":                        "Time after which a MongoDB family lock that was not released expires",
+	"database.tenancy":                              "Routing of tenants to dedicated databases",
+	"database.tenancy.enabled":                      "Whether the requests of the tenants listed in tenants use their dedicated databases",
+	"database.tenancy.datasources":                  "Dedicated databases by datasource name",
+	"database.tenancy.datasources.*":                "Dedicated database, opened when one of its tenants first uses it",
+	"database.tenancy.datasources.*.type":           "Backend of the dedicated database",
+	"database.tenancy.datasources.*.uri":            "Connection URI or DSN of the dedicated database; ${ENV_VAR} placeholders are expanded",
+	"database.tenancy.tenants":                      "Datasource by tenant ID; other tenants use the primary database",
+	"database.tenancy.tenants.*":                    "Name of the datasource of the tenant",
+	"database.tenancy.idle_timeout":                 "Time without use after which a dedicated database is closed; 0 keeps it open",
+	"database.tenancy.health_interval":              "Interval between health checks of the open dedicated databases; 0 disables them",
+	"database.tenancy.health_timeout":               "Timeout of a health check of a dedicated database",
+	"database.deletion":                             "Retention of soft deleted families",
+	"database.deletion.retention":                   "Time after its deletion when a soft deleted family is purged for good; 0 keeps deleted families",
+	"database.deletion.purge_interval":              "Interval between purges of the deleted families past their retention; 0 disables the purges",
+	"database.mongodb":                              "MongoDB settings",
+	"database.mongodb.uri":                          "MongoDB connection URI; ${ENV_VAR} placeholders are expanded",
+	"database.mongodb.connection_timeout":           "Timeout for connecting to MongoDB",
+	"database.mongodb.disconnect_timeout":           "Timeout for disconnecting from MongoDB",
+	"database.mongodb.index_timeout":                "Timeout for creating indexes",
+	"database.mongodb.migration_timeout":            "Timeout for schema migrations",
+	"database.mongodb.ping_timeout":                 "Timeout for health check pings",
+	"database.mongodb.read_repair":                  "Read repair of inconsistent legacy documents",
+	"database.mongodb.read_repair.enabled":          "Whether legacy documents are repaired instead of failing the read",
+	"database.mongodb.read_repair.write_back":       "Whether repaired families are saved in their canonical form",
+	"database.mongodb.batch":                        "Batch size of reads of many families, which bounds the documents held in memory at once",
+	"database.mongodb.batch.adaptive":               "Whether the batch size is tuned after every batch to the size and read latency of the documents",
+	"database.mongodb.batch.initial":                "Number of documents per batch that reads start with",
+	"database.mongodb.batch.min":                    "Smallest batch size that adaptive tuning may choose",
+	"database.mongodb.batch.max":                    "Largest batch size that adaptive tuning may choose",
+	"database.mongodb.batch.target_bytes":           "Total size in bytes of the documents of a batch that adaptive tuning aims for",
+	"database.mongodb.batch.target_latency":         "Time to read a batch that adaptive tuning aims for",
+	"database.postgres":                             "PostgreSQL settings",
+	"database.postgres.dsn":                         "PostgreSQL connection string; ${ENV_VAR} placeholders are expanded",
+	"database.postgres.migration_timeout":           "Timeout for schema migrations",
+	"database.postgres.row_level_security":          "Whether row-level security restricts queries to the caller's families",
+	"database.postgres.maintenance_role":            "Role with BYPASSRLS that jobs over every tenant, such as the purge of deleted families, switch to under row-level security",
+	"database.postgres.read_repair":                 "Read repair of inconsistent legacy rows",
+	"database.postgres.read_repair.enabled":         "Whether legacy rows are repaired instead of failing the read",
+	"database.postgres.read_repair.write_back":      "Whether repaired families are saved in their canonical form",
+	"database.postgres.analytics":                   "Connection pool of analytics work, such as reports and exports",
+	"database.postgres.analytics.enabled":           "Whether analytics work reads through its own read-only pool instead of the primary pool",
+	"database.postgres.analytics.max_conns":         "Maximum number of connections of the analytics pool",
+	"database.postgres.analytics.statement_timeout": "Timeout after which analytics statements are cancelled; 0 disables it",
+	"database.postgres.analytics.work_mem":          "PostgreSQL work_mem of analytics sessions, such as 64MB; empty keeps the server default",
+	"database.sqlite":                               "SQLite settings",
+	"database.sqlite.uri":                           "SQLite database URI; ${ENV_VAR} placeholders are expanded",
+	"database.sqlite.connection_timeout":            "Timeout for opening the database",
+	"database.sqlite.disconnect_timeout":            "Timeout for closing the database",
+	"database.sqlite.migration_timeout":             "Timeout for schema migrations",
+	"database.sqlite.ping_timeout":                  "Timeout for health check pings",
+	"database.sqlite.member_codec":                  "Encoding of new member blobs; rows in either format are always readable",
+	"database.sqlite.layout":                        "Storage of parents and children: json blobs in the families table, or normalized parents, children, and family_members tables",
+	"database.sqlite.read_repair":                   "Read repair of inconsistent legacy rows",
+	"database.sqlite.read_repair.enabled":           "Whether legacy rows are repaired instead of failing the read",
+	"database.sqlite.read_repair.write_back":        "Whether repaired families are saved in their canonical form",
+
+	"external_ids":                   "Validation rules for the IDs that client systems assign to families and members",
+	"external_ids.allowed_systems":   "External systems that may be referenced; empty allows any system",
+	"external_ids.allowed_systems[]": "Name of an external system",
+	"external_ids.patterns":          "Regular expression that the IDs of each system must match",
+	"external_ids.patterns.*":        "Regular expression for the IDs of the system",
+	"external_ids.max_length":        "Maximum length of an external ID",
+
+	"features":                  "Feature flags",
+	"features.use_generics":     "Enables the generic repository and service implementations",
+	"features.legacy_mutations": "Serves the deprecated mutations that report expected failures as GraphQL errors",
+
+	"hedge":         "Hedged reads of families by ID, which send a second attempt when the first is slow, so that a slow database node does not set the tail latency (MongoDB and PostgreSQL)",
+	"hedge.enabled": "Whether reads of families by ID are hedged",
+	"hedge.delay":   "Time to wait for the first attempt before sending the second; set near the p95 latency of reads, so that only the slowest reads are hedged",
+
+	"kafka":                   "Publication of domain events to a Kafka topic through a Kafka REST Proxy, for the data lake",
+	"kafka.enabled":           "Whether domain events are published to Kafka",
+	"kafka.url":               "Base URL of the Kafka REST Proxy, such as http://kafka-rest:8082",
+	"kafka.topic":             "Topic the events are published to; events are keyed by family ID, so that the events of a family stay in order",
+	"kafka.timeout":           "Timeout of a request to the proxy",
+	"kafka.username":          "User of the HTTP basic authentication to the proxy",
+	"kafka.password":          "Password of the HTTP basic authentication to the proxy; use a ${VAR} placeholder rather than a literal value",
+	"kafka.outbox":            "Delivery of domain events through the outbox of the primary database, so that no event is lost if the service stops before publishing it",
+	"kafka.outbox.enabled":    "Whether events are stored in the outbox in the same transaction as their changes and relayed to Kafka, at least once, instead of published after the changes. Needs a primary database that runs transactions and no tenants with dedicated databases",
+	"kafka.outbox.interval":   "Time between runs of the relay, which publishes the pending events of the outbox",
+	"kafka.outbox.batch_size": "Number of outbox events that the relay reads at a time",
+
+	"log":                       "Logging settings",
+	"log.level":                 "Minimum level of logged messages",
+	"log.development":           "Whether development logging (human-readable, with stack traces) is used",
+	"log.format":                "Format of log entries: json or console (default: console in development, json otherwise)",
+	"log.sampling":              "Sampling of repeated log entries",
+	"log.sampling.enabled":      "Whether repeated log entries are sampled",
+	"log.sampling.initial":      "Entries with the same level and message written per tick before sampling starts",
+	"log.sampling.thereafter":   "After the initial entries, every Nth entry with the same level and message is written per tick",
+	"log.sampling.tick":         "Interval over which repeated entries are counted",
+	"log.components":            "Log levels of components that override log.level",
+	"log.components.repository": "Log level of the repositories",
+	"log.components.resolver":   "Log level of the GraphQL server and resolvers",
+	"log.components.auth":       "Log level of authentication",
+
+	"mutations":                          "Bounds of mutations",
+	"mutations.timeout":                  "Maximum duration of a mutation; a mutation that runs longer fails with MUTATION_TIMED_OUT and its completed saves are rolled back; 0 disables the bound",
+	"names":                              "Formatting of the displayName and sortName fields of parents and children",
+	"names.default_locale":               "Locale whose name templates apply when the Accept-Language of a request matches none",
+	"names.templates":                    "Name templates by language tag, added to or replacing the built-in templates of en, hu, ja, ko, and zh",
+	"names.templates.*":                  "Name templates of the locale, using the placeholders {first}, {last}, and {given}",
+	"names.templates.*.display":          "Template of the displayName field, such as \"{given} {last}\"",
+	"names.templates.*.sort":             "Template of the sortName field, such as \"{last}, {first}\"",
+	"policy":                             "Data plausibility and privacy policies",
+	"policy.age":                         "Parent-child age plausibility policy",
+	"policy.age.mode":                    "How violations are handled: off, warn (log and count), or block (reject)",
+	"policy.age.min_parent_age_at_birth": "Minimum plausible age of a parent at a child's birth",
+	"policy.consent":                     "Consent policy for the data of minors",
+	"policy.consent.enabled":             "Whether the external IDs and names of minors are withheld unless covered by a current consent",
+	"policy.consent.minor_age":           "Age below which a child is a minor",
+	"policy.consent.max_age":             "How long a consent stays current after it is granted",
+	"policy.consent.check_interval":      "How often consents older than the maximum age are flagged as expired (0 disables the check)",
+	"policy.duplicates":                  "Detection of new families with the same members as stored families",
+	"policy.duplicates.action":           "How duplicates are handled: off, warn (log and count), reject, or link (create and record a family_duplicate_suspected event)",
+
+	"rate":                      "Rate limiting of database operations",
+	"rate.enabled":              "Whether database operations are rate limited; when disabled, they bypass the rate limiter entirely",
+	"rate.requests_per_second":  "Sustained number of operations per second",
+	"rate.burst_size":           "Maximum number of operations in a burst",
+	"rate.mode":                 "Where the token bucket is kept: local (per replica) or redis (shared by all replicas)",
+	"rate.redis":                "Redis holding the shared token bucket in redis mode",
+	"rate.redis.address":        "Host and port of the Redis server",
+	"rate.redis.password":       "Password for authenticating with Redis; empty disables authentication",
+	"rate.redis.db":             "Index of the Redis database",
+	"rate.redis.key_prefix":     "Prefix of the keys of the token buckets",
+	"rate.redis.timeout":        "Timeout for connecting to Redis and for each command",
+	"rate.redis.pool_size":      "Maximum number of connections open to Redis at once",
+	"rate.redis.pool_timeout":   "Time a command waits for a free connection to Redis when pool_size connections are in use",
+	"rate.redis.retry_interval": "Time to use the local token bucket after Redis fails, before trying Redis again",
+
+	"redaction":           "Redaction of response fields by the roles of the caller",
+	"redaction.roles":     "Fields redacted for each role; a field is redacted only if every role of the caller redacts it",
+	"redaction.roles.*":   "Fields redacted for the role",
+	"redaction.roles.*[]": "Schema coordinate of a field, such as Parent.deathDate; nullable fields resolve to null and non-null strings to [REDACTED]",
+
+	"reporting":                "Aggregate family reports, without personal data, for statistical agencies",
+	"reporting.enabled":        "Whether reports are generated on a schedule",
+	"reporting.directory":      "Directory the report files are written to",
+	"reporting.formats":        "Formats in which each report is written",
+	"reporting.formats[]":      "Report format: csv or json",
+	"reporting.interval":       "How often a report is generated",
+	"reporting.retention":      "How long report files are kept (0 keeps them forever)",
+	"reporting.min_cell_size":  "Smallest number of families a group may have and still be reported; smaller groups are suppressed (0 disables suppression)",
+	"reporting.minor_age":      "Age below which a child is counted as a dependent",
+	"reporting.region":         "How the region of a family is determined",
+	"reporting.region.system":  "External ID system whose IDs identify the region of a family; empty reports every family in an unknown region",
+	"reporting.region.pattern": "Regular expression whose first submatch, or whole match, in the external ID is the region",
+
+	"rest":         "REST/JSON gateway to the family operations; every endpoint is authorized as its GraphQL operation",
+	"rest.enabled": "Whether the REST gateway is served",
+	"rest.path":    "Path of the REST gateway, which is not prefixed by the routes",
+
+	"retry":                 "Retry settings for transient database errors",
+	"retry.max_retries":     "Maximum number of retries",
+	"retry.initial_backoff": "Backoff before the first retry",
+	"retry.max_backoff":     "Maximum backoff between retries",
+
+	"server":                                "HTTP server settings",
+	"server.port":                           "Port the server listens on",
+	"server.read_timeout":                   "Timeout for reading a request",
+	"server.write_timeout":                  "Timeout for writing a response",
+	"server.idle_timeout":                   "Timeout for idle keep-alive connections",
+	"server.shutdown_timeout":               "Time allowed for in-flight requests to complete during shutdown",
+	"server.worker_drain_timeout":           "Time allowed for background workers to drain during shutdown",
+	"server.health_endpoint":                "Path of the health check endpoint",
+	"server.quit_endpoint":                  "Path of the loopback-only endpoint that requests a graceful shutdown",
+	"server.drain_delay":                    "Time to report NOT_READY before shutdown begins, so load balancers can drain",
+	"server.rate_limit":                     "Per-subject rate limiting of HTTP requests",
+	"server.rate_limit.enabled":             "Whether HTTP requests are rate limited",
+	"server.rate_limit.requests_per_second": "Sustained number of requests per second for each subject",
+	"server.rate_limit.burst_size":          "Maximum number of requests in a burst for each subject",
+	"server.rate_limit.warn_threshold":      "Fraction of remaining requests at which clients are warned",
+	"server.routes":                         "Paths of the GraphQL endpoint and of the pages served with it",
+	"server.routes.prefix":                  "Prefix of every route and of the landing page, for example the path of the service in a gateway; empty serves them at the root",
+	"server.routes.graphql":                 "Path of the GraphQL endpoint",
+	"server.routes.playground":              "Path of the GraphQL Playground",
+	"server.routes.graphiql":                "Path of the GraphiQL interface",
+	"server.routes.docs":                    "Path of the documentation portal",
+	"server.routes.login":                   "Path of the playground sign-in page; its callback and scripts are served below it",
+	"server.routes.lifecycle":               "Path of the state machine of the status of a family, as JSON or Graphviz DOT",
+	"server.websocket":                      "GraphQL operations over websockets, such as subscriptions",
+	"server.websocket.init_timeout":         "Time allowed for a client to send connection_init after the websocket is opened",
+	"server.websocket.keepalive_interval":   "Interval of keepalive messages (graphql-ws) or pings (graphql-transport-ws); 0 disables them",
+	"server.websocket.revalidate_interval":  "Interval at which the token of a connection is validated again; 0 only closes connections when their token expires",
+	"server.websocket.max_lifetime":         "Maximum lifetime of a connection, after which the client must reconnect; 0 is unlimited",
+	"server.test_clock":                     "Pinning of the clock of a request by integration tests; never served in production",
+	"server.test_clock.enabled":             "Whether requests can pin their clock with the test clock header",
+	"server.test_clock.header":              "Header carrying the RFC 3339 time a request runs at",
+
+	"strict":          "Strict handling of unknown fields, to catch typos in configuration and requests",
+	"strict.config":   "Whether loading the configuration fails if the configuration file has unknown keys or values of the wrong type",
+	"strict.requests": "Handling of GraphQL requests with undeclared variables or unknown extensions: off ignores them, warn logs them, and error rejects the request with UNKNOWN_INPUT",
+
+	"telemetry":                                      "Telemetry settings",
+	"telemetry.shutdown_timeout":                     "Time allowed for flushing telemetry during shutdown",
+	"telemetry.exporters":                            "Telemetry exporters",
+	"telemetry.exporters.metrics":                    "Metrics exporters",
+	"telemetry.exporters.metrics.prometheus":         "Prometheus metrics exporter",
+	"telemetry.exporters.metrics.prometheus.enabled": "Whether metrics are exposed for Prometheus",
+	"telemetry.exporters.metrics.prometheus.listen":  "Address the metrics endpoint listens on",
+	"telemetry.exporters.metrics.prometheus.path":    "Path of the metrics endpoint",
+	"telemetry.tracing":                              "Distributed tracing",
+	"telemetry.tracing.enabled":                      "Whether traces are exported",
+	"telemetry.tracing.otlp":                         "OpenTelemetry Protocol exporter",
+	"telemetry.tracing.otlp.endpoint":                "Address of the OTLP collector",
+	"telemetry.tracing.otlp.insecure":                "Whether the connection to the collector is unencrypted",
+	"telemetry.tracing.otlp.timeout":                 "Timeout for exporting a batch of spans",
+	"telemetry.slo":                                  "GraphQL error rate service level objective",
+	"telemetry.slo.enabled":                          "Whether the error rate SLO is tracked",
+	"telemetry.slo.objective":                        "Target fraction of successful GraphQL operations",
+	"telemetry.slo.path":                             "Path of the SLO status endpoint",
+
+	"veto":           "Review of GraphQL operations by a webhook before they run, so that operations can be vetoed centrally",
+	"veto.enabled":   "Whether operations are reviewed by the webhook",
+	"veto.url":       "URL the operation, a summary of its variables, and the caller are posted to; the webhook answers {\"allow\": bool, \"reason\": string}",
+	"veto.timeout":   "Timeout for a review by the webhook",
+	"veto.fail_open": "Whether operations run when the webhook fails or times out; otherwise they are refused",
+	"veto.secret":    "Key of the HMAC-SHA256 signature of requests to the webhook, sent in the X-Signature-256 header; empty sends no signature",
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSchema tests that the schema describes every configuration key
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	assert.Equal(t, SchemaID, schema["$id"])
+	assert.Equal(t, false, schema["additionalProperties"])
+
+	// Every property has a description, and every leaf with a default has it in the schema
+	var walk func(schema map[string]interface{}, path string)
+	walk = func(schema map[string]interface{}, path string) {
+		assert.NotEmpty(t, schema["description"], "missing description for %q", path)
+		properties, ok := schema["properties"].(map[string]interface{})
+		if !ok {
+			if _, hasDefault := getDefaultsMap()[path]; hasDefault {
+				assert.Contains(t, schema, "default", "missing default for %q", path)
+			}
+			return
+		}
+		for name, property := range properties {
+			walk(property.(map[string]interface{}), joinPath(path, name))
+		}
+	}
+	walk(schema, "")
+
+	database := schema["properties"].(map[string]interface{})["database"].(map[string]interface{})
+	dbType := database["properties"].(map[string]interface{})["type"].(map[string]interface{})
+	assert.Equal(t, []string{"mongodb", "postgres", "sqlite"}, dbType["enum"])
+	assert.Equal(t, "sqlite", dbType["default"])
+
+	// Rules after dive apply to the items of a list
+	reporting := schema["properties"].(map[string]interface{})["reporting"].(map[string]interface{})
+	formats := reporting["properties"].(map[string]interface{})["formats"].(map[string]interface{})
+	assert.NotContains(t, formats, "enum")
+	assert.Equal(t, []string{"csv", "json"}, formats["items"].(map[string]interface{})["enum"])
+}
+
+// TestSchemaFileUpToDate tests that the published schema matches the Config struct
+func TestSchemaFileUpToDate(t *testing.T) {
+	published, err := os.ReadFile(filepath.Join("..", "..", "..", "config", "config.schema.json"))
+	require.NoError(t, err)
+
+	generated, err := SchemaJSON()
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(published), "config/config.schema.json is out of date; run make config-schema")
+}
+
+// TestValidateFile tests validation of configuration files in normal and strict mode
+func TestValidateFile(t *testing.T) {
+	t.Run("repository config files are strictly valid", func(t *testing.T) {
+		paths, err := filepath.Glob(filepath.Join("..", "..", "..", "config", "config.*.yaml"))
+		require.NoError(t, err)
+		require.NotEmpty(t, paths)
+		for _, path := range paths {
+			_, err := ValidateFile(path, true)
+			assert.NoError(t, err, path)
+		}
+	})
+
+	writeFile := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	t.Run("file values override defaults", func(t *testing.T) {
+		config, err := ValidateFile(writeFile(t, "server:\n  port: \"9000\"\npolicy:\n  age:\n    mode: block\n"), true)
+		require.NoError(t, err)
+		assert.Equal(t, "9000", config.Server.Port)
+		assert.Equal(t, "block", config.Policy.Age.Mode)
+		assert.Equal(t, "sqlite", config.Database.Type)
+	})
+
+	t.Run("unknown keys and loose types are only rejected in strict mode", func(t *testing.T) {
+		path := writeFile(t, "server:\n  port: 9000\n  read_timout: 10s\n")
+
+		_, err := ValidateFile(path, false)
+		assert.NoError(t, err)
+
+		_, err = ValidateFile(path, true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.read_timout: unknown key")
+		assert.Contains(t, err.Error(), "server.port: expected a string")
+	})
+
+	t.Run("invalid values are rejected in both modes", func(t *testing.T) {
+		path := writeFile(t, "database:\n  type: oracle\n")
+		for _, strict := range []bool{false, true} {
+			_, err := ValidateFile(path, strict)
+			require.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), "oneof"), err.Error())
+		}
+	})
+
+	t.Run("compression is rejected for databases other than SQLite", func(t *testing.T) {
+		_, err := ValidateFile(writeFile(t, "database:\n  compression:\n    enabled: true\n"), true)
+		assert.NoError(t, err)
+
+		for _, dbType := range []string{"postgres", "mongodb"} {
+			_, err := ValidateFile(writeFile(t, "database:\n  type: "+dbType+"\n  compression:\n    enabled: true\n"), true)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "database.compression is supported by the sqlite database only, not "+dbType)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := ValidateFile(filepath.Join(t.TempDir(), "missing.yaml"), false)
+		assert.Error(t, err)
+	})
+}
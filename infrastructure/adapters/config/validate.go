@@ -0,0 +1,161 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+// ValidateFile loads the configuration file at path on top of the defaults and validates
+// the result as LoadConfig would, without reading APP_ prefixed environment variables.
+// It is intended for validating rendered configuration files in deployment pipelines.
+//
+// In strict mode, the file itself is also checked against the configuration schema:
+// unknown keys, which are otherwise silently ignored, and values of the wrong type, which
+// are otherwise converted where possible, are reported together with any validation errors.
+func ValidateFile(path string, strict bool) (*Config, error) {
+	fileConfig := koanf.New(".")
+	if err := fileConfig.Load(file.Provider(path), yaml.Parser()); err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+
+	var schemaErr error
+	if strict {
+		schemaErr = strictError(path, fileConfig.Raw())
+	}
+
+	k := koanf.New(".")
+	if err := loadDefaults(k); err != nil {
+		return nil, fmt.Errorf("failed to load defaults: %w", err)
+	}
+	if err := k.Merge(fileConfig); err != nil {
+		return nil, fmt.Errorf("failed to merge config file %s: %w", path, err)
+	}
+	if err := processEnvVarsInConnectionStrings(k); err != nil {
+		return nil, fmt.Errorf("failed to process connection strings: %w", err)
+	}
+
+	config, err := processConfig(k)
+	if err != nil {
+		return nil, errors.Join(schemaErr, fmt.Errorf("failed to process config file %s: %w", path, err))
+	}
+	if schemaErr != nil {
+		return nil, schemaErr
+	}
+	return config, nil
+}
+
+// strictError returns an error that lists each problem found by checkStrict in the
+// configuration file at path, or nil if the file matches the schema
+func strictError(path string, raw map[string]interface{}) error {
+	problems := checkStrict(raw, reflect.TypeOf(Config{}), "")
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	errs := make([]error, 0, len(problems))
+	for _, problem := range problems {
+		errs = append(errs, errors.New(problem))
+	}
+	return fmt.Errorf("config file %s does not match the schema: %w", path, errors.Join(errs...))
+}
+
+// checkStrict returns a description of each key of a parsed configuration map that is not
+// a field of t, and of each value whose type does not match its field exactly
+func checkStrict(value interface{}, t reflect.Type, path string) []string {
+	var problems []string
+	switch {
+	case t == durationType:
+		switch v := value.(type) {
+		case int, int64:
+		case string:
+			if _, err := time.ParseDuration(v); err != nil {
+				if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %q is not a duration", path, v))
+				}
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("%s: expected a duration, got %T", path, value))
+		}
+
+	case t.Kind() == reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %T", displayPath(path), value)}
+		}
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			if name := fieldName(t.Field(i)); name != "" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		for key, v := range m {
+			fieldType, ok := fields[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown key", joinPath(path, key)))
+				continue
+			}
+			problems = append(problems, checkStrict(v, fieldType, joinPath(path, key))...)
+		}
+
+	case t.Kind() == reflect.Slice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected a list, got %T", path, value)}
+		}
+		for i, v := range list {
+			problems = append(problems, checkStrict(v, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+
+	case t.Kind() == reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %T", path, value)}
+		}
+		for key, v := range m {
+			problems = append(problems, checkStrict(v, t.Elem(), joinPath(path, key))...)
+		}
+
+	case t.Kind() == reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s: expected a boolean, got %T", path, value))
+		}
+
+	case t.Kind() == reflect.String:
+		if _, ok := value.(string); !ok {
+			problems = append(problems, fmt.Sprintf("%s: expected a string, got %T", path, value))
+		}
+
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		switch value.(type) {
+		case int, int64, uint64:
+		default:
+			problems = append(problems, fmt.Sprintf("%s: expected an integer, got %T", path, value))
+		}
+
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		switch value.(type) {
+		case int, int64, uint64, float64:
+		default:
+			problems = append(problems, fmt.Sprintf("%s: expected a number, got %T", path, value))
+		}
+	}
+	return problems
+}
+
+// displayPath returns the path of a configuration key for messages
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
@@ -46,6 +46,15 @@ type NotFoundError interface {
 	ResourceID() string
 }
 
+// Sentinel errors for matching errors by code with errors.Is, including when they are
+// wrapped. For example, errors.Is(err, ErrNotFound) reports whether err or any error it
+// wraps is a not found error.
+var (
+	ErrNotFound   = serviceerrors.ErrNotFound
+	ErrValidation = serviceerrors.New(serviceerrors.ValidationErrorCode, "validation failed")
+	ErrDatabase   = serviceerrors.New(serviceerrors.DatabaseErrorCode, "database operation failed")
+)
+
 // Wrapper functions for servicelib/errors
 
 // NewDomainError creates a new domain error with the given code, message, and cause
@@ -96,16 +105,12 @@ func GetErrorCode(err error) string {
 		return ""
 	}
 
-	// Try to cast to our Error interface
-	if e, ok := err.(Error); ok {
+	// Find the first error in the chain that has a code
+	var e Error
+	if errors.As(err, &e) {
 		return e.Code()
 	}
 
-	// Try to extract from wrapped error
-	if cause := GetErrorCause(err); cause != nil && cause != err {
-		return GetErrorCode(cause)
-	}
-
 	return ""
 }
 
@@ -115,8 +120,9 @@ func GetErrorMessage(err error) string {
 		return ""
 	}
 
-	// Try to cast to our Error interface
-	if e, ok := err.(Error); ok {
+	// Find the first error in the chain that has a message
+	var e Error
+	if errors.As(err, &e) {
 		return e.Message()
 	}
 
@@ -130,8 +136,9 @@ func GetErrorCause(err error) error {
 		return nil
 	}
 
-	// Try to cast to our Error interface
-	if e, ok := err.(Error); ok {
+	// Find the first error in the chain that has a cause
+	var e Error
+	if errors.As(err, &e) {
 		return e.Cause()
 	}
 
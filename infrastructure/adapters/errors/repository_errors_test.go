@@ -4,6 +4,7 @@
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	serviceerrors "github.com/abitofhelp/servicelib/errors"
@@ -164,3 +165,19 @@ func TestNewNotFoundError(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, testErr, notFoundErr.Unwrap())
 }
+
+// TestSentinels_MatchWrappedErrors tests that the sentinel errors match wrapped errors by code
+func TestSentinels_MatchWrappedErrors(t *testing.T) {
+	notFound := fmt.Errorf("loading family: %w", NewNotFoundError("Family", "123", nil))
+	assert.ErrorIs(t, notFound, ErrNotFound)
+	assert.NotErrorIs(t, notFound, ErrValidation)
+	assert.True(t, IsNotFoundError(notFound))
+
+	validation := fmt.Errorf("creating family: %w", NewValidationError("Invalid input", "name", nil))
+	assert.ErrorIs(t, validation, ErrValidation)
+	assert.True(t, IsValidationError(validation))
+
+	database := fmt.Errorf("saving family: %w", NewRepositoryError(errors.New("test error"), "failed", DatabaseErrorCode, "families"))
+	assert.ErrorIs(t, database, ErrDatabase)
+	assert.True(t, IsDatabaseError(database))
+}
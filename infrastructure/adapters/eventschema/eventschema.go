@@ -0,0 +1,249 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package eventschema is the registry of the schemas of domain events.
+//
+// Events leave the service in an Envelope, which carries the type and version of the
+// event, the time it occurred, and the producer, with the event itself as the JSON
+// payload. The registry knows the current version of each event type, generates a JSON
+// Schema for it from its Go type, and checks at startup that the current schemas are
+// compatible with the published ones in the schemas directory. Payloads of older
+// versions, such as those read back when events are replayed, are converted to the
+// current version by upcasters before they are decoded.
+package eventschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/events"
+)
+
+// Envelope is the representation of an event outside of the service
+type Envelope struct {
+	ID         string          `json:"id,omitempty"` // Deduplication key of an event relayed from the outbox
+	Type       string          `json:"type"`         // Type of the event, such as "child_moved"
+	Version    int             `json:"version"`      // Version of the payload
+	OccurredAt time.Time       `json:"occurred_at"`  // Time at which the event happened
+	Producer   string          `json:"producer"`     // Service that produced the event
+	Payload    json.RawMessage `json:"payload"`      // The event, encoded as JSON
+}
+
+// Decoder decodes the payload of the current version of an event type
+type Decoder func(payload json.RawMessage, occurredAt time.Time) (events.Event, error)
+
+// Upcaster converts the payload of an event from one version to the next
+type Upcaster func(payload map[string]interface{}) (map[string]interface{}, error)
+
+// eventType is a registered event type
+type eventType struct {
+	name      string
+	version   int
+	goType    reflect.Type
+	decode    Decoder
+	upcasters map[int]Upcaster // Indexed by the version they convert from
+}
+
+// Registry knows the event types of the service, their current versions, and how to
+// upcast and decode older versions. A Registry must not be changed once it is in use.
+type Registry struct {
+	producer string
+	types    map[string]*eventType
+}
+
+// NewRegistry creates a new, empty registry
+//
+// Parameters:
+//   - producer: The producer recorded in the envelopes of the registry
+//
+// Returns:
+//   - A new registry
+func NewRegistry(producer string) *Registry {
+	return &Registry{producer: producer, types: make(map[string]*eventType)}
+}
+
+// NewDefaultRegistry creates a registry of the domain events of the service, with the
+// upcasters of their older versions
+func NewDefaultRegistry(producer string) *Registry {
+	r := NewRegistry(producer)
+	r.Register(events.ChildMoved{}, func(payload json.RawMessage, occurredAt time.Time) (events.Event, error) {
+		var e events.ChildMoved
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, err
+		}
+		e.At = occurredAt
+		return e, nil
+	})
+	r.Register(events.FamilyQuarantined{}, func(payload json.RawMessage, occurredAt time.Time) (events.Event, error) {
+		var e events.FamilyQuarantined
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, err
+		}
+		e.At = occurredAt
+		return e, nil
+	})
+	r.Register(events.FamilyUnquarantined{}, func(payload json.RawMessage, occurredAt time.Time) (events.Event, error) {
+		var e events.FamilyUnquarantined
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, err
+		}
+		e.At = occurredAt
+		return e, nil
+	})
+	r.Register(events.QuarantinedFamilyAccessed{}, func(payload json.RawMessage, occurredAt time.Time) (events.Event, error) {
+		var e events.QuarantinedFamilyAccessed
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, err
+		}
+		e.At = occurredAt
+		return e, nil
+	})
+	r.Register(events.FamilyDuplicateSuspected{}, func(payload json.RawMessage, occurredAt time.Time) (events.Event, error) {
+		var e events.FamilyDuplicateSuspected
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, err
+		}
+		e.At = occurredAt
+		return e, nil
+	})
+	r.Register(events.FamilyDeleted{}, func(payload json.RawMessage, occurredAt time.Time) (events.Event, error) {
+		var e events.FamilyDeleted
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, err
+		}
+		e.At = occurredAt
+		return e, nil
+	})
+	r.Register(events.FamilyRestored{}, func(payload json.RawMessage, occurredAt time.Time) (events.Event, error) {
+		var e events.FamilyRestored
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, err
+		}
+		e.At = occurredAt
+		return e, nil
+	})
+	return r
+}
+
+// Register registers the current version of an event type.
+// It panics if the type is already registered, since that is a programming error.
+//
+// Parameters:
+//   - event: A value of the event type, which determines its name, version, and schema
+//   - decode: The decoder of payloads of the current version
+func (r *Registry) Register(event events.Event, decode Decoder) {
+	if _, ok := r.types[event.Name()]; ok {
+		panic(fmt.Sprintf("event type %s is already registered", event.Name()))
+	}
+	r.types[event.Name()] = &eventType{
+		name:      event.Name(),
+		version:   event.Version(),
+		goType:    reflect.TypeOf(event),
+		decode:    decode,
+		upcasters: make(map[int]Upcaster),
+	}
+}
+
+// RegisterUpcaster registers the conversion of payloads of an event type from a version
+// to the next. It panics if the event type is not registered.
+func (r *Registry) RegisterUpcaster(name string, fromVersion int, upcast Upcaster) {
+	t, ok := r.types[name]
+	if !ok {
+		panic(fmt.Sprintf("event type %s is not registered", name))
+	}
+	t.upcasters[fromVersion] = upcast
+}
+
+// Types returns the names of the registered event types, in order
+func (r *Registry) Types() []string {
+	names := make([]string, 0, len(r.types))
+	for name := range r.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Seal wraps an event in an envelope
+//
+// Returns:
+//   - The envelope of the event
+//   - An error if the event type is not registered or the event cannot be encoded
+func (r *Registry) Seal(event events.Event) (Envelope, error) {
+	t, ok := r.types[event.Name()]
+	if !ok {
+		return Envelope{}, fmt.Errorf("event type %s is not registered", event.Name())
+	}
+	if event.Version() != t.version {
+		return Envelope{}, fmt.Errorf("event type %s is registered at version %d, not %d", t.name, t.version, event.Version())
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to encode event %s: %w", t.name, err)
+	}
+	return Envelope{
+		Type:       t.name,
+		Version:    t.version,
+		OccurredAt: event.OccurredAt().UTC(),
+		Producer:   r.producer,
+		Payload:    payload,
+	}, nil
+}
+
+// Upcast converts the payload of an envelope to the current version of its event type
+//
+// Returns:
+//   - The envelope at the current version
+//   - An error if the type is unknown, the version is newer than the current one, or
+//     an upcaster is missing or fails
+func (r *Registry) Upcast(env Envelope) (Envelope, error) {
+	t, ok := r.types[env.Type]
+	if !ok {
+		return Envelope{}, fmt.Errorf("unknown event type %s", env.Type)
+	}
+	if env.Version < 1 || env.Version > t.version {
+		return Envelope{}, fmt.Errorf("unsupported version %d of event type %s, the current version is %d", env.Version, t.name, t.version)
+	}
+	if env.Version == t.version {
+		return env, nil
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(env.Payload, &payload); err != nil {
+		return Envelope{}, fmt.Errorf("failed to decode payload of event %s v%d: %w", t.name, env.Version, err)
+	}
+	for version := env.Version; version < t.version; version++ {
+		upcast, ok := t.upcasters[version]
+		if !ok {
+			return Envelope{}, fmt.Errorf("no upcaster for event type %s from version %d", t.name, version)
+		}
+		var err error
+		if payload, err = upcast(payload); err != nil {
+			return Envelope{}, fmt.Errorf("failed to upcast event %s from version %d: %w", t.name, version, err)
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to encode upcast payload of event %s: %w", t.name, err)
+	}
+	env.Version = t.version
+	env.Payload = data
+	return env, nil
+}
+
+// Decode decodes an envelope of any supported version into an event
+func (r *Registry) Decode(env Envelope) (events.Event, error) {
+	env, err := r.Upcast(env)
+	if err != nil {
+		return nil, err
+	}
+	event, err := r.types[env.Type].decode(env.Payload, env.OccurredAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode event %s v%d: %w", env.Type, env.Version, err)
+	}
+	return event, nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package eventschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// childRenamed is an event whose payload renamed "name" to "firstName" in version 2
+type childRenamed struct {
+	ChildID   string    `json:"childId"`
+	FirstName string    `json:"firstName"`
+	Note      string    `json:"note,omitempty"`
+	At        time.Time `json:"-"`
+}
+
+func (e childRenamed) Name() string          { return "child_renamed" }
+func (e childRenamed) Version() int          { return 2 }
+func (e childRenamed) OccurredAt() time.Time { return e.At }
+
+// newTestRegistry returns a registry with the childRenamed event and its upcaster from version 1
+func newTestRegistry() *Registry {
+	r := NewRegistry("test")
+	r.Register(childRenamed{}, func(payload json.RawMessage, occurredAt time.Time) (events.Event, error) {
+		var e childRenamed
+		err := json.Unmarshal(payload, &e)
+		e.At = occurredAt
+		return e, err
+	})
+	r.RegisterUpcaster("child_renamed", 1, func(payload map[string]interface{}) (map[string]interface{}, error) {
+		payload["firstName"] = payload["name"]
+		delete(payload, "name")
+		return payload, nil
+	})
+	return r
+}
+
+func TestRegistry_SealDecode(t *testing.T) {
+	r := NewDefaultRegistry("family-service/1.0.0")
+	at := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	event := events.ChildMoved{ChildID: "child-1", FromFamilyID: "family-1", ToFamilyID: "family-2", At: at}
+
+	env, err := r.Seal(event)
+	require.NoError(t, err)
+	assert.Equal(t, "child_moved", env.Type)
+	assert.Equal(t, 1, env.Version)
+	assert.Equal(t, at, env.OccurredAt)
+	assert.Equal(t, "family-service/1.0.0", env.Producer)
+	assert.JSONEq(t, `{"childId":"child-1","fromFamilyId":"family-1","toFamilyId":"family-2"}`, string(env.Payload))
+
+	// The envelope survives encoding, as when it is stored and replayed
+	data, err := json.Marshal(env)
+	require.NoError(t, err)
+	var replayed Envelope
+	require.NoError(t, json.Unmarshal(data, &replayed))
+
+	decoded, err := r.Decode(replayed)
+	require.NoError(t, err)
+	assert.Equal(t, event, decoded)
+
+	_, err = r.Seal(childRenamed{})
+	assert.ErrorContains(t, err, "not registered")
+}
+
+func TestRegistry_Upcast(t *testing.T) {
+	r := newTestRegistry()
+	at := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	decoded, err := r.Decode(Envelope{
+		Type:       "child_renamed",
+		Version:    1,
+		OccurredAt: at,
+		Payload:    json.RawMessage(`{"childId":"child-1","name":"Alex"}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, childRenamed{ChildID: "child-1", FirstName: "Alex", At: at}, decoded)
+
+	// Versions newer than the current one, and unknown types, cannot be decoded
+	_, err = r.Decode(Envelope{Type: "child_renamed", Version: 3, Payload: json.RawMessage(`{}`)})
+	assert.ErrorContains(t, err, "unsupported version 3")
+	_, err = r.Decode(Envelope{Type: "unknown", Version: 1, Payload: json.RawMessage(`{}`)})
+	assert.ErrorContains(t, err, "unknown event type")
+
+	// Without an upcaster, older versions cannot be decoded
+	r = NewRegistry("test")
+	r.Register(childRenamed{}, nil)
+	_, err = r.Decode(Envelope{Type: "child_renamed", Version: 1, Payload: json.RawMessage(`{}`)})
+	assert.ErrorContains(t, err, "no upcaster")
+}
+
+func TestRegistry_Check(t *testing.T) {
+	// The schemas of the service are published
+	require.NoError(t, NewDefaultRegistry("test").Check(Published()))
+
+	current, _, err := newTestRegistry().SchemaJSON("child_renamed")
+	require.NoError(t, err)
+	v1 := []byte(`{"type":"object","properties":{"childId":{"type":"string"},"name":{"type":"string"}},"required":["childId","name"]}`)
+
+	t.Run("compatible", func(t *testing.T) {
+		published := fstest.MapFS{
+			"child_renamed.v1.json": {Data: v1},
+			"child_renamed.v2.json": {Data: current},
+		}
+		assert.NoError(t, newTestRegistry().Check(published))
+	})
+
+	t.Run("not published", func(t *testing.T) {
+		err := newTestRegistry().Check(fstest.MapFS{})
+		assert.ErrorContains(t, err, "child_renamed v2: schema is not published")
+	})
+
+	t.Run("incompatible", func(t *testing.T) {
+		published := fstest.MapFS{
+			"child_renamed.v2.json": {Data: []byte(`{"type":"object","properties":{"childId":{"type":"integer"},"lastName":{"type":"string"}},"required":["childId"]}`)},
+		}
+		err := newTestRegistry().Check(published)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "property childId changed type from integer to string")
+		assert.Contains(t, err.Error(), "property lastName was removed")
+		assert.Contains(t, err.Error(), "property firstName became required")
+	})
+
+	t.Run("missing upcaster", func(t *testing.T) {
+		r := NewRegistry("test")
+		r.Register(childRenamed{}, nil)
+		published := fstest.MapFS{
+			"child_renamed.v1.json": {Data: v1},
+			"child_renamed.v2.json": {Data: current},
+		}
+		assert.ErrorContains(t, r.Check(published), "child_renamed v1: no upcaster to version 2")
+	})
+}
+
+func TestIncompatibilities_OptionalPropertyAdded(t *testing.T) {
+	published := typeSchema(reflect.TypeOf(struct {
+		ChildID string `json:"childId"`
+	}{}))
+	current := typeSchema(reflect.TypeOf(struct {
+		ChildID string `json:"childId"`
+		Note    string `json:"note,omitempty"`
+	}{}))
+	assert.Empty(t, Incompatibilities(published, current))
+}
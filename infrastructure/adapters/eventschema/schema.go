@@ -0,0 +1,254 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package eventschema
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// publishedFiles are the published schemas, one file per version of each event type
+//
+//go:embed schemas/*.json
+var publishedFiles embed.FS
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Published returns the published schemas of the service, named by FileName
+func Published() fs.FS {
+	published, err := fs.Sub(publishedFiles, "schemas")
+	if err != nil {
+		panic(err)
+	}
+	return published
+}
+
+// SchemaID returns the identifier of the JSON Schema of a version of an event type
+func SchemaID(name string, version int) string {
+	return fmt.Sprintf("https://github.com/abitofhelp/family-service/events/%s.v%d.schema.json", name, version)
+}
+
+// FileName returns the name of the file of the published schema of a version of an event type
+func FileName(name string, version int) string {
+	return fmt.Sprintf("%s.v%d.json", name, version)
+}
+
+// Schema returns a JSON Schema (draft 2020-12) of the payload of the current version of
+// an event type. Properties are derived from the JSON encoding of the event's Go type;
+// properties without omitempty are required. Additional properties are allowed, so that
+// optional properties can be added without a new version.
+//
+// Returns:
+//   - The schema
+//   - The current version of the event type
+//   - An error if the event type is not registered
+func (r *Registry) Schema(name string) (map[string]interface{}, int, error) {
+	t, ok := r.types[name]
+	if !ok {
+		return nil, 0, fmt.Errorf("event type %s is not registered", name)
+	}
+
+	schema := typeSchema(t.goType)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = SchemaID(t.name, t.version)
+	schema["title"] = fmt.Sprintf("%s event, version %d", t.name, t.version)
+	return schema, t.version, nil
+}
+
+// SchemaJSON returns the schema of the current version of an event type as indented JSON
+func (r *Registry) SchemaJSON(name string) ([]byte, int, error) {
+	schema, version, err := r.Schema(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, 0, err
+	}
+	return append(data, '\n'), version, nil
+}
+
+// Check checks that the schemas of the registered event types can be used with the
+// published schemas: the current version of each type must be published, the current
+// schema must be compatible with the published one, and every older published version
+// must have an upcaster to the next version.
+//
+// Returns:
+//   - An error describing every problem, or nil if there are none
+func (r *Registry) Check(published fs.FS) error {
+	var problems []error
+	for _, name := range r.Types() {
+		t := r.types[name]
+		current, _, err := r.Schema(name)
+		if err != nil {
+			return err
+		}
+
+		data, err := fs.ReadFile(published, FileName(name, t.version))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			problems = append(problems, fmt.Errorf("%s v%d: schema is not published; run make event-schema", name, t.version))
+		case err != nil:
+			problems = append(problems, fmt.Errorf("%s v%d: failed to read published schema: %w", name, t.version, err))
+		default:
+			var schema map[string]interface{}
+			if err := json.Unmarshal(data, &schema); err != nil {
+				problems = append(problems, fmt.Errorf("%s v%d: invalid published schema: %w", name, t.version, err))
+				break
+			}
+			for _, problem := range Incompatibilities(schema, current) {
+				problems = append(problems, fmt.Errorf("%s v%d: %s; publish a new version with an upcaster instead", name, t.version, problem))
+			}
+		}
+
+		for version := 1; version < t.version; version++ {
+			if _, err := fs.Stat(published, FileName(name, version)); err != nil {
+				continue
+			}
+			if _, ok := t.upcasters[version]; !ok {
+				problems = append(problems, fmt.Errorf("%s v%d: no upcaster to version %d", name, version, version+1))
+			}
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// Incompatibilities returns the changes from a published schema to a current schema that
+// would break consumers of the published schema: removed properties, changed types, and
+// properties that became required.
+func Incompatibilities(published, current map[string]interface{}) []string {
+	return incompatibilities(published, current, "")
+}
+
+// incompatibilities compares the schemas of the value at the given path
+func incompatibilities(published, current map[string]interface{}, path string) []string {
+	var problems []string
+	if publishedType, currentType := fmt.Sprint(published["type"]), fmt.Sprint(current["type"]); publishedType != currentType {
+		return append(problems, fmt.Sprintf("%s changed type from %s to %s", describe(path), publishedType, currentType))
+	}
+
+	publishedProperties, _ := published["properties"].(map[string]interface{})
+	currentProperties, _ := current["properties"].(map[string]interface{})
+	for _, name := range sortedKeys(publishedProperties) {
+		propertyPath := strings.TrimPrefix(path+"."+name, ".")
+		currentProperty, ok := currentProperties[name].(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s was removed", describe(propertyPath)))
+			continue
+		}
+		publishedProperty, _ := publishedProperties[name].(map[string]interface{})
+		problems = append(problems, incompatibilities(publishedProperty, currentProperty, propertyPath)...)
+	}
+
+	wasRequired := make(map[string]bool)
+	for _, name := range stringList(published["required"]) {
+		wasRequired[name] = true
+	}
+	for _, name := range stringList(current["required"]) {
+		if !wasRequired[name] {
+			problems = append(problems, fmt.Sprintf("%s became required", describe(strings.TrimPrefix(path+"."+name, "."))))
+		}
+	}
+
+	publishedItems, _ := published["items"].(map[string]interface{})
+	currentItems, _ := current["items"].(map[string]interface{})
+	if publishedItems != nil && currentItems != nil {
+		problems = append(problems, incompatibilities(publishedItems, currentItems, path+"[]")...)
+	}
+	return problems
+}
+
+// describe names the value at a path in problem descriptions
+func describe(path string) string {
+	if path == "" {
+		return "payload"
+	}
+	return "property " + path
+}
+
+// typeSchema returns the schema of the JSON encoding of a Go type
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		properties := make(map[string]interface{}, t.NumField())
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = typeSchema(field.Type)
+			if !strings.Contains(options, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		sort.Strings(required)
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case t.Kind() == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case t.Kind() == reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// stringList returns the strings of a list decoded from JSON or built by typeSchema
+func stringList(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		strs := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package familylock serializes the changes of a family.
+//
+// The application services hold the lock of a family for the duration of each
+// read-modify-write operation on it, so that two concurrent changes, such as two addChild
+// mutations, cannot both read the family and then overwrite each other's save. The
+// repositories lock families with the mechanism of their database: PostgreSQL with session
+// advisory locks, MongoDB with a lock document claimed with findAndModify and released by
+// its version, and SQLite, which is served by a single instance, with an in-process keyed
+// mutex.
+//
+// Acquire applies the configured timeout to every backend and records the time spent
+// waiting for locks in the family_lock_wait_seconds metric.
+package familylock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// waitSeconds measures how long changes waited for the lock of their family
+	waitSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "family_lock_wait_seconds",
+			Help:    "Time changes waited for the lock of their family, by backend",
+			Buckets: []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+		},
+		[]string{"backend"},
+	)
+
+	// timeoutsTotal counts the changes that failed because the lock of their family was
+	// not acquired in time
+	timeoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "family_lock_timeouts_total",
+			Help: "Total number of changes that timed out waiting for the lock of their family, by backend",
+		},
+		[]string{"backend"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(waitSeconds, timeoutsTotal)
+}
+
+// LockFunc acquires the lock of a family in a backend, waiting until it is acquired or the
+// context is done, and returns a function that releases the lock. The lock must not depend
+// on the context once it is acquired, since the context ends when Acquire returns.
+type LockFunc func(ctx context.Context) (unlock func(), err error)
+
+// Acquire acquires the lock of a family with a backend's LockFunc, waiting up to the
+// configured timeout.
+//
+// Parameters:
+//   - ctx: Context for the wait
+//   - backend: The name of the backend, used to label the metrics
+//   - familyID: The ID of the family, used in the error
+//   - cfg: Whether families are locked, and how long to wait for a lock
+//   - lock: Acquires the lock in the backend
+//
+// Returns:
+//   - A function that releases the lock; it may be called more than once. If locking is
+//     disabled, the function does nothing
+//   - A FamilyLockedError if the lock was not acquired within the timeout, or the error of
+//     the backend
+func Acquire(ctx context.Context, backend, familyID string, cfg config.LockingConfig, lock LockFunc) (func(), error) {
+	if !cfg.Enabled {
+		return func() {}, nil
+	}
+
+	lockCtx := ctx
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		lockCtx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	unlock, err := lock(lockCtx)
+	waitSeconds.WithLabelValues(backend).Observe(time.Since(start).Seconds())
+	if err != nil {
+		// Only the timeout of the wait means that the family is locked; the caller's
+		// context and the backend's errors are returned as they are
+		if ctx.Err() == nil && lockCtx.Err() != nil {
+			timeoutsTotal.WithLabelValues(backend).Inc()
+			return nil, domainerrors.NewFamilyLockedError(fmt.Sprintf(
+				"family %s is being changed by another operation; retry the change", familyID), err)
+		}
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() { once.Do(unlock) }, nil
+}
+
+// Keyed is an in-process mutex per key; locks of different keys do not wait for each other.
+// Keys are forgotten once no one holds or waits for their lock. A Keyed is safe for
+// concurrent use.
+type Keyed struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock is the lock of a key
+type keyedLock struct {
+	held chan struct{} // Holds a value while the lock is held
+	refs int           // Number of holders and waiters, guarded by the mutex of the Keyed
+}
+
+// NewKeyed creates a new keyed mutex
+func NewKeyed() *Keyed {
+	return &Keyed{locks: make(map[string]*keyedLock)}
+}
+
+// Lock waits until the caller holds the lock of a key
+//
+// Returns:
+//   - A function that releases the lock; it must be called exactly once
+//   - The error of the context if it was done before the lock was acquired
+func (k *Keyed) Lock(ctx context.Context, key string) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{held: make(chan struct{}, 1)}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	select {
+	case l.held <- struct{}{}:
+		return func() {
+			<-l.held
+			k.forget(key, l)
+		}, nil
+	case <-ctx.Done():
+		k.forget(key, l)
+		return nil, ctx.Err()
+	}
+}
+
+// forget removes a holder or waiter of the lock of a key, and the lock once it has none
+func (k *Keyed) forget(key string, l *keyedLock) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	l.refs--
+	if l.refs == 0 {
+		delete(k.locks, key)
+	}
+}
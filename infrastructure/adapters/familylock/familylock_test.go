@@ -0,0 +1,117 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package familylock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyed_SerializesKey(t *testing.T) {
+	keyed := NewKeyed()
+	ctx := context.Background()
+
+	// Concurrent read-modify-write operations on one key do not lose updates
+	counter := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := keyed.Lock(ctx, "family-1")
+			require.NoError(t, err)
+			value := counter
+			time.Sleep(time.Microsecond)
+			counter = value + 1
+			unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 50, counter)
+
+	// Keys are forgotten once their locks are released
+	assert.Empty(t, keyed.locks)
+}
+
+func TestKeyed_OtherKeysDoNotWait(t *testing.T) {
+	keyed := NewKeyed()
+	ctx := context.Background()
+
+	unlock, err := keyed.Lock(ctx, "family-1")
+	require.NoError(t, err)
+	defer unlock()
+
+	other, err := keyed.Lock(ctx, "family-2")
+	require.NoError(t, err)
+	other()
+}
+
+func TestKeyed_ContextDone(t *testing.T) {
+	keyed := NewKeyed()
+
+	unlock, err := keyed.Lock(context.Background(), "family-1")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = keyed.Lock(ctx, "family-1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The waiter that gave up does not keep the lock once it is released
+	unlock()
+	again, err := keyed.Lock(context.Background(), "family-1")
+	require.NoError(t, err)
+	again()
+	assert.Empty(t, keyed.locks)
+}
+
+func TestAcquire(t *testing.T) {
+	keyed := NewKeyed()
+	cfg := config.LockingConfig{Enabled: true, Timeout: 20 * time.Millisecond}
+	lock := func(ctx context.Context) (func(), error) { return keyed.Lock(ctx, "family-1") }
+
+	unlock, err := Acquire(context.Background(), "test", "family-1", cfg, lock)
+	require.NoError(t, err)
+
+	// A change that waits longer than the timeout fails with a typed error
+	_, err = Acquire(context.Background(), "test", "family-1", cfg, lock)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, domainerrors.ErrFamilyLocked))
+
+	// Releasing twice is harmless
+	unlock()
+	unlock()
+	unlock, err = Acquire(context.Background(), "test", "family-1", cfg, lock)
+	require.NoError(t, err)
+	unlock()
+
+	// Errors of the backend and of the caller's context are not reported as locked families
+	failure := errors.New("connection refused")
+	_, err = Acquire(context.Background(), "test", "family-1", cfg, func(ctx context.Context) (func(), error) { return nil, failure })
+	assert.Equal(t, failure, err)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Acquire(cancelled, "test", "family-1", cfg, lock)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, errors.Is(err, domainerrors.ErrFamilyLocked))
+}
+
+func TestAcquire_Disabled(t *testing.T) {
+	called := false
+	unlock, err := Acquire(context.Background(), "test", "family-1", config.LockingConfig{}, func(ctx context.Context) (func(), error) {
+		called = true
+		return func() {}, nil
+	})
+	require.NoError(t, err)
+	unlock()
+	assert.False(t, called)
+}
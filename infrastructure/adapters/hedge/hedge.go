@@ -0,0 +1,147 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package hedge sends hedged requests for idempotent reads, so that a slow database
+// node does not set the tail latency of the reads it serves.
+//
+// A hedged read sends one attempt, and if that attempt has not completed within the
+// hedge delay, sends a second one. The first attempt to succeed is used and the other
+// is cancelled. A read that fails before the delay is not hedged, since retrying is the
+// job of the retry layer, not of hedging. The delay is the latency budget of the read:
+// set it near the p95 latency, so that only the slowest reads send a second attempt.
+//
+// Hedged attempts run concurrently, so the operation must not modify shared state; it
+// returns its result instead.
+package hedge
+
+import (
+	"context"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	// firedTotal counts the second attempts sent because the first had not completed within the delay
+	firedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hedge_requests_fired_total",
+			Help: "Total number of hedged requests sent because the first attempt was slower than the hedge delay, by name and operation",
+		},
+		[]string{"name", "operation"},
+	)
+
+	// wonTotal counts the hedged requests that succeeded before the first attempt
+	wonTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hedge_requests_won_total",
+			Help: "Total number of hedged requests that succeeded before the first attempt, by name and operation",
+		},
+		[]string{"name", "operation"},
+	)
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(firedTotal, wonTotal)
+}
+
+// Hedger sends hedged requests for the reads of a database.
+// A nil Hedger does not hedge. A Hedger is safe for concurrent use.
+type Hedger struct {
+	name  string
+	delay time.Duration
+}
+
+// NewHedger creates a new hedger
+//
+// Parameters:
+//   - name: The name of the database, used to label the metrics
+//   - cfg: The hedging configuration
+//   - logger: Logger for recording the configuration
+//
+// Returns:
+//   - A new hedger, or nil if hedging is disabled
+func NewHedger(name string, cfg *config.HedgeConfig, logger *zap.Logger) *Hedger {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	logger.Info("Initializing hedged reads",
+		zap.String("name", name),
+		zap.Duration("delay", cfg.Delay))
+
+	return &Hedger{name: name, delay: cfg.Delay}
+}
+
+// result is the outcome of an attempt
+type result[T any] struct {
+	value  T
+	err    error
+	hedged bool
+}
+
+// Do runs an idempotent read, sending a second attempt if the first has not completed
+// within the hedge delay. The context of the attempt that loses is cancelled.
+//
+// Parameters:
+//   - ctx: Context for the read
+//   - h: The hedger; if nil, the read is run once
+//   - operation: Name of the operation, used to label the metrics
+//   - fn: The read, which must be safe to run twice concurrently
+//
+// Returns:
+//   - The result of the first attempt to succeed
+//   - The error of the first attempt to fail, if no attempt succeeded, or the context
+//     error if the context was done first
+func Do[T any](ctx context.Context, h *Hedger, operation string, fn func(ctx context.Context) (T, error)) (T, error) {
+	if h == nil {
+		return fn(ctx)
+	}
+
+	// Cancel the attempt that is still running when Do returns
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The channel holds both results, so the losing attempt never blocks
+	results := make(chan result[T], 2)
+	attempt := func(hedged bool) {
+		value, err := fn(ctx)
+		results <- result[T]{value: value, err: err, hedged: hedged}
+	}
+	go attempt(false)
+
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+
+	pending := 1
+	var firstErr error
+	for {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if r.hedged {
+					wonTotal.WithLabelValues(h.name, operation).Inc()
+				}
+				return r.value, nil
+			}
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			// A read that fails before the delay is not hedged
+			if pending == 0 {
+				var zero T
+				return zero, firstErr
+			}
+		case <-timer.C:
+			pending++
+			firedTotal.WithLabelValues(h.name, operation).Inc()
+			go attempt(true)
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package hedge
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestHedger(t *testing.T, delay time.Duration) *Hedger {
+	t.Helper()
+	h := NewHedger(t.Name(), &config.HedgeConfig{Enabled: true, Delay: delay}, zap.NewNop())
+	require.NotNil(t, h)
+	return h
+}
+
+// counts returns the hedges fired and won for an operation of a test's hedger
+func counts(t *testing.T) (fired, won float64) {
+	return testutil.ToFloat64(firedTotal.WithLabelValues(t.Name(), "GetByID")),
+		testutil.ToFloat64(wonTotal.WithLabelValues(t.Name(), "GetByID"))
+}
+
+func TestNewHedger_Disabled(t *testing.T) {
+	assert.Nil(t, NewHedger("test", &config.HedgeConfig{Enabled: false, Delay: time.Millisecond}, zap.NewNop()))
+	assert.Nil(t, NewHedger("test", nil, zap.NewNop()))
+
+	// A nil hedger runs the read once
+	var calls atomic.Int32
+	value, err := Do(context.Background(), nil, "GetByID", func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return "family", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "family", value)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestDo_FastRead(t *testing.T) {
+	h := newTestHedger(t, 50*time.Millisecond)
+	fired, _ := counts(t)
+
+	var calls atomic.Int32
+	value, err := Do(context.Background(), h, "GetByID", func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return "family", nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "family", value)
+	assert.Equal(t, int32(1), calls.Load(), "a read faster than the delay is not hedged")
+	firedAfter, _ := counts(t)
+	assert.Equal(t, fired, firedAfter)
+}
+
+func TestDo_HedgeWins(t *testing.T) {
+	h := newTestHedger(t, 10*time.Millisecond)
+	fired, won := counts(t)
+
+	var calls atomic.Int32
+	primaryCancelled := make(chan struct{})
+	value, err := Do(context.Background(), h, "GetByID", func(ctx context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			// The first attempt is stuck on a slow node until it is cancelled
+			<-ctx.Done()
+			close(primaryCancelled)
+			return "", ctx.Err()
+		}
+		return "hedged", nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "hedged", value)
+	assert.Equal(t, int32(2), calls.Load())
+	firedAfter, wonAfter := counts(t)
+	assert.Equal(t, fired+1, firedAfter)
+	assert.Equal(t, won+1, wonAfter)
+
+	select {
+	case <-primaryCancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the losing attempt was not cancelled")
+	}
+}
+
+func TestDo_PrimaryWinsAfterHedge(t *testing.T) {
+	h := newTestHedger(t, 10*time.Millisecond)
+	fired, won := counts(t)
+
+	var calls atomic.Int32
+	value, err := Do(context.Background(), h, "GetByID", func(ctx context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			time.Sleep(30 * time.Millisecond)
+			return "primary", nil
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "primary", value)
+	firedAfter, wonAfter := counts(t)
+	assert.Equal(t, fired+1, firedAfter)
+	assert.Equal(t, won, wonAfter, "the first attempt won")
+}
+
+func TestDo_Errors(t *testing.T) {
+	errSlow := errors.New("slow node failed")
+	errHedge := errors.New("hedge failed")
+
+	t.Run("failure before the delay is not hedged", func(t *testing.T) {
+		h := newTestHedger(t, 50*time.Millisecond)
+
+		var calls atomic.Int32
+		_, err := Do(context.Background(), h, "GetByID", func(ctx context.Context) (string, error) {
+			calls.Add(1)
+			return "", errSlow
+		})
+
+		assert.ErrorIs(t, err, errSlow)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("failed first attempt waits for the hedge", func(t *testing.T) {
+		h := newTestHedger(t, 10*time.Millisecond)
+
+		var calls atomic.Int32
+		value, err := Do(context.Background(), h, "GetByID", func(ctx context.Context) (string, error) {
+			if calls.Add(1) == 1 {
+				time.Sleep(20 * time.Millisecond)
+				return "", errSlow
+			}
+			time.Sleep(30 * time.Millisecond)
+			return "hedged", nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "hedged", value)
+	})
+
+	t.Run("both attempts fail", func(t *testing.T) {
+		h := newTestHedger(t, 10*time.Millisecond)
+
+		var calls atomic.Int32
+		_, err := Do(context.Background(), h, "GetByID", func(ctx context.Context) (string, error) {
+			if calls.Add(1) == 1 {
+				time.Sleep(20 * time.Millisecond)
+				return "", errSlow
+			}
+			time.Sleep(30 * time.Millisecond)
+			return "", errHedge
+		})
+
+		assert.ErrorIs(t, err, errSlow, "the error of the first attempt to fail is returned")
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		h := newTestHedger(t, 10*time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+		block := make(chan struct{})
+		defer close(block)
+
+		_, err := Do(ctx, h, "GetByID", func(ctx context.Context) (string, error) {
+			// Ignores the context, like a driver that does not support cancellation
+			<-block
+			return "", nil
+		})
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
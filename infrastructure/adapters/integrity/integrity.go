@@ -0,0 +1,233 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package integrity verifies the integrity of the families stored in a repository.
+//
+// Bit rot, partial writes, and manual edits can leave rows that the service cannot
+// load or that break the rules of the domain. The checker scans every stored family
+// through a Scanner implemented by each repository adapter, and reports rows whose
+// data cannot be decoded, rows that violate the invariants of the Family aggregate,
+// families with duplicate member IDs, and children that belong to more than one
+// family. Rows that cannot be decoded, that violate invariants, or that have
+// duplicate member IDs are corrupt: they cannot be repaired automatically, and when
+// quarantine is enabled they are moved out of the families table so that they no
+// longer fail reads, and kept for manual review. Children in more than one family
+// are only reported, since the families are valid by themselves and only a person
+// can tell which one is right.
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/repair"
+	"go.uber.org/zap"
+)
+
+// Kinds of issues, as reported by the checker
+const (
+	KindDecodeFailed            = "decode_failed"
+	KindInvariantViolated       = "invariant_violated"
+	KindDuplicateMemberID       = "duplicate_member_id"
+	KindChildInMultipleFamilies = "child_in_multiple_families"
+)
+
+// Record is a stored family as read by a Scanner. Exactly one of Family, DecodeErr, and
+// InvariantErr is set.
+type Record struct {
+	FamilyID     string
+	Family       *entity.Family  // The family, if its row could be loaded
+	DecodeErr    error           // Why the stored data could not be decoded
+	InvariantErr error           // Why the decoded data does not form a valid family
+	Repairs      []repair.Repair // Repairs made in memory to legacy data before it was decoded
+}
+
+// Scanner is implemented by repositories whose stored families can be verified
+type Scanner interface {
+	// ScanFamilies reads every stored family in ascending ID order and calls fn with
+	// each. Legacy data is repaired in memory first, whether or not read repair is
+	// enabled, since only data that cannot be repaired is corrupt; stored data is not
+	// changed. Rows that cannot be decoded or that violate invariants are passed to fn
+	// rather than failing the scan.
+	ScanFamilies(ctx context.Context, fn func(Record) error) error
+
+	// Quarantine moves a stored family out of the families table into a quarantine
+	// table or collection, with the reason it was quarantined.
+	Quarantine(ctx context.Context, familyID, reason string) error
+}
+
+// Issue is a problem found in a stored family
+type Issue struct {
+	FamilyID    string `json:"familyId"`
+	Kind        string `json:"kind"`
+	Message     string `json:"message"`
+	Corrupt     bool   `json:"corrupt"`
+	Quarantined bool   `json:"quarantined"`
+}
+
+// Report is the result of an integrity check
+type Report struct {
+	StartedAt    time.Time      `json:"startedAt"`
+	FinishedAt   time.Time      `json:"finishedAt"`
+	Scanned      int            `json:"scanned"`
+	Valid        int            `json:"valid"`
+	Corrupt      int            `json:"corrupt"`
+	Quarantined  int            `json:"quarantined"`
+	IssuesByKind map[string]int `json:"issuesByKind"`
+	Issues       []Issue        `json:"issues"`
+}
+
+// Options configures an integrity check
+type Options struct {
+	Quarantine bool // Move corrupt rows to quarantine; otherwise they are only reported
+}
+
+// Checker verifies the integrity of the families of a repository
+type Checker struct {
+	scanner Scanner
+	opts    Options
+	logger  *zap.Logger
+}
+
+// NewChecker creates a new Checker
+func NewChecker(scanner Scanner, opts Options, logger *zap.Logger) *Checker {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Checker{scanner: scanner, opts: opts, logger: logger}
+}
+
+// Run scans every stored family and reports the issues found. If quarantine is
+// enabled, corrupt rows are quarantined after the scan. The report is returned even
+// if the scan or a quarantine fails.
+func (c *Checker) Run(ctx context.Context) (*Report, error) {
+	report := &Report{StartedAt: time.Now().UTC(), IssuesByKind: map[string]int{}, Issues: []Issue{}}
+	childFamilies := make(map[string][]string)
+
+	err := c.scanner.ScanFamilies(ctx, func(rec Record) error {
+		report.Scanned++
+		issues := check(rec)
+		if rec.Family != nil {
+			// A child listed twice in one family is a duplicate member ID, not a shared child
+			seen := make(map[string]bool)
+			for _, child := range rec.Family.Children() {
+				if !seen[child.ID()] {
+					seen[child.ID()] = true
+					childFamilies[child.ID()] = append(childFamilies[child.ID()], rec.FamilyID)
+				}
+			}
+		}
+		if len(issues) == 0 {
+			report.Valid++
+			return nil
+		}
+		report.Corrupt++
+		for _, issue := range issues {
+			c.logger.Warn("Corrupt family found",
+				zap.String("family_id", issue.FamilyID),
+				zap.String("kind", issue.Kind),
+				zap.String("message", issue.Message))
+		}
+		report.Issues = append(report.Issues, issues...)
+		return nil
+	})
+	if err != nil {
+		return c.finish(report), fmt.Errorf("failed to scan families: %w", err)
+	}
+
+	// Report the children that belong to more than one family, in ascending child ID order
+	childIDs := make([]string, 0, len(childFamilies))
+	for childID, familyIDs := range childFamilies {
+		if len(familyIDs) > 1 {
+			childIDs = append(childIDs, childID)
+		}
+	}
+	sort.Strings(childIDs)
+	for _, childID := range childIDs {
+		familyIDs := childFamilies[childID]
+		for _, familyID := range familyIDs {
+			report.Issues = append(report.Issues, Issue{
+				FamilyID: familyID,
+				Kind:     KindChildInMultipleFamilies,
+				Message:  fmt.Sprintf("child %s is in families %v", childID, familyIDs),
+			})
+		}
+	}
+
+	if c.opts.Quarantine {
+		if err := c.quarantine(ctx, report); err != nil {
+			return c.finish(report), err
+		}
+	}
+	return c.finish(report), nil
+}
+
+// quarantine moves the corrupt families of a report to quarantine
+func (c *Checker) quarantine(ctx context.Context, report *Report) error {
+	done := make(map[string]bool)
+	for i := range report.Issues {
+		issue := &report.Issues[i]
+		if !issue.Corrupt {
+			continue
+		}
+		if !done[issue.FamilyID] {
+			if err := c.scanner.Quarantine(ctx, issue.FamilyID, issue.Kind+": "+issue.Message); err != nil {
+				return fmt.Errorf("failed to quarantine family %s: %w", issue.FamilyID, err)
+			}
+			c.logger.Info("Quarantined corrupt family", zap.String("family_id", issue.FamilyID))
+			done[issue.FamilyID] = true
+			report.Quarantined++
+		}
+		issue.Quarantined = true
+	}
+	return nil
+}
+
+// finish counts the issues of a report by kind and records when the check finished
+func (c *Checker) finish(report *Report) *Report {
+	for _, issue := range report.Issues {
+		report.IssuesByKind[issue.Kind]++
+	}
+	report.FinishedAt = time.Now().UTC()
+	return report
+}
+
+// check returns the issues of a single stored family
+func check(rec Record) []Issue {
+	corrupt := func(kind string, err error) []Issue {
+		return []Issue{{FamilyID: rec.FamilyID, Kind: kind, Message: err.Error(), Corrupt: true}}
+	}
+	switch {
+	case rec.DecodeErr != nil:
+		return corrupt(KindDecodeFailed, rec.DecodeErr)
+	case rec.InvariantErr != nil:
+		return corrupt(KindInvariantViolated, rec.InvariantErr)
+	case rec.Family == nil:
+		return corrupt(KindDecodeFailed, fmt.Errorf("family could not be read"))
+	}
+
+	// Member IDs identify parents and children in every operation, so they must be unique
+	var issues []Issue
+	seen := make(map[string]bool)
+	var ids []string
+	for _, p := range rec.Family.Parents() {
+		ids = append(ids, p.ID())
+	}
+	for _, ch := range rec.Family.Children() {
+		ids = append(ids, ch.ID())
+	}
+	for _, id := range ids {
+		if seen[id] {
+			issues = append(issues, Issue{
+				FamilyID: rec.FamilyID,
+				Kind:     KindDuplicateMemberID,
+				Message:  fmt.Sprintf("member ID %s is used more than once", id),
+				Corrupt:  true,
+			})
+		}
+		seen[id] = true
+	}
+	return issues
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package integrity
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScanner serves records from memory and records quarantined families
+type fakeScanner struct {
+	records       []Record
+	quarantined   []string
+	quarantineErr error
+}
+
+func (s *fakeScanner) ScanFamilies(ctx context.Context, fn func(Record) error) error {
+	for _, rec := range s.records {
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fakeScanner) Quarantine(ctx context.Context, familyID, reason string) error {
+	if s.quarantineErr != nil {
+		return s.quarantineErr
+	}
+	s.quarantined = append(s.quarantined, familyID)
+	return nil
+}
+
+// family creates a single-parent family with children of the given IDs
+func family(t *testing.T, id, parentID string, childIDs ...string) *entity.Family {
+	t.Helper()
+	p, err := entity.NewParent(parentID, "Pat", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	var children []*entity.Child
+	for _, childID := range childIDs {
+		c, err := entity.NewChild(childID, "Sam", "Doe", time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		require.NoError(t, err)
+		children = append(children, c)
+	}
+	fam, err := entity.NewFamily(id, entity.Single, []*entity.Parent{p}, children)
+	require.NoError(t, err)
+	return fam
+}
+
+func TestChecker_Run(t *testing.T) {
+	const (
+		f1, f2, f3, f4 = "f1000000-0000-4000-8000-000000000000", "f2000000-0000-4000-8000-000000000000", "f3000000-0000-4000-8000-000000000000", "f4000000-0000-4000-8000-000000000000"
+		p1, p2         = "a1000000-0000-4000-8000-000000000000", "a2000000-0000-4000-8000-000000000000"
+		c1, c2         = "c1000000-0000-4000-8000-000000000000", "c2000000-0000-4000-8000-000000000000"
+	)
+	scanner := &fakeScanner{records: []Record{
+		{FamilyID: f1, Family: family(t, f1, p1, c1)},
+		{FamilyID: f2, Family: family(t, f2, p2, c1, c2, c2)},
+		{FamilyID: f3, DecodeErr: errors.New("unexpected end of JSON input")},
+		{FamilyID: f4, InvariantErr: errors.New("family must have at least one parent")},
+	}}
+
+	report, err := NewChecker(scanner, Options{Quarantine: true}, nil).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Scanned)
+	assert.Equal(t, 1, report.Valid)
+	assert.Equal(t, 3, report.Corrupt)
+	assert.Equal(t, 3, report.Quarantined)
+	assert.Equal(t, []string{f2, f3, f4}, scanner.quarantined)
+	assert.Equal(t, map[string]int{
+		KindDuplicateMemberID:       1,
+		KindDecodeFailed:            1,
+		KindInvariantViolated:       1,
+		KindChildInMultipleFamilies: 2,
+	}, report.IssuesByKind)
+	assert.Contains(t, report.Issues, Issue{FamilyID: f2, Kind: KindDuplicateMemberID, Message: "member ID " + c2 + " is used more than once", Corrupt: true, Quarantined: true})
+	assert.Contains(t, report.Issues, Issue{FamilyID: f1, Kind: KindChildInMultipleFamilies, Message: "child " + c1 + " is in families [" + f1 + " " + f2 + "]"})
+}
+
+func TestChecker_QuarantineFailure(t *testing.T) {
+	scanner := &fakeScanner{
+		records:       []Record{{FamilyID: "f1", DecodeErr: errors.New("bad data")}},
+		quarantineErr: errors.New("connection refused"),
+	}
+
+	report, err := NewChecker(scanner, Options{Quarantine: true}, nil).Run(context.Background())
+	assert.ErrorContains(t, err, "failed to quarantine family f1")
+	require.NotNil(t, report)
+	assert.Equal(t, 1, report.Corrupt)
+	assert.Equal(t, 0, report.Quarantined)
+	assert.False(t, report.Issues[0].Quarantined)
+}
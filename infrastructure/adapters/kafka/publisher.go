@@ -0,0 +1,289 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package kafka publishes domain events to a Kafka topic, for the data lake.
+//
+// Events are sealed in the envelopes of the event schema registry and produced through the
+// v2 API of a Kafka REST Proxy. Each record is keyed by the ID of the family the event is
+// about, so that Kafka partitions the events by family and the events of a family are
+// consumed in order. Like the repositories, the publisher retries transient failures with
+// backoff and wraps its requests in a circuit breaker, so that an unavailable proxy fails
+// fast instead of slowing down every mutation.
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	circuit "github.com/abitofhelp/family-service/infrastructure/adapters/circuitwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/eventschema"
+	"github.com/abitofhelp/servicelib/retry"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Content types of the v2 API of the Kafka REST Proxy
+const (
+	contentType = "application/vnd.kafka.json.v2+json"
+	acceptType  = "application/vnd.kafka.v2+json"
+)
+
+// retriableErrorCode is the error code with which the proxy reports a record that failed
+// with a retriable error, such as a partition leader that is not available
+const retriableErrorCode = 2
+
+// defaultTimeout is the timeout of a request to the proxy if none is configured
+const defaultTimeout = 5 * time.Second
+
+// Outcomes of publications, as counted by the publications metric
+const (
+	OutcomePublished = "published"
+	OutcomeFailed    = "failed"
+)
+
+// publishedTotal counts the events published to Kafka by event type and outcome
+var publishedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "kafka_events_published_total",
+		Help: "Total number of domain events published to Kafka, by event type and by whether they were published or failed",
+	},
+	[]string{"event", "outcome"},
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(publishedTotal)
+}
+
+// Key returns the ID of the family an event is about, which keys its record. A child that
+// moves is keyed by the family it joined. Events of unknown types have no key and are
+// spread over the partitions.
+func Key(event events.Event) string {
+	switch e := event.(type) {
+	case events.ChildMoved:
+		return e.ToFamilyID
+	case events.FamilyQuarantined:
+		return e.FamilyID
+	case events.FamilyUnquarantined:
+		return e.FamilyID
+	case events.QuarantinedFamilyAccessed:
+		return e.FamilyID
+	case events.FamilyDuplicateSuspected:
+		return e.FamilyID
+	case events.FamilyDeleted:
+		return e.FamilyID
+	case events.FamilyRestored:
+		return e.FamilyID
+	default:
+		return ""
+	}
+}
+
+// record is a record of a produce request of the proxy
+type record struct {
+	Key   *string              `json:"key"` // Null to let Kafka pick the partition
+	Value eventschema.Envelope `json:"value"`
+}
+
+// produceRequest is the body of a produce request of the proxy
+type produceRequest struct {
+	Records []record `json:"records"`
+}
+
+// produceResponse is the body of the answer of the proxy to a produce request, with the
+// partition and offset, or the error, of each record
+type produceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode int    `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// proxyError is the body of the answer of the proxy to a request that failed
+type proxyError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// retriableError is a failure of the proxy that may succeed if the request is sent again
+type retriableError struct {
+	error
+}
+
+// Unwrap returns the failure
+func (e retriableError) Unwrap() error {
+	return e.error
+}
+
+// isRetryable reports whether a failed request is sent again
+func isRetryable(err error) bool {
+	var retriable retriableError
+	return errors.As(err, &retriable) || retry.IsNetworkError(err) || retry.IsTimeoutError(err)
+}
+
+// Publisher publishes domain events to a Kafka topic through a Kafka REST Proxy
+type Publisher struct {
+	url      string
+	topic    string
+	username string
+	password string
+	registry *eventschema.Registry
+	client   *http.Client
+
+	circuitBreaker *circuit.CircuitBreaker
+	retryConfig    retry.Config
+	logger         *zap.Logger
+}
+
+var _ ports.EventPublisher = (*Publisher)(nil)
+
+// NewPublisher creates a new publisher
+//
+// Parameters:
+//   - cfg: The configuration of the proxy and the topic
+//   - registry: The registry of the event types, which seals events in envelopes
+//   - circuitConfig: The configuration of the circuit breaker of the proxy
+//   - retryConfig: The configuration of the retries of transient failures
+//   - logger: Logger for published events and circuit breaker state changes
+//
+// Returns:
+//   - A new publisher
+//   - An error if the URL of the proxy is invalid or the topic is missing
+func NewPublisher(cfg config.KafkaConfig, registry *eventschema.Registry, circuitConfig *config.CircuitConfig, retryConfig config.RetryConfig, logger *zap.Logger) (*Publisher, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid kafka REST proxy URL: must be an absolute http or https URL")
+	}
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("no kafka topic is configured")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Publisher{
+		url:            strings.TrimSuffix(u.String(), "/") + "/topics/" + url.PathEscape(cfg.Topic),
+		topic:          cfg.Topic,
+		username:       cfg.Username,
+		password:       cfg.Password,
+		registry:       registry,
+		client:         &http.Client{Timeout: timeout},
+		circuitBreaker: circuit.NewCircuitBreaker("kafka", circuitConfig, logger),
+		retryConfig: retry.DefaultConfig().
+			WithMaxRetries(retryConfig.MaxRetries).
+			WithInitialBackoff(retryConfig.InitialBackoff).
+			WithMaxBackoff(retryConfig.MaxBackoff),
+		logger: logger,
+	}, nil
+}
+
+// Publish produces a record of the event to the topic, keyed by the ID of its family
+func (p *Publisher) Publish(ctx context.Context, event events.Event) error {
+	env, err := p.registry.Seal(event)
+	if err != nil {
+		return err
+	}
+	return p.publish(ctx, env, Key(event))
+}
+
+// PublishEnvelope produces a record of an event that has already been sealed, such as an
+// event relayed from the outbox with its deduplication key, keyed by the ID of its family
+func (p *Publisher) PublishEnvelope(ctx context.Context, env eventschema.Envelope) error {
+	event, err := p.registry.Decode(env)
+	if err != nil {
+		return err
+	}
+	return p.publish(ctx, env, Key(event))
+}
+
+// publish produces a record of an envelope to the topic with the given key; an empty key
+// lets Kafka pick the partition
+func (p *Publisher) publish(ctx context.Context, env eventschema.Envelope, key string) error {
+	rec := record{Value: env}
+	if key != "" {
+		rec.Key = &key
+	}
+	body, err := json.Marshal(produceRequest{Records: []record{rec}})
+	if err != nil {
+		return fmt.Errorf("failed to encode kafka record of event %s: %w", env.Type, err)
+	}
+
+	operation := func(ctx context.Context) error {
+		return p.produce(ctx, body)
+	}
+	err = p.circuitBreaker.Execute(ctx, "Publish", func(ctx context.Context) error {
+		return retry.Do(ctx, operation, p.retryConfig, isRetryable)
+	})
+	if err != nil {
+		publishedTotal.WithLabelValues(env.Type, OutcomeFailed).Inc()
+		return fmt.Errorf("failed to publish event %s to kafka topic %s: %w", env.Type, p.topic, err)
+	}
+	publishedTotal.WithLabelValues(env.Type, OutcomePublished).Inc()
+	return nil
+}
+
+// produce sends a produce request to the proxy
+func (p *Publisher) produce(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create kafka REST proxy request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", acceptType)
+	if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return retriableError{fmt.Errorf("kafka REST proxy request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+	answer, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var perr proxyError
+		_ = json.Unmarshal(answer, &perr)
+		err := fmt.Errorf("kafka REST proxy answered with status %d: %s", resp.StatusCode, perr.Message)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return retriableError{err}
+		}
+		return err
+	}
+
+	var produced produceResponse
+	if err := json.Unmarshal(answer, &produced); err != nil {
+		return fmt.Errorf("failed to decode kafka REST proxy answer: %w", err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.Error == "" && offset.ErrorCode == 0 {
+			p.logger.Debug("Published event to kafka",
+				zap.String("topic", p.topic),
+				zap.Int("partition", offset.Partition),
+				zap.Int64("offset", offset.Offset))
+			continue
+		}
+		err := fmt.Errorf("kafka rejected the record: %s (error code %d)", offset.Error, offset.ErrorCode)
+		if offset.ErrorCode == retriableErrorCode {
+			return retriableError{err}
+		}
+		return err
+	}
+	return nil
+}
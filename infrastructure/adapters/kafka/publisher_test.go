@@ -0,0 +1,174 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package kafka
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/events"
+	circuit "github.com/abitofhelp/family-service/infrastructure/adapters/circuitwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/eventschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// proxy is a Kafka REST Proxy that answers with a sequence of statuses and bodies, and
+// records the last request
+type proxy struct {
+	*httptest.Server
+	requests atomic.Int32
+	answers  []answer
+	req      *http.Request
+	body     []byte
+}
+
+// answer is an answer of the proxy
+type answer struct {
+	status int
+	body   string
+}
+
+// produced is the answer of the proxy to a record that was produced
+var produced = answer{http.StatusOK, `{"offsets":[{"partition":3,"offset":42,"error_code":null,"error":null}]}`}
+
+func newProxy(t *testing.T, answers ...answer) *proxy {
+	p := &proxy{answers: answers}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(p.requests.Add(1)) - 1
+		p.req = r
+		p.body, _ = io.ReadAll(r.Body)
+		a := p.answers[len(p.answers)-1]
+		if n < len(p.answers) {
+			a = p.answers[n]
+		}
+		w.WriteHeader(a.status)
+		_, _ = io.WriteString(w, a.body)
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+var (
+	at         = time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	childMoved = events.ChildMoved{ChildID: "child-1", FromFamilyID: "family-1", ToFamilyID: "family-2", At: at}
+	retries    = config.RetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+)
+
+func newPublisher(t *testing.T, cfg config.KafkaConfig, circuitConfig config.CircuitConfig) *Publisher {
+	p, err := NewPublisher(cfg, eventschema.NewDefaultRegistry("family-service/1.0.0"), &circuitConfig, retries, nil)
+	require.NoError(t, err)
+	return p
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	proxy := newProxy(t, produced)
+	p := newPublisher(t, config.KafkaConfig{URL: proxy.URL + "/", Topic: "family.events", Username: "family-service", Password: "s3cret"}, config.CircuitConfig{})
+
+	require.NoError(t, p.Publish(context.Background(), childMoved))
+	assert.Equal(t, "/topics/family.events", proxy.req.URL.Path)
+	assert.Equal(t, "application/vnd.kafka.json.v2+json", proxy.req.Header.Get("Content-Type"))
+	user, password, ok := proxy.req.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "family-service", user)
+	assert.Equal(t, "s3cret", password)
+	assert.JSONEq(t, `{"records": [{
+		"key": "family-2",
+		"value": {
+			"type": "child_moved",
+			"version": 1,
+			"occurred_at": "2025-03-04T05:06:07Z",
+			"producer": "family-service/1.0.0",
+			"payload": {"childId": "child-1", "fromFamilyId": "family-1", "toFamilyId": "family-2"}
+		}
+	}]}`, string(proxy.body))
+}
+
+func TestPublisher_PublishEnvelope(t *testing.T) {
+	proxy := newProxy(t, produced)
+	p := newPublisher(t, config.KafkaConfig{URL: proxy.URL, Topic: "family.events"}, config.CircuitConfig{})
+	env, err := eventschema.NewDefaultRegistry("family-service/0.9.0").Seal(childMoved)
+	require.NoError(t, err)
+	env.ID = "message-1"
+
+	// The envelope is produced as it was sealed, with its deduplication key
+	require.NoError(t, p.PublishEnvelope(context.Background(), env))
+	assert.JSONEq(t, `{"records": [{
+		"key": "family-2",
+		"value": {
+			"id": "message-1",
+			"type": "child_moved",
+			"version": 1,
+			"occurred_at": "2025-03-04T05:06:07Z",
+			"producer": "family-service/0.9.0",
+			"payload": {"childId": "child-1", "fromFamilyId": "family-1", "toFamilyId": "family-2"}
+		}
+	}]}`, string(proxy.body))
+
+	// Envelopes of unknown event types are not produced
+	env.Type = "family_teleported"
+	assert.ErrorContains(t, p.PublishEnvelope(context.Background(), env), "unknown event type")
+	assert.Equal(t, int32(1), proxy.requests.Load())
+}
+
+func TestPublisher_Retries(t *testing.T) {
+	// Failures of the proxy and retriable errors of a record are retried
+	proxy := newProxy(t,
+		answer{http.StatusServiceUnavailable, `{"error_code":50302,"message":"broker unavailable"}`},
+		answer{http.StatusOK, `{"offsets":[{"partition":null,"offset":null,"error_code":2,"error":"leader not available"}]}`},
+		produced)
+	p := newPublisher(t, config.KafkaConfig{URL: proxy.URL, Topic: "family.events"}, config.CircuitConfig{})
+	require.NoError(t, p.Publish(context.Background(), childMoved))
+	assert.Equal(t, int32(3), proxy.requests.Load())
+
+	// Rejected requests and records are not
+	for _, rejected := range []answer{
+		{http.StatusNotFound, `{"error_code":40401,"message":"Topic not found"}`},
+		{http.StatusOK, `{"offsets":[{"partition":null,"offset":null,"error_code":1,"error":"record too large"}]}`},
+	} {
+		proxy := newProxy(t, rejected)
+		p := newPublisher(t, config.KafkaConfig{URL: proxy.URL, Topic: "family.events"}, config.CircuitConfig{})
+		assert.Error(t, p.Publish(context.Background(), childMoved))
+		assert.Equal(t, int32(1), proxy.requests.Load())
+	}
+}
+
+func TestPublisher_CircuitBreaker(t *testing.T) {
+	proxy := newProxy(t, answer{http.StatusBadRequest, `{"error_code":40001,"message":"invalid record"}`})
+	p := newPublisher(t, config.KafkaConfig{URL: proxy.URL, Topic: "family.events"}, config.CircuitConfig{
+		Enabled:         true,
+		Timeout:         time.Second,
+		MaxConcurrent:   10,
+		ErrorThreshold:  0.5,
+		VolumeThreshold: 2,
+		SleepWindow:     time.Minute,
+	})
+
+	for i := 0; i < 2; i++ {
+		assert.ErrorContains(t, p.Publish(context.Background(), childMoved), "invalid record")
+	}
+	err := p.Publish(context.Background(), childMoved)
+	assert.True(t, circuit.IsOpenError(err), "expected an open circuit, got %v", err)
+	assert.Equal(t, int32(2), proxy.requests.Load())
+}
+
+func TestNewPublisher(t *testing.T) {
+	registry := eventschema.NewDefaultRegistry("family-service/1.0.0")
+	_, err := NewPublisher(config.KafkaConfig{URL: "kafka-rest:8082", Topic: "family.events"}, registry, &config.CircuitConfig{}, retries, nil)
+	assert.ErrorContains(t, err, "invalid kafka REST proxy URL")
+
+	_, err = NewPublisher(config.KafkaConfig{URL: "http://kafka-rest:8082"}, registry, &config.CircuitConfig{}, retries, nil)
+	assert.ErrorContains(t, err, "no kafka topic is configured")
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "family-2", Key(childMoved))
+	assert.Equal(t, "family-1", Key(events.FamilyQuarantined{FamilyID: "family-1"}))
+	assert.Equal(t, "family-1", Key(events.FamilyDuplicateSuspected{FamilyID: "family-1", DuplicateOf: []string{"family-0"}}))
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package loggingwrapper
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Log formats
+const (
+	FormatJSON    = "json"    // One JSON object per line, for log collectors
+	FormatConsole = "console" // Human-readable lines with colored levels, for developers
+)
+
+// Components whose log level can be configured separately from the main level
+const (
+	ComponentRepository = "repository"
+	ComponentResolver   = "resolver"
+	ComponentAuth       = "auth"
+)
+
+// Build creates the main logger from the log configuration.
+//
+// The format defaults to console in development and JSON otherwise. Sampling, if enabled,
+// limits how many entries with the same level and message are written per tick. Loggers
+// derived with Component use the level configured for their component.
+//
+// Returns:
+//   - The main logger
+//   - An error if a level or the format is invalid
+func Build(cfg config.LogConfig) (*zap.Logger, error) {
+	return build(cfg, zapcore.Lock(os.Stdout))
+}
+
+// build creates the main logger from the log configuration, writing to out
+func build(cfg config.LogConfig, out zapcore.WriteSyncer) (*zap.Logger, error) {
+	level, err := parseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	components := make(map[string]zapcore.Level)
+	for name, text := range map[string]string{
+		ComponentRepository: cfg.Components.Repository,
+		ComponentResolver:   cfg.Components.Resolver,
+		ComponentAuth:       cfg.Components.Auth,
+	} {
+		if text == "" {
+			continue
+		}
+		componentLevel, err := parseLevel(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level of component %s: %w", name, err)
+		}
+		components[name] = componentLevel
+	}
+
+	encoder, err := newEncoder(cfg.Format, cfg.Development)
+	if err != nil {
+		return nil, err
+	}
+
+	// The output accepts every level; levelCore filters by the level of the logger's component
+	var core zapcore.Core = zapcore.NewCore(encoder, out, zapcore.DebugLevel)
+	if cfg.Sampling.Enabled {
+		tick := cfg.Sampling.Tick
+		if tick <= 0 {
+			tick = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, cfg.Sampling.Initial, cfg.Sampling.Thereafter)
+	}
+	core = &levelCore{Core: core, level: level, components: components}
+
+	options := []zap.Option{zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)}
+	if cfg.Development {
+		options = append(options, zap.Development())
+	}
+	return zap.New(core, options...), nil
+}
+
+// Component returns a logger for a component, named after it, that logs at the level
+// configured for the component, or at the level of logger if none is configured.
+// Loggers not created by Build are only named.
+func Component(logger *zap.Logger, name string) *zap.Logger {
+	return logger.Named(name).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		lc, ok := core.(*levelCore)
+		if !ok {
+			return core
+		}
+		level, ok := lc.components[name]
+		if !ok {
+			return core
+		}
+		return &levelCore{Core: lc.Core, level: level, components: lc.components}
+	}))
+}
+
+// parseLevel parses the name of a log level
+func parseLevel(text string) (zapcore.Level, error) {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(text)); err != nil {
+		return level, fmt.Errorf("invalid log level %q: %w", text, err)
+	}
+	return level, nil
+}
+
+// newEncoder creates the encoder of a log format
+func newEncoder(format string, development bool) (zapcore.Encoder, error) {
+	if format == "" {
+		format = FormatJSON
+		if development {
+			format = FormatConsole
+		}
+	}
+
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		FunctionKey:    zapcore.OmitKey,
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+
+	switch format {
+	case FormatJSON:
+		return zapcore.NewJSONEncoder(encoderConfig), nil
+	case FormatConsole:
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		return zapcore.NewConsoleEncoder(encoderConfig), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+}
+
+// levelCore filters the entries of a core by level. It keeps the levels of the components,
+// so that Component can replace the level of the loggers derived from it.
+type levelCore struct {
+	zapcore.Core
+	level      zapcore.Level
+	components map[string]zapcore.Level
+}
+
+// Enabled reports whether entries at the level are written
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+// Level returns the minimum level of the entries written
+func (c *levelCore) Level() zapcore.Level {
+	return c.level
+}
+
+// With adds structured context to the core
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level, components: c.components}
+}
+
+// Check adds the core to the checked entry if the entry's level is enabled
+func (c *levelCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return ce
+	}
+	return c.Core.Check(entry, ce)
+}
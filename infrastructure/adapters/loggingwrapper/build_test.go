@@ -0,0 +1,119 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package loggingwrapper
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// buildToBuffer builds a logger that writes to a buffer
+func buildToBuffer(t *testing.T, cfg config.LogConfig) (*zap.Logger, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, err := build(cfg, zapcore.AddSync(&buf))
+	require.NoError(t, err)
+	return logger, &buf
+}
+
+// lines returns the non-empty lines written to buf
+func lines(buf *bytes.Buffer) []string {
+	var result []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+func TestBuild_Format(t *testing.T) {
+	t.Run("JSON by default in production", func(t *testing.T) {
+		logger, buf := buildToBuffer(t, config.LogConfig{Level: "info"})
+		logger.Info("started")
+
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "started", entry["msg"])
+		assert.Equal(t, "info", entry["level"])
+	})
+
+	t.Run("Console by default in development", func(t *testing.T) {
+		logger, buf := buildToBuffer(t, config.LogConfig{Level: "info", Development: true})
+		logger.Info("started")
+
+		assert.False(t, json.Valid(buf.Bytes()))
+		assert.Contains(t, buf.String(), "INFO")
+		assert.Contains(t, buf.String(), "started")
+	})
+
+	t.Run("Explicit format overrides development", func(t *testing.T) {
+		logger, buf := buildToBuffer(t, config.LogConfig{Level: "info", Development: true, Format: FormatJSON})
+		logger.Info("started")
+
+		assert.True(t, json.Valid(buf.Bytes()))
+	})
+
+	t.Run("Invalid settings are rejected", func(t *testing.T) {
+		_, err := build(config.LogConfig{Level: "info", Format: "xml"}, zapcore.AddSync(&bytes.Buffer{}))
+		assert.Error(t, err)
+
+		_, err = build(config.LogConfig{Level: "verbose"}, zapcore.AddSync(&bytes.Buffer{}))
+		assert.Error(t, err)
+
+		_, err = build(config.LogConfig{Level: "info", Components: config.LogComponentConfig{Auth: "verbose"}}, zapcore.AddSync(&bytes.Buffer{}))
+		assert.ErrorContains(t, err, "component auth")
+	})
+}
+
+func TestBuild_Sampling(t *testing.T) {
+	logger, buf := buildToBuffer(t, config.LogConfig{
+		Level:    "info",
+		Sampling: config.LogSamplingConfig{Enabled: true, Initial: 2, Thereafter: 3, Tick: time.Minute},
+	})
+
+	for i := 0; i < 8; i++ {
+		logger.Info("repeated")
+	}
+	logger.Info("different")
+
+	// The first 2 entries, then every 3rd: entries 1, 2, 5, and 8, plus the different message
+	assert.Len(t, lines(buf), 5)
+}
+
+func TestComponent(t *testing.T) {
+	logger, buf := buildToBuffer(t, config.LogConfig{
+		Level:      "info",
+		Components: config.LogComponentConfig{Repository: "debug", Auth: "error"},
+	})
+
+	logger.Debug("main debug")
+	Component(logger, ComponentRepository).Debug("repository debug")
+	Component(logger, ComponentRepository).With(zap.String("table", "families")).Debug("repository debug with fields")
+	Component(logger, ComponentAuth).Warn("auth warning")
+	Component(logger, ComponentAuth).Error("auth error")
+	Component(logger, ComponentResolver).Debug("resolver debug")
+	Component(logger, ComponentResolver).Info("resolver info")
+
+	output := buf.String()
+	assert.NotContains(t, output, "main debug")
+	assert.Contains(t, output, "repository debug")
+	assert.Contains(t, output, "repository debug with fields")
+	assert.NotContains(t, output, "auth warning")
+	assert.Contains(t, output, "auth error")
+	assert.NotContains(t, output, "resolver debug", "components without a level log at the main level")
+	assert.Contains(t, output, "resolver info")
+	assert.Contains(t, output, `"logger":"repository"`)
+
+	// Loggers not created by Build are only named
+	assert.NotNil(t, Component(zap.NewNop(), ComponentAuth))
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package migration plans the schema migrations of the repositories.
+//
+// Each repository adapter brings its schema up to date by itself, with idempotent steps
+// such as creating a table or an index, the first time it is used. Before a release is
+// rolled out to production, operators want to know what those steps will do to the
+// database. A Planner inspects the database without changing it and returns a Plan: every
+// step of the schema with whether it has been applied, the DDL it runs, and the locks it
+// takes on existing data, together with the estimated size of the tables it locks.
+package migration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Step is a step of the schema of a repository
+type Step struct {
+	Name    string `json:"name"`
+	Table   string `json:"table"`   // Table or collection changed by the step
+	DDL     string `json:"ddl"`     // Statement or command run by the step
+	Lock    string `json:"lock"`    // Locks taken on existing data while the step runs
+	Applied bool   `json:"applied"` // Whether the database already has the result of the step
+}
+
+// Plan is the state of the schema of a repository
+type Plan struct {
+	Backend string           `json:"backend"`
+	Steps   []Step           `json:"steps"`
+	Rows    map[string]int64 `json:"rows"` // Estimated number of rows of the existing tables
+}
+
+// Planner is implemented by repositories whose schema migrations can be planned
+type Planner interface {
+	// PlanSchema inspects the database and returns the plan of its schema. It never
+	// changes the database.
+	PlanSchema(ctx context.Context) (*Plan, error)
+
+	// ApplySchema applies the pending steps of the schema
+	ApplySchema(ctx context.Context) error
+}
+
+// Pending returns the steps that have not been applied, in the order they run
+func (p *Plan) Pending() []Step {
+	var pending []Step
+	for _, step := range p.Steps {
+		if !step.Applied {
+			pending = append(pending, step)
+		}
+	}
+	return pending
+}
+
+// Write writes the pending steps of the plan, their DDL, and their lock impact as text
+func (p *Plan) Write(w io.Writer) error {
+	var b strings.Builder
+	pending := p.Pending()
+	fmt.Fprintf(&b, "Backend: %s\n", p.Backend)
+	fmt.Fprintf(&b, "Pending migrations: %d of %d\n", len(pending), len(p.Steps))
+	for i, step := range pending {
+		fmt.Fprintf(&b, "\n%d. %s\n", i+1, step.Name)
+		if rows, ok := p.Rows[step.Table]; ok {
+			fmt.Fprintf(&b, "   Table: %s (about %d rows)\n", step.Table, rows)
+		} else {
+			fmt.Fprintf(&b, "   Table: %s (new)\n", step.Table)
+		}
+		fmt.Fprintf(&b, "   Lock:  %s\n", step.Lock)
+		b.WriteString("   DDL:\n")
+		for _, line := range strings.Split(dedent(step.DDL), "\n") {
+			fmt.Fprintf(&b, "     %s\n", line)
+		}
+	}
+	if len(pending) == 0 {
+		b.WriteString("\nThe schema is up to date.\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dedent removes the blank lines around a statement and the indentation common to its lines
+func dedent(ddl string) string {
+	lines := strings.Split(strings.TrimRight(ddl, " \t\n"), "\n")
+	for len(lines) > 1 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		}
+		lines[i] = strings.TrimRight(lines[i], " \t")
+	}
+	return strings.Join(lines, "\n")
+}
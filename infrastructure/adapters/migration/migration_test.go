@@ -0,0 +1,76 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan_Pending(t *testing.T) {
+	plan := &Plan{Steps: []Step{
+		{Name: "a", Applied: true},
+		{Name: "b"},
+		{Name: "c", Applied: true},
+		{Name: "d"},
+	}}
+
+	pending := plan.Pending()
+
+	require.Len(t, pending, 2)
+	assert.Equal(t, "b", pending[0].Name)
+	assert.Equal(t, "d", pending[1].Name)
+}
+
+func TestPlan_Write(t *testing.T) {
+	plan := &Plan{
+		Backend: "postgres",
+		Rows:    map[string]int64{"families": 1200},
+		Steps: []Step{
+			{Name: "Create table families", Table: "families", DDL: "CREATE TABLE families (id TEXT)", Applied: true},
+			{
+				Name:  "Add column external_ids",
+				Table: "families",
+				DDL: `
+		ALTER TABLE families
+		    ADD COLUMN external_ids JSONB
+	`,
+				Lock: "ACCESS EXCLUSIVE",
+			},
+			{Name: "Create table admin_quarantines", Table: "admin_quarantines", DDL: "CREATE TABLE admin_quarantines (id TEXT)", Lock: "None"},
+		},
+	}
+
+	var b strings.Builder
+	require.NoError(t, plan.Write(&b))
+
+	expected := `Backend: postgres
+Pending migrations: 2 of 3
+
+1. Add column external_ids
+   Table: families (about 1200 rows)
+   Lock:  ACCESS EXCLUSIVE
+   DDL:
+     ALTER TABLE families
+         ADD COLUMN external_ids JSONB
+
+2. Create table admin_quarantines
+   Table: admin_quarantines (new)
+   Lock:  None
+   DDL:
+     CREATE TABLE admin_quarantines (id TEXT)
+`
+	assert.Equal(t, expected, b.String())
+}
+
+func TestPlan_Write_UpToDate(t *testing.T) {
+	plan := &Plan{Backend: "sqlite", Steps: []Step{{Name: "a", Applied: true}}}
+
+	var b strings.Builder
+	require.NoError(t, plan.Write(&b))
+
+	assert.Equal(t, "Backend: sqlite\nPending migrations: 0 of 1\n\nThe schema is up to date.\n", b.String())
+}
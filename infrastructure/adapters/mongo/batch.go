@@ -0,0 +1,154 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Bounds of the batch size when they are not configured
+const (
+	defaultBatchSize    = 100
+	defaultMinBatchSize = 10
+	defaultMaxBatchSize = 1000
+)
+
+var (
+	// batchSizeChosen records the batch sizes chosen for queries and batches of documents
+	batchSizeChosen = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mongodb_batch_size",
+			Help:    "Batch sizes chosen for the queries and batches of documents of MongoDB reads of many families, by operation",
+			Buckets: []float64{10, 25, 50, 100, 250, 500, 1000, 2500, 5000},
+		},
+		[]string{"operation"},
+	)
+
+	// batchSizeCurrent is the batch size that the next query starts with
+	batchSizeCurrent = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mongodb_batch_size_current",
+			Help: "Batch size that the next MongoDB read of many families starts with",
+		},
+	)
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(batchSizeChosen, batchSizeCurrent)
+}
+
+// batchSizer chooses the batch size of reads of many families. The size is the number of
+// documents that the server returns per batch and that are decoded together, so it bounds
+// the memory of a read in progress. When it is adaptive, the size is tuned after every
+// batch, so that a batch holds about the target number of bytes and is read within the
+// target latency: it shrinks when documents are large or slow to read, and grows when they
+// are small and fast. A batchSizer is safe for concurrent use.
+type batchSizer struct {
+	cfg config.MongoBatchConfig
+
+	mu   sync.Mutex
+	size int
+}
+
+// newBatchSizer creates a batch sizer, filling in the bounds that are not configured and
+// keeping the initial size within them
+func newBatchSizer(cfg config.MongoBatchConfig) *batchSizer {
+	if cfg.Initial <= 0 {
+		cfg.Initial = defaultBatchSize
+	}
+	if cfg.Min <= 0 {
+		cfg.Min = min(defaultMinBatchSize, cfg.Initial)
+	}
+	if cfg.Max <= 0 {
+		cfg.Max = max(defaultMaxBatchSize, cfg.Initial)
+	}
+	if cfg.Max < cfg.Min {
+		cfg.Max = cfg.Min
+	}
+	s := &batchSizer{cfg: cfg, size: min(max(cfg.Initial, cfg.Min), cfg.Max)}
+	batchSizeCurrent.Set(float64(s.size))
+	return s
+}
+
+// Size returns the current batch size
+func (s *batchSizer) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Observe tunes the batch size after a batch of documents was read, and returns the size
+// of the next batch. The size changes at most twofold per batch, so that one unusual batch
+// does not swing it.
+//
+// Parameters:
+//   - docs: The number of documents of the batch
+//   - bytes: The total size of the documents of the batch
+//   - elapsed: The time it took to read the batch
+func (s *batchSizer) Observe(docs, bytes int, elapsed time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.cfg.Adaptive || docs == 0 {
+		return s.size
+	}
+
+	// The largest batch that stays within each target, assuming later documents are like
+	// those of this batch
+	target := s.cfg.Max
+	if s.cfg.TargetBytes > 0 && bytes > 0 {
+		target = min(target, int(int64(s.cfg.TargetBytes)*int64(docs)/int64(bytes)))
+	}
+	if s.cfg.TargetLatency > 0 && elapsed > 0 {
+		target = min(target, int(int64(docs)*int64(s.cfg.TargetLatency)/int64(elapsed)))
+	}
+
+	next := min(max(target, s.size/2), s.size*2)
+	s.size = min(max(next, s.cfg.Min), s.cfg.Max)
+	batchSizeCurrent.Set(float64(s.size))
+	return s.size
+}
+
+// meter returns a meter of the batches of a read, which starts with the current batch size
+func (s *batchSizer) meter(operation string) *batchMeter {
+	size := s.Size()
+	batchSizeChosen.WithLabelValues(operation).Observe(float64(size))
+	return &batchMeter{sizer: s, operation: operation, size: size}
+}
+
+// batchMeter measures the batches of documents of a read for its batch sizer
+type batchMeter struct {
+	sizer     *batchSizer
+	operation string
+	size      int       // Size of the current batch
+	bytes     int       // Total size of the documents of the current batch
+	start     time.Time // Time at which the first document of the current batch was read
+}
+
+// Read records a document of the current batch
+func (m *batchMeter) Read(doc bson.Raw) {
+	if m.start.IsZero() {
+		m.start = time.Now()
+	}
+	m.bytes += len(doc)
+}
+
+// Full reports whether a batch of docs documents is full
+func (m *batchMeter) Full(docs int) bool {
+	return docs >= m.size
+}
+
+// Done records that the current batch of docs documents is complete and tunes the size of
+// the next batch
+func (m *batchMeter) Done(docs int) {
+	next := m.sizer.Observe(docs, m.bytes, time.Since(m.start))
+	if next != m.size {
+		batchSizeChosen.WithLabelValues(m.operation).Observe(float64(next))
+	}
+	m.size, m.bytes, m.start = next, 0, time.Time{}
+}
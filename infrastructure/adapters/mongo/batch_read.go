@@ -0,0 +1,34 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"sort"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// Ensure MongoFamilyRepository implements ports.FamilyBatchReader
+var _ ports.FamilyBatchReader = (*MongoFamilyRepository)(nil)
+
+// GetByIDs reads the families with the given IDs, with their versions, in ascending ID
+// order with one query on the family_id index
+func (r *MongoFamilyRepository) GetByIDs(ctx context.Context, ids []string) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Getting families by IDs from MongoDB", zap.Int("count", len(ids)))
+
+	if len(ids) == 0 {
+		return []*entity.Family{}, nil
+	}
+	families, err := r.findFamilies(ctx, "GetByIDs", bson.M{"family_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	// Documents are decoded in batches, which need not keep the order of the cursor
+	sort.Slice(families, func(i, j int) bool { return families[i].ID() < families[j].ID() })
+	return families, nil
+}
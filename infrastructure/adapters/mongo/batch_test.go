@@ -0,0 +1,67 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchSizer_Observe(t *testing.T) {
+	s := newBatchSizer(config.MongoBatchConfig{
+		Adaptive:      true,
+		Initial:       100,
+		Min:           10,
+		Max:           400,
+		TargetBytes:   1 << 20,
+		TargetLatency: 100 * time.Millisecond,
+	})
+	assert.Equal(t, 100, s.Size())
+
+	// Small documents read quickly grow the batch, at most twofold per batch and up to the maximum
+	assert.Equal(t, 200, s.Observe(100, 100*1024, 10*time.Millisecond))
+	assert.Equal(t, 400, s.Observe(200, 200*1024, 20*time.Millisecond))
+	assert.Equal(t, 400, s.Observe(400, 400*1024, 40*time.Millisecond))
+
+	// Large documents shrink it, at most by half per batch and down to the minimum
+	assert.Equal(t, 200, s.Observe(400, 400*(256<<10), 40*time.Millisecond))
+	assert.Equal(t, 100, s.Observe(200, 200*(256<<10), 20*time.Millisecond))
+	assert.Equal(t, 50, s.Observe(100, 100*(256<<10), 10*time.Millisecond))
+
+	// The size settles where a batch holds about the target number of bytes
+	assert.Equal(t, 64, s.Observe(50, 50*(16<<10), 5*time.Millisecond))
+	assert.Equal(t, 64, s.Observe(64, 64*(16<<10), 5*time.Millisecond))
+
+	// Slow reads shrink it as well
+	assert.Equal(t, 32, s.Observe(64, 64*1024, time.Second))
+	assert.Equal(t, 16, s.Observe(32, 32*1024, time.Second))
+	assert.Equal(t, 10, s.Observe(16, 16*1024, time.Second))
+}
+
+func TestBatchSizer_Fixed(t *testing.T) {
+	s := newBatchSizer(config.MongoBatchConfig{Initial: 50, TargetBytes: 1024})
+	assert.Equal(t, 50, s.Observe(50, 50*(1<<20), time.Second))
+
+	// Without configuration, reads use 100 documents per batch
+	assert.Equal(t, 100, newBatchSizer(config.MongoBatchConfig{}).Size())
+
+	// The initial size is kept within the bounds
+	assert.Equal(t, 20, newBatchSizer(config.MongoBatchConfig{Initial: 100, Min: 5, Max: 20}).Size())
+}
+
+func TestBatchMeter(t *testing.T) {
+	s := newBatchSizer(config.MongoBatchConfig{Adaptive: true, Initial: 2, Min: 1, Max: 8, TargetBytes: 1 << 20})
+	m := s.meter("GetAll")
+	assert.False(t, m.Full(1))
+	m.Read(make([]byte, 64))
+	m.Read(make([]byte, 64))
+	assert.True(t, m.Full(2))
+
+	m.Done(2)
+	assert.Equal(t, 4, m.size)
+	assert.Zero(t, m.bytes)
+	assert.Equal(t, 4, s.Size())
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/servicelib/errors"
+)
+
+// Consents are stored in the consents field of child documents. It is omitted for
+// children without consents, so documents written before consents were introduced are
+// read unchanged.
+
+// ConsentDocument represents how a consent granted for a child is stored in MongoDB
+type ConsentDocument struct {
+	GrantedBy string   `bson:"grantedBy"`
+	GrantedAt string   `bson:"grantedAt"`
+	Scopes    []string `bson:"scopes"`
+	ExpiredAt *string  `bson:"expiredAt,omitempty"`
+}
+
+// consentsToDocuments converts a child's consents to documents
+func consentsToDocuments(consents []entity.Consent) []ConsentDocument {
+	if len(consents) == 0 {
+		return nil
+	}
+	docs := make([]ConsentDocument, 0, len(consents))
+	for _, consent := range consents {
+		scopes := make([]string, 0, len(consent.Scopes))
+		for _, scope := range consent.Scopes {
+			scopes = append(scopes, string(scope))
+		}
+		var expiredAt *string
+		if consent.ExpiredAt != nil {
+			str := consent.ExpiredAt.Format(time.RFC3339)
+			expiredAt = &str
+		}
+		docs = append(docs, ConsentDocument{
+			GrantedBy: consent.GrantedBy,
+			GrantedAt: consent.GrantedAt.Format(time.RFC3339),
+			Scopes:    scopes,
+			ExpiredAt: expiredAt,
+		})
+	}
+	return docs
+}
+
+// setChildConsents restores the consents of a child from a document
+func setChildConsents(child *entity.Child, docs []ConsentDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	consents := make([]entity.Consent, 0, len(docs))
+	for _, doc := range docs {
+		grantedAt, err := time.Parse(time.RFC3339, doc.GrantedAt)
+		if err != nil {
+			return errors.NewDatabaseError("invalid consent grant time format", "parse", "families", err)
+		}
+		var expiredAt *time.Time
+		if doc.ExpiredAt != nil {
+			parsed, err := time.Parse(time.RFC3339, *doc.ExpiredAt)
+			if err != nil {
+				return errors.NewDatabaseError("invalid consent expiry time format", "parse", "families", err)
+			}
+			expiredAt = &parsed
+		}
+		scopes := make([]entity.ConsentScope, 0, len(doc.Scopes))
+		for _, scope := range doc.Scopes {
+			scopes = append(scopes, entity.ConsentScope(scope))
+		}
+		consents = append(consents, entity.Consent{
+			GrantedBy: doc.GrantedBy,
+			GrantedAt: grantedAt,
+			Scopes:    scopes,
+			ExpiredAt: expiredAt,
+		})
+	}
+	return child.SetConsents(consents)
+}
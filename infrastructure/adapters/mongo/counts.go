@@ -0,0 +1,87 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+
+	"github.com/abitofhelp/servicelib/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CountFamilies counts the families that are not deleted
+func (r *MongoFamilyRepository) CountFamilies(ctx context.Context) (int, error) {
+	r.logger.Debug(ctx, "Counting families in MongoDB")
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, r.defaultTimeout)
+	defer cancel()
+
+	var count int64
+	err := r.protect(ctxWithTimeout, "CountFamilies", func(ctx context.Context) error {
+		var err error
+		count, err = r.Collection.CountDocuments(ctx, inTenant(ctx, notDeleted(bson.M{})))
+		if err != nil {
+			return errors.NewDatabaseError("failed to count families", "query", "families", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// CountParents counts the distinct parents of the families that are not deleted
+func (r *MongoFamilyRepository) CountParents(ctx context.Context) (int, error) {
+	r.logger.Debug(ctx, "Counting parents in MongoDB")
+	return r.countMembers(ctx, "CountParents", "parents")
+}
+
+// CountChildren counts the distinct children of the families that are not deleted
+func (r *MongoFamilyRepository) CountChildren(ctx context.Context) (int, error) {
+	r.logger.Debug(ctx, "Counting children in MongoDB")
+	return r.countMembers(ctx, "CountChildren", "children")
+}
+
+// countMembers counts the distinct IDs of the members of a field with an aggregation
+// pipeline, so that a member of several families, such as a remarried parent, is counted once
+func (r *MongoFamilyRepository) countMembers(ctx context.Context, operation, field string) (int, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, r.defaultTimeout)
+	defer cancel()
+
+	pipeline := bson.A{
+		bson.M{"$match": inTenant(ctx, notDeleted(bson.M{}))},
+		bson.M{"$unwind": "$" + field},
+		bson.M{"$group": bson.M{"_id": "$" + field + ".id"}},
+		bson.M{"$count": "count"},
+	}
+
+	var count int
+	err := r.protect(ctxWithTimeout, operation, func(ctx context.Context) error {
+		cursor, err := r.Collection.Aggregate(ctx, pipeline)
+		if err != nil {
+			return errors.NewDatabaseError("failed to count "+field, "query", "families", err)
+		}
+		defer cursor.Close(ctx)
+
+		// $count returns no document when nothing is counted
+		count = 0
+		if cursor.Next(ctx) {
+			var result struct {
+				Count int `bson:"count"`
+			}
+			if err := cursor.Decode(&result); err != nil {
+				return errors.NewDatabaseError("failed to decode count of "+field, "query", "families", err)
+			}
+			count = result.Count
+		}
+		if err := cursor.Err(); err != nil {
+			return errors.NewDatabaseError("failed to count "+field, "query", "families", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/servicelib/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Outbox messages set aside by operators are kept in a collection named after the outbox with
+// this suffix until they are replayed or purged. The repository has no transactions, so a
+// message is copied before it is removed: a move that fails halfway leaves the message in
+// both collections, never in neither, and can be repeated.
+const deadLettersSuffix = outboxSuffix + "_dead_letters"
+
+// deadLetterDocument is the stored form of a dead letter
+type deadLetterDocument struct {
+	outboxDocument `bson:",inline"`
+	Reason         string    `bson:"reason"`
+	DeadLetteredAt time.Time `bson:"deadLetteredAt"`
+}
+
+// Ensure MongoFamilyRepository implements ports.OutboxAdministrator
+var _ ports.OutboxAdministrator = (*MongoFamilyRepository)(nil)
+
+// deadLetters returns the collection of the dead letters
+func (r *MongoFamilyRepository) deadLetters() *mongo.Collection {
+	return r.Collection.Database().Collection(r.Collection.Name() + deadLettersSuffix)
+}
+
+// DeadLetterOutboxMessages moves the messages with the given IDs from the outbox to the dead
+// letters and returns the number of messages moved
+func (r *MongoFamilyRepository) DeadLetterOutboxMessages(ctx context.Context, ids []string, reason string, at time.Time) (int, error) {
+	var docs []outboxDocument
+	if err := r.findByMessageIDs(ctx, r.outbox(), ids, &docs); err != nil {
+		return 0, errors.NewDatabaseError("failed to read outbox", "query", "families"+outboxSuffix, err)
+	}
+	if len(docs) == 0 {
+		return 0, nil
+	}
+
+	letters := make([]interface{}, 0, len(docs))
+	moved := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		doc.Seq = primitive.NewObjectID()
+		letters = append(letters, deadLetterDocument{outboxDocument: doc, Reason: reason, DeadLetteredAt: at.UTC()})
+		moved = append(moved, doc.ID)
+	}
+	if _, err := r.deadLetters().InsertMany(ctx, letters, options.InsertMany().SetOrdered(true)); err != nil {
+		return 0, errors.NewDatabaseError("failed to add messages to dead letters", "insert", "families"+deadLettersSuffix, err)
+	}
+	if err := r.RemoveFromOutbox(ctx, moved); err != nil {
+		return 0, err
+	}
+	return len(moved), nil
+}
+
+// DeadLetters returns at most limit dead letters of the given type, or of every type if it is
+// empty, in the order in which they were set aside
+func (r *MongoFamilyRepository) DeadLetters(ctx context.Context, eventType string, limit int) ([]ports.DeadLetter, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.deadLetters().Find(ctx, ofType(eventType), opts)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to read dead letters", "query", "families"+deadLettersSuffix, err)
+	}
+	defer cursor.Close(ctx)
+
+	letters := []ports.DeadLetter{}
+	for cursor.Next(ctx) {
+		var doc deadLetterDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, errors.NewDatabaseError("failed to decode dead letter", "query", "families"+deadLettersSuffix, err)
+		}
+		letters = append(letters, ports.DeadLetter{
+			OutboxMessage: ports.OutboxMessage{
+				ID:        doc.ID,
+				Type:      doc.Type,
+				Payload:   []byte(doc.Payload),
+				CreatedAt: doc.CreatedAt.UTC(),
+			},
+			Reason:         doc.Reason,
+			DeadLetteredAt: doc.DeadLetteredAt.UTC(),
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, errors.NewDatabaseError("error iterating over dead letters", "query", "families"+deadLettersSuffix, err)
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetters moves the dead letters with the given IDs back to the end of the outbox,
+// in the order in which they were set aside, and returns the number of messages moved
+func (r *MongoFamilyRepository) ReplayDeadLetters(ctx context.Context, ids []string) (int, error) {
+	var docs []deadLetterDocument
+	if err := r.findByMessageIDs(ctx, r.deadLetters(), ids, &docs); err != nil {
+		return 0, errors.NewDatabaseError("failed to read dead letters", "query", "families"+deadLettersSuffix, err)
+	}
+	if len(docs) == 0 {
+		return 0, nil
+	}
+
+	messages := make([]ports.OutboxMessage, 0, len(docs))
+	moved := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		messages = append(messages, ports.OutboxMessage{
+			ID:        doc.ID,
+			Type:      doc.Type,
+			Payload:   []byte(doc.Payload),
+			CreatedAt: doc.CreatedAt,
+		})
+		moved = append(moved, doc.ID)
+	}
+	if err := r.AddToOutbox(ctx, messages...); err != nil {
+		return 0, err
+	}
+	return r.PurgeDeadLetters(ctx, moved)
+}
+
+// PurgeDeadLetters removes the dead letters with the given IDs for good and returns the
+// number of messages removed
+func (r *MongoFamilyRepository) PurgeDeadLetters(ctx context.Context, ids []string) (int, error) {
+	result, err := r.deadLetters().DeleteMany(ctx, bson.M{"messageId": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, errors.NewDatabaseError("failed to remove dead letters", "delete", "families"+deadLettersSuffix, err)
+	}
+	return int(result.DeletedCount), nil
+}
+
+// findByMessageIDs decodes the documents of a collection with the given message IDs into
+// docs, in the order in which they were stored
+func (r *MongoFamilyRepository) findByMessageIDs(ctx context.Context, collection *mongo.Collection, ids []string, docs interface{}) error {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"messageId": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, docs)
+}
+
+// ofType returns the filter of the messages of the given type, or of every message if it is
+// empty
+func ofType(eventType string) bson.M {
+	if eventType == "" {
+		return bson.M{}
+	}
+	return bson.M{"type": eventType}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/servicelib/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// Deleted families keep their document, with the time of their deletion in the deletedAt
+// field. Every read of families skips the documents that have the field, and Save replaces
+// the document without it, so a family saved again with the ID of a deleted family
+// replaces it.
+
+// Ensure MongoFamilyRepository implements ports.FamilyDeleter
+var _ ports.FamilyDeleter = (*MongoFamilyRepository)(nil)
+
+// notDeleted returns a copy of a filter that also leaves out the deleted families. A
+// filter on a missing field matches null, so documents saved before families were soft
+// deleted match.
+func notDeleted(filter bson.M) bson.M {
+	out := make(bson.M, len(filter)+1)
+	for k, v := range filter {
+		out[k] = v
+	}
+	out["deletedAt"] = nil
+	return out
+}
+
+// SoftDeleteFamily marks a family as deleted and reports whether it was found and not
+// already deleted
+func (r *MongoFamilyRepository) SoftDeleteFamily(ctx context.Context, familyID string, deletedAt time.Time) (bool, error) {
+	r.logger.Debug(ctx, "Soft deleting family in MongoDB", zap.String("family_id", familyID))
+
+	ctxWithTimeout, cancel := context.WithTimeout(r.joinUnitOfWork(ctx), r.defaultTimeout)
+	defer cancel()
+
+	var matched int64
+	err := r.protect(ctxWithTimeout, "SoftDeleteFamily", func(ctx context.Context) error {
+		result, err := r.Collection.UpdateOne(ctx, inTenant(ctx, notDeleted(bson.M{"family_id": familyID})),
+			bson.M{"$set": bson.M{"deletedAt": deletedAt.UTC()}})
+		if err != nil {
+			return errors.NewDatabaseError("failed to soft delete family", "update", "families", err)
+		}
+		matched = result.MatchedCount
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return matched > 0, nil
+}
+
+// HardDeleteFamily removes a family and reports whether it was found
+func (r *MongoFamilyRepository) HardDeleteFamily(ctx context.Context, familyID string) (bool, error) {
+	r.logger.Debug(ctx, "Hard deleting family in MongoDB", zap.String("family_id", familyID))
+
+	ctxWithTimeout, cancel := context.WithTimeout(r.joinUnitOfWork(ctx), r.defaultTimeout)
+	defer cancel()
+
+	var deleted int64
+	err := r.protect(ctxWithTimeout, "HardDeleteFamily", func(ctx context.Context) error {
+		result, err := r.Collection.DeleteOne(ctx, inTenant(ctx, bson.M{"family_id": familyID}))
+		if err != nil {
+			return errors.NewDatabaseError("failed to hard delete family", "delete", "families", err)
+		}
+		deleted = result.DeletedCount
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
+
+// Ensure MongoFamilyRepository implements ports.FamilyRestorer
+var _ ports.FamilyRestorer = (*MongoFamilyRepository)(nil)
+
+// RestoreFamily clears the deletion mark of a family and reports whether it was found and
+// deleted
+func (r *MongoFamilyRepository) RestoreFamily(ctx context.Context, familyID string) (bool, error) {
+	r.logger.Debug(ctx, "Restoring family in MongoDB", zap.String("family_id", familyID))
+
+	ctxWithTimeout, cancel := context.WithTimeout(r.joinUnitOfWork(ctx), r.defaultTimeout)
+	defer cancel()
+
+	var matched int64
+	err := r.protect(ctxWithTimeout, "RestoreFamily", func(ctx context.Context) error {
+		result, err := r.Collection.UpdateOne(ctx, inTenant(ctx, bson.M{"family_id": familyID, "deletedAt": bson.M{"$ne": nil}}),
+			bson.M{"$unset": bson.M{"deletedAt": ""}})
+		if err != nil {
+			return errors.NewDatabaseError("failed to restore family", "update", "families", err)
+		}
+		matched = result.MatchedCount
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return matched > 0, nil
+}
+
+// PurgeDeletedFamilies removes the families deleted before a time and returns their IDs.
+// Each family is removed with its own deletion, which is skipped if the family was saved
+// again since it was found, so that only the IDs of the removed families are returned.
+func (r *MongoFamilyRepository) PurgeDeletedFamilies(ctx context.Context, deletedBefore time.Time, allTenants bool) ([]string, error) {
+	r.logger.Debug(ctx, "Purging deleted families in MongoDB", zap.Time("deleted_before", deletedBefore), zap.Bool("all_tenants", allTenants))
+
+	ctxWithTimeout, cancel := context.WithTimeout(r.joinUnitOfWork(ctx), r.defaultTimeout)
+	defer cancel()
+
+	filter := bson.M{"deletedAt": bson.M{"$lt": deletedBefore.UTC()}}
+	if !allTenants {
+		filter = inTenant(ctx, filter)
+	}
+
+	var ids []string
+	err := r.protect(ctxWithTimeout, "PurgeDeletedFamilies", func(ctx context.Context) error {
+		cursor, err := r.Collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"family_id": 1}).SetSort(bson.M{"family_id": 1}))
+		if err != nil {
+			return errors.NewDatabaseError("failed to find deleted families", "query", "families", err)
+		}
+		var docs []FamilyDocument
+		if err := cursor.All(ctx, &docs); err != nil {
+			return errors.NewDatabaseError("failed to find deleted families", "query", "families", err)
+		}
+
+		ids = ids[:0]
+		for _, doc := range docs {
+			docFilter := make(bson.M, len(filter)+1)
+			for k, v := range filter {
+				docFilter[k] = v
+			}
+			docFilter["family_id"] = doc.FamilyID
+			result, err := r.Collection.DeleteOne(ctx, docFilter)
+			if err != nil {
+				return errors.NewDatabaseError("failed to purge deleted family", "delete", "families", err)
+			}
+			if result.DeletedCount > 0 {
+				ids = append(ids, doc.FamilyID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/servicelib/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// External IDs are stored in the externalIds field of family, parent, and child documents
+// and covered by wildcard indexes. System names are validated by the domain to contain only
+// lowercase letters, digits, '-' and '_', so they are safe to use in field paths.
+
+// externalIDFilter returns a filter matching documents in which another entity of the
+// referenced kind holds the referenced external ID
+func externalIDFilter(ref entity.ExternalIDRef) bson.M {
+	field := "externalIds." + ref.System
+	switch ref.Owner {
+	case entity.ExternalIDOwnerParent:
+		return bson.M{"parents": bson.M{"$elemMatch": bson.M{field: ref.ExternalID, "id": bson.M{"$ne": ref.EntityID}}}}
+	case entity.ExternalIDOwnerChild:
+		return bson.M{"children": bson.M{"$elemMatch": bson.M{field: ref.ExternalID, "id": bson.M{"$ne": ref.EntityID}}}}
+	default:
+		return bson.M{field: ref.ExternalID, "family_id": bson.M{"$ne": ref.EntityID}}
+	}
+}
+
+// checkExternalIDsUnique verifies that none of the family's external IDs is held by another
+// entity of the same kind. A parent that belongs to several families holds its external IDs
+// in each of them, so only holders with a different entity ID are conflicts.
+func (r *MongoFamilyRepository) checkExternalIDsUnique(ctx context.Context, fam *entity.Family) error {
+	for _, ref := range fam.ExternalIDRefs() {
+		var doc FamilyDocument
+		err := r.Collection.FindOne(ctx, inTenant(ctx, externalIDFilter(ref)), options.FindOne().SetProjection(bson.M{"family_id": 1})).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return errors.NewDatabaseError("failed to check external ID uniqueness", "query", "families", err)
+		}
+
+		r.logger.Warn(ctx, "External ID is already assigned",
+			zap.String("family_id", fam.ID()),
+			zap.String("system", ref.System),
+			zap.String("external_id", ref.ExternalID),
+			zap.String("conflicting_family_id", doc.FamilyID))
+		return errors.NewValidationError(
+			fmt.Sprintf("external ID %s:%s is already assigned to another %s in family %s", ref.System, ref.ExternalID, strings.ToLower(string(ref.Owner)), doc.FamilyID),
+			"ExternalIDs", nil)
+	}
+	return nil
+}
+
+// FindByExternalID finds the families in which the family itself or one of its members
+// has the given external ID in the given external system
+func (r *MongoFamilyRepository) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Finding families by external ID in MongoDB",
+		zap.String("system", system),
+		zap.String("external_id", externalID))
+
+	if system == "" {
+		return nil, errors.NewValidationError("system is required", "system", nil)
+	}
+	if externalID == "" {
+		return nil, errors.NewValidationError("external ID is required", "externalID", nil)
+	}
+	if _, err := entity.NewExternalIDs(map[string]string{system: externalID}); err != nil {
+		return nil, err
+	}
+
+	// Create a context with timeout
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, r.defaultTimeout)
+	defer cancel()
+
+	field := "externalIds." + system
+	filter := bson.M{"$or": bson.A{
+		bson.M{field: externalID},
+		bson.M{"parents." + field: externalID},
+		bson.M{"children." + field: externalID},
+	}}
+
+	var docs []FamilyDocument
+	err := r.protect(ctxWithTimeout, "FindByExternalID", func(ctx context.Context) error {
+		cursor, err := r.Collection.Find(ctx, inTenant(ctx, notDeleted(filter)), options.Find().SetBatchSize(int32(r.batches.Size())).SetSort(bson.M{"family_id": 1}))
+		if err != nil {
+			r.logger.Error(ctx, "Failed to find families by external ID in MongoDB", zap.Error(err))
+			return errors.NewDatabaseError("failed to find families by external ID", "query", "families", err)
+		}
+		defer cursor.Close(ctx)
+
+		if err := cursor.All(ctx, &docs); err != nil {
+			r.logger.Error(ctx, "Failed to decode family documents", zap.Error(err))
+			return errors.NewDatabaseError("failed to decode family documents", "query", "families", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	families, err := r.processFamilyBatch(ctx, docs)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug(ctx, "Successfully found families by external ID in MongoDB",
+		zap.String("system", system),
+		zap.Int("count", len(families)))
+	return families, nil
+}
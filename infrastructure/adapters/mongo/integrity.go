@@ -0,0 +1,114 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/integrity"
+	"github.com/abitofhelp/servicelib/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// Quarantined documents are moved unchanged to a collection named after the families
+// collection with this suffix, with the reason and time they were quarantined, so that
+// they can be reviewed and restored by hand
+const quarantineSuffix = "_quarantine"
+
+// Ensure MongoFamilyRepository implements integrity.Scanner
+var _ integrity.Scanner = (*MongoFamilyRepository)(nil)
+
+// ScanFamilies reads every stored family in ascending ID order for an integrity check
+func (r *MongoFamilyRepository) ScanFamilies(ctx context.Context, fn func(integrity.Record) error) error {
+	cursor, err := r.Collection.Find(ctx, bson.M{}, options.Find().
+		SetBatchSize(int32(r.batches.Size())).
+		SetSort(bson.D{{Key: "family_id", Value: 1}}))
+	if err != nil {
+		return errors.NewDatabaseError("failed to query families", "query", "families", err)
+	}
+	defer cursor.Close(ctx)
+
+	// Repair legacy data in memory, so that only data that cannot be repaired is reported
+	repairing := *r
+	repairing.readRepair.Enabled = true
+
+	for cursor.Next(ctx) {
+		if err := fn(repairing.decodeRecord(ctx, cursor.Current)); err != nil {
+			return err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return errors.NewDatabaseError("cursor error while scanning families", "query", "families", err)
+	}
+	return nil
+}
+
+// decodeRecord decodes a family document into an integrity record
+func (r *MongoFamilyRepository) decodeRecord(ctx context.Context, raw bson.Raw) integrity.Record {
+	rec := integrity.Record{FamilyID: documentFamilyID(raw)}
+
+	var doc FamilyDocument
+	repairs, err := r.repairDocument(ctx, raw, &doc)
+	if err != nil {
+		rec.DecodeErr = fmt.Errorf("failed to decode family document: %w", err)
+		return rec
+	}
+	rec.Repairs = repairs
+
+	fam, err := r.documentToEntity(doc)
+	if err != nil {
+		rec.InvariantErr = fmt.Errorf("invalid family: %w", err)
+		return rec
+	}
+
+	rec.Family = fam
+	return rec
+}
+
+// documentFamilyID returns the family ID of a document, or the hex form of its _id if the
+// document has no family ID, so that every document can be reported and quarantined
+func documentFamilyID(raw bson.Raw) string {
+	if id, ok := raw.Lookup("family_id").StringValueOK(); ok && id != "" {
+		return id
+	}
+	if oid, ok := raw.Lookup("_id").ObjectIDOK(); ok {
+		return oid.Hex()
+	}
+	return ""
+}
+
+// Quarantine moves a stored family document out of the families collection into the
+// quarantine collection
+func (r *MongoFamilyRepository) Quarantine(ctx context.Context, familyID, reason string) error {
+	filter := bson.M{"family_id": familyID}
+	if oid, err := primitive.ObjectIDFromHex(familyID); err == nil {
+		filter = bson.M{"$or": bson.A{filter, bson.M{"_id": oid}}}
+	}
+
+	var doc bson.M
+	if err := r.Collection.FindOne(ctx, filter).Decode(&doc); err != nil {
+		return errors.NewDatabaseError("failed to find family to quarantine", "query", "families", err)
+	}
+	doc["quarantineReason"] = reason
+	doc["quarantinedAt"] = time.Now().UTC().Format(time.RFC3339)
+
+	// Copy the document before deleting it, so that it is never lost; quarantining again
+	// after a failed delete replaces the copy
+	quarantine := r.Collection.Database().Collection(r.Collection.Name() + quarantineSuffix)
+	_, err := quarantine.ReplaceOne(ctx, bson.M{"_id": doc["_id"]}, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		return errors.NewDatabaseError("failed to copy family to quarantine", "insert", "families"+quarantineSuffix, err)
+	}
+	if _, err := r.Collection.DeleteOne(ctx, bson.M{"_id": doc["_id"]}); err != nil {
+		return errors.NewDatabaseError("failed to delete family", "delete", "families", err)
+	}
+
+	r.logger.Warn(ctx, "Quarantined family", zap.String("family_id", familyID), zap.String("reason", reason))
+	return nil
+}
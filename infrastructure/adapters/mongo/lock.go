@@ -0,0 +1,123 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/familylock"
+	"github.com/abitofhelp/servicelib/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// The locks of families are recorded in a collection named after the families collection
+// with this suffix, with one document per family that has been locked
+const familyLocksSuffix = "_locks"
+
+// Intervals between attempts to claim a lock that is held; the interval doubles up to the maximum
+const (
+	lockPollInterval    = 10 * time.Millisecond
+	maxLockPollInterval = 200 * time.Millisecond
+)
+
+// unlockTimeout bounds the release of a lock, which runs after the operation's context may
+// have ended
+const unlockTimeout = 5 * time.Second
+
+// lockDocument is the stored form of the lock of a family. Each claim increments the
+// version, so that a holder whose lease expired cannot release the lock of a later holder.
+type lockDocument struct {
+	FamilyID  string    `bson:"_id"`
+	Held      bool      `bson:"held"`
+	Version   int64     `bson:"version"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// Ensure MongoFamilyRepository implements ports.FamilyLocker
+var _ ports.FamilyLocker = (*MongoFamilyRepository)(nil)
+
+// familyLocks returns the collection of the locks of families
+func (r *MongoFamilyRepository) familyLocks() *mongo.Collection {
+	return r.Collection.Database().Collection(r.Collection.Name() + familyLocksSuffix)
+}
+
+// LockFamily waits until the caller holds the lock of a family. The lock is claimed with
+// findAndModify when it is free or its lease has expired, and released by its version.
+func (r *MongoFamilyRepository) LockFamily(ctx context.Context, familyID string) (func(), error) {
+	return familylock.Acquire(ctx, "mongodb", familyID, r.locking, func(ctx context.Context) (func(), error) {
+		interval := lockPollInterval
+		for {
+			version, claimed, err := r.claimLock(ctx, familyID)
+			if err != nil {
+				return nil, err
+			}
+			if claimed {
+				return func() { r.releaseLock(familyID, version) }, nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(interval):
+			}
+			if interval *= 2; interval > maxLockPollInterval {
+				interval = maxLockPollInterval
+			}
+		}
+	})
+}
+
+// claimLock claims the lock of a family if it is free or its lease has expired
+//
+// Returns:
+//   - The version of the claimed lock
+//   - Whether the lock was claimed; false if another caller holds it
+//   - An error if the lock could not be read or written
+func (r *MongoFamilyRepository) claimLock(ctx context.Context, familyID string) (int64, bool, error) {
+	now := time.Now().UTC()
+	filter := bson.M{
+		"_id": familyID,
+		"$or": bson.A{
+			bson.M{"held": false},
+			bson.M{"expiresAt": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"held": true, "expiresAt": now.Add(r.locking.Lease)},
+		"$inc": bson.M{"version": 1},
+	}
+
+	// A lock that is held does not match the filter, so the upsert inserts a second
+	// document with its ID and fails with a duplicate key error
+	var doc lockDocument
+	err := r.familyLocks().FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, errors.NewDatabaseError("failed to lock family", "update", "families"+familyLocksSuffix, err)
+	}
+	return doc.Version, true, nil
+}
+
+// releaseLock releases the lock of a family if it still has the version that was claimed
+func (r *MongoFamilyRepository) releaseLock(familyID string, version int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+	defer cancel()
+
+	_, err := r.familyLocks().UpdateOne(ctx,
+		bson.M{"_id": familyID, "version": version},
+		bson.M{"$set": bson.M{"held": false}})
+	if err != nil {
+		// The lock is released when its lease expires
+		r.logger.Error(ctx, "Failed to unlock family",
+			zap.Error(err),
+			zap.String("family_id", familyID))
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/servicelib/errors"
+)
+
+// Preferred names and name histories are stored in the preferredName and nameHistory
+// fields of parent and child documents. Both are omitted for members without them, so
+// documents written before they were introduced are read unchanged.
+
+// NameChangeDocument represents how an entry of a member's name history is stored in MongoDB
+type NameChangeDocument struct {
+	FirstName     string `bson:"firstName"`
+	LastName      string `bson:"lastName"`
+	EffectiveDate string `bson:"effectiveDate"`
+	Reason        string `bson:"reason,omitempty"`
+}
+
+// namedMember is a parent or child whose names are restored from a document
+type namedMember interface {
+	SetPreferredName(name string) error
+	SetNameHistory(changes []entity.NameChange) error
+}
+
+// nameHistoryToDocuments converts a member's name history to documents
+func nameHistoryToDocuments(history []entity.NameChange) []NameChangeDocument {
+	if len(history) == 0 {
+		return nil
+	}
+	docs := make([]NameChangeDocument, 0, len(history))
+	for _, change := range history {
+		docs = append(docs, NameChangeDocument{
+			FirstName:     change.FirstName,
+			LastName:      change.LastName,
+			EffectiveDate: change.EffectiveDate.Format(time.RFC3339),
+			Reason:        string(change.Reason),
+		})
+	}
+	return docs
+}
+
+// setMemberNames restores the preferred name and name history of a member from a document
+func setMemberNames(member namedMember, preferredName string, docs []NameChangeDocument) error {
+	if err := member.SetPreferredName(preferredName); err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	history := make([]entity.NameChange, 0, len(docs))
+	for _, doc := range docs {
+		effectiveDate, err := time.Parse(time.RFC3339, doc.EffectiveDate)
+		if err != nil {
+			return errors.NewDatabaseError("invalid name change effective date format", "parse", "families", err)
+		}
+		history = append(history, entity.NameChange{
+			FirstName:     doc.FirstName,
+			LastName:      doc.LastName,
+			EffectiveDate: effectiveDate,
+			Reason:        entity.NameChangeReason(doc.Reason),
+		})
+	}
+	return member.SetNameHistory(history)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/servicelib/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Domain events waiting to be relayed to the message broker are stored in a collection named
+// after the families collection with this suffix. Documents are keyed by object IDs, which
+// increase in the order in which a replica stores them. Messages are stored in the unit of
+// work of their context, like the changes that raised them.
+const outboxSuffix = "_outbox"
+
+// outboxDocument is the stored form of an outbox message
+type outboxDocument struct {
+	Seq       primitive.ObjectID `bson:"_id"`
+	ID        string             `bson:"messageId"`
+	Type      string             `bson:"type"`
+	Payload   string             `bson:"payload"`
+	CreatedAt time.Time          `bson:"createdAt"`
+}
+
+// Ensure MongoFamilyRepository implements ports.OutboxRepository
+var _ ports.OutboxRepository = (*MongoFamilyRepository)(nil)
+
+// outbox returns the collection of the outbox
+func (r *MongoFamilyRepository) outbox() *mongo.Collection {
+	return r.Collection.Database().Collection(r.Collection.Name() + outboxSuffix)
+}
+
+// AddToOutbox stores messages in the outbox, in order
+func (r *MongoFamilyRepository) AddToOutbox(ctx context.Context, messages ...ports.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(messages))
+	for _, message := range messages {
+		docs = append(docs, outboxDocument{
+			Seq:       primitive.NewObjectID(),
+			ID:        message.ID,
+			Type:      message.Type,
+			Payload:   string(message.Payload),
+			CreatedAt: message.CreatedAt.UTC(),
+		})
+	}
+	if _, err := r.outbox().InsertMany(r.joinUnitOfWork(ctx), docs, options.InsertMany().SetOrdered(true)); err != nil {
+		return errors.NewDatabaseError("failed to add messages to outbox", "insert", "families"+outboxSuffix, err)
+	}
+	return nil
+}
+
+// PendingOutboxMessages returns at most limit messages of the outbox, in the order in which
+// they were stored
+func (r *MongoFamilyRepository) PendingOutboxMessages(ctx context.Context, limit int) ([]ports.OutboxMessage, error) {
+	return r.OutboxMessages(ctx, "", limit)
+}
+
+// OutboxMessages returns at most limit messages of the outbox of the given type, or of every
+// type if it is empty, in the order in which they were stored
+func (r *MongoFamilyRepository) OutboxMessages(ctx context.Context, eventType string, limit int) ([]ports.OutboxMessage, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.outbox().Find(ctx, ofType(eventType), opts)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to read outbox", "query", "families"+outboxSuffix, err)
+	}
+	defer cursor.Close(ctx)
+
+	messages := []ports.OutboxMessage{}
+	for cursor.Next(ctx) {
+		var doc outboxDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, errors.NewDatabaseError("failed to decode outbox message", "query", "families"+outboxSuffix, err)
+		}
+		messages = append(messages, ports.OutboxMessage{
+			ID:        doc.ID,
+			Type:      doc.Type,
+			Payload:   []byte(doc.Payload),
+			CreatedAt: doc.CreatedAt.UTC(),
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, errors.NewDatabaseError("error iterating over outbox messages", "query", "families"+outboxSuffix, err)
+	}
+	return messages, nil
+}
+
+// RemoveFromOutbox removes the messages with the given IDs from the outbox
+func (r *MongoFamilyRepository) RemoveFromOutbox(ctx context.Context, ids []string) error {
+	if _, err := r.outbox().DeleteMany(ctx, bson.M{"messageId": bson.M{"$in": ids}}); err != nil {
+		return errors.NewDatabaseError("failed to remove messages from outbox", "delete", "families"+outboxSuffix, err)
+	}
+	return nil
+}
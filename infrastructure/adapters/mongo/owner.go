@@ -0,0 +1,113 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"sort"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/servicelib/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// Ensure MongoFamilyRepository implements ports.FamilyOwnerReader
+var _ ports.FamilyOwnerReader = (*MongoFamilyRepository)(nil)
+
+// ListOwnedFamilies reads a page of the families of an owner in ascending ID order, from a
+// range of the tenantId_1_ownerId_1_family_id_1 index
+func (r *MongoFamilyRepository) ListOwnedFamilies(ctx context.Context, ownerID, after string, limit int) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Listing a page of the families of an owner from MongoDB",
+		zap.String("owner_id", ownerID), zap.String("after", after), zap.Int("limit", limit))
+
+	page := options.Find().
+		SetSort(bson.D{{Key: "family_id", Value: 1}}).
+		SetLimit(int64(limit))
+	filter := mongoOwner(query.Eq(query.FieldOwnerID, ownerID))
+	filter["family_id"] = bson.M{"$gt": after}
+	families, err := r.findFamilies(ctx, "ListOwnedFamilies", filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	// Documents are decoded in batches, which need not keep the order of the cursor
+	sort.Slice(families, func(i, j int) bool { return families[i].ID() < families[j].ID() })
+	return families, nil
+}
+
+// CountByOwner counts the families of an owner that are not deleted, and the distinct
+// parents and children in them, leaving out the families with the excluded IDs. The three
+// counts are facets of one aggregation, so that the families are matched once.
+func (r *MongoFamilyRepository) CountByOwner(ctx context.Context, ownerID string, excluded []string) (ports.FamilyCounts, error) {
+	r.logger.Debug(ctx, "Counting the families of an owner in MongoDB", zap.String("owner_id", ownerID))
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, r.defaultTimeout)
+	defer cancel()
+
+	filter := mongoOwner(query.Eq(query.FieldOwnerID, ownerID))
+	if len(excluded) > 0 {
+		filter["family_id"] = bson.M{"$nin": excluded}
+	}
+	// distinctMembers counts the distinct IDs of the members of a field, so that a member of
+	// several families, such as a remarried parent, is counted once
+	distinctMembers := func(field string) bson.A {
+		return bson.A{
+			bson.M{"$unwind": "$" + field},
+			bson.M{"$group": bson.M{"_id": "$" + field + ".id"}},
+			bson.M{"$count": "count"},
+		}
+	}
+	pipeline := bson.A{
+		bson.M{"$match": inTenant(ctx, notDeleted(filter))},
+		bson.M{"$facet": bson.M{
+			"families": bson.A{bson.M{"$count": "count"}},
+			"parents":  distinctMembers("parents"),
+			"children": distinctMembers("children"),
+		}},
+	}
+
+	// $count returns no document when nothing is counted
+	type facet []struct {
+		Count int `bson:"count"`
+	}
+	first := func(f facet) int {
+		if len(f) == 0 {
+			return 0
+		}
+		return f[0].Count
+	}
+
+	var counts ports.FamilyCounts
+	err := r.protect(ctxWithTimeout, "CountByOwner", func(ctx context.Context) error {
+		cursor, err := r.Collection.Aggregate(ctx, pipeline)
+		if err != nil {
+			return errors.NewDatabaseError("failed to count the families of the owner", "query", "families", err)
+		}
+		defer cursor.Close(ctx)
+
+		counts = ports.FamilyCounts{}
+		if cursor.Next(ctx) {
+			var result struct {
+				Families facet `bson:"families"`
+				Parents  facet `bson:"parents"`
+				Children facet `bson:"children"`
+			}
+			if err := cursor.Decode(&result); err != nil {
+				return errors.NewDatabaseError("failed to decode the counts of the owner", "query", "families", err)
+			}
+			counts = ports.FamilyCounts{Families: first(result.Families), Parents: first(result.Parents), Children: first(result.Children)}
+		}
+		if err := cursor.Err(); err != nil {
+			return errors.NewDatabaseError("failed to count the families of the owner", "query", "families", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return ports.FamilyCounts{}, err
+	}
+	return counts, nil
+}
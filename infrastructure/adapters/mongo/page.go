@@ -0,0 +1,35 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"sort"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// Ensure MongoFamilyRepository implements ports.FamilyPager
+var _ ports.FamilyPager = (*MongoFamilyRepository)(nil)
+
+// ListFamilies reads a page of families in ascending ID order. The page starts after the
+// given ID on the family_id index, so reading a page costs the same wherever it starts.
+func (r *MongoFamilyRepository) ListFamilies(ctx context.Context, after string, limit int) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Listing a page of families from MongoDB", zap.String("after", after), zap.Int("limit", limit))
+
+	page := options.Find().
+		SetSort(bson.D{{Key: "family_id", Value: 1}}).
+		SetLimit(int64(limit))
+	families, err := r.findFamilies(ctx, "ListFamilies", bson.M{"family_id": bson.M{"$gt": after}}, page)
+	if err != nil {
+		return nil, err
+	}
+
+	// Documents are decoded in batches, which need not keep the order of the cursor
+	sort.Slice(families, func(i, j int) bool { return families[i].ID() < families[j].ID() })
+	return families, nil
+}
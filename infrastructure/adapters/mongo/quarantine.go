@@ -0,0 +1,106 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/servicelib/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Families quarantined by administrators are recorded in a collection named after the
+// families collection with this suffix, keyed by family ID. Unlike the documents in the
+// integrity quarantine collection, the families stay in the families collection, unchanged.
+const adminQuarantinesSuffix = "_admin_quarantines"
+
+// quarantineDocument is the stored form of a quarantine
+type quarantineDocument struct {
+	FamilyID      string    `bson:"_id"`
+	Reason        string    `bson:"reason"`
+	QuarantinedBy string    `bson:"quarantinedBy"`
+	QuarantinedAt time.Time `bson:"quarantinedAt"`
+}
+
+// Ensure MongoFamilyRepository implements ports.QuarantineRepository
+var _ ports.QuarantineRepository = (*MongoFamilyRepository)(nil)
+
+// adminQuarantines returns the collection of the quarantines placed by administrators
+func (r *MongoFamilyRepository) adminQuarantines() *mongo.Collection {
+	return r.Collection.Database().Collection(r.Collection.Name() + adminQuarantinesSuffix)
+}
+
+// GetFamilyQuarantine returns the quarantine of a family, or nil if it is not quarantined
+func (r *MongoFamilyRepository) GetFamilyQuarantine(ctx context.Context, familyID string) (*entity.Quarantine, error) {
+	var doc quarantineDocument
+	err := r.adminQuarantines().FindOne(ctx, bson.M{"_id": familyID}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to get family quarantine", "query", "families"+adminQuarantinesSuffix, err)
+	}
+	q := doc.toEntity()
+	return &q, nil
+}
+
+// SaveFamilyQuarantine stores the quarantine of a family, replacing any previous one
+func (r *MongoFamilyRepository) SaveFamilyQuarantine(ctx context.Context, q entity.Quarantine) error {
+	doc := quarantineDocument{
+		FamilyID:      q.FamilyID,
+		Reason:        q.Reason,
+		QuarantinedBy: q.QuarantinedBy,
+		QuarantinedAt: q.QuarantinedAt.UTC(),
+	}
+	_, err := r.adminQuarantines().ReplaceOne(r.joinUnitOfWork(ctx), bson.M{"_id": q.FamilyID}, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		return errors.NewDatabaseError("failed to save family quarantine", "update", "families"+adminQuarantinesSuffix, err)
+	}
+	return nil
+}
+
+// DeleteFamilyQuarantine removes the quarantine of a family and reports whether it had one
+func (r *MongoFamilyRepository) DeleteFamilyQuarantine(ctx context.Context, familyID string) (bool, error) {
+	result, err := r.adminQuarantines().DeleteOne(r.joinUnitOfWork(ctx), bson.M{"_id": familyID})
+	if err != nil {
+		return false, errors.NewDatabaseError("failed to delete family quarantine", "delete", "families"+adminQuarantinesSuffix, err)
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// ListFamilyQuarantines returns every quarantine, in ascending family ID order
+func (r *MongoFamilyRepository) ListFamilyQuarantines(ctx context.Context) ([]entity.Quarantine, error) {
+	cursor, err := r.adminQuarantines().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list family quarantines", "query", "families"+adminQuarantinesSuffix, err)
+	}
+	defer cursor.Close(ctx)
+
+	quarantines := []entity.Quarantine{}
+	for cursor.Next(ctx) {
+		var doc quarantineDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, errors.NewDatabaseError("failed to decode family quarantine", "query", "families"+adminQuarantinesSuffix, err)
+		}
+		quarantines = append(quarantines, doc.toEntity())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, errors.NewDatabaseError("error iterating over family quarantines", "query", "families"+adminQuarantinesSuffix, err)
+	}
+	return quarantines, nil
+}
+
+// toEntity converts a stored quarantine to the domain
+func (d quarantineDocument) toEntity() entity.Quarantine {
+	return entity.Quarantine{
+		FamilyID:      d.FamilyID,
+		Reason:        d.Reason,
+		QuarantinedBy: d.QuarantinedBy,
+		QuarantinedAt: d.QuarantinedAt.UTC(),
+	}
+}
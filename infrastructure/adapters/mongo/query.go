@@ -0,0 +1,275 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// Filters are translated into MongoDB query filters on family documents. Conditions on
+// members use the array fields of the parents and children, which match when any member
+// satisfies the condition. Counts are compared with the parentCount and childrenCount
+// fields, which are stored with every document. Documents saved before the counts were
+// stored are matched on the arrays instead, with $size and with the existence of an
+// array element (there are more than n members when element n exists). Birth dates are
+// stored as RFC 3339 strings in UTC, so they are compared as strings in the same format.
+// Conditions on member names are case-insensitive regular expressions of the quoted value,
+// which no index serves. A text index is not used: it only matches whole words, and $text
+// can be neither negated nor repeated in a filter.
+
+// mongoOperators are the MongoDB comparison operators of the query operators
+var mongoOperators = map[query.Op]string{
+	query.OpNe:  "$ne",
+	query.OpLt:  "$lt",
+	query.OpLte: "$lte",
+	query.OpGt:  "$gt",
+	query.OpGte: "$gte",
+}
+
+// matchNothing is a filter that matches no document, since every document has an _id
+var matchNothing = bson.M{"_id": bson.M{"$exists": false}}
+
+// mongoFilter translates a valid filter into a MongoDB query filter
+func mongoFilter(f query.Filter) bson.M {
+	switch f := f.(type) {
+	case nil:
+		return bson.M{}
+	case query.And:
+		if len(f) == 0 {
+			return bson.M{}
+		}
+		return bson.M{"$and": mongoFilters(f)}
+	case query.Or:
+		if len(f) == 0 {
+			return matchNothing
+		}
+		return bson.M{"$or": mongoFilters(f)}
+	case query.Not:
+		return bson.M{"$nor": bson.A{mongoFilter(f.Filter)}}
+	case query.Condition:
+		return mongoCondition(f)
+	default:
+		return matchNothing
+	}
+}
+
+// mongoFilters translates the filters of an And or Or
+func mongoFilters(filters []query.Filter) bson.A {
+	translated := make(bson.A, len(filters))
+	for i, f := range filters {
+		translated[i] = mongoFilter(f)
+	}
+	return translated
+}
+
+// mongoCondition translates a single condition
+func mongoCondition(c query.Condition) bson.M {
+	switch c.Field {
+	case query.FieldID:
+		return mongoCompare("family_id", c.Op, c.Value)
+	case query.FieldStatus:
+		return mongoCompare("status", c.Op, c.Value)
+	case query.FieldOwnerID:
+		return mongoOwner(c)
+	case query.FieldParentCount:
+		return mongoCount("parentCount", "parents", c)
+	case query.FieldChildCount:
+		return mongoCount("childrenCount", "children", c)
+	case query.FieldParentID:
+		return mongoCompare("parents.id", c.Op, c.Value)
+	case query.FieldChildID:
+		return mongoCompare("children.id", c.Op, c.Value)
+	case query.FieldParentLastName:
+		return mongoCompare("parents.lastName", c.Op, c.Value)
+	case query.FieldParentBirthDate:
+		return mongoBirthDate("parents", c)
+	case query.FieldChildBirthDate:
+		return mongoBirthDate("children", c)
+	case query.FieldMemberName:
+		return mongoMemberName(c.Value.(string))
+	default:
+		return matchNothing
+	}
+}
+
+// mongoCompare translates the comparison of a field with a value
+func mongoCompare(field string, op query.Op, value interface{}) bson.M {
+	switch op {
+	case query.OpEq:
+		return bson.M{field: value}
+	case query.OpIn:
+		return bson.M{field: bson.M{"$in": value}}
+	default:
+		return bson.M{field: bson.M{mongoOperators[op]: value}}
+	}
+}
+
+// mongoMemberName translates a condition that the first, last, or preferred name of a
+// member contains a value, ignoring case
+func mongoMemberName(value string) bson.M {
+	pattern := primitive.Regex{Pattern: regexp.QuoteMeta(value), Options: "i"}
+	names := bson.A{}
+	for _, array := range []string{"parents", "children"} {
+		for _, field := range []string{"firstName", "lastName", "preferredName"} {
+			names = append(names, bson.M{array + "." + field: pattern})
+		}
+	}
+	return bson.M{"$or": names}
+}
+
+// mongoBirthDate translates a condition on the birth dates of the members of an array
+// field. Both bounds of a range must hold for the same member, so a range is matched with
+// $elemMatch.
+func mongoBirthDate(arrayField string, c query.Condition) bson.M {
+	if r, ok := c.Value.(query.Range); ok {
+		return bson.M{arrayField: bson.M{"$elemMatch": bson.M{"birthDate": bson.M{
+			"$gte": r.From.UTC().Format(time.RFC3339),
+			"$lte": r.To.UTC().Format(time.RFC3339),
+		}}}}
+	}
+	return mongoCompare(arrayField+".birthDate", c.Op, c.Value.(time.Time).UTC().Format(time.RFC3339))
+}
+
+// mongoCount translates a condition on a count, which is compared with its stored field,
+// or with the array it counts in documents without the field
+func mongoCount(countField, arrayField string, c query.Condition) bson.M {
+	stored := mongoCompare(countField, c.Op, c.Value)
+	if c.Op == query.OpNe {
+		// $ne also matches documents without the field
+		stored = bson.M{countField: bson.M{"$exists": true, "$ne": c.Value}}
+	}
+	return bson.M{"$or": bson.A{
+		stored,
+		bson.M{"$and": bson.A{bson.M{countField: bson.M{"$exists": false}}, mongoSize(arrayField, c)}},
+	}}
+}
+
+// mongoOwner translates a condition on the owner of a family. Families without an owner
+// are stored without the ownerId field, which null matches.
+func mongoOwner(c query.Condition) bson.M {
+	// values returns the values of the condition, with null for the empty owner
+	values := func(owners ...string) bson.A {
+		matched := bson.A{}
+		for _, owner := range owners {
+			matched = append(matched, owner)
+			if owner == "" {
+				matched = append(matched, nil)
+			}
+		}
+		return matched
+	}
+	switch c.Op {
+	case query.OpEq:
+		return bson.M{"ownerId": bson.M{"$in": values(c.Value.(string))}}
+	case query.OpNe:
+		return bson.M{"ownerId": bson.M{"$nin": values(c.Value.(string))}}
+	case query.OpIn:
+		return bson.M{"ownerId": bson.M{"$in": values(c.Value.([]string)...)}}
+	default:
+		return matchNothing
+	}
+}
+
+// mongoSize translates a condition on the number of elements of an array field
+func mongoSize(field string, c query.Condition) bson.M {
+	// moreThan matches arrays with more than n elements
+	moreThan := func(n int) bson.M {
+		if n < 0 {
+			return bson.M{}
+		}
+		return bson.M{fmt.Sprintf("%s.%d", field, n): bson.M{"$exists": true}}
+	}
+	// atMost matches arrays with at most n elements
+	atMost := func(n int) bson.M {
+		if n < 0 {
+			return matchNothing
+		}
+		return bson.M{fmt.Sprintf("%s.%d", field, n): bson.M{"$exists": false}}
+	}
+
+	switch c.Op {
+	case query.OpIn:
+		values := c.Value.([]int)
+		if len(values) == 0 {
+			return matchNothing
+		}
+		sizes := make(bson.A, len(values))
+		for i, n := range values {
+			sizes[i] = bson.M{field: bson.M{"$size": n}}
+		}
+		return bson.M{"$or": sizes}
+	}
+
+	n := c.Value.(int)
+	switch c.Op {
+	case query.OpEq:
+		return bson.M{field: bson.M{"$size": n}}
+	case query.OpNe:
+		return bson.M{field: bson.M{"$not": bson.M{"$size": n}}}
+	case query.OpGt:
+		return moreThan(n)
+	case query.OpGte:
+		return moreThan(n - 1)
+	case query.OpLt:
+		return atMost(n - 1)
+	case query.OpLte:
+		return atMost(n)
+	default:
+		return matchNothing
+	}
+}
+
+// Find finds the families that match a filter, in ascending ID order
+func (r *MongoFamilyRepository) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	if err := query.Validate(filter); err != nil {
+		return nil, err
+	}
+
+	mf := mongoFilter(filter)
+	r.logger.Debug(ctx, "Finding families by filter in MongoDB", zap.Any("filter", mf))
+
+	families, err := r.findFamilies(ctx, "Find", mf)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].ID() < families[j].ID() })
+	return families, nil
+}
+
+// Ensure MongoFamilyRepository implements ports.FamilyFilterPager
+var _ ports.FamilyFilterPager = (*MongoFamilyRepository)(nil)
+
+// FindPage finds at most limit families that match a filter and whose IDs sort after the
+// given ID, in ascending ID order
+func (r *MongoFamilyRepository) FindPage(ctx context.Context, filter query.Filter, after string, limit int) ([]*entity.Family, error) {
+	if err := query.Validate(filter); err != nil {
+		return nil, err
+	}
+
+	mf := bson.M{"$and": bson.A{mongoFilter(filter), bson.M{"family_id": bson.M{"$gt": after}}}}
+	r.logger.Debug(ctx, "Finding a page of families by filter in MongoDB", zap.Any("filter", mf), zap.Int("limit", limit))
+
+	page := options.Find().
+		SetSort(bson.D{{Key: "family_id", Value: 1}}).
+		SetLimit(int64(limit))
+	families, err := r.findFamilies(ctx, "FindPage", mf, page)
+	if err != nil {
+		return nil, err
+	}
+
+	// Documents are decoded in batches, which need not keep the order of the cursor
+	sort.Slice(families, func(i, j int) bool { return families[i].ID() < families[j].ID() })
+	return families, nil
+}
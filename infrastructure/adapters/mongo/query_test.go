@@ -0,0 +1,254 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestMongoFilter evaluates the translation of every shared case against the documents
+// the repository stores, with the subset of MongoDB query semantics the translation uses
+func TestMongoFilter(t *testing.T) {
+	testMongoFilter(t, familyDocuments(t))
+}
+
+// TestMongoFilter_DocumentsWithoutCounts evaluates the shared cases against documents
+// saved before the counts were stored
+func TestMongoFilter_DocumentsWithoutCounts(t *testing.T) {
+	docs := familyDocuments(t)
+	for _, doc := range docs {
+		delete(doc, "parentCount")
+		delete(doc, "childrenCount")
+	}
+	testMongoFilter(t, docs)
+}
+
+// familyDocuments returns the documents the repository stores for the shared families, by ID
+func familyDocuments(t *testing.T) map[string]bson.M {
+	repo := setupTest(t)
+	docs := make(map[string]bson.M)
+	for _, fam := range querytest.Families(t) {
+		data, err := bson.Marshal(repo.entityToDocument(fam))
+		require.NoError(t, err)
+		var doc bson.M
+		require.NoError(t, bson.Unmarshal(data, &doc))
+		docs[fam.ID()] = doc
+	}
+	return docs
+}
+
+// testMongoFilter evaluates the translation of every shared case against documents
+func testMongoFilter(t *testing.T, docs map[string]bson.M) {
+	for _, tc := range querytest.Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			filter := mongoFilter(tc.Filter)
+
+			var got []string
+			for id, doc := range docs {
+				if matchDocument(t, filter, doc) {
+					got = append(got, id)
+				}
+			}
+			sort.Strings(got)
+			assert.Equal(t, tc.Want, got)
+		})
+	}
+}
+
+func TestMongoFilter_Translation(t *testing.T) {
+	assert.Equal(t,
+		bson.M{"$and": bson.A{
+			bson.M{"status": "SINGLE"},
+			bson.M{"$or": bson.A{
+				bson.M{"childrenCount": bson.M{"$gt": 1}},
+				bson.M{"$and": bson.A{bson.M{"childrenCount": bson.M{"$exists": false}}, bson.M{"children.1": bson.M{"$exists": true}}}},
+			}},
+			bson.M{"$nor": bson.A{bson.M{"parents.id": bson.M{"$in": []string{"p1", "p2"}}}}},
+		}},
+		mongoFilter(query.And{
+			query.Eq(query.FieldStatus, "SINGLE"),
+			query.Condition{Field: query.FieldChildCount, Op: query.OpGt, Value: 1},
+			query.Not{Filter: query.In(query.FieldParentID, []string{"p1", "p2"})},
+		}))
+}
+
+// matchDocument reports whether a document matches a filter
+func matchDocument(t *testing.T, filter bson.M, doc bson.M) bool {
+	for key, value := range filter {
+		switch key {
+		case "$and", "$or", "$nor":
+			matches := 0
+			for _, f := range value.(bson.A) {
+				if matchDocument(t, f.(bson.M), doc) {
+					matches++
+				}
+			}
+			n := len(value.(bson.A))
+			if (key == "$and" && matches != n) || (key == "$or" && matches == 0) || (key == "$nor" && matches != 0) {
+				return false
+			}
+		default:
+			if !matchValues(t, resolve(doc, strings.Split(key, ".")), value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// resolve returns the values at a dotted path of a document, descending into arrays
+func resolve(value interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{value}
+	}
+	switch v := value.(type) {
+	case primitive.D:
+		return resolve(v.Map(), path)
+	case bson.M:
+		child, ok := v[path[0]]
+		if !ok {
+			return nil
+		}
+		return resolve(child, path[1:])
+	case bson.A:
+		if i, err := strconv.Atoi(path[0]); err == nil {
+			if i < len(v) {
+				return resolve(v[i], path[1:])
+			}
+			return nil
+		}
+		var values []interface{}
+		for _, element := range v {
+			values = append(values, resolve(element, path)...)
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// matchValues reports whether the values at a path satisfy a condition
+func matchValues(t *testing.T, values []interface{}, condition interface{}) bool {
+	if re, ok := condition.(primitive.Regex); ok {
+		pattern := re.Pattern
+		if strings.Contains(re.Options, "i") {
+			pattern = "(?i)" + pattern
+		}
+		compiled := regexp.MustCompile(pattern)
+		return anyValue(values, func(v interface{}) bool {
+			s, ok := v.(string)
+			return ok && compiled.MatchString(s)
+		})
+	}
+
+	operators, ok := condition.(bson.M)
+	if !ok {
+		return anyValue(values, func(v interface{}) bool { return equal(v, condition) })
+	}
+
+	for op, operand := range operators {
+		var matched bool
+		switch op {
+		case "$exists":
+			matched = (len(values) > 0) == operand.(bool)
+		case "$size":
+			matched = anyValue(values, func(v interface{}) bool {
+				a, ok := v.(bson.A)
+				return ok && len(a) == operand.(int)
+			})
+		case "$elemMatch":
+			matched = anyValue(values, func(v interface{}) bool {
+				a, ok := v.(bson.A)
+				return ok && anyValue(a, func(element interface{}) bool {
+					doc, ok := element.(bson.M)
+					if d, isD := element.(primitive.D); isD {
+						doc, ok = d.Map(), true
+					}
+					return ok && matchDocument(t, operand.(bson.M), doc)
+				})
+			})
+		case "$not":
+			matched = !matchValues(t, values, operand)
+		case "$ne":
+			matched = !anyValue(values, func(v interface{}) bool { return equal(v, operand) })
+		case "$in":
+			list := reflect.ValueOf(operand)
+			in := func(v interface{}) bool {
+				for i := 0; i < list.Len(); i++ {
+					if equal(v, list.Index(i).Interface()) {
+						return true
+					}
+				}
+				return false
+			}
+			// null also matches documents without the field
+			matched = anyValue(values, in) || (len(values) == 0 && in(nil))
+		case "$nin":
+			matched = !matchValues(t, values, bson.M{"$in": operand})
+		case "$lt", "$lte", "$gt", "$gte":
+			matched = anyValue(values, func(v interface{}) bool {
+				var c int
+				if n, ok := number(v); ok {
+					m, _ := number(operand)
+					c = int(n - m)
+				} else if s, ok := v.(string); ok {
+					c = strings.Compare(s, operand.(string))
+				} else {
+					return false
+				}
+				return map[string]bool{"$lt": c < 0, "$lte": c <= 0, "$gt": c > 0, "$gte": c >= 0}[op]
+			})
+		default:
+			t.Fatalf("unsupported operator %s", op)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// anyValue reports whether any value satisfies a predicate
+func anyValue(values []interface{}, predicate func(interface{}) bool) bool {
+	for _, v := range values {
+		if predicate(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal reports whether two values are equal, comparing numbers of any integer type by value
+func equal(a, b interface{}) bool {
+	if m, ok := number(a); ok {
+		n, ok := number(b)
+		return ok && m == n
+	}
+	return a == b
+}
+
+// number returns the value of an integer of any type
+func number(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/concurrency"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/repair"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// decodeDocument decodes a family document. If read repair is enabled, the status and
+// member documents are repaired before decoding, and the repairs are logged and counted.
+// It reports whether the document was repaired.
+func (r *MongoFamilyRepository) decodeDocument(ctx context.Context, raw bson.Raw, doc *FamilyDocument) (bool, error) {
+	repairs, err := r.repairDocument(ctx, raw, doc)
+	return len(repairs) > 0, err
+}
+
+// repairDocument decodes a family document as decodeDocument does and returns the
+// repairs that were made
+func (r *MongoFamilyRepository) repairDocument(ctx context.Context, raw bson.Raw, doc *FamilyDocument) ([]repair.Repair, error) {
+	if !r.readRepair.Enabled {
+		return nil, bson.Unmarshal(raw, doc)
+	}
+
+	var m bson.M
+	if err := bson.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+
+	var repairs []repair.Repair
+	for _, path := range []string{"parents", "children"} {
+		members, memberRepairs := repair.Members(m[path], repair.CamelCase, path)
+		if members == nil {
+			// Leave the field unchanged so that decoding reports the error
+			continue
+		}
+		m[path] = members
+		repairs = append(repairs, memberRepairs...)
+	}
+
+	// Derive a missing status from the number of parents
+	status, _ := m["status"].(string)
+	parents, _ := m["parents"].([]interface{})
+	status, statusRepairs := repair.Status(status, len(parents))
+	if len(statusRepairs) > 0 {
+		m["status"] = status
+		repairs = append(repairs, statusRepairs...)
+	}
+
+	if len(repairs) == 0 {
+		return nil, bson.Unmarshal(raw, doc)
+	}
+
+	data, err := bson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	if err := bson.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+
+	repair.Record(ctx, r.logger, "mongodb", doc.FamilyID, repairs)
+	return repairs, nil
+}
+
+// writeBack saves the canonical form of the families with the given IDs if write-back is
+// enabled. The families are saved concurrently, within the repository fan-out limit.
+// Failures are logged and do not fail the read.
+func (r *MongoFamilyRepository) writeBack(ctx context.Context, families []*entity.Family, repairedIDs map[string]bool) {
+	if !r.readRepair.WriteBack || len(repairedIDs) == 0 {
+		return
+	}
+	repaired := make([]*entity.Family, 0, len(repairedIDs))
+	for _, fam := range families {
+		if repairedIDs[fam.ID()] {
+			repaired = append(repaired, fam)
+		}
+	}
+	_ = concurrency.ForEach(ctx, r.fanOut, len(repaired), func(ctx context.Context, i int) error {
+		fam := repaired[i]
+		if err := r.Save(ctx, fam); err != nil {
+			r.logger.Warn(ctx, "Failed to write back repaired family", zap.Error(err), zap.String("family_id", fam.ID()))
+			return nil
+		}
+		r.logger.Info(ctx, "Wrote back repaired family", zap.String("family_id", fam.ID()))
+		return nil
+	})
+}
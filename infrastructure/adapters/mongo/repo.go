@@ -9,9 +9,13 @@
 	"time"
 
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
 	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/assembly"
 	circuit "github.com/abitofhelp/family-service/infrastructure/adapters/circuitwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/concurrency"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/hedge"
 	rate "github.com/abitofhelp/family-service/infrastructure/adapters/ratewrapper"
 	"github.com/abitofhelp/servicelib/errors"
 	"github.com/abitofhelp/servicelib/logging"
@@ -54,29 +58,45 @@ func getRetryConfig() retry.Config {
 
 // FamilyDocument represents how a family is stored in MongoDB
 type FamilyDocument struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty"`
-	FamilyID string             `bson:"family_id"`
-	Status   string             `bson:"status"`
-	Parents  []ParentDocument   `bson:"parents"`
-	Children []ChildDocument    `bson:"children"`
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	FamilyID    string             `bson:"family_id"`
+	Status      string             `bson:"status"`
+	Parents     []ParentDocument   `bson:"parents"`
+	Children    []ChildDocument    `bson:"children"`
+	ExternalIDs map[string]string  `bson:"externalIds,omitempty"`
+	OwnerID     string             `bson:"ownerId,omitempty"`   // ID of the user who owns the family
+	TenantID    string             `bson:"tenantId,omitempty"`  // ID of the tenant of the family
+	DeletedAt   *time.Time         `bson:"deletedAt,omitempty"` // Set while the family is soft deleted
+	Version     int                `bson:"version"`             // Number of saves of the family
+
+	// Counts of the parents and children, stored so that filters on counts can use indexes
+	ParentCount   int `bson:"parentCount"`
+	ChildrenCount int `bson:"childrenCount"`
 }
 
 // ParentDocument represents how a parent is stored in MongoDB
 type ParentDocument struct {
-	ID        string  `bson:"id"`
-	FirstName string  `bson:"firstName"`
-	LastName  string  `bson:"lastName"`
-	BirthDate string  `bson:"birthDate"`
-	DeathDate *string `bson:"deathDate,omitempty"`
+	ID            string               `bson:"id"`
+	FirstName     string               `bson:"firstName"`
+	LastName      string               `bson:"lastName"`
+	BirthDate     string               `bson:"birthDate"`
+	DeathDate     *string              `bson:"deathDate,omitempty"`
+	ExternalIDs   map[string]string    `bson:"externalIds,omitempty"`
+	PreferredName string               `bson:"preferredName,omitempty"`
+	NameHistory   []NameChangeDocument `bson:"nameHistory,omitempty"`
 }
 
 // ChildDocument represents how a child is stored in MongoDB
 type ChildDocument struct {
-	ID        string  `bson:"id"`
-	FirstName string  `bson:"firstName"`
-	LastName  string  `bson:"lastName"`
-	BirthDate string  `bson:"birthDate"`
-	DeathDate *string `bson:"deathDate,omitempty"`
+	ID            string               `bson:"id"`
+	FirstName     string               `bson:"firstName"`
+	LastName      string               `bson:"lastName"`
+	BirthDate     string               `bson:"birthDate"`
+	DeathDate     *string              `bson:"deathDate,omitempty"`
+	ExternalIDs   map[string]string    `bson:"externalIds,omitempty"`
+	PreferredName string               `bson:"preferredName,omitempty"`
+	NameHistory   []NameChangeDocument `bson:"nameHistory,omitempty"`
+	Consents      []ConsentDocument    `bson:"consents,omitempty"`
 }
 
 // MongoFamilyRepository implements the ports.FamilyRepository interface for MongoDB
@@ -85,8 +105,14 @@ type MongoFamilyRepository struct {
 	logger         *logging.ContextLogger
 	circuitBreaker *circuit.CircuitBreaker
 	rateLimiter    *rate.RateLimiter
-	batchSize      int32        // Default batch size for queries
+	hedger         *hedge.Hedger // Hedges reads by ID; nil when hedging is disabled
+	batches        *batchSizer  // Chooses the batch size of reads of many families
 	defaultTimeout time.Duration // Default timeout for operations
+	readRepair     config.ReadRepairConfig
+	memoryBudget   config.MemoryBudgetConfig // Bounds the memory of results of many families
+	locking        config.LockingConfig      // Serializes the changes of a family
+	fanOut         *concurrency.Limiter // Limits the operations of a fan-out, such as write-back
+	transactions   *transactionSupport  // Whether the deployment runs units of work
 }
 
 // Ensure MongoFamilyRepository implements ports.FamilyRepository
@@ -131,6 +157,36 @@ func NewMongoFamilyRepository(collection *mongo.Collection, logger *logging.Cont
 		}
 	}
 
+	// Get the read repair configuration, reading strictly if it is not configured
+	var readRepair config.ReadRepairConfig
+	if globalConfig != nil {
+		readRepair = globalConfig.Database.MongoDB.ReadRepair
+	}
+
+	// Get the memory budget of results, leaving them unbounded if it is not configured
+	var memoryBudget config.MemoryBudgetConfig
+	if globalConfig != nil {
+		memoryBudget = globalConfig.Database.MemoryBudget
+	}
+
+	// Get the locking configuration, changing families without locks if it is not configured
+	var locking config.LockingConfig
+	if globalConfig != nil {
+		locking = globalConfig.Database.Locking
+	}
+
+	// Get the batch size configuration, reading 100 documents per batch if it is not configured
+	var batchConfig config.MongoBatchConfig
+	if globalConfig != nil {
+		batchConfig = globalConfig.Database.MongoDB.Batch
+	}
+
+	// Fan out one operation at a time unless concurrency limits are configured
+	fanOutLimit := 1
+	if globalConfig != nil && globalConfig.Concurrency.Enabled {
+		fanOutLimit = globalConfig.Concurrency.Repository
+	}
+
 	// Create a new zap logger for the circuit breaker and rate limiter
 	zapLogger := zap.NewExample()
 
@@ -140,13 +196,25 @@ func NewMongoFamilyRepository(collection *mongo.Collection, logger *logging.Cont
 	// Create rate limiter using the family-service wrapper
 	rl := rate.NewRateLimiter("mongodb", rateConfig, zapLogger)
 
+	// Hedge reads by ID if hedging is configured
+	var hedger *hedge.Hedger
+	if globalConfig != nil {
+		hedger = hedge.NewHedger("mongodb", &globalConfig.Hedge, zapLogger)
+	}
+
 	repo := &MongoFamilyRepository{
 		Collection:     collection,
 		logger:         logger,
 		circuitBreaker: cb,
 		rateLimiter:    rl,
-		batchSize:      100,                // Process 100 documents at a time
+		hedger:         hedger,
+		batches:        newBatchSizer(batchConfig),
 		defaultTimeout: 5 * time.Second,    // Default timeout for operations
+		readRepair:     readRepair,
+		memoryBudget:   memoryBudget,
+		locking:        locking,
+		fanOut:         concurrency.NewLimiter(concurrency.ClassRepository, fanOutLimit),
+		transactions:   &transactionSupport{},
 	}
 
 	// Determine if we should skip index creation (useful for tests)
@@ -169,36 +237,8 @@ func (r *MongoFamilyRepository) ensureIndexes(ctx context.Context) {
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, r.defaultTimeout)
 	defer cancel()
 
-	// Create indexes
-	indexes := []mongo.IndexModel{
-		{
-			Keys: bson.D{
-				{Key: "family_id", Value: 1},
-			},
-			Options: options.Index().SetUnique(true),
-		},
-		{
-			Keys: bson.D{
-				{Key: "parents.id", Value: 1},
-			},
-			Options: options.Index().SetBackground(true),
-		},
-		{
-			Keys: bson.D{
-				{Key: "children.id", Value: 1},
-			},
-			Options: options.Index().SetBackground(true),
-		},
-		{
-			Keys: bson.D{
-				{Key: "status", Value: 1},
-			},
-			Options: options.Index().SetBackground(true),
-		},
-	}
-
 	// Create indexes in the background
-	_, err := r.Collection.Indexes().CreateMany(ctxWithTimeout, indexes)
+	_, err := r.Collection.Indexes().CreateMany(ctxWithTimeout, familyIndexes())
 	if err != nil {
 		r.logger.Error(ctx, "Failed to create indexes", zap.Error(err))
 		// Don't panic, just log the error
@@ -221,6 +261,7 @@ func (r *MongoFamilyRepository) GetByID(ctx context.Context, id string) (*entity
 
 	var doc FamilyDocument
 	var family *entity.Family
+	repairedIDs := make(map[string]bool)
 	var retryErr error
 
 	// Define the operation to retry
@@ -229,17 +270,29 @@ func (r *MongoFamilyRepository) GetByID(ctx context.Context, id string) (*entity
 		findOptions := options.FindOne().
 			// Only return the fields we need
 			SetProjection(bson.M{
-				"_id":       1,
-				"family_id": 1,
-				"status":    1,
-				"parents":   1,
-				"children":  1,
+				"_id":         1,
+				"family_id":   1,
+				"status":      1,
+				"parents":     1,
+				"children":    1,
+				"externalIds": 1,
+				"ownerId":     1,
 			})
 
-		// Find the family with the specified ID
-		err := r.Collection.FindOne(ctx, bson.M{"family_id": id}, findOptions).Decode(&doc)
+		// Find the family with the specified ID; the query may be hedged, so the
+		// document is decoded once, from the attempt that is used
+		raw, err := hedge.Do(ctx, r.hedger, "GetByID", func(ctx context.Context) (bson.Raw, error) {
+			return r.Collection.FindOne(ctx, inTenant(ctx, notDeleted(bson.M{"family_id": id})), findOptions).Raw()
+		})
+		if err == nil {
+			var repaired bool
+			repaired, err = r.decodeDocument(ctx, raw, &doc)
+			if repaired {
+				repairedIDs[doc.FamilyID] = true
+			}
+		}
 		if err != nil {
-			if err == mongo.ErrNoDocuments {
+			if errors.Is(err, mongo.ErrNoDocuments) {
 				r.logger.Info(ctx, "Family not found in MongoDB", zap.String("family_id", id))
 				return errors.NewNotFoundError("Family", id, nil)
 			}
@@ -258,12 +311,12 @@ func (r *MongoFamilyRepository) GetByID(ctx context.Context, id string) (*entity
 	// Define which errors are retryable
 	isRetryable := func(err error) bool {
 		// Don't retry not found errors
-		if _, ok := err.(*errors.NotFoundError); ok {
+		if errors.IsNotFoundError(err) {
 			return false
 		}
 
 		// Don't retry validation errors
-		if _, ok := err.(*errors.ValidationError); ok {
+		if errors.IsValidationError(err) {
 			return false
 		}
 
@@ -314,13 +367,13 @@ func (r *MongoFamilyRepository) GetByID(ctx context.Context, id string) (*entity
 	// Handle retry errors
 	if retryErr != nil {
 		// If it's already a typed error, return it directly
-		if _, ok := retryErr.(*errors.NotFoundError); ok {
+		if errors.IsNotFoundError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.ValidationError); ok {
+		if errors.IsValidationError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.DatabaseError); ok {
+		if errors.IsDatabaseError(retryErr) {
 			return nil, retryErr
 		}
 
@@ -328,6 +381,9 @@ func (r *MongoFamilyRepository) GetByID(ctx context.Context, id string) (*entity
 		return nil, errors.NewDatabaseError("failed to get family from MongoDB after retries", "query", "families", retryErr)
 	}
 
+	// Write back the canonical form if the family was repaired
+	r.writeBack(ctx, []*entity.Family{family}, repairedIDs)
+
 	r.logger.Debug(ctx, "Successfully retrieved family from MongoDB", zap.String("family_id", id))
 	return family, nil
 }
@@ -347,36 +403,75 @@ func (r *MongoFamilyRepository) Save(ctx context.Context, fam *entity.Family) er
 		return err
 	}
 
-	// Create a context with timeout using the repository's default timeout
-	ctxWithTimeout, cancel := context.WithTimeout(ctx, r.defaultTimeout)
+	// Create a context with timeout using the repository's default timeout, in the unit of
+	// work of the context
+	ctxWithTimeout, cancel := context.WithTimeout(r.joinUnitOfWork(ctx), r.defaultTimeout)
 	defer cancel()
 
 	// Convert domain entity to document
 	doc := r.entityToDocument(fam)
+	doc.TenantID = tenantOf(ctx)
 	var retryErr error
 
 	// Define the operation to retry
 	operation := func(ctx context.Context) error {
-		// Use ReplaceOne with upsert to handle both insert and update
+		// Enforce uniqueness of external IDs
+		if err := r.checkExternalIDsUnique(ctx, fam); err != nil {
+			return err
+		}
+
+		// Read the stored version, over which the next version is saved
 		// Query by family_id instead of _id
-		_, err := r.Collection.ReplaceOne(
-			ctx,
-			bson.M{"family_id": doc.FamilyID},
-			doc,
-			options.Replace().SetUpsert(true),
-		)
+		var stored FamilyDocument
+		err := r.Collection.FindOne(ctx, bson.M{"family_id": doc.FamilyID}, options.FindOne().SetProjection(bson.M{"version": 1})).Decode(&stored)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// Insert a new family; the unique index on family_id rejects a concurrent insert
+			doc.Version = fam.Version() + 1
+			if _, err := r.Collection.InsertOne(ctx, doc); err != nil {
+				if mongo.IsDuplicateKeyError(err) && fam.Version() == 0 {
+					return existingFamilyError(fam)
+				}
+				if mongo.IsDuplicateKeyError(err) {
+					return staleVersionError(fam)
+				}
+				r.logger.Error(ctx, "Failed to save family to MongoDB", zap.Error(err), zap.String("family_id", fam.ID()))
+				return errors.NewDatabaseError("failed to save family to MongoDB", "save", "families", err)
+			}
+			return nil
+		}
+		if err != nil {
+			r.logger.Error(ctx, "Failed to read family version from MongoDB", zap.Error(err), zap.String("family_id", fam.ID()))
+			return errors.NewDatabaseError("failed to read family version from MongoDB", "save", "families", err)
+		}
+		if fam.Version() == 0 && !ports.IsOverwrite(ctx) {
+			return existingFamilyError(fam)
+		}
+		if fam.Version() != 0 && fam.Version() != stored.version() {
+			return staleVersionError(fam)
+		}
 
+		// Replace the family only if no other operation saved it since its version was read
+		doc.Version = stored.version() + 1
+		result, err := r.Collection.ReplaceOne(ctx, inTenant(ctx, versionFilter(doc.FamilyID, stored.version())), doc)
 		if err != nil {
 			r.logger.Error(ctx, "Failed to save family to MongoDB", zap.Error(err), zap.String("family_id", fam.ID()))
 			return errors.NewDatabaseError("failed to save family to MongoDB", "save", "families", err)
 		}
+		if result.MatchedCount == 0 {
+			return staleVersionError(fam)
+		}
 		return nil
 	}
 
 	// Define which errors are retryable
 	isRetryable := func(err error) bool {
 		// Don't retry validation errors
-		if _, ok := err.(*errors.ValidationError); ok {
+		if errors.IsValidationError(err) {
+			return false
+		}
+
+		// Don't retry version conflicts, which fail again until the family is read again
+		if errors.Is(err, domainerrors.ErrConcurrencyConflict) {
 			return false
 		}
 
@@ -427,16 +522,20 @@ func (r *MongoFamilyRepository) Save(ctx context.Context, fam *entity.Family) er
 	// Handle retry errors
 	if retryErr != nil {
 		// If it's already a typed error, return it directly
-		if _, ok := retryErr.(*errors.ValidationError); ok {
+		if errors.IsValidationError(retryErr) {
+			return retryErr
+		}
+		if errors.IsDatabaseError(retryErr) {
 			return retryErr
 		}
-		if _, ok := retryErr.(*errors.DatabaseError); ok {
+		if errors.Is(retryErr, domainerrors.ErrConcurrencyConflict) {
 			return retryErr
 		}
 
 		// Otherwise, wrap it in a database error
 		return errors.NewDatabaseError("failed to save family to MongoDB after retries", "save", "families", retryErr)
 	}
+	fam.SetVersion(doc.Version)
 
 	r.logger.Debug(ctx, "Successfully saved family to MongoDB", zap.String("family_id", fam.ID()))
 	return nil
@@ -456,25 +555,31 @@ func (r *MongoFamilyRepository) FindByParentID(ctx context.Context, parentID str
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, r.defaultTimeout)
 	defer cancel()
 
-	var families []*entity.Family
-	var retryErr error
+	var result *assembly.Assembler
+	repairedIDs := make(map[string]bool)
+	var retryErr, budgetErr error
 
 	// Define the operation to retry
 	operation := func(ctx context.Context) error {
+		// Start with the current batch size, which is tuned as the documents are read
+		meter := r.batches.meter("FindByParentID")
+
 		// Set up options for the query with batch size and projection
 		findOptions := options.Find().
-			SetBatchSize(r.batchSize).
+			SetBatchSize(int32(meter.size)).
 			// Only return the fields we need
 			SetProjection(bson.M{
-				"_id":       1,
-				"family_id": 1,
-				"status":    1,
-				"parents":   1,
-				"children":  1,
+				"_id":         1,
+				"family_id":   1,
+				"status":      1,
+				"parents":     1,
+				"children":    1,
+				"externalIds": 1,
+				"ownerId":     1,
 			})
 
 		// Find families with the specified parent ID
-		cursor, err := r.Collection.Find(ctx, bson.M{"parents.id": parentID}, findOptions)
+		cursor, err := r.Collection.Find(ctx, inTenant(ctx, notDeleted(bson.M{"parents.id": parentID})), findOptions)
 		if err != nil {
 			r.logger.Error(ctx, "Failed to find families by parent ID in MongoDB", 
 				zap.Error(err), 
@@ -483,16 +588,18 @@ func (r *MongoFamilyRepository) FindByParentID(ctx context.Context, parentID str
 		}
 		defer cursor.Close(ctx)
 
-		// Process documents in batches
-		families = make([]*entity.Family, 0)
+		// Process documents in batches, stopping if the result exceeds the memory budget
+		result = assembly.NewAssembler(r.memoryBudget, "FindByParentID")
+		budgetErr = nil
 
 		// Create a buffer for batch processing
-		batch := make([]FamilyDocument, 0, r.batchSize)
+		batch := make([]FamilyDocument, 0, meter.size)
 
 		// Process the cursor in batches
 		for cursor.Next(ctx) {
-			// If we've reached the batch size, process the batch
-			if len(batch) >= int(r.batchSize) {
+			// If we've reached the batch size, tune the size of the next batch and process the batch
+			if meter.Full(len(batch)) {
+				meter.Done(len(batch))
 				// Process the current batch
 				batchFamilies, err := r.processFamilyBatch(ctx, batch)
 				if err != nil {
@@ -500,15 +607,24 @@ func (r *MongoFamilyRepository) FindByParentID(ctx context.Context, parentID str
 				}
 
 				// Add the processed families to the result
-				families = append(families, batchFamilies...)
+				if budgetErr = result.AddAll(batchFamilies); budgetErr != nil {
+					// Stop reading without failing the operation, so that a result that is
+					// too large is neither retried nor counted against the circuit breaker
+					return nil
+				}
 
 				// Clear the batch
 				batch = batch[:0]
 			}
 
 			// Decode the current document
+			meter.Read(cursor.Current)
 			var doc FamilyDocument
-			if err := cursor.Decode(&doc); err != nil {
+			repaired, err := r.decodeDocument(ctx, cursor.Current, &doc)
+			if repaired {
+				repairedIDs[doc.FamilyID] = true
+			}
+			if err != nil {
 				r.logger.Error(ctx, "Failed to decode family document", zap.Error(err))
 				return errors.NewDatabaseError("failed to decode family document", "query", "families", err)
 			}
@@ -523,7 +639,11 @@ func (r *MongoFamilyRepository) FindByParentID(ctx context.Context, parentID str
 			if err != nil {
 				return err
 			}
-			families = append(families, batchFamilies...)
+			if budgetErr = result.AddAll(batchFamilies); budgetErr != nil {
+				// Stop reading without failing the operation, so that a result that is
+				// too large is neither retried nor counted against the circuit breaker
+				return nil
+			}
 		}
 
 		// Check for cursor errors
@@ -540,7 +660,7 @@ func (r *MongoFamilyRepository) FindByParentID(ctx context.Context, parentID str
 	// Define which errors are retryable
 	isRetryable := func(err error) bool {
 		// Don't retry validation errors
-		if _, ok := err.(*errors.ValidationError); ok {
+		if errors.IsValidationError(err) {
 			return false
 		}
 
@@ -591,10 +711,10 @@ func (r *MongoFamilyRepository) FindByParentID(ctx context.Context, parentID str
 	// Handle retry errors
 	if retryErr != nil {
 		// If it's already a typed error, return it directly
-		if _, ok := retryErr.(*errors.ValidationError); ok {
+		if errors.IsValidationError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.DatabaseError); ok {
+		if errors.IsDatabaseError(retryErr) {
 			return nil, retryErr
 		}
 
@@ -602,9 +722,19 @@ func (r *MongoFamilyRepository) FindByParentID(ctx context.Context, parentID str
 		return nil, errors.NewDatabaseError("failed to find families by parent ID after retries", "query", "families", retryErr)
 	}
 
+	// Return the error of a result that exceeds the memory budget
+	if budgetErr != nil {
+		return nil, budgetErr
+	}
+	families := result.Families()
+
 	r.logger.Debug(ctx, "Successfully found families by parent ID in MongoDB",
 		zap.String("parent_id", parentID),
 		zap.Int("count", len(families)))
+
+	// Write back the canonical form of repaired families
+	r.writeBack(ctx, families, repairedIDs)
+
 	return families, nil
 }
 
@@ -624,6 +754,7 @@ func (r *MongoFamilyRepository) FindByChildID(ctx context.Context, childID strin
 
 	var doc FamilyDocument
 	var family *entity.Family
+	repairedIDs := make(map[string]bool)
 	var retryErr error
 
 	// Define the operation to retry
@@ -632,17 +763,26 @@ func (r *MongoFamilyRepository) FindByChildID(ctx context.Context, childID strin
 		findOptions := options.FindOne().
 			// Only return the fields we need
 			SetProjection(bson.M{
-				"_id":       1,
-				"family_id": 1,
-				"status":    1,
-				"parents":   1,
-				"children":  1,
+				"_id":         1,
+				"family_id":   1,
+				"status":      1,
+				"parents":     1,
+				"children":    1,
+				"externalIds": 1,
+				"ownerId":     1,
 			})
 
 		// Find the family with the specified child ID
-		err := r.Collection.FindOne(ctx, bson.M{"children.id": childID}, findOptions).Decode(&doc)
+		raw, err := r.Collection.FindOne(ctx, inTenant(ctx, notDeleted(bson.M{"children.id": childID})), findOptions).Raw()
+		if err == nil {
+			var repaired bool
+			repaired, err = r.decodeDocument(ctx, raw, &doc)
+			if repaired {
+				repairedIDs[doc.FamilyID] = true
+			}
+		}
 		if err != nil {
-			if err == mongo.ErrNoDocuments {
+			if errors.Is(err, mongo.ErrNoDocuments) {
 				r.logger.Info(ctx, "Family with child not found in MongoDB", zap.String("child_id", childID))
 				return errors.NewNotFoundError("Family with Child", childID, nil)
 			}
@@ -661,12 +801,12 @@ func (r *MongoFamilyRepository) FindByChildID(ctx context.Context, childID strin
 	// Define which errors are retryable
 	isRetryable := func(err error) bool {
 		// Don't retry not found errors
-		if _, ok := err.(*errors.NotFoundError); ok {
+		if errors.IsNotFoundError(err) {
 			return false
 		}
 
 		// Don't retry validation errors
-		if _, ok := err.(*errors.ValidationError); ok {
+		if errors.IsValidationError(err) {
 			return false
 		}
 
@@ -717,13 +857,13 @@ func (r *MongoFamilyRepository) FindByChildID(ctx context.Context, childID strin
 	// Handle retry errors
 	if retryErr != nil {
 		// If it's already a typed error, return it directly
-		if _, ok := retryErr.(*errors.NotFoundError); ok {
+		if errors.IsNotFoundError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.ValidationError); ok {
+		if errors.IsValidationError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.DatabaseError); ok {
+		if errors.IsDatabaseError(retryErr) {
 			return nil, retryErr
 		}
 
@@ -735,6 +875,9 @@ func (r *MongoFamilyRepository) FindByChildID(ctx context.Context, childID strin
 		zap.String("child_id", childID),
 		zap.String("family_id", doc.FamilyID))
 
+	// Write back the canonical form if the family was repaired
+	r.writeBack(ctx, []*entity.Family{family}, repairedIDs)
+
 	return family, nil
 }
 
@@ -743,46 +886,61 @@ func (r *MongoFamilyRepository) FindByChildID(ctx context.Context, childID strin
 func (r *MongoFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family, error) {
 	r.logger.Debug(ctx, "Getting all families from MongoDB using batch processing")
 
+	return r.findFamilies(ctx, "GetAll", bson.M{})
+}
+
+// findFamilies finds the families that match a filter, decoding the documents in batches.
+// Options such as a sort or a limit are merged into the options of the query.
+func (r *MongoFamilyRepository) findFamilies(ctx context.Context, name string, filter bson.M, opts ...*options.FindOptions) ([]*entity.Family, error) {
 	// Create a context with timeout
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, r.defaultTimeout)
 	defer cancel()
 
-	var families []*entity.Family
-	var retryErr error
+	var result *assembly.Assembler
+	repairedIDs := make(map[string]bool)
+	var retryErr, budgetErr error
 
 	// Define the operation to retry
 	operation := func(ctx context.Context) error {
+		// Start with the current batch size, which is tuned as the documents are read
+		meter := r.batches.meter(name)
+
 		// Use a more efficient approach with batch processing
 		// Set up options for the query
 		findOptions := options.Find().
-			SetBatchSize(r.batchSize).
+			SetBatchSize(int32(meter.size)).
 			// Only return the fields we need
 			SetProjection(bson.M{
-				"_id":       1,
-				"family_id": 1,
-				"status":    1,
-				"parents":   1,
-				"children":  1,
+				"_id":         1,
+				"family_id":   1,
+				"status":      1,
+				"parents":     1,
+				"children":    1,
+				"externalIds": 1,
+				"ownerId":     1,
 			})
+		findOptions = options.MergeFindOptions(append([]*options.FindOptions{findOptions}, opts...)...)
 
-		// Find all documents in the collection
-		cursor, err := r.Collection.Find(ctx, bson.M{}, findOptions)
+		// Find the matching documents in the collection
+		cursor, err := r.Collection.Find(ctx, inTenant(ctx, notDeleted(filter)), findOptions)
 		if err != nil {
 			r.logger.Error(ctx, "Failed to get all families from MongoDB", zap.Error(err))
 			return errors.NewDatabaseError("failed to get all families", "query", "families", err)
 		}
 		defer cursor.Close(ctx)
 
-		// Process documents in batches
-		families = make([]*entity.Family, 0)
+		// Process documents in batches, stopping if the result exceeds the memory budget
+		result = assembly.NewAssembler(r.memoryBudget, name)
+		budgetErr = nil
 
 		// Create a buffer for batch processing
-		batch := make([]FamilyDocument, 0, r.batchSize)
+		batch := make([]FamilyDocument, 0, meter.size)
 
 		// Process the cursor in batches
 		for cursor.Next(ctx) {
-			// If we've reached the batch size, process the batch
-			if len(batch) >= int(r.batchSize) {
+			// If we've reached the batch size, tune the size of the next batch and process the batch
+			if meter.Full(len(batch)) {
+				meter.Done(len(batch))
 				// Process the current batch
 				batchFamilies, err := r.processFamilyBatch(ctx, batch)
 				if err != nil {
@@ -790,15 +948,24 @@ func (r *MongoFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family, e
 				}
 
 				// Add the processed families to the result
-				families = append(families, batchFamilies...)
+				if budgetErr = result.AddAll(batchFamilies); budgetErr != nil {
+					// Stop reading without failing the operation, so that a result that is
+					// too large is neither retried nor counted against the circuit breaker
+					return nil
+				}
 
 				// Clear the batch
 				batch = batch[:0]
 			}
 
 			// Decode the current document
+			meter.Read(cursor.Current)
 			var doc FamilyDocument
-			if err := cursor.Decode(&doc); err != nil {
+			repaired, err := r.decodeDocument(ctx, cursor.Current, &doc)
+			if repaired {
+				repairedIDs[doc.FamilyID] = true
+			}
+			if err != nil {
 				r.logger.Error(ctx, "Failed to decode family document", zap.Error(err))
 				return errors.NewDatabaseError("failed to decode family document", "query", "families", err)
 			}
@@ -813,7 +980,11 @@ func (r *MongoFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family, e
 			if err != nil {
 				return err
 			}
-			families = append(families, batchFamilies...)
+			if budgetErr = result.AddAll(batchFamilies); budgetErr != nil {
+				// Stop reading without failing the operation, so that a result that is
+				// too large is neither retried nor counted against the circuit breaker
+				return nil
+			}
 		}
 
 		// Check for cursor errors
@@ -828,7 +999,7 @@ func (r *MongoFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family, e
 	// Define which errors are retryable
 	isRetryable := func(err error) bool {
 		// Don't retry validation errors
-		if _, ok := err.(*errors.ValidationError); ok {
+		if errors.IsValidationError(err) {
 			return false
 		}
 
@@ -854,7 +1025,7 @@ func (r *MongoFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family, e
 			err := circuitOperation(ctx)
 			return err == nil, err
 		}
-		_, err := circuit.Execute(ctx, r.circuitBreaker, "GetAll", circuitOpWrapper)
+		_, err := circuit.Execute(ctx, r.circuitBreaker, name, circuitOpWrapper)
 		return err
 	}
 
@@ -864,7 +1035,7 @@ func (r *MongoFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family, e
 		err := rateOperation(ctx)
 		return err == nil, err
 	}
-	_, err := rate.Execute(ctxWithTimeout, r.rateLimiter, "GetAll", rateOpWrapper)
+	_, err := rate.Execute(ctxWithTimeout, r.rateLimiter, name, rateOpWrapper)
 
 	// Check for errors from rate limiter or circuit breaker
 	if err != nil && retryErr == nil {
@@ -879,18 +1050,28 @@ func (r *MongoFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family, e
 	// Handle retry errors
 	if retryErr != nil {
 		// If it's already a typed error, return it directly
-		if _, ok := retryErr.(*errors.ValidationError); ok {
+		if errors.IsValidationError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.DatabaseError); ok {
+		if errors.IsDatabaseError(retryErr) {
 			return nil, retryErr
 		}
 
 		// Otherwise, wrap it in a database error
-		return nil, errors.NewDatabaseError("failed to get all families after retries", "query", "families", retryErr)
+		return nil, errors.NewDatabaseError("failed to find families after retries", "query", "families", retryErr)
+	}
+
+	// Return the error of a result that exceeds the memory budget
+	if budgetErr != nil {
+		return nil, budgetErr
 	}
+	families := result.Families()
+
+	r.logger.Debug(ctx, "Successfully retrieved families from MongoDB", zap.Int("count", len(families)))
+
+	// Write back the canonical form of repaired families
+	r.writeBack(ctx, families, repairedIDs)
 
-	r.logger.Debug(ctx, "Successfully retrieved all families from MongoDB", zap.Int("count", len(families)))
 	return families, nil
 }
 
@@ -937,6 +1118,12 @@ func (r *MongoFamilyRepository) documentToEntity(doc FamilyDocument) (*entity.Fa
 		if err != nil {
 			return nil, err
 		}
+		if err := parentEntity.SetExternalIDs(p.ExternalIDs); err != nil {
+			return nil, err
+		}
+		if err := setMemberNames(parentEntity, p.PreferredName, p.NameHistory); err != nil {
+			return nil, err
+		}
 		parents = append(parents, parentEntity)
 	}
 
@@ -963,12 +1150,30 @@ func (r *MongoFamilyRepository) documentToEntity(doc FamilyDocument) (*entity.Fa
 		if err != nil {
 			return nil, err
 		}
+		if err := childEntity.SetExternalIDs(c.ExternalIDs); err != nil {
+			return nil, err
+		}
+		if err := setMemberNames(childEntity, c.PreferredName, c.NameHistory); err != nil {
+			return nil, err
+		}
+		if err := setChildConsents(childEntity, c.Consents); err != nil {
+			return nil, err
+		}
 		children = append(children, childEntity)
 	}
 
 	// Create family entity
 	// Use FamilyID field which contains the string ID
-	return entity.NewFamily(doc.FamilyID, entity.Status(doc.Status), parents, children)
+	family, err := entity.NewFamily(doc.FamilyID, entity.Status(doc.Status), parents, children)
+	if err != nil {
+		return nil, err
+	}
+	if err := family.SetExternalIDs(doc.ExternalIDs); err != nil {
+		return nil, err
+	}
+	family.SetOwnerID(doc.OwnerID)
+	family.SetVersion(doc.version())
+	return family, nil
 }
 
 // entityToDocument converts a Family entity to a FamilyDocument
@@ -983,11 +1188,14 @@ func (r *MongoFamilyRepository) entityToDocument(fam *entity.Family) FamilyDocum
 		}
 
 		parents = append(parents, ParentDocument{
-			ID:        p.ID(),
-			FirstName: p.FirstName(),
-			LastName:  p.LastName(),
-			BirthDate: p.BirthDate().Format(time.RFC3339),
-			DeathDate: deathDateStr,
+			ID:            p.ID(),
+			FirstName:     p.FirstName(),
+			LastName:      p.LastName(),
+			BirthDate:     p.BirthDate().Format(time.RFC3339),
+			DeathDate:     deathDateStr,
+			ExternalIDs:   p.ExternalIDs(),
+			PreferredName: p.PreferredName(),
+			NameHistory:   nameHistoryToDocuments(p.NameHistory()),
 		})
 	}
 
@@ -1001,11 +1209,15 @@ func (r *MongoFamilyRepository) entityToDocument(fam *entity.Family) FamilyDocum
 		}
 
 		children = append(children, ChildDocument{
-			ID:        c.ID(),
-			FirstName: c.FirstName(),
-			LastName:  c.LastName(),
-			BirthDate: c.BirthDate().Format(time.RFC3339),
-			DeathDate: deathDateStr,
+			ID:            c.ID(),
+			FirstName:     c.FirstName(),
+			LastName:      c.LastName(),
+			BirthDate:     c.BirthDate().Format(time.RFC3339),
+			DeathDate:     deathDateStr,
+			ExternalIDs:   c.ExternalIDs(),
+			PreferredName: c.PreferredName(),
+			NameHistory:   nameHistoryToDocuments(c.NameHistory()),
+			Consents:      consentsToDocuments(c.Consents()),
 		})
 	}
 
@@ -1014,10 +1226,15 @@ func (r *MongoFamilyRepository) entityToDocument(fam *entity.Family) FamilyDocum
 
 	// Create document
 	return FamilyDocument{
-		ID:       objectID,
-		FamilyID: fam.ID(),
-		Status:   string(fam.Status()),
-		Parents:  parents,
-		Children: children,
+		ID:          objectID,
+		FamilyID:    fam.ID(),
+		Status:      string(fam.Status()),
+		Parents:     parents,
+		Children:    children,
+		ExternalIDs: fam.ExternalIDs(),
+		OwnerID:     fam.OwnerID(),
+
+		ParentCount:   len(parents),
+		ChildrenCount: len(children),
 	}
 }
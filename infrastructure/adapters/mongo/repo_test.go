@@ -3,6 +3,7 @@
 package mongo
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap/zaptest"
 )
@@ -330,3 +332,48 @@ func TestDocumentToEntity_InvalidDates(t *testing.T) {
 		})
 	}
 }
+
+// TestDecodeDocument_ReadRepair tests that legacy documents are repaired before decoding
+// when read repair is enabled, and decoded unchanged otherwise.
+func TestDecodeDocument_ReadRepair(t *testing.T) {
+	repo := setupTest(t)
+
+	familyID := generateTestUUID()
+	raw, err := bson.Marshal(bson.M{
+		"family_id": familyID,
+		"status":    "married",
+		"parents": bson.A{
+			bson.M{"id": generateTestUUID(), "FirstName": "John", "LastName": "Doe", "birthDate": primitive.NewDateTimeFromTime(time.Date(1980, 1, 2, 0, 0, 0, 0, time.UTC))},
+			bson.M{"ID": generateTestUUID(), "first_name": "Jane", "last_name": "Doe", "birth_date": "1982-03-04"},
+		},
+	})
+	require.NoError(t, err)
+
+	// Strict reads do not repair
+	var doc FamilyDocument
+	repaired, err := repo.decodeDocument(context.Background(), raw, &doc)
+	assert.False(t, repaired)
+	if err == nil {
+		_, err = repo.documentToEntity(doc)
+	}
+	assert.Error(t, err)
+
+	// Tolerant reads repair keys, dates, status, and missing lists
+	repo.readRepair.Enabled = true
+	doc = FamilyDocument{}
+	repaired, err = repo.decodeDocument(context.Background(), raw, &doc)
+	require.NoError(t, err)
+	assert.True(t, repaired)
+	assert.Equal(t, familyID, doc.FamilyID)
+	assert.Equal(t, string(entity.Married), doc.Status)
+	require.Len(t, doc.Parents, 2)
+	assert.Equal(t, "John", doc.Parents[0].FirstName)
+	assert.Equal(t, "1980-01-02T00:00:00Z", doc.Parents[0].BirthDate)
+	assert.Equal(t, "Jane", doc.Parents[1].FirstName)
+	assert.Equal(t, "1982-03-04T00:00:00Z", doc.Parents[1].BirthDate)
+	assert.NotNil(t, doc.Children)
+
+	family, err := repo.documentToEntity(doc)
+	require.NoError(t, err)
+	assert.Equal(t, entity.Married, family.Status())
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/abitofhelp/servicelib/errors"
+)
+
+// protect runs an operation behind the rate limiter and the circuit breaker of the repository.
+// When both are disabled by configuration, the operation runs directly, so that tests and
+// local development are not affected by rejections or by state left by earlier operations.
+//
+// Parameters:
+//   - ctx: The context of the operation
+//   - operation: The name of the operation, used for logging and metrics
+//   - fn: The operation
+//
+// Returns:
+//   - The error returned by fn, or a database error if the operation was rejected
+//     by the rate limiter or the circuit breaker
+func (r *MongoFamilyRepository) protect(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	if r.rateLimiter == nil && r.circuitBreaker == nil {
+		return fn(ctx)
+	}
+
+	var fnErr error
+	err := r.rateLimiter.Execute(ctx, operation, func(ctx context.Context) error {
+		return r.circuitBreaker.Execute(ctx, operation, func(ctx context.Context) error {
+			fnErr = fn(ctx)
+			return fnErr
+		})
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "rate limit exceeded") {
+			return errors.NewDatabaseError("rate limit exceeded", "query", "families", err)
+		}
+		return errors.NewDatabaseError("circuit breaker is open", "query", "families", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/migration"
+	"github.com/abitofhelp/servicelib/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Lock impact of index builds. Since MongoDB 4.2, an index build holds an exclusive lock
+// on the collection only at its start and end, and reads and writes continue in between.
+const (
+	lockIndex       = "Exclusive lock on the collection only at the start and end of the build; reads and writes continue during the build"
+	lockUniqueIndex = lockIndex + "; the build fails if stored documents have duplicate keys"
+)
+
+// familyIndex is an index of the families collection
+type familyIndex struct {
+	keys       bson.D
+	unique     bool
+	background bool
+}
+
+// familyIndexSpecs are the indexes of the families collection, in the order they are created
+var familyIndexSpecs = []familyIndex{
+	{keys: bson.D{{Key: "family_id", Value: 1}}, unique: true},
+	{keys: bson.D{{Key: "parents.id", Value: 1}}, background: true},
+	{keys: bson.D{{Key: "children.id", Value: 1}}, background: true},
+	{keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "family_id", Value: 1}}, background: true},
+	{keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "ownerId", Value: 1}, {Key: "family_id", Value: 1}}, background: true},
+	{keys: bson.D{{Key: "status", Value: 1}}, background: true},
+	{keys: bson.D{{Key: "parentCount", Value: 1}}, background: true},
+	{keys: bson.D{{Key: "childrenCount", Value: 1}}, background: true},
+	{keys: bson.D{{Key: "externalIds.$**", Value: 1}}},
+	{keys: bson.D{{Key: "parents.externalIds.$**", Value: 1}}},
+	{keys: bson.D{{Key: "children.externalIds.$**", Value: 1}}},
+}
+
+// familyIndexes returns the models of the indexes of the families collection
+func familyIndexes() []mongo.IndexModel {
+	models := make([]mongo.IndexModel, 0, len(familyIndexSpecs))
+	for _, index := range familyIndexSpecs {
+		model := mongo.IndexModel{Keys: index.keys}
+		switch {
+		case index.unique:
+			model.Options = options.Index().SetUnique(true)
+		case index.background:
+			model.Options = options.Index().SetBackground(true)
+		}
+		models = append(models, model)
+	}
+	return models
+}
+
+// name returns the name MongoDB gives the index, such as "family_id_1"
+func (i familyIndex) name() string {
+	parts := make([]string, 0, 2*len(i.keys))
+	for _, key := range i.keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// command returns the shell command that creates the index in a collection
+func (i familyIndex) command(collection string) string {
+	keys := make([]string, 0, len(i.keys))
+	for _, key := range i.keys {
+		keys = append(keys, fmt.Sprintf("%q: %v", key.Key, key.Value))
+	}
+	if i.unique {
+		return fmt.Sprintf("db.%s.createIndex({%s}, {unique: true})", collection, strings.Join(keys, ", "))
+	}
+	return fmt.Sprintf("db.%s.createIndex({%s})", collection, strings.Join(keys, ", "))
+}
+
+// Ensure MongoFamilyRepository implements migration.Planner
+var _ migration.Planner = (*MongoFamilyRepository)(nil)
+
+// PlanSchema inspects the database and returns the plan of its indexes without changing it.
+// The number of documents is MongoDB's estimate from the collection metadata.
+func (r *MongoFamilyRepository) PlanSchema(ctx context.Context) (*migration.Plan, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, r.defaultTimeout)
+	defer cancel()
+
+	cursor, err := r.Collection.Indexes().List(ctxWithTimeout)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list indexes", "plan", r.Collection.Name(), err)
+	}
+	var existing []struct {
+		Name string `bson:"name"`
+	}
+	if err := cursor.All(ctxWithTimeout, &existing); err != nil {
+		return nil, errors.NewDatabaseError("failed to list indexes", "plan", r.Collection.Name(), err)
+	}
+	names := make(map[string]bool, len(existing))
+	for _, index := range existing {
+		names[index.Name] = true
+	}
+
+	documents, err := r.Collection.EstimatedDocumentCount(ctxWithTimeout)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to count documents", "plan", r.Collection.Name(), err)
+	}
+
+	collection := r.Collection.Name()
+	plan := &migration.Plan{Backend: "mongodb", Rows: map[string]int64{collection: documents}}
+	for _, index := range familyIndexSpecs {
+		lock := lockIndex
+		if index.unique {
+			lock = lockUniqueIndex
+		}
+		plan.Steps = append(plan.Steps, migration.Step{
+			Name:    "Create index " + index.name(),
+			Table:   collection,
+			DDL:     index.command(collection),
+			Lock:    lock,
+			Applied: names[index.name()],
+		})
+	}
+	return plan, nil
+}
+
+// ApplySchema creates the missing indexes of the families collection
+func (r *MongoFamilyRepository) ApplySchema(ctx context.Context) error {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, r.defaultTimeout)
+	defer cancel()
+
+	if _, err := r.Collection.Indexes().CreateMany(ctxWithTimeout, familyIndexes()); err != nil {
+		return errors.NewDatabaseError("failed to create indexes", "apply", r.Collection.Name(), err)
+	}
+	return nil
+}
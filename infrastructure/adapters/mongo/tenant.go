@@ -0,0 +1,37 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Families belong to the tenant of the context they are saved with, whose ID the tenantId
+// field holds. Every query of the repository is scoped to the tenant of its context, so
+// that a tenant never reads, changes, or counts the families of another tenant; a context
+// without a tenant is scoped to the families saved without one, such as the documents
+// saved before families had tenants.
+
+// inTenant returns a copy of a filter that also leaves out the families of other tenants
+// than the tenant of the context. A filter on a missing field matches null, so documents
+// saved without a tenant match a context without one.
+func inTenant(ctx context.Context, filter bson.M) bson.M {
+	out := make(bson.M, len(filter)+1)
+	for k, v := range filter {
+		out[k] = v
+	}
+	if tenant := tenantOf(ctx); tenant != "" {
+		out["tenantId"] = tenant
+	} else {
+		out["tenantId"] = bson.M{"$in": bson.A{"", nil}}
+	}
+	return out
+}
+
+// tenantOf returns the ID of the tenant of a context, or "" if it has none
+func tenantOf(ctx context.Context) string {
+	return servicecontext.GetTenantID(ctx)
+}
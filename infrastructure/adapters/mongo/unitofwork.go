@@ -0,0 +1,154 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/servicelib/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// A unit of work is a transaction in a session of the client, which its context carries.
+// The operations that change families, their quarantines, or the outbox join the unit of
+// work of their context, and their changes are applied when the unit is committed. MongoDB
+// aborts the transaction when one of its operations fails, so a failed operation fails the
+// whole unit. The other operations read the committed families. Transactions need a replica
+// set or a sharded cluster; on a standalone server, Begin fails with ports.ErrNoUnitOfWork.
+
+// Ensure MongoFamilyRepository implements ports.UnitOfWork
+var _ ports.UnitOfWork = (*MongoFamilyRepository)(nil)
+
+// unitOfWorkKey is the context key of the unit of work of a context
+type unitOfWorkKey struct{}
+
+// unitOfWork is a unit of work of a repository
+type unitOfWork struct {
+	repo    *MongoFamilyRepository
+	session mongo.Session
+
+	mu    sync.Mutex
+	ended bool // Whether the transaction has been committed or aborted
+}
+
+// transactionSupport records whether the deployment of a repository supports transactions,
+// once it is known
+type transactionSupport struct {
+	mu        sync.Mutex
+	known     bool
+	supported bool
+}
+
+// unitOfWork returns the unit of work of the repository that the context carries, or nil
+func (r *MongoFamilyRepository) unitOfWork(ctx context.Context) *unitOfWork {
+	unit, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	if !ok || unit.repo != r {
+		return nil
+	}
+	return unit
+}
+
+// joinUnitOfWork returns the context of an operation that changes families: a context that
+// runs the operation in the transaction of the unit of work of the context, or the context
+func (r *MongoFamilyRepository) joinUnitOfWork(ctx context.Context) context.Context {
+	if unit := r.unitOfWork(ctx); unit != nil {
+		return mongo.NewSessionContext(ctx, unit.session)
+	}
+	return ctx
+}
+
+// supportsTransactions reports whether the deployment is a replica set or a sharded cluster,
+// which run transactions. The answer is kept once the deployment has been asked.
+func (r *MongoFamilyRepository) supportsTransactions(ctx context.Context) (bool, error) {
+	r.transactions.mu.Lock()
+	defer r.transactions.mu.Unlock()
+	if r.transactions.known {
+		return r.transactions.supported, nil
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	admin := r.Collection.Database().Client().Database("admin")
+	if err := admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, errors.NewDatabaseError("failed to read the topology of MongoDB", "hello", "families", err)
+	}
+	r.transactions.known = true
+	r.transactions.supported = hello.SetName != "" || hello.Msg == "isdbgrid"
+	return r.transactions.supported, nil
+}
+
+// Begin begins a unit of work and returns the context of its operations. It fails with
+// ports.ErrNoUnitOfWork if the deployment cannot run transactions.
+func (r *MongoFamilyRepository) Begin(ctx context.Context) (context.Context, error) {
+	if r.unitOfWork(ctx) != nil {
+		return nil, errors.NewDatabaseError("a unit of work is already in progress", "begin", "families", nil)
+	}
+
+	supported, err := r.supportsTransactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !supported {
+		return nil, ports.ErrNoUnitOfWork
+	}
+
+	session, err := r.Collection.Database().Client().StartSession()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin unit of work", zap.Error(err))
+		return nil, errors.NewDatabaseError("failed to begin unit of work", "begin", "families", err)
+	}
+	if err := session.StartTransaction(); err != nil {
+		session.EndSession(ctx)
+		r.logger.Error(ctx, "Failed to begin unit of work", zap.Error(err))
+		return nil, errors.NewDatabaseError("failed to begin unit of work", "begin", "families", err)
+	}
+	return context.WithValue(ctx, unitOfWorkKey{}, &unitOfWork{repo: r, session: session}), nil
+}
+
+// Commit applies the changes of the unit of work of the context
+func (r *MongoFamilyRepository) Commit(ctx context.Context) error {
+	unit := r.unitOfWork(ctx)
+	if unit == nil {
+		return errors.NewDatabaseError("no unit of work is in progress", "commit", "families", nil)
+	}
+	unit.mu.Lock()
+	defer unit.mu.Unlock()
+	if unit.ended {
+		return errors.NewDatabaseError("no unit of work is in progress", "commit", "families", nil)
+	}
+
+	// A unit of work whose commit fails ends too; ending the session aborts its transaction
+	unit.ended = true
+	defer unit.session.EndSession(ctx)
+	if err := unit.session.CommitTransaction(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to commit unit of work", zap.Error(err))
+		return errors.NewDatabaseError("failed to commit unit of work", "commit", "families", err)
+	}
+	return nil
+}
+
+// Rollback discards the changes of the unit of work of the context
+func (r *MongoFamilyRepository) Rollback(ctx context.Context) error {
+	unit := r.unitOfWork(ctx)
+	if unit == nil {
+		return nil
+	}
+	unit.mu.Lock()
+	defer unit.mu.Unlock()
+	if unit.ended {
+		return nil
+	}
+
+	unit.ended = true
+	defer unit.session.EndSession(ctx)
+	if err := unit.session.AbortTransaction(ctx); err != nil {
+		return errors.NewDatabaseError("failed to roll back unit of work", "rollback", "families", err)
+	}
+	return nil
+}
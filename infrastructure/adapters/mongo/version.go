@@ -0,0 +1,48 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package mongo
+
+import (
+	"fmt"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// The version field of a family document counts the saves of the family. Save replaces a
+// document only if it still has the version of the family it was given, and rejects the
+// save with a ConcurrencyError otherwise, so that concurrent changes of a family cannot
+// overwrite each other. A family with version 0 is inserted, and rejected if its ID is
+// taken, unless the save is marked with ports.WithOverwrite. Documents saved before
+// families were versioned have no version field and count as version 1.
+
+// version returns the version of the family of the document
+func (d FamilyDocument) version() int {
+	if d.Version == 0 {
+		return 1
+	}
+	return d.Version
+}
+
+// versionFilter returns the filter of the document of a family with the given version
+func versionFilter(familyID string, version int) bson.M {
+	if version == 1 {
+		// A null condition also matches the documents without a version field
+		return bson.M{"family_id": familyID, "version": bson.M{"$in": bson.A{1, nil}}}
+	}
+	return bson.M{"family_id": familyID, "version": version}
+}
+
+// staleVersionError returns the error of a save of a family that was changed by another
+// operation after it was read
+func staleVersionError(fam *entity.Family) error {
+	return domainerrors.NewConcurrencyError(fmt.Sprintf(
+		"family %s was changed by another operation after version %d was read", fam.ID(), fam.Version()), nil)
+}
+
+// existingFamilyError returns the error of a save of a new family whose ID is taken by a
+// stored family
+func existingFamilyError(fam *entity.Family) error {
+	return domainerrors.NewConcurrencyError(fmt.Sprintf("family %s already exists", fam.ID()), nil)
+}
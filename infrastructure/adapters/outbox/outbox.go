@@ -0,0 +1,183 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package outbox delivers domain events to the message broker through the outbox of the
+// repository, so that events are not lost if the service stops between saving a change and
+// publishing its events.
+//
+// The Outbox is the event publisher with which the domain service stores events: it seals
+// each event in the envelope of the event schema registry, with a new deduplication key, and
+// adds it to the outbox of the repository, in the unit of work of the change that raised it
+// if there is one. The Relay reads the outbox in the order in which the events were stored,
+// publishes them to the broker, and removes them once the broker has accepted them. An event
+// whose removal fails, or that a replica relays at the same time as another, is published
+// again with the same key, so that delivery is at least once and consumers drop duplicates
+// by the key.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/eventschema"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// defaultBatchSize is the number of messages that the relay reads at a time if none is
+// configured
+const defaultBatchSize = 100
+
+// Outcomes of relayed messages, as counted by the relayed metric
+const (
+	OutcomeRelayed = "relayed"
+	OutcomeFailed  = "failed"
+)
+
+// relayedTotal counts the messages of the outbox relayed to the broker by event type and
+// outcome
+var relayedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "outbox_messages_relayed_total",
+		Help: "Total number of outbox messages relayed to the message broker, by event type and by whether they were published or failed",
+	},
+	[]string{"event", "outcome"},
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(relayedTotal)
+}
+
+// Outbox stores domain events in the outbox of a repository
+type Outbox struct {
+	repo     ports.OutboxRepository
+	registry *eventschema.Registry
+}
+
+var _ ports.EventPublisher = (*Outbox)(nil)
+
+// NewOutbox creates a new outbox
+//
+// Parameters:
+//   - repo: The repository whose outbox stores the events
+//   - registry: The registry of the event types, which seals events in envelopes
+//
+// Returns:
+//   - A new outbox
+func NewOutbox(repo ports.OutboxRepository, registry *eventschema.Registry) *Outbox {
+	return &Outbox{repo: repo, registry: registry}
+}
+
+// Publish stores the event in the outbox, in the unit of work of the context if there is
+// one, with a new deduplication key
+func (o *Outbox) Publish(ctx context.Context, event events.Event) error {
+	env, err := o.registry.Seal(event)
+	if err != nil {
+		return err
+	}
+	env.ID = uuid.NewString()
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox message of event %s: %w", env.Type, err)
+	}
+
+	return o.repo.AddToOutbox(ctx, ports.OutboxMessage{
+		ID:        env.ID,
+		Type:      env.Type,
+		Payload:   payload,
+		CreatedAt: clock.Now(ctx).UTC(),
+	})
+}
+
+// Broker is the message broker to which the relay publishes the events of the outbox, such
+// as the Kafka publisher
+type Broker interface {
+	// PublishEnvelope publishes a sealed event with its deduplication key
+	PublishEnvelope(ctx context.Context, env eventschema.Envelope) error
+}
+
+// Relay publishes the messages of an outbox to a message broker
+type Relay struct {
+	repo      ports.OutboxRepository
+	broker    Broker
+	batchSize int
+	logger    *zap.Logger
+}
+
+// NewRelay creates a new relay
+//
+// Parameters:
+//   - repo: The repository whose outbox is relayed
+//   - broker: The message broker to which the messages are published
+//   - batchSize: The number of messages read at a time (0 for the default)
+//   - logger: Logger for relayed and failed messages
+//
+// Returns:
+//   - A new relay
+func NewRelay(repo ports.OutboxRepository, broker Broker, batchSize int, logger *zap.Logger) *Relay {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Relay{repo: repo, broker: broker, batchSize: batchSize, logger: logger}
+}
+
+// Run publishes the messages of the outbox in the order in which they were stored, one
+// batch at a time, until the outbox is empty. It stops at the first message that cannot be
+// published, so that the events of a family are never published out of order, and the
+// message is published again by the next run. Run is the job of a periodic worker.
+//
+// Returns:
+//   - An error if the outbox cannot be read or a message cannot be published or removed
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		messages, err := r.repo.PendingOutboxMessages(ctx, r.batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read the outbox: %w", err)
+		}
+
+		relayed := make([]string, 0, len(messages))
+		publishErr := r.publish(ctx, messages, &relayed)
+		if len(relayed) > 0 {
+			if err := r.repo.RemoveFromOutbox(ctx, relayed); err != nil {
+				return fmt.Errorf("failed to remove %d relayed messages from the outbox: %w", len(relayed), err)
+			}
+			r.logger.Debug("Relayed outbox messages", zap.Int("count", len(relayed)))
+		}
+		if publishErr != nil || len(messages) < r.batchSize {
+			return publishErr
+		}
+	}
+}
+
+// publish publishes messages in order and appends the IDs of the published messages to
+// relayed, until a message cannot be published
+func (r *Relay) publish(ctx context.Context, messages []ports.OutboxMessage, relayed *[]string) error {
+	for _, message := range messages {
+		var env eventschema.Envelope
+		err := json.Unmarshal(message.Payload, &env)
+		if err == nil {
+			// The message ID is the deduplication key, whatever the stored envelope says
+			env.ID = message.ID
+			err = r.broker.PublishEnvelope(ctx, env)
+		}
+		if err != nil {
+			relayedTotal.WithLabelValues(message.Type, OutcomeFailed).Inc()
+			r.logger.Error("Failed to relay outbox message",
+				zap.Error(err),
+				zap.String("message_id", message.ID),
+				zap.String("event", message.Type))
+			return fmt.Errorf("failed to relay outbox message %s of event %s: %w", message.ID, message.Type, err)
+		}
+		relayedTotal.WithLabelValues(message.Type, OutcomeRelayed).Inc()
+		*relayed = append(*relayed, message.ID)
+	}
+	return nil
+}
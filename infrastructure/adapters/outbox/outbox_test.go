@@ -0,0 +1,140 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/events"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/eventschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOutbox is an outbox in memory
+type memoryOutbox struct {
+	messages  []ports.OutboxMessage
+	removeErr error
+}
+
+func (o *memoryOutbox) AddToOutbox(_ context.Context, messages ...ports.OutboxMessage) error {
+	o.messages = append(o.messages, messages...)
+	return nil
+}
+
+func (o *memoryOutbox) PendingOutboxMessages(_ context.Context, limit int) ([]ports.OutboxMessage, error) {
+	return o.messages[:min(limit, len(o.messages))], nil
+}
+
+func (o *memoryOutbox) RemoveFromOutbox(_ context.Context, ids []string) error {
+	if o.removeErr != nil {
+		return o.removeErr
+	}
+	removed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		removed[id] = true
+	}
+	kept := o.messages[:0]
+	for _, message := range o.messages {
+		if !removed[message.ID] {
+			kept = append(kept, message)
+		}
+	}
+	o.messages = kept
+	return nil
+}
+
+// recordingBroker records the published envelopes and fails the publications of the
+// events of a family
+type recordingBroker struct {
+	published []eventschema.Envelope
+	failing   string
+}
+
+func (b *recordingBroker) PublishEnvelope(_ context.Context, env eventschema.Envelope) error {
+	var deleted events.FamilyDeleted
+	if err := json.Unmarshal(env.Payload, &deleted); err != nil {
+		return err
+	}
+	if deleted.FamilyID == b.failing {
+		return errors.New("broker unavailable")
+	}
+	b.published = append(b.published, env)
+	return nil
+}
+
+// store stores the deletion events of the given families in an outbox
+func store(t *testing.T, repo *memoryOutbox, familyIDs ...string) {
+	o := NewOutbox(repo, eventschema.NewDefaultRegistry("family-service/1.0.0"))
+	for _, id := range familyIDs {
+		require.NoError(t, o.Publish(context.Background(), events.FamilyDeleted{FamilyID: id, At: time.Now()}))
+	}
+}
+
+func TestOutbox_Publish(t *testing.T) {
+	repo := &memoryOutbox{}
+	store(t, repo, "family-1", "family-2")
+
+	require.Len(t, repo.messages, 2)
+	assert.NotEmpty(t, repo.messages[0].ID)
+	assert.NotEqual(t, repo.messages[0].ID, repo.messages[1].ID, "every event has its own deduplication key")
+	assert.Equal(t, "family_deleted", repo.messages[0].Type)
+	assert.Contains(t, string(repo.messages[0].Payload), `"id":"`+repo.messages[0].ID+`"`)
+	assert.Contains(t, string(repo.messages[0].Payload), `"familyId":"family-1"`)
+}
+
+func TestRelay_Run(t *testing.T) {
+	t.Run("relays every message in order", func(t *testing.T) {
+		repo := &memoryOutbox{}
+		store(t, repo, "family-1", "family-2", "family-3")
+		ids := []string{repo.messages[0].ID, repo.messages[1].ID, repo.messages[2].ID}
+		broker := &recordingBroker{}
+
+		// Batches smaller than the outbox are read until it is empty
+		require.NoError(t, NewRelay(repo, broker, 2, nil).Run(context.Background()))
+
+		assert.Empty(t, repo.messages)
+		require.Len(t, broker.published, 3)
+		for i, env := range broker.published {
+			assert.Equal(t, ids[i], env.ID)
+			assert.Equal(t, "family_deleted", env.Type)
+		}
+	})
+
+	t.Run("stops at a message that cannot be published", func(t *testing.T) {
+		repo := &memoryOutbox{}
+		store(t, repo, "family-1", "family-2", "family-3")
+		broker := &recordingBroker{failing: "family-2"}
+
+		err := NewRelay(repo, broker, 0, nil).Run(context.Background())
+
+		require.ErrorContains(t, err, "broker unavailable")
+		assert.Len(t, broker.published, 1)
+		require.Len(t, repo.messages, 2, "the failed message and those after it stay in the outbox")
+
+		// The next run publishes them once the broker is available again
+		broker.failing = ""
+		require.NoError(t, NewRelay(repo, broker, 0, nil).Run(context.Background()))
+		assert.Len(t, broker.published, 3)
+		assert.Empty(t, repo.messages)
+	})
+
+	t.Run("publishes again the messages that cannot be removed", func(t *testing.T) {
+		repo := &memoryOutbox{removeErr: errors.New("connection lost")}
+		store(t, repo, "family-1")
+		broker := &recordingBroker{}
+
+		require.ErrorContains(t, NewRelay(repo, broker, 0, nil).Run(context.Background()), "connection lost")
+		repo.removeErr = nil
+		require.NoError(t, NewRelay(repo, broker, 0, nil).Run(context.Background()))
+
+		require.Len(t, broker.published, 2)
+		assert.Equal(t, broker.published[0].ID, broker.published[1].ID, "duplicates carry the same deduplication key")
+		assert.Empty(t, repo.messages)
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/abitofhelp/family-service/core/domain/workload"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// AnalyticsApplicationName is the application_name of analytics sessions, so that their
+// statements can be told apart from interactive traffic in pg_stat_activity
+const AnalyticsApplicationName = "family-service-analytics"
+
+// analyticsPoolConfig returns the configuration of the analytics pool. Its sessions are
+// read-only, and their statements are bounded by the configured timeout and work_mem.
+func analyticsPoolConfig(cfg config.PostgresConfig) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PostgreSQL DSN: %w", err)
+	}
+
+	analytics := cfg.Analytics
+	if analytics.MaxConns > 0 {
+		poolConfig.MaxConns = int32(analytics.MaxConns)
+	}
+	poolConfig.MinConns = 0
+
+	params := poolConfig.ConnConfig.RuntimeParams
+	params["application_name"] = AnalyticsApplicationName
+	params["default_transaction_read_only"] = "on"
+	params["statement_timeout"] = strconv.FormatInt(analytics.StatementTimeout.Milliseconds(), 10)
+	if analytics.WorkMem != "" {
+		params["work_mem"] = analytics.WorkMem
+	}
+
+	// Analytics reads see the same tenant rows as the primary pool
+	if cfg.RowLevelSecurity {
+		poolConfig.BeforeAcquire = setTenantForConnection
+	}
+	return poolConfig, nil
+}
+
+// NewAnalyticsPool creates the connection pool of analytics work, which shares the
+// database of the primary pool but has its own, smaller set of connections.
+//
+// Parameters:
+//   - ctx: Context for establishing and pinging the pool
+//   - cfg: PostgreSQL configuration with the analytics settings
+//   - logger: Logger for connection events
+//
+// Returns:
+//   - The analytics pool if successful
+//   - An error if the pool cannot be created or reached
+func NewAnalyticsPool(ctx context.Context, cfg config.PostgresConfig, logger *zap.Logger) (*pgxpool.Pool, error) {
+	poolConfig, err := analyticsPoolConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize PostgreSQL analytics pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping PostgreSQL analytics pool: %w", err)
+	}
+
+	if logger != nil {
+		logger.Info("Connected PostgreSQL analytics pool",
+			zap.Int32("max_conns", poolConfig.MaxConns),
+			zap.Duration("statement_timeout", cfg.Analytics.StatementTimeout),
+			zap.String("work_mem", cfg.Analytics.WorkMem))
+	}
+	return pool, nil
+}
+
+// SetAnalyticsPool sets the pool that reads of analytics work use instead of the primary pool
+func (r *PostgresFamilyRepository) SetAnalyticsPool(pool *pgxpool.Pool) {
+	r.analyticsDB = pool
+}
+
+// reader returns the pool that reads in a context use: the analytics pool for analytics
+// work when one is set, and the primary pool otherwise
+func (r *PostgresFamilyRepository) reader(ctx context.Context) *pgxpool.Pool {
+	if r.analyticsDB != nil && workload.IsAnalytics(ctx) {
+		return r.analyticsDB
+	}
+	return r.DB
+}
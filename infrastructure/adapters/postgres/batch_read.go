@@ -0,0 +1,29 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"go.uber.org/zap"
+)
+
+// Ensure PostgresFamilyRepository implements ports.FamilyBatchReader
+var _ ports.FamilyBatchReader = (*PostgresFamilyRepository)(nil)
+
+// GetByIDs reads the families with the given IDs, with their versions, in ascending ID
+// order with one query
+func (r *PostgresFamilyRepository) GetByIDs(ctx context.Context, ids []string) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Getting families by IDs from PostgreSQL", zap.Int("count", len(ids)))
+
+	if len(ids) == 0 {
+		return []*entity.Family{}, nil
+	}
+	return r.queryFamilies(ctx, "GetByIDs", "failed to get families by IDs", `
+            SELECT id, status, parents, children, external_ids, owner_id, version FROM families
+            WHERE id = ANY($1) AND tenant_id = $2 AND deleted_at IS NULL
+            ORDER BY id
+        `, ids, tenantOf(ctx))
+}
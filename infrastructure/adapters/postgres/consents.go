@@ -0,0 +1,81 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+)
+
+// Consents are stored in the consents key of the children JSON. It is omitted for
+// children without consents, so rows written before consents were introduced are
+// read unchanged.
+
+// jsonConsent represents how a consent granted for a child is stored in the JSON
+type jsonConsent struct {
+	GrantedBy string   `json:"grantedBy"`
+	GrantedAt string   `json:"grantedAt"`
+	Scopes    []string `json:"scopes"`
+	ExpiredAt *string  `json:"expiredAt,omitempty"`
+}
+
+// consentsToJSON converts a child's consents to their JSON form
+func consentsToJSON(consents []entity.Consent) []jsonConsent {
+	if len(consents) == 0 {
+		return nil
+	}
+	entries := make([]jsonConsent, 0, len(consents))
+	for _, consent := range consents {
+		scopes := make([]string, 0, len(consent.Scopes))
+		for _, scope := range consent.Scopes {
+			scopes = append(scopes, string(scope))
+		}
+		var expiredAt *string
+		if consent.ExpiredAt != nil {
+			str := consent.ExpiredAt.Format(time.RFC3339)
+			expiredAt = &str
+		}
+		entries = append(entries, jsonConsent{
+			GrantedBy: consent.GrantedBy,
+			GrantedAt: consent.GrantedAt.Format(time.RFC3339),
+			Scopes:    scopes,
+			ExpiredAt: expiredAt,
+		})
+	}
+	return entries
+}
+
+// setChildConsents restores the consents of a child from the JSON
+func setChildConsents(child *entity.Child, entries []jsonConsent) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	consents := make([]entity.Consent, 0, len(entries))
+	for _, entry := range entries {
+		grantedAt, err := time.Parse(time.RFC3339, entry.GrantedAt)
+		if err != nil {
+			return NewRepositoryError(err, "invalid consent grant time format", "DATA_FORMAT_ERROR")
+		}
+		var expiredAt *time.Time
+		if entry.ExpiredAt != nil {
+			parsed, err := time.Parse(time.RFC3339, *entry.ExpiredAt)
+			if err != nil {
+				return NewRepositoryError(err, "invalid consent expiry time format", "DATA_FORMAT_ERROR")
+			}
+			expiredAt = &parsed
+		}
+		scopes := make([]entity.ConsentScope, 0, len(entry.Scopes))
+		for _, scope := range entry.Scopes {
+			scopes = append(scopes, entity.ConsentScope(scope))
+		}
+		consents = append(consents, entity.Consent{
+			GrantedBy: entry.GrantedBy,
+			GrantedAt: grantedAt,
+			Scopes:    scopes,
+			ExpiredAt: expiredAt,
+		})
+	}
+	return child.SetConsents(consents)
+}
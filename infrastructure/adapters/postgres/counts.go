@@ -0,0 +1,60 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import "context"
+
+// Queries of the counts of the families and members of a tenant. A member of several
+// families, such as a remarried parent, is counted once.
+const (
+	countFamiliesQuery = "SELECT COUNT(*) FROM families WHERE tenant_id = $1 AND deleted_at IS NULL"
+
+	countParentsQuery = `
+            SELECT COUNT(DISTINCT COALESCE(member->>'id', member->>'ID'))
+            FROM families, jsonb_array_elements(parents) AS member
+            WHERE tenant_id = $1 AND deleted_at IS NULL
+        `
+
+	countChildrenQuery = `
+            SELECT COUNT(DISTINCT COALESCE(member->>'id', member->>'ID'))
+            FROM families, jsonb_array_elements(children) AS member
+            WHERE tenant_id = $1 AND deleted_at IS NULL
+        `
+)
+
+// CountFamilies counts the families that are not deleted
+func (r *PostgresFamilyRepository) CountFamilies(ctx context.Context) (int, error) {
+	r.logger.Debug(ctx, "Counting families in PostgreSQL")
+	return r.count(ctx, "CountFamilies", "failed to count families", countFamiliesQuery)
+}
+
+// CountParents counts the distinct parents of the families that are not deleted
+func (r *PostgresFamilyRepository) CountParents(ctx context.Context) (int, error) {
+	r.logger.Debug(ctx, "Counting parents in PostgreSQL")
+	return r.count(ctx, "CountParents", "failed to count parents", countParentsQuery)
+}
+
+// CountChildren counts the distinct children of the families that are not deleted
+func (r *PostgresFamilyRepository) CountChildren(ctx context.Context) (int, error) {
+	r.logger.Debug(ctx, "Counting children in PostgreSQL")
+	return r.count(ctx, "CountChildren", "failed to count children", countChildrenQuery)
+}
+
+// count runs a query that returns a single count, scoped to the tenant of the context
+func (r *PostgresFamilyRepository) count(ctx context.Context, operation, failure, query string) (int, error) {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err := r.protect(ctx, operation, func(ctx context.Context) error {
+		if err := r.reader(ctx).QueryRow(ctx, query, tenantOf(ctx)).Scan(&count); err != nil {
+			return NewRepositoryError(err, failure, "POSTGRES_ERROR")
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+)
+
+// Outbox messages set aside by operators are kept in the event_outbox_dead_letters table, in
+// the order of seq, until they are replayed or purged. The table is created with the outbox.
+// Each move between the tables is a single statement, so that a message is never in both
+// or in neither.
+const createOutboxDeadLettersSchema = `
+	CREATE TABLE IF NOT EXISTS event_outbox_dead_letters (
+		seq BIGSERIAL PRIMARY KEY,
+		id VARCHAR(36) NOT NULL UNIQUE,
+		type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		reason TEXT NOT NULL,
+		dead_lettered_at TIMESTAMP WITH TIME ZONE NOT NULL
+	);
+	`
+
+// Ensure PostgresFamilyRepository implements ports.OutboxAdministrator
+var _ ports.OutboxAdministrator = (*PostgresFamilyRepository)(nil)
+
+// DeadLetterOutboxMessages moves the messages with the given IDs from the outbox to the dead
+// letters and returns the number of messages moved
+func (r *PostgresFamilyRepository) DeadLetterOutboxMessages(ctx context.Context, ids []string, reason string, at time.Time) (int, error) {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return 0, err
+	}
+
+	tag, err := r.DB.Exec(ctx, `
+		WITH moved AS (
+			DELETE FROM event_outbox WHERE id = ANY($1) RETURNING seq, id, type, payload, created_at
+		)
+		INSERT INTO event_outbox_dead_letters (id, type, payload, created_at, reason, dead_lettered_at)
+		SELECT id, type, payload, created_at, $2, $3 FROM moved ORDER BY seq
+	`, ids, reason, at.UTC())
+	if err != nil {
+		return 0, NewRepositoryError(err, "failed to move messages to dead letters", "POSTGRES_ERROR")
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// DeadLetters returns at most limit dead letters of the given type, or of every type if it is
+// empty, in the order in which they were set aside
+func (r *PostgresFamilyRepository) DeadLetters(ctx context.Context, eventType string, limit int) ([]ports.DeadLetter, error) {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, type, payload, created_at, reason, dead_lettered_at
+		FROM event_outbox_dead_letters WHERE ($2 = '' OR type = $2) ORDER BY seq LIMIT $1
+	`, limit, eventType)
+	if err != nil {
+		return nil, NewRepositoryError(err, "failed to read dead letters", "POSTGRES_ERROR")
+	}
+	defer rows.Close()
+
+	letters := []ports.DeadLetter{}
+	for rows.Next() {
+		var letter ports.DeadLetter
+		var payload string
+		if err := rows.Scan(&letter.ID, &letter.Type, &payload, &letter.CreatedAt, &letter.Reason, &letter.DeadLetteredAt); err != nil {
+			return nil, NewRepositoryError(err, "failed to scan dead letter", "POSTGRES_ERROR")
+		}
+		letter.Payload = []byte(payload)
+		letter.CreatedAt = letter.CreatedAt.UTC()
+		letter.DeadLetteredAt = letter.DeadLetteredAt.UTC()
+		letters = append(letters, letter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, NewRepositoryError(err, "error iterating over dead letters", "POSTGRES_ERROR")
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetters moves the dead letters with the given IDs back to the end of the outbox,
+// in the order in which they were set aside, and returns the number of messages moved
+func (r *PostgresFamilyRepository) ReplayDeadLetters(ctx context.Context, ids []string) (int, error) {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return 0, err
+	}
+
+	tag, err := r.DB.Exec(ctx, `
+		WITH moved AS (
+			DELETE FROM event_outbox_dead_letters WHERE id = ANY($1) RETURNING seq, id, type, payload, created_at
+		)
+		INSERT INTO event_outbox (id, type, payload, created_at)
+		SELECT id, type, payload, created_at FROM moved ORDER BY seq
+	`, ids)
+	if err != nil {
+		return 0, NewRepositoryError(err, "failed to replay dead letters", "POSTGRES_ERROR")
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// PurgeDeadLetters removes the dead letters with the given IDs for good and returns the
+// number of messages removed
+func (r *PostgresFamilyRepository) PurgeDeadLetters(ctx context.Context, ids []string) (int, error) {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return 0, err
+	}
+
+	tag, err := r.DB.Exec(ctx, "DELETE FROM event_outbox_dead_letters WHERE id = ANY($1)", ids)
+	if err != nil {
+		return 0, NewRepositoryError(err, "failed to purge dead letters", "POSTGRES_ERROR")
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// Deleted families keep their row, with the time of their deletion in the deleted_at
+// column. Every read of families skips the rows whose deleted_at is set, and Save clears
+// it, so a family saved again with the ID of a deleted family replaces it.
+
+// Ensure PostgresFamilyRepository implements ports.FamilyDeleter
+var _ ports.FamilyDeleter = (*PostgresFamilyRepository)(nil)
+
+// SoftDeleteFamily marks a family as deleted and reports whether it was found and not
+// already deleted
+func (r *PostgresFamilyRepository) SoftDeleteFamily(ctx context.Context, familyID string, deletedAt time.Time) (bool, error) {
+	r.logger.Debug(ctx, "Soft deleting family in PostgreSQL", zap.String("family_id", familyID))
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return false, err
+	}
+
+	var tag pgconn.CommandTag
+	err := r.protect(ctx, "SoftDeleteFamily", func(ctx context.Context) error {
+		var err error
+		tag, err = r.execer(ctx).Exec(ctx, "UPDATE families SET deleted_at = $2 WHERE id = $1 AND tenant_id = $3 AND deleted_at IS NULL", familyID, deletedAt.UTC(), tenantOf(ctx))
+		if err != nil {
+			return NewRepositoryError(err, "failed to soft delete family", "POSTGRES_ERROR")
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// HardDeleteFamily removes a family and reports whether it was found
+func (r *PostgresFamilyRepository) HardDeleteFamily(ctx context.Context, familyID string) (bool, error) {
+	r.logger.Debug(ctx, "Hard deleting family in PostgreSQL", zap.String("family_id", familyID))
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return false, err
+	}
+
+	var tag pgconn.CommandTag
+	err := r.protect(ctx, "HardDeleteFamily", func(ctx context.Context) error {
+		var err error
+		tag, err = r.execer(ctx).Exec(ctx, "DELETE FROM families WHERE id = $1 AND tenant_id = $2", familyID, tenantOf(ctx))
+		if err != nil {
+			return NewRepositoryError(err, "failed to hard delete family", "POSTGRES_ERROR")
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Ensure PostgresFamilyRepository implements ports.FamilyRestorer
+var _ ports.FamilyRestorer = (*PostgresFamilyRepository)(nil)
+
+// RestoreFamily clears the deletion mark of a family and reports whether it was found and
+// deleted
+func (r *PostgresFamilyRepository) RestoreFamily(ctx context.Context, familyID string) (bool, error) {
+	r.logger.Debug(ctx, "Restoring family in PostgreSQL", zap.String("family_id", familyID))
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return false, err
+	}
+
+	var tag pgconn.CommandTag
+	err := r.protect(ctx, "RestoreFamily", func(ctx context.Context) error {
+		var err error
+		tag, err = r.execer(ctx).Exec(ctx, "UPDATE families SET deleted_at = NULL WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL", familyID, tenantOf(ctx))
+		if err != nil {
+			return NewRepositoryError(err, "failed to restore family", "POSTGRES_ERROR")
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// PurgeDeletedFamilies removes the families deleted before a time and returns their IDs.
+//
+// Under row-level security, the policy shows a connection only the families of the tenant
+// of its context, so a purge of every tenant switches its transaction to the maintenance
+// role, which bypasses row-level security. Without a maintenance role it fails instead of
+// purging nothing.
+func (r *PostgresFamilyRepository) PurgeDeletedFamilies(ctx context.Context, deletedBefore time.Time, allTenants bool) ([]string, error) {
+	r.logger.Debug(ctx, "Purging deleted families in PostgreSQL", zap.Time("deleted_before", deletedBefore), zap.Bool("all_tenants", allTenants))
+
+	bypass := allTenants && rowLevelSecurityEnabled()
+	if bypass && maintenanceRole() == "" {
+		return nil, NewRepositoryError(ErrNoMaintenanceRole, "failed to purge deleted families", "POSTGRES_ERROR")
+	}
+	if err := r.ensureTableExists(ctx); err != nil {
+		return nil, err
+	}
+
+	query := "DELETE FROM families WHERE deleted_at < $1"
+	args := []interface{}{deletedBefore.UTC()}
+	if !allTenants {
+		query += " AND tenant_id = $2"
+		args = append(args, tenantOf(ctx))
+	}
+
+	var ids []string
+	err := r.protect(ctx, "PurgeDeletedFamilies", func(ctx context.Context) error {
+		tx, err := r.begin(ctx)
+		if err != nil {
+			return NewRepositoryError(err, "failed to begin transaction", "POSTGRES_ERROR")
+		}
+		defer tx.Rollback(ctx)
+
+		// The role is reset when the transaction ends
+		if bypass {
+			if _, err := tx.Exec(ctx, "SET LOCAL ROLE "+pgx.Identifier{maintenanceRole()}.Sanitize()); err != nil {
+				return NewRepositoryError(err, "failed to switch to the maintenance role", "POSTGRES_ERROR")
+			}
+		}
+
+		rows, err := tx.Query(ctx, query+" RETURNING id", args...)
+		if err != nil {
+			return NewRepositoryError(err, "failed to purge deleted families", "POSTGRES_ERROR")
+		}
+		ids, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return NewRepositoryError(err, "failed to purge deleted families", "POSTGRES_ERROR")
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return NewRepositoryError(err, "failed to commit transaction", "POSTGRES_ERROR")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/servicelib/errors"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// External IDs of families are stored in the external_ids column, and external IDs of
+// parents and children in the externalIds field of each member in the parents and
+// children columns. All three columns have GIN indexes, so uniqueness checks and
+// lookups use JSONB containment queries. External IDs are unique within a tenant, and the
+// queries are scoped to the tenant of their context like every other query.
+
+// familyExternalIDQuery finds another family holding an external ID
+const familyExternalIDQuery = `
+	SELECT id FROM families
+	WHERE external_ids @> $1::jsonb AND id <> $2 AND tenant_id = $3
+	LIMIT 1
+`
+
+// memberExternalIDQuery finds another member of the given column (parents or children)
+// holding an external ID. Legacy rows may use upper-case member keys.
+const memberExternalIDQuery = `
+	SELECT COALESCE(member->>'id', member->>'ID') FROM families, jsonb_array_elements(%[1]s) AS member
+	WHERE %[1]s @> $1::jsonb
+	AND member->'externalIds'->>$2 = $3
+	AND COALESCE(member->>'id', member->>'ID') <> $4
+	AND tenant_id = $5
+	LIMIT 1
+`
+
+// encodeFamilyExternalIDs encodes the family's external IDs for the external_ids column
+func encodeFamilyExternalIDs(fam *entity.Family) ([]byte, error) {
+	ids := fam.ExternalIDs()
+	if ids == nil {
+		ids = map[string]string{}
+	}
+	return json.Marshal(ids)
+}
+
+// newFamilyWithExternalIDs creates a family entity and sets the external IDs decoded from
+// the external_ids column
+func newFamilyWithExternalIDs(famID, status string, parents []*entity.Parent, children []*entity.Child, externalIDsData []byte) (*entity.Family, error) {
+	fam, err := entity.NewFamily(famID, entity.Status(status), parents, children)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(externalIDsData) > 0 {
+		var ids map[string]string
+		if err := json.Unmarshal(externalIDsData, &ids); err != nil {
+			return nil, NewRepositoryError(err, "failed to unmarshal external IDs data", "JSON_ERROR")
+		}
+		if err := fam.SetExternalIDs(ids); err != nil {
+			return nil, NewRepositoryError(err, "invalid family external IDs", "CONVERSION_ERROR")
+		}
+	}
+
+	return fam, nil
+}
+
+// checkExternalIDsUnique verifies that none of the family's external IDs is held by another
+// entity of the same kind. A parent that belongs to several families holds its external IDs
+// in each of them, so only holders with a different entity ID are conflicts.
+func (r *PostgresFamilyRepository) checkExternalIDsUnique(ctx context.Context, tx pgx.Tx, fam *entity.Family) error {
+	for _, ref := range fam.ExternalIDRefs() {
+		var query string
+		var args []interface{}
+
+		switch ref.Owner {
+		case entity.ExternalIDOwnerFamily:
+			containment, _ := json.Marshal(map[string]string{ref.System: ref.ExternalID})
+			query = familyExternalIDQuery
+			args = []interface{}{containment, ref.EntityID, tenantOf(ctx)}
+		case entity.ExternalIDOwnerParent, entity.ExternalIDOwnerChild:
+			column := "parents"
+			if ref.Owner == entity.ExternalIDOwnerChild {
+				column = "children"
+			}
+			containment, _ := json.Marshal([]map[string]interface{}{{"externalIds": map[string]string{ref.System: ref.ExternalID}}})
+			query = fmt.Sprintf(memberExternalIDQuery, column)
+			args = []interface{}{containment, ref.System, ref.ExternalID, ref.EntityID, tenantOf(ctx)}
+		}
+
+		var holderID string
+		err := tx.QueryRow(ctx, query, args...).Scan(&holderID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return NewRepositoryError(err, "failed to check external ID uniqueness", "POSTGRES_ERROR")
+		}
+
+		r.logger.Warn(ctx, "External ID is already assigned",
+			zap.String("family_id", fam.ID()),
+			zap.String("system", ref.System),
+			zap.String("external_id", ref.ExternalID),
+			zap.String("assigned_to", holderID))
+		return errors.NewValidationError(
+			fmt.Sprintf("external ID %s:%s is already assigned to %s %s", ref.System, ref.ExternalID, strings.ToLower(string(ref.Owner)), holderID),
+			"ExternalIDs", nil)
+	}
+	return nil
+}
+
+// FindByExternalID finds the families in which the family itself or one of its members
+// has the given external ID in the given external system
+func (r *PostgresFamilyRepository) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Finding families by external ID in PostgreSQL",
+		zap.String("system", system),
+		zap.String("external_id", externalID))
+
+	if system == "" {
+		return nil, errors.NewValidationError("system is required", "system", nil)
+	}
+	if externalID == "" {
+		return nil, errors.NewValidationError("external ID is required", "externalID", nil)
+	}
+
+	// Ensure table exists
+	if err := r.ensureTableExists(ctx); err != nil {
+		return nil, err
+	}
+
+	familyContainment, _ := json.Marshal(map[string]string{system: externalID})
+	memberContainment, _ := json.Marshal([]map[string]interface{}{{"externalIds": map[string]string{system: externalID}}})
+
+	var rows pgx.Rows
+	err := r.protect(ctx, "FindByExternalID", func(ctx context.Context) error {
+		var err error
+		rows, err = r.DB.Query(ctx, `
+            SELECT id FROM families
+            WHERE tenant_id = $3 AND deleted_at IS NULL
+            AND (external_ids @> $1::jsonb OR parents @> $2::jsonb OR children @> $2::jsonb)
+            ORDER BY id
+        `, familyContainment, memberContainment, tenantOf(ctx))
+		if err != nil {
+			return NewRepositoryError(err, "failed to find families by external ID", "POSTGRES_ERROR")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var familyIDs []string
+	for rows.Next() {
+		var familyID string
+		if err := rows.Scan(&familyID); err != nil {
+			rows.Close()
+			return nil, NewRepositoryError(err, "failed to scan family row", "POSTGRES_ERROR")
+		}
+		familyIDs = append(familyIDs, familyID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, NewRepositoryError(err, "error iterating over family rows", "POSTGRES_ERROR")
+	}
+
+	families := make([]*entity.Family, 0, len(familyIDs))
+	for _, familyID := range familyIDs {
+		fam, err := r.GetByID(ctx, familyID)
+		if err != nil {
+			return nil, err
+		}
+		families = append(families, fam)
+	}
+
+	return families, nil
+}
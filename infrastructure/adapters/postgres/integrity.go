@@ -0,0 +1,172 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/integrity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/repair"
+	"go.uber.org/zap"
+)
+
+// Quarantined rows keep their stored data unchanged, with the reason and time they were
+// quarantined, so that they can be reviewed and restored by hand
+const createQuarantineSchema = `
+	CREATE TABLE IF NOT EXISTS families_quarantine (
+		id VARCHAR(36) PRIMARY KEY,
+		status VARCHAR(20) NOT NULL,
+		parents JSONB NOT NULL,
+		children JSONB NOT NULL,
+		external_ids JSONB NOT NULL,
+		reason TEXT NOT NULL,
+		quarantined_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+// Ensure PostgresFamilyRepository implements integrity.Scanner
+var _ integrity.Scanner = (*PostgresFamilyRepository)(nil)
+
+// ScanFamilies reads every stored family in ascending ID order for an integrity check
+func (r *PostgresFamilyRepository) ScanFamilies(ctx context.Context, fn func(integrity.Record) error) error {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return err
+	}
+
+	rows, err := r.reader(ctx).Query(ctx, "SELECT id, status, parents, children, external_ids FROM families ORDER BY id")
+	if err != nil {
+		return NewRepositoryError(err, "failed to query families", "POSTGRES_ERROR")
+	}
+	defer rows.Close()
+
+	// Repair legacy data in memory, so that only data that cannot be repaired is reported
+	repairing := *r
+	repairing.readRepair.Enabled = true
+
+	for rows.Next() {
+		var famID, statusStr string
+		var parentsData, childrenData, externalIDsData []byte
+		if err := rows.Scan(&famID, &statusStr, &parentsData, &childrenData, &externalIDsData); err != nil {
+			return NewRepositoryError(err, "failed to scan family row", "POSTGRES_ERROR")
+		}
+
+		repairs := repairing.repairRow(ctx, famID, &statusStr, &parentsData, &childrenData)
+		rec := decodeRecord(famID, statusStr, parentsData, childrenData, externalIDsData)
+		rec.Repairs = repairs
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return NewRepositoryError(err, "error iterating over family rows", "POSTGRES_ERROR")
+	}
+	return nil
+}
+
+// decodeRecord decodes the columns of a family row into an integrity record. Member keys
+// are normalized first, so that legacy rows that reads accept are not reported.
+func decodeRecord(famID, statusStr string, parentsData, childrenData, externalIDsData []byte) integrity.Record {
+	rec := integrity.Record{FamilyID: famID}
+
+	var parentDTOs []entity.ParentDTO
+	if err := decodeMembers(parentsData, "parents", &parentDTOs); err != nil {
+		rec.DecodeErr = fmt.Errorf("failed to decode parents: %w", err)
+		return rec
+	}
+	var childDTOs []entity.ChildDTO
+	if err := decodeMembers(childrenData, "children", &childDTOs); err != nil {
+		rec.DecodeErr = fmt.Errorf("failed to decode children: %w", err)
+		return rec
+	}
+
+	parents := make([]*entity.Parent, 0, len(parentDTOs))
+	for _, dto := range parentDTOs {
+		p, err := entity.ParentFromDTO(dto)
+		if err != nil {
+			rec.InvariantErr = fmt.Errorf("invalid parent %s: %w", dto.ID, err)
+			return rec
+		}
+		parents = append(parents, p)
+	}
+	children := make([]*entity.Child, 0, len(childDTOs))
+	for _, dto := range childDTOs {
+		c, err := entity.ChildFromDTO(dto)
+		if err != nil {
+			rec.InvariantErr = fmt.Errorf("invalid child %s: %w", dto.ID, err)
+			return rec
+		}
+		children = append(children, c)
+	}
+
+	fam, err := entity.NewFamily(famID, entity.Status(statusStr), parents, children)
+	if err != nil {
+		rec.InvariantErr = fmt.Errorf("invalid family: %w", err)
+		return rec
+	}
+	if len(externalIDsData) > 0 {
+		var ids map[string]string
+		if err := json.Unmarshal(externalIDsData, &ids); err != nil {
+			rec.DecodeErr = fmt.Errorf("failed to decode external IDs: %w", err)
+			return rec
+		}
+		if err := fam.SetExternalIDs(ids); err != nil {
+			rec.InvariantErr = fmt.Errorf("invalid external IDs: %w", err)
+			return rec
+		}
+	}
+
+	rec.Family = fam
+	return rec
+}
+
+// decodeMembers decodes a JSON array of members into DTOs
+func decodeMembers(data []byte, path string, dtos interface{}) error {
+	normalized, _, err := repair.MembersJSON(data, repair.CamelCase, path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(normalized, dtos)
+}
+
+// Quarantine moves a stored family out of the families table into the
+// families_quarantine table
+func (r *PostgresFamilyRepository) Quarantine(ctx context.Context, familyID, reason string) error {
+	if _, err := r.DB.Exec(ctx, createQuarantineSchema); err != nil {
+		return NewRepositoryError(err, "failed to create quarantine table", "POSTGRES_ERROR")
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return NewRepositoryError(err, "failed to begin transaction", "POSTGRES_ERROR")
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO families_quarantine (id, status, parents, children, external_ids, reason)
+		SELECT id, status, parents, children, external_ids, $2 FROM families WHERE id = $1
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			parents = EXCLUDED.parents,
+			children = EXCLUDED.children,
+			external_ids = EXCLUDED.external_ids,
+			reason = EXCLUDED.reason,
+			quarantined_at = CURRENT_TIMESTAMP
+	`, familyID, reason)
+	if err != nil {
+		return NewRepositoryError(err, "failed to copy family to quarantine", "POSTGRES_ERROR")
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM families WHERE id = $1", familyID); err != nil {
+		return NewRepositoryError(err, "failed to delete family", "POSTGRES_ERROR")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return NewRepositoryError(err, "failed to commit transaction", "POSTGRES_ERROR")
+	}
+
+	r.logger.Warn(ctx, "Quarantined family", zap.String("family_id", familyID), zap.String("reason", reason))
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRecord(t *testing.T) {
+	const famID = "f1000000-0000-4000-8000-000000000000"
+	parent := `[{"id":"a1000000-0000-4000-8000-000000000000","firstName":"Pat","lastName":"Doe","birthDate":"1980-01-01T00:00:00Z"}]`
+	legacyParent := `[{"ID":"a1000000-0000-4000-8000-000000000000","FirstName":"Pat","LastName":"Doe","BirthDate":"1980-01-01"}]`
+
+	rec := decodeRecord(famID, "SINGLE", []byte(parent), []byte("[]"), []byte(`{"crm":"C-1"}`))
+	require.NotNil(t, rec.Family)
+	assert.Equal(t, "Pat", rec.Family.Parents()[0].FirstName())
+	assert.Equal(t, map[string]string{"crm": "C-1"}, rec.Family.ExternalIDs())
+
+	rec = decodeRecord(famID, "SINGLE", []byte(legacyParent), []byte("[]"), nil)
+	assert.NotNil(t, rec.Family, "legacy rows that reads repair are not corrupt")
+
+	rec = decodeRecord(famID, "SINGLE", []byte(`{"id":"x"}`), []byte("[]"), nil)
+	assert.Error(t, rec.DecodeErr)
+	assert.Nil(t, rec.Family)
+
+	rec = decodeRecord(famID, "MARRIED", []byte(parent), []byte("[]"), nil)
+	assert.ErrorContains(t, rec.InvariantErr, "married family must have exactly two parents")
+	assert.Nil(t, rec.Family)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/familylock"
+	"go.uber.org/zap"
+)
+
+// unlockTimeout bounds the release of an advisory lock, which runs after the operation's
+// context may have ended
+const unlockTimeout = 5 * time.Second
+
+// Ensure PostgresFamilyRepository implements ports.FamilyLocker
+var _ ports.FamilyLocker = (*PostgresFamilyRepository)(nil)
+
+// LockFamily waits until the caller holds the advisory lock of a family. Advisory locks
+// belong to a database session, so the lock holds a connection of the pool until it is
+// released; the changes of the family are saved through other connections.
+func (r *PostgresFamilyRepository) LockFamily(ctx context.Context, familyID string) (func(), error) {
+	return familylock.Acquire(ctx, "postgres", familyID, r.locking, func(ctx context.Context) (func(), error) {
+		conn, err := r.DB.Acquire(ctx)
+		if err != nil {
+			return nil, NewRepositoryError(err, "failed to acquire a connection to lock the family", "POSTGRES_ERROR")
+		}
+
+		// Waiting for the lock is cancelled with the context, which closes the connection
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock(hashtextextended($1, 0))", familyID); err != nil {
+			conn.Release()
+			return nil, NewRepositoryError(err, "failed to lock the family", "POSTGRES_ERROR")
+		}
+
+		return func() {
+			unlockCtx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+			defer cancel()
+
+			// A session that may still hold the lock must not return to the pool
+			if _, err := conn.Exec(unlockCtx, "SELECT pg_advisory_unlock(hashtextextended($1, 0))", familyID); err != nil {
+				r.logger.Error(unlockCtx, "Failed to unlock family; closing the connection",
+					zap.Error(err),
+					zap.String("family_id", familyID))
+				_ = conn.Conn().Close(unlockCtx)
+			}
+			conn.Release()
+		}, nil
+	})
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+)
+
+// Preferred names and name histories are stored in the preferredName and nameHistory
+// keys of the parents and children JSON. Both are omitted for members without them, so
+// rows written before they were introduced are read unchanged.
+
+// jsonNameChange represents how an entry of a member's name history is stored in the JSON
+type jsonNameChange struct {
+	FirstName     string `json:"firstName"`
+	LastName      string `json:"lastName"`
+	EffectiveDate string `json:"effectiveDate"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// namedMember is a parent or child whose names are restored from the JSON
+type namedMember interface {
+	SetPreferredName(name string) error
+	SetNameHistory(changes []entity.NameChange) error
+}
+
+// nameHistoryToJSON converts a member's name history to its JSON form
+func nameHistoryToJSON(history []entity.NameChange) []jsonNameChange {
+	if len(history) == 0 {
+		return nil
+	}
+	entries := make([]jsonNameChange, 0, len(history))
+	for _, change := range history {
+		entries = append(entries, jsonNameChange{
+			FirstName:     change.FirstName,
+			LastName:      change.LastName,
+			EffectiveDate: change.EffectiveDate.Format(time.RFC3339),
+			Reason:        string(change.Reason),
+		})
+	}
+	return entries
+}
+
+// setMemberNames restores the preferred name and name history of a member from the JSON
+func setMemberNames(member namedMember, preferredName string, entries []jsonNameChange) error {
+	if err := member.SetPreferredName(preferredName); err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	history := make([]entity.NameChange, 0, len(entries))
+	for _, entry := range entries {
+		effectiveDate, err := time.Parse(time.RFC3339, entry.EffectiveDate)
+		if err != nil {
+			return NewRepositoryError(err, "invalid name change effective date format", "DATA_FORMAT_ERROR")
+		}
+		history = append(history, entity.NameChange{
+			FirstName:     entry.FirstName,
+			LastName:      entry.LastName,
+			EffectiveDate: effectiveDate,
+			Reason:        entity.NameChangeReason(entry.Reason),
+		})
+	}
+	return member.SetNameHistory(history)
+}
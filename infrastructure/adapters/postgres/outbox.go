@@ -0,0 +1,101 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+)
+
+// Domain events waiting to be relayed to the message broker are stored in the event_outbox
+// table, in the order of seq. The table is created with the families table, before any
+// unit of work begins, so that AddToOutbox can join the transaction of a unit of work.
+// Outbox messages are always read from the primary pool, which has every committed message.
+const createOutboxSchema = `
+	CREATE TABLE IF NOT EXISTS event_outbox (
+		seq BIGSERIAL PRIMARY KEY,
+		id VARCHAR(36) NOT NULL UNIQUE,
+		type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL
+	);
+	`
+
+// Ensure PostgresFamilyRepository implements ports.OutboxRepository
+var _ ports.OutboxRepository = (*PostgresFamilyRepository)(nil)
+
+// AddToOutbox stores messages in the outbox, in the unit of work of the context if there is
+// one. The messages are stored together or not at all.
+func (r *PostgresFamilyRepository) AddToOutbox(ctx context.Context, messages ...ports.OutboxMessage) error {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return err
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return NewRepositoryError(err, "failed to begin transaction", "POSTGRES_ERROR")
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, message := range messages {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO event_outbox (id, type, payload, created_at) VALUES ($1, $2, $3, $4)
+		`, message.ID, message.Type, string(message.Payload), message.CreatedAt.UTC())
+		if err != nil {
+			return NewRepositoryError(err, "failed to add message to outbox", "POSTGRES_ERROR")
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return NewRepositoryError(err, "failed to commit transaction", "POSTGRES_ERROR")
+	}
+	return nil
+}
+
+// PendingOutboxMessages returns at most limit messages of the outbox, in the order in which
+// they were stored
+func (r *PostgresFamilyRepository) PendingOutboxMessages(ctx context.Context, limit int) ([]ports.OutboxMessage, error) {
+	return r.OutboxMessages(ctx, "", limit)
+}
+
+// OutboxMessages returns at most limit messages of the outbox of the given type, or of every
+// type if it is empty, in the order in which they were stored
+func (r *PostgresFamilyRepository) OutboxMessages(ctx context.Context, eventType string, limit int) ([]ports.OutboxMessage, error) {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.Query(ctx, "SELECT id, type, payload, created_at FROM event_outbox WHERE ($2 = '' OR type = $2) ORDER BY seq LIMIT $1", limit, eventType)
+	if err != nil {
+		return nil, NewRepositoryError(err, "failed to read outbox", "POSTGRES_ERROR")
+	}
+	defer rows.Close()
+
+	messages := []ports.OutboxMessage{}
+	for rows.Next() {
+		var message ports.OutboxMessage
+		var payload string
+		if err := rows.Scan(&message.ID, &message.Type, &payload, &message.CreatedAt); err != nil {
+			return nil, NewRepositoryError(err, "failed to scan outbox message", "POSTGRES_ERROR")
+		}
+		message.Payload = []byte(payload)
+		message.CreatedAt = message.CreatedAt.UTC()
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, NewRepositoryError(err, "error iterating over outbox messages", "POSTGRES_ERROR")
+	}
+	return messages, nil
+}
+
+// RemoveFromOutbox removes the messages with the given IDs from the outbox
+func (r *PostgresFamilyRepository) RemoveFromOutbox(ctx context.Context, ids []string) error {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return err
+	}
+
+	if _, err := r.DB.Exec(ctx, "DELETE FROM event_outbox WHERE id = ANY($1)", ids); err != nil {
+		return NewRepositoryError(err, "failed to remove messages from outbox", "POSTGRES_ERROR")
+	}
+	return nil
+}
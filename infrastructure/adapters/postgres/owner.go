@@ -0,0 +1,77 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"go.uber.org/zap"
+)
+
+// The families of an owner are counted and read from the owner index, which leads with the
+// tenant and the owner, so that a caller who may only access its own families reads only
+// those, in ID order for the pages of the owner.
+const (
+	createOwnerIDIndex = "\n\tCREATE INDEX IF NOT EXISTS idx_families_owner_id ON families(tenant_id, owner_id, id);\n\t"
+
+	// countByOwnerQuery counts the families of an owner that are not excluded, and the
+	// distinct parents and children in them. A member of several families of the owner, such
+	// as a remarried parent, is counted once.
+	countByOwnerQuery = `
+            WITH owned AS (
+                SELECT parents, children FROM families
+                WHERE tenant_id = $1 AND owner_id = $2 AND deleted_at IS NULL AND NOT (id = ANY($3))
+            )
+            SELECT
+                (SELECT COUNT(*) FROM owned),
+                (SELECT COUNT(DISTINCT COALESCE(member->>'id', member->>'ID'))
+                    FROM owned, jsonb_array_elements(owned.parents) AS member),
+                (SELECT COUNT(DISTINCT COALESCE(member->>'id', member->>'ID'))
+                    FROM owned, jsonb_array_elements(owned.children) AS member)
+        `
+)
+
+// Ensure PostgresFamilyRepository implements ports.FamilyOwnerReader
+var _ ports.FamilyOwnerReader = (*PostgresFamilyRepository)(nil)
+
+// ListOwnedFamilies reads a page of the families of an owner in ascending ID order, from a
+// range of the owner index
+func (r *PostgresFamilyRepository) ListOwnedFamilies(ctx context.Context, ownerID, after string, limit int) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Listing a page of the families of an owner from PostgreSQL",
+		zap.String("owner_id", ownerID), zap.String("after", after), zap.Int("limit", limit))
+
+	return r.queryFamilies(ctx, "ListOwnedFamilies", "failed to list the families of the owner", `
+            SELECT id, status, parents, children, external_ids, owner_id FROM families
+            WHERE id > $1 AND tenant_id = $3 AND owner_id = $4 AND deleted_at IS NULL
+            ORDER BY id
+            LIMIT $2
+        `, after, limit, tenantOf(ctx), ownerID)
+}
+
+// CountByOwner counts the families of an owner that are not deleted, and the distinct
+// parents and children in them, leaving out the families with the excluded IDs
+func (r *PostgresFamilyRepository) CountByOwner(ctx context.Context, ownerID string, excluded []string) (ports.FamilyCounts, error) {
+	r.logger.Debug(ctx, "Counting the families of an owner in PostgreSQL", zap.String("owner_id", ownerID))
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return ports.FamilyCounts{}, err
+	}
+	// A nil slice is sent as NULL, which would exclude every family
+	if excluded == nil {
+		excluded = []string{}
+	}
+
+	var families, parents, children int64
+	err := r.protect(ctx, "CountByOwner", func(ctx context.Context) error {
+		if err := r.reader(ctx).QueryRow(ctx, countByOwnerQuery, tenantOf(ctx), ownerID, excluded).Scan(&families, &parents, &children); err != nil {
+			return NewRepositoryError(err, "failed to count the families of the owner", "POSTGRES_ERROR")
+		}
+		return nil
+	})
+	if err != nil {
+		return ports.FamilyCounts{}, err
+	}
+	return ports.FamilyCounts{Families: int(families), Parents: int(parents), Children: int(children)}, nil
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"go.uber.org/zap"
+)
+
+// Ensure PostgresFamilyRepository implements ports.FamilyPager
+var _ ports.FamilyPager = (*PostgresFamilyRepository)(nil)
+
+// ListFamilies reads a page of families in ascending ID order. The page starts after the
+// given ID on the primary key, so reading a page costs the same wherever it starts.
+func (r *PostgresFamilyRepository) ListFamilies(ctx context.Context, after string, limit int) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Listing a page of families from PostgreSQL", zap.String("after", after), zap.Int("limit", limit))
+
+	return r.queryFamilies(ctx, "ListFamilies", "failed to list families", `
+            SELECT id, status, parents, children, external_ids, owner_id FROM families
+            WHERE id > $1 AND tenant_id = $3 AND deleted_at IS NULL
+            ORDER BY id
+            LIMIT $2
+        `, after, limit, tenantOf(ctx))
+}
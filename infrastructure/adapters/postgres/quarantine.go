@@ -0,0 +1,121 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/servicelib/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+// Families quarantined by administrators are recorded in the admin_quarantines table.
+// Unlike the rows in families_quarantine, which the integrity check moved out of the
+// families table, the families stay in the families table, unchanged. Quarantines are
+// always read from the primary pool, so that a new quarantine applies at once.
+const createAdminQuarantinesSchema = `
+	CREATE TABLE IF NOT EXISTS admin_quarantines (
+		family_id VARCHAR(36) PRIMARY KEY,
+		reason TEXT NOT NULL,
+		quarantined_by TEXT NOT NULL,
+		quarantined_at TIMESTAMP WITH TIME ZONE NOT NULL
+	);
+	`
+
+// Ensure PostgresFamilyRepository implements ports.QuarantineRepository
+var _ ports.QuarantineRepository = (*PostgresFamilyRepository)(nil)
+
+// ensureAdminQuarantinesSchema creates the admin_quarantines table if it does not exist
+func (r *PostgresFamilyRepository) ensureAdminQuarantinesSchema(ctx context.Context) error {
+	// The schema was ensured when the unit of work of the context began
+	if r.unitOfWork(ctx) != nil {
+		return nil
+	}
+
+	if _, err := r.DB.Exec(ctx, createAdminQuarantinesSchema); err != nil {
+		return NewRepositoryError(err, "failed to create quarantine table", "POSTGRES_ERROR")
+	}
+	return nil
+}
+
+// GetFamilyQuarantine returns the quarantine of a family, or nil if it is not quarantined
+func (r *PostgresFamilyRepository) GetFamilyQuarantine(ctx context.Context, familyID string) (*entity.Quarantine, error) {
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return nil, err
+	}
+
+	var q entity.Quarantine
+	err := r.DB.QueryRow(ctx, `
+		SELECT family_id, reason, quarantined_by, quarantined_at FROM admin_quarantines WHERE family_id = $1
+	`, familyID).Scan(&q.FamilyID, &q.Reason, &q.QuarantinedBy, &q.QuarantinedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, NewRepositoryError(err, "failed to get family quarantine", "POSTGRES_ERROR")
+	}
+	q.QuarantinedAt = q.QuarantinedAt.UTC()
+	return &q, nil
+}
+
+// SaveFamilyQuarantine stores the quarantine of a family, replacing any previous one
+func (r *PostgresFamilyRepository) SaveFamilyQuarantine(ctx context.Context, q entity.Quarantine) error {
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return err
+	}
+
+	_, err := r.execer(ctx).Exec(ctx, `
+		INSERT INTO admin_quarantines (family_id, reason, quarantined_by, quarantined_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (family_id) DO UPDATE SET
+			reason = EXCLUDED.reason,
+			quarantined_by = EXCLUDED.quarantined_by,
+			quarantined_at = EXCLUDED.quarantined_at
+	`, q.FamilyID, q.Reason, q.QuarantinedBy, q.QuarantinedAt)
+	if err != nil {
+		return NewRepositoryError(err, "failed to save family quarantine", "POSTGRES_ERROR")
+	}
+	return nil
+}
+
+// DeleteFamilyQuarantine removes the quarantine of a family and reports whether it had one
+func (r *PostgresFamilyRepository) DeleteFamilyQuarantine(ctx context.Context, familyID string) (bool, error) {
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return false, err
+	}
+
+	tag, err := r.execer(ctx).Exec(ctx, "DELETE FROM admin_quarantines WHERE family_id = $1", familyID)
+	if err != nil {
+		return false, NewRepositoryError(err, "failed to delete family quarantine", "POSTGRES_ERROR")
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListFamilyQuarantines returns every quarantine, in ascending family ID order
+func (r *PostgresFamilyRepository) ListFamilyQuarantines(ctx context.Context) ([]entity.Quarantine, error) {
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.Query(ctx, "SELECT family_id, reason, quarantined_by, quarantined_at FROM admin_quarantines ORDER BY family_id")
+	if err != nil {
+		return nil, NewRepositoryError(err, "failed to list family quarantines", "POSTGRES_ERROR")
+	}
+	defer rows.Close()
+
+	quarantines := []entity.Quarantine{}
+	for rows.Next() {
+		var q entity.Quarantine
+		if err := rows.Scan(&q.FamilyID, &q.Reason, &q.QuarantinedBy, &q.QuarantinedAt); err != nil {
+			return nil, NewRepositoryError(err, "failed to scan family quarantine", "POSTGRES_ERROR")
+		}
+		q.QuarantinedAt = q.QuarantinedAt.UTC()
+		quarantines = append(quarantines, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, NewRepositoryError(err, "error iterating over family quarantines", "POSTGRES_ERROR")
+	}
+	return quarantines, nil
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"go.uber.org/zap"
+)
+
+// Filters are translated into a WHERE clause on the families table. Counts use the
+// parent_count and children_count columns, which PostgreSQL computes from the parents and
+// children arrays when a row is written, and conditions on members use EXISTS over
+// their elements, so that a condition matches when any member satisfies it. Legacy rows
+// may use upper-case member keys, and birth dates are stored as RFC 3339 strings, which
+// are compared as timestamps. Conditions on member names are first matched with LIKE
+// against family_member_names, whose trigram index finds the candidate families, and then
+// checked name by name.
+
+// sqlOperators are the SQL comparison operators of the query operators
+var sqlOperators = map[query.Op]string{
+	query.OpEq:  "=",
+	query.OpNe:  "<>",
+	query.OpLt:  "<",
+	query.OpLte: "<=",
+	query.OpGt:  ">",
+	query.OpGte: ">=",
+}
+
+// whereBuilder builds a WHERE clause and its positional arguments
+type whereBuilder struct {
+	args []interface{}
+}
+
+// whereClause translates a valid filter into a SQL condition and its arguments
+func whereClause(f query.Filter) (string, []interface{}) {
+	b := &whereBuilder{}
+	return b.build(f), b.args
+}
+
+// arg adds an argument and returns its placeholder
+func (b *whereBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// build translates a filter node
+func (b *whereBuilder) build(f query.Filter) string {
+	switch f := f.(type) {
+	case nil:
+		return "TRUE"
+	case query.And:
+		return b.join(f, " AND ", "TRUE")
+	case query.Or:
+		return b.join(f, " OR ", "FALSE")
+	case query.Not:
+		return "NOT (" + b.build(f.Filter) + ")"
+	case query.Condition:
+		return b.condition(f)
+	default:
+		return "FALSE"
+	}
+}
+
+// join translates the filters of an And or Or
+func (b *whereBuilder) join(filters []query.Filter, separator, empty string) string {
+	if len(filters) == 0 {
+		return empty
+	}
+	parts := make([]string, len(filters))
+	for i, f := range filters {
+		parts[i] = b.build(f)
+	}
+	return "(" + strings.Join(parts, separator) + ")"
+}
+
+// condition translates a single condition
+func (b *whereBuilder) condition(c query.Condition) string {
+	switch c.Field {
+	case query.FieldID:
+		return b.compare("id", c)
+	case query.FieldStatus:
+		return b.compare("status", c)
+	case query.FieldOwnerID:
+		return b.compare("owner_id", c)
+	case query.FieldParentCount:
+		return b.compare("parent_count", c)
+	case query.FieldChildCount:
+		return b.compare("children_count", c)
+	case query.FieldParentID:
+		return b.member("parents", "COALESCE(member->>'id', member->>'ID')", c)
+	case query.FieldChildID:
+		return b.member("children", "COALESCE(member->>'id', member->>'ID')", c)
+	case query.FieldParentLastName:
+		return b.member("parents", "COALESCE(member->>'lastName', member->>'LastName')", c)
+	case query.FieldParentBirthDate:
+		return b.member("parents", "COALESCE(member->>'birthDate', member->>'BirthDate')::timestamptz", c)
+	case query.FieldChildBirthDate:
+		return b.member("children", "COALESCE(member->>'birthDate', member->>'BirthDate')::timestamptz", c)
+	case query.FieldMemberName:
+		return b.memberName(c.Value.(string))
+	default:
+		return "FALSE"
+	}
+}
+
+// member translates a condition that matches when any element of a member column satisfies it
+func (b *whereBuilder) member(column, expr string, c query.Condition) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements(%s) AS member WHERE %s)", column, b.compare(expr, c))
+}
+
+// memberNames are the names of a member that conditions on member names are matched against
+const memberNames = "ARRAY[COALESCE(member->>'firstName', member->>'FirstName'), COALESCE(member->>'lastName', member->>'LastName'), member->>'preferredName']"
+
+// memberName translates a condition that the name of a member contains a value, ignoring
+// case. The LIKE on the names of all the members is served by the trigram index, and the
+// EXISTS keeps a value from matching across two names.
+func (b *whereBuilder) memberName(value string) string {
+	pattern := b.arg("%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(value)) + "%")
+	lower := b.arg(strings.ToLower(value))
+	exists := func(column string) string {
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements(%s) AS member, unnest(%s) AS name WHERE strpos(lower(name), %s) > 0)", column, memberNames, lower)
+	}
+	return fmt.Sprintf("(family_member_names(parents, children) LIKE %s AND (%s OR %s))", pattern, exists("parents"), exists("children"))
+}
+
+// compare translates the comparison of an expression with the value of a condition
+func (b *whereBuilder) compare(expr string, c query.Condition) string {
+	if r, ok := c.Value.(query.Range); ok {
+		return fmt.Sprintf("%s BETWEEN %s AND %s", expr, b.arg(r.From), b.arg(r.To))
+	}
+	if c.Op != query.OpIn {
+		return fmt.Sprintf("%s %s %s", expr, sqlOperators[c.Op], b.arg(c.Value))
+	}
+
+	switch values := c.Value.(type) {
+	case []string:
+		if len(values) == 0 {
+			return "FALSE"
+		}
+		return fmt.Sprintf("%s = ANY(%s)", expr, b.arg(values))
+	case []int:
+		if len(values) == 0 {
+			return "FALSE"
+		}
+		ints := make([]int64, len(values))
+		for i, v := range values {
+			ints[i] = int64(v)
+		}
+		return fmt.Sprintf("%s = ANY(%s)", expr, b.arg(ints))
+	default:
+		return "FALSE"
+	}
+}
+
+// Find finds the families that match a filter, in ascending ID order
+func (r *PostgresFamilyRepository) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	if err := query.Validate(filter); err != nil {
+		return nil, err
+	}
+
+	where, args := whereClause(filter)
+	r.logger.Debug(ctx, "Finding families by filter in PostgreSQL", zap.String("where", where))
+
+	args = append(args, tenantOf(ctx))
+	return r.queryFamilies(ctx, "Find", "failed to find families", `
+            SELECT id, status, parents, children, external_ids, owner_id FROM families
+            WHERE tenant_id = $`+strconv.Itoa(len(args))+` AND deleted_at IS NULL AND (`+where+`)
+            ORDER BY id
+        `, args...)
+}
+
+// Ensure PostgresFamilyRepository implements ports.FamilyFilterPager
+var _ ports.FamilyFilterPager = (*PostgresFamilyRepository)(nil)
+
+// FindPage finds at most limit families that match a filter and whose IDs sort after the
+// given ID, in ascending ID order
+func (r *PostgresFamilyRepository) FindPage(ctx context.Context, filter query.Filter, after string, limit int) ([]*entity.Family, error) {
+	if err := query.Validate(filter); err != nil {
+		return nil, err
+	}
+
+	where, args := whereClause(filter)
+	r.logger.Debug(ctx, "Finding a page of families by filter in PostgreSQL",
+		zap.String("where", where), zap.String("after", after), zap.Int("limit", limit))
+
+	args = append(args, tenantOf(ctx), after, limit)
+	return r.queryFamilies(ctx, "FindPage", "failed to find a page of families", `
+            SELECT id, status, parents, children, external_ids, owner_id FROM families
+            WHERE tenant_id = $`+strconv.Itoa(len(args)-2)+` AND id > $`+strconv.Itoa(len(args)-1)+` AND deleted_at IS NULL AND (`+where+`)
+            ORDER BY id
+            LIMIT $`+strconv.Itoa(len(args))+`
+        `, args...)
+}
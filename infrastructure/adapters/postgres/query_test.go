@@ -0,0 +1,74 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhereClause(t *testing.T) {
+	born := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name   string
+		filter query.Filter
+		where  string
+		args   []interface{}
+	}{
+		{"no filter", nil, "TRUE", nil},
+		{"empty or", query.Or{}, "FALSE", nil},
+		{"status in", query.In(query.FieldStatus, []string{"SINGLE", "DIVORCED"}), "status = ANY($1)", []interface{}{[]string{"SINGLE", "DIVORCED"}}},
+		{"empty in", query.In(query.FieldID, []string{}), "FALSE", nil},
+		{"count", query.Condition{Field: query.FieldChildCount, Op: query.OpGte, Value: 2}, "children_count >= $1", []interface{}{2}},
+		{"count in", query.In(query.FieldParentCount, []int{1, 2}), "parent_count = ANY($1)", []interface{}{[]int64{1, 2}}},
+		{"member id", query.Eq(query.FieldParentID, "p1"),
+			"EXISTS (SELECT 1 FROM jsonb_array_elements(parents) AS member WHERE COALESCE(member->>'id', member->>'ID') = $1)",
+			[]interface{}{"p1"}},
+		{"member name", query.Eq(query.FieldParentLastName, "Doe"),
+			"EXISTS (SELECT 1 FROM jsonb_array_elements(parents) AS member WHERE COALESCE(member->>'lastName', member->>'LastName') = $1)",
+			[]interface{}{"Doe"}},
+		{"member name contains", query.Contains(query.FieldMemberName, "Em_"),
+			"(family_member_names(parents, children) LIKE $1 AND (" +
+				"EXISTS (SELECT 1 FROM jsonb_array_elements(parents) AS member, unnest(" + memberNames + ") AS name WHERE strpos(lower(name), $2) > 0) OR " +
+				"EXISTS (SELECT 1 FROM jsonb_array_elements(children) AS member, unnest(" + memberNames + ") AS name WHERE strpos(lower(name), $2) > 0)))",
+			[]interface{}{`%em\_%`, "em_"}},
+		{"member range", query.Condition{Field: query.FieldChildBirthDate, Op: query.OpBetween, Value: query.Range{From: born, To: born.AddDate(1, 0, 0)}},
+			"EXISTS (SELECT 1 FROM jsonb_array_elements(children) AS member WHERE COALESCE(member->>'birthDate', member->>'BirthDate')::timestamptz BETWEEN $1 AND $2)",
+			[]interface{}{born, born.AddDate(1, 0, 0)}},
+		{"compound", query.And{
+			query.Condition{Field: query.FieldStatus, Op: query.OpNe, Value: "MARRIED"},
+			query.Not{Filter: query.Condition{Field: query.FieldChildBirthDate, Op: query.OpLt, Value: born}},
+		},
+			"(status <> $1 AND NOT (EXISTS (SELECT 1 FROM jsonb_array_elements(children) AS member WHERE COALESCE(member->>'birthDate', member->>'BirthDate')::timestamptz < $2)))",
+			[]interface{}{"MARRIED", born}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			where, args := whereClause(tc.filter)
+			assert.Equal(t, tc.where, where)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+// TestWhereClause_SharedCases checks that every shared case translates into a well-formed
+// clause: balanced parentheses and one argument per placeholder
+func TestWhereClause_SharedCases(t *testing.T) {
+	for _, tc := range querytest.Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			where, args := whereClause(tc.Filter)
+			assert.Equal(t, strings.Count(where, "("), strings.Count(where, ")"))
+			for i := range args {
+				assert.Contains(t, where, fmt.Sprintf("$%d", i+1))
+			}
+			assert.NotContains(t, where, fmt.Sprintf("$%d", len(args)+1))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/concurrency"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/repair"
+	"go.uber.org/zap"
+)
+
+// repairRow repairs the status and member documents of a family row in place if read
+// repair is enabled, and logs and counts the repairs
+func (r *PostgresFamilyRepository) repairRow(ctx context.Context, famID string, statusStr *string, parentsData, childrenData *[]byte) []repair.Repair {
+	if !r.readRepair.Enabled {
+		return nil
+	}
+
+	var repairs []repair.Repair
+	for _, column := range []struct {
+		data *[]byte
+		path string
+	}{{parentsData, "parents"}, {childrenData, "children"}} {
+		repaired, columnRepairs, err := repair.MembersJSON(*column.data, repair.CamelCase, column.path)
+		if err != nil {
+			// Leave the data unchanged so that decoding reports the error
+			continue
+		}
+		*column.data = repaired
+		repairs = append(repairs, columnRepairs...)
+	}
+
+	// Derive a missing status from the number of parents
+	var parentCount int
+	if strings.TrimSpace(*statusStr) == "" {
+		var parents []json.RawMessage
+		if err := json.Unmarshal(*parentsData, &parents); err == nil {
+			parentCount = len(parents)
+		}
+	}
+	status, statusRepairs := repair.Status(*statusStr, parentCount)
+	*statusStr = status
+	repairs = append(repairs, statusRepairs...)
+
+	repair.Record(ctx, r.logger, "postgres", famID, repairs)
+	return repairs
+}
+
+// writeBack saves the canonical form of repaired families if write-back is enabled.
+// The families are saved concurrently, within the repository fan-out limit.
+// Failures are logged and do not fail the read.
+func (r *PostgresFamilyRepository) writeBack(ctx context.Context, families []*entity.Family) {
+	if !r.readRepair.WriteBack {
+		return
+	}
+	_ = concurrency.ForEach(ctx, r.fanOut, len(families), func(ctx context.Context, i int) error {
+		fam := families[i]
+		if err := r.Save(ctx, fam); err != nil {
+			r.logger.Warn(ctx, "Failed to write back repaired family", zap.Error(err), zap.String("family_id", fam.ID()))
+			return nil
+		}
+		r.logger.Info(ctx, "Wrote back repaired family", zap.String("family_id", fam.ID()))
+		return nil
+	})
+}
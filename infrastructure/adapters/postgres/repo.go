@@ -5,15 +5,20 @@
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
 	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/assembly"
 	circuit "github.com/abitofhelp/family-service/infrastructure/adapters/circuitwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/concurrency"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
 	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/hedge"
 	rate "github.com/abitofhelp/family-service/infrastructure/adapters/ratewrapper"
 	"github.com/abitofhelp/servicelib/errors"
 	"github.com/abitofhelp/servicelib/logging"
@@ -77,6 +82,12 @@ type PostgresFamilyRepository struct {
 	logger         *logging.ContextLogger
 	circuitBreaker *circuit.CircuitBreaker
 	rateLimiter    *rate.RateLimiter
+	hedger         *hedge.Hedger // Hedges reads by ID; nil when hedging is disabled
+	readRepair     config.ReadRepairConfig
+	memoryBudget   config.MemoryBudgetConfig // Bounds the memory of results of many families
+	locking        config.LockingConfig      // Serializes the changes of a family
+	fanOut         *concurrency.Limiter      // Limits the operations of a fan-out, such as write-back
+	analyticsDB    *pgxpool.Pool             // Read-only pool of analytics work; nil uses DB
 }
 
 // Ensure PostgresFamilyRepository implements ports.FamilyRepository
@@ -120,6 +131,30 @@ func NewPostgresFamilyRepository(db *pgxpool.Pool, logger *logging.ContextLogger
 		}
 	}
 
+	// Get the read repair configuration, reading strictly if it is not configured
+	var readRepair config.ReadRepairConfig
+	if globalConfig != nil {
+		readRepair = globalConfig.Database.Postgres.ReadRepair
+	}
+
+	// Get the memory budget of results, leaving them unbounded if it is not configured
+	var memoryBudget config.MemoryBudgetConfig
+	if globalConfig != nil {
+		memoryBudget = globalConfig.Database.MemoryBudget
+	}
+
+	// Get the locking configuration, changing families without locks if it is not configured
+	var locking config.LockingConfig
+	if globalConfig != nil {
+		locking = globalConfig.Database.Locking
+	}
+
+	// Fan out one operation at a time unless concurrency limits are configured
+	fanOutLimit := 1
+	if globalConfig != nil && globalConfig.Concurrency.Enabled {
+		fanOutLimit = globalConfig.Concurrency.Repository
+	}
+
 	// Create a new zap logger for the circuit breaker and rate limiter
 	zapLogger := zap.NewExample()
 
@@ -129,72 +164,47 @@ func NewPostgresFamilyRepository(db *pgxpool.Pool, logger *logging.ContextLogger
 	// Create rate limiter using the family-service wrapper
 	rl := rate.NewRateLimiter("postgres", rateConfig, zapLogger)
 
+	// Hedge reads by ID if hedging is configured
+	var hedger *hedge.Hedger
+	if globalConfig != nil {
+		hedger = hedge.NewHedger("postgres", &globalConfig.Hedge, zapLogger)
+	}
+
 	return &PostgresFamilyRepository{
 		DB:             db,
 		logger:         logger,
 		circuitBreaker: cb,
 		rateLimiter:    rl,
+		hedger:         hedger,
+		readRepair:     readRepair,
+		memoryBudget:   memoryBudget,
+		locking:        locking,
+		fanOut:         concurrency.NewLimiter(concurrency.ClassRepository, fanOutLimit),
 	}
 }
 
 // ensureTableExists creates the families table if it doesn't exist
 func (r *PostgresFamilyRepository) ensureTableExists(ctx context.Context) error {
+	// The schema was ensured when the unit of work of the context began
+	if r.unitOfWork(ctx) != nil {
+		return nil
+	}
+
 	r.logger.Debug(ctx, "Ensuring families table exists in PostgreSQL")
 
-	query := `
-	CREATE TABLE IF NOT EXISTS families (
-		id VARCHAR(36) PRIMARY KEY,
-		status VARCHAR(20) NOT NULL,
-		parents JSONB NOT NULL,
-		children JSONB NOT NULL,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-	);
-
-	-- Create indexes if they don't exist
-	DO $$
-	BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_families_status') THEN
-			CREATE INDEX idx_families_status ON families(status);
-		END IF;
-
-		IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_families_parents') THEN
-			CREATE INDEX idx_families_parents ON families USING GIN (parents);
-		END IF;
-
-		IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_families_children') THEN
-			CREATE INDEX idx_families_children ON families USING GIN (children);
-		END IF;
-	END
-	$$;
-
-	-- Create update trigger function if it doesn't exist
-	CREATE OR REPLACE FUNCTION update_updated_at_column()
-	RETURNS TRIGGER AS $$
-	BEGIN
-		NEW.updated_at = CURRENT_TIMESTAMP;
-		RETURN NEW;
-	END;
-	$$ LANGUAGE plpgsql;
-
-	-- Create trigger if it doesn't exist
-	DO $$
-	BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_families_updated_at') THEN
-			CREATE TRIGGER update_families_updated_at
-			BEFORE UPDATE ON families
-			FOR EACH ROW
-			EXECUTE FUNCTION update_updated_at_column();
-		END IF;
-	END
-	$$;
-	`
-	_, err := r.DB.Exec(ctx, query)
+	_, err := r.DB.Exec(ctx, familiesSchema)
 	if err != nil {
 		r.logger.Error(ctx, "Failed to create families table in PostgreSQL", zap.Error(err))
 		return NewRepositoryError(err, "failed to create families table", "POSTGRES_ERROR")
 	}
 
+	// Enforce tenant isolation in the database when row-level security is enabled
+	if rowLevelSecurityEnabled() {
+		if err := r.ensureRowLevelSecurity(ctx); err != nil {
+			return err
+		}
+	}
+
 	r.logger.Debug(ctx, "Families table exists in PostgreSQL")
 	return nil
 }
@@ -219,35 +229,48 @@ func (r *PostgresFamilyRepository) GetByID(ctx context.Context, id string) (*ent
 
 	var famID string
 	var statusStr string
-	var parentsData, childrenData []byte
+	var parentsData, childrenData, externalIDsData []byte
+	var ownerID string
+	var version int
 	var retryErr error
 
 	// Define the operation to retry
 	operation := func(ctx context.Context) error {
-		err := r.DB.QueryRow(ctx, `
-			SELECT id, status, parents, children FROM families WHERE id = $1
-		`, id).Scan(&famID, &statusStr, &parentsData, &childrenData)
+		// The query may be hedged, so each attempt scans into its own row
+		type familyRow struct {
+			id, status, ownerID            string
+			parents, children, externalIDs []byte
+			version                        int
+		}
+		row, err := hedge.Do(ctx, r.hedger, "GetByID", func(ctx context.Context) (familyRow, error) {
+			var row familyRow
+			err := r.DB.QueryRow(ctx, `
+				SELECT id, status, parents, children, external_ids, owner_id, version FROM families WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+			`, id, tenantOf(ctx)).Scan(&row.id, &row.status, &row.parents, &row.children, &row.externalIDs, &row.ownerID, &row.version)
+			return row, err
+		})
 
 		if err != nil {
-			if err == pgx.ErrNoRows {
+			if errors.Is(err, pgx.ErrNoRows) {
 				r.logger.Info(ctx, "Family not found in PostgreSQL", zap.String("family_id", id))
 				return errors.NewNotFoundError("Family", id, nil)
 			}
 			r.logger.Error(ctx, "Failed to get family from PostgreSQL", zap.Error(err), zap.String("family_id", id))
 			return NewRepositoryError(err, "failed to get family from PostgreSQL", "POSTGRES_ERROR")
 		}
+		famID, statusStr, parentsData, childrenData, externalIDsData, ownerID, version = row.id, row.status, row.parents, row.children, row.externalIDs, row.ownerID, row.version
 		return nil
 	}
 
 	// Define which errors are retryable
 	isRetryable := func(err error) bool {
 		// Don't retry not found errors
-		if _, ok := err.(*errors.NotFoundError); ok {
+		if errors.IsNotFoundError(err) {
 			return false
 		}
 
 		// Don't retry validation errors
-		if _, ok := err.(*errors.ValidationError); ok {
+		if errors.IsValidationError(err) {
 			return false
 		}
 
@@ -298,13 +321,13 @@ func (r *PostgresFamilyRepository) GetByID(ctx context.Context, id string) (*ent
 	// Handle retry errors
 	if retryErr != nil {
 		// If it's already a typed error, return it directly
-		if _, ok := retryErr.(*errors.NotFoundError); ok {
+		if errors.IsNotFoundError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.ValidationError); ok {
+		if errors.IsValidationError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.DatabaseError); ok {
+		if errors.IsDatabaseError(retryErr) {
 			return nil, retryErr
 		}
 
@@ -314,31 +337,41 @@ func (r *PostgresFamilyRepository) GetByID(ctx context.Context, id string) (*ent
 
 	r.logger.Debug(ctx, "Successfully retrieved family data from PostgreSQL", zap.String("family_id", id))
 
+	// Repair legacy data if read repair is enabled
+	repairs := r.repairRow(ctx, famID, &statusStr, &parentsData, &childrenData)
+
 	// Define custom structs for JSON unmarshaling to handle both uppercase and lowercase field names
 	type jsonParent struct {
-		ID        string  `json:"ID,omitempty"`
-		Id        string  `json:"id,omitempty"`
-		FirstName string  `json:"FirstName,omitempty"`
-		FirstN    string  `json:"firstName,omitempty"`
-		LastName  string  `json:"LastName,omitempty"`
-		LastN     string  `json:"lastName,omitempty"`
-		BirthDate string  `json:"BirthDate,omitempty"`
-		BirthD    string  `json:"birthDate,omitempty"`
-		DeathDate *string `json:"DeathDate,omitempty"`
-		DeathD    *string `json:"deathDate,omitempty"`
+		ID            string            `json:"ID,omitempty"`
+		Id            string            `json:"id,omitempty"`
+		FirstName     string            `json:"FirstName,omitempty"`
+		FirstN        string            `json:"firstName,omitempty"`
+		LastName      string            `json:"LastName,omitempty"`
+		LastN         string            `json:"lastName,omitempty"`
+		BirthDate     string            `json:"BirthDate,omitempty"`
+		BirthD        string            `json:"birthDate,omitempty"`
+		DeathDate     *string           `json:"DeathDate,omitempty"`
+		DeathD        *string           `json:"deathDate,omitempty"`
+		ExternalIDs   map[string]string `json:"externalIds,omitempty"`
+		PreferredName string            `json:"preferredName,omitempty"`
+		NameHistory   []jsonNameChange  `json:"nameHistory,omitempty"`
 	}
 
 	type jsonChild struct {
-		ID        string  `json:"ID,omitempty"`
-		Id        string  `json:"id,omitempty"`
-		FirstName string  `json:"FirstName,omitempty"`
-		FirstN    string  `json:"firstName,omitempty"`
-		LastName  string  `json:"LastName,omitempty"`
-		LastN     string  `json:"lastName,omitempty"`
-		BirthDate string  `json:"BirthDate,omitempty"`
-		BirthD    string  `json:"birthDate,omitempty"`
-		DeathDate *string `json:"DeathDate,omitempty"`
-		DeathD    *string `json:"deathDate,omitempty"`
+		ID            string            `json:"ID,omitempty"`
+		Id            string            `json:"id,omitempty"`
+		FirstName     string            `json:"FirstName,omitempty"`
+		FirstN        string            `json:"firstName,omitempty"`
+		LastName      string            `json:"LastName,omitempty"`
+		LastN         string            `json:"lastName,omitempty"`
+		BirthDate     string            `json:"BirthDate,omitempty"`
+		BirthD        string            `json:"birthDate,omitempty"`
+		DeathDate     *string           `json:"DeathDate,omitempty"`
+		DeathD        *string           `json:"deathDate,omitempty"`
+		ExternalIDs   map[string]string `json:"externalIds,omitempty"`
+		PreferredName string            `json:"preferredName,omitempty"`
+		NameHistory   []jsonNameChange  `json:"nameHistory,omitempty"`
+		Consents      []jsonConsent     `json:"consents,omitempty"`
 	}
 
 	// Parse parents JSON
@@ -390,6 +423,12 @@ type jsonChild struct {
 		if err != nil {
 			return nil, NewRepositoryError(err, "failed to create parent entity", "CONVERSION_ERROR")
 		}
+		if err := p.SetExternalIDs(jp.ExternalIDs); err != nil {
+			return nil, NewRepositoryError(err, "invalid parent external IDs", "CONVERSION_ERROR")
+		}
+		if err := setMemberNames(p, jp.PreferredName, jp.NameHistory); err != nil {
+			return nil, NewRepositoryError(err, "invalid parent names", "CONVERSION_ERROR")
+		}
 		parents = append(parents, p)
 	}
 
@@ -442,15 +481,43 @@ type jsonChild struct {
 		if err != nil {
 			return nil, NewRepositoryError(err, "failed to create child entity", "CONVERSION_ERROR")
 		}
+		if err := c.SetExternalIDs(jc.ExternalIDs); err != nil {
+			return nil, NewRepositoryError(err, "invalid child external IDs", "CONVERSION_ERROR")
+		}
+		if err := setMemberNames(c, jc.PreferredName, jc.NameHistory); err != nil {
+			return nil, NewRepositoryError(err, "invalid child names", "CONVERSION_ERROR")
+		}
+		if err := setChildConsents(c, jc.Consents); err != nil {
+			return nil, NewRepositoryError(err, "invalid child consents", "CONVERSION_ERROR")
+		}
 		children = append(children, c)
 	}
 
 	// Create family entity
-	return entity.NewFamily(famID, entity.Status(statusStr), parents, children)
+	family, err := newFamilyWithExternalIDs(famID, statusStr, parents, children, externalIDsData)
+	if err != nil {
+		return nil, err
+	}
+	family.SetOwnerID(ownerID)
+	family.SetVersion(version)
+
+	// Write back the canonical form if the family was repaired
+	if len(repairs) > 0 {
+		r.writeBack(ctx, []*entity.Family{family})
+	}
+
+	return family, nil
 }
 
 // Save persists a family
 func (r *PostgresFamilyRepository) Save(ctx context.Context, fam *entity.Family) error {
+	return r.protect(ctx, "Save", func(ctx context.Context) error {
+		return r.save(ctx, fam)
+	})
+}
+
+// save persists a family in a transaction
+func (r *PostgresFamilyRepository) save(ctx context.Context, fam *entity.Family) error {
 	if fam == nil {
 		r.logger.Warn(ctx, "Family cannot be nil for Save")
 		return errors.NewValidationError("family cannot be nil", "family", nil)
@@ -468,7 +535,8 @@ func (r *PostgresFamilyRepository) Save(ctx context.Context, fam *entity.Family)
 		return err
 	}
 
-	tx, err := r.DB.Begin(ctx)
+	// Begin transaction, which joins the unit of work of the context
+	tx, err := r.begin(ctx)
 	if err != nil {
 		r.logger.Error(ctx, "Failed to begin transaction", zap.Error(err), zap.String("family_id", fam.ID()))
 		return NewRepositoryError(err, "failed to begin transaction", "POSTGRES_ERROR")
@@ -487,19 +555,26 @@ func (r *PostgresFamilyRepository) Save(ctx context.Context, fam *entity.Family)
 	// Create custom JSON-compatible structures for parents and children
 	// to ensure proper date formatting
 	type jsonParent struct {
-		ID        string  `json:"id"`
-		FirstName string  `json:"firstName"`
-		LastName  string  `json:"lastName"`
-		BirthDate string  `json:"birthDate"`
-		DeathDate *string `json:"deathDate,omitempty"`
+		ID            string            `json:"id"`
+		FirstName     string            `json:"firstName"`
+		LastName      string            `json:"lastName"`
+		BirthDate     string            `json:"birthDate"`
+		DeathDate     *string           `json:"deathDate,omitempty"`
+		ExternalIDs   map[string]string `json:"externalIds,omitempty"`
+		PreferredName string            `json:"preferredName,omitempty"`
+		NameHistory   []jsonNameChange  `json:"nameHistory,omitempty"`
 	}
 
 	type jsonChild struct {
-		ID        string  `json:"id"`
-		FirstName string  `json:"firstName"`
-		LastName  string  `json:"lastName"`
-		BirthDate string  `json:"birthDate"`
-		DeathDate *string `json:"deathDate,omitempty"`
+		ID            string            `json:"id"`
+		FirstName     string            `json:"firstName"`
+		LastName      string            `json:"lastName"`
+		BirthDate     string            `json:"birthDate"`
+		DeathDate     *string           `json:"deathDate,omitempty"`
+		ExternalIDs   map[string]string `json:"externalIds,omitempty"`
+		PreferredName string            `json:"preferredName,omitempty"`
+		NameHistory   []jsonNameChange  `json:"nameHistory,omitempty"`
+		Consents      []jsonConsent     `json:"consents,omitempty"`
 	}
 
 	// Convert parents to JSON-compatible format
@@ -512,11 +587,14 @@ type jsonChild struct {
 		}
 
 		jsonParents = append(jsonParents, jsonParent{
-			ID:        p.ID(),
-			FirstName: p.FirstName(),
-			LastName:  p.LastName(),
-			BirthDate: p.BirthDate().Format(time.RFC3339),
-			DeathDate: deathDateStr,
+			ID:            p.ID(),
+			FirstName:     p.FirstName(),
+			LastName:      p.LastName(),
+			BirthDate:     p.BirthDate().Format(time.RFC3339),
+			DeathDate:     deathDateStr,
+			ExternalIDs:   p.ExternalIDs(),
+			PreferredName: p.PreferredName(),
+			NameHistory:   nameHistoryToJSON(p.NameHistory()),
 		})
 	}
 
@@ -530,11 +608,15 @@ type jsonChild struct {
 		}
 
 		jsonChildren = append(jsonChildren, jsonChild{
-			ID:        c.ID(),
-			FirstName: c.FirstName(),
-			LastName:  c.LastName(),
-			BirthDate: c.BirthDate().Format(time.RFC3339),
-			DeathDate: deathDateStr,
+			ID:            c.ID(),
+			FirstName:     c.FirstName(),
+			LastName:      c.LastName(),
+			BirthDate:     c.BirthDate().Format(time.RFC3339),
+			DeathDate:     deathDateStr,
+			ExternalIDs:   c.ExternalIDs(),
+			PreferredName: c.PreferredName(),
+			NameHistory:   nameHistoryToJSON(c.NameHistory()),
+			Consents:      consentsToJSON(c.Consents()),
 		})
 	}
 
@@ -558,16 +640,45 @@ type jsonChild struct {
 		return NewRepositoryError(nil, "invalid children JSON", "JSON_ERROR")
 	}
 
-	// Execute SQL
-	_, txErr = tx.Exec(ctx, `
-        INSERT INTO families (id, status, parents, children)
-        VALUES ($1, $2, $3::jsonb, $4::jsonb)
+	externalIDsJSON, err := encodeFamilyExternalIDs(fam)
+	if err != nil {
+		return NewRepositoryError(err, "failed to marshal external IDs to JSON", "JSON_ERROR")
+	}
+
+	// Enforce uniqueness of external IDs
+	if txErr = r.checkExternalIDsUnique(ctx, tx, fam); txErr != nil {
+		return txErr
+	}
+
+	// Execute SQL. The next version is saved over the version that was read. A family that
+	// was not read from the repository is inserted, or saved over any version if the save is
+	// marked with ports.WithOverwrite. A stale version or a taken ID updates no row, and
+	// neither does a family of another tenant with the same ID.
+	var version int
+	txErr = tx.QueryRow(ctx, `
+        INSERT INTO families (id, status, parents, children, external_ids, owner_id, tenant_id, version)
+        VALUES ($1, $2, $3::jsonb, $4::jsonb, $5::jsonb, $7, $8, $6 + 1)
         ON CONFLICT (id) DO UPDATE SET
             status = EXCLUDED.status,
             parents = EXCLUDED.parents,
-            children = EXCLUDED.children
-    `, fam.ID(), string(fam.Status()), parentsJSON, childrenJSON)
-
+            children = EXCLUDED.children,
+            external_ids = EXCLUDED.external_ids,
+            owner_id = EXCLUDED.owner_id,
+            deleted_at = NULL,
+            version = families.version + 1
+        WHERE families.tenant_id = EXCLUDED.tenant_id AND (($6 = 0 AND $9) OR families.version = $6)
+        RETURNING version
+    `, fam.ID(), string(fam.Status()), parentsJSON, childrenJSON, externalIDsJSON, fam.Version(), fam.OwnerID(), tenantOf(ctx), ports.IsOverwrite(ctx)).Scan(&version)
+
+	if errors.Is(txErr, pgx.ErrNoRows) && fam.Version() == 0 {
+		txErr = domainerrors.NewConcurrencyError(fmt.Sprintf("family %s already exists", fam.ID()), nil)
+		return txErr
+	}
+	if errors.Is(txErr, pgx.ErrNoRows) {
+		txErr = domainerrors.NewConcurrencyError(fmt.Sprintf(
+			"family %s was changed by another operation after version %d was read", fam.ID(), fam.Version()), nil)
+		return txErr
+	}
 	if txErr != nil {
 		return NewRepositoryError(txErr, "failed to save family to PostgreSQL", "POSTGRES_ERROR")
 	}
@@ -576,6 +687,7 @@ type jsonChild struct {
 	if txErr = tx.Commit(ctx); txErr != nil {
 		return NewRepositoryError(txErr, "failed to commit transaction", "POSTGRES_ERROR")
 	}
+	fam.SetVersion(version)
 
 	return nil
 }
@@ -595,53 +707,75 @@ func (r *PostgresFamilyRepository) FindByParentID(ctx context.Context, parentID
 	}
 
 	// Query for both uppercase and lowercase ID fields
-	rows, err := r.DB.Query(ctx, `
-        SELECT id, status, parents, children FROM families 
-        WHERE parents @> ANY (ARRAY[jsonb_build_array(jsonb_build_object('id', $1))]) 
-        OR parents @> ANY (ARRAY[jsonb_build_array(jsonb_build_object('ID', $1))])
-    `, parentID)
+	var rows pgx.Rows
+	err := r.protect(ctx, "FindByParentID", func(ctx context.Context) error {
+		var err error
+		rows, err = r.DB.Query(ctx, `
+            SELECT id, status, parents, children, external_ids, owner_id FROM families 
+            WHERE tenant_id = $2 AND deleted_at IS NULL
+            AND (parents @> ANY (ARRAY[jsonb_build_array(jsonb_build_object('id', $1))])
+            OR parents @> ANY (ARRAY[jsonb_build_array(jsonb_build_object('ID', $1))]))
+        `, parentID, tenantOf(ctx))
+		if err != nil {
+			return NewRepositoryError(err, "failed to find families by parent ID", "POSTGRES_ERROR")
+		}
+		return nil
+	})
 
 	if err != nil {
-		return nil, NewRepositoryError(err, "failed to find families by parent ID", "POSTGRES_ERROR")
+		return nil, err
 	}
 	defer rows.Close()
 
-	var families []*entity.Family
+	// Stop reading if the result exceeds the memory budget
+	result := assembly.NewAssembler(r.memoryBudget, "FindByParentID")
+	var repaired []*entity.Family
 
 	for rows.Next() {
 		var famID string
 		var statusStr string
-		var parentsData, childrenData []byte
+		var parentsData, childrenData, externalIDsData []byte
+		var ownerID string
 
-		if err := rows.Scan(&famID, &statusStr, &parentsData, &childrenData); err != nil {
+		if err := rows.Scan(&famID, &statusStr, &parentsData, &childrenData, &externalIDsData, &ownerID); err != nil {
 			return nil, NewRepositoryError(err, "failed to scan family row", "POSTGRES_ERROR")
 		}
 
+		// Repair legacy data if read repair is enabled
+		repairs := r.repairRow(ctx, famID, &statusStr, &parentsData, &childrenData)
+
 		// Define custom structs for JSON unmarshaling to handle both uppercase and lowercase field names
 		type jsonParent struct {
-			ID        string  `json:"ID,omitempty"`
-			Id        string  `json:"id,omitempty"`
-			FirstName string  `json:"FirstName,omitempty"`
-			FirstN    string  `json:"firstName,omitempty"`
-			LastName  string  `json:"LastName,omitempty"`
-			LastN     string  `json:"lastName,omitempty"`
-			BirthDate string  `json:"BirthDate,omitempty"`
-			BirthD    string  `json:"birthDate,omitempty"`
-			DeathDate *string `json:"DeathDate,omitempty"`
-			DeathD    *string `json:"deathDate,omitempty"`
+			ID            string            `json:"ID,omitempty"`
+			Id            string            `json:"id,omitempty"`
+			FirstName     string            `json:"FirstName,omitempty"`
+			FirstN        string            `json:"firstName,omitempty"`
+			LastName      string            `json:"LastName,omitempty"`
+			LastN         string            `json:"lastName,omitempty"`
+			BirthDate     string            `json:"BirthDate,omitempty"`
+			BirthD        string            `json:"birthDate,omitempty"`
+			DeathDate     *string           `json:"DeathDate,omitempty"`
+			DeathD        *string           `json:"deathDate,omitempty"`
+			ExternalIDs   map[string]string `json:"externalIds,omitempty"`
+			PreferredName string            `json:"preferredName,omitempty"`
+			NameHistory   []jsonNameChange  `json:"nameHistory,omitempty"`
 		}
 
 		type jsonChild struct {
-			ID        string  `json:"ID,omitempty"`
-			Id        string  `json:"id,omitempty"`
-			FirstName string  `json:"FirstName,omitempty"`
-			FirstN    string  `json:"firstName,omitempty"`
-			LastName  string  `json:"LastName,omitempty"`
-			LastN     string  `json:"lastName,omitempty"`
-			BirthDate string  `json:"BirthDate,omitempty"`
-			BirthD    string  `json:"birthDate,omitempty"`
-			DeathDate *string `json:"DeathDate,omitempty"`
-			DeathD    *string `json:"deathDate,omitempty"`
+			ID            string            `json:"ID,omitempty"`
+			Id            string            `json:"id,omitempty"`
+			FirstName     string            `json:"FirstName,omitempty"`
+			FirstN        string            `json:"firstName,omitempty"`
+			LastName      string            `json:"LastName,omitempty"`
+			LastN         string            `json:"lastName,omitempty"`
+			BirthDate     string            `json:"BirthDate,omitempty"`
+			BirthD        string            `json:"birthDate,omitempty"`
+			DeathDate     *string           `json:"DeathDate,omitempty"`
+			DeathD        *string           `json:"deathDate,omitempty"`
+			ExternalIDs   map[string]string `json:"externalIds,omitempty"`
+			PreferredName string            `json:"preferredName,omitempty"`
+			NameHistory   []jsonNameChange  `json:"nameHistory,omitempty"`
+			Consents      []jsonConsent     `json:"consents,omitempty"`
 		}
 
 		// Parse parents JSON
@@ -693,6 +827,12 @@ type jsonChild struct {
 			if err != nil {
 				return nil, NewRepositoryError(err, "failed to create parent entity", "CONVERSION_ERROR")
 			}
+			if err := p.SetExternalIDs(jp.ExternalIDs); err != nil {
+				return nil, NewRepositoryError(err, "invalid parent external IDs", "CONVERSION_ERROR")
+			}
+			if err := setMemberNames(p, jp.PreferredName, jp.NameHistory); err != nil {
+				return nil, NewRepositoryError(err, "invalid parent names", "CONVERSION_ERROR")
+			}
 			parents = append(parents, p)
 		}
 
@@ -745,23 +885,42 @@ type jsonChild struct {
 			if err != nil {
 				return nil, NewRepositoryError(err, "failed to create child entity", "CONVERSION_ERROR")
 			}
+			if err := c.SetExternalIDs(jc.ExternalIDs); err != nil {
+				return nil, NewRepositoryError(err, "invalid child external IDs", "CONVERSION_ERROR")
+			}
+			if err := setMemberNames(c, jc.PreferredName, jc.NameHistory); err != nil {
+				return nil, NewRepositoryError(err, "invalid child names", "CONVERSION_ERROR")
+			}
+			if err := setChildConsents(c, jc.Consents); err != nil {
+				return nil, NewRepositoryError(err, "invalid child consents", "CONVERSION_ERROR")
+			}
 			children = append(children, c)
 		}
 
 		// Create family entity
-		fam, err := entity.NewFamily(famID, entity.Status(statusStr), parents, children)
+		fam, err := newFamilyWithExternalIDs(famID, statusStr, parents, children, externalIDsData)
 		if err != nil {
 			return nil, NewRepositoryError(err, "failed to create family entity", "CONVERSION_ERROR")
 		}
+		fam.SetOwnerID(ownerID)
 
-		families = append(families, fam)
+		if len(repairs) > 0 {
+			repaired = append(repaired, fam)
+		}
+		if err := result.Add(fam); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, NewRepositoryError(err, "error iterating over family rows", "POSTGRES_ERROR")
 	}
 
-	return families, nil
+	// Write back the canonical form of repaired families
+	rows.Close()
+	r.writeBack(ctx, repaired)
+
+	return result.Families(), nil
 }
 
 // FindByChildID finds the family that contains a specific child
@@ -780,47 +939,66 @@ func (r *PostgresFamilyRepository) FindByChildID(ctx context.Context, childID st
 
 	var famID string
 	var statusStr string
-	var parentsData, childrenData []byte
+	var parentsData, childrenData, externalIDsData []byte
+	var ownerID string
 
 	// Query for both uppercase and lowercase ID fields
-	err := r.DB.QueryRow(ctx, `
-        SELECT id, status, parents, children FROM families 
-        WHERE children @> ANY (ARRAY[jsonb_build_array(jsonb_build_object('id', $1))])
-        OR children @> ANY (ARRAY[jsonb_build_array(jsonb_build_object('ID', $1))])
-    `, childID).Scan(&famID, &statusStr, &parentsData, &childrenData)
+	err := r.protect(ctx, "FindByChildID", func(ctx context.Context) error {
+		err := r.DB.QueryRow(ctx, `
+            SELECT id, status, parents, children, external_ids, owner_id FROM families 
+            WHERE tenant_id = $2 AND deleted_at IS NULL
+            AND (children @> ANY (ARRAY[jsonb_build_array(jsonb_build_object('id', $1))])
+            OR children @> ANY (ARRAY[jsonb_build_array(jsonb_build_object('ID', $1))]))
+        `, childID, tenantOf(ctx)).Scan(&famID, &statusStr, &parentsData, &childrenData, &externalIDsData, &ownerID)
 
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, errors.NewNotFoundError("Family with Child", childID, nil)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errors.NewNotFoundError("Family with Child", childID, nil)
+			}
+			return NewRepositoryError(err, "failed to find family by child ID", "POSTGRES_ERROR")
 		}
-		return nil, NewRepositoryError(err, "failed to find family by child ID", "POSTGRES_ERROR")
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
+	// Repair legacy data if read repair is enabled
+	repairs := r.repairRow(ctx, famID, &statusStr, &parentsData, &childrenData)
+
 	// Define custom structs for JSON unmarshaling to handle both uppercase and lowercase field names
 	type jsonParent struct {
-		ID        string  `json:"ID,omitempty"`
-		Id        string  `json:"id,omitempty"`
-		FirstName string  `json:"FirstName,omitempty"`
-		FirstN    string  `json:"firstName,omitempty"`
-		LastName  string  `json:"LastName,omitempty"`
-		LastN     string  `json:"lastName,omitempty"`
-		BirthDate string  `json:"BirthDate,omitempty"`
-		BirthD    string  `json:"birthDate,omitempty"`
-		DeathDate *string `json:"DeathDate,omitempty"`
-		DeathD    *string `json:"deathDate,omitempty"`
+		ID            string            `json:"ID,omitempty"`
+		Id            string            `json:"id,omitempty"`
+		FirstName     string            `json:"FirstName,omitempty"`
+		FirstN        string            `json:"firstName,omitempty"`
+		LastName      string            `json:"LastName,omitempty"`
+		LastN         string            `json:"lastName,omitempty"`
+		BirthDate     string            `json:"BirthDate,omitempty"`
+		BirthD        string            `json:"birthDate,omitempty"`
+		DeathDate     *string           `json:"DeathDate,omitempty"`
+		DeathD        *string           `json:"deathDate,omitempty"`
+		ExternalIDs   map[string]string `json:"externalIds,omitempty"`
+		PreferredName string            `json:"preferredName,omitempty"`
+		NameHistory   []jsonNameChange  `json:"nameHistory,omitempty"`
 	}
 
 	type jsonChild struct {
-		ID        string  `json:"ID,omitempty"`
-		Id        string  `json:"id,omitempty"`
-		FirstName string  `json:"FirstName,omitempty"`
-		FirstN    string  `json:"firstName,omitempty"`
-		LastName  string  `json:"LastName,omitempty"`
-		LastN     string  `json:"lastName,omitempty"`
-		BirthDate string  `json:"BirthDate,omitempty"`
-		BirthD    string  `json:"birthDate,omitempty"`
-		DeathDate *string `json:"DeathDate,omitempty"`
-		DeathD    *string `json:"deathDate,omitempty"`
+		ID            string            `json:"ID,omitempty"`
+		Id            string            `json:"id,omitempty"`
+		FirstName     string            `json:"FirstName,omitempty"`
+		FirstN        string            `json:"firstName,omitempty"`
+		LastName      string            `json:"LastName,omitempty"`
+		LastN         string            `json:"lastName,omitempty"`
+		BirthDate     string            `json:"BirthDate,omitempty"`
+		BirthD        string            `json:"birthDate,omitempty"`
+		DeathDate     *string           `json:"DeathDate,omitempty"`
+		DeathD        *string           `json:"deathDate,omitempty"`
+		ExternalIDs   map[string]string `json:"externalIds,omitempty"`
+		PreferredName string            `json:"preferredName,omitempty"`
+		NameHistory   []jsonNameChange  `json:"nameHistory,omitempty"`
+		Consents      []jsonConsent     `json:"consents,omitempty"`
 	}
 
 	// Parse parents JSON
@@ -872,6 +1050,12 @@ type jsonChild struct {
 		if err != nil {
 			return nil, NewRepositoryError(err, "failed to create parent entity", "CONVERSION_ERROR")
 		}
+		if err := p.SetExternalIDs(jp.ExternalIDs); err != nil {
+			return nil, NewRepositoryError(err, "invalid parent external IDs", "CONVERSION_ERROR")
+		}
+		if err := setMemberNames(p, jp.PreferredName, jp.NameHistory); err != nil {
+			return nil, NewRepositoryError(err, "invalid parent names", "CONVERSION_ERROR")
+		}
 		parents = append(parents, p)
 	}
 
@@ -924,67 +1108,122 @@ type jsonChild struct {
 		if err != nil {
 			return nil, NewRepositoryError(err, "failed to create child entity", "CONVERSION_ERROR")
 		}
+		if err := c.SetExternalIDs(jc.ExternalIDs); err != nil {
+			return nil, NewRepositoryError(err, "invalid child external IDs", "CONVERSION_ERROR")
+		}
+		if err := setMemberNames(c, jc.PreferredName, jc.NameHistory); err != nil {
+			return nil, NewRepositoryError(err, "invalid child names", "CONVERSION_ERROR")
+		}
+		if err := setChildConsents(c, jc.Consents); err != nil {
+			return nil, NewRepositoryError(err, "invalid child consents", "CONVERSION_ERROR")
+		}
 		children = append(children, c)
 	}
 
 	// Create family entity
-	return entity.NewFamily(famID, entity.Status(statusStr), parents, children)
+	family, err := newFamilyWithExternalIDs(famID, statusStr, parents, children, externalIDsData)
+	if err != nil {
+		return nil, err
+	}
+	family.SetOwnerID(ownerID)
+
+	// Write back the canonical form if the family was repaired
+	if len(repairs) > 0 {
+		r.writeBack(ctx, []*entity.Family{family})
+	}
+
+	return family, nil
 }
 
 // GetAll retrieves all families
 func (r *PostgresFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family, error) {
 	r.logger.Debug(ctx, "Getting all families from PostgreSQL")
 
+	return r.queryFamilies(ctx, "GetAll", "failed to get all families", `
+            SELECT id, status, parents, children, external_ids, owner_id FROM families
+            WHERE tenant_id = $1 AND deleted_at IS NULL
+        `, tenantOf(ctx))
+}
+
+// queryFamilies runs a query that selects family rows and decodes the families. The rows
+// have the id, status, parents, children, external_ids, and owner_id columns, and
+// optionally the version column, without which the families are read without versions.
+func (r *PostgresFamilyRepository) queryFamilies(ctx context.Context, operation, failure, query string, args ...interface{}) ([]*entity.Family, error) {
 	// Ensure table exists
 	if err := r.ensureTableExists(ctx); err != nil {
 		return nil, err
 	}
 
-	rows, err := r.DB.Query(ctx, `
-        SELECT id, status, parents, children FROM families
-    `)
+	var rows pgx.Rows
+	err := r.protect(ctx, operation, func(ctx context.Context) error {
+		var err error
+		rows, err = r.reader(ctx).Query(ctx, query, args...)
+		if err != nil {
+			return NewRepositoryError(err, failure, "POSTGRES_ERROR")
+		}
+		return nil
+	})
 
 	if err != nil {
-		return nil, NewRepositoryError(err, "failed to get all families", "POSTGRES_ERROR")
+		return nil, err
 	}
 	defer rows.Close()
 
-	var families []*entity.Family
+	// Stop reading if the result exceeds the memory budget
+	result := assembly.NewAssembler(r.memoryBudget, operation)
+	var repaired []*entity.Family
+	versioned := len(rows.FieldDescriptions()) > 6
 
 	for rows.Next() {
 		var famID string
 		var statusStr string
-		var parentsData, childrenData []byte
+		var parentsData, childrenData, externalIDsData []byte
+		var ownerID string
+		var version int
 
-		if err := rows.Scan(&famID, &statusStr, &parentsData, &childrenData); err != nil {
+		dest := []interface{}{&famID, &statusStr, &parentsData, &childrenData, &externalIDsData, &ownerID}
+		if versioned {
+			dest = append(dest, &version)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return nil, NewRepositoryError(err, "failed to scan family row", "POSTGRES_ERROR")
 		}
 
+		// Repair legacy data if read repair is enabled
+		repairs := r.repairRow(ctx, famID, &statusStr, &parentsData, &childrenData)
+
 		// Define custom structs for JSON unmarshaling to handle both uppercase and lowercase field names
 		type jsonParent struct {
-			ID        string  `json:"ID,omitempty"`
-			Id        string  `json:"id,omitempty"`
-			FirstName string  `json:"FirstName,omitempty"`
-			FirstN    string  `json:"firstName,omitempty"`
-			LastName  string  `json:"LastName,omitempty"`
-			LastN     string  `json:"lastName,omitempty"`
-			BirthDate string  `json:"BirthDate,omitempty"`
-			BirthD    string  `json:"birthDate,omitempty"`
-			DeathDate *string `json:"DeathDate,omitempty"`
-			DeathD    *string `json:"deathDate,omitempty"`
+			ID            string            `json:"ID,omitempty"`
+			Id            string            `json:"id,omitempty"`
+			FirstName     string            `json:"FirstName,omitempty"`
+			FirstN        string            `json:"firstName,omitempty"`
+			LastName      string            `json:"LastName,omitempty"`
+			LastN         string            `json:"lastName,omitempty"`
+			BirthDate     string            `json:"BirthDate,omitempty"`
+			BirthD        string            `json:"birthDate,omitempty"`
+			DeathDate     *string           `json:"DeathDate,omitempty"`
+			DeathD        *string           `json:"deathDate,omitempty"`
+			ExternalIDs   map[string]string `json:"externalIds,omitempty"`
+			PreferredName string            `json:"preferredName,omitempty"`
+			NameHistory   []jsonNameChange  `json:"nameHistory,omitempty"`
 		}
 
 		type jsonChild struct {
-			ID        string  `json:"ID,omitempty"`
-			Id        string  `json:"id,omitempty"`
-			FirstName string  `json:"FirstName,omitempty"`
-			FirstN    string  `json:"firstName,omitempty"`
-			LastName  string  `json:"LastName,omitempty"`
-			LastN     string  `json:"lastName,omitempty"`
-			BirthDate string  `json:"BirthDate,omitempty"`
-			BirthD    string  `json:"birthDate,omitempty"`
-			DeathDate *string `json:"DeathDate,omitempty"`
-			DeathD    *string `json:"deathDate,omitempty"`
+			ID            string            `json:"ID,omitempty"`
+			Id            string            `json:"id,omitempty"`
+			FirstName     string            `json:"FirstName,omitempty"`
+			FirstN        string            `json:"firstName,omitempty"`
+			LastName      string            `json:"LastName,omitempty"`
+			LastN         string            `json:"lastName,omitempty"`
+			BirthDate     string            `json:"BirthDate,omitempty"`
+			BirthD        string            `json:"birthDate,omitempty"`
+			DeathDate     *string           `json:"DeathDate,omitempty"`
+			DeathD        *string           `json:"deathDate,omitempty"`
+			ExternalIDs   map[string]string `json:"externalIds,omitempty"`
+			PreferredName string            `json:"preferredName,omitempty"`
+			NameHistory   []jsonNameChange  `json:"nameHistory,omitempty"`
+			Consents      []jsonConsent     `json:"consents,omitempty"`
 		}
 
 		// Parse parents JSON
@@ -1036,6 +1275,12 @@ type jsonChild struct {
 			if err != nil {
 				return nil, NewRepositoryError(err, "failed to create parent entity", "CONVERSION_ERROR")
 			}
+			if err := p.SetExternalIDs(jp.ExternalIDs); err != nil {
+				return nil, NewRepositoryError(err, "invalid parent external IDs", "CONVERSION_ERROR")
+			}
+			if err := setMemberNames(p, jp.PreferredName, jp.NameHistory); err != nil {
+				return nil, NewRepositoryError(err, "invalid parent names", "CONVERSION_ERROR")
+			}
 			parents = append(parents, p)
 		}
 
@@ -1088,21 +1333,41 @@ type jsonChild struct {
 			if err != nil {
 				return nil, NewRepositoryError(err, "failed to create child entity", "CONVERSION_ERROR")
 			}
+			if err := c.SetExternalIDs(jc.ExternalIDs); err != nil {
+				return nil, NewRepositoryError(err, "invalid child external IDs", "CONVERSION_ERROR")
+			}
+			if err := setMemberNames(c, jc.PreferredName, jc.NameHistory); err != nil {
+				return nil, NewRepositoryError(err, "invalid child names", "CONVERSION_ERROR")
+			}
+			if err := setChildConsents(c, jc.Consents); err != nil {
+				return nil, NewRepositoryError(err, "invalid child consents", "CONVERSION_ERROR")
+			}
 			children = append(children, c)
 		}
 
 		// Create family entity
-		fam, err := entity.NewFamily(famID, entity.Status(statusStr), parents, children)
+		fam, err := newFamilyWithExternalIDs(famID, statusStr, parents, children, externalIDsData)
 		if err != nil {
 			return nil, NewRepositoryError(err, "failed to create family entity", "CONVERSION_ERROR")
 		}
+		fam.SetOwnerID(ownerID)
+		fam.SetVersion(version)
 
-		families = append(families, fam)
+		if len(repairs) > 0 {
+			repaired = append(repaired, fam)
+		}
+		if err := result.Add(fam); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, NewRepositoryError(err, "error iterating over family rows", "POSTGRES_ERROR")
 	}
 
-	return families, nil
+	// Write back the canonical form of repaired families
+	rows.Close()
+	r.writeBack(ctx, repaired)
+
+	return result.Families(), nil
 }
@@ -0,0 +1,44 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"strings"
+)
+
+// protect runs an operation behind the rate limiter and the circuit breaker of the repository.
+// When both are disabled by configuration, the operation runs directly, so that tests and
+// local development are not affected by rejections or by state left by earlier operations.
+//
+// Parameters:
+//   - ctx: The context of the operation
+//   - operation: The name of the operation, used for logging and metrics
+//   - fn: The operation
+//
+// Returns:
+//   - The error returned by fn, or a repository error if the operation was rejected
+//     by the rate limiter or the circuit breaker
+func (r *PostgresFamilyRepository) protect(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	if r.rateLimiter == nil && r.circuitBreaker == nil {
+		return fn(ctx)
+	}
+
+	var fnErr error
+	err := r.rateLimiter.Execute(ctx, operation, func(ctx context.Context) error {
+		return r.circuitBreaker.Execute(ctx, operation, func(ctx context.Context) error {
+			fnErr = fn(ctx)
+			return fnErr
+		})
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "rate limit exceeded") {
+			return NewRepositoryError(err, "rate limit exceeded", "POSTGRES_ERROR")
+		}
+		return NewRepositoryError(err, "circuit breaker is open", "POSTGRES_ERROR")
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	circuit "github.com/abitofhelp/family-service/infrastructure/adapters/circuitwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	rate "github.com/abitofhelp/family-service/infrastructure/adapters/ratewrapper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestProtect_Disabled tests that operations run directly when the wrappers are disabled
+func TestProtect_Disabled(t *testing.T) {
+	repo := setupTest(t)
+	repo.circuitBreaker = circuit.NewCircuitBreaker("postgres", &config.CircuitConfig{Enabled: false}, zaptest.NewLogger(t))
+	repo.rateLimiter = rate.NewRateLimiter("postgres", &config.RateConfig{Enabled: false}, zaptest.NewLogger(t))
+	require.Nil(t, repo.circuitBreaker)
+	require.Nil(t, repo.rateLimiter)
+
+	// Every call runs, and errors are returned unchanged
+	opErr := stderrors.New("query failed")
+	for i := 0; i < 10; i++ {
+		calls := 0
+		err := repo.protect(context.Background(), "GetAll", func(ctx context.Context) error {
+			calls++
+			return opErr
+		})
+		assert.Same(t, opErr, err)
+		assert.Equal(t, 1, calls)
+	}
+}
+
+// TestProtect_RateLimited tests that operations rejected by the rate limiter fail with a repository error
+func TestProtect_RateLimited(t *testing.T) {
+	repo := setupTest(t)
+	repo.rateLimiter = rate.NewRateLimiter("postgres", &config.RateConfig{
+		Enabled:           true,
+		RequestsPerSecond: 1,
+		BurstSize:         1,
+	}, zaptest.NewLogger(t))
+	require.NotNil(t, repo.rateLimiter)
+
+	// The first operation takes the only token
+	err := repo.protect(context.Background(), "GetAll", func(ctx context.Context) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The second operation is rejected without running
+	err = repo.protect(context.Background(), "GetAll", func(ctx context.Context) error {
+		t.Fatal("operation should not run when the rate limit is exceeded")
+		return nil
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limit exceeded")
+}
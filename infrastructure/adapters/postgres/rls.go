@@ -0,0 +1,138 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/migration"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// TenantSettingName is the PostgreSQL session variable that holds the current tenant ID.
+// Row-level security policies on the families table compare each row's tenant_id
+// against this setting, so cross-tenant rows are never visible to a connection.
+const TenantSettingName = "app.tenant_id"
+
+// Statements of row-level security on the families table. The tenant_id column and its
+// index are part of the schema of the families table, and FORCE ensures the policy also
+// applies to the table owner.
+const (
+	enableRowLevelSecurity = "\n\tALTER TABLE families ENABLE ROW LEVEL SECURITY;\n\t"
+	forceRowLevelSecurity  = "\n\tALTER TABLE families FORCE ROW LEVEL SECURITY;\n\t"
+
+	createTenantIsolationPolicy = `
+	DO $$
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'families' AND policyname = 'families_tenant_isolation') THEN
+			CREATE POLICY families_tenant_isolation ON families
+				USING (tenant_id = current_setting('` + TenantSettingName + `', true))
+				WITH CHECK (tenant_id = current_setting('` + TenantSettingName + `', true));
+		END IF;
+	END
+	$$;
+	`
+)
+
+// rowLevelSecuritySteps are the steps of row-level security on the families table, which
+// are applied after the families steps when row-level security is enabled
+var rowLevelSecuritySteps = []schemaStep{
+	{Step: migration.Step{Name: "Enable row-level security on the families table", Table: "families", DDL: enableRowLevelSecurity, Lock: lockTableCatalog},
+		applied: "SELECT COALESCE((SELECT relrowsecurity FROM pg_class WHERE oid = to_regclass('families')), false)"},
+	{Step: migration.Step{Name: "Force row-level security on the owner of the families table", Table: "families", DDL: forceRowLevelSecurity, Lock: lockTableCatalog},
+		applied: "SELECT COALESCE((SELECT relforcerowsecurity FROM pg_class WHERE oid = to_regclass('families')), false)"},
+	{Step: migration.Step{Name: "Create policy families_tenant_isolation", Table: "families", DDL: createTenantIsolationPolicy, Lock: lockTableCatalog},
+		applied: "SELECT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'families' AND policyname = 'families_tenant_isolation')"},
+}
+
+// rowLevelSecuritySQL applies row-level security to the families table
+var rowLevelSecuritySQL = joinSteps(rowLevelSecuritySteps)
+
+// rowLevelSecurityEnabled reports whether row-level security is enabled in the global configuration
+func rowLevelSecurityEnabled() bool {
+	return globalConfig != nil && globalConfig.Database.Postgres.RowLevelSecurity
+}
+
+// ErrNoMaintenanceRole is returned by operations on the families of every tenant, such as
+// the purge of deleted families, when row-level security is enabled without a maintenance
+// role, since the policy would hide the families of every tenant from them
+var ErrNoMaintenanceRole = errors.New("row-level security is enabled without database.postgres.maintenance_role, which operations on every tenant need")
+
+// maintenanceRole returns the role with BYPASSRLS that operations on the families of every
+// tenant switch to under row-level security, or "" if none is configured
+func maintenanceRole() string {
+	if globalConfig == nil {
+		return ""
+	}
+	return globalConfig.Database.Postgres.MaintenanceRole
+}
+
+// ensureRowLevelSecurity applies the tenant isolation schema and policy to the families table
+func (r *PostgresFamilyRepository) ensureRowLevelSecurity(ctx context.Context) error {
+	r.logger.Debug(ctx, "Ensuring row-level security on families table in PostgreSQL")
+
+	if _, err := r.DB.Exec(ctx, rowLevelSecuritySQL); err != nil {
+		r.logger.Error(ctx, "Failed to apply row-level security to families table in PostgreSQL", zap.Error(err))
+		return NewRepositoryError(err, "failed to apply row-level security to families table", "POSTGRES_ERROR")
+	}
+
+	return nil
+}
+
+// setTenantForConnection sets the tenant session variable on a pooled connection.
+// It is used as the pool's BeforeAcquire hook, so every query issued through the
+// pool runs with the tenant ID of the context that acquired the connection. When
+// the context has no tenant the setting is cleared, which makes the RLS policy
+// hide all tenant rows rather than leak them.
+func setTenantForConnection(ctx context.Context, conn *pgx.Conn) bool {
+	tenantID := servicecontext.GetTenantID(ctx)
+	if _, err := conn.Exec(ctx, "SELECT set_config($1, $2, false)", TenantSettingName, tenantID); err != nil {
+		// Returning false destroys the connection so that it is never used with a stale tenant
+		return false
+	}
+	return true
+}
+
+// TenantAwarePostgresInitializer creates a PostgreSQL connection pool that sets the
+// tenant session variable on every acquired connection.
+//
+// Its signature matches the PostgreSQL initializer accepted by the repository
+// initializers in the diwrapper package, so it can be injected in place of the
+// default initializer when row-level security is enabled.
+//
+// Parameters:
+//   - ctx: Context for establishing and pinging the pool
+//   - dsn: PostgreSQL connection string
+//   - logger: Logger for connection events
+//
+// Returns:
+//   - A *pgxpool.Pool as interface{} if successful
+//   - An error if the pool cannot be created or reached
+func TenantAwarePostgresInitializer(ctx context.Context, dsn string, logger *zap.Logger) (interface{}, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PostgreSQL DSN: %w", err)
+	}
+	poolConfig.BeforeAcquire = setTenantForConnection
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize PostgreSQL connection pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping PostgreSQL: %w", err)
+	}
+
+	if logger != nil {
+		logger.Info("Connected to PostgreSQL with tenant row-level security",
+			zap.String("tenant_setting", TenantSettingName))
+	}
+	return pool, nil
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestRowLevelSecuritySQL tests that the RLS migration enforces the tenant policy
+func TestRowLevelSecuritySQL(t *testing.T) {
+	assert.Contains(t, rowLevelSecuritySQL, "ENABLE ROW LEVEL SECURITY")
+	assert.Contains(t, rowLevelSecuritySQL, "FORCE ROW LEVEL SECURITY")
+	assert.Contains(t, rowLevelSecuritySQL, "current_setting('app.tenant_id', true)")
+	assert.Contains(t, rowLevelSecuritySQL, "WITH CHECK")
+}
+
+// TestRowLevelSecurityDisabledByDefault tests that RLS is off without configuration
+func TestRowLevelSecurityDisabledByDefault(t *testing.T) {
+	assert.False(t, rowLevelSecurityEnabled())
+}
+
+// TestTenantAwarePostgresInitializerInvalidDSN tests that an invalid DSN is rejected
+func TestTenantAwarePostgresInitializerInvalidDSN(t *testing.T) {
+	_, err := TenantAwarePostgresInitializer(context.Background(), "://not-a-dsn", nil)
+	assert.Error(t, err)
+}
+
+// TestRowLevelSecurityIsolatesTenants tests that the tenant setting of the pool and the
+// policy hide the families of other tenants from queries that are not scoped by the
+// repository. It runs against the database of POSTGRES_TEST_DSN, as a role of its own:
+// superusers bypass row-level security.
+func TestRowLevelSecurityIsolatesTenants(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" || os.Getenv("SKIP_POSTGRESQL_TESTS") == "true" {
+		t.Skip("Skipping PostgreSQL row-level security test: POSTGRES_TEST_DSN is not set")
+	}
+	tenantDSN, err := url.Parse(dsn)
+	if err != nil || !strings.HasPrefix(tenantDSN.Scheme, "postgres") {
+		t.Skip("Skipping PostgreSQL row-level security test: POSTGRES_TEST_DSN is not a URL")
+	}
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	// The families of the test are kept in a schema of their own, owned by a role that is
+	// not a superuser
+	admin, err := pgxpool.New(ctx, dsn)
+	require.NoError(t, err)
+	defer admin.Close()
+	suffix := strings.ReplaceAll(uuid.NewString()[:8], "-", "")
+	role, schema, password := "family_rls_"+suffix, "family_rls_"+suffix, uuid.NewString()
+	maintenance := role + "_maintenance"
+	for _, statement := range []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		fmt.Sprintf("CREATE ROLE %s LOGIN PASSWORD '%s'", role, password),
+		fmt.Sprintf("CREATE SCHEMA %s AUTHORIZATION %s", schema, role),
+		fmt.Sprintf("ALTER ROLE %s SET search_path = %s, public", role, schema),
+		// The purge of every tenant switches to a role that bypasses row-level security
+		fmt.Sprintf("CREATE ROLE %s NOLOGIN BYPASSRLS", maintenance),
+		fmt.Sprintf("GRANT %s TO %s", maintenance, role),
+		fmt.Sprintf("GRANT USAGE ON SCHEMA %s TO %s", schema, maintenance),
+	} {
+		_, err := admin.Exec(ctx, statement)
+		require.NoError(t, err, statement)
+	}
+	defer func() {
+		_, _ = admin.Exec(ctx, fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+		_, _ = admin.Exec(ctx, fmt.Sprintf("DROP ROLE %s", maintenance))
+		_, _ = admin.Exec(ctx, fmt.Sprintf("DROP ROLE %s", role))
+	}()
+
+	// The repository applies the policy with the schema of the families table
+	previous := globalConfig
+	globalConfig = &config.Config{}
+	globalConfig.Database.Postgres.RowLevelSecurity = true
+	defer func() { globalConfig = previous }()
+
+	tenantDSN.User = url.UserPassword(role, password)
+	db, err := TenantAwarePostgresInitializer(ctx, tenantDSN.String(), logger)
+	require.NoError(t, err)
+	pool := db.(*pgxpool.Pool)
+	defer pool.Close()
+	repo := NewPostgresFamilyRepository(pool, logging.NewContextLogger(logger))
+
+	acme := servicecontext.WithTenantID(ctx, "acme")
+	globex := servicecontext.WithTenantID(ctx, "globex")
+	newFamily := func() *entity.Family {
+		parent, err := entity.NewParent(generateTestUUID(), "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		require.NoError(t, err)
+		family, err := entity.NewFamily(generateTestUUID(), entity.Single, []*entity.Parent{parent}, []*entity.Child{})
+		require.NoError(t, err)
+		return family
+	}
+	acmeFamily, globexFamily := newFamily(), newFamily()
+	require.NoError(t, repo.Save(acme, acmeFamily))
+	require.NoError(t, repo.Save(globex, globexFamily))
+
+	// familyIDs reads the IDs of every family that a context can see, without the tenant
+	// filter of the repository
+	familyIDs := func(ctx context.Context) []string {
+		rows, err := pool.Query(ctx, "SELECT id FROM families ORDER BY id")
+		require.NoError(t, err)
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+		return ids
+	}
+
+	t.Run("the pool sets the tenant of the context", func(t *testing.T) {
+		var tenant string
+		require.NoError(t, pool.QueryRow(acme, "SELECT current_setting('"+TenantSettingName+"', true)").Scan(&tenant))
+		assert.Equal(t, "acme", tenant)
+		require.NoError(t, pool.QueryRow(ctx, "SELECT current_setting('"+TenantSettingName+"', true)").Scan(&tenant))
+		assert.Empty(t, tenant, "the tenant of a previous context is cleared")
+	})
+
+	t.Run("a tenant sees only its own families", func(t *testing.T) {
+		assert.Equal(t, []string{acmeFamily.ID()}, familyIDs(acme))
+		assert.Equal(t, []string{globexFamily.ID()}, familyIDs(globex))
+		assert.Empty(t, familyIDs(ctx), "a context without a tenant sees no tenant's families")
+	})
+
+	t.Run("a tenant cannot move a family to another tenant", func(t *testing.T) {
+		_, err := pool.Exec(acme, "UPDATE families SET tenant_id = 'globex' WHERE id = $1", acmeFamily.ID())
+		assert.Error(t, err)
+	})
+
+	t.Run("a tenant cannot change the families of another tenant", func(t *testing.T) {
+		tag, err := pool.Exec(acme, "DELETE FROM families WHERE id = $1", globexFamily.ID())
+		require.NoError(t, err)
+		assert.Zero(t, tag.RowsAffected())
+		assert.Equal(t, []string{globexFamily.ID()}, familyIDs(globex))
+	})
+
+	t.Run("the purge of every tenant purges the families of each tenant", func(t *testing.T) {
+		_, err := admin.Exec(ctx, fmt.Sprintf("GRANT SELECT, DELETE ON %s.families TO %s", schema, maintenance))
+		require.NoError(t, err)
+
+		acmeDeleted, globexDeleted := newFamily(), newFamily()
+		require.NoError(t, repo.Save(acme, acmeDeleted))
+		require.NoError(t, repo.Save(globex, globexDeleted))
+		deletedAt := time.Now().Add(-time.Hour)
+		for _, deleted := range []struct {
+			ctx    context.Context
+			family *entity.Family
+		}{{acme, acmeDeleted}, {globex, globexDeleted}} {
+			ok, err := repo.SoftDeleteFamily(deleted.ctx, deleted.family.ID(), deletedAt)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+
+		// Without a maintenance role the purge fails rather than purging nothing
+		_, err = repo.PurgeDeletedFamilies(ctx, time.Now(), true)
+		assert.ErrorIs(t, err, ErrNoMaintenanceRole)
+
+		globalConfig.Database.Postgres.MaintenanceRole = maintenance
+		defer func() { globalConfig.Database.Postgres.MaintenanceRole = "" }()
+		ids, err := repo.PurgeDeletedFamilies(ctx, time.Now(), true)
+		require.NoError(t, err)
+		expected := []string{acmeDeleted.ID(), globexDeleted.ID()}
+		sort.Strings(expected)
+		assert.Equal(t, expected, ids)
+
+		// The families that are not deleted are kept, and the role is reset with the transaction
+		assert.Equal(t, []string{acmeFamily.ID()}, familyIDs(acme))
+		assert.Equal(t, []string{globexFamily.ID()}, familyIDs(globex))
+		var current string
+		require.NoError(t, pool.QueryRow(ctx, "SELECT current_user").Scan(&current))
+		assert.Equal(t, role, current)
+	})
+}
+
+// TestPurgeDeletedFamilies_NoMaintenanceRole tests that a purge of every tenant under
+// row-level security fails without a maintenance role, before it reaches the database
+func TestPurgeDeletedFamilies_NoMaintenanceRole(t *testing.T) {
+	previous := globalConfig
+	globalConfig = &config.Config{}
+	globalConfig.Database.Postgres.RowLevelSecurity = true
+	defer func() { globalConfig = previous }()
+
+	repo := &PostgresFamilyRepository{logger: logging.NewContextLogger(zaptest.NewLogger(t))}
+	_, err := repo.PurgeDeletedFamilies(context.Background(), time.Now(), true)
+	assert.ErrorIs(t, err, ErrNoMaintenanceRole)
+}
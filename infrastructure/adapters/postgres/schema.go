@@ -0,0 +1,269 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/migration"
+	"github.com/jackc/pgx/v5"
+)
+
+// Statements of the schema of the families table. Every statement is idempotent, so that
+// the schema can be brought up to date by running them all, whatever its current state.
+const (
+	createFamiliesTable = `
+	CREATE TABLE IF NOT EXISTS families (
+		id VARCHAR(36) PRIMARY KEY,
+		status VARCHAR(20) NOT NULL,
+		parents JSONB NOT NULL,
+		children JSONB NOT NULL,
+		external_ids JSONB NOT NULL DEFAULT '{}'::jsonb,
+		parent_count INTEGER GENERATED ALWAYS AS (CASE WHEN jsonb_typeof(parents) = 'array' THEN jsonb_array_length(parents) ELSE 0 END) STORED,
+		children_count INTEGER GENERATED ALWAYS AS (CASE WHEN jsonb_typeof(children) = 'array' THEN jsonb_array_length(children) ELSE 0 END) STORED,
+		deleted_at TIMESTAMP WITH TIME ZONE,
+		version INTEGER NOT NULL DEFAULT 1,
+		owner_id TEXT NOT NULL DEFAULT '',
+		tenant_id TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	// Add the external_ids column to tables created before external IDs were supported
+	addExternalIDsColumn = `
+	ALTER TABLE families ADD COLUMN IF NOT EXISTS external_ids JSONB NOT NULL DEFAULT '{}'::jsonb;
+	`
+
+	// Add the parent_count and children_count columns to tables created before the counts
+	// were stored. PostgreSQL maintains them from the parents and children on every write,
+	// so filters on counts do not read the members.
+	addCountColumns = `
+	ALTER TABLE families
+		ADD COLUMN IF NOT EXISTS parent_count INTEGER GENERATED ALWAYS AS (CASE WHEN jsonb_typeof(parents) = 'array' THEN jsonb_array_length(parents) ELSE 0 END) STORED,
+		ADD COLUMN IF NOT EXISTS children_count INTEGER GENERATED ALWAYS AS (CASE WHEN jsonb_typeof(children) = 'array' THEN jsonb_array_length(children) ELSE 0 END) STORED;
+	`
+
+	// Add the deleted_at column, which marks the families that were deleted but are kept,
+	// to tables created before families were soft deleted
+	addDeletedAtColumn = `
+	ALTER TABLE families ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+	`
+
+	// Add the version column, which counts the saves of a family for optimistic concurrency
+	// control, to tables created before families were versioned. Existing rows have version 1.
+	addVersionColumn = `
+	ALTER TABLE families ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+	`
+
+	// Add the owner_id column, which holds the ID of the user who owns a family, to tables
+	// created before families had owners. Existing families have no owner.
+	addOwnerIDColumn = `
+	ALTER TABLE families ADD COLUMN IF NOT EXISTS owner_id TEXT NOT NULL DEFAULT '';
+	`
+
+	createStatusIndex      = "\n\tCREATE INDEX IF NOT EXISTS idx_families_status ON families(status);\n\t"
+	createParentsIndex     = "\n\tCREATE INDEX IF NOT EXISTS idx_families_parents ON families USING GIN (parents);\n\t"
+	createChildrenIndex    = "\n\tCREATE INDEX IF NOT EXISTS idx_families_children ON families USING GIN (children);\n\t"
+	createExternalIDsIndex = "\n\tCREATE INDEX IF NOT EXISTS idx_families_external_ids ON families USING GIN (external_ids);\n\t"
+	createParentCountIndex = "\n\tCREATE INDEX IF NOT EXISTS idx_families_parent_count ON families(parent_count);\n\t"
+	createChildCountIndex  = "\n\tCREATE INDEX IF NOT EXISTS idx_families_children_count ON families(children_count);\n\t"
+
+	// Install pg_trgm, whose trigram indexes serve LIKE patterns that start with a wildcard
+	createTrigramExtension = "\n\tCREATE EXTENSION IF NOT EXISTS pg_trgm;\n\t"
+
+	// family_member_names returns the first, last, and preferred names of the members of a
+	// family, in lower case and one per line, so that a trigram index of it finds the
+	// families with a member whose name contains a string
+	createMemberNamesFunction = `
+	CREATE OR REPLACE FUNCTION family_member_names(parents JSONB, children JSONB)
+	RETURNS TEXT AS $$
+		SELECT COALESCE(lower(string_agg(concat_ws(E'\n',
+			COALESCE(member->>'firstName', member->>'FirstName'),
+			COALESCE(member->>'lastName', member->>'LastName'),
+			member->>'preferredName'), E'\n')), '')
+		FROM jsonb_array_elements(
+			CASE WHEN jsonb_typeof(parents) = 'array' THEN parents ELSE '[]'::jsonb END ||
+			CASE WHEN jsonb_typeof(children) = 'array' THEN children ELSE '[]'::jsonb END) AS member
+	$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+	`
+
+	createMemberNamesIndex = "\n\tCREATE INDEX IF NOT EXISTS idx_families_member_names ON families USING GIN (family_member_names(parents, children) gin_trgm_ops);\n\t"
+
+	createUpdatedAtFunction = `
+	CREATE OR REPLACE FUNCTION update_updated_at_column()
+	RETURNS TRIGGER AS $$
+	BEGIN
+		NEW.updated_at = CURRENT_TIMESTAMP;
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql;
+	`
+
+	createUpdatedAtTrigger = `
+	DO $$
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_families_updated_at') THEN
+			CREATE TRIGGER update_families_updated_at
+			BEFORE UPDATE ON families
+			FOR EACH ROW
+			EXECUTE FUNCTION update_updated_at_column();
+		END IF;
+	END
+	$$;
+	`
+)
+
+// Lock impact of the steps on existing data
+const (
+	lockNewTable     = "None on existing data; the table is created empty"
+	lockAddColumn    = "ACCESS EXCLUSIVE on the table, briefly; the default is not volatile, so rows are not rewritten (PostgreSQL 11+)"
+	lockRewrite      = "ACCESS EXCLUSIVE on the table while every row is rewritten to compute the stored columns; reads and writes wait"
+	lockIndex        = "SHARE on the table for the duration of the index build; reads continue, writes wait"
+	lockFunction     = "None; the function definition is replaced"
+	lockExtension    = "None on existing data; the extension is installed in the database"
+	lockTrigger      = "SHARE ROW EXCLUSIVE on the table, briefly; writes wait"
+	lockTableCatalog = "ACCESS EXCLUSIVE on the table, briefly; reads and writes wait while the catalog is updated"
+)
+
+// schemaStep is a step of the schema with the query that reports whether it has been applied
+type schemaStep struct {
+	migration.Step
+	applied string // Query returning true if the step has nothing left to do
+}
+
+// indexApplied returns a query that reports whether an index exists
+func indexApplied(index string) string {
+	return "SELECT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = '" + index + "')"
+}
+
+// familiesSteps are the steps of the schema of the families table, in the order they are applied
+var familiesSteps = []schemaStep{
+	{Step: migration.Step{Name: "Create the families table", Table: "families", DDL: createFamiliesTable, Lock: lockNewTable},
+		applied: "SELECT to_regclass('families') IS NOT NULL"},
+	{Step: migration.Step{Name: "Add the external_ids column to the families table", Table: "families", DDL: addExternalIDsColumn, Lock: lockAddColumn},
+		// A families table created by the first step already has the column
+		applied: "SELECT to_regclass('families') IS NULL OR EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'families' AND column_name = 'external_ids')"},
+	{Step: migration.Step{Name: "Add the parent_count and children_count columns to the families table", Table: "families", DDL: addCountColumns, Lock: lockRewrite},
+		// A families table created by the first step already has the columns
+		applied: "SELECT to_regclass('families') IS NULL OR (SELECT COUNT(*) FROM information_schema.columns WHERE table_name = 'families' AND column_name IN ('parent_count', 'children_count')) = 2"},
+	{Step: migration.Step{Name: "Add the deleted_at column to the families table", Table: "families", DDL: addDeletedAtColumn, Lock: lockAddColumn},
+		// A families table created by the first step already has the column
+		applied: "SELECT to_regclass('families') IS NULL OR EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'families' AND column_name = 'deleted_at')"},
+	{Step: migration.Step{Name: "Add the version column to the families table", Table: "families", DDL: addVersionColumn, Lock: lockAddColumn},
+		// A families table created by the first step already has the column
+		applied: "SELECT to_regclass('families') IS NULL OR EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'families' AND column_name = 'version')"},
+	{Step: migration.Step{Name: "Add the owner_id column to the families table", Table: "families", DDL: addOwnerIDColumn, Lock: lockAddColumn},
+		// A families table created by the first step already has the column
+		applied: "SELECT to_regclass('families') IS NULL OR EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'families' AND column_name = 'owner_id')"},
+	{Step: migration.Step{Name: "Add the tenant_id column to the families table", Table: "families", DDL: addTenantIDColumn, Lock: lockAddColumn},
+		// A families table created by the first step already has the column
+		applied: "SELECT to_regclass('families') IS NULL OR EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'families' AND column_name = 'tenant_id')"},
+	{Step: migration.Step{Name: "Create index idx_families_tenant_id", Table: "families", DDL: createTenantIDIndex, Lock: lockIndex},
+		applied: indexApplied("idx_families_tenant_id")},
+	{Step: migration.Step{Name: "Create index idx_families_owner_id", Table: "families", DDL: createOwnerIDIndex, Lock: lockIndex},
+		applied: indexApplied("idx_families_owner_id")},
+	{Step: migration.Step{Name: "Create index idx_families_status", Table: "families", DDL: createStatusIndex, Lock: lockIndex},
+		applied: indexApplied("idx_families_status")},
+	{Step: migration.Step{Name: "Create index idx_families_parents", Table: "families", DDL: createParentsIndex, Lock: lockIndex},
+		applied: indexApplied("idx_families_parents")},
+	{Step: migration.Step{Name: "Create index idx_families_children", Table: "families", DDL: createChildrenIndex, Lock: lockIndex},
+		applied: indexApplied("idx_families_children")},
+	{Step: migration.Step{Name: "Create index idx_families_external_ids", Table: "families", DDL: createExternalIDsIndex, Lock: lockIndex},
+		applied: indexApplied("idx_families_external_ids")},
+	{Step: migration.Step{Name: "Create index idx_families_parent_count", Table: "families", DDL: createParentCountIndex, Lock: lockIndex},
+		applied: indexApplied("idx_families_parent_count")},
+	{Step: migration.Step{Name: "Create index idx_families_children_count", Table: "families", DDL: createChildCountIndex, Lock: lockIndex},
+		applied: indexApplied("idx_families_children_count")},
+	{Step: migration.Step{Name: "Install extension pg_trgm", Table: "families", DDL: createTrigramExtension, Lock: lockExtension},
+		applied: "SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm')"},
+	{Step: migration.Step{Name: "Create function family_member_names", Table: "families", DDL: createMemberNamesFunction, Lock: lockFunction},
+		applied: "SELECT EXISTS (SELECT 1 FROM pg_proc WHERE proname = 'family_member_names')"},
+	{Step: migration.Step{Name: "Create index idx_families_member_names", Table: "families", DDL: createMemberNamesIndex, Lock: lockIndex},
+		applied: indexApplied("idx_families_member_names")},
+	{Step: migration.Step{Name: "Create function update_updated_at_column", Table: "families", DDL: createUpdatedAtFunction, Lock: lockFunction},
+		applied: "SELECT EXISTS (SELECT 1 FROM pg_proc WHERE proname = 'update_updated_at_column')"},
+	{Step: migration.Step{Name: "Create trigger update_families_updated_at", Table: "families", DDL: createUpdatedAtTrigger, Lock: lockTrigger},
+		applied: "SELECT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_families_updated_at')"},
+	// The outbox is created with the families table, since units of work store events in it
+	{Step: migration.Step{Name: "Create the event_outbox table", Table: "event_outbox", DDL: createOutboxSchema, Lock: lockNewTable},
+		applied: "SELECT to_regclass('event_outbox') IS NOT NULL"},
+	{Step: migration.Step{Name: "Create the event_outbox_dead_letters table", Table: "event_outbox_dead_letters", DDL: createOutboxDeadLettersSchema, Lock: lockNewTable},
+		applied: "SELECT to_regclass('event_outbox_dead_letters') IS NOT NULL"},
+}
+
+// quarantineSteps are the steps of the quarantine tables
+var quarantineSteps = []schemaStep{
+	{Step: migration.Step{Name: "Create the admin_quarantines table", Table: "admin_quarantines", DDL: createAdminQuarantinesSchema, Lock: lockNewTable},
+		applied: "SELECT to_regclass('admin_quarantines') IS NOT NULL"},
+	{Step: migration.Step{Name: "Create the families_quarantine table", Table: "families_quarantine", DDL: createQuarantineSchema, Lock: lockNewTable},
+		applied: "SELECT to_regclass('families_quarantine') IS NOT NULL"},
+}
+
+// familiesSchema brings the schema of the families table up to date
+var familiesSchema = joinSteps(familiesSteps)
+
+// joinSteps joins the statements of steps into a single script
+func joinSteps(steps []schemaStep) string {
+	var b strings.Builder
+	for _, step := range steps {
+		b.WriteString(step.DDL)
+	}
+	return b.String()
+}
+
+// schemaSteps returns the steps of the schema in the order they are applied, including
+// the steps of row-level security if it is enabled
+func schemaSteps() []schemaStep {
+	steps := append([]schemaStep{}, familiesSteps...)
+	if rowLevelSecurityEnabled() {
+		steps = append(steps, rowLevelSecuritySteps...)
+	}
+	return append(steps, quarantineSteps...)
+}
+
+// Ensure PostgresFamilyRepository implements migration.Planner
+var _ migration.Planner = (*PostgresFamilyRepository)(nil)
+
+// PlanSchema inspects the database and returns the plan of its schema without changing it.
+// The numbers of rows are the planner's estimates, as of the last ANALYZE of each table.
+func (r *PostgresFamilyRepository) PlanSchema(ctx context.Context) (*migration.Plan, error) {
+	plan := &migration.Plan{Backend: "postgres", Rows: make(map[string]int64)}
+	for _, step := range schemaSteps() {
+		var applied bool
+		if err := r.DB.QueryRow(ctx, step.applied).Scan(&applied); err != nil {
+			return nil, NewRepositoryError(err, "failed to inspect the schema", "POSTGRES_ERROR")
+		}
+		step.Step.Applied = applied
+		plan.Steps = append(plan.Steps, step.Step)
+
+		if _, estimated := plan.Rows[step.Table]; estimated {
+			continue
+		}
+		var rows *int64
+		err := r.DB.QueryRow(ctx, "SELECT GREATEST(reltuples, 0)::bigint FROM pg_class WHERE oid = to_regclass($1)", step.Table).Scan(&rows)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, NewRepositoryError(err, "failed to estimate the rows of "+step.Table, "POSTGRES_ERROR")
+		}
+		if rows != nil {
+			plan.Rows[step.Table] = *rows
+		}
+	}
+	return plan, nil
+}
+
+// ApplySchema applies the pending steps of the schema
+func (r *PostgresFamilyRepository) ApplySchema(ctx context.Context) error {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return err
+	}
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return err
+	}
+	if _, err := r.DB.Exec(ctx, createQuarantineSchema); err != nil {
+		return NewRepositoryError(err, "failed to create quarantine table", "POSTGRES_ERROR")
+	}
+	return nil
+}
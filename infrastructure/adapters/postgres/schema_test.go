@@ -0,0 +1,47 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSchemaSteps tests that every step of the schema can be planned and is idempotent
+func TestSchemaSteps(t *testing.T) {
+	steps := append(append(append([]schemaStep{}, familiesSteps...), rowLevelSecuritySteps...), quarantineSteps...)
+	for _, step := range steps {
+		assert.NotEmpty(t, step.Name)
+		assert.NotEmpty(t, step.Table, step.Name)
+		assert.NotEmpty(t, step.Lock, step.Name)
+		assert.True(t, strings.HasPrefix(step.applied, "SELECT "), step.Name)
+
+		ddl := strings.TrimSpace(step.DDL)
+		idempotent := strings.Contains(ddl, "IF NOT EXISTS") || strings.HasPrefix(ddl, "CREATE OR REPLACE") ||
+			strings.Contains(ddl, "ROW LEVEL SECURITY")
+		assert.True(t, idempotent, "step %q must be idempotent", step.Name)
+	}
+
+	// The families table is created with the columns that later steps add to older tables
+	assert.Contains(t, familiesSchema, "external_ids JSONB NOT NULL DEFAULT '{}'::jsonb,")
+	assert.Contains(t, familiesSchema, addExternalIDsColumn)
+	assert.Contains(t, familiesSchema, "parent_count INTEGER GENERATED ALWAYS AS")
+	assert.Contains(t, familiesSchema, "children_count INTEGER GENERATED ALWAYS AS")
+	assert.Contains(t, familiesSchema, addCountColumns)
+	assert.Contains(t, familiesSchema, "deleted_at TIMESTAMP WITH TIME ZONE,")
+	assert.Contains(t, familiesSchema, addDeletedAtColumn)
+	assert.Contains(t, familiesSchema, "version INTEGER NOT NULL DEFAULT 1,")
+	assert.Contains(t, familiesSchema, addVersionColumn)
+	assert.Contains(t, familiesSchema, "owner_id TEXT NOT NULL DEFAULT '',")
+	assert.Contains(t, familiesSchema, addOwnerIDColumn)
+	assert.Contains(t, familiesSchema, "tenant_id TEXT NOT NULL DEFAULT '',")
+	assert.Contains(t, familiesSchema, addTenantIDColumn)
+	assert.Contains(t, familiesSchema, "ON families(tenant_id, id)")
+}
+
+// TestSchemaSteps_RowLevelSecurity tests that row-level security is only planned when enabled
+func TestSchemaSteps_RowLevelSecurity(t *testing.T) {
+	assert.Len(t, schemaSteps(), len(familiesSteps)+len(quarantineSteps))
+}
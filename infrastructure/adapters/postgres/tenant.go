@@ -0,0 +1,32 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+
+	servicecontext "github.com/abitofhelp/servicelib/context"
+)
+
+// Families belong to the tenant of the context they are saved with, whose ID the tenant_id
+// column holds. Every query of the repository is scoped to the tenant of its context, so
+// that a tenant never reads, changes, or counts the families of another tenant; a context
+// without a tenant is scoped to the families saved without one, such as the families saved
+// before families had tenants. Row-level security, where it is enabled, enforces the same
+// isolation in the database.
+const (
+	// Add the tenant_id column to tables created before families had tenants. Existing
+	// families have no tenant.
+	addTenantIDColumn = `
+	ALTER TABLE families ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT '';
+	`
+
+	// The index leads with the tenant, so that the families of a tenant are read from a
+	// range of it, in ID order for the pages of the tenant
+	createTenantIDIndex = "\n\tCREATE INDEX IF NOT EXISTS idx_families_tenant_id ON families(tenant_id, id);\n\t"
+)
+
+// tenantOf returns the ID of the tenant of a context, or "" if it has none
+func tenantOf(ctx context.Context) string {
+	return servicecontext.GetTenantID(ctx)
+}
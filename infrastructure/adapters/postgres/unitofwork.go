@@ -0,0 +1,108 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package postgres
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// A unit of work is a transaction of the repository that its context carries. The
+// operations that change families, their quarantines, or the outbox join the unit of work
+// of their context: those of several statements run in a savepoint of its transaction, so
+// that a failed operation leaves the unit as it was, and their changes are applied when the
+// unit is committed. The other operations read the committed families.
+
+// Ensure PostgresFamilyRepository implements ports.UnitOfWork
+var _ ports.UnitOfWork = (*PostgresFamilyRepository)(nil)
+
+// unitOfWorkKey is the context key of the unit of work of a context
+type unitOfWorkKey struct{}
+
+// unitOfWork is a unit of work of a repository
+type unitOfWork struct {
+	repo *PostgresFamilyRepository
+	tx   pgx.Tx
+}
+
+// unitOfWork returns the unit of work of the repository that the context carries, or nil
+func (r *PostgresFamilyRepository) unitOfWork(ctx context.Context) *unitOfWork {
+	unit, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	if !ok || unit.repo != r {
+		return nil
+	}
+	return unit
+}
+
+// Begin begins a unit of work and returns the context of its operations
+func (r *PostgresFamilyRepository) Begin(ctx context.Context) (context.Context, error) {
+	if r.unitOfWork(ctx) != nil {
+		return nil, NewRepositoryError(nil, "a unit of work is already in progress", "POSTGRES_ERROR")
+	}
+
+	// The schema is ensured before the unit of work, whose statements would otherwise wait
+	// for the locks that the schema statements take
+	if err := r.ensureTableExists(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin unit of work", zap.Error(err))
+		return nil, NewRepositoryError(err, "failed to begin unit of work", "POSTGRES_ERROR")
+	}
+	return context.WithValue(ctx, unitOfWorkKey{}, &unitOfWork{repo: r, tx: tx}), nil
+}
+
+// Commit applies the changes of the unit of work of the context
+func (r *PostgresFamilyRepository) Commit(ctx context.Context) error {
+	unit := r.unitOfWork(ctx)
+	if unit == nil {
+		return NewRepositoryError(nil, "no unit of work is in progress", "POSTGRES_ERROR")
+	}
+	if err := unit.tx.Commit(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to commit unit of work", zap.Error(err))
+		return NewRepositoryError(err, "failed to commit unit of work", "POSTGRES_ERROR")
+	}
+	return nil
+}
+
+// Rollback discards the changes of the unit of work of the context
+func (r *PostgresFamilyRepository) Rollback(ctx context.Context) error {
+	unit := r.unitOfWork(ctx)
+	if unit == nil {
+		return nil
+	}
+	if err := unit.tx.Rollback(ctx); err != nil && !stderrors.Is(err, pgx.ErrTxClosed) {
+		return NewRepositoryError(err, "failed to roll back unit of work", "POSTGRES_ERROR")
+	}
+	return nil
+}
+
+// begin begins the transaction of an operation that changes families: a savepoint in the
+// transaction of the unit of work of the context, or a transaction of its own
+func (r *PostgresFamilyRepository) begin(ctx context.Context) (pgx.Tx, error) {
+	if unit := r.unitOfWork(ctx); unit != nil {
+		return unit.tx.Begin(ctx)
+	}
+	return r.DB.Begin(ctx)
+}
+
+// execer returns the executor of a statement that changes families: the transaction of the
+// unit of work of the context, or the pool
+func (r *PostgresFamilyRepository) execer(ctx context.Context) interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+} {
+	if unit := r.unitOfWork(ctx); unit != nil {
+		return unit.tx
+	}
+	return r.DB
+}
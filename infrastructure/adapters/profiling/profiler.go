@@ -4,6 +4,7 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	httppprof "net/http/pprof"
@@ -116,7 +117,7 @@ func (p *Profiler) StartPprofServer(ctx context.Context, addr string) error {
 	}
 
 	go func() {
-		if err := p.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := p.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			p.logger.Error(ctx, "Pprof HTTP server error", zap.Error(err))
 		}
 	}()
@@ -6,19 +6,49 @@
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
 
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/redis"
+	"github.com/abitofhelp/servicelib/errors"
 	"github.com/abitofhelp/servicelib/logging"
 	"github.com/abitofhelp/servicelib/rate"
 	"go.uber.org/zap"
 )
 
+// Mode values for config.RateConfig.Mode
+const (
+	// ModeLocal keeps a token bucket in each replica
+	ModeLocal = "local"
+
+	// ModeRedis keeps a token bucket in Redis that is shared by all replicas
+	ModeRedis = "redis"
+)
+
+// TokenBucket is a token bucket shared by all replicas of the service
+type TokenBucket interface {
+	// Take takes a token from the named bucket, and reports whether one was available
+	Take(ctx context.Context, name string, requestsPerSecond, burstSize int) (bool, error)
+}
+
 // RateLimiter implements a token bucket rate limiter to protect resources
 // from being overwhelmed by too many requests.
+//
+// With a shared token bucket, the limit applies to all replicas together. While the
+// shared bucket is unavailable, the local token bucket of the replica is used instead.
 type RateLimiter struct {
 	name   string
 	rl     *rate.RateLimiter
 	logger *zap.Logger
+
+	shared            TokenBucket
+	requestsPerSecond int
+	burstSize         int
+	retryInterval     time.Duration
+	localUntil        atomic.Int64 // Unix nanoseconds until which the local bucket is used
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -48,11 +78,69 @@ func NewRateLimiter(name string, cfg *config.RateConfig, logger *zap.Logger) *Ra
 	// Create servicelib rate limiter
 	serviceRL := rate.NewRateLimiter(rateConfig, options)
 
-	return &RateLimiter{
-		name:   name,
-		rl:     serviceRL,
-		logger: logger,
+	rl := &RateLimiter{
+		name:              name,
+		rl:                serviceRL,
+		logger:            logger,
+		requestsPerSecond: cfg.RequestsPerSecond,
+		burstSize:         cfg.BurstSize,
+		retryInterval:     cfg.Redis.RetryInterval,
+	}
+
+	if cfg.Mode == ModeRedis {
+		logger.Info("Sharing rate limiter through Redis",
+			zap.String("name", name),
+			zap.String("address", cfg.Redis.Address))
+		rl.shared = redis.NewTokenBucket(redis.NewClient(&cfg.Redis), cfg.Redis.KeyPrefix)
+	}
+
+	return rl
+}
+
+// takeShared takes a token from the shared token bucket.
+// It returns false for ok if there is no shared bucket or it is unavailable,
+// in which case the local token bucket must be used.
+func (rl *RateLimiter) takeShared(ctx context.Context) (allowed bool, ok bool) {
+	if rl.shared == nil || time.Now().UnixNano() < rl.localUntil.Load() {
+		return false, false
+	}
+
+	allowed, err := rl.shared.Take(ctx, rl.name, rl.requestsPerSecond, rl.burstSize)
+	if err != nil {
+		if ctx.Err() != nil {
+			// The caller gave up; this says nothing about the shared bucket
+			return false, false
+		}
+		rl.localUntil.Store(time.Now().Add(rl.retryInterval).UnixNano())
+		rl.logger.Warn("Shared rate limiter is unavailable, falling back to the local rate limiter",
+			zap.String("name", rl.name),
+			zap.Duration("retry_interval", rl.retryInterval),
+			zap.Error(err))
+		return false, false
+	}
+	return allowed, true
+}
+
+// exceeded returns the error for a request that exceeds the rate limit: a ThrottledError
+// whose message matches the error of the servicelib rate limiter, and whose cause is that
+// error if the servicelib rate limiter rejected the request
+func (rl *RateLimiter) exceeded(operation string, cause error) error {
+	if cause == nil {
+		rl.logger.Warn("Rate limit exceeded, rejecting request",
+			zap.String("rate_limiter", rl.name),
+			zap.String("operation", operation))
+		cause = errors.New(errors.ResourceExhaustedCode, fmt.Sprintf("rate limit exceeded for %s", rl.name))
+	}
+	return domainerrors.NewThrottledError(fmt.Sprintf("rate limit exceeded for %s", rl.name), rl.RetryAfter(), cause)
+}
+
+// RetryAfter returns the delay after which a rejected request may be retried: the time the
+// token bucket takes to refill one token
+func (rl *RateLimiter) RetryAfter() time.Duration {
+	if rl == nil || rl.requestsPerSecond <= 0 {
+		return 0
 	}
+	return time.Second / time.Duration(rl.requestsPerSecond)
 }
 
 // Allow checks if a request should be allowed based on the rate limit
@@ -63,6 +151,9 @@ func (rl *RateLimiter) Allow() bool {
 		return true
 	}
 
+	if allowed, ok := rl.takeShared(context.Background()); ok {
+		return allowed
+	}
 	return rl.rl.Allow()
 }
 
@@ -75,14 +166,26 @@ func (rl *RateLimiter) Execute(ctx context.Context, operation string, fn func(ct
 		return fn(ctx)
 	}
 
+	if allowed, ok := rl.takeShared(ctx); ok {
+		if !allowed {
+			return rl.exceeded(operation, nil)
+		}
+		return fn(ctx)
+	}
+
 	// Create a wrapper function that adapts to servicelib's rate.Execute
+	called := false
 	wrapper := func(ctx context.Context) (interface{}, error) {
+		called = true
 		err := fn(ctx)
 		return nil, err
 	}
 
-	// Execute the function with rate limiting
+	// Execute the function with rate limiting; a request that is rejected never calls it
 	_, err := rate.Execute(ctx, rl.rl, operation, wrapper)
+	if err != nil && !called {
+		return rl.exceeded(operation, err)
+	}
 
 	return err
 }
@@ -96,6 +199,23 @@ func (rl *RateLimiter) ExecuteWithWait(ctx context.Context, operation string, fn
 		return fn(ctx)
 	}
 
+	// Wait for a token from the shared bucket, polling at the refill rate
+	interval := time.Second / time.Duration(rl.requestsPerSecond)
+	for {
+		allowed, ok := rl.takeShared(ctx)
+		if !ok {
+			break
+		}
+		if allowed {
+			return fn(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+
 	// Create a wrapper function that adapts to servicelib's rate.ExecuteWithWait
 	wrapper := func(ctx context.Context) (interface{}, error) {
 		err := fn(ctx)
@@ -126,6 +246,22 @@ func Execute(ctx context.Context, rl *RateLimiter, operation string, fn func(ctx
 		return fn(ctx)
 	}
 
-	// Execute the function with rate limiting using the servicelib rate.Execute
-	return rate.Execute(ctx, rl.rl, operation, fn)
+	if allowed, ok := rl.takeShared(ctx); ok {
+		if !allowed {
+			return false, rl.exceeded(operation, nil)
+		}
+		return fn(ctx)
+	}
+
+	// Execute the function with rate limiting using the servicelib rate.Execute; a request
+	// that is rejected never calls it
+	called := false
+	result, err := rate.Execute(ctx, rl.rl, operation, func(ctx context.Context) (bool, error) {
+		called = true
+		return fn(ctx)
+	})
+	if err != nil && !called {
+		return false, rl.exceeded(operation, err)
+	}
+	return result, err
 }
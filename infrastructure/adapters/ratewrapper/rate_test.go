@@ -4,9 +4,11 @@
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -143,6 +145,12 @@ func TestRateLimiter_Execute_RateLimitExceeded(t *testing.T) {
 	})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "rate limit exceeded")
+
+	// The rejection may be retried once the bucket has refilled a token
+	code, retryAfter, ok := domainerrors.RetryAfter(err)
+	require.True(t, ok)
+	assert.Equal(t, domainerrors.ThrottledCode, code)
+	assert.Equal(t, 100*time.Millisecond, retryAfter)
 }
 
 func TestRateLimiter_ExecuteWithWait(t *testing.T) {
@@ -268,3 +276,81 @@ func TestRateLimiter_NilSafety(t *testing.T) {
 		rl.Reset()
 	})
 }
+
+// fakeBucket is a shared token bucket that holds a fixed number of tokens
+type fakeBucket struct {
+	tokens int
+	err    error
+	takes  int
+}
+
+func (b *fakeBucket) Take(ctx context.Context, name string, requestsPerSecond, burstSize int) (bool, error) {
+	b.takes++
+	if b.err != nil {
+		return false, b.err
+	}
+	if b.tokens == 0 {
+		return false, nil
+	}
+	b.tokens--
+	return true, nil
+}
+
+func TestRateLimiter_SharedBucket(t *testing.T) {
+	cfg := &config.RateConfig{
+		Enabled:           true,
+		RequestsPerSecond: 1000,
+		BurstSize:         1000,
+		Mode:              ModeRedis,
+		Redis:             config.RedisConfig{Address: "127.0.0.1:0", RetryInterval: time.Minute},
+	}
+	rl := NewRateLimiter("test", cfg, zaptest.NewLogger(t))
+	require.NotNil(t, rl)
+	bucket := &fakeBucket{tokens: 1}
+	rl.shared = bucket
+	ctx := context.Background()
+
+	// The shared bucket limits requests even though the local bucket has tokens
+	allowed, err := Execute(ctx, rl, "op", func(ctx context.Context) (bool, error) { return true, nil })
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	_, err = Execute(ctx, rl, "op", func(ctx context.Context) (bool, error) {
+		t.Fatal("the function must not run when the shared rate limit is exceeded")
+		return false, nil
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limit exceeded for test")
+	assert.False(t, rl.Allow())
+	assert.Error(t, rl.Execute(ctx, "op", func(ctx context.Context) error { return nil }))
+	assert.Equal(t, 4, bucket.takes)
+}
+
+func TestRateLimiter_SharedBucketUnavailable(t *testing.T) {
+	cfg := &config.RateConfig{
+		Enabled:           true,
+		RequestsPerSecond: 1000,
+		BurstSize:         1000,
+		Mode:              ModeRedis,
+		Redis:             config.RedisConfig{Address: "127.0.0.1:0", RetryInterval: time.Minute},
+	}
+	rl := NewRateLimiter("test", cfg, zaptest.NewLogger(t))
+	require.NotNil(t, rl)
+	bucket := &fakeBucket{err: errors.New("connection refused")}
+	rl.shared = bucket
+	ctx := context.Background()
+
+	// The local bucket is used while the shared bucket is unavailable
+	err := rl.Execute(ctx, "op", func(ctx context.Context) error { return nil })
+	require.NoError(t, err)
+	assert.True(t, rl.Allow())
+	assert.Equal(t, 1, bucket.takes, "the shared bucket is not retried before the retry interval")
+
+	// The shared bucket is used again after the retry interval
+	bucket.err = nil
+	bucket.tokens = 1
+	rl.localUntil.Store(time.Now().UnixNano())
+	require.NoError(t, rl.ExecuteWithWait(ctx, "op", func(ctx context.Context) error { return nil }))
+	assert.Equal(t, 2, bucket.takes)
+	assert.Equal(t, 0, bucket.tokens)
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package rate
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"go.uber.org/zap"
+)
+
+// idleBucketTTL is how long a subject's bucket is kept after its last request.
+// With any practical rate and burst, a bucket idle this long has refilled completely,
+// so dropping it loses no state.
+const idleBucketTTL = 10 * time.Minute
+
+// State describes a subject's rate limit after a request has been counted.
+type State struct {
+	// Limit is the maximum number of requests a subject can make in a burst
+	Limit int
+
+	// Remaining is the number of requests the subject can still make right now
+	Remaining int
+
+	// Reset is the time at which the subject's allowance will be fully replenished
+	Reset time.Time
+
+	// RetryAfter is how long a rejected subject must wait before a request is allowed
+	RetryAfter time.Duration
+
+	// Allowed reports whether the request was allowed
+	Allowed bool
+
+	// Warning reports whether the subject is close to being rejected
+	Warning bool
+}
+
+// subjectBucket is a token bucket for a single subject
+type subjectBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// SubjectLimiter implements a token bucket rate limiter with a separate bucket per subject,
+// such as an authenticated user or a client IP address. Unlike RateLimiter it reports the
+// state of the bucket on every request, so callers can advise clients before rejecting them.
+type SubjectLimiter struct {
+	name          string
+	rate          float64
+	burst         int
+	warnThreshold float64
+	logger        *zap.Logger
+
+	mu        sync.Mutex
+	buckets   map[string]*subjectBucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// NewSubjectLimiter creates a new per-subject rate limiter
+func NewSubjectLimiter(name string, cfg *config.HTTPRateConfig, logger *zap.Logger) *SubjectLimiter {
+	if !cfg.Enabled {
+		logger.Info("Subject rate limiter is disabled", zap.String("name", name))
+		return nil
+	}
+
+	logger.Info("Initializing subject rate limiter",
+		zap.String("name", name),
+		zap.Int("requests_per_second", cfg.RequestsPerSecond),
+		zap.Int("burst_size", cfg.BurstSize),
+		zap.Float64("warn_threshold", cfg.WarnThreshold))
+
+	return &SubjectLimiter{
+		name:          name,
+		rate:          float64(cfg.RequestsPerSecond),
+		burst:         cfg.BurstSize,
+		warnThreshold: cfg.WarnThreshold,
+		logger:        logger,
+		buckets:       make(map[string]*subjectBucket),
+		now:           time.Now,
+	}
+}
+
+// Take counts a request for the subject and returns the resulting rate limit state.
+// If the subject has no remaining allowance, the request is not counted and the
+// returned state has Allowed set to false.
+func (l *SubjectLimiter) Take(subject string) State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	b, ok := l.buckets[subject]
+	if !ok {
+		b = &subjectBucket{tokens: float64(l.burst), lastSeen: now}
+		l.buckets[subject] = b
+	}
+
+	// Refill the bucket for the time elapsed since the subject's last request
+	elapsed := now.Sub(b.lastSeen).Seconds()
+	b.tokens = math.Min(float64(l.burst), b.tokens+elapsed*l.rate)
+	b.lastSeen = now
+
+	state := State{Limit: l.burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		state.Allowed = true
+	} else {
+		state.RetryAfter = l.durationFor(1 - b.tokens)
+		l.logger.Debug("Subject rate limit exceeded",
+			zap.String("name", l.name),
+			zap.String("subject", subject),
+			zap.Duration("retry_after", state.RetryAfter))
+	}
+
+	state.Remaining = int(b.tokens)
+	state.Reset = now.Add(l.durationFor(float64(l.burst) - b.tokens))
+	state.Warning = float64(state.Remaining) <= l.warnThreshold*float64(l.burst)
+	return state
+}
+
+// durationFor returns the time needed to refill the given number of tokens
+func (l *SubjectLimiter) durationFor(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens / l.rate * float64(time.Second)))
+}
+
+// prune removes buckets that have been idle long enough to refill completely.
+// The caller must hold l.mu.
+func (l *SubjectLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < idleBucketTTL {
+		return
+	}
+	l.lastPrune = now
+
+	for subject, b := range l.buckets {
+		if now.Sub(b.lastSeen) >= idleBucketTTL {
+			delete(l.buckets, subject)
+		}
+	}
+}
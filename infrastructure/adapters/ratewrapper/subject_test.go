@@ -0,0 +1,69 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package rate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestSubjectLimiter(t *testing.T) (*SubjectLimiter, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewSubjectLimiter("test", &config.HTTPRateConfig{
+		Enabled:           true,
+		RequestsPerSecond: 1,
+		BurstSize:         5,
+		WarnThreshold:     0.2,
+	}, zaptest.NewLogger(t))
+	require.NotNil(t, l)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestNewSubjectLimiter_Disabled(t *testing.T) {
+	l := NewSubjectLimiter("test", &config.HTTPRateConfig{Enabled: false}, zaptest.NewLogger(t))
+	assert.Nil(t, l)
+}
+
+func TestSubjectLimiter_Take(t *testing.T) {
+	l, now := newTestSubjectLimiter(t)
+
+	// The burst is consumed, with a warning as the allowance runs low
+	for i := 4; i >= 0; i-- {
+		state := l.Take("user:alice")
+		assert.True(t, state.Allowed)
+		assert.Equal(t, 5, state.Limit)
+		assert.Equal(t, i, state.Remaining)
+		assert.Equal(t, i <= 1, state.Warning)
+	}
+
+	// The next request is rejected until a token is refilled
+	state := l.Take("user:alice")
+	assert.False(t, state.Allowed)
+	assert.Equal(t, time.Second, state.RetryAfter)
+	assert.Equal(t, now.Add(5*time.Second), state.Reset)
+
+	// Other subjects have their own allowance
+	assert.True(t, l.Take("user:bob").Allowed)
+
+	*now = now.Add(time.Second)
+	state = l.Take("user:alice")
+	assert.True(t, state.Allowed)
+	assert.Equal(t, 0, state.Remaining)
+}
+
+func TestSubjectLimiter_PrunesIdleSubjects(t *testing.T) {
+	l, now := newTestSubjectLimiter(t)
+
+	l.Take("user:alice")
+	*now = now.Add(idleBucketTTL)
+	l.Take("user:bob")
+
+	assert.Len(t, l.buckets, 1)
+	assert.Contains(t, l.buckets, "user:bob")
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// CircuitState records open circuits in Redis, so that all replicas of the service
+// back off from a failing dependency once any of them has opened its circuit.
+// An open circuit is a key that expires when the circuit may be closed again.
+type CircuitState struct {
+	client    *Client
+	keyPrefix string
+}
+
+// NewCircuitState creates a new shared circuit state
+//
+// Parameters:
+//   - client: The Redis client
+//   - keyPrefix: The prefix of the keys of the open circuits
+//
+// Returns:
+//   - A new shared circuit state
+func NewCircuitState(client *Client, keyPrefix string) *CircuitState {
+	return &CircuitState{client: client, keyPrefix: keyPrefix}
+}
+
+// Open marks the named circuit as open for the given duration
+func (s *CircuitState) Open(ctx context.Context, name string, duration time.Duration) error {
+	_, err := s.client.Do(ctx, "SET", s.keyPrefix+name, "open", "PX", strconv.FormatInt(duration.Milliseconds(), 10))
+	return err
+}
+
+// OpenFor returns how much longer the named circuit is open, or zero if it is closed
+func (s *CircuitState) OpenFor(ctx context.Context, name string) (time.Duration, error) {
+	ttl, err := Int(s.client.Do(ctx, "PTTL", s.keyPrefix+name))
+	if err != nil {
+		return 0, err
+	}
+	if ttl <= 0 {
+		// -2 means the circuit is closed; -1, a key without an expiry, is not written by Open
+		return 0, nil
+	}
+	return time.Duration(ttl) * time.Millisecond, nil
+}
@@ -0,0 +1,443 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package redis provides a minimal Redis client for state that is shared by the
+// replicas of the service, such as distributed rate limiting and circuit breaker state,
+// and for messages between them, such as cache invalidations.
+//
+// The client speaks the RESP protocol over a bounded pool of connections and supports
+// the commands needed by the service, including Lua scripts through EVALSHA and
+// publishing and subscribing to channels.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+)
+
+// ErrNil is returned when Redis replies with a nil value
+var ErrNil = errors.New("redis: nil reply")
+
+// ErrClosed is returned when a command is issued on a closed client
+var ErrClosed = errors.New("redis: client is closed")
+
+// ErrPoolTimeout is returned when every connection of the pool stays in use for the pool timeout
+var ErrPoolTimeout = errors.New("redis: timed out waiting for a free connection")
+
+// Error is an error reply from Redis, such as a wrong command or a failed script
+type Error string
+
+// Error returns the message of the error reply
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+// Client is a Redis client that is safe for concurrent use. It opens at most the pool size
+// of connections at once; each command holds one of the slots of the pool while it runs.
+type Client struct {
+	address  string
+	password string
+	db       int
+	timeout  time.Duration
+	wait     time.Duration
+	slots    chan struct{}
+
+	mu     sync.Mutex
+	idle   []*conn
+	size   int
+	closed bool
+}
+
+// conn is a connection to Redis with a buffered reader for replies
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// NewClient creates a new Redis client. Connections are opened when they are first needed.
+//
+// Parameters:
+//   - cfg: The Redis configuration
+//
+// Returns:
+//   - A new Redis client
+func NewClient(cfg *config.RedisConfig) *Client {
+	size := cfg.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+	return &Client{
+		address:  cfg.Address,
+		password: cfg.Password,
+		db:       cfg.DB,
+		timeout:  cfg.Timeout,
+		wait:     cfg.PoolTimeout,
+		slots:    make(chan struct{}, size),
+		size:     size,
+	}
+}
+
+// Do sends a command to Redis and returns its reply.
+// Replies are returned as string, int64, []interface{}, or nil for nil replies;
+// error replies are returned as an Error.
+//
+// Parameters:
+//   - ctx: The context of the command, whose deadline bounds the command
+//   - args: The command and its arguments
+//
+// Returns:
+//   - The reply of the command
+//   - An error if the command could not be sent or Redis replied with an error
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.do(ctx, c.timeout, args)
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		// The state of the connection is unknown after an I/O error
+		cn.Close()
+		c.release()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Ping checks that Redis is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Get returns the value of a key
+//
+// Parameters:
+//   - ctx: The context of the command
+//   - key: The key to read
+//
+// Returns:
+//   - The value of the key
+//   - ErrNil if the key does not exist, or an error if the command failed
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	reply, err := c.Do(ctx, "GET", key)
+	if err != nil {
+		return "", err
+	}
+	switch v := reply.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", ErrNil
+	default:
+		return "", fmt.Errorf("redis: unexpected reply type %T for a string", reply)
+	}
+}
+
+// Set sets the value of a key, which expires after ttl; a ttl of zero never expires
+//
+// Parameters:
+//   - ctx: The context of the command
+//   - key: The key to write
+//   - value: The value of the key
+//   - ttl: The time after which the key expires, rounded up to a millisecond
+//
+// Returns:
+//   - An error if the command failed
+func (c *Client) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	args := []string{"SET", key, value}
+	if ttl > 0 {
+		ms := (ttl + time.Millisecond - 1) / time.Millisecond
+		args = append(args, "PX", strconv.FormatInt(int64(ms), 10))
+	}
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+// Del deletes keys
+//
+// Parameters:
+//   - ctx: The context of the command
+//   - keys: The keys to delete
+//
+// Returns:
+//   - The number of keys that were deleted
+//   - An error if the command failed
+func (c *Client) Del(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	return Int(c.Do(ctx, append([]string{"DEL"}, keys...)...))
+}
+
+// Eval runs a Lua script, sending its body only if Redis has not cached it yet
+//
+// Parameters:
+//   - ctx: The context of the command
+//   - script: The script to run
+//   - keys: The keys accessed by the script
+//   - args: The additional arguments of the script
+//
+// Returns:
+//   - The reply of the script
+//   - An error if the script could not be run
+func (c *Client) Eval(ctx context.Context, script *Script, keys []string, args ...string) (interface{}, error) {
+	reply, err := c.Do(ctx, script.command("EVALSHA", script.sha, keys, args)...)
+	var replyErr Error
+	if errors.As(err, &replyErr) && strings.HasPrefix(string(replyErr), "NOSCRIPT") {
+		return c.Do(ctx, script.command("EVAL", script.src, keys, args)...)
+	}
+	return reply, err
+}
+
+// Close closes the idle connections; connections in use are closed when they are returned
+func (c *Client) Close() error {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.closed = true
+	c.mu.Unlock()
+
+	var errs []error
+	for _, cn := range idle {
+		if err := cn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// get takes a slot of the pool, waiting up to the pool timeout for one to be free, and then
+// takes an idle connection or opens a new one
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	if err := c.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		c.release()
+		return nil, ErrClosed
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+
+	cn, err := c.dial(ctx)
+	if err != nil {
+		c.release()
+		return nil, err
+	}
+	return cn, nil
+}
+
+// acquire takes a slot of the pool, within the earlier of the context deadline and the pool timeout
+func (c *Client) acquire(ctx context.Context) error {
+	select {
+	case c.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	var timeout <-chan time.Time
+	if c.wait > 0 {
+		timer := time.NewTimer(c.wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case c.slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return ErrPoolTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot of the pool
+func (c *Client) release() {
+	<-c.slots
+}
+
+// put returns a connection to the pool, closing it if the client is closed, and frees its slot
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	if c.closed || len(c.idle) >= c.size {
+		c.mu.Unlock()
+		cn.Close()
+		c.release()
+		return
+	}
+	c.idle = append(c.idle, cn)
+	c.mu.Unlock()
+	c.release()
+}
+
+// dial opens a new connection, authenticating and selecting the database if configured
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	nc, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to connect to %s: %w", c.address, err)
+	}
+	cn := &conn{Conn: nc, reader: bufio.NewReader(nc)}
+
+	if c.password != "" {
+		if _, err := cn.do(ctx, c.timeout, []string{"AUTH", c.password}); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("redis: failed to authenticate: %w", err)
+		}
+	}
+	if c.db != 0 {
+		if _, err := cn.do(ctx, c.timeout, []string{"SELECT", strconv.Itoa(c.db)}); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("redis: failed to select database %d: %w", c.db, err)
+		}
+	}
+	return cn, nil
+}
+
+// do writes a command and reads its reply, within the earlier of the context deadline and the timeout
+func (cn *conn) do(ctx context.Context, timeout time.Duration, args []string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if timeout > 0 && (!ok || time.Until(deadline) > timeout) {
+		deadline, ok = time.Now().Add(timeout), true
+	}
+	if ok {
+		if err := cn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	if _, err := io.WriteString(cn, b.String()); err != nil {
+		return nil, err
+	}
+	return readReply(cn.reader)
+}
+
+// readReply reads a RESP reply
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, Error(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk length %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed array length %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			// Error replies nested in arrays are returned as values
+			item, err := readReply(r)
+			var replyErr Error
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+			if err != nil {
+				item = replyErr
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+	}
+}
+
+// Int converts a reply to an integer
+//
+// Parameters:
+//   - reply: The reply of a command
+//   - err: The error of the command, which is returned unchanged
+//
+// Returns:
+//   - The integer value of the reply
+//   - An error if the command failed or the reply is not an integer
+func Int(reply interface{}, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	switch v := reply.(type) {
+	case int64:
+		return v, nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case nil:
+		return 0, ErrNil
+	default:
+		return 0, fmt.Errorf("redis: unexpected reply type %T for an integer", reply)
+	}
+}
+
+// Script is a Lua script that is run with Eval
+type Script struct {
+	src string
+	sha string
+}
+
+// NewScript creates a new script from its Lua source
+func NewScript(src string) *Script {
+	sum := sha1.Sum([]byte(src))
+	return &Script{src: src, sha: hex.EncodeToString(sum[:])}
+}
+
+// command builds the arguments of an EVAL or EVALSHA command
+func (s *Script) command(name, script string, keys, args []string) []string {
+	cmd := make([]string, 0, 3+len(keys)+len(args))
+	cmd = append(cmd, name, script, strconv.Itoa(len(keys)))
+	cmd = append(cmd, keys...)
+	return append(cmd, args...)
+}
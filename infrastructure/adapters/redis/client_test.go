@@ -0,0 +1,337 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package redis
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer is a Redis server that answers commands with a handler
+type fakeServer struct {
+	listener net.Listener
+	handler  func(args []string) string
+
+	mu       sync.Mutex
+	commands [][]string
+	conns    int
+}
+
+// newFakeServer starts a fake Redis server that is stopped when the test ends
+func newFakeServer(t *testing.T, handler func(args []string) string) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeServer{listener: listener, handler: handler}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+// serve reads commands from a connection and writes the replies of the handler
+func (s *fakeServer) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, n)
+		for i := range args {
+			header, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+			arg := make([]byte, size+2)
+			if _, err := io.ReadFull(r, arg); err != nil {
+				return
+			}
+			args[i] = string(arg[:size])
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, args)
+		s.mu.Unlock()
+		if _, err := c.Write([]byte(s.handler(args))); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeServer) config() *config.RedisConfig {
+	return &config.RedisConfig{Address: s.listener.Addr().String(), Timeout: time.Second, PoolSize: 2}
+}
+
+func TestClient_Do(t *testing.T) {
+	server := newFakeServer(t, func(args []string) string {
+		switch args[0] {
+		case "PING":
+			return "+PONG\r\n"
+		case "GET":
+			if args[1] == "missing" {
+				return "$-1\r\n"
+			}
+			return "$5\r\nvalue\r\n"
+		case "INCR":
+			return ":42\r\n"
+		case "MGET":
+			return "*2\r\n$1\r\na\r\n$-1\r\n"
+		default:
+			return "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+	})
+	client := NewClient(server.config())
+	defer client.Close()
+	ctx := context.Background()
+
+	require.NoError(t, client.Ping(ctx))
+
+	reply, err := client.Do(ctx, "GET", "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", reply)
+
+	reply, err = client.Do(ctx, "GET", "missing")
+	require.NoError(t, err)
+	assert.Nil(t, reply)
+
+	n, err := Int(client.Do(ctx, "INCR", "counter"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), n)
+
+	reply, err = client.Do(ctx, "MGET", "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", nil}, reply)
+
+	_, err = client.Do(ctx, "BOGUS")
+	var replyErr Error
+	require.ErrorAs(t, err, &replyErr)
+	assert.Equal(t, "redis: ERR unknown command 'BOGUS'", err.Error())
+
+	// Error replies do not close the connection
+	require.NoError(t, client.Ping(ctx))
+	server.mu.Lock()
+	assert.Equal(t, 1, server.conns)
+	server.mu.Unlock()
+}
+
+func TestClient_GetSetDel(t *testing.T) {
+	server := newFakeServer(t, func(args []string) string {
+		switch args[0] {
+		case "GET":
+			if args[1] == "missing" {
+				return "$-1\r\n"
+			}
+			return "$5\r\nvalue\r\n"
+		case "SET":
+			return "+OK\r\n"
+		case "DEL":
+			return ":" + strconv.Itoa(len(args)-1) + "\r\n"
+		default:
+			return "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+	})
+	client := NewClient(server.config())
+	defer client.Close()
+	ctx := context.Background()
+
+	value, err := client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	_, err = client.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNil)
+
+	require.NoError(t, client.Set(ctx, "key", "value", 1500*time.Microsecond))
+	require.NoError(t, client.Set(ctx, "forever", "value", 0))
+
+	n, err := client.Del(ctx, "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	// Deleting no keys sends no command
+	n, err = client.Del(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	server.mu.Lock()
+	assert.Equal(t, [][]string{
+		{"GET", "key"},
+		{"GET", "missing"},
+		{"SET", "key", "value", "PX", "2"},
+		{"SET", "forever", "value"},
+		{"DEL", "a", "b"},
+	}, server.commands)
+	server.mu.Unlock()
+}
+
+func TestClient_AuthAndSelect(t *testing.T) {
+	server := newFakeServer(t, func(args []string) string {
+		if args[0] == "AUTH" && args[1] != "secret" {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		return "+OK\r\n"
+	})
+	cfg := server.config()
+	cfg.Password = "secret"
+	cfg.DB = 3
+	client := NewClient(cfg)
+	defer client.Close()
+
+	require.NoError(t, client.Ping(context.Background()))
+	server.mu.Lock()
+	assert.Equal(t, [][]string{{"AUTH", "secret"}, {"SELECT", "3"}, {"PING"}}, server.commands)
+	server.mu.Unlock()
+
+	cfg.Password = "wrong"
+	_, err := NewClient(cfg).Do(context.Background(), "PING")
+	assert.ErrorContains(t, err, "failed to authenticate")
+}
+
+func TestClient_Unavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	client := NewClient(&config.RedisConfig{Address: address, Timeout: time.Second})
+	assert.Error(t, client.Ping(context.Background()))
+
+	require.NoError(t, client.Close())
+	assert.ErrorIs(t, client.Ping(context.Background()), ErrClosed)
+}
+
+func TestClient_PoolSize(t *testing.T) {
+	unblock := make(chan struct{})
+	server := newFakeServer(t, func(args []string) string {
+		if args[0] == "BLOCK" {
+			<-unblock
+		}
+		return "+OK\r\n"
+	})
+	cfg := server.config()
+	cfg.PoolTimeout = 50 * time.Millisecond
+	client := NewClient(cfg)
+	defer client.Close()
+	ctx := context.Background()
+
+	// Both connections of the pool are in use
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Do(ctx, "BLOCK")
+			assert.NoError(t, err)
+		}()
+	}
+	require.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.commands) == 2
+	}, time.Second, 5*time.Millisecond)
+
+	// A third command waits for a free connection instead of opening one
+	assert.ErrorIs(t, client.Ping(ctx), ErrPoolTimeout)
+
+	done := make(chan error, 1)
+	go func() { done <- client.Ping(ctx) }()
+	unblock <- struct{}{}
+	require.NoError(t, <-done)
+	close(unblock)
+	wg.Wait()
+
+	server.mu.Lock()
+	assert.Equal(t, 2, server.conns)
+	server.mu.Unlock()
+}
+
+func TestTokenBucket_Take(t *testing.T) {
+	script := ""
+	server := newFakeServer(t, func(args []string) string {
+		switch args[0] {
+		case "EVALSHA":
+			if script == "" {
+				return "-NOSCRIPT No matching script. Please use EVAL.\r\n"
+			}
+			return ":0\r\n"
+		case "EVAL":
+			script = args[1]
+			return ":1\r\n"
+		}
+		return "-ERR unexpected command\r\n"
+	})
+	client := NewClient(server.config())
+	defer client.Close()
+	bucket := NewTokenBucket(client, "family-service:rate:")
+
+	// The script is loaded on first use
+	allowed, err := bucket.Take(context.Background(), "mongodb", 100, 50)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = bucket.Take(context.Background(), "mongodb", 100, 50)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	require.Len(t, server.commands, 3)
+	assert.Equal(t, []string{"EVALSHA", tokenBucketScript.sha, "1", "family-service:rate:mongodb", "100", "50"}, server.commands[0])
+	assert.Equal(t, tokenBucketScript.src, server.commands[1][1])
+	assert.Equal(t, "EVALSHA", server.commands[2][0])
+}
+
+func TestCircuitState(t *testing.T) {
+	server := newFakeServer(t, func(args []string) string {
+		switch {
+		case args[0] == "SET":
+			return "+OK\r\n"
+		case args[0] == "PTTL" && args[1] == "family-service:circuit:postgres":
+			return ":2500\r\n"
+		case args[0] == "PTTL":
+			return ":-2\r\n"
+		}
+		return "-ERR unexpected command\r\n"
+	})
+	client := NewClient(server.config())
+	defer client.Close()
+	state := NewCircuitState(client, "family-service:circuit:")
+	ctx := context.Background()
+
+	require.NoError(t, state.Open(ctx, "postgres", 10*time.Second))
+	server.mu.Lock()
+	assert.Equal(t, []string{"SET", "family-service:circuit:postgres", "open", "PX", "10000"}, server.commands[0])
+	server.mu.Unlock()
+
+	remaining, err := state.OpenFor(ctx, "postgres")
+	require.NoError(t, err)
+	assert.Equal(t, 2500*time.Millisecond, remaining)
+
+	remaining, err = state.OpenFor(ctx, "mongodb")
+	require.NoError(t, err)
+	assert.Zero(t, remaining)
+}
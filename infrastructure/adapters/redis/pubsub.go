@@ -0,0 +1,91 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Subscription is a subscription to a Redis channel on a dedicated connection
+type Subscription struct {
+	cn *conn
+}
+
+// Publish publishes a message on a channel
+//
+// Parameters:
+//   - ctx: The context of the command
+//   - channel: The channel to publish on
+//   - message: The message to publish
+//
+// Returns:
+//   - The number of subscribers that received the message
+//   - An error if the message could not be published
+func (c *Client) Publish(ctx context.Context, channel, message string) (int64, error) {
+	return Int(c.Do(ctx, "PUBLISH", channel, message))
+}
+
+// Subscribe subscribes to a channel on a new connection, which is not part of the pool.
+// It returns once Redis has confirmed the subscription.
+//
+// Parameters:
+//   - ctx: The context of the subscription, whose deadline bounds subscribing
+//   - channel: The channel to subscribe to
+//
+// Returns:
+//   - The subscription, which must be closed when it is no longer needed
+//   - An error if the subscription failed
+func (c *Client) Subscribe(ctx context.Context, channel string) (*Subscription, error) {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return nil, ErrClosed
+	}
+
+	cn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := cn.do(ctx, c.timeout, []string{"SUBSCRIBE", channel})
+	if err == nil {
+		if items, ok := reply.([]interface{}); !ok || len(items) != 3 || items[0] != "subscribe" {
+			err = fmt.Errorf("redis: unexpected reply %v to SUBSCRIBE", reply)
+		}
+	}
+	if err == nil {
+		// Messages arrive whenever they are published
+		err = cn.SetDeadline(time.Time{})
+	}
+	if err != nil {
+		cn.Close()
+		return nil, fmt.Errorf("redis: failed to subscribe to %s: %w", channel, err)
+	}
+	return &Subscription{cn: cn}, nil
+}
+
+// Receive waits for the next message published on the channel. It fails when the
+// connection fails or the subscription is closed.
+func (s *Subscription) Receive() (string, error) {
+	for {
+		reply, err := readReply(s.cn.reader)
+		if err != nil {
+			return "", err
+		}
+		// Confirmations and other replies are skipped
+		items, ok := reply.([]interface{})
+		if !ok || len(items) != 3 || items[0] != "message" {
+			continue
+		}
+		if message, ok := items[2].(string); ok {
+			return message, nil
+		}
+	}
+}
+
+// Close closes the subscription and its connection
+func (s *Subscription) Close() error {
+	return s.cn.Close()
+}
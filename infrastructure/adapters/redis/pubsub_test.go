@@ -0,0 +1,62 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package redis
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_PublishSubscribe(t *testing.T) {
+	server := newFakeServer(t, func(args []string) string {
+		switch args[0] {
+		case "PUBLISH":
+			return ":2\r\n"
+		case "SUBSCRIBE":
+			// The confirmation is followed by a message published on the channel
+			return "*3\r\n$9\r\nsubscribe\r\n$" + strconv.Itoa(len(args[1])) + "\r\n" + args[1] + "\r\n:1\r\n" +
+				"*3\r\n$7\r\nmessage\r\n$" + strconv.Itoa(len(args[1])) + "\r\n" + args[1] + "\r\n$5\r\nhello\r\n"
+		default:
+			return "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+	})
+	client := NewClient(server.config())
+	defer client.Close()
+	ctx := context.Background()
+
+	receivers, err := client.Publish(ctx, "events", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), receivers)
+
+	sub, err := client.Subscribe(ctx, "events")
+	require.NoError(t, err)
+	message, err := sub.Receive()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", message)
+
+	// Closing the subscription ends Receive
+	require.NoError(t, sub.Close())
+	_, err = sub.Receive()
+	assert.Error(t, err)
+
+	// Subscriptions are not made on closed clients
+	require.NoError(t, client.Close())
+	_, err = client.Subscribe(ctx, "events")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestClient_SubscribeRejected(t *testing.T) {
+	server := newFakeServer(t, func(args []string) string {
+		return "-NOPERM this user has no permissions to access the 'events' channel\r\n"
+	})
+	client := NewClient(server.config())
+	defer client.Close()
+
+	_, err := client.Subscribe(context.Background(), "events")
+	var replyErr Error
+	assert.ErrorAs(t, err, &replyErr)
+}
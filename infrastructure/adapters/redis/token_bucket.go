@@ -0,0 +1,75 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package redis
+
+import (
+	"context"
+	"strconv"
+)
+
+// tokenBucketScript takes a token from the bucket at KEYS[1], refilling it at ARGV[1]
+// tokens per second up to ARGV[2] tokens. It returns 1 if a token was taken and 0 otherwise.
+// The time of the Redis server is used, so that the clocks of the replicas do not matter.
+var tokenBucketScript = NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return allowed
+`)
+
+// TokenBucket is a token bucket kept in Redis and shared by all replicas of the service
+type TokenBucket struct {
+	client    *Client
+	keyPrefix string
+}
+
+// NewTokenBucket creates a new token bucket
+//
+// Parameters:
+//   - client: The Redis client
+//   - keyPrefix: The prefix of the keys of the buckets
+//
+// Returns:
+//   - A new token bucket
+func NewTokenBucket(client *Client, keyPrefix string) *TokenBucket {
+	return &TokenBucket{client: client, keyPrefix: keyPrefix}
+}
+
+// Take takes a token from the named bucket, which is created full if it does not exist
+//
+// Parameters:
+//   - ctx: The context of the operation
+//   - name: The name of the bucket
+//   - requestsPerSecond: The rate at which the bucket is refilled
+//   - burstSize: The capacity of the bucket
+//
+// Returns:
+//   - Whether a token was taken
+//   - An error if Redis could not be reached or the script failed
+func (b *TokenBucket) Take(ctx context.Context, name string, requestsPerSecond, burstSize int) (bool, error) {
+	allowed, err := Int(b.client.Eval(ctx, tokenBucketScript, []string{b.keyPrefix + name},
+		strconv.Itoa(requestsPerSecond), strconv.Itoa(burstSize)))
+	if err != nil {
+		return false, err
+	}
+	return allowed == 1, nil
+}
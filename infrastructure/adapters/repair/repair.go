@@ -0,0 +1,364 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package repair provides tolerant reading of inconsistent legacy family data.
+//
+// Earlier versions of the service, and data imported from other systems, stored
+// members with mixed-case or snake_case keys, dates in formats other than RFC3339,
+// dates with a time of day, and families without a status. Reading such data fails in strict mode. The
+// functions in this package repair what they can by normalizing keys and values
+// and defaulting missing optional fields, and report each repair so that the
+// repositories can log and count them and, if configured, write back the
+// canonical form.
+package repair
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/metrics"
+	"github.com/abitofhelp/servicelib/logging"
+	"go.uber.org/zap"
+)
+
+// Kind classifies a repair
+type Kind string
+
+const (
+	// KindKeyNormalized is a key renamed to its canonical form
+	KindKeyNormalized Kind = "key_normalized"
+
+	// KindValueNormalized is a value rewritten in its canonical form
+	KindValueNormalized Kind = "value_normalized"
+
+	// KindDateReformatted is a date converted to RFC3339
+	KindDateReformatted Kind = "date_reformatted"
+
+	// KindDateTruncated is a timestamp with a time of day or a time zone offset reduced
+	// to midnight UTC of its calendar date, since birth and death dates are calendar dates
+	KindDateTruncated Kind = "date_truncated"
+
+	// KindDefaultApplied is a missing or empty optional field set to its default
+	KindDefaultApplied Kind = "default_applied"
+)
+
+// Repair describes a single change made to legacy data
+type Repair struct {
+	Kind   Kind   // Kind of repair
+	Path   string // Location of the repaired field, for example "parents[0].firstName"
+	Detail string // Description of the change
+}
+
+// String returns a description of the repair for logging
+func (r Repair) String() string {
+	return fmt.Sprintf("%s %s: %s", r.Kind, r.Path, r.Detail)
+}
+
+// KeyStyle selects the canonical member keys used by a backend
+type KeyStyle int
+
+const (
+	// CamelCase keys (id, firstName, ...) are used by PostgreSQL and MongoDB
+	CamelCase KeyStyle = iota
+
+	// PascalCase keys (ID, FirstName, ...) are used by the JSON member blobs in SQLite
+	PascalCase
+)
+
+// memberKeys are the canonical member keys for each key style
+var memberKeys = map[KeyStyle]struct {
+	id, firstName, lastName, birthDate, deathDate, externalIDs string
+}{
+	CamelCase:  {"id", "firstName", "lastName", "birthDate", "deathDate", "externalIds"},
+	PascalCase: {"ID", "FirstName", "LastName", "BirthDate", "DeathDate", "ExternalIDs"},
+}
+
+// dateLayouts are the legacy date formats that are converted to RFC3339.
+// The calendar date is kept as written; dates without a zone are interpreted as UTC.
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"01/02/2006",
+}
+
+// normalizeKey folds a key to lowercase without separators for comparison
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(key))
+}
+
+// Keys renames the keys of m that differ from one of the canonical keys only in case or
+// separators (for example "FirstName" or "first_name" for "firstName"). If both a
+// canonical key and a variant are present, the canonical value is kept unless it is empty.
+// Keys that match no canonical key are kept unchanged.
+func Keys(m map[string]interface{}, canonical []string, path string) (map[string]interface{}, []Repair) {
+	lookup := make(map[string]string, len(canonical))
+	for _, key := range canonical {
+		lookup[normalizeKey(key)] = key
+	}
+
+	// Iterate in sorted order so that repairs are deterministic
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]interface{}, len(m))
+	var repairs []Repair
+	for _, key := range keys {
+		value := m[key]
+		target, ok := lookup[normalizeKey(key)]
+		if !ok || target == key {
+			if existing, exists := result[key]; !exists || isEmpty(existing) {
+				result[key] = value
+			}
+			continue
+		}
+
+		repairs = append(repairs, Repair{Kind: KindKeyNormalized, Path: join(path, target), Detail: fmt.Sprintf("renamed %q", key)})
+		if existing, exists := result[target]; exists && !isEmpty(existing) {
+			continue
+		}
+		if canonicalValue, exists := m[target]; exists && !isEmpty(canonicalValue) {
+			continue
+		}
+		result[target] = value
+	}
+	return result, repairs
+}
+
+// Member repairs a parent or child document. It normalizes keys to the given style,
+// trims names, converts dates to RFC3339, and removes empty optional fields.
+// It returns false if v is not a document.
+func Member(v interface{}, style KeyStyle, path string) (map[string]interface{}, []Repair, bool) {
+	m, ok := asMap(v)
+	if !ok {
+		return nil, nil, false
+	}
+
+	k := memberKeys[style]
+	m, repairs := Keys(m, []string{k.id, k.firstName, k.lastName, k.birthDate, k.deathDate, k.externalIDs}, path)
+
+	for _, key := range []string{k.id, k.firstName, k.lastName} {
+		if s, ok := m[key].(string); ok && s != strings.TrimSpace(s) {
+			m[key] = strings.TrimSpace(s)
+			repairs = append(repairs, Repair{Kind: KindValueNormalized, Path: join(path, key), Detail: "trimmed whitespace"})
+		}
+	}
+
+	for _, key := range []string{k.birthDate, k.deathDate} {
+		value, exists := m[key]
+		if !exists || value == nil {
+			continue
+		}
+		if s, ok := value.(string); ok && strings.TrimSpace(s) == "" && key == k.deathDate {
+			delete(m, key)
+			repairs = append(repairs, Repair{Kind: KindDefaultApplied, Path: join(path, key), Detail: "removed empty date"})
+			continue
+		}
+		if formatted, kind, changed := formatDate(value); changed {
+			m[key] = formatted
+			detail := fmt.Sprintf("converted %v to RFC3339", value)
+			if kind == KindDateTruncated {
+				detail = fmt.Sprintf("truncated %v to its calendar date %s", value, formatted)
+			}
+			repairs = append(repairs, Repair{Kind: kind, Path: join(path, key), Detail: detail})
+		}
+	}
+
+	if value, exists := m[k.externalIDs]; exists && value == nil {
+		delete(m, k.externalIDs)
+		repairs = append(repairs, Repair{Kind: KindDefaultApplied, Path: join(path, k.externalIDs), Detail: "removed null external IDs"})
+	}
+
+	return m, repairs, true
+}
+
+// Members repairs a list of parent or child documents. A missing list defaults to an empty list.
+// Elements that are not documents are kept unchanged and fail when they are decoded.
+func Members(v interface{}, style KeyStyle, path string) ([]interface{}, []Repair) {
+	if v == nil {
+		return []interface{}{}, []Repair{{Kind: KindDefaultApplied, Path: path, Detail: "defaulted missing list to empty"}}
+	}
+
+	list, ok := asSlice(v)
+	if !ok {
+		return nil, nil
+	}
+
+	result := make([]interface{}, 0, len(list))
+	var repairs []Repair
+	for i, element := range list {
+		member, memberRepairs, ok := Member(element, style, fmt.Sprintf("%s[%d]", path, i))
+		if !ok {
+			result = append(result, element)
+			continue
+		}
+		result = append(result, member)
+		repairs = append(repairs, memberRepairs...)
+	}
+	return result, repairs
+}
+
+// MembersJSON repairs a JSON array of parent or child documents. The data is returned
+// unchanged if it needs no repairs or is not a JSON array.
+func MembersJSON(data []byte, style KeyStyle, path string) ([]byte, []Repair, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" || trimmed == "null" {
+		return []byte("[]"), []Repair{{Kind: KindDefaultApplied, Path: path, Detail: "defaulted missing list to empty"}}, nil
+	}
+	if !strings.HasPrefix(trimmed, "[") {
+		return data, nil, nil
+	}
+
+	var list []interface{}
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	if err := decoder.Decode(&list); err != nil {
+		return nil, nil, err
+	}
+
+	repaired, repairs := Members(list, style, path)
+	if len(repairs) == 0 {
+		return data, nil, nil
+	}
+
+	result, err := json.Marshal(repaired)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, repairs, nil
+}
+
+// Status repairs a family status. Statuses are converted to upper case, and a missing
+// status is derived from the number of parents (SINGLE for one, MARRIED for two).
+// A missing status that cannot be derived is returned unchanged.
+func Status(status string, parentCount int) (string, []Repair) {
+	normalized := strings.ToUpper(strings.TrimSpace(status))
+	if normalized == "" {
+		switch parentCount {
+		case 1:
+			return string(entity.Single), []Repair{{Kind: KindDefaultApplied, Path: "status", Detail: "derived SINGLE from one parent"}}
+		case 2:
+			return string(entity.Married), []Repair{{Kind: KindDefaultApplied, Path: "status", Detail: "derived MARRIED from two parents"}}
+		default:
+			return status, nil
+		}
+	}
+	if normalized != status {
+		return normalized, []Repair{{Kind: KindValueNormalized, Path: "status", Detail: fmt.Sprintf("converted %q to %q", status, normalized)}}
+	}
+	return status, nil
+}
+
+// Record logs the repairs made to a family and counts them in the read repair metric
+func Record(ctx context.Context, logger *logging.ContextLogger, backend, familyID string, repairs []Repair) {
+	if len(repairs) == 0 {
+		return
+	}
+
+	descriptions := make([]string, 0, len(repairs))
+	for _, r := range repairs {
+		metrics.RepositoryReadRepairs.WithLabelValues(backend, string(r.Kind)).Inc()
+		descriptions = append(descriptions, r.String())
+	}
+
+	logger.Warn(ctx, "Repaired inconsistent legacy family data",
+		zap.String("backend", backend),
+		zap.String("family_id", familyID),
+		zap.Int("repair_count", len(repairs)),
+		zap.Strings("repairs", descriptions))
+}
+
+// formatDate converts a date value to an RFC3339 string at midnight UTC of its calendar
+// date, and returns the kind of the repair. It reports false if the value is already in
+// that form or cannot be converted, in which case decoding reports the error.
+func formatDate(value interface{}) (string, Kind, bool) {
+	switch v := value.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			formatted, kind := canonicalDate(t)
+			return formatted, kind, formatted != v
+		}
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				formatted, kind := canonicalDate(t)
+				return formatted, kind, true
+			}
+		}
+		return v, "", false
+	case time.Time:
+		formatted, kind := canonicalDate(v)
+		return formatted, kind, true
+	case interface{ Time() time.Time }:
+		// BSON datetimes written by legacy MongoDB clients, which only hold the UTC instant
+		formatted, kind := canonicalDate(v.Time().UTC())
+		return formatted, kind, true
+	default:
+		return "", "", false
+	}
+}
+
+// canonicalDate formats a time as midnight UTC of its calendar date, in its own location.
+// The repair truncates the time if it had a time of day or an offset, and only
+// reformats it otherwise.
+func canonicalDate(t time.Time) (string, Kind) {
+	date := entity.DateOf(t).Time()
+	if !t.Equal(date) {
+		return date.Format(time.RFC3339), KindDateTruncated
+	}
+	return date.Format(time.RFC3339), KindDateReformatted
+}
+
+// isEmpty reports whether a value is nil or an empty string
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	s, ok := value.(string)
+	return ok && s == ""
+}
+
+// join appends a key to a path
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// asMap returns v as a map if it is a map with string keys, including named map types
+// such as bson.M
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, true
+	}
+	rv := reflect.ValueOf(v)
+	target := reflect.TypeOf(map[string]interface{}(nil))
+	if rv.Kind() != reflect.Map || !rv.Type().ConvertibleTo(target) {
+		return nil, false
+	}
+	return rv.Convert(target).Interface().(map[string]interface{}), true
+}
+
+// asSlice returns v as a slice, including named slice types such as bson.A
+func asSlice(v interface{}) ([]interface{}, bool) {
+	if s, ok := v.([]interface{}); ok {
+		return s, true
+	}
+	rv := reflect.ValueOf(v)
+	target := reflect.TypeOf([]interface{}(nil))
+	if rv.Kind() != reflect.Slice || !rv.Type().ConvertibleTo(target) {
+		return nil, false
+	}
+	return rv.Convert(target).Interface().([]interface{}), true
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package repair
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestKeys(t *testing.T) {
+	canonical := []string{"id", "firstName"}
+
+	m, repairs := Keys(map[string]interface{}{"ID": "1", "first_name": "John", "nickname": "Jack"}, canonical, "parents[0]")
+	assert.Equal(t, map[string]interface{}{"id": "1", "firstName": "John", "nickname": "Jack"}, m)
+	require.Len(t, repairs, 2)
+	assert.Equal(t, KindKeyNormalized, repairs[0].Kind)
+	assert.Equal(t, "parents[0].id", repairs[0].Path)
+
+	// The canonical key wins unless it is empty
+	m, _ = Keys(map[string]interface{}{"id": "1", "ID": "2"}, canonical, "")
+	assert.Equal(t, map[string]interface{}{"id": "1"}, m)
+	m, _ = Keys(map[string]interface{}{"id": "", "ID": "2"}, canonical, "")
+	assert.Equal(t, map[string]interface{}{"id": "2"}, m)
+
+	// Canonical data needs no repairs
+	_, repairs = Keys(map[string]interface{}{"id": "1", "firstName": "John"}, canonical, "")
+	assert.Empty(t, repairs)
+}
+
+func TestMember(t *testing.T) {
+	m, repairs, ok := Member(map[string]interface{}{
+		"Id":          " 1 ",
+		"first_name":  "John",
+		"LastName":    "Doe",
+		"birth_date":  "1980-01-02",
+		"deathDate":   "",
+		"externalIds": nil,
+	}, CamelCase, "parents[0]")
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{
+		"id":        "1",
+		"firstName": "John",
+		"lastName":  "Doe",
+		"birthDate": "1980-01-02T00:00:00Z",
+	}, m)
+	assert.Len(t, repairs, 8)
+
+	// BSON datetimes are converted to RFC3339 strings
+	birthDate := time.Date(1980, 1, 2, 0, 0, 0, 0, time.UTC)
+	m, _, ok = Member(bson.M{"ID": "1", "BirthDate": primitive.NewDateTimeFromTime(birthDate)}, CamelCase, "")
+	require.True(t, ok)
+	assert.Equal(t, "1980-01-02T00:00:00Z", m["birthDate"])
+
+	// Timestamps are truncated to their calendar date as written, in their own offset
+	for value, expected := range map[string]string{
+		"1980-01-02T00:00:00-05:00": "1980-01-02T00:00:00Z",
+		"1980-01-02T00:00:00+02:00": "1980-01-02T00:00:00Z",
+		"1980-01-02T15:04:05Z":      "1980-01-02T00:00:00Z",
+		"1980-01-02 15:04:05":       "1980-01-02T00:00:00Z",
+	} {
+		m, repairs, _ = Member(map[string]interface{}{"birthDate": value}, CamelCase, "")
+		assert.Equal(t, expected, m["birthDate"], value)
+		require.Len(t, repairs, 1, value)
+		assert.Equal(t, KindDateTruncated, repairs[0].Kind, value)
+	}
+
+	// Unrecognized dates are left for decoding to report
+	m, _, _ = Member(map[string]interface{}{"birthDate": "yesterday"}, CamelCase, "")
+	assert.Equal(t, "yesterday", m["birthDate"])
+
+	_, _, ok = Member("not a document", CamelCase, "")
+	assert.False(t, ok)
+}
+
+func TestMembersJSON(t *testing.T) {
+	data, repairs, err := MembersJSON([]byte(`[{"id":"1","first_name":"John","birth_date":"01/02/1980"}]`), PascalCase, "parents")
+	require.NoError(t, err)
+	assert.Len(t, repairs, 4)
+
+	var members []map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &members))
+	assert.Equal(t, []map[string]interface{}{{"ID": "1", "FirstName": "John", "BirthDate": "1980-01-02T00:00:00Z"}}, members)
+
+	// Missing lists default to empty
+	data, repairs, err = MembersJSON([]byte("null"), PascalCase, "children")
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(data))
+	assert.Len(t, repairs, 1)
+
+	// Canonical and binary data are returned unchanged
+	canonical := []byte(`[{"ID":"1","FirstName":"John","BirthDate":"1980-01-02T00:00:00Z"}]`)
+	data, repairs, err = MembersJSON(canonical, PascalCase, "parents")
+	require.NoError(t, err)
+	assert.Equal(t, canonical, data)
+	assert.Empty(t, repairs)
+
+	binary := []byte{0x00, 0x01, 0x02}
+	data, repairs, err = MembersJSON(binary, PascalCase, "parents")
+	require.NoError(t, err)
+	assert.Equal(t, binary, data)
+	assert.Empty(t, repairs)
+
+	_, _, err = MembersJSON([]byte("[{"), PascalCase, "parents")
+	assert.Error(t, err)
+}
+
+func TestStatus(t *testing.T) {
+	status, repairs := Status("married", 2)
+	assert.Equal(t, "MARRIED", status)
+	assert.Len(t, repairs, 1)
+
+	status, repairs = Status("", 1)
+	assert.Equal(t, "SINGLE", status)
+	assert.Len(t, repairs, 1)
+
+	status, repairs = Status("", 2)
+	assert.Equal(t, "MARRIED", status)
+	assert.Len(t, repairs, 1)
+
+	status, repairs = Status("", 0)
+	assert.Equal(t, "", status)
+	assert.Empty(t, repairs)
+
+	status, repairs = Status("DIVORCED", 1)
+	assert.Equal(t, "DIVORCED", status)
+	assert.Empty(t, repairs)
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package reportstore keeps the aggregate family reports as files in a directory, so that
+// they can be collected by, or copied to, the statistical agencies that receive them.
+//
+// Each report is written once per configured format, to a file named after the time it
+// was generated, such as family-report-20250601T000000Z.csv. Files are written to a
+// temporary name and renamed, so that a collector never reads a partial report. Reports
+// older than the retention period are deleted.
+package reportstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/reporting"
+	"go.uber.org/zap"
+)
+
+// FilePrefix is the prefix of the names of report files
+const FilePrefix = "family-report-"
+
+// timeLayout is the layout of the generation time in the names of report files
+const timeLayout = "20060102T150405Z"
+
+// Generator generates a report
+type Generator func(ctx context.Context) (*reporting.Report, error)
+
+// Store writes reports to a directory and deletes them once they are older than the
+// retention period
+type Store struct {
+	dir       string
+	formats   []string
+	retention time.Duration
+	logger    *zap.Logger
+}
+
+// New creates a new Store.
+//
+// Parameters:
+//   - dir: Directory of the report files, created if it does not exist
+//   - formats: Formats in which each report is written (reporting.FormatCSV, reporting.FormatJSON)
+//   - retention: How long report files are kept (0 keeps them forever)
+//   - logger: Logger for written and deleted reports
+//
+// Returns:
+//   - A new Store
+//   - An error if a format is not supported
+func New(dir string, formats []string, retention time.Duration, logger *zap.Logger) (*Store, error) {
+	for _, format := range formats {
+		if format != reporting.FormatCSV && format != reporting.FormatJSON {
+			return nil, fmt.Errorf("unsupported report format %q", format)
+		}
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Store{dir: dir, formats: formats, retention: retention, logger: logger}, nil
+}
+
+// Write writes a report in every format of the store and returns the paths of the files
+func (s *Store) Write(report *reporting.Report) ([]string, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	name := FilePrefix + report.GeneratedAt.UTC().Format(timeLayout)
+	paths := make([]string, 0, len(s.formats))
+	for _, format := range s.formats {
+		path := filepath.Join(s.dir, name+"."+format)
+		if err := writeFile(path, report, format); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+
+	s.logger.Info("Wrote family report", zap.Strings("files", paths))
+	return paths, nil
+}
+
+// writeFile writes a report in a format to a temporary file and renames it to path
+func writeFile(path string, report *reporting.Report, format string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := report.Write(tmp, format); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s report: %w", format, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s report: %w", format, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s report: %w", format, err)
+	}
+	return nil
+}
+
+// Prune deletes the report files generated before the retention period and returns the
+// number deleted. Files in the directory that are not reports are left alone.
+func (s *Store) Prune(now time.Time) (int, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read report directory: %w", err)
+	}
+
+	cutoff := now.Add(-s.retention)
+	deleted := 0
+	for _, entry := range entries {
+		generatedAt, ok := parseName(entry.Name())
+		if !ok || entry.IsDir() || !generatedAt.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil {
+			return deleted, fmt.Errorf("failed to delete report %s: %w", entry.Name(), err)
+		}
+		deleted++
+	}
+
+	if deleted > 0 {
+		s.logger.Info("Deleted expired family reports", zap.Int("count", deleted))
+	}
+	return deleted, nil
+}
+
+// parseName returns the generation time of a report file from its name
+func parseName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, FilePrefix) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, FilePrefix), filepath.Ext(name))
+	generatedAt, err := time.Parse(timeLayout, stamp)
+	return generatedAt, err == nil
+}
+
+// Job returns a job, for a periodic worker, that generates a report, writes it, and
+// deletes expired reports
+func (s *Store) Job(generate Generator) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		report, err := generate(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := s.Write(report); err != nil {
+			return err
+		}
+		_, err = s.Prune(time.Now())
+		return err
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package reportstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/reporting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStore_Write(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	store, err := New(dir, []string{reporting.FormatCSV, reporting.FormatJSON}, 0, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	report := reporting.Generate(nil, reporting.Options{}, time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC))
+	paths, err := store.Write(report)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "family-report-20250601T000000Z.csv"),
+		filepath.Join(dir, "family-report-20250601T000000Z.json"),
+	}, paths)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "temporary files are removed")
+}
+
+func TestStore_Prune(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"family-report-20250101T000000Z.csv",
+		"family-report-20250101T000000Z.json",
+		"family-report-20250525T000000Z.csv",
+		"notes.txt",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	store, err := New(dir, []string{reporting.FormatCSV}, 30*24*time.Hour, nil)
+	require.NoError(t, err)
+
+	deleted, err := store.Prune(time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.FileExists(t, filepath.Join(dir, "family-report-20250525T000000Z.csv"))
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+}
+
+func TestStore_Job(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir, []string{reporting.FormatJSON}, time.Hour, nil)
+	require.NoError(t, err)
+
+	job := store.Job(func(ctx context.Context) (*reporting.Report, error) {
+		return reporting.Generate(nil, reporting.Options{}, time.Now()), nil
+	})
+	require.NoError(t, job(context.Background()))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	failing := store.Job(func(ctx context.Context) (*reporting.Report, error) {
+		return nil, errors.New("database unavailable")
+	})
+	assert.Error(t, failing(context.Background()))
+}
+
+func TestNew_UnsupportedFormat(t *testing.T) {
+	_, err := New(t.TempDir(), []string{"xml"}, 0, nil)
+	assert.Error(t, err)
+}
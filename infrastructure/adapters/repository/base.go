@@ -209,12 +209,12 @@ func NewBaseRepository(logger *loggingwrapper.ContextLogger, dbName string) *Bas
 //   - false if retrying is unlikely to help
 func IsRetryableError(err error) bool {
 	// Don't retry not found errors
-	if _, ok := err.(*errors.NotFoundError); ok {
+	if errors.IsNotFoundError(err) {
 		return false
 	}
 
 	// Don't retry validation errors
-	if _, ok := err.(*errors.ValidationError); ok {
+	if errors.IsValidationError(err) {
 		return false
 	}
 
@@ -304,13 +304,13 @@ func HandleRepositoryError(
 	retryErr error,
 ) error {
 	// If it's already a typed error, return it directly
-	if _, ok := retryErr.(*errors.NotFoundError); ok {
+	if errors.IsNotFoundError(retryErr) {
 		return retryErr
 	}
-	if _, ok := retryErr.(*errors.ValidationError); ok {
+	if errors.IsValidationError(retryErr) {
 		return retryErr
 	}
-	if _, ok := retryErr.(*errors.DatabaseError); ok {
+	if errors.IsDatabaseError(retryErr) {
 		return retryErr
 	}
 
@@ -0,0 +1,730 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package repositorytest provides a conformance suite for implementations of the
+// FamilyRepository port.
+//
+// Every backend must behave the same way for the application services to be
+// backend-agnostic. The suite checks the observable behavior of a repository through
+// the port only: saving and reading families and their owners, updating them in place,
+// rejecting saves of stale versions and of new families with taken IDs, finding them by
+// parent, child, and external ID, finding a page of the families that match a filter,
+// counting them and their members, counting and listing the families of an owner, deleting, restoring, and purging them, keeping the families
+// of each tenant from the others, and storing events in the outbox. It is run against
+// SQLite in the unit tests and against PostgreSQL and MongoDB in the container-based
+// integration tests.
+//
+// The suite uses fresh IDs for every family it saves and never assumes that the
+// repository is empty, so it can run against a database that holds other data.
+package repositorytest
+
+import (
+	"context"
+	stderrors "errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/abitofhelp/servicelib/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run runs the conformance suite against the repository
+func Run(t *testing.T, repo ports.FamilyRepository) {
+	ctx := context.Background()
+
+	t.Run("save and get by ID", func(t *testing.T) {
+		fam := newFamily(t, 2, 1)
+		require.NoError(t, fam.SetExternalIDs(map[string]string{"conformance": uuid.NewString()}))
+		require.NoError(t, repo.Save(ctx, fam))
+
+		retrieved, err := repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		assertFamilyEqual(t, fam, retrieved)
+	})
+
+	t.Run("save and get member names", func(t *testing.T) {
+		fam := newFamily(t, 1, 1)
+		parent, child := fam.Parents()[0], fam.Children()[0]
+		require.NoError(t, parent.SetPreferredName("Pat"))
+		require.NoError(t, parent.ChangeName(entity.NameChange{FirstName: "Parent", LastName: "Renamed", EffectiveDate: time.Date(2005, time.June, 1, 0, 0, 0, 0, time.UTC), Reason: entity.NameChangeMarriage}))
+		require.NoError(t, child.ChangeName(entity.NameChange{FirstName: "Kid", LastName: "Conformance", EffectiveDate: time.Date(2020, time.March, 15, 0, 0, 0, 0, time.UTC), Reason: entity.NameChangeLegal}))
+		require.NoError(t, repo.Save(ctx, fam))
+
+		retrieved, err := repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		assertFamilyEqual(t, fam, retrieved)
+	})
+
+	t.Run("save and get child consents", func(t *testing.T) {
+		fam := newFamily(t, 1, 1)
+		parentID, childID := fam.Parents()[0].ID(), fam.Children()[0].ID()
+		stale, err := entity.NewConsent(parentID, time.Date(2020, time.January, 2, 10, 0, 0, 0, time.UTC), []entity.ConsentScope{entity.ConsentScopeNames})
+		require.NoError(t, err)
+		current, err := entity.NewConsent(parentID, time.Now(), entity.ConsentScopes)
+		require.NoError(t, err)
+		require.NoError(t, fam.GrantChildConsent(childID, stale))
+		require.NoError(t, fam.GrantChildConsent(childID, current))
+		require.Equal(t, 1, fam.ExpireChildConsents(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), time.Now()))
+		require.NoError(t, repo.Save(ctx, fam))
+
+		retrieved, err := repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		assertFamilyEqual(t, fam, retrieved)
+	})
+
+	t.Run("save and get owner", func(t *testing.T) {
+		fam := newFamily(t, 1, 1)
+		fam.SetOwnerID("owner-" + uuid.NewString())
+		require.NoError(t, repo.Save(ctx, fam))
+
+		retrieved, err := repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		assertFamilyEqual(t, fam, retrieved)
+
+		families, err := repo.FindByParentID(ctx, fam.Parents()[0].ID())
+		require.NoError(t, err)
+		require.Len(t, families, 1)
+		assert.Equal(t, fam.OwnerID(), families[0].OwnerID())
+
+		retrieved, err = repo.FindByChildID(ctx, fam.Children()[0].ID())
+		require.NoError(t, err)
+		assert.Equal(t, fam.OwnerID(), retrieved.OwnerID())
+
+		// The owner of a family can change
+		fam.SetOwnerID("owner-" + uuid.NewString())
+		require.NoError(t, repo.Save(ctx, fam))
+		retrieved, err = repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		assert.Equal(t, fam.OwnerID(), retrieved.OwnerID())
+	})
+
+	t.Run("get missing family", func(t *testing.T) {
+		retrieved, err := repo.GetByID(ctx, uuid.NewString())
+		require.Error(t, err)
+		assert.Nil(t, retrieved)
+
+		var notFound *errors.NotFoundError
+		assert.True(t, stderrors.As(err, &notFound), "expected a NotFoundError, got %v", err)
+	})
+
+	t.Run("save updates existing family", func(t *testing.T) {
+		fam := newFamily(t, 1, 1)
+		require.NoError(t, repo.Save(ctx, fam))
+
+		child := newChild(t, "Added")
+		require.NoError(t, fam.AddChild(child))
+		require.NoError(t, fam.RemoveChild(fam.Children()[0].ID()))
+		require.NoError(t, repo.Save(ctx, fam))
+
+		retrieved, err := repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		assertFamilyEqual(t, fam, retrieved)
+
+		all, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, countID(all, fam.ID()), "an updated family must not be duplicated")
+	})
+
+	t.Run("save rejects a stale version", func(t *testing.T) {
+		fam := newFamily(t, 1, 0)
+		require.NoError(t, repo.Save(ctx, fam))
+
+		first, err := repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		second, err := repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		require.Equal(t, first.Version(), second.Version())
+
+		require.NoError(t, first.AddChild(newChild(t, "First")))
+		require.NoError(t, repo.Save(ctx, first))
+		assert.Equal(t, second.Version()+1, first.Version())
+
+		require.NoError(t, second.AddChild(newChild(t, "Second")))
+		err = repo.Save(ctx, second)
+		assert.True(t, stderrors.Is(err, domainerrors.ErrConcurrencyConflict), "expected a concurrency conflict, got %v", err)
+
+		retrieved, err := repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		assertFamilyEqual(t, first, retrieved)
+		assert.Equal(t, first.Version(), retrieved.Version())
+	})
+
+	t.Run("save of a new family rejects a taken ID", func(t *testing.T) {
+		fam := newFamily(t, 1, 0)
+		fam.SetOwnerID("owner-a")
+		require.NoError(t, repo.Save(ctx, fam))
+
+		// A new family with the ID of a stored family has version 0 and must not replace it
+		other := newFamily(t, 2, 1)
+		taken, err := entity.NewFamily(fam.ID(), other.Status(), other.Parents(), other.Children())
+		require.NoError(t, err)
+		taken.SetOwnerID("owner-b")
+		err = repo.Save(ctx, taken)
+		assert.True(t, stderrors.Is(err, domainerrors.ErrConcurrencyConflict), "expected a concurrency conflict, got %v", err)
+
+		retrieved, err := repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		assertFamilyEqual(t, fam, retrieved)
+		assert.Equal(t, "owner-a", retrieved.OwnerID())
+
+		// A save marked as an overwrite, such as a migration's, replaces it
+		require.NoError(t, repo.Save(ports.WithOverwrite(ctx), taken))
+		retrieved, err = repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		assertFamilyEqual(t, taken, retrieved)
+	})
+
+	t.Run("get all", func(t *testing.T) {
+		first := newFamily(t, 1, 0)
+		second := newFamily(t, 2, 2)
+		require.NoError(t, repo.Save(ctx, first))
+		require.NoError(t, repo.Save(ctx, second))
+
+		all, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, countID(all, first.ID()))
+		assert.Equal(t, 1, countID(all, second.ID()))
+	})
+
+	t.Run("find by parent ID", func(t *testing.T) {
+		fam := newFamily(t, 2, 1)
+		require.NoError(t, repo.Save(ctx, fam))
+
+		families, err := repo.FindByParentID(ctx, fam.Parents()[1].ID())
+		require.NoError(t, err)
+		require.Len(t, families, 1)
+		assertFamilyEqual(t, fam, families[0])
+
+		families, err = repo.FindByParentID(ctx, uuid.NewString())
+		require.NoError(t, err)
+		assert.Empty(t, families)
+	})
+
+	t.Run("find by child ID", func(t *testing.T) {
+		fam := newFamily(t, 1, 2)
+		require.NoError(t, repo.Save(ctx, fam))
+
+		retrieved, err := repo.FindByChildID(ctx, fam.Children()[1].ID())
+		require.NoError(t, err)
+		require.NotNil(t, retrieved)
+		assertFamilyEqual(t, fam, retrieved)
+
+		// A missing child is reported either as no family or as a NotFoundError
+		retrieved, err = repo.FindByChildID(ctx, uuid.NewString())
+		assert.Nil(t, retrieved)
+		if err != nil {
+			var notFound *errors.NotFoundError
+			assert.True(t, stderrors.As(err, &notFound), "expected a NotFoundError, got %v", err)
+		}
+	})
+
+	t.Run("find by external ID", func(t *testing.T) {
+		familyRef, parentRef := uuid.NewString(), uuid.NewString()
+
+		fam := newFamily(t, 1, 0)
+		require.NoError(t, fam.SetExternalIDs(map[string]string{"conformance": familyRef}))
+		require.NoError(t, fam.Parents()[0].SetExternalIDs(map[string]string{"conformance": parentRef}))
+		require.NoError(t, repo.Save(ctx, fam))
+
+		for _, ref := range []string{familyRef, parentRef} {
+			families, err := repo.FindByExternalID(ctx, "conformance", ref)
+			require.NoError(t, err)
+			require.Len(t, families, 1)
+			assert.Equal(t, fam.ID(), families[0].ID())
+		}
+
+		families, err := repo.FindByExternalID(ctx, "conformance", uuid.NewString())
+		require.NoError(t, err)
+		assert.Empty(t, families)
+	})
+
+	t.Run("external ID held by another family", func(t *testing.T) {
+		ref := uuid.NewString()
+
+		fam := newFamily(t, 1, 0)
+		require.NoError(t, fam.SetExternalIDs(map[string]string{"conformance": ref}))
+		require.NoError(t, repo.Save(ctx, fam))
+
+		other := newFamily(t, 1, 0)
+		require.NoError(t, other.SetExternalIDs(map[string]string{"conformance": ref}))
+		assert.Error(t, repo.Save(ctx, other))
+	})
+
+	t.Run("find a page of the families that match a filter", func(t *testing.T) {
+		// A name of letters only that no other family has
+		name := "Page" + strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return 'a' + r - '0'
+			}
+			return r
+		}, strings.ReplaceAll(uuid.NewString(), "-", ""))
+		var matching []string
+		for i := 0; i < 5; i++ {
+			fam := newFamily(t, 1, 0)
+			if i%2 == 0 {
+				require.NoError(t, fam.AddChild(newChild(t, name)))
+				matching = append(matching, fam.ID())
+			}
+			require.NoError(t, repo.Save(ctx, fam))
+		}
+		sort.Strings(matching)
+
+		filter := query.Contains(query.FieldMemberName, strings.ToLower(name))
+		page, err := ports.FindFamiliesPage(ctx, repo, filter, "", 2)
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, matching[:2], []string{page[0].ID(), page[1].ID()})
+		page, err = ports.FindFamiliesPage(ctx, repo, filter, page[1].ID(), 2)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, matching[2], page[0].ID())
+
+		// Another tenant has no matching families
+		page, err = ports.FindFamiliesPage(servicecontext.WithTenantID(ctx, "conformance-"+uuid.NewString()), repo, filter, "", 10)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+	})
+
+	t.Run("count families and members", func(t *testing.T) {
+		counts := func() []int {
+			families, err := repo.CountFamilies(ctx)
+			require.NoError(t, err)
+			parents, err := repo.CountParents(ctx)
+			require.NoError(t, err)
+			children, err := repo.CountChildren(ctx)
+			require.NoError(t, err)
+			return []int{families, parents, children}
+		}
+		before := counts()
+
+		// A parent of two families, such as a remarried parent, is counted once
+		first := newFamily(t, 2, 1)
+		require.NoError(t, repo.Save(ctx, first))
+		shared, err := entity.NewParent(first.Parents()[0].ID(), "Parent", "Conformance", first.Parents()[0].BirthDate(), nil)
+		require.NoError(t, err)
+		second, err := entity.NewFamily(uuid.NewString(), entity.Single, []*entity.Parent{shared}, []*entity.Child{newChild(t, "Child")})
+		require.NoError(t, err)
+		require.NoError(t, repo.Save(ctx, second))
+
+		assert.Equal(t, []int{before[0] + 2, before[1] + 2, before[2] + 2}, counts())
+
+		// Deleted families and the members that belong only to them are not counted
+		if deleter, ok := repo.(ports.FamilyDeleter); ok {
+			deleted, err := deleter.SoftDeleteFamily(ctx, second.ID(), time.Now())
+			require.NoError(t, err)
+			require.True(t, deleted)
+			assert.Equal(t, []int{before[0] + 1, before[1] + 2, before[2] + 1}, counts())
+		}
+	})
+
+	t.Run("count and list the families of an owner", func(t *testing.T) {
+		owner := "owner-" + uuid.NewString()
+		first, second, third := newFamily(t, 2, 1), newFamily(t, 1, 2), newFamily(t, 1, 0)
+		shared, err := entity.NewParent(first.Parents()[0].ID(), "Parent", "Conformance", first.Parents()[0].BirthDate(), nil)
+		require.NoError(t, err)
+		fourth, err := entity.NewFamily(uuid.NewString(), entity.Single, []*entity.Parent{shared}, []*entity.Child{newChild(t, "Child")})
+		require.NoError(t, err)
+		for _, fam := range []*entity.Family{first, second, fourth} {
+			fam.SetOwnerID(owner)
+			require.NoError(t, repo.Save(ctx, fam))
+		}
+		// A family of another owner is neither counted nor listed
+		third.SetOwnerID("owner-" + uuid.NewString())
+		require.NoError(t, repo.Save(ctx, third))
+
+		// A parent of two families of the owner is counted once
+		counts, err := ports.CountFamiliesByOwner(ctx, repo, owner, nil)
+		require.NoError(t, err)
+		assert.Equal(t, ports.FamilyCounts{Families: 3, Parents: 3, Children: 4}, counts)
+
+		// Excluded families, such as quarantined ones, and the members only they have are not counted
+		counts, err = ports.CountFamiliesByOwner(ctx, repo, owner, []string{second.ID(), uuid.NewString()})
+		require.NoError(t, err)
+		assert.Equal(t, ports.FamilyCounts{Families: 2, Parents: 2, Children: 2}, counts)
+
+		// The families of the owner are listed a page at a time in ID order
+		owned := []string{first.ID(), second.ID(), fourth.ID()}
+		sort.Strings(owned)
+		page, err := ports.ListFamiliesByOwner(ctx, repo, owner, "", 2)
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, owned[:2], []string{page[0].ID(), page[1].ID()})
+		assert.Equal(t, owner, page[0].OwnerID())
+		page, err = ports.ListFamiliesByOwner(ctx, repo, owner, page[1].ID(), 2)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, owned[2], page[0].ID())
+
+		// Another tenant has no families of the owner
+		tenant := servicecontext.WithTenantID(ctx, "conformance-"+uuid.NewString())
+		counts, err = ports.CountFamiliesByOwner(tenant, repo, owner, nil)
+		require.NoError(t, err)
+		assert.Zero(t, counts)
+		page, err = ports.ListFamiliesByOwner(tenant, repo, owner, "", 10)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+
+		// Deleted families are neither counted nor listed
+		if deleter, ok := repo.(ports.FamilyDeleter); ok {
+			deleted, err := deleter.SoftDeleteFamily(ctx, fourth.ID(), time.Now())
+			require.NoError(t, err)
+			require.True(t, deleted)
+			counts, err = ports.CountFamiliesByOwner(ctx, repo, owner, nil)
+			require.NoError(t, err)
+			assert.Equal(t, ports.FamilyCounts{Families: 2, Parents: 3, Children: 3}, counts)
+			page, err = ports.ListFamiliesByOwner(ctx, repo, owner, "", 10)
+			require.NoError(t, err)
+			assert.Zero(t, countID(page, fourth.ID()))
+		}
+	})
+
+	t.Run("tenant isolation", func(t *testing.T) {
+		tenant := servicecontext.WithTenantID(ctx, "conformance-"+uuid.NewString())
+		other := servicecontext.WithTenantID(ctx, "conformance-"+uuid.NewString())
+		countFamilies := func(ctx context.Context) int {
+			count, err := repo.CountFamilies(ctx)
+			require.NoError(t, err)
+			return count
+		}
+		before := countFamilies(other)
+
+		fam := newFamily(t, 1, 1)
+		require.NoError(t, repo.Save(tenant, fam))
+		retrieved, err := repo.GetByID(tenant, fam.ID())
+		require.NoError(t, err)
+		assertFamilyEqual(t, fam, retrieved)
+
+		// Another tenant, or a context without one, neither reads nor counts the family
+		for _, ctx := range []context.Context{other, ctx} {
+			_, err = repo.GetByID(ctx, fam.ID())
+			var notFound *errors.NotFoundError
+			assert.True(t, stderrors.As(err, &notFound), "expected a NotFoundError, got %v", err)
+			all, err := repo.GetAll(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, countID(all, fam.ID()))
+			families, err := repo.FindByParentID(ctx, fam.Parents()[0].ID())
+			require.NoError(t, err)
+			assert.Empty(t, families)
+			retrieved, err = repo.FindByChildID(ctx, fam.Children()[0].ID())
+			if err == nil {
+				assert.Nil(t, retrieved)
+			}
+		}
+		assert.Equal(t, before, countFamilies(other))
+
+		// Nor does it overwrite or delete the family
+		assert.Error(t, repo.Save(other, fam))
+		if deleter, ok := repo.(ports.FamilyDeleter); ok {
+			deleted, err := deleter.SoftDeleteFamily(other, fam.ID(), time.Now())
+			require.NoError(t, err)
+			assert.False(t, deleted)
+			deleted, err = deleter.HardDeleteFamily(other, fam.ID())
+			require.NoError(t, err)
+			assert.False(t, deleted)
+		}
+		retrieved, err = repo.GetByID(tenant, fam.ID())
+		require.NoError(t, err)
+		assertFamilyEqual(t, fam, retrieved)
+	})
+
+	if deleter, ok := repo.(ports.FamilyDeleter); ok {
+		t.Run("soft and hard delete", func(t *testing.T) {
+			fam := newFamily(t, 1, 1)
+			require.NoError(t, repo.Save(ctx, fam))
+
+			deleted, err := deleter.SoftDeleteFamily(ctx, fam.ID(), time.Now())
+			require.NoError(t, err)
+			assert.True(t, deleted)
+			deleted, err = deleter.SoftDeleteFamily(ctx, fam.ID(), time.Now())
+			require.NoError(t, err)
+			assert.False(t, deleted, "the family is already deleted")
+
+			// A deleted family is left out of every read
+			_, err = repo.GetByID(ctx, fam.ID())
+			var notFound *errors.NotFoundError
+			assert.True(t, stderrors.As(err, &notFound), "expected a NotFoundError, got %v", err)
+			all, err := repo.GetAll(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, countID(all, fam.ID()))
+			families, err := repo.FindByParentID(ctx, fam.Parents()[0].ID())
+			require.NoError(t, err)
+			assert.Empty(t, families)
+
+			// Saving the family again clears the mark
+			require.NoError(t, repo.Save(ctx, fam))
+			_, err = repo.GetByID(ctx, fam.ID())
+			require.NoError(t, err)
+
+			deleted, err = deleter.HardDeleteFamily(ctx, fam.ID())
+			require.NoError(t, err)
+			assert.True(t, deleted)
+			deleted, err = deleter.HardDeleteFamily(ctx, fam.ID())
+			require.NoError(t, err)
+			assert.False(t, deleted)
+			_, err = repo.GetByID(ctx, fam.ID())
+			assert.Error(t, err)
+		})
+	}
+
+	if restorer, ok := repo.(ports.FamilyRestorer); ok {
+		t.Run("restore and purge deleted families", func(t *testing.T) {
+			deleter, ok := repo.(ports.FamilyDeleter)
+			require.True(t, ok, "a repository that restores families must delete them")
+
+			fam := newFamily(t, 1, 1)
+			require.NoError(t, repo.Save(ctx, fam))
+			restored, err := restorer.RestoreFamily(ctx, fam.ID())
+			require.NoError(t, err)
+			assert.False(t, restored, "the family is not deleted")
+
+			// A restored family is read again as it was
+			deletedAt := time.Now().Add(-time.Hour)
+			deleted, err := deleter.SoftDeleteFamily(ctx, fam.ID(), deletedAt)
+			require.NoError(t, err)
+			require.True(t, deleted)
+			restored, err = restorer.RestoreFamily(ctx, fam.ID())
+			require.NoError(t, err)
+			assert.True(t, restored)
+			retrieved, err := repo.GetByID(ctx, fam.ID())
+			require.NoError(t, err)
+			assertFamilyEqual(t, fam, retrieved)
+			families, err := repo.FindByParentID(ctx, fam.Parents()[0].ID())
+			require.NoError(t, err)
+			assert.Len(t, families, 1)
+
+			// Only the families deleted before the time are purged; another tenant's are kept
+			other := servicecontext.WithTenantID(ctx, "conformance-"+uuid.NewString())
+			recent := newFamily(t, 1, 0)
+			require.NoError(t, repo.Save(ctx, recent))
+			otherFam := newFamily(t, 1, 0)
+			require.NoError(t, repo.Save(other, otherFam))
+			for _, del := range []struct {
+				ctx context.Context
+				id  string
+				at  time.Time
+			}{{ctx, fam.ID(), deletedAt}, {ctx, recent.ID(), time.Now()}, {other, otherFam.ID(), deletedAt}} {
+				deleted, err := deleter.SoftDeleteFamily(del.ctx, del.id, del.at)
+				require.NoError(t, err)
+				require.True(t, deleted)
+			}
+
+			ids, err := restorer.PurgeDeletedFamilies(ctx, deletedAt.Add(time.Minute), false)
+			require.NoError(t, err)
+			assert.Contains(t, ids, fam.ID())
+			assert.NotContains(t, ids, recent.ID())
+			assert.NotContains(t, ids, otherFam.ID())
+			restored, err = restorer.RestoreFamily(ctx, fam.ID())
+			require.NoError(t, err)
+			assert.False(t, restored, "a purged family is removed for good")
+			restored, err = restorer.RestoreFamily(ctx, recent.ID())
+			require.NoError(t, err)
+			assert.True(t, restored)
+
+			// A purge of every tenant purges the families of the other tenant too
+			ids, err = restorer.PurgeDeletedFamilies(ctx, deletedAt.Add(time.Minute), true)
+			require.NoError(t, err)
+			assert.Contains(t, ids, otherFam.ID())
+			restored, err = restorer.RestoreFamily(other, otherFam.ID())
+			require.NoError(t, err)
+			assert.False(t, restored)
+		})
+	}
+
+	if outbox, ok := repo.(ports.OutboxRepository); ok {
+		t.Run("outbox", func(t *testing.T) {
+			newMessage := func() ports.OutboxMessage {
+				return ports.OutboxMessage{
+					ID:        uuid.NewString(),
+					Type:      "conformance",
+					Payload:   []byte(`{"type":"conformance"}`),
+					CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+				}
+			}
+
+			// Messages are read in the order in which they were stored
+			first, second := newMessage(), newMessage()
+			require.NoError(t, outbox.AddToOutbox(ctx, first, second))
+			pending := pendingMessages(t, outbox, first.ID, second.ID)
+			require.Len(t, pending, 2)
+			assert.Equal(t, first.ID, pending[0].ID)
+			assert.Equal(t, first.Type, pending[0].Type)
+			assert.JSONEq(t, string(first.Payload), string(pending[0].Payload))
+			assert.True(t, first.CreatedAt.Equal(pending[0].CreatedAt), "creation time %v != %v", first.CreatedAt, pending[0].CreatedAt)
+			assert.Equal(t, second.ID, pending[1].ID)
+
+			// Removed messages are no longer pending; unknown IDs are ignored
+			require.NoError(t, outbox.RemoveFromOutbox(ctx, []string{first.ID, uuid.NewString()}))
+			assert.Len(t, pendingMessages(t, outbox, first.ID, second.ID), 1)
+			require.NoError(t, outbox.RemoveFromOutbox(ctx, []string{second.ID}))
+			assert.Empty(t, pendingMessages(t, outbox, first.ID, second.ID))
+
+			// Messages are stored with the saves of a unit of work, or not at all, if the
+			// database can run units of work, such as MongoDB as a replica set
+			unit, ok := repo.(ports.UnitOfWork)
+			if !ok {
+				return
+			}
+			for _, commit := range []bool{false, true} {
+				fam := newFamily(t, 1, 0)
+				message := newMessage()
+				unitCtx, err := unit.Begin(ctx)
+				if stderrors.Is(err, ports.ErrNoUnitOfWork) {
+					return
+				}
+				require.NoError(t, err)
+				require.NoError(t, repo.Save(unitCtx, fam))
+				require.NoError(t, outbox.AddToOutbox(unitCtx, message))
+				if commit {
+					require.NoError(t, unit.Commit(unitCtx))
+				} else {
+					require.NoError(t, unit.Rollback(unitCtx))
+				}
+
+				_, err = repo.GetByID(ctx, fam.ID())
+				pending := pendingMessages(t, outbox, message.ID)
+				if commit {
+					assert.NoError(t, err)
+					assert.Len(t, pending, 1)
+				} else {
+					assert.Error(t, err, "the family of a rolled back unit is not saved")
+					assert.Empty(t, pending, "the message of a rolled back unit is not stored")
+				}
+				require.NoError(t, outbox.RemoveFromOutbox(ctx, []string{message.ID}))
+			}
+		})
+	}
+}
+
+// pendingMessages returns the pending messages of the outbox with the given IDs, in order
+func pendingMessages(t *testing.T, outbox ports.OutboxRepository, ids ...string) []ports.OutboxMessage {
+	t.Helper()
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	all, err := outbox.PendingOutboxMessages(context.Background(), 10000)
+	require.NoError(t, err)
+	var messages []ports.OutboxMessage
+	for _, message := range all {
+		if wanted[message.ID] {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// newFamily creates a family with new IDs and the given number of parents and children
+func newFamily(t *testing.T, parentCount, childCount int) *entity.Family {
+	t.Helper()
+
+	parents := make([]*entity.Parent, 0, parentCount)
+	for i := 0; i < parentCount; i++ {
+		parent, err := entity.NewParent(uuid.NewString(), "Parent", "Conformance", time.Date(1980+i, time.January, 2, 0, 0, 0, 0, time.UTC), nil)
+		require.NoError(t, err)
+		parents = append(parents, parent)
+	}
+
+	children := make([]*entity.Child, 0, childCount)
+	for i := 0; i < childCount; i++ {
+		children = append(children, newChild(t, "Child"))
+	}
+
+	status := entity.Single
+	if parentCount == 2 {
+		status = entity.Married
+	}
+
+	fam, err := entity.NewFamily(uuid.NewString(), status, parents, children)
+	require.NoError(t, err)
+	return fam
+}
+
+// newChild creates a child with a new ID
+func newChild(t *testing.T, firstName string) *entity.Child {
+	t.Helper()
+
+	child, err := entity.NewChild(uuid.NewString(), firstName, "Conformance", time.Date(2012, time.March, 4, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	return child
+}
+
+// assertFamilyEqual asserts that a family read from the repository matches the saved family
+func assertFamilyEqual(t *testing.T, expected, actual *entity.Family) {
+	t.Helper()
+
+	want, got := expected.ToDTO(), actual.ToDTO()
+	assert.Equal(t, want.ID, got.ID)
+	assert.Equal(t, want.Status, got.Status)
+	assert.Equal(t, want.ExternalIDs, got.ExternalIDs)
+	assert.Equal(t, want.OwnerID, got.OwnerID)
+
+	require.Len(t, got.Parents, len(want.Parents))
+	for i, parent := range want.Parents {
+		assert.Equal(t, parent.ID, got.Parents[i].ID)
+		assert.Equal(t, parent.FirstName, got.Parents[i].FirstName)
+		assert.Equal(t, parent.LastName, got.Parents[i].LastName)
+		assert.True(t, parent.BirthDate.Equal(got.Parents[i].BirthDate), "parent birth date %v != %v", parent.BirthDate, got.Parents[i].BirthDate)
+		assert.Equal(t, parent.ExternalIDs, got.Parents[i].ExternalIDs)
+		assert.Equal(t, parent.PreferredName, got.Parents[i].PreferredName)
+		assertNameHistoryEqual(t, parent.NameHistory, got.Parents[i].NameHistory)
+	}
+
+	require.Len(t, got.Children, len(want.Children))
+	for i, child := range want.Children {
+		assert.Equal(t, child.ID, got.Children[i].ID)
+		assert.Equal(t, child.FirstName, got.Children[i].FirstName)
+		assert.Equal(t, child.LastName, got.Children[i].LastName)
+		assert.True(t, child.BirthDate.Equal(got.Children[i].BirthDate), "child birth date %v != %v", child.BirthDate, got.Children[i].BirthDate)
+		assert.Equal(t, child.PreferredName, got.Children[i].PreferredName)
+		assertNameHistoryEqual(t, child.NameHistory, got.Children[i].NameHistory)
+		assertConsentsEqual(t, child.Consents, got.Children[i].Consents)
+	}
+}
+
+// assertConsentsEqual asserts that consents read from the repository match the saved consents
+func assertConsentsEqual(t *testing.T, expected, actual []entity.Consent) {
+	t.Helper()
+
+	require.Len(t, actual, len(expected))
+	for i, consent := range expected {
+		assert.Equal(t, consent.GrantedBy, actual[i].GrantedBy)
+		assert.True(t, consent.GrantedAt.Equal(actual[i].GrantedAt), "grant time %v != %v", consent.GrantedAt, actual[i].GrantedAt)
+		assert.Equal(t, consent.Scopes, actual[i].Scopes)
+		require.Equal(t, consent.ExpiredAt == nil, actual[i].ExpiredAt == nil)
+		if consent.ExpiredAt != nil {
+			assert.True(t, consent.ExpiredAt.Equal(*actual[i].ExpiredAt), "expiry time %v != %v", consent.ExpiredAt, actual[i].ExpiredAt)
+		}
+	}
+}
+
+// assertNameHistoryEqual asserts that a name history read from the repository matches the saved history
+func assertNameHistoryEqual(t *testing.T, expected, actual []entity.NameChange) {
+	t.Helper()
+
+	require.Len(t, actual, len(expected))
+	for i, name := range expected {
+		assert.Equal(t, name.FullName(), actual[i].FullName())
+		assert.Equal(t, name.Reason, actual[i].Reason)
+		assert.True(t, name.EffectiveDate.Equal(actual[i].EffectiveDate), "effective date %v != %v", name.EffectiveDate, actual[i].EffectiveDate)
+	}
+}
+
+// countID returns the number of families with the given ID
+func countID(families []*entity.Family, id string) int {
+	count := 0
+	for _, fam := range families {
+		if fam.ID() == id {
+			count++
+		}
+	}
+	return count
+}
@@ -53,7 +53,8 @@ func (v *InputValidator) Validate(ctx context.Context, data interface{}) error {
 	v.logger.Debug(ctx, "Validating input data", zap.String("type", reflect.TypeOf(data).String()))
 
 	if err := v.validator.Struct(data); err != nil {
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
 			// Convert validation errors to a more user-friendly format
 			errorMessages := make([]string, 0, len(validationErrors))
 			for _, e := range validationErrors {
@@ -83,7 +84,8 @@ func (v *InputValidator) ValidateField(ctx context.Context, fieldName string, va
 
 	err := v.validator.Var(value, tag)
 	if err != nil {
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
 			errorMessages := make([]string, 0, len(validationErrors))
 			for _, e := range validationErrors {
 				errorMessages = append(errorMessages, fmt.Sprintf(
@@ -0,0 +1,631 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package shadow provides a family repository that shadows reads to a second repository.
+//
+// Before the service is switched to another backend, the new backend can be run as a
+// shadow of the current one to validate that both return the same data. The shadowing
+// repository serves every operation from the primary repository. Sampled read operations
+// are also sent to the shadow repository in the background, and the results of both are
+// compared and counted; differences of sampled mismatches are logged. The shadow never
+// affects the response to the client: its errors and latency are invisible to callers,
+// and when too many shadow reads are in flight further reads are not shadowed.
+//
+// Writes go to the primary repository only, so the shadow must be kept in sync by other
+// means, for example by replicating or migrating the data. During a migration to the
+// shadow's backend, dual writes keep it in sync: every save that succeeds on the primary,
+// which stays authoritative, is also written to the shadow. A failed shadow write is
+// counted and logged but not returned, and the divergence it leaves shows up in the
+// comparisons of later reads.
+package shadow
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/diff"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/metrics"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/servicelib/errors"
+	"github.com/abitofhelp/servicelib/logging"
+	"go.uber.org/zap"
+)
+
+// Results of a shadow read, as counted in the shadow comparison metric
+const (
+	ResultMatch       = "match"
+	ResultMismatch    = "mismatch"
+	ResultShadowError = "shadow_error"
+	ResultDropped     = "dropped"
+)
+
+// Results of a dual write, as counted in the dual write metric
+const (
+	ResultWritten = "written"
+	ResultFailed  = "failed"
+)
+
+// maxDifferences limits the number of differences reported for a single mismatch
+const maxDifferences = 10
+
+// Repository is a FamilyRepository that serves operations from a primary repository
+// and compares sampled reads with a shadow repository
+type Repository struct {
+	primary ports.FamilyRepository
+	shadow  ports.FamilyRepository
+	cfg     config.ShadowConfig
+	logger  *logging.ContextLogger
+	random  func() float64
+	slots   chan struct{}
+
+	mu       sync.Mutex
+	stopped  bool
+	inFlight sync.WaitGroup
+}
+
+// Ensure Repository implements the FamilyRepository port
+var _ ports.FamilyRepository = (*Repository)(nil)
+
+// NewRepository creates a new shadowing repository.
+//
+// Parameters:
+//   - primary: The repository that serves all operations
+//   - shadow: The repository that sampled reads are compared with
+//   - cfg: Sampling, concurrency, and timeout of the shadow reads
+//   - logger: Logger for shadow errors and differences
+func NewRepository(primary, shadow ports.FamilyRepository, cfg config.ShadowConfig, logger *logging.ContextLogger) *Repository {
+	if cfg.MaxConcurrency < 1 {
+		cfg.MaxConcurrency = 1
+	}
+	return &Repository{
+		primary: primary,
+		shadow:  shadow,
+		cfg:     cfg,
+		logger:  logger,
+		random:  rand.Float64,
+		slots:   make(chan struct{}, cfg.MaxConcurrency),
+	}
+}
+
+// GetByID retrieves a family from the primary repository and shadows the read
+func (r *Repository) GetByID(ctx context.Context, id string) (*entity.Family, error) {
+	fam, err := r.primary.GetByID(ctx, id)
+	r.compare(ctx, "get_by_id", []zap.Field{zap.String("family_id", id)}, single(fam), err,
+		func(ctx context.Context) ([]*entity.Family, error) {
+			fam, err := r.shadow.GetByID(ctx, id)
+			return single(fam), err
+		})
+	return fam, err
+}
+
+// GetAll retrieves all families from the primary repository and shadows the read
+func (r *Repository) GetAll(ctx context.Context) ([]*entity.Family, error) {
+	families, err := r.primary.GetAll(ctx)
+	r.compare(ctx, "get_all", nil, families, err, r.shadow.GetAll)
+	return families, err
+}
+
+// ExportAll exports all families from the primary repository without shadowing the read,
+// since comparing a full export would hold both copies in memory. It falls back to GetAll
+// if the primary repository does not implement ports.FamilyExporter.
+func (r *Repository) ExportAll(ctx context.Context, fn func(*entity.Family) error) error {
+	if exporter, ok := r.primary.(ports.FamilyExporter); ok {
+		return exporter.ExportAll(ctx, fn)
+	}
+
+	families, err := r.primary.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, fam := range families {
+		if err := fn(fam); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LockFamily locks a family in the primary repository, which serves every change. It does
+// not lock anything if the primary repository does not implement ports.FamilyLocker.
+func (r *Repository) LockFamily(ctx context.Context, familyID string) (func(), error) {
+	if locker, ok := r.primary.(ports.FamilyLocker); ok {
+		return locker.LockFamily(ctx, familyID)
+	}
+	return func() {}, nil
+}
+
+// Ensure Repository implements ports.UnitOfWork
+var _ ports.UnitOfWork = (*Repository)(nil)
+
+// Begin begins a unit of work of the primary repository, which serves every change. It
+// fails with ports.ErrNoUnitOfWork if the primary repository does not implement
+// ports.UnitOfWork. Dual writes do not join the unit of work: if it is rolled back, the
+// shadow keeps the saves that the primary discarded, and later comparisons report them.
+func (r *Repository) Begin(ctx context.Context) (context.Context, error) {
+	if unit, ok := r.primary.(ports.UnitOfWork); ok {
+		return unit.Begin(ctx)
+	}
+	return nil, ports.ErrNoUnitOfWork
+}
+
+// Commit applies the changes of the unit of work of the primary repository
+func (r *Repository) Commit(ctx context.Context) error {
+	if unit, ok := r.primary.(ports.UnitOfWork); ok {
+		return unit.Commit(ctx)
+	}
+	return ports.ErrNoUnitOfWork
+}
+
+// Rollback discards the changes of the unit of work of the primary repository
+func (r *Repository) Rollback(ctx context.Context) error {
+	if unit, ok := r.primary.(ports.UnitOfWork); ok {
+		return unit.Rollback(ctx)
+	}
+	return nil
+}
+
+// Save persists a family in the primary repository and, with dual writes, in the shadow
+// repository. Only the primary's error is returned. The shadow write runs after the
+// primary's, under the caller's lock of the family, so the shadow receives the saves of a
+// family in the same order.
+func (r *Repository) Save(ctx context.Context, fam *entity.Family) error {
+	if err := r.primary.Save(ctx, fam); err != nil {
+		return err
+	}
+	if !r.cfg.DualWrite {
+		return nil
+	}
+
+	// The shadow write must not be abandoned when the client goes away, or the shadow
+	// would miss a save the primary has
+	shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.cfg.Timeout)
+	defer cancel()
+
+	// The primary has checked the version of the family, and the shadow counts the saves of
+	// its copy itself, so the copy replaces the shadow's without a version check
+	version := fam.Version()
+	fam.SetVersion(0)
+	defer fam.SetVersion(version)
+	if err := r.shadow.Save(ports.WithOverwrite(shadowCtx), fam); err != nil {
+		metrics.RepositoryDualWrites.WithLabelValues(ResultFailed).Inc()
+		r.logger.Warn(ctx, "Shadow repository write failed; the shadow has diverged from the primary",
+			zap.String("family_id", fam.ID()), zap.Error(err))
+		return nil
+	}
+	metrics.RepositoryDualWrites.WithLabelValues(ResultWritten).Inc()
+	return nil
+}
+
+// Ensure Repository implements ports.FamilyDeleter
+var _ ports.FamilyDeleter = (*Repository)(nil)
+
+// SoftDeleteFamily marks a family as deleted in the primary repository and, with dual
+// writes, in the shadow repository. It fails if the primary repository does not implement
+// ports.FamilyDeleter.
+func (r *Repository) SoftDeleteFamily(ctx context.Context, familyID string, deletedAt time.Time) (bool, error) {
+	deleter, ok := r.primary.(ports.FamilyDeleter)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository cannot delete families", "delete", "families", nil)
+	}
+	deleted, err := deleter.SoftDeleteFamily(ctx, familyID, deletedAt)
+	if err != nil {
+		return false, err
+	}
+	r.deleteInShadow(ctx, familyID, func(ctx context.Context, shadow ports.FamilyDeleter) error {
+		_, err := shadow.SoftDeleteFamily(ctx, familyID, deletedAt)
+		return err
+	})
+	return deleted, nil
+}
+
+// HardDeleteFamily removes a family from the primary repository and, with dual writes,
+// from the shadow repository. It fails if the primary repository does not implement
+// ports.FamilyDeleter.
+func (r *Repository) HardDeleteFamily(ctx context.Context, familyID string) (bool, error) {
+	deleter, ok := r.primary.(ports.FamilyDeleter)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository cannot delete families", "delete", "families", nil)
+	}
+	deleted, err := deleter.HardDeleteFamily(ctx, familyID)
+	if err != nil {
+		return false, err
+	}
+	r.deleteInShadow(ctx, familyID, func(ctx context.Context, shadow ports.FamilyDeleter) error {
+		_, err := shadow.HardDeleteFamily(ctx, familyID)
+		return err
+	})
+	return deleted, nil
+}
+
+// deleteInShadow applies a deletion done in the primary repository to the shadow
+// repository, with dual writes, like Save. A shadow that cannot delete families diverges.
+func (r *Repository) deleteInShadow(ctx context.Context, familyID string, del func(ctx context.Context, shadow ports.FamilyDeleter) error) {
+	r.changeInShadow(ctx, "delete", familyID, func(ctx context.Context) error {
+		shadow, ok := r.shadow.(ports.FamilyDeleter)
+		if !ok {
+			return errors.NewDatabaseError("the shadow repository cannot delete families", "delete", "families", nil)
+		}
+		return del(ctx, shadow)
+	})
+}
+
+// changeInShadow applies a change of a family done in the primary repository, other than a
+// save, to the shadow repository, with dual writes, like Save
+func (r *Repository) changeInShadow(ctx context.Context, operation, familyID string, change func(ctx context.Context) error) {
+	if !r.cfg.DualWrite {
+		return
+	}
+
+	shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.cfg.Timeout)
+	defer cancel()
+	if err := change(shadowCtx); err != nil {
+		metrics.RepositoryDualWrites.WithLabelValues(ResultFailed).Inc()
+		r.logger.Warn(ctx, "Shadow repository "+operation+" failed; the shadow has diverged from the primary",
+			zap.String("family_id", familyID), zap.Error(err))
+		return
+	}
+	metrics.RepositoryDualWrites.WithLabelValues(ResultWritten).Inc()
+}
+
+// Ensure Repository implements ports.FamilyRestorer
+var _ ports.FamilyRestorer = (*Repository)(nil)
+
+// RestoreFamily restores a deleted family in the primary repository and, with dual writes,
+// in the shadow repository. It fails if the primary repository does not implement
+// ports.FamilyRestorer.
+func (r *Repository) RestoreFamily(ctx context.Context, familyID string) (bool, error) {
+	restorer, ok := r.primary.(ports.FamilyRestorer)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository cannot restore families", "update", "families", nil)
+	}
+	restored, err := restorer.RestoreFamily(ctx, familyID)
+	if err != nil {
+		return false, err
+	}
+	r.changeInShadow(ctx, "restore", familyID, func(ctx context.Context) error {
+		shadow, ok := r.shadow.(ports.FamilyRestorer)
+		if !ok {
+			return errors.NewDatabaseError("the shadow repository cannot restore families", "update", "families", nil)
+		}
+		_, err := shadow.RestoreFamily(ctx, familyID)
+		return err
+	})
+	return restored, nil
+}
+
+// PurgeDeletedFamilies purges the deleted families of the primary repository and, with dual
+// writes, removes the purged families from the shadow repository. It fails if the primary
+// repository does not implement ports.FamilyRestorer.
+func (r *Repository) PurgeDeletedFamilies(ctx context.Context, deletedBefore time.Time, allTenants bool) ([]string, error) {
+	restorer, ok := r.primary.(ports.FamilyRestorer)
+	if !ok {
+		return nil, errors.NewDatabaseError("the repository cannot purge families", "delete", "families", nil)
+	}
+	ids, err := restorer.PurgeDeletedFamilies(ctx, deletedBefore, allTenants)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		r.deleteInShadow(ctx, id, func(ctx context.Context, shadow ports.FamilyDeleter) error {
+			_, err := shadow.HardDeleteFamily(ctx, id)
+			return err
+		})
+	}
+	return ids, nil
+}
+
+// FindByParentID finds families by parent in the primary repository and shadows the read
+func (r *Repository) FindByParentID(ctx context.Context, parentID string) ([]*entity.Family, error) {
+	families, err := r.primary.FindByParentID(ctx, parentID)
+	r.compare(ctx, "find_by_parent_id", []zap.Field{zap.String("parent_id", parentID)}, families, err,
+		func(ctx context.Context) ([]*entity.Family, error) {
+			return r.shadow.FindByParentID(ctx, parentID)
+		})
+	return families, err
+}
+
+// FindByChildID finds a family by child in the primary repository and shadows the read
+func (r *Repository) FindByChildID(ctx context.Context, childID string) (*entity.Family, error) {
+	fam, err := r.primary.FindByChildID(ctx, childID)
+	r.compare(ctx, "find_by_child_id", []zap.Field{zap.String("child_id", childID)}, single(fam), err,
+		func(ctx context.Context) ([]*entity.Family, error) {
+			fam, err := r.shadow.FindByChildID(ctx, childID)
+			return single(fam), err
+		})
+	return fam, err
+}
+
+// FindByExternalID finds families by external ID in the primary repository and shadows the read
+func (r *Repository) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	families, err := r.primary.FindByExternalID(ctx, system, externalID)
+	r.compare(ctx, "find_by_external_id", []zap.Field{zap.String("system", system), zap.String("external_id", externalID)}, families, err,
+		func(ctx context.Context) ([]*entity.Family, error) {
+			return r.shadow.FindByExternalID(ctx, system, externalID)
+		})
+	return families, err
+}
+
+// Find finds the families that match a filter in the primary repository and shadows the read
+func (r *Repository) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	families, err := r.primary.Find(ctx, filter)
+	r.compare(ctx, "find", []zap.Field{zap.Any("filter", filter)}, families, err,
+		func(ctx context.Context) ([]*entity.Family, error) {
+			return r.shadow.Find(ctx, filter)
+		})
+	return families, err
+}
+
+// CountFamilies counts the families in the primary repository. Counts are not shadowed,
+// since they differ whenever the shadow repository is behind.
+func (r *Repository) CountFamilies(ctx context.Context) (int, error) {
+	return r.primary.CountFamilies(ctx)
+}
+
+// CountParents counts the distinct parents in the primary repository
+func (r *Repository) CountParents(ctx context.Context) (int, error) {
+	return r.primary.CountParents(ctx)
+}
+
+// CountChildren counts the distinct children in the primary repository
+func (r *Repository) CountChildren(ctx context.Context) (int, error) {
+	return r.primary.CountChildren(ctx)
+}
+
+// ListFamilies reads a page of families from the primary repository and shadows the read.
+// Either repository that does not implement ports.FamilyPager is read with GetAll.
+func (r *Repository) ListFamilies(ctx context.Context, after string, limit int) ([]*entity.Family, error) {
+	families, err := listFamilies(ctx, r.primary, after, limit)
+	r.compare(ctx, "list_families", []zap.Field{zap.String("after", after), zap.Int("limit", limit)}, families, err,
+		func(ctx context.Context) ([]*entity.Family, error) {
+			return listFamilies(ctx, r.shadow, after, limit)
+		})
+	return families, err
+}
+
+// listFamilies reads a page of families from a repository, with GetAll if it does not
+// implement ports.FamilyPager
+func listFamilies(ctx context.Context, repo ports.FamilyRepository, after string, limit int) ([]*entity.Family, error) {
+	if pager, ok := repo.(ports.FamilyPager); ok {
+		return pager.ListFamilies(ctx, after, limit)
+	}
+	families, err := repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return query.Page(families, after, limit), nil
+}
+
+// Ensure Repository implements ports.FamilyOwnerReader
+var _ ports.FamilyOwnerReader = (*Repository)(nil)
+
+// CountByOwner counts the families of an owner in the primary repository, like the other
+// counts, which are not shadowed
+func (r *Repository) CountByOwner(ctx context.Context, ownerID string, excluded []string) (ports.FamilyCounts, error) {
+	return ports.CountFamiliesByOwner(ctx, r.primary, ownerID, excluded)
+}
+
+// ListOwnedFamilies reads a page of the families of an owner from the primary repository and
+// shadows the read. Either repository that does not implement ports.FamilyOwnerReader is
+// read with Find.
+func (r *Repository) ListOwnedFamilies(ctx context.Context, ownerID, after string, limit int) ([]*entity.Family, error) {
+	families, err := ports.ListFamiliesByOwner(ctx, r.primary, ownerID, after, limit)
+	r.compare(ctx, "list_owned_families", []zap.Field{zap.String("owner_id", ownerID), zap.String("after", after), zap.Int("limit", limit)}, families, err,
+		func(ctx context.Context) ([]*entity.Family, error) {
+			return ports.ListFamiliesByOwner(ctx, r.shadow, ownerID, after, limit)
+		})
+	return families, err
+}
+
+// Ensure Repository implements ports.FamilyFilterPager
+var _ ports.FamilyFilterPager = (*Repository)(nil)
+
+// FindPage finds a page of the families that match a filter in the primary repository and
+// shadows the read. Either repository that does not implement ports.FamilyFilterPager is
+// read with Find.
+func (r *Repository) FindPage(ctx context.Context, filter query.Filter, after string, limit int) ([]*entity.Family, error) {
+	families, err := ports.FindFamiliesPage(ctx, r.primary, filter, after, limit)
+	r.compare(ctx, "find_page", []zap.Field{zap.Any("filter", filter), zap.String("after", after), zap.Int("limit", limit)}, families, err,
+		func(ctx context.Context) ([]*entity.Family, error) {
+			return ports.FindFamiliesPage(ctx, r.shadow, filter, after, limit)
+		})
+	return families, err
+}
+
+// Ensure Repository implements ports.FamilyBatchReader
+var _ ports.FamilyBatchReader = (*Repository)(nil)
+
+// GetByIDs retrieves families by ID from the primary repository and shadows the read.
+// Either repository that does not implement ports.FamilyBatchReader is read with GetByID.
+func (r *Repository) GetByIDs(ctx context.Context, ids []string) ([]*entity.Family, error) {
+	families, err := ports.GetFamiliesByID(ctx, r.primary, ids)
+	r.compare(ctx, "get_by_ids", []zap.Field{zap.Int("count", len(ids))}, families, err,
+		func(ctx context.Context) ([]*entity.Family, error) {
+			return ports.GetFamiliesByID(ctx, r.shadow, ids)
+		})
+	return families, err
+}
+
+// Stop stops shadowing reads and waits until the comparisons in flight have finished
+// or the context is done
+func (r *Repository) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// compare samples a read and, if it is sampled, runs the shadow read in the background
+// and compares its result with the result of the primary. Reads that failed on the
+// primary for reasons other than a missing family are not shadowed.
+func (r *Repository) compare(ctx context.Context, operation string, fields []zap.Field, families []*entity.Family, err error, read func(ctx context.Context) ([]*entity.Family, error)) {
+	if err != nil && !isNotFound(err) {
+		return
+	}
+	if r.random() >= r.cfg.SampleRate {
+		return
+	}
+
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return
+	}
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		r.mu.Unlock()
+		metrics.RepositoryShadowComparisons.WithLabelValues(operation, ResultDropped).Inc()
+		return
+	}
+	r.inFlight.Add(1)
+	r.mu.Unlock()
+
+	// Convert the primary's result now, since the caller may modify the families
+	expected := toDTOs(families)
+
+	// The shadow read must outlive the request but keeps its values, such as the tenant
+	shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.cfg.Timeout)
+	go func() {
+		defer r.inFlight.Done()
+		defer func() { <-r.slots }()
+		defer cancel()
+
+		actual, err := read(shadowCtx)
+		if err != nil && !isNotFound(err) {
+			r.report(shadowCtx, operation, ResultShadowError, fields, zap.Error(err))
+			return
+		}
+
+		differences := Diff(expected, toDTOs(actual))
+		if len(differences) > 0 {
+			r.report(shadowCtx, operation, ResultMismatch, fields, zap.Strings("differences", differences))
+			return
+		}
+		metrics.RepositoryShadowComparisons.WithLabelValues(operation, ResultMatch).Inc()
+	}()
+}
+
+// report counts a failed comparison and logs it if it is sampled
+func (r *Repository) report(ctx context.Context, operation, result string, fields []zap.Field, detail zap.Field) {
+	metrics.RepositoryShadowComparisons.WithLabelValues(operation, result).Inc()
+	if r.random() >= r.cfg.DiffSampleRate {
+		return
+	}
+
+	message := "Shadow repository returned different results"
+	if result == ResultShadowError {
+		message = "Shadow repository read failed"
+	}
+	logFields := append([]zap.Field{zap.String("operation", operation)}, fields...)
+	r.logger.Warn(ctx, message, append(logFields, detail)...)
+}
+
+// Diff returns the differences between the families returned by the primary and the
+// shadow repository, at most maxDifferences of them. Families are matched by ID, so the
+// order of the results does not matter; the order of the members of a family does.
+func Diff(expected, actual []entity.FamilyDTO) []string {
+	d := &differ{}
+
+	byID := make(map[string]entity.FamilyDTO, len(actual))
+	for _, fam := range actual {
+		byID[fam.ID] = fam
+	}
+	for _, want := range expected {
+		got, ok := byID[want.ID]
+		if !ok {
+			d.add("family %s: missing in shadow", want.ID)
+			continue
+		}
+		delete(byID, want.ID)
+		d.family(want, got)
+	}
+
+	extra := make([]string, 0, len(byID))
+	for id := range byID {
+		extra = append(extra, id)
+	}
+	sort.Strings(extra)
+	for _, id := range extra {
+		d.add("family %s: only in shadow", id)
+	}
+
+	if d.omitted > 0 {
+		d.differences = append(d.differences, fmt.Sprintf("%d more differences", d.omitted))
+	}
+	return d.differences
+}
+
+// differ collects differences up to the limit
+type differ struct {
+	differences []string
+	omitted     int
+}
+
+// add records a difference, or counts it as omitted once the limit is reached
+func (d *differ) add(format string, args ...interface{}) {
+	if len(d.differences) >= maxDifferences {
+		d.omitted++
+		return
+	}
+	d.differences = append(d.differences, fmt.Sprintf(format, args...))
+}
+
+// family records the differences between two versions of a family
+func (d *differ) family(want, got entity.FamilyDTO) {
+	path := "family " + want.ID
+	for _, c := range diff.Families(want, got) {
+		switch c.Kind {
+		case diff.KindAdded:
+			d.add("%s.%s: only in shadow", path, c.Path)
+		case diff.KindRemoved:
+			d.add("%s.%s: missing in shadow", path, c.Path)
+		default:
+			d.add("%s.%s: %q in primary, %q in shadow", path, c.Path, c.Before, c.After)
+		}
+	}
+}
+
+// single returns a family as a result list, which is empty if there is no family
+func single(fam *entity.Family) []*entity.Family {
+	if fam == nil {
+		return nil
+	}
+	return []*entity.Family{fam}
+}
+
+// toDTOs converts the families of a result to DTOs
+func toDTOs(families []*entity.Family) []entity.FamilyDTO {
+	dtos := make([]entity.FamilyDTO, 0, len(families))
+	for _, fam := range families {
+		if fam != nil {
+			dtos = append(dtos, fam.ToDTO())
+		}
+	}
+	return dtos
+}
+
+// isNotFound reports whether an error reports a missing family. A missing family is
+// treated as an empty result, so that backends that report it differently still match.
+func isNotFound(err error) bool {
+	var notFound *errors.NotFoundError
+	return stderrors.As(err, &notFound)
+}
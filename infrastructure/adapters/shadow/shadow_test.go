@@ -0,0 +1,297 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package shadow
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/metrics"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/servicelib/errors"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// IDs of the test families and parents
+const (
+	family1 = "0d8f4f5e-1c1a-4b9e-9a51-6b2d7c1e0a01"
+	family2 = "0d8f4f5e-1c1a-4b9e-9a51-6b2d7c1e0a02"
+	parent1 = "5b7e2c3d-8a4f-4e6b-b1c9-2f3a4d5e6f01"
+	parent2 = "5b7e2c3d-8a4f-4e6b-b1c9-2f3a4d5e6f02"
+)
+
+// fakeRepository is an in-memory FamilyRepository for the shadowing tests
+type fakeRepository struct {
+	mu       sync.Mutex
+	families map[string]*entity.Family
+	err      error
+	block    chan struct{} // If set, reads wait until it is closed
+	saveErr  error
+	saves    int
+}
+
+func newFakeRepository(families ...*entity.Family) *fakeRepository {
+	r := &fakeRepository{families: make(map[string]*entity.Family)}
+	for _, fam := range families {
+		r.families[fam.ID()] = fam
+	}
+	return r
+}
+
+func (r *fakeRepository) wait() error {
+	if r.block != nil {
+		<-r.block
+	}
+	return r.err
+}
+
+func (r *fakeRepository) GetByID(ctx context.Context, id string) (*entity.Family, error) {
+	if err := r.wait(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fam, ok := r.families[id]
+	if !ok {
+		return nil, errors.NewNotFoundError("Family", id, nil)
+	}
+	return fam, nil
+}
+
+func (r *fakeRepository) GetAll(ctx context.Context) ([]*entity.Family, error) {
+	if err := r.wait(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	families := make([]*entity.Family, 0, len(r.families))
+	for _, fam := range r.families {
+		families = append(families, fam)
+	}
+	return families, nil
+}
+
+func (r *fakeRepository) Save(ctx context.Context, fam *entity.Family) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+	r.saves++
+	r.families[fam.ID()] = fam
+	return nil
+}
+
+func (r *fakeRepository) FindByParentID(ctx context.Context, parentID string) ([]*entity.Family, error) {
+	return r.GetAll(ctx)
+}
+
+func (r *fakeRepository) FindByChildID(ctx context.Context, childID string) (*entity.Family, error) {
+	return nil, r.wait()
+}
+
+func (r *fakeRepository) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	return r.GetAll(ctx)
+}
+
+func (r *fakeRepository) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	return r.GetAll(ctx)
+}
+
+func (r *fakeRepository) CountFamilies(ctx context.Context) (int, error) {
+	families, err := r.GetAll(ctx)
+	return len(families), err
+}
+
+func (r *fakeRepository) CountParents(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeRepository) CountChildren(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// newTestFamily creates a single-parent family
+func newTestFamily(t *testing.T, id, parentID, firstName string) *entity.Family {
+	t.Helper()
+
+	parent, err := entity.NewParent(parentID, firstName, "Shadow", time.Date(1980, time.January, 2, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := entity.NewFamily(id, entity.Single, []*entity.Parent{parent}, nil)
+	require.NoError(t, err)
+	return fam
+}
+
+// newTestRepository creates a shadowing repository that shadows every read
+func newTestRepository(primary, shadow *fakeRepository, maxConcurrency int) *Repository {
+	return NewRepository(primary, shadow, config.ShadowConfig{
+		Enabled:        true,
+		SampleRate:     1,
+		DiffSampleRate: 1,
+		MaxConcurrency: maxConcurrency,
+		Timeout:        time.Second,
+	}, logging.NewContextLogger(zap.NewNop()))
+}
+
+// comparisons returns the number of comparisons with the given operation and result
+func comparisons(operation, result string) float64 {
+	return testutil.ToFloat64(metrics.RepositoryShadowComparisons.WithLabelValues(operation, result))
+}
+
+func TestRepository_Match(t *testing.T) {
+	metrics.ResetMetrics()
+	fam := newTestFamily(t, family1, parent1, "Ada")
+	repo := newTestRepository(newFakeRepository(fam), newFakeRepository(fam), 4)
+
+	retrieved, err := repo.GetByID(context.Background(), family1)
+	require.NoError(t, err)
+	assert.Same(t, fam, retrieved)
+
+	// A missing family matches however it is reported
+	_, err = repo.GetByID(context.Background(), "missing")
+	assert.Error(t, err)
+	_, err = repo.FindByChildID(context.Background(), "missing")
+	assert.NoError(t, err)
+
+	require.NoError(t, repo.Stop(context.Background()))
+	assert.Equal(t, 2.0, comparisons("get_by_id", ResultMatch))
+	assert.Equal(t, 1.0, comparisons("find_by_child_id", ResultMatch))
+}
+
+func TestRepository_Mismatch(t *testing.T) {
+	metrics.ResetMetrics()
+	primary := newFakeRepository(newTestFamily(t, family1, parent1, "Ada"))
+	shadow := newFakeRepository(newTestFamily(t, family1, parent1, "Bea"), newTestFamily(t, family2, parent2, "Cy"))
+	repo := newTestRepository(primary, shadow, 4)
+
+	families, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, families, 1, "clients must receive the primary's result")
+
+	require.NoError(t, repo.Stop(context.Background()))
+	assert.Equal(t, 1.0, comparisons("get_all", ResultMismatch))
+}
+
+func TestRepository_ShadowErrorDoesNotAffectResponse(t *testing.T) {
+	metrics.ResetMetrics()
+	fam := newTestFamily(t, family1, parent1, "Ada")
+	shadow := newFakeRepository(fam)
+	shadow.err = stderrors.New("connection refused")
+	repo := newTestRepository(newFakeRepository(fam), shadow, 4)
+
+	retrieved, err := repo.GetByID(context.Background(), family1)
+	require.NoError(t, err)
+	assert.Same(t, fam, retrieved)
+
+	require.NoError(t, repo.Stop(context.Background()))
+	assert.Equal(t, 1.0, comparisons("get_by_id", ResultShadowError))
+}
+
+func TestRepository_DropsReadsWhenShadowIsBusy(t *testing.T) {
+	metrics.ResetMetrics()
+	fam := newTestFamily(t, family1, parent1, "Ada")
+	shadow := newFakeRepository(fam)
+	shadow.block = make(chan struct{})
+	repo := newTestRepository(newFakeRepository(fam), shadow, 1)
+
+	// The first read occupies the only slot; the second is answered without shadowing
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetByID(context.Background(), family1)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1.0, comparisons("get_by_id", ResultDropped))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, repo.Stop(ctx), context.DeadlineExceeded)
+
+	close(shadow.block)
+	require.NoError(t, repo.Stop(context.Background()))
+	assert.Equal(t, 1.0, comparisons("get_by_id", ResultMatch))
+}
+
+func TestRepository_SaveGoesToPrimaryOnly(t *testing.T) {
+	primary, shadow := newFakeRepository(), newFakeRepository()
+	repo := newTestRepository(primary, shadow, 4)
+
+	require.NoError(t, repo.Save(context.Background(), newTestFamily(t, family1, parent1, "Ada")))
+	assert.Equal(t, 1, primary.saves)
+	assert.Equal(t, 0, shadow.saves)
+}
+
+func TestRepository_DualWrite(t *testing.T) {
+	metrics.ResetMetrics()
+	primary, shadow := newFakeRepository(), newFakeRepository()
+	repo := newTestRepository(primary, shadow, 4)
+	repo.cfg.DualWrite = true
+
+	// A save that was written to both repositories reads the same from both
+	fam := newTestFamily(t, family1, parent1, "Ada")
+	require.NoError(t, repo.Save(context.Background(), fam))
+	assert.Equal(t, 1, primary.saves)
+	assert.Equal(t, 1, shadow.saves)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.RepositoryDualWrites.WithLabelValues(ResultWritten)))
+
+	// A failed shadow write is not returned, and the divergence shows in later reads
+	shadow.saveErr = stderrors.New("connection refused")
+	require.NoError(t, repo.Save(context.Background(), newTestFamily(t, family1, parent1, "Bea")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.RepositoryDualWrites.WithLabelValues(ResultFailed)))
+
+	_, err := repo.GetByID(context.Background(), family1)
+	require.NoError(t, err)
+	require.NoError(t, repo.Stop(context.Background()))
+	assert.Equal(t, 1.0, comparisons("get_by_id", ResultMismatch))
+}
+
+func TestRepository_DualWriteSkipsFailedPrimaryWrites(t *testing.T) {
+	metrics.ResetMetrics()
+	primary, shadow := newFakeRepository(), newFakeRepository()
+	primary.saveErr = stderrors.New("constraint violation")
+	repo := newTestRepository(primary, shadow, 4)
+	repo.cfg.DualWrite = true
+
+	err := repo.Save(context.Background(), newTestFamily(t, family1, parent1, "Ada"))
+	assert.ErrorIs(t, err, primary.saveErr)
+	assert.Equal(t, 0, shadow.saves)
+}
+
+func TestDiff(t *testing.T) {
+	fam := newTestFamily(t, family1, parent1, "Ada").ToDTO()
+
+	renamed := newTestFamily(t, family1, parent1, "Bea").ToDTO()
+	assert.Equal(t, []string{"family " + family1 + `.parents[0].firstName: "Ada" in primary, "Bea" in shadow`}, Diff([]entity.FamilyDTO{fam}, []entity.FamilyDTO{renamed}))
+
+	other := newTestFamily(t, family2, parent2, "Cy").ToDTO()
+	assert.Empty(t, Diff([]entity.FamilyDTO{fam, other}, []entity.FamilyDTO{other, fam}), "order of results does not matter")
+	assert.Equal(t, []string{"family " + family2 + ": missing in shadow"}, Diff([]entity.FamilyDTO{fam, other}, []entity.FamilyDTO{fam}))
+	assert.Equal(t, []string{"family " + family2 + ": only in shadow"}, Diff([]entity.FamilyDTO{fam}, []entity.FamilyDTO{fam, other}))
+
+	// Missing and empty external IDs are equal
+	withEmpty := fam
+	withEmpty.ExternalIDs = map[string]string{}
+	assert.Empty(t, Diff([]entity.FamilyDTO{fam}, []entity.FamilyDTO{withEmpty}))
+
+	// Name histories are compared entry by entry
+	married := fam
+	married.Parents = append([]entity.ParentDTO(nil), fam.Parents...)
+	married.Parents[0].NameHistory = []entity.NameChange{{FirstName: "Ada", LastName: "Shadow", EffectiveDate: time.Date(1980, time.January, 2, 0, 0, 0, 0, time.UTC)}}
+	assert.Equal(t, []string{"family " + family1 + ".parents[0].nameHistory[0]: missing in shadow"}, Diff([]entity.FamilyDTO{married}, []entity.FamilyDTO{fam}))
+
+	// Differences are capped
+	many := make([]entity.FamilyDTO, 0, maxDifferences+2)
+	for i := 0; i < maxDifferences+2; i++ {
+		many = append(many, entity.FamilyDTO{ID: string(rune('a' + i))})
+	}
+	differences := Diff(many, nil)
+	require.Len(t, differences, maxDifferences+1)
+	assert.Equal(t, "2 more differences", differences[maxDifferences])
+}
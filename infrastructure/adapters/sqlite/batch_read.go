@@ -0,0 +1,46 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"go.uber.org/zap"
+)
+
+// maxBatchReadIDs is the number of IDs read by one query of GetByIDs, which stays below the
+// limit of SQLite on the parameters of a statement
+const maxBatchReadIDs = 500
+
+// Ensure SQLiteFamilyRepository implements ports.FamilyBatchReader
+var _ ports.FamilyBatchReader = (*SQLiteFamilyRepository)(nil)
+
+// GetByIDs reads the families with the given IDs, with their versions, in ascending ID
+// order, with one query for every 500 IDs
+func (r *SQLiteFamilyRepository) GetByIDs(ctx context.Context, ids []string) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Getting families by IDs from SQLite", zap.Int("count", len(ids)))
+
+	families := make([]*entity.Family, 0, len(ids))
+	for start := 0; start < len(ids); start += maxBatchReadIDs {
+		batch := ids[start:min(start+maxBatchReadIDs, len(ids))]
+		args := make([]interface{}, len(batch), len(batch)+1)
+		for i, id := range batch {
+			args[i] = id
+		}
+		args = append(args, tenantOf(ctx))
+
+		read, err := r.queryFamilies(ctx, "GetByIDs",
+			"SELECT f.id, f.status, f.parents, f.children, f.external_ids, f.owner_id, (SELECT version FROM families v WHERE v.id = f.id) FROM "+r.familyRows()+
+				" f WHERE f.id IN (?"+strings.Repeat(", ?", len(batch)-1)+") AND "+inTenant+" AND "+notDeleted+" ORDER BY f.id", args...)
+		if err != nil {
+			return nil, err
+		}
+		families = append(families, read...)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].ID() < families[j].ID() })
+	return families, nil
+}
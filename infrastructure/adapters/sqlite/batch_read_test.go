@@ -0,0 +1,44 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetByIDs(t *testing.T) {
+	repo, db, ctrl := setupTest(t)
+	defer ctrl.Finish()
+	defer db.Close()
+
+	ctx := context.Background()
+	families := querytest.Families(t)
+	require.GreaterOrEqual(t, len(families), 3)
+	for _, fam := range families {
+		require.NoError(t, repo.Save(ctx, fam))
+	}
+	require.NoError(t, repo.Save(ctx, families[0]))
+
+	// The families are read with their versions, in ID order, without the missing IDs
+	ids := []string{families[2].ID(), "missing", families[0].ID()}
+	got, err := repo.GetByIDs(ctx, ids)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	for _, fam := range got {
+		stored, err := repo.GetByID(ctx, fam.ID())
+		require.NoError(t, err)
+		assert.Positive(t, fam.Version())
+		assert.Equal(t, stored.Version(), fam.Version())
+		assert.Equal(t, stored.ToDTO(), fam.ToDTO())
+	}
+	assert.Less(t, got[0].ID(), got[1].ID())
+
+	got, err = repo.GetByIDs(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/codec"
+	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+)
+
+// The numbers of parents and children of a family are stored in the parent_count and
+// children_count columns when the family is saved, since the members may be stored in a
+// binary encoding that SQLite cannot evaluate. Rows saved before the columns were added
+// have NULL counts until they are saved again; filters on counts select them as
+// candidates, so that their counts are checked on the decoded families.
+const (
+	// addCountColumns adds the count columns to families tables created before the counts
+	// were stored
+	addCountColumns = `
+	ALTER TABLE families ADD COLUMN parent_count INTEGER;
+	ALTER TABLE families ADD COLUMN children_count INTEGER;
+	`
+
+	// countColumnsExist counts the count columns of the families table
+	countColumnsExist = "SELECT COUNT(*) FROM pragma_table_info('families') WHERE name IN ('parent_count', 'children_count')"
+
+	createParentCountIndex = `
+	CREATE INDEX IF NOT EXISTS idx_families_parent_count ON families (parent_count);
+	`
+	createChildrenCountIndex = `
+	CREATE INDEX IF NOT EXISTS idx_families_children_count ON families (children_count);
+	`
+)
+
+// countColumns are the columns of the count fields
+var countColumns = map[query.Field]string{
+	query.FieldParentCount: "parent_count",
+	query.FieldChildCount:  "children_count",
+}
+
+// ensureCountColumns adds the count columns to families tables created before the counts
+// were stored and creates their indexes
+func (r *SQLiteFamilyRepository) ensureCountColumns(ctx context.Context) error {
+	var count int
+	if err := r.DB.QueryRowContext(ctx, countColumnsExist).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		if _, err := r.DB.ExecContext(ctx, addCountColumns); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.DB.ExecContext(ctx, createParentCountIndex+createChildrenCountIndex)
+	return err
+}
+
+// countPrefilter translates a condition on a count into a SQL condition on its column
+// that also selects the rows whose counts have not been stored yet
+func countPrefilter(column string, c query.Condition) (string, []interface{}) {
+	if c.Op == query.OpIn {
+		values := c.Value.([]int)
+		if len(values) == 0 {
+			return "0", nil
+		}
+		args := make([]interface{}, len(values))
+		for i, v := range values {
+			args[i] = v
+		}
+		return fmt.Sprintf("(%s IS NULL OR %s IN (%s))", column, column, strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")), args
+	}
+	return fmt.Sprintf("(%s IS NULL OR %s %s ?)", column, column, sqlOperators[c.Op]), []interface{}{c.Value}
+}
+
+// sqlOperators are the SQL comparison operators of the query operators
+var sqlOperators = map[query.Op]string{
+	query.OpEq:  "=",
+	query.OpNe:  "<>",
+	query.OpLt:  "<",
+	query.OpLte: "<=",
+	query.OpGt:  ">",
+	query.OpGte: ">=",
+}
+
+// Statements of the counts of the families and members of a scope, such as the families of
+// a tenant, whose condition is formatted into them. A member of several families, such as a
+// remarried parent, is counted once. With the json layout, the members of each row are
+// counted in the JSON of its blob; members stored in a binary encoding are decoded.
+const (
+	countFamiliesQuery = "SELECT COUNT(*) FROM families WHERE %s"
+
+	// countNormalizedMembersQuery counts the members of a role with the index of family_members
+	countNormalizedMembersQuery = "SELECT COUNT(DISTINCT member_id) FROM family_members " +
+		"WHERE role = ? AND family_id IN (SELECT families.id FROM families WHERE %s)"
+
+	// jsonMembers selects the IDs of the members of a column in the rows stored as JSON
+	jsonMembers = "SELECT DISTINCT COALESCE(json_extract(member.value, '$.id'), json_extract(member.value, '$.ID')) " +
+		"FROM families, json_each(families.%[1]s) AS member WHERE %[2]s AND json_valid(families.%[1]s)"
+
+	// binaryMembers selects the blobs of a member column that are not JSON
+	binaryMembers = "SELECT %[1]s FROM families WHERE %[2]s AND NOT json_valid(%[1]s)"
+)
+
+// familyScope is the condition on the rows of the families table that are counted, with its
+// arguments. Its columns are qualified with the table, since json_each has an id column.
+type familyScope struct {
+	where string
+	args  []interface{}
+}
+
+// tenantScope returns the scope of the families of the tenant of the context
+func tenantScope(ctx context.Context) familyScope {
+	return familyScope{
+		where: "families.tenant_id = ? AND families.deleted_at IS NULL",
+		args:  []interface{}{tenantOf(ctx)},
+	}
+}
+
+// CountFamilies counts the families that are not deleted
+func (r *SQLiteFamilyRepository) CountFamilies(ctx context.Context) (int, error) {
+	r.logger.Debug(ctx, "Counting families in SQLite")
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return 0, err
+	}
+	scope := tenantScope(ctx)
+	var count int
+	if err := r.DB.QueryRowContext(ctx, fmt.Sprintf(countFamiliesQuery, scope.where), scope.args...).Scan(&count); err != nil {
+		return 0, repoerrors.NewRepositoryError(err, "failed to count families", repoerrors.SQLiteErrorCode, "families")
+	}
+	return count, nil
+}
+
+// CountParents counts the distinct parents of the families that are not deleted
+func (r *SQLiteFamilyRepository) CountParents(ctx context.Context) (int, error) {
+	r.logger.Debug(ctx, "Counting parents in SQLite")
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return 0, err
+	}
+	return r.countMembers(ctx, tenantScope(ctx), "parents", roleParent, decodeParentIDs)
+}
+
+// CountChildren counts the distinct children of the families that are not deleted
+func (r *SQLiteFamilyRepository) CountChildren(ctx context.Context) (int, error) {
+	r.logger.Debug(ctx, "Counting children in SQLite")
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return 0, err
+	}
+	return r.countMembers(ctx, tenantScope(ctx), "children", roleChild, decodeChildIDs)
+}
+
+// decodeParentIDs returns the IDs of the parents of a binary blob
+func decodeParentIDs(data string) ([]string, error) {
+	var parents []entity.ParentDTO
+	if err := codec.DecodeParents([]byte(data), &parents); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(parents))
+	for i, p := range parents {
+		ids[i] = p.ID
+	}
+	return ids, nil
+}
+
+// decodeChildIDs returns the IDs of the children of a binary blob
+func decodeChildIDs(data string) ([]string, error) {
+	var children []entity.ChildDTO
+	if err := codec.DecodeChildren([]byte(data), &children); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(children))
+	for i, c := range children {
+		ids[i] = c.ID
+	}
+	return ids, nil
+}
+
+// countMembers counts the distinct members of a column in the families of a scope, whose
+// binary blobs are decoded into member IDs by decode. The IDs of the JSON blobs are only read
+// if there are binary blobs, whose members may also be members of JSON blobs; otherwise
+// SQLite counts them.
+func (r *SQLiteFamilyRepository) countMembers(ctx context.Context, scope familyScope, column, role string, decode func(string) ([]string, error)) (int, error) {
+	failed := func(err error) (int, error) {
+		return 0, repoerrors.NewRepositoryError(err, "failed to count "+column, repoerrors.SQLiteErrorCode, "families")
+	}
+
+	var count int
+	if r.layout == layoutNormalized {
+		args := append([]interface{}{role}, scope.args...)
+		if err := r.DB.QueryRowContext(ctx, fmt.Sprintf(countNormalizedMembersQuery, scope.where), args...).Scan(&count); err != nil {
+			return failed(err)
+		}
+		return count, nil
+	}
+
+	ids := make(map[string]bool)
+	if err := r.scanStrings(ctx, fmt.Sprintf(binaryMembers, column, scope.where), scope.args, func(data string) error {
+		members, err := decode(data)
+		for _, id := range members {
+			ids[id] = true
+		}
+		return err
+	}); err != nil {
+		return failed(err)
+	}
+	if len(ids) == 0 {
+		if err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+fmt.Sprintf(jsonMembers, column, scope.where)+")", scope.args...).Scan(&count); err != nil {
+			return failed(err)
+		}
+		return count, nil
+	}
+
+	if err := r.scanStrings(ctx, fmt.Sprintf(jsonMembers, column, scope.where), scope.args, func(id string) error {
+		ids[id] = true
+		return nil
+	}); err != nil {
+		return failed(err)
+	}
+	return len(ids), nil
+}
+
+// scanStrings calls fn with the string of each row of a query of one column that is not NULL,
+// run with the given arguments
+func (r *SQLiteFamilyRepository) scanStrings(ctx context.Context, query string, args []interface{}, fn func(string) error) error {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value sql.NullString
+		if err := rows.Scan(&value); err != nil {
+			return err
+		}
+		if !value.Valid {
+			continue
+		}
+		if err := fn(value.String); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/codec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCountMembers_MixedCodecs counts members stored as JSON and in a binary encoding once
+func TestCountMembers_MixedCodecs(t *testing.T) {
+	repo, db, ctrl := setupTest(t)
+	defer ctrl.Finish()
+	defer db.Close()
+	ctx := context.Background()
+
+	birthDate := time.Date(1980, time.March, 4, 0, 0, 0, 0, time.UTC)
+	newFamily := func(parentID string) *entity.Family {
+		parent, err := entity.NewParent(parentID, "Jane", "Doe", birthDate, nil)
+		require.NoError(t, err)
+		child, err := entity.NewChild(generateTestUUID(), "Ann", "Doe", birthDate.AddDate(30, 0, 0), nil)
+		require.NoError(t, err)
+		fam, err := entity.NewFamily(generateTestUUID(), entity.Single, []*entity.Parent{parent}, []*entity.Child{child})
+		require.NoError(t, err)
+		return fam
+	}
+
+	// The parent of both families is stored once as JSON and once as protobuf
+	parentID := generateTestUUID()
+	require.NoError(t, repo.Save(ctx, newFamily(parentID)))
+	protobuf, err := codec.New(codec.Protobuf)
+	require.NoError(t, err)
+	repo.codec = protobuf
+	require.NoError(t, repo.Save(ctx, newFamily(parentID)))
+	require.NoError(t, repo.Save(ctx, newFamily(generateTestUUID())))
+
+	families, err := repo.CountFamilies(ctx)
+	require.NoError(t, err)
+	parents, err := repo.CountParents(ctx)
+	require.NoError(t, err)
+	children, err := repo.CountChildren(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 2, 3}, []int{families, parents, children})
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+)
+
+// Outbox messages set aside by operators are kept in the event_outbox_dead_letters table, in
+// the order of seq, until they are replayed or purged. The table is created with the outbox.
+const createOutboxDeadLettersSchema = `
+	CREATE TABLE IF NOT EXISTS event_outbox_dead_letters (
+		seq INTEGER PRIMARY KEY AUTOINCREMENT,
+		id TEXT NOT NULL UNIQUE,
+		type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		dead_lettered_at TEXT NOT NULL
+	);
+	`
+
+// Ensure SQLiteFamilyRepository implements ports.OutboxAdministrator
+var _ ports.OutboxAdministrator = (*SQLiteFamilyRepository)(nil)
+
+// DeadLetterOutboxMessages moves the messages with the given IDs from the outbox to the dead
+// letters, in one transaction, and returns the number of messages moved
+func (r *SQLiteFamilyRepository) DeadLetterOutboxMessages(ctx context.Context, ids []string, reason string, at time.Time) (int, error) {
+	return r.moveOutboxMessages(ctx, ids, "event_outbox", `
+		INSERT INTO event_outbox_dead_letters (id, type, payload, created_at, reason, dead_lettered_at)
+		SELECT id, type, payload, created_at, ?, ? FROM event_outbox WHERE id IN (%s) ORDER BY seq
+	`, reason, at.UTC().Format(time.RFC3339Nano))
+}
+
+// DeadLetters returns at most limit dead letters of the given type, or of every type if it is
+// empty, in the order in which they were set aside
+func (r *SQLiteFamilyRepository) DeadLetters(ctx context.Context, eventType string, limit int) ([]ports.DeadLetter, error) {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, type, payload, created_at, reason, dead_lettered_at
+		FROM event_outbox_dead_letters WHERE (?1 = '' OR type = ?1) ORDER BY seq LIMIT ?2
+	`, eventType, limit)
+	if err != nil {
+		return nil, repoerrors.NewRepositoryError(err, "failed to read dead letters", repoerrors.SQLiteErrorCode, "event_outbox_dead_letters")
+	}
+	defer rows.Close()
+
+	letters := []ports.DeadLetter{}
+	for rows.Next() {
+		var letter ports.DeadLetter
+		var payload, createdAt, deadLetteredAt string
+		if err := rows.Scan(&letter.ID, &letter.Type, &payload, &createdAt, &letter.Reason, &deadLetteredAt); err != nil {
+			return nil, repoerrors.NewRepositoryError(err, "failed to scan dead letter", repoerrors.SQLiteErrorCode, "event_outbox_dead_letters")
+		}
+		if letter.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err == nil {
+			letter.DeadLetteredAt, err = time.Parse(time.RFC3339Nano, deadLetteredAt)
+		}
+		if err != nil {
+			return nil, repoerrors.NewRepositoryError(err, "failed to parse dead letter time", repoerrors.SQLiteErrorCode, "event_outbox_dead_letters")
+		}
+		letter.Payload = []byte(payload)
+		letters = append(letters, letter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repoerrors.NewRepositoryError(err, "error iterating over dead letters", repoerrors.SQLiteErrorCode, "event_outbox_dead_letters")
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetters moves the dead letters with the given IDs back to the end of the outbox,
+// in the order in which they were set aside, in one transaction, and returns the number of
+// messages moved
+func (r *SQLiteFamilyRepository) ReplayDeadLetters(ctx context.Context, ids []string) (int, error) {
+	return r.moveOutboxMessages(ctx, ids, "event_outbox_dead_letters", `
+		INSERT INTO event_outbox (id, type, payload, created_at)
+		SELECT id, type, payload, created_at FROM event_outbox_dead_letters WHERE id IN (%s) ORDER BY seq
+	`)
+}
+
+// PurgeDeadLetters removes the dead letters with the given IDs for good and returns the
+// number of messages removed
+func (r *SQLiteFamilyRepository) PurgeDeadLetters(ctx context.Context, ids []string) (int, error) {
+	return r.moveOutboxMessages(ctx, ids, "event_outbox_dead_letters", "")
+}
+
+// moveOutboxMessages copies the messages with the given IDs out of a table with the copy
+// statement, whose %s is replaced by the placeholders of the IDs and whose other arguments
+// precede them, and deletes them from the table, with one pair of statements for every 500
+// IDs, in one transaction. Without a copy statement, the messages are only deleted.
+//
+// Returns:
+//   - The number of messages deleted from the table
+func (r *SQLiteFamilyRepository) moveOutboxMessages(ctx context.Context, ids []string, table, copyStatement string, copyArgs ...interface{}) (int, error) {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return 0, err
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return 0, repoerrors.NewRepositoryError(err, "failed to begin transaction", repoerrors.SQLiteErrorCode, table)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	moved := 0
+	for start := 0; start < len(ids); start += maxBatchReadIDs {
+		batch := ids[start:min(start+maxBatchReadIDs, len(ids))]
+		args := make([]interface{}, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		placeholders := "?" + strings.Repeat(", ?", len(batch)-1)
+
+		if copyStatement != "" {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(copyStatement, placeholders), append(append([]interface{}{}, copyArgs...), args...)...); err != nil {
+				return 0, repoerrors.NewRepositoryError(err, "failed to move outbox messages", repoerrors.SQLiteErrorCode, table)
+			}
+		}
+		result, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE id IN ("+placeholders+")", args...)
+		if err != nil {
+			return 0, repoerrors.NewRepositoryError(err, "failed to remove outbox messages", repoerrors.SQLiteErrorCode, table)
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return 0, repoerrors.NewRepositoryError(err, "failed to remove outbox messages", repoerrors.SQLiteErrorCode, table)
+		}
+		moved += int(deleted)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, repoerrors.NewRepositoryError(err, "failed to commit transaction", repoerrors.SQLiteErrorCode, table)
+	}
+	return moved, nil
+}
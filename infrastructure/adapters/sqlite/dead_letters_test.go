@@ -0,0 +1,83 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeadLetters sets outbox messages aside, replays them to the end of the outbox, and
+// purges them
+func TestDeadLetters(t *testing.T) {
+	repo, db, ctrl := setupTest(t)
+	defer ctrl.Finish()
+	defer db.Close()
+
+	ctx := context.Background()
+	createdAt := time.Date(2025, time.March, 3, 14, 0, 0, 0, time.UTC)
+	message := func(id string) ports.OutboxMessage {
+		return ports.OutboxMessage{ID: id, Type: "family_deleted", Payload: []byte(`{"id":"` + id + `"}`), CreatedAt: createdAt}
+	}
+	pending := func() []string {
+		messages, err := repo.PendingOutboxMessages(ctx, 10)
+		require.NoError(t, err)
+		ids := make([]string, len(messages))
+		for i, m := range messages {
+			ids[i] = m.ID
+		}
+		return ids
+	}
+	require.NoError(t, repo.AddToOutbox(ctx, message("m1"), message("m2"), message("m3")))
+	restored := ports.OutboxMessage{ID: "m4", Type: "family_restored", Payload: []byte(`{"id":"m4"}`), CreatedAt: createdAt}
+	require.NoError(t, repo.AddToOutbox(ctx, restored))
+
+	// The type of the listed messages is matched before the limit
+	messages, err := repo.OutboxMessages(ctx, "family_restored", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []ports.OutboxMessage{restored}, messages)
+	require.NoError(t, repo.RemoveFromOutbox(ctx, []string{"m4"}))
+
+	// Setting messages aside lets the relay publish those after them
+	at := createdAt.Add(time.Hour)
+	moved, err := repo.DeadLetterOutboxMessages(ctx, []string{"m1", "m2", "missing"}, "rejected by the broker", at)
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	assert.Equal(t, []string{"m3"}, pending())
+
+	letters, err := repo.DeadLetters(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []ports.DeadLetter{
+		{OutboxMessage: message("m1"), Reason: "rejected by the broker", DeadLetteredAt: at},
+		{OutboxMessage: message("m2"), Reason: "rejected by the broker", DeadLetteredAt: at},
+	}, letters)
+
+	// The type is matched before the limit
+	letters, err = repo.DeadLetters(ctx, "family_deleted", 1)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, "m1", letters[0].ID)
+	letters, err = repo.DeadLetters(ctx, "family_restored", 10)
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+
+	// A replayed message goes back to the end of the outbox, with its key
+	replayed, err := repo.ReplayDeadLetters(ctx, []string{"m2", "m3"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	assert.Equal(t, []string{"m3", "m2"}, pending())
+
+	// A purged message is removed for good
+	purged, err := repo.PurgeDeadLetters(ctx, []string{"m1"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	letters, err = repo.DeadLetters(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+	assert.Equal(t, []string{"m3", "m2"}, pending())
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+	"go.uber.org/zap"
+)
+
+// Deleted families keep their row, with the time of their deletion in the deleted_at
+// column of the families table. Every read of families skips the deleted rows, and Save
+// clears the column, so a family saved again with the ID of a deleted family replaces it.
+const (
+	// addDeletedAtColumn adds the deleted_at column to families tables created before
+	// families were soft deleted
+	addDeletedAtColumn = "ALTER TABLE families ADD COLUMN deleted_at TEXT"
+
+	// deletedAtColumnExists counts the deleted_at columns of the families table
+	deletedAtColumnExists = "SELECT COUNT(*) FROM pragma_table_info('families') WHERE name = 'deleted_at'"
+
+	createDeletedAtIndex = `
+	CREATE INDEX IF NOT EXISTS idx_families_deleted_at ON families (deleted_at) WHERE deleted_at IS NOT NULL;
+	`
+
+	// notDeleted is the condition on the rows of families, or of family_documents, of the
+	// families that are not deleted. The view has no deleted_at column, so the deleted
+	// families are looked up with the partial index of the column.
+	notDeleted = "id NOT IN (SELECT id FROM families WHERE deleted_at IS NOT NULL)"
+)
+
+// Ensure SQLiteFamilyRepository implements ports.FamilyDeleter
+var _ ports.FamilyDeleter = (*SQLiteFamilyRepository)(nil)
+
+// ensureDeletedAtColumn adds the deleted_at column to families tables created before
+// families were soft deleted and creates its index
+func (r *SQLiteFamilyRepository) ensureDeletedAtColumn(ctx context.Context) error {
+	var count int
+	if err := r.DB.QueryRowContext(ctx, deletedAtColumnExists).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		if _, err := r.DB.ExecContext(ctx, addDeletedAtColumn); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.DB.ExecContext(ctx, createDeletedAtIndex)
+	return err
+}
+
+// SoftDeleteFamily marks a family as deleted and reports whether it was found and not
+// already deleted
+func (r *SQLiteFamilyRepository) SoftDeleteFamily(ctx context.Context, familyID string, deletedAt time.Time) (bool, error) {
+	r.logger.Debug(ctx, "Soft deleting family in SQLite", zap.String("family_id", familyID))
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return false, err
+	}
+
+	result, err := r.execer(ctx).ExecContext(ctx, "UPDATE families SET deleted_at = ? WHERE id = ? AND tenant_id = ? AND deleted_at IS NULL",
+		deletedAt.UTC().Format(time.RFC3339Nano), familyID, tenantOf(ctx))
+	if err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to soft delete family", repoerrors.SQLiteErrorCode, "families")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to soft delete family", repoerrors.SQLiteErrorCode, "families")
+	}
+	return deleted > 0, nil
+}
+
+// HardDeleteFamily removes a family, with its external IDs and the members that belong to
+// no other family, and reports whether it was found
+func (r *SQLiteFamilyRepository) HardDeleteFamily(ctx context.Context, familyID string) (bool, error) {
+	r.logger.Debug(ctx, "Hard deleting family in SQLite", zap.String("family_id", familyID))
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return false, err
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to begin transaction", repoerrors.SQLiteErrorCode, "families")
+	}
+	defer tx.Rollback()
+
+	// The family of another tenant is left alone, with its external IDs and members
+	var found int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM families WHERE id = ? AND tenant_id = ?", familyID, tenantOf(ctx)).Scan(&found); err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to hard delete family", repoerrors.SQLiteErrorCode, "families")
+	}
+	if found == 0 {
+		return false, nil
+	}
+
+	deleted, err := r.deleteFamily(ctx, tx, familyID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to commit transaction", repoerrors.SQLiteErrorCode, "families")
+	}
+	return deleted, nil
+}
+
+// deleteFamily removes a family, with its external IDs and the members that belong to no
+// other family, in a transaction, and reports whether it was found
+func (r *SQLiteFamilyRepository) deleteFamily(ctx context.Context, tx *transaction, familyID string) (bool, error) {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM family_external_ids WHERE family_id = ?", familyID); err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to delete external IDs", repoerrors.SQLiteErrorCode, "family_external_ids")
+	}
+	if r.layout == layoutNormalized {
+		if err := saveMembers(ctx, tx.Tx, familyID, nil, nil); err != nil {
+			return false, repoerrors.NewRepositoryError(err, "failed to delete family members", repoerrors.SQLiteErrorCode, "family_members")
+		}
+	}
+	result, err := tx.ExecContext(ctx, "DELETE FROM families WHERE id = ?", familyID)
+	if err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to hard delete family", repoerrors.SQLiteErrorCode, "families")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to hard delete family", repoerrors.SQLiteErrorCode, "families")
+	}
+	return deleted > 0, nil
+}
+
+// Ensure SQLiteFamilyRepository implements ports.FamilyRestorer
+var _ ports.FamilyRestorer = (*SQLiteFamilyRepository)(nil)
+
+// RestoreFamily clears the deletion mark of a family and reports whether it was found and
+// deleted
+func (r *SQLiteFamilyRepository) RestoreFamily(ctx context.Context, familyID string) (bool, error) {
+	r.logger.Debug(ctx, "Restoring family in SQLite", zap.String("family_id", familyID))
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return false, err
+	}
+
+	result, err := r.execer(ctx).ExecContext(ctx, "UPDATE families SET deleted_at = NULL WHERE id = ? AND tenant_id = ? AND deleted_at IS NOT NULL",
+		familyID, tenantOf(ctx))
+	if err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to restore family", repoerrors.SQLiteErrorCode, "families")
+	}
+	restored, err := result.RowsAffected()
+	if err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to restore family", repoerrors.SQLiteErrorCode, "families")
+	}
+	return restored > 0, nil
+}
+
+// PurgeDeletedFamilies removes the families deleted before a time, with their external IDs
+// and the members that belong to no other family, and returns their IDs. The times of
+// deletion are compared as Julian days, since RFC 3339 times with fractional seconds of
+// different lengths do not sort as text.
+func (r *SQLiteFamilyRepository) PurgeDeletedFamilies(ctx context.Context, deletedBefore time.Time, allTenants bool) ([]string, error) {
+	r.logger.Debug(ctx, "Purging deleted families in SQLite", zap.Time("deleted_before", deletedBefore), zap.Bool("all_tenants", allTenants))
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return nil, repoerrors.NewRepositoryError(err, "failed to begin transaction", repoerrors.SQLiteErrorCode, "families")
+	}
+	defer tx.Rollback()
+
+	query := "SELECT id FROM families WHERE deleted_at IS NOT NULL AND julianday(deleted_at) < julianday(?)"
+	args := []interface{}{deletedBefore.UTC().Format(time.RFC3339Nano)}
+	if !allTenants {
+		query += " AND tenant_id = ?"
+		args = append(args, tenantOf(ctx))
+	}
+	rows, err := tx.QueryContext(ctx, query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, repoerrors.NewRepositoryError(err, "failed to find deleted families", repoerrors.SQLiteErrorCode, "families")
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, repoerrors.NewRepositoryError(err, "failed to scan deleted family", repoerrors.SQLiteErrorCode, "families")
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, repoerrors.NewRepositoryError(err, "failed to find deleted families", repoerrors.SQLiteErrorCode, "families")
+	}
+
+	// The families are deleted once the rows are closed, since the connection of the
+	// transaction cannot run statements while it reads them
+	for _, id := range ids {
+		if _, err := r.deleteFamily(ctx, tx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, repoerrors.NewRepositoryError(err, "failed to commit transaction", repoerrors.SQLiteErrorCode, "families")
+	}
+	return ids, nil
+}
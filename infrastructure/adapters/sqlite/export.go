@@ -0,0 +1,22 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/assembly"
+)
+
+// Ensure SQLiteFamilyRepository implements ports.FamilyExporter
+var _ ports.FamilyExporter = (*SQLiteFamilyRepository)(nil)
+
+// ExportAll reads every stored family in ascending ID order and calls fn with each.
+// Families beyond the memory budget are spilled to disk until the read completes.
+func (r *SQLiteFamilyRepository) ExportAll(ctx context.Context, fn func(*entity.Family) error) error {
+	r.logger.Debug(ctx, "Exporting all families from SQLite")
+
+	return assembly.Export(ctx, r, r.memoryBudget, "ExportAll", fn)
+}
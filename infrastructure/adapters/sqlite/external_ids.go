@@ -0,0 +1,212 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+	"github.com/abitofhelp/servicelib/errors"
+	"github.com/abitofhelp/servicelib/retry"
+	"go.uber.org/zap"
+)
+
+// External IDs of families are stored in the external_ids column of the families table.
+// External IDs of parents and children are stored in the member blobs. Every external ID
+// is also indexed in the family_external_ids table, which is used to enforce uniqueness
+// per owner kind and system within a tenant and to look up families by external ID.
+const (
+	createExternalIDsTable = `
+	CREATE TABLE IF NOT EXISTS family_external_ids (
+		owner TEXT NOT NULL,
+		system TEXT NOT NULL,
+		external_id TEXT NOT NULL,
+		entity_id TEXT NOT NULL,
+		family_id TEXT NOT NULL,
+		PRIMARY KEY (owner, system, external_id, family_id)
+	);
+	`
+	createExternalIDsFamilyIndex = `
+	CREATE INDEX IF NOT EXISTS idx_family_external_ids_family_id ON family_external_ids (family_id);
+	`
+	createExternalIDsLookupIndex = `
+	CREATE INDEX IF NOT EXISTS idx_family_external_ids_lookup ON family_external_ids (system, external_id);
+	`
+	createExternalIDsSchema = createExternalIDsTable + createExternalIDsFamilyIndex + createExternalIDsLookupIndex
+
+	// addExternalIDsColumn adds the external_ids column to families tables created before
+	// external IDs were supported
+	addExternalIDsColumn = "ALTER TABLE families ADD COLUMN external_ids TEXT NOT NULL DEFAULT '{}'"
+)
+
+// ensureExternalIDsSchema adds the external_ids column to families tables created before
+// external IDs were supported and creates the external ID index table
+func (r *SQLiteFamilyRepository) ensureExternalIDsSchema(ctx context.Context) error {
+	var count int
+	err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM pragma_table_info('families') WHERE name = 'external_ids'").Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if _, err := r.DB.ExecContext(ctx, addExternalIDsColumn); err != nil {
+			return err
+		}
+	}
+
+	_, err = r.DB.ExecContext(ctx, createExternalIDsSchema)
+	return err
+}
+
+// encodeFamilyExternalIDs encodes the family's external IDs for the external_ids column
+func encodeFamilyExternalIDs(fam *entity.Family) (string, error) {
+	ids := fam.ExternalIDs()
+	if ids == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// setFamilyExternalIDs decodes the external_ids column and sets the family's external IDs
+func setFamilyExternalIDs(fam *entity.Family, data string) error {
+	if data == "" {
+		return nil
+	}
+	var ids map[string]string
+	if err := json.Unmarshal([]byte(data), &ids); err != nil {
+		return err
+	}
+	return fam.SetExternalIDs(ids)
+}
+
+// saveExternalIDs verifies that none of the family's external IDs is held by another entity
+// of the same kind and replaces the family's rows in the external ID index
+func (r *SQLiteFamilyRepository) saveExternalIDs(ctx context.Context, tx *sql.Tx, fam *entity.Family) error {
+	refs := fam.ExternalIDRefs()
+	for _, ref := range refs {
+		var entityID string
+		err := tx.QueryRowContext(ctx,
+			"SELECT entity_id FROM family_external_ids WHERE owner = ? AND system = ? AND external_id = ? AND entity_id <> ? "+
+				"AND family_id IN (SELECT id FROM families WHERE tenant_id = ?) LIMIT 1",
+			string(ref.Owner), ref.System, ref.ExternalID, ref.EntityID, tenantOf(ctx)).Scan(&entityID)
+		if err == nil {
+			r.logger.Warn(ctx, "External ID is already assigned",
+				zap.String("family_id", fam.ID()),
+				zap.String("system", ref.System),
+				zap.String("external_id", ref.ExternalID),
+				zap.String("assigned_to", entityID))
+			return errors.NewValidationError(
+				fmt.Sprintf("external ID %s:%s is already assigned to %s %s", ref.System, ref.ExternalID, strings.ToLower(string(ref.Owner)), entityID),
+				"ExternalIDs", nil)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return repoerrors.NewRepositoryError(err, "failed to check external ID uniqueness", repoerrors.SQLiteErrorCode, "family_external_ids")
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM family_external_ids WHERE family_id = ?", fam.ID()); err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to delete external IDs", repoerrors.SQLiteErrorCode, "family_external_ids")
+	}
+	for _, ref := range refs {
+		_, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO family_external_ids (owner, system, external_id, entity_id, family_id) VALUES (?, ?, ?, ?, ?)",
+			string(ref.Owner), ref.System, ref.ExternalID, ref.EntityID, fam.ID())
+		if err != nil {
+			return repoerrors.NewRepositoryError(err, "failed to save external ID", repoerrors.SQLiteErrorCode, "family_external_ids")
+		}
+	}
+	return nil
+}
+
+// FindByExternalID finds the families in which the family itself or one of its members
+// has the given external ID in the given external system
+func (r *SQLiteFamilyRepository) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Finding families by external ID in SQLite",
+		zap.String("system", system),
+		zap.String("external_id", externalID))
+
+	if system == "" {
+		return nil, errors.NewValidationError("system is required", "system", nil)
+	}
+	if externalID == "" {
+		return nil, errors.NewValidationError("external ID is required", "externalID", nil)
+	}
+
+	// Ensure table exists
+	if err := r.ensureTableExists(ctx); err != nil {
+		return nil, err
+	}
+
+	// Create a context with timeout
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var familyIDs []string
+	operation := func(ctx context.Context) error {
+		rows, err := r.DB.QueryContext(ctx,
+			"SELECT DISTINCT family_id FROM family_external_ids WHERE system = ? AND external_id = ? AND family_id IN (SELECT id FROM families WHERE tenant_id = ? AND deleted_at IS NULL) ORDER BY family_id",
+			system, externalID, tenantOf(ctx))
+		if err != nil {
+			r.logger.Error(ctx, "Failed to query external IDs", zap.Error(err))
+			return repoerrors.NewRepositoryError(err, "failed to query external IDs", repoerrors.SQLiteErrorCode, "family_external_ids")
+		}
+		defer rows.Close()
+
+		familyIDs = []string{}
+		for rows.Next() {
+			var familyID string
+			if err := rows.Scan(&familyID); err != nil {
+				return repoerrors.NewRepositoryError(err, "failed to scan external ID row", repoerrors.SQLiteErrorCode, "family_external_ids")
+			}
+			familyIDs = append(familyIDs, familyID)
+		}
+		return rows.Err()
+	}
+
+	isRetryable := func(err error) bool {
+		return retry.IsNetworkError(err) || retry.IsTimeoutError(err) || retry.IsTransientError(err)
+	}
+
+	var retryErr error
+	err := r.rateLimiter.Execute(ctxWithTimeout, "FindByExternalID", func(ctx context.Context) error {
+		return r.circuitBreaker.Execute(ctx, "FindByExternalID", func(ctx context.Context) error {
+			retryErr = retry.Do(ctx, operation, getRetryConfig(), isRetryable)
+			return retryErr
+		})
+	})
+	if err != nil {
+		if retryErr != nil {
+			if errors.IsDatabaseError(retryErr) {
+				return nil, retryErr
+			}
+			return nil, repoerrors.NewRepositoryError(retryErr, "failed to find families by external ID after retries", repoerrors.SQLiteErrorCode, "families")
+		}
+		if strings.Contains(err.Error(), "rate limit exceeded") {
+			return nil, repoerrors.NewRepositoryError(err, "rate limit exceeded", repoerrors.SQLiteErrorCode, "families")
+		}
+		return nil, repoerrors.NewRepositoryError(err, "circuit breaker is open", repoerrors.SQLiteErrorCode, "families")
+	}
+
+	families := make([]*entity.Family, 0, len(familyIDs))
+	for _, familyID := range familyIDs {
+		fam, err := r.GetByID(ctx, familyID)
+		if err != nil {
+			return nil, err
+		}
+		families = append(families, fam)
+	}
+
+	r.logger.Info(ctx, "Successfully found families by external ID",
+		zap.String("system", system),
+		zap.Int("family_count", len(families)))
+	return families, nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/codec"
+	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/integrity"
+	"go.uber.org/zap"
+)
+
+// Quarantined rows keep their stored data unchanged, with the reason and time they were
+// quarantined, so that they can be reviewed and restored by hand
+const createQuarantineSchema = `
+	CREATE TABLE IF NOT EXISTS families_quarantine (
+		id TEXT PRIMARY KEY,
+		status TEXT NOT NULL,
+		parents TEXT NOT NULL,
+		children TEXT NOT NULL,
+		external_ids TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		quarantined_at TEXT NOT NULL
+	);
+	`
+
+// Ensure SQLiteFamilyRepository implements integrity.Scanner
+var _ integrity.Scanner = (*SQLiteFamilyRepository)(nil)
+
+// ScanFamilies reads every stored family in ascending ID order for an integrity check
+func (r *SQLiteFamilyRepository) ScanFamilies(ctx context.Context, fn func(integrity.Record) error) error {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, status, parents, children, external_ids FROM "+r.familyRows()+" ORDER BY id")
+	if err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to query families", repoerrors.SQLiteErrorCode, "families")
+	}
+	defer rows.Close()
+
+	// Repair legacy data in memory, so that only data that cannot be repaired is reported
+	repairing := *r
+	repairing.readRepair.Enabled = true
+
+	for rows.Next() {
+		var famID, statusStr, parentsData, childrenData, externalIDsData string
+		if err := rows.Scan(&famID, &statusStr, &parentsData, &childrenData, &externalIDsData); err != nil {
+			return repoerrors.NewRepositoryError(err, "failed to scan family row", repoerrors.SQLiteErrorCode, "families")
+		}
+
+		repairs := repairing.repairRow(ctx, famID, &statusStr, &parentsData, &childrenData)
+		rec := decodeRecord(famID, statusStr, parentsData, childrenData, externalIDsData)
+		rec.Repairs = repairs
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return repoerrors.NewRepositoryError(err, "error iterating over family rows", repoerrors.SQLiteErrorCode, "families")
+	}
+	return nil
+}
+
+// decodeRecord decodes the columns of a family row into an integrity record
+func decodeRecord(famID, statusStr, parentsData, childrenData, externalIDsData string) integrity.Record {
+	rec := integrity.Record{FamilyID: famID}
+
+	var parentDTOs []entity.ParentDTO
+	if err := codec.DecodeParents([]byte(parentsData), &parentDTOs); err != nil {
+		rec.DecodeErr = fmt.Errorf("failed to decode parents: %w", err)
+		return rec
+	}
+	var childDTOs []entity.ChildDTO
+	if err := codec.DecodeChildren([]byte(childrenData), &childDTOs); err != nil {
+		rec.DecodeErr = fmt.Errorf("failed to decode children: %w", err)
+		return rec
+	}
+
+	parents := make([]*entity.Parent, 0, len(parentDTOs))
+	for _, dto := range parentDTOs {
+		p, err := entity.ParentFromDTO(dto)
+		if err != nil {
+			rec.InvariantErr = fmt.Errorf("invalid parent %s: %w", dto.ID, err)
+			return rec
+		}
+		parents = append(parents, p)
+	}
+	children := make([]*entity.Child, 0, len(childDTOs))
+	for _, dto := range childDTOs {
+		c, err := entity.ChildFromDTO(dto)
+		if err != nil {
+			rec.InvariantErr = fmt.Errorf("invalid child %s: %w", dto.ID, err)
+			return rec
+		}
+		children = append(children, c)
+	}
+
+	fam, err := entity.NewFamily(famID, entity.Status(statusStr), parents, children)
+	if err != nil {
+		rec.InvariantErr = fmt.Errorf("invalid family: %w", err)
+		return rec
+	}
+	if err := setFamilyExternalIDs(fam, externalIDsData); err != nil {
+		rec.DecodeErr = fmt.Errorf("failed to decode external IDs: %w", err)
+		return rec
+	}
+
+	rec.Family = fam
+	return rec
+}
+
+// Quarantine moves a stored family and its external IDs out of the families tables
+// into the families_quarantine table
+func (r *SQLiteFamilyRepository) Quarantine(ctx context.Context, familyID, reason string) error {
+	if _, err := r.DB.ExecContext(ctx, createQuarantineSchema); err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to create quarantine table", repoerrors.SQLiteErrorCode, "families_quarantine")
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to begin transaction", repoerrors.SQLiteErrorCode, "families")
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO families_quarantine (id, status, parents, children, external_ids, reason, quarantined_at)
+		SELECT id, status, parents, children, external_ids, ?, ? FROM `+r.familyRows()+` WHERE id = ?
+	`, reason, time.Now().UTC().Format(time.RFC3339), familyID)
+	if err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to copy family to quarantine", repoerrors.SQLiteErrorCode, "families_quarantine")
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM family_external_ids WHERE family_id = ?", familyID); err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to delete external IDs", repoerrors.SQLiteErrorCode, "family_external_ids")
+	}
+	if r.layout == layoutNormalized {
+		if err := saveMembers(ctx, tx, familyID, nil, nil); err != nil {
+			return repoerrors.NewRepositoryError(err, "failed to delete family members", repoerrors.SQLiteErrorCode, "family_members")
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM families WHERE id = ?", familyID); err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to delete family", repoerrors.SQLiteErrorCode, "families")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to commit transaction", repoerrors.SQLiteErrorCode, "families")
+	}
+
+	r.logger.Warn(ctx, "Quarantined family", zap.String("family_id", familyID), zap.String("reason", reason))
+	return nil
+}
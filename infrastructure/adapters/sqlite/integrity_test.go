@@ -0,0 +1,78 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/integrity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifyIntegrity checks and quarantines corrupt rows stored in SQLite
+func TestVerifyIntegrity(t *testing.T) {
+	repo, db, ctrl := setupTest(t)
+	defer ctrl.Finish()
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, fam := range querytest.Families(t) {
+		require.NoError(t, repo.Save(ctx, fam))
+	}
+
+	const (
+		badJSON     = "e1000000-0000-4000-8000-000000000000"
+		noParents   = "e2000000-0000-4000-8000-000000000000"
+		duplicateID = "e3000000-0000-4000-8000-000000000000"
+		sharedChild = "e4000000-0000-4000-8000-000000000000"
+	)
+	parent := `[{"ID":"b1000000-0000-4000-8000-000000000000","FirstName":"Pat","LastName":"Doe","BirthDate":"1980-01-01T00:00:00Z"}]`
+	rows := []struct{ id, status, parents, children string }{
+		{badJSON, "SINGLE", `[{"ID":`, "[]"},
+		{noParents, "SINGLE", "[]", "[]"},
+		{duplicateID, "SINGLE", parent, `[{"ID":"b1000000-0000-4000-8000-000000000000","FirstName":"Sam","LastName":"Doe","BirthDate":"2010-01-01T00:00:00Z"}]`},
+		{sharedChild, "SINGLE", parent, `[{"ID":"` + querytest.Child3 + `","FirstName":"Sam","LastName":"Doe","BirthDate":"2010-01-01T00:00:00Z"}]`},
+	}
+	for _, row := range rows {
+		_, err := db.ExecContext(ctx, "INSERT INTO families (id, status, parents, children) VALUES (?, ?, ?, ?)", row.id, row.status, row.parents, row.children)
+		require.NoError(t, err)
+	}
+
+	// Without quarantine, corrupt rows are only reported
+	report, err := integrity.NewChecker(repo, integrity.Options{}, nil).Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 8, report.Scanned)
+	assert.Equal(t, 5, report.Valid)
+	assert.Equal(t, 3, report.Corrupt)
+	assert.Equal(t, 0, report.Quarantined)
+	assert.Equal(t, map[string]int{
+		integrity.KindDecodeFailed:            1,
+		integrity.KindInvariantViolated:       1,
+		integrity.KindDuplicateMemberID:       1,
+		integrity.KindChildInMultipleFamilies: 2,
+	}, report.IssuesByKind)
+	_, err = repo.GetAll(ctx)
+	assert.Error(t, err, "corrupt rows fail reads until they are quarantined")
+
+	// With quarantine, corrupt rows are moved to the quarantine table
+	report, err = integrity.NewChecker(repo, integrity.Options{Quarantine: true}, nil).Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Quarantined)
+	for _, issue := range report.Issues {
+		assert.Equal(t, issue.Kind != integrity.KindChildInMultipleFamilies, issue.Quarantined, issue.FamilyID)
+	}
+
+	families, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, families, 5)
+
+	var quarantined int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM families_quarantine").Scan(&quarantined))
+	assert.Equal(t, 3, quarantined)
+	var reason string
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT reason FROM families_quarantine WHERE id = ?", duplicateID).Scan(&reason))
+	assert.Contains(t, reason, integrity.KindDuplicateMemberID)
+}
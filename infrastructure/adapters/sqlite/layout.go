@@ -0,0 +1,427 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/codec"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/migration"
+	"go.uber.org/zap"
+)
+
+// Layouts of the members of families. With the json layout, the parents and children of a
+// family are stored as blobs in the parents and children columns of the families table.
+// With the normalized layout, they are stored in the parents and children tables, one row
+// per person, and linked to their families by the family_members table, which indexes the
+// families of every person. The small multi-valued attributes of a person, such as its
+// external IDs, name history, and consents, stay JSON columns of its row.
+const (
+	layoutJSON       = "json"
+	layoutNormalized = "normalized"
+)
+
+// Member roles of the family_members table
+const (
+	roleParent = "parent"
+	roleChild  = "child"
+)
+
+// Statements of the normalized layout
+const (
+	// createNormalizedFamiliesSchema creates the families table without member columns
+	createNormalizedFamiliesSchema = `
+	CREATE TABLE IF NOT EXISTS families (
+		id TEXT PRIMARY KEY,
+		status TEXT NOT NULL,
+		external_ids TEXT NOT NULL DEFAULT '{}',
+		parent_count INTEGER,
+		children_count INTEGER,
+		deleted_at TEXT,
+		version INTEGER NOT NULL DEFAULT 1,
+		owner_id TEXT NOT NULL DEFAULT '',
+		tenant_id TEXT NOT NULL DEFAULT ''
+	);
+	`
+
+	createParentsTable = `
+	CREATE TABLE IF NOT EXISTS parents (
+		id TEXT PRIMARY KEY,
+		first_name TEXT NOT NULL,
+		last_name TEXT NOT NULL,
+		birth_date TEXT NOT NULL,
+		death_date TEXT,
+		preferred_name TEXT NOT NULL DEFAULT '',
+		external_ids TEXT,
+		name_history TEXT
+	);
+	`
+
+	createChildrenTable = `
+	CREATE TABLE IF NOT EXISTS children (
+		id TEXT PRIMARY KEY,
+		first_name TEXT NOT NULL,
+		last_name TEXT NOT NULL,
+		birth_date TEXT NOT NULL,
+		death_date TEXT,
+		preferred_name TEXT NOT NULL DEFAULT '',
+		external_ids TEXT,
+		name_history TEXT,
+		consents TEXT
+	);
+	`
+
+	createFamilyMembersTable = `
+	CREATE TABLE IF NOT EXISTS family_members (
+		family_id TEXT NOT NULL,
+		member_id TEXT NOT NULL,
+		role TEXT NOT NULL CHECK (role IN ('parent', 'child')),
+		position INTEGER NOT NULL,
+		PRIMARY KEY (family_id, role, position)
+	);
+	`
+
+	createFamilyMembersIndex = `
+	CREATE INDEX IF NOT EXISTS idx_family_members_member ON family_members (member_id, role);
+	`
+
+	// moveMembers describes the step that moves the member blobs of an existing families
+	// table into the normalized tables. The blobs are decoded by the service, since they
+	// may be stored in a binary encoding that SQLite cannot evaluate.
+	moveMembers = `
+	-- For each row of families, in one transaction: decode the parents and children blobs
+	-- and insert them into parents, children, and family_members
+	ALTER TABLE families DROP COLUMN parents;
+	ALTER TABLE families DROP COLUMN children;
+	`
+
+	// createFamilyDocumentsView reads families with their members as JSON arrays, in the
+	// columns of the json layout, so that families are read the same way in both layouts
+	createFamilyDocumentsView = `
+	CREATE VIEW IF NOT EXISTS family_documents AS
+	SELECT f.id, f.status,
+		COALESCE((SELECT json_group_array(json_object(
+				'ID', p.id, 'FirstName', p.first_name, 'LastName', p.last_name,
+				'BirthDate', p.birth_date, 'DeathDate', p.death_date,
+				'ExternalIDs', json(p.external_ids), 'PreferredName', p.preferred_name,
+				'NameHistory', json(p.name_history)) ORDER BY m.position)
+			FROM family_members m JOIN parents p ON p.id = m.member_id
+			WHERE m.family_id = f.id AND m.role = 'parent'), '[]') AS parents,
+		COALESCE((SELECT json_group_array(json_object(
+				'ID', c.id, 'FirstName', c.first_name, 'LastName', c.last_name,
+				'BirthDate', c.birth_date, 'DeathDate', c.death_date,
+				'ExternalIDs', json(c.external_ids), 'PreferredName', c.preferred_name,
+				'NameHistory', json(c.name_history), 'Consents', json(c.consents)) ORDER BY m.position)
+			FROM family_members m JOIN children c ON c.id = m.member_id
+			WHERE m.family_id = f.id AND m.role = 'child'), '[]') AS children,
+		f.external_ids, f.parent_count, f.children_count, f.owner_id
+	FROM families f;
+	`
+
+	// memberColumnsExist counts the member columns of the families table
+	memberColumnsExist = "SELECT COUNT(*) FROM pragma_table_info('families') WHERE name IN ('parents', 'children')"
+
+	lockMoveMembers = "Write lock on the database while every family is moved; writes wait"
+)
+
+// moveBatchSize is the number of families read at a time when members are moved
+const moveBatchSize = 500
+
+// normalizedSchemaSteps are the steps of the schema of the normalized layout, in the order
+// they are applied. The families table is created without member columns; an existing
+// families table of the json layout has its members moved into the normalized tables.
+var normalizedSchemaSteps = append([]schemaStep{
+	{Step: migration.Step{Name: "Create the families table", Table: "families", DDL: createNormalizedFamiliesSchema, Lock: lockNewTable},
+		applied: tableExists("families")},
+}, append(append([]schemaStep(nil), schemaSteps[1:]...),
+	schemaStep{Step: migration.Step{Name: "Create the parents table", Table: "parents", DDL: createParentsTable, Lock: lockNewTable},
+		applied: tableExists("parents")},
+	schemaStep{Step: migration.Step{Name: "Create the children table", Table: "children", DDL: createChildrenTable, Lock: lockNewTable},
+		applied: tableExists("children")},
+	schemaStep{Step: migration.Step{Name: "Create the family_members table", Table: "family_members", DDL: createFamilyMembersTable, Lock: lockNewTable},
+		applied: tableExists("family_members")},
+	schemaStep{Step: migration.Step{Name: "Create index idx_family_members_member", Table: "family_members", DDL: createFamilyMembersIndex, Lock: lockIndex},
+		applied: indexExists("idx_family_members_member")},
+	schemaStep{Step: migration.Step{Name: "Move the parents and children of the families table into the normalized tables", Table: "families", DDL: moveMembers, Lock: lockMoveMembers},
+		// A families table created by the first step has no member columns
+		applied: "SELECT (" + memberColumnsExist + ") = 0"},
+	schemaStep{Step: migration.Step{Name: "Create the family_documents view", Table: "family_documents", DDL: createFamilyDocumentsView, Lock: lockNewTable},
+		applied: "SELECT COUNT(*) FROM sqlite_master WHERE type = 'view' AND name = 'family_documents'"},
+	schemaStep{Step: migration.Step{Name: "Add the owner_id column to the family_documents view", Table: "family_documents", DDL: recreateFamilyDocumentsView, Lock: lockNewTable},
+		// A view created by the previous step already has the column
+		applied: "SELECT (" + documentsOwnerIDColumnExists + ") + ((SELECT COUNT(*) FROM sqlite_master WHERE type = 'view' AND name = 'family_documents') = 0)"},
+)...)
+
+// steps returns the steps of the schema of the layout of the repository
+func (r *SQLiteFamilyRepository) steps() []schemaStep {
+	if r.layout == layoutNormalized {
+		return normalizedSchemaSteps
+	}
+	return schemaSteps
+}
+
+// familiesSchema returns the statement that creates the families table of the layout
+func (r *SQLiteFamilyRepository) familiesSchema() string {
+	if r.layout == layoutNormalized {
+		return createNormalizedFamiliesSchema
+	}
+	return createFamiliesSchema
+}
+
+// familyRows returns the table or view whose rows have the id, status, parents, children,
+// external_ids, parent_count, children_count, and owner_id columns of families
+func (r *SQLiteFamilyRepository) familyRows() string {
+	if r.layout == layoutNormalized {
+		return "family_documents"
+	}
+	return "families"
+}
+
+// familiesOfMember returns the query of the families of a tenant that have a member of a
+// role, which finds them with the index of family_members. Its arguments are the member ID
+// and the tenant ID.
+func familiesOfMember(role string) string {
+	return "SELECT id, status, parents, children, external_ids, owner_id FROM family_documents " +
+		"WHERE id IN (SELECT family_id FROM family_members WHERE member_id = ? AND role = '" + role + "') AND " + inTenant + " AND " + notDeleted + " ORDER BY id"
+}
+
+// ensureNormalizedSchema creates the tables of the normalized layout, moves the members of
+// a families table of the json layout into them, and creates the family_documents view
+func (r *SQLiteFamilyRepository) ensureNormalizedSchema(ctx context.Context) error {
+	_, err := r.DB.ExecContext(ctx, createParentsTable+createChildrenTable+createFamilyMembersTable+createFamilyMembersIndex)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	if err := r.DB.QueryRowContext(ctx, memberColumnsExist).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		if err := r.moveMembers(ctx); err != nil {
+			return err
+		}
+	}
+
+	if _, err = r.DB.ExecContext(ctx, createFamilyDocumentsView); err != nil {
+		return err
+	}
+	return r.ensureDocumentsOwnerID(ctx)
+}
+
+// moveMembers moves the member blobs of every family into the normalized tables and drops
+// the member columns, in one transaction. Legacy blobs are repaired as they are read if
+// read repair is enabled.
+func (r *SQLiteFamilyRepository) moveMembers(ctx context.Context) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Another instance may have moved the members since they were checked
+	var count int
+	if err := tx.QueryRowContext(ctx, memberColumnsExist).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	type row struct {
+		id, status, parents, children string
+	}
+	moved := 0
+	after := ""
+	for {
+		rows, err := tx.QueryContext(ctx, "SELECT id, status, parents, children FROM families WHERE id > ? ORDER BY id LIMIT ?", after, moveBatchSize)
+		if err != nil {
+			return err
+		}
+		var batch []row
+		for rows.Next() {
+			var fr row
+			if err := rows.Scan(&fr.id, &fr.status, &fr.parents, &fr.children); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, fr)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, fr := range batch {
+			r.repairRow(ctx, fr.id, &fr.status, &fr.parents, &fr.children)
+			var parentDTOs []entity.ParentDTO
+			if err := codec.DecodeParents([]byte(fr.parents), &parentDTOs); err != nil {
+				return fmt.Errorf("failed to decode the parents of family %s: %w", fr.id, err)
+			}
+			var childDTOs []entity.ChildDTO
+			if err := codec.DecodeChildren([]byte(fr.children), &childDTOs); err != nil {
+				return fmt.Errorf("failed to decode the children of family %s: %w", fr.id, err)
+			}
+			if err := saveMembers(ctx, tx, fr.id, parentDTOs, childDTOs); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, "UPDATE families SET status = ?, parent_count = ?, children_count = ? WHERE id = ?",
+				fr.status, len(parentDTOs), len(childDTOs), fr.id)
+			if err != nil {
+				return err
+			}
+		}
+		moved += len(batch)
+		if len(batch) < moveBatchSize {
+			break
+		}
+		after = batch[len(batch)-1].id
+	}
+
+	if _, err := tx.ExecContext(ctx, "ALTER TABLE families DROP COLUMN parents; ALTER TABLE families DROP COLUMN children;"); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.logger.Info(ctx, "Moved the members of families into the normalized tables", zap.Int("family_count", moved))
+	return nil
+}
+
+// saveMembers replaces the members of a family in the normalized tables. A person is
+// stored once, whatever the number of its families, so the row of a person is updated
+// with its latest attributes, and deleted when it no longer belongs to any family.
+func saveMembers(ctx context.Context, tx *sql.Tx, familyID string, parents []entity.ParentDTO, children []entity.ChildDTO) error {
+	// The current members, which are deleted below if they belong to no family afterward
+	rows, err := tx.QueryContext(ctx, "SELECT member_id, role FROM family_members WHERE family_id = ?", familyID)
+	if err != nil {
+		return err
+	}
+	var previous [][2]string
+	for rows.Next() {
+		var memberID, role string
+		if err := rows.Scan(&memberID, &role); err != nil {
+			rows.Close()
+			return err
+		}
+		previous = append(previous, [2]string{memberID, role})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM family_members WHERE family_id = ?", familyID); err != nil {
+		return err
+	}
+
+	for i, p := range parents {
+		externalIDs, nameHistory, err := encodePersonAttributes(p.ExternalIDs, p.NameHistory)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO parents (id, first_name, last_name, birth_date, death_date, preferred_name, external_ids, name_history)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET first_name = excluded.first_name, last_name = excluded.last_name,
+				birth_date = excluded.birth_date, death_date = excluded.death_date, preferred_name = excluded.preferred_name,
+				external_ids = excluded.external_ids, name_history = excluded.name_history
+		`, p.ID, p.FirstName, p.LastName, formatTime(p.BirthDate), formatOptionalTime(p.DeathDate), p.PreferredName, externalIDs, nameHistory)
+		if err != nil {
+			return err
+		}
+		if err := insertMember(ctx, tx, familyID, p.ID, roleParent, i); err != nil {
+			return err
+		}
+	}
+
+	for i, c := range children {
+		externalIDs, nameHistory, err := encodePersonAttributes(c.ExternalIDs, c.NameHistory)
+		if err != nil {
+			return err
+		}
+		consents, err := encodeJSONColumn(c.Consents, c.Consents == nil)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO children (id, first_name, last_name, birth_date, death_date, preferred_name, external_ids, name_history, consents)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET first_name = excluded.first_name, last_name = excluded.last_name,
+				birth_date = excluded.birth_date, death_date = excluded.death_date, preferred_name = excluded.preferred_name,
+				external_ids = excluded.external_ids, name_history = excluded.name_history, consents = excluded.consents
+		`, c.ID, c.FirstName, c.LastName, formatTime(c.BirthDate), formatOptionalTime(c.DeathDate), c.PreferredName, externalIDs, nameHistory, consents)
+		if err != nil {
+			return err
+		}
+		if err := insertMember(ctx, tx, familyID, c.ID, roleChild, i); err != nil {
+			return err
+		}
+	}
+
+	// Delete the former members that belong to no family anymore
+	for _, member := range previous {
+		table := "parents"
+		if member[1] == roleChild {
+			table = "children"
+		}
+		_, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE id = ? AND NOT EXISTS (SELECT 1 FROM family_members WHERE member_id = ? AND role = ?)",
+			member[0], member[0], member[1])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertMember links a person to a family at a position among the members of its role
+func insertMember(ctx context.Context, tx *sql.Tx, familyID, memberID, role string, position int) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO family_members (family_id, member_id, role, position) VALUES (?, ?, ?, ?)",
+		familyID, memberID, role, position)
+	return err
+}
+
+// encodePersonAttributes encodes the external IDs and name history of a person for their
+// columns, which are NULL if the person has none
+func encodePersonAttributes(externalIDs map[string]string, nameHistory []entity.NameChange) (interface{}, interface{}, error) {
+	ids, err := encodeJSONColumn(externalIDs, externalIDs == nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	history, err := encodeJSONColumn(nameHistory, nameHistory == nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ids, history, nil
+}
+
+// encodeJSONColumn encodes a value for a JSON column, or returns NULL if the value is nil
+func encodeJSONColumn(v interface{}, isNil bool) (interface{}, error) {
+	if isNil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// formatTime formats a time as in the JSON encoding of members
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// formatOptionalTime formats a time that may be absent, which is stored as NULL
+func formatOptionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
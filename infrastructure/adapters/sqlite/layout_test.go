@@ -0,0 +1,143 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	rate "github.com/abitofhelp/family-service/infrastructure/adapters/ratewrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/repositorytest"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+// setupNormalizedTest creates a repository of the normalized layout on an in-memory database
+func setupNormalizedTest(t *testing.T) (*SQLiteFamilyRepository, *sql.DB) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1) // Every connection to :memory: opens a new database
+	t.Cleanup(func() { db.Close() })
+
+	repo := NewSQLiteFamilyRepository(db, logging.NewContextLogger(zaptest.NewLogger(t)))
+	repo.layout = layoutNormalized
+	require.NoError(t, repo.ensureTableExists(context.Background()))
+	return repo, db
+}
+
+func TestSQLiteFamilyRepository_NormalizedConformance(t *testing.T) {
+	repo, _ := setupNormalizedTest(t)
+	// The suite runs more operations than the default burst of the rate limiter
+	repo.rateLimiter = rate.NewRateLimiter("sqlite", &config.RateConfig{Enabled: false}, zap.NewNop())
+
+	repositorytest.Run(t, repo)
+}
+
+func TestSQLiteFamilyRepository_NormalizedMembers(t *testing.T) {
+	repo, db := setupNormalizedTest(t)
+	ctx := context.Background()
+
+	birthDate := time.Date(1980, time.March, 4, 0, 0, 0, 0, time.UTC)
+	parent, err := entity.NewParent(generateTestUUID(), "Jane", "Doe", birthDate, nil)
+	require.NoError(t, err)
+	child, err := entity.NewChild(generateTestUUID(), "Ann", "Doe", birthDate.AddDate(30, 0, 0), nil)
+	require.NoError(t, err)
+	family, err := entity.NewFamily(generateTestUUID(), entity.Single, []*entity.Parent{parent}, []*entity.Child{child})
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, family))
+
+	// The members are rows of the normalized tables, and the families table has no blobs
+	var parents, children, members, blobs int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM parents").Scan(&parents))
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM children").Scan(&children))
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM family_members").Scan(&members))
+	require.NoError(t, db.QueryRowContext(ctx, memberColumnsExist).Scan(&blobs))
+	assert.Equal(t, []int{1, 1, 2, 0}, []int{parents, children, members, blobs})
+
+	found, err := repo.FindByParentID(ctx, parent.ID())
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, family.ID(), found[0].ID())
+	assert.Equal(t, "Jane", found[0].Parents()[0].FirstName())
+
+	byChild, err := repo.FindByChildID(ctx, child.ID())
+	require.NoError(t, err)
+	assert.Equal(t, family.ID(), byChild.ID())
+
+	// The lookups of members use the index of family_members
+	var detail, plan string
+	var id, parentID, notUsed int
+	rows, err := db.QueryContext(ctx, "EXPLAIN QUERY PLAN "+familiesOfMember(roleParent), parent.ID(), "")
+	require.NoError(t, err)
+	for rows.Next() {
+		require.NoError(t, rows.Scan(&id, &parentID, &notUsed, &detail))
+		plan += detail + "\n"
+	}
+	require.NoError(t, rows.Close())
+	assert.Contains(t, plan, "idx_family_members_member")
+
+	// A person that leaves every family is deleted
+	require.NoError(t, family.RemoveChild(child.ID()))
+	require.NoError(t, repo.Save(ctx, family))
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM children").Scan(&children))
+	assert.Zero(t, children)
+}
+
+// TestSQLiteFamilyRepository_MoveMembers switches a database of the json layout to the
+// normalized layout
+func TestSQLiteFamilyRepository_MoveMembers(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	ctx := context.Background()
+
+	jsonRepo := NewSQLiteFamilyRepository(db, logging.NewContextLogger(zaptest.NewLogger(t)))
+	families := make([]*entity.Family, 0, 3)
+	for i := 0; i < 3; i++ {
+		parent, err := entity.NewParent(generateTestUUID(), "Jane", "Doe", time.Date(1980, time.March, 4, 0, 0, 0, 0, time.UTC), nil)
+		require.NoError(t, err)
+		family, err := entity.NewFamily(generateTestUUID(), entity.Single, []*entity.Parent{parent}, nil)
+		require.NoError(t, err)
+		require.NoError(t, jsonRepo.Save(ctx, family))
+		families = append(families, family)
+	}
+
+	repo := NewSQLiteFamilyRepository(db, logging.NewContextLogger(zaptest.NewLogger(t)))
+	repo.layout = layoutNormalized
+	plan, err := repo.PlanSchema(ctx)
+	require.NoError(t, err)
+	var pending []string
+	for _, step := range plan.Pending() {
+		pending = append(pending, step.Name)
+	}
+	assert.Equal(t, []string{
+		"Create the admin_quarantines table",
+		"Create the families_quarantine table",
+		"Create the parents table",
+		"Create the children table",
+		"Create the family_members table",
+		"Create index idx_family_members_member",
+		"Move the parents and children of the families table into the normalized tables",
+		"Create the family_documents view",
+	}, pending)
+
+	require.NoError(t, repo.ApplySchema(ctx))
+	plan, err = repo.PlanSchema(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Pending())
+
+	for _, family := range families {
+		found, err := repo.FindByParentID(ctx, family.Parents()[0].ID())
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, family.ID(), found[0].ID())
+	}
+}
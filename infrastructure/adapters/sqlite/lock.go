@@ -0,0 +1,22 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/familylock"
+)
+
+// Ensure SQLiteFamilyRepository implements ports.FamilyLocker
+var _ ports.FamilyLocker = (*SQLiteFamilyRepository)(nil)
+
+// LockFamily waits until the caller holds the lock of a family. A SQLite database is served
+// by a single instance, so families are locked in process; the locks serialize the changes
+// made through this repository only.
+func (r *SQLiteFamilyRepository) LockFamily(ctx context.Context, familyID string) (func(), error) {
+	return familylock.Acquire(ctx, "sqlite", familyID, r.locking, func(ctx context.Context) (func(), error) {
+		return r.familyLocks.Lock(ctx, familyID)
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLockFamily serializes the changes of a family and fails changes that wait too long
+func TestLockFamily(t *testing.T) {
+	repo, db, ctrl := setupTest(t)
+	defer ctrl.Finish()
+	defer db.Close()
+
+	ctx := context.Background()
+	const familyA, familyB = "a1000000-0000-4000-8000-000000000000", "b1000000-0000-4000-8000-000000000000"
+
+	// Without a locking configuration, families are not locked
+	unlock, err := repo.LockFamily(ctx, familyA)
+	require.NoError(t, err)
+	again, err := repo.LockFamily(ctx, familyA)
+	require.NoError(t, err)
+	again()
+	unlock()
+
+	repo.locking = config.LockingConfig{Enabled: true, Timeout: 20 * time.Millisecond}
+
+	unlock, err = repo.LockFamily(ctx, familyA)
+	require.NoError(t, err)
+
+	// Another family is not locked
+	other, err := repo.LockFamily(ctx, familyB)
+	require.NoError(t, err)
+	other()
+
+	// A second change of the family waits for the first and times out
+	_, err = repo.LockFamily(ctx, familyA)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, domainerrors.ErrFamilyLocked))
+
+	// A change waiting for the lock proceeds once it is released
+	acquired := make(chan error, 1)
+	repo.locking.Timeout = time.Second
+	go func() {
+		release, err := repo.LockFamily(ctx, familyA)
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	unlock()
+	require.NoError(t, <-acquired)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+)
+
+// Domain events waiting to be relayed to the message broker are stored in the event_outbox
+// table, in the order of seq. The table is created with the families table, before any
+// unit of work begins, so that AddToOutbox can join the transaction of a unit of work.
+const createOutboxSchema = `
+	CREATE TABLE IF NOT EXISTS event_outbox (
+		seq INTEGER PRIMARY KEY AUTOINCREMENT,
+		id TEXT NOT NULL UNIQUE,
+		type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	);
+	`
+
+// Ensure SQLiteFamilyRepository implements ports.OutboxRepository
+var _ ports.OutboxRepository = (*SQLiteFamilyRepository)(nil)
+
+// ensureOutboxSchema creates the event_outbox and event_outbox_dead_letters tables if they
+// do not exist
+func (r *SQLiteFamilyRepository) ensureOutboxSchema(ctx context.Context) error {
+	if _, err := r.DB.ExecContext(ctx, createOutboxSchema+createOutboxDeadLettersSchema); err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to create outbox table", repoerrors.SQLiteErrorCode, "event_outbox")
+	}
+	return nil
+}
+
+// AddToOutbox stores messages in the outbox, in the unit of work of the context if there is
+// one. The messages are stored together or not at all.
+func (r *SQLiteFamilyRepository) AddToOutbox(ctx context.Context, messages ...ports.OutboxMessage) error {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return err
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to begin transaction", repoerrors.SQLiteErrorCode, "event_outbox")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, message := range messages {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO event_outbox (id, type, payload, created_at) VALUES (?, ?, ?, ?)
+		`, message.ID, message.Type, string(message.Payload), message.CreatedAt.UTC().Format(time.RFC3339Nano))
+		if err != nil {
+			return repoerrors.NewRepositoryError(err, "failed to add message to outbox", repoerrors.SQLiteErrorCode, "event_outbox")
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to commit transaction", repoerrors.SQLiteErrorCode, "event_outbox")
+	}
+	return nil
+}
+
+// PendingOutboxMessages returns at most limit messages of the outbox, in the order in which
+// they were stored
+func (r *SQLiteFamilyRepository) PendingOutboxMessages(ctx context.Context, limit int) ([]ports.OutboxMessage, error) {
+	return r.OutboxMessages(ctx, "", limit)
+}
+
+// OutboxMessages returns at most limit messages of the outbox of the given type, or of every
+// type if it is empty, in the order in which they were stored
+func (r *SQLiteFamilyRepository) OutboxMessages(ctx context.Context, eventType string, limit int) ([]ports.OutboxMessage, error) {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, type, payload, created_at FROM event_outbox WHERE (?1 = '' OR type = ?1) ORDER BY seq LIMIT ?2", eventType, limit)
+	if err != nil {
+		return nil, repoerrors.NewRepositoryError(err, "failed to read outbox", repoerrors.SQLiteErrorCode, "event_outbox")
+	}
+	defer rows.Close()
+
+	messages := []ports.OutboxMessage{}
+	for rows.Next() {
+		var message ports.OutboxMessage
+		var payload, createdAt string
+		if err := rows.Scan(&message.ID, &message.Type, &payload, &createdAt); err != nil {
+			return nil, repoerrors.NewRepositoryError(err, "failed to scan outbox message", repoerrors.SQLiteErrorCode, "event_outbox")
+		}
+		at, err := time.Parse(time.RFC3339Nano, createdAt)
+		if err != nil {
+			return nil, repoerrors.NewRepositoryError(err, "failed to parse outbox message time", repoerrors.SQLiteErrorCode, "event_outbox")
+		}
+		message.Payload = []byte(payload)
+		message.CreatedAt = at
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repoerrors.NewRepositoryError(err, "error iterating over outbox messages", repoerrors.SQLiteErrorCode, "event_outbox")
+	}
+	return messages, nil
+}
+
+// RemoveFromOutbox removes the messages with the given IDs from the outbox, with one
+// statement for every 500 IDs
+func (r *SQLiteFamilyRepository) RemoveFromOutbox(ctx context.Context, ids []string) error {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(ids); start += maxBatchReadIDs {
+		batch := ids[start:min(start+maxBatchReadIDs, len(ids))]
+		args := make([]interface{}, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		_, err := r.DB.ExecContext(ctx, "DELETE FROM event_outbox WHERE id IN (?"+strings.Repeat(", ?", len(batch)-1)+")", args...)
+		if err != nil {
+			return repoerrors.NewRepositoryError(err, "failed to remove messages from outbox", repoerrors.SQLiteErrorCode, "event_outbox")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+	"go.uber.org/zap"
+)
+
+// Ensure SQLiteFamilyRepository implements ports.FamilyOwnerReader
+var _ ports.FamilyOwnerReader = (*SQLiteFamilyRepository)(nil)
+
+// The owner_id column of the families table holds the ID of the user who owns a family.
+// Rows saved before the column was added have no owner.
+const (
+	// addOwnerIDColumn adds the owner_id column to families tables created before families
+	// had owners
+	addOwnerIDColumn = "ALTER TABLE families ADD COLUMN owner_id TEXT NOT NULL DEFAULT ''"
+
+	// ownerIDColumnExists counts the owner_id columns of the families table
+	ownerIDColumnExists = "SELECT COUNT(*) FROM pragma_table_info('families') WHERE name = 'owner_id'"
+
+	// recreateFamilyDocumentsView replaces a family_documents view created before families
+	// had owners with one that has the owner_id column
+	recreateFamilyDocumentsView = "\n\tDROP VIEW IF EXISTS family_documents;\n\t" + createFamilyDocumentsView
+
+	// documentsOwnerIDColumnExists counts the owner_id columns of the family_documents view
+	documentsOwnerIDColumnExists = "SELECT COUNT(*) FROM pragma_table_info('family_documents') WHERE name = 'owner_id'"
+
+	// The index leads with the tenant and the owner, so that the families of an owner are
+	// counted and read from a range of it, in ID order for the pages of the owner
+	createOwnerIDIndex = `
+	CREATE INDEX IF NOT EXISTS idx_families_owner_id ON families (tenant_id, owner_id, id);
+	`
+
+	// ownedBy is the condition on the rows of families, or of family_documents, of the
+	// families of an owner, whose arguments are the tenant ID and the owner ID
+	ownedBy = "id IN (SELECT id FROM families WHERE tenant_id = ? AND owner_id = ?)"
+)
+
+// ensureOwnerIDColumn adds the owner_id column to families tables created before families
+// had owners
+func (r *SQLiteFamilyRepository) ensureOwnerIDColumn(ctx context.Context) error {
+	var count int
+	if err := r.DB.QueryRowContext(ctx, ownerIDColumnExists).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := r.DB.ExecContext(ctx, addOwnerIDColumn)
+	return err
+}
+
+// ensureDocumentsOwnerID recreates a family_documents view created before families had
+// owners, whose rows lack the owner_id column
+func (r *SQLiteFamilyRepository) ensureDocumentsOwnerID(ctx context.Context) error {
+	var count int
+	if err := r.DB.QueryRowContext(ctx, documentsOwnerIDColumnExists).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := r.DB.ExecContext(ctx, recreateFamilyDocumentsView)
+	return err
+}
+
+// ensureOwnerIDIndex creates the index of the families of an owner, once the owner_id and
+// tenant_id columns exist
+func (r *SQLiteFamilyRepository) ensureOwnerIDIndex(ctx context.Context) error {
+	_, err := r.DB.ExecContext(ctx, createOwnerIDIndex)
+	return err
+}
+
+// ListOwnedFamilies reads a page of the families of an owner in ascending ID order, from a
+// range of the owner index
+func (r *SQLiteFamilyRepository) ListOwnedFamilies(ctx context.Context, ownerID, after string, limit int) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Listing a page of the families of an owner from SQLite",
+		zap.String("owner_id", ownerID), zap.String("after", after), zap.Int("limit", limit))
+
+	return r.queryFamilies(ctx, "ListOwnedFamilies",
+		"SELECT id, status, parents, children, external_ids, owner_id FROM "+r.familyRows()+" WHERE id > ? AND "+ownedBy+" AND "+notDeleted+" ORDER BY id LIMIT ?",
+		after, tenantOf(ctx), ownerID, limit)
+}
+
+// CountByOwner counts the families of an owner that are not deleted, and the distinct
+// parents and children in them, leaving out the families with the excluded IDs
+func (r *SQLiteFamilyRepository) CountByOwner(ctx context.Context, ownerID string, excluded []string) (ports.FamilyCounts, error) {
+	r.logger.Debug(ctx, "Counting the families of an owner in SQLite", zap.String("owner_id", ownerID))
+
+	if err := r.ensureTableExists(ctx); err != nil {
+		return ports.FamilyCounts{}, err
+	}
+	scope := ownerScope(ctx, ownerID, excluded)
+
+	var counts ports.FamilyCounts
+	if err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM families WHERE "+scope.where, scope.args...).Scan(&counts.Families); err != nil {
+		return ports.FamilyCounts{}, repoerrors.NewRepositoryError(err, "failed to count the families of the owner", repoerrors.SQLiteErrorCode, "families")
+	}
+	var err error
+	if counts.Parents, err = r.countMembers(ctx, scope, "parents", roleParent, decodeParentIDs); err != nil {
+		return ports.FamilyCounts{}, err
+	}
+	if counts.Children, err = r.countMembers(ctx, scope, "children", roleChild, decodeChildIDs); err != nil {
+		return ports.FamilyCounts{}, err
+	}
+	return counts, nil
+}
+
+// ownerScope returns the scope of the families of an owner in the tenant of the context,
+// without the families with the excluded IDs
+func ownerScope(ctx context.Context, ownerID string, excluded []string) familyScope {
+	scope := familyScope{
+		where: "families.tenant_id = ? AND families.owner_id = ? AND families.deleted_at IS NULL",
+		args:  []interface{}{tenantOf(ctx), ownerID},
+	}
+	if len(excluded) > 0 {
+		scope.where += " AND families.id NOT IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(excluded)), ", ") + ")"
+		for _, id := range excluded {
+			scope.args = append(scope.args, id)
+		}
+	}
+	return scope
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"go.uber.org/zap"
+)
+
+// Ensure SQLiteFamilyRepository implements ports.FamilyPager
+var _ ports.FamilyPager = (*SQLiteFamilyRepository)(nil)
+
+// ListFamilies reads a page of families in ascending ID order. The page starts after the
+// given ID on the primary key, so reading a page costs the same wherever it starts.
+func (r *SQLiteFamilyRepository) ListFamilies(ctx context.Context, after string, limit int) ([]*entity.Family, error) {
+	r.logger.Debug(ctx, "Listing a page of families from SQLite", zap.String("after", after), zap.Int("limit", limit))
+
+	return r.queryFamilies(ctx, "ListFamilies",
+		"SELECT id, status, parents, children, external_ids, owner_id FROM "+r.familyRows()+" WHERE id > ? AND "+inTenant+" AND "+notDeleted+" ORDER BY id LIMIT ?", after, tenantOf(ctx), limit)
+}
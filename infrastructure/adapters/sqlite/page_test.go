@@ -0,0 +1,47 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFamilies(t *testing.T) {
+	repo, db, ctrl := setupTest(t)
+	defer ctrl.Finish()
+	defer db.Close()
+
+	ctx := context.Background()
+	families := querytest.Families(t)
+	for _, fam := range families {
+		require.NoError(t, repo.Save(ctx, fam))
+	}
+
+	ids := func(page []*entity.Family) []string {
+		var got []string
+		for _, fam := range page {
+			got = append(got, fam.ID())
+		}
+		return got
+	}
+
+	// Paging through the families returns the pages that query.Page defines
+	var after string
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, len(families), "paging did not end")
+		page, err := repo.ListFamilies(ctx, after, 2)
+		require.NoError(t, err)
+		assert.Equal(t, ids(query.Page(families, after, 2)), ids(page))
+		if len(page) < 2 {
+			break
+		}
+		after = page[len(page)-1].ID()
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+)
+
+// Families quarantined by administrators are recorded in the admin_quarantines table.
+// Unlike the rows in families_quarantine, which the integrity check moved out of the
+// families table, the families stay in the families table, unchanged.
+const createAdminQuarantinesSchema = `
+	CREATE TABLE IF NOT EXISTS admin_quarantines (
+		family_id TEXT PRIMARY KEY,
+		reason TEXT NOT NULL,
+		quarantined_by TEXT NOT NULL,
+		quarantined_at TEXT NOT NULL
+	);
+	`
+
+// Ensure SQLiteFamilyRepository implements ports.QuarantineRepository
+var _ ports.QuarantineRepository = (*SQLiteFamilyRepository)(nil)
+
+// ensureAdminQuarantinesSchema creates the admin_quarantines table if it does not exist
+func (r *SQLiteFamilyRepository) ensureAdminQuarantinesSchema(ctx context.Context) error {
+	// The schema was ensured when the unit of work of the context began
+	if r.unitOfWork(ctx) != nil {
+		return nil
+	}
+
+	if _, err := r.DB.ExecContext(ctx, createAdminQuarantinesSchema); err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to create quarantine table", repoerrors.SQLiteErrorCode, "admin_quarantines")
+	}
+	return nil
+}
+
+// GetFamilyQuarantine returns the quarantine of a family, or nil if it is not quarantined
+func (r *SQLiteFamilyRepository) GetFamilyQuarantine(ctx context.Context, familyID string) (*entity.Quarantine, error) {
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return nil, err
+	}
+
+	row := r.DB.QueryRowContext(ctx, "SELECT family_id, reason, quarantined_by, quarantined_at FROM admin_quarantines WHERE family_id = ?", familyID)
+	q, err := scanQuarantine(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, repoerrors.NewRepositoryError(err, "failed to get family quarantine", repoerrors.SQLiteErrorCode, "admin_quarantines")
+	}
+	return &q, nil
+}
+
+// SaveFamilyQuarantine stores the quarantine of a family, replacing any previous one
+func (r *SQLiteFamilyRepository) SaveFamilyQuarantine(ctx context.Context, q entity.Quarantine) error {
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return err
+	}
+
+	_, err := r.execer(ctx).ExecContext(ctx, `
+		INSERT OR REPLACE INTO admin_quarantines (family_id, reason, quarantined_by, quarantined_at)
+		VALUES (?, ?, ?, ?)
+	`, q.FamilyID, q.Reason, q.QuarantinedBy, q.QuarantinedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to save family quarantine", repoerrors.SQLiteErrorCode, "admin_quarantines")
+	}
+	return nil
+}
+
+// DeleteFamilyQuarantine removes the quarantine of a family and reports whether it had one
+func (r *SQLiteFamilyRepository) DeleteFamilyQuarantine(ctx context.Context, familyID string) (bool, error) {
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return false, err
+	}
+
+	result, err := r.execer(ctx).ExecContext(ctx, "DELETE FROM admin_quarantines WHERE family_id = ?", familyID)
+	if err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to delete family quarantine", repoerrors.SQLiteErrorCode, "admin_quarantines")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, repoerrors.NewRepositoryError(err, "failed to delete family quarantine", repoerrors.SQLiteErrorCode, "admin_quarantines")
+	}
+	return deleted > 0, nil
+}
+
+// ListFamilyQuarantines returns every quarantine, in ascending family ID order
+func (r *SQLiteFamilyRepository) ListFamilyQuarantines(ctx context.Context) ([]entity.Quarantine, error) {
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, "SELECT family_id, reason, quarantined_by, quarantined_at FROM admin_quarantines ORDER BY family_id")
+	if err != nil {
+		return nil, repoerrors.NewRepositoryError(err, "failed to list family quarantines", repoerrors.SQLiteErrorCode, "admin_quarantines")
+	}
+	defer rows.Close()
+
+	quarantines := []entity.Quarantine{}
+	for rows.Next() {
+		q, err := scanQuarantine(rows)
+		if err != nil {
+			return nil, repoerrors.NewRepositoryError(err, "failed to scan family quarantine", repoerrors.SQLiteErrorCode, "admin_quarantines")
+		}
+		quarantines = append(quarantines, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repoerrors.NewRepositoryError(err, "error iterating over family quarantines", repoerrors.SQLiteErrorCode, "admin_quarantines")
+	}
+	return quarantines, nil
+}
+
+// scanQuarantine scans a row of the admin_quarantines table
+func scanQuarantine(row interface{ Scan(dest ...any) error }) (entity.Quarantine, error) {
+	var q entity.Quarantine
+	var quarantinedAt string
+	if err := row.Scan(&q.FamilyID, &q.Reason, &q.QuarantinedBy, &quarantinedAt); err != nil {
+		return entity.Quarantine{}, err
+	}
+	at, err := time.Parse(time.RFC3339, quarantinedAt)
+	if err != nil {
+		return entity.Quarantine{}, err
+	}
+	q.QuarantinedAt = at
+	return q, nil
+}
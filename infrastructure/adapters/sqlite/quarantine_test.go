@@ -0,0 +1,49 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFamilyQuarantines stores, lists, and removes the quarantines placed by administrators
+func TestFamilyQuarantines(t *testing.T) {
+	repo, db, ctrl := setupTest(t)
+	defer ctrl.Finish()
+	defer db.Close()
+
+	ctx := context.Background()
+	const familyA, familyB = "a1000000-0000-4000-8000-000000000000", "b1000000-0000-4000-8000-000000000000"
+
+	q, err := repo.GetFamilyQuarantine(ctx, familyA)
+	require.NoError(t, err)
+	assert.Nil(t, q)
+
+	at := time.Date(2025, time.March, 3, 14, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.SaveFamilyQuarantine(ctx, entity.Quarantine{FamilyID: familyB, Reason: "fraud 7", QuarantinedBy: "admin-1", QuarantinedAt: at}))
+	require.NoError(t, repo.SaveFamilyQuarantine(ctx, entity.Quarantine{FamilyID: familyA, Reason: "fraud 1", QuarantinedAt: at}))
+	require.NoError(t, repo.SaveFamilyQuarantine(ctx, entity.Quarantine{FamilyID: familyA, Reason: "fraud 2", QuarantinedAt: at}))
+
+	q, err = repo.GetFamilyQuarantine(ctx, familyB)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.Quarantine{FamilyID: familyB, Reason: "fraud 7", QuarantinedBy: "admin-1", QuarantinedAt: at}, q)
+
+	list, err := repo.ListFamilyQuarantines(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, familyA, list[0].FamilyID)
+	assert.Equal(t, "fraud 2", list[0].Reason, "quarantining again replaces the reason")
+
+	deleted, err := repo.DeleteFamilyQuarantine(ctx, familyA)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = repo.DeleteFamilyQuarantine(ctx, familyA)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
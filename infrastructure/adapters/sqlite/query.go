@@ -0,0 +1,175 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"go.uber.org/zap"
+)
+
+// Parents and children may be stored in a binary encoding, so SQLite cannot evaluate
+// conditions on members. Filters are translated into a WHERE clause on the id, status,
+// and count columns that selects a superset of the matching families, and the exact
+// filter is then applied to the decoded families with query.Match. Conditions on member
+// names also narrow the rows whose members are stored as JSON with LIKE, which SQLite
+// evaluates without decoding them.
+
+// prefilter translates the parts of a valid filter on the id, status, and count columns into a
+// SQL condition that every matching family satisfies. It reports false if no such
+// condition can be derived, in which case every family must be read.
+func prefilter(f query.Filter) (string, []interface{}, bool) {
+	switch f := f.(type) {
+	case query.And:
+		// Any subset of the conditions of an And selects a superset of its matches
+		var parts []string
+		var args []interface{}
+		for _, child := range f {
+			if where, childArgs, ok := prefilter(child); ok {
+				parts = append(parts, where)
+				args = append(args, childArgs...)
+			}
+		}
+		if len(parts) == 0 {
+			return "", nil, false
+		}
+		return "(" + strings.Join(parts, " AND ") + ")", args, true
+	case query.Or:
+		// Every alternative of an Or must be restricted for the Or to be restricted
+		if len(f) == 0 {
+			return "0", nil, true
+		}
+		parts := make([]string, 0, len(f))
+		var args []interface{}
+		for _, child := range f {
+			where, childArgs, ok := prefilter(child)
+			if !ok {
+				return "", nil, false
+			}
+			parts = append(parts, where)
+			args = append(args, childArgs...)
+		}
+		return "(" + strings.Join(parts, " OR ") + ")", args, true
+	case query.Condition:
+		if column, ok := countColumns[f.Field]; ok {
+			where, args := countPrefilter(column, f)
+			return where, args, true
+		}
+		if f.Field == query.FieldMemberName {
+			return namePrefilter(f.Value.(string))
+		}
+		column := map[query.Field]string{query.FieldID: "id", query.FieldStatus: "status", query.FieldOwnerID: "owner_id"}[f.Field]
+		if column == "" {
+			return "", nil, false
+		}
+		switch f.Op {
+		case query.OpEq:
+			return column + " = ?", []interface{}{f.Value}, true
+		case query.OpNe:
+			return column + " <> ?", []interface{}{f.Value}, true
+		case query.OpIn:
+			values := f.Value.([]string)
+			if len(values) == 0 {
+				return "0", nil, true
+			}
+			args := make([]interface{}, len(values))
+			for i, v := range values {
+				args[i] = v
+			}
+			return fmt.Sprintf("%s IN (%s)", column, strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")), args, true
+		}
+	}
+	// Negations are not restricted, since a superset of the matches of the negated
+	// filter cannot be turned into a superset of the matches of the negation
+	return "", nil, false
+}
+
+// namePrefilter translates a condition that a member name contains a value into a LIKE on
+// the members stored as JSON, which contain every name. Rows whose members are stored in a
+// binary encoding are kept. LIKE ignores the case of ASCII letters only, and the JSON
+// escapes some characters, so values with other characters are not translated.
+func namePrefilter(value string) (string, []interface{}, bool) {
+	for _, r := range value {
+		if r < ' ' || r > '~' || strings.ContainsRune(`"\<>&`, r) {
+			return "", nil, false
+		}
+	}
+	pattern := "%" + strings.NewReplacer("%", `\%`, "_", `\_`).Replace(value) + "%"
+	return `(NOT json_valid(parents) OR NOT json_valid(children) OR parents LIKE ? ESCAPE '\' OR children LIKE ? ESCAPE '\')`,
+		[]interface{}{pattern, pattern}, true
+}
+
+// Find finds the families that match a filter, in ascending ID order
+func (r *SQLiteFamilyRepository) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	if err := query.Validate(filter); err != nil {
+		return nil, err
+	}
+
+	where, args := findConditions(ctx, filter)
+	r.logger.Debug(ctx, "Finding families by filter in SQLite", zap.String("where", where))
+
+	candidates, err := r.queryFamilies(ctx, "Find", "SELECT id, status, parents, children, external_ids, owner_id FROM "+r.familyRows()+" WHERE "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	families := make([]*entity.Family, 0, len(candidates))
+	for _, fam := range candidates {
+		if query.Match(filter, fam) {
+			families = append(families, fam)
+		}
+	}
+	return families, nil
+}
+
+// Ensure SQLiteFamilyRepository implements ports.FamilyFilterPager
+var _ ports.FamilyFilterPager = (*SQLiteFamilyRepository)(nil)
+
+// FindPage finds at most limit families that match a filter and whose IDs sort after the
+// given ID, in ascending ID order. Some of the rows selected by the prefilter may not match
+// the filter, so they are read limit at a time until the page is full or no rows are left.
+func (r *SQLiteFamilyRepository) FindPage(ctx context.Context, filter query.Filter, after string, limit int) ([]*entity.Family, error) {
+	if err := query.Validate(filter); err != nil {
+		return nil, err
+	}
+
+	where, args := findConditions(ctx, filter)
+	r.logger.Debug(ctx, "Finding a page of families by filter in SQLite",
+		zap.String("where", where), zap.String("after", after), zap.Int("limit", limit))
+
+	sql := "SELECT id, status, parents, children, external_ids, owner_id FROM " + r.familyRows() + " WHERE " + where + " AND id > ? ORDER BY id LIMIT ?"
+	families := make([]*entity.Family, 0, limit)
+	for len(families) < limit {
+		candidates, err := r.queryFamilies(ctx, "FindPage", sql, append(append([]interface{}{}, args...), after, limit)...)
+		if err != nil {
+			return nil, err
+		}
+		for _, fam := range candidates {
+			if len(families) < limit && query.Match(filter, fam) {
+				families = append(families, fam)
+			}
+		}
+		if len(candidates) < limit {
+			break
+		}
+		after = candidates[len(candidates)-1].ID()
+	}
+	return families, nil
+}
+
+// findConditions returns the WHERE clause, and its arguments, of the rows of the families of
+// the tenant that are not deleted and satisfy the prefilter of a valid filter
+func findConditions(ctx context.Context, filter query.Filter) (string, []interface{}) {
+	where := inTenant + " AND " + notDeleted
+	args := []interface{}{tenantOf(ctx)}
+	if restriction, restrictionArgs, ok := prefilter(filter); ok {
+		where += " AND " + restriction
+		args = append(args, restrictionArgs...)
+	}
+	return where, args
+}
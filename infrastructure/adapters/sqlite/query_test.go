@@ -0,0 +1,90 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	rate "github.com/abitofhelp/family-service/infrastructure/adapters/ratewrapper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestFind runs every shared case against families stored in SQLite
+func TestFind(t *testing.T) {
+	repo, db, ctrl := setupTest(t)
+	defer ctrl.Finish()
+	defer db.Close()
+	// Every case runs a query, more than the default burst of the rate limiter
+	repo.rateLimiter = rate.NewRateLimiter("sqlite", &config.RateConfig{Enabled: false}, zap.NewNop())
+
+	ctx := context.Background()
+	for _, fam := range querytest.Families(t) {
+		require.NoError(t, repo.Save(ctx, fam))
+	}
+
+	for _, tc := range querytest.Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			families, err := repo.Find(ctx, tc.Filter)
+			require.NoError(t, err)
+
+			var got []string
+			for _, fam := range families {
+				got = append(got, fam.ID())
+			}
+			assert.Equal(t, tc.Want, got)
+		})
+	}
+
+	_, err := repo.Find(ctx, query.Eq("tags", "x"))
+	assert.Error(t, err)
+}
+
+func TestPrefilter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter query.Filter
+		where  string
+		args   []interface{}
+		ok     bool
+	}{
+		{"status", query.Eq(query.FieldStatus, "SINGLE"), "status = ?", []interface{}{"SINGLE"}, true},
+		{"id in", query.In(query.FieldID, []string{"a", "b"}), "id IN (?, ?)", []interface{}{"a", "b"}, true},
+		{"member", query.Eq(query.FieldChildID, "c"), "", nil, false},
+		{"and keeps the restricted conditions", query.And{
+			query.Eq(query.FieldChildID, "c"),
+			query.Condition{Field: query.FieldStatus, Op: query.OpNe, Value: "MARRIED"},
+		}, "(status <> ?)", []interface{}{"MARRIED"}, true},
+		{"count", query.Condition{Field: query.FieldChildCount, Op: query.OpGte, Value: 3},
+			"(children_count IS NULL OR children_count >= ?)", []interface{}{3}, true},
+		{"count in", query.In(query.FieldParentCount, []int{1, 2}),
+			"(parent_count IS NULL OR parent_count IN (?, ?))", []interface{}{1, 2}, true},
+		{"or needs every alternative", query.Or{
+			query.Eq(query.FieldStatus, "SINGLE"),
+			query.Eq(query.FieldChildID, "c"),
+		}, "", nil, false},
+		{"or", query.Or{
+			query.Eq(query.FieldStatus, "SINGLE"),
+			query.Eq(query.FieldID, "a"),
+		}, "(status = ? OR id = ?)", []interface{}{"SINGLE", "a"}, true},
+		{"member name", query.Contains(query.FieldMemberName, "50%_off"),
+			`(NOT json_valid(parents) OR NOT json_valid(children) OR parents LIKE ? ESCAPE '\' OR children LIKE ? ESCAPE '\')`,
+			[]interface{}{`%50\%\_off%`, `%50\%\_off%`}, true},
+		{"member name escaped by the JSON", query.Contains(query.FieldMemberName, "Zoë"), "", nil, false},
+		{"not", query.Not{Filter: query.Eq(query.FieldStatus, "SINGLE")}, "", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			where, args, ok := prefilter(tc.filter)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.where, where)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
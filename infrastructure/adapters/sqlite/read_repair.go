@@ -0,0 +1,66 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/codec"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/repair"
+	"go.uber.org/zap"
+)
+
+// repairRow repairs the status and member blobs of a family row in place if read repair is
+// enabled, and logs and counts the repairs. Binary member blobs are written by the current
+// codecs and are left unchanged.
+func (r *SQLiteFamilyRepository) repairRow(ctx context.Context, famID string, statusStr, parentsData, childrenData *string) []repair.Repair {
+	if !r.readRepair.Enabled {
+		return nil
+	}
+
+	var repairs []repair.Repair
+	for _, column := range []struct {
+		data *string
+		path string
+	}{{parentsData, "parents"}, {childrenData, "children"}} {
+		repaired, columnRepairs, err := repair.MembersJSON([]byte(*column.data), repair.PascalCase, column.path)
+		if err != nil {
+			// Leave the data unchanged so that decoding reports the error
+			continue
+		}
+		*column.data = string(repaired)
+		repairs = append(repairs, columnRepairs...)
+	}
+
+	// Derive a missing status from the number of parents
+	var parentCount int
+	if strings.TrimSpace(*statusStr) == "" {
+		var parentDTOs []entity.ParentDTO
+		if err := codec.DecodeParents([]byte(*parentsData), &parentDTOs); err == nil {
+			parentCount = len(parentDTOs)
+		}
+	}
+	status, statusRepairs := repair.Status(*statusStr, parentCount)
+	*statusStr = status
+	repairs = append(repairs, statusRepairs...)
+
+	repair.Record(ctx, r.logger, "sqlite", famID, repairs)
+	return repairs
+}
+
+// writeBack saves the canonical form of repaired families if write-back is enabled.
+// Failures are logged and do not fail the read.
+func (r *SQLiteFamilyRepository) writeBack(ctx context.Context, families []*entity.Family) {
+	if !r.readRepair.WriteBack {
+		return
+	}
+	for _, fam := range families {
+		if err := r.Save(ctx, fam); err != nil {
+			r.logger.Warn(ctx, "Failed to write back repaired family", zap.Error(err), zap.String("family_id", fam.ID()))
+			continue
+		}
+		r.logger.Info(ctx, "Wrote back repaired family", zap.String("family_id", fam.ID()))
+	}
+}
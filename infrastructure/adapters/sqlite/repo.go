@@ -5,16 +5,19 @@
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
 	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/assembly"
 	circuit "github.com/abitofhelp/family-service/infrastructure/adapters/circuitwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/codec"
 	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
 	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/familylock"
 	rate "github.com/abitofhelp/family-service/infrastructure/adapters/ratewrapper"
 	"github.com/abitofhelp/servicelib/errors"
 	"github.com/abitofhelp/servicelib/logging"
@@ -77,6 +80,12 @@ type SQLiteFamilyRepository struct {
 	logger         *logging.ContextLogger
 	circuitBreaker *circuit.CircuitBreaker
 	rateLimiter    *rate.RateLimiter
+	codec          codec.Codec
+	layout         string // Storage of the members of families, layoutJSON or layoutNormalized
+	readRepair     config.ReadRepairConfig
+	memoryBudget   config.MemoryBudgetConfig // Bounds the memory of results of many families
+	locking        config.LockingConfig      // Serializes the changes of a family
+	familyLocks    *familylock.Keyed         // Locks of the families changed by this instance
 }
 
 // Ensure SQLiteFamilyRepository implements ports.FamilyRepository
@@ -120,6 +129,49 @@ func NewSQLiteFamilyRepository(db *sql.DB, logger *logging.ContextLogger) *SQLit
 		}
 	}
 
+	// Get the codec for parents and children, falling back to JSON if it is not configured
+	memberCodec, _ := codec.New("")
+	if globalConfig != nil {
+		if configured, err := codec.New(globalConfig.Database.SQLite.MemberCodec); err == nil {
+			memberCodec = configured
+		} else {
+			logger.Warn(context.Background(), "Unsupported member codec, falling back to JSON", zap.Error(err))
+		}
+
+		// Compress the member blobs of large families if enabled
+		if compression := globalConfig.Database.Compression; compression.Enabled {
+			if compressed, err := codec.WithCompression(memberCodec, compression.Algorithm, compression.ThresholdBytes); err == nil {
+				memberCodec = compressed
+			} else {
+				logger.Warn(context.Background(), "Unsupported compression algorithm, member blobs will not be compressed", zap.Error(err))
+			}
+		}
+	}
+
+	// Get the layout of members, storing them as blobs if it is not configured
+	layout := layoutJSON
+	if globalConfig != nil && globalConfig.Database.SQLite.Layout == layoutNormalized {
+		layout = layoutNormalized
+	}
+
+	// Get the read repair configuration, reading strictly if it is not configured
+	var readRepair config.ReadRepairConfig
+	if globalConfig != nil {
+		readRepair = globalConfig.Database.SQLite.ReadRepair
+	}
+
+	// Get the memory budget of results, leaving them unbounded if it is not configured
+	var memoryBudget config.MemoryBudgetConfig
+	if globalConfig != nil {
+		memoryBudget = globalConfig.Database.MemoryBudget
+	}
+
+	// Get the locking configuration, changing families without locks if it is not configured
+	var locking config.LockingConfig
+	if globalConfig != nil {
+		locking = globalConfig.Database.Locking
+	}
+
 	// Create circuit breaker using the family-service wrapper
 	cb := circuit.NewCircuitBreaker("sqlite", circuitConfig, zap.NewNop())
 
@@ -131,27 +183,77 @@ func NewSQLiteFamilyRepository(db *sql.DB, logger *logging.ContextLogger) *SQLit
 		logger:         logger,
 		circuitBreaker: cb,
 		rateLimiter:    rl,
+		codec:          memberCodec,
+		layout:         layout,
+		readRepair:     readRepair,
+		memoryBudget:   memoryBudget,
+		locking:        locking,
+		familyLocks:    familylock.NewKeyed(),
 	}
 }
 
 // ensureTableExists creates the families table if it doesn't exist
 func (r *SQLiteFamilyRepository) ensureTableExists(ctx context.Context) error {
+	// The schema was ensured when the unit of work of the context began
+	if r.unitOfWork(ctx) != nil {
+		return nil
+	}
+
 	r.logger.Debug(ctx, "Ensuring families table exists in SQLite")
 
-	query := `
-	CREATE TABLE IF NOT EXISTS families (
-		id TEXT PRIMARY KEY,
-		status TEXT NOT NULL,
-		parents TEXT NOT NULL,
-		children TEXT NOT NULL
-	);
-	`
-	_, err := r.DB.ExecContext(ctx, query)
+	_, err := r.DB.ExecContext(ctx, r.familiesSchema())
 	if err != nil {
 		r.logger.Error(ctx, "Failed to create families table in SQLite", zap.Error(err))
 		return NewRepositoryError(err, "failed to create families table", "SQLITE_ERROR")
 	}
 
+	if err := r.ensureExternalIDsSchema(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to create external IDs schema in SQLite", zap.Error(err))
+		return NewRepositoryError(err, "failed to create external IDs schema", "SQLITE_ERROR")
+	}
+
+	if err := r.ensureCountColumns(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to create count columns in SQLite", zap.Error(err))
+		return NewRepositoryError(err, "failed to create count columns", "SQLITE_ERROR")
+	}
+
+	if err := r.ensureDeletedAtColumn(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to create deleted_at column in SQLite", zap.Error(err))
+		return NewRepositoryError(err, "failed to create deleted_at column", "SQLITE_ERROR")
+	}
+
+	if err := r.ensureVersionColumn(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to create version column in SQLite", zap.Error(err))
+		return NewRepositoryError(err, "failed to create version column", "SQLITE_ERROR")
+	}
+
+	if err := r.ensureOwnerIDColumn(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to create owner_id column in SQLite", zap.Error(err))
+		return NewRepositoryError(err, "failed to create owner_id column", "SQLITE_ERROR")
+	}
+
+	if err := r.ensureTenantIDColumn(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to create tenant_id column in SQLite", zap.Error(err))
+		return NewRepositoryError(err, "failed to create tenant_id column", "SQLITE_ERROR")
+	}
+
+	if err := r.ensureOwnerIDIndex(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to create owner_id index in SQLite", zap.Error(err))
+		return NewRepositoryError(err, "failed to create owner_id index", "SQLITE_ERROR")
+	}
+
+	if err := r.ensureOutboxSchema(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to create outbox table in SQLite", zap.Error(err))
+		return NewRepositoryError(err, "failed to create outbox table", "SQLITE_ERROR")
+	}
+
+	if r.layout == layoutNormalized {
+		if err := r.ensureNormalizedSchema(ctx); err != nil {
+			r.logger.Error(ctx, "Failed to create normalized schema in SQLite", zap.Error(err))
+			return NewRepositoryError(err, "failed to create normalized schema", "SQLITE_ERROR")
+		}
+	}
+
 	r.logger.Debug(ctx, "Families table exists in SQLite")
 	return nil
 }
@@ -176,16 +278,17 @@ func (r *SQLiteFamilyRepository) GetByID(ctx context.Context, id string) (*entit
 
 	var famID string
 	var statusStr string
-	var parentsData, childrenData string
+	var parentsData, childrenData, externalIDsData, ownerID string
+	var version int
 	var retryErr error
 
 	// Define the operation to retry
 	operation := func(ctx context.Context) error {
-		query := "SELECT id, status, parents, children FROM families WHERE id = ?"
-		err := r.DB.QueryRowContext(ctx, query, id).Scan(&famID, &statusStr, &parentsData, &childrenData)
+		query := "SELECT id, status, parents, children, external_ids, owner_id, " + versionOfFamily + " FROM " + r.familyRows() + " WHERE id = ? AND " + inTenant + " AND " + notDeleted
+		err := r.DB.QueryRowContext(ctx, query, id, id, tenantOf(ctx)).Scan(&famID, &statusStr, &parentsData, &childrenData, &externalIDsData, &ownerID, &version)
 
 		if err != nil {
-			if err == sql.ErrNoRows {
+			if errors.Is(err, sql.ErrNoRows) {
 				r.logger.Info(ctx, "Family not found in SQLite", zap.String("family_id", id))
 				return repoerrors.NewNotFoundError("Family", id, nil)
 			}
@@ -198,12 +301,12 @@ func (r *SQLiteFamilyRepository) GetByID(ctx context.Context, id string) (*entit
 	// Define which errors are retryable
 	isRetryable := func(err error) bool {
 		// Don't retry not found errors
-		if _, ok := err.(*errors.NotFoundError); ok {
+		if errors.IsNotFoundError(err) {
 			return false
 		}
 
 		// Don't retry validation errors
-		if _, ok := err.(*errors.ValidationError); ok {
+		if errors.IsValidationError(err) {
 			return false
 		}
 
@@ -243,13 +346,13 @@ func (r *SQLiteFamilyRepository) GetByID(ctx context.Context, id string) (*entit
 	// Handle retry errors
 	if retryErr != nil {
 		// If it's already a typed error, return it directly
-		if _, ok := retryErr.(*errors.NotFoundError); ok {
+		if errors.IsNotFoundError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.ValidationError); ok {
+		if errors.IsValidationError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.DatabaseError); ok {
+		if errors.IsDatabaseError(retryErr) {
 			return nil, retryErr
 		}
 
@@ -259,9 +362,12 @@ func (r *SQLiteFamilyRepository) GetByID(ctx context.Context, id string) (*entit
 
 	r.logger.Debug(ctx, "Successfully retrieved family data from SQLite", zap.String("family_id", id))
 
-	// Parse parents JSON
+	// Repair legacy data if read repair is enabled
+	repairs := r.repairRow(ctx, famID, &statusStr, &parentsData, &childrenData)
+
+	// Decode parents (JSON or binary)
 	var parentDTOs []entity.ParentDTO
-	if err := json.Unmarshal([]byte(parentsData), &parentDTOs); err != nil {
+	if err := codec.DecodeParents([]byte(parentsData), &parentDTOs); err != nil {
 		r.logger.Error(ctx, "Failed to unmarshal parents data", zap.Error(err), zap.String("family_id", id))
 		return nil, NewRepositoryError(err, "failed to unmarshal parents data", "JSON_ERROR")
 	}
@@ -280,9 +386,9 @@ func (r *SQLiteFamilyRepository) GetByID(ctx context.Context, id string) (*entit
 		parents = append(parents, p)
 	}
 
-	// Parse children JSON
+	// Decode children (JSON or binary)
 	var childDTOs []entity.ChildDTO
-	if err := json.Unmarshal([]byte(childrenData), &childDTOs); err != nil {
+	if err := codec.DecodeChildren([]byte(childrenData), &childDTOs); err != nil {
 		r.logger.Error(ctx, "Failed to unmarshal children data", zap.Error(err), zap.String("family_id", id))
 		return nil, NewRepositoryError(err, "failed to unmarshal children data", "JSON_ERROR")
 	}
@@ -308,6 +414,19 @@ func (r *SQLiteFamilyRepository) GetByID(ctx context.Context, id string) (*entit
 		return nil, err
 	}
 
+	// Set the family's external IDs
+	if err := setFamilyExternalIDs(family, externalIDsData); err != nil {
+		r.logger.Error(ctx, "Failed to decode family external IDs", zap.Error(err), zap.String("family_id", id))
+		return nil, NewRepositoryError(err, "failed to decode family external IDs", "JSON_ERROR")
+	}
+	family.SetOwnerID(ownerID)
+	family.SetVersion(version)
+
+	// Write back the canonical form if the family was repaired
+	if len(repairs) > 0 {
+		r.writeBack(ctx, []*entity.Family{family})
+	}
+
 	r.logger.Debug(ctx, "Successfully retrieved family from SQLite",
 		zap.String("family_id", id),
 		zap.String("status", statusStr),
@@ -343,8 +462,8 @@ func (r *SQLiteFamilyRepository) Save(ctx context.Context, fam *entity.Family) e
 
 	// Define the operation to retry
 	operation := func(ctx context.Context) error {
-		// Begin transaction
-		tx, err := r.DB.BeginTx(ctx, nil)
+		// Begin transaction, which joins the unit of work of the context
+		tx, err := r.begin(ctx)
 		if err != nil {
 			r.logger.Error(ctx, "Failed to begin transaction", zap.Error(err), zap.String("family_id", fam.ID()))
 			return repoerrors.NewRepositoryError(err, "failed to begin transaction", repoerrors.SQLiteErrorCode, "families")
@@ -363,69 +482,104 @@ func (r *SQLiteFamilyRepository) Save(ctx context.Context, fam *entity.Family) e
 			}
 		}()
 
-		// Convert parents to DTOs for JSON serialization
+		// Convert parents to DTOs for serialization
 		parentDTOs := make([]entity.ParentDTO, 0, len(fam.Parents()))
 		for _, p := range fam.Parents() {
 			parentDTOs = append(parentDTOs, p.ToDTO())
 		}
 
-		// Convert children to DTOs for JSON serialization
+		// Convert children to DTOs for serialization
 		childDTOs := make([]entity.ChildDTO, 0, len(fam.Children()))
 		for _, c := range fam.Children() {
 			childDTOs = append(childDTOs, c.ToDTO())
 		}
 
-		// Marshal to JSON
-		parentsJSON, err := json.Marshal(parentDTOs)
+		// Encode with the configured member codec
+		parentsData, err := r.codec.EncodeParents(parentDTOs)
 		if err != nil {
-			r.logger.Error(ctx, "Failed to marshal parents to JSON",
+			r.logger.Error(ctx, "Failed to encode parents",
 				zap.Error(err),
-				zap.String("family_id", fam.ID()))
-			return repoerrors.NewRepositoryError(err, "failed to marshal parents to JSON", repoerrors.JSONErrorCode, "families")
+				zap.String("family_id", fam.ID()),
+				zap.String("codec", r.codec.Name()))
+			return repoerrors.NewRepositoryError(err, "failed to encode parents", repoerrors.JSONErrorCode, "families")
 		}
 
-		childrenJSON, err := json.Marshal(childDTOs)
+		childrenData, err := r.codec.EncodeChildren(childDTOs)
 		if err != nil {
-			r.logger.Error(ctx, "Failed to marshal children to JSON",
+			r.logger.Error(ctx, "Failed to encode children",
 				zap.Error(err),
-				zap.String("family_id", fam.ID()))
-			return repoerrors.NewRepositoryError(err, "failed to marshal children to JSON", repoerrors.JSONErrorCode, "families")
+				zap.String("family_id", fam.ID()),
+				zap.String("codec", r.codec.Name()))
+			return repoerrors.NewRepositoryError(err, "failed to encode children", repoerrors.JSONErrorCode, "families")
 		}
 
-		// Check if family exists
-		var exists bool
-		err = tx.QueryRowContext(ctx, "SELECT 1 FROM families WHERE id = ?", fam.ID()).Scan(&exists)
-		if err != nil && err != sql.ErrNoRows {
+		externalIDsData, err := encodeFamilyExternalIDs(fam)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to encode family external IDs", zap.Error(err), zap.String("family_id", fam.ID()))
+			return repoerrors.NewRepositoryError(err, "failed to encode family external IDs", repoerrors.JSONErrorCode, "families")
+		}
+
+		// Check if family exists, and that it has not been saved since it was read
+		var stored int
+		err = tx.QueryRowContext(ctx, "SELECT version FROM families WHERE id = ?", fam.ID()).Scan(&stored)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
 			r.logger.Error(ctx, "Failed to check if family exists",
 				zap.Error(err),
 				zap.String("family_id", fam.ID()))
 			return repoerrors.NewRepositoryError(err, "failed to check if family exists", repoerrors.SQLiteErrorCode, "families")
 		}
+		if err == nil && fam.Version() == 0 && !ports.IsOverwrite(ctx) {
+			return existingFamilyError(fam)
+		}
+		if err == nil && fam.Version() != 0 && fam.Version() != stored {
+			return staleVersionError(fam)
+		}
+		version := stored + 1
+		if errors.Is(err, sql.ErrNoRows) {
+			version = fam.Version() + 1
+		}
 
 		var query string
 		var args []interface{}
 		var operationType string
 
-		if err == sql.ErrNoRows {
+		switch {
+		case errors.Is(err, sql.ErrNoRows) && r.layout == layoutNormalized:
+			// Insert new family, whose members are saved below
+			operationType = "insert"
+			query = "INSERT INTO families (id, status, external_ids, parent_count, children_count, owner_id, tenant_id, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+			args = []interface{}{fam.ID(), string(fam.Status()), externalIDsData, len(parentDTOs), len(childDTOs), fam.OwnerID(), tenantOf(ctx), version}
+			r.logger.Debug(ctx, "Inserting new family",
+				zap.String("family_id", fam.ID()),
+				zap.String("status", string(fam.Status())))
+		case errors.Is(err, sql.ErrNoRows):
 			// Insert new family
 			operationType = "insert"
-			query = "INSERT INTO families (id, status, parents, children) VALUES (?, ?, ?, ?)"
-			args = []interface{}{fam.ID(), string(fam.Status()), parentsJSON, childrenJSON}
+			query = "INSERT INTO families (id, status, parents, children, external_ids, parent_count, children_count, owner_id, tenant_id, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+			args = []interface{}{fam.ID(), string(fam.Status()), parentsData, childrenData, externalIDsData, len(parentDTOs), len(childDTOs), fam.OwnerID(), tenantOf(ctx), version}
 			r.logger.Debug(ctx, "Inserting new family",
 				zap.String("family_id", fam.ID()),
 				zap.String("status", string(fam.Status())))
-		} else {
+		case r.layout == layoutNormalized:
+			// Update existing family, whose members are saved below
+			operationType = "update"
+			query = "UPDATE families SET status = ?, external_ids = ?, parent_count = ?, children_count = ?, owner_id = ?, deleted_at = NULL, version = ? WHERE id = ? AND version = ? AND tenant_id = ?"
+			args = []interface{}{string(fam.Status()), externalIDsData, len(parentDTOs), len(childDTOs), fam.OwnerID(), version, fam.ID(), stored, tenantOf(ctx)}
+			r.logger.Debug(ctx, "Updating existing family",
+				zap.String("family_id", fam.ID()),
+				zap.String("status", string(fam.Status())))
+		default:
 			// Update existing family
 			operationType = "update"
-			query = "UPDATE families SET status = ?, parents = ?, children = ? WHERE id = ?"
-			args = []interface{}{string(fam.Status()), parentsJSON, childrenJSON, fam.ID()}
+			query = "UPDATE families SET status = ?, parents = ?, children = ?, external_ids = ?, parent_count = ?, children_count = ?, owner_id = ?, deleted_at = NULL, version = ? WHERE id = ? AND version = ? AND tenant_id = ?"
+			args = []interface{}{string(fam.Status()), parentsData, childrenData, externalIDsData, len(parentDTOs), len(childDTOs), fam.OwnerID(), version, fam.ID(), stored, tenantOf(ctx)}
 			r.logger.Debug(ctx, "Updating existing family",
 				zap.String("family_id", fam.ID()),
 				zap.String("status", string(fam.Status())))
 		}
 
 		// Execute SQL
-		_, err = tx.ExecContext(ctx, query, args...)
+		result, err := tx.ExecContext(ctx, query, args...)
 		if err != nil {
 			r.logger.Error(ctx, "Failed to save family to SQLite",
 				zap.Error(err),
@@ -433,6 +587,24 @@ func (r *SQLiteFamilyRepository) Save(ctx context.Context, fam *entity.Family) e
 				zap.String("operation", operationType))
 			return repoerrors.NewRepositoryError(err, "failed to save family to SQLite", repoerrors.SQLiteErrorCode, "families")
 		}
+		if saved, err := result.RowsAffected(); err == nil && saved == 0 {
+			return staleVersionError(fam)
+		}
+
+		// Save the members in the normalized tables
+		if r.layout == layoutNormalized {
+			if err := saveMembers(ctx, tx.Tx, fam.ID(), parentDTOs, childDTOs); err != nil {
+				r.logger.Error(ctx, "Failed to save family members to SQLite",
+					zap.Error(err),
+					zap.String("family_id", fam.ID()))
+				return repoerrors.NewRepositoryError(err, "failed to save family members to SQLite", repoerrors.SQLiteErrorCode, "family_members")
+			}
+		}
+
+		// Enforce external ID uniqueness and update the external ID index
+		if err := r.saveExternalIDs(ctx, tx.Tx, fam); err != nil {
+			return err
+		}
 
 		// Commit transaction
 		if err = tx.Commit(); err != nil {
@@ -442,6 +614,7 @@ func (r *SQLiteFamilyRepository) Save(ctx context.Context, fam *entity.Family) e
 			return repoerrors.NewRepositoryError(err, "failed to commit transaction", repoerrors.SQLiteErrorCode, "families")
 		}
 		committed = true
+		fam.SetVersion(version)
 
 		r.logger.Info(ctx, "Successfully saved family to SQLite",
 			zap.String("family_id", fam.ID()),
@@ -455,7 +628,12 @@ func (r *SQLiteFamilyRepository) Save(ctx context.Context, fam *entity.Family) e
 	// Define which errors are retryable
 	isRetryable := func(err error) bool {
 		// Don't retry validation errors
-		if _, ok := err.(*errors.ValidationError); ok {
+		if errors.IsValidationError(err) {
+			return false
+		}
+
+		// Don't retry version conflicts, which fail again until the family is read again
+		if errors.Is(err, domainerrors.ErrConcurrencyConflict) {
 			return false
 		}
 
@@ -495,10 +673,13 @@ func (r *SQLiteFamilyRepository) Save(ctx context.Context, fam *entity.Family) e
 	// Handle retry errors
 	if retryErr != nil {
 		// If it's already a typed error, return it directly
-		if _, ok := retryErr.(*errors.ValidationError); ok {
+		if errors.IsValidationError(retryErr) {
 			return retryErr
 		}
-		if _, ok := retryErr.(*errors.DatabaseError); ok {
+		if errors.IsDatabaseError(retryErr) {
+			return retryErr
+		}
+		if errors.Is(retryErr, domainerrors.ErrConcurrencyConflict) {
 			return retryErr
 		}
 
@@ -527,15 +708,21 @@ func (r *SQLiteFamilyRepository) FindByParentID(ctx context.Context, parentID st
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	var families []*entity.Family
-	var retryErr error
+	var result *assembly.Assembler
+	var repaired []*entity.Family
+	var retryErr, budgetErr error
 
 	// Define the operation to retry
 	operation := func(ctx context.Context) error {
-		// SQLite doesn't have native JSON path operators like PostgreSQL,
-		// so we need to fetch all families and filter in application code
-		r.logger.Debug(ctx, "Querying all families to filter by parent ID", zap.String("parent_id", parentID))
-		rows, err := r.DB.QueryContext(ctx, "SELECT id, status, parents, children FROM families")
+		// SQLite doesn't have native JSON path operators like PostgreSQL, so with the json
+		// layout we need to fetch all families and filter in application code. The
+		// normalized layout selects the families of the parent with the index of members.
+		r.logger.Debug(ctx, "Querying families to filter by parent ID", zap.String("parent_id", parentID))
+		query, args := "SELECT id, status, parents, children, external_ids, owner_id FROM families WHERE "+inTenant+" AND "+notDeleted, []interface{}{tenantOf(ctx)}
+		if r.layout == layoutNormalized {
+			query, args = familiesOfMember(roleParent), []interface{}{parentID, tenantOf(ctx)}
+		}
+		rows, err := r.DB.QueryContext(ctx, query, args...)
 		if err != nil {
 			r.logger.Error(ctx, "Failed to query families", zap.Error(err))
 			return repoerrors.NewRepositoryError(err, "failed to query families", repoerrors.SQLiteErrorCode, "families")
@@ -543,21 +730,26 @@ func (r *SQLiteFamilyRepository) FindByParentID(ctx context.Context, parentID st
 		defer rows.Close()
 
 		var matchCount int
-		families = []*entity.Family{} // Initialize empty slice to avoid nil return
+		result = assembly.NewAssembler(r.memoryBudget, "FindByParentID")
+		repaired = nil
+		budgetErr = nil
 
 		for rows.Next() {
 			var famID string
 			var statusStr string
-			var parentsData, childrenData string
+			var parentsData, childrenData, externalIDsData, ownerID string
 
-			if err := rows.Scan(&famID, &statusStr, &parentsData, &childrenData); err != nil {
+			if err := rows.Scan(&famID, &statusStr, &parentsData, &childrenData, &externalIDsData, &ownerID); err != nil {
 				r.logger.Error(ctx, "Failed to scan family row", zap.Error(err))
 				return repoerrors.NewRepositoryError(err, "failed to scan family row", repoerrors.SQLiteErrorCode, "families")
 			}
 
-			// Parse parents JSON
+			// Repair legacy data if read repair is enabled
+			repairs := r.repairRow(ctx, famID, &statusStr, &parentsData, &childrenData)
+
+			// Decode parents (JSON or binary)
 			var parentDTOs []entity.ParentDTO
-			if err := json.Unmarshal([]byte(parentsData), &parentDTOs); err != nil {
+			if err := codec.DecodeParents([]byte(parentsData), &parentDTOs); err != nil {
 				r.logger.Error(ctx, "Failed to unmarshal parents data",
 					zap.Error(err),
 					zap.String("family_id", famID))
@@ -596,9 +788,9 @@ func (r *SQLiteFamilyRepository) FindByParentID(ctx context.Context, parentID st
 				parents = append(parents, p)
 			}
 
-			// Parse children JSON
+			// Decode children (JSON or binary)
 			var childDTOs []entity.ChildDTO
-			if err := json.Unmarshal([]byte(childrenData), &childDTOs); err != nil {
+			if err := codec.DecodeChildren([]byte(childrenData), &childDTOs); err != nil {
 				r.logger.Error(ctx, "Failed to unmarshal children data",
 					zap.Error(err),
 					zap.String("family_id", famID))
@@ -628,7 +820,24 @@ func (r *SQLiteFamilyRepository) FindByParentID(ctx context.Context, parentID st
 				return repoerrors.NewRepositoryError(err, "failed to create family entity", repoerrors.ConversionErrorCode, "families")
 			}
 
-			families = append(families, fam)
+			// Set the family's external IDs
+			if err := setFamilyExternalIDs(fam, externalIDsData); err != nil {
+				r.logger.Error(ctx, "Failed to decode family external IDs",
+					zap.Error(err),
+					zap.String("family_id", famID))
+				return repoerrors.NewRepositoryError(err, "failed to decode family external IDs", repoerrors.JSONErrorCode, "families")
+			}
+			fam.SetOwnerID(ownerID)
+
+			if len(repairs) > 0 {
+				repaired = append(repaired, fam)
+			}
+			if err := result.Add(fam); err != nil {
+				// Stop reading without failing the operation, so that a result that is too
+				// large is neither retried nor counted against the circuit breaker
+				budgetErr = err
+				return nil
+			}
 		}
 
 		if err := rows.Err(); err != nil {
@@ -638,7 +847,7 @@ func (r *SQLiteFamilyRepository) FindByParentID(ctx context.Context, parentID st
 
 		r.logger.Info(ctx, "Successfully found families by parent ID",
 			zap.String("parent_id", parentID),
-			zap.Int("family_count", len(families)),
+			zap.Int("family_count", len(result.Families())),
 			zap.Int("total_matches", matchCount))
 		return nil
 	}
@@ -646,7 +855,7 @@ func (r *SQLiteFamilyRepository) FindByParentID(ctx context.Context, parentID st
 	// Define which errors are retryable
 	isRetryable := func(err error) bool {
 		// Don't retry validation errors
-		if _, ok := err.(*errors.ValidationError); ok {
+		if errors.IsValidationError(err) {
 			return false
 		}
 
@@ -686,10 +895,10 @@ func (r *SQLiteFamilyRepository) FindByParentID(ctx context.Context, parentID st
 	// Handle retry errors
 	if retryErr != nil {
 		// If it's already a typed error, return it directly
-		if _, ok := retryErr.(*errors.ValidationError); ok {
+		if errors.IsValidationError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.DatabaseError); ok {
+		if errors.IsDatabaseError(retryErr) {
 			return nil, retryErr
 		}
 
@@ -697,13 +906,28 @@ func (r *SQLiteFamilyRepository) FindByParentID(ctx context.Context, parentID st
 		return nil, repoerrors.NewRepositoryError(retryErr, "failed to find families by parent ID after retries", repoerrors.SQLiteErrorCode, "families")
 	}
 
-	return families, nil
+	// Return the error of a result that exceeds the memory budget
+	if budgetErr != nil {
+		return nil, budgetErr
+	}
+
+	// Write back the canonical form of repaired families
+	r.writeBack(ctx, repaired)
+
+	return result.Families(), nil
 }
 
 // GetAll retrieves all families
 func (r *SQLiteFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family, error) {
 	r.logger.Debug(ctx, "Getting all families from SQLite")
 
+	return r.queryFamilies(ctx, "GetAll", "SELECT id, status, parents, children, external_ids, owner_id FROM "+r.familyRows()+" WHERE "+inTenant+" AND "+notDeleted, tenantOf(ctx))
+}
+
+// queryFamilies runs a query that selects family rows and decodes the families. The rows
+// have the id, status, parents, children, external_ids, and owner_id columns, and
+// optionally the version column, without which the families are read without versions.
+func (r *SQLiteFamilyRepository) queryFamilies(ctx context.Context, name, query string, args ...interface{}) ([]*entity.Family, error) {
 	// Ensure table exists
 	if err := r.ensureTableExists(ctx); err != nil {
 		return nil, err
@@ -713,35 +937,50 @@ func (r *SQLiteFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family,
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	var families []*entity.Family
-	var retryErr error
+	var result *assembly.Assembler
+	var repaired []*entity.Family
+	var retryErr, budgetErr error
 
 	// Define the operation to retry
 	operation := func(ctx context.Context) error {
-		// Query all families
-		rows, err := r.DB.QueryContext(ctx, "SELECT id, status, parents, children FROM families")
+		// Query the families
+		rows, err := r.DB.QueryContext(ctx, query, args...)
 		if err != nil {
 			r.logger.Error(ctx, "Failed to query all families", zap.Error(err))
 			return repoerrors.NewRepositoryError(err, "failed to query families", repoerrors.SQLiteErrorCode, "families")
 		}
 		defer rows.Close()
+		columns, err := rows.Columns()
+		if err != nil {
+			return repoerrors.NewRepositoryError(err, "failed to read family columns", repoerrors.SQLiteErrorCode, "families")
+		}
+		versioned := len(columns) > 6
 
-		// Initialize empty slice to avoid nil return
-		families = []*entity.Family{}
+		result = assembly.NewAssembler(r.memoryBudget, name)
+		repaired = nil
+		budgetErr = nil
 
 		for rows.Next() {
 			var famID string
 			var statusStr string
-			var parentsData, childrenData string
+			var parentsData, childrenData, externalIDsData, ownerID string
+			var version int
 
-			if err := rows.Scan(&famID, &statusStr, &parentsData, &childrenData); err != nil {
+			dest := []interface{}{&famID, &statusStr, &parentsData, &childrenData, &externalIDsData, &ownerID}
+			if versioned {
+				dest = append(dest, &version)
+			}
+			if err := rows.Scan(dest...); err != nil {
 				r.logger.Error(ctx, "Failed to scan family row", zap.Error(err))
 				return repoerrors.NewRepositoryError(err, "failed to scan family row", repoerrors.SQLiteErrorCode, "families")
 			}
 
-			// Parse parents JSON
+			// Repair legacy data if read repair is enabled
+			repairs := r.repairRow(ctx, famID, &statusStr, &parentsData, &childrenData)
+
+			// Decode parents (JSON or binary)
 			var parentDTOs []entity.ParentDTO
-			if err := json.Unmarshal([]byte(parentsData), &parentDTOs); err != nil {
+			if err := codec.DecodeParents([]byte(parentsData), &parentDTOs); err != nil {
 				r.logger.Error(ctx, "Failed to unmarshal parents data",
 					zap.Error(err),
 					zap.String("family_id", famID))
@@ -762,9 +1001,9 @@ func (r *SQLiteFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family,
 				parents = append(parents, p)
 			}
 
-			// Parse children JSON
+			// Decode children (JSON or binary)
 			var childDTOs []entity.ChildDTO
-			if err := json.Unmarshal([]byte(childrenData), &childDTOs); err != nil {
+			if err := codec.DecodeChildren([]byte(childrenData), &childDTOs); err != nil {
 				r.logger.Error(ctx, "Failed to unmarshal children data",
 					zap.Error(err),
 					zap.String("family_id", famID))
@@ -794,10 +1033,28 @@ func (r *SQLiteFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family,
 				return repoerrors.NewRepositoryError(err, "failed to create family entity", repoerrors.ConversionErrorCode, "families")
 			}
 
+			// Set the family's external IDs
+			if err := setFamilyExternalIDs(fam, externalIDsData); err != nil {
+				r.logger.Error(ctx, "Failed to decode family external IDs",
+					zap.Error(err),
+					zap.String("family_id", famID))
+				return repoerrors.NewRepositoryError(err, "failed to decode family external IDs", repoerrors.JSONErrorCode, "families")
+			}
+			fam.SetOwnerID(ownerID)
+			fam.SetVersion(version)
+
 			r.logger.Debug(ctx, "Retrieved family",
 				zap.String("family_id", famID),
 				zap.String("status", statusStr))
-			families = append(families, fam)
+			if len(repairs) > 0 {
+				repaired = append(repaired, fam)
+			}
+			if err := result.Add(fam); err != nil {
+				// Stop reading without failing the operation, so that a result that is too
+				// large is neither retried nor counted against the circuit breaker
+				budgetErr = err
+				return nil
+			}
 		}
 
 		if err := rows.Err(); err != nil {
@@ -805,14 +1062,14 @@ func (r *SQLiteFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family,
 			return repoerrors.NewRepositoryError(err, "error iterating over family rows", repoerrors.SQLiteErrorCode, "families")
 		}
 
-		r.logger.Info(ctx, "Successfully retrieved all families", zap.Int("family_count", len(families)))
+		r.logger.Info(ctx, "Successfully retrieved all families", zap.Int("family_count", len(result.Families())))
 		return nil
 	}
 
 	// Define which errors are retryable
 	isRetryable := func(err error) bool {
 		// Don't retry validation errors
-		if _, ok := err.(*errors.ValidationError); ok {
+		if errors.IsValidationError(err) {
 			return false
 		}
 
@@ -833,11 +1090,11 @@ func (r *SQLiteFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family,
 	// Wrap the circuit breaker operation with rate limiter
 	rateOperation := func(ctx context.Context) error {
 		// Execute with circuit breaker
-		return r.circuitBreaker.Execute(ctx, "GetAll", circuitOperation)
+		return r.circuitBreaker.Execute(ctx, name, circuitOperation)
 	}
 
 	// Execute with rate limiter
-	err := r.rateLimiter.Execute(ctxWithTimeout, "GetAll", rateOperation)
+	err := r.rateLimiter.Execute(ctxWithTimeout, name, rateOperation)
 
 	// Check for errors from rate limiter or circuit breaker
 	if err != nil && retryErr == nil {
@@ -852,18 +1109,26 @@ func (r *SQLiteFamilyRepository) GetAll(ctx context.Context) ([]*entity.Family,
 	// Handle retry errors
 	if retryErr != nil {
 		// If it's already a typed error, return it directly
-		if _, ok := retryErr.(*errors.ValidationError); ok {
+		if errors.IsValidationError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.DatabaseError); ok {
+		if errors.IsDatabaseError(retryErr) {
 			return nil, retryErr
 		}
 
 		// Otherwise, wrap it in a database error
-		return nil, repoerrors.NewRepositoryError(retryErr, "failed to get all families after retries", repoerrors.SQLiteErrorCode, "families")
+		return nil, repoerrors.NewRepositoryError(retryErr, "failed to query families after retries", repoerrors.SQLiteErrorCode, "families")
 	}
 
-	return families, nil
+	// Return the error of a result that exceeds the memory budget
+	if budgetErr != nil {
+		return nil, budgetErr
+	}
+
+	// Write back the canonical form of repaired families
+	r.writeBack(ctx, repaired)
+
+	return result.Families(), nil
 }
 
 // FindByChildID finds the family that contains a specific child
@@ -885,14 +1150,20 @@ func (r *SQLiteFamilyRepository) FindByChildID(ctx context.Context, childID stri
 	defer cancel()
 
 	var family *entity.Family
+	var repaired bool
 	var retryErr error
 
 	// Define the operation to retry
 	operation := func(ctx context.Context) error {
-		// SQLite doesn't have native JSON path operators like PostgreSQL,
-		// so we need to fetch all families and filter in application code
-		r.logger.Debug(ctx, "Querying all families to filter by child ID", zap.String("child_id", childID))
-		rows, err := r.DB.QueryContext(ctx, "SELECT id, status, parents, children FROM families")
+		// SQLite doesn't have native JSON path operators like PostgreSQL, so with the json
+		// layout we need to fetch all families and filter in application code. The
+		// normalized layout selects the family of the child with the index of members.
+		r.logger.Debug(ctx, "Querying families to filter by child ID", zap.String("child_id", childID))
+		query, args := "SELECT id, status, parents, children, external_ids, owner_id FROM families WHERE "+inTenant+" AND "+notDeleted, []interface{}{tenantOf(ctx)}
+		if r.layout == layoutNormalized {
+			query, args = familiesOfMember(roleChild), []interface{}{childID, tenantOf(ctx)}
+		}
+		rows, err := r.DB.QueryContext(ctx, query, args...)
 		if err != nil {
 			r.logger.Error(ctx, "Failed to query families", zap.Error(err))
 			return repoerrors.NewRepositoryError(err, "failed to query families", repoerrors.SQLiteErrorCode, "families")
@@ -904,18 +1175,21 @@ func (r *SQLiteFamilyRepository) FindByChildID(ctx context.Context, childID stri
 		for rows.Next() {
 			var famID string
 			var statusStr string
-			var parentsData, childrenData string
+			var parentsData, childrenData, externalIDsData, ownerID string
 
-			if err := rows.Scan(&famID, &statusStr, &parentsData, &childrenData); err != nil {
+			if err := rows.Scan(&famID, &statusStr, &parentsData, &childrenData, &externalIDsData, &ownerID); err != nil {
 				r.logger.Error(ctx, "Failed to scan family row", zap.Error(err))
 				return repoerrors.NewRepositoryError(err, "failed to scan family row", repoerrors.SQLiteErrorCode, "families")
 			}
 
+			// Repair legacy data if read repair is enabled
+			repairs := r.repairRow(ctx, famID, &statusStr, &parentsData, &childrenData)
+
 			familiesChecked++
 
-			// Parse children JSON
+			// Decode children (JSON or binary)
 			var childDTOs []entity.ChildDTO
-			if err := json.Unmarshal([]byte(childrenData), &childDTOs); err != nil {
+			if err := codec.DecodeChildren([]byte(childrenData), &childDTOs); err != nil {
 				r.logger.Error(ctx, "Failed to unmarshal children data",
 					zap.Error(err),
 					zap.String("family_id", famID))
@@ -939,9 +1213,9 @@ func (r *SQLiteFamilyRepository) FindByChildID(ctx context.Context, childID stri
 				zap.String("family_id", famID),
 				zap.String("child_id", childID))
 
-			// Parse parents JSON
+			// Decode parents (JSON or binary)
 			var parentDTOs []entity.ParentDTO
-			if err := json.Unmarshal([]byte(parentsData), &parentDTOs); err != nil {
+			if err := codec.DecodeParents([]byte(parentsData), &parentDTOs); err != nil {
 				r.logger.Error(ctx, "Failed to unmarshal parents data",
 					zap.Error(err),
 					zap.String("family_id", famID))
@@ -985,7 +1259,17 @@ func (r *SQLiteFamilyRepository) FindByChildID(ctx context.Context, childID stri
 				return repoerrors.NewRepositoryError(err, "failed to create family entity", repoerrors.ConversionErrorCode, "families")
 			}
 
+			// Set the family's external IDs
+			if err := setFamilyExternalIDs(fam, externalIDsData); err != nil {
+				r.logger.Error(ctx, "Failed to decode family external IDs",
+					zap.Error(err),
+					zap.String("family_id", famID))
+				return repoerrors.NewRepositoryError(err, "failed to decode family external IDs", repoerrors.JSONErrorCode, "families")
+			}
+			fam.SetOwnerID(ownerID)
+
 			family = fam
+			repaired = len(repairs) > 0
 			r.logger.Info(ctx, "Successfully found family by child ID",
 				zap.String("child_id", childID),
 				zap.String("family_id", famID),
@@ -1009,12 +1293,12 @@ func (r *SQLiteFamilyRepository) FindByChildID(ctx context.Context, childID stri
 	// Define which errors are retryable
 	isRetryable := func(err error) bool {
 		// Don't retry not found errors
-		if _, ok := err.(*errors.NotFoundError); ok {
+		if errors.IsNotFoundError(err) {
 			return false
 		}
 
 		// Don't retry validation errors
-		if _, ok := err.(*errors.ValidationError); ok {
+		if errors.IsValidationError(err) {
 			return false
 		}
 
@@ -1054,13 +1338,13 @@ func (r *SQLiteFamilyRepository) FindByChildID(ctx context.Context, childID stri
 	// Handle retry errors
 	if retryErr != nil {
 		// If it's already a typed error, return it directly
-		if _, ok := retryErr.(*errors.NotFoundError); ok {
+		if errors.IsNotFoundError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.ValidationError); ok {
+		if errors.IsValidationError(retryErr) {
 			return nil, retryErr
 		}
-		if _, ok := retryErr.(*errors.DatabaseError); ok {
+		if errors.IsDatabaseError(retryErr) {
 			return nil, retryErr
 		}
 
@@ -1068,5 +1352,10 @@ func (r *SQLiteFamilyRepository) FindByChildID(ctx context.Context, childID stri
 		return nil, repoerrors.NewRepositoryError(retryErr, "failed to find family by child ID after retries", repoerrors.SQLiteErrorCode, "families")
 	}
 
+	// Write back the canonical form if the family was repaired
+	if repaired {
+		r.writeBack(ctx, []*entity.Family{family})
+	}
+
 	return family, nil
 }
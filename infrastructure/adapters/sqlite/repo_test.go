@@ -10,12 +10,16 @@
 	"time"
 
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	rate "github.com/abitofhelp/family-service/infrastructure/adapters/ratewrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/repositorytest"
 	"github.com/abitofhelp/servicelib/logging"
 	"github.com/google/uuid"
 	"github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -311,3 +315,133 @@ func TestSQLiteFamilyRepository_GetAll(t *testing.T) {
 		assert.Len(t, retrieved, len(families))
 	})
 }
+
+// TestSQLiteFamilyRepository_ExternalIDs tests that external IDs are persisted, unique, and searchable
+func TestSQLiteFamilyRepository_Conformance(t *testing.T) {
+	repo, db, ctrl := setupTest(t)
+	defer ctrl.Finish()
+	defer db.Close()
+	// The suite runs more operations than the default burst of the rate limiter
+	repo.rateLimiter = rate.NewRateLimiter("sqlite", &config.RateConfig{Enabled: false}, zap.NewNop())
+
+	repositorytest.Run(t, repo)
+}
+
+func TestSQLiteFamilyRepository_ExternalIDs(t *testing.T) {
+	repo, db, ctrl := setupTest(t)
+	defer ctrl.Finish()
+	defer db.Close()
+
+	parent, err := entity.NewParent(generateTestUUID(), "John", "Doe", time.Now().AddDate(-30, 0, 0), nil)
+	require.NoError(t, err)
+	require.NoError(t, parent.SetExternalIDs(map[string]string{"crm": "P-1"}))
+
+	family, err := entity.NewFamily(generateTestUUID(), entity.Single, []*entity.Parent{parent}, []*entity.Child{})
+	require.NoError(t, err)
+	require.NoError(t, family.SetExternalIDs(map[string]string{"crm": "F-1"}))
+	require.NoError(t, repo.Save(context.Background(), family))
+
+	t.Run("round trip", func(t *testing.T) {
+		retrieved, err := repo.GetByID(context.Background(), family.ID())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"crm": "F-1"}, retrieved.ExternalIDs())
+		assert.Equal(t, map[string]string{"crm": "P-1"}, retrieved.Parents()[0].ExternalIDs())
+	})
+
+	t.Run("find by family and member external ID", func(t *testing.T) {
+		families, err := repo.FindByExternalID(context.Background(), "crm", "F-1")
+		require.NoError(t, err)
+		require.Len(t, families, 1)
+		assert.Equal(t, family.ID(), families[0].ID())
+
+		families, err = repo.FindByExternalID(context.Background(), "crm", "P-1")
+		require.NoError(t, err)
+		assert.Len(t, families, 1)
+
+		families, err = repo.FindByExternalID(context.Background(), "crm", "unknown")
+		require.NoError(t, err)
+		assert.Empty(t, families)
+	})
+
+	t.Run("same parent in another family", func(t *testing.T) {
+		other, err := entity.NewFamily(generateTestUUID(), entity.Single, []*entity.Parent{parent}, []*entity.Child{})
+		require.NoError(t, err)
+		require.NoError(t, repo.Save(context.Background(), other))
+
+		families, err := repo.FindByExternalID(context.Background(), "crm", "P-1")
+		require.NoError(t, err)
+		assert.Len(t, families, 2)
+	})
+
+	t.Run("external ID held by another entity", func(t *testing.T) {
+		otherParent, err := entity.NewParent(generateTestUUID(), "Jane", "Smith", time.Now().AddDate(-28, 0, 0), nil)
+		require.NoError(t, err)
+		require.NoError(t, otherParent.SetExternalIDs(map[string]string{"crm": "P-1"}))
+
+		other, err := entity.NewFamily(generateTestUUID(), entity.Single, []*entity.Parent{otherParent}, []*entity.Child{})
+		require.NoError(t, err)
+		assert.Error(t, repo.Save(context.Background(), other))
+
+		// Families and parents have separate external ID namespaces
+		require.NoError(t, otherParent.SetExternalIDs(nil))
+		require.NoError(t, other.SetExternalIDs(map[string]string{"crm": "P-1"}))
+		assert.NoError(t, repo.Save(context.Background(), other))
+	})
+}
+
+func TestSQLiteFamilyRepository_ReadRepair(t *testing.T) {
+	repo, db, ctrl := setupTest(t)
+	defer ctrl.Finish()
+	defer db.Close()
+
+	// A legacy row with snake_case keys, a date without a time, and no status
+	famID := generateTestUUID()
+	parentID := generateTestUUID()
+	parents := `[{"id":"` + parentID + `","first_name":"John","last_name":"Doe","birth_date":"1980-01-02"}]`
+	_, err := db.Exec("INSERT INTO families (id, status, parents, children) VALUES (?, ?, ?, ?)", famID, "", parents, "null")
+	require.NoError(t, err)
+
+	t.Run("strict read fails", func(t *testing.T) {
+		_, err := repo.GetByID(context.Background(), famID)
+		assert.Error(t, err)
+	})
+
+	t.Run("tolerant read repairs", func(t *testing.T) {
+		repo.readRepair.Enabled = true
+
+		fam, err := repo.GetByID(context.Background(), famID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.Single, fam.Status())
+		require.Len(t, fam.Parents(), 1)
+		assert.Equal(t, parentID, fam.Parents()[0].ID())
+		assert.Equal(t, "John", fam.Parents()[0].FirstName())
+		assert.Equal(t, time.Date(1980, 1, 2, 0, 0, 0, 0, time.UTC), fam.Parents()[0].BirthDate().UTC())
+		assert.Empty(t, fam.Children())
+
+		families, err := repo.FindByParentID(context.Background(), parentID)
+		require.NoError(t, err)
+		assert.Len(t, families, 1)
+
+		// The row is not written back by default
+		var status string
+		require.NoError(t, db.QueryRow("SELECT status FROM families WHERE id = ?", famID).Scan(&status))
+		assert.Equal(t, "", status)
+	})
+
+	t.Run("write back", func(t *testing.T) {
+		repo.readRepair.WriteBack = true
+
+		_, err := repo.GetByID(context.Background(), famID)
+		require.NoError(t, err)
+
+		var status, storedParents string
+		require.NoError(t, db.QueryRow("SELECT status, parents FROM families WHERE id = ?", famID).Scan(&status, &storedParents))
+		assert.Equal(t, string(entity.Single), status)
+		assert.Contains(t, storedParents, `"FirstName":"John"`)
+
+		// The canonical form can be read strictly
+		repo.readRepair = config.ReadRepairConfig{}
+		_, err = repo.GetByID(context.Background(), famID)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/migration"
+)
+
+// createFamiliesSchema creates the families table
+const createFamiliesSchema = `
+	CREATE TABLE IF NOT EXISTS families (
+		id TEXT PRIMARY KEY,
+		status TEXT NOT NULL,
+		parents TEXT NOT NULL,
+		children TEXT NOT NULL,
+		external_ids TEXT NOT NULL DEFAULT '{}',
+		parent_count INTEGER,
+		children_count INTEGER,
+		deleted_at TEXT,
+		version INTEGER NOT NULL DEFAULT 1,
+		owner_id TEXT NOT NULL DEFAULT '',
+		tenant_id TEXT NOT NULL DEFAULT ''
+	);
+	`
+
+// SQLite takes a write lock on the whole database for each schema change. Readers are
+// not blocked in WAL mode, but writers wait until the change is committed.
+const (
+	lockNewTable  = "Write lock on the database while the empty table is created"
+	lockAddColumn = "Write lock on the database while the column is added; rows are not rewritten"
+	lockIndex     = "Write lock on the database for the duration of the index build; writes wait"
+)
+
+// schemaStep is a step of the schema with the query that reports whether it has been applied
+type schemaStep struct {
+	migration.Step
+	applied string // Query returning a positive count if the step has nothing left to do
+}
+
+// tableExists returns a query that counts the tables with the given name
+func tableExists(table string) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = '%s'", table)
+}
+
+// indexExists returns a query that counts the indexes with the given name
+func indexExists(index string) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = '%s'", index)
+}
+
+// schemaSteps are the steps of the schema, in the order they are applied
+var schemaSteps = []schemaStep{
+	{Step: migration.Step{Name: "Create the families table", Table: "families", DDL: createFamiliesSchema, Lock: lockNewTable},
+		applied: tableExists("families")},
+	{Step: migration.Step{Name: "Add the external_ids column to the families table", Table: "families", DDL: addExternalIDsColumn, Lock: lockAddColumn},
+		// A families table created by the first step already has the column
+		applied: "SELECT (SELECT COUNT(*) FROM pragma_table_info('families') WHERE name = 'external_ids') + ((" + tableExists("families") + ") = 0)"},
+	{Step: migration.Step{Name: "Add the parent_count and children_count columns to the families table", Table: "families", DDL: addCountColumns, Lock: lockAddColumn},
+		// A families table created by the first step already has the columns
+		applied: "SELECT ((" + countColumnsExist + ") = 2) + ((" + tableExists("families") + ") = 0)"},
+	{Step: migration.Step{Name: "Create index idx_families_parent_count", Table: "families", DDL: createParentCountIndex, Lock: lockIndex},
+		applied: indexExists("idx_families_parent_count")},
+	{Step: migration.Step{Name: "Create index idx_families_children_count", Table: "families", DDL: createChildrenCountIndex, Lock: lockIndex},
+		applied: indexExists("idx_families_children_count")},
+	{Step: migration.Step{Name: "Add the deleted_at column to the families table", Table: "families", DDL: addDeletedAtColumn, Lock: lockAddColumn},
+		// A families table created by the first step already has the column
+		applied: "SELECT (" + deletedAtColumnExists + ") + ((" + tableExists("families") + ") = 0)"},
+	{Step: migration.Step{Name: "Create index idx_families_deleted_at", Table: "families", DDL: createDeletedAtIndex, Lock: lockIndex},
+		applied: indexExists("idx_families_deleted_at")},
+	{Step: migration.Step{Name: "Add the version column to the families table", Table: "families", DDL: addVersionColumn, Lock: lockAddColumn},
+		// A families table created by the first step already has the column
+		applied: "SELECT (" + versionColumnExists + ") + ((" + tableExists("families") + ") = 0)"},
+	{Step: migration.Step{Name: "Add the owner_id column to the families table", Table: "families", DDL: addOwnerIDColumn, Lock: lockAddColumn},
+		// A families table created by the first step already has the column
+		applied: "SELECT (" + ownerIDColumnExists + ") + ((" + tableExists("families") + ") = 0)"},
+	{Step: migration.Step{Name: "Add the tenant_id column to the families table", Table: "families", DDL: addTenantIDColumn, Lock: lockAddColumn},
+		// A families table created by the first step already has the column
+		applied: "SELECT (" + tenantIDColumnExists + ") + ((" + tableExists("families") + ") = 0)"},
+	{Step: migration.Step{Name: "Create index idx_families_tenant_id", Table: "families", DDL: createTenantIDIndex, Lock: lockIndex},
+		applied: indexExists("idx_families_tenant_id")},
+	{Step: migration.Step{Name: "Create index idx_families_owner_id", Table: "families", DDL: createOwnerIDIndex, Lock: lockIndex},
+		applied: indexExists("idx_families_owner_id")},
+	{Step: migration.Step{Name: "Create the family_external_ids table", Table: "family_external_ids", DDL: createExternalIDsTable, Lock: lockNewTable},
+		applied: tableExists("family_external_ids")},
+	{Step: migration.Step{Name: "Create index idx_family_external_ids_family_id", Table: "family_external_ids", DDL: createExternalIDsFamilyIndex, Lock: lockIndex},
+		applied: indexExists("idx_family_external_ids_family_id")},
+	{Step: migration.Step{Name: "Create index idx_family_external_ids_lookup", Table: "family_external_ids", DDL: createExternalIDsLookupIndex, Lock: lockIndex},
+		applied: indexExists("idx_family_external_ids_lookup")},
+	{Step: migration.Step{Name: "Create the admin_quarantines table", Table: "admin_quarantines", DDL: createAdminQuarantinesSchema, Lock: lockNewTable},
+		applied: tableExists("admin_quarantines")},
+	{Step: migration.Step{Name: "Create the families_quarantine table", Table: "families_quarantine", DDL: createQuarantineSchema, Lock: lockNewTable},
+		applied: tableExists("families_quarantine")},
+	{Step: migration.Step{Name: "Create the event_outbox table", Table: "event_outbox", DDL: createOutboxSchema, Lock: lockNewTable},
+		applied: tableExists("event_outbox")},
+	{Step: migration.Step{Name: "Create the event_outbox_dead_letters table", Table: "event_outbox_dead_letters", DDL: createOutboxDeadLettersSchema, Lock: lockNewTable},
+		applied: tableExists("event_outbox_dead_letters")},
+}
+
+// Ensure SQLiteFamilyRepository implements migration.Planner
+var _ migration.Planner = (*SQLiteFamilyRepository)(nil)
+
+// PlanSchema inspects the database and returns the plan of its schema without changing it
+func (r *SQLiteFamilyRepository) PlanSchema(ctx context.Context) (*migration.Plan, error) {
+	plan := &migration.Plan{Backend: "sqlite", Rows: make(map[string]int64)}
+	for _, step := range r.steps() {
+		var count int
+		if err := r.DB.QueryRowContext(ctx, step.applied).Scan(&count); err != nil {
+			return nil, repoerrors.NewRepositoryError(err, "failed to inspect the schema", repoerrors.SQLiteErrorCode, step.Table)
+		}
+		step.Step.Applied = count > 0
+		plan.Steps = append(plan.Steps, step.Step)
+	}
+
+	// Count the rows of the existing tables, which are locked by the pending steps
+	for _, step := range r.steps() {
+		if _, counted := plan.Rows[step.Table]; counted {
+			continue
+		}
+		var exists int
+		if err := r.DB.QueryRowContext(ctx, tableExists(step.Table)).Scan(&exists); err != nil {
+			return nil, repoerrors.NewRepositoryError(err, "failed to inspect the schema", repoerrors.SQLiteErrorCode, step.Table)
+		}
+		if exists == 0 {
+			continue
+		}
+		var rows int64
+		if err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+step.Table).Scan(&rows); err != nil {
+			return nil, repoerrors.NewRepositoryError(err, "failed to count rows", repoerrors.SQLiteErrorCode, step.Table)
+		}
+		plan.Rows[step.Table] = rows
+	}
+	return plan, nil
+}
+
+// ApplySchema applies the pending steps of the schema
+func (r *SQLiteFamilyRepository) ApplySchema(ctx context.Context) error {
+	if err := r.ensureTableExists(ctx); err != nil {
+		return err
+	}
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return err
+	}
+	if _, err := r.DB.ExecContext(ctx, createQuarantineSchema); err != nil {
+		return repoerrors.NewRepositoryError(err, "failed to create quarantine table", repoerrors.SQLiteErrorCode, "families_quarantine")
+	}
+	return nil
+}
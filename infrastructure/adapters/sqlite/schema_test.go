@@ -0,0 +1,107 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestPlanSchema plans the migration of a families table created before external IDs were
+// supported, applies it, and plans again
+func TestPlanSchema(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1) // Every connection to :memory: opens a new database
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `
+		CREATE TABLE families (id TEXT PRIMARY KEY, status TEXT NOT NULL, parents TEXT NOT NULL, children TEXT NOT NULL);
+		INSERT INTO families VALUES ('a1000000-0000-4000-8000-000000000000', 'SINGLE', '[]', '[]');
+	`)
+	require.NoError(t, err)
+	repo := NewSQLiteFamilyRepository(db, logging.NewContextLogger(zaptest.NewLogger(t)))
+
+	plan, err := repo.PlanSchema(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite", plan.Backend)
+	assert.Len(t, plan.Steps, len(schemaSteps))
+	var pending []string
+	for _, step := range plan.Pending() {
+		pending = append(pending, step.Name)
+	}
+	assert.Equal(t, []string{
+		"Add the external_ids column to the families table",
+		"Add the parent_count and children_count columns to the families table",
+		"Create index idx_families_parent_count",
+		"Create index idx_families_children_count",
+		"Add the deleted_at column to the families table",
+		"Create index idx_families_deleted_at",
+		"Add the version column to the families table",
+		"Add the owner_id column to the families table",
+		"Add the tenant_id column to the families table",
+		"Create index idx_families_tenant_id",
+		"Create index idx_families_owner_id",
+		"Create the family_external_ids table",
+		"Create index idx_family_external_ids_family_id",
+		"Create index idx_family_external_ids_lookup",
+		"Create the admin_quarantines table",
+		"Create the families_quarantine table",
+		"Create the event_outbox table",
+		"Create the event_outbox_dead_letters table",
+	}, pending)
+	assert.Equal(t, map[string]int64{"families": 1}, plan.Rows)
+
+	var out bytes.Buffer
+	require.NoError(t, plan.Write(&out))
+	assert.Contains(t, out.String(), "Pending migrations: 18 of 19")
+	assert.Contains(t, out.String(), "Table: families (about 1 rows)")
+	assert.Contains(t, out.String(), addExternalIDsColumn)
+
+	// Planning does not change the database
+	again, err := repo.PlanSchema(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, plan, again)
+
+	require.NoError(t, repo.ApplySchema(ctx))
+	plan, err = repo.PlanSchema(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Pending())
+	assert.Equal(t, int64(1), plan.Rows["families"])
+	assert.Equal(t, int64(0), plan.Rows["admin_quarantines"])
+
+	// Filters on counts still find the rows saved before the counts were stored
+	var parentCount sql.NullInt64
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT parent_count FROM families").Scan(&parentCount))
+	assert.False(t, parentCount.Valid)
+	where, args, ok := prefilter(query.Eq(query.FieldParentCount, 0))
+	require.True(t, ok)
+	var candidates int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM families WHERE "+where, args...).Scan(&candidates))
+	assert.Equal(t, 1, candidates)
+}
+
+// TestPlanSchema_EmptyDatabase plans the creation of the whole schema
+func TestPlanSchema_EmptyDatabase(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	repo := NewSQLiteFamilyRepository(db, logging.NewContextLogger(zaptest.NewLogger(t)))
+	plan, err := repo.PlanSchema(context.Background())
+	require.NoError(t, err)
+
+	// The external_ids, count, deleted_at, version, owner_id, and tenant_id columns are created with the families table
+	assert.Len(t, plan.Pending(), len(schemaSteps)-6)
+	assert.Empty(t, plan.Rows)
+}
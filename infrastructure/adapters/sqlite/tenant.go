@@ -0,0 +1,56 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+
+	servicecontext "github.com/abitofhelp/servicelib/context"
+)
+
+// Families belong to the tenant of the context they are saved with, whose ID the tenant_id
+// column of the families table holds. Every query of the repository is scoped to the
+// tenant of its context, so that a tenant never reads, changes, or counts the families of
+// another tenant; a context without a tenant is scoped to the families saved without one,
+// such as the families saved before families had tenants.
+const (
+	// addTenantIDColumn adds the tenant_id column to families tables created before
+	// families had tenants
+	addTenantIDColumn = "ALTER TABLE families ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''"
+
+	// tenantIDColumnExists counts the tenant_id columns of the families table
+	tenantIDColumnExists = "SELECT COUNT(*) FROM pragma_table_info('families') WHERE name = 'tenant_id'"
+
+	// The index leads with the tenant, so that the families of a tenant are read from a
+	// range of it, in ID order for the pages of the tenant
+	createTenantIDIndex = `
+	CREATE INDEX IF NOT EXISTS idx_families_tenant_id ON families (tenant_id, id);
+	`
+
+	// inTenant is the condition on the rows of families, or of family_documents, of the
+	// families of a tenant, whose ID is its argument. The view has no tenant_id column, so
+	// the families of the tenant are looked up with the index of the column.
+	inTenant = "id IN (SELECT id FROM families WHERE tenant_id = ?)"
+)
+
+// ensureTenantIDColumn adds the tenant_id column to families tables created before families
+// had tenants and creates its index
+func (r *SQLiteFamilyRepository) ensureTenantIDColumn(ctx context.Context) error {
+	var count int
+	if err := r.DB.QueryRowContext(ctx, tenantIDColumnExists).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		if _, err := r.DB.ExecContext(ctx, addTenantIDColumn); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.DB.ExecContext(ctx, createTenantIDIndex)
+	return err
+}
+
+// tenantOf returns the ID of the tenant of a context, or "" if it has none
+func tenantOf(ctx context.Context) string {
+	return servicecontext.GetTenantID(ctx)
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	repoerrors "github.com/abitofhelp/family-service/infrastructure/adapters/errors"
+	"go.uber.org/zap"
+)
+
+// A unit of work is a transaction of the repository that its context carries. The
+// operations that change families, their quarantines, or the outbox join the unit of work
+// of their context: those of several statements run in a savepoint of its transaction, so
+// that a failed operation leaves the unit as it was, and their changes are applied when the
+// unit is committed. The other operations read the committed families.
+
+// Ensure SQLiteFamilyRepository implements ports.UnitOfWork
+var _ ports.UnitOfWork = (*SQLiteFamilyRepository)(nil)
+
+// unitOfWorkKey is the context key of the unit of work of a context
+type unitOfWorkKey struct{}
+
+// unitOfWork is a unit of work of a repository
+type unitOfWork struct {
+	repo       *SQLiteFamilyRepository
+	tx         *sql.Tx
+	savepoints int // Number of savepoints created in the transaction, which names the next
+}
+
+// unitOfWork returns the unit of work of the repository that the context carries, or nil
+func (r *SQLiteFamilyRepository) unitOfWork(ctx context.Context) *unitOfWork {
+	unit, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	if !ok || unit.repo != r {
+		return nil
+	}
+	return unit
+}
+
+// Begin begins a unit of work and returns the context of its operations
+func (r *SQLiteFamilyRepository) Begin(ctx context.Context) (context.Context, error) {
+	if r.unitOfWork(ctx) != nil {
+		return nil, repoerrors.NewRepositoryError(nil, "a unit of work is already in progress", repoerrors.SQLiteErrorCode, "families")
+	}
+
+	// The schema is ensured before the unit of work, whose operations do not change it
+	if err := r.ensureTableExists(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.ensureAdminQuarantinesSchema(ctx); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin unit of work", zap.Error(err))
+		return nil, repoerrors.NewRepositoryError(err, "failed to begin unit of work", repoerrors.SQLiteErrorCode, "families")
+	}
+	return context.WithValue(ctx, unitOfWorkKey{}, &unitOfWork{repo: r, tx: tx}), nil
+}
+
+// Commit applies the changes of the unit of work of the context
+func (r *SQLiteFamilyRepository) Commit(ctx context.Context) error {
+	unit := r.unitOfWork(ctx)
+	if unit == nil {
+		return repoerrors.NewRepositoryError(nil, "no unit of work is in progress", repoerrors.SQLiteErrorCode, "families")
+	}
+	if err := unit.tx.Commit(); err != nil {
+		r.logger.Error(ctx, "Failed to commit unit of work", zap.Error(err))
+		return repoerrors.NewRepositoryError(err, "failed to commit unit of work", repoerrors.SQLiteErrorCode, "families")
+	}
+	return nil
+}
+
+// Rollback discards the changes of the unit of work of the context
+func (r *SQLiteFamilyRepository) Rollback(ctx context.Context) error {
+	unit := r.unitOfWork(ctx)
+	if unit == nil {
+		return nil
+	}
+	if err := unit.tx.Rollback(); err != nil && !stderrors.Is(err, sql.ErrTxDone) {
+		return repoerrors.NewRepositoryError(err, "failed to roll back unit of work", repoerrors.SQLiteErrorCode, "families")
+	}
+	return nil
+}
+
+// execer returns the executor of a statement that changes families: the transaction of the
+// unit of work of the context, or the database
+func (r *SQLiteFamilyRepository) execer(ctx context.Context) interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+} {
+	if unit := r.unitOfWork(ctx); unit != nil {
+		return unit.tx
+	}
+	return r.DB
+}
+
+// transaction is the transaction of an operation that changes families: a transaction of
+// its own, or a savepoint in the transaction of the unit of work of its context
+type transaction struct {
+	*sql.Tx
+	ctx       context.Context
+	savepoint string // Name of the savepoint; empty for a transaction of its own
+	done      bool
+}
+
+// begin begins the transaction of an operation that changes families
+func (r *SQLiteFamilyRepository) begin(ctx context.Context) (*transaction, error) {
+	unit := r.unitOfWork(ctx)
+	if unit == nil {
+		tx, err := r.DB.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &transaction{Tx: tx, ctx: ctx}, nil
+	}
+
+	unit.savepoints++
+	savepoint := fmt.Sprintf("operation_%d", unit.savepoints)
+	if _, err := unit.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return nil, err
+	}
+	return &transaction{Tx: unit.tx, ctx: ctx, savepoint: savepoint}, nil
+}
+
+// Commit commits the transaction, or releases its savepoint into the unit of work
+func (t *transaction) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if t.savepoint == "" {
+		return t.Tx.Commit()
+	}
+	_, err := t.Tx.ExecContext(t.ctx, "RELEASE SAVEPOINT "+t.savepoint)
+	return err
+}
+
+// Rollback rolls the transaction back, or rolls the unit of work back to its savepoint
+func (t *transaction) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if t.savepoint == "" {
+		return t.Tx.Rollback()
+	}
+	_, err := t.Tx.ExecContext(context.WithoutCancel(t.ctx), "ROLLBACK TO SAVEPOINT "+t.savepoint+"; RELEASE SAVEPOINT "+t.savepoint)
+	return err
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestUnitOfWork applies the saves of a unit of work when it is committed, and none of them
+// when it is rolled back
+func TestUnitOfWork(t *testing.T) {
+	// A database file, whose connections share the families that the units commit
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "families.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewSQLiteFamilyRepository(db, logging.NewContextLogger(zaptest.NewLogger(t)))
+
+	ctx := context.Background()
+	families := querytest.Families(t)
+	require.GreaterOrEqual(t, len(families), 4)
+
+	t.Run("rollback discards the saves", func(t *testing.T) {
+		unitCtx, err := repo.Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, repo.Save(unitCtx, families[0]))
+		require.NoError(t, repo.Save(unitCtx, families[1]))
+		require.NoError(t, repo.Rollback(unitCtx))
+
+		for _, fam := range families[:2] {
+			retrieved, err := repo.GetByID(ctx, fam.ID())
+			require.Error(t, err)
+			assert.Nil(t, retrieved)
+		}
+	})
+
+	t.Run("commit applies the saves", func(t *testing.T) {
+		unitCtx, err := repo.Begin(ctx)
+		require.NoError(t, err)
+
+		// A unit of work does not begin another
+		_, err = repo.Begin(unitCtx)
+		require.Error(t, err)
+
+		// A save that fails leaves the other saves of the unit
+		require.NoError(t, repo.Save(unitCtx, families[2]))
+		stale := *families[2]
+		stale.SetVersion(families[2].Version() + 5)
+		err = repo.Save(unitCtx, &stale)
+		assert.True(t, errors.Is(err, domainerrors.ErrConcurrencyConflict))
+		require.NoError(t, repo.Save(unitCtx, families[3]))
+		require.NoError(t, repo.Commit(unitCtx))
+
+		for _, fam := range families[2:4] {
+			got, err := repo.GetByID(ctx, fam.ID())
+			require.NoError(t, err)
+			assert.Equal(t, 1, got.Version())
+		}
+	})
+
+	t.Run("quarantines join the unit of work", func(t *testing.T) {
+		q, err := entity.NewQuarantine(families[0].ID(), "fraud investigation 42", "admin-1", time.Now())
+		require.NoError(t, err)
+
+		unitCtx, err := repo.Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, repo.SaveFamilyQuarantine(unitCtx, q))
+		require.NoError(t, repo.Rollback(unitCtx))
+		got, err := repo.GetFamilyQuarantine(ctx, q.FamilyID)
+		require.NoError(t, err)
+		assert.Nil(t, got, "a rolled back quarantine is not saved")
+
+		unitCtx, err = repo.Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, repo.SaveFamilyQuarantine(unitCtx, q))
+		require.NoError(t, repo.Commit(unitCtx))
+		got, err = repo.GetFamilyQuarantine(ctx, q.FamilyID)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+
+		unitCtx, err = repo.Begin(ctx)
+		require.NoError(t, err)
+		deleted, err := repo.DeleteFamilyQuarantine(unitCtx, q.FamilyID)
+		require.NoError(t, err)
+		assert.True(t, deleted)
+		require.NoError(t, repo.Commit(unitCtx))
+		got, err = repo.GetFamilyQuarantine(ctx, q.FamilyID)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("commit without a unit of work", func(t *testing.T) {
+		require.Error(t, repo.Commit(ctx))
+		require.NoError(t, repo.Rollback(ctx))
+	})
+}
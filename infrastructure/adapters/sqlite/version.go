@@ -0,0 +1,57 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+)
+
+// The version column of the families table counts the saves of a family. Save writes the
+// next version only over the version of the family it was given, and rejects the save with
+// a ConcurrencyError otherwise, so that concurrent changes of a family cannot overwrite
+// each other. A family with version 0 is inserted, and rejected if its ID is taken, unless
+// the save is marked with ports.WithOverwrite. Rows saved before the column was added have
+// version 1.
+const (
+	// addVersionColumn adds the version column to families tables created before families
+	// were versioned
+	addVersionColumn = "ALTER TABLE families ADD COLUMN version INTEGER NOT NULL DEFAULT 1"
+
+	// versionColumnExists counts the version columns of the families table
+	versionColumnExists = "SELECT COUNT(*) FROM pragma_table_info('families') WHERE name = 'version'"
+
+	// versionOfFamily is the version of the family with the ID of its parameter, which reads
+	// the families table in both layouts
+	versionOfFamily = "(SELECT version FROM families WHERE id = ?)"
+)
+
+// ensureVersionColumn adds the version column to families tables created before families
+// were versioned
+func (r *SQLiteFamilyRepository) ensureVersionColumn(ctx context.Context) error {
+	var count int
+	if err := r.DB.QueryRowContext(ctx, versionColumnExists).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := r.DB.ExecContext(ctx, addVersionColumn)
+	return err
+}
+
+// staleVersionError returns the error of a save of a family that was changed by another
+// operation after it was read
+func staleVersionError(fam *entity.Family) error {
+	return domainerrors.NewConcurrencyError(fmt.Sprintf(
+		"family %s was changed by another operation after version %d was read", fam.ID(), fam.Version()), nil)
+}
+
+// existingFamilyError returns the error of a save of a new family whose ID is taken by a
+// stored family
+func existingFamilyError(fam *entity.Family) error {
+	return domainerrors.NewConcurrencyError(fmt.Sprintf("family %s already exists", fam.ID()), nil)
+}
@@ -0,0 +1,486 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package staleread provides a family repository that serves reads from snapshots while
+// the circuit breaker of the database is open.
+//
+// The repository keeps a snapshot of each family it reads or saves, in memory and bounded
+// in number, evicting the least recently used. When a read of a single family is rejected
+// because the circuit is open, the family's snapshot is served instead, provided it is not
+// older than the maximum staleness, and the read is recorded in the request's Tracker so
+// that the response can tell the client that it is stale. Only requests that carry a
+// Tracker are served from snapshots: the GraphQL API adds one to queries, so mutations,
+// which must not act on stale data, fail fast as before. Reads of lists of families are
+// never served from snapshots, since the snapshots cannot tell whether a list is complete.
+//
+// Snapshots are kept per tenant, so that the snapshots of one tenant are never served to
+// another.
+package staleread
+
+import (
+	"container/list"
+	"context"
+	stderrors "errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/metrics"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	circuit "github.com/abitofhelp/family-service/infrastructure/adapters/circuitwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/abitofhelp/servicelib/errors"
+	"github.com/abitofhelp/servicelib/logging"
+	"go.uber.org/zap"
+)
+
+// Results of a read that fell back to snapshots, as counted in the stale reads metric
+const (
+	ResultServed  = "served"
+	ResultMissing = "missing"
+	ResultExpired = "expired"
+)
+
+// snapshot is the last known good state of a family
+type snapshot struct {
+	key    string
+	tenant string
+	family entity.FamilyDTO
+	at     time.Time
+}
+
+// Repository is a FamilyRepository that serves reads of single families from snapshots
+// while the circuit breaker of the repository it wraps is open
+type Repository struct {
+	primary ports.FamilyRepository
+	cfg     config.StaleReadsConfig
+	logger  *logging.ContextLogger
+	now     func() time.Time
+
+	mu        sync.Mutex
+	snapshots map[string]*list.Element // Elements of order by key
+	order     *list.List               // Snapshots, most recently used first
+}
+
+// Ensure Repository implements the FamilyRepository port
+var _ ports.FamilyRepository = (*Repository)(nil)
+
+// NewRepository creates a new repository that serves stale reads.
+//
+// Parameters:
+//   - primary: The repository that serves all operations while its circuit is closed
+//   - cfg: Maximum staleness and number of the snapshots
+//   - logger: Logger for the reads served from snapshots
+func NewRepository(primary ports.FamilyRepository, cfg config.StaleReadsConfig, logger *logging.ContextLogger) *Repository {
+	if cfg.MaxEntries < 1 {
+		cfg.MaxEntries = 1
+	}
+	return &Repository{
+		primary:   primary,
+		cfg:       cfg,
+		logger:    logger,
+		now:       time.Now,
+		snapshots: make(map[string]*list.Element),
+		order:     list.New(),
+	}
+}
+
+// GetByID retrieves a family, or its snapshot while the circuit is open
+func (r *Repository) GetByID(ctx context.Context, id string) (*entity.Family, error) {
+	fam, err := r.primary.GetByID(ctx, id)
+	switch {
+	case err == nil:
+		r.record(ctx, fam)
+	case isNotFound(err):
+		r.forget(ctx, id)
+	default:
+		return r.fallback(ctx, "get_by_id", err, func(s *snapshot) bool { return s.family.ID == id })
+	}
+	return fam, err
+}
+
+// GetAll retrieves all families. The families are not recorded, so that reading every
+// family does not evict the snapshots of the families that clients read one by one.
+func (r *Repository) GetAll(ctx context.Context) ([]*entity.Family, error) {
+	return r.primary.GetAll(ctx)
+}
+
+// ExportAll exports all families without recording them. It falls back to GetAll if the
+// primary repository does not implement ports.FamilyExporter.
+func (r *Repository) ExportAll(ctx context.Context, fn func(*entity.Family) error) error {
+	if exporter, ok := r.primary.(ports.FamilyExporter); ok {
+		return exporter.ExportAll(ctx, fn)
+	}
+
+	families, err := r.primary.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, fam := range families {
+		if err := fn(fam); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LockFamily locks a family in the primary repository. It does not lock anything if the
+// primary repository does not implement ports.FamilyLocker.
+func (r *Repository) LockFamily(ctx context.Context, familyID string) (func(), error) {
+	if locker, ok := r.primary.(ports.FamilyLocker); ok {
+		return locker.LockFamily(ctx, familyID)
+	}
+	return func() {}, nil
+}
+
+// Ensure Repository implements ports.UnitOfWork
+var _ ports.UnitOfWork = (*Repository)(nil)
+
+// Begin begins a unit of work of the primary repository. It fails with
+// ports.ErrNoUnitOfWork if the primary repository does not implement ports.UnitOfWork.
+// Saves in the unit of work are recorded when they succeed; if the unit of work is rolled
+// back, their snapshots are replaced by the next read or save of their families.
+func (r *Repository) Begin(ctx context.Context) (context.Context, error) {
+	if unit, ok := r.primary.(ports.UnitOfWork); ok {
+		return unit.Begin(ctx)
+	}
+	return nil, ports.ErrNoUnitOfWork
+}
+
+// Commit applies the changes of the unit of work of the primary repository
+func (r *Repository) Commit(ctx context.Context) error {
+	if unit, ok := r.primary.(ports.UnitOfWork); ok {
+		return unit.Commit(ctx)
+	}
+	return ports.ErrNoUnitOfWork
+}
+
+// Rollback discards the changes of the unit of work of the primary repository
+func (r *Repository) Rollback(ctx context.Context) error {
+	if unit, ok := r.primary.(ports.UnitOfWork); ok {
+		return unit.Rollback(ctx)
+	}
+	return nil
+}
+
+// Ensure Repository implements ports.FamilyDeleter
+var _ ports.FamilyDeleter = (*Repository)(nil)
+
+// SoftDeleteFamily marks a family as deleted in the primary repository and forgets its
+// snapshot, so that it is not served once deleted. It fails if the primary repository does
+// not implement ports.FamilyDeleter.
+func (r *Repository) SoftDeleteFamily(ctx context.Context, familyID string, deletedAt time.Time) (bool, error) {
+	deleter, ok := r.primary.(ports.FamilyDeleter)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository cannot delete families", "delete", "families", nil)
+	}
+	deleted, err := deleter.SoftDeleteFamily(ctx, familyID, deletedAt)
+	if err == nil {
+		r.forget(ctx, familyID)
+	}
+	return deleted, err
+}
+
+// HardDeleteFamily removes a family from the primary repository and forgets its snapshot.
+// It fails if the primary repository does not implement ports.FamilyDeleter.
+func (r *Repository) HardDeleteFamily(ctx context.Context, familyID string) (bool, error) {
+	deleter, ok := r.primary.(ports.FamilyDeleter)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository cannot delete families", "delete", "families", nil)
+	}
+	deleted, err := deleter.HardDeleteFamily(ctx, familyID)
+	if err == nil {
+		r.forget(ctx, familyID)
+	}
+	return deleted, err
+}
+
+// Ensure Repository implements ports.FamilyRestorer
+var _ ports.FamilyRestorer = (*Repository)(nil)
+
+// RestoreFamily restores a deleted family in the primary repository. It fails if the
+// primary repository does not implement ports.FamilyRestorer.
+func (r *Repository) RestoreFamily(ctx context.Context, familyID string) (bool, error) {
+	restorer, ok := r.primary.(ports.FamilyRestorer)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository cannot restore families", "update", "families", nil)
+	}
+	return restorer.RestoreFamily(ctx, familyID)
+}
+
+// PurgeDeletedFamilies purges the deleted families of the primary repository and forgets
+// their snapshots. It fails if the primary repository does not implement
+// ports.FamilyRestorer.
+func (r *Repository) PurgeDeletedFamilies(ctx context.Context, deletedBefore time.Time, allTenants bool) ([]string, error) {
+	restorer, ok := r.primary.(ports.FamilyRestorer)
+	if !ok {
+		return nil, errors.NewDatabaseError("the repository cannot purge families", "delete", "families", nil)
+	}
+	ids, err := restorer.PurgeDeletedFamilies(ctx, deletedBefore, allTenants)
+	for _, id := range ids {
+		r.forget(ctx, id)
+	}
+	return ids, err
+}
+
+// Save persists a family and records it once it is saved. Saves are never served from
+// snapshots, so they fail fast while the circuit is open.
+func (r *Repository) Save(ctx context.Context, fam *entity.Family) error {
+	if err := r.primary.Save(ctx, fam); err != nil {
+		return err
+	}
+	r.record(ctx, fam)
+	return nil
+}
+
+// FindByParentID finds families by parent and records them
+func (r *Repository) FindByParentID(ctx context.Context, parentID string) ([]*entity.Family, error) {
+	families, err := r.primary.FindByParentID(ctx, parentID)
+	if err == nil {
+		r.record(ctx, families...)
+	}
+	return families, err
+}
+
+// FindByChildID finds a family by child, or the snapshot of the family that had the
+// child while the circuit is open
+func (r *Repository) FindByChildID(ctx context.Context, childID string) (*entity.Family, error) {
+	fam, err := r.primary.FindByChildID(ctx, childID)
+	if err != nil && !isNotFound(err) {
+		return r.fallback(ctx, "find_by_child_id", err, func(s *snapshot) bool {
+			for _, child := range s.family.Children {
+				if child.ID == childID {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	if fam != nil {
+		r.record(ctx, fam)
+	}
+	return fam, err
+}
+
+// FindByExternalID finds families by external ID and records them
+func (r *Repository) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	families, err := r.primary.FindByExternalID(ctx, system, externalID)
+	if err == nil {
+		r.record(ctx, families...)
+	}
+	return families, err
+}
+
+// Find finds the families that match a filter and records them
+func (r *Repository) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	families, err := r.primary.Find(ctx, filter)
+	if err == nil {
+		r.record(ctx, families...)
+	}
+	return families, err
+}
+
+// CountFamilies counts the families in the primary repository. Counts cannot be served
+// from snapshots, so they fail while the primary repository is unavailable.
+func (r *Repository) CountFamilies(ctx context.Context) (int, error) {
+	return r.primary.CountFamilies(ctx)
+}
+
+// CountParents counts the distinct parents in the primary repository
+func (r *Repository) CountParents(ctx context.Context) (int, error) {
+	return r.primary.CountParents(ctx)
+}
+
+// CountChildren counts the distinct children in the primary repository
+func (r *Repository) CountChildren(ctx context.Context) (int, error) {
+	return r.primary.CountChildren(ctx)
+}
+
+// ListFamilies reads a page of families from the primary repository and records them. It
+// falls back to GetAll if the primary repository does not implement ports.FamilyPager.
+func (r *Repository) ListFamilies(ctx context.Context, after string, limit int) ([]*entity.Family, error) {
+	var families []*entity.Family
+	var err error
+	if pager, ok := r.primary.(ports.FamilyPager); ok {
+		families, err = pager.ListFamilies(ctx, after, limit)
+	} else if families, err = r.primary.GetAll(ctx); err == nil {
+		families = query.Page(families, after, limit)
+	}
+	if err == nil {
+		r.record(ctx, families...)
+	}
+	return families, err
+}
+
+// Ensure Repository implements ports.FamilyOwnerReader
+var _ ports.FamilyOwnerReader = (*Repository)(nil)
+
+// CountByOwner counts the families of an owner in the primary repository. It falls back to
+// Find if the primary repository does not implement ports.FamilyOwnerReader.
+func (r *Repository) CountByOwner(ctx context.Context, ownerID string, excluded []string) (ports.FamilyCounts, error) {
+	return ports.CountFamiliesByOwner(ctx, r.primary, ownerID, excluded)
+}
+
+// ListOwnedFamilies reads a page of the families of an owner from the primary repository and
+// records them. It falls back to Find if the primary repository does not implement
+// ports.FamilyOwnerReader.
+func (r *Repository) ListOwnedFamilies(ctx context.Context, ownerID, after string, limit int) ([]*entity.Family, error) {
+	families, err := ports.ListFamiliesByOwner(ctx, r.primary, ownerID, after, limit)
+	if err == nil {
+		r.record(ctx, families...)
+	}
+	return families, err
+}
+
+// Ensure Repository implements ports.FamilyFilterPager
+var _ ports.FamilyFilterPager = (*Repository)(nil)
+
+// FindPage finds a page of the families that match a filter in the primary repository and
+// records them. It falls back to Find if the primary repository does not implement
+// ports.FamilyFilterPager.
+func (r *Repository) FindPage(ctx context.Context, filter query.Filter, after string, limit int) ([]*entity.Family, error) {
+	families, err := ports.FindFamiliesPage(ctx, r.primary, filter, after, limit)
+	if err == nil {
+		r.record(ctx, families...)
+	}
+	return families, err
+}
+
+// Ensure Repository implements ports.FamilyBatchReader
+var _ ports.FamilyBatchReader = (*Repository)(nil)
+
+// GetByIDs retrieves families by ID and records them. If the read fails, each family is
+// read by GetByID, which serves its snapshot while the circuit breaker is open. It falls
+// back to GetByID if the primary repository does not implement ports.FamilyBatchReader.
+func (r *Repository) GetByIDs(ctx context.Context, ids []string) ([]*entity.Family, error) {
+	families, err := ports.GetFamiliesByID(ctx, r.primary, ids)
+	if err == nil {
+		r.record(ctx, families...)
+		found := make(map[string]bool, len(families))
+		for _, fam := range families {
+			found[fam.ID()] = true
+		}
+		for _, id := range ids {
+			if !found[id] {
+				r.forget(ctx, id)
+			}
+		}
+		return families, nil
+	}
+
+	families = make([]*entity.Family, 0, len(ids))
+	for _, id := range ids {
+		fam, err := r.GetByID(ctx, id)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		families = append(families, fam)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].ID() < families[j].ID() })
+	return families, nil
+}
+
+// fallback serves the snapshot that matches a read which failed with err, if the read was
+// rejected by an open circuit, the request accepts stale reads, and the snapshot is fresh
+// enough. Otherwise it returns err.
+func (r *Repository) fallback(ctx context.Context, operation string, err error, match func(s *snapshot) bool) (*entity.Family, error) {
+	tracker := trackerFromContext(ctx)
+	if tracker == nil || !circuit.IsOpenError(err) {
+		return nil, err
+	}
+
+	tenant := servicecontext.GetTenantID(ctx)
+	r.mu.Lock()
+	var found *snapshot
+	for e := r.order.Front(); e != nil; e = e.Next() {
+		s := e.Value.(*snapshot)
+		if s.tenant == tenant && match(s) {
+			found = s
+			r.order.MoveToFront(e)
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if found == nil {
+		metrics.RepositoryStaleReads.WithLabelValues(operation, ResultMissing).Inc()
+		return nil, err
+	}
+	age := r.now().Sub(found.at)
+	if age > r.cfg.MaxStaleness {
+		metrics.RepositoryStaleReads.WithLabelValues(operation, ResultExpired).Inc()
+		return nil, err
+	}
+
+	fam, convErr := entity.FamilyFromDTO(found.family)
+	if convErr != nil {
+		r.logger.Warn(ctx, "Failed to restore family snapshot",
+			zap.String("family_id", found.family.ID), zap.Error(convErr))
+		return nil, err
+	}
+	metrics.RepositoryStaleReads.WithLabelValues(operation, ResultServed).Inc()
+	tracker.served(found.at)
+	r.logger.Info(ctx, "Serving stale family snapshot while the circuit breaker is open",
+		zap.String("operation", operation),
+		zap.String("family_id", found.family.ID),
+		zap.Duration("age", age))
+	return fam, nil
+}
+
+// record keeps snapshots of families read from or saved to the primary repository,
+// evicting the least recently used snapshots beyond the maximum number
+func (r *Repository) record(ctx context.Context, families ...*entity.Family) {
+	if len(families) == 0 {
+		return
+	}
+
+	tenant := servicecontext.GetTenantID(ctx)
+	now := r.now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, fam := range families {
+		if fam == nil {
+			continue
+		}
+		// The DTO is a copy, so later changes of the family by the caller do not change it
+		s := &snapshot{key: snapshotKey(tenant, fam.ID()), tenant: tenant, family: fam.ToDTO(), at: now}
+		if e, ok := r.snapshots[s.key]; ok {
+			e.Value = s
+			r.order.MoveToFront(e)
+			continue
+		}
+		r.snapshots[s.key] = r.order.PushFront(s)
+	}
+	for r.order.Len() > r.cfg.MaxEntries {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.snapshots, oldest.Value.(*snapshot).key)
+	}
+}
+
+// forget removes the snapshot of a family that no longer exists
+func (r *Repository) forget(ctx context.Context, id string) {
+	key := snapshotKey(servicecontext.GetTenantID(ctx), id)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.snapshots[key]; ok {
+		r.order.Remove(e)
+		delete(r.snapshots, key)
+	}
+}
+
+// snapshotKey returns the key of the snapshot of a family of a tenant
+func snapshotKey(tenant, id string) string {
+	return tenant + "\x00" + id
+}
+
+// isNotFound reports whether an error reports a missing family
+func isNotFound(err error) bool {
+	var notFound *errors.NotFoundError
+	return stderrors.As(err, &notFound)
+}
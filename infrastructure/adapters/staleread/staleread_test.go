@@ -0,0 +1,284 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package staleread
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/metrics"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/abitofhelp/servicelib/errors"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// IDs of the test families and members
+const (
+	family1 = "0d8f4f5e-1c1a-4b9e-9a51-6b2d7c1e0a01"
+	family2 = "0d8f4f5e-1c1a-4b9e-9a51-6b2d7c1e0a02"
+	parent1 = "5b7e2c3d-8a4f-4e6b-b1c9-2f3a4d5e6f01"
+	parent2 = "5b7e2c3d-8a4f-4e6b-b1c9-2f3a4d5e6f02"
+	child1  = "7c9d1e2f-3a4b-4c5d-8e6f-9a0b1c2d3e01"
+)
+
+// errCircuitOpen is the error of a repository whose circuit breaker is open
+var errCircuitOpen = stderrors.New("database error: circuit breaker is open")
+
+// fakeRepository is an in-memory FamilyRepository whose reads and saves fail with err
+type fakeRepository struct {
+	families map[string]*entity.Family
+	err      error
+}
+
+func newFakeRepository(families ...*entity.Family) *fakeRepository {
+	r := &fakeRepository{families: make(map[string]*entity.Family)}
+	for _, fam := range families {
+		r.families[fam.ID()] = fam
+	}
+	return r
+}
+
+func (r *fakeRepository) GetByID(ctx context.Context, id string) (*entity.Family, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	fam, ok := r.families[id]
+	if !ok {
+		return nil, errors.NewNotFoundError("Family", id, nil)
+	}
+	return fam, nil
+}
+
+func (r *fakeRepository) GetAll(ctx context.Context) ([]*entity.Family, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	families := make([]*entity.Family, 0, len(r.families))
+	for _, fam := range r.families {
+		families = append(families, fam)
+	}
+	return families, nil
+}
+
+func (r *fakeRepository) Save(ctx context.Context, fam *entity.Family) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.families[fam.ID()] = fam
+	return nil
+}
+
+func (r *fakeRepository) FindByParentID(ctx context.Context, parentID string) ([]*entity.Family, error) {
+	return r.GetAll(ctx)
+}
+
+func (r *fakeRepository) FindByChildID(ctx context.Context, childID string) (*entity.Family, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, fam := range r.families {
+		for _, child := range fam.Children() {
+			if child.ID() == childID {
+				return fam, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRepository) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	return r.GetAll(ctx)
+}
+
+func (r *fakeRepository) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	return r.GetAll(ctx)
+}
+
+func (r *fakeRepository) CountFamilies(ctx context.Context) (int, error) {
+	families, err := r.GetAll(ctx)
+	return len(families), err
+}
+
+func (r *fakeRepository) CountParents(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeRepository) CountChildren(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// newTestFamily creates a single-parent family with the given children
+func newTestFamily(t *testing.T, id, parentID, firstName string, childIDs ...string) *entity.Family {
+	t.Helper()
+
+	birthDate := time.Date(1980, time.January, 2, 0, 0, 0, 0, time.UTC)
+	parent, err := entity.NewParent(parentID, firstName, "Stale", birthDate, nil)
+	require.NoError(t, err)
+	var children []*entity.Child
+	for _, childID := range childIDs {
+		child, err := entity.NewChild(childID, "Kid", "Stale", birthDate.AddDate(25, 0, 0), nil)
+		require.NoError(t, err)
+		children = append(children, child)
+	}
+	fam, err := entity.NewFamily(id, entity.Single, []*entity.Parent{parent}, children)
+	require.NoError(t, err)
+	return fam
+}
+
+// newTestRepository creates a repository with a clock the test controls
+func newTestRepository(primary *fakeRepository, maxEntries int) (*Repository, *time.Time) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	repo := NewRepository(primary, config.StaleReadsConfig{
+		Enabled:      true,
+		MaxStaleness: time.Minute,
+		MaxEntries:   maxEntries,
+	}, logging.NewContextLogger(zap.NewNop()))
+	repo.now = func() time.Time { return now }
+	return repo, &now
+}
+
+// staleReads returns the number of stale reads with the given operation and result
+func staleReads(operation, result string) float64 {
+	return testutil.ToFloat64(metrics.RepositoryStaleReads.WithLabelValues(operation, result))
+}
+
+func TestRepository_ServesSnapshotWhileCircuitIsOpen(t *testing.T) {
+	metrics.ResetMetrics()
+	primary := newFakeRepository(newTestFamily(t, family1, parent1, "Ada", child1))
+	repo, now := newTestRepository(primary, 10)
+
+	_, err := repo.GetByID(context.Background(), family1)
+	require.NoError(t, err)
+
+	primary.err = errCircuitOpen
+	*now = now.Add(30 * time.Second)
+	ctx, tracker := WithTracker(context.Background())
+
+	fam, err := repo.GetByID(ctx, family1)
+	require.NoError(t, err)
+	assert.Equal(t, family1, fam.ID())
+	fam, err = repo.FindByChildID(ctx, child1)
+	require.NoError(t, err)
+	assert.Equal(t, family1, fam.ID())
+
+	reads, oldest := tracker.Stale()
+	assert.Equal(t, 2, reads)
+	assert.Equal(t, now.Add(-30*time.Second), oldest)
+	assert.Equal(t, 1.0, staleReads("get_by_id", ResultServed))
+	assert.Equal(t, 1.0, staleReads("find_by_child_id", ResultServed))
+}
+
+func TestRepository_FailsWithoutFreshSnapshot(t *testing.T) {
+	metrics.ResetMetrics()
+	primary := newFakeRepository(newTestFamily(t, family1, parent1, "Ada"))
+	repo, now := newTestRepository(primary, 10)
+	_, err := repo.GetByID(context.Background(), family1)
+	require.NoError(t, err)
+	primary.err = errCircuitOpen
+
+	// Requests without a tracker, such as mutations, fail fast
+	_, err = repo.GetByID(context.Background(), family1)
+	assert.Equal(t, errCircuitOpen, err)
+
+	ctx, tracker := WithTracker(context.Background())
+	_, err = repo.GetByID(ctx, family2)
+	assert.Equal(t, errCircuitOpen, err)
+	assert.Equal(t, 1.0, staleReads("get_by_id", ResultMissing))
+
+	*now = now.Add(2 * time.Minute)
+	_, err = repo.GetByID(ctx, family1)
+	assert.Equal(t, errCircuitOpen, err)
+	assert.Equal(t, 1.0, staleReads("get_by_id", ResultExpired))
+
+	// Other failures are not served from snapshots
+	primary.err = stderrors.New("invalid family ID")
+	*now = now.Add(-2 * time.Minute)
+	_, err = repo.GetByID(ctx, family1)
+	assert.Equal(t, primary.err, err)
+
+	reads, _ := tracker.Stale()
+	assert.Zero(t, reads)
+}
+
+func TestRepository_WritesFailFast(t *testing.T) {
+	fam := newTestFamily(t, family1, parent1, "Ada")
+	primary := newFakeRepository()
+	repo, _ := newTestRepository(primary, 10)
+	require.NoError(t, repo.Save(context.Background(), fam))
+
+	primary.err = errCircuitOpen
+	ctx, _ := WithTracker(context.Background())
+	assert.Equal(t, errCircuitOpen, repo.Save(ctx, fam))
+
+	// The saved family is served while the circuit is open
+	_, err := repo.GetByID(ctx, family1)
+	assert.NoError(t, err)
+}
+
+func TestRepository_SnapshotsAreIsolated(t *testing.T) {
+	fam := newTestFamily(t, family1, parent1, "Ada")
+	primary := newFakeRepository(fam)
+	repo, _ := newTestRepository(primary, 10)
+
+	acme := servicecontext.WithTenantID(context.Background(), "acme")
+	_, err := repo.GetByID(acme, family1)
+	require.NoError(t, err)
+
+	// Changes of the family by the caller do not change its snapshot
+	parent, err := entity.NewParent(parent2, "Bea", "Stale", time.Date(1981, time.May, 6, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	require.NoError(t, fam.AddParent(parent))
+
+	primary.err = errCircuitOpen
+	ctx, _ := WithTracker(acme)
+	stale, err := repo.GetByID(ctx, family1)
+	require.NoError(t, err)
+	assert.Len(t, stale.Parents(), 1)
+
+	// The snapshots of one tenant are not served to another
+	ctx, _ = WithTracker(servicecontext.WithTenantID(context.Background(), "globex"))
+	_, err = repo.GetByID(ctx, family1)
+	assert.Equal(t, errCircuitOpen, err)
+}
+
+func TestRepository_EvictsLeastRecentlyUsed(t *testing.T) {
+	primary := newFakeRepository(newTestFamily(t, family1, parent1, "Ada"), newTestFamily(t, family2, parent2, "Bea"))
+	repo, _ := newTestRepository(primary, 1)
+
+	_, err := repo.GetByID(context.Background(), family1)
+	require.NoError(t, err)
+	_, err = repo.GetByID(context.Background(), family2)
+	require.NoError(t, err)
+
+	primary.err = errCircuitOpen
+	ctx, _ := WithTracker(context.Background())
+	_, err = repo.GetByID(ctx, family1)
+	assert.Equal(t, errCircuitOpen, err)
+	_, err = repo.GetByID(ctx, family2)
+	assert.NoError(t, err)
+}
+
+func TestRepository_ForgetsMissingFamilies(t *testing.T) {
+	primary := newFakeRepository(newTestFamily(t, family1, parent1, "Ada"))
+	repo, _ := newTestRepository(primary, 10)
+	_, err := repo.GetByID(context.Background(), family1)
+	require.NoError(t, err)
+
+	delete(primary.families, family1)
+	_, err = repo.GetByID(context.Background(), family1)
+	require.Error(t, err)
+
+	primary.err = errCircuitOpen
+	ctx, _ := WithTracker(context.Background())
+	_, err = repo.GetByID(ctx, family1)
+	assert.Equal(t, errCircuitOpen, err)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package staleread
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// trackerKey is the context key for the Tracker of a request
+type trackerKey struct{}
+
+// Tracker records the reads of a request that were served from snapshots. A request
+// accepts stale reads only if its context carries a Tracker.
+type Tracker struct {
+	mu     sync.Mutex
+	reads  int
+	oldest time.Time
+}
+
+// WithTracker returns a context whose reads may be served from snapshots, and the Tracker
+// that records them
+func WithTracker(ctx context.Context) (context.Context, *Tracker) {
+	tracker := &Tracker{}
+	return context.WithValue(ctx, trackerKey{}, tracker), tracker
+}
+
+// trackerFromContext returns the Tracker of a request, or nil if it does not accept stale reads
+func trackerFromContext(ctx context.Context) *Tracker {
+	tracker, _ := ctx.Value(trackerKey{}).(*Tracker)
+	return tracker
+}
+
+// served records a read served from a snapshot taken at the given time
+func (t *Tracker) served(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reads++
+	if t.oldest.IsZero() || at.Before(t.oldest) {
+		t.oldest = at
+	}
+}
+
+// Stale returns the number of reads served from snapshots and the time of the oldest
+// snapshot served. It returns zero reads if the request was not served stale data.
+func (t *Tracker) Stale() (reads int, oldest time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.reads, t.oldest
+}
@@ -0,0 +1,794 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package tenancy provides a family repository that routes tenants to dedicated databases.
+//
+// Some tenants require a database of their own. The routing repository reads the tenant
+// of each operation from its context and serves the operation from the datasource the
+// routing table names for that tenant; all other tenants, and operations without a
+// tenant, are served from the primary repository.
+//
+// Datasources are opened lazily, when one of their tenants first uses them, and an open
+// datasource is shared by all of its tenants. A datasource that has not been used for the
+// idle timeout is closed, and opened again by its next operation. Open datasources are
+// pinged periodically; their health is reported per tenant and as metrics. A datasource
+// that fails to open is not cached: the operation fails and the next one tries again.
+package tenancy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/abitofhelp/servicelib/errors"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Results of opening a datasource, as counted in the opens metric
+const (
+	ResultOpened = "opened"
+	ResultFailed = "failed"
+)
+
+var (
+	// opensTotal counts the attempts to open a datasource
+	opensTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_datasource_opens_total",
+			Help: "Total number of attempts to open a dedicated tenant database, by datasource and result",
+		},
+		[]string{"datasource", "result"},
+	)
+
+	// openGauge reports whether a datasource is open
+	openGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "tenant_datasource_open",
+			Help: "Whether a dedicated tenant database is open (1) or closed (0), by datasource",
+		},
+		[]string{"datasource"},
+	)
+
+	// healthyGauge reports the result of the last health check of a datasource
+	healthyGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "tenant_datasource_healthy",
+			Help: "Whether the last health check of an open dedicated tenant database succeeded (1) or failed (0), by datasource",
+		},
+		[]string{"datasource"},
+	)
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(opensTotal, openGauge, healthyGauge)
+}
+
+// Datasource is an open dedicated database
+type Datasource struct {
+	// Repository serves the families of the tenants of the datasource
+	Repository ports.FamilyRepository
+
+	// Ping checks that the database is reachable; nil if it cannot be checked
+	Ping func(ctx context.Context) error
+
+	// Close releases the connections of the database; nil if there is nothing to release
+	Close func(ctx context.Context) error
+}
+
+// Opener opens the dedicated database of a datasource
+type Opener func(ctx context.Context, name string, cfg config.DatasourceConfig) (*Datasource, error)
+
+// TenantHealth is the health of the dedicated database of a tenant
+type TenantHealth struct {
+	Tenant     string    `json:"tenant"`
+	Datasource string    `json:"datasource"`
+	Open       bool      `json:"open"`
+	Healthy    bool      `json:"healthy"`
+	CheckedAt  time.Time `json:"checkedAt,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// pool is the shared instance of a datasource. Its fields other than opened, ds, and err
+// are guarded by the mutex of the router; ds and err are set once, before opened is closed.
+type pool struct {
+	name   string
+	opened chan struct{}
+	ds     *Datasource
+	err    error
+
+	users     int
+	lastUsed  time.Time
+	healthy   bool
+	checkedAt time.Time
+	checkErr  string
+}
+
+// isOpen reports whether the datasource of the pool has been opened successfully
+func (p *pool) isOpen() bool {
+	select {
+	case <-p.opened:
+		return p.err == nil
+	default:
+		return false
+	}
+}
+
+// Router is a FamilyRepository that serves the operations of each tenant from the
+// datasource of the tenant, or from the primary repository
+type Router struct {
+	primary ports.FamilyRepository
+	cfg     config.TenancyConfig
+	open    Opener
+	logger  *logging.ContextLogger
+	now     func() time.Time
+
+	mu      sync.Mutex
+	pools   map[string]*pool
+	stopped bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Ensure Router implements the FamilyRepository port
+var _ ports.FamilyRepository = (*Router)(nil)
+
+// NewRouter creates a new routing repository.
+//
+// Parameters:
+//   - primary: The repository of the tenants without a dedicated database
+//   - cfg: The datasources, the routing table, and the lifecycle of the datasources
+//   - open: Opens the dedicated database of a datasource
+//   - logger: Logger for the lifecycle and health of the datasources
+//
+// Returns:
+//   - A new routing repository
+//   - An error if a tenant is routed to a datasource that is not configured
+func NewRouter(primary ports.FamilyRepository, cfg config.TenancyConfig, open Opener, logger *logging.ContextLogger) (*Router, error) {
+	for tenant, name := range cfg.Tenants {
+		if _, ok := cfg.Datasources[name]; !ok {
+			return nil, fmt.Errorf("tenant %s is routed to unknown datasource %q", tenant, name)
+		}
+	}
+	return &Router{
+		primary: primary,
+		cfg:     cfg,
+		open:    open,
+		logger:  logger,
+		now:     time.Now,
+		pools:   make(map[string]*pool),
+	}, nil
+}
+
+// Start starts the health checks and the closing of idle datasources in the background
+func (r *Router) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil || r.stopped {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.run(r.stop, r.done)
+}
+
+// Stop stops the background work and closes the open datasources. Operations that start
+// afterwards on a dedicated database fail.
+func (r *Router) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopped = true
+	stop, done := r.stop, r.done
+	r.stop = nil
+	pools := make([]*pool, 0, len(r.pools))
+	for name, p := range r.pools {
+		pools = append(pools, p)
+		delete(r.pools, name)
+	}
+	r.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var firstErr error
+	for _, p := range pools {
+		select {
+		case <-p.opened:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err := r.closePool(ctx, p); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Health returns the health of the dedicated database of each routed tenant, ordered by
+// tenant. The database of a tenant that has not been opened is reported as closed.
+func (r *Router) Health() []TenantHealth {
+	tenants := make([]string, 0, len(r.cfg.Tenants))
+	for tenant := range r.cfg.Tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	health := make([]TenantHealth, 0, len(tenants))
+	for _, tenant := range tenants {
+		name := r.cfg.Tenants[tenant]
+		h := TenantHealth{Tenant: tenant, Datasource: name}
+		if p, ok := r.pools[name]; ok && p.isOpen() {
+			h.Open = true
+			h.Healthy = p.healthy
+			h.CheckedAt = p.checkedAt
+			h.Error = p.checkErr
+		}
+		health = append(health, h)
+	}
+	return health
+}
+
+// GetByID retrieves a family from the repository of the tenant
+func (r *Router) GetByID(ctx context.Context, id string) (*entity.Family, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return repo.GetByID(ctx, id)
+}
+
+// GetAll retrieves all families from the repository of the tenant
+func (r *Router) GetAll(ctx context.Context) ([]*entity.Family, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return repo.GetAll(ctx)
+}
+
+// ExportAll exports all families from the repository of the tenant. It falls back to
+// GetAll if that repository does not implement ports.FamilyExporter.
+func (r *Router) ExportAll(ctx context.Context, fn func(*entity.Family) error) error {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if exporter, ok := repo.(ports.FamilyExporter); ok {
+		return exporter.ExportAll(ctx, fn)
+	}
+
+	families, err := repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, fam := range families {
+		if err := fn(fam); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LockFamily locks a family in the repository of the tenant, which stays open until the
+// family is unlocked. It does not lock anything if that repository does not implement
+// ports.FamilyLocker.
+func (r *Router) LockFamily(ctx context.Context, familyID string) (func(), error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	locker, ok := repo.(ports.FamilyLocker)
+	if !ok {
+		release()
+		return func() {}, nil
+	}
+	unlock, err := locker.LockFamily(ctx, familyID)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unlock()
+			release()
+		})
+	}, nil
+}
+
+// Ensure Router implements ports.UnitOfWork
+var _ ports.UnitOfWork = (*Router)(nil)
+
+// routedUnitKey is the context key of a unit of work that a router began
+type routedUnitKey struct{ router *Router }
+
+// routedUnit is a unit of work of the repository of a tenant, which stays open until the
+// unit of work ends
+type routedUnit struct {
+	unit    ports.UnitOfWork
+	release func()
+}
+
+// Begin begins a unit of work in the repository of the tenant, which stays open until the
+// unit of work is committed or rolled back. It fails with ports.ErrNoUnitOfWork if that
+// repository does not implement ports.UnitOfWork.
+func (r *Router) Begin(ctx context.Context) (context.Context, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unit, ok := repo.(ports.UnitOfWork)
+	if !ok {
+		release()
+		return nil, ports.ErrNoUnitOfWork
+	}
+	unitCtx, err := unit.Begin(ctx)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	var once sync.Once
+	routed := &routedUnit{unit: unit, release: func() { once.Do(release) }}
+	return context.WithValue(unitCtx, routedUnitKey{router: r}, routed), nil
+}
+
+// Commit applies the changes of the unit of work of the context
+func (r *Router) Commit(ctx context.Context) error {
+	routed, ok := ctx.Value(routedUnitKey{router: r}).(*routedUnit)
+	if !ok {
+		return errors.NewDatabaseError("no unit of work is in progress", "commit", "families", nil)
+	}
+	if err := routed.unit.Commit(ctx); err != nil {
+		return err
+	}
+	routed.release()
+	return nil
+}
+
+// Rollback discards the changes of the unit of work of the context
+func (r *Router) Rollback(ctx context.Context) error {
+	routed, ok := ctx.Value(routedUnitKey{router: r}).(*routedUnit)
+	if !ok {
+		return nil
+	}
+	defer routed.release()
+	return routed.unit.Rollback(ctx)
+}
+
+// Ensure Router implements ports.FamilyDeleter
+var _ ports.FamilyDeleter = (*Router)(nil)
+
+// SoftDeleteFamily marks a family as deleted in the repository of the tenant. It fails if
+// that repository does not implement ports.FamilyDeleter.
+func (r *Router) SoftDeleteFamily(ctx context.Context, familyID string, deletedAt time.Time) (bool, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	deleter, ok := repo.(ports.FamilyDeleter)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository of the tenant cannot delete families", "delete", "families", nil)
+	}
+	return deleter.SoftDeleteFamily(ctx, familyID, deletedAt)
+}
+
+// HardDeleteFamily removes a family from the repository of the tenant. It fails if that
+// repository does not implement ports.FamilyDeleter.
+func (r *Router) HardDeleteFamily(ctx context.Context, familyID string) (bool, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	deleter, ok := repo.(ports.FamilyDeleter)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository of the tenant cannot delete families", "delete", "families", nil)
+	}
+	return deleter.HardDeleteFamily(ctx, familyID)
+}
+
+// Ensure Router implements ports.FamilyRestorer
+var _ ports.FamilyRestorer = (*Router)(nil)
+
+// RestoreFamily restores a deleted family in the repository of the tenant. It fails if that
+// repository does not implement ports.FamilyRestorer.
+func (r *Router) RestoreFamily(ctx context.Context, familyID string) (bool, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	restorer, ok := repo.(ports.FamilyRestorer)
+	if !ok {
+		return false, errors.NewDatabaseError("the repository of the tenant cannot restore families", "update", "families", nil)
+	}
+	return restorer.RestoreFamily(ctx, familyID)
+}
+
+// PurgeDeletedFamilies purges the deleted families of the repository of the tenant. Even a
+// purge of every tenant purges that repository only, so the retention job, which has no
+// tenant, purges the primary repository. It fails if the repository does not implement
+// ports.FamilyRestorer.
+func (r *Router) PurgeDeletedFamilies(ctx context.Context, deletedBefore time.Time, allTenants bool) ([]string, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	restorer, ok := repo.(ports.FamilyRestorer)
+	if !ok {
+		return nil, errors.NewDatabaseError("the repository of the tenant cannot purge families", "delete", "families", nil)
+	}
+	return restorer.PurgeDeletedFamilies(ctx, deletedBefore, allTenants)
+}
+
+// Save persists a family in the repository of the tenant
+func (r *Router) Save(ctx context.Context, fam *entity.Family) error {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return repo.Save(ctx, fam)
+}
+
+// FindByParentID finds families by parent in the repository of the tenant
+func (r *Router) FindByParentID(ctx context.Context, parentID string) ([]*entity.Family, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return repo.FindByParentID(ctx, parentID)
+}
+
+// FindByChildID finds a family by child in the repository of the tenant
+func (r *Router) FindByChildID(ctx context.Context, childID string) (*entity.Family, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return repo.FindByChildID(ctx, childID)
+}
+
+// FindByExternalID finds families by external ID in the repository of the tenant
+func (r *Router) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return repo.FindByExternalID(ctx, system, externalID)
+}
+
+// Find finds the families that match a filter in the repository of the tenant
+func (r *Router) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return repo.Find(ctx, filter)
+}
+
+// CountFamilies counts the families in the repository of the tenant
+func (r *Router) CountFamilies(ctx context.Context) (int, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return repo.CountFamilies(ctx)
+}
+
+// CountParents counts the distinct parents in the repository of the tenant
+func (r *Router) CountParents(ctx context.Context) (int, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return repo.CountParents(ctx)
+}
+
+// CountChildren counts the distinct children in the repository of the tenant
+func (r *Router) CountChildren(ctx context.Context) (int, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return repo.CountChildren(ctx)
+}
+
+// ListFamilies reads a page of families from the repository of the tenant. It falls back
+// to GetAll if that repository does not implement ports.FamilyPager.
+func (r *Router) ListFamilies(ctx context.Context, after string, limit int) ([]*entity.Family, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if pager, ok := repo.(ports.FamilyPager); ok {
+		return pager.ListFamilies(ctx, after, limit)
+	}
+
+	families, err := repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return query.Page(families, after, limit), nil
+}
+
+// Ensure Router implements ports.FamilyOwnerReader
+var _ ports.FamilyOwnerReader = (*Router)(nil)
+
+// CountByOwner counts the families of an owner in the repository of the tenant. It falls
+// back to Find if that repository does not implement ports.FamilyOwnerReader.
+func (r *Router) CountByOwner(ctx context.Context, ownerID string, excluded []string) (ports.FamilyCounts, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return ports.FamilyCounts{}, err
+	}
+	defer release()
+
+	return ports.CountFamiliesByOwner(ctx, repo, ownerID, excluded)
+}
+
+// ListOwnedFamilies reads a page of the families of an owner from the repository of the
+// tenant. It falls back to Find if that repository does not implement
+// ports.FamilyOwnerReader.
+func (r *Router) ListOwnedFamilies(ctx context.Context, ownerID, after string, limit int) ([]*entity.Family, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return ports.ListFamiliesByOwner(ctx, repo, ownerID, after, limit)
+}
+
+// Ensure Router implements ports.FamilyFilterPager
+var _ ports.FamilyFilterPager = (*Router)(nil)
+
+// FindPage finds a page of the families that match a filter in the repository of the
+// tenant. It falls back to Find if that repository does not implement
+// ports.FamilyFilterPager.
+func (r *Router) FindPage(ctx context.Context, filter query.Filter, after string, limit int) ([]*entity.Family, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return ports.FindFamiliesPage(ctx, repo, filter, after, limit)
+}
+
+// Ensure Router implements ports.FamilyBatchReader
+var _ ports.FamilyBatchReader = (*Router)(nil)
+
+// GetByIDs retrieves families by ID from the repository of the tenant of the context. It
+// falls back to GetByID if that repository does not implement ports.FamilyBatchReader.
+func (r *Router) GetByIDs(ctx context.Context, ids []string) ([]*entity.Family, error) {
+	repo, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return ports.GetFamiliesByID(ctx, repo, ids)
+}
+
+// acquire returns the repository of the tenant of the context, and a function that
+// releases it once the operation has completed. A datasource in use is not closed as
+// idle. The first operation of a datasource opens it while the others wait.
+func (r *Router) acquire(ctx context.Context) (ports.FamilyRepository, func(), error) {
+	tenant := servicecontext.GetTenantID(ctx)
+	name, ok := r.cfg.Tenants[tenant]
+	if !ok {
+		return r.primary, func() {}, nil
+	}
+
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return nil, nil, errors.NewDatabaseError("the datasource of the tenant is closed", "route", name, nil)
+	}
+	p, exists := r.pools[name]
+	if !exists {
+		p = &pool{name: name, opened: make(chan struct{})}
+		r.pools[name] = p
+	}
+	p.users++
+	r.mu.Unlock()
+
+	if !exists {
+		r.openPool(ctx, p)
+	}
+
+	select {
+	case <-p.opened:
+	case <-ctx.Done():
+		r.release(p)
+		return nil, nil, ctx.Err()
+	}
+	if p.err != nil {
+		r.release(p)
+		return nil, nil, errors.NewDatabaseError("failed to open the datasource of the tenant", "route", name, p.err)
+	}
+	return p.ds.Repository, func() { r.release(p) }, nil
+}
+
+// openPool opens the datasource of a pool. The open is not abandoned when the operation
+// that triggered it is cancelled, since other operations may be waiting for it. A pool
+// that fails to open is removed, so that the next operation tries again.
+func (r *Router) openPool(ctx context.Context, p *pool) {
+	ds, err := r.open(context.WithoutCancel(ctx), p.name, r.cfg.Datasources[p.name])
+
+	r.mu.Lock()
+	p.ds, p.err = ds, err
+	if err != nil {
+		if r.pools[p.name] == p {
+			delete(r.pools, p.name)
+		}
+	} else {
+		p.lastUsed = r.now()
+		p.healthy = true
+	}
+	close(p.opened)
+	r.mu.Unlock()
+
+	if err != nil {
+		opensTotal.WithLabelValues(p.name, ResultFailed).Inc()
+		r.logger.Error(ctx, "Failed to open tenant datasource", zap.String("datasource", p.name), zap.Error(err))
+		return
+	}
+	opensTotal.WithLabelValues(p.name, ResultOpened).Inc()
+	openGauge.WithLabelValues(p.name).Set(1)
+	healthyGauge.WithLabelValues(p.name).Set(1)
+	r.logger.Info(ctx, "Opened tenant datasource", zap.String("datasource", p.name))
+}
+
+// release marks the end of an operation on a pool
+func (r *Router) release(p *pool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p.users--
+	p.lastUsed = r.now()
+}
+
+// closePool closes the datasource of a pool, if it was opened
+func (r *Router) closePool(ctx context.Context, p *pool) error {
+	if p.err != nil || p.ds == nil {
+		return nil
+	}
+	openGauge.WithLabelValues(p.name).Set(0)
+	if p.ds.Close == nil {
+		return nil
+	}
+	if err := p.ds.Close(ctx); err != nil {
+		r.logger.Warn(ctx, "Failed to close tenant datasource", zap.String("datasource", p.name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// run checks the health of the open datasources and closes the idle ones until stop is closed
+func (r *Router) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	var healthTick, idleTick <-chan time.Time
+	if r.cfg.HealthInterval > 0 {
+		ticker := time.NewTicker(r.cfg.HealthInterval)
+		defer ticker.Stop()
+		healthTick = ticker.C
+	}
+	if r.cfg.IdleTimeout > 0 {
+		// Check twice per timeout, so that a datasource is closed at most half a timeout late
+		ticker := time.NewTicker(r.cfg.IdleTimeout / 2)
+		defer ticker.Stop()
+		idleTick = ticker.C
+	}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-healthTick:
+			r.checkHealth(context.Background())
+		case <-idleTick:
+			r.closeIdle(context.Background())
+		}
+	}
+}
+
+// checkHealth pings the open datasources and records the results
+func (r *Router) checkHealth(ctx context.Context) {
+	r.mu.Lock()
+	pools := make([]*pool, 0, len(r.pools))
+	for _, p := range r.pools {
+		if p.isOpen() && p.ds.Ping != nil {
+			pools = append(pools, p)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, p := range pools {
+		pingCtx, cancel := context.WithTimeout(ctx, r.cfg.HealthTimeout)
+		err := p.ds.Ping(pingCtx)
+		cancel()
+
+		r.mu.Lock()
+		wasHealthy := p.healthy
+		p.healthy = err == nil
+		p.checkedAt = r.now()
+		p.checkErr = ""
+		if err != nil {
+			p.checkErr = err.Error()
+		}
+		r.mu.Unlock()
+
+		if err != nil {
+			healthyGauge.WithLabelValues(p.name).Set(0)
+			if wasHealthy {
+				r.logger.Warn(ctx, "Tenant datasource is unhealthy", zap.String("datasource", p.name), zap.Error(err))
+			}
+			continue
+		}
+		healthyGauge.WithLabelValues(p.name).Set(1)
+		if !wasHealthy {
+			r.logger.Info(ctx, "Tenant datasource is healthy again", zap.String("datasource", p.name))
+		}
+	}
+}
+
+// closeIdle closes the open datasources that have not been used for the idle timeout
+func (r *Router) closeIdle(ctx context.Context) {
+	now := r.now()
+
+	r.mu.Lock()
+	var idle []*pool
+	for name, p := range r.pools {
+		if p.isOpen() && p.users == 0 && now.Sub(p.lastUsed) >= r.cfg.IdleTimeout {
+			idle = append(idle, p)
+			delete(r.pools, name)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, p := range idle {
+		if err := r.closePool(ctx, p); err == nil {
+			r.logger.Info(ctx, "Closed idle tenant datasource", zap.String("datasource", p.name))
+		}
+	}
+}
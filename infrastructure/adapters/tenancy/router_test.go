@@ -0,0 +1,297 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package tenancy
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/abitofhelp/servicelib/errors"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeRepository is an in-memory FamilyRepository that records its saves
+type fakeRepository struct {
+	mu    sync.Mutex
+	saved []string
+}
+
+func (r *fakeRepository) GetByID(ctx context.Context, id string) (*entity.Family, error) {
+	return nil, errors.NewNotFoundError("Family", id, nil)
+}
+
+func (r *fakeRepository) GetAll(ctx context.Context) ([]*entity.Family, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) Save(ctx context.Context, fam *entity.Family) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.saved = append(r.saved, fam.ID())
+	return nil
+}
+
+func (r *fakeRepository) FindByParentID(ctx context.Context, parentID string) ([]*entity.Family, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) FindByChildID(ctx context.Context, childID string) (*entity.Family, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) CountFamilies(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeRepository) CountParents(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeRepository) CountChildren(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeRepository) LockFamily(ctx context.Context, familyID string) (func(), error) {
+	return func() {}, nil
+}
+
+func (r *fakeRepository) savedIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.saved...)
+}
+
+// fakeOpener opens fake datasources and counts the opens and closes of each
+type fakeOpener struct {
+	mu      sync.Mutex
+	repos   map[string]*fakeRepository
+	opens   map[string]int
+	closes  map[string]int
+	openErr error
+	pingErr error
+}
+
+func newFakeOpener() *fakeOpener {
+	return &fakeOpener{
+		repos:  make(map[string]*fakeRepository),
+		opens:  make(map[string]int),
+		closes: make(map[string]int),
+	}
+}
+
+func (o *fakeOpener) open(ctx context.Context, name string, cfg config.DatasourceConfig) (*Datasource, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.openErr != nil {
+		return nil, o.openErr
+	}
+	o.opens[name]++
+	repo, ok := o.repos[name]
+	if !ok {
+		repo = &fakeRepository{}
+		o.repos[name] = repo
+	}
+	return &Datasource{
+		Repository: repo,
+		Ping: func(ctx context.Context) error {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			return o.pingErr
+		},
+		Close: func(ctx context.Context) error {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			o.closes[name]++
+			return nil
+		},
+	}, nil
+}
+
+func (o *fakeOpener) count(counts map[string]int, name string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return counts[name]
+}
+
+// testConfig routes two tenants to a shared datasource and one to its own
+func testConfig() config.TenancyConfig {
+	return config.TenancyConfig{
+		Enabled: true,
+		Datasources: map[string]config.DatasourceConfig{
+			"shared":    {Type: "postgres", URI: "postgres://shared"},
+			"dedicated": {Type: "mongodb", URI: "mongodb://dedicated"},
+		},
+		Tenants: map[string]string{
+			"tenant-a": "shared",
+			"tenant-b": "shared",
+			"tenant-c": "dedicated",
+		},
+		IdleTimeout:    time.Minute,
+		HealthInterval: time.Minute,
+		HealthTimeout:  time.Second,
+	}
+}
+
+func newTestRouter(t *testing.T, primary *fakeRepository, opener *fakeOpener) *Router {
+	t.Helper()
+
+	router, err := NewRouter(primary, testConfig(), opener.open, logging.NewContextLogger(zap.NewNop()))
+	require.NoError(t, err)
+	return router
+}
+
+func newTestFamily(t *testing.T, id string) *entity.Family {
+	t.Helper()
+
+	parent, err := entity.NewParent("5b7e2c3d-8a4f-4e6b-b1c9-2f3a4d5e6f01", "Ada", "Tenant", time.Date(1980, time.January, 2, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := entity.NewFamily(id, entity.Single, []*entity.Parent{parent}, nil)
+	require.NoError(t, err)
+	return fam
+}
+
+func tenantContext(tenant string) context.Context {
+	return servicecontext.WithTenantID(context.Background(), tenant)
+}
+
+func TestNewRouter_UnknownDatasource(t *testing.T) {
+	cfg := testConfig()
+	cfg.Tenants["tenant-d"] = "missing"
+
+	_, err := NewRouter(&fakeRepository{}, cfg, newFakeOpener().open, logging.NewContextLogger(zap.NewNop()))
+	assert.ErrorContains(t, err, "missing")
+}
+
+func TestRouter_RoutesByTenant(t *testing.T) {
+	primary := &fakeRepository{}
+	opener := newFakeOpener()
+	router := newTestRouter(t, primary, opener)
+
+	family1 := "0d8f4f5e-1c1a-4b9e-9a51-6b2d7c1e0a01"
+	family2 := "0d8f4f5e-1c1a-4b9e-9a51-6b2d7c1e0a02"
+	family3 := "0d8f4f5e-1c1a-4b9e-9a51-6b2d7c1e0a03"
+	require.NoError(t, router.Save(tenantContext("tenant-a"), newTestFamily(t, family1)))
+	require.NoError(t, router.Save(tenantContext("tenant-b"), newTestFamily(t, family2)))
+	require.NoError(t, router.Save(tenantContext("tenant-z"), newTestFamily(t, family3)))
+	require.NoError(t, router.Save(context.Background(), newTestFamily(t, family3)))
+
+	// Tenants of a datasource share one instance of it
+	assert.Equal(t, []string{family1, family2}, opener.repos["shared"].savedIDs())
+	assert.Equal(t, 1, opener.count(opener.opens, "shared"))
+	assert.Equal(t, 0, opener.count(opener.opens, "dedicated"))
+	assert.Equal(t, []string{family3, family3}, primary.savedIDs())
+}
+
+func TestRouter_OpenFailureIsRetried(t *testing.T) {
+	opener := newFakeOpener()
+	opener.openErr = stderrors.New("connection refused")
+	router := newTestRouter(t, &fakeRepository{}, opener)
+
+	_, err := router.GetAll(tenantContext("tenant-c"))
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "connection refused")
+
+	opener.mu.Lock()
+	opener.openErr = nil
+	opener.mu.Unlock()
+
+	_, err = router.GetAll(tenantContext("tenant-c"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, opener.count(opener.opens, "dedicated"))
+}
+
+func TestRouter_ClosesIdleDatasources(t *testing.T) {
+	opener := newFakeOpener()
+	router := newTestRouter(t, &fakeRepository{}, opener)
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	router.now = func() time.Time { return now }
+
+	_, err := router.GetAll(tenantContext("tenant-a"))
+	require.NoError(t, err)
+
+	// A locked family keeps its datasource in use
+	unlock, err := router.LockFamily(tenantContext("tenant-c"), "0d8f4f5e-1c1a-4b9e-9a51-6b2d7c1e0a01")
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	router.closeIdle(context.Background())
+	assert.Equal(t, 1, opener.count(opener.closes, "shared"))
+	assert.Equal(t, 0, opener.count(opener.closes, "dedicated"))
+
+	unlock()
+	unlock()
+	now = now.Add(2 * time.Minute)
+	router.closeIdle(context.Background())
+	assert.Equal(t, 1, opener.count(opener.closes, "dedicated"))
+
+	// The next operation opens the datasource again
+	_, err = router.GetAll(tenantContext("tenant-b"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, opener.count(opener.opens, "shared"))
+}
+
+func TestRouter_Health(t *testing.T) {
+	opener := newFakeOpener()
+	router := newTestRouter(t, &fakeRepository{}, opener)
+
+	_, err := router.GetAll(tenantContext("tenant-a"))
+	require.NoError(t, err)
+
+	opener.mu.Lock()
+	opener.pingErr = stderrors.New("timeout")
+	opener.mu.Unlock()
+	router.checkHealth(context.Background())
+
+	health := router.Health()
+	require.Len(t, health, 3)
+	assert.Equal(t, "tenant-a", health[0].Tenant)
+	assert.True(t, health[0].Open)
+	assert.False(t, health[0].Healthy)
+	assert.Equal(t, "timeout", health[0].Error)
+	assert.Equal(t, "shared", health[1].Datasource)
+	assert.True(t, health[1].Open)
+	assert.Equal(t, TenantHealth{Tenant: "tenant-c", Datasource: "dedicated"}, health[2])
+
+	opener.mu.Lock()
+	opener.pingErr = nil
+	opener.mu.Unlock()
+	router.checkHealth(context.Background())
+	assert.True(t, router.Health()[0].Healthy)
+}
+
+func TestRouter_Stop(t *testing.T) {
+	opener := newFakeOpener()
+	router := newTestRouter(t, &fakeRepository{}, opener)
+	router.Start()
+
+	_, err := router.GetAll(tenantContext("tenant-c"))
+	require.NoError(t, err)
+
+	require.NoError(t, router.Stop(context.Background()))
+	assert.Equal(t, 1, opener.count(opener.closes, "dedicated"))
+
+	_, err = router.GetAll(tenantContext("tenant-c"))
+	assert.Error(t, err)
+
+	// Tenants without a dedicated database are still served by the primary
+	_, err = router.GetAll(tenantContext("tenant-z"))
+	assert.NoError(t, err)
+}
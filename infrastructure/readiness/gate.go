@@ -0,0 +1,153 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package readiness coordinates pod termination with load balancers.
+//
+// When a pod is terminated, load balancers keep routing requests to it until they
+// observe that it is no longer ready. A Gate closes this window: draining the gate
+// makes the health check report NOT_READY, then waits a configurable delay for load
+// balancers to stop sending traffic before the server begins its graceful shutdown.
+// Termination can be requested with a signal or with a POST to the quit endpoint,
+// which is intended for a Kubernetes preStop hook.
+package readiness
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// StatusNotReady is the status reported by the health check while the gate drains
+const StatusNotReady = "NOT_READY"
+
+// Gate tracks whether the server is ready to receive traffic.
+type Gate struct {
+	draining   atomic.Bool
+	drainDelay time.Duration
+	quit       chan struct{}
+	quitOnce   sync.Once
+	logger     *zap.Logger
+}
+
+// NewGate creates a ready gate that waits drainDelay for load balancers when it is drained.
+//
+// Parameters:
+//   - drainDelay: Time to wait after reporting NOT_READY before shutdown proceeds
+//   - logger: Logger for termination events
+//
+// Returns:
+//   - A new Gate that reports ready
+func NewGate(drainDelay time.Duration, logger *zap.Logger) *Gate {
+	return &Gate{
+		drainDelay: drainDelay,
+		quit:       make(chan struct{}),
+		logger:     logger,
+	}
+}
+
+// Ready reports whether the server is ready to receive traffic.
+func (g *Gate) Ready() bool {
+	return !g.draining.Load()
+}
+
+// Quit requests termination of the server. Calling it more than once has no further effect.
+func (g *Gate) Quit() {
+	g.quitOnce.Do(func() {
+		g.logger.Info("Termination requested through the quit endpoint")
+		close(g.quit)
+	})
+}
+
+// WithQuit returns a copy of parent that is cancelled when termination is requested with Quit.
+// It is meant to be passed to the function that waits for termination signals, so that both
+// signals and the quit endpoint start the same shutdown chain.
+//
+// Parameters:
+//   - parent: The context to derive from
+//
+// Returns:
+//   - A context that is done when parent is done or Quit is called
+//   - A function that releases the resources of the context
+func (g *Gate) WithQuit(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	go func() {
+		select {
+		case <-g.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// Drain makes the gate report NOT_READY and waits for the drain delay, or until ctx is done.
+// Draining an already draining gate does not wait again.
+//
+// Parameters:
+//   - ctx: The context that bounds the wait
+func (g *Gate) Drain(ctx context.Context) {
+	if g.draining.Swap(true) {
+		return
+	}
+	g.logger.Info("Reporting NOT_READY and waiting for load balancers to drain",
+		zap.Duration("drain_delay", g.drainDelay))
+	if g.drainDelay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(g.drainDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		g.logger.Warn("Drain delay cut short", zap.Error(ctx.Err()))
+	}
+}
+
+// HealthMiddleware wraps the health check handler so that it responds with
+// 503 Service Unavailable and a NOT_READY status while the gate drains.
+func (g *Gate) HealthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if g.Ready() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": StatusNotReady})
+	})
+}
+
+// QuitHandler returns a handler that requests termination of the server.
+// Only POST requests from the loopback interface are accepted, so the endpoint
+// can be called by a preStop hook in the pod but not from outside of it.
+func (g *Gate) QuitHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if !isLoopback(r.RemoteAddr) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		g.Quit()
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+// isLoopback reports whether a remote address is on the loopback interface
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
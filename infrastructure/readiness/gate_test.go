@@ -0,0 +1,87 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package readiness
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestGate_DrainReportsNotReady(t *testing.T) {
+	g := NewGate(20*time.Millisecond, zaptest.NewLogger(t))
+	health := g.HealthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	health.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	start := time.Now()
+	g.Drain(context.Background())
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond, "drain waits for the delay")
+	assert.False(t, g.Ready())
+
+	rec = httptest.NewRecorder()
+	health.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status":"NOT_READY"}`, rec.Body.String())
+
+	// A second drain does not wait again
+	start = time.Now()
+	g.Drain(context.Background())
+	assert.Less(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestGate_DrainCancelled(t *testing.T) {
+	g := NewGate(time.Minute, zaptest.NewLogger(t))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	g.Drain(ctx)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.False(t, g.Ready())
+}
+
+func TestGate_QuitHandler(t *testing.T) {
+	g := NewGate(0, zaptest.NewLogger(t))
+	ctx, cancel := g.WithQuit(context.Background())
+	defer cancel()
+	handler := g.QuitHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quitquitquit", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/quitquitquit", nil)
+	req.RemoteAddr = "10.0.0.7:41000"
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code, "requests from outside the pod are rejected")
+	assert.NoError(t, ctx.Err())
+
+	req = httptest.NewRequest(http.MethodPost, "/quitquitquit", nil)
+	req.RemoteAddr = "127.0.0.1:41000"
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		require.Fail(t, "quit did not cancel the context")
+	}
+
+	// Quitting twice is harmless
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
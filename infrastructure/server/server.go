@@ -5,7 +5,9 @@
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/abitofhelp/servicelib/logging"
@@ -70,6 +72,10 @@ func New(cfg Config, handler http.Handler, logger *zap.Logger, contextLogger *lo
 	// Apply middleware to the handler using the centralized middleware package
 	wrappedHandler := middleware.ApplyMiddleware(handler, logger)
 
+	// The middleware neither lets handlers hijack connections nor lets requests run for
+	// longer than a minute, so websocket upgrades bypass it
+	wrappedHandler = bypassForUpgrades(handler, wrappedHandler)
+
 	return &Server{
 		Server: &http.Server{
 			Addr:         ":" + cfg.Port,
@@ -84,13 +90,25 @@ func New(cfg Config, handler http.Handler, logger *zap.Logger, contextLogger *lo
 	}
 }
 
+// bypassForUpgrades returns a handler that serves websocket upgrade requests with handler
+// and other requests with wrapped, the handler with middleware applied
+func bypassForUpgrades(handler, wrapped http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		wrapped.ServeHTTP(w, r)
+	})
+}
+
 // Start starts the server in a goroutine.
 // It begins listening for HTTP requests in a non-blocking manner.
 // If the server fails to start, it logs a fatal error and terminates the application.
 func (s *Server) Start() {
 	s.contextLogger.Info(context.Background(), "Starting HTTP server", zap.String("address", s.Addr))
 	go func() {
-		if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := s.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			s.contextLogger.Fatal(context.Background(), "HTTP server failed to start", zap.Error(err))
 		}
 	}()
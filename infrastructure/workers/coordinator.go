@@ -0,0 +1,209 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package workers provides coordination for background workers during shutdown.
+//
+// Background components such as jobs, outbox relays, and indexers register
+// themselves with a Coordinator when they start. On shutdown the Coordinator
+// signals every registered worker to stop, waits for each one within its own
+// deadline, and reports which components failed to stop within budget.
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// StopFunc signals a worker to stop and blocks until it has drained or the
+// context is done. Implementations should return ctx.Err() when they give up
+// waiting so the Coordinator can distinguish a timeout from a failure.
+type StopFunc func(ctx context.Context) error
+
+// Worker is a background component that can be drained during shutdown.
+type Worker interface {
+	// Name returns a unique, human-readable name for the worker
+	Name() string
+
+	// Stop signals the worker to stop and waits for it to drain
+	Stop(ctx context.Context) error
+}
+
+// registration holds a registered worker and its drain deadline
+type registration struct {
+	name     string
+	deadline time.Duration
+	stop     StopFunc
+}
+
+// Result describes the outcome of draining a single worker.
+type Result struct {
+	Name     string        // Name of the worker
+	Duration time.Duration // Time taken for the worker to stop (or to give up)
+	TimedOut bool          // True if the worker did not stop within its deadline
+	Err      error         // Error returned by the worker, if any
+}
+
+// Report summarizes the outcome of draining all registered workers.
+type Report struct {
+	Results []Result // Results for every registered worker, sorted by name
+}
+
+// Failed returns the results for workers that timed out or returned an error.
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.TimedOut || res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Err returns an error describing all workers that failed to stop cleanly,
+// or nil if every worker drained within its deadline.
+func (r *Report) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	parts := make([]string, 0, len(failed))
+	for _, res := range failed {
+		if res.TimedOut {
+			parts = append(parts, fmt.Sprintf("%s: did not stop within budget", res.Name))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s: %v", res.Name, res.Err))
+		}
+	}
+	return fmt.Errorf("failed to drain %d worker(s): %s", len(failed), strings.Join(parts, "; "))
+}
+
+// Coordinator tracks background workers and drains them on shutdown.
+type Coordinator struct {
+	mu              sync.Mutex
+	workers         []registration
+	defaultDeadline time.Duration
+	logger          *zap.Logger
+}
+
+// NewCoordinator creates a new Coordinator.
+//
+// Parameters:
+//   - defaultDeadline: The drain deadline applied to workers registered without their own deadline
+//   - logger: Logger for drain progress and failures
+//
+// Returns:
+//   - A new Coordinator with no registered workers
+func NewCoordinator(defaultDeadline time.Duration, logger *zap.Logger) *Coordinator {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Coordinator{
+		defaultDeadline: defaultDeadline,
+		logger:          logger,
+	}
+}
+
+// Register adds a worker that will be drained on shutdown using the default deadline.
+func (c *Coordinator) Register(w Worker) {
+	c.RegisterFunc(w.Name(), 0, w.Stop)
+}
+
+// RegisterWithDeadline adds a worker with its own drain deadline.
+func (c *Coordinator) RegisterWithDeadline(w Worker, deadline time.Duration) {
+	c.RegisterFunc(w.Name(), deadline, w.Stop)
+}
+
+// RegisterFunc adds a named stop function with an optional drain deadline.
+// A deadline of zero or less means the Coordinator's default deadline is used.
+func (c *Coordinator) RegisterFunc(name string, deadline time.Duration, stop StopFunc) {
+	if deadline <= 0 {
+		deadline = c.defaultDeadline
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.workers = append(c.workers, registration{name: name, deadline: deadline, stop: stop})
+}
+
+// Len returns the number of registered workers.
+func (c *Coordinator) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.workers)
+}
+
+// Drain signals all registered workers to stop concurrently and waits for each
+// one up to its own deadline. The parent context bounds the overall drain: if it
+// is cancelled, any worker still running is reported as timed out.
+//
+// Parameters:
+//   - ctx: Context bounding the overall drain operation
+//
+// Returns:
+//   - A Report describing the outcome for every registered worker
+func (c *Coordinator) Drain(ctx context.Context) *Report {
+	c.mu.Lock()
+	workers := make([]registration, len(c.workers))
+	copy(workers, c.workers)
+	c.mu.Unlock()
+
+	results := make([]Result, len(workers))
+	var wg sync.WaitGroup
+	for i, w := range workers {
+		wg.Add(1)
+		go func(i int, w registration) {
+			defer wg.Done()
+			results[i] = c.drainOne(ctx, w)
+		}(i, w)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := &Report{Results: results}
+	for _, res := range report.Failed() {
+		c.logger.Error("Worker failed to stop within budget",
+			zap.String("worker", res.Name),
+			zap.Duration("elapsed", res.Duration),
+			zap.Bool("timed_out", res.TimedOut),
+			zap.Error(res.Err))
+	}
+	c.logger.Info("Background workers drained",
+		zap.Int("total", len(results)),
+		zap.Int("failed", len(report.Failed())))
+
+	return report
+}
+
+// drainOne stops a single worker within its deadline
+func (c *Coordinator) drainOne(ctx context.Context, w registration) Result {
+	workerCtx, cancel := context.WithTimeout(ctx, w.deadline)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- w.stop(workerCtx)
+	}()
+
+	c.logger.Debug("Stopping worker", zap.String("worker", w.name), zap.Duration("deadline", w.deadline))
+
+	select {
+	case err := <-done:
+		res := Result{Name: w.name, Duration: time.Since(start), Err: err}
+		if err != nil && workerCtx.Err() != nil && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)) {
+			res.TimedOut = true
+			res.Err = nil
+		}
+		return res
+	case <-workerCtx.Done():
+		return Result{Name: w.name, Duration: time.Since(start), TimedOut: true}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// testWorker is a simple Worker implementation for tests
+type testWorker struct {
+	name  string
+	delay time.Duration
+	err   error
+}
+
+func (w *testWorker) Name() string { return w.name }
+
+func (w *testWorker) Stop(ctx context.Context) error {
+	select {
+	case <-time.After(w.delay):
+		return w.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestCoordinator_DrainAllStop(t *testing.T) {
+	c := NewCoordinator(time.Second, zaptest.NewLogger(t))
+	c.Register(&testWorker{name: "outbox"})
+	c.Register(&testWorker{name: "indexer", delay: 10 * time.Millisecond})
+
+	report := c.Drain(context.Background())
+
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, "indexer", report.Results[0].Name)
+	assert.Equal(t, "outbox", report.Results[1].Name)
+	assert.Empty(t, report.Failed())
+	assert.NoError(t, report.Err())
+}
+
+func TestCoordinator_DrainPerComponentDeadline(t *testing.T) {
+	c := NewCoordinator(time.Second, zaptest.NewLogger(t))
+	c.RegisterWithDeadline(&testWorker{name: "slow", delay: time.Second}, 20*time.Millisecond)
+	c.Register(&testWorker{name: "fast"})
+
+	start := time.Now()
+	report := c.Drain(context.Background())
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	failed := report.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, "slow", failed[0].Name)
+	assert.True(t, failed[0].TimedOut)
+	assert.ErrorContains(t, report.Err(), "slow: did not stop within budget")
+}
+
+func TestCoordinator_DrainWorkerError(t *testing.T) {
+	c := NewCoordinator(time.Second, zaptest.NewLogger(t))
+	c.RegisterFunc("jobs", 0, func(ctx context.Context) error {
+		return errors.New("flush failed")
+	})
+
+	report := c.Drain(context.Background())
+
+	failed := report.Failed()
+	require.Len(t, failed, 1)
+	assert.False(t, failed[0].TimedOut)
+	assert.ErrorContains(t, report.Err(), "jobs: flush failed")
+}
+
+func TestCoordinator_DrainParentCancelled(t *testing.T) {
+	c := NewCoordinator(time.Minute, zaptest.NewLogger(t))
+	c.Register(&testWorker{name: "stuck", delay: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	report := c.Drain(ctx)
+
+	require.Len(t, report.Failed(), 1)
+	assert.True(t, report.Failed()[0].TimedOut)
+}
+
+func TestCoordinator_DrainEmpty(t *testing.T) {
+	c := NewCoordinator(time.Second, nil)
+
+	report := c.Drain(context.Background())
+
+	assert.Equal(t, 0, c.Len())
+	assert.Empty(t, report.Results)
+	assert.NoError(t, report.Err())
+}
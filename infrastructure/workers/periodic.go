@@ -0,0 +1,114 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package workers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// JobFunc is a unit of work run by a Periodic worker. The context is cancelled when the
+// worker is stopped, so that a run in progress can give up early.
+type JobFunc func(ctx context.Context) error
+
+// Periodic is a Worker that runs a job at a fixed interval until it is stopped.
+// Runs never overlap: the next run is scheduled only after the previous one returns.
+type Periodic struct {
+	name     string
+	interval time.Duration
+	job      JobFunc
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+	started bool
+}
+
+// Ensure Periodic implements Worker
+var _ Worker = (*Periodic)(nil)
+
+// NewPeriodic creates a new Periodic worker that has not been started.
+//
+// Parameters:
+//   - name: Unique, human-readable name for the worker
+//   - interval: Time between the end of one run and the start of the next (must be positive)
+//   - job: The job to run
+//   - logger: Logger for failed runs
+//
+// Returns:
+//   - A new Periodic worker
+func NewPeriodic(name string, interval time.Duration, job JobFunc, logger *zap.Logger) *Periodic {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Periodic{
+		name:     name,
+		interval: interval,
+		job:      job,
+		logger:   logger,
+	}
+}
+
+// Name returns the name of the worker
+func (p *Periodic) Name() string {
+	return p.name
+}
+
+// Start runs the job in the background, first after one interval and then every interval
+// until Stop is called. Calling Start more than once has no effect.
+func (p *Periodic) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return
+	}
+	p.started = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go p.run(ctx, p.done)
+}
+
+// run runs the job every interval until ctx is cancelled
+func (p *Periodic) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	timer := time.NewTimer(p.interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if err := p.job(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Error("Periodic job failed", zap.String("worker", p.name), zap.Error(err))
+		}
+		timer.Reset(p.interval)
+	}
+}
+
+// Stop cancels the job and waits for a run in progress to return, or for ctx to be done.
+// Stopping a worker that was never started returns immediately.
+func (p *Periodic) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPeriodic_RunsUntilStopped(t *testing.T) {
+	var runs atomic.Int32
+	p := NewPeriodic("consent-expiry", 5*time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		return errors.New("failures are logged and do not stop the worker")
+	}, zaptest.NewLogger(t))
+
+	p.Start()
+	p.Start()
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+
+	require.NoError(t, p.Stop(context.Background()))
+	stopped := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load())
+	assert.Equal(t, "consent-expiry", p.Name())
+}
+
+func TestPeriodic_StopCancelsRunInProgress(t *testing.T) {
+	started := make(chan struct{})
+	p := NewPeriodic("slow", time.Millisecond, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, zaptest.NewLogger(t))
+
+	p.Start()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, p.Stop(ctx))
+}
+
+func TestPeriodic_StopBeforeStart(t *testing.T) {
+	p := NewPeriodic("idle", time.Hour, func(ctx context.Context) error { return nil }, nil)
+	assert.NoError(t, p.Stop(context.Background()))
+}
+
+func TestPeriodic_DrainedByCoordinator(t *testing.T) {
+	p := NewPeriodic("job", time.Hour, func(ctx context.Context) error { return nil }, nil)
+	p.Start()
+
+	c := NewCoordinator(time.Second, zaptest.NewLogger(t))
+	c.Register(p)
+	report := c.Drain(context.Background())
+
+	require.Len(t, report.Results, 1)
+	assert.NoError(t, report.Err())
+}
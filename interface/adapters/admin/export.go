@@ -0,0 +1,280 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package admin
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"go.uber.org/zap"
+)
+
+// ExportErrorTrailer is the trailer of an export that failed after its first family was
+// written, whose body ends early
+const ExportErrorTrailer = "Export-Error"
+
+// ExportCursorTrailer is the trailer of an export that stopped at its limit before the last
+// family. It holds the ID of the last family written, the after parameter of the next chunk.
+const ExportCursorTrailer = "Export-Cursor"
+
+// errChunkFull stops an export whose chunk holds as many families as its limit
+var errChunkFull = errors.New("the export chunk is full")
+
+// exportColumns are the columns of a CSV export, which has a row for each parent and child
+var exportColumns = []string{
+	"family_id", "family_status", "family_version",
+	"member_type", "member_id", "first_name", "last_name", "preferred_name", "birth_date", "death_date",
+}
+
+// familyWriter writes the families of an export in a format
+type familyWriter interface {
+	// Begin writes what precedes the families; only the first chunk of an export has it
+	Begin() error
+
+	// Write writes a family
+	Write(family *entity.FamilyDTO) error
+
+	// End writes what follows the families and flushes the writer
+	End() error
+}
+
+// exportFormat is a format of the export
+type exportFormat struct {
+	contentType string
+	extension   string
+	writer      func(w io.Writer) familyWriter
+}
+
+// exportFormats are the formats of the export, by the value of the format parameter
+var exportFormats = map[string]exportFormat{
+	"json": {"application/x-ndjson", "jsonl", func(w io.Writer) familyWriter { return &jsonLinesWriter{encoder: json.NewEncoder(w)} }},
+	"csv":  {"text/csv; charset=utf-8", "csv", func(w io.Writer) familyWriter { return &csvWriter{writer: csv.NewWriter(w)} }},
+}
+
+// exportFamilies streams every family as an attachment in the format of the format
+// parameter, json by default. The headers are written with the first family, so a failure
+// before it is reported as an error response; a later failure ends the body early and
+// is reported in the Export-Error trailer.
+//
+// An export is downloaded in chunks, or resumed after an interrupted download, with the
+// after and limit parameters: a chunk holds at most limit families, those whose IDs sort
+// after the ID of after, and a chunk that stops at its limit sets the Export-Cursor trailer
+// to the after of the next chunk. Only the first chunk has the header row of a CSV export,
+// so that the chunks of an export are appended to one file.
+//
+// With an export store, each export, or chunk, is stored to the end even if its download is
+// interrupted, and its ETag identifies it. A Range request whose If-Range is the ETag of a
+// stored export of the tenant of the caller is served from the store, without exporting the
+// families again, whatever its parameters; other Range requests are answered with the whole
+// export, as its headers are written before its length is known.
+func (h *Handler) exportFamilies(w http.ResponseWriter, r *http.Request) string {
+	if h.deps.Families == nil {
+		writeError(w, http.StatusNotImplemented, CodeUnavailable, "family export is unavailable")
+		return OutcomeFailed
+	}
+	if h.deps.Exports != nil && r.Header.Get("Range") != "" && h.resumeExport(w, r) {
+		return OutcomeSucceeded
+	}
+
+	params := r.URL.Query()
+	name := params.Get("format")
+	if name == "" {
+		name = "json"
+	}
+	format, ok := exportFormats[name]
+	if !ok {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "the format must be json or csv")
+		return OutcomeFailed
+	}
+	after := params.Get("after")
+	limit := 0
+	if value := params.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			writeError(w, http.StatusBadRequest, CodeBadRequest, "the limit must be a positive integer")
+			return OutcomeFailed
+		}
+	}
+
+	ctx := r.Context()
+	var out io.Writer = w
+	id := ""
+	if h.deps.Exports != nil {
+		file, created, err := h.deps.Exports.create()
+		if err != nil {
+			h.deps.Logger.Warn("Failed to store the export, whose download cannot be resumed", zap.Error(err))
+		} else {
+			defer file.Close()
+			id, out = created, &storingWriter{file: file, client: w}
+			ctx = context.WithoutCancel(ctx)
+		}
+	}
+
+	writer := format.writer(out)
+	started := false
+	begin := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		format.setHeaders(w)
+		w.Header().Set("Trailer", ExportErrorTrailer+", "+ExportCursorTrailer)
+		if id != "" {
+			w.Header().Set("ETag", `"`+id+`"`)
+			w.Header().Set("Accept-Ranges", "bytes")
+		}
+		w.WriteHeader(http.StatusOK)
+		if after != "" {
+			return nil
+		}
+		return writer.Begin()
+	}
+
+	written := 0
+	last := ""
+	count, err := h.deps.Families.ExportFamilies(ctx, after, func(family *entity.FamilyDTO) error {
+		if limit > 0 && written == limit {
+			return errChunkFull
+		}
+		if err := begin(); err != nil {
+			return err
+		}
+		if err := writer.Write(family); err != nil {
+			return err
+		}
+		written++
+		last = family.ID
+		return nil
+	})
+	full := errors.Is(err, errChunkFull)
+	if err == nil || full {
+		if err = begin(); err == nil {
+			err = writer.End()
+		}
+	}
+	if err != nil {
+		if id != "" {
+			h.deps.Exports.discard(id)
+		}
+		h.deps.Logger.Error("Failed to export families", zap.Int("count", count), zap.Error(err))
+		if !started {
+			writeError(w, http.StatusInternalServerError, CodeFailed, "failed to export families")
+			return OutcomeFailed
+		}
+		w.Header().Set(ExportErrorTrailer, fmt.Sprintf("the export failed after %d families", count))
+		return OutcomeFailed
+	}
+	cursor := ""
+	if full {
+		cursor = last
+		w.Header().Set(ExportCursorTrailer, cursor)
+	}
+	if id != "" {
+		h.deps.Exports.keep(id, servicecontext.GetTenantID(r.Context()), format, cursor)
+	}
+	return OutcomeSucceeded
+}
+
+// resumeExport serves a Range request from the stored export whose ETag is the If-Range of
+// the request, and reports whether there is one. The cursor of a stored chunk is sent in the
+// Export-Cursor header.
+func (h *Handler) resumeExport(w http.ResponseWriter, r *http.Request) bool {
+	id := strings.Trim(r.Header.Get("If-Range"), `"`)
+	file, export, ok := h.deps.Exports.open(id, servicecontext.GetTenantID(r.Context()))
+	if !ok {
+		return false
+	}
+	defer file.Close()
+
+	export.format.setHeaders(w)
+	w.Header().Set("ETag", `"`+id+`"`)
+	if export.cursor != "" {
+		w.Header().Set(ExportCursorTrailer, export.cursor)
+	}
+	http.ServeContent(w, r, "", export.createdAt, file)
+	return true
+}
+
+// setHeaders sets the headers of an export in the format
+func (f exportFormat) setHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", f.contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="families.`+f.extension+`"`)
+}
+
+// storingWriter writes an export to its file in the export store and to the client. A failed
+// write to the client, whose download was interrupted, does not stop the export, which is
+// stored to the end so that the client resumes it.
+type storingWriter struct {
+	file      io.Writer
+	client    io.Writer
+	clientErr error
+}
+
+func (s *storingWriter) Write(p []byte) (int, error) {
+	n, err := s.file.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if s.clientErr == nil {
+		_, s.clientErr = s.client.Write(p)
+	}
+	return n, nil
+}
+
+// jsonLinesWriter writes a family DTO on each line, the format the data migration tool imports
+type jsonLinesWriter struct {
+	encoder *json.Encoder
+}
+
+func (j *jsonLinesWriter) Begin() error { return nil }
+
+func (j *jsonLinesWriter) Write(family *entity.FamilyDTO) error { return j.encoder.Encode(family) }
+
+func (j *jsonLinesWriter) End() error { return nil }
+
+// csvWriter writes a row for each parent and child of a family, with the family's columns
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (c *csvWriter) Begin() error { return c.writer.Write(exportColumns) }
+
+func (c *csvWriter) Write(family *entity.FamilyDTO) error {
+	row := func(memberType, id, firstName, lastName, preferredName string, birthDate time.Time, deathDate *time.Time) error {
+		death := ""
+		if deathDate != nil {
+			death = deathDate.UTC().Format(time.RFC3339)
+		}
+		return c.writer.Write([]string{
+			family.ID, family.Status, strconv.Itoa(family.Version),
+			memberType, id, firstName, lastName, preferredName, birthDate.UTC().Format(time.RFC3339), death,
+		})
+	}
+
+	for _, p := range family.Parents {
+		if err := row("PARENT", p.ID, p.FirstName, p.LastName, p.PreferredName, p.BirthDate, p.DeathDate); err != nil {
+			return err
+		}
+	}
+	for _, ch := range family.Children {
+		if err := row("CHILD", ch.ID, ch.FirstName, ch.LastName, ch.PreferredName, ch.BirthDate, ch.DeathDate); err != nil {
+			return err
+		}
+	}
+	return c.writer.Error()
+}
+
+func (c *csvWriter) End() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+	"time"
+)
+
+// storedExport is an export kept by an ExportStore
+type storedExport struct {
+	path      string
+	tenant    string
+	format    exportFormat
+	cursor    string
+	createdAt time.Time
+}
+
+// ExportStore keeps the bodies of the exports of the admin API on disk for a while, so that an
+// interrupted download is resumed with a Range request without exporting the families again.
+// Each export is identified by a random ID, its ETag, and is resumed only by callers of the
+// tenant that exported it.
+type ExportStore struct {
+	dir       string
+	retention time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	exports map[string]storedExport
+	pending map[string]string
+}
+
+// NewExportStore creates a store that keeps exports for the retention period in a new
+// directory below dir, the system temporary directory if it is empty
+func NewExportStore(dir string, retention time.Duration) (*ExportStore, error) {
+	path, err := os.MkdirTemp(dir, "family-exports-")
+	if err != nil {
+		return nil, err
+	}
+	return &ExportStore{
+		dir:       path,
+		retention: retention,
+		now:       time.Now,
+		exports:   make(map[string]storedExport),
+		pending:   make(map[string]string),
+	}, nil
+}
+
+// create creates the file of a new export and returns it with the ID of the export. It
+// removes the expired exports first.
+func (s *ExportStore) create() (*os.File, string, error) {
+	s.sweep()
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	file, err := os.CreateTemp(s.dir, "export-*")
+	if err != nil {
+		return nil, "", err
+	}
+
+	id := hex.EncodeToString(random)
+	s.mu.Lock()
+	s.pending[id] = file.Name()
+	s.mu.Unlock()
+	return file, id, nil
+}
+
+// keep keeps a completed export until the end of the retention period
+func (s *ExportStore) keep(id, tenant string, format exportFormat, cursor string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, ok := s.pending[id]
+	if !ok {
+		return
+	}
+	delete(s.pending, id)
+	s.exports[id] = storedExport{path: path, tenant: tenant, format: format, cursor: cursor, createdAt: s.now()}
+}
+
+// discard removes an export that failed
+func (s *ExportStore) discard(id string) {
+	s.mu.Lock()
+	path, ok := s.pending[id]
+	delete(s.pending, id)
+	s.mu.Unlock()
+	if ok {
+		_ = os.Remove(path)
+	}
+}
+
+// open opens the kept export with the given ID, if it has not expired and was exported by
+// the tenant
+func (s *ExportStore) open(id, tenant string) (*os.File, storedExport, bool) {
+	s.mu.Lock()
+	export, ok := s.exports[id]
+	s.mu.Unlock()
+	if !ok || export.tenant != tenant || s.expired(export) {
+		return nil, storedExport{}, false
+	}
+	file, err := os.Open(export.path)
+	if err != nil {
+		return nil, storedExport{}, false
+	}
+	return file, export, true
+}
+
+// expired reports whether the retention period of an export has ended
+func (s *ExportStore) expired(export storedExport) bool {
+	return !s.now().Before(export.createdAt.Add(s.retention))
+}
+
+// sweep removes the expired exports. A download that has opened one reads it to the end.
+func (s *ExportStore) sweep() {
+	s.mu.Lock()
+	var expired []string
+	for id, export := range s.exports {
+		if s.expired(export) {
+			expired = append(expired, export.path)
+			delete(s.exports, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, path := range expired {
+		_ = os.Remove(path)
+	}
+}
+
+// Close removes the directory of the store and every export in it
+func (s *ExportStore) Close(_ context.Context) error {
+	s.mu.Lock()
+	s.exports = make(map[string]storedExport)
+	s.pending = make(map[string]string)
+	s.mu.Unlock()
+	return os.RemoveAll(s.dir)
+}
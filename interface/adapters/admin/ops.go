@@ -0,0 +1,472 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package admin serves the admin API, which exposes safe runbook actions to operators.
+//
+// The actions are served below the admin path:
+//
+//	GET  /               Lists the actions and the state of the replica
+//	POST /cache/flush    Flushes the cache of every replica
+//	POST /keys/rotate    Rotates the JWT validation keys from the secret key file
+//	GET  /reindex        Reports the state of the last reindex
+//	POST /reindex        Creates the missing indexes of the database in the background
+//	GET  /maintenance    Reports the maintenance mode
+//	POST /maintenance    Turns the maintenance mode on or off
+//	GET  /config         Returns the effective configuration, with secrets redacted
+//	GET  /tenants        Reports the health of the dedicated database of each routed tenant
+//	GET  /captures       Downloads the captured GraphQL operations
+//	DELETE /captures     Clears the captured GraphQL operations
+//	GET  /export         Streams every family as JSON Lines or, with ?format=csv, as CSV, in
+//	                     chunks that resume after a family with ?after=ID&limit=N, or from
+//	                     a byte with a Range request for a stored export
+//	GET  /outbox         Lists the messages at the head of the event outbox, with their
+//	                     personal data redacted, filtered with ?limit=N&type=TYPE
+//	GET  /outbox/dead-letters  Lists the messages set aside from the outbox, likewise
+//	POST /outbox/dead-letters  Sets messages of the outbox aside, such as poison messages
+//	                     that block the relay
+//	POST /outbox/replay  Moves dead letters back to the end of the outbox
+//	POST /outbox/purge   Removes dead letters for good
+//
+// Every action requires an authenticated caller with the ADMIN role and the ops scope of
+// the action, and is written to the audit log with its outcome, including when it is
+// denied. Actions apply to the replica that serves the request, except the cache flush,
+// which is broadcast to the other replicas over the cache invalidation bus.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/migration"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/tenancy"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/authz"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/capture"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/maintenance"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+)
+
+// Outcomes of the audited actions
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	OutcomeDenied    = "denied"
+)
+
+// Error codes of the admin API, in addition to those of the authorizer
+const (
+	// CodeUnavailable is the error code of an action this replica cannot take, for example
+	// flushing the cache when caching is disabled
+	CodeUnavailable = "UNAVAILABLE"
+
+	// CodeConflict is the error code of an action refused because of the state of the
+	// replica, for example a reindex while another one is running
+	CodeConflict = "CONFLICT"
+
+	// CodeBadRequest is the error code of a request with an invalid body
+	CodeBadRequest = "BAD_REQUEST"
+
+	// CodeFailed is the error code of an action that failed
+	CodeFailed = "FAILED"
+)
+
+// roles are the roles allowed to take the actions
+var roles = []string{"ADMIN"}
+
+// Cache is the cache flushed by the admin API
+type Cache interface {
+	// Flush empties the cache of every replica and reports whether the other replicas were told
+	Flush(ctx context.Context) bool
+}
+
+// Keys are the JWT validation keys rotated by the admin API
+type Keys interface {
+	// Rotate makes the key of the secret key file the current key
+	Rotate(ctx context.Context) error
+
+	// RotatedAt returns the time the keys were last rotated, or the zero time
+	RotatedAt() time.Time
+}
+
+// Configuration is the effective configuration dumped by the admin API
+type Configuration interface {
+	// Redact returns the configuration with its secrets redacted
+	Redact() map[string]interface{}
+}
+
+// Tenants are the dedicated tenant databases whose health the admin API reports
+type Tenants interface {
+	// Health returns the health of the dedicated database of each routed tenant
+	Health() []tenancy.TenantHealth
+}
+
+// Captures are the captured GraphQL operations downloaded through the admin API
+type Captures interface {
+	// Captures returns the captured operations, oldest first
+	Captures() []capture.Capture
+
+	// Clear removes the captured operations and returns their number
+	Clear() int
+}
+
+// Families are the families exported by the admin API
+type Families interface {
+	// ExportFamilies calls fn with every family whose ID sorts after the given ID, in
+	// ascending ID order, without holding them all in memory, and returns the number of
+	// families exported. An empty after exports every family.
+	ExportFamilies(ctx context.Context, after string, fn func(*entity.FamilyDTO) error) (int, error)
+}
+
+// Outbox is the event outbox whose messages the admin API lists, sets aside, replays, and
+// purges
+type Outbox interface {
+	ports.OutboxAdministrator
+}
+
+// Authorizer authorizes the callers of the admin API
+type Authorizer interface {
+	AuthorizeScope(ctx context.Context, action string, allowedRoles []string, scope authz.Scope) error
+}
+
+// Auditor writes the audit entries of the actions
+type Auditor interface {
+	Action(ctx context.Context, action, outcome string, details ...zap.Field)
+}
+
+// Dependencies are what the actions act on. The cache, the schema, the tenants, the
+// captures, the outbox, and the exports are nil when this replica has no cache, its
+// repository has no schema to reindex, it does not route tenants to dedicated databases, it
+// does not capture operations, it does not store events in an outbox, or it does not keep
+// exports for their downloads to be resumed.
+type Dependencies struct {
+	Cache       Cache
+	Keys        Keys
+	Schema      migration.Planner
+	Tenants     Tenants
+	Captures    Captures
+	Families    Families
+	Outbox      Outbox
+	Exports     *ExportStore
+	Maintenance *maintenance.Mode
+	Config      Configuration
+	Authorizer  Authorizer
+	Audit       Auditor
+	Logger      *zap.Logger
+}
+
+// Action describes an action of the admin API
+type Action struct {
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Scope  string `json:"scope"`
+}
+
+// ReindexState is the state of the last reindex of this replica
+type ReindexState struct {
+	Running    bool      `json:"running"`
+	StartedAt  time.Time `json:"startedAt,omitempty"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// route is an action served by the handler
+type route struct {
+	Action
+	serve func(w http.ResponseWriter, r *http.Request) (outcome string)
+}
+
+// Handler serves the admin API
+type Handler struct {
+	path   string
+	deps   Dependencies
+	routes []route
+
+	mu      sync.Mutex
+	reindex ReindexState
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// NewHandler creates the handler of the admin API served below a path
+func NewHandler(path string, deps Dependencies) *Handler {
+	if deps.Logger == nil {
+		deps.Logger = zap.NewNop()
+	}
+	h := &Handler{path: strings.TrimSuffix(path, "/"), deps: deps}
+	h.routes = []route{
+		{Action{"status", http.MethodGet, "/", string(authz.ScopeOpsConfig)}, h.status},
+		{Action{"cache.flush", http.MethodPost, "/cache/flush", string(authz.ScopeOpsCache)}, h.flushCache},
+		{Action{"keys.rotate", http.MethodPost, "/keys/rotate", string(authz.ScopeOpsKeys)}, h.rotateKeys},
+		{Action{"reindex.status", http.MethodGet, "/reindex", string(authz.ScopeOpsReindex)}, h.reindexStatus},
+		{Action{"reindex", http.MethodPost, "/reindex", string(authz.ScopeOpsReindex)}, h.startReindex},
+		{Action{"maintenance.status", http.MethodGet, "/maintenance", string(authz.ScopeOpsMaintenance)}, h.maintenanceStatus},
+		{Action{"maintenance.set", http.MethodPost, "/maintenance", string(authz.ScopeOpsMaintenance)}, h.setMaintenance},
+		{Action{"config.dump", http.MethodGet, "/config", string(authz.ScopeOpsConfig)}, h.dumpConfig},
+		{Action{"tenants.health", http.MethodGet, "/tenants", string(authz.ScopeOpsConfig)}, h.tenantHealth},
+		{Action{"captures.download", http.MethodGet, "/captures", string(authz.ScopeOpsCapture)}, h.downloadCaptures},
+		{Action{"captures.clear", http.MethodDelete, "/captures", string(authz.ScopeOpsCapture)}, h.clearCaptures},
+		{Action{"families.export", http.MethodGet, "/export", string(authz.ScopeExportRun)}, h.exportFamilies},
+		{Action{"outbox.list", http.MethodGet, "/outbox", string(authz.ScopeOpsOutbox)}, h.listOutbox},
+		{Action{"outbox.deadletters", http.MethodGet, "/outbox/dead-letters", string(authz.ScopeOpsOutbox)}, h.listDeadLetters},
+		{Action{"outbox.deadletter", http.MethodPost, "/outbox/dead-letters", string(authz.ScopeOpsOutbox)}, h.deadLetterOutbox},
+		{Action{"outbox.replay", http.MethodPost, "/outbox/replay", string(authz.ScopeOpsOutbox)}, h.replayDeadLetters},
+		{Action{"outbox.purge", http.MethodPost, "/outbox/purge", string(authz.ScopeOpsOutbox)}, h.purgeDeadLetters},
+	}
+	return h
+}
+
+// Path returns the path the admin API is served below
+func (h *Handler) Path() string {
+	return h.path
+}
+
+// ServeHTTP authorizes the caller, takes the action of the request, and audits it
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sub := strings.TrimPrefix(r.URL.Path, h.path)
+	if sub == "" {
+		sub = "/"
+	}
+
+	var allowed []string
+	for _, rt := range h.routes {
+		if rt.Path != sub {
+			continue
+		}
+		if rt.Method != r.Method {
+			allowed = append(allowed, rt.Method)
+			continue
+		}
+
+		if err := h.deps.Authorizer.AuthorizeScope(r.Context(), rt.Name, roles, authz.Scope(rt.Scope)); err != nil {
+			h.deps.Audit.Action(r.Context(), rt.Name, OutcomeDenied, zap.Error(err))
+			deny(w, err)
+			return
+		}
+		h.deps.Audit.Action(r.Context(), rt.Name, rt.serve(w, r))
+		return
+	}
+
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, http.StatusMethodNotAllowed, CodeBadRequest, http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// Stop cancels a running reindex and waits for it to end
+func (h *Handler) Stop(ctx context.Context) error {
+	h.mu.Lock()
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// status lists the actions and the state of the replica
+func (h *Handler) status(w http.ResponseWriter, r *http.Request) string {
+	actions := make([]Action, 0, len(h.routes))
+	for _, rt := range h.routes {
+		actions = append(actions, rt.Action)
+	}
+
+	status := map[string]interface{}{
+		"actions":     actions,
+		"maintenance": h.deps.Maintenance.State(),
+		"reindex":     h.reindexState(),
+	}
+	if rotatedAt := h.deps.Keys.RotatedAt(); !rotatedAt.IsZero() {
+		status["keysRotatedAt"] = rotatedAt
+	}
+	writeJSON(w, http.StatusOK, status)
+	return OutcomeSucceeded
+}
+
+// flushCache flushes the cache of every replica
+func (h *Handler) flushCache(w http.ResponseWriter, r *http.Request) string {
+	if h.deps.Cache == nil {
+		writeError(w, http.StatusNotImplemented, CodeUnavailable, "caching is disabled")
+		return OutcomeFailed
+	}
+
+	broadcast := h.deps.Cache.Flush(r.Context())
+	writeJSON(w, http.StatusOK, map[string]interface{}{"flushed": true, "broadcast": broadcast})
+	return OutcomeSucceeded
+}
+
+// rotateKeys rotates the JWT validation keys
+func (h *Handler) rotateKeys(w http.ResponseWriter, r *http.Request) string {
+	if err := h.deps.Keys.Rotate(r.Context()); err != nil {
+		h.deps.Logger.Error("Failed to rotate the JWT validation keys", zap.Error(err))
+		writeError(w, http.StatusConflict, CodeConflict, err.Error())
+		return OutcomeFailed
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"rotatedAt": h.deps.Keys.RotatedAt()})
+	return OutcomeSucceeded
+}
+
+// reindexStatus reports the state of the last reindex
+func (h *Handler) reindexStatus(w http.ResponseWriter, r *http.Request) string {
+	writeJSON(w, http.StatusOK, h.reindexState())
+	return OutcomeSucceeded
+}
+
+// startReindex creates the missing indexes of the database in the background
+func (h *Handler) startReindex(w http.ResponseWriter, r *http.Request) string {
+	if h.deps.Schema == nil {
+		writeError(w, http.StatusNotImplemented, CodeUnavailable, "the repository has no schema to reindex")
+		return OutcomeFailed
+	}
+
+	h.mu.Lock()
+	if h.reindex.Running {
+		h.mu.Unlock()
+		writeError(w, http.StatusConflict, CodeConflict, "a reindex is already running")
+		return OutcomeFailed
+	}
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	h.reindex = ReindexState{Running: true, StartedAt: time.Now()}
+	h.cancel = cancel
+	h.running.Add(1)
+	state := h.reindex
+	h.mu.Unlock()
+
+	go h.runReindex(ctx)
+
+	writeJSON(w, http.StatusAccepted, state)
+	return OutcomeSucceeded
+}
+
+// runReindex applies the schema of the repository and audits the result
+func (h *Handler) runReindex(ctx context.Context) {
+	defer h.running.Done()
+
+	err := h.deps.Schema.ApplySchema(ctx)
+
+	h.mu.Lock()
+	h.cancel()
+	h.cancel = nil
+	h.reindex.Running = false
+	h.reindex.FinishedAt = time.Now()
+	duration := h.reindex.FinishedAt.Sub(h.reindex.StartedAt)
+	if err != nil {
+		h.reindex.Error = err.Error()
+	}
+	h.mu.Unlock()
+
+	if err != nil {
+		h.deps.Logger.Error("Reindex failed", zap.Error(err))
+		h.deps.Audit.Action(ctx, "reindex.completed", OutcomeFailed, zap.Duration("duration", duration), zap.Error(err))
+		return
+	}
+	h.deps.Audit.Action(ctx, "reindex.completed", OutcomeSucceeded, zap.Duration("duration", duration))
+}
+
+// reindexState returns the state of the last reindex
+func (h *Handler) reindexState() ReindexState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.reindex
+}
+
+// maintenanceStatus reports the maintenance mode
+func (h *Handler) maintenanceStatus(w http.ResponseWriter, r *http.Request) string {
+	writeJSON(w, http.StatusOK, h.deps.Maintenance.State())
+	return OutcomeSucceeded
+}
+
+// setMaintenance turns the maintenance mode on or off
+func (h *Handler) setMaintenance(w http.ResponseWriter, r *http.Request) string {
+	var body struct {
+		Enabled *bool  `json:"enabled"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil || body.Enabled == nil {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, `the body must be {"enabled": true|false, "reason": "..."}`)
+		return OutcomeFailed
+	}
+
+	writeJSON(w, http.StatusOK, h.deps.Maintenance.Set(*body.Enabled, body.Reason))
+	return OutcomeSucceeded
+}
+
+// dumpConfig returns the effective configuration with its secrets redacted
+func (h *Handler) dumpConfig(w http.ResponseWriter, r *http.Request) string {
+	writeJSON(w, http.StatusOK, h.deps.Config.Redact())
+	return OutcomeSucceeded
+}
+
+// tenantHealth reports the health of the dedicated database of each routed tenant
+func (h *Handler) tenantHealth(w http.ResponseWriter, r *http.Request) string {
+	if h.deps.Tenants == nil {
+		writeError(w, http.StatusNotImplemented, CodeUnavailable, "tenant routing is disabled")
+		return OutcomeFailed
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tenants": h.deps.Tenants.Health()})
+	return OutcomeSucceeded
+}
+
+// downloadCaptures returns the captured GraphQL operations as an attachment
+func (h *Handler) downloadCaptures(w http.ResponseWriter, r *http.Request) string {
+	if h.deps.Captures == nil {
+		writeError(w, http.StatusNotImplemented, CodeUnavailable, "operation capture is disabled")
+		return OutcomeFailed
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="captures.json"`)
+	writeJSON(w, http.StatusOK, capture.Download{Captures: h.deps.Captures.Captures()})
+	return OutcomeSucceeded
+}
+
+// clearCaptures removes the captured GraphQL operations
+func (h *Handler) clearCaptures(w http.ResponseWriter, r *http.Request) string {
+	if h.deps.Captures == nil {
+		writeError(w, http.StatusNotImplemented, CodeUnavailable, "operation capture is disabled")
+		return OutcomeFailed
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cleared": h.deps.Captures.Clear()})
+	return OutcomeSucceeded
+}
+
+// deny writes the response of a caller the authorizer refused
+func deny(w http.ResponseWriter, err error) {
+	code, status := authz.CodeForbidden, http.StatusForbidden
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) && gqlErr.Extensions["code"] == authz.CodeUnauthenticated {
+		code, status = authz.CodeUnauthenticated, http.StatusUnauthorized
+	}
+	writeError(w, status, code, err.Error())
+}
+
+// writeError writes an error response with an error code
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{"code": code, "message": message},
+	})
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
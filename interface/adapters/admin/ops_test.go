@@ -0,0 +1,674 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/migration"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/tenancy"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/authz"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/capture"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/maintenance"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type stubCache struct{ flushes int }
+
+func (c *stubCache) Flush(context.Context) bool {
+	c.flushes++
+	return true
+}
+
+type stubKeys struct {
+	err       error
+	rotatedAt time.Time
+}
+
+func (k *stubKeys) Rotate(context.Context) error {
+	if k.err == nil {
+		k.rotatedAt = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	}
+	return k.err
+}
+
+func (k *stubKeys) RotatedAt() time.Time { return k.rotatedAt }
+
+// stubSchema blocks ApplySchema until it is released
+type stubSchema struct {
+	release chan struct{}
+	err     error
+}
+
+func (s *stubSchema) PlanSchema(context.Context) (*migration.Plan, error) {
+	return &migration.Plan{}, nil
+}
+
+func (s *stubSchema) ApplySchema(ctx context.Context) error {
+	select {
+	case <-s.release:
+		return s.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type stubTenants []tenancy.TenantHealth
+
+func (t stubTenants) Health() []tenancy.TenantHealth { return t }
+
+type stubConfig map[string]interface{}
+
+func (c stubConfig) Redact() map[string]interface{} { return c }
+
+// stubFamilies exports its families, in ascending ID order, failing with err after
+// failAfter of them if err is set
+type stubFamilies struct {
+	families  []*entity.FamilyDTO
+	failAfter int
+	err       error
+}
+
+func (f *stubFamilies) ExportFamilies(_ context.Context, after string, fn func(*entity.FamilyDTO) error) (int, error) {
+	count := 0
+	for _, family := range f.families {
+		if family.ID <= after {
+			continue
+		}
+		if f.err != nil && count == f.failAfter {
+			return count, f.err
+		}
+		if err := fn(family); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if f.err != nil {
+		return count, f.err
+	}
+	return count, nil
+}
+
+// stubOutbox keeps its messages and dead letters in memory
+type stubOutbox struct {
+	pending []ports.OutboxMessage
+	dead    []ports.DeadLetter
+}
+
+func (o *stubOutbox) OutboxMessages(_ context.Context, eventType string, limit int) ([]ports.OutboxMessage, error) {
+	messages := []ports.OutboxMessage{}
+	for _, message := range o.pending {
+		if len(messages) < limit && (eventType == "" || message.Type == eventType) {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+func (o *stubOutbox) DeadLetterOutboxMessages(_ context.Context, ids []string, reason string, at time.Time) (int, error) {
+	kept := []ports.OutboxMessage{}
+	for _, message := range o.pending {
+		if contains(ids, message.ID) {
+			o.dead = append(o.dead, ports.DeadLetter{OutboxMessage: message, Reason: reason, DeadLetteredAt: at})
+		} else {
+			kept = append(kept, message)
+		}
+	}
+	moved := len(o.pending) - len(kept)
+	o.pending = kept
+	return moved, nil
+}
+
+func (o *stubOutbox) DeadLetters(_ context.Context, eventType string, limit int) ([]ports.DeadLetter, error) {
+	letters := []ports.DeadLetter{}
+	for _, letter := range o.dead {
+		if len(letters) < limit && (eventType == "" || letter.Type == eventType) {
+			letters = append(letters, letter)
+		}
+	}
+	return letters, nil
+}
+
+func (o *stubOutbox) ReplayDeadLetters(_ context.Context, ids []string) (int, error) {
+	return o.removeDeadLetters(ids, true), nil
+}
+
+func (o *stubOutbox) PurgeDeadLetters(_ context.Context, ids []string) (int, error) {
+	return o.removeDeadLetters(ids, false), nil
+}
+
+func (o *stubOutbox) removeDeadLetters(ids []string, replay bool) int {
+	kept := []ports.DeadLetter{}
+	for _, letter := range o.dead {
+		switch {
+		case !contains(ids, letter.ID):
+			kept = append(kept, letter)
+		case replay:
+			o.pending = append(o.pending, letter.OutboxMessage)
+		}
+	}
+	removed := len(o.dead) - len(kept)
+	o.dead = kept
+	return removed
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// entry is an audit entry recorded by recordingAuditor
+type entry struct {
+	action, outcome, actor string
+}
+
+type recordingAuditor struct {
+	mu      sync.Mutex
+	entries []entry
+}
+
+func (a *recordingAuditor) Action(ctx context.Context, action, outcome string, _ ...zap.Field) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	actor, _ := middleware.GetUserID(ctx)
+	a.entries = append(a.entries, entry{action, outcome, actor})
+}
+
+func (a *recordingAuditor) last() entry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.entries[len(a.entries)-1]
+}
+
+type fixture struct {
+	handler  *Handler
+	cache    *stubCache
+	keys     *stubKeys
+	schema   *stubSchema
+	captures *capture.Recorder
+	families *stubFamilies
+	outbox   *stubOutbox
+	mode     *maintenance.Mode
+	audit    *recordingAuditor
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		cache:    &stubCache{},
+		keys:     &stubKeys{},
+		schema:   &stubSchema{release: make(chan struct{})},
+		captures: capture.NewRecorder(10, nil),
+		families: &stubFamilies{families: exportedFamilies()},
+		outbox:   &stubOutbox{},
+		mode:     maintenance.NewMode(),
+		audit:    &recordingAuditor{},
+	}
+	f.handler = NewHandler("/admin/ops/", Dependencies{
+		Cache:       f.cache,
+		Keys:        f.keys,
+		Schema:      f.schema,
+		Tenants:     stubTenants{{Tenant: "tenant-a", Datasource: "dedicated", Open: true, Healthy: true, CheckedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}},
+		Captures:    f.captures,
+		Families:    f.families,
+		Outbox:      f.outbox,
+		Maintenance: f.mode,
+		Config:      stubConfig{"auth": map[string]interface{}{"jwt": map[string]interface{}{"secret_key": "REDACTED"}}},
+		Authorizer:  authz.NewAuthorizer(true),
+		Audit:       f.audit,
+	})
+	return f
+}
+
+// do serves a request from a caller holding the scopes; without scopes, the caller is anonymous
+func (f *fixture) do(method, path, body string, scopes ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if len(scopes) > 0 {
+		ctx := middleware.WithUserID(req.Context(), "operator")
+		ctx = middleware.WithUserRoles(ctx, []string{"ADMIN"})
+		ctx = middleware.WithUserScopes(ctx, scopes)
+		req = req.WithContext(ctx)
+	}
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body
+}
+
+func TestHandler_Authorization(t *testing.T) {
+	f := newFixture()
+
+	rec := f.do(http.MethodPost, "/admin/ops/cache/flush", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, entry{"cache.flush", OutcomeDenied, ""}, f.audit.last())
+
+	rec = f.do(http.MethodPost, "/admin/ops/cache/flush", "", string(authz.ScopeOpsConfig))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, authz.CodeForbidden, decode(t, rec)["error"].(map[string]interface{})["code"])
+	assert.Equal(t, entry{"cache.flush", OutcomeDenied, "operator"}, f.audit.last())
+	assert.Zero(t, f.cache.flushes)
+
+	rec = f.do(http.MethodPost, "/admin/ops/cache/flush", "", string(authz.ScopeOpsCache))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, map[string]interface{}{"flushed": true, "broadcast": true}, decode(t, rec))
+	assert.Equal(t, entry{"cache.flush", OutcomeSucceeded, "operator"}, f.audit.last())
+	assert.Equal(t, 1, f.cache.flushes)
+}
+
+func TestHandler_Routing(t *testing.T) {
+	f := newFixture()
+
+	rec := f.do(http.MethodGet, "/admin/ops/cache/flush", "", string(authz.ScopeOpsCache))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+
+	rec = f.do(http.MethodGet, "/admin/ops/unknown", "", string(authz.ScopeOpsConfig))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = f.do(http.MethodGet, "/admin/ops", "", string(authz.ScopeOpsConfig))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, decode(t, rec)["actions"], len(f.handler.routes))
+}
+
+func TestHandler_RotateKeys(t *testing.T) {
+	f := newFixture()
+
+	rec := f.do(http.MethodPost, "/admin/ops/keys/rotate", "", string(authz.ScopeOpsKeys))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2025-01-02T03:04:05Z", decode(t, rec)["rotatedAt"])
+
+	f.keys.err = errors.New("the secret key file holds the current key")
+	rec = f.do(http.MethodPost, "/admin/ops/keys/rotate", "", string(authz.ScopeOpsKeys))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, entry{"keys.rotate", OutcomeFailed, "operator"}, f.audit.last())
+}
+
+func TestHandler_Reindex(t *testing.T) {
+	f := newFixture()
+
+	rec := f.do(http.MethodPost, "/admin/ops/reindex", "", string(authz.ScopeOpsReindex))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, true, decode(t, rec)["running"])
+
+	rec = f.do(http.MethodPost, "/admin/ops/reindex", "", string(authz.ScopeOpsReindex))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	close(f.schema.release)
+	assert.Eventually(t, func() bool { return !f.handler.reindexState().Running }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return f.audit.last() == entry{"reindex.completed", OutcomeSucceeded, "operator"}
+	}, time.Second, time.Millisecond)
+
+	rec = f.do(http.MethodGet, "/admin/ops/reindex", "", string(authz.ScopeOpsReindex))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := decode(t, rec)
+	assert.Equal(t, false, body["running"])
+	assert.NotContains(t, body, "error")
+}
+
+func TestHandler_StopCancelsReindex(t *testing.T) {
+	f := newFixture()
+
+	rec := f.do(http.MethodPost, "/admin/ops/reindex", "", string(authz.ScopeOpsReindex))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	require.NoError(t, f.handler.Stop(context.Background()))
+	state := f.handler.reindexState()
+	assert.False(t, state.Running)
+	assert.Equal(t, context.Canceled.Error(), state.Error)
+}
+
+func TestHandler_Maintenance(t *testing.T) {
+	f := newFixture()
+
+	rec := f.do(http.MethodPost, "/admin/ops/maintenance", `{"reason":"missing enabled"}`, string(authz.ScopeOpsMaintenance))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = f.do(http.MethodPost, "/admin/ops/maintenance", `{"enabled":true,"reason":"reindexing"}`, string(authz.ScopeOpsMaintenance))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, f.mode.State().Enabled)
+	assert.Equal(t, "reindexing", f.mode.State().Reason)
+
+	rec = f.do(http.MethodGet, "/admin/ops/maintenance", "", string(authz.ScopeOpsMaintenance))
+	assert.Equal(t, true, decode(t, rec)["enabled"])
+
+	f.do(http.MethodPost, "/admin/ops/maintenance", `{"enabled":false}`, string(authz.ScopeOpsMaintenance))
+	assert.False(t, f.mode.State().Enabled)
+}
+
+func TestHandler_DumpConfig(t *testing.T) {
+	f := newFixture()
+
+	rec := f.do(http.MethodGet, "/admin/ops/config", "", string(authz.ScopeOpsConfig))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"auth":{"jwt":{"secret_key":"REDACTED"}}}`, rec.Body.String())
+	assert.Equal(t, entry{"config.dump", OutcomeSucceeded, "operator"}, f.audit.last())
+}
+
+func TestHandler_TenantHealth(t *testing.T) {
+	f := newFixture()
+
+	rec := f.do(http.MethodGet, "/admin/ops/tenants", "", string(authz.ScopeOpsConfig))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"tenants":[{"tenant":"tenant-a","datasource":"dedicated","open":true,"healthy":true,"checkedAt":"2025-01-02T03:04:05Z"}]}`, rec.Body.String())
+	assert.Equal(t, entry{"tenants.health", OutcomeSucceeded, "operator"}, f.audit.last())
+}
+
+func TestHandler_Captures(t *testing.T) {
+	f := newFixture()
+	f.captures.Record(capture.Capture{Operation: "GetFamily", Type: "query", Query: "query GetFamily { getFamily(id: \"family-1\") { id } }"})
+
+	// Captures require their own scope
+	assert.Equal(t, http.StatusForbidden, f.do(http.MethodGet, "/admin/ops/captures", "", string(authz.ScopeOpsConfig)).Code)
+
+	rec := f.do(http.MethodGet, "/admin/ops/captures", "", string(authz.ScopeOpsCapture))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment")
+	captures, err := capture.ReadCaptures(rec.Body)
+	require.NoError(t, err)
+	require.Len(t, captures, 1)
+	assert.Equal(t, "GetFamily", captures[0].Operation)
+	assert.Equal(t, entry{"captures.download", OutcomeSucceeded, "operator"}, f.audit.last())
+
+	rec = f.do(http.MethodDelete, "/admin/ops/captures", "", string(authz.ScopeOpsCapture))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"cleared":1}`, rec.Body.String())
+	assert.Empty(t, f.captures.Captures())
+}
+
+func TestHandler_Unavailable(t *testing.T) {
+	handler := NewHandler("/admin/ops", Dependencies{
+		Keys:        &stubKeys{},
+		Maintenance: maintenance.NewMode(),
+		Authorizer:  authz.NewAuthorizer(true),
+		Audit:       &recordingAuditor{},
+	})
+	f := &fixture{handler: handler}
+
+	assert.Equal(t, http.StatusNotImplemented, f.do(http.MethodPost, "/admin/ops/cache/flush", "", string(authz.ScopeOpsCache)).Code)
+	assert.Equal(t, http.StatusNotImplemented, f.do(http.MethodPost, "/admin/ops/reindex", "", string(authz.ScopeOpsReindex)).Code)
+	assert.Equal(t, http.StatusNotImplemented, f.do(http.MethodGet, "/admin/ops/tenants", "", string(authz.ScopeOpsConfig)).Code)
+	assert.Equal(t, http.StatusNotImplemented, f.do(http.MethodGet, "/admin/ops/captures", "", string(authz.ScopeOpsCapture)).Code)
+	assert.Equal(t, http.StatusNotImplemented, f.do(http.MethodGet, "/admin/ops/export", "", string(authz.ScopeExportRun)).Code)
+	assert.Equal(t, http.StatusNotImplemented, f.do(http.MethodGet, "/admin/ops/outbox", "", string(authz.ScopeOpsOutbox)).Code)
+	assert.Equal(t, http.StatusNotImplemented, f.do(http.MethodPost, "/admin/ops/outbox/replay", `{"ids":["m1"]}`, string(authz.ScopeOpsOutbox)).Code)
+}
+
+func TestHandler_Outbox(t *testing.T) {
+	f := newFixture()
+	createdAt := time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC)
+	f.outbox.pending = []ports.OutboxMessage{
+		{ID: "m1", Type: "family_quarantined", CreatedAt: createdAt, Payload: []byte(`{"id":"m1","type":"family_quarantined","version":1,"producer":"family-service",` +
+			`"payload":{"familyId":"family-1","reason":"Jane Doe reported fraud","children":[{"childIds":["c1"],"name":"Ann"}]}}`)},
+		{ID: "m2", Type: "family_deleted", CreatedAt: createdAt, Payload: []byte(`{"payload":{"familyId":"family-2","hard":true}}`)},
+		{ID: "m3", Type: "family_deleted", CreatedAt: createdAt, Payload: []byte(`not json`)},
+	}
+
+	// The outbox requires its own scope
+	assert.Equal(t, http.StatusForbidden, f.do(http.MethodGet, "/admin/ops/outbox", "", string(authz.ScopeOpsConfig)).Code)
+
+	// Listed payloads keep IDs, numbers, booleans, and the envelope, and redact other strings
+	rec := f.do(http.MethodGet, "/admin/ops/outbox?type=family_quarantined", "", string(authz.ScopeOpsOutbox))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"messages":[{"id":"m1","type":"family_quarantined","createdAt":"2025-03-03T14:00:00Z",`+
+		`"payload":{"id":"m1","type":"family_quarantined","version":1,"producer":"family-service",`+
+		`"payload":{"familyId":"family-1","reason":"REDACTED","children":[{"childIds":["c1"],"name":"REDACTED"}]}}}]}`, rec.Body.String())
+	assert.Equal(t, entry{"outbox.list", OutcomeSucceeded, "operator"}, f.audit.last())
+
+	rec = f.do(http.MethodGet, "/admin/ops/outbox?limit=3&type=family_deleted", "", string(authz.ScopeOpsOutbox))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	messages := decode(t, rec)["messages"].([]interface{})
+	require.Len(t, messages, 2)
+	assert.Equal(t, map[string]interface{}{"payload": map[string]interface{}{"familyId": "family-2", "hard": true}}, messages[0].(map[string]interface{})["payload"])
+	assert.Equal(t, "REDACTED", messages[1].(map[string]interface{})["payload"])
+	assert.Contains(t, messages[1].(map[string]interface{})["payloadError"], "not JSON")
+	assert.Equal(t, http.StatusBadRequest, f.do(http.MethodGet, "/admin/ops/outbox?limit=0", "", string(authz.ScopeOpsOutbox)).Code)
+
+	// The limit counts only the messages of the type, which need not be at the head of the outbox
+	rec = f.do(http.MethodGet, "/admin/ops/outbox?limit=1&type=family_deleted", "", string(authz.ScopeOpsOutbox))
+	messages = decode(t, rec)["messages"].([]interface{})
+	require.Len(t, messages, 1)
+	assert.Equal(t, "m2", messages[0].(map[string]interface{})["id"])
+
+	// Poison messages are set aside with a reason
+	assert.Equal(t, http.StatusBadRequest, f.do(http.MethodPost, "/admin/ops/outbox/dead-letters", `{"ids":["m3"]}`, string(authz.ScopeOpsOutbox)).Code)
+	rec = f.do(http.MethodPost, "/admin/ops/outbox/dead-letters", `{"ids":["m1","m3"],"reason":"rejected by the broker"}`, string(authz.ScopeOpsOutbox))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"deadLettered":2}`, rec.Body.String())
+	assert.Equal(t, entry{"outbox.deadletter", OutcomeSucceeded, "operator"}, f.audit.last())
+
+	rec = f.do(http.MethodGet, "/admin/ops/outbox/dead-letters", "", string(authz.ScopeOpsOutbox))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	letters := decode(t, rec)["deadLetters"].([]interface{})
+	require.Len(t, letters, 2)
+	assert.Equal(t, "m1", letters[0].(map[string]interface{})["id"])
+	assert.Equal(t, "rejected by the broker", letters[0].(map[string]interface{})["reason"])
+	assert.NotEmpty(t, letters[0].(map[string]interface{})["deadLetteredAt"])
+	rec = f.do(http.MethodGet, "/admin/ops/outbox/dead-letters?limit=1&type=family_deleted", "", string(authz.ScopeOpsOutbox))
+	letters = decode(t, rec)["deadLetters"].([]interface{})
+	require.Len(t, letters, 1)
+	assert.Equal(t, "m3", letters[0].(map[string]interface{})["id"])
+
+	// Replayed messages go back to the end of the outbox, purged ones are gone
+	rec = f.do(http.MethodPost, "/admin/ops/outbox/replay", `{"ids":["m1"]}`, string(authz.ScopeOpsOutbox))
+	assert.JSONEq(t, `{"replayed":1}`, rec.Body.String())
+	rec = f.do(http.MethodPost, "/admin/ops/outbox/purge", `{"ids":["m3"]}`, string(authz.ScopeOpsOutbox))
+	assert.JSONEq(t, `{"purged":1}`, rec.Body.String())
+	assert.Equal(t, entry{"outbox.purge", OutcomeSucceeded, "operator"}, f.audit.last())
+	assert.Empty(t, f.outbox.dead)
+	require.Len(t, f.outbox.pending, 2)
+	assert.Equal(t, "m1", f.outbox.pending[1].ID)
+	assert.Equal(t, http.StatusBadRequest, f.do(http.MethodPost, "/admin/ops/outbox/purge", `{"ids":[]}`, string(authz.ScopeOpsOutbox)).Code)
+}
+
+// exportedFamilies are the families of the export tests
+func exportedFamilies() []*entity.FamilyDTO {
+	born := time.Date(1980, 1, 31, 0, 0, 0, 0, time.UTC)
+	died := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	return []*entity.FamilyDTO{
+		{
+			ID: "family-1", Status: "MARRIED", Version: 3,
+			Parents: []entity.ParentDTO{
+				{ID: "parent-1", FirstName: "John", LastName: "Doe", BirthDate: born},
+				{ID: "parent-2", FirstName: "Jane", LastName: "Doe, Jr.", BirthDate: born, DeathDate: &died},
+			},
+			Children: []entity.ChildDTO{{ID: "child-1", FirstName: "Jim", LastName: "Doe", PreferredName: "Jimmy", BirthDate: born.AddDate(25, 0, 0)}},
+		},
+		{
+			ID: "family-2", Status: "SINGLE", Version: 1,
+			Parents: []entity.ParentDTO{{ID: "parent-3", FirstName: "Ann", LastName: "Roe", BirthDate: born}},
+		},
+	}
+}
+
+func TestHandler_Export(t *testing.T) {
+	f := newFixture()
+
+	// The export requires its own scope
+	assert.Equal(t, http.StatusForbidden, f.do(http.MethodGet, "/admin/ops/export", "", string(authz.ScopeOpsConfig)).Code)
+
+	t.Run("json lines", func(t *testing.T) {
+		rec := f.do(http.MethodGet, "/admin/ops/export", "", string(authz.ScopeExportRun))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Header().Get("Content-Disposition"), `filename="families.jsonl"`)
+		assert.Empty(t, rec.Result().Trailer.Get(ExportErrorTrailer))
+
+		// Each line is a family DTO, which the data migration tool imports
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		for i, line := range lines {
+			var family entity.FamilyDTO
+			require.NoError(t, json.Unmarshal([]byte(line), &family))
+			assert.Equal(t, *f.families.families[i], family)
+		}
+		assert.Equal(t, entry{"families.export", OutcomeSucceeded, "operator"}, f.audit.last())
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		rec := f.do(http.MethodGet, "/admin/ops/export?format=csv", "", string(authz.ScopeExportRun))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, strings.Join([]string{
+			"family_id,family_status,family_version,member_type,member_id,first_name,last_name,preferred_name,birth_date,death_date",
+			"family-1,MARRIED,3,PARENT,parent-1,John,Doe,,1980-01-31T00:00:00Z,",
+			`family-1,MARRIED,3,PARENT,parent-2,Jane,"Doe, Jr.",,1980-01-31T00:00:00Z,2020-06-01T00:00:00Z`,
+			"family-1,MARRIED,3,CHILD,child-1,Jim,Doe,Jimmy,2005-01-31T00:00:00Z,",
+			"family-2,SINGLE,1,PARENT,parent-3,Ann,Roe,,1980-01-31T00:00:00Z,",
+		}, "\n")+"\n", rec.Body.String())
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		// A chunk that stops at its limit holds the cursor of the next chunk
+		rec := f.do(http.MethodGet, "/admin/ops/export?format=csv&limit=1", "", string(authz.ScopeExportRun))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "family-1", rec.Result().Trailer.Get(ExportCursorTrailer))
+		assert.Equal(t, 4, strings.Count(rec.Body.String(), "\n"), "the header row and the rows of the first family")
+		assert.Equal(t, entry{"families.export", OutcomeSucceeded, "operator"}, f.audit.last())
+
+		// The next chunk has no header row, and the last chunk has no cursor
+		rec = f.do(http.MethodGet, "/admin/ops/export?format=csv&limit=1&after=family-1", "", string(authz.ScopeExportRun))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Result().Trailer.Get(ExportCursorTrailer))
+		assert.Equal(t, "family-2,SINGLE,1,PARENT,parent-3,Ann,Roe,,1980-01-31T00:00:00Z,\n", rec.Body.String())
+
+		// A chunk that holds the last family exactly has no cursor either
+		rec = f.do(http.MethodGet, "/admin/ops/export?limit=2", "", string(authz.ScopeExportRun))
+		assert.Empty(t, rec.Result().Trailer.Get(ExportCursorTrailer))
+		assert.Equal(t, 2, strings.Count(rec.Body.String(), "\n"))
+
+		// A chunk after the last family is empty
+		rec = f.do(http.MethodGet, "/admin/ops/export?after=family-2", "", string(authz.ScopeExportRun))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		for _, limit := range []string{"0", "-1", "many"} {
+			rec := f.do(http.MethodGet, "/admin/ops/export?limit="+limit, "", string(authz.ScopeExportRun))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, limit)
+		}
+		assert.Equal(t, entry{"families.export", OutcomeFailed, "operator"}, f.audit.last())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		rec := f.do(http.MethodGet, "/admin/ops/export?format=xml", "", string(authz.ScopeExportRun))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, entry{"families.export", OutcomeFailed, "operator"}, f.audit.last())
+	})
+
+	t.Run("failure before the first family", func(t *testing.T) {
+		f.families.err, f.families.failAfter = errors.New("connection reset"), 0
+		defer func() { f.families.err = nil }()
+
+		rec := f.do(http.MethodGet, "/admin/ops/export", "", string(authz.ScopeExportRun))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, CodeFailed, decode(t, rec)["error"].(map[string]interface{})["code"])
+		assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("failure after the first family", func(t *testing.T) {
+		f.families.err, f.families.failAfter = errors.New("connection reset"), 1
+		defer func() { f.families.err = nil }()
+
+		rec := f.do(http.MethodGet, "/admin/ops/export", "", string(authz.ScopeExportRun))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 1, strings.Count(rec.Body.String(), "\n"))
+		assert.Equal(t, "the export failed after 1 families", rec.Result().Trailer.Get(ExportErrorTrailer))
+		assert.Equal(t, entry{"families.export", OutcomeFailed, "operator"}, f.audit.last())
+	})
+
+	t.Run("resumed downloads", func(t *testing.T) {
+		exports, err := NewExportStore(t.TempDir(), time.Hour)
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, exports.Close(context.Background())) }()
+		f.handler.deps.Exports = exports
+		defer func() { f.handler.deps.Exports = nil }()
+
+		// get serves a request for the first chunk of a CSV export from a caller of the tenant
+		get := func(w http.ResponseWriter, tenant string, headers map[string]string) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/ops/export?format=csv&limit=1", nil)
+			ctx := middleware.WithUserID(req.Context(), "operator")
+			ctx = middleware.WithUserRoles(ctx, []string{"ADMIN"})
+			ctx = middleware.WithUserScopes(ctx, []string{string(authz.ScopeExportRun)})
+			req = req.WithContext(servicecontext.WithTenantID(ctx, tenant))
+			for name, value := range headers {
+				req.Header.Set(name, value)
+			}
+			f.handler.ServeHTTP(w, req)
+		}
+
+		rec := httptest.NewRecorder()
+		get(rec, "acme", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "bytes", rec.Header().Get("Accept-Ranges"))
+		etag := rec.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		body := rec.Body.String()
+
+		// The rest of the stored export is served with its cursor, without exporting again
+		rec = httptest.NewRecorder()
+		get(rec, "acme", map[string]string{"Range": "bytes=10-", "If-Range": etag})
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, body[10:], rec.Body.String())
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "family-1", rec.Header().Get(ExportCursorTrailer))
+		assert.Equal(t, entry{"families.export", OutcomeSucceeded, "operator"}, f.audit.last())
+
+		// Another tenant, or an unknown ETag, gets a new export
+		for tenant, ifRange := range map[string]string{"globex": etag, "acme": `"unknown"`} {
+			rec = httptest.NewRecorder()
+			get(rec, tenant, map[string]string{"Range": "bytes=10-", "If-Range": ifRange})
+			assert.Equal(t, http.StatusOK, rec.Code, tenant)
+			assert.Equal(t, body, rec.Body.String(), tenant)
+			assert.NotEqual(t, etag, rec.Header().Get("ETag"), tenant)
+		}
+
+		// The export is stored to the end even if the download is interrupted
+		interrupted := httptest.NewRecorder()
+		get(interruptedWriter{interrupted}, "acme", nil)
+		rec = httptest.NewRecorder()
+		get(rec, "acme", map[string]string{"Range": "bytes=0-", "If-Range": interrupted.Header().Get("ETag")})
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, body, rec.Body.String())
+
+		// An expired export is exported again
+		exports.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		rec = httptest.NewRecorder()
+		get(rec, "acme", map[string]string{"Range": "bytes=10-", "If-Range": etag})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, body, rec.Body.String())
+	})
+}
+
+// interruptedWriter is a response writer whose client has gone away
+type interruptedWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (interruptedWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
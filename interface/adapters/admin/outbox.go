@@ -0,0 +1,242 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"go.uber.org/zap"
+)
+
+// Limits of the outbox actions
+const (
+	defaultOutboxLimit = 100  // Messages listed without a limit parameter
+	maxOutboxLimit     = 1000 // Messages listed, or IDs of a request, at most
+)
+
+// envelopeFields are the fields of the event envelope kept in the payloads of listed
+// messages, which describe the event rather than the people in it
+var envelopeFields = map[string]bool{"type": true, "version": true, "occurred_at": true, "producer": true}
+
+// OutboxMessage is a message of the outbox, or a dead letter, as listed by the admin API,
+// with the personal data of its payload redacted
+type OutboxMessage struct {
+	ID             string      `json:"id"`
+	Type           string      `json:"type"`
+	CreatedAt      time.Time   `json:"createdAt"`
+	Payload        interface{} `json:"payload"`
+	PayloadError   string      `json:"payloadError,omitempty"`
+	Reason         string      `json:"reason,omitempty"`
+	DeadLetteredAt *time.Time  `json:"deadLetteredAt,omitempty"`
+}
+
+// listOutbox lists the messages at the head of the outbox, which the relay publishes next
+func (h *Handler) listOutbox(w http.ResponseWriter, r *http.Request) string {
+	if h.deps.Outbox == nil {
+		writeError(w, http.StatusNotImplemented, CodeUnavailable, "the event outbox is disabled")
+		return OutcomeFailed
+	}
+	limit, eventType, ok := outboxFilters(w, r)
+	if !ok {
+		return OutcomeFailed
+	}
+
+	messages, err := h.deps.Outbox.OutboxMessages(r.Context(), eventType, limit)
+	if err != nil {
+		h.deps.Logger.Error("Failed to read the outbox", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, CodeFailed, "failed to read the outbox")
+		return OutcomeFailed
+	}
+
+	listed := make([]OutboxMessage, 0, len(messages))
+	for _, message := range messages {
+		listed = append(listed, redactMessage(message))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"messages": listed})
+	return OutcomeSucceeded
+}
+
+// listDeadLetters lists the messages set aside from the outbox
+func (h *Handler) listDeadLetters(w http.ResponseWriter, r *http.Request) string {
+	if h.deps.Outbox == nil {
+		writeError(w, http.StatusNotImplemented, CodeUnavailable, "the event outbox is disabled")
+		return OutcomeFailed
+	}
+	limit, eventType, ok := outboxFilters(w, r)
+	if !ok {
+		return OutcomeFailed
+	}
+
+	letters, err := h.deps.Outbox.DeadLetters(r.Context(), eventType, limit)
+	if err != nil {
+		h.deps.Logger.Error("Failed to read the dead letters", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, CodeFailed, "failed to read the dead letters")
+		return OutcomeFailed
+	}
+
+	listed := make([]OutboxMessage, 0, len(letters))
+	for _, letter := range letters {
+		message := redactMessage(letter.OutboxMessage)
+		message.Reason = letter.Reason
+		message.DeadLetteredAt = &letter.DeadLetteredAt
+		listed = append(listed, message)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"deadLetters": listed})
+	return OutcomeSucceeded
+}
+
+// deadLetterOutbox sets messages of the outbox aside, so that the relay publishes those
+// after them
+func (h *Handler) deadLetterOutbox(w http.ResponseWriter, r *http.Request) string {
+	if h.deps.Outbox == nil {
+		writeError(w, http.StatusNotImplemented, CodeUnavailable, "the event outbox is disabled")
+		return OutcomeFailed
+	}
+	var body struct {
+		IDs    []string `json:"ids"`
+		Reason string   `json:"reason"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil ||
+		len(body.IDs) == 0 || len(body.IDs) > maxOutboxLimit || strings.TrimSpace(body.Reason) == "" {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, `the body must be {"ids": ["..."], "reason": "..."}, with at most 1000 IDs`)
+		return OutcomeFailed
+	}
+
+	moved, err := h.deps.Outbox.DeadLetterOutboxMessages(r.Context(), body.IDs, body.Reason, time.Now())
+	if err != nil {
+		h.deps.Logger.Error("Failed to move outbox messages to the dead letters", zap.Strings("ids", body.IDs), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, CodeFailed, "failed to move the messages to the dead letters")
+		return OutcomeFailed
+	}
+	h.deps.Logger.Info("Moved outbox messages to the dead letters", zap.Strings("ids", body.IDs), zap.Int("moved", moved), zap.String("reason", body.Reason))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"deadLettered": moved})
+	return OutcomeSucceeded
+}
+
+// replayDeadLetters moves dead letters back to the end of the outbox
+func (h *Handler) replayDeadLetters(w http.ResponseWriter, r *http.Request) string {
+	ids, ok := h.deadLetterIDs(w, r)
+	if !ok {
+		return OutcomeFailed
+	}
+
+	replayed, err := h.deps.Outbox.ReplayDeadLetters(r.Context(), ids)
+	if err != nil {
+		h.deps.Logger.Error("Failed to replay dead letters", zap.Strings("ids", ids), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, CodeFailed, "failed to replay the dead letters")
+		return OutcomeFailed
+	}
+	h.deps.Logger.Info("Replayed dead letters", zap.Strings("ids", ids), zap.Int("replayed", replayed))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"replayed": replayed})
+	return OutcomeSucceeded
+}
+
+// purgeDeadLetters removes dead letters for good
+func (h *Handler) purgeDeadLetters(w http.ResponseWriter, r *http.Request) string {
+	ids, ok := h.deadLetterIDs(w, r)
+	if !ok {
+		return OutcomeFailed
+	}
+
+	purged, err := h.deps.Outbox.PurgeDeadLetters(r.Context(), ids)
+	if err != nil {
+		h.deps.Logger.Error("Failed to purge dead letters", zap.Strings("ids", ids), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, CodeFailed, "failed to purge the dead letters")
+		return OutcomeFailed
+	}
+	h.deps.Logger.Info("Purged dead letters", zap.Strings("ids", ids), zap.Int("purged", purged))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"purged": purged})
+	return OutcomeSucceeded
+}
+
+// deadLetterIDs reads the IDs of the dead letters of a replay or purge, or writes the error
+// response of an outbox that is disabled or a body that is invalid
+func (h *Handler) deadLetterIDs(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	if h.deps.Outbox == nil {
+		writeError(w, http.StatusNotImplemented, CodeUnavailable, "the event outbox is disabled")
+		return nil, false
+	}
+	var body struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil ||
+		len(body.IDs) == 0 || len(body.IDs) > maxOutboxLimit {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, `the body must be {"ids": ["..."]}, with at most 1000 IDs`)
+		return nil, false
+	}
+	return body.IDs, true
+}
+
+// outboxFilters reads the limit and type parameters of a listing, or writes the error
+// response of an invalid limit
+func outboxFilters(w http.ResponseWriter, r *http.Request) (limit int, eventType string, ok bool) {
+	params := r.URL.Query()
+	limit = defaultOutboxLimit
+	if value := params.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 || limit > maxOutboxLimit {
+			writeError(w, http.StatusBadRequest, CodeBadRequest, "the limit must be an integer from 1 to 1000")
+			return 0, "", false
+		}
+	}
+	return limit, params.Get("type"), true
+}
+
+// redactMessage returns a message as listed, with the personal data of its payload redacted
+func redactMessage(message ports.OutboxMessage) OutboxMessage {
+	listed := OutboxMessage{ID: message.ID, Type: message.Type, CreatedAt: message.CreatedAt}
+	var payload interface{}
+	if err := json.Unmarshal(message.Payload, &payload); err != nil {
+		listed.Payload = config.Redacted
+		listed.PayloadError = "the payload is not JSON: " + err.Error()
+		return listed
+	}
+	if envelope, ok := payload.(map[string]interface{}); ok {
+		for key, value := range envelope {
+			if !envelopeFields[key] && !isIDField(key) {
+				envelope[key] = redactValue(key, value)
+			}
+		}
+		listed.Payload = envelope
+		return listed
+	}
+	listed.Payload = redactValue("", payload)
+	return listed
+}
+
+// redactValue returns the value of a field of a payload with its strings replaced, except
+// those of ID fields, which identify records rather than people. Numbers, booleans, and
+// nulls are kept, and the elements of an array are redacted as the field that holds them.
+func redactValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if isIDField(key) {
+			return v
+		}
+		return config.Redacted
+	case map[string]interface{}:
+		for k, field := range v {
+			v[k] = redactValue(k, field)
+		}
+		return v
+	case []interface{}:
+		for i, element := range v {
+			v[i] = redactValue(key, element)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// isIDField reports whether a field of a payload holds IDs, such as id, familyId, or
+// childIds
+func isIDField(key string) bool {
+	return key == "id" || strings.HasSuffix(key, "Id") || strings.HasSuffix(key, "Ids")
+}
@@ -0,0 +1,333 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package authz authorizes GraphQL operations with fine-grained, per-operation scopes.
+//
+// Every operation protected by the @isAuthorized directive is mapped to a single scope
+// in the Operations table, such as "family:create" for createFamily or "child:remove"
+// for removeChild. The directive may also protect a field of another type, such as
+// Parent.families, which is named in the table by its schema coordinate. A caller is
+// authorized if they hold one of the roles allowed by the directive and the scope of the
+// operation. Tokens minted before fine-grained scopes were introduced carry coarse scopes
+// (READ, WRITE, CREATE, DELETE) and resources (FAMILY, PARENT, CHILD); they are accepted
+// in place of the fine-grained scope while coarse scopes are enabled, so that existing
+// clients keep working during migration.
+package authz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Scope is a fine-grained permission to perform one kind of GraphQL operation
+type Scope string
+
+const (
+	// ScopeFamilyRead allows reading and counting families
+	ScopeFamilyRead Scope = "family:read"
+
+	// ScopeFamilyCreate allows creating families
+	ScopeFamilyCreate Scope = "family:create"
+
+	// ScopeFamilyUpdate allows replacing the data of a family and changing the names of its members
+	ScopeFamilyUpdate Scope = "family:update"
+
+	// ScopeFamilyDivorce allows divorcing the parents of a family
+	ScopeFamilyDivorce Scope = "family:divorce"
+
+	// ScopeFamilyRemarry allows remarrying the parent of a family and merging families
+	ScopeFamilyRemarry Scope = "family:remarry"
+
+	// ScopeFamilyDelete allows deleting families
+	ScopeFamilyDelete Scope = "family:delete"
+
+	// ScopeFamilyAudit allows reading policy reports about families
+	ScopeFamilyAudit Scope = "family:audit"
+
+	// ScopeFamilyQuarantine allows quarantining families, releasing them, and listing quarantines
+	ScopeFamilyQuarantine Scope = "family:quarantine"
+
+	// ScopeParentRead allows reading, counting, and searching by parents
+	ScopeParentRead Scope = "parent:read"
+
+	// ScopeParentAdd allows adding parents to families
+	ScopeParentAdd Scope = "parent:add"
+
+	// ScopeParentUpdate allows updating parents, for example marking them deceased
+	ScopeParentUpdate Scope = "parent:update"
+
+	// ScopeChildRead allows counting and searching by children
+	ScopeChildRead Scope = "child:read"
+
+	// ScopeChildAdd allows adding children to families
+	ScopeChildAdd Scope = "child:add"
+
+	// ScopeChildRemove allows removing children from families
+	ScopeChildRemove Scope = "child:remove"
+
+	// ScopeChildUpdate allows updating children, for example marking them deceased
+	ScopeChildUpdate Scope = "child:update"
+
+	// ScopeChildMove allows moving children between families
+	ScopeChildMove Scope = "child:move"
+
+	// ScopeChildConsent allows recording consents for the data of children
+	ScopeChildConsent Scope = "child:consent"
+
+	// ScopeExportRun allows exporting every family through the admin API
+	ScopeExportRun Scope = "export:run"
+
+	// ScopeOpsCache allows flushing the caches of the replicas through the admin API
+	ScopeOpsCache Scope = "ops:cache"
+
+	// ScopeOpsKeys allows rotating the JWT validation keys through the admin API
+	ScopeOpsKeys Scope = "ops:keys"
+
+	// ScopeOpsReindex allows creating the missing indexes of the database through the admin API
+	ScopeOpsReindex Scope = "ops:reindex"
+
+	// ScopeOpsMaintenance allows turning maintenance mode on and off through the admin API
+	ScopeOpsMaintenance Scope = "ops:maintenance"
+
+	// ScopeOpsConfig allows reading the effective configuration, with secrets redacted,
+	// through the admin API
+	ScopeOpsConfig Scope = "ops:config"
+
+	// ScopeOpsCapture allows downloading and clearing the captured GraphQL operations
+	// through the admin API
+	ScopeOpsCapture Scope = "ops:capture"
+
+	// ScopeOpsOutbox allows listing, setting aside, replaying, and purging the messages of
+	// the event outbox through the admin API
+	ScopeOpsOutbox Scope = "ops:outbox"
+)
+
+// Scopes lists every fine-grained scope
+var Scopes = []Scope{
+	ScopeFamilyRead,
+	ScopeFamilyCreate,
+	ScopeFamilyUpdate,
+	ScopeFamilyDivorce,
+	ScopeFamilyRemarry,
+	ScopeFamilyDelete,
+	ScopeFamilyAudit,
+	ScopeFamilyQuarantine,
+	ScopeParentRead,
+	ScopeParentAdd,
+	ScopeParentUpdate,
+	ScopeChildRead,
+	ScopeChildAdd,
+	ScopeChildRemove,
+	ScopeChildUpdate,
+	ScopeChildMove,
+	ScopeChildConsent,
+	ScopeExportRun,
+	ScopeOpsCache,
+	ScopeOpsKeys,
+	ScopeOpsReindex,
+	ScopeOpsMaintenance,
+	ScopeOpsConfig,
+	ScopeOpsCapture,
+	ScopeOpsOutbox,
+}
+
+// Operations maps each GraphQL field protected by the @isAuthorized directive to the
+// scope it requires: the fields of Query and Mutation by their names, and the fields of
+// other types by their schema coordinates (see OperationName). Fields missing from the
+// table are denied.
+var Operations = map[string]Scope{
+	// Queries
+	"getFamily":                ScopeFamilyRead,
+	"getAllFamilies":           ScopeFamilyRead,
+	"families":                 ScopeFamilyRead,
+	"compareFamilies":          ScopeFamilyRead,
+	"findFamiliesByExternalId": ScopeFamilyRead,
+	"searchFamilies":           ScopeFamilyRead,
+	"memberNameAsOf":           ScopeFamilyRead,
+	"countFamilies":            ScopeFamilyRead,
+	"people":                   ScopeFamilyRead,
+	"estimateQueryCost":        ScopeFamilyRead,
+	"agePolicyViolations":      ScopeFamilyAudit,
+	"familyQuarantines":        ScopeFamilyQuarantine,
+	"findFamiliesByParent":     ScopeParentRead,
+	"parents":                  ScopeParentRead,
+	"countParents":             ScopeParentRead,
+	"findFamilyByChild":        ScopeChildRead,
+	"countChildren":            ScopeChildRead,
+
+	// Fields of types other than Query and Mutation
+	"Parent.externalIds": ScopeParentRead,
+	"Parent.nameHistory": ScopeParentRead,
+	"Parent.families":    ScopeParentRead,
+	"Child.externalIds":  ScopeChildRead,
+	"Child.nameHistory":  ScopeChildRead,
+	"Child.consents":     ScopeChildRead,
+
+	// Mutations
+	"createFamily":       ScopeFamilyCreate,
+	"updateFamily":       ScopeFamilyUpdate,
+	"changeMemberName":   ScopeFamilyUpdate,
+	"setPreferredName":   ScopeFamilyUpdate,
+	"divorce":            ScopeFamilyDivorce,
+	"deleteFamily":       ScopeFamilyDelete,
+	"addParent":          ScopeParentAdd,
+	"markParentDeceased": ScopeParentUpdate,
+	"addChild":           ScopeChildAdd,
+	"removeChild":        ScopeChildRemove,
+
+	// Mutations returning payloads with user errors
+	"createFamilyV2":       ScopeFamilyCreate,
+	"updateFamilyV2":       ScopeFamilyUpdate,
+	"changeMemberNameV2":   ScopeFamilyUpdate,
+	"setPreferredNameV2":   ScopeFamilyUpdate,
+	"divorceV2":            ScopeFamilyDivorce,
+	"deleteFamilyV2":       ScopeFamilyDelete,
+	"addParentV2":          ScopeParentAdd,
+	"markParentDeceasedV2": ScopeParentUpdate,
+	"addChildV2":           ScopeChildAdd,
+	"removeChildV2":        ScopeChildRemove,
+	"moveChild":            ScopeChildMove,
+	"remarry":              ScopeFamilyRemarry,
+	"mergeFamilies":        ScopeFamilyRemarry,
+	"grantConsent":         ScopeChildConsent,
+	"updateParent":         ScopeParentUpdate,
+	"updateChild":          ScopeChildUpdate,
+	"markChildDeceased":    ScopeChildUpdate,
+	"quarantineFamily":     ScopeFamilyQuarantine,
+	"unquarantineFamily":   ScopeFamilyQuarantine,
+	"restoreFamily":        ScopeFamilyDelete,
+	"purgeDeletedFamilies": ScopeFamilyDelete,
+}
+
+// Error codes of authorization errors
+const (
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	CodeForbidden       = "FORBIDDEN"
+)
+
+// OperationName returns the name of a field of a type in the Operations table: the name
+// of the field for the root operation types, and its schema coordinate, such as
+// "Parent.families", for other types
+func OperationName(typeName, fieldName string) string {
+	switch typeName {
+	case "", "Query", "Mutation", "Subscription":
+		return fieldName
+	default:
+		return typeName + "." + fieldName
+	}
+}
+
+// Requirement is the access required by an operation, as declared by its @isAuthorized directive
+type Requirement struct {
+	Operation    string   // Name of the GraphQL field
+	AllowedRoles []string // Roles that may perform the operation
+	CoarseScopes []string // Coarse scopes accepted in place of the operation's scope
+	Resource     string   // Resource the coarse scopes must apply to
+}
+
+// Authorizer checks callers against the operations table
+type Authorizer struct {
+	acceptCoarseScopes bool
+}
+
+// NewAuthorizer creates a new Authorizer.
+//
+// Parameters:
+//   - acceptCoarseScopes: Whether coarse scopes and resources are accepted in place of fine-grained scopes
+func NewAuthorizer(acceptCoarseScopes bool) *Authorizer {
+	return &Authorizer{acceptCoarseScopes: acceptCoarseScopes}
+}
+
+// Authorize returns nil if the caller identified by the auth middleware may perform the
+// operation, otherwise a GraphQL error with the UNAUTHENTICATED or FORBIDDEN code.
+func (a *Authorizer) Authorize(ctx context.Context, req Requirement) error {
+	if userID, ok := middleware.GetUserID(ctx); !ok || userID == "" {
+		return newError(CodeUnauthenticated, "authentication required")
+	}
+
+	roles, _ := middleware.GetUserRoles(ctx)
+	if !containsAny(roles, req.AllowedRoles) {
+		return newError(CodeForbidden, fmt.Sprintf("%s requires one of the roles %s", req.Operation, strings.Join(req.AllowedRoles, ", ")))
+	}
+
+	scope, ok := Operations[req.Operation]
+	if !ok {
+		return newError(CodeForbidden, fmt.Sprintf("%s has no authorization rule", req.Operation))
+	}
+	return a.authorizeScope(ctx, req, scope)
+}
+
+// AuthorizeScope returns nil if the caller identified by the auth middleware holds one of
+// the allowed roles and the scope, otherwise an error with the UNAUTHENTICATED or FORBIDDEN
+// code. It authorizes actions that are not GraphQL operations, such as those of the admin
+// API, for which coarse scopes are never accepted.
+func (a *Authorizer) AuthorizeScope(ctx context.Context, action string, allowedRoles []string, scope Scope) error {
+	if userID, ok := middleware.GetUserID(ctx); !ok || userID == "" {
+		return newError(CodeUnauthenticated, "authentication required")
+	}
+
+	roles, _ := middleware.GetUserRoles(ctx)
+	if !containsAny(roles, allowedRoles) {
+		return newError(CodeForbidden, fmt.Sprintf("%s requires one of the roles %s", action, strings.Join(allowedRoles, ", ")))
+	}
+	return a.authorizeScope(ctx, Requirement{Operation: action}, scope)
+}
+
+// authorizeScope checks that a caller with an allowed role holds the scope of the
+// requirement, or its coarse scopes while they are accepted
+func (a *Authorizer) authorizeScope(ctx context.Context, req Requirement, scope Scope) error {
+	scopes, _ := middleware.GetUserScopes(ctx)
+	if contains(scopes, string(scope)) {
+		return nil
+	}
+
+	if a.acceptCoarseScopes && len(req.CoarseScopes) > 0 {
+		resources, _ := middleware.GetUserResources(ctx)
+		if contains(resources, req.Resource) && containsAll(scopes, req.CoarseScopes) {
+			return nil
+		}
+	}
+
+	return newError(CodeForbidden, fmt.Sprintf("%s requires the scope %s", req.Operation, scope))
+}
+
+// newError creates a GraphQL error with an error code extension
+func newError(code, message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message:    message,
+		Extensions: map[string]interface{}{"code": code},
+	}
+}
+
+// contains reports whether values contains value, ignoring case
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny reports whether values contains at least one of wanted
+func containsAny(values, wanted []string) bool {
+	for _, w := range wanted {
+		if contains(values, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAll reports whether values contains every element of wanted
+func containsAll(values, wanted []string) bool {
+	for _, w := range wanted {
+		if !contains(values, w) {
+			return false
+		}
+	}
+	return true
+}
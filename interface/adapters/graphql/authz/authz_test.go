@@ -0,0 +1,143 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package authz
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// userContext returns a context for a user authenticated by the auth middleware
+func userContext(roles, scopes, resources []string) context.Context {
+	ctx := middleware.WithUserID(context.Background(), "user-1")
+	ctx = middleware.WithUserRoles(ctx, roles)
+	ctx = middleware.WithUserScopes(ctx, scopes)
+	return middleware.WithUserResources(ctx, resources)
+}
+
+// errorCode returns the code extension of an authorization error
+func errorCode(t *testing.T, err error) string {
+	var gqlErr *gqlerror.Error
+	require.ErrorAs(t, err, &gqlErr)
+	return gqlErr.Extensions["code"].(string)
+}
+
+func TestAuthorize(t *testing.T) {
+	removeChild := Requirement{
+		Operation:    "removeChild",
+		AllowedRoles: []string{"ADMIN", "EDITOR"},
+		CoarseScopes: []string{"DELETE"},
+		Resource:     "CHILD",
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		req    Requirement
+		coarse bool
+		code   string
+	}{
+		{"unauthenticated", context.Background(), removeChild, true, CodeUnauthenticated},
+		{"fine-grained scope", userContext([]string{"EDITOR"}, []string{"child:remove"}, nil), removeChild, false, ""},
+		{"role matched case-insensitively", userContext([]string{"editor"}, []string{"child:remove"}, nil), removeChild, false, ""},
+		{"role not allowed", userContext([]string{"VIEWER"}, []string{"child:remove"}, nil), removeChild, true, CodeForbidden},
+		{"other fine-grained scope", userContext([]string{"EDITOR"}, []string{"child:add"}, nil), removeChild, true, CodeForbidden},
+		{"coarse scope accepted", userContext([]string{"EDITOR"}, []string{"DELETE"}, []string{"CHILD"}), removeChild, true, ""},
+		{"coarse scope rejected", userContext([]string{"EDITOR"}, []string{"DELETE"}, []string{"CHILD"}), removeChild, false, CodeForbidden},
+		{"coarse scope for other resource", userContext([]string{"EDITOR"}, []string{"DELETE"}, []string{"FAMILY"}), removeChild, true, CodeForbidden},
+		{"unknown operation", userContext([]string{"ADMIN"}, []string{"READ"}, []string{"FAMILY"}), Requirement{Operation: "unknown", AllowedRoles: []string{"ADMIN"}, CoarseScopes: []string{"READ"}, Resource: "FAMILY"}, true, CodeForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewAuthorizer(tt.coarse).Authorize(tt.ctx, tt.req)
+			if tt.code == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.code, errorCode(t, err))
+		})
+	}
+}
+
+func TestAuthorizeScope(t *testing.T) {
+	authorizer := NewAuthorizer(true)
+	admin := []string{"ADMIN"}
+
+	assert.NoError(t, authorizer.AuthorizeScope(userContext(admin, []string{"ops:cache"}, nil), "flushCache", admin, ScopeOpsCache))
+	assert.Equal(t, CodeUnauthenticated, errorCode(t, authorizer.AuthorizeScope(context.Background(), "flushCache", admin, ScopeOpsCache)))
+	assert.Equal(t, CodeForbidden, errorCode(t, authorizer.AuthorizeScope(userContext([]string{"EDITOR"}, []string{"ops:cache"}, nil), "flushCache", admin, ScopeOpsCache)))
+	assert.Equal(t, CodeForbidden, errorCode(t, authorizer.AuthorizeScope(userContext(admin, []string{"ops:config"}, nil), "flushCache", admin, ScopeOpsCache)))
+
+	// Coarse scopes never stand in for the scopes of actions
+	assert.Equal(t, CodeForbidden, errorCode(t, authorizer.AuthorizeScope(userContext(admin, []string{"READ", "WRITE", "CREATE", "DELETE"}, []string{"FAMILY"}), "flushCache", admin, ScopeOpsCache)))
+}
+
+func TestOperationName(t *testing.T) {
+	assert.Equal(t, "getFamily", OperationName("Query", "getFamily"))
+	assert.Equal(t, "createFamily", OperationName("Mutation", "createFamily"))
+	assert.Equal(t, "Parent.families", OperationName("Parent", "families"))
+}
+
+func TestAuthorize_Field(t *testing.T) {
+	authorizer := NewAuthorizer(true)
+	families := Requirement{
+		Operation:    OperationName("Parent", "families"),
+		AllowedRoles: []string{"ADMIN", "EDITOR", "VIEWER"},
+		CoarseScopes: []string{"READ"},
+		Resource:     "PARENT",
+	}
+
+	// A caller that may read families but not parents is denied the families of a parent
+	err := authorizer.Authorize(userContext([]string{"VIEWER"}, []string{"family:read"}, nil), families)
+	assert.Equal(t, CodeForbidden, errorCode(t, err))
+	assert.Contains(t, err.Error(), "Parent.families requires the scope parent:read")
+
+	assert.NoError(t, authorizer.Authorize(userContext([]string{"VIEWER"}, []string{"parent:read"}, nil), families))
+	assert.NoError(t, authorizer.Authorize(userContext([]string{"VIEWER"}, []string{"READ"}, []string{"PARENT"}), families))
+	assert.Equal(t, CodeForbidden, errorCode(t, authorizer.Authorize(userContext([]string{"VIEWER"}, []string{"READ"}, []string{"FAMILY"}), families)))
+
+	// The external IDs of a child are read with child:read, not with the family
+	externalIDs := Requirement{
+		Operation:    OperationName("Child", "externalIds"),
+		AllowedRoles: []string{"ADMIN", "EDITOR", "VIEWER"},
+		CoarseScopes: []string{"READ"},
+		Resource:     "CHILD",
+	}
+	assert.Equal(t, CodeForbidden, errorCode(t, authorizer.Authorize(userContext([]string{"VIEWER"}, []string{"family:read"}, nil), externalIDs)))
+	assert.NoError(t, authorizer.Authorize(userContext([]string{"VIEWER"}, []string{"child:read"}, nil), externalIDs))
+}
+
+func TestOperationsCoverSchema(t *testing.T) {
+	source, err := os.ReadFile("../schema.graphql")
+	require.NoError(t, err)
+
+	schema, gqlErr := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: string(source)})
+	require.Nil(t, gqlErr)
+
+	protected := make(map[string]bool)
+	for _, typ := range schema.Types {
+		for _, field := range typ.Fields {
+			if field.Directives.ForName("isAuthorized") != nil {
+				protected[OperationName(typ.Name, field.Name)] = true
+			}
+		}
+	}
+	require.NotEmpty(t, protected)
+
+	for operation := range protected {
+		assert.Contains(t, Operations, operation, "operation has no scope in the authorization table")
+	}
+	for operation, scope := range Operations {
+		assert.True(t, protected[operation], "authorization table maps %s, which is not a protected operation", operation)
+		assert.Contains(t, Scopes, scope)
+	}
+}
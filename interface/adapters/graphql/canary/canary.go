@@ -0,0 +1,166 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package canary routes GraphQL operations to canary strategies of the domain rules.
+//
+// A strategy is an alternate set of domain rules registered with the application services,
+// so that new rules can be tried on part of the traffic before they replace the default
+// ones. The strategy of an operation is the one named by its request header; failing that,
+// the strategy of the caller's tenant; failing that, the percentage strategy for the
+// configured percentage of operations. All other operations use the default domain rules.
+//
+// The results and durations of the root fields are counted by strategy, so that the
+// strategies can be compared before a canary is promoted or rolled back.
+package canary
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/core/application/strategy"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/slo"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// OperationResultsTotal counts the results of root fields by strategy and SLO outcome
+	OperationResultsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "canary_operation_results_total",
+			Help: "Total number of results of root GraphQL fields by strategy of the domain rules and SLO outcome",
+		},
+		[]string{"strategy", "field", "outcome"},
+	)
+
+	// OperationDuration observes the duration of root fields by strategy
+	OperationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "canary_operation_duration_seconds",
+			Help:    "Duration of root GraphQL fields by strategy of the domain rules",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"strategy", "field"},
+	)
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(OperationResultsTotal, OperationDuration)
+}
+
+// Selector selects the strategy of operations
+type Selector struct {
+	header             string
+	strategies         map[string]bool
+	tenants            map[string]string
+	percentage         float64
+	percentageStrategy string
+	random             func() float64
+}
+
+// NewSelector creates a selector for the canary configuration. It fails if a tenant or the
+// percentage of operations is routed to a strategy that is not configured.
+func NewSelector(cfg config.CanaryConfig) (*Selector, error) {
+	strategies := map[string]bool{strategy.Default: true}
+	for name := range cfg.Strategies {
+		strategies[name] = true
+	}
+
+	var unknown []string
+	for tenant, name := range cfg.Tenants {
+		if !strategies[name] {
+			unknown = append(unknown, fmt.Sprintf("tenant %s: %s", tenant, name))
+		}
+	}
+	if cfg.Percentage > 0 && !strategies[cfg.PercentageStrategy] {
+		unknown = append(unknown, fmt.Sprintf("percentage: %q", cfg.PercentageStrategy))
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("canary routes to unknown strategies: %s", strings.Join(unknown, ", "))
+	}
+
+	return &Selector{
+		header:             cfg.Header,
+		strategies:         strategies,
+		tenants:            cfg.Tenants,
+		percentage:         cfg.Percentage,
+		percentageStrategy: cfg.PercentageStrategy,
+		random:             rand.Float64,
+	}, nil
+}
+
+// Select returns the strategy of an operation with the given request headers. A header
+// naming an unknown strategy is ignored.
+func (s *Selector) Select(ctx context.Context, headers http.Header) string {
+	if s.header != "" {
+		if name := headers.Get(s.header); s.strategies[name] {
+			return name
+		}
+	}
+	if name, ok := s.tenants[servicecontext.GetTenantID(ctx)]; ok {
+		return name
+	}
+	if s.percentage > 0 && s.random()*100 < s.percentage {
+		return s.percentageStrategy
+	}
+	return strategy.Default
+}
+
+// Extension is a gqlgen handler extension that routes each operation to the strategy
+// selected for it and counts the results of its root fields by strategy
+type Extension struct {
+	selector *Selector
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+	graphql.FieldInterceptor
+} = Extension{}
+
+// NewExtension creates an extension that routes operations with a selector
+func NewExtension(selector *Selector) Extension {
+	return Extension{selector: selector}
+}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "CanaryStrategy"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation records the strategy selected for the operation in its context
+func (e Extension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	var headers http.Header
+	if graphql.HasOperationContext(ctx) {
+		headers = graphql.GetOperationContext(ctx).Headers
+	}
+	return next(strategy.WithStrategy(ctx, e.selector.Select(ctx, headers)))
+}
+
+// InterceptField counts the result and duration of root fields by strategy
+func (Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver || (fc.Object != "Query" && fc.Object != "Mutation") {
+		return next(ctx)
+	}
+
+	name := strategy.From(ctx)
+	start := time.Now()
+	res, err := next(ctx)
+	OperationDuration.WithLabelValues(name, fc.Field.Name).Observe(time.Since(start).Seconds())
+	OperationResultsTotal.WithLabelValues(name, fc.Field.Name, string(slo.Classify(err))).Inc()
+	return res, err
+}
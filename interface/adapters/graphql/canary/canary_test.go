@@ -0,0 +1,122 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package canary
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/core/application/strategy"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func testConfig() config.CanaryConfig {
+	return config.CanaryConfig{
+		Enabled: true,
+		Header:  "X-Canary-Strategy",
+		Strategies: map[string]config.CanaryStrategyConfig{
+			"strict-age":  {},
+			"new-consent": {},
+		},
+		Tenants:            map[string]string{"tenant-a": "new-consent"},
+		Percentage:         10,
+		PercentageStrategy: "strict-age",
+	}
+}
+
+func TestNewSelector_UnknownStrategies(t *testing.T) {
+	cfg := testConfig()
+	cfg.Tenants["tenant-b"] = "missing"
+	cfg.PercentageStrategy = "absent"
+
+	_, err := NewSelector(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tenant tenant-b: missing")
+	assert.Contains(t, err.Error(), `percentage: "absent"`)
+
+	// The percentage strategy is only required when a percentage is routed to it
+	cfg = testConfig()
+	cfg.Percentage = 0
+	cfg.PercentageStrategy = ""
+	_, err = NewSelector(cfg)
+	assert.NoError(t, err)
+}
+
+func TestSelector_Select(t *testing.T) {
+	selector, err := NewSelector(testConfig())
+	require.NoError(t, err)
+	roll := 0.5
+	selector.random = func() float64 { return roll }
+
+	header := func(name string) http.Header {
+		h := http.Header{}
+		h.Set("X-Canary-Strategy", name)
+		return h
+	}
+	tenantCtx := servicecontext.WithTenantID(context.Background(), "tenant-a")
+
+	// The header takes precedence over the tenant, and may select the default rules
+	assert.Equal(t, "strict-age", selector.Select(tenantCtx, header("strict-age")))
+	assert.Equal(t, strategy.Default, selector.Select(tenantCtx, header(strategy.Default)))
+
+	// Unknown strategies in the header are ignored
+	assert.Equal(t, "new-consent", selector.Select(tenantCtx, header("unknown")))
+	assert.Equal(t, strategy.Default, selector.Select(context.Background(), header("unknown")))
+
+	// The percentage strategy is selected for the configured fraction of operations
+	roll = 0.05
+	assert.Equal(t, "strict-age", selector.Select(context.Background(), nil))
+	roll = 0.1
+	assert.Equal(t, strategy.Default, selector.Select(context.Background(), nil))
+}
+
+func TestExtension(t *testing.T) {
+	selector, err := NewSelector(testConfig())
+	require.NoError(t, err)
+	ext := NewExtension(selector)
+
+	headers := http.Header{}
+	headers.Set("X-Canary-Strategy", "new-consent")
+	ctx := graphql.WithOperationContext(context.Background(), &graphql.OperationContext{Headers: headers})
+
+	var selected string
+	ext.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		selected = strategy.From(ctx)
+		return nil
+	})
+	assert.Equal(t, "new-consent", selected)
+
+	// Root resolvers are counted by strategy and outcome; other fields are not
+	fieldCtx := func(object string, isResolver bool) context.Context {
+		ctx := strategy.WithStrategy(context.Background(), "new-consent")
+		return graphql.WithFieldContext(ctx, &graphql.FieldContext{
+			Object:     object,
+			IsResolver: isResolver,
+			Field:      graphql.CollectedField{Field: &ast.Field{Name: "getFamily"}},
+		})
+	}
+	failures := OperationResultsTotal.WithLabelValues("new-consent", "getFamily", "system_error")
+	successes := OperationResultsTotal.WithLabelValues("new-consent", "getFamily", "success")
+	beforeFailures, beforeSuccesses := testutil.ToFloat64(failures), testutil.ToFloat64(successes)
+
+	_, err = ext.InterceptField(fieldCtx("Query", true), func(ctx context.Context) (any, error) {
+		return nil, errors.New("database unavailable")
+	})
+	assert.Error(t, err)
+	res, err := ext.InterceptField(fieldCtx("Query", true), func(ctx context.Context) (any, error) { return "resolved", nil })
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", res)
+	_, _ = ext.InterceptField(fieldCtx("Family", true), func(ctx context.Context) (any, error) { return nil, nil })
+	_, _ = ext.InterceptField(fieldCtx("Query", false), func(ctx context.Context) (any, error) { return nil, nil })
+
+	assert.Equal(t, beforeFailures+1, testutil.ToFloat64(failures))
+	assert.Equal(t, beforeSuccesses+1, testutil.ToFloat64(successes))
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package capture records sanitized GraphQL operations for debugging, and replays them.
+//
+// Hard-to-reproduce bugs are easier to chase with the operations that caused them. With
+// capture enabled, the Extension records every query and mutation in a ring buffer that
+// keeps the most recent operations: the query, its variables, the roles and scopes of the
+// caller, and metadata about the result (duration, size, error codes and paths, and the
+// response extensions set by the backends, such as staleness or redactions). Administrators
+// download the buffer from the admin API, and Replay runs the captured operations against
+// a test server.
+//
+// Captures never hold the personal data of families. The values of the masked fields, in
+// variables and in literals of the query, are replaced before they are recorded: strings
+// with a pseudonym that is the same for the same value until the service restarts, so
+// that operations on the same person can still be related, and dates with the first day
+// of their year, so that age rules behave the same when the operation is replayed. The
+// identity of the caller and the data of responses are not recorded.
+package capture
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCapacity is the number of operations kept when the capacity is not set
+const DefaultCapacity = 100
+
+// DefaultMaskFields are the fields that hold personal data, masked when no fields are set
+var DefaultMaskFields = []string{
+	"firstName",
+	"lastName",
+	"birthDate",
+	"deathDate",
+	"parentLastName",
+	"childBornOnOrAfter",
+	"childBornOnOrBefore",
+	"externalIds",
+}
+
+// Capture is a recorded GraphQL operation
+type Capture struct {
+	ID        int64          `json:"id"`
+	Time      time.Time      `json:"time"`
+	Operation string         `json:"operation,omitempty"` // Name of the operation
+	Type      string         `json:"type"`                // query or mutation
+	Query     string         `json:"query"`               // Query with its masked literals
+	Variables map[string]any `json:"variables,omitempty"` // Variables with their masked values
+	Roles     []string       `json:"roles,omitempty"`     // Roles of the caller
+	Scopes    []string       `json:"scopes,omitempty"`    // Scopes of the caller
+	Result    Result         `json:"result"`
+}
+
+// Result is the metadata of the result of a captured operation
+type Result struct {
+	DurationMs float64       `json:"durationMs"`
+	DataBytes  int           `json:"dataBytes"`            // Size of the data of the response
+	Errors     []ResultError `json:"errors,omitempty"`     // Errors of the response, without their messages
+	Extensions []string      `json:"extensions,omitempty"` // Names of the response extensions, sorted
+}
+
+// ResultError is an error of the response of a captured operation
+type ResultError struct {
+	Path string `json:"path,omitempty"` // Path of the field that failed, such as "getFamily.parents"
+	Code string `json:"code,omitempty"` // Error code of the error
+}
+
+// Recorder keeps the most recent captured operations in a ring buffer. A Recorder is safe
+// for concurrent use.
+type Recorder struct {
+	masker *Masker
+
+	mu       sync.Mutex
+	captures []Capture
+	next     int   // Index of the slot the next capture is written to
+	full     bool  // Whether every slot holds a capture
+	lastID   int64 // ID of the last capture
+}
+
+// NewRecorder creates a recorder that keeps the most recent operations
+//
+// Parameters:
+//   - capacity: The number of operations kept (0 for DefaultCapacity)
+//   - maskFields: The names of the fields whose values are masked (nil for DefaultMaskFields)
+//
+// Returns:
+//   - A new recorder
+func NewRecorder(capacity int, maskFields []string) *Recorder {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	if maskFields == nil {
+		maskFields = DefaultMaskFields
+	}
+	return &Recorder{masker: NewMasker(maskFields), captures: make([]Capture, capacity)}
+}
+
+// Masker returns the masker of the recorder
+func (r *Recorder) Masker() *Masker {
+	return r.masker
+}
+
+// Record adds a capture to the buffer, replacing the oldest one if the buffer is full, and
+// returns its ID
+func (r *Recorder) Record(c Capture) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastID++
+	c.ID = r.lastID
+	r.captures[r.next] = c
+	r.next = (r.next + 1) % len(r.captures)
+	if r.next == 0 {
+		r.full = true
+	}
+	return c.ID
+}
+
+// Captures returns the captures in the buffer, oldest first
+func (r *Recorder) Captures() []Capture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]Capture(nil), r.captures[:r.next]...)
+	}
+	out := make([]Capture, 0, len(r.captures))
+	out = append(out, r.captures[r.next:]...)
+	return append(out, r.captures[:r.next]...)
+}
+
+// Clear empties the buffer and returns the number of captures removed
+func (r *Recorder) Clear() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.captures)
+	}
+	clear(r.captures)
+	r.next, r.full = 0, false
+	return n
+}
+
+// Masker replaces the values of the fields that hold personal data
+type Masker struct {
+	fields map[string]bool // Masked field names, in lower case
+	key    []byte          // Key of the pseudonyms, random for each masker
+}
+
+// NewMasker creates a masker of the fields with the given names, compared without case
+func NewMasker(fields []string) *Masker {
+	m := &Masker{fields: make(map[string]bool, len(fields)), key: make([]byte, 32)}
+	for _, f := range fields {
+		m.fields[strings.ToLower(f)] = true
+	}
+	_, _ = rand.Read(m.key)
+	return m
+}
+
+// masks reports whether the values of a field are masked
+func (m *Masker) masks(name string) bool {
+	return m.fields[strings.ToLower(name)]
+}
+
+// Variables returns a copy of the variables of an operation, with the values of the masked
+// fields, at any depth, replaced
+func (m *Masker) Variables(vars map[string]any) map[string]any {
+	if len(vars) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(vars))
+	for name, v := range vars {
+		out[name] = m.variable(name, v, m.masks(name))
+	}
+	return out
+}
+
+// variable returns a copy of the value of a variable or of one of its fields, masked if
+// it or any of its parents is masked
+func (m *Masker) variable(name string, v any, masked bool) any {
+	masked = masked || m.masks(name)
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			out[k] = m.variable(k, child, masked)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = m.variable(name, child, masked)
+		}
+		return out
+	case string:
+		if masked {
+			return m.String(val)
+		}
+		return val
+	case bool, nil:
+		return val
+	default:
+		// Numbers
+		if masked {
+			return 0
+		}
+		return val
+	}
+}
+
+// String masks a string value: a date keeps its year only, and any other string is
+// replaced by a pseudonym
+func (m *Masker) String(s string) string {
+	if masked, ok := maskDate(s); ok {
+		return masked
+	}
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(s))
+	return "masked-" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// maskDate replaces a YYYY-MM-DD date or an RFC3339 timestamp with the first day of its
+// year, in the same format
+func maskDate(s string) (string, bool) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02"), true
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339), true
+	}
+	return "", false
+}
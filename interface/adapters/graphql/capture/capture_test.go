@@ -0,0 +1,196 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestRecorder_RingBuffer(t *testing.T) {
+	recorder := NewRecorder(2, nil)
+	assert.Empty(t, recorder.Captures())
+
+	for _, name := range []string{"first", "second", "third"} {
+		recorder.Record(Capture{Operation: name})
+	}
+
+	// The oldest capture is replaced
+	captures := recorder.Captures()
+	require.Len(t, captures, 2)
+	assert.Equal(t, "second", captures[0].Operation)
+	assert.Equal(t, int64(2), captures[0].ID)
+	assert.Equal(t, "third", captures[1].Operation)
+
+	assert.Equal(t, 2, recorder.Clear())
+	assert.Empty(t, recorder.Captures())
+	assert.Equal(t, int64(4), recorder.Record(Capture{Operation: "fourth"}))
+}
+
+func TestMasker_Variables(t *testing.T) {
+	masker := NewMasker(DefaultMaskFields)
+	vars := map[string]any{
+		"id": "family-1",
+		"input": map[string]any{
+			"id": "family-1",
+			"parents": []any{
+				map[string]any{
+					"id":          "parent-1",
+					"firstName":   "Ann",
+					"lastName":    "Smith",
+					"birthDate":   "1980-06-15",
+					"externalIds": []any{map[string]any{"system": "ssn", "id": "123-45-6789"}},
+				},
+			},
+		},
+		"lastName": "Smith",
+	}
+
+	masked := masker.Variables(vars)
+	parent := masked["input"].(map[string]any)["parents"].([]any)[0].(map[string]any)
+	assert.Equal(t, "family-1", masked["id"])
+	assert.Equal(t, "parent-1", parent["id"])
+	assert.Equal(t, "1980-01-01", parent["birthDate"], "dates keep their year")
+	assert.True(t, strings.HasPrefix(parent["firstName"].(string), "masked-"))
+	assert.Equal(t, parent["lastName"], masked["lastName"], "the same value has the same pseudonym")
+	assert.NotContains(t, parent["externalIds"].([]any)[0].(map[string]any)["id"], "6789")
+
+	// The variables of the operation are left as they are
+	assert.Equal(t, "Ann", vars["input"].(map[string]any)["parents"].([]any)[0].(map[string]any)["firstName"])
+}
+
+func TestMasker_Query(t *testing.T) {
+	masker := NewMasker(DefaultMaskFields)
+	query, err := masker.Query(`mutation AddParent($id: ID!, $lastName: String = "Smith") {
+		addParent(familyId: $id, input: {id: "p-1", firstName: "Ann", lastName: $lastName, birthDate: "1980-06-15T00:00:00Z"}) {
+			family { id parents { firstName } }
+		}
+	}`)
+	require.NoError(t, err)
+
+	assert.Contains(t, query, `id:"p-1"`)
+	assert.Contains(t, query, `birthDate:"1980-01-01T00:00:00Z"`)
+	assert.Contains(t, query, `lastName:$lastName`)
+	assert.Contains(t, query, `parents {`)
+	assert.NotContains(t, query, "Ann")
+	assert.NotContains(t, query, "Smith")
+
+	_, err = masker.Query("{ getFamily(")
+	assert.Error(t, err)
+}
+
+// operationContext returns a context of a caller running a GraphQL operation
+func operationContext(query string, vars map[string]any) context.Context {
+	ctx := middleware.WithUserRoles(context.Background(), []string{"EDITOR"})
+	ctx = middleware.WithUserScopes(ctx, []string{"family:read"})
+	return graphql.WithOperationContext(ctx, &graphql.OperationContext{
+		RawQuery:      query,
+		Variables:     vars,
+		OperationName: "GetFamily",
+		Operation:     &ast.OperationDefinition{Operation: ast.Query, Name: "GetFamily"},
+	})
+}
+
+func TestExtension_InterceptResponse(t *testing.T) {
+	recorder := NewRecorder(10, nil)
+	ext := NewExtension(recorder, false)
+	ctx := operationContext(`query GetFamily($id: ID!) { getFamily(id: $id) { id } }`, map[string]any{"id": "family-1"})
+
+	ext.InterceptResponse(ctx, func(context.Context) *graphql.Response {
+		return &graphql.Response{
+			Data:       json.RawMessage(`{"getFamily":null}`),
+			Errors:     gqlerror.List{{Message: "family family-1 of Ann Smith not found", Path: ast.Path{ast.PathName("getFamily")}, Extensions: map[string]any{"code": "NOT_FOUND"}}},
+			Extensions: map[string]any{"stale": true, "redactions": []string{}},
+		}
+	})
+
+	captures := recorder.Captures()
+	require.Len(t, captures, 1)
+	c := captures[0]
+	assert.Equal(t, "GetFamily", c.Operation)
+	assert.Equal(t, "query", c.Type)
+	assert.Contains(t, c.Query, "getFamily(id: $id)")
+	assert.Equal(t, map[string]any{"id": "family-1"}, c.Variables)
+	assert.Equal(t, []string{"EDITOR"}, c.Roles)
+	assert.Equal(t, []string{"family:read"}, c.Scopes)
+	assert.Equal(t, len(`{"getFamily":null}`), c.Result.DataBytes)
+	assert.Equal(t, []ResultError{{Path: "getFamily", Code: "NOT_FOUND"}}, c.Result.Errors)
+	assert.Equal(t, []string{"redactions", "stale"}, c.Result.Extensions)
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Ann", "error messages are not captured")
+}
+
+func TestExtension_ErrorsOnly(t *testing.T) {
+	recorder := NewRecorder(10, nil)
+	ext := NewExtension(recorder, true)
+	ctx := operationContext(`{ getFamily(id: "family-1") { id } }`, nil)
+
+	ext.InterceptResponse(ctx, func(context.Context) *graphql.Response {
+		return &graphql.Response{Data: json.RawMessage(`{}`)}
+	})
+	assert.Empty(t, recorder.Captures())
+
+	ext.InterceptResponse(ctx, func(context.Context) *graphql.Response {
+		return &graphql.Response{Errors: gqlerror.List{{Message: "boom"}}}
+	})
+	assert.Len(t, recorder.Captures(), 1)
+}
+
+func TestReplay(t *testing.T) {
+	// The handler fails operations unless the caller has the ADMIN role
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "family-1", body.Variables["id"])
+		userID, _ := middleware.GetUserID(r.Context())
+		assert.Equal(t, ReplayUserID, userID)
+
+		roles, _ := middleware.GetUserRoles(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if len(roles) > 0 && roles[0] == "ADMIN" {
+			_, _ = w.Write([]byte(`{"data":{"getFamily":{"id":"family-1"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errors":[{"message":"denied","path":["getFamily"],"extensions":{"code":"FORBIDDEN"}}],"data":null}`))
+	})
+
+	captured := []Capture{
+		{ID: 1, Query: `{ getFamily(id: $id) { id } }`, Variables: map[string]any{"id": "family-1"}, Roles: []string{"VIEWER"},
+			Result: Result{Errors: []ResultError{{Path: "getFamily", Code: "FORBIDDEN"}}}},
+		{ID: 2, Query: `{ getFamily(id: $id) { id } }`, Variables: map[string]any{"id": "family-1"}, Roles: []string{"ADMIN"},
+			Result: Result{Errors: []ResultError{{Path: "getFamily", Code: "INTERNAL_ERROR"}}}},
+		{ID: 3},
+	}
+	results := Replay(context.Background(), handler, captured)
+	require.Len(t, results, 3)
+	assert.False(t, results[0].Diverged)
+	assert.True(t, results[1].Diverged, "the error no longer happens")
+	assert.Empty(t, results[1].Replayed)
+	assert.True(t, results[2].Diverged)
+	assert.NotEmpty(t, results[2].Error)
+}
+
+func TestReadCaptures(t *testing.T) {
+	captures, err := ReadCaptures(strings.NewReader(`{"captures":[{"id":7,"type":"mutation","query":"mutation { x }"}]}`))
+	require.NoError(t, err)
+	require.Len(t, captures, 1)
+	assert.Equal(t, int64(7), captures[0].ID)
+
+	_, err = ReadCaptures(strings.NewReader(`[`))
+	assert.Error(t, err)
+}
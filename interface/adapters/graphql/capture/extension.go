@@ -0,0 +1,117 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package capture
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Extension is a gqlgen handler extension that records the queries and mutations it
+// serves in a Recorder. Subscriptions are not recorded.
+type Extension struct {
+	recorder   *Recorder
+	errorsOnly bool
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = Extension{}
+
+// NewExtension creates an extension that records operations in a recorder
+//
+// Parameters:
+//   - recorder: The recorder of the operations
+//   - errorsOnly: Whether only the operations whose response has errors are recorded
+//
+// Returns:
+//   - A new extension
+func NewExtension(recorder *Recorder, errorsOnly bool) Extension {
+	return Extension{recorder: recorder, errorsOnly: errorsOnly}
+}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "OperationCapture"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse runs the operation and records it with the metadata of its response
+func (e Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	started := time.Now()
+	resp := next(ctx)
+	if !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+	oc := graphql.GetOperationContext(ctx)
+	if oc.Operation != nil && oc.Operation.Operation == ast.Subscription {
+		return resp
+	}
+	if e.errorsOnly && (resp == nil || len(resp.Errors) == 0) {
+		return resp
+	}
+
+	e.recorder.Record(e.capture(ctx, oc, resp, started))
+	return resp
+}
+
+// capture builds the capture of an operation and its response
+func (e Extension) capture(ctx context.Context, oc *graphql.OperationContext, resp *graphql.Response, started time.Time) Capture {
+	masker := e.recorder.Masker()
+	c := Capture{
+		Time:      started.UTC(),
+		Operation: oc.OperationName,
+		Type:      string(ast.Query),
+		Variables: masker.Variables(oc.Variables),
+		Result:    Result{DurationMs: float64(time.Since(started).Microseconds()) / 1000},
+	}
+	if oc.Operation != nil {
+		c.Type = string(oc.Operation.Operation)
+		if c.Operation == "" {
+			c.Operation = oc.Operation.Name
+		}
+	}
+	// A query that does not parse is kept out, since its literals cannot be masked
+	if query, err := masker.Query(oc.RawQuery); err == nil {
+		c.Query = query
+	}
+	c.Roles, _ = middleware.GetUserRoles(ctx)
+	c.Scopes, _ = middleware.GetUserScopes(ctx)
+
+	if resp != nil {
+		c.Result.DataBytes = len(resp.Data)
+		for _, gqlErr := range resp.Errors {
+			c.Result.Errors = append(c.Result.Errors, resultError(gqlErr))
+		}
+		for name := range resp.Extensions {
+			c.Result.Extensions = append(c.Result.Extensions, name)
+		}
+		sort.Strings(c.Result.Extensions)
+	}
+	return c
+}
+
+// resultError returns the path and code of an error of a response. Messages are left out,
+// since they may quote the personal data of the operation.
+func resultError(gqlErr *gqlerror.Error) ResultError {
+	var out ResultError
+	if len(gqlErr.Path) > 0 {
+		out.Path = gqlErr.Path.String()
+	}
+	if code, ok := gqlErr.Extensions["code"]; ok {
+		out.Code = fmt.Sprint(code)
+	}
+	return out
+}
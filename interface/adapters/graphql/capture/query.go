@@ -0,0 +1,95 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package capture
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// Query returns a query with the literal values of the masked arguments and input fields,
+// and of the default values of the masked variables, replaced. The query is parsed again
+// rather than taken from the operation, whose document is shared with its execution.
+func (m *Masker) Query(raw string) (string, error) {
+	doc, err := parser.ParseQuery(&ast.Source{Input: raw})
+	if err != nil {
+		return "", fmt.Errorf("failed to parse captured query: %w", err)
+	}
+	for _, op := range doc.Operations {
+		for _, def := range op.VariableDefinitions {
+			m.value(def.Variable, def.DefaultValue, false)
+		}
+		m.directives(op.Directives)
+		m.selections(op.SelectionSet)
+	}
+	for _, frag := range doc.Fragments {
+		m.directives(frag.Directives)
+		m.selections(frag.SelectionSet)
+	}
+
+	var b strings.Builder
+	formatter.NewFormatter(&b, formatter.WithIndent("  ")).FormatQueryDocument(doc)
+	return b.String(), nil
+}
+
+// selections masks the arguments of the fields of a selection set, at any depth
+func (m *Masker) selections(set ast.SelectionSet) {
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			m.arguments(s.Arguments)
+			m.directives(s.Directives)
+			m.selections(s.SelectionSet)
+		case *ast.InlineFragment:
+			m.directives(s.Directives)
+			m.selections(s.SelectionSet)
+		case *ast.FragmentSpread:
+			m.directives(s.Directives)
+		}
+	}
+}
+
+// directives masks the arguments of directives
+func (m *Masker) directives(list ast.DirectiveList) {
+	for _, d := range list {
+		m.arguments(d.Arguments)
+	}
+}
+
+// arguments masks the values of arguments
+func (m *Masker) arguments(args ast.ArgumentList) {
+	for _, arg := range args {
+		m.value(arg.Name, arg.Value, false)
+	}
+}
+
+// value masks a literal value, if it or any of its parents is masked. Variables are left
+// as they are, since their values are masked with the variables.
+func (m *Masker) value(name string, v *ast.Value, masked bool) {
+	if v == nil {
+		return
+	}
+	masked = masked || m.masks(name)
+	switch v.Kind {
+	case ast.ObjectValue:
+		for _, child := range v.Children {
+			m.value(child.Name, child.Value, masked)
+		}
+	case ast.ListValue:
+		for _, child := range v.Children {
+			m.value(name, child.Value, masked)
+		}
+	case ast.StringValue, ast.BlockValue:
+		if masked {
+			v.Kind, v.Raw = ast.StringValue, m.String(v.Raw)
+		}
+	case ast.IntValue, ast.FloatValue:
+		if masked {
+			v.Raw = "0"
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package capture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ReplayUserID is the user ID of the caller of replayed operations
+const ReplayUserID = "capture-replay"
+
+// Download is the body of a download of the captures from the admin API
+type Download struct {
+	Captures []Capture `json:"captures"`
+}
+
+// ReadCaptures reads the captures of a download from the admin API
+func ReadCaptures(r io.Reader) ([]Capture, error) {
+	var download Download
+	if err := json.NewDecoder(r).Decode(&download); err != nil {
+		return nil, fmt.Errorf("failed to read captures: %w", err)
+	}
+	return download.Captures, nil
+}
+
+// ReplayResult is the result of a replayed operation
+type ReplayResult struct {
+	ID        int64         `json:"id"`
+	Operation string        `json:"operation,omitempty"`
+	Captured  []ResultError `json:"captured,omitempty"` // Errors of the captured response
+	Replayed  []ResultError `json:"replayed,omitempty"` // Errors of the replayed response
+	Diverged  bool          `json:"diverged"`           // Whether the errors differ
+	Error     string        `json:"error,omitempty"`    // Why the operation could not be replayed
+}
+
+// Replay runs captured operations in order against a GraphQL handler, each as a caller
+// with the roles and scopes of its capture, and compares the errors of the responses
+// with those of the captures. The handler is called directly, so it must not require
+// authentication, which the context of the request carries instead: typically it is the
+// GraphQL server of a test container.
+//
+// Parameters:
+//   - ctx: The context of the replay
+//   - handler: The GraphQL handler the operations are sent to
+//   - captures: The captured operations
+//
+// Returns:
+//   - The result of each operation, in order
+func Replay(ctx context.Context, handler http.Handler, captures []Capture) []ReplayResult {
+	results := make([]ReplayResult, 0, len(captures))
+	for _, c := range captures {
+		result := ReplayResult{ID: c.ID, Operation: c.Operation, Captured: c.Result.Errors}
+		replayed, err := replay(ctx, handler, c)
+		if err != nil {
+			result.Error = err.Error()
+			result.Diverged = true
+		} else {
+			result.Replayed = replayed
+			result.Diverged = !sameErrors(c.Result.Errors, replayed)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// replay runs a captured operation and returns the errors of its response
+func replay(ctx context.Context, handler http.Handler, c Capture) ([]ResultError, error) {
+	if c.Query == "" {
+		return nil, fmt.Errorf("the query of the operation was not captured")
+	}
+	body, err := json.Marshal(map[string]any{
+		"query":         c.Query,
+		"operationName": c.Operation,
+		"variables":     c.Variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = middleware.WithUserID(ctx, ReplayUserID)
+	ctx = middleware.WithUserRoles(ctx, c.Roles)
+	ctx = middleware.WithUserScopes(ctx, c.Scopes)
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp struct {
+		Errors gqlerror.List `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("invalid response with status %d: %w", rec.Code, err)
+	}
+	var replayed []ResultError
+	for _, gqlErr := range resp.Errors {
+		replayed = append(replayed, resultError(gqlErr))
+	}
+	return replayed, nil
+}
+
+// sameErrors reports whether two responses have the same errors, in any order
+func sameErrors(a, b []ResultError) bool {
+	return slices.Equal(errorKeys(a), errorKeys(b))
+}
+
+// errorKeys returns the sorted paths and codes of errors
+func errorKeys(errs []ResultError) []string {
+	keys := make([]string, 0, len(errs))
+	for _, e := range errs {
+		keys = append(keys, e.Path+" "+e.Code)
+	}
+	sort.Strings(keys)
+	return keys
+}
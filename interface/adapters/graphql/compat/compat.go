@@ -0,0 +1,76 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package compat controls the compatibility mode of the GraphQL API during the
+// migration of mutations to payload types.
+//
+// Mutations that return payloads report expected failures in userErrors, and the
+// mutations they replace are marked as deprecated in the schema. While the
+// compatibility mode is on, deprecated mutations keep working so that clients can
+// migrate at their own pace. Once it is turned off, deprecated mutations are
+// rejected with a LEGACY_MUTATION_DISABLED error that names the replacement.
+package compat
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CodeLegacyMutationDisabled is the error code of a rejected deprecated mutation
+const CodeLegacyMutationDisabled = "LEGACY_MUTATION_DISABLED"
+
+// Extension is a gqlgen handler extension that rejects deprecated mutations
+// when the compatibility mode is off.
+type Extension struct {
+	legacyMutations bool
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = Extension{}
+
+// NewExtension creates a new Extension.
+//
+// Parameters:
+//   - legacyMutations: Whether deprecated mutations are still served
+func NewExtension(legacyMutations bool) Extension {
+	return Extension{legacyMutations: legacyMutations}
+}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "MutationCompatibility"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptField rejects deprecated mutations before their resolver runs
+func (e Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	if e.legacyMutations {
+		return next(ctx)
+	}
+
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Object != "Mutation" || fc.Field.Definition == nil {
+		return next(ctx)
+	}
+	deprecated := fc.Field.Definition.Directives.ForName("deprecated")
+	if deprecated == nil {
+		return next(ctx)
+	}
+
+	message := "mutation " + fc.Field.Name + " is no longer supported"
+	if reason := deprecated.Arguments.ForName("reason"); reason != nil && reason.Value != nil {
+		message += ": " + reason.Value.Raw
+	}
+	return nil, &gqlerror.Error{
+		Message:    message,
+		Path:       fc.Path(),
+		Extensions: map[string]interface{}{"code": CodeLegacyMutationDisabled},
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package compat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// fieldContext returns a context for resolving a field of the given object
+func fieldContext(object, name string, directives ast.DirectiveList) context.Context {
+	return graphql.WithFieldContext(context.Background(), &graphql.FieldContext{
+		Object: object,
+		Field: graphql.CollectedField{Field: &ast.Field{
+			Name:       name,
+			Alias:      name,
+			Definition: &ast.FieldDefinition{Name: name, Directives: directives},
+		}},
+	})
+}
+
+func TestExtension_InterceptField(t *testing.T) {
+	deprecated := ast.DirectiveList{{
+		Name: "deprecated",
+		Arguments: ast.ArgumentList{{
+			Name:  "reason",
+			Value: &ast.Value{Kind: ast.StringValue, Raw: "Use createFamilyV2"},
+		}},
+	}}
+	next := func(ctx context.Context) (any, error) { return "resolved", nil }
+
+	// Compatibility mode serves deprecated mutations
+	res, err := NewExtension(true).InterceptField(fieldContext("Mutation", "createFamily", deprecated), next)
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", res)
+
+	ext := NewExtension(false)
+
+	_, err = ext.InterceptField(fieldContext("Mutation", "createFamily", deprecated), next)
+	var gqlErr *gqlerror.Error
+	require.ErrorAs(t, err, &gqlErr)
+	assert.Equal(t, CodeLegacyMutationDisabled, gqlErr.Extensions["code"])
+	assert.Equal(t, "mutation createFamily is no longer supported: Use createFamilyV2", gqlErr.Message)
+
+	// Current mutations and deprecated fields of other types are not affected
+	res, err = ext.InterceptField(fieldContext("Mutation", "createFamilyV2", nil), next)
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", res)
+
+	res, err = ext.InterceptField(fieldContext("Family", "legacyField", deprecated), next)
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", res)
+}
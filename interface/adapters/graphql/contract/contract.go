@@ -0,0 +1,163 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package contract checks the GraphQL schema against the operations of its registered
+// consumers, so that a schema change that would break a consumer is caught before it ships.
+//
+// Consumers register the operations they send in the contract registry, a directory with
+// a subdirectory per consumer holding its operation documents (*.graphql):
+//
+//	contracts/
+//	  web-frontend/
+//	    family.graphql
+//	  mobile-app/
+//	    children.graphql
+//
+// Every document is validated against the schema as the GraphQL endpoint would validate
+// it, so removing or renaming a field, argument, type, or enum value that a consumer uses,
+// or making an argument it omits required, is reported as a violation that names the
+// consumer, the document, and its location. The registry is checked by the schema pipeline
+// before code generation, by the tests of this package, and at startup.
+package contract
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// DocumentExtension is the extension of the operation documents of the registry
+const DocumentExtension = ".graphql"
+
+// Registry is the set of consumers whose operations the schema must keep serving
+type Registry struct {
+	Consumers []Consumer
+}
+
+// Consumer is a client of the API and the operation documents it sends
+type Consumer struct {
+	Name      string
+	Documents []Document
+}
+
+// Document is an operation document of a consumer
+type Document struct {
+	Path  string // Path of the document, relative to the registry
+	Query string
+}
+
+// Violation is an error that the schema reports for an operation of a consumer
+type Violation struct {
+	Consumer string
+	Document string // Path of the document, relative to the registry
+	Line     int    // Line of the error in the document, 0 if unknown
+	Message  string
+}
+
+// String returns the violation as document:line: message; the path of the document
+// starts with the name of the consumer
+func (v Violation) String() string {
+	if v.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", v.Document, v.Line, v.Message)
+	}
+	return fmt.Sprintf("%s: %s", v.Document, v.Message)
+}
+
+// LoadRegistry loads the registry from a directory. Files at the top of the directory,
+// such as its README, are ignored, and so are files below a consumer that are not
+// operation documents. Consumers and documents are sorted by name.
+func LoadRegistry(dir string) (*Registry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contract registry: %w", err)
+	}
+
+	registry := &Registry{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		consumer := Consumer{Name: entry.Name()}
+		err := filepath.WalkDir(filepath.Join(dir, entry.Name()), func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(path) != DocumentExtension {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			consumer.Documents = append(consumer.Documents, Document{Path: filepath.ToSlash(rel), Query: string(content)})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the documents of consumer %s: %w", entry.Name(), err)
+		}
+		if len(consumer.Documents) > 0 {
+			sort.Slice(consumer.Documents, func(i, j int) bool { return consumer.Documents[i].Path < consumer.Documents[j].Path })
+			registry.Consumers = append(registry.Consumers, consumer)
+		}
+	}
+	sort.Slice(registry.Consumers, func(i, j int) bool { return registry.Consumers[i].Name < registry.Consumers[j].Name })
+	return registry, nil
+}
+
+// Operations returns the number of operations in the registry
+func (r *Registry) Operations() int {
+	count := 0
+	for _, consumer := range r.Consumers {
+		for _, doc := range consumer.Documents {
+			if parsed, err := parser.ParseQuery(&ast.Source{Name: doc.Path, Input: doc.Query}); err == nil {
+				count += len(parsed.Operations)
+			}
+		}
+	}
+	return count
+}
+
+// Check validates every document of the registry against a schema and returns the
+// violations, in registry order. A registry without violations is served by the schema.
+func Check(schema *ast.Schema, registry *Registry) []Violation {
+	var violations []Violation
+	for _, consumer := range registry.Consumers {
+		for _, doc := range consumer.Documents {
+			_, errs := gqlparser.LoadQuery(schema, doc.Query)
+			for _, err := range errs {
+				violations = append(violations, violation(consumer.Name, doc.Path, err))
+			}
+		}
+	}
+	return violations
+}
+
+// Consumers returns the names of the consumers with violations, sorted
+func Consumers(violations []Violation) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, v := range violations {
+		if !seen[v.Consumer] {
+			seen[v.Consumer] = true
+			names = append(names, v.Consumer)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// violation converts a validation error of a document to a violation
+func violation(consumer, path string, err *gqlerror.Error) Violation {
+	v := Violation{Consumer: consumer, Document: path, Message: strings.TrimSpace(err.Message)}
+	if len(err.Locations) > 0 {
+		v.Line = err.Locations[0].Line
+	}
+	return v
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package contract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/generated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// registryDir is the contract registry of the repository
+const registryDir = "../../../../contracts"
+
+const testSchema = `
+type Query {
+  family(id: ID!): Family
+}
+
+type Family {
+  id: ID!
+  status: String!
+}
+`
+
+// writeRegistry writes a registry with the given documents, keyed by path
+func writeRegistry(t *testing.T, documents map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Contracts"), 0o644))
+	for path, content := range documents {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestLoadRegistry(t *testing.T) {
+	dir := writeRegistry(t, map[string]string{
+		"web/b.graphql":         "query B { family(id: 1) { id } }",
+		"web/nested/a.graphql":  "query A { family(id: 1) { id } } query C { family(id: 2) { status } }",
+		"web/notes.txt":         "not an operation",
+		"mobile/family.graphql": "query M { family(id: 1) { status } }",
+		"empty/notes.txt":       "no documents",
+	})
+
+	registry, err := LoadRegistry(dir)
+	require.NoError(t, err)
+	require.Len(t, registry.Consumers, 2)
+	assert.Equal(t, "mobile", registry.Consumers[0].Name)
+	assert.Equal(t, "web", registry.Consumers[1].Name)
+	assert.Equal(t, "web/b.graphql", registry.Consumers[1].Documents[0].Path)
+	assert.Equal(t, "web/nested/a.graphql", registry.Consumers[1].Documents[1].Path)
+	assert.Equal(t, 4, registry.Operations())
+
+	_, err = LoadRegistry(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphql", Input: testSchema})
+	registry, err := LoadRegistry(writeRegistry(t, map[string]string{
+		"web/family.graphql":    "query Family($id: ID!) {\n  family(id: $id) {\n    id\n    status\n  }\n}\n",
+		"mobile/family.graphql": "query Family($id: ID!) {\n  family(id: $id) {\n    id\n    name\n  }\n}\n",
+	}))
+	require.NoError(t, err)
+
+	violations := Check(schema, registry)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "mobile", violations[0].Consumer)
+	assert.Equal(t, 4, violations[0].Line)
+	assert.Contains(t, violations[0].String(), `mobile/family.graphql:4: Cannot query field "name" on type "Family".`)
+	assert.Equal(t, []string{"mobile"}, Consumers(violations))
+
+	// Removing a field breaks every consumer that selects it
+	schema = gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphql", Input: `
+type Query {
+  family(id: ID!): Family
+}
+
+type Family {
+  id: ID!
+}
+`})
+	assert.Equal(t, []string{"mobile", "web"}, Consumers(Check(schema, registry)))
+}
+
+// TestRegistry fails when the schema no longer serves an operation of a registered consumer
+func TestRegistry(t *testing.T) {
+	registry, err := LoadRegistry(registryDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, registry.Consumers)
+
+	schema := generated.NewExecutableSchema(generated.Config{}).Schema()
+	for _, v := range Check(schema, registry) {
+		t.Errorf("schema breaks consumer %s: %s", v.Consumer, v)
+	}
+}
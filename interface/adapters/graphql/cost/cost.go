@@ -0,0 +1,111 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package cost estimates the cost of GraphQL operations without executing them.
+//
+// The server rejects operations whose complexity exceeds its limit before running any
+// resolver. Clients can send an operation to the estimateQueryCost query to learn its
+// complexity and whether it would be accepted, so that they can tune their queries
+// before sending them. Estimates use the same parser, validation rules, and complexity
+// calculation as the server.
+package cost
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	servicegraphql "github.com/abitofhelp/servicelib/graphql"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Estimate is the estimated cost of an operation
+type Estimate struct {
+	Complexity    int      // Complexity of the operation, as calculated by the server
+	MaxComplexity int      // Maximum complexity the server accepts
+	Depth         int      // Depth of the deepest field selection of the operation
+	Accepted      bool     // Whether the server would accept the operation
+	Errors        []string // Reasons the server would reject the operation
+}
+
+// Estimator estimates the cost of operations against an executable schema
+type Estimator struct {
+	schema        graphql.ExecutableSchema
+	maxComplexity int
+}
+
+// NewEstimator creates a new Estimator.
+//
+// Parameters:
+//   - schema: Executable schema that operations run against
+//   - maxComplexity: Maximum complexity the server accepts
+func NewEstimator(schema graphql.ExecutableSchema, maxComplexity int) *Estimator {
+	return &Estimator{schema: schema, maxComplexity: maxComplexity}
+}
+
+// MaxComplexity returns the maximum complexity a server with a configuration accepts.
+// The servicelib server applies MaxQueryDepth as a second complexity limit, so the
+// smaller of the two limits applies.
+func MaxComplexity(cfg servicegraphql.ServerConfig) int {
+	if cfg.MaxQueryDepth < cfg.MaxQueryComplexity {
+		return cfg.MaxQueryDepth
+	}
+	return cfg.MaxQueryComplexity
+}
+
+// Estimate parses, validates, and scores an operation of a query document. The
+// operation name may be empty if the document has a single operation. Documents that
+// cannot be parsed or validated are not accepted, and their errors are reported.
+func (e *Estimator) Estimate(ctx context.Context, query, operationName string) *Estimate {
+	estimate := &Estimate{MaxComplexity: e.maxComplexity, Errors: []string{}}
+
+	doc, errs := gqlparser.LoadQuery(e.schema.Schema(), query)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			estimate.Errors = append(estimate.Errors, err.Message)
+		}
+		return estimate
+	}
+
+	op := doc.Operations.ForName(operationName)
+	if op == nil {
+		if operationName == "" {
+			estimate.Errors = append(estimate.Errors, "operation name is required when the document has several operations")
+		} else {
+			estimate.Errors = append(estimate.Errors, fmt.Sprintf("operation %s not found", operationName))
+		}
+		return estimate
+	}
+
+	estimate.Complexity = complexity.Calculate(ctx, e.schema, op, nil)
+	estimate.Depth = depth(op.SelectionSet)
+	if estimate.Complexity > e.maxComplexity {
+		// The message of the error the server returns
+		estimate.Errors = append(estimate.Errors, fmt.Sprintf("operation has complexity %d, which exceeds the limit of %d", estimate.Complexity, e.maxComplexity))
+		return estimate
+	}
+
+	estimate.Accepted = true
+	return estimate
+}
+
+// depth returns the depth of the deepest field of a selection set, following fragments
+func depth(selectionSet ast.SelectionSet) int {
+	deepest := 0
+	for _, selection := range selectionSet {
+		var d int
+		switch s := selection.(type) {
+		case *ast.Field:
+			d = 1 + depth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			d = depth(s.Definition.SelectionSet)
+		case *ast.InlineFragment:
+			d = depth(s.SelectionSet)
+		}
+		if d > deepest {
+			deepest = d
+		}
+	}
+	return deepest
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package cost_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/cost"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/generated"
+	servicegraphql "github.com/abitofhelp/servicelib/graphql"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const familiesQuery = `{ getAllFamilies { id parents { id firstName } } }`
+
+func TestEstimator_Estimate(t *testing.T) {
+	estimator := cost.NewEstimator(generated.NewExecutableSchema(generated.Config{}), 10)
+
+	testCases := []struct {
+		name          string
+		query         string
+		operationName string
+		complexity    int
+		depth         int
+		accepted      bool
+		errMsg        string
+	}{
+		{"nested selections", familiesQuery, "", 5, 3, true, ""},
+		{"fragments", `query Q { getAllFamilies { ...F } } fragment F on Family { id children { id } }`, "Q", 4, 3, true, ""},
+		{"named operation", `query A { countFamilies } query B { countParents countChildren }`, "B", 2, 1, true, ""},
+		{"over the limit", `{ getAllFamilies { id status parentCount childrenCount parents { id firstName lastName } children { id firstName lastName } } }`, "", 13, 3, false, "operation has complexity 13, which exceeds the limit of 10"},
+		{"parse error", `{ getAllFamilies { id }`, "", 0, 0, false, "Expected Name"},
+		{"validation error", `{ getAllFamilies { unknown } }`, "", 0, 0, false, `Cannot query field "unknown" on type "Family"`},
+		{"missing operation", `query A { countFamilies }`, "B", 0, 0, false, "operation B not found"},
+		{"ambiguous operation", `query A { countFamilies } query B { countParents }`, "", 0, 0, false, "operation name is required"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			estimate := estimator.Estimate(context.Background(), tc.query, tc.operationName)
+			assert.Equal(t, tc.complexity, estimate.Complexity)
+			assert.Equal(t, tc.depth, estimate.Depth)
+			assert.Equal(t, 10, estimate.MaxComplexity)
+			assert.Equal(t, tc.accepted, estimate.Accepted)
+			if tc.errMsg == "" {
+				assert.Empty(t, estimate.Errors)
+			} else {
+				require.NotEmpty(t, estimate.Errors)
+				assert.Contains(t, estimate.Errors[0], tc.errMsg)
+			}
+		})
+	}
+}
+
+// TestMaxComplexity tests that estimates use the limit the servicelib server enforces,
+// by comparing an estimate with the server's rejection of the same operation
+func TestMaxComplexity(t *testing.T) {
+	schema := generated.NewExecutableSchema(generated.Config{})
+	cfg := servicegraphql.ServerConfig{MaxQueryDepth: 4, MaxQueryComplexity: 100, RequestTimeout: time.Second}
+	require.Equal(t, 4, cost.MaxComplexity(cfg))
+	assert.Equal(t, 100, cost.MaxComplexity(servicegraphql.ServerConfig{MaxQueryDepth: 200, MaxQueryComplexity: 100}))
+
+	estimate := cost.NewEstimator(schema, cost.MaxComplexity(cfg)).Estimate(context.Background(), familiesQuery, "")
+	require.False(t, estimate.Accepted)
+
+	server := servicegraphql.NewServer(schema, logging.NewContextLogger(zap.NewNop()), cfg)
+	body, err := json.Marshal(map[string]string{"query": familiesQuery})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var response struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.NotEmpty(t, response.Errors)
+	assert.Equal(t, estimate.Errors[0], response.Errors[0].Message)
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package docs serves a read-only documentation portal generated from the GraphQL schema.
+//
+// The portal lists the queries and mutations with their arguments, the roles and scope
+// they require, and the examples attached with the @example directive, followed by the
+// types, enums, inputs, and custom directives of the schema. Deprecated fields and enum
+// values are flagged with their deprecation reason. The page is rendered once from the
+// executable schema when the handler is created, and its template is embedded in the
+// binary, so the portal can be served in production without the playground.
+package docs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/authz"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/schemalint"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+//go:embed portal.html.tmpl
+var portalTemplate string
+
+// page is the parsed portal template
+var page = template.Must(template.New("portal").Parse(portalTemplate))
+
+// Portal is the documentation of a GraphQL schema
+type Portal struct {
+	Title      string
+	Endpoint   string // Path of the GraphQL endpoint that serves the schema
+	Queries    []Field
+	Mutations  []Field
+	Types      []Type
+	Directives []Directive
+}
+
+// Field is a field of a type, or a query or mutation
+type Field struct {
+	Name        string
+	Type        string
+	Description string
+	Arguments   []Argument
+	Deprecation *string  // Reason the field is deprecated, nil if it is not
+	Roles       []string // Roles allowed by the @isAuthorized directive
+	Scope       string   // Fine-grained scope required by the operation
+	Examples    []Example
+}
+
+// Argument is an argument of a field or directive, or a field of an input type
+type Argument struct {
+	Name        string
+	Type        string
+	Description string
+	Default     string
+}
+
+// Example is an example operation attached to a field with the @example directive
+type Example struct {
+	Title string
+	Query string
+}
+
+// Type is a named object, input, enum, interface, union, or scalar type
+type Type struct {
+	Name        string
+	Kind        string
+	Description string
+	Fields      []Field
+	Inputs      []Argument
+	Values      []EnumValue
+	Members     []string // Types of a union
+}
+
+// EnumValue is a value of an enum type
+type EnumValue struct {
+	Name        string
+	Description string
+	Deprecation *string
+}
+
+// Directive is a directive defined by the schema
+type Directive struct {
+	Name        string
+	Description string
+	Arguments   []Argument
+	Locations   []string
+	Repeatable  bool
+}
+
+// NewPortal builds the documentation of a schema. Built-in types and directives, and the
+// lintIgnore directive, which only applies to the schema lint, are omitted.
+func NewPortal(title string, schema *ast.Schema) Portal {
+	portal := Portal{Title: title}
+	if schema.Query != nil {
+		portal.Queries = operations(schema.Query)
+	}
+	if schema.Mutation != nil {
+		portal.Mutations = operations(schema.Mutation)
+	}
+
+	names := make([]string, 0, len(schema.Types))
+	for name, def := range schema.Types {
+		if def.BuiltIn || def == schema.Query || def == schema.Mutation || def == schema.Subscription {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		portal.Types = append(portal.Types, newType(schema.Types[name]))
+	}
+
+	names = names[:0]
+	for name, def := range schema.Directives {
+		if def.Position != nil && def.Position.Src != nil && def.Position.Src.BuiltIn || name == schemalint.DirectiveLintIgnore {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def := schema.Directives[name]
+		directive := Directive{
+			Name:        def.Name,
+			Description: def.Description,
+			Arguments:   arguments(def.Arguments),
+			Repeatable:  def.IsRepeatable,
+		}
+		for _, location := range def.Locations {
+			directive.Locations = append(directive.Locations, string(location))
+		}
+		portal.Directives = append(portal.Directives, directive)
+	}
+	return portal
+}
+
+// operations documents the fields of the query or mutation type, in schema order
+func operations(def *ast.Definition) []Field {
+	fields := make([]Field, 0, len(def.Fields))
+	for _, f := range def.Fields {
+		if strings.HasPrefix(f.Name, "__") {
+			continue
+		}
+		field := newField(f)
+		if scope, ok := authz.Operations[f.Name]; ok {
+			field.Scope = string(scope)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// newField documents a field
+func newField(f *ast.FieldDefinition) Field {
+	field := Field{
+		Name:        f.Name,
+		Type:        f.Type.String(),
+		Description: f.Description,
+		Arguments:   arguments(f.Arguments),
+		Deprecation: deprecation(f.Directives),
+	}
+	if auth := f.Directives.ForName("isAuthorized"); auth != nil {
+		if roles := auth.Arguments.ForName("allowedRoles"); roles != nil && roles.Value != nil {
+			for _, role := range roles.Value.Children {
+				field.Roles = append(field.Roles, role.Value.Raw)
+			}
+		}
+	}
+	for _, d := range f.Directives.ForNames("example") {
+		example := Example{Title: f.Name}
+		if title := d.Arguments.ForName("title"); title != nil && title.Value != nil && title.Value.Raw != "" {
+			example.Title = title.Value.Raw
+		}
+		if query := d.Arguments.ForName("query"); query != nil && query.Value != nil {
+			example.Query = query.Value.Raw
+		}
+		field.Examples = append(field.Examples, example)
+	}
+	return field
+}
+
+// newType documents a named type
+func newType(def *ast.Definition) Type {
+	t := Type{
+		Name:        def.Name,
+		Kind:        strings.ToLower(string(def.Kind)),
+		Description: def.Description,
+		Members:     def.Types,
+	}
+	switch def.Kind {
+	case ast.InputObject:
+		for _, f := range def.Fields {
+			t.Inputs = append(t.Inputs, newArgument(f.Name, f.Type, f.Description, f.DefaultValue))
+		}
+	case ast.Enum:
+		for _, v := range def.EnumValues {
+			t.Values = append(t.Values, EnumValue{Name: v.Name, Description: v.Description, Deprecation: deprecation(v.Directives)})
+		}
+	default:
+		for _, f := range def.Fields {
+			if strings.HasPrefix(f.Name, "__") {
+				continue
+			}
+			t.Fields = append(t.Fields, newField(f))
+		}
+	}
+	return t
+}
+
+// arguments documents the arguments of a field or directive
+func arguments(defs ast.ArgumentDefinitionList) []Argument {
+	args := make([]Argument, 0, len(defs))
+	for _, a := range defs {
+		args = append(args, newArgument(a.Name, a.Type, a.Description, a.DefaultValue))
+	}
+	return args
+}
+
+// newArgument documents an argument or input field
+func newArgument(name string, typ *ast.Type, description string, defaultValue *ast.Value) Argument {
+	arg := Argument{Name: name, Type: typ.String(), Description: description}
+	if defaultValue != nil {
+		arg.Default = defaultValue.String()
+	}
+	return arg
+}
+
+// deprecation returns the reason given by a @deprecated directive, or nil if there is none
+func deprecation(directives ast.DirectiveList) *string {
+	d := directives.ForName("deprecated")
+	if d == nil {
+		return nil
+	}
+	reason := "No longer supported"
+	if arg := d.Arguments.ForName("reason"); arg != nil && arg.Value != nil {
+		reason = arg.Value.Raw
+	}
+	return &reason
+}
+
+// Render renders the portal as an HTML page
+func (p Portal) Render() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, p); err != nil {
+		return nil, fmt.Errorf("failed to render documentation portal: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// NewHandler returns an HTTP handler that serves the documentation portal of a schema.
+// The page is rendered once; it changes only when the server is rebuilt with a new schema.
+// The endpoint is the path of the GraphQL endpoint, which the page names.
+func NewHandler(title, endpoint string, schema *ast.Schema) (http.Handler, error) {
+	portal := NewPortal(title, schema)
+	portal.Endpoint = endpoint
+	body, err := portal.Render()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+		if r.Method == http.MethodHead {
+			return
+		}
+		_, _ = w.Write(body)
+	}), nil
+}
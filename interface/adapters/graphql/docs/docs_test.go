@@ -0,0 +1,118 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package docs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/generated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// findField returns the field with the given name
+func findField(t *testing.T, fields []Field, name string) Field {
+	t.Helper()
+	for _, f := range fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	t.Fatalf("field %s not found", name)
+	return Field{}
+}
+
+func TestNewPortal_FamilySchema(t *testing.T) {
+	schema := generated.NewExecutableSchema(generated.Config{}).Schema()
+	portal := NewPortal("Family Service", schema)
+
+	getFamily := findField(t, portal.Queries, "getFamily")
+	assert.Equal(t, "Family", getFamily.Type)
+	assert.Equal(t, []string{"ADMIN", "EDITOR", "VIEWER"}, getFamily.Roles)
+	assert.Equal(t, "family:read", getFamily.Scope)
+	require.Len(t, getFamily.Examples, 1)
+	assert.Equal(t, "getFamily", getFamily.Examples[0].Title)
+	assert.Contains(t, getFamily.Examples[0].Query, `getFamily(id: "family-123")`)
+	require.Len(t, getFamily.Arguments, 1)
+	assert.Equal(t, Argument{Name: "id", Type: "ID!", Description: "Unique identifier of the family to retrieve"}, getFamily.Arguments[0])
+
+	deleteFamily := findField(t, portal.Mutations, "deleteFamily")
+	assert.Equal(t, "family:delete", deleteFamily.Scope)
+
+	// Every operation has an example
+	for _, op := range append(append([]Field(nil), portal.Queries...), portal.Mutations...) {
+		assert.NotEmpty(t, op.Examples, "operation %s has no example", op.Name)
+	}
+
+	// Built-in types and directives are omitted, custom ones are included
+	var typeNames, directiveNames []string
+	for _, typ := range portal.Types {
+		typeNames = append(typeNames, typ.Name)
+	}
+	for _, d := range portal.Directives {
+		directiveNames = append(directiveNames, d.Name)
+	}
+	assert.Contains(t, typeNames, "Family")
+	assert.Contains(t, typeNames, "FamilyStatus")
+	assert.NotContains(t, typeNames, "String")
+	assert.NotContains(t, typeNames, "Query")
+	assert.ElementsMatch(t, []string{"example", "isAuthorized"}, directiveNames)
+}
+
+func TestNewPortal_Deprecations(t *testing.T) {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Name: "test.graphql", Input: `
+		directive @example(title: String, query: String!) repeatable on FIELD_DEFINITION
+		type Query {
+			"Old lookup"
+			old: String @deprecated(reason: "Use new")
+			new: String @example(title: "Lookup", query: "{ new }")
+		}
+		enum Color { RED BLUE @deprecated }
+	`})
+	portal := NewPortal("Test", schema)
+
+	old := findField(t, portal.Queries, "old")
+	require.NotNil(t, old.Deprecation)
+	assert.Equal(t, "Use new", *old.Deprecation)
+	assert.Empty(t, old.Scope, "fields missing from the authorization table have no scope")
+
+	fresh := findField(t, portal.Queries, "new")
+	assert.Nil(t, fresh.Deprecation)
+	assert.Equal(t, []Example{{Title: "Lookup", Query: "{ new }"}}, fresh.Examples)
+
+	require.Len(t, portal.Types, 1)
+	require.Len(t, portal.Types[0].Values, 2)
+	assert.Nil(t, portal.Types[0].Values[0].Deprecation)
+	require.NotNil(t, portal.Types[0].Values[1].Deprecation)
+	assert.Equal(t, "No longer supported", *portal.Types[0].Values[1].Deprecation)
+}
+
+func TestNewHandler(t *testing.T) {
+	handler, err := NewHandler("Family Service", "/family-service/graphql", generated.NewExecutableSchema(generated.Config{}).Schema())
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `id="mutation-createFamily"`)
+	assert.Contains(t, rec.Body.String(), "family:create")
+	assert.Contains(t, rec.Body.String(), "served at <code>/family-service/graphql</code>")
+
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/docs", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+}
@@ -3,10 +3,15 @@
 package dto
 
 import (
+	"encoding/base64"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/abitofhelp/family-service/core/domain/diff"
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/policy"
+	"github.com/abitofhelp/family-service/core/domain/query"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
 	"github.com/abitofhelp/servicelib/valueobject/identification"
 )
@@ -28,13 +33,22 @@ type FamilyMapper interface {
 }
 
 // familyMapper implements FamilyMapper
-type familyMapper struct{}
+type familyMapper struct {
+	consentPolicy *policy.ConsentPolicy // Withholds data of minors without consent (nil withholds nothing)
+}
 
 // NewFamilyMapper creates a new instance of FamilyMapper
 func NewFamilyMapper() FamilyMapper {
 	return &familyMapper{}
 }
 
+// NewFamilyMapperWithConsentPolicy creates a new instance of FamilyMapper that withholds
+// the data of minors not covered by a current consent from the GraphQL models.
+// Redaction is applied only to output, so that data withheld from a response is kept in storage.
+func NewFamilyMapperWithConsentPolicy(consentPolicy *policy.ConsentPolicy) FamilyMapper {
+	return &familyMapper{consentPolicy: consentPolicy}
+}
+
 func (m *familyMapper) ToDomain(input model.FamilyInput) (entity.FamilyDTO, error) {
 	if input.ID == "" {
 		return entity.FamilyDTO{}, fmt.Errorf("invalid ID: ID cannot be empty")
@@ -66,11 +80,17 @@ func (m *familyMapper) ToDomain(input model.FamilyInput) (entity.FamilyDTO, erro
 		children = append(children, child)
 	}
 
+	externalIDs, err := toExternalIDs(input.ExternalIds)
+	if err != nil {
+		return entity.FamilyDTO{}, err
+	}
+
 	return entity.FamilyDTO{
-		ID:       input.ID.String(),
-		Status:   status,
-		Parents:  parents,
-		Children: children,
+		ID:          input.ID.String(),
+		Status:      status,
+		Parents:     parents,
+		Children:    children,
+		ExternalIDs: externalIDs,
 	}, nil
 }
 
@@ -105,11 +125,21 @@ func (m *familyMapper) ToGraphQL(dto entity.FamilyDTO) (*model.Family, error) {
 		return nil, fmt.Errorf("invalid family status: %s", dto.Status)
 	}
 
+	// A family without an owner has no owner ID
+	var ownerID *string
+	if dto.OwnerID != "" {
+		ownerID = &dto.OwnerID
+	}
+
 	return &model.Family{
-		ID:       identification.ID(dto.ID),
-		Status:   status,
-		Parents:  parents,
-		Children: children,
+		ID:          identification.ID(dto.ID),
+		Status:      status,
+		Parents:     parents,
+		Children:    children,
+		ExternalIds: toGraphQLExternalIDs(dto.ExternalIDs),
+		OwnerID:     ownerID,
+		Checksum:    dto.Checksum(),
+		Version:     dto.Version,
 	}, nil
 }
 
@@ -118,29 +148,32 @@ func (m *familyMapper) ToParentDTO(input model.ParentInput) (entity.ParentDTO, e
 		return entity.ParentDTO{}, fmt.Errorf("invalid ID: ID cannot be empty")
 	}
 
-	birthDate, err := time.Parse(RFC3339DateFormat, input.BirthDate)
-	if err != nil {
-		return entity.ParentDTO{}, fmt.Errorf("invalid birth date: %w", err)
+	if input.BirthDate.IsZero() {
+		return entity.ParentDTO{}, fmt.Errorf("invalid birth date: birth date is required")
 	}
+	birthDate := input.BirthDate.Time()
 
 	var deathDate *time.Time
 	if input.DeathDate != nil {
-		parsed, err := time.Parse(RFC3339DateFormat, *input.DeathDate)
-		if err != nil {
-			return entity.ParentDTO{}, fmt.Errorf("invalid death date: %w", err)
-		}
-		if parsed.Before(birthDate) {
+		if input.DeathDate.Before(input.BirthDate) {
 			return entity.ParentDTO{}, fmt.Errorf("death date cannot be before birth date")
 		}
+		parsed := input.DeathDate.Time()
 		deathDate = &parsed
 	}
 
+	externalIDs, err := toExternalIDs(input.ExternalIds)
+	if err != nil {
+		return entity.ParentDTO{}, err
+	}
+
 	return entity.ParentDTO{
-		ID:        input.ID.String(),
-		FirstName: input.FirstName,
-		LastName:  input.LastName,
-		BirthDate: birthDate,
-		DeathDate: deathDate,
+		ID:          input.ID.String(),
+		FirstName:   input.FirstName,
+		LastName:    input.LastName,
+		BirthDate:   birthDate,
+		DeathDate:   deathDate,
+		ExternalIDs: externalIDs,
 	}, nil
 }
 
@@ -149,29 +182,32 @@ func (m *familyMapper) ToChildDTO(input model.ChildInput) (entity.ChildDTO, erro
 		return entity.ChildDTO{}, fmt.Errorf("invalid ID: ID cannot be empty")
 	}
 
-	birthDate, err := time.Parse(RFC3339DateFormat, input.BirthDate)
-	if err != nil {
-		return entity.ChildDTO{}, fmt.Errorf("invalid birth date: %w", err)
+	if input.BirthDate.IsZero() {
+		return entity.ChildDTO{}, fmt.Errorf("invalid birth date: birth date is required")
 	}
+	birthDate := input.BirthDate.Time()
 
 	var deathDate *time.Time
 	if input.DeathDate != nil {
-		parsed, err := time.Parse(RFC3339DateFormat, *input.DeathDate)
-		if err != nil {
-			return entity.ChildDTO{}, fmt.Errorf("invalid death date: %w", err)
-		}
-		if parsed.Before(birthDate) {
+		if input.DeathDate.Before(input.BirthDate) {
 			return entity.ChildDTO{}, fmt.Errorf("death date cannot be before birth date")
 		}
+		parsed := input.DeathDate.Time()
 		deathDate = &parsed
 	}
 
+	externalIDs, err := toExternalIDs(input.ExternalIds)
+	if err != nil {
+		return entity.ChildDTO{}, err
+	}
+
 	return entity.ChildDTO{
-		ID:        input.ID.String(),
-		FirstName: input.FirstName,
-		LastName:  input.LastName,
-		BirthDate: birthDate,
-		DeathDate: deathDate,
+		ID:          input.ID.String(),
+		FirstName:   input.FirstName,
+		LastName:    input.LastName,
+		BirthDate:   birthDate,
+		DeathDate:   deathDate,
+		ExternalIDs: externalIDs,
 	}, nil
 }
 
@@ -180,18 +216,21 @@ func (m *familyMapper) ToParent(dto entity.ParentDTO) (*model.Parent, error) {
 		return nil, fmt.Errorf("invalid ID: ID cannot be empty")
 	}
 
-	var deathDate *string
+	var deathDate *entity.Date
 	if dto.DeathDate != nil {
-		formatted := dto.DeathDate.Format(RFC3339DateFormat)
-		deathDate = &formatted
+		date := entity.DateOf(*dto.DeathDate)
+		deathDate = &date
 	}
 
 	return &model.Parent{
-		ID:        identification.ID(dto.ID),
-		FirstName: dto.FirstName,
-		LastName:  dto.LastName,
-		BirthDate: dto.BirthDate.Format(RFC3339DateFormat),
-		DeathDate: deathDate,
+		ID:            identification.ID(dto.ID),
+		FirstName:     dto.FirstName,
+		LastName:      dto.LastName,
+		BirthDate:     entity.DateOf(dto.BirthDate),
+		DeathDate:     deathDate,
+		ExternalIds:   toGraphQLExternalIDs(dto.ExternalIDs),
+		PreferredName: toGraphQLPreferredName(dto.PreferredName),
+		NameHistory:   toGraphQLNameHistory(dto.NameHistory),
 	}, nil
 }
 
@@ -200,17 +239,278 @@ func (m *familyMapper) ToChild(dto entity.ChildDTO) (*model.Child, error) {
 		return nil, fmt.Errorf("invalid ID: ID cannot be empty")
 	}
 
-	var deathDate *string
+	var deathDate *entity.Date
 	if dto.DeathDate != nil {
-		formatted := dto.DeathDate.Format(RFC3339DateFormat)
-		deathDate = &formatted
+		date := entity.DateOf(*dto.DeathDate)
+		deathDate = &date
 	}
 
+	withheld := m.consentPolicy.Redact(&dto, time.Now())
+
 	return &model.Child{
-		ID:        identification.ID(dto.ID),
-		FirstName: dto.FirstName,
-		LastName:  dto.LastName,
-		BirthDate: dto.BirthDate.Format(RFC3339DateFormat),
-		DeathDate: deathDate,
+		ID:             identification.ID(dto.ID),
+		FirstName:      dto.FirstName,
+		LastName:       dto.LastName,
+		BirthDate:      entity.DateOf(dto.BirthDate),
+		DeathDate:      deathDate,
+		ExternalIds:    toGraphQLExternalIDs(dto.ExternalIDs),
+		PreferredName:  toGraphQLPreferredName(dto.PreferredName),
+		NameHistory:    toGraphQLNameHistory(dto.NameHistory),
+		Consents:       toGraphQLConsents(dto.Consents),
+		WithheldScopes: toGraphQLConsentScopes(withheld),
+	}, nil
+}
+
+// ToNameChange converts a name change input to a domain name change
+func ToNameChange(input model.NameChangeInput) (entity.NameChange, error) {
+	effectiveDate, err := time.Parse(RFC3339DateFormat, input.EffectiveDate)
+	if err != nil {
+		return entity.NameChange{}, fmt.Errorf("invalid effective date: %w", err)
+	}
+
+	var reason entity.NameChangeReason
+	if input.Reason != nil {
+		reason = entity.NameChangeReason(*input.Reason)
+	}
+
+	return entity.NameChange{
+		FirstName:     input.FirstName,
+		LastName:      input.LastName,
+		EffectiveDate: effectiveDate,
+		Reason:        reason,
+	}, nil
+}
+
+// ToMemberDetails converts a member details input to domain corrections of a member's details
+func ToMemberDetails(input model.MemberDetailsInput) entity.MemberDetails {
+	var details entity.MemberDetails
+	if input.FirstName != nil {
+		details.FirstName = *input.FirstName
+	}
+	if input.LastName != nil {
+		details.LastName = *input.LastName
+	}
+	if input.BirthDate != nil {
+		details.BirthDate = input.BirthDate.Time()
+	}
+	return details
+}
+
+// ToGraphQLNameChange converts a domain name change to a GraphQL name change
+func ToGraphQLNameChange(change entity.NameChange) *model.NameChange {
+	var reason *model.NameChangeReason
+	if change.Reason != "" {
+		r := model.NameChangeReason(change.Reason)
+		reason = &r
+	}
+
+	return &model.NameChange{
+		FirstName:     change.FirstName,
+		LastName:      change.LastName,
+		EffectiveDate: change.EffectiveDate.Format(RFC3339DateFormat),
+		Reason:        reason,
+	}
+}
+
+// ToConsent converts a consent input to a domain consent granted at the given time,
+// or at the time in the input if it has one
+func ToConsent(input model.ConsentInput, now time.Time) (entity.Consent, error) {
+	grantedAt := now
+	if input.GrantedAt != nil {
+		parsed, err := time.Parse(RFC3339DateFormat, *input.GrantedAt)
+		if err != nil {
+			return entity.Consent{}, fmt.Errorf("invalid grant time: %w", err)
+		}
+		grantedAt = parsed
+	}
+
+	scopes := make([]entity.ConsentScope, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		scopes = append(scopes, entity.ConsentScope(scope))
+	}
+
+	return entity.Consent{
+		GrantedBy: input.GrantedBy.String(),
+		GrantedAt: grantedAt,
+		Scopes:    scopes,
 	}, nil
 }
+
+// ToGraphQLQuarantine converts the quarantine of a family to a GraphQL quarantine
+func ToGraphQLQuarantine(q entity.Quarantine) *model.Quarantine {
+	var quarantinedBy *identification.ID
+	if q.QuarantinedBy != "" {
+		id := identification.ID(q.QuarantinedBy)
+		quarantinedBy = &id
+	}
+
+	return &model.Quarantine{
+		FamilyID:      identification.ID(q.FamilyID),
+		Reason:        q.Reason,
+		QuarantinedBy: quarantinedBy,
+		QuarantinedAt: q.QuarantinedAt.Format(RFC3339DateFormat),
+	}
+}
+
+// ToGraphQLChange converts a difference between two families to a GraphQL family change
+func ToGraphQLChange(c diff.Change) *model.FamilyChange {
+	change := &model.FamilyChange{
+		Path: c.Path,
+		Kind: model.FamilyChangeKind(c.Kind),
+	}
+	if c.Member != "" {
+		id := identification.ID(c.Member)
+		change.MemberID = &id
+	}
+	if c.Field != "" {
+		change.Field = &c.Field
+	}
+	if c.Kind != diff.KindAdded {
+		change.Before = &c.Before
+	}
+	if c.Kind != diff.KindRemoved {
+		change.After = &c.After
+	}
+	return change
+}
+
+// ToFilter converts a GraphQL family filter to a query filter. A birth date range with
+// both bounds becomes a single condition, so that the same child must be born in it.
+func ToFilter(input model.FamilyFilterInput) query.Filter {
+	filter := query.And{}
+	if input.Status != nil {
+		statuses := make([]string, 0, len(input.Status))
+		for _, status := range input.Status {
+			statuses = append(statuses, string(status))
+		}
+		filter = append(filter, query.In(query.FieldStatus, statuses))
+	}
+	if input.ParentLastName != nil {
+		filter = append(filter, query.Eq(query.FieldParentLastName, *input.ParentLastName))
+	}
+	switch from, to := input.ChildBornOnOrAfter, input.ChildBornOnOrBefore; {
+	case from != nil && to != nil:
+		filter = append(filter, query.Condition{Field: query.FieldChildBirthDate, Op: query.OpBetween, Value: query.Range{From: from.Time(), To: to.Time()}})
+	case from != nil:
+		filter = append(filter, query.Condition{Field: query.FieldChildBirthDate, Op: query.OpGte, Value: from.Time()})
+	case to != nil:
+		filter = append(filter, query.Condition{Field: query.FieldChildBirthDate, Op: query.OpLte, Value: to.Time()})
+	}
+	if input.MinChildren != nil {
+		filter = append(filter, query.Condition{Field: query.FieldChildCount, Op: query.OpGte, Value: *input.MinChildren})
+	}
+	if input.MaxChildren != nil {
+		filter = append(filter, query.Condition{Field: query.FieldChildCount, Op: query.OpLte, Value: *input.MaxChildren})
+	}
+	return filter
+}
+
+// sortFields are the query fields of the GraphQL family sort fields
+var sortFields = map[model.FamilySortField]query.Field{
+	model.FamilySortFieldID:            query.FieldID,
+	model.FamilySortFieldStatus:        query.FieldStatus,
+	model.FamilySortFieldParentCount:   query.FieldParentCount,
+	model.FamilySortFieldChildrenCount: query.FieldChildCount,
+}
+
+// ToOrder converts a GraphQL family sort order to a query order
+func ToOrder(input model.FamilySortInput) query.Order {
+	return query.Order{
+		Field:      sortFields[input.Field],
+		Descending: input.Direction == model.SortDirectionDesc,
+	}
+}
+
+// ToCursor encodes the ID of a family as the opaque cursor of the family in a page
+func ToCursor(familyID string) string {
+	return base64.StdEncoding.EncodeToString([]byte(familyID))
+}
+
+// FromCursor decodes the ID of a family from its cursor
+func FromCursor(cursor string) (string, error) {
+	familyID, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || len(familyID) == 0 {
+		return "", fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return string(familyID), nil
+}
+
+// toGraphQLConsents converts the consents of a child to GraphQL consents
+func toGraphQLConsents(consents []entity.Consent) []*model.Consent {
+	result := make([]*model.Consent, 0, len(consents))
+	for _, c := range consents {
+		var expiredAt *string
+		if c.ExpiredAt != nil {
+			formatted := c.ExpiredAt.Format(RFC3339DateFormat)
+			expiredAt = &formatted
+		}
+		result = append(result, &model.Consent{
+			GrantedBy: identification.ID(c.GrantedBy),
+			GrantedAt: c.GrantedAt.Format(RFC3339DateFormat),
+			Scopes:    toGraphQLConsentScopes(c.Scopes),
+			ExpiredAt: expiredAt,
+		})
+	}
+	return result
+}
+
+// toGraphQLConsentScopes converts consent scopes to GraphQL consent scopes
+func toGraphQLConsentScopes(scopes []entity.ConsentScope) []model.ConsentScope {
+	result := make([]model.ConsentScope, 0, len(scopes))
+	for _, scope := range scopes {
+		result = append(result, model.ConsentScope(scope))
+	}
+	return result
+}
+
+// toGraphQLPreferredName converts a preferred name to a GraphQL nullable string
+func toGraphQLPreferredName(preferredName string) *string {
+	if preferredName == "" {
+		return nil
+	}
+	return &preferredName
+}
+
+// toGraphQLNameHistory converts a name history to GraphQL name changes
+func toGraphQLNameHistory(history []entity.NameChange) []*model.NameChange {
+	result := make([]*model.NameChange, 0, len(history))
+	for _, change := range history {
+		result = append(result, ToGraphQLNameChange(change))
+	}
+	return result
+}
+
+// toExternalIDs converts external ID inputs to a map of system to ID.
+// Each system may appear at most once; the values are validated by the domain.
+func toExternalIDs(inputs []*model.ExternalIDInput) (map[string]string, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	externalIDs := make(map[string]string, len(inputs))
+	for _, input := range inputs {
+		if input == nil {
+			continue
+		}
+		if _, exists := externalIDs[input.System]; exists {
+			return nil, fmt.Errorf("invalid external IDs: duplicate system %q", input.System)
+		}
+		externalIDs[input.System] = input.ID
+	}
+	return externalIDs, nil
+}
+
+// toGraphQLExternalIDs converts a map of system to ID to GraphQL external IDs sorted by system
+func toGraphQLExternalIDs(externalIDs map[string]string) []*model.ExternalID {
+	systems := make([]string, 0, len(externalIDs))
+	for system := range externalIDs {
+		systems = append(systems, system)
+	}
+	sort.Strings(systems)
+
+	result := make([]*model.ExternalID, 0, len(systems))
+	for _, system := range systems {
+		result = append(result, &model.ExternalID{System: system, ID: externalIDs[system]})
+	}
+	return result
+}
@@ -7,6 +7,9 @@
 	"time"
 
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/policy"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/core/domain/query/querytest"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
 	"github.com/abitofhelp/servicelib/valueobject/identification"
 	"github.com/google/uuid"
@@ -14,13 +17,22 @@
 	"github.com/stretchr/testify/require"
 )
 
+// mustParseDate parses a date written as YYYY-MM-DD
+func mustParseDate(value string) entity.Date {
+	d, err := entity.ParseDate(value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
 func TestFamilyMapper_ToDomain(t *testing.T) {
 	// Setup test data
 	familyID := uuid.New().String()
 	parentID := uuid.New().String()
 	childID := uuid.New().String()
-	birthDate := "2000-01-01T00:00:00Z"
-	deathDate := "2020-01-01T00:00:00Z"
+	birthDate := mustParseDate("2000-01-01")
+	deathDate := mustParseDate("2020-01-01")
 	input := model.FamilyInput{
 		ID:     identification.ID(familyID),
 		Status: model.FamilyStatus("ACTIVE"),
@@ -108,30 +120,31 @@ func TestFamilyMapper_ToGraphQL(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, identification.ID(input.ID), result.ID)
 	assert.Equal(t, model.FamilyStatus("ACTIVE"), result.Status)
+	assert.Equal(t, input.Checksum(), result.Checksum)
 
 	// Assert parents
 	require.Len(t, result.Parents, 1)
 	assert.Equal(t, identification.ID(input.Parents[0].ID), result.Parents[0].ID)
 	assert.Equal(t, input.Parents[0].FirstName, result.Parents[0].FirstName)
 	assert.Equal(t, input.Parents[0].LastName, result.Parents[0].LastName)
-	assert.Equal(t, "2000-01-01T00:00:00Z", result.Parents[0].BirthDate)
+	assert.Equal(t, "2000-01-01", result.Parents[0].BirthDate.String())
 	require.NotNil(t, result.Parents[0].DeathDate)
-	assert.Equal(t, "2020-01-01T00:00:00Z", *result.Parents[0].DeathDate)
+	assert.Equal(t, "2020-01-01", result.Parents[0].DeathDate.String())
 
 	// Assert children
 	require.Len(t, result.Children, 1)
 	assert.Equal(t, identification.ID(input.Children[0].ID), result.Children[0].ID)
 	assert.Equal(t, input.Children[0].FirstName, result.Children[0].FirstName)
 	assert.Equal(t, input.Children[0].LastName, result.Children[0].LastName)
-	assert.Equal(t, "2000-01-01T00:00:00Z", result.Children[0].BirthDate)
+	assert.Equal(t, "2000-01-01", result.Children[0].BirthDate.String())
 	assert.Nil(t, result.Children[0].DeathDate)
 }
 
 func TestFamilyMapper_ToParentDTO(t *testing.T) {
 	// Setup test data
 	parentID := uuid.New().String()
-	birthDate := "2000-01-01T00:00:00Z"
-	deathDate := "2020-01-01T00:00:00Z"
+	birthDate := mustParseDate("2000-01-01")
+	deathDate := mustParseDate("2020-01-01")
 	input := model.ParentInput{
 		ID:        identification.ID(parentID),
 		FirstName: "John",
@@ -151,15 +164,15 @@ func TestFamilyMapper_ToParentDTO(t *testing.T) {
 	assert.Equal(t, input.ID.String(), result.ID)
 	assert.Equal(t, input.FirstName, result.FirstName)
 	assert.Equal(t, input.LastName, result.LastName)
-	assert.Equal(t, birthDate, result.BirthDate.Format(time.RFC3339))
+	assert.Equal(t, birthDate.Time(), result.BirthDate)
 	require.NotNil(t, result.DeathDate)
-	assert.Equal(t, deathDate, result.DeathDate.Format(time.RFC3339))
+	assert.Equal(t, deathDate.Time(), *result.DeathDate)
 }
 
 func TestFamilyMapper_ToChildDTO(t *testing.T) {
 	// Setup test data
 	childID := uuid.New().String()
-	birthDate := "2000-01-01T00:00:00Z"
+	birthDate := mustParseDate("2000-01-01")
 	input := model.ChildInput{
 		ID:        identification.ID(childID),
 		FirstName: "Jane",
@@ -179,7 +192,7 @@ func TestFamilyMapper_ToChildDTO(t *testing.T) {
 	assert.Equal(t, input.ID.String(), result.ID)
 	assert.Equal(t, input.FirstName, result.FirstName)
 	assert.Equal(t, input.LastName, result.LastName)
-	assert.Equal(t, birthDate, result.BirthDate.Format(time.RFC3339))
+	assert.Equal(t, birthDate.Time(), result.BirthDate)
 	assert.Nil(t, result.DeathDate)
 }
 
@@ -207,9 +220,9 @@ func TestFamilyMapper_ToParent(t *testing.T) {
 	assert.Equal(t, identification.ID(input.ID), result.ID)
 	assert.Equal(t, input.FirstName, result.FirstName)
 	assert.Equal(t, input.LastName, result.LastName)
-	assert.Equal(t, "2000-01-01T00:00:00Z", result.BirthDate)
+	assert.Equal(t, "2000-01-01", result.BirthDate.String())
 	require.NotNil(t, result.DeathDate)
-	assert.Equal(t, "2020-01-01T00:00:00Z", *result.DeathDate)
+	assert.Equal(t, "2020-01-01", result.DeathDate.String())
 }
 
 func TestFamilyMapper_ToChild(t *testing.T) {
@@ -235,7 +248,7 @@ func TestFamilyMapper_ToChild(t *testing.T) {
 	assert.Equal(t, identification.ID(input.ID), result.ID)
 	assert.Equal(t, input.FirstName, result.FirstName)
 	assert.Equal(t, input.LastName, result.LastName)
-	assert.Equal(t, "2000-01-01T00:00:00Z", result.BirthDate)
+	assert.Equal(t, "2000-01-01", result.BirthDate.String())
 	assert.Nil(t, result.DeathDate)
 }
 
@@ -247,11 +260,10 @@ func TestFamilyMapper_Error_Cases(t *testing.T) {
 		expectedError string
 	}{
 		{
-			name: "Invalid birth date in ParentInput",
+			name: "Missing birth date in ParentInput",
 			setupInvalid: func() interface{} {
 				return model.ParentInput{
-					ID:        identification.ID(uuid.New().String()),
-					BirthDate: "invalid-date",
+					ID: identification.ID(uuid.New().String()),
 				}
 			},
 			testFunction: func(input interface{}) error {
@@ -259,31 +271,13 @@ func TestFamilyMapper_Error_Cases(t *testing.T) {
 				_, err := mapper.ToParentDTO(input.(model.ParentInput))
 				return err
 			},
-			expectedError: "parsing time",
+			expectedError: "birth date is required",
 		},
 		{
-			name: "Invalid death date in ParentInput",
-			setupInvalid: func() interface{} {
-				invalidDate := "invalid-date"
-				return model.ParentInput{
-					ID:        identification.ID(uuid.New().String()),
-					BirthDate: "2000-01-01T00:00:00Z",
-					DeathDate: &invalidDate,
-				}
-			},
-			testFunction: func(input interface{}) error {
-				mapper := NewFamilyMapper()
-				_, err := mapper.ToParentDTO(input.(model.ParentInput))
-				return err
-			},
-			expectedError: "parsing time",
-		},
-		{
-			name: "Invalid birth date in ChildInput",
+			name: "Missing birth date in ChildInput",
 			setupInvalid: func() interface{} {
 				return model.ChildInput{
-					ID:        identification.ID(uuid.New().String()),
-					BirthDate: "invalid-date",
+					ID: identification.ID(uuid.New().String()),
 				}
 			},
 			testFunction: func(input interface{}) error {
@@ -291,7 +285,7 @@ func TestFamilyMapper_Error_Cases(t *testing.T) {
 				_, err := mapper.ToChildDTO(input.(model.ChildInput))
 				return err
 			},
-			expectedError: "parsing time",
+			expectedError: "birth date is required",
 		},
 		{
 			name: "Invalid FamilyStatus in FamilyInput",
@@ -313,7 +307,7 @@ func TestFamilyMapper_Error_Cases(t *testing.T) {
 			setupInvalid: func() interface{} {
 				return model.ParentInput{
 					ID:        "",
-					BirthDate: "2000-01-01T00:00:00Z",
+					BirthDate: mustParseDate("2000-01-01"),
 				}
 			},
 			testFunction: func(input interface{}) error {
@@ -326,10 +320,10 @@ func TestFamilyMapper_Error_Cases(t *testing.T) {
 		{
 			name: "Death date before birth date in ParentInput",
 			setupInvalid: func() interface{} {
-				deathDate := "1999-01-01T00:00:00Z" // Before birth date
+				deathDate := mustParseDate("1999-01-01") // Before birth date
 				return model.ParentInput{
 					ID:        identification.ID(uuid.New().String()),
-					BirthDate: "2000-01-01T00:00:00Z",
+					BirthDate: mustParseDate("2000-01-01"),
 					DeathDate: &deathDate,
 				}
 			},
@@ -352,6 +346,26 @@ func TestFamilyMapper_Error_Cases(t *testing.T) {
 	}
 }
 
+func TestFamilyMapper_CalendarDates(t *testing.T) {
+	mapper := NewFamilyMapper()
+
+	// A timestamp sent by a client west of UTC keeps the day it was written on
+	input := model.ParentInput{
+		ID:        identification.ID(uuid.New().String()),
+		FirstName: "John",
+		LastName:  "Doe",
+		BirthDate: mustParseDate("1980-01-01T00:00:00-05:00"),
+	}
+
+	result, err := mapper.ToParentDTO(input)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), result.BirthDate)
+
+	parent, err := mapper.ToParent(result)
+	require.NoError(t, err)
+	assert.Equal(t, "1980-01-01", parent.BirthDate.String())
+}
+
 func TestFamilyMapper_Edge_Cases(t *testing.T) {
 	t.Run("Empty parents and children lists", func(t *testing.T) {
 		input := entity.FamilyDTO{
@@ -372,7 +386,7 @@ func TestFamilyMapper_Edge_Cases(t *testing.T) {
 	})
 
 	t.Run("Future dates are allowed", func(t *testing.T) {
-		futureDate := time.Now().AddDate(1, 0, 0).Format(RFC3339DateFormat)
+		futureDate := entity.DateOf(time.Now().AddDate(1, 0, 0))
 		input := model.ParentInput{
 			ID:        identification.ID(uuid.New().String()),
 			FirstName: "John",
@@ -385,7 +399,7 @@ func TestFamilyMapper_Edge_Cases(t *testing.T) {
 
 		require.NoError(t, err)
 		assert.Equal(t, input.ID.String(), result.ID)
-		assert.Equal(t, futureDate, result.BirthDate.Format(RFC3339DateFormat))
+		assert.Equal(t, futureDate.Time(), result.BirthDate)
 	})
 
 	t.Run("Very long names are allowed", func(t *testing.T) {
@@ -394,7 +408,7 @@ func TestFamilyMapper_Edge_Cases(t *testing.T) {
 			ID:        identification.ID(uuid.New().String()),
 			FirstName: longName,
 			LastName:  longName,
-			BirthDate: "2000-01-01T00:00:00Z",
+			BirthDate: mustParseDate("2000-01-01"),
 		}
 
 		mapper := NewFamilyMapper()
@@ -405,3 +419,225 @@ func TestFamilyMapper_Edge_Cases(t *testing.T) {
 		assert.Equal(t, input.LastName, result.LastName)
 	})
 }
+
+func TestFamilyMapper_ExternalIDs(t *testing.T) {
+	mapper := NewFamilyMapper()
+
+	t.Run("Inputs are converted to a map", func(t *testing.T) {
+		input := model.ParentInput{
+			ID:          identification.ID(uuid.New().String()),
+			FirstName:   "John",
+			LastName:    "Doe",
+			BirthDate:   mustParseDate("2000-01-01"),
+			ExternalIds: []*model.ExternalIDInput{{System: "crm", ID: "CRM-1"}, {System: "billing", ID: "B-1"}},
+		}
+
+		result, err := mapper.ToParentDTO(input)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"crm": "CRM-1", "billing": "B-1"}, result.ExternalIDs)
+	})
+
+	t.Run("Duplicate systems are rejected", func(t *testing.T) {
+		input := model.ChildInput{
+			ID:          identification.ID(uuid.New().String()),
+			FirstName:   "Jane",
+			LastName:    "Doe",
+			BirthDate:   mustParseDate("2000-01-01"),
+			ExternalIds: []*model.ExternalIDInput{{System: "crm", ID: "CRM-1"}, {System: "crm", ID: "CRM-2"}},
+		}
+
+		_, err := mapper.ToChildDTO(input)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate system")
+	})
+
+	t.Run("GraphQL external IDs are sorted by system", func(t *testing.T) {
+		input := entity.FamilyDTO{
+			ID:          uuid.New().String(),
+			Status:      "ACTIVE",
+			ExternalIDs: map[string]string{"crm": "F-1", "billing": "B-1"},
+		}
+
+		result, err := mapper.ToGraphQL(input)
+
+		require.NoError(t, err)
+		assert.Equal(t, []*model.ExternalID{{System: "billing", ID: "B-1"}, {System: "crm", ID: "F-1"}}, result.ExternalIds)
+	})
+}
+
+func TestFamilyMapper_MemberNames(t *testing.T) {
+	mapper := NewFamilyMapper()
+
+	t.Run("Name change input is converted", func(t *testing.T) {
+		reason := model.NameChangeReasonMarriage
+		change, err := ToNameChange(model.NameChangeInput{
+			FirstName:     "Jane",
+			LastName:      "Smith",
+			EffectiveDate: "2005-06-01T00:00:00Z",
+			Reason:        &reason,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, entity.NameChange{FirstName: "Jane", LastName: "Smith", EffectiveDate: time.Date(2005, 6, 1, 0, 0, 0, 0, time.UTC), Reason: entity.NameChangeMarriage}, change)
+
+		_, err = ToNameChange(model.NameChangeInput{FirstName: "Jane", LastName: "Smith", EffectiveDate: "June 2005"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid effective date")
+	})
+
+	t.Run("Member details input is converted", func(t *testing.T) {
+		firstName := "Jane"
+		birthDate, err := entity.NewDate(1980, time.February, 3)
+		require.NoError(t, err)
+
+		details := ToMemberDetails(model.MemberDetailsInput{FirstName: &firstName, BirthDate: &birthDate})
+		assert.Equal(t, entity.MemberDetails{FirstName: "Jane", BirthDate: time.Date(1980, 2, 3, 0, 0, 0, 0, time.UTC)}, details)
+		assert.True(t, ToMemberDetails(model.MemberDetailsInput{}).IsEmpty())
+	})
+
+	t.Run("Preferred name and name history are converted", func(t *testing.T) {
+		birthDate := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+		result, err := mapper.ToParent(entity.ParentDTO{
+			ID:            uuid.New().String(),
+			FirstName:     "Jane",
+			LastName:      "Smith",
+			BirthDate:     birthDate,
+			PreferredName: "Janie",
+			NameHistory: []entity.NameChange{
+				{FirstName: "Jane", LastName: "Doe", EffectiveDate: birthDate},
+				{FirstName: "Jane", LastName: "Smith", EffectiveDate: time.Date(2005, 6, 1, 0, 0, 0, 0, time.UTC), Reason: entity.NameChangeMarriage},
+			},
+		})
+
+		require.NoError(t, err)
+		require.NotNil(t, result.PreferredName)
+		assert.Equal(t, "Janie", *result.PreferredName)
+		require.Len(t, result.NameHistory, 2)
+		assert.Nil(t, result.NameHistory[0].Reason, "the name from birth has no reason")
+		assert.Equal(t, model.NameChangeReasonMarriage, *result.NameHistory[1].Reason)
+		assert.Equal(t, "2005-06-01T00:00:00Z", result.NameHistory[1].EffectiveDate)
+	})
+
+	t.Run("Members without names have no preferred name and an empty history", func(t *testing.T) {
+		result, err := mapper.ToChild(entity.ChildDTO{ID: uuid.New().String(), FirstName: "Jimmy", LastName: "Doe", BirthDate: time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+		require.NoError(t, err)
+		assert.Nil(t, result.PreferredName)
+		assert.NotNil(t, result.NameHistory)
+		assert.Empty(t, result.NameHistory)
+	})
+}
+
+func TestFamilyMapper_Consents(t *testing.T) {
+	parentID := uuid.New().String()
+	now := time.Now()
+	minor := entity.ChildDTO{
+		ID:            uuid.New().String(),
+		FirstName:     "Jimmy",
+		LastName:      "Doe",
+		BirthDate:     now.AddDate(-10, 0, 0),
+		ExternalIDs:   map[string]string{"school": "S-1"},
+		PreferredName: "Jim",
+		NameHistory:   []entity.NameChange{{FirstName: "Jimmy", LastName: "Smith", EffectiveDate: now.AddDate(-10, 0, 0)}},
+	}
+
+	t.Run("Consent input is converted", func(t *testing.T) {
+		consent, err := ToConsent(model.ConsentInput{
+			GrantedBy: identification.ID(parentID),
+			Scopes:    []model.ConsentScope{model.ConsentScopeNames},
+		}, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, entity.Consent{GrantedBy: parentID, GrantedAt: now, Scopes: []entity.ConsentScope{entity.ConsentScopeNames}}, consent)
+
+		grantedAt := "2020-06-01T00:00:00Z"
+		consent, err = ToConsent(model.ConsentInput{GrantedBy: identification.ID(parentID), GrantedAt: &grantedAt}, now)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC), consent.GrantedAt)
+
+		invalid := "June 2020"
+		_, err = ToConsent(model.ConsentInput{GrantedBy: identification.ID(parentID), GrantedAt: &invalid}, now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid grant time")
+	})
+
+	t.Run("Without a consent policy nothing is withheld", func(t *testing.T) {
+		result, err := NewFamilyMapper().ToChild(minor)
+
+		require.NoError(t, err)
+		assert.Len(t, result.ExternalIds, 1)
+		assert.NotNil(t, result.PreferredName)
+		assert.Empty(t, result.WithheldScopes)
+		assert.NotNil(t, result.Consents)
+	})
+
+	t.Run("Data of minors not covered by a current consent is withheld", func(t *testing.T) {
+		child := minor
+		grantedAt := now.Add(-time.Hour).UTC().Truncate(time.Second)
+		child.Consents = []entity.Consent{{GrantedBy: parentID, GrantedAt: grantedAt, Scopes: []entity.ConsentScope{entity.ConsentScopeNames}}}
+
+		result, err := NewFamilyMapperWithConsentPolicy(policy.NewConsentPolicy(true, 18, 0)).ToChild(child)
+
+		require.NoError(t, err)
+		assert.Empty(t, result.ExternalIds)
+		require.NotNil(t, result.PreferredName)
+		assert.Equal(t, "Jim", *result.PreferredName)
+		assert.Len(t, result.NameHistory, 1)
+		assert.Equal(t, []model.ConsentScope{model.ConsentScopeExternalIDS}, result.WithheldScopes)
+		require.Len(t, result.Consents, 1)
+		assert.Equal(t, identification.ID(parentID), result.Consents[0].GrantedBy)
+		assert.Equal(t, grantedAt.Format(RFC3339DateFormat), result.Consents[0].GrantedAt)
+		assert.Nil(t, result.Consents[0].ExpiredAt)
+		assert.Len(t, child.ExternalIDs, 1, "the input DTO is not modified")
+	})
+}
+
+func TestCursor(t *testing.T) {
+	cursor := ToCursor("family-123")
+	assert.Equal(t, "ZmFtaWx5LTEyMw==", cursor)
+
+	familyID, err := FromCursor(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, "family-123", familyID)
+
+	for _, invalid := range []string{"", "not a cursor", "ZmFtaWx5"[:5]} {
+		_, err := FromCursor(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestToFilter(t *testing.T) {
+	families := querytest.Families(t)
+	ids := func(filter query.Filter) []string {
+		require.NoError(t, query.Validate(filter))
+		var got []string
+		for _, fam := range families {
+			if query.Match(filter, fam) {
+				got = append(got, fam.ID())
+			}
+		}
+		return got
+	}
+
+	assert.Len(t, ids(ToFilter(model.FamilyFilterInput{})), len(families))
+
+	roe := "Roe"
+	assert.Equal(t, []string{querytest.Family3}, ids(ToFilter(model.FamilyFilterInput{ParentLastName: &roe})))
+
+	one, two := 1, 2
+	assert.Equal(t, []string{querytest.Family2, querytest.Family3}, ids(ToFilter(model.FamilyFilterInput{MinChildren: &one, MaxChildren: &two})))
+	assert.Equal(t, []string{querytest.Family1, querytest.Family4}, ids(ToFilter(model.FamilyFilterInput{Status: []model.FamilyStatus{model.FamilyStatusSingle}})))
+
+	// Both bounds of a birth date range must hold for the same child
+	from, to := mustParseDate("2006-01-01"), mustParseDate("2009-12-31")
+	assert.Empty(t, ids(ToFilter(model.FamilyFilterInput{ChildBornOnOrAfter: &from, ChildBornOnOrBefore: &to})))
+	assert.Equal(t, []string{querytest.Family2, querytest.Family3, querytest.Family4}, ids(ToFilter(model.FamilyFilterInput{ChildBornOnOrAfter: &from})))
+	assert.Equal(t, []string{querytest.Family2}, ids(ToFilter(model.FamilyFilterInput{ChildBornOnOrBefore: &to})))
+}
+
+func TestToOrder(t *testing.T) {
+	assert.Equal(t, query.Order{Field: query.FieldID}, ToOrder(model.FamilySortInput{Field: model.FamilySortFieldID, Direction: model.SortDirectionAsc}))
+	assert.Equal(t, query.Order{Field: query.FieldChildCount, Descending: true}, ToOrder(model.FamilySortInput{Field: model.FamilySortFieldChildrenCount, Direction: model.SortDirectionDesc}))
+}
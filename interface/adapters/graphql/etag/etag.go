@@ -0,0 +1,109 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package etag supports conditional GET requests to the GraphQL API.
+//
+// Queries sent with GET receive an ETag computed from the data and errors of the
+// response, so it changes whenever a family in the result changes (see the checksum
+// field of Family). Clients that send the ETag back in If-None-Match receive
+// 304 Not Modified without a body while the result is unchanged. Response extensions,
+// such as the rate limit state, vary between requests and are not part of the ETag.
+package etag
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Middleware returns HTTP middleware that adds ETags to successful GET responses and
+// answers conditional GET requests with 304 Not Modified when the ETag matches.
+// Other requests, including WebSocket upgrades, are passed through unchanged.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			_, _ = w.Write(rec.body.Bytes())
+			return
+		}
+
+		tag := Compute(rec.body.Bytes())
+		w.Header().Set("ETag", tag)
+		// Results depend on the caller, so only private caches may store them, and must revalidate
+		w.Header().Set("Cache-Control", "private, no-cache")
+		w.Header().Add("Vary", "Authorization")
+
+		if Match(r.Header.Get("If-None-Match"), tag) {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(rec.body.Bytes())
+	})
+}
+
+// Compute returns the ETag of a GraphQL response body.
+// Only the data and errors of the response are included; if the body is not a
+// GraphQL response, the whole body is.
+func Compute(body []byte) string {
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors json.RawMessage `json:"errors"`
+	}
+	content := body
+	if err := json.Unmarshal(body, &response); err == nil {
+		content = append(append([]byte{}, response.Data...), response.Errors...)
+	}
+
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Match reports whether an If-None-Match header matches the ETag.
+// Weak comparison is used, as specified for If-None-Match.
+func Match(ifNoneMatch, tag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// recorder buffers a response so that its ETag can be computed before it is written
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+// WriteHeader records the status code of the response
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+}
+
+// Write buffers the body of the response
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(b)
+}
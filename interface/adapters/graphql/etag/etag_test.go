@@ -0,0 +1,71 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package etag
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	remaining := 10
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining--
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("query") == "bad" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"bad query"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"getFamily":{"checksum":"abc"}},"extensions":{"rateLimit":{"remaining":` + strconv.Itoa(remaining) + `}}}`))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?query=ok", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	tag := rec.Header().Get("ETag")
+	require.NotEmpty(t, tag)
+	assert.Equal(t, "private, no-cache", rec.Header().Get("Cache-Control"))
+	assert.Contains(t, rec.Body.String(), `"checksum":"abc"`)
+
+	// The ETag does not depend on response extensions
+	req := httptest.NewRequest(http.MethodGet, "/graphql?query=ok", nil)
+	req.Header.Set("If-None-Match", `W/"other", `+tag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, tag, rec.Header().Get("ETag"))
+
+	req = httptest.NewRequest(http.MethodGet, "/graphql?query=ok", nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Failed requests are not tagged
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?query=bad", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Empty(t, rec.Header().Get("ETag"))
+	assert.Contains(t, rec.Body.String(), "bad query")
+
+	// Other methods are passed through
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("ETag"))
+}
+
+func TestCompute(t *testing.T) {
+	a := Compute([]byte(`{"data":{"x":1},"extensions":{"n":1}}`))
+	assert.Equal(t, a, Compute([]byte(`{"data":{"x":1},"extensions":{"n":2}}`)))
+	assert.NotEqual(t, a, Compute([]byte(`{"data":{"x":2}}`)))
+	assert.NotEqual(t, a, Compute([]byte(`{"data":{"x":1},"errors":[{"message":"partial"}]}`)))
+	assert.NotEqual(t, Compute([]byte("a")), Compute([]byte("b")))
+}
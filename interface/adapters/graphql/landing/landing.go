@@ -0,0 +1,103 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package landing serves the landing page of the service, which links to the GraphQL
+// endpoint and the pages served with it.
+//
+// The links are generated from the configured routes, so they follow the paths and prefix
+// the service is served at. The page is rendered once when the handler is created, and its
+// template is embedded in the binary.
+package landing
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/etag"
+)
+
+//go:embed landing.html.tmpl
+var pageTemplate string
+
+// page is the parsed landing page template
+var page = template.Must(template.New("landing").Parse(pageTemplate))
+
+// Page is the content of the landing page
+type Page struct {
+	GraphQL string // Path of the GraphQL endpoint
+	Links   []Link
+}
+
+// Link is a link of the landing page to a page of the service
+type Link struct {
+	Title       string
+	Path        string
+	Description string
+}
+
+// NewPage returns the landing page of the routes. The sign-in page is linked only if
+// login is served.
+func NewPage(routes config.RoutesConfig, login bool) Page {
+	p := Page{
+		GraphQL: routes.Path(routes.GraphQL),
+		Links: []Link{
+			{Title: "GraphQL Playground", Path: routes.Path(routes.Playground), Description: "Run queries and mutations interactively"},
+			{Title: "GraphiQL", Path: routes.Path(routes.GraphiQL), Description: "Explore the schema and run queries"},
+			{Title: "Documentation", Path: routes.Path(routes.Docs), Description: "Read the queries, mutations, and types of the schema"},
+			{Title: "Family Lifecycle", Path: routes.Path(routes.Lifecycle), Description: "Get the allowed status transitions of a family as JSON, or as Graphviz DOT with ?format=dot"},
+		},
+	}
+	if login {
+		p.Links = append(p.Links, Link{Title: "Sign in", Path: routes.Path(routes.Login), Description: "Sign in to the playground and documentation with the identity provider"})
+	}
+	return p
+}
+
+// Render renders the landing page as HTML
+func (p Page) Render() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, p); err != nil {
+		return nil, fmt.Errorf("failed to render landing page: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// NewHandler returns an HTTP handler that serves the landing page of the routes at their
+// root. It is registered at the root, which matches every path below it, so other paths
+// are not found.
+func NewHandler(routes config.RoutesConfig, login bool) (http.Handler, error) {
+	body, err := NewPage(routes, login).Render()
+	if err != nil {
+		return nil, err
+	}
+	root := routes.Root()
+	tag := etag.Compute(body)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != root {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("ETag", tag)
+		if etag.Match(r.Header.Get("If-None-Match"), tag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+		if r.Method == http.MethodHead {
+			return
+		}
+		_, _ = w.Write(body)
+	}), nil
+}
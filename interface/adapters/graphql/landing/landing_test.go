@@ -0,0 +1,66 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package landing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRoutes are the default routes below a gateway prefix
+var testRoutes = config.RoutesConfig{
+	Prefix:     "/family-service",
+	GraphQL:    "/graphql",
+	Playground: "/playground",
+	GraphiQL:   "/graphiql",
+	Docs:       "/docs",
+	Login:      "/login",
+	Lifecycle:  "/lifecycle",
+}
+
+func TestNewPage(t *testing.T) {
+	p := NewPage(testRoutes, false)
+	assert.Equal(t, "/family-service/graphql", p.GraphQL)
+	paths := make([]string, 0, len(p.Links))
+	for _, link := range p.Links {
+		paths = append(paths, link.Path)
+	}
+	assert.Equal(t, []string{"/family-service/playground", "/family-service/graphiql", "/family-service/docs", "/family-service/lifecycle"}, paths)
+
+	p = NewPage(testRoutes, true)
+	assert.Equal(t, "/family-service/login", p.Links[len(p.Links)-1].Path)
+}
+
+func TestNewHandler(t *testing.T) {
+	handler, err := NewHandler(testRoutes, false)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/family-service/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `<code>/family-service/graphql</code>`)
+	assert.Contains(t, rec.Body.String(), `<a href="/family-service/playground">GraphQL Playground</a>`)
+	assert.NotContains(t, rec.Body.String(), "Sign in")
+
+	req := httptest.NewRequest(http.MethodGet, "/family-service/", nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	for _, path := range []string{"/", "/family-service/unknown"} {
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/family-service/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
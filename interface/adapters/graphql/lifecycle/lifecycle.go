@@ -0,0 +1,153 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package lifecycle serves the state machine of the status of a family, as JSON or as a
+// Graphviz DOT graph.
+//
+// The state machine is generated from the transition table that the Family aggregate
+// consults when its status changes, so the frontend and the documentation always show
+// the transitions the domain actually allows. Both documents are rendered once when the
+// handler is created.
+package lifecycle
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/etag"
+)
+
+// Formats of the state machine
+const (
+	FormatJSON = "json"
+	FormatDOT  = "dot"
+)
+
+// Content types of the formats
+const (
+	contentTypeJSON = "application/json"
+	contentTypeDOT  = "text/vnd.graphviz"
+)
+
+// StateMachine is the lifecycle of a family
+type StateMachine struct {
+	Statuses    []string     `json:"statuses"`
+	Transitions []Transition `json:"transitions"`
+}
+
+// Transition is a change of status that an operation of a family makes
+type Transition struct {
+	Operation string `json:"operation"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Condition string `json:"condition,omitempty"`
+}
+
+// NewStateMachine returns the lifecycle of a family that the domain implements
+func NewStateMachine() StateMachine {
+	m := StateMachine{}
+	for _, status := range entity.Statuses() {
+		m.Statuses = append(m.Statuses, string(status))
+	}
+	for _, t := range entity.Lifecycle() {
+		m.Transitions = append(m.Transitions, Transition{
+			Operation: t.Operation,
+			From:      string(t.From),
+			To:        string(t.To),
+			Condition: t.Condition,
+		})
+	}
+	return m
+}
+
+// JSON renders the state machine as indented JSON
+func (m StateMachine) JSON() ([]byte, error) {
+	body, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render lifecycle as JSON: %w", err)
+	}
+	return append(body, '\n'), nil
+}
+
+// DOT renders the state machine as a Graphviz directed graph, with a node per status and
+// an edge per transition labeled with its operation
+func (m StateMachine) DOT() []byte {
+	var b strings.Builder
+	b.WriteString("digraph FamilyLifecycle {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+	for _, status := range m.Statuses {
+		fmt.Fprintf(&b, "  %q;\n", status)
+	}
+	for _, t := range m.Transitions {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q, tooltip=%q];\n", t.From, t.To, t.Operation, t.Condition)
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+// document is a rendered format of the state machine
+type document struct {
+	contentType string
+	body        []byte
+	tag         string
+}
+
+// NewHandler returns an HTTP handler that serves the state machine as JSON, or as DOT when
+// the format query parameter is dot or the request accepts text/vnd.graphviz
+func NewHandler() (http.Handler, error) {
+	m := NewStateMachine()
+	body, err := m.JSON()
+	if err != nil {
+		return nil, err
+	}
+	documents := map[string]document{
+		FormatJSON: {contentType: contentTypeJSON, body: body, tag: etag.Compute(body)},
+	}
+	body = m.DOT()
+	documents[FormatDOT] = document{contentType: contentTypeDOT, body: body, tag: etag.Compute(body)}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		doc, ok := documents[format(r)]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown format; use %s or %s", FormatJSON, FormatDOT), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Vary", "Accept")
+		w.Header().Set("ETag", doc.tag)
+		if etag.Match(r.Header.Get("If-None-Match"), doc.tag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", doc.contentType+"; charset=utf-8")
+		if r.Method == http.MethodHead {
+			return
+		}
+		_, _ = w.Write(doc.body)
+	}), nil
+}
+
+// format returns the format requested by the format query parameter or, without one, by
+// the Accept header
+func format(r *http.Request) string {
+	if f := r.URL.Query().Get("format"); f != "" {
+		return strings.ToLower(f)
+	}
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted)); err == nil && mediaType == contentTypeDOT {
+			return FormatDOT
+		}
+	}
+	return FormatJSON
+}
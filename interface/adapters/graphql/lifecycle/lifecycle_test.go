@@ -0,0 +1,80 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package lifecycle
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStateMachine(t *testing.T) {
+	m := NewStateMachine()
+	assert.Equal(t, []string{"SINGLE", "MARRIED", "DIVORCED", "WIDOWED", "ABANDONED"}, m.Statuses)
+	assert.Len(t, m.Transitions, len(entity.Lifecycle()))
+	assert.Contains(t, m.Transitions, Transition{
+		Operation: entity.OperationDivorce,
+		From:      "MARRIED",
+		To:        "DIVORCED",
+		Condition: entity.Lifecycle()[3].Condition,
+	})
+}
+
+func TestStateMachine_DOT(t *testing.T) {
+	dot := string(NewStateMachine().DOT())
+	assert.Contains(t, dot, "digraph FamilyLifecycle {")
+	assert.Contains(t, dot, `"ABANDONED";`)
+	assert.Contains(t, dot, `"SINGLE" -> "MARRIED" [label="marry"`)
+	assert.Contains(t, dot, `"MARRIED" -> "WIDOWED" [label="widow"`)
+}
+
+func TestNewHandler(t *testing.T) {
+	handler, err := NewHandler()
+	require.NoError(t, err)
+
+	serve := func(target, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/lifecycle", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	var m StateMachine
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &m))
+	assert.Equal(t, NewStateMachine(), m)
+
+	rec = serve("/lifecycle?format=dot", "")
+	assert.Equal(t, "text/vnd.graphviz; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, string(NewStateMachine().DOT()), rec.Body.String())
+
+	rec = serve("/lifecycle", "text/html, text/vnd.graphviz;q=0.9")
+	assert.Equal(t, "text/vnd.graphviz; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	// The format parameter takes precedence over the Accept header
+	rec = serve("/lifecycle?format=json", "text/vnd.graphviz")
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	assert.Equal(t, http.StatusBadRequest, serve("/lifecycle?format=svg", "").Code)
+
+	// Unchanged documents are not sent again
+	req := httptest.NewRequest(http.MethodGet, "/lifecycle", nil)
+	req.Header.Set("If-None-Match", serve("/lifecycle", "").Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/lifecycle", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package loader batches the reads of families made by the resolvers of a GraphQL query.
+//
+// The fields of the elements of a list are resolved one by one, so a field that reads a
+// family would read the database once per element. The extension gives each query its own
+// loaders, which collect the reads of the fields resolved together and make them with one
+// read of the database, and keep their results until the query ends, so that a family read
+// by several fields is read once. A query therefore makes a constant number of database
+// round trips per level of nesting, whatever the number of elements.
+//
+// Mutations get no loaders: a read cached by a loader would hide the writes of the later
+// mutations of the same operation.
+package loader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/core/application/ports"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Loaders are the loaders of a query
+type Loaders struct {
+	// Families loads families by ID
+	Families *Loader[string, *entity.FamilyDTO]
+
+	// FamiliesByParent loads the families of parents by parent ID
+	FamiliesByParent *Loader[string, []*entity.FamilyDTO]
+}
+
+// contextKey is the type of the context key of the loaders
+type contextKey struct{}
+
+// NewLoaders creates the loaders of a query, which read families from a service
+//
+// Parameters:
+//   - service: The service that reads the families
+//   - wait: How long the loaders wait for more keys before they read a batch (0 for DefaultWait)
+//   - maxBatch: The largest number of keys read at once (0 for DefaultMaxBatch)
+//
+// Returns:
+//   - The loaders of one query
+func NewLoaders(service ports.FamilyApplicationService, wait time.Duration, maxBatch int) *Loaders {
+	return &Loaders{
+		Families:         NewLoader(fetchFamilies(service), wait, maxBatch),
+		FamiliesByParent: NewLoader(fetchFamiliesByParent(service), wait, maxBatch),
+	}
+}
+
+// WithLoaders returns a context with the loaders of a query
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, contextKey{}, loaders)
+}
+
+// For returns the loaders of the query of a context, or nil outside a query, whose
+// resolvers then read the service directly
+func For(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(contextKey{}).(*Loaders)
+	return loaders
+}
+
+// fetchFamilies reads a batch of families by ID. The families that the batch leaves out,
+// because they are missing or quarantined, are read one by one, so that their errors are
+// those of a read of a single family.
+func fetchFamilies(service ports.FamilyApplicationService) FetchFunc[string, *entity.FamilyDTO] {
+	return func(ctx context.Context, ids []string) ([]*entity.FamilyDTO, []error) {
+		families := make([]*entity.FamilyDTO, len(ids))
+		errs := make([]error, len(ids))
+
+		read, err := service.GetFamilies(ctx, ids)
+		if err != nil {
+			for i := range errs {
+				errs[i] = err
+			}
+			return families, errs
+		}
+
+		byID := make(map[string]*entity.FamilyDTO, len(read))
+		for _, fam := range read {
+			byID[fam.ID] = fam
+		}
+		for i, id := range ids {
+			if fam, ok := byID[id]; ok {
+				families[i] = fam
+				continue
+			}
+			families[i], errs[i] = service.GetFamily(ctx, id)
+		}
+		return families, errs
+	}
+}
+
+// fetchFamiliesByParent reads the families of a batch of parents with one filtered read
+func fetchFamiliesByParent(service ports.FamilyApplicationService) FetchFunc[string, []*entity.FamilyDTO] {
+	return func(ctx context.Context, parentIDs []string) ([][]*entity.FamilyDTO, []error) {
+		families := make([][]*entity.FamilyDTO, len(parentIDs))
+		errs := make([]error, len(parentIDs))
+
+		read, err := service.FindFamilies(ctx, query.In(query.FieldParentID, parentIDs), query.Order{})
+		if err != nil {
+			for i := range errs {
+				errs[i] = fmt.Errorf("failed to find families of parents: %w", err)
+			}
+			return families, errs
+		}
+
+		index := make(map[string]int, len(parentIDs))
+		for i, id := range parentIDs {
+			index[id] = i
+			families[i] = []*entity.FamilyDTO{}
+		}
+		for _, fam := range read {
+			for _, parent := range fam.Parents {
+				if i, ok := index[parent.ID]; ok {
+					families[i] = append(families[i], fam)
+				}
+			}
+		}
+		return families, errs
+	}
+}
+
+// Extension is a gqlgen handler extension that gives each query its own loaders
+type Extension struct {
+	service  ports.FamilyApplicationService
+	wait     time.Duration
+	maxBatch int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = Extension{}
+
+// NewExtension creates an extension that gives each query loaders that read families from
+// a service with the default wait and batch size
+func NewExtension(service ports.FamilyApplicationService) Extension {
+	return Extension{service: service, wait: DefaultWait, maxBatch: DefaultMaxBatch}
+}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "DataLoaders"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse gives a query its own loaders
+func (e Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+	if oc.Operation == nil || oc.Operation.Operation != ast.Query {
+		return next(ctx)
+	}
+
+	return next(WithLoaders(ctx, NewLoaders(e.service, e.wait, e.maxBatch)))
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package loader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errMissingResult is the error of a key whose value a fetch left out
+var errMissingResult = errors.New("loader: the fetch returned no result for the key")
+
+// Defaults of the wait and the batch size of loaders
+const (
+	// DefaultWait is how long a loader waits for more keys before it fetches a batch. The
+	// fields of the elements of a list are resolved concurrently, so their keys arrive
+	// within a fraction of it.
+	DefaultWait = 2 * time.Millisecond
+
+	// DefaultMaxBatch is the largest number of keys that a loader fetches at once
+	DefaultMaxBatch = 100
+)
+
+// FetchFunc fetches the values of a batch of distinct keys. It returns a value and an
+// error for every key, in the order of the keys.
+type FetchFunc[K comparable, V any] func(ctx context.Context, keys []K) ([]V, []error)
+
+// Loader batches and caches the loads of values by key. The keys loaded within the wait of
+// the first key of a batch are fetched together, and the result of each key is kept, so
+// that a key is fetched at most once by a loader. Loaders are made for a single operation,
+// whose reads they cache until it ends.
+type Loader[K comparable, V any] struct {
+	fetch    FetchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	batch   *batch[K, V]
+}
+
+// result is the result of the load of a key, which is set once done is closed
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// batch is a batch of keys that have not been fetched yet
+type batch[K comparable, V any] struct {
+	ctx     context.Context
+	keys    []K
+	results []*result[V]
+	timer   *time.Timer
+}
+
+// NewLoader creates a loader that fetches batches of at most maxBatch keys after waiting
+// wait for more keys. A wait or batch size that is not positive is replaced by its default.
+func NewLoader[K comparable, V any](fetch FetchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	if wait <= 0 {
+		wait = DefaultWait
+	}
+	if maxBatch <= 0 {
+		maxBatch = DefaultMaxBatch
+	}
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		results:  make(map[K]*result[V]),
+	}
+}
+
+// Load returns the value of a key, fetching it with the other keys of its batch unless it
+// was loaded before. A batch is fetched with the context of its first key.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	r, ok := l.results[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.results[key] = r
+		l.add(ctx, key, r)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// add adds a key to the current batch, and fetches the batch once it is full. The caller
+// must hold the lock.
+func (l *Loader[K, V]) add(ctx context.Context, key K, r *result[V]) {
+	if l.batch == nil {
+		b := &batch[K, V]{ctx: ctx}
+		b.timer = time.AfterFunc(l.wait, func() { l.dispatch(b) })
+		l.batch = b
+	}
+
+	b := l.batch
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, r)
+	if len(b.keys) >= l.maxBatch {
+		b.timer.Stop()
+		l.batch = nil
+		go l.run(b)
+	}
+}
+
+// dispatch fetches a batch whose wait has passed, unless it was fetched when it was full
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+
+	l.run(b)
+}
+
+// run fetches a batch and sets the results of its keys. A key whose value the fetch leaves
+// out fails with errMissingResult.
+func (l *Loader[K, V]) run(b *batch[K, V]) {
+	values, errs := l.fetch(b.ctx, b.keys)
+
+	for i, r := range b.results {
+		if i < len(values) {
+			r.value = values[i]
+		}
+		if i < len(errs) {
+			r.err = errs[i]
+		}
+		if i >= len(values) && r.err == nil {
+			r.err = errMissingResult
+		}
+		close(r.done)
+	}
+}
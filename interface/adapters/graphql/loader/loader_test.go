@@ -0,0 +1,186 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package loader
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/core/application/ports"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	serviceerrors "github.com/abitofhelp/servicelib/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// recorder is a fetch that records its batches
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (r *recorder) fetch(_ context.Context, keys []int) ([]int, []error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	batch := append([]int(nil), keys...)
+	sort.Ints(batch)
+	r.batches = append(r.batches, batch)
+
+	values := make([]int, len(keys))
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		if key < 0 {
+			errs[i] = errors.New("negative key")
+			continue
+		}
+		values[i] = key * 10
+	}
+	return values, errs
+}
+
+// loadAll loads keys concurrently and returns their values
+func loadAll(t *testing.T, l *Loader[int, int], keys ...int) []int {
+	values := make([]int, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i, key int) {
+			defer wg.Done()
+			value, err := l.Load(context.Background(), key)
+			assert.NoError(t, err)
+			values[i] = value
+		}(i, key)
+	}
+	wg.Wait()
+	return values
+}
+
+func TestLoader(t *testing.T) {
+	t.Run("concurrent loads are fetched together and cached", func(t *testing.T) {
+		r := &recorder{}
+		l := NewLoader(r.fetch, 20*time.Millisecond, 10)
+
+		assert.Equal(t, []int{10, 20, 30, 10}, loadAll(t, l, 1, 2, 3, 1))
+		assert.Equal(t, []int{20}, loadAll(t, l, 2))
+		assert.Equal(t, [][]int{{1, 2, 3}}, r.batches)
+	})
+
+	t.Run("a full batch is fetched without waiting", func(t *testing.T) {
+		r := &recorder{}
+		l := NewLoader(r.fetch, time.Hour, 2)
+
+		assert.Equal(t, []int{10, 20}, loadAll(t, l, 1, 2))
+		assert.Equal(t, [][]int{{1, 2}}, r.batches)
+	})
+
+	t.Run("errors are returned for their keys only", func(t *testing.T) {
+		r := &recorder{}
+		l := NewLoader(r.fetch, 0, 0)
+
+		_, err := l.Load(context.Background(), -1)
+		assert.EqualError(t, err, "negative key")
+		value, err := l.Load(context.Background(), 4)
+		require.NoError(t, err)
+		assert.Equal(t, 40, value)
+	})
+
+	t.Run("a key that the fetch leaves out fails", func(t *testing.T) {
+		l := NewLoader(func(context.Context, []int) ([]int, []error) { return nil, nil }, 0, 0)
+
+		_, err := l.Load(context.Background(), 1)
+		assert.ErrorIs(t, err, errMissingResult)
+	})
+}
+
+// fakeService serves families from a map; it implements only the reads of the loaders
+type fakeService struct {
+	ports.FamilyApplicationService
+	families map[string]*entity.FamilyDTO
+	batches  int
+}
+
+func (s *fakeService) GetFamilies(_ context.Context, ids []string) ([]*entity.FamilyDTO, error) {
+	s.batches++
+	var families []*entity.FamilyDTO
+	for _, id := range ids {
+		if fam, ok := s.families[id]; ok {
+			families = append(families, fam)
+		}
+	}
+	return families, nil
+}
+
+func (s *fakeService) GetFamily(_ context.Context, id string) (*entity.FamilyDTO, error) {
+	if fam, ok := s.families[id]; ok {
+		return fam, nil
+	}
+	return nil, serviceerrors.NewNotFoundError("Family", id, nil)
+}
+
+func (s *fakeService) FindFamilies(_ context.Context, filter query.Filter, _ query.Order) ([]*entity.FamilyDTO, error) {
+	s.batches++
+	var families []*entity.FamilyDTO
+	for _, fam := range s.families {
+		for _, parent := range fam.Parents {
+			for _, id := range filter.(query.Condition).Value.([]string) {
+				if parent.ID == id {
+					families = append(families, fam)
+				}
+			}
+		}
+	}
+	return families, nil
+}
+
+func TestLoaders(t *testing.T) {
+	service := &fakeService{families: map[string]*entity.FamilyDTO{
+		"f1": {ID: "f1", Parents: []entity.ParentDTO{{ID: "p1"}}},
+		"f2": {ID: "f2", Parents: []entity.ParentDTO{{ID: "p1"}, {ID: "p2"}}},
+	}}
+	loaders := NewLoaders(service, 0, 0)
+	ctx := context.Background()
+
+	fam, err := loaders.Families.Load(ctx, "f1")
+	require.NoError(t, err)
+	assert.Equal(t, "f1", fam.ID)
+
+	// A missing family is read on its own, for the error of a read of a single family
+	_, err = loaders.Families.Load(ctx, "missing")
+	assert.True(t, serviceerrors.IsNotFoundError(err))
+
+	families, err := loaders.FamiliesByParent.Load(ctx, "p2")
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "f2", families[0].ID)
+
+	families, err = loaders.FamiliesByParent.Load(ctx, "p3")
+	require.NoError(t, err)
+	assert.Empty(t, families)
+	assert.Equal(t, 4, service.batches)
+}
+
+func TestExtension_InterceptResponse(t *testing.T) {
+	ext := NewExtension(&fakeService{})
+	loadersOf := func(operation ast.Operation) *Loaders {
+		ctx := graphql.WithOperationContext(context.Background(), &graphql.OperationContext{
+			Operation: &ast.OperationDefinition{Operation: operation},
+		})
+		var loaders *Loaders
+		ext.InterceptResponse(ctx, func(ctx context.Context) *graphql.Response {
+			loaders = For(ctx)
+			return &graphql.Response{}
+		})
+		return loaders
+	}
+
+	assert.NotNil(t, loadersOf(ast.Query))
+	assert.Nil(t, loadersOf(ast.Mutation))
+	assert.Nil(t, For(context.Background()))
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package locale formats the names of family members for the locale of a request.
+//
+// Display conventions differ between locales: in Chinese, Japanese, Korean, and Hungarian
+// the family name comes first. The Accept-Language header of a request selects the name
+// templates that its displayName and sortName fields are formatted with. The templates of
+// a locale are found with a fallback policy: each language tag of the header, in order of
+// preference, is tried as written and then with its last subtag removed until a template
+// matches ("zh-Hant-TW", "zh-Hant", "zh"); if none does, the default locale is tried the
+// same way, and then the built-in Western template applies.
+package locale
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Header is the request header that selects the locales of a request
+const Header = "Accept-Language"
+
+// contextKey is the key of the locales of a request in its context
+type contextKey struct{}
+
+// WithLocales returns a context with the locales of a request, in order of preference
+func WithLocales(ctx context.Context, locales []string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locales)
+}
+
+// Locales returns the locales of a request, in order of preference, or nil if it has none
+func Locales(ctx context.Context) []string {
+	locales, _ := ctx.Value(contextKey{}).([]string)
+	return locales
+}
+
+// Middleware stores the locales of the Accept-Language header of each request in its context
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if header := r.Header.Get(Header); header != "" {
+			r = r.WithContext(WithLocales(r.Context(), ParseAcceptLanguage(header)))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header in lower case,
+// in order of preference. Tags with the same quality keep their order; the wildcard and
+// tags with a quality of zero or an invalid quality are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+			quality = q
+		}
+		if quality > 0 {
+			tags = append(tags, weighted{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].quality > tags[j].quality })
+	locales := make([]string, len(tags))
+	for i, t := range tags {
+		locales[i] = t.tag
+	}
+	return locales
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package locale
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"ja-jp", "ja", "en-us", "en"}, ParseAcceptLanguage("ja-JP, ja;q=0.9, en-US;q=0.8, en;q=0.7"))
+	assert.Equal(t, []string{"zh-hant-tw", "fr"}, ParseAcceptLanguage("fr;q=0.5, zh-Hant-TW"))
+	assert.Equal(t, []string{"de"}, ParseAcceptLanguage("*, de;q=0.1, it;q=0, es;q=high"))
+	assert.Empty(t, ParseAcceptLanguage(""))
+}
+
+func TestMiddleware(t *testing.T) {
+	var got []string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = Locales(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	req.Header.Set(Header, "ko-KR,ko;q=0.9")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, []string{"ko-kr", "ko"}, got)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/graphql", nil))
+	assert.Nil(t, got)
+}
+
+func TestFormatter(t *testing.T) {
+	f := NewDefaultFormatter()
+	in := func(locales ...string) context.Context {
+		return WithLocales(context.Background(), locales)
+	}
+
+	testCases := []struct {
+		name    string
+		ctx     context.Context
+		locale  string
+		display string
+		sort    string
+	}{
+		{"western", in("en-us"), "en", "Ada Lovelace", "Lovelace, Ada"},
+		{"chinese", in("zh-hant-tw"), "zh", "Wang Wei", "Wang Wei"},
+		{"japanese", in("ja"), "ja", "Yamada Taro", "Yamada Taro"},
+		{"korean after an unknown locale", in("tlh", "ko"), "ko", "Kim Minjun", "Kim Minjun"},
+		{"unknown locale", in("fr-ca"), "en", "Ada Lovelace", "Lovelace, Ada"},
+		{"no locale", context.Background(), "en", "Ada Lovelace", "Lovelace, Ada"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			locale, _ := f.Template(tc.ctx)
+			assert.Equal(t, tc.locale, locale)
+
+			first, last := "Ada", "Lovelace"
+			switch tc.locale {
+			case "zh":
+				first, last = "Wei", "Wang"
+			case "ja":
+				first, last = "Taro", "Yamada"
+			case "ko":
+				first, last = "Minjun", "Kim"
+			}
+			assert.Equal(t, tc.display, f.DisplayName(tc.ctx, first, last, ""))
+			assert.Equal(t, tc.sort, f.SortName(tc.ctx, first, last))
+		})
+	}
+
+	// Display names use the preferred name; sort names do not
+	assert.Equal(t, "Bea Lovelace", f.DisplayName(in("en"), "Beatrice", "Lovelace", "Bea"))
+	assert.Equal(t, "Lovelace, Beatrice", f.SortName(in("en"), "Beatrice", "Lovelace"))
+
+	// Empty names leave no stray separators
+	assert.Equal(t, "Lovelace", f.SortName(in("en"), "", "Lovelace"))
+}
+
+func TestNewFormatter(t *testing.T) {
+	// Configured templates replace built-in ones, and the default locale is the fallback
+	f, err := NewFormatter("HU", map[string]Template{
+		"ZH":    {Display: "{last}{first}", Sort: "{last}{first}"},
+		"en-gb": {Display: "{first} {last}", Sort: "{last} {first}"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "WangWei", f.DisplayName(WithLocales(context.Background(), []string{"zh-cn"}), "Wei", "Wang", ""))
+	assert.Equal(t, "Beatrice Lovelace", f.DisplayName(WithLocales(context.Background(), []string{"en-gb"}), "Beatrice", "Lovelace", "Bea"))
+	assert.Equal(t, "Nagy Anna", f.DisplayName(WithLocales(context.Background(), []string{"fr"}), "Anna", "Nagy", ""))
+
+	_, err = NewFormatter("", map[string]Template{"de": {Display: "{vorname} {last}", Sort: "{last}"}})
+	assert.ErrorContains(t, err, "unknown placeholder {vorname}")
+	_, err = NewFormatter("", map[string]Template{"de": {Display: "{first} {last}"}})
+	assert.ErrorContains(t, err, "template is empty")
+}
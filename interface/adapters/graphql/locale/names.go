@@ -0,0 +1,152 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package locale
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Placeholders of the name templates
+const (
+	PlaceholderFirst = "{first}" // First name
+	PlaceholderLast  = "{last}"  // Last name, the family name
+	PlaceholderGiven = "{given}" // Preferred name, or the first name if there is none
+)
+
+// DefaultLocale is the locale whose templates apply when no other locale matches
+const DefaultLocale = "en"
+
+// Template formats the names of a family member for a locale
+type Template struct {
+	Display string // Template of the name shown to users, such as "{given} {last}"
+	Sort    string // Template of the name that lists of members are sorted by, such as "{last}, {first}"
+}
+
+// western puts the given name first and sorts by family name
+var western = Template{Display: "{given} {last}", Sort: "{last}, {first}"}
+
+// familyNameFirst puts the family name first, for display and sorting
+var familyNameFirst = Template{Display: "{last} {given}", Sort: "{last} {first}"}
+
+// BuiltinTemplates are the templates of the locales that need no configuration.
+// Configured templates are added to them and replace those of the same locale.
+var BuiltinTemplates = map[string]Template{
+	DefaultLocale: western,
+	"hu":          familyNameFirst,
+	"ja":          familyNameFirst,
+	"ko":          familyNameFirst,
+	"zh":          familyNameFirst,
+}
+
+// placeholderPattern matches the placeholders of a template, and anything else in braces
+var placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// Formatter formats the names of family members for the locales of requests
+type Formatter struct {
+	templates     map[string]Template
+	defaultLocale string
+}
+
+// NewFormatter creates a formatter with the built-in templates and the given templates,
+// which are keyed by language tag
+//
+// Parameters:
+//   - defaultLocale: The locale whose templates apply to requests without a matching
+//     locale (DefaultLocale if empty)
+//   - templates: Templates by language tag, which add to or replace the built-in templates
+//
+// Returns:
+//   - A new formatter
+//   - An error if a template is empty or uses an unknown placeholder
+func NewFormatter(defaultLocale string, templates map[string]Template) (*Formatter, error) {
+	f := &Formatter{
+		templates:     make(map[string]Template, len(BuiltinTemplates)+len(templates)),
+		defaultLocale: strings.ToLower(defaultLocale),
+	}
+	if f.defaultLocale == "" {
+		f.defaultLocale = DefaultLocale
+	}
+	for tag, t := range BuiltinTemplates {
+		f.templates[tag] = t
+	}
+	for tag, t := range templates {
+		for _, template := range []string{t.Display, t.Sort} {
+			if err := validateTemplate(template); err != nil {
+				return nil, fmt.Errorf("invalid name template of locale %s: %w", tag, err)
+			}
+		}
+		f.templates[strings.ToLower(tag)] = t
+	}
+	return f, nil
+}
+
+// NewDefaultFormatter creates a formatter with the built-in templates only
+func NewDefaultFormatter() *Formatter {
+	f, _ := NewFormatter(DefaultLocale, nil)
+	return f
+}
+
+// validateTemplate checks that a template is not empty and only uses known placeholders
+func validateTemplate(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return fmt.Errorf("template is empty")
+	}
+	for _, placeholder := range placeholderPattern.FindAllString(template, -1) {
+		switch placeholder {
+		case PlaceholderFirst, PlaceholderLast, PlaceholderGiven:
+		default:
+			return fmt.Errorf("unknown placeholder %s in %q", placeholder, template)
+		}
+	}
+	return nil
+}
+
+// Template returns the locale and the templates that apply to a request, following the
+// fallback policy: the locales of the request in order of preference, then the default
+// locale, each tried with fewer and fewer subtags, then the built-in Western templates
+func (f *Formatter) Template(ctx context.Context) (string, Template) {
+	for _, locale := range append(Locales(ctx), f.defaultLocale) {
+		for tag := locale; tag != ""; {
+			if t, ok := f.templates[tag]; ok {
+				return tag, t
+			}
+			i := strings.LastIndex(tag, "-")
+			if i < 0 {
+				break
+			}
+			tag = tag[:i]
+		}
+	}
+	return DefaultLocale, western
+}
+
+// DisplayName formats the name of a family member for display to the user of a request
+func (f *Formatter) DisplayName(ctx context.Context, firstName, lastName, preferredName string) string {
+	_, t := f.Template(ctx)
+	return format(t.Display, firstName, lastName, preferredName)
+}
+
+// SortName formats the name of a family member that lists are sorted by for the user of a
+// request. It ignores preferred names, so that members sort by their legal names.
+func (f *Formatter) SortName(ctx context.Context, firstName, lastName string) string {
+	_, t := f.Template(ctx)
+	return format(t.Sort, firstName, lastName, "")
+}
+
+// format fills in the placeholders of a template. Runs of spaces left by empty names are
+// collapsed, and separators left dangling at either end are removed.
+func format(template, firstName, lastName, preferredName string) string {
+	given := preferredName
+	if given == "" {
+		given = firstName
+	}
+	name := strings.NewReplacer(
+		PlaceholderFirst, firstName,
+		PlaceholderLast, lastName,
+		PlaceholderGiven, given,
+	).Replace(template)
+	return strings.Trim(strings.Join(strings.Fields(name), " "), " ,")
+}
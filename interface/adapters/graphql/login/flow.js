@@ -0,0 +1,150 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Runs the OIDC authorization code flow with PKCE on the sign-in page.
+//
+// The sign-in page starts the flow and its callback completes it; the sign-in page with
+// ?logout=1 signs out.
+// The access token is kept in session storage under TOKEN_KEY, where session.js reads it.
+(function () {
+  "use strict";
+
+  var TOKEN_KEY = "family-service.login.token";
+  var PENDING_KEY = "family-service.login.pending";
+
+  var client = JSON.parse(document.getElementById("login-client").textContent);
+  var DEFAULT_RETURN = client.returnPath;
+  var params = new URLSearchParams(location.search);
+
+  function status(message) {
+    document.getElementById("login-status").textContent = message;
+  }
+
+  // Only paths of this service are valid return addresses
+  function safeReturn(path) {
+    if (!path || path.charAt(0) !== "/" || path.charAt(1) === "/" || path.charAt(1) === "\\") {
+      return DEFAULT_RETURN;
+    }
+    return path;
+  }
+
+  function base64url(bytes) {
+    var binary = "";
+    for (var i = 0; i < bytes.length; i++) {
+      binary += String.fromCharCode(bytes[i]);
+    }
+    return btoa(binary).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+  }
+
+  function randomString() {
+    return base64url(crypto.getRandomValues(new Uint8Array(32)));
+  }
+
+  function challenge(verifier) {
+    return crypto.subtle.digest("SHA-256", new TextEncoder().encode(verifier)).then(function (digest) {
+      return base64url(new Uint8Array(digest));
+    });
+  }
+
+  // subject returns the name of the signed-in user from the claims of a JWT, if it is one
+  function subject(token) {
+    try {
+      var payload = token.split(".")[1].replace(/-/g, "+").replace(/_/g, "/");
+      var claims = JSON.parse(atob(payload));
+      return claims.preferred_username || claims.email || claims.sub || "";
+    } catch (e) {
+      return "";
+    }
+  }
+
+  function redirectURI() {
+    return location.origin + client.callbackPath;
+  }
+
+  function start() {
+    var returnTo = safeReturn(params.get("return_to"));
+    var pending = { state: randomString(), verifier: randomString(), returnTo: returnTo };
+
+    fetch(client.issuer + "/.well-known/openid-configuration")
+      .then(function (response) {
+        if (!response.ok) {
+          throw new Error("discovery failed with status " + response.status);
+        }
+        return response.json();
+      })
+      .then(function (discovery) {
+        pending.tokenEndpoint = discovery.token_endpoint;
+        return challenge(pending.verifier).then(function (codeChallenge) {
+          sessionStorage.setItem(PENDING_KEY, JSON.stringify(pending));
+          var query = new URLSearchParams({
+            response_type: "code",
+            client_id: client.clientId,
+            redirect_uri: redirectURI(),
+            scope: client.scope,
+            state: pending.state,
+            code_challenge: codeChallenge,
+            code_challenge_method: "S256"
+          });
+          location.assign(discovery.authorization_endpoint + "?" + query.toString());
+        });
+      })
+      .catch(function (err) {
+        status("Sign-in is not available: " + err.message);
+      });
+  }
+
+  function complete() {
+    var pending = JSON.parse(sessionStorage.getItem(PENDING_KEY) || "null");
+    sessionStorage.removeItem(PENDING_KEY);
+
+    if (params.get("error")) {
+      status("Sign-in failed: " + (params.get("error_description") || params.get("error")));
+      return;
+    }
+    if (!pending || params.get("state") !== pending.state || !params.get("code")) {
+      status("Sign-in failed: the response does not match a sign-in started here. Try again.");
+      return;
+    }
+
+    fetch(pending.tokenEndpoint, {
+      method: "POST",
+      headers: { "Content-Type": "application/x-www-form-urlencoded" },
+      body: new URLSearchParams({
+        grant_type: "authorization_code",
+        code: params.get("code"),
+        redirect_uri: redirectURI(),
+        client_id: client.clientId,
+        code_verifier: pending.verifier
+      })
+    })
+      .then(function (response) {
+        if (!response.ok) {
+          throw new Error("token request failed with status " + response.status);
+        }
+        return response.json();
+      })
+      .then(function (tokens) {
+        if (!tokens.access_token) {
+          throw new Error("no access token was issued");
+        }
+        var lifetime = Number(tokens.expires_in) || 3600;
+        sessionStorage.setItem(TOKEN_KEY, JSON.stringify({
+          accessToken: tokens.access_token,
+          subject: subject(tokens.id_token || "") || subject(tokens.access_token),
+          expiresAt: Date.now() + lifetime * 1000
+        }));
+        location.replace(pending.returnTo);
+      })
+      .catch(function (err) {
+        status("Sign-in failed: " + err.message);
+      });
+  }
+
+  if (location.pathname === client.callbackPath) {
+    complete();
+  } else if (params.has("logout")) {
+    sessionStorage.removeItem(TOKEN_KEY);
+    location.replace(safeReturn(params.get("return_to")));
+  } else {
+    start();
+  }
+})();
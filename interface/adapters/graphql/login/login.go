@@ -0,0 +1,260 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package login lets developers sign in to the playground and the documentation portal
+// with the identity provider, instead of pasting tokens.
+//
+// The sign-in page at the login route runs the OIDC authorization code flow with PKCE in
+// the browser: it discovers the endpoints of the issuer, redirects to its authorization
+// endpoint, and exchanges the code returned to its callback for an access token at
+// its token endpoint. The client is public, so the service holds no client secret. The
+// token is kept in the session storage of the browser until it expires or the developer
+// signs out. Pages wrapped with Inject load a script that shows who is signed in and adds
+// the token as a bearer token to their requests to the service.
+package login
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/etag"
+)
+
+// Paths served below the path of the sign-in page
+const (
+	// CallbackSuffix is the path to which the identity provider redirects after sign-in
+	CallbackSuffix = "/callback"
+
+	// FlowScriptSuffix is the path of the script that runs the authorization code flow
+	FlowScriptSuffix = "/flow.js"
+
+	// SessionScriptSuffix is the path of the script injected into pages by Inject
+	SessionScriptSuffix = "/session.js"
+)
+
+//go:embed login.html.tmpl
+var pageTemplate string
+
+//go:embed flow.js
+var flowScript []byte
+
+//go:embed session.js
+var sessionScript []byte
+
+// page is the parsed sign-in page template
+var page = template.Must(template.New("login").Parse(pageTemplate))
+
+// Client is the OIDC client configuration passed to the sign-in page
+type Client struct {
+	Issuer       string `json:"issuer"`
+	ClientID     string `json:"clientId"`
+	Scope        string `json:"scope"`
+	CallbackPath string `json:"callbackPath"`
+	ReturnPath   string `json:"returnPath"` // Page shown after sign-in if none was requested
+}
+
+// view is the data of the sign-in page
+type view struct {
+	Client         Client
+	FlowScriptPath string
+	PlaygroundPath string
+	DocsPath       string
+}
+
+// Handler serves the sign-in page and the login scripts
+type Handler struct {
+	path       string
+	view       view
+	csp        string
+	sessionTag []byte // Script element that Inject adds to the head of pages
+}
+
+// NewHandler creates the handler of the sign-in page of an identity provider.
+//
+// Parameters:
+//   - cfg: The identity provider and client
+//   - routes: The paths of the sign-in page and of the pages it links to
+//
+// Returns:
+//   - The handler, to be registered at its Path and below
+//   - An error if the issuer is not an absolute http or https URL, or the client ID is missing
+func NewHandler(cfg config.PlaygroundLoginConfig, routes config.RoutesConfig) (*Handler, error) {
+	issuer, err := url.Parse(strings.TrimSuffix(cfg.Issuer, "/"))
+	if err != nil || (issuer.Scheme != "https" && issuer.Scheme != "http") || issuer.Host == "" {
+		return nil, fmt.Errorf("invalid OIDC issuer %q: must be an absolute http or https URL", cfg.Issuer)
+	}
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("OIDC client ID is required")
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid"}
+	}
+
+	path := routes.Path(routes.Login)
+	origin := issuer.Scheme + "://" + issuer.Host
+	return &Handler{
+		path: path,
+		view: view{
+			Client: Client{
+				Issuer:       issuer.String(),
+				ClientID:     cfg.ClientID,
+				Scope:        strings.Join(scopes, " "),
+				CallbackPath: path + CallbackSuffix,
+				ReturnPath:   routes.Path(routes.Playground),
+			},
+			FlowScriptPath: path + FlowScriptSuffix,
+			PlaygroundPath: routes.Path(routes.Playground),
+			DocsPath:       routes.Path(routes.Docs),
+		},
+		// The page only talks to the identity provider, whose token endpoint must share its origin
+		csp:        "default-src 'none'; script-src 'self'; connect-src " + origin + "; style-src 'unsafe-inline'",
+		sessionTag: []byte(`<script src="` + template.HTMLEscapeString(path+SessionScriptSuffix) + `"></script>`),
+	}, nil
+}
+
+// Path returns the path of the sign-in page
+func (h *Handler) Path() string {
+	return h.path
+}
+
+// ServeHTTP serves the sign-in page at its path and callback, and the login scripts
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch r.URL.Path {
+	case h.path, h.path + CallbackSuffix:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", h.csp)
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		if r.Method == http.MethodHead {
+			return
+		}
+		_ = page.Execute(w, h.view)
+	case h.path + FlowScriptSuffix:
+		serveScript(w, r, flowScript)
+	case h.path + SessionScriptSuffix:
+		serveScript(w, r, sessionScript)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveScript serves an embedded script
+func serveScript(w http.ResponseWriter, r *http.Request, script []byte) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(script)
+}
+
+// Inject returns HTTP middleware that adds the session script to the head of the HTML
+// pages served by next, such as the playground and the documentation portal. The script
+// shows a sign-in link or the signed-in user, and sends the token with the requests of
+// the page to the service. A Content-Security-Policy of the page is extended to allow the
+// script, and the ETag of the page is recomputed from the page with the script.
+func (h *Handler) Inject(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ifNoneMatch := r.Header.Get("If-None-Match")
+		r.Header.Del("If-None-Match")
+		rec := &recorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		for key, values := range rec.header {
+			w.Header()[key] = values
+		}
+		body := rec.body
+		if rec.status == http.StatusOK && strings.HasPrefix(rec.header.Get("Content-Type"), "text/html") {
+			body = injectScript(body, h.sessionTag)
+			if csp := rec.header.Get("Content-Security-Policy"); csp != "" {
+				w.Header().Set("Content-Security-Policy", allowSessionScript(csp))
+			}
+			w.Header().Del("Content-Length")
+			tag := etag.Compute(body)
+			w.Header().Set("ETag", tag)
+			if etag.Match(ifNoneMatch, tag) {
+				w.Header().Del("Content-Type")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(body)
+	})
+}
+
+// injectScript inserts the session script element before the end of the head of a page,
+// or at its start if it has no head
+func injectScript(body, sessionTag []byte) []byte {
+	at := strings.Index(strings.ToLower(string(body)), "</head>")
+	if at < 0 {
+		at = 0
+	}
+	injected := make([]byte, 0, len(body)+len(sessionTag))
+	injected = append(injected, body[:at]...)
+	injected = append(injected, sessionTag...)
+	return append(injected, body[at:]...)
+}
+
+// allowSessionScript extends a Content-Security-Policy to allow scripts of the service
+func allowSessionScript(csp string) string {
+	directives := strings.Split(csp, ";")
+	for i, directive := range directives {
+		fields := strings.Fields(directive)
+		if len(fields) > 0 && fields[0] == "script-src" {
+			for _, source := range fields[1:] {
+				if source == "'self'" {
+					return csp
+				}
+			}
+			directives[i] = strings.TrimRight(directive, " ") + " 'self'"
+			return strings.Join(directives, ";")
+		}
+	}
+	return strings.TrimRight(csp, "; ") + "; script-src 'self'"
+}
+
+// recorder buffers a page so that the session script can be inserted before it is written
+type recorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        []byte
+}
+
+// Header returns the headers of the buffered response
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader records the status code of the response
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+}
+
+// Write buffers the body of the response
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body = append(r.body, b...)
+	return len(b), nil
+}
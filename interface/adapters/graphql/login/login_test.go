@@ -0,0 +1,177 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package login
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRoutes are the default routes
+var testRoutes = config.RoutesConfig{
+	GraphQL:    "/graphql",
+	Playground: "/playground",
+	GraphiQL:   "/graphiql",
+	Docs:       "/docs",
+	Login:      "/login",
+}
+
+func newTestHandler(t *testing.T, routes config.RoutesConfig) *Handler {
+	h, err := NewHandler(config.PlaygroundLoginConfig{
+		Enabled:  true,
+		Issuer:   "https://idp.example.com/realms/family/",
+		ClientID: "family-playground",
+		Scopes:   []string{"openid", "profile"},
+	}, routes)
+	require.NoError(t, err)
+	return h
+}
+
+func TestNewHandler_Invalid(t *testing.T) {
+	for name, cfg := range map[string]config.PlaygroundLoginConfig{
+		"missing issuer":   {Enabled: true, ClientID: "family-playground"},
+		"relative issuer":  {Enabled: true, Issuer: "/realms/family", ClientID: "family-playground"},
+		"other scheme":     {Enabled: true, Issuer: "ftp://idp.example.com", ClientID: "family-playground"},
+		"missing clientID": {Enabled: true, Issuer: "https://idp.example.com"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewHandler(cfg, testRoutes)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHandler_Page(t *testing.T) {
+	h := newTestHandler(t, testRoutes)
+	assert.Equal(t, "/login", h.Path())
+
+	for _, path := range []string{"/login", "/login/callback?code=abc&state=xyz"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		assert.Contains(t, rec.Header().Get("Content-Security-Policy"), "connect-src https://idp.example.com;")
+
+		match := regexp.MustCompile(`(?s)<script id="login-client" type="application/json">(.*?)</script>`).FindStringSubmatch(rec.Body.String())
+		require.Len(t, match, 2)
+		var client Client
+		require.NoError(t, json.Unmarshal([]byte(match[1]), &client))
+		assert.Equal(t, Client{
+			Issuer:       "https://idp.example.com/realms/family",
+			ClientID:     "family-playground",
+			Scope:        "openid profile",
+			CallbackPath: "/login/callback",
+			ReturnPath:   "/playground",
+		}, client)
+		assert.Contains(t, rec.Body.String(), `<script src="/login/flow.js" defer></script>`)
+		assert.Contains(t, rec.Body.String(), `<a href="/playground">Playground</a> &middot; <a href="/docs">Documentation</a>`)
+	}
+}
+
+func TestHandler_Prefix(t *testing.T) {
+	routes := testRoutes
+	routes.Prefix = "/family-service"
+	routes.Login = "/sign-in"
+	h := newTestHandler(t, routes)
+	assert.Equal(t, "/family-service/sign-in", h.Path())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/family-service/sign-in", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"callbackPath":"/family-service/sign-in/callback"`)
+	assert.Contains(t, rec.Body.String(), `"returnPath":"/family-service/playground"`)
+	assert.Contains(t, rec.Body.String(), `<script src="/family-service/sign-in/flow.js" defer></script>`)
+	assert.Contains(t, rec.Body.String(), `<a href="/family-service/docs">Documentation</a>`)
+
+	for _, path := range []string{"/family-service/sign-in/callback", "/family-service/sign-in/session.js"} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandler_Scripts(t *testing.T) {
+	h := newTestHandler(t, testRoutes)
+
+	for _, path := range []string{"/login/flow.js", "/login/session.js"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, "text/javascript; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.NotEmpty(t, rec.Body.Bytes())
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestInject(t *testing.T) {
+	page := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+		w.Header().Set("ETag", `"page"`)
+		_, _ = w.Write([]byte("<html><head><title>Docs</title></head><body></body></html>"))
+	})
+	handler := newTestHandler(t, testRoutes).Inject(page)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `<html><head><title>Docs</title><script src="/login/session.js"></script></head><body></body></html>`, rec.Body.String())
+	assert.Equal(t, "default-src 'none'; style-src 'unsafe-inline'; script-src 'self'", rec.Header().Get("Content-Security-Policy"))
+
+	tag := rec.Header().Get("ETag")
+	assert.NotEqual(t, `"page"`, tag)
+
+	// The ETag of the page with the script answers conditional requests
+	req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	req.Header.Set("If-None-Match", tag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.Bytes())
+
+	// The ETag of the page without the script does not
+	req = httptest.NewRequest(http.MethodGet, "/docs", nil)
+	req.Header.Set("If-None-Match", `"page"`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestInject_NotHTML(t *testing.T) {
+	handler := newTestHandler(t, testRoutes).Inject(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"head":"</head>"}`))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/playground", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, `{"head":"</head>"}`, rec.Body.String())
+}
+
+func TestAllowSessionScript(t *testing.T) {
+	assert.Equal(t, "default-src 'none'; script-src 'self'", allowSessionScript("default-src 'none'"))
+	assert.Equal(t, "default-src 'none'; script-src https://cdn.example.com 'self'", allowSessionScript("default-src 'none'; script-src https://cdn.example.com"))
+	assert.Equal(t, "script-src 'self' 'unsafe-inline'", allowSessionScript("script-src 'self' 'unsafe-inline'"))
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Sends the token acquired on the sign-in page with the requests of the page to the
+// service, and shows who is signed in. It is loaded in the head of the page, before the
+// scripts of the page capture fetch. An Authorization header set by the page, such as one
+// entered in the headers editor of the playground, takes precedence over the token.
+(function () {
+  "use strict";
+
+  var TOKEN_KEY = "family-service.login.token";
+
+  // The script is served below the sign-in page, wherever the routes put it
+  var LOGIN_PATH = new URL(document.currentScript.src).pathname.replace(/\/session\.js$/, "");
+
+  function session() {
+    var stored = JSON.parse(sessionStorage.getItem(TOKEN_KEY) || "null");
+    if (stored && stored.expiresAt <= Date.now()) {
+      sessionStorage.removeItem(TOKEN_KEY);
+      return null;
+    }
+    return stored;
+  }
+
+  var originalFetch = window.fetch;
+  window.fetch = function (input, init) {
+    var current = session();
+    var target = new URL(input instanceof Request ? input.url : String(input), location.href);
+    if (!current || target.origin !== location.origin) {
+      return originalFetch.call(this, input, init);
+    }
+
+    var request = new Request(input, init);
+    if (!request.headers.has("Authorization")) {
+      var headers = new Headers(request.headers);
+      headers.set("Authorization", "Bearer " + current.accessToken);
+      request = new Request(request, { headers: headers });
+    }
+    return originalFetch.call(this, request);
+  };
+
+  function link(text, href) {
+    var a = document.createElement("a");
+    a.textContent = text;
+    a.href = href;
+    a.style.color = "#ffffff";
+    a.style.marginLeft = "0.5rem";
+    return a;
+  }
+
+  function render() {
+    var here = encodeURIComponent(location.pathname + location.search + location.hash);
+    var bar = document.createElement("div");
+    bar.id = "family-service-login";
+    bar.style.cssText = "position: fixed; right: 1rem; bottom: 1rem; z-index: 10000; padding: 0.4rem 0.75rem;" +
+      "border-radius: 4px; background: #243b53; color: #ffffff; font: 13px sans-serif;";
+
+    var current = session();
+    if (current) {
+      bar.appendChild(document.createTextNode("Signed in" + (current.subject ? " as " + current.subject : "")));
+      bar.appendChild(link("Sign out", LOGIN_PATH + "?logout=1&return_to=" + here));
+    } else {
+      bar.appendChild(link("Sign in", LOGIN_PATH + "?return_to=" + here));
+    }
+    document.body.appendChild(bar);
+  }
+
+  if (document.readyState === "loading") {
+    document.addEventListener("DOMContentLoaded", render);
+  } else {
+    render();
+  }
+})();
@@ -0,0 +1,136 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package maintenance lets operators put the GraphQL API in maintenance mode.
+//
+// While the mode is on, mutations fail with the MAINTENANCE_MODE error code and queries
+// are still served, so that the data can be read while it is being repaired, migrated, or
+// reindexed. The mode is held in memory and applies to the replica it is set on.
+package maintenance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CodeMaintenanceMode is the error code of a mutation refused during maintenance
+const CodeMaintenanceMode = "MAINTENANCE_MODE"
+
+var (
+	enabledGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "graphql_maintenance_mode",
+		Help: "Whether the GraphQL API is in maintenance mode (1) or not (0)",
+	})
+
+	refusedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "graphql_maintenance_refused_total",
+		Help: "Number of mutations refused because the GraphQL API was in maintenance mode",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(enabledGauge, refusedCounter)
+}
+
+// State is the state of the maintenance mode
+type State struct {
+	Enabled bool      `json:"enabled"`
+	Reason  string    `json:"reason,omitempty"`
+	Since   time.Time `json:"since,omitempty"`
+}
+
+// Mode is the maintenance mode of the GraphQL API; it is off when created
+type Mode struct {
+	mu    sync.RWMutex
+	state State
+	now   func() time.Time
+}
+
+// NewMode creates a maintenance mode that is off
+func NewMode() *Mode {
+	return &Mode{now: time.Now}
+}
+
+// Set turns the maintenance mode on or off and returns its new state.
+// The reason is only kept while the mode is on.
+func (m *Mode) Set(enabled bool, reason string) State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case !enabled:
+		m.state = State{}
+		enabledGauge.Set(0)
+	case !m.state.Enabled:
+		m.state = State{Enabled: true, Reason: reason, Since: m.now()}
+		enabledGauge.Set(1)
+	default:
+		m.state.Reason = reason
+	}
+	return m.state
+}
+
+// State returns the state of the maintenance mode
+func (m *Mode) State() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Extension is a gqlgen handler extension that refuses mutations while the maintenance
+// mode is on
+type Extension struct {
+	mode *Mode
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = Extension{}
+
+// NewExtension creates an extension that refuses mutations while the mode is on
+func NewExtension(mode *Mode) Extension {
+	return Extension{mode: mode}
+}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "MaintenanceMode"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation refuses mutations while the maintenance mode is on
+func (e Extension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+	if oc.Operation == nil || oc.Operation.Operation != ast.Mutation {
+		return next(ctx)
+	}
+
+	state := e.mode.State()
+	if !state.Enabled {
+		return next(ctx)
+	}
+
+	refusedCounter.Inc()
+	message := "the service is in maintenance mode; mutations are unavailable"
+	if state.Reason != "" {
+		message += ": " + state.Reason
+	}
+	return graphql.OneShot(&graphql.Response{
+		Errors: gqlerror.List{{
+			Message:    message,
+			Extensions: map[string]interface{}{"code": CodeMaintenanceMode},
+		}},
+	})
+}
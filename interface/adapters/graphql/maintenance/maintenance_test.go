@@ -0,0 +1,70 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func operationContext(operation ast.Operation) context.Context {
+	return graphql.WithOperationContext(context.Background(), &graphql.OperationContext{
+		Operation: &ast.OperationDefinition{Operation: operation},
+	})
+}
+
+func run(ext Extension, ctx context.Context) *graphql.Response {
+	return ext.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		return graphql.OneShot(&graphql.Response{Data: json.RawMessage(`{"ok":true}`)})
+	})(ctx)
+}
+
+func TestMode_Set(t *testing.T) {
+	mode := NewMode()
+	since := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	mode.now = func() time.Time { return since }
+
+	assert.Equal(t, State{}, mode.State())
+
+	assert.Equal(t, State{Enabled: true, Reason: "reindexing", Since: since}, mode.Set(true, "reindexing"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(enabledGauge))
+
+	// Changing the reason keeps the time the mode was turned on
+	mode.now = func() time.Time { return since.Add(time.Hour) }
+	assert.Equal(t, State{Enabled: true, Reason: "migrating", Since: since}, mode.Set(true, "migrating"))
+
+	assert.Equal(t, State{}, mode.Set(false, "ignored"))
+	assert.Equal(t, 0.0, testutil.ToFloat64(enabledGauge))
+}
+
+func TestExtension_RefusesMutations(t *testing.T) {
+	mode := NewMode()
+	ext := NewExtension(mode)
+
+	resp := run(ext, operationContext(ast.Mutation))
+	assert.Empty(t, resp.Errors)
+
+	mode.Set(true, "reindexing")
+	before := testutil.ToFloat64(refusedCounter)
+
+	resp = run(ext, operationContext(ast.Query))
+	assert.Empty(t, resp.Errors)
+
+	resp = run(ext, operationContext(ast.Mutation))
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, CodeMaintenanceMode, resp.Errors[0].Extensions["code"])
+	assert.Contains(t, resp.Errors[0].Message, "reindexing")
+	assert.Equal(t, before+1, testutil.ToFloat64(refusedCounter))
+
+	mode.Set(false, "")
+	resp = run(ext, operationContext(ast.Mutation))
+	assert.Empty(t, resp.Errors)
+}
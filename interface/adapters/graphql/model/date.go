@@ -0,0 +1,30 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+)
+
+// MarshalDate writes a Date scalar as "YYYY-MM-DD"
+func MarshalDate(d entity.Date) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, strconv.Quote(d.String()))
+	})
+}
+
+// UnmarshalDate reads a Date scalar. A date is written as "YYYY-MM-DD"; for
+// compatibility with clients that send timestamps, an RFC3339 timestamp is also
+// accepted and its date is taken as written, without converting it to UTC.
+func UnmarshalDate(v interface{}) (entity.Date, error) {
+	s, ok := v.(string)
+	if !ok {
+		return entity.Date{}, fmt.Errorf("date must be a string in YYYY-MM-DD format")
+	}
+	return entity.ParseDate(s)
+}
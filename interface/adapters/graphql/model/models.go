@@ -3,23 +3,74 @@
 package model
 
 import (
+	"github.com/abitofhelp/family-service/core/domain/entity"
 	"github.com/abitofhelp/servicelib/valueobject/identification"
 )
 
 // Parent represents a parent in a family
 type Parent struct {
-	ID        identification.ID `json:"id"`
-	FirstName string            `json:"firstName"`
-	LastName  string            `json:"lastName"`
-	BirthDate string            `json:"birthDate"`
-	DeathDate *string           `json:"deathDate,omitempty"`
+	ID            identification.ID `json:"id"`
+	FirstName     string            `json:"firstName"`
+	LastName      string            `json:"lastName"`
+	BirthDate     entity.Date       `json:"birthDate"`
+	DeathDate     *entity.Date      `json:"deathDate,omitempty"`
+	ExternalIds   []*ExternalID     `json:"externalIds"`
+	PreferredName *string           `json:"preferredName,omitempty"`
+	NameHistory   []*NameChange     `json:"nameHistory"`
 }
 
 // Child represents a child in a family
 type Child struct {
-	ID        identification.ID `json:"id"`
-	FirstName string            `json:"firstName"`
-	LastName  string            `json:"lastName"`
-	BirthDate string            `json:"birthDate"`
-	DeathDate *string           `json:"deathDate,omitempty"`
+	ID             identification.ID `json:"id"`
+	FirstName      string            `json:"firstName"`
+	LastName       string            `json:"lastName"`
+	BirthDate      entity.Date       `json:"birthDate"`
+	DeathDate      *entity.Date      `json:"deathDate,omitempty"`
+	ExternalIds    []*ExternalID     `json:"externalIds"`
+	PreferredName  *string           `json:"preferredName,omitempty"`
+	NameHistory    []*NameChange     `json:"nameHistory"`
+	Consents       []*Consent        `json:"consents"`
+	WithheldScopes []ConsentScope    `json:"withheldScopes"`
+}
+
+// IsPerson marks Parent as an implementation of the Person interface
+func (Parent) IsPerson() {}
+
+// GetID returns the ID of the parent
+func (p Parent) GetID() identification.ID { return p.ID }
+
+// GetFirstName returns the first name of the parent
+func (p Parent) GetFirstName() string { return p.FirstName }
+
+// GetLastName returns the last name of the parent
+func (p Parent) GetLastName() string { return p.LastName }
+
+// GetBirthDate returns the birth date of the parent
+func (p Parent) GetBirthDate() entity.Date { return p.BirthDate }
+
+// GetDeathDate returns the death date of the parent, or nil if the parent is alive
+func (p Parent) GetDeathDate() *entity.Date { return p.DeathDate }
+
+// IsPerson marks Child as an implementation of the Person interface
+func (Child) IsPerson() {}
+
+// GetID returns the ID of the child
+func (c Child) GetID() identification.ID { return c.ID }
+
+// GetFirstName returns the first name of the child
+func (c Child) GetFirstName() string { return c.FirstName }
+
+// GetLastName returns the last name of the child
+func (c Child) GetLastName() string { return c.LastName }
+
+// GetBirthDate returns the birth date of the child
+func (c Child) GetBirthDate() entity.Date { return c.BirthDate }
+
+// GetDeathDate returns the death date of the child, or nil if the child is alive
+func (c Child) GetDeathDate() *entity.Date { return c.DeathDate }
+
+// ExternalID represents the identifier of a family or family member in an external system
+type ExternalID struct {
+	System string `json:"system"`
+	ID     string `json:"id"`
 }
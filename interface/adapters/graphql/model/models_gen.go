@@ -8,9 +8,66 @@
 	"io"
 	"strconv"
 
+	"github.com/abitofhelp/family-service/core/domain/entity"
 	"github.com/abitofhelp/servicelib/valueobject/identification"
 )
 
+// Person is a member of a family, either a parent or a child.
+// Fragments on Person select the fields that parents and children share.
+type Person interface {
+	IsPerson()
+	// Unique identifier for the person
+	GetID() identification.ID
+	// First name of the person
+	GetFirstName() string
+	// Last name of the person
+	GetLastName() string
+	// Birth date of the person
+	GetBirthDate() entity.Date
+	// Death date of the person, if applicable
+	GetDeathDate() *entity.Date
+}
+
+// Result of the addChildV2 mutation.
+type AddChildPayload struct {
+	// The updated family, or null if the child could not be added
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the addParentV2 mutation.
+type AddParentPayload struct {
+	// The updated family, or null if the parent could not be added
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// AgePolicyViolation describes a parent-child pair in a family with implausible ages.
+type AgePolicyViolation struct {
+	// ID of the family containing the parent and child
+	FamilyID identification.ID `json:"familyId"`
+	// ID of the parent
+	ParentID identification.ID `json:"parentId"`
+	// ID of the child
+	ChildID identification.ID `json:"childId"`
+	// Rule that was violated
+	Rule AgePolicyRule `json:"rule"`
+	// Age of the parent in whole years when the child was born (negative if the parent is younger)
+	ParentAgeAtBirth int `json:"parentAgeAtBirth"`
+	// Human-readable description of the violation
+	Message string `json:"message"`
+}
+
+// Result of the changeMemberNameV2 mutation.
+type ChangeMemberNamePayload struct {
+	// The updated family, or null if the name change could not be recorded
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
 // Input for creating or adding a child to a family.
 type ChildInput struct {
 	// Unique identifier for the child
@@ -19,12 +76,63 @@ type ChildInput struct {
 	FirstName string `json:"firstName"`
 	// Last name of the child (1-50 characters)
 	LastName string `json:"lastName"`
-	// Birth date of the child in RFC3339 format (YYYY-MM-DD).
+	// Birth date of the child.
 	// Must not be in the future.
-	BirthDate string `json:"birthDate"`
-	// Death date of the child in RFC3339 format (YYYY-MM-DD), if applicable.
+	BirthDate entity.Date `json:"birthDate"`
+	// Death date of the child, if applicable.
 	// Must be after the birth date and not in the future.
-	DeathDate *string `json:"deathDate,omitempty"`
+	DeathDate *entity.Date `json:"deathDate,omitempty"`
+	// IDs of the child in external systems, at most one per system.
+	// Each external ID must not be assigned to another child.
+	ExternalIds []*ExternalIDInput `json:"externalIds,omitempty"`
+}
+
+// Consent records that a parent consented to the data of a child being stored and returned.
+// Consents stop being current when they reach the configured maximum age, and are then
+// flagged as expired by a background check.
+type Consent struct {
+	// ID of the parent who granted the consent
+	GrantedBy identification.ID `json:"grantedBy"`
+	// When the consent was granted, in RFC3339 format
+	GrantedAt string `json:"grantedAt"`
+	// Data of the child covered by the consent
+	Scopes []ConsentScope `json:"scopes"`
+	// When the consent was flagged as expired, in RFC3339 format, or null while it is current
+	ExpiredAt *string `json:"expiredAt,omitempty"`
+}
+
+// Input for a consent granted by a parent for the data of a child.
+type ConsentInput struct {
+	// ID of the parent granting the consent, who must be a parent of the family
+	GrantedBy identification.ID `json:"grantedBy"`
+	// Data of the child covered by the consent (at least one scope)
+	Scopes []ConsentScope `json:"scopes"`
+	// When the consent was granted, in RFC3339 format (now if omitted; cannot be in the future)
+	GrantedAt *string `json:"grantedAt,omitempty"`
+}
+
+// Result of the createFamilyV2 mutation.
+type CreateFamilyPayload struct {
+	// The created family, or null if it could not be created
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the deleteFamilyV2 mutation.
+type DeleteFamilyPayload struct {
+	// ID of the deleted family, or null if it could not be deleted
+	DeletedFamilyID *identification.ID `json:"deletedFamilyId,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the divorceV2 mutation.
+type DivorcePayload struct {
+	// The original family after the divorce, or null if the divorce could not be processed
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
 }
 
 // Error represents an error that occurred during a GraphQL operation.
@@ -38,6 +146,24 @@ type Error struct {
 	Path []string `json:"path,omitempty"`
 }
 
+// Filter for finding families by external ID.
+type ExternalIDFilter struct {
+	// Name of the external system
+	System string `json:"system"`
+	// Identifier in the external system
+	ID string `json:"id"`
+	// Kind of entity holding the external ID (any kind if omitted)
+	Owner *ExternalIDOwner `json:"owner,omitempty"`
+}
+
+// Input for an identifier in an external system.
+type ExternalIDInput struct {
+	// Name of the external system (lowercase letters, digits, '-' or '_')
+	System string `json:"system"`
+	// Identifier of the entity in the external system
+	ID string `json:"id"`
+}
+
 // Family represents a family unit with parents and children.
 // A family must have at least one parent and can have zero or more children.
 // A family can have at most two parents.
@@ -54,6 +180,88 @@ type Family struct {
 	ParentCount int `json:"parentCount"`
 	// Number of children in the family
 	ChildrenCount int `json:"childrenCount"`
+	// Number of living children younger than the age of majority.
+	// Computed by the server, so that dashboards need not fetch the members.
+	NumberOfDependents int `json:"numberOfDependents"`
+	// Whether a parent or a child of the family has died
+	HasDeceasedMembers bool `json:"hasDeceasedMembers"`
+	// Whole years between the births of the oldest and the youngest member of the family
+	GenerationSpanYears int `json:"generationSpanYears"`
+	// IDs of the family in external systems
+	ExternalIds []*ExternalID `json:"externalIds"`
+	// ID of the user who owns the family, who created it, or null if it has no owner.
+	// Users other than administrators only see and change the families they own.
+	OwnerID *string `json:"ownerId,omitempty"`
+	// Checksum of the state of the family, which changes whenever the family changes.
+	// Use it to validate cached copies of the family, like an HTTP ETag.
+	Checksum string `json:"checksum"`
+	// Version of the stored family, which counts its saves, or 0 where a list of families was
+	// read without versions. A mutation that saves a family changed by another operation since
+	// it was read fails with the CONCURRENCY_CONFLICT error code and may be retried at once.
+	Version int `json:"version"`
+}
+
+// FamilyChange is a difference between two families, such as a changed field of a member
+// or a member that only one of them has.
+type FamilyChange struct {
+	// Path of the value in the family, such as parents[0].firstName
+	Path string `json:"path"`
+	// ID of the parent or child of the left family the value belongs to, or null for a field of the family
+	MemberID *identification.ID `json:"memberId,omitempty"`
+	// Name of the changed field, such as firstName, or null for a whole member
+	Field *string `json:"field,omitempty"`
+	// Kind of the change
+	Kind FamilyChangeKind `json:"kind"`
+	// Value in the left family, or null if added
+	Before *string `json:"before,omitempty"`
+	// Value in the right family, or null if removed
+	After *string `json:"after,omitempty"`
+}
+
+// FamilyComparison is the field-by-field and member-by-member comparison of two families.
+// Members are matched by their position in the family.
+type FamilyComparison struct {
+	// The left family
+	Left *Family `json:"left"`
+	// The right family
+	Right *Family `json:"right"`
+	// Whether the families have the same contents, apart from their IDs
+	Identical bool `json:"identical"`
+	// Changes that turn the left family into the right one, in the order of the fields of a family
+	Changes []*FamilyChange `json:"changes"`
+}
+
+// A page of families, in ascending ID order.
+type FamilyConnection struct {
+	// The families of the page, each with its cursor
+	Edges []*FamilyEdge `json:"edges"`
+	// Information for requesting the next page
+	PageInfo *PageInfo `json:"pageInfo"`
+}
+
+// A family in a page of families.
+type FamilyEdge struct {
+	// Opaque cursor of the family, after which the next page may start
+	Cursor string `json:"cursor"`
+	// The family
+	Node *Family `json:"node"`
+}
+
+// FamilyFilterInput selects families by their status and members. Families match when they
+// satisfy every given criterion.
+type FamilyFilterInput struct {
+	// Statuses of which the family has one
+	Status []FamilyStatus `json:"status,omitempty"`
+	// Last name of a parent of the family
+	ParentLastName *string `json:"parentLastName,omitempty"`
+	// Earliest birth date of a child of the family; with childBornOnOrBefore, the same child must be born in the range
+	ChildBornOnOrAfter *entity.Date `json:"childBornOnOrAfter,omitempty"`
+	// Latest birth date of a child of the family; with childBornOnOrAfter, the same child must be born in the range
+	ChildBornOnOrBefore *entity.Date `json:"childBornOnOrBefore,omitempty"`
+	// Minimum number of children of the family
+	MinChildren *int `json:"minChildren,omitempty"`
+	// Maximum number of children of the family
+	MaxChildren *int `json:"maxChildren,omitempty"`
 }
 
 // Input for creating a new family.
@@ -73,6 +281,69 @@ type FamilyInput struct {
 	Parents []*ParentInput `json:"parents"`
 	// List of children in the family (0 or more).
 	Children []*ChildInput `json:"children"`
+	// IDs of the family in external systems, at most one per system.
+	// Each external ID must not be assigned to another family.
+	ExternalIds []*ExternalIDInput `json:"externalIds,omitempty"`
+}
+
+// FamilySortInput is the order of a list of families. Families with equal values of the
+// field are sorted by ascending ID.
+type FamilySortInput struct {
+	// Field to sort by
+	Field FamilySortField `json:"field"`
+	// Direction in which the field is sorted
+	Direction SortDirection `json:"direction"`
+}
+
+// Result of the grantConsent mutation.
+type GrantConsentPayload struct {
+	// The updated family, or null if the consent could not be recorded
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the markChildDeceased mutation.
+type MarkChildDeceasedPayload struct {
+	// The updated family, or null if the child could not be marked as deceased
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the markParentDeceasedV2 mutation.
+type MarkParentDeceasedPayload struct {
+	// The updated family, or null if the parent could not be marked as deceased
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Input for correcting the recorded details of a parent or child. At least one detail is
+// required; details that are not given are kept.
+type MemberDetailsInput struct {
+	// Corrected first name (letters, spaces, and hyphens)
+	FirstName *string `json:"firstName,omitempty"`
+	// Corrected last name (letters, spaces, and hyphens)
+	LastName *string `json:"lastName,omitempty"`
+	// Corrected birth date
+	BirthDate *entity.Date `json:"birthDate,omitempty"`
+}
+
+// Result of the mergeFamilies mutation.
+type MergeFamiliesPayload struct {
+	// The merged family, or null if the families could not be merged
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the moveChild mutation.
+type MoveChildPayload struct {
+	// The family the child moved to, or null if the child could not be moved
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
 }
 
 // Mutations for modifying family data.
@@ -80,6 +351,38 @@ type FamilyInput struct {
 type Mutation struct {
 }
 
+// NameChange is a name of a family member and the date from which it applies.
+type NameChange struct {
+	// First name from the effective date
+	FirstName string `json:"firstName"`
+	// Last name from the effective date
+	LastName string `json:"lastName"`
+	// Date from which the name applies in RFC3339 format (YYYY-MM-DD)
+	EffectiveDate string `json:"effectiveDate"`
+	// Reason for the change (null for the name from birth)
+	Reason *NameChangeReason `json:"reason,omitempty"`
+}
+
+// Input for a name change of a parent or child.
+type NameChangeInput struct {
+	// New first name (letters, spaces, and hyphens)
+	FirstName string `json:"firstName"`
+	// New last name (letters, spaces, and hyphens)
+	LastName string `json:"lastName"`
+	// Date from which the name applies in RFC3339 format (YYYY-MM-DD)
+	EffectiveDate string `json:"effectiveDate"`
+	// Reason for the change (OTHER if omitted)
+	Reason *NameChangeReason `json:"reason,omitempty"`
+}
+
+// Information about a page of a paginated list.
+type PageInfo struct {
+	// Whether more items follow the last item of the page
+	HasNextPage bool `json:"hasNextPage"`
+	// Cursor of the last item of the page, or null if the page is empty
+	EndCursor *string `json:"endCursor,omitempty"`
+}
+
 // Input for creating or adding a parent to a family.
 // Parents must be at least 18 years old.
 type ParentInput struct {
@@ -89,12 +392,46 @@ type ParentInput struct {
 	FirstName string `json:"firstName"`
 	// Last name of the parent (1-50 characters)
 	LastName string `json:"lastName"`
-	// Birth date of the parent in RFC3339 format (YYYY-MM-DD).
+	// Birth date of the parent.
 	// Must be at least 18 years before the current date.
-	BirthDate string `json:"birthDate"`
-	// Death date of the parent in RFC3339 format (YYYY-MM-DD), if applicable.
+	BirthDate entity.Date `json:"birthDate"`
+	// Death date of the parent, if applicable.
 	// Must be after the birth date and not in the future.
-	DeathDate *string `json:"deathDate,omitempty"`
+	DeathDate *entity.Date `json:"deathDate,omitempty"`
+	// IDs of the parent in external systems, at most one per system.
+	// Each external ID must not be assigned to another parent.
+	ExternalIds []*ExternalIDInput `json:"externalIds,omitempty"`
+}
+
+// Result of the purgeDeletedFamilies mutation.
+type PurgeDeletedFamiliesPayload struct {
+	// IDs of the purged families, in ascending order; empty if none were purged
+	PurgedFamilyIds []identification.ID `json:"purgedFamilyIds"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Quarantine records that an administrator froze a family, for example during a fraud
+// investigation. While a family is quarantined, only administrators can read or change it:
+// other callers are refused with a FAMILY_QUARANTINED error, quarantined families are left
+// out of their search results, and every attempt to access the family is audited.
+type Quarantine struct {
+	// ID of the quarantined family
+	FamilyID identification.ID `json:"familyId"`
+	// Why the family was quarantined
+	Reason string `json:"reason"`
+	// ID of the administrator who quarantined the family, or null if unknown
+	QuarantinedBy *identification.ID `json:"quarantinedBy,omitempty"`
+	// When the family was quarantined, in RFC3339 format
+	QuarantinedAt string `json:"quarantinedAt"`
+}
+
+// Result of the quarantineFamily mutation.
+type QuarantineFamilyPayload struct {
+	// The quarantine of the family, or null if the family could not be quarantined
+	Quarantine *Quarantine `json:"quarantine,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
 }
 
 // Queries for retrieving family data.
@@ -102,6 +439,397 @@ type ParentInput struct {
 type Query struct {
 }
 
+// QueryCostEstimate is the estimated cost of a query.
+type QueryCostEstimate struct {
+	// Complexity of the operation: each selected field costs one plus the cost of its selections
+	Complexity int `json:"complexity"`
+	// Maximum complexity the server accepts
+	MaxComplexity int `json:"maxComplexity"`
+	// Depth of the deepest field selection of the operation
+	Depth int `json:"depth"`
+	// Whether the server would accept the operation
+	Accepted bool `json:"accepted"`
+	// Reasons the server would reject the operation, such as parse, validation, or complexity errors
+	Errors []string `json:"errors"`
+}
+
+// Result of the remarry mutation.
+type RemarryPayload struct {
+	// The married family, or null if the family could not remarry
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the removeChildV2 mutation.
+type RemoveChildPayload struct {
+	// The updated family, or null if the child could not be removed
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the restoreFamily mutation.
+type RestoreFamilyPayload struct {
+	// The restored family, or null if it could not be restored
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the setPreferredNameV2 mutation.
+type SetPreferredNamePayload struct {
+	// The updated family, or null if the preferred name could not be set
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the unquarantineFamily mutation.
+type UnquarantineFamilyPayload struct {
+	// The removed quarantine, or null if it could not be removed
+	Quarantine *Quarantine `json:"quarantine,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the updateChild mutation.
+type UpdateChildPayload struct {
+	// The updated family, or null if the child could not be updated
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the updateFamilyV2 mutation.
+type UpdateFamilyPayload struct {
+	// The updated family, or null if it could not be updated
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Result of the updateParent mutation.
+type UpdateParentPayload struct {
+	// The updated family, or null if the parent could not be updated
+	Family *Family `json:"family,omitempty"`
+	// Expected failures that prevented the mutation; empty on success
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// An expected failure of a mutation, such as invalid input or a violated business rule.
+// Unexpected failures, such as an unavailable database, are still reported as GraphQL errors.
+type UserError struct {
+	// Code of the failure
+	Code UserErrorCode `json:"code"`
+	// Human-readable description of the failure
+	Message string `json:"message"`
+	// Path to the input field that caused the failure, if known
+	Field []string `json:"field,omitempty"`
+}
+
+// AgePolicyRule identifies the rule of the parent-child age plausibility policy that was violated.
+type AgePolicyRule string
+
+const (
+	// The parent was born after the child
+	AgePolicyRuleParentYoungerThanChild AgePolicyRule = "PARENT_YOUNGER_THAN_CHILD"
+	// The parent was younger than the configured minimum age when the child was born
+	AgePolicyRuleParentUnderMinimumAge AgePolicyRule = "PARENT_UNDER_MINIMUM_AGE"
+)
+
+var AllAgePolicyRule = []AgePolicyRule{
+	AgePolicyRuleParentYoungerThanChild,
+	AgePolicyRuleParentUnderMinimumAge,
+}
+
+func (e AgePolicyRule) IsValid() bool {
+	switch e {
+	case AgePolicyRuleParentYoungerThanChild, AgePolicyRuleParentUnderMinimumAge:
+		return true
+	}
+	return false
+}
+
+func (e AgePolicyRule) String() string {
+	return string(e)
+}
+
+func (e *AgePolicyRule) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AgePolicyRule(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AgePolicyRule", str)
+	}
+	return nil
+}
+
+func (e AgePolicyRule) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AgePolicyRule) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AgePolicyRule) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// ConsentScope identifies the data of a child covered by a consent.
+type ConsentScope string
+
+const (
+	// IDs of the child in external systems, such as school or health records
+	ConsentScopeExternalIDS ConsentScope = "EXTERNAL_IDS"
+	// Preferred name and name history of the child
+	ConsentScopeNames ConsentScope = "NAMES"
+)
+
+var AllConsentScope = []ConsentScope{
+	ConsentScopeExternalIDS,
+	ConsentScopeNames,
+}
+
+func (e ConsentScope) IsValid() bool {
+	switch e {
+	case ConsentScopeExternalIDS, ConsentScopeNames:
+		return true
+	}
+	return false
+}
+
+func (e ConsentScope) String() string {
+	return string(e)
+}
+
+func (e *ConsentScope) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ConsentScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ConsentScope", str)
+	}
+	return nil
+}
+
+func (e ConsentScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ConsentScope) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ConsentScope) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// ExternalIdOwner identifies the kind of entity that holds an external ID.
+type ExternalIDOwner string
+
+const (
+	// External ID of a family
+	ExternalIDOwnerFamily ExternalIDOwner = "FAMILY"
+	// External ID of a parent
+	ExternalIDOwnerParent ExternalIDOwner = "PARENT"
+	// External ID of a child
+	ExternalIDOwnerChild ExternalIDOwner = "CHILD"
+)
+
+var AllExternalIDOwner = []ExternalIDOwner{
+	ExternalIDOwnerFamily,
+	ExternalIDOwnerParent,
+	ExternalIDOwnerChild,
+}
+
+func (e ExternalIDOwner) IsValid() bool {
+	switch e {
+	case ExternalIDOwnerFamily, ExternalIDOwnerParent, ExternalIDOwnerChild:
+		return true
+	}
+	return false
+}
+
+func (e ExternalIDOwner) String() string {
+	return string(e)
+}
+
+func (e *ExternalIDOwner) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExternalIDOwner(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExternalIdOwner", str)
+	}
+	return nil
+}
+
+func (e ExternalIDOwner) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ExternalIDOwner) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ExternalIDOwner) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// FamilyChangeKind is the kind of a difference between two families.
+type FamilyChangeKind string
+
+const (
+	// The value differs between the families
+	FamilyChangeKindChanged FamilyChangeKind = "CHANGED"
+	// Only the right family has the value, such as a member
+	FamilyChangeKindAdded FamilyChangeKind = "ADDED"
+	// Only the left family has the value, such as a member
+	FamilyChangeKindRemoved FamilyChangeKind = "REMOVED"
+)
+
+var AllFamilyChangeKind = []FamilyChangeKind{
+	FamilyChangeKindChanged,
+	FamilyChangeKindAdded,
+	FamilyChangeKindRemoved,
+}
+
+func (e FamilyChangeKind) IsValid() bool {
+	switch e {
+	case FamilyChangeKindChanged, FamilyChangeKindAdded, FamilyChangeKindRemoved:
+		return true
+	}
+	return false
+}
+
+func (e FamilyChangeKind) String() string {
+	return string(e)
+}
+
+func (e *FamilyChangeKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FamilyChangeKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FamilyChangeKind", str)
+	}
+	return nil
+}
+
+func (e FamilyChangeKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *FamilyChangeKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e FamilyChangeKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// FamilySortField is a field that families can be sorted by.
+type FamilySortField string
+
+const (
+	// The ID of the family
+	FamilySortFieldID FamilySortField = "ID"
+	// The status of the family
+	FamilySortFieldStatus FamilySortField = "STATUS"
+	// The number of parents of the family
+	FamilySortFieldParentCount FamilySortField = "PARENT_COUNT"
+	// The number of children of the family
+	FamilySortFieldChildrenCount FamilySortField = "CHILDREN_COUNT"
+)
+
+var AllFamilySortField = []FamilySortField{
+	FamilySortFieldID,
+	FamilySortFieldStatus,
+	FamilySortFieldParentCount,
+	FamilySortFieldChildrenCount,
+}
+
+func (e FamilySortField) IsValid() bool {
+	switch e {
+	case FamilySortFieldID, FamilySortFieldStatus, FamilySortFieldParentCount, FamilySortFieldChildrenCount:
+		return true
+	}
+	return false
+}
+
+func (e FamilySortField) String() string {
+	return string(e)
+}
+
+func (e *FamilySortField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FamilySortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FamilySortField", str)
+	}
+	return nil
+}
+
+func (e FamilySortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *FamilySortField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e FamilySortField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 // FamilyStatus represents the current status of a family.
 // The status affects what operations can be performed on the family.
 type FamilyStatus string
@@ -173,6 +901,70 @@ func (e FamilyStatus) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// NameChangeReason classifies why the name of a family member changed.
+type NameChangeReason string
+
+const (
+	// Name taken on marriage
+	NameChangeReasonMarriage NameChangeReason = "MARRIAGE"
+	// Name taken on divorce, usually a previous name
+	NameChangeReasonDivorce NameChangeReason = "DIVORCE"
+	// Name changed by legal process, such as a deed poll or court order
+	NameChangeReasonLegal NameChangeReason = "LEGAL"
+	// Name changed for any other reason
+	NameChangeReasonOther NameChangeReason = "OTHER"
+)
+
+var AllNameChangeReason = []NameChangeReason{
+	NameChangeReasonMarriage,
+	NameChangeReasonDivorce,
+	NameChangeReasonLegal,
+	NameChangeReasonOther,
+}
+
+func (e NameChangeReason) IsValid() bool {
+	switch e {
+	case NameChangeReasonMarriage, NameChangeReasonDivorce, NameChangeReasonLegal, NameChangeReasonOther:
+		return true
+	}
+	return false
+}
+
+func (e NameChangeReason) String() string {
+	return string(e)
+}
+
+func (e *NameChangeReason) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NameChangeReason(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NameChangeReason", str)
+	}
+	return nil
+}
+
+func (e NameChangeReason) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NameChangeReason) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NameChangeReason) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 // Resource represents the resource type being accessed.
 // Different resources may have different access controls.
 type Resource string
@@ -361,3 +1153,161 @@ func (e Scope) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+// SortDirection is the direction in which a list is sorted.
+type SortDirection string
+
+const (
+	// Smallest value first
+	SortDirectionAsc SortDirection = "ASC"
+	// Largest value first
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SortDirection) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SortDirection) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// Code of an expected failure of a mutation.
+type UserErrorCode string
+
+const (
+	// The input is invalid or violates a business rule
+	UserErrorCodeValidationError UserErrorCode = "VALIDATION_ERROR"
+	// The family, parent, or child does not exist
+	UserErrorCodeNotFound UserErrorCode = "NOT_FOUND"
+	// The family would have more than two parents
+	UserErrorCodeFamilyTooManyParents UserErrorCode = "FAMILY_TOO_MANY_PARENTS"
+	// The parent is already in the family
+	UserErrorCodeFamilyParentExists UserErrorCode = "FAMILY_PARENT_EXISTS"
+	// A parent with the same name and birth date is already in the family
+	UserErrorCodeFamilyParentDuplicate UserErrorCode = "FAMILY_PARENT_DUPLICATE"
+	// The child is already in the family
+	UserErrorCodeFamilyChildExists UserErrorCode = "FAMILY_CHILD_EXISTS"
+	// The only parent of a family cannot be removed
+	UserErrorCodeFamilyCannotRemoveLastParent UserErrorCode = "FAMILY_CANNOT_REMOVE_LAST_PARENT"
+	// The operation requires a married family
+	UserErrorCodeFamilyNotMarried UserErrorCode = "FAMILY_NOT_MARRIED"
+	// A divorce requires a family with two parents
+	UserErrorCodeFamilyDivorceRequiresTwoParents UserErrorCode = "FAMILY_DIVORCE_REQUIRES_TWO_PARENTS"
+	// The status of the family could not be updated
+	UserErrorCodeFamilyStatusUpdateFailed UserErrorCode = "FAMILY_STATUS_UPDATE_FAILED"
+	// The family cannot change to the requested status
+	UserErrorCodeFamilyInvalidStatusTransition UserErrorCode = "FAMILY_INVALID_STATUS_TRANSITION"
+	// The parent is already marked as deceased
+	UserErrorCodeParentAlreadyDeceased UserErrorCode = "PARENT_ALREADY_DECEASED"
+	// The child is already marked as deceased
+	UserErrorCodeChildAlreadyDeceased UserErrorCode = "CHILD_ALREADY_DECEASED"
+	// The family is quarantined and can only be read or changed by administrators
+	UserErrorCodeFamilyQuarantined UserErrorCode = "FAMILY_QUARANTINED"
+	// A stored family has the same members, and the duplicate policy rejects duplicates
+	UserErrorCodeFamilyDuplicate UserErrorCode = "FAMILY_DUPLICATE"
+	// The mutation did not complete within the mutation timeout; none of its changes were applied
+	UserErrorCodeMutationTimedOut UserErrorCode = "MUTATION_TIMED_OUT"
+)
+
+var AllUserErrorCode = []UserErrorCode{
+	UserErrorCodeValidationError,
+	UserErrorCodeNotFound,
+	UserErrorCodeFamilyTooManyParents,
+	UserErrorCodeFamilyParentExists,
+	UserErrorCodeFamilyParentDuplicate,
+	UserErrorCodeFamilyChildExists,
+	UserErrorCodeFamilyCannotRemoveLastParent,
+	UserErrorCodeFamilyNotMarried,
+	UserErrorCodeFamilyDivorceRequiresTwoParents,
+	UserErrorCodeFamilyStatusUpdateFailed,
+	UserErrorCodeFamilyInvalidStatusTransition,
+	UserErrorCodeParentAlreadyDeceased,
+	UserErrorCodeChildAlreadyDeceased,
+	UserErrorCodeFamilyQuarantined,
+	UserErrorCodeFamilyDuplicate,
+	UserErrorCodeMutationTimedOut,
+}
+
+func (e UserErrorCode) IsValid() bool {
+	switch e {
+	case UserErrorCodeValidationError, UserErrorCodeNotFound, UserErrorCodeFamilyTooManyParents, UserErrorCodeFamilyParentExists, UserErrorCodeFamilyParentDuplicate, UserErrorCodeFamilyChildExists, UserErrorCodeFamilyCannotRemoveLastParent, UserErrorCodeFamilyNotMarried, UserErrorCodeFamilyDivorceRequiresTwoParents, UserErrorCodeFamilyStatusUpdateFailed, UserErrorCodeFamilyInvalidStatusTransition, UserErrorCodeParentAlreadyDeceased, UserErrorCodeChildAlreadyDeceased, UserErrorCodeFamilyQuarantined, UserErrorCodeFamilyDuplicate, UserErrorCodeMutationTimedOut:
+		return true
+	}
+	return false
+}
+
+func (e UserErrorCode) String() string {
+	return string(e)
+}
+
+func (e *UserErrorCode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserErrorCode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserErrorCode", str)
+	}
+	return nil
+}
+
+func (e UserErrorCode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *UserErrorCode) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e UserErrorCode) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package quarantine enforces the quarantine of families in the GraphQL API.
+//
+// Administrators quarantine families, for example during a fraud investigation, and the
+// application services then refuse access to a quarantined family to anyone but an
+// administrator. This extension records the authenticated caller of each operation, so
+// that the application services can tell administrators apart, and reports refused
+// accesses with the FAMILY_QUARANTINED error code, so that clients can tell a frozen
+// family apart from a failure.
+package quarantine
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/core/application/access"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CodeFamilyQuarantined is the error code of an access to a quarantined family that was refused
+const CodeFamilyQuarantined = domainerrors.FamilyQuarantinedCode
+
+// AdminRole is the role that may access quarantined families
+const AdminRole = "ADMIN"
+
+// Extension is a gqlgen handler extension that records the caller of each operation and
+// reports refused accesses to quarantined families with their own error code
+type Extension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+	graphql.FieldInterceptor
+} = Extension{}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "FamilyQuarantine"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation records the authenticated caller of the operation
+func (Extension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	return next(WithCaller(ctx))
+}
+
+// WithCaller records the caller identified by the auth middleware for the application
+// services. It is also used by the adapters that call the services outside of GraphQL.
+func WithCaller(ctx context.Context) context.Context {
+	userID, _ := middleware.GetUserID(ctx)
+	roles, _ := middleware.GetUserRoles(ctx)
+
+	caller := access.Caller{ID: userID}
+	for _, role := range roles {
+		if strings.EqualFold(role, AdminRole) {
+			caller.Admin = userID != ""
+		}
+	}
+	return access.WithCaller(ctx, caller)
+}
+
+// InterceptField reports a refused access to a quarantined family with the
+// FAMILY_QUARANTINED error code
+func (Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	res, err := next(ctx)
+	if err == nil || !errors.Is(err, domainerrors.ErrFamilyQuarantined) {
+		return res, err
+	}
+
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		return res, err
+	}
+	quarantined := &gqlerror.Error{
+		Message:    domainerrors.GetErrorMessage(err),
+		Extensions: map[string]interface{}{"code": CodeFamilyQuarantined},
+	}
+	if fc := graphql.GetFieldContext(ctx); fc != nil {
+		quarantined.Path = fc.Path()
+	}
+	return res, quarantined
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package quarantine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/core/application/access"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestExtension_InterceptOperation(t *testing.T) {
+	callerOf := func(ctx context.Context) access.Caller {
+		var caller access.Caller
+		Extension{}.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+			caller = access.CallerFrom(ctx)
+			return graphql.OneShot(&graphql.Response{})
+		})
+		return caller
+	}
+
+	admin := middleware.WithUserRoles(middleware.WithUserID(context.Background(), "admin-1"), []string{"admin"})
+	assert.Equal(t, access.Caller{ID: "admin-1", Admin: true}, callerOf(admin))
+
+	editor := middleware.WithUserRoles(middleware.WithUserID(context.Background(), "editor-1"), []string{"EDITOR"})
+	assert.Equal(t, access.Caller{ID: "editor-1"}, callerOf(editor))
+
+	// A role without an authenticated user is not trusted
+	assert.False(t, callerOf(middleware.WithUserRoles(context.Background(), []string{AdminRole})).Admin)
+}
+
+func TestExtension_InterceptField(t *testing.T) {
+	ext := Extension{}
+
+	_, err := ext.InterceptField(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, fmt.Errorf("failed to get family: %w", domainerrors.NewFamilyQuarantinedError("family family-1 is quarantined", nil))
+	})
+	var gqlErr *gqlerror.Error
+	require.ErrorAs(t, err, &gqlErr)
+	assert.Equal(t, CodeFamilyQuarantined, gqlErr.Extensions["code"])
+	assert.Equal(t, "family family-1 is quarantined", gqlErr.Message)
+
+	// Other errors and results are not changed
+	failure := errors.New("database unavailable")
+	_, err = ext.InterceptField(context.Background(), func(ctx context.Context) (any, error) { return nil, failure })
+	assert.Equal(t, failure, err)
+
+	res, err := ext.InterceptField(context.Background(), func(ctx context.Context) (any, error) { return "resolved", nil })
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", res)
+}
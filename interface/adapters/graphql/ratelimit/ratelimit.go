@@ -0,0 +1,151 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package ratelimit applies per-subject rate limits to the GraphQL API and advises
+// clients of their remaining allowance before they are rejected.
+//
+// Every response carries the standard X-RateLimit-Limit, X-RateLimit-Remaining, and
+// X-RateLimit-Reset headers. GraphQL responses also include the same state under the
+// "rateLimit" response extension, with a warning once the allowance runs low, so
+// SDKs can back off proactively. Requests from subjects with no remaining allowance
+// are rejected with 429 Too Many Requests and a Retry-After header.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	rate "github.com/abitofhelp/family-service/infrastructure/adapters/ratewrapper"
+	"github.com/abitofhelp/servicelib/auth"
+)
+
+// ExtensionKey is the GraphQL response extension that carries the rate limit state
+const ExtensionKey = "rateLimit"
+
+// warningMessage is included in the response extension when the allowance is running low
+const warningMessage = "Rate limit nearly exhausted; reduce request rate to avoid rejection"
+
+// stateKey is the context key for the subject's rate limit state
+type stateKey struct{}
+
+// Subject returns the rate limit subject for a request: the authenticated user when
+// the auth middleware has identified one, otherwise the client IP address.
+func Subject(r *http.Request) string {
+	if userID, ok := auth.GetUserIDFromContext(r.Context()); ok && userID != "" {
+		return "user:" + userID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// Middleware returns HTTP middleware that applies the per-subject limiter to each request.
+// It must run inside the auth middleware so that authenticated users are limited by identity.
+// A nil limiter disables rate limiting.
+func Middleware(limiter *rate.SubjectLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state := limiter.Take(Subject(r))
+			setHeaders(w.Header(), state)
+
+			if !state.Allowed {
+				reject(w, state)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), stateKey{}, state)))
+		})
+	}
+}
+
+// StateFromContext returns the rate limit state recorded for the current request
+func StateFromContext(ctx context.Context) (rate.State, bool) {
+	state, ok := ctx.Value(stateKey{}).(rate.State)
+	return state, ok
+}
+
+// setHeaders writes the standard rate limit headers
+func setHeaders(h http.Header, state rate.State) {
+	h.Set("X-RateLimit-Limit", strconv.Itoa(state.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(state.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(state.Reset.Unix(), 10))
+}
+
+// reject writes a GraphQL-shaped 429 response for a subject that exceeded its limit
+func reject(w http.ResponseWriter, state rate.State) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(state.RetryAfter.Seconds()))))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []map[string]interface{}{{
+			"message": "Rate limit exceeded",
+			"extensions": map[string]interface{}{
+				"code":       "RATE_LIMITED",
+				ExtensionKey: extensionValue(state),
+			},
+		}},
+	})
+}
+
+// extensionValue returns the response extension representation of the rate limit state
+func extensionValue(state rate.State) map[string]interface{} {
+	value := map[string]interface{}{
+		"limit":     state.Limit,
+		"remaining": state.Remaining,
+		"reset":     state.Reset.UTC().Format(time.RFC3339),
+	}
+	if state.RetryAfter > 0 {
+		value["retryAfterSeconds"] = int(math.Ceil(state.RetryAfter.Seconds()))
+	}
+	if state.Warning {
+		value["warning"] = warningMessage
+	}
+	return value
+}
+
+// Extension is a gqlgen handler extension that adds the rate limit state recorded by
+// Middleware to each GraphQL response.
+type Extension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = Extension{}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "RateLimitAdvisory"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse adds the rate limit state to the response extensions
+func (Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	state, ok := StateFromContext(ctx)
+	if resp == nil || !ok {
+		return resp
+	}
+
+	if resp.Extensions == nil {
+		resp.Extensions = make(map[string]interface{})
+	}
+	resp.Extensions[ExtensionKey] = extensionValue(state)
+	return resp
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	rate "github.com/abitofhelp/family-service/infrastructure/adapters/ratewrapper"
+	"github.com/abitofhelp/servicelib/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSubject(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	req.RemoteAddr = "10.0.0.1:54321"
+	assert.Equal(t, "ip:10.0.0.1", Subject(req))
+
+	req = req.WithContext(auth.WithUserID(req.Context(), "alice"))
+	assert.Equal(t, "user:alice", Subject(req))
+}
+
+func TestMiddleware(t *testing.T) {
+	limiter := rate.NewSubjectLimiter("test", &config.HTTPRateConfig{
+		Enabled:           true,
+		RequestsPerSecond: 1,
+		BurstSize:         2,
+		WarnThreshold:     0.5,
+	}, zaptest.NewLogger(t))
+
+	var seen rate.State
+	handler := Middleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = StateFromContext(r.Context())
+	}))
+
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", nil))
+		return rec
+	}
+
+	rec := serve()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", rec.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, rec.Header().Get("X-RateLimit-Reset"))
+	assert.True(t, seen.Warning)
+
+	serve()
+	rec = serve()
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), `"code":"RATE_LIMITED"`)
+}
+
+func TestMiddleware_Disabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	rec := httptest.NewRecorder()
+	Middleware(nil)(next).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("X-RateLimit-Limit"))
+}
+
+func TestExtension_InterceptResponse(t *testing.T) {
+	state := rate.State{Limit: 10, Remaining: 1, Allowed: true, Warning: true}
+	ctx := context.WithValue(context.Background(), stateKey{}, state)
+
+	resp := Extension{}.InterceptResponse(ctx, func(ctx context.Context) *graphql.Response {
+		return &graphql.Response{}
+	})
+
+	require.Contains(t, resp.Extensions, ExtensionKey)
+	value := resp.Extensions[ExtensionKey].(map[string]interface{})
+	assert.Equal(t, 10, value["limit"])
+	assert.Equal(t, 1, value["remaining"])
+	assert.Equal(t, warningMessage, value["warning"])
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package redaction redacts fields of GraphQL responses by the roles of the caller.
+//
+// The @isAuthorized directive decides whether a caller may run an operation at all;
+// redaction decides which of the fields it returns the caller may see. Compliance manages
+// the rules centrally, in configuration, as the fields redacted for each role, named by
+// their schema coordinates such as "Parent.deathDate". A rule for an interface field, such
+// as "Person.deathDate", applies to the field of every type that implements it.
+//
+// Fields are redacted after they are resolved: a nullable field resolves to null, and a
+// non-null String field to the Mask. Other non-null fields cannot be redacted, since null
+// would fail the whole object. A field is redacted only if every role of the caller redacts
+// it, so a caller with an additional role that may see the field sees it. The response of
+// an operation lists its redacted fields in the "redactions" response extension, so that
+// clients can tell a redacted value apart from a missing one.
+package redaction
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// ExtensionKey is the GraphQL response extension that lists the redacted fields
+const ExtensionKey = "redactions"
+
+// Mask is the value of a redacted non-null String field
+const Mask = "[REDACTED]"
+
+// Redaction is a field that was redacted from a response
+type Redaction struct {
+	Path  ast.Path `json:"path"`  // Path of the field in the response
+	Field string   `json:"field"` // Schema coordinate of the field, such as "Parent.deathDate"
+}
+
+// Extension is a gqlgen handler extension that redacts fields by the roles of the caller
+// and lists the redacted fields in the response extensions
+type Extension struct {
+	// fields holds the fields redacted for each role, in upper case, by the coordinates of
+	// the fields of object types, with the mask of each field (nil for null)
+	fields map[string]map[string]any
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = &Extension{}
+
+// NewExtension creates an extension that redacts fields by the roles of the caller
+//
+// Parameters:
+//   - schema: The schema the fields are looked up in
+//   - rules: The coordinates of the redacted fields, such as "Parent.deathDate", by role
+//
+// Returns:
+//   - A new extension
+//   - An error if a rule names an unknown field or a non-null field that is not a String
+func NewExtension(schema *ast.Schema, rules map[string][]string) (*Extension, error) {
+	e := &Extension{fields: make(map[string]map[string]any, len(rules))}
+	for role, coordinates := range rules {
+		fields := make(map[string]any)
+		for _, coordinate := range coordinates {
+			if err := resolve(schema, coordinate, fields); err != nil {
+				return nil, fmt.Errorf("invalid redaction rule of role %s: %w", role, err)
+			}
+		}
+		e.fields[strings.ToUpper(role)] = fields
+	}
+	return e, nil
+}
+
+// resolve adds the fields of object types that a coordinate names, with their masks
+func resolve(schema *ast.Schema, coordinate string, fields map[string]any) error {
+	typeName, fieldName, ok := strings.Cut(coordinate, ".")
+	def := schema.Types[typeName]
+	if !ok || def == nil || def.Fields.ForName(fieldName) == nil {
+		return fmt.Errorf("unknown field %q", coordinate)
+	}
+
+	types := []*ast.Definition{def}
+	if def.IsAbstractType() {
+		types = schema.GetPossibleTypes(def)
+	}
+	for _, t := range types {
+		field := t.Fields.ForName(fieldName)
+		if field == nil {
+			continue
+		}
+		var mask any
+		if field.Type.NonNull {
+			if field.Type.NamedType != "String" {
+				return fmt.Errorf("field %s.%s is a non-null %s, which cannot be redacted", t.Name, fieldName, field.Type.Name())
+			}
+			mask = Mask
+		}
+		fields[t.Name+"."+fieldName] = mask
+	}
+	return nil
+}
+
+// ExtensionName returns the name of the extension
+func (*Extension) ExtensionName() string {
+	return "Redaction"
+}
+
+// Validate validates the extension against the schema
+func (*Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// recorder collects the fields redacted from a response, which resolve concurrently
+type recorder struct {
+	mu         sync.Mutex
+	redactions []Redaction
+}
+
+// recorderKey is the key of the recorder of a response in its context
+type recorderKey struct{}
+
+// InterceptResponse lists the fields redacted from the response in its extensions
+func (e *Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	rec := &recorder{}
+	resp := next(context.WithValue(ctx, recorderKey{}, rec))
+
+	rec.mu.Lock()
+	redactions := rec.redactions
+	rec.redactions = nil
+	rec.mu.Unlock()
+	if resp == nil || len(redactions) == 0 {
+		return resp
+	}
+
+	sort.Slice(redactions, func(i, j int) bool {
+		return redactions[i].Path.String() < redactions[j].Path.String()
+	})
+	if resp.Extensions == nil {
+		resp.Extensions = make(map[string]interface{})
+	}
+	resp.Extensions[ExtensionKey] = redactions
+	return resp
+}
+
+// InterceptField redacts a resolved field that every role of the caller redacts
+func (e *Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	res, err := next(ctx)
+	fc := graphql.GetFieldContext(ctx)
+	if err != nil || fc == nil || fc.Field.Field == nil {
+		return res, err
+	}
+
+	coordinate := fc.Object + "." + fc.Field.Name
+	mask, redacted := e.redact(ctx, coordinate)
+	if !redacted {
+		return res, nil
+	}
+	if rec, ok := ctx.Value(recorderKey{}).(*recorder); ok {
+		rec.mu.Lock()
+		rec.redactions = append(rec.redactions, Redaction{Path: fc.Path(), Field: coordinate})
+		rec.mu.Unlock()
+	}
+	return mask, nil
+}
+
+// redact returns the mask of a field and whether every role of the caller redacts it
+func (e *Extension) redact(ctx context.Context, coordinate string) (any, bool) {
+	roles, _ := middleware.GetUserRoles(ctx)
+	if len(roles) == 0 {
+		return nil, false
+	}
+	var mask any
+	for _, role := range roles {
+		m, ok := e.fields[strings.ToUpper(role)][coordinate]
+		if !ok {
+			return nil, false
+		}
+		mask = m
+	}
+	return mask, true
+}
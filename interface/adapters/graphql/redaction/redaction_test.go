@@ -0,0 +1,91 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package redaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var schema = gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphql", Input: `
+type Query { family: Family }
+type Family { id: ID! parents: [Parent!]! }
+interface Person { firstName: String! deathDate: String }
+type Parent implements Person { firstName: String! lastName: String! deathDate: String birthDate: Int! }
+type Child implements Person { firstName: String! deathDate: String }
+`})
+
+// fieldContext returns the context of a field of the first parent in the family query
+func fieldContext(ctx context.Context, name string) context.Context {
+	field := func(object, name string) *graphql.FieldContext {
+		return &graphql.FieldContext{Object: object, Field: graphql.CollectedField{Field: &ast.Field{Name: name, Alias: name}}}
+	}
+	index := 0
+	ctx = graphql.WithFieldContext(ctx, field("Query", "family"))
+	ctx = graphql.WithFieldContext(ctx, field("Family", "parents"))
+	ctx = graphql.WithFieldContext(ctx, &graphql.FieldContext{Index: &index})
+	return graphql.WithFieldContext(ctx, field("Parent", name))
+}
+
+func TestNewExtension(t *testing.T) {
+	_, err := NewExtension(schema, map[string][]string{"VIEWER": {"Person.deathDate", "Parent.lastName"}})
+	require.NoError(t, err)
+
+	_, err = NewExtension(schema, map[string][]string{"VIEWER": {"Parent.middleName"}})
+	assert.ErrorContains(t, err, `unknown field "Parent.middleName"`)
+	_, err = NewExtension(schema, map[string][]string{"VIEWER": {"deathDate"}})
+	assert.ErrorContains(t, err, `unknown field "deathDate"`)
+	_, err = NewExtension(schema, map[string][]string{"VIEWER": {"Parent.birthDate"}})
+	assert.ErrorContains(t, err, "non-null Int, which cannot be redacted")
+}
+
+func TestExtension(t *testing.T) {
+	ext, err := NewExtension(schema, map[string][]string{
+		"viewer": {"Person.deathDate", "Parent.lastName"},
+		"EDITOR": {"Parent.lastName"},
+	})
+	require.NoError(t, err)
+
+	// run resolves the fields of a parent for a caller with roles, as one response
+	run := func(roles []string, fields map[string]any) (map[string]any, map[string]interface{}) {
+		ctx := middleware.WithUserRoles(context.Background(), roles)
+		results := make(map[string]any)
+		resp := ext.InterceptResponse(ctx, func(ctx context.Context) *graphql.Response {
+			for name, value := range fields {
+				res, err := ext.InterceptField(fieldContext(ctx, name), func(ctx context.Context) (any, error) { return value, nil })
+				require.NoError(t, err)
+				results[name] = res
+			}
+			return &graphql.Response{}
+		})
+		return results, resp.Extensions
+	}
+
+	fields := map[string]any{"firstName": "John", "lastName": "Doe", "deathDate": "2020-01-01"}
+
+	// Nullable fields resolve to null, and non-null strings to the mask
+	results, extensions := run([]string{"VIEWER"}, fields)
+	assert.Equal(t, map[string]any{"firstName": "John", "lastName": Mask, "deathDate": nil}, results)
+	assert.Equal(t, []Redaction{
+		{Path: ast.Path{ast.PathName("family"), ast.PathName("parents"), ast.PathIndex(0), ast.PathName("deathDate")}, Field: "Parent.deathDate"},
+		{Path: ast.Path{ast.PathName("family"), ast.PathName("parents"), ast.PathIndex(0), ast.PathName("lastName")}, Field: "Parent.lastName"},
+	}, extensions[ExtensionKey])
+
+	// A field is redacted only if every role of the caller redacts it
+	results, _ = run([]string{"EDITOR", "VIEWER"}, fields)
+	assert.Equal(t, map[string]any{"firstName": "John", "lastName": Mask, "deathDate": "2020-01-01"}, results)
+
+	// Roles without rules see every field, and responses without redactions are not annotated
+	results, extensions = run([]string{"ADMIN"}, fields)
+	assert.Equal(t, fields, results)
+	assert.Nil(t, extensions)
+	results, _ = run([]string{"VIEWER", "ADMIN"}, fields)
+	assert.Equal(t, fields, results)
+}
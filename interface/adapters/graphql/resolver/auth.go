@@ -6,22 +6,43 @@
 	"context"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/authz"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
 )
 
 // IsAuthorized is a directive middleware for role-based access control.
+//
+// The caller must hold one of the allowed roles and the fine-grained scope that the
+// authorization table maps to the field, whether it is an operation or a field of another
+// type. The coarse scopes and resource of the directive
+// are accepted in place of the fine-grained scope while coarse scopes are enabled.
 func (r *Resolver) IsAuthorized(ctx context.Context, obj any, next graphql.Resolver, allowedRoles []model.Role, requiredScopes []model.Scope, resource *model.Resource) (res any, err error) {
-	// TODO: Implement proper authorization check
-	// This is a placeholder implementation that allows all requests
-	// In a real implementation, this would check the user's roles and permissions
+	req := authz.Requirement{
+		AllowedRoles: make([]string, 0, len(allowedRoles)),
+		CoarseScopes: make([]string, 0, len(requiredScopes)),
+	}
+	if fc := graphql.GetFieldContext(ctx); fc != nil {
+		req.Operation = authz.OperationName(fc.Object, fc.Field.Name)
+	}
+	for _, role := range allowedRoles {
+		req.AllowedRoles = append(req.AllowedRoles, role.String())
+	}
+	for _, scope := range requiredScopes {
+		req.CoarseScopes = append(req.CoarseScopes, scope.String())
+	}
+	if resource != nil {
+		req.Resource = resource.String()
+	}
+
+	if err := r.authorizer.Authorize(ctx, req); err != nil {
+		return nil, err
+	}
 	return next(ctx)
 }
 
 // checkAuthorization is a helper function for role-based access control.
-// It checks if the user has the required roles and permissions for a specific resource.
+// Access is enforced by the IsAuthorized directive before a resolver runs, so this
+// helper allows all requests.
 func checkAuthorization(ctx context.Context, allowedRoles []string, requiredScopes []string, resource string) error {
-	// TODO: Implement proper authorization check
-	// This is a placeholder implementation that allows all requests
-	// In a real implementation, this would check the user's roles and permissions
 	return nil
 }
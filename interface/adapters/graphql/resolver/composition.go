@@ -0,0 +1,85 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package resolver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
+)
+
+// defaultMajorityAge is the age of majority until the resolver is given one
+const defaultMajorityAge = 18
+
+// compositionCacheSize is the number of family compositions the resolver keeps
+const compositionCacheSize = 10000
+
+// compositionCache keeps the compositions of families by the checksum of the family, which
+// changes whenever the family changes, and the date of the count of dependents. The
+// composition fields of a family are computed once per version of the family and day,
+// however many of them, and however many queries, select them.
+type compositionCache struct {
+	mu      sync.Mutex
+	entries map[string]entity.Composition
+}
+
+// get returns the cached composition of a key, or computes and caches it. The cache is
+// emptied when it is full, which only costs recomputing the compositions of recent families.
+func (c *compositionCache) get(key string, compute func() entity.Composition) entity.Composition {
+	c.mu.Lock()
+	composition, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return composition
+	}
+
+	composition = compute()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil || len(c.entries) >= compositionCacheSize {
+		c.entries = make(map[string]entity.Composition)
+	}
+	c.entries[key] = composition
+	return composition
+}
+
+// composition returns the composition of a family at the time of the request
+func (r *Resolver) composition(ctx context.Context, obj *model.Family) entity.Composition {
+	at := clock.Now(ctx)
+	compute := func() entity.Composition {
+		return compositionOf(obj).Composition(at, r.majorityAge)
+	}
+	if obj.Checksum == "" {
+		return compute()
+	}
+	return r.compositions.get(obj.ID.String()+"/"+obj.Checksum+"/"+at.Format("2006-01-02"), compute)
+}
+
+// compositionOf returns the birth and death dates of the members of a family, which are all
+// that its composition is computed from
+func compositionOf(obj *model.Family) entity.FamilyDTO {
+	family := entity.FamilyDTO{
+		Parents:  make([]entity.ParentDTO, 0, len(obj.Parents)),
+		Children: make([]entity.ChildDTO, 0, len(obj.Children)),
+	}
+	for _, p := range obj.Parents {
+		family.Parents = append(family.Parents, entity.ParentDTO{BirthDate: p.BirthDate.Time(), DeathDate: timeOf(p.DeathDate)})
+	}
+	for _, c := range obj.Children {
+		family.Children = append(family.Children, entity.ChildDTO{BirthDate: c.BirthDate.Time(), DeathDate: timeOf(c.DeathDate)})
+	}
+	return family
+}
+
+// timeOf returns the time of a date, or nil if there is no date
+func timeOf(date *entity.Date) *time.Time {
+	if date == nil {
+		return nil
+	}
+	t := date.Time()
+	return &t
+}
@@ -16,4 +16,18 @@ func (r *familyResolver) ParentCount(ctx context.Context, obj *model.Family) (in
 // ChildrenCount is the resolver for the childrenCount field.
 func (r *familyResolver) ChildrenCount(ctx context.Context, obj *model.Family) (int, error) {
 	return len(obj.Children), nil
-}
\ No newline at end of file
+}
+// NumberOfDependents is the resolver for the numberOfDependents field.
+func (r *familyResolver) NumberOfDependents(ctx context.Context, obj *model.Family) (int, error) {
+	return r.composition(ctx, obj).NumberOfDependents, nil
+}
+
+// HasDeceasedMembers is the resolver for the hasDeceasedMembers field.
+func (r *familyResolver) HasDeceasedMembers(ctx context.Context, obj *model.Family) (bool, error) {
+	return r.composition(ctx, obj).HasDeceasedMembers, nil
+}
+
+// GenerationSpanYears is the resolver for the generationSpanYears field.
+func (r *familyResolver) GenerationSpanYears(ctx context.Context, obj *model.Family) (int, error) {
+	return r.composition(ctx, obj).GenerationSpanYears, nil
+}
@@ -6,7 +6,10 @@
 	"context"
 	"time"
 
+	"github.com/abitofhelp/family-service/core/application/ports"
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/policy"
+	"github.com/abitofhelp/family-service/core/domain/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -31,6 +34,29 @@ func (m *MockFamilyService) GetFamily(ctx context.Context, id string) (*entity.F
 	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
 }
 
+func (m *MockFamilyService) GetFamilies(ctx context.Context, ids []string) ([]*entity.FamilyDTO, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) CountFamilies(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockFamilyService) CountParents(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockFamilyService) CountChildren(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockFamilyService) GetAllFamilies(ctx context.Context) ([]*entity.FamilyDTO, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -39,6 +65,38 @@ func (m *MockFamilyService) GetAllFamilies(ctx context.Context) ([]*entity.Famil
 	return args.Get(0).([]*entity.FamilyDTO), args.Error(1)
 }
 
+func (m *MockFamilyService) FindFamilies(ctx context.Context, filter query.Filter, order query.Order) ([]*entity.FamilyDTO, error) {
+	args := m.Called(ctx, filter, order)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) SearchFamilies(ctx context.Context, search, after string, first int) ([]*entity.FamilyDTO, error) {
+	args := m.Called(ctx, search, after, first)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) ListFamilies(ctx context.Context, after string, first int) (*ports.FamilyPage, error) {
+	args := m.Called(ctx, after, first)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ports.FamilyPage), args.Error(1)
+}
+
+func (m *MockFamilyService) CompareFamilies(ctx context.Context, leftID, rightID string) (*ports.FamilyComparison, error) {
+	args := m.Called(ctx, leftID, rightID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ports.FamilyComparison), args.Error(1)
+}
+
 func (m *MockFamilyService) UpdateFamily(ctx context.Context, dto entity.FamilyDTO) (*entity.FamilyDTO, error) {
 	args := m.Called(ctx, dto)
 	if args.Get(0) == nil {
@@ -47,11 +105,27 @@ func (m *MockFamilyService) UpdateFamily(ctx context.Context, dto entity.FamilyD
 	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
 }
 
-func (m *MockFamilyService) DeleteFamily(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
+func (m *MockFamilyService) DeleteFamily(ctx context.Context, id string, hard bool) error {
+	args := m.Called(ctx, id, hard)
 	return args.Error(0)
 }
 
+func (m *MockFamilyService) RestoreFamily(ctx context.Context, id string) (*entity.FamilyDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) PurgeDeletedFamilies(ctx context.Context, olderThan time.Duration) ([]string, error) {
+	args := m.Called(ctx, olderThan)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockFamilyService) AddParent(ctx context.Context, familyID string, parentDTO entity.ParentDTO) (*entity.FamilyDTO, error) {
 	args := m.Called(ctx, familyID, parentDTO)
 	if args.Get(0) == nil {
@@ -76,6 +150,30 @@ func (m *MockFamilyService) RemoveChild(ctx context.Context, familyID string, ch
 	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
 }
 
+func (m *MockFamilyService) MoveChild(ctx context.Context, childID string, fromFamilyID string, toFamilyID string) (*entity.FamilyDTO, error) {
+	args := m.Called(ctx, childID, fromFamilyID, toFamilyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) Remarry(ctx context.Context, familyID string, spouseDTO entity.ParentDTO) (*entity.FamilyDTO, error) {
+	args := m.Called(ctx, familyID, spouseDTO)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) MergeFamilies(ctx context.Context, familyID string, spouseFamilyID string, childIDs []string) (*entity.FamilyDTO, error) {
+	args := m.Called(ctx, familyID, spouseFamilyID, childIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
+}
+
 func (m *MockFamilyService) MarkParentDeceased(ctx context.Context, familyID string, parentID string, deathDate time.Time) (*entity.FamilyDTO, error) {
 	args := m.Called(ctx, familyID, parentID, deathDate)
 	if args.Get(0) == nil {
@@ -84,6 +182,86 @@ func (m *MockFamilyService) MarkParentDeceased(ctx context.Context, familyID str
 	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
 }
 
+func (m *MockFamilyService) MarkChildDeceased(ctx context.Context, familyID string, childID string, deathDate time.Time) (*entity.FamilyDTO, error) {
+	args := m.Called(ctx, familyID, childID, deathDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) ChangeMemberName(ctx context.Context, familyID string, memberID string, change entity.NameChange) (*entity.FamilyDTO, error) {
+	args := m.Called(ctx, familyID, memberID, change)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) SetMemberPreferredName(ctx context.Context, familyID string, memberID string, preferredName string) (*entity.FamilyDTO, error) {
+	args := m.Called(ctx, familyID, memberID, preferredName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) UpdateParent(ctx context.Context, familyID string, parentID string, details entity.MemberDetails) (*entity.FamilyDTO, error) {
+	args := m.Called(ctx, familyID, parentID, details)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) UpdateChild(ctx context.Context, familyID string, childID string, details entity.MemberDetails) (*entity.FamilyDTO, error) {
+	args := m.Called(ctx, familyID, childID, details)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) GrantChildConsent(ctx context.Context, familyID string, childID string, consent entity.Consent) (*entity.FamilyDTO, error) {
+	args := m.Called(ctx, familyID, childID, consent)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) QuarantineFamily(ctx context.Context, familyID string, reason string) (*entity.Quarantine, error) {
+	args := m.Called(ctx, familyID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Quarantine), args.Error(1)
+}
+
+func (m *MockFamilyService) UnquarantineFamily(ctx context.Context, familyID string) (*entity.Quarantine, error) {
+	args := m.Called(ctx, familyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Quarantine), args.Error(1)
+}
+
+func (m *MockFamilyService) GetFamilyQuarantines(ctx context.Context) ([]entity.Quarantine, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Quarantine), args.Error(1)
+}
+
+func (m *MockFamilyService) GetMemberNameAsOf(ctx context.Context, familyID string, memberID string, date time.Time) (*entity.NameChange, error) {
+	args := m.Called(ctx, familyID, memberID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.NameChange), args.Error(1)
+}
+
 func (m *MockFamilyService) Divorce(ctx context.Context, familyID string, custodialParentID string) (*entity.FamilyDTO, error) {
 	args := m.Called(ctx, familyID, custodialParentID)
 	if args.Get(0) == nil {
@@ -109,6 +287,27 @@ func (m *MockFamilyService) FindFamilyByChild(ctx context.Context, childID strin
 	return args.Get(0).(*entity.FamilyDTO), args.Error(1)
 }
 
+func (m *MockFamilyService) FindFamiliesByExternalID(ctx context.Context, system, externalID string, owner entity.ExternalIDOwner) ([]*entity.FamilyDTO, error) {
+	args := m.Called(ctx, system, externalID, owner)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.FamilyDTO), args.Error(1)
+}
+
+func (m *MockFamilyService) GetAgePolicyViolations(ctx context.Context) ([]policy.Violation, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]policy.Violation), args.Error(1)
+}
+
+func (m *MockFamilyService) ExportFamilies(ctx context.Context, after string, fn func(*entity.FamilyDTO) error) (int, error) {
+	args := m.Called(ctx, after, fn)
+	return args.Int(0), args.Error(1)
+}
+
 // Methods required by ApplicationService[*entity.Family, *entity.FamilyDTO] interface
 func (m *MockFamilyService) Create(ctx context.Context, dto *entity.FamilyDTO) (*entity.FamilyDTO, error) {
 	args := m.Called(ctx, dto)
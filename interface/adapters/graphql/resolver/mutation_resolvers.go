@@ -7,6 +7,9 @@
 	"fmt"
 	"time"
 
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/dto"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
 	"github.com/abitofhelp/servicelib/valueobject/identification"
 )
@@ -21,7 +24,7 @@ func (r *mutationResolver) CreateFamily(ctx context.Context, input model.FamilyI
 	// Convert input to domain DTO
 	familyDTO, err := r.mapper.ToDomain(input)
 	if err != nil {
-		return nil, fmt.Errorf("invalid input: %w", err)
+		return nil, newInputError("input", "invalid input", err)
 	}
 
 	// Call service
@@ -49,7 +52,7 @@ func (r *mutationResolver) AddParent(ctx context.Context, familyID identificatio
 	// Convert input to domain DTO
 	parentDTO, err := r.mapper.ToParentDTO(input)
 	if err != nil {
-		return nil, fmt.Errorf("invalid input: %w", err)
+		return nil, newInputError("input", "invalid input", err)
 	}
 
 	// Call service
@@ -77,7 +80,7 @@ func (r *mutationResolver) AddChild(ctx context.Context, familyID identification
 	// Convert input to domain DTO
 	childDTO, err := r.mapper.ToChildDTO(input)
 	if err != nil {
-		return nil, fmt.Errorf("invalid input: %w", err)
+		return nil, newInputError("input", "invalid input", err)
 	}
 
 	// Call service
@@ -127,7 +130,7 @@ func (r *mutationResolver) UpdateFamily(ctx context.Context, input model.FamilyI
 	// Convert input to domain DTO
 	familyDTO, err := r.mapper.ToDomain(input)
 	if err != nil {
-		return nil, fmt.Errorf("invalid input: %w", err)
+		return nil, newInputError("input", "invalid input", err)
 	}
 
 	// Call service
@@ -146,14 +149,14 @@ func (r *mutationResolver) UpdateFamily(ctx context.Context, input model.FamilyI
 }
 
 // DeleteFamily is the resolver for the deleteFamily field.
-func (r *mutationResolver) DeleteFamily(ctx context.Context, id identification.ID) (bool, error) {
+func (r *mutationResolver) DeleteFamily(ctx context.Context, id identification.ID, hard bool) (bool, error) {
 	// Check authorization
 	if err := checkAuthorization(ctx, []string{"ADMIN"}, []string{"DELETE"}, "FAMILY"); err != nil {
 		return false, err
 	}
 
 	// Call service
-	if err := r.familyService.DeleteFamily(ctx, id.String()); err != nil {
+	if err := r.familyService.DeleteFamily(ctx, id.String(), hard); err != nil {
 		return false, fmt.Errorf("failed to delete family: %w", err)
 	}
 
@@ -161,22 +164,72 @@ func (r *mutationResolver) DeleteFamily(ctx context.Context, id identification.I
 }
 
 // MarkParentDeceased is the resolver for the markParentDeceased field.
-func (r *mutationResolver) MarkParentDeceased(ctx context.Context, familyID identification.ID, parentID identification.ID, deathDate string) (*model.Family, error) {
+func (r *mutationResolver) MarkParentDeceased(ctx context.Context, familyID identification.ID, parentID identification.ID, deathDate entity.Date) (*model.Family, error) {
 	// Check authorization
 	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR"}, []string{"UPDATE"}, "FAMILY"); err != nil {
 		return nil, err
 	}
 
-	// Parse death date using RFC3339 format as required by the project guidelines
-	parsedDeathDate, err := time.Parse(time.RFC3339, deathDate)
+	// Call service with the death date at midnight UTC, the form in which dates are stored
+	resultDTO, err := r.familyService.MarkParentDeceased(ctx, familyID.String(), parentID.String(), deathDate.Time())
 	if err != nil {
-		return nil, fmt.Errorf("invalid death date format (expected RFC3339): %w", err)
+		return nil, fmt.Errorf("failed to mark parent as deceased: %w", err)
+	}
+
+	// Convert result back to GraphQL model
+	result, err := r.mapper.ToGraphQL(*resultDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+
+	return result, nil
+}
+
+// ChangeMemberName is the resolver for the changeMemberName field.
+func (r *mutationResolver) ChangeMemberName(ctx context.Context, familyID identification.ID, memberID identification.ID, input model.NameChangeInput) (*model.Family, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR"}, []string{"UPDATE"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Convert input to domain name change
+	change, err := dto.ToNameChange(input)
+	if err != nil {
+		return nil, newInputError("input", "invalid input", err)
 	}
 
 	// Call service
-	resultDTO, err := r.familyService.MarkParentDeceased(ctx, familyID.String(), parentID.String(), parsedDeathDate)
+	resultDTO, err := r.familyService.ChangeMemberName(ctx, familyID.String(), memberID.String(), change)
 	if err != nil {
-		return nil, fmt.Errorf("failed to mark parent as deceased: %w", err)
+		return nil, fmt.Errorf("failed to change member name: %w", err)
+	}
+
+	// Convert result back to GraphQL model
+	result, err := r.mapper.ToGraphQL(*resultDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+
+	return result, nil
+}
+
+// SetPreferredName is the resolver for the setPreferredName field.
+func (r *mutationResolver) SetPreferredName(ctx context.Context, familyID identification.ID, memberID identification.ID, preferredName *string) (*model.Family, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR"}, []string{"UPDATE"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// An omitted preferred name clears it
+	var name string
+	if preferredName != nil {
+		name = *preferredName
+	}
+
+	// Call service
+	resultDTO, err := r.familyService.SetMemberPreferredName(ctx, familyID.String(), memberID.String(), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set preferred name: %w", err)
 	}
 
 	// Convert result back to GraphQL model
@@ -209,3 +262,401 @@ func (r *mutationResolver) Divorce(ctx context.Context, familyID identification.
 
 	return result, nil
 }
+
+// CreateFamilyV2 is the resolver for the createFamilyV2 field.
+func (r *mutationResolver) CreateFamilyV2(ctx context.Context, input model.FamilyInput) (*model.CreateFamilyPayload, error) {
+	family, err := r.CreateFamily(ctx, input)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CreateFamilyPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// AddParentV2 is the resolver for the addParentV2 field.
+func (r *mutationResolver) AddParentV2(ctx context.Context, familyID identification.ID, input model.ParentInput) (*model.AddParentPayload, error) {
+	family, err := r.AddParent(ctx, familyID, input)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	return &model.AddParentPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// AddChildV2 is the resolver for the addChildV2 field.
+func (r *mutationResolver) AddChildV2(ctx context.Context, familyID identification.ID, input model.ChildInput) (*model.AddChildPayload, error) {
+	family, err := r.AddChild(ctx, familyID, input)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	return &model.AddChildPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// RemoveChildV2 is the resolver for the removeChildV2 field.
+func (r *mutationResolver) RemoveChildV2(ctx context.Context, familyID identification.ID, childID identification.ID) (*model.RemoveChildPayload, error) {
+	family, err := r.RemoveChild(ctx, familyID, childID)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	return &model.RemoveChildPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// MarkParentDeceasedV2 is the resolver for the markParentDeceasedV2 field.
+func (r *mutationResolver) MarkParentDeceasedV2(ctx context.Context, familyID identification.ID, parentID identification.ID, deathDate entity.Date) (*model.MarkParentDeceasedPayload, error) {
+	family, err := r.MarkParentDeceased(ctx, familyID, parentID, deathDate)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	return &model.MarkParentDeceasedPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// ChangeMemberNameV2 is the resolver for the changeMemberNameV2 field.
+func (r *mutationResolver) ChangeMemberNameV2(ctx context.Context, familyID identification.ID, memberID identification.ID, input model.NameChangeInput) (*model.ChangeMemberNamePayload, error) {
+	family, err := r.ChangeMemberName(ctx, familyID, memberID, input)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ChangeMemberNamePayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// SetPreferredNameV2 is the resolver for the setPreferredNameV2 field.
+func (r *mutationResolver) SetPreferredNameV2(ctx context.Context, familyID identification.ID, memberID identification.ID, preferredName *string) (*model.SetPreferredNamePayload, error) {
+	family, err := r.SetPreferredName(ctx, familyID, memberID, preferredName)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	return &model.SetPreferredNamePayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// DivorceV2 is the resolver for the divorceV2 field.
+func (r *mutationResolver) DivorceV2(ctx context.Context, familyID identification.ID, custodialParentID identification.ID) (*model.DivorcePayload, error) {
+	family, err := r.Divorce(ctx, familyID, custodialParentID)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	return &model.DivorcePayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// DeleteFamilyV2 is the resolver for the deleteFamilyV2 field.
+func (r *mutationResolver) DeleteFamilyV2(ctx context.Context, id identification.ID, hard bool) (*model.DeleteFamilyPayload, error) {
+	_, err := r.DeleteFamily(ctx, id, hard)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	payload := &model.DeleteFamilyPayload{UserErrors: userErrors}
+	if len(userErrors) == 0 {
+		payload.DeletedFamilyID = &id
+	}
+	return payload, nil
+}
+
+// UpdateFamilyV2 is the resolver for the updateFamilyV2 field.
+func (r *mutationResolver) UpdateFamilyV2(ctx context.Context, input model.FamilyInput) (*model.UpdateFamilyPayload, error) {
+	family, err := r.UpdateFamily(ctx, input)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	return &model.UpdateFamilyPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// MoveChild is the resolver for the moveChild field.
+func (r *mutationResolver) MoveChild(ctx context.Context, childID identification.ID, fromFamilyID identification.ID, toFamilyID identification.ID) (*model.MoveChildPayload, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR"}, []string{"UPDATE"}, "CHILD"); err != nil {
+		return nil, err
+	}
+
+	// Call service
+	resultDTO, err := r.familyService.MoveChild(ctx, childID.String(), fromFamilyID.String(), toFamilyID.String())
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move child: %w", err)
+	}
+	if len(userErrors) > 0 {
+		return &model.MoveChildPayload{UserErrors: userErrors}, nil
+	}
+
+	// Convert result back to GraphQL model
+	family, err := r.mapper.ToGraphQL(*resultDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+
+	return &model.MoveChildPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// Remarry is the resolver for the remarry field.
+func (r *mutationResolver) Remarry(ctx context.Context, familyID identification.ID, spouse model.ParentInput) (*model.RemarryPayload, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR"}, []string{"UPDATE"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Convert input to domain DTO
+	spouseDTO, err := r.mapper.ToParentDTO(spouse)
+	if err != nil {
+		userErrors, _ := toUserErrors(newInputError("spouse", "invalid input", err))
+		return &model.RemarryPayload{UserErrors: userErrors}, nil
+	}
+
+	// Call service
+	resultDTO, err := r.familyService.Remarry(ctx, familyID.String(), spouseDTO)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarry: %w", err)
+	}
+	if len(userErrors) > 0 {
+		return &model.RemarryPayload{UserErrors: userErrors}, nil
+	}
+
+	// Convert result back to GraphQL model
+	family, err := r.mapper.ToGraphQL(*resultDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+
+	return &model.RemarryPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// MergeFamilies is the resolver for the mergeFamilies field.
+func (r *mutationResolver) MergeFamilies(ctx context.Context, familyID identification.ID, spouseFamilyID identification.ID, childIds []identification.ID) (*model.MergeFamiliesPayload, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR"}, []string{"UPDATE"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	childIDs := make([]string, len(childIds))
+	for i, id := range childIds {
+		childIDs[i] = id.String()
+	}
+
+	// Call service
+	resultDTO, err := r.familyService.MergeFamilies(ctx, familyID.String(), spouseFamilyID.String(), childIDs)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge families: %w", err)
+	}
+	if len(userErrors) > 0 {
+		return &model.MergeFamiliesPayload{UserErrors: userErrors}, nil
+	}
+
+	// Convert result back to GraphQL model
+	family, err := r.mapper.ToGraphQL(*resultDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+
+	return &model.MergeFamiliesPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// GrantConsent is the resolver for the grantConsent field.
+func (r *mutationResolver) GrantConsent(ctx context.Context, familyID identification.ID, childID identification.ID, input model.ConsentInput) (*model.GrantConsentPayload, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR"}, []string{"UPDATE"}, "CHILD"); err != nil {
+		return nil, err
+	}
+
+	// Convert input to a domain consent
+	consent, err := dto.ToConsent(input, clock.Now(ctx))
+	if err != nil {
+		userErrors, _ := toUserErrors(newInputError("input.grantedAt", "invalid grant time format (expected RFC3339)", err))
+		return &model.GrantConsentPayload{UserErrors: userErrors}, nil
+	}
+
+	// Call service
+	resultDTO, err := r.familyService.GrantChildConsent(ctx, familyID.String(), childID.String(), consent)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to grant consent: %w", err)
+	}
+	if len(userErrors) > 0 {
+		return &model.GrantConsentPayload{UserErrors: userErrors}, nil
+	}
+
+	// Convert result back to GraphQL model
+	family, err := r.mapper.ToGraphQL(*resultDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+
+	return &model.GrantConsentPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// MarkChildDeceased is the resolver for the markChildDeceased field.
+func (r *mutationResolver) MarkChildDeceased(ctx context.Context, familyID identification.ID, childID identification.ID, deathDate entity.Date) (*model.MarkChildDeceasedPayload, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR"}, []string{"UPDATE"}, "CHILD"); err != nil {
+		return nil, err
+	}
+
+	// Call service
+	resultDTO, err := r.familyService.MarkChildDeceased(ctx, familyID.String(), childID.String(), deathDate.Time())
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark child as deceased: %w", err)
+	}
+	if len(userErrors) > 0 {
+		return &model.MarkChildDeceasedPayload{UserErrors: userErrors}, nil
+	}
+
+	// Convert result back to GraphQL model
+	family, err := r.mapper.ToGraphQL(*resultDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+
+	return &model.MarkChildDeceasedPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// UpdateParent is the resolver for the updateParent field.
+func (r *mutationResolver) UpdateParent(ctx context.Context, familyID identification.ID, parentID identification.ID, input model.MemberDetailsInput) (*model.UpdateParentPayload, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR"}, []string{"UPDATE"}, "PARENT"); err != nil {
+		return nil, err
+	}
+
+	// Call service
+	resultDTO, err := r.familyService.UpdateParent(ctx, familyID.String(), parentID.String(), dto.ToMemberDetails(input))
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update parent: %w", err)
+	}
+	if len(userErrors) > 0 {
+		return &model.UpdateParentPayload{UserErrors: userErrors}, nil
+	}
+
+	// Convert result back to GraphQL model
+	family, err := r.mapper.ToGraphQL(*resultDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+
+	return &model.UpdateParentPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// UpdateChild is the resolver for the updateChild field.
+func (r *mutationResolver) UpdateChild(ctx context.Context, familyID identification.ID, childID identification.ID, input model.MemberDetailsInput) (*model.UpdateChildPayload, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR"}, []string{"UPDATE"}, "CHILD"); err != nil {
+		return nil, err
+	}
+
+	// Call service
+	resultDTO, err := r.familyService.UpdateChild(ctx, familyID.String(), childID.String(), dto.ToMemberDetails(input))
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update child: %w", err)
+	}
+	if len(userErrors) > 0 {
+		return &model.UpdateChildPayload{UserErrors: userErrors}, nil
+	}
+
+	// Convert result back to GraphQL model
+	family, err := r.mapper.ToGraphQL(*resultDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+
+	return &model.UpdateChildPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// QuarantineFamily is the resolver for the quarantineFamily field.
+func (r *mutationResolver) QuarantineFamily(ctx context.Context, id identification.ID, reason string) (*model.QuarantineFamilyPayload, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN"}, []string{"UPDATE"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Call service
+	q, err := r.familyService.QuarantineFamily(ctx, id.String(), reason)
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to quarantine family: %w", err)
+	}
+	if len(userErrors) > 0 {
+		return &model.QuarantineFamilyPayload{UserErrors: userErrors}, nil
+	}
+
+	return &model.QuarantineFamilyPayload{Quarantine: dto.ToGraphQLQuarantine(*q), UserErrors: userErrors}, nil
+}
+
+// UnquarantineFamily is the resolver for the unquarantineFamily field.
+func (r *mutationResolver) UnquarantineFamily(ctx context.Context, id identification.ID) (*model.UnquarantineFamilyPayload, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN"}, []string{"UPDATE"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Call service
+	q, err := r.familyService.UnquarantineFamily(ctx, id.String())
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove family quarantine: %w", err)
+	}
+	if len(userErrors) > 0 {
+		return &model.UnquarantineFamilyPayload{UserErrors: userErrors}, nil
+	}
+
+	return &model.UnquarantineFamilyPayload{Quarantine: dto.ToGraphQLQuarantine(*q), UserErrors: userErrors}, nil
+}
+
+// RestoreFamily is the resolver for the restoreFamily field.
+func (r *mutationResolver) RestoreFamily(ctx context.Context, id identification.ID) (*model.RestoreFamilyPayload, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN"}, []string{"DELETE"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Call service
+	resultDTO, err := r.familyService.RestoreFamily(ctx, id.String())
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore family: %w", err)
+	}
+	if len(userErrors) > 0 {
+		return &model.RestoreFamilyPayload{UserErrors: userErrors}, nil
+	}
+
+	// Convert result back to GraphQL model
+	family, err := r.mapper.ToGraphQL(*resultDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+
+	return &model.RestoreFamilyPayload{Family: family, UserErrors: userErrors}, nil
+}
+
+// PurgeDeletedFamilies is the resolver for the purgeDeletedFamilies field.
+func (r *mutationResolver) PurgeDeletedFamilies(ctx context.Context, olderThan string) (*model.PurgeDeletedFamiliesPayload, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN"}, []string{"DELETE"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Parse the duration, such as "720h", and call service
+	var ids []string
+	age, err := time.ParseDuration(olderThan)
+	if err != nil {
+		err = newInputError("olderThan", fmt.Sprintf("olderThan %q is not a duration, such as \"720h\"", olderThan), err)
+	} else {
+		ids, err = r.familyService.PurgeDeletedFamilies(ctx, age)
+	}
+	userErrors, err := toUserErrors(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge deleted families: %w", err)
+	}
+
+	purged := make([]identification.ID, len(ids))
+	for i, id := range ids {
+		purged[i] = identification.ID(id)
+	}
+	return &model.PurgeDeletedFamiliesPayload{PurgedFamilyIds: purged, UserErrors: userErrors}, nil
+}
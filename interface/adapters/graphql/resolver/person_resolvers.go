@@ -0,0 +1,72 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package resolver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/loader"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
+)
+
+// DisplayName is the resolver for the displayName field.
+func (r *parentResolver) DisplayName(ctx context.Context, obj *model.Parent) (string, error) {
+	return r.names.DisplayName(ctx, obj.FirstName, obj.LastName, preferredName(obj.PreferredName)), nil
+}
+
+// SortName is the resolver for the sortName field.
+func (r *parentResolver) SortName(ctx context.Context, obj *model.Parent) (string, error) {
+	return r.names.SortName(ctx, obj.FirstName, obj.LastName), nil
+}
+
+// Families is the resolver for the families field.
+func (r *parentResolver) Families(ctx context.Context, obj *model.Parent) ([]*model.Family, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR", "VIEWER"}, []string{"READ"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Call service; in a query, the families of all selected parents are read together
+	var resultDTOs []*entity.FamilyDTO
+	var err error
+	if loaders := loader.For(ctx); loaders != nil {
+		resultDTOs, err = loaders.FamiliesByParent.Load(ctx, obj.ID.String())
+	} else {
+		resultDTOs, err = r.familyService.FindFamiliesByParent(ctx, obj.ID.String())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find families of parent: %w", err)
+	}
+
+	// Convert results to GraphQL models
+	results := make([]*model.Family, 0, len(resultDTOs))
+	for _, dto := range resultDTOs {
+		result, err := r.mapper.ToGraphQL(*dto)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// DisplayName is the resolver for the displayName field.
+func (r *childResolver) DisplayName(ctx context.Context, obj *model.Child) (string, error) {
+	return r.names.DisplayName(ctx, obj.FirstName, obj.LastName, preferredName(obj.PreferredName)), nil
+}
+
+// SortName is the resolver for the sortName field.
+func (r *childResolver) SortName(ctx context.Context, obj *model.Child) (string, error) {
+	return r.names.SortName(ctx, obj.FirstName, obj.LastName), nil
+}
+
+// preferredName returns a preferred name, which is empty if there is none or it was withheld
+func preferredName(name *string) string {
+	if name == nil {
+		return ""
+	}
+	return *name
+}
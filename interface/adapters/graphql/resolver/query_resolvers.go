@@ -5,41 +5,193 @@
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/dto"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/loader"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
+	serviceerrors "github.com/abitofhelp/servicelib/errors"
 	"github.com/abitofhelp/servicelib/valueobject/identification"
 )
 
+// AgePolicyViolations is the resolver for the agePolicyViolations field.
+func (r *queryResolver) AgePolicyViolations(ctx context.Context) ([]*model.AgePolicyViolation, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN"}, []string{"READ"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Call service
+	violations, err := r.familyService.GetAgePolicyViolations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get age policy violations: %w", err)
+	}
+
+	// Convert results to GraphQL models
+	results := make([]*model.AgePolicyViolation, 0, len(violations))
+	for _, v := range violations {
+		results = append(results, &model.AgePolicyViolation{
+			FamilyID:         identification.ID(v.FamilyID),
+			ParentID:         identification.ID(v.ParentID),
+			ChildID:          identification.ID(v.ChildID),
+			Rule:             model.AgePolicyRule(v.Rule),
+			ParentAgeAtBirth: v.ParentAgeAtBirth,
+			Message:          v.Message,
+		})
+	}
+
+	return results, nil
+}
+
+// FamilyQuarantines is the resolver for the familyQuarantines field.
+func (r *queryResolver) FamilyQuarantines(ctx context.Context) ([]*model.Quarantine, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN"}, []string{"READ"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Call service
+	quarantines, err := r.familyService.GetFamilyQuarantines(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family quarantines: %w", err)
+	}
+
+	// Convert results to GraphQL models
+	results := make([]*model.Quarantine, 0, len(quarantines))
+	for _, q := range quarantines {
+		results = append(results, dto.ToGraphQLQuarantine(q))
+	}
+
+	return results, nil
+}
+
 // CountFamilies is the resolver for the countFamilies field.
 func (r *queryResolver) CountFamilies(ctx context.Context) (int, error) {
-	// Get all families
-	families, err := r.familyService.GetAllFamilies(ctx)
+	// Count the families in the database
+	count, err := r.familyService.CountFamilies(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get families: %w", err)
+		return 0, fmt.Errorf("failed to count families: %w", err)
 	}
 
-	// Return the count of families
-	return len(families), nil
+	return count, nil
 }
 
 // CountParents is the resolver for the countParents field.
 func (r *queryResolver) CountParents(ctx context.Context) (int, error) {
-	// Get all families
-	families, err := r.familyService.GetAllFamilies(ctx)
+	// Count the unique parents in the database
+	count, err := r.familyService.CountParents(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get families: %w", err)
+		return 0, fmt.Errorf("failed to count parents: %w", err)
 	}
 
-	// Count unique parents
-	parents := make(map[string]bool)
-	for _, family := range families {
-		for _, parent := range family.Parents {
-			parents[parent.ID] = true
+	return count, nil
+}
+
+// EstimateQueryCost is the resolver for the estimateQueryCost field.
+func (r *queryResolver) EstimateQueryCost(ctx context.Context, query string, operationName *string) (*model.QueryCostEstimate, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR", "VIEWER"}, []string{"READ"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	if r.costEstimator == nil {
+		return nil, fmt.Errorf("query cost estimation is not available")
+	}
+
+	name := ""
+	if operationName != nil {
+		name = *operationName
+	}
+
+	// Score the operation without executing it
+	estimate := r.costEstimator.Estimate(ctx, query, name)
+	return &model.QueryCostEstimate{
+		Complexity:    estimate.Complexity,
+		MaxComplexity: estimate.MaxComplexity,
+		Depth:         estimate.Depth,
+		Accepted:      estimate.Accepted,
+		Errors:        estimate.Errors,
+	}, nil
+}
+
+// Families is the resolver for the families field.
+func (r *queryResolver) Families(ctx context.Context, first int, after *string) (*model.FamilyConnection, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR", "VIEWER"}, []string{"READ"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Decode the cursor
+	var afterID string
+	if after != nil {
+		id, err := dto.FromCursor(*after)
+		if err != nil {
+			return nil, serviceerrors.NewValidationError("invalid cursor", "after", err)
+		}
+		afterID = id
+	}
+
+	// Call service
+	page, err := r.familyService.ListFamilies(ctx, afterID, first)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list families: %w", err)
+	}
+
+	// Convert the page to a GraphQL connection
+	connection := &model.FamilyConnection{
+		Edges:    make([]*model.FamilyEdge, 0, len(page.Families)),
+		PageInfo: &model.PageInfo{HasNextPage: page.HasNextPage},
+	}
+	for _, familyDTO := range page.Families {
+		node, err := r.mapper.ToGraphQL(*familyDTO)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert result: %w", err)
 		}
+		connection.Edges = append(connection.Edges, &model.FamilyEdge{Cursor: dto.ToCursor(familyDTO.ID), Node: node})
+	}
+	if len(connection.Edges) > 0 {
+		endCursor := connection.Edges[len(connection.Edges)-1].Cursor
+		connection.PageInfo.EndCursor = &endCursor
+	}
+
+	return connection, nil
+}
+
+// CompareFamilies is the resolver for the compareFamilies field.
+func (r *queryResolver) CompareFamilies(ctx context.Context, leftID identification.ID, rightID identification.ID) (*model.FamilyComparison, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR", "VIEWER"}, []string{"READ"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Call service
+	comparison, err := r.familyService.CompareFamilies(ctx, leftID.String(), rightID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare families: %w", err)
+	}
+
+	// Convert result
+	left, err := r.mapper.ToGraphQL(*comparison.Left)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+	right, err := r.mapper.ToGraphQL(*comparison.Right)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+	changes := make([]*model.FamilyChange, 0, len(comparison.Changes))
+	for _, change := range comparison.Changes {
+		changes = append(changes, dto.ToGraphQLChange(change))
 	}
 
-	// Return the count of unique parents
-	return len(parents), nil
+	return &model.FamilyComparison{
+		Left:      left,
+		Right:     right,
+		Identical: len(changes) == 0,
+		Changes:   changes,
+	}, nil
 }
 
 // FindFamiliesByParent is the resolver for the findFamiliesByParent field.
@@ -90,6 +242,89 @@ func (r *queryResolver) FindFamilyByChild(ctx context.Context, childID identific
 	return result, nil
 }
 
+// FindFamiliesByExternalID is the resolver for the findFamiliesByExternalId field.
+func (r *queryResolver) FindFamiliesByExternalID(ctx context.Context, filter model.ExternalIDFilter) ([]*model.Family, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR", "VIEWER"}, []string{"READ"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// An omitted owner matches external IDs of any kind of entity
+	var owner entity.ExternalIDOwner
+	if filter.Owner != nil {
+		owner = entity.ExternalIDOwner(*filter.Owner)
+	}
+
+	// Call service
+	resultDTOs, err := r.familyService.FindFamiliesByExternalID(ctx, filter.System, filter.ID, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find families by external ID: %w", err)
+	}
+
+	// Convert results to GraphQL models
+	results := make([]*model.Family, 0, len(resultDTOs))
+	for _, dto := range resultDTOs {
+		result, err := r.mapper.ToGraphQL(*dto)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SearchFamilies is the resolver for the searchFamilies field.
+func (r *queryResolver) SearchFamilies(ctx context.Context, search string, first int, after *identification.ID) ([]*model.Family, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR", "VIEWER"}, []string{"READ"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	// Call service
+	var afterID string
+	if after != nil {
+		afterID = after.String()
+	}
+	resultDTOs, err := r.familyService.SearchFamilies(ctx, search, afterID, first)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search families: %w", err)
+	}
+
+	// Convert results to GraphQL models
+	results := make([]*model.Family, 0, len(resultDTOs))
+	for _, dto := range resultDTOs {
+		result, err := r.mapper.ToGraphQL(*dto)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// MemberNameAsOf is the resolver for the memberNameAsOf field.
+func (r *queryResolver) MemberNameAsOf(ctx context.Context, familyID identification.ID, memberID identification.ID, date string) (*model.NameChange, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR", "VIEWER"}, []string{"READ"}, "FAMILY"); err != nil {
+		return nil, err
+	}
+
+	parsedDate, err := time.Parse(dto.RFC3339DateFormat, date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format (expected RFC3339): %w", err)
+	}
+
+	// Call service
+	name, err := r.familyService.GetMemberNameAsOf(ctx, familyID.String(), memberID.String(), parsedDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member name: %w", err)
+	}
+
+	return dto.ToGraphQLNameChange(*name), nil
+}
+
 // Parents is the resolver for the parents field.
 func (r *queryResolver) Parents(ctx context.Context) ([]*model.Parent, error) {
 	// Check authorization
@@ -129,28 +364,66 @@ func (r *queryResolver) Parents(ctx context.Context) ([]*model.Parent, error) {
 	return parents, nil
 }
 
-// CountChildren is the resolver for the countChildren field.
-func (r *queryResolver) CountChildren(ctx context.Context) (int, error) {
+// People is the resolver for the people field.
+func (r *queryResolver) People(ctx context.Context) ([]model.Person, error) {
 	// Check authorization
-	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR", "VIEWER"}, []string{"READ"}, "CHILD"); err != nil {
-		return 0, err
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR", "VIEWER"}, []string{"READ"}, "FAMILY"); err != nil {
+		return nil, err
 	}
 
 	// Get all families
 	families, err := r.familyService.GetAllFamilies(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get families: %w", err)
+		return nil, fmt.Errorf("failed to get families: %w", err)
 	}
 
-	// Count unique children
-	children := make(map[string]bool)
+	// Collect each member once; a parent can be part of several families
+	people := []model.Person{}
+	seen := make(map[string]bool)
 	for _, family := range families {
-		for _, child := range family.Children {
-			children[child.ID] = true
+		for _, parentDTO := range family.Parents {
+			if seen[parentDTO.ID] {
+				continue
+			}
+			seen[parentDTO.ID] = true
+
+			parent, err := r.mapper.ToParent(parentDTO)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert parent: %w", err)
+			}
+			people = append(people, parent)
+		}
+		for _, childDTO := range family.Children {
+			if seen[childDTO.ID] {
+				continue
+			}
+			seen[childDTO.ID] = true
+
+			child, err := r.mapper.ToChild(childDTO)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert child: %w", err)
+			}
+			people = append(people, child)
 		}
 	}
 
-	return len(children), nil
+	return people, nil
+}
+
+// CountChildren is the resolver for the countChildren field.
+func (r *queryResolver) CountChildren(ctx context.Context) (int, error) {
+	// Check authorization
+	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR", "VIEWER"}, []string{"READ"}, "CHILD"); err != nil {
+		return 0, err
+	}
+
+	// Count the unique children in the database
+	count, err := r.familyService.CountChildren(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count children: %w", err)
+	}
+
+	return count, nil
 }
 
 // GetFamily is the resolver for the getFamily field.
@@ -160,8 +433,15 @@ func (r *queryResolver) GetFamily(ctx context.Context, id identification.ID) (*m
 		return nil, err
 	}
 
-	// Call service
-	resultDTO, err := r.familyService.GetFamily(ctx, id.String())
+	// Call service; in a query, the family is read together with the other families read
+	// by the query
+	var resultDTO *entity.FamilyDTO
+	var err error
+	if loaders := loader.For(ctx); loaders != nil {
+		resultDTO, err = loaders.Families.Load(ctx, id.String())
+	} else {
+		resultDTO, err = r.familyService.GetFamily(ctx, id.String())
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get family: %w", err)
 	}
@@ -176,14 +456,28 @@ func (r *queryResolver) GetFamily(ctx context.Context, id identification.ID) (*m
 }
 
 // GetAllFamilies is the resolver for the getAllFamilies field.
-func (r *queryResolver) GetAllFamilies(ctx context.Context) ([]*model.Family, error) {
+func (r *queryResolver) GetAllFamilies(ctx context.Context, filter *model.FamilyFilterInput, sort *model.FamilySortInput) ([]*model.Family, error) {
 	// Check authorization
 	if err := checkAuthorization(ctx, []string{"ADMIN", "EDITOR", "VIEWER"}, []string{"READ"}, "FAMILY"); err != nil {
 		return nil, err
 	}
 
-	// Call service
-	resultDTOs, err := r.familyService.GetAllFamilies(ctx)
+	// Call service; without a filter or order, every family is returned in ID order
+	var resultDTOs []*entity.FamilyDTO
+	var err error
+	if filter == nil && sort == nil {
+		resultDTOs, err = r.familyService.GetAllFamilies(ctx)
+	} else {
+		var f query.Filter
+		if filter != nil {
+			f = dto.ToFilter(*filter)
+		}
+		var order query.Order
+		if sort != nil {
+			order = dto.ToOrder(*sort)
+		}
+		resultDTOs, err = r.familyService.FindFamilies(ctx, f, order)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get families: %w", err)
 	}
@@ -18,8 +18,11 @@
 
 import (
 	"github.com/abitofhelp/family-service/core/application/ports"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/authz"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/cost"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/dto"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/generated"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/locale"
 )
 
 // This file will not be regenerated automatically.
@@ -43,6 +46,11 @@
 type Resolver struct {
 	familyService ports.FamilyApplicationService // Application service for family operations
 	mapper       dto.FamilyMapper               // Mapper for converting between GraphQL and domain models
+	authorizer    *authz.Authorizer              // Authorizer for the isAuthorized directive
+	costEstimator *cost.Estimator                // Estimator of the cost of queries; nil if unavailable
+	names         *locale.Formatter              // Formatter of the display and sort names of members
+	majorityAge   int                            // Age of majority of the numberOfDependents field of families
+	compositions  compositionCache               // Compositions of families, by family version and day
 }
 
 // NewResolver creates a new resolver with the given dependencies.
@@ -54,16 +62,52 @@ type Resolver struct {
 // Parameters:
 //   - familyService: Application service for family operations
 //   - mapper: Mapper for converting between GraphQL and domain models
+//   - authorizer: Authorizer for the isAuthorized directive (nil accepts fine-grained and coarse scopes)
 //
 // Returns:
 //   - A new Resolver instance with the provided dependencies
-func NewResolver(familyService ports.FamilyApplicationService, mapper dto.FamilyMapper) *Resolver {
+func NewResolver(familyService ports.FamilyApplicationService, mapper dto.FamilyMapper, authorizer *authz.Authorizer) *Resolver {
+	if authorizer == nil {
+		authorizer = authz.NewAuthorizer(true)
+	}
 	return &Resolver{
 		familyService: familyService,
 		mapper:       mapper,
+		authorizer:    authorizer,
+		names:         locale.NewDefaultFormatter(),
+		majorityAge:   defaultMajorityAge,
 	}
 }
 
+// SetCostEstimator sets the estimator of the estimateQueryCost query.
+//
+// The estimator needs the executable schema, which is created from the resolver,
+// so it is set after the schema is created.
+//
+// Parameters:
+//   - estimator: Estimator of the cost of queries against the server's schema and limits
+func (r *Resolver) SetCostEstimator(estimator *cost.Estimator) {
+	r.costEstimator = estimator
+}
+
+// SetNameFormatter sets the formatter of the displayName and sortName fields of parents
+// and children, which uses the built-in name templates until it is set.
+//
+// Parameters:
+//   - names: Formatter of the names of members for the locale of a request
+func (r *Resolver) SetNameFormatter(names *locale.Formatter) {
+	r.names = names
+}
+
+// SetMajorityAge sets the age of majority of the numberOfDependents field of families,
+// which is 18 until it is set.
+//
+// Parameters:
+//   - age: The age, in years, at which children are no longer dependents
+func (r *Resolver) SetMajorityAge(age int) {
+	r.majorityAge = age
+}
+
 // Query returns the query resolver implementation.
 //
 // This method returns a resolver for GraphQL query operations.
@@ -106,6 +150,24 @@ func (r *Resolver) Family() generated.FamilyResolver {
 	return &familyResolver{r}
 }
 
+// Parent returns the parent resolver implementation, which resolves the computed
+// fields of parents.
+//
+// Returns:
+//   - A ParentResolver implementation that can resolve fields on Parent objects
+func (r *Resolver) Parent() generated.ParentResolver {
+	return &parentResolver{r}
+}
+
+// Child returns the child resolver implementation, which resolves the computed
+// fields of children.
+//
+// Returns:
+//   - A ChildResolver implementation that can resolve fields on Child objects
+func (r *Resolver) Child() generated.ChildResolver {
+	return &childResolver{r}
+}
+
 // These types implement the specific resolver interfaces generated by gqlgen.
 // Each embeds the main Resolver to access its dependencies.
 type (
@@ -123,4 +185,14 @@ func (r *Resolver) Family() generated.FamilyResolver {
 	familyResolver struct {
 		*Resolver // Embeds the main Resolver for access to dependencies
 	}
+
+	// parentResolver implements the ParentResolver interface for resolving fields on Parent objects.
+	parentResolver struct {
+		*Resolver // Embeds the main Resolver for access to dependencies
+	}
+
+	// childResolver implements the ChildResolver interface for resolving fields on Child objects.
+	childResolver struct {
+		*Resolver // Embeds the main Resolver for access to dependencies
+	}
 )
@@ -22,15 +22,15 @@ func TestResolver_CreateFamily_Integration(t *testing.T) {
 	// Setup mock service and mapper
 	mockService := new(MockFamilyService)
 	mockMapper := NewMockFamilyMapper()
-	resolver := NewResolver(mockService, mockMapper)
+	resolver := NewResolver(mockService, mockMapper, nil)
 
 	// Test data
 	familyID := uuid.New().String()
 	parentID := uuid.New().String()
 	childID := uuid.New().String()
 	now := time.Now()
-	birthDate := now.AddDate(-30, 0, 0).Format(time.RFC3339)
-	deathDate := now.AddDate(-1, 0, 0).Format(time.RFC3339)
+	birthDate := entity.DateOf(now.AddDate(-30, 0, 0))
+	deathDate := entity.DateOf(now.AddDate(-1, 0, 0))
 
 	input := model.FamilyInput{
 		ID:     identification.ID(familyID),
@@ -142,7 +142,7 @@ func TestResolver_GetFamily_Integration(t *testing.T) {
 	// Setup mock service and mapper
 	mockService := new(MockFamilyService)
 	mockMapper := NewMockFamilyMapper()
-	resolver := NewResolver(mockService, mockMapper)
+	resolver := NewResolver(mockService, mockMapper, nil)
 
 	// Test data
 	familyID := uuid.New().String()
@@ -188,8 +188,8 @@ func TestResolver_GetFamily_Integration(t *testing.T) {
 				ID:        identification.ID(parentID),
 				FirstName: "John",
 				LastName:  "Doe",
-				BirthDate: birthDate.Format(time.RFC3339),
-				DeathDate: func() *string { s := deathDate.Format(time.RFC3339); return &s }(),
+				BirthDate: entity.DateOf(birthDate),
+				DeathDate: func() *entity.Date { d := entity.DateOf(deathDate); return &d }(),
 			},
 		},
 		Children: []*model.Child{
@@ -197,7 +197,7 @@ func TestResolver_GetFamily_Integration(t *testing.T) {
 				ID:        identification.ID(childID),
 				FirstName: "Jane",
 				LastName:  "Doe",
-				BirthDate: birthDate.Format(time.RFC3339),
+				BirthDate: entity.DateOf(birthDate),
 			},
 		},
 	}
@@ -220,16 +220,16 @@ func TestResolver_GetFamily_Integration(t *testing.T) {
 	assert.Equal(t, identification.ID(parentID), result.Parents[0].ID)
 	assert.Equal(t, "John", result.Parents[0].FirstName)
 	assert.Equal(t, "Doe", result.Parents[0].LastName)
-	assert.Equal(t, birthDate.Format(time.RFC3339), result.Parents[0].BirthDate)
+	assert.Equal(t, entity.DateOf(birthDate), result.Parents[0].BirthDate)
 	require.NotNil(t, result.Parents[0].DeathDate)
-	assert.Equal(t, deathDate.Format(time.RFC3339), *result.Parents[0].DeathDate)
+	assert.Equal(t, entity.DateOf(deathDate), *result.Parents[0].DeathDate)
 
 	// Assert children
 	require.Len(t, result.Children, 1)
 	assert.Equal(t, identification.ID(childID), result.Children[0].ID)
 	assert.Equal(t, "Jane", result.Children[0].FirstName)
 	assert.Equal(t, "Doe", result.Children[0].LastName)
-	assert.Equal(t, birthDate.Format(time.RFC3339), result.Children[0].BirthDate)
+	assert.Equal(t, entity.DateOf(birthDate), result.Children[0].BirthDate)
 	assert.Nil(t, result.Children[0].DeathDate)
 
 	// Verify mock expectations
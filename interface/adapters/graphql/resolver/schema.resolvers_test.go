@@ -6,12 +6,19 @@
 	"testing"
 	"time"
 
+	"github.com/abitofhelp/family-service/core/application/ports"
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	"github.com/abitofhelp/family-service/core/domain/diff"
 	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/dto"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/locale"
 	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
+	"github.com/abitofhelp/servicelib/errors"
 	"github.com/abitofhelp/servicelib/valueobject/identification"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockFamilyService is defined in mock_family_service.go
@@ -44,7 +51,7 @@ func TestQueryResolver_GetFamily(t *testing.T) {
 	// Create mock service and mapper
 	mockService := new(MockFamilyService)
 	mockMapper := NewMockFamilyMapper()
-	resolver := NewResolver(mockService, mockMapper)
+	resolver := NewResolver(mockService, mockMapper, nil)
 
 	// Create test data
 	ctx := context.Background()
@@ -63,7 +70,7 @@ func TestQueryResolver_GetFamily(t *testing.T) {
 				ID:        identification.ID(testFamily.Parents[0].ID),
 				FirstName: testFamily.Parents[0].FirstName,
 				LastName:  testFamily.Parents[0].LastName,
-				BirthDate: testFamily.Parents[0].BirthDate.Format(time.RFC3339),
+				BirthDate: entity.DateOf(testFamily.Parents[0].BirthDate),
 			},
 		},
 		Children: []*model.Child{
@@ -71,7 +78,7 @@ func TestQueryResolver_GetFamily(t *testing.T) {
 				ID:        identification.ID(testFamily.Children[0].ID),
 				FirstName: testFamily.Children[0].FirstName,
 				LastName:  testFamily.Children[0].LastName,
-				BirthDate: testFamily.Children[0].BirthDate.Format(time.RFC3339),
+				BirthDate: entity.DateOf(testFamily.Children[0].BirthDate),
 			},
 		},
 	}, nil)
@@ -95,7 +102,7 @@ func TestQueryResolver_GetFamily_Error(t *testing.T) {
 	// Create mock service and mapper
 	mockService := new(MockFamilyService)
 	mockMapper := NewMockFamilyMapper()
-	resolver := NewResolver(mockService, mockMapper)
+	resolver := NewResolver(mockService, mockMapper, nil)
 
 	// Create test data
 	ctx := context.Background()
@@ -121,7 +128,7 @@ func TestMutationResolver_CreateFamily(t *testing.T) {
 	// Create mock service and mapper
 	mockService := new(MockFamilyService)
 	mockMapper := NewMockFamilyMapper()
-	resolver := NewResolver(mockService, mockMapper)
+	resolver := NewResolver(mockService, mockMapper, nil)
 
 	// Create test data
 	ctx := context.Background()
@@ -133,7 +140,7 @@ func TestMutationResolver_CreateFamily(t *testing.T) {
 				ID:        identification.ID("parent1"),
 				FirstName: "John",
 				LastName:  "Doe",
-				BirthDate: "1980-01-01T00:00:00Z",
+				BirthDate: entity.DateOf(time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)),
 			},
 		},
 		Children: []*model.ChildInput{
@@ -141,7 +148,7 @@ func TestMutationResolver_CreateFamily(t *testing.T) {
 				ID:        identification.ID("child1"),
 				FirstName: "Jane",
 				LastName:  "Doe",
-				BirthDate: "2010-01-01T00:00:00Z",
+				BirthDate: entity.DateOf(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)),
 			},
 		},
 	}
@@ -165,7 +172,7 @@ func TestMutationResolver_CreateFamily(t *testing.T) {
 				ID:        identification.ID(testFamily.Parents[0].ID),
 				FirstName: testFamily.Parents[0].FirstName,
 				LastName:  testFamily.Parents[0].LastName,
-				BirthDate: testFamily.Parents[0].BirthDate.Format(time.RFC3339),
+				BirthDate: entity.DateOf(testFamily.Parents[0].BirthDate),
 			},
 		},
 		Children: []*model.Child{
@@ -173,7 +180,7 @@ func TestMutationResolver_CreateFamily(t *testing.T) {
 				ID:        identification.ID(testFamily.Children[0].ID),
 				FirstName: testFamily.Children[0].FirstName,
 				LastName:  testFamily.Children[0].LastName,
-				BirthDate: testFamily.Children[0].BirthDate.Format(time.RFC3339),
+				BirthDate: entity.DateOf(testFamily.Children[0].BirthDate),
 			},
 		},
 	}, nil)
@@ -199,7 +206,7 @@ func TestMutationResolver_AddChild(t *testing.T) {
 	// Create mock service and mapper
 	mockService := new(MockFamilyService)
 	mockMapper := NewMockFamilyMapper()
-	resolver := NewResolver(mockService, mockMapper)
+	resolver := NewResolver(mockService, mockMapper, nil)
 
 	// Create test data
 	ctx := context.Background()
@@ -208,7 +215,7 @@ func TestMutationResolver_AddChild(t *testing.T) {
 		ID:        identification.ID("child2"),
 		FirstName: "Jim",
 		LastName:  "Doe",
-		BirthDate: "2012-01-01T00:00:00Z",
+		BirthDate: entity.DateOf(time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)),
 	}
 
 	testFamily := createTestFamilyDTO()
@@ -236,7 +243,7 @@ func TestMutationResolver_AddChild(t *testing.T) {
 				ID:        identification.ID(testFamily.Parents[0].ID),
 				FirstName: testFamily.Parents[0].FirstName,
 				LastName:  testFamily.Parents[0].LastName,
-				BirthDate: testFamily.Parents[0].BirthDate.Format(time.RFC3339),
+				BirthDate: entity.DateOf(testFamily.Parents[0].BirthDate),
 			},
 		},
 		Children: []*model.Child{
@@ -244,13 +251,13 @@ func TestMutationResolver_AddChild(t *testing.T) {
 				ID:        identification.ID(testFamily.Children[0].ID),
 				FirstName: testFamily.Children[0].FirstName,
 				LastName:  testFamily.Children[0].LastName,
-				BirthDate: testFamily.Children[0].BirthDate.Format(time.RFC3339),
+				BirthDate: entity.DateOf(testFamily.Children[0].BirthDate),
 			},
 			{
 				ID:        identification.ID(testFamily.Children[1].ID),
 				FirstName: testFamily.Children[1].FirstName,
 				LastName:  testFamily.Children[1].LastName,
-				BirthDate: testFamily.Children[1].BirthDate.Format(time.RFC3339),
+				BirthDate: entity.DateOf(testFamily.Children[1].BirthDate),
 			},
 		},
 	}, nil)
@@ -278,42 +285,250 @@ func TestQueryResolver_CountChildren(t *testing.T) {
 	// Create mock service and mapper
 	mockService := new(MockFamilyService)
 	mockMapper := NewMockFamilyMapper()
-	resolver := NewResolver(mockService, mockMapper)
+	resolver := NewResolver(mockService, mockMapper, nil)
 
-	// Create test data
+	// Set up mock expectations; the children are counted by the database
 	ctx := context.Background()
-	testFamilies := []*entity.FamilyDTO{
-		createTestFamilyDTO(),
-		{
-			ID:     "family2",
-			Status: "ACTIVE",
-			Children: []entity.ChildDTO{
-				{
-					ID:        "child2",
-					FirstName: "Jim",
-					LastName:  "Smith",
-					BirthDate: time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC),
-				},
-				{
-					ID:        "child3",
-					FirstName: "Sarah",
-					LastName:  "Smith",
-					BirthDate: time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC),
-				},
-			},
-		},
-	}
-
-	// Set up mock expectations
-	mockService.On("GetAllFamilies", ctx).Return(testFamilies, nil)
+	mockService.On("CountChildren", ctx).Return(3, nil)
 
 	// Execute the resolver
 	count, err := resolver.Query().CountChildren(ctx)
 
 	// Assert results
 	assert.NoError(t, err)
-	assert.Equal(t, 3, count) // Total unique children across all families
+	assert.Equal(t, 3, count)
+
+	// Verify mock
+	mockService.AssertExpectations(t)
+}
+
+func TestQueryResolver_People(t *testing.T) {
+	// Create mock service and a real mapper
+	mockService := new(MockFamilyService)
+	resolver := NewResolver(mockService, dto.NewFamilyMapper(), nil)
+
+	// parent1 is part of both families
+	ctx := context.Background()
+	family2 := createTestFamilyDTO()
+	family2.ID = "family2"
+	family2.Children[0].ID = "child2"
+	mockService.On("GetAllFamilies", ctx).Return([]*entity.FamilyDTO{createTestFamilyDTO(), family2}, nil)
+
+	// Execute the resolver
+	people, err := resolver.Query().People(ctx)
+
+	// Each member is returned once, parents of a family before its children
+	assert.NoError(t, err)
+	var ids []string
+	for _, person := range people {
+		ids = append(ids, person.GetID().String())
+	}
+	assert.Equal(t, []string{"parent1", "child1", "child2"}, ids)
+	assert.IsType(t, &model.Parent{}, people[0])
+	assert.IsType(t, &model.Child{}, people[1])
+	assert.Equal(t, "Jane", people[1].GetFirstName())
+
+	// Verify mock
+	mockService.AssertExpectations(t)
+}
+
+func TestQueryResolver_GetAllFamilies_Filter(t *testing.T) {
+	// Create mock service and a real mapper
+	mockService := new(MockFamilyService)
+	resolver := NewResolver(mockService, dto.NewFamilyMapper(), nil)
+
+	ctx := context.Background()
+	lastName, minChildren := "Doe", 1
+	filter := &model.FamilyFilterInput{ParentLastName: &lastName, MinChildren: &minChildren}
+	sort := &model.FamilySortInput{Field: model.FamilySortFieldChildrenCount, Direction: model.SortDirectionDesc}
+	mockService.On("FindFamilies", ctx, dto.ToFilter(*filter), query.Order{Field: query.FieldChildCount, Descending: true}).
+		Return([]*entity.FamilyDTO{createTestFamilyDTO()}, nil)
+
+	// A filter or order is passed to the service, which filters in the repository
+	families, err := resolver.Query().GetAllFamilies(ctx, filter, sort)
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "family1", families[0].ID.String())
+
+	// Without either, every family is returned
+	mockService.On("GetAllFamilies", ctx).Return([]*entity.FamilyDTO{}, nil)
+	families, err = resolver.Query().GetAllFamilies(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, families)
+
+	// Verify mock
+	mockService.AssertExpectations(t)
+}
+
+func TestQueryResolver_SearchFamilies(t *testing.T) {
+	// Create mock service and a real mapper
+	mockService := new(MockFamilyService)
+	resolver := NewResolver(mockService, dto.NewFamilyMapper(), nil)
+
+	ctx := context.Background()
+	mockService.On("SearchFamilies", ctx, "doe jo", "", 10).Return([]*entity.FamilyDTO{createTestFamilyDTO()}, nil)
+	mockService.On("SearchFamilies", ctx, "doe jo", "family1", 10).Return([]*entity.FamilyDTO{}, nil)
+	mockService.On("SearchFamilies", ctx, " ", "", 10).Return(nil, errors.NewValidationError("search must contain a name", "query", nil))
+
+	// The search is made by the service
+	families, err := resolver.Query().SearchFamilies(ctx, "doe jo", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "family1", families[0].ID.String())
+
+	// The next results start after the last family
+	families, err = resolver.Query().SearchFamilies(ctx, "doe jo", 10, &families[0].ID)
+	require.NoError(t, err)
+	assert.Empty(t, families)
+
+	_, err = resolver.Query().SearchFamilies(ctx, " ", 10, nil)
+	assert.True(t, errors.IsValidationError(err))
+
+	// Verify mock
+	mockService.AssertExpectations(t)
+}
+
+func TestQueryResolver_Families(t *testing.T) {
+	// Create mock service and a real mapper
+	mockService := new(MockFamilyService)
+	resolver := NewResolver(mockService, dto.NewFamilyMapper(), nil)
+
+	ctx := context.Background()
+	family2 := createTestFamilyDTO()
+	family2.ID = "family2"
+	mockService.On("ListFamilies", ctx, "", 2).Return(&ports.FamilyPage{
+		Families:    []*entity.FamilyDTO{createTestFamilyDTO(), family2},
+		HasNextPage: true,
+	}, nil)
+	mockService.On("ListFamilies", ctx, "family2", 2).Return(&ports.FamilyPage{Families: []*entity.FamilyDTO{}}, nil)
+
+	// The first page ends with the cursor of its last family
+	connection, err := resolver.Query().Families(ctx, 2, nil)
+	require.NoError(t, err)
+	require.Len(t, connection.Edges, 2)
+	assert.Equal(t, "family1", connection.Edges[0].Node.ID.String())
+	assert.Equal(t, dto.ToCursor("family1"), connection.Edges[0].Cursor)
+	assert.True(t, connection.PageInfo.HasNextPage)
+	require.NotNil(t, connection.PageInfo.EndCursor)
+	assert.Equal(t, dto.ToCursor("family2"), *connection.PageInfo.EndCursor)
+
+	// The next page starts after that cursor; an empty page has no end cursor
+	connection, err = resolver.Query().Families(ctx, 2, connection.PageInfo.EndCursor)
+	require.NoError(t, err)
+	assert.Empty(t, connection.Edges)
+	assert.False(t, connection.PageInfo.HasNextPage)
+	assert.Nil(t, connection.PageInfo.EndCursor)
+
+	// A cursor that was not returned by the service is rejected
+	invalid := "not a cursor"
+	_, err = resolver.Query().Families(ctx, 2, &invalid)
+	assert.Error(t, err)
 
 	// Verify mock
 	mockService.AssertExpectations(t)
 }
+
+func TestQueryResolver_CompareFamilies(t *testing.T) {
+	// Create mock service and a real mapper
+	mockService := new(MockFamilyService)
+	resolver := NewResolver(mockService, dto.NewFamilyMapper(), nil)
+
+	ctx := context.Background()
+	left := createTestFamilyDTO()
+	right := createTestFamilyDTO()
+	right.ID = "family2"
+	right.Parents[0].FirstName = "Jane"
+	mockService.On("CompareFamilies", ctx, "family1", "family2").Return(&ports.FamilyComparison{
+		Left:    left,
+		Right:   right,
+		Changes: diff.Families(*left, *right),
+	}, nil)
+	mockService.On("CompareFamilies", ctx, "family1", "missing").Return(nil, fmt.Errorf("family not found"))
+
+	comparison, err := resolver.Query().CompareFamilies(ctx, "family1", "family2")
+	require.NoError(t, err)
+	assert.Equal(t, "family1", comparison.Left.ID.String())
+	assert.Equal(t, "family2", comparison.Right.ID.String())
+	assert.False(t, comparison.Identical)
+	require.Len(t, comparison.Changes, 1)
+	change := comparison.Changes[0]
+	assert.Equal(t, "parents[0].firstName", change.Path)
+	assert.Equal(t, model.FamilyChangeKindChanged, change.Kind)
+	require.NotNil(t, change.MemberID)
+	assert.Equal(t, left.Parents[0].ID, change.MemberID.String())
+	require.NotNil(t, change.Before)
+	require.NotNil(t, change.After)
+	assert.Equal(t, left.Parents[0].FirstName, *change.Before)
+	assert.Equal(t, "Jane", *change.After)
+
+	_, err = resolver.Query().CompareFamilies(ctx, "family1", "missing")
+	assert.Error(t, err)
+
+	// Verify mock
+	mockService.AssertExpectations(t)
+}
+
+func TestPersonResolvers_Names(t *testing.T) {
+	resolver := NewResolver(new(MockFamilyService), dto.NewFamilyMapper(), nil)
+	preferred := "Johnny"
+	parent := &model.Parent{ID: "parent1", FirstName: "John", LastName: "Doe", PreferredName: &preferred}
+	child := &model.Child{ID: "child1", FirstName: "Taro", LastName: "Yamada"}
+
+	// Western order without a locale
+	ctx := context.Background()
+	displayName, err := resolver.Parent().DisplayName(ctx, parent)
+	require.NoError(t, err)
+	assert.Equal(t, "Johnny Doe", displayName)
+	sortName, err := resolver.Parent().SortName(ctx, parent)
+	require.NoError(t, err)
+	assert.Equal(t, "Doe, John", sortName)
+
+	// Family name first for Japanese
+	ctx = locale.WithLocales(ctx, []string{"ja-jp", "en"})
+	displayName, err = resolver.Child().DisplayName(ctx, child)
+	require.NoError(t, err)
+	assert.Equal(t, "Yamada Taro", displayName)
+	sortName, err = resolver.Child().SortName(ctx, child)
+	require.NoError(t, err)
+	assert.Equal(t, "Yamada Taro", sortName)
+
+	// Configured templates replace the built-in ones
+	names, err := locale.NewFormatter("en", map[string]locale.Template{"ja": {Display: "{last}{first}", Sort: "{last}{first}"}})
+	require.NoError(t, err)
+	resolver.SetNameFormatter(names)
+	displayName, err = resolver.Child().DisplayName(ctx, child)
+	require.NoError(t, err)
+	assert.Equal(t, "YamadaTaro", displayName)
+}
+
+func TestFamilyResolver_Composition(t *testing.T) {
+	resolver := NewResolver(new(MockFamilyService), dto.NewFamilyMapper(), nil)
+	family, err := dto.NewFamilyMapper().ToGraphQL(*createTestFamilyDTO())
+	require.NoError(t, err)
+
+	ctx := clock.Freeze(context.Background(), time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	dependents, err := resolver.Family().NumberOfDependents(ctx, family)
+	require.NoError(t, err)
+	assert.Equal(t, 1, dependents)
+	deceased, err := resolver.Family().HasDeceasedMembers(ctx, family)
+	require.NoError(t, err)
+	assert.False(t, deceased)
+	span, err := resolver.Family().GenerationSpanYears(ctx, family)
+	require.NoError(t, err)
+	assert.Equal(t, 30, span)
+
+	// Cached compositions are not reused on another day, when a child may have come of age
+	ctx = clock.Freeze(context.Background(), time.Date(2028, 1, 1, 12, 0, 0, 0, time.UTC))
+	dependents, err = resolver.Family().NumberOfDependents(ctx, family)
+	require.NoError(t, err)
+	assert.Equal(t, 0, dependents)
+
+	// Nor for another version of the family
+	died, err := entity.ParseDate("2027-03-04")
+	require.NoError(t, err)
+	family.Children[0].DeathDate = &died
+	family.Checksum = "changed"
+	deceased, err = resolver.Family().HasDeceasedMembers(ctx, family)
+	require.NoError(t, err)
+	assert.True(t, deceased)
+}
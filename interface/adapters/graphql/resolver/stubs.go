@@ -0,0 +1,35 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package resolver
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CodeNotImplemented is the error code of a field whose resolver is a stub
+const CodeNotImplemented = "NOT_IMPLEMENTED"
+
+// notImplemented returns the error of a stub resolver.
+//
+// A stub is a resolver that exists so that the schema and the resolvers agree, but that
+// does not work yet. A stub returns notImplemented with its field, as "Type.field":
+//
+//	return nil, notImplemented("Query.familyTree")
+//
+// tools/graphql-gen lists the stubs in stubs_gen.go, and the server flags them at startup.
+func notImplemented(field string) error {
+	return &gqlerror.Error{
+		Message:    fmt.Sprintf("%s is not implemented", field),
+		Extensions: map[string]interface{}{"code": CodeNotImplemented},
+	}
+}
+
+// StubbedFields returns the schema fields, as "Type.field", whose resolvers are stubs
+func StubbedFields() []string {
+	fields := append([]string(nil), stubbedFields...)
+	sort.Strings(fields)
+	return fields
+}
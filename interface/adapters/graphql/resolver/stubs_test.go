@@ -0,0 +1,34 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestNotImplemented(t *testing.T) {
+	err := notImplemented("Query.familyTree")
+
+	var gqlErr *gqlerror.Error
+	require.ErrorAs(t, err, &gqlErr)
+	assert.Equal(t, "Query.familyTree is not implemented", gqlErr.Message)
+	assert.Equal(t, CodeNotImplemented, gqlErr.Extensions["code"])
+}
+
+func TestStubbedFields(t *testing.T) {
+	saved := stubbedFields
+	defer func() { stubbedFields = saved }()
+
+	stubbedFields = []string{"Query.familyTree", "Mutation.archiveFamily"}
+
+	fields := StubbedFields()
+	assert.Equal(t, []string{"Mutation.archiveFamily", "Query.familyTree"}, fields)
+
+	// The returned slice is a copy
+	fields[0] = "changed"
+	assert.Equal(t, "Query.familyTree", stubbedFields[0])
+}
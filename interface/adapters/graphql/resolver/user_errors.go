@@ -0,0 +1,96 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package resolver
+
+import (
+	"errors"
+
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
+	serviceerrors "github.com/abitofhelp/servicelib/errors"
+)
+
+// inputError is an error in a mutation argument that could not be converted to the domain
+type inputError struct {
+	field   string
+	message string
+	cause   error
+}
+
+// newInputError creates an error for an invalid mutation argument
+func newInputError(field, message string, cause error) error {
+	return &inputError{field: field, message: message, cause: cause}
+}
+
+// Error returns the error message
+func (e *inputError) Error() string {
+	return e.message + ": " + e.cause.Error()
+}
+
+// Unwrap returns the cause of the error
+func (e *inputError) Unwrap() error {
+	return e.cause
+}
+
+// toUserErrors converts an error returned by a mutation into the user errors of its payload.
+//
+// Expected failures (invalid input, missing resources, and violated business rules) become
+// user errors. Any other error is returned unchanged, to be reported as a GraphQL error.
+//
+// Returns:
+//   - The user errors, which are empty if err is nil
+//   - The error to report as a GraphQL error, or nil if the failure was expected
+func toUserErrors(err error) ([]*model.UserError, error) {
+	if err == nil {
+		return []*model.UserError{}, nil
+	}
+	if userErr := toUserError(err); userErr != nil {
+		return []*model.UserError{userErr}, nil
+	}
+	return nil, err
+}
+
+// toUserError converts an expected failure into a user error, or returns nil for any other error
+func toUserError(err error) *model.UserError {
+	var inputErr *inputError
+	if errors.As(err, &inputErr) {
+		return newUserError(model.UserErrorCodeValidationError, inputErr.Error(), inputErr.field)
+	}
+
+	// Errors of the domain layer carry their own codes
+	var domainValidationErr domainerrors.ValidationError
+	if errors.As(err, &domainValidationErr) {
+		return newUserError(model.UserErrorCodeValidationError, domainValidationErr.Message(), domainValidationErr.Field())
+	}
+	var domainNotFoundErr domainerrors.NotFoundError
+	if errors.As(err, &domainNotFoundErr) {
+		return newUserError(model.UserErrorCodeNotFound, domainNotFoundErr.Message(), "")
+	}
+	var domainErr domainerrors.Error
+	if errors.As(err, &domainErr) {
+		if code := model.UserErrorCode(domainErr.Code()); code.IsValid() {
+			return newUserError(code, domainErr.Message(), "")
+		}
+		return nil
+	}
+
+	// Errors raised through the service library by entities and policies
+	var validationErr *serviceerrors.ValidationError
+	if errors.As(err, &validationErr) {
+		return newUserError(model.UserErrorCodeValidationError, validationErr.GetMessage(), validationErr.Field)
+	}
+	var notFoundErr *serviceerrors.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return newUserError(model.UserErrorCodeNotFound, notFoundErr.GetMessage(), "")
+	}
+	return nil
+}
+
+// newUserError creates a user error, with a field path if the field is known
+func newUserError(code model.UserErrorCode, message, field string) *model.UserError {
+	userErr := &model.UserError{Code: code, Message: message}
+	if field != "" {
+		userErr.Field = []string{field}
+	}
+	return userErr
+}
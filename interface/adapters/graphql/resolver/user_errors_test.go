@@ -0,0 +1,286 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/errorswrapper"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
+	"github.com/abitofhelp/servicelib/valueobject/identification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToUserErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    *model.UserError
+		wantErr bool
+	}{
+		{
+			name: "domain rule violation",
+			err:  fmt.Errorf("failed to add parent: %w", domainerrors.NewFamilyTooManyParentsError("family cannot have more than two parents", nil)),
+			want: &model.UserError{Code: model.UserErrorCodeFamilyTooManyParents, Message: "family cannot have more than two parents"},
+		},
+		{
+			name: "entity validation",
+			err:  fmt.Errorf("failed to create family: %w", errorswrapper.NewValidationError("invalid birth date", "BirthDate", nil)),
+			want: &model.UserError{Code: model.UserErrorCodeValidationError, Message: "invalid birth date", Field: []string{"BirthDate"}},
+		},
+		{
+			name: "domain validation",
+			err:  domainerrors.NewValidationError("a widowed family must have one parent", "Status", nil),
+			want: &model.UserError{Code: model.UserErrorCodeValidationError, Message: "a widowed family must have one parent", Field: []string{"Status"}},
+		},
+		{
+			name: "not found",
+			err:  fmt.Errorf("failed to add child: %w", errorswrapper.NewNotFoundError("Family", "family-1", nil)),
+			want: &model.UserError{Code: model.UserErrorCodeNotFound, Message: "Family with ID family-1 not found"},
+		},
+		{
+			name: "quarantined family",
+			err:  fmt.Errorf("failed to add child: %w", domainerrors.NewFamilyQuarantinedError("family family-1 is quarantined", nil)),
+			want: &model.UserError{Code: model.UserErrorCodeFamilyQuarantined, Message: "family family-1 is quarantined"},
+		},
+		{
+			name: "invalid argument",
+			err:  newInputError("deathDate", "invalid death date format (expected RFC3339)", errors.New("bad date")),
+			want: &model.UserError{Code: model.UserErrorCodeValidationError, Message: "invalid death date format (expected RFC3339): bad date", Field: []string{"deathDate"}},
+		},
+		{
+			name:    "database failure",
+			err:     errorswrapper.NewDatabaseError("failed to save family", "save", "families", errors.New("connection refused")),
+			wantErr: true,
+		},
+		{
+			name:    "domain error without a user error code",
+			err:     domainerrors.NewFamilyCreateFailedError("failed to create new family", errors.New("disk full")),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userErrors, err := toUserErrors(tt.err)
+			if tt.wantErr {
+				assert.Equal(t, tt.err, err, "unexpected failures are reported as GraphQL errors")
+				assert.Nil(t, userErrors)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []*model.UserError{tt.want}, userErrors)
+		})
+	}
+
+	userErrors, err := toUserErrors(nil)
+	require.NoError(t, err)
+	assert.NotNil(t, userErrors, "userErrors is non-null on success")
+	assert.Empty(t, userErrors)
+}
+
+func TestMutationResolver_PayloadMutations(t *testing.T) {
+	mockService := new(MockFamilyService)
+	mockMapper := NewMockFamilyMapper()
+	resolver := NewResolver(mockService, mockMapper, nil)
+	ctx := context.Background()
+
+	parentDTO := entity.ParentDTO{ID: "parent3"}
+	input := model.ParentInput{ID: "parent3"}
+	mockMapper.On("ToParentDTO", input).Return(parentDTO, nil)
+	mockService.On("AddParent", ctx, "family1", parentDTO).
+		Return(nil, domainerrors.NewFamilyTooManyParentsError("family cannot have more than two parents", nil))
+
+	payload, err := resolver.Mutation().AddParentV2(ctx, identification.ID("family1"), input)
+	require.NoError(t, err)
+	assert.Nil(t, payload.Family)
+	require.Len(t, payload.UserErrors, 1)
+	assert.Equal(t, model.UserErrorCodeFamilyTooManyParents, payload.UserErrors[0].Code)
+
+	// The deprecated mutation still reports the failure as an error
+	_, err = resolver.Mutation().AddParent(ctx, identification.ID("family1"), input)
+	assert.Error(t, err)
+
+	// Unexpected failures are still reported as errors
+	mockService.On("DeleteFamily", ctx, "family2", false).Return(errorswrapper.NewDatabaseError("failed to delete family", "delete", "families", errors.New("timeout")))
+	_, err = resolver.Mutation().DeleteFamilyV2(ctx, identification.ID("family2"), false)
+	assert.Error(t, err)
+
+	mockService.On("DeleteFamily", ctx, "family1", false).Return(nil)
+	deleted, err := resolver.Mutation().DeleteFamilyV2(ctx, identification.ID("family1"), false)
+	require.NoError(t, err)
+	assert.Empty(t, deleted.UserErrors)
+	require.NotNil(t, deleted.DeletedFamilyID)
+	assert.Equal(t, identification.ID("family1"), *deleted.DeletedFamilyID)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMutationResolver_MoveChild(t *testing.T) {
+	mockService := new(MockFamilyService)
+	mockMapper := NewMockFamilyMapper()
+	resolver := NewResolver(mockService, mockMapper, nil)
+	ctx := context.Background()
+
+	target := &entity.FamilyDTO{ID: "family2", ChildrenCount: 1}
+	mockService.On("MoveChild", ctx, "child1", "family1", "family2").Return(target, nil)
+	mockMapper.On("ToGraphQL", *target).Return(&model.Family{ID: identification.ID("family2")}, nil)
+
+	payload, err := resolver.Mutation().MoveChild(ctx, identification.ID("child1"), identification.ID("family1"), identification.ID("family2"))
+	require.NoError(t, err)
+	require.NotNil(t, payload.Family)
+	assert.Equal(t, identification.ID("family2"), payload.Family.ID)
+	assert.Empty(t, payload.UserErrors)
+
+	// Violated rules are reported in userErrors
+	mockService.On("MoveChild", ctx, "child1", "family1", "family1").
+		Return(nil, errorswrapper.NewValidationError("a child can only be moved to a different family", "ToFamilyID", nil))
+	payload, err = resolver.Mutation().MoveChild(ctx, identification.ID("child1"), identification.ID("family1"), identification.ID("family1"))
+	require.NoError(t, err)
+	assert.Nil(t, payload.Family)
+	require.Len(t, payload.UserErrors, 1)
+	assert.Equal(t, model.UserErrorCodeValidationError, payload.UserErrors[0].Code)
+	assert.Equal(t, []string{"ToFamilyID"}, payload.UserErrors[0].Field)
+
+	// Failed saves are reported as errors
+	mockService.On("MoveChild", ctx, "child1", "family1", "family3").
+		Return(nil, errorswrapper.NewDatabaseError("failed to save families after moving child", "save", "families", errors.New("timeout")))
+	_, err = resolver.Mutation().MoveChild(ctx, identification.ID("child1"), identification.ID("family1"), identification.ID("family3"))
+	assert.Error(t, err)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMutationResolver_UpdateMembers(t *testing.T) {
+	mockService := new(MockFamilyService)
+	mockMapper := NewMockFamilyMapper()
+	resolver := NewResolver(mockService, mockMapper, nil)
+	ctx := context.Background()
+
+	firstName := "Jane"
+	family := &entity.FamilyDTO{ID: "family1"}
+	mockService.On("UpdateParent", ctx, "family1", "parent1", entity.MemberDetails{FirstName: "Jane"}).Return(family, nil)
+	mockMapper.On("ToGraphQL", *family).Return(&model.Family{ID: identification.ID("family1")}, nil)
+
+	payload, err := resolver.Mutation().UpdateParent(ctx, identification.ID("family1"), identification.ID("parent1"), model.MemberDetailsInput{FirstName: &firstName})
+	require.NoError(t, err)
+	require.NotNil(t, payload.Family)
+	assert.Empty(t, payload.UserErrors)
+
+	// Missing members are reported in userErrors
+	mockService.On("UpdateChild", ctx, "family1", "child9", entity.MemberDetails{FirstName: "Jane"}).
+		Return(nil, errorswrapper.NewNotFoundError("Child", "child9", nil))
+	childPayload, err := resolver.Mutation().UpdateChild(ctx, identification.ID("family1"), identification.ID("child9"), model.MemberDetailsInput{FirstName: &firstName})
+	require.NoError(t, err)
+	assert.Nil(t, childPayload.Family)
+	require.Len(t, childPayload.UserErrors, 1)
+	assert.Equal(t, model.UserErrorCodeNotFound, childPayload.UserErrors[0].Code)
+
+	// Children already marked as deceased are reported in userErrors
+	deathDate, err := entity.NewDate(2020, time.January, 1)
+	require.NoError(t, err)
+	mockService.On("MarkChildDeceased", ctx, "family1", "child1", deathDate.Time()).
+		Return(nil, domainerrors.NewChildAlreadyDeceasedError("child is already marked as deceased", nil))
+	deceasedPayload, err := resolver.Mutation().MarkChildDeceased(ctx, identification.ID("family1"), identification.ID("child1"), deathDate)
+	require.NoError(t, err)
+	require.Len(t, deceasedPayload.UserErrors, 1)
+	assert.Equal(t, model.UserErrorCodeChildAlreadyDeceased, deceasedPayload.UserErrors[0].Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMutationResolver_Remarriage(t *testing.T) {
+	mockService := new(MockFamilyService)
+	mockMapper := NewMockFamilyMapper()
+	resolver := NewResolver(mockService, mockMapper, nil)
+	ctx := context.Background()
+
+	family := &entity.FamilyDTO{ID: "family1", Status: "MARRIED"}
+	mockService.On("MergeFamilies", ctx, "family1", "family2", []string{"child1"}).Return(family, nil)
+	mockMapper.On("ToGraphQL", *family).Return(&model.Family{ID: identification.ID("family1")}, nil)
+
+	payload, err := resolver.Mutation().MergeFamilies(ctx, identification.ID("family1"), identification.ID("family2"), []identification.ID{"child1"})
+	require.NoError(t, err)
+	require.NotNil(t, payload.Family)
+	assert.Empty(t, payload.UserErrors)
+
+	// Families that cannot marry are reported in userErrors
+	mockService.On("MergeFamilies", ctx, "family1", "family3", []string{}).
+		Return(nil, domainerrors.NewFamilyInvalidTransitionError("a spouse family must have exactly one parent", nil))
+	payload, err = resolver.Mutation().MergeFamilies(ctx, identification.ID("family1"), identification.ID("family3"), nil)
+	require.NoError(t, err)
+	assert.Nil(t, payload.Family)
+	require.Len(t, payload.UserErrors, 1)
+	assert.Equal(t, model.UserErrorCodeFamilyInvalidStatusTransition, payload.UserErrors[0].Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMutationResolver_QuarantineFamily(t *testing.T) {
+	mockService := new(MockFamilyService)
+	resolver := NewResolver(mockService, NewMockFamilyMapper(), nil)
+	ctx := context.Background()
+
+	at := time.Date(2025, time.March, 3, 14, 0, 0, 0, time.UTC)
+	q := &entity.Quarantine{FamilyID: "family1", Reason: "fraud", QuarantinedBy: "admin-1", QuarantinedAt: at}
+	mockService.On("QuarantineFamily", ctx, "family1", "fraud").Return(q, nil)
+
+	payload, err := resolver.Mutation().QuarantineFamily(ctx, identification.ID("family1"), "fraud")
+	require.NoError(t, err)
+	assert.Empty(t, payload.UserErrors)
+	adminID := identification.ID("admin-1")
+	assert.Equal(t, &model.Quarantine{FamilyID: "family1", Reason: "fraud", QuarantinedBy: &adminID, QuarantinedAt: "2025-03-03T14:00:00Z"}, payload.Quarantine)
+
+	// Removing a quarantine that does not exist is reported in userErrors
+	mockService.On("UnquarantineFamily", ctx, "family2").Return(nil, errorswrapper.NewNotFoundError("Quarantine", "family2", nil))
+	released, err := resolver.Mutation().UnquarantineFamily(ctx, identification.ID("family2"))
+	require.NoError(t, err)
+	assert.Nil(t, released.Quarantine)
+	require.Len(t, released.UserErrors, 1)
+	assert.Equal(t, model.UserErrorCodeNotFound, released.UserErrors[0].Code)
+
+	mockService.On("GetFamilyQuarantines", ctx).Return([]entity.Quarantine{*q}, nil)
+	quarantines, err := resolver.Query().FamilyQuarantines(ctx)
+	require.NoError(t, err)
+	require.Len(t, quarantines, 1)
+	assert.Equal(t, identification.ID("family1"), quarantines[0].FamilyID)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMutationResolver_RestoreAndPurgeFamilies(t *testing.T) {
+	mockService := new(MockFamilyService)
+	resolver := NewResolver(mockService, NewMockFamilyMapper(), nil)
+	ctx := context.Background()
+
+	// Restoring a family that is not deleted is reported in userErrors
+	mockService.On("RestoreFamily", ctx, "family2").Return(nil, errorswrapper.NewNotFoundError("Family", "family2", nil))
+	restored, err := resolver.Mutation().RestoreFamily(ctx, identification.ID("family2"))
+	require.NoError(t, err)
+	assert.Nil(t, restored.Family)
+	require.Len(t, restored.UserErrors, 1)
+	assert.Equal(t, model.UserErrorCodeNotFound, restored.UserErrors[0].Code)
+
+	mockService.On("PurgeDeletedFamilies", ctx, 720*time.Hour).Return([]string{"family1", "family3"}, nil)
+	purged, err := resolver.Mutation().PurgeDeletedFamilies(ctx, "720h")
+	require.NoError(t, err)
+	assert.Empty(t, purged.UserErrors)
+	assert.Equal(t, []identification.ID{"family1", "family3"}, purged.PurgedFamilyIds)
+
+	// A duration that cannot be parsed is reported in userErrors without a purge
+	purged, err = resolver.Mutation().PurgeDeletedFamilies(ctx, "30 days")
+	require.NoError(t, err)
+	assert.Empty(t, purged.PurgedFamilyIds)
+	require.Len(t, purged.UserErrors, 1)
+	assert.Equal(t, model.UserErrorCodeValidationError, purged.UserErrors[0].Code)
+	assert.Equal(t, []string{"olderThan"}, purged.UserErrors[0].Field)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package resolverlimit bounds the number of GraphQL resolvers that run at once.
+//
+// gqlgen resolves the fields of each element of a list concurrently, so a query for many
+// families runs a resolver per family and field at the same time. Resolvers are grouped
+// into field classes, each with its own limit shared by all requests: root fields of
+// queries, whose resolvers call the application services, and object fields with
+// resolvers, such as the fields of each family. Fields without resolvers only read the
+// resolved object and are not limited, and neither are subscriptions, which are long-lived.
+package resolverlimit
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/concurrency"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+)
+
+// Extension is a gqlgen handler extension that limits the concurrent resolvers of each field class
+type Extension struct {
+	root  *concurrency.Limiter
+	field *concurrency.Limiter
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = Extension{}
+
+// NewExtension creates a new Extension with the limits of the configuration
+func NewExtension(cfg config.ConcurrencyConfig) Extension {
+	return Extension{
+		root:  concurrency.NewLimiter(concurrency.ClassRoot, cfg.Root),
+		field: concurrency.NewLimiter(concurrency.ClassField, cfg.Field),
+	}
+}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "ResolverConcurrencyLimit"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptField waits for a slot of the field's class before running its resolver.
+// The slot is held only while the resolver runs; the fields of its result are resolved
+// after it is released, so nested fields cannot deadlock on the limits.
+func (e Extension) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	limiter := e.limiter(graphql.GetFieldContext(ctx))
+	if limiter == nil {
+		return next(ctx)
+	}
+
+	if err := limiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer limiter.Release()
+	return next(ctx)
+}
+
+// limiter returns the limiter of the field's class, or nil if the field is not limited
+func (e Extension) limiter(fc *graphql.FieldContext) *concurrency.Limiter {
+	if fc == nil || !fc.IsResolver {
+		return nil
+	}
+	switch fc.Object {
+	case "Query":
+		return e.root
+	case "Mutation", "Subscription":
+		// Mutations already run one at a time, and subscriptions would hold their slots
+		return nil
+	default:
+		return e.field
+	}
+}
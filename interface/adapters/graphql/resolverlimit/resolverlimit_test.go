@@ -0,0 +1,46 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package resolverlimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fieldContext(object string, isResolver bool) context.Context {
+	return graphql.WithFieldContext(context.Background(), &graphql.FieldContext{Object: object, IsResolver: isResolver})
+}
+
+func TestExtension_InterceptField(t *testing.T) {
+	ext := NewExtension(config.ConcurrencyConfig{Enabled: true, Root: 1, Field: 1})
+	resolved := func(ctx context.Context) (interface{}, error) { return "ok", nil }
+
+	// Hold the only slot of the field class
+	require.NoError(t, ext.field.Acquire(context.Background()))
+
+	// Fields of the class wait for a slot
+	ctx, cancel := context.WithTimeout(fieldContext("Family", true), 10*time.Millisecond)
+	defer cancel()
+	_, err := ext.InterceptField(ctx, resolved)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Other classes, and fields without resolvers, are not affected
+	res, err := ext.InterceptField(fieldContext("Query", true), resolved)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", res)
+	_, err = ext.InterceptField(fieldContext("Family", false), resolved)
+	require.NoError(t, err)
+	_, err = ext.InterceptField(fieldContext("Mutation", true), resolved)
+	require.NoError(t, err)
+
+	ext.field.Release()
+	res, err = ext.InterceptField(fieldContext("Family", true), resolved)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", res)
+}
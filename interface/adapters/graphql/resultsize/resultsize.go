@@ -0,0 +1,62 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package resultsize reports results that exceed the memory budget in the GraphQL API.
+//
+// Reads of many families, such as getAllFamilies, stop when their result would exceed the
+// memory budget of the service rather than exhaust its memory. This extension reports such
+// reads with the RESULT_TOO_LARGE error code, so that clients can tell them apart from a
+// failure and paginate or narrow the query instead of retrying it.
+package resultsize
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CodeResultTooLarge is the error code of a read whose result exceeds the memory budget
+const CodeResultTooLarge = domainerrors.ResultTooLargeCode
+
+// Extension is a gqlgen handler extension that reports results that exceed the memory
+// budget with their own error code
+type Extension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = Extension{}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "ResultSize"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptField reports a result that exceeds the memory budget with the
+// RESULT_TOO_LARGE error code
+func (Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	res, err := next(ctx)
+	if err == nil || !errors.Is(err, domainerrors.ErrResultTooLarge) {
+		return res, err
+	}
+
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		return res, err
+	}
+	tooLarge := &gqlerror.Error{
+		Message:    domainerrors.GetErrorMessage(err),
+		Extensions: map[string]interface{}{"code": CodeResultTooLarge},
+	}
+	if fc := graphql.GetFieldContext(ctx); fc != nil {
+		tooLarge.Path = fc.Path()
+	}
+	return res, tooLarge
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package resultsize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestExtension_InterceptField(t *testing.T) {
+	ext := Extension{}
+
+	_, err := ext.InterceptField(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, fmt.Errorf("failed to get all families: %w", domainerrors.NewResultTooLargeError("the result of GetAll is too large", nil))
+	})
+	var gqlErr *gqlerror.Error
+	require.ErrorAs(t, err, &gqlErr)
+	assert.Equal(t, CodeResultTooLarge, gqlErr.Extensions["code"])
+	assert.Equal(t, "the result of GetAll is too large", gqlErr.Message)
+
+	// Other errors and results are not changed
+	failure := errors.New("database unavailable")
+	_, err = ext.InterceptField(context.Background(), func(ctx context.Context) (any, error) { return nil, failure })
+	assert.Equal(t, failure, err)
+
+	res, err := ext.InterceptField(context.Background(), func(ctx context.Context) (any, error) { return "resolved", nil })
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", res)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package retryafter reports the failures that clients may retry after a delay in the
+// GraphQL API.
+//
+// An operation rejected by a rate limiter of the service, such as the limiter of the
+// database, fails with the THROTTLED error code, and one rejected because the circuit
+// breaker of a dependency is open fails with the UPSTREAM_UNAVAILABLE error code, rather
+// than as an internal error. Both carry the "retryAfterSeconds" error extension, computed
+// from the state of the limiter or circuit breaker, as the rateLimit response extension
+// does for per-client rate limits, so that clients back off for as long as needed.
+//
+// A mutation whose save was rejected because another operation changed the family since
+// it was read fails with the CONCURRENCY_CONFLICT error code, and may be retried at once,
+// after the shortest delay of one second.
+package retryafter
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Error codes of the failures that may be retried after a delay
+const (
+	// CodeThrottled is the error code of an operation rejected by a rate limiter
+	CodeThrottled = domainerrors.ThrottledCode
+
+	// CodeUpstreamUnavailable is the error code of an operation rejected by an open circuit
+	CodeUpstreamUnavailable = domainerrors.UpstreamUnavailableCode
+
+	// CodeConcurrencyConflict is the error code of a save of a family that was changed since it was read
+	CodeConcurrencyConflict = domainerrors.ConcurrencyConflictCode
+)
+
+// ExtensionKey is the error extension that carries the delay after which to retry
+const ExtensionKey = "retryAfterSeconds"
+
+// Seconds returns a delay after which to retry in whole seconds, rounded up, and at least
+// one second, so that clients never retry at once
+func Seconds(retryAfter time.Duration) int {
+	if seconds := int(math.Ceil(retryAfter.Seconds())); seconds > 1 {
+		return seconds
+	}
+	return 1
+}
+
+// Extension is a gqlgen handler extension that reports the failures that may be retried
+// after a delay with their own error code and the delay
+type Extension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = Extension{}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "RetryAfter"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptField reports a failure that may be retried after a delay with the THROTTLED,
+// UPSTREAM_UNAVAILABLE, or CONCURRENCY_CONFLICT error code and the retryAfterSeconds extension
+func (Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	res, err := next(ctx)
+	if err == nil {
+		return res, err
+	}
+	code, retryAfter, ok := domainerrors.RetryAfter(err)
+	if !ok {
+		return res, err
+	}
+
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		return res, err
+	}
+	retryable := &gqlerror.Error{
+		Message: domainerrors.GetErrorMessage(err),
+		Extensions: map[string]interface{}{
+			"code":       code,
+			ExtensionKey: Seconds(retryAfter),
+		},
+	}
+	if fc := graphql.GetFieldContext(ctx); fc != nil {
+		retryable.Path = fc.Path()
+	}
+	return res, retryable
+}
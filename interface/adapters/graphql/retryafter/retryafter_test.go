@@ -0,0 +1,65 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package retryafter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	serviceerrors "github.com/abitofhelp/servicelib/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestExtension_InterceptField(t *testing.T) {
+	ext := Extension{}
+	testCases := []struct {
+		name    string
+		err     error
+		code    string
+		message string
+		seconds int
+	}{
+		{"throttled", domainerrors.NewThrottledError("rate limit exceeded for mongo", 100*time.Millisecond, nil), CodeThrottled, "rate limit exceeded for mongo", 1},
+		{"upstream unavailable", domainerrors.NewUpstreamUnavailableError("circuit breaker mongo is open", 4200*time.Millisecond, nil), CodeUpstreamUnavailable, "circuit breaker mongo is open", 5},
+		{"concurrency conflict", domainerrors.NewConcurrencyError("family was changed by another operation", nil), CodeConcurrencyConflict, "family was changed by another operation", 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// The failure is found below the errors of the repository and the service
+			wrapped := serviceerrors.NewApplicationError(serviceerrors.DatabaseErrorCode, "failed to get family",
+				serviceerrors.NewDatabaseError("circuit breaker is open", "query", "families", tc.err))
+			_, err := ext.InterceptField(context.Background(), func(ctx context.Context) (any, error) {
+				return nil, fmt.Errorf("failed to get family: %w", wrapped)
+			})
+
+			var gqlErr *gqlerror.Error
+			require.ErrorAs(t, err, &gqlErr)
+			assert.Equal(t, tc.code, gqlErr.Extensions["code"])
+			assert.Equal(t, tc.seconds, gqlErr.Extensions[ExtensionKey])
+			assert.Equal(t, tc.message, gqlErr.Message)
+		})
+	}
+
+	// Other errors and results are not changed
+	failure := errors.New("database unavailable")
+	_, err := ext.InterceptField(context.Background(), func(ctx context.Context) (any, error) { return nil, failure })
+	assert.Equal(t, failure, err)
+
+	res, err := ext.InterceptField(context.Background(), func(ctx context.Context) (any, error) { return "resolved", nil })
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", res)
+}
+
+func TestSeconds(t *testing.T) {
+	assert.Equal(t, 1, Seconds(0))
+	assert.Equal(t, 1, Seconds(100*time.Millisecond))
+	assert.Equal(t, 2, Seconds(1500*time.Millisecond))
+	assert.Equal(t, 30, Seconds(30*time.Second))
+}
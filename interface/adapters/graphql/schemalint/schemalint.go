@@ -0,0 +1,185 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package schemalint checks the GraphQL schema against the naming and nullability
+// conventions of the API.
+//
+// The conventions are:
+//   - Type names are PascalCase, and input types end in "Input"
+//   - Field and argument names are camelCase
+//   - Enum values are UPPER_SNAKE_CASE
+//   - Lists in output types are never null and never contain nulls: [T!]!
+//   - The id field of an output type is non-null
+//   - The input argument of a mutation is non-null
+//   - Types and fields have descriptions
+//
+// A type or field that cannot follow a convention, usually because changing it would
+// break clients, is exempted with the lintIgnore directive, which names the rules and
+// the reason:
+//
+//	field: [String!] @lintIgnore(rules: ["list-nullability"], reason: "null when unknown")
+//
+// The rules run before code generation, so that a schema that breaks them is caught
+// before resolvers are written against it.
+package schemalint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// DirectiveLintIgnore is the name of the directive that exempts a type or field from rules
+const DirectiveLintIgnore = "lintIgnore"
+
+// Rule names
+const (
+	RuleTypeName         = "type-name"
+	RuleInputName        = "input-name"
+	RuleFieldName        = "field-name"
+	RuleArgumentName     = "argument-name"
+	RuleEnumValue        = "enum-value"
+	RuleListNullability  = "list-nullability"
+	RuleIDNullability    = "id-nullability"
+	RuleInputNullability = "input-nullability"
+	RuleDescription      = "description"
+)
+
+var (
+	pascalCase     = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	camelCase      = regexp.MustCompile(`^[a-z][A-Za-z0-9]*$`)
+	upperSnakeCase = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+)
+
+// Problem is a violation of a convention
+type Problem struct {
+	Rule     string // Name of the violated rule
+	Location string // Where the problem is, such as "Family.parents" or "Mutation.createFamily(input)"
+	Message  string // Description of the problem
+}
+
+// String returns a description of the problem
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s [%s]", p.Location, p.Message, p.Rule)
+}
+
+// Lint checks a schema against the conventions and returns the problems, sorted by
+// location. Built-in and introspection types are not checked.
+func Lint(schema *ast.Schema) []Problem {
+	var problems []Problem
+	var exempt []string
+	report := func(rule, location, format string, args ...interface{}) {
+		for _, r := range exempt {
+			if r == rule {
+				return
+			}
+		}
+		problems = append(problems, Problem{Rule: rule, Location: location, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, def := range schema.Types {
+		if def.BuiltIn || strings.HasPrefix(def.Name, "__") {
+			continue
+		}
+		typeExempt := ignoredRules(def.Directives)
+		exempt = typeExempt
+
+		if !pascalCase.MatchString(def.Name) {
+			report(RuleTypeName, def.Name, "type name must be PascalCase")
+		}
+		if def.Kind == ast.InputObject && !strings.HasSuffix(def.Name, "Input") {
+			report(RuleInputName, def.Name, "input type name must end in \"Input\"")
+		}
+		if strings.TrimSpace(def.Description) == "" {
+			report(RuleDescription, def.Name, "type must have a description")
+		}
+
+		for _, value := range def.EnumValues {
+			if !upperSnakeCase.MatchString(value.Name) {
+				report(RuleEnumValue, def.Name+"."+value.Name, "enum value must be UPPER_SNAKE_CASE")
+			}
+		}
+
+		output := def.Kind == ast.Object || def.Kind == ast.Interface
+		for _, field := range def.Fields {
+			if strings.HasPrefix(field.Name, "__") {
+				continue
+			}
+			location := def.Name + "." + field.Name
+			exempt = append(typeExempt[:len(typeExempt):len(typeExempt)], ignoredRules(field.Directives)...)
+
+			if !camelCase.MatchString(field.Name) {
+				report(RuleFieldName, location, "field name must be camelCase")
+			}
+			if strings.TrimSpace(field.Description) == "" {
+				report(RuleDescription, location, "field must have a description")
+			}
+			if output && !listNullabilityOK(field.Type) {
+				report(RuleListNullability, location, "list must be non-null with non-null elements, as [%s!]!", baseName(field.Type))
+			}
+			if output && field.Name == "id" && !field.Type.NonNull {
+				report(RuleIDNullability, location, "id must be non-null")
+			}
+
+			for _, arg := range field.Arguments {
+				argLocation := fmt.Sprintf("%s(%s)", location, arg.Name)
+				if !camelCase.MatchString(arg.Name) {
+					report(RuleArgumentName, argLocation, "argument name must be camelCase")
+				}
+				if isMutation(schema, def) && arg.Name == "input" && !arg.Type.NonNull {
+					report(RuleInputNullability, argLocation, "input argument of a mutation must be non-null")
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Location != problems[j].Location {
+			return problems[i].Location < problems[j].Location
+		}
+		return problems[i].Rule < problems[j].Rule
+	})
+	return problems
+}
+
+// ignoredRules returns the rules named by the lintIgnore directive in a directive list
+func ignoredRules(directives ast.DirectiveList) []string {
+	var rules []string
+	for _, directive := range directives.ForNames(DirectiveLintIgnore) {
+		arg := directive.Arguments.ForName("rules")
+		if arg == nil || arg.Value == nil {
+			continue
+		}
+		for _, child := range arg.Value.Children {
+			rules = append(rules, child.Value.Raw)
+		}
+	}
+	return rules
+}
+
+// listNullabilityOK reports whether a type is not a list, or is a non-null list of
+// non-null elements at every level
+func listNullabilityOK(t *ast.Type) bool {
+	for t.Elem != nil {
+		if !t.NonNull || !t.Elem.NonNull {
+			return false
+		}
+		t = t.Elem
+	}
+	return true
+}
+
+// baseName returns the name of the named type at the core of a type
+func baseName(t *ast.Type) string {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return t.NamedType
+}
+
+// isMutation reports whether a type is the mutation root type of a schema
+func isMutation(schema *ast.Schema, def *ast.Definition) bool {
+	return schema.Mutation != nil && schema.Mutation.Name == def.Name
+}
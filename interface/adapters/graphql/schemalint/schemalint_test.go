@@ -0,0 +1,174 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package schemalint
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const lintIgnoreDirective = `
+directive @lintIgnore(rules: [String!]!, reason: String!) on OBJECT | INPUT_OBJECT | FIELD_DEFINITION | INPUT_FIELD_DEFINITION | ENUM
+`
+
+func loadSchema(t *testing.T, input string) *ast.Schema {
+	t.Helper()
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: "test.graphql", Input: lintIgnoreDirective + input})
+	require.NoError(t, err)
+	return schema
+}
+
+func rules(problems []Problem) map[string][]string {
+	found := make(map[string][]string)
+	for _, p := range problems {
+		found[p.Location] = append(found[p.Location], p.Rule)
+	}
+	return found
+}
+
+func TestLint_Clean(t *testing.T) {
+	schema := loadSchema(t, `
+"A family"
+type Family {
+  "The ID"
+  id: ID!
+  "The members"
+  members: [String!]!
+}
+
+"The status of a family"
+enum Status { ACTIVE, IN_REVIEW }
+
+"Input for creating a family"
+input FamilyInput {
+  "The name"
+  name: String
+}
+
+"Queries"
+type Query {
+  "Gets a family"
+  getFamily(familyId: ID!): Family
+}
+
+"Mutations"
+type Mutation {
+  "Creates a family"
+  createFamily(input: FamilyInput!): Family!
+}
+`)
+
+	assert.Empty(t, Lint(schema))
+}
+
+func TestLint_Violations(t *testing.T) {
+	schema := loadSchema(t, `
+"A family"
+type family_record {
+  id: ID
+  "The members"
+  Members: [String]
+}
+
+"Status"
+enum Status { active }
+
+"Filter"
+input FamilyFilter {
+  "Name"
+  name: String
+}
+
+"Queries"
+type Query {
+  "Gets a family"
+  getFamily(family_id: ID!): family_record
+}
+
+"Mutations"
+type Mutation {
+  "Creates a family"
+  createFamily(input: FamilyFilter): family_record
+}
+`)
+
+	found := rules(Lint(schema))
+	assert.Equal(t, []string{RuleTypeName}, found["family_record"])
+	assert.ElementsMatch(t, []string{RuleDescription, RuleIDNullability}, found["family_record.id"])
+	assert.ElementsMatch(t, []string{RuleFieldName, RuleListNullability}, found["family_record.Members"])
+	assert.Equal(t, []string{RuleEnumValue}, found["Status.active"])
+	assert.Equal(t, []string{RuleInputName}, found["FamilyFilter"])
+	assert.Equal(t, []string{RuleArgumentName}, found["Query.getFamily(family_id)"])
+	assert.Equal(t, []string{RuleInputNullability}, found["Mutation.createFamily(input)"])
+}
+
+func TestLint_ListNullability(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		want  bool
+	}{
+		{name: "non-null list of non-null", field: "[String!]!", want: true},
+		{name: "nullable list", field: "[String!]", want: false},
+		{name: "nullable elements", field: "[String]!", want: false},
+		{name: "nested nullable elements", field: "[[String]!]!", want: false},
+		{name: "nested non-null", field: "[[String!]!]!", want: true},
+		{name: "not a list", field: "String", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := loadSchema(t, `
+"Queries"
+type Query {
+  "Values"
+  values: `+tt.field+`
+}
+`)
+			problems := Lint(schema)
+			assert.Equal(t, tt.want, len(problems) == 0, "problems: %v", problems)
+		})
+	}
+}
+
+func TestLint_LintIgnore(t *testing.T) {
+	schema := loadSchema(t, `
+"Queries"
+type Query {
+  "Values"
+  values: [String!] @lintIgnore(rules: ["list-nullability"], reason: "null when unknown")
+  "Other values"
+  others: [String!] @lintIgnore(rules: ["description"], reason: "wrong rule")
+}
+
+"Filter"
+input Filter @lintIgnore(rules: ["input-name"], reason: "existing clients") {
+  "Name"
+  name: String
+}
+`)
+
+	problems := Lint(schema)
+	require.Len(t, problems, 1)
+	assert.Equal(t, Problem{
+		Rule:     RuleListNullability,
+		Location: "Query.others",
+		Message:  "list must be non-null with non-null elements, as [String!]!",
+	}, problems[0])
+	assert.Equal(t, "Query.others: list must be non-null with non-null elements, as [String!]! [list-nullability]", problems[0].String())
+}
+
+func TestLint_Schema(t *testing.T) {
+	input, err := os.ReadFile("../schema.graphql")
+	require.NoError(t, err)
+
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: string(input)})
+	require.NoError(t, err)
+
+	assert.Empty(t, Lint(schema), "the API schema must follow the conventions")
+}
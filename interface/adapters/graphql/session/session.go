@@ -0,0 +1,41 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package session makes each GraphQL operation session-consistent: reads see the writes
+// made earlier in the same operation.
+//
+// A mutation that changes a family and then selects the family in its payload, or a
+// document whose mutations read families changed by earlier mutations, would otherwise
+// be served from caches that may not yet reflect the writes. Each operation tracks the
+// families it writes, and the application services read those families uncached.
+// Writes are tracked per operation only, so other requests are not affected.
+package session
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/core/application/consistency"
+)
+
+// Extension is a gqlgen handler extension that tracks the writes of each operation
+type Extension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = Extension{}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "SessionConsistency"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation starts tracking the writes of the operation
+func (Extension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	return next(consistency.Track(ctx))
+}
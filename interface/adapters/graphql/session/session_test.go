@@ -0,0 +1,32 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/core/application/consistency"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtension_InterceptOperation(t *testing.T) {
+	ext := Extension{}
+
+	// Writes are visible to later reads of the same operation
+	var written bool
+	ext.InterceptOperation(context.Background(), func(ctx context.Context) graphql.ResponseHandler {
+		consistency.MarkWritten(ctx, "family-1")
+		written = consistency.Written(ctx, "family-1")
+		return graphql.OneShot(&graphql.Response{})
+	})
+	assert.True(t, written)
+
+	// Each operation starts without writes
+	ext.InterceptOperation(context.Background(), func(ctx context.Context) graphql.ResponseHandler {
+		written = consistency.Written(ctx, "family-1")
+		return graphql.OneShot(&graphql.Response{})
+	})
+	assert.False(t, written)
+}
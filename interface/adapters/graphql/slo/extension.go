@@ -0,0 +1,100 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package slo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// resultKey is the context key for the per-operation result holder
+type resultKey struct{}
+
+// operationResult accumulates the classification of resolver errors for a single operation.
+// Resolvers may run concurrently, so access is synchronized.
+type operationResult struct {
+	mu      sync.Mutex
+	outcome Outcome
+}
+
+func (r *operationResult) record(outcome Outcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outcome = worse(r.outcome, outcome)
+}
+
+func (r *operationResult) get() Outcome {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.outcome
+}
+
+// Extension is a gqlgen handler extension that classifies each GraphQL operation
+// result and records it in a Tracker.
+//
+// Resolver errors are classified before the error presenter converts them into
+// GraphQL errors, because the presenter hides the original error type. Errors
+// that never reach a resolver (parse and validation failures) are classified
+// by their GraphQL error code.
+type Extension struct {
+	tracker *Tracker
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Extension{}
+
+// NewExtension creates a new Extension that records outcomes in the given tracker.
+func NewExtension(tracker *Tracker) Extension {
+	return Extension{tracker: tracker}
+}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "SLOTracking"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse classifies the operation response and records it in the tracker
+func (e Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	result := &operationResult{outcome: OutcomeSuccess}
+	resp := next(context.WithValue(ctx, resultKey{}, result))
+
+	outcome := result.get()
+	if resp != nil {
+		for _, gqlErr := range resp.Errors {
+			// Errors not returned by a resolver (e.g. recovered panics) are
+			// classified by their GraphQL error code
+			if outcome == OutcomeSuccess {
+				outcome = worse(outcome, classifyCode(gqlErr))
+			}
+		}
+	}
+
+	operation := ""
+	if graphql.HasOperationContext(ctx) {
+		operation = graphql.GetOperationContext(ctx).OperationName
+	}
+	e.tracker.Record(operation, outcome)
+
+	return resp
+}
+
+// InterceptField classifies resolver errors before the error presenter rewrites them
+func (Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	res, err := next(ctx)
+	if err != nil {
+		if result, ok := ctx.Value(resultKey{}).(*operationResult); ok {
+			result.record(Classify(err))
+		}
+	}
+	return res, err
+}
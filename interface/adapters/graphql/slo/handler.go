@@ -0,0 +1,47 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package slo
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// DefaultWindows are the rolling windows reported by the debug handler
+var DefaultWindows = []time.Duration{5 * time.Minute, time.Hour, 6 * time.Hour, 24 * time.Hour}
+
+// Report is the JSON document served by the SLO debug endpoint
+type Report struct {
+	Objective float64         `json:"objective"`
+	Windows   []Window        `json:"windows"`
+	Alerts    []BurnRateAlert `json:"alerts"`
+}
+
+// Report computes compliance over the default windows and evaluates the default burn rate alerts.
+func (t *Tracker) Report() Report {
+	report := Report{Objective: t.objective}
+	for _, window := range DefaultWindows {
+		report.Windows = append(report.Windows, t.Compliance(window))
+	}
+	for _, alert := range DefaultBurnRateAlerts() {
+		report.Alerts = append(report.Alerts, t.Evaluate(alert))
+	}
+	return report
+}
+
+// NewHandler returns an HTTP handler that reports current SLO compliance as JSON.
+func NewHandler(tracker *Tracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(tracker.Report()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package slo provides service level objective (SLO) instrumentation for the GraphQL API.
+//
+// Every GraphQL operation is classified into one of three outcomes:
+//   - success: the operation completed without errors
+//   - user_error: the operation failed because of the client (invalid input, not found, unauthorized, ...)
+//   - system_error: the operation failed because of the service (database failure, timeout, panic, ...)
+//
+// Only system errors consume the error budget. Outcomes are exported as Prometheus
+// counters and kept in a rolling-window Tracker that computes compliance and
+// burn rates, which are exposed through a debug HTTP endpoint.
+package slo
+
+import (
+	"context"
+	"errors"
+
+	serviceerrors "github.com/abitofhelp/servicelib/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Outcome is the SLO classification of a GraphQL operation result.
+type Outcome string
+
+const (
+	// OutcomeSuccess indicates the operation completed without errors
+	OutcomeSuccess Outcome = "success"
+
+	// OutcomeUserError indicates the operation failed because of the client
+	OutcomeUserError Outcome = "user_error"
+
+	// OutcomeSystemError indicates the operation failed because of the service
+	OutcomeSystemError Outcome = "system_error"
+)
+
+// userErrorCodes are GraphQL error extension codes that are attributed to the client
+var userErrorCodes = map[string]bool{
+	"VALIDATION_ERROR":              true,
+	"GRAPHQL_VALIDATION_FAILED":     true,
+	"GRAPHQL_PARSE_FAILED":          true,
+	"NOT_FOUND":                     true,
+	"UNAUTHORIZED":                  true,
+	"UNAUTHENTICATED":               true,
+	"FORBIDDEN":                     true,
+	"CANCELED":                      true,
+	"CLIENT_DISCONNECTED":           true,
+	"COMPLEXITY_LIMIT_EXCEEDED":     true,
+	"PERSISTED_QUERY_NOT_FOUND":     true,
+	"PERSISTED_QUERY_NOT_SUPPORTED": true,
+	"BAD_USER_INPUT":                true,
+	"LEGACY_MUTATION_DISABLED":      true,
+	"UNKNOWN_INPUT":                 true,
+}
+
+// OperationResultsTotal counts GraphQL operation results by operation and outcome
+var OperationResultsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "graphql_operation_results_total",
+		Help: "Total number of GraphQL operation results by SLO outcome",
+	},
+	[]string{"operation", "outcome"},
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(OperationResultsTotal)
+}
+
+// Classify returns the SLO outcome for an error returned by a resolver.
+//
+// Errors caused by the client (validation, business rule violations, missing
+// resources, authentication and authorization failures, client cancellation)
+// are user errors. Everything else is a system error.
+func Classify(err error) Outcome {
+	if err == nil {
+		return OutcomeSuccess
+	}
+
+	switch {
+	case serviceerrors.IsNotFoundError(err),
+		serviceerrors.IsValidationError(err),
+		serviceerrors.IsBusinessRuleError(err),
+		serviceerrors.IsAuthenticationError(err),
+		serviceerrors.IsAuthorizationError(err),
+		errors.Is(err, context.Canceled):
+		return OutcomeUserError
+	}
+
+	var validationErrs *serviceerrors.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return OutcomeUserError
+	}
+
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		if gqlErr.Err != nil && gqlErr.Err != err {
+			return Classify(gqlErr.Err)
+		}
+		return classifyCode(gqlErr)
+	}
+
+	return OutcomeSystemError
+}
+
+// classifyCode classifies a GraphQL error by its extension code
+func classifyCode(err *gqlerror.Error) Outcome {
+	if code, ok := err.Extensions["code"].(string); ok && userErrorCodes[code] {
+		return OutcomeUserError
+	}
+	return OutcomeSystemError
+}
+
+// worse returns the more severe of two outcomes
+func worse(a, b Outcome) Outcome {
+	rank := func(o Outcome) int {
+		switch o {
+		case OutcomeSystemError:
+			return 2
+		case OutcomeUserError:
+			return 1
+		default:
+			return 0
+		}
+	}
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package slo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	serviceerrors "github.com/abitofhelp/servicelib/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Outcome
+	}{
+		{"nil", nil, OutcomeSuccess},
+		{"not found", serviceerrors.NewNotFoundError("Family", "123", nil), OutcomeUserError},
+		{"validation", serviceerrors.NewValidationError("invalid", "name", nil), OutcomeUserError},
+		{"canceled", fmt.Errorf("query: %w", context.Canceled), OutcomeUserError},
+		{"database", serviceerrors.NewDatabaseError("failed", "select", "families", nil), OutcomeSystemError},
+		{"plain", errors.New("boom"), OutcomeSystemError},
+		{"gql validation code", &gqlerror.Error{Message: "bad", Extensions: map[string]interface{}{"code": "GRAPHQL_VALIDATION_FAILED"}}, OutcomeUserError},
+		{"gql internal code", &gqlerror.Error{Message: "bad", Extensions: map[string]interface{}{"code": "INTERNAL_ERROR"}}, OutcomeSystemError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Classify(tt.err))
+		})
+	}
+}
+
+func TestTracker_Compliance(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker(0.99, time.Minute, time.Hour)
+	tracker.now = func() time.Time { return now }
+
+	// A system error 30 minutes ago falls outside the 5 minute window
+	now = now.Add(-30 * time.Minute)
+	tracker.Record("getFamily", OutcomeSystemError)
+	now = now.Add(30 * time.Minute)
+
+	for i := 0; i < 97; i++ {
+		tracker.Record("getFamily", OutcomeSuccess)
+	}
+	tracker.Record("getFamily", OutcomeUserError)
+	tracker.Record("getFamily", OutcomeSystemError)
+	tracker.Record("getFamily", OutcomeSystemError)
+
+	short := tracker.Compliance(5 * time.Minute)
+	assert.Equal(t, uint64(100), short.Total)
+	assert.Equal(t, uint64(2), short.SystemErrors)
+	assert.InDelta(t, 0.98, short.SLI, 1e-9)
+	assert.InDelta(t, 2.0, short.BurnRate, 1e-9)
+	assert.False(t, short.Compliant)
+
+	long := tracker.Compliance(time.Hour)
+	assert.Equal(t, uint64(101), long.Total)
+	assert.Equal(t, uint64(3), long.SystemErrors)
+}
+
+func TestTracker_Evaluate(t *testing.T) {
+	tracker := NewTracker(0.999, time.Minute, 24*time.Hour)
+	for i := 0; i < 90; i++ {
+		tracker.Record("getFamily", OutcomeSuccess)
+	}
+	for i := 0; i < 10; i++ {
+		tracker.Record("getFamily", OutcomeSystemError)
+	}
+
+	alert := tracker.Evaluate(DefaultBurnRateAlerts()[0])
+	assert.True(t, alert.Firing)
+}
+
+func TestBurnRate(t *testing.T) {
+	assert.InDelta(t, 1.0, BurnRate(0.001, 0.999), 1e-9)
+	assert.InDelta(t, 14.4, BurnRate(0.0144, 0.999), 1e-9)
+	assert.Equal(t, 0.0, BurnRate(0, 1))
+}
+
+func TestNewHandler(t *testing.T) {
+	tracker := NewTracker(0.999, time.Minute, 24*time.Hour)
+	tracker.Record("getFamily", OutcomeSuccess)
+
+	rec := httptest.NewRecorder()
+	NewHandler(tracker).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slo", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"objective":0.999`)
+	assert.Contains(t, rec.Body.String(), `"window":"5m0s"`)
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package slo
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// bucket holds outcome counts for a single time slice of the rolling window
+type bucket struct {
+	start        time.Time
+	success      uint64
+	userErrors   uint64
+	systemErrors uint64
+}
+
+// Window summarizes SLO compliance over a rolling time window.
+type Window struct {
+	Duration        time.Duration `json:"-"`
+	Name            string        `json:"window"`
+	Total           uint64        `json:"total"`
+	Success         uint64        `json:"success"`
+	UserErrors      uint64        `json:"user_errors"`
+	SystemErrors    uint64        `json:"system_errors"`
+	SLI             float64       `json:"sli"`
+	ErrorRatio      float64       `json:"error_ratio"`
+	BurnRate        float64       `json:"burn_rate"`
+	BudgetRemaining float64       `json:"error_budget_remaining"`
+	Compliant       bool          `json:"compliant"`
+}
+
+// Tracker records GraphQL operation outcomes in fixed-size time buckets and
+// computes compliance over rolling windows up to its retention period.
+type Tracker struct {
+	mu         sync.Mutex
+	objective  float64
+	resolution time.Duration
+	buckets    []bucket
+	now        func() time.Time
+}
+
+// NewTracker creates a new Tracker.
+//
+// Parameters:
+//   - objective: The target ratio of good operations, e.g. 0.999 for "three nines"
+//   - resolution: The size of each time bucket; windows are accurate to this granularity
+//   - retention: The longest window that can be queried
+//
+// Returns:
+//   - A new Tracker with no recorded operations
+func NewTracker(objective float64, resolution, retention time.Duration) *Tracker {
+	if resolution <= 0 {
+		resolution = time.Minute
+	}
+	if retention < resolution {
+		retention = resolution
+	}
+	return &Tracker{
+		objective:  objective,
+		resolution: resolution,
+		buckets:    make([]bucket, int(retention/resolution)),
+		now:        time.Now,
+	}
+}
+
+// Objective returns the tracker's SLO objective.
+func (t *Tracker) Objective() float64 {
+	return t.objective
+}
+
+// Record records the outcome of a single GraphQL operation and exports it as a Prometheus counter.
+func (t *Tracker) Record(operation string, outcome Outcome) {
+	if operation == "" {
+		operation = "anonymous"
+	}
+	OperationResultsTotal.WithLabelValues(operation, string(outcome)).Inc()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := t.current()
+	switch outcome {
+	case OutcomeSuccess:
+		b.success++
+	case OutcomeUserError:
+		b.userErrors++
+	default:
+		b.systemErrors++
+	}
+}
+
+// current returns the bucket for the current time slice, resetting it if it is stale.
+// The caller must hold t.mu.
+func (t *Tracker) current() *bucket {
+	start := t.now().Truncate(t.resolution)
+	idx := int(start.UnixNano()/int64(t.resolution)) % len(t.buckets)
+	b := &t.buckets[idx]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	return b
+}
+
+// Compliance returns the SLO compliance over the given rolling window.
+//
+// User errors count as good events because they do not reflect on the
+// service's reliability; only system errors consume the error budget.
+func (t *Tracker) Compliance(window time.Duration) Window {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := t.now().Add(-window)
+	w := Window{Duration: window, Name: window.String()}
+	for _, b := range t.buckets {
+		if b.start.IsZero() || !b.start.Add(t.resolution).After(cutoff) {
+			continue
+		}
+		w.Success += b.success
+		w.UserErrors += b.userErrors
+		w.SystemErrors += b.systemErrors
+	}
+	w.Total = w.Success + w.UserErrors + w.SystemErrors
+
+	w.SLI = 1
+	if w.Total > 0 {
+		w.ErrorRatio = float64(w.SystemErrors) / float64(w.Total)
+		w.SLI = 1 - w.ErrorRatio
+	}
+	w.BurnRate = BurnRate(w.ErrorRatio, t.objective)
+	w.BudgetRemaining = 1 - w.BurnRate
+	w.Compliant = w.SLI >= t.objective
+	return w
+}
+
+// BurnRate returns how fast the error budget is being consumed.
+//
+// A burn rate of 1 means the budget will be exactly used up over the SLO
+// period; a burn rate of 14.4 sustained for one hour consumes 2% of a 30-day budget.
+func BurnRate(errorRatio, objective float64) float64 {
+	budget := 1 - objective
+	if budget <= 0 {
+		// A 100% objective has no budget; any error exhausts it immediately
+		if errorRatio > 0 {
+			return math.MaxFloat64
+		}
+		return 0
+	}
+	return errorRatio / budget
+}
+
+// BurnRateAlert describes a multi-window burn rate alert condition.
+//
+// Following the multi-window, multi-burn-rate approach, an alert fires only
+// when both the long window and the short window exceed the threshold: the
+// long window shows the burn is significant, the short window shows it is
+// still happening.
+type BurnRateAlert struct {
+	Name        string        `json:"name"`
+	LongWindow  time.Duration `json:"-"`
+	ShortWindow time.Duration `json:"-"`
+	Threshold   float64       `json:"threshold"`
+	Firing      bool          `json:"firing"`
+}
+
+// DefaultBurnRateAlerts returns the standard page and ticket alert conditions for a 30-day SLO.
+func DefaultBurnRateAlerts() []BurnRateAlert {
+	return []BurnRateAlert{
+		{Name: "page-fast", LongWindow: time.Hour, ShortWindow: 5 * time.Minute, Threshold: 14.4},
+		{Name: "page-slow", LongWindow: 6 * time.Hour, ShortWindow: 30 * time.Minute, Threshold: 6},
+		{Name: "ticket", LongWindow: 24 * time.Hour, ShortWindow: 2 * time.Hour, Threshold: 3},
+	}
+}
+
+// Evaluate reports whether the alert condition is currently met.
+func (t *Tracker) Evaluate(alert BurnRateAlert) BurnRateAlert {
+	long := t.Compliance(alert.LongWindow)
+	short := t.Compliance(alert.ShortWindow)
+	alert.Firing = long.BurnRate >= alert.Threshold && short.BurnRate >= alert.Threshold
+	return alert
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package staleness tells clients when a query was answered from snapshots because the
+// database was unavailable.
+//
+// Queries accept stale reads: while the circuit breaker of the database is open, the
+// families they read are served from the last known good snapshots kept by the stale read
+// repository. The response of such a query carries the "staleness" response extension,
+// with the time of the oldest snapshot served and its age, so that clients can show or
+// discard the data. Mutations never accept stale reads, so they fail fast while the
+// database is unavailable.
+package staleness
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/staleread"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// ExtensionKey is the GraphQL response extension that flags stale responses
+const ExtensionKey = "staleness"
+
+// reason is the reason of a stale response, as included in the response extension
+const reason = "The database is unavailable; data was served from snapshots"
+
+// Extension is a gqlgen handler extension that lets queries be served from snapshots and
+// flags the responses that were
+type Extension struct {
+	now func() time.Time
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = Extension{}
+
+// NewExtension creates an extension that lets queries be served from snapshots
+func NewExtension() Extension {
+	return Extension{now: time.Now}
+}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "StaleReads"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse lets the reads of queries be served from snapshots and adds the
+// staleness of the snapshots served to the response extensions
+func (e Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+	if oc.Operation == nil || oc.Operation.Operation != ast.Query {
+		return next(ctx)
+	}
+
+	ctx, tracker := staleread.WithTracker(ctx)
+	resp := next(ctx)
+	reads, oldest := tracker.Stale()
+	if resp == nil || reads == 0 {
+		return resp
+	}
+
+	if resp.Extensions == nil {
+		resp.Extensions = make(map[string]interface{})
+	}
+	resp.Extensions[ExtensionKey] = map[string]interface{}{
+		"stale":      true,
+		"reason":     reason,
+		"staleReads": reads,
+		"asOf":       oldest.UTC().Format(time.RFC3339),
+		"ageSeconds": int(e.now().Sub(oldest).Seconds()),
+	}
+	return resp
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package staleness
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/staleread"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.uber.org/zap"
+)
+
+const familyID = "0d8f4f5e-1c1a-4b9e-9a51-6b2d7c1e0a01"
+
+// flakyRepository serves GetByID until its circuit is opened; it implements no other operation
+type flakyRepository struct {
+	ports.FamilyRepository
+	family *entity.Family
+	open   bool
+}
+
+func (r *flakyRepository) GetByID(ctx context.Context, id string) (*entity.Family, error) {
+	if r.open {
+		return nil, errors.New("circuit breaker is open")
+	}
+	return r.family, nil
+}
+
+func operationContext(operation ast.Operation) context.Context {
+	return graphql.WithOperationContext(context.Background(), &graphql.OperationContext{
+		Operation: &ast.OperationDefinition{Operation: operation},
+	})
+}
+
+func TestExtension_InterceptResponse(t *testing.T) {
+	parent, err := entity.NewParent("5b7e2c3d-8a4f-4e6b-b1c9-2f3a4d5e6f01", "Ada", "Stale", time.Date(1980, time.January, 2, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := entity.NewFamily(familyID, entity.Single, []*entity.Parent{parent}, nil)
+	require.NoError(t, err)
+
+	primary := &flakyRepository{family: fam}
+	repo := staleread.NewRepository(primary, config.StaleReadsConfig{Enabled: true, MaxStaleness: time.Hour, MaxEntries: 10},
+		logging.NewContextLogger(zap.NewNop()))
+	_, err = repo.GetByID(context.Background(), familyID)
+	require.NoError(t, err)
+	primary.open = true
+
+	ext := NewExtension()
+	ext.now = func() time.Time { return time.Now().Add(90 * time.Second) }
+
+	// Queries are served from snapshots and flagged as stale
+	resp := ext.InterceptResponse(operationContext(ast.Query), func(ctx context.Context) *graphql.Response {
+		_, err := repo.GetByID(ctx, familyID)
+		assert.NoError(t, err)
+		return &graphql.Response{}
+	})
+	require.Contains(t, resp.Extensions, ExtensionKey)
+	value := resp.Extensions[ExtensionKey].(map[string]interface{})
+	assert.Equal(t, true, value["stale"])
+	assert.Equal(t, 1, value["staleReads"])
+	assert.InDelta(t, 90, value["ageSeconds"], 5)
+	assert.NotEmpty(t, value["asOf"])
+
+	// Mutations are not
+	called := false
+	resp = ext.InterceptResponse(operationContext(ast.Mutation), func(ctx context.Context) *graphql.Response {
+		_, err := repo.GetByID(ctx, familyID)
+		called = err != nil
+		return &graphql.Response{}
+	})
+	assert.True(t, called, "mutations must fail fast")
+	assert.NotContains(t, resp.Extensions, ExtensionKey)
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package strict catches typos in GraphQL requests.
+//
+// GraphQL ignores variables that the operation of a request does not declare, and gqlgen
+// ignores request extensions it does not know, so a misspelled variable silently falls
+// back to its default and a misspelled extension has no effect. In warn mode, such
+// requests are logged and counted; in error mode, they are rejected with the
+// UNKNOWN_INPUT error code before they run. Unknown fields of input objects are always
+// rejected by GraphQL validation.
+package strict
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+	"go.uber.org/zap"
+)
+
+// Modes of handling unknown inputs
+const (
+	ModeOff   = "off"
+	ModeWarn  = "warn"
+	ModeError = "error"
+)
+
+// CodeUnknownInput is the error code of a request rejected for unknown inputs
+const CodeUnknownInput = "UNKNOWN_INPUT"
+
+// Kinds of unknown inputs, as counted in the unknown inputs metric
+const (
+	KindVariable  = "variable"
+	KindExtension = "extension"
+)
+
+// knownExtensions are the request extensions the server handles: automatic persisted queries
+var knownExtensions = map[string]bool{
+	"persistedQuery": true,
+}
+
+var unknownInputs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "graphql_unknown_inputs_total",
+	Help: "Number of GraphQL requests with undeclared variables or unknown extensions, by kind of input",
+}, []string{"kind"})
+
+func init() {
+	prometheus.MustRegister(unknownInputs)
+}
+
+// Extension is a gqlgen handler extension that reports or rejects requests with unknown
+// variables or extensions
+type Extension struct {
+	mode   string
+	logger *zap.Logger
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationParameterMutator
+} = Extension{}
+
+// NewExtension creates an extension that handles unknown inputs in the given mode
+func NewExtension(mode string, logger *zap.Logger) Extension {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return Extension{mode: mode, logger: logger}
+}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "StrictInputs"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationParameters checks the variables and extensions of a request, and rejects
+// the request in error mode if any of them are unknown
+func (e Extension) MutateOperationParameters(ctx context.Context, params *graphql.RawParams) *gqlerror.Error {
+	if e.mode != ModeWarn && e.mode != ModeError {
+		return nil
+	}
+
+	variables := UnknownVariables(params)
+	extensions := UnknownExtensions(params)
+	if len(variables) == 0 && len(extensions) == 0 {
+		return nil
+	}
+	if len(variables) > 0 {
+		unknownInputs.WithLabelValues(KindVariable).Inc()
+	}
+	if len(extensions) > 0 {
+		unknownInputs.WithLabelValues(KindExtension).Inc()
+	}
+
+	if e.mode == ModeWarn {
+		e.logger.Warn("GraphQL request has unknown inputs",
+			zap.String("operation", params.OperationName),
+			zap.Strings("unknown_variables", variables),
+			zap.Strings("unknown_extensions", extensions))
+		return nil
+	}
+
+	var problems []string
+	if len(variables) > 0 {
+		problems = append(problems, fmt.Sprintf("variables not declared by the operation: %s", strings.Join(variables, ", ")))
+	}
+	if len(extensions) > 0 {
+		problems = append(problems, fmt.Sprintf("unknown extensions: %s", strings.Join(extensions, ", ")))
+	}
+	return &gqlerror.Error{
+		Message: "the request has unknown inputs; " + strings.Join(problems, "; "),
+		Extensions: map[string]interface{}{
+			"code":              CodeUnknownInput,
+			"unknownVariables":  variables,
+			"unknownExtensions": extensions,
+		},
+	}
+}
+
+// UnknownVariables returns, in order, the variables of a request that its operation does
+// not declare. It returns none if the operation cannot be determined, such as when the
+// query does not parse, so that the error is reported by the executor.
+func UnknownVariables(params *graphql.RawParams) []string {
+	if len(params.Variables) == 0 || params.Query == "" {
+		return nil
+	}
+	doc, err := parser.ParseQuery(&ast.Source{Input: params.Query})
+	if err != nil {
+		return nil
+	}
+	op := doc.Operations.ForName(params.OperationName)
+	if op == nil {
+		return nil
+	}
+
+	var unknown []string
+	for name := range params.Variables {
+		if op.VariableDefinitions.ForName(name) == nil {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// UnknownExtensions returns, in order, the extensions of a request that the server does not handle
+func UnknownExtensions(params *graphql.RawParams) []string {
+	var unknown []string
+	for name := range params.Extensions {
+		if !knownExtensions[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package strict
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// request returns the parameters of a request for a family that misspells a variable and an extension
+func request() *graphql.RawParams {
+	return &graphql.RawParams{
+		Query:     `query GetFamily($id: ID!, $withChildren: Boolean = true) { getFamily(id: $id) { id } }`,
+		Variables: map[string]interface{}{"id": "f1", "withChildern": false},
+		Extensions: map[string]interface{}{
+			"persistedQuery": map[string]interface{}{"version": 1},
+			"tracing":        true,
+		},
+	}
+}
+
+func TestUnknownVariables(t *testing.T) {
+	assert.Equal(t, []string{"withChildern"}, UnknownVariables(request()))
+
+	// The operation is selected by name when the query has several
+	params := request()
+	params.Query = `query A($id: ID!) { getFamily(id: $id) { id } } query B($withChildern: Boolean) { __typename }`
+	params.OperationName = "B"
+	assert.Equal(t, []string{"id"}, UnknownVariables(params))
+
+	// Requests whose operation cannot be determined are left to the executor
+	params.OperationName = ""
+	assert.Empty(t, UnknownVariables(params))
+	params.Query = "query {"
+	assert.Empty(t, UnknownVariables(params))
+}
+
+func TestUnknownExtensions(t *testing.T) {
+	assert.Equal(t, []string{"tracing"}, UnknownExtensions(request()))
+}
+
+func TestExtension_Modes(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Nil(t, NewExtension(ModeOff, nil).MutateOperationParameters(ctx, request()))
+
+	core, logs := observer.New(zap.WarnLevel)
+	before := testutil.ToFloat64(unknownInputs.WithLabelValues(KindVariable))
+	assert.Nil(t, NewExtension(ModeWarn, zap.New(core)).MutateOperationParameters(ctx, request()))
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, []interface{}{"withChildern"}, logs.All()[0].ContextMap()["unknown_variables"])
+	assert.Equal(t, before+1, testutil.ToFloat64(unknownInputs.WithLabelValues(KindVariable)))
+
+	err := NewExtension(ModeError, nil).MutateOperationParameters(ctx, request())
+	require.NotNil(t, err)
+	assert.Equal(t, CodeUnknownInput, err.Extensions["code"])
+	assert.Contains(t, err.Message, "variables not declared by the operation: withChildern")
+	assert.Contains(t, err.Message, "unknown extensions: tracing")
+
+	// Requests without unknown inputs run in every mode
+	params := request()
+	delete(params.Variables, "withChildern")
+	delete(params.Extensions, "tracing")
+	assert.Nil(t, NewExtension(ModeError, nil).MutateOperationParameters(ctx, params))
+}
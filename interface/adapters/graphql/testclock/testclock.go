@@ -0,0 +1,46 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package testclock lets integration tests pin the clock of the service for a request.
+//
+// A request with the test clock header, X-Test-Clock by default, set to an RFC 3339 time
+// runs with its context frozen at that time, so the timestamps of its events, consents,
+// and quarantines, and the ages that validation rules derive, are computed at the pinned
+// time. The pinned time is echoed in the same response header.
+// Requests with an invalid time are rejected with 400 Bad Request, and requests without
+// the header run on the system clock. The middleware is installed only when the test
+// clock is enabled outside production.
+package testclock
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/clock"
+)
+
+// DefaultHeader is the header that pins the clock when none is configured
+const DefaultHeader = "X-Test-Clock"
+
+// Middleware returns HTTP middleware that freezes the context of each request with the
+// header at the time it carries. An empty header uses DefaultHeader.
+func Middleware(header string) func(http.Handler) http.Handler {
+	if header == "" {
+		header = DefaultHeader
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.Header.Get(header)
+			if value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			at, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				http.Error(w, "invalid "+header+" header: expected an RFC 3339 time", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set(header, at.Format(time.RFC3339Nano))
+			next.ServeHTTP(w, r.WithContext(clock.Freeze(r.Context(), at)))
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package testclock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	var seen time.Time
+	handler := Middleware("")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = clock.Now(r.Context())
+	}))
+
+	serve := func(value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		if value != "" {
+			req.Header.Set(DefaultHeader, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("2030-03-04T05:06:07Z")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, time.Date(2030, time.March, 4, 5, 6, 7, 0, time.UTC), seen.UTC())
+	assert.Equal(t, "2030-03-04T05:06:07Z", rec.Header().Get(DefaultHeader))
+
+	// Requests without the header run on the system clock
+	rec = serve("")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.WithinDuration(t, time.Now(), seen, time.Second)
+	assert.Empty(t, rec.Header().Get(DefaultHeader))
+
+	seen = time.Time{}
+	rec = serve("yesterday")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.True(t, seen.IsZero(), "requests with an invalid time must not run")
+}
+
+func TestMiddleware_CustomHeader(t *testing.T) {
+	var frozen bool
+	handler := Middleware("X-Frozen-Time")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, frozen = clock.Frozen(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	req.Header.Set(DefaultHeader, "2030-03-04T05:06:07Z")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.False(t, frozen, "only the configured header pins the clock")
+
+	req.Header.Set("X-Frozen-Time", "2030-03-04T05:06:07.5+02:00")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.True(t, frozen)
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package veto lets a hook allow or deny GraphQL operations before they run.
+//
+// Organizations can veto operations centrally, for example to block exports during an
+// incident. Before an operation runs, the hook is asked to review it: the operation name
+// and type, its root fields, a summary of its variables, and the caller. Variable values
+// are never passed to the hook; only their kinds and sizes are. A denied operation fails
+// with the OPERATION_VETOED error code. When the hook fails, the operation runs if the
+// extension fails open, and fails with the VETO_UNAVAILABLE error code otherwise.
+// Introspection queries are not reviewed.
+package veto
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+)
+
+const (
+	// CodeOperationVetoed is the error code of an operation the hook denied
+	CodeOperationVetoed = "OPERATION_VETOED"
+
+	// CodeVetoUnavailable is the error code of an operation refused because the hook failed
+	CodeVetoUnavailable = "VETO_UNAVAILABLE"
+)
+
+// Hook reviews operations before they run
+type Hook interface {
+	// Review decides whether an operation may run. An error means no decision was made.
+	Review(ctx context.Context, req Request) (Decision, error)
+}
+
+// Request describes an operation to review
+type Request struct {
+	OperationName string                     `json:"operationName"`
+	OperationType string                     `json:"operationType"`
+	Fields        []string                   `json:"fields"`
+	Variables     map[string]VariableSummary `json:"variables"`
+	Principal     Principal                  `json:"principal"`
+}
+
+// VariableSummary describes a variable without its value
+type VariableSummary struct {
+	Kind string `json:"kind"`           // null, string, number, boolean, list, or object
+	Size int    `json:"size,omitempty"` // Length of a string, or number of items or keys
+}
+
+// Principal is the caller of an operation; its ID is empty for anonymous callers
+type Principal struct {
+	ID     string   `json:"id"`
+	Roles  []string `json:"roles"`
+	Scopes []string `json:"scopes"`
+}
+
+// Decision is the result of a review
+type Decision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Extension is a gqlgen handler extension that has each operation reviewed by a hook
+// before it runs
+type Extension struct {
+	hook     Hook
+	failOpen bool
+	logger   *zap.Logger
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = Extension{}
+
+// NewExtension creates an extension that has operations reviewed by a hook.
+// If failOpen is set, operations run when the hook fails; otherwise they are refused.
+func NewExtension(hook Hook, failOpen bool, logger *zap.Logger) Extension {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return Extension{hook: hook, failOpen: failOpen, logger: logger}
+}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "OperationVeto"
+}
+
+// Validate validates the extension against the schema
+func (Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation has the operation reviewed and refuses it if it is denied
+func (e Extension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+	if oc.Operation == nil {
+		return next(ctx)
+	}
+
+	req := NewRequest(ctx, oc)
+	if introspection(req.Fields) {
+		return next(ctx)
+	}
+
+	decision, err := e.hook.Review(ctx, req)
+	if err != nil {
+		if e.failOpen {
+			e.logger.Warn("Operation review failed, running the operation",
+				zap.String("operation", req.OperationName), zap.Error(err))
+			return next(ctx)
+		}
+		e.logger.Error("Operation review failed, refusing the operation",
+			zap.String("operation", req.OperationName), zap.Error(err))
+		return refuse(CodeVetoUnavailable, "operation could not be reviewed")
+	}
+
+	if !decision.Allow {
+		e.logger.Warn("Operation vetoed",
+			zap.String("operation", req.OperationName),
+			zap.Strings("fields", req.Fields),
+			zap.String("principal", req.Principal.ID),
+			zap.String("reason", decision.Reason))
+		message := "operation vetoed"
+		if decision.Reason != "" {
+			message += ": " + decision.Reason
+		}
+		return refuse(CodeOperationVetoed, message)
+	}
+	return next(ctx)
+}
+
+// NewRequest describes an operation for review
+func NewRequest(ctx context.Context, oc *graphql.OperationContext) Request {
+	req := Request{
+		OperationName: oc.OperationName,
+		Variables:     make(map[string]VariableSummary, len(oc.Variables)),
+	}
+	if oc.Operation != nil {
+		req.OperationType = string(oc.Operation.Operation)
+		if req.OperationName == "" {
+			req.OperationName = oc.Operation.Name
+		}
+		req.Fields = rootFields(oc.Operation.SelectionSet, nil)
+	}
+	for name, value := range oc.Variables {
+		req.Variables[name] = Summarize(value)
+	}
+
+	req.Principal.ID, _ = middleware.GetUserID(ctx)
+	req.Principal.Roles, _ = middleware.GetUserRoles(ctx)
+	req.Principal.Scopes, _ = middleware.GetUserScopes(ctx)
+	return req
+}
+
+// Summarize describes a variable value by its kind and size
+func Summarize(value interface{}) VariableSummary {
+	switch v := value.(type) {
+	case nil:
+		return VariableSummary{Kind: "null"}
+	case string:
+		return VariableSummary{Kind: "string", Size: len(v)}
+	case bool:
+		return VariableSummary{Kind: "boolean"}
+	case json.Number, float32, float64, int, int32, int64:
+		return VariableSummary{Kind: "number"}
+	case []interface{}:
+		return VariableSummary{Kind: "list", Size: len(v)}
+	case map[string]interface{}:
+		return VariableSummary{Kind: "object", Size: len(v)}
+	default:
+		return VariableSummary{Kind: "unknown"}
+	}
+}
+
+// rootFields appends the names of the root fields of a selection set, including those
+// selected through fragments, in the order they are selected
+func rootFields(selections ast.SelectionSet, fields []string) []string {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			fields = appendUnique(fields, s.Name)
+		case *ast.InlineFragment:
+			fields = rootFields(s.SelectionSet, fields)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				fields = rootFields(s.Definition.SelectionSet, fields)
+			}
+		}
+	}
+	return fields
+}
+
+// appendUnique appends a name unless it is already present
+func appendUnique(names []string, name string) []string {
+	for _, existing := range names {
+		if existing == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
+
+// introspection reports whether an operation only selects introspection fields
+func introspection(fields []string) bool {
+	for _, field := range fields {
+		if !strings.HasPrefix(field, "__") {
+			return false
+		}
+	}
+	return len(fields) > 0
+}
+
+// refuse returns a response handler that fails the operation with an error code
+func refuse(code, message string) graphql.ResponseHandler {
+	return graphql.OneShot(&graphql.Response{
+		Errors: gqlerror.List{{
+			Message:    message,
+			Extensions: map[string]interface{}{"code": code},
+		}},
+	})
+}
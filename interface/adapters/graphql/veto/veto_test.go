@@ -0,0 +1,118 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package veto
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// stubHook returns a fixed decision and records the requests it reviews
+type stubHook struct {
+	decision Decision
+	err      error
+	requests []Request
+}
+
+func (h *stubHook) Review(_ context.Context, req Request) (Decision, error) {
+	h.requests = append(h.requests, req)
+	return h.decision, h.err
+}
+
+func operationContext(fields ...string) context.Context {
+	selections := ast.SelectionSet{}
+	for _, field := range fields {
+		selections = append(selections, &ast.Field{Name: field, Alias: field})
+	}
+	ctx := middleware.WithUserID(context.Background(), "user-1")
+	ctx = middleware.WithUserRoles(ctx, []string{"ADMIN"})
+	ctx = middleware.WithUserScopes(ctx, []string{"family:export"})
+	return graphql.WithOperationContext(ctx, &graphql.OperationContext{
+		OperationName: "Export",
+		Variables: map[string]interface{}{
+			"id":     "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+			"limit":  json.Number("10"),
+			"filter": map[string]interface{}{"status": "ACTIVE"},
+			"tags":   []interface{}{"a", "b", "c"},
+			"after":  nil,
+		},
+		Operation: &ast.OperationDefinition{Operation: ast.Query, Name: "Export", SelectionSet: selections},
+	})
+}
+
+func run(ext Extension, ctx context.Context) *graphql.Response {
+	return ext.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		return graphql.OneShot(&graphql.Response{Data: json.RawMessage(`{"ok":true}`)})
+	})(ctx)
+}
+
+func TestExtension_Allow(t *testing.T) {
+	hook := &stubHook{decision: Decision{Allow: true}}
+	resp := run(NewExtension(hook, false, nil), operationContext("exportFamilies"))
+
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"ok":true}`, string(resp.Data))
+	require.Len(t, hook.requests, 1)
+	assert.Equal(t, Request{
+		OperationName: "Export",
+		OperationType: "query",
+		Fields:        []string{"exportFamilies"},
+		Variables: map[string]VariableSummary{
+			"id":     {Kind: "string", Size: 36},
+			"limit":  {Kind: "number"},
+			"filter": {Kind: "object", Size: 1},
+			"tags":   {Kind: "list", Size: 3},
+			"after":  {Kind: "null"},
+		},
+		Principal: Principal{ID: "user-1", Roles: []string{"ADMIN"}, Scopes: []string{"family:export"}},
+	}, hook.requests[0])
+}
+
+func TestExtension_Deny(t *testing.T) {
+	hook := &stubHook{decision: Decision{Allow: false, Reason: "exports are suspended"}}
+	resp := run(NewExtension(hook, true, nil), operationContext("exportFamilies"))
+
+	assert.Nil(t, resp.Data)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "operation vetoed: exports are suspended", resp.Errors[0].Message)
+	assert.Equal(t, CodeOperationVetoed, resp.Errors[0].Extensions["code"])
+}
+
+func TestExtension_HookFailure(t *testing.T) {
+	hook := &stubHook{err: errors.New("connection refused")}
+
+	resp := run(NewExtension(hook, true, nil), operationContext("exportFamilies"))
+	assert.Empty(t, resp.Errors)
+
+	resp = run(NewExtension(hook, false, nil), operationContext("exportFamilies"))
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, CodeVetoUnavailable, resp.Errors[0].Extensions["code"])
+}
+
+func TestExtension_Introspection(t *testing.T) {
+	hook := &stubHook{err: errors.New("connection refused")}
+	resp := run(NewExtension(hook, false, nil), operationContext("__schema"))
+
+	assert.Empty(t, resp.Errors)
+	assert.Empty(t, hook.requests)
+}
+
+func TestRootFields(t *testing.T) {
+	selections := ast.SelectionSet{
+		&ast.Field{Name: "getFamily"},
+		&ast.InlineFragment{SelectionSet: ast.SelectionSet{&ast.Field{Name: "exportFamilies"}}},
+		&ast.FragmentSpread{Definition: &ast.FragmentDefinition{SelectionSet: ast.SelectionSet{
+			&ast.Field{Name: "getFamily", Alias: "other"},
+			&ast.Field{Name: "__typename"},
+		}}},
+	}
+	assert.Equal(t, []string{"getFamily", "exportFamilies", "__typename"}, rootFields(selections, nil))
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package veto
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+)
+
+// SignatureHeader is the header of the HMAC-SHA256 signature of the body of a review request
+const SignatureHeader = "X-Signature-256"
+
+// maxDecisionSize is the largest decision body read from a webhook
+const maxDecisionSize = 64 << 10
+
+// Webhook is a Hook that posts operations to an HTTP endpoint for review.
+//
+// The request body is a JSON Request. The endpoint answers 200 OK with a JSON Decision,
+// such as {"allow": false, "reason": "exports are suspended"}; any other answer is a
+// failure. If a secret is configured, the body is signed with HMAC-SHA256 and the
+// signature is sent as "sha256=<hex>" in the X-Signature-256 header.
+type Webhook struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhook creates a webhook hook from its configuration
+//
+// Returns:
+//   - The webhook
+//   - An error if the URL is not an absolute http or https URL
+func NewWebhook(cfg config.VetoConfig) (*Webhook, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid veto webhook URL %q: must be an absolute http or https URL", cfg.URL)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Webhook{
+		url:    u.String(),
+		secret: []byte(cfg.Secret),
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Review posts an operation to the webhook and returns its decision
+func (w *Webhook) Review(ctx context.Context, req Request) (Decision, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to encode review request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to create review request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if len(w.secret) > 0 {
+		httpReq.Header.Set(SignatureHeader, Sign(w.secret, body))
+	}
+
+	resp, err := w.client.Do(httpReq)
+	if err != nil {
+		return Decision{}, fmt.Errorf("veto webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Decision{}, fmt.Errorf("veto webhook answered with status %d", resp.StatusCode)
+	}
+
+	var answer struct {
+		Allow  *bool  `json:"allow"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDecisionSize)).Decode(&answer); err != nil {
+		return Decision{}, fmt.Errorf("invalid veto webhook answer: %w", err)
+	}
+	if answer.Allow == nil {
+		return Decision{}, fmt.Errorf("invalid veto webhook answer: allow is missing")
+	}
+	return Decision{Allow: *answer.Allow, Reason: answer.Reason}, nil
+}
+
+// Sign returns the signature of a request body, as sent in the X-Signature-256 header
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package veto
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebhook_InvalidURL(t *testing.T) {
+	for _, u := range []string{"", "/review", "ftp://veto.example.com"} {
+		_, err := NewWebhook(config.VetoConfig{Enabled: true, URL: u})
+		assert.Error(t, err, u)
+	}
+}
+
+func TestWebhook_Review(t *testing.T) {
+	secret := "webhook-secret"
+	var received Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, Sign([]byte(secret), body), r.Header.Get(SignatureHeader))
+		require.NoError(t, json.Unmarshal(body, &received))
+
+		_, _ = w.Write([]byte(`{"allow": false, "reason": "exports are suspended"}`))
+	}))
+	defer server.Close()
+
+	hook, err := NewWebhook(config.VetoConfig{Enabled: true, URL: server.URL, Secret: secret})
+	require.NoError(t, err)
+
+	req := Request{OperationName: "Export", OperationType: "query", Fields: []string{"exportFamilies"}}
+	decision, err := hook.Review(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, Decision{Allow: false, Reason: "exports are suspended"}, decision)
+	assert.Equal(t, "Export", received.OperationName)
+	assert.Equal(t, []string{"exportFamilies"}, received.Fields)
+}
+
+func TestWebhook_ReviewFailures(t *testing.T) {
+	for name, handler := range map[string]http.HandlerFunc{
+		"error status": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		},
+		"invalid body": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`allow`))
+		},
+		"missing decision": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"reason": "maybe"}`))
+		},
+		"timeout": func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"allow": true}`))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			hook, err := NewWebhook(config.VetoConfig{Enabled: true, URL: server.URL, Timeout: 50 * time.Millisecond})
+			require.NoError(t, err)
+
+			_, err = hook.Review(context.Background(), Request{OperationName: "Export"})
+			assert.Error(t, err)
+		})
+	}
+}
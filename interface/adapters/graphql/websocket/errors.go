@@ -0,0 +1,104 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package websocket
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/abitofhelp/servicelib/middleware"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+)
+
+// HandleErrors sets the error presenter and the recover func of the websocket server to
+// those of the HTTP server of servicelib, so that an operation fails the same way over
+// either: errors that are not GraphQL errors, such as wrapped driver errors, are logged
+// and sent to the client as INTERNAL_ERROR, and panics are logged and recovered.
+//
+// Parameters:
+//   - server: The GraphQL server of the websocket connections
+//   - logger: Logger for the errors and panics of operations
+func HandleErrors(server *handler.Server, logger *logging.ContextLogger) {
+	server.SetErrorPresenter(presentError(logger))
+	server.SetRecoverFunc(recoverPanic(logger))
+}
+
+// recoverPanic returns the recover func of the HTTP server
+func recoverPanic(logger *logging.ContextLogger) graphql.RecoverFunc {
+	return func(ctx context.Context, err interface{}) error {
+		logger.Error(ctx, "GraphQL panic recovered",
+			zap.Any("error", err),
+			zap.String("request_id", middleware.RequestID(ctx)),
+		)
+		return &gqlerror.Error{
+			Message: "Internal server error",
+			Extensions: map[string]interface{}{
+				"code": "INTERNAL_ERROR",
+				"time": time.Now().Format(time.RFC3339),
+			},
+		}
+	}
+}
+
+// presentError returns the error presenter of the HTTP server
+func presentError(logger *logging.ContextLogger) graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, err error) *gqlerror.Error {
+		requestID := middleware.RequestID(ctx)
+		timestamp := time.Now().UTC().Format(time.RFC3339)
+
+		// GraphQL errors, such as those of the resolvers, are presented as they are
+		var gqlErr *gqlerror.Error
+		if stderrors.As(err, &gqlErr) {
+			return gqlErr
+		}
+
+		if stderrors.Is(err, context.Canceled) {
+			logger.Debug(ctx, "Request context was canceled",
+				zap.String("request_id", requestID),
+				zap.Error(err),
+			)
+			return &gqlerror.Error{
+				Message: "The request was interrupted. This could be due to a client disconnect.",
+				Extensions: map[string]interface{}{
+					"code":       "CLIENT_DISCONNECTED",
+					"timestamp":  timestamp,
+					"request_id": requestID,
+				},
+			}
+		}
+
+		if stderrors.Is(err, context.DeadlineExceeded) {
+			logger.Debug(ctx, "Request timed out",
+				zap.String("request_id", requestID),
+				zap.Error(err),
+			)
+			return &gqlerror.Error{
+				Message: "The request timed out. Please try again with a simpler query or contact support if the issue persists.",
+				Extensions: map[string]interface{}{
+					"code":       "TIMEOUT",
+					"timestamp":  timestamp,
+					"request_id": requestID,
+				},
+			}
+		}
+
+		// The details of other errors are logged, not disclosed
+		logger.Error(ctx, "GraphQL error",
+			zap.Error(err),
+			zap.String("request_id", requestID),
+		)
+		return &gqlerror.Error{
+			Message: "An error occurred while processing your request",
+			Extensions: map[string]interface{}{
+				"code":       "INTERNAL_ERROR",
+				"timestamp":  timestamp,
+				"request_id": requestID,
+			},
+		}
+	}
+}
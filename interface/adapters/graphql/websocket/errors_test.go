@@ -0,0 +1,55 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/abitofhelp/servicelib/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestPresentError(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	present := presentError(logging.NewContextLogger(zap.New(core)))
+
+	tests := []struct {
+		name    string
+		err     error
+		message string
+		code    interface{}
+	}{
+		{"GraphQL errors are presented as they are", gqlerror.Errorf("family not found"), "family not found", nil},
+		{"other errors are not disclosed", fmt.Errorf("failed to list families: %w", errors.New("connection refused")), "An error occurred while processing your request", "INTERNAL_ERROR"},
+		{"canceled operations", fmt.Errorf("query: %w", context.Canceled), "The request was interrupted. This could be due to a client disconnect.", "CLIENT_DISCONNECTED"},
+		{"timed out operations", context.DeadlineExceeded, "The request timed out. Please try again with a simpler query or contact support if the issue persists.", "TIMEOUT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			presented := present(context.Background(), tt.err)
+
+			assert.Equal(t, tt.message, presented.Message)
+			assert.Equal(t, tt.code, presented.Extensions["code"])
+		})
+	}
+	assert.Equal(t, 1, logs.FilterMessage("GraphQL error").Len(), "the undisclosed error is logged")
+}
+
+func TestRecoverPanic(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+
+	err := recoverPanic(logging.NewContextLogger(zap.New(core)))(context.Background(), "nil map")
+
+	var gqlErr *gqlerror.Error
+	require.ErrorAs(t, err, &gqlErr)
+	assert.Equal(t, "Internal server error", gqlErr.Message)
+	assert.Equal(t, "INTERNAL_ERROR", gqlErr.Extensions["code"])
+	assert.Equal(t, 1, logs.FilterMessage("GraphQL panic recovered").Len())
+}
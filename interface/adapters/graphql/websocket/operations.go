@@ -0,0 +1,94 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package websocket
+
+import (
+	"context"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var (
+	// OperationsActive is the number of operations in progress over websocket connections,
+	// such as open subscriptions, by type and root field
+	OperationsActive = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "graphql_websocket_operations_active",
+			Help: "Number of GraphQL operations in progress over websocket connections by type and root field",
+		},
+		[]string{"type", "operation"},
+	)
+
+	// ResponsesTotal counts the responses sent for operations over websocket connections,
+	// one for each event of a subscription, by type and root field
+	ResponsesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "graphql_websocket_responses_total",
+			Help: "Total number of responses of GraphQL operations over websocket connections by type and root field",
+		},
+		[]string{"type", "operation"},
+	)
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(OperationsActive, ResponsesTotal)
+}
+
+// Operations is a gqlgen handler extension of the websocket server that counts the
+// operations in progress over its connections and the responses sent for them. Operations
+// are labeled by their root field, which the schema bounds, rather than by their name,
+// which clients choose.
+type Operations struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = Operations{}
+
+// ExtensionName returns the name of the extension
+func (Operations) ExtensionName() string {
+	return "WebsocketOperationMetrics"
+}
+
+// Validate validates the extension against the schema
+func (Operations) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation counts the operation as active until its last response has been sent
+func (Operations) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	kind, operation := operationLabels(graphql.GetOperationContext(ctx))
+	responses := next(ctx)
+
+	active := OperationsActive.WithLabelValues(kind, operation)
+	active.Inc()
+	var once sync.Once
+	return func(ctx context.Context) *graphql.Response {
+		response := responses(ctx)
+		if response == nil {
+			// The transport asks for responses until there are none left
+			once.Do(active.Dec)
+			return nil
+		}
+		ResponsesTotal.WithLabelValues(kind, operation).Inc()
+		return response
+	}
+}
+
+// operationLabels returns the type and the root field of an operation. An operation that
+// selects several root fields is labeled by the first.
+func operationLabels(oc *graphql.OperationContext) (string, string) {
+	if oc == nil || oc.Operation == nil {
+		return "unknown", "unknown"
+	}
+	for _, selection := range oc.Operation.SelectionSet {
+		if field, ok := selection.(*ast.Field); ok {
+			return string(oc.Operation.Operation), field.Name
+		}
+	}
+	return string(oc.Operation.Operation), "unknown"
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package websocket
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler/testserver"
+	gorilla "github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperations_CountsSubscriptions(t *testing.T) {
+	server := testserver.New()
+	server.AddTransport(NewManager(testConfig(), &fakeValidator{}).Transport())
+	server.Use(Operations{})
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	conn, _, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	require.Equal(t, "connection_ack", initialize(t, conn, "Bearer good").Type)
+
+	active := OperationsActive.WithLabelValues("subscription", "name")
+	responses := ResponsesTotal.WithLabelValues("subscription", "name")
+	started, sent := testutil.ToFloat64(active), testutil.ToFloat64(responses)
+
+	require.NoError(t, conn.WriteJSON(map[string]any{
+		"type":    "start",
+		"id":      "1",
+		"payload": map[string]any{"query": "subscription { name }"},
+	}))
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(active) == started+1
+	}, time.Second, 10*time.Millisecond)
+
+	for i := 1; i <= 2; i++ {
+		server.SendNextSubscriptionMessage()
+		assert.Equal(t, "data", read(t, conn).Type)
+		assert.Equal(t, sent+float64(i), testutil.ToFloat64(responses))
+	}
+
+	server.SendCompleteSubscriptionMessage()
+	assert.Equal(t, "complete", read(t, conn).Type)
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(active) == started
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, sent+2, testutil.ToFloat64(responses))
+}
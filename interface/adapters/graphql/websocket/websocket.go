@@ -0,0 +1,336 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package websocket authenticates and manages the lifetime of GraphQL connections over
+// websockets, which carry subscriptions.
+//
+// Browsers cannot set headers on websocket requests, so a connection is authenticated by
+// the bearer token in the Authorization field of its connection_init payload. The token is
+// validated like the token of an HTTP request, and the user and tenant it identifies are
+// added to the context of the operations of the connection. A connection outlives the requests the
+// token was issued for, so its token is validated again periodically, and the connection
+// is closed with a connection error as soon as the token expires or is no longer valid,
+// and once it reaches its maximum lifetime. Clients then reconnect with a fresh token.
+//
+// Keepalive messages (graphql-ws) or pings (graphql-transport-ws) are sent at the
+// configured interval. Connections, revalidations, and the reasons connections are closed
+// are counted in metrics, and the Operations extension counts the operations over the
+// connections and their responses. HandleErrors presents the errors of operations and
+// recovers from their panics like the HTTP server.
+package websocket
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	authwrapper "github.com/abitofhelp/family-service/infrastructure/adapters/authwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	autherrors "github.com/abitofhelp/servicelib/auth/errors"
+	"github.com/abitofhelp/servicelib/auth/jwt"
+	authmiddleware "github.com/abitofhelp/servicelib/auth/middleware"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reasons a connection is closed, as counted in the closes metric
+const (
+	ReasonTokenExpired = "token_expired" // The token of the connection expired
+	ReasonTokenInvalid = "token_invalid" // The token of the connection failed revalidation
+	ReasonMaxLifetime  = "max_lifetime"  // The connection reached its maximum lifetime
+	ReasonClosed       = "closed"        // The client closed the connection
+	ReasonLost         = "lost"          // The connection was lost, or missed its keepalive pongs
+)
+
+// Results of authenticating a connection and of revalidating its token
+const (
+	ResultAccepted = "accepted"
+	ResultRejected = "rejected"
+	ResultValid    = "valid"
+	ResultInvalid  = "invalid"
+)
+
+// closeCodeAbnormal is the websocket close code of a connection that was lost
+const closeCodeAbnormal = 1006
+
+var (
+	// ConnectionsActive is the number of open authenticated connections
+	ConnectionsActive = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "graphql_websocket_connections_active",
+			Help: "Number of open authenticated GraphQL websocket connections",
+		},
+	)
+
+	// ConnectionsTotal counts the connections by result of their authentication
+	ConnectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "graphql_websocket_connections_total",
+			Help: "Total number of GraphQL websocket connections by result of the authentication of their connection_init payload",
+		},
+		[]string{"result"},
+	)
+
+	// RevalidationsTotal counts the periodic revalidations of tokens by result
+	RevalidationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "graphql_websocket_revalidations_total",
+			Help: "Total number of revalidations of the tokens of GraphQL websocket connections by result",
+		},
+		[]string{"result"},
+	)
+
+	// ClosesTotal counts the closed connections by reason
+	ClosesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "graphql_websocket_closes_total",
+			Help: "Total number of closed authenticated GraphQL websocket connections by reason",
+		},
+		[]string{"reason"},
+	)
+
+	// ConnectionDuration observes how long connections stay open
+	ConnectionDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "graphql_websocket_connection_duration_seconds",
+			Help:    "Duration of authenticated GraphQL websocket connections",
+			Buckets: []float64{1, 10, 60, 300, 900, 1800, 3600, 7200, 14400},
+		},
+	)
+)
+
+func init() {
+	// Register metrics with the default Prometheus registry
+	prometheus.MustRegister(ConnectionsActive, ConnectionsTotal, RevalidationsTotal, ClosesTotal, ConnectionDuration)
+}
+
+// errAuthorizationRequired is the connection error of a connection_init without a token
+var errAuthorizationRequired = stderrors.New("authorization required: send a bearer token as Authorization in the connection_init payload")
+
+// errInvalidToken is the connection error of a connection_init with an invalid token.
+// The reason the token is invalid is not disclosed.
+var errInvalidToken = stderrors.New("invalid or expired token")
+
+// TokenValidator validates bearer tokens, as the auth service does for HTTP requests
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, tokenString string) (*jwt.Claims, error)
+}
+
+// Manager authenticates websocket connections and closes them when their token expires
+type Manager struct {
+	cfg       config.WebsocketConfig
+	validator TokenValidator
+	now       func() time.Time
+}
+
+// NewManager creates a manager of the websocket connections of the GraphQL server
+func NewManager(cfg config.WebsocketConfig, validator TokenValidator) *Manager {
+	return &Manager{cfg: cfg, validator: validator, now: time.Now}
+}
+
+// Transport returns the websocket transport of the GraphQL server, which authenticates the
+// connection_init payload and sends keepalive messages
+func (m *Manager) Transport() transport.Websocket {
+	return transport.Websocket{
+		InitFunc:              m.initialize,
+		InitTimeout:           m.cfg.InitTimeout,
+		CloseFunc:             m.closed,
+		KeepAlivePingInterval: m.cfg.KeepaliveInterval,
+		PingPongInterval:      m.cfg.KeepaliveInterval,
+	}
+}
+
+// Upgrades returns an HTTP handler that serves websocket upgrade requests with ws, the
+// server with the managed transport, and other requests with next
+func Upgrades(ws, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isUpgrade(r) {
+			ws.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Authenticate returns middleware that authenticates requests with authenticate, except
+// websocket upgrade requests to path, the GraphQL endpoint. Browsers cannot send an
+// Authorization header with them, so their connections are authenticated by their
+// connection_init payload instead.
+func Authenticate(path string, authenticate func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		authenticated := authenticate(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == path && isUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			authenticated.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isUpgrade reports whether a request asks to upgrade to a websocket
+func isUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// initialize authenticates the connection_init payload of a connection and starts
+// watching its token and lifetime
+func (m *Manager) initialize(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+	token := bearerToken(payload.Authorization())
+	if token == "" {
+		ConnectionsTotal.WithLabelValues(ResultRejected).Inc()
+		return ctx, nil, errAuthorizationRequired
+	}
+	claims, err := m.validator.ValidateToken(ctx, token)
+	if err != nil {
+		ConnectionsTotal.WithLabelValues(ResultRejected).Inc()
+		return ctx, nil, errInvalidToken
+	}
+
+	// The operations of the connection run as the user of the token
+	ctx = authmiddleware.WithUserID(ctx, claims.UserID)
+	ctx = authmiddleware.WithUserRoles(ctx, claims.Roles)
+	ctx = authmiddleware.WithUserScopes(ctx, claims.Scopes)
+	ctx = authmiddleware.WithUserResources(ctx, claims.Resources)
+
+	// and are scoped to the families of the tenant of the token, which withTenant records
+	// for HTTP requests but not for upgrade requests, as they carry no token
+	if tenant := authwrapper.TokenTenant(token); tenant != "" {
+		ctx = servicecontext.WithTenantID(ctx, tenant)
+	}
+
+	conn := newConnection(ctx, m.now())
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	go m.watch(conn, token, expiresAt)
+
+	ConnectionsTotal.WithLabelValues(ResultAccepted).Inc()
+	ConnectionsActive.Inc()
+	return conn, nil, nil
+}
+
+// watch closes a connection when its token expires or fails revalidation, or when it
+// reaches its maximum lifetime, until the connection is closed
+func (m *Manager) watch(conn *connection, token string, expiresAt time.Time) {
+	var expired, lifetime, revalidate <-chan time.Time
+	if !expiresAt.IsZero() {
+		timer := time.NewTimer(expiresAt.Sub(m.now()))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	if m.cfg.MaxLifetime > 0 {
+		timer := time.NewTimer(m.cfg.MaxLifetime)
+		defer timer.Stop()
+		lifetime = timer.C
+	}
+	if m.cfg.RevalidateInterval > 0 {
+		ticker := time.NewTicker(m.cfg.RevalidateInterval)
+		defer ticker.Stop()
+		revalidate = ticker.C
+	}
+
+	for {
+		select {
+		case <-conn.Done():
+			return
+		case <-expired:
+			conn.close(ReasonTokenExpired, "token expired")
+			return
+		case <-lifetime:
+			conn.close(ReasonMaxLifetime, "maximum connection lifetime reached")
+			return
+		case <-revalidate:
+			if _, err := m.validator.ValidateToken(conn, token); err != nil {
+				RevalidationsTotal.WithLabelValues(ResultInvalid).Inc()
+				if stderrors.Is(err, autherrors.ErrExpiredToken) {
+					conn.close(ReasonTokenExpired, "token expired")
+				} else {
+					conn.close(ReasonTokenInvalid, "token is no longer valid")
+				}
+				return
+			}
+			RevalidationsTotal.WithLabelValues(ResultValid).Inc()
+		}
+	}
+}
+
+// closed records a closed connection and stops watching it. Connections that failed to
+// authenticate were counted when they were rejected.
+func (m *Manager) closed(ctx context.Context, closeCode int) {
+	conn, ok := ctx.(*connection)
+	if !ok {
+		return
+	}
+
+	reason := ReasonClosed
+	if closeCode == closeCodeAbnormal {
+		reason = ReasonLost
+	}
+	var closing *closeError
+	if stderrors.As(context.Cause(conn.Context), &closing) {
+		reason = closing.reason
+	}
+	conn.cancel(nil)
+
+	ClosesTotal.WithLabelValues(reason).Inc()
+	ConnectionsActive.Dec()
+	ConnectionDuration.Observe(m.now().Sub(conn.opened).Seconds())
+}
+
+// bearerToken returns the token of an Authorization value, with or without the Bearer scheme
+func bearerToken(authorization string) string {
+	const scheme = "bearer "
+	authorization = strings.TrimSpace(authorization)
+	if len(authorization) > len(scheme) && strings.EqualFold(authorization[:len(scheme)], scheme) {
+		return strings.TrimSpace(authorization[len(scheme):])
+	}
+	return authorization
+}
+
+// closeError is the cause of the cancellation of a connection that is closed by the server
+type closeError struct {
+	reason  string
+	message string
+}
+
+// Error returns the message sent to the client as the connection error
+func (e *closeError) Error() string {
+	return e.message
+}
+
+// connection is the context of an authenticated connection. Cancelling it closes the
+// connection; the transport sends the message of its cause to the client as the
+// connection error.
+type connection struct {
+	context.Context
+	cancel context.CancelCauseFunc
+	opened time.Time
+}
+
+// newConnection creates the context of a connection opened at a time
+func newConnection(ctx context.Context, opened time.Time) *connection {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &connection{Context: ctx, cancel: cancel, opened: opened}
+}
+
+// close closes the connection for a reason
+func (c *connection) close(reason, message string) {
+	c.cancel(&closeError{reason: reason, message: message + "; reconnect with a new token"})
+}
+
+// Value returns the value of a key in the context. Once the connection is closed by the
+// server, the close reason of the transport is the message of the cause.
+func (c *connection) Value(key any) any {
+	var closing *closeError
+	if c.Err() != nil && stderrors.As(context.Cause(c.Context), &closing) {
+		if value := transport.AppendCloseReason(context.Background(), closing.Error()).Value(key); value != nil {
+			return value
+		}
+	}
+	return c.Context.Value(key)
+}
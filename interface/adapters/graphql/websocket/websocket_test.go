@@ -0,0 +1,277 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler/testserver"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/servicelib/auth/jwt"
+	authmiddleware "github.com/abitofhelp/servicelib/auth/middleware"
+	servicecontext "github.com/abitofhelp/servicelib/context"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	gorilla "github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeValidator accepts the token "good", and the token it is given, until it is revoked
+type fakeValidator struct {
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	revoked   bool
+}
+
+func (v *fakeValidator) ValidateToken(ctx context.Context, token string) (*jwt.Claims, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if (token != "good" && token != v.token) || v.revoked {
+		return nil, errors.New("token is invalid")
+	}
+	claims := &jwt.Claims{UserID: "user-1", Roles: []string{"ADMIN"}}
+	if !v.expiresAt.IsZero() {
+		claims.ExpiresAt = jwtv5.NewNumericDate(v.expiresAt)
+	}
+	return claims, nil
+}
+
+func (v *fakeValidator) revoke() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.revoked = true
+}
+
+func testConfig() config.WebsocketConfig {
+	return config.WebsocketConfig{
+		InitTimeout:        time.Second,
+		KeepaliveInterval:  time.Second,
+		RevalidateInterval: time.Minute,
+		MaxLifetime:        time.Hour,
+	}
+}
+
+// dial starts a server with the managed transport and opens a graphql-ws connection to it
+func dial(t *testing.T, manager *Manager) *gorilla.Conn {
+	t.Helper()
+	server := testserver.New()
+	server.AddTransport(manager.Transport())
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	conn, _, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+type message struct {
+	Type    string `json:"type"`
+	Payload struct {
+		Message string `json:"message"`
+	} `json:"payload"`
+}
+
+// initialize sends a connection_init with an authorization and returns the reply
+func initialize(t *testing.T, conn *gorilla.Conn, authorization string) message {
+	t.Helper()
+	init := map[string]any{"type": "connection_init"}
+	if authorization != "" {
+		init["payload"] = map[string]any{"Authorization": authorization}
+	}
+	require.NoError(t, conn.WriteJSON(init))
+	return read(t, conn)
+}
+
+// read returns the next message other than a keepalive
+func read(t *testing.T, conn *gorilla.Conn) message {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		var msg message
+		require.NoError(t, conn.ReadJSON(&msg))
+		if msg.Type != "ka" {
+			return msg
+		}
+	}
+}
+
+func TestManager_RejectsConnectionsWithoutValidTokens(t *testing.T) {
+	manager := NewManager(testConfig(), &fakeValidator{})
+	rejected := testutil.ToFloat64(ConnectionsTotal.WithLabelValues(ResultRejected))
+
+	msg := initialize(t, dial(t, manager), "")
+	assert.Equal(t, "connection_error", msg.Type)
+	assert.Contains(t, msg.Payload.Message, "authorization required")
+
+	msg = initialize(t, dial(t, manager), "Bearer forged")
+	assert.Equal(t, "connection_error", msg.Type)
+	assert.Equal(t, "invalid or expired token", msg.Payload.Message)
+
+	assert.Equal(t, rejected+2, testutil.ToFloat64(ConnectionsTotal.WithLabelValues(ResultRejected)))
+}
+
+func TestManager_AuthenticatesOperations(t *testing.T) {
+	manager := NewManager(testConfig(), &fakeValidator{})
+
+	ctx, ack, err := manager.initialize(context.Background(), transport.InitPayload{"authorization": "good"})
+	require.NoError(t, err)
+	assert.Nil(t, ack)
+	userID, _ := authmiddleware.GetUserID(ctx)
+	assert.Equal(t, "user-1", userID)
+	assert.True(t, authmiddleware.HasRole(ctx, "ADMIN"))
+
+	manager.closed(ctx, 1000)
+	assert.Error(t, ctx.Err(), "closing a connection stops watching it")
+}
+
+func TestManager_ScopesOperationsToTenant(t *testing.T) {
+	token, err := jwtv5.NewWithClaims(jwtv5.SigningMethodHS256, jwtv5.MapClaims{"sub": "user-1", "tenant_id": "acme"}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	manager := NewManager(testConfig(), &fakeValidator{token: token})
+
+	ctx, _, err := manager.initialize(context.Background(), transport.InitPayload{"authorization": "Bearer " + token})
+	require.NoError(t, err)
+	defer manager.closed(ctx, 1000)
+	assert.Equal(t, "acme", servicecontext.GetTenantID(ctx), "the operations of the connection are scoped to the tenant of the token")
+
+	// A token without a tenant gets the families saved without one
+	ctx, _, err = manager.initialize(context.Background(), transport.InitPayload{"authorization": "good"})
+	require.NoError(t, err)
+	defer manager.closed(ctx, 1000)
+	assert.Empty(t, servicecontext.GetTenantID(ctx))
+}
+
+func TestManager_ClosesConnections(t *testing.T) {
+	tests := []struct {
+		name    string
+		reason  string
+		message string
+		setup   func(cfg *config.WebsocketConfig, validator *fakeValidator)
+	}{
+		{
+			name:    "token expiry",
+			reason:  ReasonTokenExpired,
+			message: "token expired",
+			setup: func(_ *config.WebsocketConfig, validator *fakeValidator) {
+				validator.expiresAt = time.Now().Add(200 * time.Millisecond)
+			},
+		},
+		{
+			name:    "failed revalidation",
+			reason:  ReasonTokenInvalid,
+			message: "token is no longer valid",
+			setup: func(cfg *config.WebsocketConfig, validator *fakeValidator) {
+				cfg.RevalidateInterval = 100 * time.Millisecond
+				time.AfterFunc(150*time.Millisecond, validator.revoke)
+			},
+		},
+		{
+			name:    "maximum lifetime",
+			reason:  ReasonMaxLifetime,
+			message: "maximum connection lifetime reached",
+			setup: func(cfg *config.WebsocketConfig, _ *fakeValidator) {
+				cfg.MaxLifetime = 200 * time.Millisecond
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			validator := &fakeValidator{}
+			tt.setup(&cfg, validator)
+			closes := testutil.ToFloat64(ClosesTotal.WithLabelValues(tt.reason))
+
+			conn := dial(t, NewManager(cfg, validator))
+			require.Equal(t, "connection_ack", initialize(t, conn, "Bearer good").Type)
+
+			msg := read(t, conn)
+			assert.Equal(t, "connection_error", msg.Type)
+			assert.Equal(t, tt.message+"; reconnect with a new token", msg.Payload.Message)
+			_, _, err := conn.ReadMessage()
+			assert.True(t, gorilla.IsCloseError(err, gorilla.CloseNormalClosure), "unexpected error: %v", err)
+
+			assert.Eventually(t, func() bool {
+				return testutil.ToFloat64(ClosesTotal.WithLabelValues(tt.reason)) == closes+1
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestManager_CountsClientCloses(t *testing.T) {
+	closes := testutil.ToFloat64(ClosesTotal.WithLabelValues(ReasonClosed))
+	active := testutil.ToFloat64(ConnectionsActive)
+
+	conn := dial(t, NewManager(testConfig(), &fakeValidator{}))
+	require.Equal(t, "connection_ack", initialize(t, conn, "Bearer good").Type)
+	assert.Equal(t, active+1, testutil.ToFloat64(ConnectionsActive))
+
+	require.NoError(t, conn.WriteMessage(gorilla.CloseMessage, gorilla.FormatCloseMessage(gorilla.CloseNormalClosure, "")))
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(ClosesTotal.WithLabelValues(ReasonClosed)) == closes+1 &&
+			testutil.ToFloat64(ConnectionsActive) == active
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBearerToken(t *testing.T) {
+	assert.Equal(t, "abc", bearerToken("Bearer abc"))
+	assert.Equal(t, "abc", bearerToken("bearer  abc "))
+	assert.Equal(t, "abc", bearerToken("abc"))
+	assert.Equal(t, "", bearerToken(""))
+}
+
+func TestUpgrades(t *testing.T) {
+	handler := Upgrades(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusSwitchingProtocols) }),
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	req.Header.Set("Upgrade", "WebSocket")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusSwitchingProtocols, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAuthenticate(t *testing.T) {
+	authenticate := func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusUnauthorized) })
+	}
+	handler := Authenticate("/graphql", authenticate)(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }),
+	)
+
+	tests := []struct {
+		path    string
+		upgrade bool
+		want    int
+	}{
+		{path: "/graphql", upgrade: true, want: http.StatusOK},
+		{path: "/graphql", want: http.StatusUnauthorized},
+		{path: "/other", upgrade: true, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.upgrade {
+			req.Header.Set("Upgrade", "websocket")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, tt.want, rec.Code, "%s upgrade=%v", tt.path, tt.upgrade)
+	}
+}
@@ -0,0 +1,522 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Package rest serves a REST/JSON gateway to the family operations, for clients that
+// cannot speak GraphQL, such as curl scripts and legacy integrations.
+//
+// The endpoints are served below the REST path:
+//
+//	GET    /families                          Lists the families
+//	POST   /families                          Creates a family
+//	GET    /families/{id}                     Returns a family
+//	PUT    /families/{id}                     Replaces the data of a family
+//	DELETE /families/{id}                     Deletes a family; ?hard=true removes it for good
+//	POST   /families/{id}/parents             Adds a parent to a family
+//	POST   /families/{id}/children            Adds a child to a family
+//	DELETE /families/{id}/children/{childId}  Removes a child from a family
+//	GET    /openapi.json                      Returns the OpenAPI document of the gateway
+//
+// Every endpoint calls the same application service as the GraphQL API and is authorized
+// as its GraphQL operation, with the same roles and scopes. Requests and responses use the
+// JSON representation of the GraphQL inputs and types, so a family reads the same through
+// both APIs, with the data of children redacted by the same consent policy. Mutations are
+// refused while the service is in maintenance mode, and quarantined families are refused
+// to anyone but administrators. Errors are returned as {"error": {"code", "message"}},
+// with the codes of the GraphQL user errors. Operations rejected by a rate limiter or an
+// open circuit breaker fail with 429 THROTTLED or 503 UPSTREAM_UNAVAILABLE and a
+// Retry-After header.
+package rest
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/authz"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/dto"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/maintenance"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/quarantine"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/retryafter"
+	serviceerrors "github.com/abitofhelp/servicelib/errors"
+	"github.com/abitofhelp/servicelib/valueobject/identification"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+)
+
+// Error codes of the gateway, in addition to those of the authorizer and the user errors
+// of the GraphQL API
+const (
+	// CodeValidationError is the error code of an invalid request body or a violated rule
+	CodeValidationError = "VALIDATION_ERROR"
+
+	// CodeNotFound is the error code of a family, parent, or child that does not exist
+	CodeNotFound = "NOT_FOUND"
+
+	// CodeInternalError is the error code of an unexpected failure
+	CodeInternalError = "INTERNAL_ERROR"
+)
+
+// maxBodyBytes is the largest request body accepted
+const maxBodyBytes = 1 << 20
+
+// openAPIPath is the path of the OpenAPI document, below the REST path
+const openAPIPath = "/openapi.json"
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// Families are the family operations of the application service
+type Families interface {
+	CreateFamily(ctx context.Context, dto entity.FamilyDTO) (*entity.FamilyDTO, error)
+	GetFamily(ctx context.Context, id string) (*entity.FamilyDTO, error)
+	GetAllFamilies(ctx context.Context) ([]*entity.FamilyDTO, error)
+	UpdateFamily(ctx context.Context, dto entity.FamilyDTO) (*entity.FamilyDTO, error)
+	DeleteFamily(ctx context.Context, id string, hard bool) error
+	AddParent(ctx context.Context, familyID string, parentDTO entity.ParentDTO) (*entity.FamilyDTO, error)
+	AddChild(ctx context.Context, familyID string, childDTO entity.ChildDTO) (*entity.FamilyDTO, error)
+	RemoveChild(ctx context.Context, familyID string, childID string) (*entity.FamilyDTO, error)
+}
+
+// Authorizer authorizes the callers of the gateway
+type Authorizer interface {
+	Authorize(ctx context.Context, req authz.Requirement) error
+}
+
+// Dependencies are what the endpoints call
+type Dependencies struct {
+	Families    Families
+	Mapper      dto.FamilyMapper
+	Authorizer  Authorizer
+	Maintenance *maintenance.Mode
+	Logger      *zap.Logger
+}
+
+// Endpoint describes an endpoint of the gateway and the GraphQL operation it is authorized as
+type Endpoint struct {
+	Method    string
+	Path      string
+	Operation string
+	Mutation  bool
+	access    authz.Requirement
+}
+
+// route is an endpoint served by the handler
+type route struct {
+	Endpoint
+	serve func(w http.ResponseWriter, r *http.Request)
+}
+
+// Handler serves the REST gateway
+type Handler struct {
+	path    string
+	deps    Dependencies
+	routes  []route
+	mux     *http.ServeMux
+	openAPI []byte
+}
+
+// NewHandler creates the handler of the REST gateway served below a path
+func NewHandler(path string, deps Dependencies) (*Handler, error) {
+	if deps.Logger == nil {
+		deps.Logger = zap.NewNop()
+	}
+	h := &Handler{path: strings.TrimSuffix(path, "/"), deps: deps, mux: http.NewServeMux()}
+
+	openAPI, err := renderOpenAPI(h.path)
+	if err != nil {
+		return nil, err
+	}
+	h.openAPI = openAPI
+
+	readers := []string{"ADMIN", "EDITOR", "VIEWER"}
+	editors := []string{"ADMIN", "EDITOR"}
+	h.routes = []route{
+		{endpoint(http.MethodGet, "/families", "getAllFamilies", readers, "READ", "FAMILY"), h.listFamilies},
+		{endpoint(http.MethodPost, "/families", "createFamily", editors, "CREATE", "FAMILY"), h.createFamily},
+		{endpoint(http.MethodGet, "/families/{id}", "getFamily", readers, "READ", "FAMILY"), h.getFamily},
+		{endpoint(http.MethodPut, "/families/{id}", "updateFamily", editors, "WRITE", "FAMILY"), h.updateFamily},
+		{endpoint(http.MethodDelete, "/families/{id}", "deleteFamily", []string{"ADMIN"}, "DELETE", "FAMILY"), h.deleteFamily},
+		{endpoint(http.MethodPost, "/families/{id}/parents", "addParent", editors, "CREATE", "PARENT"), h.addParent},
+		{endpoint(http.MethodPost, "/families/{id}/children", "addChild", editors, "CREATE", "CHILD"), h.addChild},
+		{endpoint(http.MethodDelete, "/families/{id}/children/{childId}", "removeChild", editors, "DELETE", "CHILD"), h.removeChild},
+	}
+	for _, rt := range h.routes {
+		h.mux.Handle(rt.Method+" "+h.path+rt.Path, h.authorize(rt))
+	}
+	h.mux.HandleFunc(http.MethodGet+" "+h.path+openAPIPath, h.serveOpenAPI)
+	return h, nil
+}
+
+// endpoint describes an endpoint with the access that its GraphQL operation requires, as
+// declared by the @isAuthorized directive of the operation
+func endpoint(method, path, operation string, roles []string, coarseScope, resource string) Endpoint {
+	return Endpoint{
+		Method:    method,
+		Path:      path,
+		Operation: operation,
+		Mutation:  method != http.MethodGet,
+		access: authz.Requirement{
+			Operation:    operation,
+			AllowedRoles: roles,
+			CoarseScopes: []string{coarseScope},
+			Resource:     resource,
+		},
+	}
+}
+
+// Path returns the path the gateway is served below
+func (h *Handler) Path() string {
+	return h.path
+}
+
+// Endpoints returns the endpoints of the gateway
+func (h *Handler) Endpoints() []Endpoint {
+	endpoints := make([]Endpoint, 0, len(h.routes))
+	for _, rt := range h.routes {
+		endpoints = append(endpoints, rt.Endpoint)
+	}
+	return endpoints
+}
+
+// ServeHTTP serves the endpoint of the request
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// authorize authorizes the caller as the GraphQL operation of the route, refuses mutations
+// during maintenance, and records the caller for the application service
+func (h *Handler) authorize(rt route) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := h.deps.Authorizer.Authorize(r.Context(), rt.access); err != nil {
+			deny(w, err)
+			return
+		}
+		if rt.Mutation {
+			if state := h.deps.Maintenance.State(); state.Enabled {
+				message := "the service is in maintenance mode; mutations are unavailable"
+				if state.Reason != "" {
+					message += ": " + state.Reason
+				}
+				writeError(w, http.StatusServiceUnavailable, maintenance.CodeMaintenanceMode, message)
+				return
+			}
+		}
+		rt.serve(w, r.WithContext(quarantine.WithCaller(r.Context())))
+	})
+}
+
+// listFamilies lists the families
+func (h *Handler) listFamilies(w http.ResponseWriter, r *http.Request) {
+	families, err := h.deps.Families.GetAllFamilies(r.Context())
+	if err != nil {
+		h.fail(w, "list families", err)
+		return
+	}
+
+	result := make([]*model.Family, 0, len(families))
+	for _, family := range families {
+		converted, err := h.toFamily(family)
+		if err != nil {
+			h.fail(w, "list families", err)
+			return
+		}
+		result = append(result, converted)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// createFamily creates a family
+func (h *Handler) createFamily(w http.ResponseWriter, r *http.Request) {
+	var input model.FamilyInput
+	if !decode(w, r, &input) || !complete(w, input) {
+		return
+	}
+	familyDTO, err := h.deps.Mapper.ToDomain(input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeValidationError, err.Error())
+		return
+	}
+
+	family, err := h.deps.Families.CreateFamily(r.Context(), familyDTO)
+	if err != nil {
+		h.fail(w, "create family", err)
+		return
+	}
+	w.Header().Set("Location", h.path+"/families/"+family.ID)
+	h.writeFamily(w, http.StatusCreated, family)
+}
+
+// getFamily returns a family
+func (h *Handler) getFamily(w http.ResponseWriter, r *http.Request) {
+	family, err := h.deps.Families.GetFamily(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.fail(w, "get family", err)
+		return
+	}
+	if family == nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, fmt.Sprintf("family %s not found", r.PathValue("id")))
+		return
+	}
+	h.writeFamily(w, http.StatusOK, family)
+}
+
+// updateFamily replaces the data of a family. The body may omit the ID of the family,
+// but must not name another family.
+func (h *Handler) updateFamily(w http.ResponseWriter, r *http.Request) {
+	var input model.FamilyInput
+	if !decode(w, r, &input) || !complete(w, input) {
+		return
+	}
+	id := r.PathValue("id")
+	if input.ID == "" {
+		input.ID = identification.ID(id)
+	}
+	if input.ID.String() != id {
+		writeError(w, http.StatusBadRequest, CodeValidationError, "the ID of the body does not match the ID of the path")
+		return
+	}
+	familyDTO, err := h.deps.Mapper.ToDomain(input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeValidationError, err.Error())
+		return
+	}
+
+	family, err := h.deps.Families.UpdateFamily(r.Context(), familyDTO)
+	if err != nil {
+		h.fail(w, "update family", err)
+		return
+	}
+	h.writeFamily(w, http.StatusOK, family)
+}
+
+// deleteFamily deletes a family, marking it as deleted unless the hard query parameter is true
+func (h *Handler) deleteFamily(w http.ResponseWriter, r *http.Request) {
+	hard := false
+	if value := r.URL.Query().Get("hard"); value != "" {
+		var err error
+		if hard, err = strconv.ParseBool(value); err != nil {
+			writeError(w, http.StatusBadRequest, CodeValidationError, "hard must be true or false")
+			return
+		}
+	}
+	if err := h.deps.Families.DeleteFamily(r.Context(), r.PathValue("id"), hard); err != nil {
+		h.fail(w, "delete family", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addParent adds a parent to a family
+func (h *Handler) addParent(w http.ResponseWriter, r *http.Request) {
+	var input model.ParentInput
+	if !decode(w, r, &input) {
+		return
+	}
+	parentDTO, err := h.deps.Mapper.ToParentDTO(input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeValidationError, err.Error())
+		return
+	}
+
+	family, err := h.deps.Families.AddParent(r.Context(), r.PathValue("id"), parentDTO)
+	if err != nil {
+		h.fail(w, "add parent", err)
+		return
+	}
+	h.writeFamily(w, http.StatusOK, family)
+}
+
+// addChild adds a child to a family
+func (h *Handler) addChild(w http.ResponseWriter, r *http.Request) {
+	var input model.ChildInput
+	if !decode(w, r, &input) {
+		return
+	}
+	childDTO, err := h.deps.Mapper.ToChildDTO(input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeValidationError, err.Error())
+		return
+	}
+
+	family, err := h.deps.Families.AddChild(r.Context(), r.PathValue("id"), childDTO)
+	if err != nil {
+		h.fail(w, "add child", err)
+		return
+	}
+	h.writeFamily(w, http.StatusOK, family)
+}
+
+// removeChild removes a child from a family
+func (h *Handler) removeChild(w http.ResponseWriter, r *http.Request) {
+	family, err := h.deps.Families.RemoveChild(r.Context(), r.PathValue("id"), r.PathValue("childId"))
+	if err != nil {
+		h.fail(w, "remove child", err)
+		return
+	}
+	h.writeFamily(w, http.StatusOK, family)
+}
+
+// serveOpenAPI returns the OpenAPI document of the gateway
+func (h *Handler) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(h.openAPI)
+}
+
+// writeFamily writes a family with its checksum as the entity tag
+func (h *Handler) writeFamily(w http.ResponseWriter, status int, family *entity.FamilyDTO) {
+	result, err := h.toFamily(family)
+	if err != nil {
+		h.fail(w, "convert family", err)
+		return
+	}
+	if result.Checksum != "" {
+		w.Header().Set("ETag", `"`+result.Checksum+`"`)
+	}
+	writeJSON(w, status, result)
+}
+
+// toFamily converts a family to its GraphQL representation, with the counts that GraphQL
+// resolves from the members
+func (h *Handler) toFamily(family *entity.FamilyDTO) (*model.Family, error) {
+	result, err := h.deps.Mapper.ToGraphQL(*family)
+	if err != nil {
+		return nil, err
+	}
+	result.ParentCount = len(result.Parents)
+	result.ChildrenCount = len(result.Children)
+	return result, nil
+}
+
+// fail writes the response of a failed operation. Expected failures are returned with the
+// code of their GraphQL user error; any other failure is logged and reported as internal.
+func (h *Handler) fail(w http.ResponseWriter, operation string, err error) {
+	status, code, message := classify(err)
+	if status == http.StatusInternalServerError {
+		h.deps.Logger.Error("REST operation failed", zap.String("operation", operation), zap.Error(err))
+	}
+	if _, retryAfter, ok := domainerrors.RetryAfter(err); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(retryafter.Seconds(retryAfter)))
+	}
+	writeError(w, status, code, message)
+}
+
+// classify returns the status, error code, and message of a failure
+func classify(err error) (int, string, string) {
+	switch {
+	case errors.Is(err, domainerrors.ErrFamilyQuarantined):
+		return http.StatusForbidden, domainerrors.FamilyQuarantinedCode, domainerrors.GetErrorMessage(err)
+	case errors.Is(err, domainerrors.ErrFamilyLocked):
+		return http.StatusConflict, domainerrors.FamilyLockedCode, domainerrors.GetErrorMessage(err)
+	case errors.Is(err, domainerrors.ErrConcurrencyConflict):
+		return http.StatusConflict, domainerrors.ConcurrencyConflictCode, domainerrors.GetErrorMessage(err)
+	case errors.Is(err, domainerrors.ErrMutationTimedOut):
+		return http.StatusGatewayTimeout, domainerrors.MutationTimedOutCode, domainerrors.GetErrorMessage(err)
+	}
+
+	// Failures that may be retried after a delay, which fail fast while the database is protected
+	if code, _, ok := domainerrors.RetryAfter(err); ok {
+		if code == domainerrors.ThrottledCode {
+			return http.StatusTooManyRequests, code, domainerrors.GetErrorMessage(err)
+		}
+		return http.StatusServiceUnavailable, code, domainerrors.GetErrorMessage(err)
+	}
+
+	// Errors of the domain layer carry their own codes
+	var domainValidationErr domainerrors.ValidationError
+	if errors.As(err, &domainValidationErr) {
+		return http.StatusBadRequest, CodeValidationError, domainValidationErr.Message()
+	}
+	var domainNotFoundErr domainerrors.NotFoundError
+	if errors.As(err, &domainNotFoundErr) {
+		return http.StatusNotFound, CodeNotFound, domainNotFoundErr.Message()
+	}
+	var domainErr domainerrors.Error
+	if errors.As(err, &domainErr) {
+		if code := model.UserErrorCode(domainErr.Code()); code.IsValid() {
+			return http.StatusUnprocessableEntity, code.String(), domainErr.Message()
+		}
+	}
+
+	// Errors raised through the service library by entities and policies
+	var validationErr *serviceerrors.ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest, CodeValidationError, validationErr.GetMessage()
+	}
+	var notFoundErr *serviceerrors.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return http.StatusNotFound, CodeNotFound, notFoundErr.GetMessage()
+	}
+	return http.StatusInternalServerError, CodeInternalError, http.StatusText(http.StatusInternalServerError)
+}
+
+// renderOpenAPI returns the OpenAPI document with the REST path as its server
+func renderOpenAPI(path string) ([]byte, error) {
+	var document map[string]interface{}
+	if err := json.Unmarshal(openAPIDocument, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse the OpenAPI document: %w", err)
+	}
+	document["servers"] = []map[string]string{{"url": path}}
+	body, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the OpenAPI document: %w", err)
+	}
+	return append(body, '\n'), nil
+}
+
+// decode decodes a JSON request body, writing the error response if it is invalid
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, CodeValidationError, "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// complete reports whether a family input has no null parents or children, which GraphQL
+// rejects before the input is converted, writing the error response if it has
+func complete(w http.ResponseWriter, input model.FamilyInput) bool {
+	for _, parent := range input.Parents {
+		if parent == nil {
+			writeError(w, http.StatusBadRequest, CodeValidationError, "parents must not be null")
+			return false
+		}
+	}
+	for _, child := range input.Children {
+		if child == nil {
+			writeError(w, http.StatusBadRequest, CodeValidationError, "children must not be null")
+			return false
+		}
+	}
+	return true
+}
+
+// deny writes the response of a caller the authorizer refused
+func deny(w http.ResponseWriter, err error) {
+	code, status := authz.CodeForbidden, http.StatusForbidden
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) && gqlErr.Extensions["code"] == authz.CodeUnauthenticated {
+		code, status = authz.CodeUnauthenticated, http.StatusUnauthorized
+	}
+	writeError(w, status, code, err.Error())
+}
+
+// writeError writes an error response with an error code
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{"code": code, "message": message},
+	})
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,415 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/application/access"
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	domainerrors "github.com/abitofhelp/family-service/core/domain/errors"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/authz"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/dto"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/maintenance"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
+	"github.com/abitofhelp/servicelib/auth/middleware"
+	serviceerrors "github.com/abitofhelp/servicelib/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubFamilies keeps families in memory and records the caller of the last operation
+type stubFamilies struct {
+	families map[string]entity.FamilyDTO
+	caller   access.Caller
+	err      error
+}
+
+func newStubFamilies(families ...entity.FamilyDTO) *stubFamilies {
+	s := &stubFamilies{families: map[string]entity.FamilyDTO{}}
+	for _, family := range families {
+		s.families[family.ID] = family
+	}
+	return s
+}
+
+func (s *stubFamilies) get(ctx context.Context, id string) (*entity.FamilyDTO, error) {
+	s.caller = access.CallerFrom(ctx)
+	if s.err != nil {
+		return nil, s.err
+	}
+	family, ok := s.families[id]
+	if !ok {
+		return nil, domainerrors.NewNotFoundError("Family", id, nil)
+	}
+	return &family, nil
+}
+
+func (s *stubFamilies) put(ctx context.Context, family entity.FamilyDTO) (*entity.FamilyDTO, error) {
+	s.caller = access.CallerFrom(ctx)
+	if s.err != nil {
+		return nil, s.err
+	}
+	family.ParentCount, family.ChildrenCount = len(family.Parents), len(family.Children)
+	s.families[family.ID] = family
+	return &family, nil
+}
+
+func (s *stubFamilies) CreateFamily(ctx context.Context, family entity.FamilyDTO) (*entity.FamilyDTO, error) {
+	return s.put(ctx, family)
+}
+
+func (s *stubFamilies) GetFamily(ctx context.Context, id string) (*entity.FamilyDTO, error) {
+	return s.get(ctx, id)
+}
+
+func (s *stubFamilies) GetAllFamilies(ctx context.Context) ([]*entity.FamilyDTO, error) {
+	s.caller = access.CallerFrom(ctx)
+	var families []*entity.FamilyDTO
+	for _, family := range s.families {
+		family := family
+		families = append(families, &family)
+	}
+	return families, s.err
+}
+
+func (s *stubFamilies) UpdateFamily(ctx context.Context, family entity.FamilyDTO) (*entity.FamilyDTO, error) {
+	if _, err := s.get(ctx, family.ID); err != nil {
+		return nil, err
+	}
+	return s.put(ctx, family)
+}
+
+func (s *stubFamilies) DeleteFamily(ctx context.Context, id string, hard bool) error {
+	if _, err := s.get(ctx, id); err != nil {
+		return err
+	}
+	delete(s.families, id)
+	return nil
+}
+
+func (s *stubFamilies) AddParent(ctx context.Context, familyID string, parent entity.ParentDTO) (*entity.FamilyDTO, error) {
+	family, err := s.get(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(family.Parents) == 2 {
+		return nil, domainerrors.NewFamilyTooManyParentsError("family already has two parents", nil)
+	}
+	family.Parents = append(family.Parents, parent)
+	return s.put(ctx, *family)
+}
+
+func (s *stubFamilies) AddChild(ctx context.Context, familyID string, child entity.ChildDTO) (*entity.FamilyDTO, error) {
+	family, err := s.get(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	family.Children = append(family.Children, child)
+	return s.put(ctx, *family)
+}
+
+func (s *stubFamilies) RemoveChild(ctx context.Context, familyID string, childID string) (*entity.FamilyDTO, error) {
+	family, err := s.get(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	for i, child := range family.Children {
+		if child.ID == childID {
+			family.Children = append(family.Children[:i:i], family.Children[i+1:]...)
+			return s.put(ctx, *family)
+		}
+	}
+	return nil, domainerrors.NewNotFoundError("Child", childID, nil)
+}
+
+// testFamily returns a divorced family with one parent and one adult child
+func testFamily(id string) entity.FamilyDTO {
+	return entity.FamilyDTO{
+		ID:     id,
+		Status: "DIVORCED",
+		Parents: []entity.ParentDTO{
+			{ID: "parent-1", FirstName: "Jane", LastName: "Doe", BirthDate: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		Children: []entity.ChildDTO{
+			{ID: "child-1", FirstName: "Jim", LastName: "Doe", BirthDate: time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		ParentCount:   1,
+		ChildrenCount: 1,
+	}
+}
+
+// caller authenticates the requests with the given roles and every scope
+func caller(userID string, roles ...string) func(*http.Request) *http.Request {
+	scopes := make([]string, 0, len(authz.Scopes))
+	for _, scope := range authz.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return func(r *http.Request) *http.Request {
+		ctx := middleware.WithUserID(r.Context(), userID)
+		ctx = middleware.WithUserRoles(ctx, roles)
+		ctx = middleware.WithUserScopes(ctx, scopes)
+		return r.WithContext(ctx)
+	}
+}
+
+var (
+	admin  = caller("admin-1", "ADMIN")
+	editor = caller("editor-1", "EDITOR")
+	viewer = caller("viewer-1", "VIEWER")
+)
+
+// newTestHandler creates a gateway below /rest over the given families
+func newTestHandler(t *testing.T, families Families, mode *maintenance.Mode) *Handler {
+	t.Helper()
+	h, err := NewHandler("/rest/", Dependencies{
+		Families:    families,
+		Mapper:      dto.NewFamilyMapper(),
+		Authorizer:  authz.NewAuthorizer(false),
+		Maintenance: mode,
+	})
+	require.NoError(t, err)
+	return h
+}
+
+// serve sends a request to the handler as a caller
+func serve(h http.Handler, as func(*http.Request) *http.Request, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if as != nil {
+		req = as(req)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// errorCode returns the error code of an error response
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Error struct{ Code string } `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+	return body.Error.Code
+}
+
+// decodeFamily decodes a family response
+func decodeFamily(t *testing.T, rec *httptest.ResponseRecorder) model.Family {
+	t.Helper()
+	var family model.Family
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &family), rec.Body.String())
+	return family
+}
+
+func TestHandler_Families(t *testing.T) {
+	families := newStubFamilies(testFamily("family-1"))
+	h := newTestHandler(t, families, maintenance.NewMode())
+	assert.Equal(t, "/rest", h.Path())
+
+	rec := serve(h, viewer, http.MethodGet, "/rest/families/family-1", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	family := decodeFamily(t, rec)
+	assert.Equal(t, "family-1", family.ID.String())
+	assert.Equal(t, `"`+family.Checksum+`"`, rec.Header().Get("ETag"))
+	require.Len(t, family.Parents, 1)
+	assert.Equal(t, "1970-01-01", family.Parents[0].BirthDate.String())
+
+	rec = serve(h, viewer, http.MethodGet, "/rest/families", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list []model.Family
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Len(t, list, 1)
+
+	rec = serve(h, editor, http.MethodPost, "/rest/families", `{
+		"id": "family-2",
+		"status": "MARRIED",
+		"parents": [
+			{"id": "parent-2", "firstName": "John", "lastName": "Roe", "birthDate": "1970-02-02"},
+			{"id": "parent-3", "firstName": "Joan", "lastName": "Roe", "birthDate": "1971-03-03"}
+		],
+		"children": []
+	}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "/rest/families/family-2", rec.Header().Get("Location"))
+	assert.Equal(t, 2, decodeFamily(t, rec).ParentCount)
+
+	rec = serve(h, editor, http.MethodPost, "/rest/families/family-1/children",
+		`{"id": "child-2", "firstName": "Ann", "lastName": "Doe", "birthDate": "1998-04-04"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 2, decodeFamily(t, rec).ChildrenCount)
+
+	rec = serve(h, editor, http.MethodDelete, "/rest/families/family-1/children/child-1", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "child-2", decodeFamily(t, rec).Children[0].ID.String())
+
+	rec = serve(h, editor, http.MethodPost, "/rest/families/family-2/parents",
+		`{"id": "parent-4", "firstName": "Jo", "lastName": "Roe", "birthDate": "1972-05-05"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, domainerrors.FamilyTooManyParentsCode, errorCode(t, rec))
+
+	// The ID of the family may be omitted on update, but must match the path
+	update := `{"status": "MARRIED", "parents": [{"id": "parent-2", "firstName": "John", "lastName": "Roe", "birthDate": "1970-02-02"}], "children": []}`
+	rec = serve(h, editor, http.MethodPut, "/rest/families/family-2", update)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 1, decodeFamily(t, rec).ParentCount)
+	rec = serve(h, editor, http.MethodPut, "/rest/families/family-2", `{"id": "family-1", "status": "MARRIED", "parents": [], "children": []}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(h, admin, http.MethodDelete, "/rest/families/family-2?hard=maybe", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serve(h, admin, http.MethodDelete, "/rest/families/family-2?hard=true", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = serve(h, viewer, http.MethodGet, "/rest/families/family-2", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, CodeNotFound, errorCode(t, rec))
+}
+
+func TestHandler_InvalidBodies(t *testing.T) {
+	h := newTestHandler(t, newStubFamilies(testFamily("family-1")), maintenance.NewMode())
+
+	for name, body := range map[string]string{
+		"not JSON":     `{"id": `,
+		"null parent":  `{"id": "family-2", "status": "SINGLE", "parents": [null], "children": []}`,
+		"null child":   `{"id": "family-2", "status": "SINGLE", "parents": [], "children": [null]}`,
+		"missing ID":   `{"status": "MARRIED", "parents": [], "children": []}`,
+		"invalid date": `{"id": "family-2", "status": "MARRIED", "parents": [{"id": "p", "firstName": "A", "lastName": "B", "birthDate": "1 May"}], "children": []}`,
+	} {
+		rec := serve(h, editor, http.MethodPost, "/rest/families", body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, name)
+		assert.Equal(t, CodeValidationError, errorCode(t, rec), name)
+	}
+}
+
+func TestHandler_Authorization(t *testing.T) {
+	families := newStubFamilies(testFamily("family-1"))
+	h := newTestHandler(t, families, maintenance.NewMode())
+
+	rec := serve(h, nil, http.MethodGet, "/rest/families", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, authz.CodeUnauthenticated, errorCode(t, rec))
+
+	rec = serve(h, viewer, http.MethodDelete, "/rest/families/family-1/children/child-1", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, authz.CodeForbidden, errorCode(t, rec))
+
+	// Only administrators delete families, as through GraphQL
+	rec = serve(h, editor, http.MethodDelete, "/rest/families/family-1", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// A caller without the scope of the operation is refused
+	noScopes := func(r *http.Request) *http.Request {
+		return r.WithContext(middleware.WithUserRoles(middleware.WithUserID(r.Context(), "editor-2"), []string{"EDITOR"}))
+	}
+	assert.Equal(t, http.StatusForbidden, serve(h, noScopes, http.MethodGet, "/rest/families", "").Code)
+
+	// The caller is recorded for the quarantine checks of the application service
+	serve(h, admin, http.MethodGet, "/rest/families/family-1", "")
+	assert.Equal(t, access.Caller{ID: "admin-1", Admin: true}, families.caller)
+	serve(h, viewer, http.MethodGet, "/rest/families/family-1", "")
+	assert.Equal(t, access.Caller{ID: "viewer-1"}, families.caller)
+}
+
+func TestHandler_Maintenance(t *testing.T) {
+	mode := maintenance.NewMode()
+	mode.Set(true, "database upgrade")
+	h := newTestHandler(t, newStubFamilies(testFamily("family-1")), mode)
+
+	rec := serve(h, admin, http.MethodDelete, "/rest/families/family-1", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, maintenance.CodeMaintenanceMode, errorCode(t, rec))
+	assert.Contains(t, rec.Body.String(), "database upgrade")
+
+	// Reads are served during maintenance
+	assert.Equal(t, http.StatusOK, serve(h, viewer, http.MethodGet, "/rest/families/family-1", "").Code)
+}
+
+func TestHandler_Routing(t *testing.T) {
+	h := newTestHandler(t, newStubFamilies(), maintenance.NewMode())
+
+	rec := serve(h, admin, http.MethodPatch, "/rest/families", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Contains(t, rec.Header().Get("Allow"), http.MethodPost)
+
+	assert.Equal(t, http.StatusNotFound, serve(h, admin, http.MethodGet, "/rest/people", "").Code)
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("get: %w", domainerrors.NewFamilyQuarantinedError("family is quarantined", nil)), http.StatusForbidden, domainerrors.FamilyQuarantinedCode},
+		{domainerrors.NewFamilyLockedError("family is locked", nil), http.StatusConflict, domainerrors.FamilyLockedCode},
+		{domainerrors.NewDatabaseError("save failed", "save", "families", domainerrors.NewConcurrencyError("family was changed", nil)), http.StatusConflict, domainerrors.ConcurrencyConflictCode},
+		{domainerrors.NewMutationTimedOutError("timed out", nil), http.StatusGatewayTimeout, domainerrors.MutationTimedOutCode},
+		{fmt.Errorf("get: %w", domainerrors.NewThrottledError("rate limit exceeded for sqlite", time.Second, nil)), http.StatusTooManyRequests, domainerrors.ThrottledCode},
+		{domainerrors.NewDatabaseError("query failed", "find", "families", domainerrors.NewUpstreamUnavailableError("circuit breaker sqlite is open", time.Second, nil)), http.StatusServiceUnavailable, domainerrors.UpstreamUnavailableCode},
+		{domainerrors.NewValidationError("invalid", "firstName", nil), http.StatusBadRequest, CodeValidationError},
+		{domainerrors.NewNotFoundError("Family", "family-1", nil), http.StatusNotFound, CodeNotFound},
+		{domainerrors.NewFamilyNotMarriedError("not married", nil), http.StatusUnprocessableEntity, domainerrors.FamilyNotMarriedCode},
+		{serviceerrors.NewValidationError("invalid", "lastName", nil), http.StatusBadRequest, CodeValidationError},
+		{serviceerrors.NewNotFoundError("Family", "family-1", nil), http.StatusNotFound, CodeNotFound},
+		{domainerrors.NewDatabaseError("connection lost", "find", "families", nil), http.StatusInternalServerError, CodeInternalError},
+		{errors.New("boom"), http.StatusInternalServerError, CodeInternalError},
+	}
+	for _, tt := range tests {
+		status, code, _ := classify(tt.err)
+		assert.Equal(t, tt.status, status, tt.err.Error())
+		assert.Equal(t, tt.code, code, tt.err.Error())
+	}
+}
+
+func TestHandler_RetryAfter(t *testing.T) {
+	families := newStubFamilies(testFamily("family-1"))
+	h := newTestHandler(t, families, maintenance.NewMode())
+
+	// An open circuit breaker is reported with the time until it is expected to close
+	families.err = fmt.Errorf("failed to get family: %w", domainerrors.NewUpstreamUnavailableError("circuit breaker sqlite is open", 2500*time.Millisecond, nil))
+	rec := serve(h, viewer, http.MethodGet, "/rest/families/family-1", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, domainerrors.UpstreamUnavailableCode, errorCode(t, rec))
+	assert.Equal(t, "3", rec.Header().Get("Retry-After"))
+
+	// Other failures carry no Retry-After header
+	families.err = domainerrors.NewFamilyLockedError("family is locked", nil)
+	rec = serve(h, viewer, http.MethodGet, "/rest/families/family-1", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Empty(t, rec.Header().Get("Retry-After"))
+}
+
+// TestOpenAPI fails when an endpoint is not documented as its operation
+func TestOpenAPI(t *testing.T) {
+	h := newTestHandler(t, newStubFamilies(), maintenance.NewMode())
+
+	// The document is public, so that tools can load it before signing in
+	rec := serve(h, nil, http.MethodGet, "/rest/openapi.json", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var document struct {
+		Servers []struct{ URL string } `json:"servers"`
+		Paths   map[string]map[string]json.RawMessage
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &document))
+	require.Len(t, document.Servers, 1)
+	assert.Equal(t, "/rest", document.Servers[0].URL)
+
+	for _, e := range h.Endpoints() {
+		raw, ok := document.Paths[e.Path][strings.ToLower(e.Method)]
+		if !assert.True(t, ok, "%s %s is not documented", e.Method, e.Path) {
+			continue
+		}
+		var operation struct{ OperationID string }
+		require.NoError(t, json.Unmarshal(raw, &operation))
+		assert.Equal(t, e.Operation, operation.OperationID, "%s %s", e.Method, e.Path)
+		_, ok = authz.Operations[e.Operation]
+		assert.True(t, ok, "%s has no authorization rule", e.Operation)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Images of the database containers started for the integration tests
+const (
+	postgresImage = "postgres:16-alpine"
+	mongoImage    = "mongo:7"
+)
+
+// containerPassword is the password of the databases in the test containers
+const containerPassword = "integration-test-password"
+
+// containerStartTimeout bounds how long a database container may take to accept connections
+const containerStartTimeout = 2 * time.Minute
+
+// databaseContainer is a database started in a Docker container for the integration tests.
+// Containers are shared by the tests of a run and removed by TestMain.
+type databaseContainer struct {
+	once sync.Once
+	id   string
+	addr string // Host address of the published database port
+	err  error
+}
+
+var (
+	postgresContainer databaseContainer
+	mongoContainer    databaseContainer
+)
+
+// TestMain runs the integration tests and removes the database containers they started
+func TestMain(m *testing.M) {
+	code := m.Run()
+	for _, c := range []*databaseContainer{&postgresContainer, &mongoContainer} {
+		c.remove()
+	}
+	os.Exit(code)
+}
+
+// dockerAvailable reports whether the docker CLI is installed and can reach a Docker daemon
+func dockerAvailable() bool {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx, "docker", "info").Run() == nil
+}
+
+// start starts the container once and waits until ready succeeds. It skips the test if
+// Docker is not available and fails it if the container cannot be started.
+func (c *databaseContainer) start(t *testing.T, image, port string, env []string, ready func(ctx context.Context, addr string) error) string {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("Skipping container-based integration test in short mode")
+	}
+
+	c.once.Do(func() {
+		if !dockerAvailable() {
+			c.err = errDockerUnavailable
+			return
+		}
+
+		args := []string{"run", "--detach", "--rm", "--publish", "127.0.0.1::" + port}
+		for _, e := range env {
+			args = append(args, "--env", e)
+		}
+		args = append(args, image)
+
+		out, err := exec.Command("docker", args...).Output()
+		if err != nil {
+			c.err = fmt.Errorf("failed to start %s: %w", image, commandError(err))
+			return
+		}
+		c.id = strings.TrimSpace(string(out))
+
+		out, err = exec.Command("docker", "port", c.id, port+"/tcp").Output()
+		if err != nil {
+			c.err = fmt.Errorf("failed to get published port of %s: %w", image, commandError(err))
+			return
+		}
+		c.addr = strings.TrimSpace(strings.Split(string(out), "\n")[0])
+
+		// Wait until the database accepts connections
+		ctx, cancel := context.WithTimeout(context.Background(), containerStartTimeout)
+		defer cancel()
+		for {
+			attemptCtx, attemptCancel := context.WithTimeout(ctx, 5*time.Second)
+			err = ready(attemptCtx, c.addr)
+			attemptCancel()
+			if err == nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				c.err = fmt.Errorf("%s did not become ready: %w", image, err)
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	})
+
+	if c.err == errDockerUnavailable {
+		t.Skip("Skipping container-based integration test: Docker is not available")
+	}
+	if c.err != nil {
+		t.Fatalf("Failed to start database container: %v", c.err)
+	}
+	return c.addr
+}
+
+// remove removes the container if it was started
+func (c *databaseContainer) remove() {
+	if c.id == "" {
+		return
+	}
+	_ = exec.Command("docker", "rm", "--force", c.id).Run()
+}
+
+// errDockerUnavailable is recorded when a container is not started because Docker is not available
+var errDockerUnavailable = fmt.Errorf("docker is not available")
+
+// commandError adds the standard error output of a failed command to its error
+func commandError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
+
+// postgresContainerDSN starts a PostgreSQL container and returns its DSN
+func postgresContainerDSN(t *testing.T) string {
+	t.Helper()
+
+	addr := postgresContainer.start(t, postgresImage, "5432",
+		[]string{"POSTGRES_PASSWORD=" + containerPassword, "POSTGRES_DB=family_service"},
+		func(ctx context.Context, addr string) error {
+			pool, err := pgxpool.New(ctx, postgresDSN(addr))
+			if err != nil {
+				return err
+			}
+			defer pool.Close()
+			return pool.Ping(ctx)
+		})
+	return postgresDSN(addr)
+}
+
+// postgresDSN returns the DSN of the PostgreSQL container at addr
+func postgresDSN(addr string) string {
+	return fmt.Sprintf("postgres://postgres:%s@%s/family_service?sslmode=disable", containerPassword, addr)
+}
+
+// mongoContainerURI starts a MongoDB container and returns its URI
+func mongoContainerURI(t *testing.T) string {
+	t.Helper()
+
+	addr := mongoContainer.start(t, mongoImage, "27017",
+		[]string{"MONGO_INITDB_ROOT_USERNAME=root", "MONGO_INITDB_ROOT_PASSWORD=" + containerPassword},
+		func(ctx context.Context, addr string) error {
+			client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI(addr)))
+			if err != nil {
+				return err
+			}
+			defer client.Disconnect(context.Background())
+			return client.Ping(ctx, nil)
+		})
+	return mongoURI(addr)
+}
+
+// mongoURI returns the URI of the MongoDB container at addr
+func mongoURI(addr string) string {
+	return fmt.Sprintf("mongodb://root:%s@%s/family_service?authSource=admin", containerPassword, addr)
+}
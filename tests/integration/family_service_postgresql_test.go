@@ -26,28 +26,16 @@
 )
 
 // setupPostgreSQLTestConfig creates a test configuration with PostgreSQL database
-func setupPostgreSQLTestConfig() *config.Config {
+func setupPostgreSQLTestConfig(t *testing.T) *config.Config {
 	cfg := &config.Config{}
 
 	// Set up database config
 	cfg.Database.Type = "postgres"
 
-	// Use environment variable for PostgreSQL DSN if available, otherwise use default with authentication
+	// Use environment variable for PostgreSQL DSN if available, otherwise start a PostgreSQL container
 	postgresDSN := os.Getenv("POSTGRES_TEST_DSN")
 	if postgresDSN == "" {
-		// Get PostgreSQL credentials from environment variables or use defaults from secrets
-		username := os.Getenv("POSTGRESQL_USERNAME")
-		if username == "" {
-			username = "postgres"
-		}
-
-		password := os.Getenv("POSTGRESQL_PASSWORD")
-		if password == "" {
-			password = "NVsHFXcxqUsMoEgiUnE7jvzXxhp3gn9nsgkXCsetAHLhcpyLRmWhKixUpfr3J7tE"
-		}
-
-		// Create PostgreSQL DSN with authentication
-		postgresDSN = fmt.Sprintf("postgres://%s:%s@localhost:5432/family_service?sslmode=disable", username, password)
+		postgresDSN = postgresContainerDSN(t)
 	}
 	cfg.Database.Postgres.DSN = postgresDSN
 
@@ -79,7 +67,7 @@ func setupPostgreSQLTestContainer(t *testing.T) *di.Container {
 	ctx := context.Background()
 
 	// Create config
-	cfg := setupPostgreSQLTestConfig()
+	cfg := setupPostgreSQLTestConfig(t)
 
 	// Log the PostgreSQL DSN for debugging
 	t.Logf("Using PostgreSQL DSN: %s", cfg.Database.Postgres.DSN)
@@ -151,6 +139,7 @@ func setupPostgreSQLTestServer(t *testing.T, container *di.Container) *httptest.
 	resolverObj := resolver.NewResolver(
 		container.GetFamilyApplicationService(),
 		container.GetFamilyMapper(),
+		container.GetAuthorizer(),
 	)
 
 	// Create GraphQL handler
@@ -315,14 +304,14 @@ func TestIntegrationCreateFamilyPostgreSQL(t *testing.T) {
 	assert.NotEmpty(t, parent1["id"], "Parent ID should not be empty")
 	assert.Equal(t, "John", parent1["firstName"], "First name should be John")
 	assert.Equal(t, "Smith", parent1["lastName"], "Last name should be Smith")
-	assert.Equal(t, "1980-01-01T00:00:00Z", parent1["birthDate"], "Birth date should be 1980-01-01T00:00:00Z")
+	assert.Equal(t, "1980-01-01", parent1["birthDate"], "Birth date should be 1980-01-01")
 
 	// Check second parent
 	parent2 := parents[1].(map[string]interface{})
 	assert.NotEmpty(t, parent2["id"], "Parent ID should not be empty")
 	assert.Equal(t, "Jane", parent2["firstName"], "First name should be Jane")
 	assert.Equal(t, "Smith", parent2["lastName"], "Last name should be Smith")
-	assert.Equal(t, "1982-02-02T00:00:00Z", parent2["birthDate"], "Birth date should be 1982-02-02T00:00:00Z")
+	assert.Equal(t, "1982-02-02", parent2["birthDate"], "Birth date should be 1982-02-02")
 
 	// Check children
 	children, hasChildren := createFamily["children"].([]interface{})
@@ -334,14 +323,14 @@ func TestIntegrationCreateFamilyPostgreSQL(t *testing.T) {
 	assert.NotEmpty(t, child1["id"], "Child ID should not be empty")
 	assert.Equal(t, "Jimmy", child1["firstName"], "First name should be Jimmy")
 	assert.Equal(t, "Smith", child1["lastName"], "Last name should be Smith")
-	assert.Equal(t, "2010-03-03T00:00:00Z", child1["birthDate"], "Birth date should be 2010-03-03T00:00:00Z")
+	assert.Equal(t, "2010-03-03", child1["birthDate"], "Birth date should be 2010-03-03")
 
 	// Check second child
 	child2 := children[1].(map[string]interface{})
 	assert.NotEmpty(t, child2["id"], "Child ID should not be empty")
 	assert.Equal(t, "Sally", child2["firstName"], "First name should be Sally")
 	assert.Equal(t, "Smith", child2["lastName"], "Last name should be Smith")
-	assert.Equal(t, "2012-04-04T00:00:00Z", child2["birthDate"], "Birth date should be 2012-04-04T00:00:00Z")
+	assert.Equal(t, "2012-04-04", child2["birthDate"], "Birth date should be 2012-04-04")
 
 	// Check counts
 	parentCount, hasParentCount := createFamily["parentCount"].(float64)
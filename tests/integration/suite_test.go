@@ -0,0 +1,233 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/abitofhelp/family-service/cmd/server/graphql/di"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/repositorytest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backend sets up the DI container and GraphQL server for one database backend
+type backend struct {
+	name      string
+	skipEnv   string // Environment variable that skips the backend when set to "true"
+	container func(t *testing.T) *di.Container
+	server    func(t *testing.T, container *di.Container) *httptest.Server
+}
+
+// backends are the database backends the suites run against. PostgreSQL and MongoDB run in
+// Docker containers unless POSTGRES_TEST_DSN or MONGODB_TEST_URI is set, and are skipped
+// when Docker is not available.
+var backends = []backend{
+	{name: "SQLite", container: setupTestContainer, server: setupTestServer},
+	{name: "PostgreSQL", skipEnv: "SKIP_POSTGRESQL_TESTS", container: setupPostgreSQLTestContainer, server: setupPostgreSQLTestServer},
+	{name: "MongoDB", skipEnv: "SKIP_MONGODB_TESTS", container: setupMongoDBTestContainer, server: setupMongoDBTestServer},
+}
+
+// skip skips the test if the backend is disabled
+func (b backend) skip(t *testing.T) {
+	if b.skipEnv != "" && os.Getenv(b.skipEnv) == "true" {
+		t.Skipf("Skipping %s tests", b.name)
+	}
+}
+
+// TestIntegrationRepositoryConformance runs the repository conformance suite against each backend
+func TestIntegrationRepositoryConformance(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			b.skip(t)
+			container := b.container(t)
+			defer container.Close()
+
+			repositorytest.Run(t, container.GetFamilyRepository())
+		})
+	}
+}
+
+// TestIntegrationGraphQLSuite runs the GraphQL integration suite against each backend
+func TestIntegrationGraphQLSuite(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			b.skip(t)
+			container := b.container(t)
+			defer container.Close()
+			server := b.server(t, container)
+			defer server.Close()
+
+			runGraphQLSuite(t, container, server)
+		})
+	}
+}
+
+// runGraphQLSuite exercises the main queries and mutations of the API end to end
+func runGraphQLSuite(t *testing.T, container *di.Container, server *httptest.Server) {
+	editor := generateScopedToken(t, container, "EDITOR", "family:read", "parent:read", "child:read", "family:create", "child:add", "child:remove")
+	viewer := generateScopedToken(t, container, "VIEWER", "family:read", "parent:read", "child:read")
+
+	familyID, parentID, otherParentID, childID := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+
+	t.Run("create family", func(t *testing.T) {
+		data := graphqlOK(t, server, editor, fmt.Sprintf(`mutation {
+			createFamily(input: {
+				id: %q
+				status: MARRIED
+				parents: [
+					{id: %q, firstName: "Ada", lastName: "Suite", birthDate: "1980-01-02T00:00:00Z"}
+					{id: %q, firstName: "Cy", lastName: "Suite", birthDate: "1981-05-06T00:00:00Z"}
+				]
+				children: []
+			}) { id status parentCount childrenCount }
+		}`, familyID, parentID, otherParentID))
+
+		family := data["createFamily"].(map[string]interface{})
+		assert.Equal(t, familyID, family["id"])
+		assert.Equal(t, "MARRIED", family["status"])
+		assert.EqualValues(t, 2, family["parentCount"])
+		assert.EqualValues(t, 0, family["childrenCount"])
+	})
+
+	t.Run("add child", func(t *testing.T) {
+		data := graphqlOK(t, server, editor, fmt.Sprintf(`mutation {
+			addChild(familyId: %q, input: {id: %q, firstName: "Bo", lastName: "Suite", birthDate: "2010-03-04T00:00:00Z"}) {
+				children { id firstName }
+			}
+		}`, familyID, childID))
+
+		children := data["addChild"].(map[string]interface{})["children"].([]interface{})
+		require.Len(t, children, 1)
+		assert.Equal(t, childID, children[0].(map[string]interface{})["id"])
+	})
+
+	t.Run("queries", func(t *testing.T) {
+		data := graphqlOK(t, server, viewer, fmt.Sprintf(`query {
+			getFamily(id: %q) { id childrenCount }
+			findFamilyByChild(childId: %q) { id }
+			findFamiliesByParent(parentId: %q) { id }
+			countFamilies
+		}`, familyID, childID, parentID))
+
+		assert.EqualValues(t, 1, data["getFamily"].(map[string]interface{})["childrenCount"])
+		assert.Equal(t, familyID, data["findFamilyByChild"].(map[string]interface{})["id"])
+		byParent := data["findFamiliesByParent"].([]interface{})
+		require.Len(t, byParent, 1)
+		assert.Equal(t, familyID, byParent[0].(map[string]interface{})["id"])
+		assert.GreaterOrEqual(t, data["countFamilies"].(float64), float64(1))
+	})
+
+	t.Run("remove child", func(t *testing.T) {
+		before := graphqlOK(t, server, viewer, fmt.Sprintf(`query { getFamily(id: %q) { checksum } }`, familyID))
+		unchanged := graphqlOK(t, server, viewer, fmt.Sprintf(`query { getFamily(id: %q) { checksum } }`, familyID))
+		assert.Equal(t, before["getFamily"], unchanged["getFamily"], "the checksum is stable while the family is unchanged")
+
+		data := graphqlOK(t, server, editor, fmt.Sprintf(`mutation {
+			removeChild(familyId: %q, childId: %q) { childrenCount checksum }
+		}`, familyID, childID))
+
+		removed := data["removeChild"].(map[string]interface{})
+		assert.EqualValues(t, 0, removed["childrenCount"])
+		assert.NotEqual(t, before["getFamily"].(map[string]interface{})["checksum"], removed["checksum"], "the checksum changes with the family")
+	})
+
+	t.Run("payload mutations report expected failures as user errors", func(t *testing.T) {
+		query := fmt.Sprintf(`mutation {
+			addChildV2(familyId: %q, input: {id: %q, firstName: "Di", lastName: "Suite", birthDate: "2012-07-08T00:00:00Z"}) {
+				family { childrenCount }
+				userErrors { code message }
+			}
+		}`, familyID, childID)
+
+		payload := graphqlOK(t, server, editor, query)["addChildV2"].(map[string]interface{})
+		assert.Empty(t, payload["userErrors"])
+		assert.EqualValues(t, 1, payload["family"].(map[string]interface{})["childrenCount"])
+
+		payload = graphqlOK(t, server, editor, query)["addChildV2"].(map[string]interface{})
+		assert.Nil(t, payload["family"])
+		userErrors := payload["userErrors"].([]interface{})
+		require.Len(t, userErrors, 1)
+		assert.Equal(t, "FAMILY_CHILD_EXISTS", userErrors[0].(map[string]interface{})["code"])
+
+		payload = graphqlOK(t, server, editor, fmt.Sprintf(`mutation {
+			removeChildV2(familyId: %q, childId: %q) { family { id } userErrors { code } }
+		}`, uuid.NewString(), childID))["removeChildV2"].(map[string]interface{})
+		userErrors = payload["userErrors"].([]interface{})
+		require.Len(t, userErrors, 1)
+		assert.Equal(t, "NOT_FOUND", userErrors[0].(map[string]interface{})["code"])
+	})
+
+	t.Run("missing scope is forbidden", func(t *testing.T) {
+		errs := graphqlErrors(t, server, viewer, fmt.Sprintf(`mutation {
+			removeChild(familyId: %q, childId: %q) { id }
+		}`, familyID, uuid.NewString()))
+
+		require.NotEmpty(t, errs)
+		extensions, _ := errs[0]["extensions"].(map[string]interface{})
+		assert.Equal(t, "FORBIDDEN", extensions["code"])
+	})
+}
+
+// generateScopedToken generates a JWT token with the given role and per-operation scopes
+func generateScopedToken(t *testing.T, container *di.Container, role string, scopes ...string) string {
+	t.Helper()
+
+	token, err := container.GetAuthService().GenerateToken(context.Background(), "suite-"+strings.ToLower(role), []string{role}, scopes, nil)
+	require.NoError(t, err)
+	return token
+}
+
+// graphqlRequest sends a GraphQL request and returns the data and errors of the response
+func graphqlRequest(t *testing.T, server *httptest.Server, token, query string) (map[string]interface{}, []map[string]interface{}) {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(string(body)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var result struct {
+		Data   map[string]interface{}   `json:"data"`
+		Errors []map[string]interface{} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &result), "response: %s", raw)
+	return result.Data, result.Errors
+}
+
+// graphqlOK sends a GraphQL request that must succeed and returns the data of the response
+func graphqlOK(t *testing.T, server *httptest.Server, token, query string) map[string]interface{} {
+	t.Helper()
+
+	data, errs := graphqlRequest(t, server, token, query)
+	require.Empty(t, errs, "GraphQL errors")
+	require.NotNil(t, data)
+	return data
+}
+
+// graphqlErrors sends a GraphQL request that must fail and returns the errors of the response
+func graphqlErrors(t *testing.T, server *httptest.Server, token, query string) []map[string]interface{} {
+	t.Helper()
+
+	_, errs := graphqlRequest(t, server, token, query)
+	return errs
+}
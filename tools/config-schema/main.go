@@ -0,0 +1,71 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Command config-schema emits the JSON Schema of the service configuration and
+// validates rendered configuration files against it.
+//
+// Without flags, the schema is written to standard output (or to the file given
+// with -o), so that it can be published alongside Terraform or OpenTofu modules
+// and used by editors and pipelines to check generated configuration. With
+// -validate, each file is validated as the service would load it; -strict also
+// rejects unknown keys and values of the wrong type. The exit status is 1 if any
+// file is invalid.
+//
+// Usage:
+//
+//	config-schema -o config/config.schema.json
+//	config-schema -validate -strict rendered/config.prod.yaml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+)
+
+func main() {
+	output := flag.String("o", "", "file to write the schema to (default standard output)")
+	validate := flag.Bool("validate", false, "validate the configuration files given as arguments instead of emitting the schema")
+	strict := flag.Bool("strict", false, "with -validate, also reject unknown keys and values of the wrong type")
+	flag.Parse()
+
+	if *validate {
+		os.Exit(validateFiles(flag.Args(), *strict))
+	}
+
+	schema, err := config.SchemaJSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(schema)
+		return
+	}
+	if err := os.WriteFile(*output, schema, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write schema: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// validateFiles validates each configuration file and returns the exit status
+func validateFiles(paths []string, strict bool) int {
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "-validate requires at least one configuration file")
+		flag.Usage()
+		return 2
+	}
+
+	status := 0
+	for _, path := range paths {
+		if _, err := config.ValidateFile(path, strict); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: invalid\n%v\n", path, err)
+			status = 1
+			continue
+		}
+		fmt.Printf("%s: valid\n", path)
+	}
+	return status
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Command event-schema publishes the JSON Schemas of the domain events of the service.
+//
+// The schema of the current version of each event type is written to the schemas
+// directory, as <type>.v<version>.json. Schemas of older versions are kept, so that
+// consumers and the startup check can rely on them. A schema is not written if it is
+// incompatible with the published schema of the same version; the exit status is then 1
+// and the event type needs a new version with an upcaster instead.
+//
+// Usage:
+//
+//	event-schema
+//	event-schema -dir infrastructure/adapters/eventschema/schemas
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/abitofhelp/family-service/infrastructure/adapters/eventschema"
+)
+
+func main() {
+	dir := flag.String("dir", "infrastructure/adapters/eventschema/schemas", "directory of the published schemas")
+	flag.Parse()
+
+	registry := eventschema.NewDefaultRegistry("event-schema")
+	status := 0
+	for _, name := range registry.Types() {
+		if err := publish(registry, name, *dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+// publish writes the schema of the current version of an event type, unless it is
+// incompatible with the published schema of the same version
+func publish(registry *eventschema.Registry, name, dir string) error {
+	data, version, err := registry.SchemaJSON(name)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, eventschema.FileName(name, version))
+
+	published, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	default:
+		var before, after map[string]interface{}
+		if err := json.Unmarshal(published, &before); err != nil {
+			return fmt.Errorf("invalid published schema %s: %w", path, err)
+		}
+		if err := json.Unmarshal(data, &after); err != nil {
+			return err
+		}
+		if problems := eventschema.Incompatibilities(before, after); len(problems) > 0 {
+			for _, problem := range problems {
+				fmt.Fprintf(os.Stderr, "%s v%d: %s\n", name, version, problem)
+			}
+			return fmt.Errorf("%s v%d: incompatible with the published schema; publish a new version with an upcaster instead", name, version)
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Printf("%s v%d: written to %s\n", name, version, path)
+	return nil
+}
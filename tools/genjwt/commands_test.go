@@ -0,0 +1,174 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateToken runs generate with the given flags and returns the only token it prints
+func generateToken(t *testing.T, args ...string) string {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, run(append([]string{"generate", "-quiet"}, args...), nil, &stdout, &stderr), stderr.String())
+	return strings.TrimSpace(stdout.String())
+}
+
+func TestRun_CustomToken(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"generate", "-output", "json", "-subject", "ada", "-roles", "EDITOR, VIEWER", "-scopes", "family:read,child:add", "-expiry", "1h", "-tenant", "acme"}
+	require.Equal(t, exitOK, run(args, nil, &stdout, &stderr), stderr.String())
+
+	var result struct {
+		Tokens []token `json:"tokens"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	require.Len(t, result.Tokens, 1)
+	c := result.Tokens[0].Claims
+	assert.Equal(t, "custom", result.Tokens[0].Name)
+	assert.Equal(t, "ada", c.Subject)
+	assert.Equal(t, []string{"EDITOR", "VIEWER"}, c.Roles)
+	assert.Equal(t, []string{"family:read", "child:add"}, c.Scopes)
+	assert.Equal(t, "acme", c.Tenant)
+
+	expiresAt, err := time.Parse(time.RFC3339, c.ExpiresAt)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+}
+
+func TestRun_GenerateUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{"generate", "-role", "admin", "-scopes", "family:read"},
+		{"generate", "-roles", "EDITOR"},
+		{"generate", "-expiry", "-1h"},
+		{"generate", "extra"},
+		{"unknown"},
+	} {
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, exitUsage, run(args, nil, &stdout, &stderr), args)
+		assert.Empty(t, stdout.String())
+		assert.NotEmpty(t, stderr.String())
+	}
+}
+
+func TestRun_Inspect(t *testing.T) {
+	value := generateToken(t, "-role", "viewer", "-tenant", "acme")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, run([]string{"inspect", "-output", "json"}, strings.NewReader("Bearer "+value+"\n"), &stdout, &stderr), stderr.String())
+
+	var decoded inspection
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &decoded))
+	assert.Equal(t, "HS256", decoded.Header["alg"])
+	assert.Equal(t, "viewer", decoded.Claims["sub"])
+	assert.Equal(t, "acme", decoded.Claims["tenant_id"])
+	assert.NotEmpty(t, decoded.ExpiresAt)
+	assert.False(t, decoded.Expired)
+
+	stdout.Reset()
+	require.Equal(t, exitOK, run([]string{"inspect", value}, nil, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "Signature: not verified")
+
+	assert.Equal(t, exitFailure, run([]string{"inspect", "not-a-token"}, nil, &stdout, &stderr))
+	assert.Equal(t, exitUsage, run([]string{"inspect"}, strings.NewReader(""), &stdout, &stderr))
+}
+
+func TestRun_Verify(t *testing.T) {
+	value := generateToken(t, "-role", "editor", "-tenant", "acme")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, run([]string{"verify", "-output", "json", value}, nil, &stdout, &stderr), stderr.String())
+	var result verification
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	assert.True(t, result.Valid)
+	assert.Equal(t, "editor", result.Claims.Subject)
+	assert.Equal(t, "acme", result.Claims.Tenant)
+
+	// A token whose signature does not match is not valid
+	stdout.Reset()
+	stderr.Reset()
+	assert.Equal(t, exitFailure, run([]string{"verify", value + "x"}, nil, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "invalid token")
+
+	stderr.Reset()
+	assert.Equal(t, exitFailure, run([]string{"verify", "-quiet", value + "x"}, nil, &stdout, &stderr))
+	assert.Empty(t, stderr.String())
+}
+
+func TestRun_VerifyJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "k1", Algorithm: "RS256", Use: "sig"},
+		}})
+	}))
+	defer server.Close()
+
+	sign := func(method gojwt.SigningMethod, kid string, signingKey interface{}) string {
+		token := gojwt.NewWithClaims(method, gojwt.MapClaims{
+			"sub":   "ada",
+			"roles": []string{"VIEWER"},
+			"iss":   "https://idp.example.com",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = kid
+		value, err := token.SignedString(signingKey)
+		require.NoError(t, err)
+		return value
+	}
+
+	var stdout, stderr bytes.Buffer
+	value := sign(gojwt.SigningMethodRS256, "k1", key)
+	require.Equal(t, exitOK, run([]string{"verify", "-jwks", server.URL, "-issuer", "https://idp.example.com", value}, nil, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "Valid token, verified with "+server.URL)
+	assert.Contains(t, stdout.String(), "ada")
+
+	for name, args := range map[string][]string{
+		"wrong issuer": {"verify", "-jwks", server.URL, "-issuer", "https://other.example.com", value},
+		"unknown key":  {"verify", "-jwks", server.URL, sign(gojwt.SigningMethodRS256, "k2", key)},
+		"wrong method": {"verify", "-jwks", server.URL, sign(gojwt.SigningMethodHS256, "k1", []byte("public key as a secret"))},
+	} {
+		stderr.Reset()
+		assert.Equal(t, exitFailure, run(args, nil, &stdout, &stderr), name)
+		assert.Contains(t, stderr.String(), "invalid token", name)
+	}
+}
+
+func TestRun_Snippet(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, run([]string{"snippet", "-url", "http://localhost:8089/graphql", "abc.def.ghi"}, nil, &stdout, &stderr), stderr.String())
+	assert.Equal(t, "curl -s -X POST 'http://localhost:8089/graphql' \\\n"+
+		"  -H 'Content-Type: application/json' \\\n"+
+		"  -H 'Authorization: Bearer abc.def.ghi' \\\n"+
+		"  -d '{\"query\":\"{ __typename }\"}'\n", stdout.String())
+
+	stdout.Reset()
+	require.Equal(t, exitOK, run([]string{"snippet", "-format", "headers", "-"}, strings.NewReader("abc.def.ghi\n"), &stdout, &stderr))
+	assert.JSONEq(t, `{"Authorization": "Bearer abc.def.ghi"}`, stdout.String())
+
+	stdout.Reset()
+	require.Equal(t, exitOK, run([]string{"snippet", "-format", "websocket", "abc.def.ghi"}, nil, &stdout, &stderr))
+	assert.JSONEq(t, `{"type": "connection_init", "payload": {"Authorization": "Bearer abc.def.ghi"}}`, stdout.String())
+
+	assert.Equal(t, exitUsage, run([]string{"snippet", "-format", "wget", "abc.def.ghi"}, nil, &stdout, &stderr))
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Per-operation scopes, as defined by the authorization table in interface/adapters/graphql/authz
+var (
+	// viewerScopes allow reading families, parents, and children
+	viewerScopes = []string{"family:read", "parent:read", "child:read"}
+
+	// editorScopes additionally allow creating and changing families
+	editorScopes = append(append([]string{}, viewerScopes...),
+		"family:create", "family:update", "family:divorce", "family:remarry",
+		"parent:add", "parent:update",
+		"child:add", "child:remove", "child:update", "child:move", "child:consent")
+
+	// adminScopes are every per-operation scope and the scopes of the admin API
+	adminScopes = append(append([]string{}, editorScopes...), "family:delete", "family:audit", "family:quarantine", "export:run",
+		"ops:cache", "ops:keys", "ops:reindex", "ops:maintenance", "ops:config", "ops:capture", "ops:outbox")
+)
+
+// tokenSpec describes a token to generate
+type tokenSpec struct {
+	name      string // Name of the token, as printed in text output
+	subject   string
+	roles     []string
+	scopes    []string
+	resources []string
+	tenant    string
+}
+
+// tokenSpecs are the tokens generated by the tool, in output order
+var tokenSpecs = []tokenSpec{
+	{name: "Admin", subject: "admin", roles: []string{"ADMIN"}, scopes: adminScopes},
+	{name: "Editor", subject: "editor", roles: []string{"EDITOR"}, scopes: editorScopes},
+	{name: "Viewer", subject: "viewer", roles: []string{"VIEWER"}, scopes: viewerScopes},
+}
+
+// runGenerate generates tokens and returns the exit status
+func runGenerate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("genjwt generate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("output", formatText, "output format: text, json, yaml, or table")
+	quiet := flags.Bool("quiet", false, "print only the tokens, one per line, and no logs")
+	role := flags.String("role", "", "generate only the token of this role: admin, editor, or viewer (default all)")
+	subject := flags.String("subject", "", "generate a custom token for this subject")
+	roles := flags.String("roles", "", "comma-separated roles of the custom token")
+	scopes := flags.String("scopes", "", "comma-separated scopes of the custom token")
+	resources := flags.String("resources", "", "comma-separated resources of the custom token")
+	expiry := flags.Duration("expiry", 0, "lifetime of the tokens (default the token duration of the service)")
+	tenant := flags.String("tenant", "", "tenant of the tokens, set as the tenant_id claim")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments: %s\n", strings.Join(flags.Args(), " "))
+		return exitUsage
+	}
+	if !validFormat(*output, formats) {
+		fmt.Fprintf(stderr, "invalid -output %q: must be one of %s\n", *output, strings.Join(formats, ", "))
+		return exitUsage
+	}
+	if *expiry < 0 {
+		fmt.Fprintf(stderr, "invalid -expiry %s: must not be negative\n", *expiry)
+		return exitUsage
+	}
+
+	var specs []tokenSpec
+	if *subject != "" || *roles != "" || *scopes != "" || *resources != "" {
+		if *role != "" {
+			fmt.Fprintln(stderr, "-role cannot be combined with the flags of a custom token")
+			return exitUsage
+		}
+		if *subject == "" {
+			fmt.Fprintln(stderr, "-subject is required for a custom token")
+			return exitUsage
+		}
+		specs = []tokenSpec{{name: "Custom", subject: *subject, roles: splitList(*roles), scopes: splitList(*scopes), resources: splitList(*resources)}}
+	} else {
+		var err error
+		if specs, err = selectSpecs(*role); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	for i := range specs {
+		specs[i].tenant = *tenant
+	}
+
+	logger, ok := newLogger(*quiet, stderr)
+	if !ok {
+		return exitFailure
+	}
+	defer logger.Sync()
+
+	tokens, err := generate(context.Background(), logger, specs, *expiry)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailure
+	}
+
+	if *quiet {
+		err = writeQuiet(stdout, tokens)
+	} else {
+		err = writeTokens(stdout, *output, tokens)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// newLogger returns the logger of a command. Logs, including those of loading the
+// configuration, go to standard error, so they never mix with the output; in quiet mode,
+// there are none.
+func newLogger(quiet bool, stderr io.Writer) (*zap.Logger, bool) {
+	if quiet {
+		log.SetOutput(io.Discard)
+		return zap.NewNop(), true
+	}
+	log.SetOutput(stderr)
+	logger, err := zap.NewProduction()
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to create logger: %v\n", err)
+		return nil, false
+	}
+	return logger, true
+}
+
+// selectSpecs returns the specs of the tokens to generate for the -role flag
+func selectSpecs(role string) ([]tokenSpec, error) {
+	if role == "" {
+		return append([]tokenSpec{}, tokenSpecs...), nil
+	}
+	for _, spec := range tokenSpecs {
+		if strings.EqualFold(spec.name, role) {
+			return []tokenSpec{spec}, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid -role %q: must be one of admin, editor, viewer", role)
+}
+
+// splitList splits a comma-separated list, ignoring empty elements
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// generate generates and validates a token for each spec
+func generate(ctx context.Context, logger *zap.Logger, specs []tokenSpec, expiry time.Duration) ([]token, error) {
+	svc, err := loadService(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]token, 0, len(specs))
+	for _, spec := range specs {
+		value, err := svc.sign(spec, expiry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s token: %w", strings.ToLower(spec.name), err)
+		}
+
+		// Validate the token, so that the output shows the claims a server would see
+		claims, err := svc.validate(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate %s token: %w", strings.ToLower(spec.name), err)
+		}
+
+		tokens = append(tokens, newToken(spec.name, value, claims, spec.tenant))
+	}
+	return tokens, nil
+}
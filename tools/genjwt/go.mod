@@ -1,5 +0,0 @@
-module github.com/abitofhelp/family-service/tools/genjwt
-
-go 1.24
-
-
@@ -0,0 +1,265 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// inspectFormats are the output formats of inspect and verify
+var inspectFormats = []string{formatText, formatJSON, formatYAML}
+
+// runInspect decodes a token without verifying it and returns the exit status
+func runInspect(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("genjwt inspect", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("output", formatText, "output format: text, json, or yaml")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !validFormat(*output, inspectFormats) {
+		fmt.Fprintf(stderr, "invalid -output %q: must be one of %s\n", *output, strings.Join(inspectFormats, ", "))
+		return exitUsage
+	}
+	value, err := readToken(flags.Args(), stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	decoded, err := inspect(value, time.Now())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailure
+	}
+	if err := writeInspection(stdout, *output, decoded); err != nil {
+		fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// inspect decodes the header and claims of a token without verifying its signature
+func inspect(value string, now time.Time) (inspection, error) {
+	mapClaims := gojwt.MapClaims{}
+	parsed, _, err := gojwt.NewParser().ParseUnverified(value, mapClaims)
+	if err != nil {
+		return inspection{}, fmt.Errorf("failed to decode token: %w", err)
+	}
+
+	decoded := inspection{Header: parsed.Header, Claims: mapClaims}
+	if issuedAt, err := mapClaims.GetIssuedAt(); err == nil && issuedAt != nil {
+		decoded.IssuedAt = issuedAt.UTC().Format(time.RFC3339)
+	}
+	if expiresAt, err := mapClaims.GetExpirationTime(); err == nil && expiresAt != nil {
+		decoded.ExpiresAt = expiresAt.UTC().Format(time.RFC3339)
+		decoded.Expired = !now.Before(expiresAt.Time)
+	}
+	return decoded, nil
+}
+
+// runVerify verifies a token and returns the exit status: exitOK if the token is valid and
+// exitFailure if it is not
+func runVerify(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("genjwt verify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("output", formatText, "output format: text, json, or yaml")
+	jwks := flags.String("jwks", "", "URL or file of a JWKS to verify the token against (default the secret key of the service)")
+	issuer := flags.String("issuer", "", "with -jwks, the issuer the token must have")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout of fetching the JWKS")
+	quiet := flags.Bool("quiet", false, "print nothing; only the exit status reports whether the token is valid")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !validFormat(*output, inspectFormats) {
+		fmt.Fprintf(stderr, "invalid -output %q: must be one of %s\n", *output, strings.Join(inspectFormats, ", "))
+		return exitUsage
+	}
+	value, err := readToken(flags.Args(), stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	logger, ok := newLogger(*quiet, stderr)
+	if !ok {
+		return exitFailure
+	}
+	defer logger.Sync()
+
+	ctx := context.Background()
+	var result verification
+	if *jwks != "" {
+		result, err = verifyWithJWKS(ctx, value, *jwks, *issuer, *timeout)
+	} else {
+		var svc *service
+		if svc, err = loadService(ctx, logger); err == nil {
+			result, err = verifyWithService(ctx, svc, value)
+		}
+	}
+	if err != nil {
+		if !*quiet {
+			fmt.Fprintf(stderr, "invalid token: %v\n", err)
+		}
+		return exitFailure
+	}
+
+	if !*quiet {
+		if err := writeVerification(stdout, *output, result); err != nil {
+			fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+			return exitFailure
+		}
+	}
+	return exitOK
+}
+
+// verifyWithService validates a token with the secret key of the service, as the service does
+func verifyWithService(ctx context.Context, svc *service, value string) (verification, error) {
+	raw, err := svc.validate(ctx, value)
+	if err != nil {
+		return verification{}, err
+	}
+
+	// The claims of the service have no tenant, so it is read from the validated token
+	var extra tokenClaims
+	if _, _, err := gojwt.NewParser().ParseUnverified(value, &extra); err != nil {
+		return verification{}, err
+	}
+	return verification{Valid: true, VerifiedWith: "service secret key", Claims: newClaims(raw, extra.TenantID)}, nil
+}
+
+// verifyWithJWKS verifies the signature of a token with the key of a JWKS that has its key
+// ID, and checks its expiry and, if given, its issuer
+func verifyWithJWKS(ctx context.Context, value, location, issuer string, timeout time.Duration) (verification, error) {
+	set, err := loadJWKS(ctx, location, timeout)
+	if err != nil {
+		return verification{}, err
+	}
+
+	var options []gojwt.ParserOption
+	if issuer != "" {
+		options = append(options, gojwt.WithIssuer(issuer))
+	}
+	var verified tokenClaims
+	if _, err := gojwt.NewParser(options...).ParseWithClaims(value, &verified, jwksKey(set)); err != nil {
+		return verification{}, err
+	}
+	return verification{Valid: true, VerifiedWith: location, Claims: newClaims(&verified.Claims, verified.TenantID)}, nil
+}
+
+// loadJWKS reads a JWKS from a URL or a file
+func loadJWKS(ctx context.Context, location string, timeout time.Duration) (*jose.JSONWebKeySet, error) {
+	var data []byte
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS URL: %w", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the JWKS: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch the JWKS: %s", resp.Status)
+		}
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, fmt.Errorf("failed to fetch the JWKS: %w", err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(location); err != nil {
+			return nil, fmt.Errorf("failed to read the JWKS: %w", err)
+		}
+	}
+
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse the JWKS: %w", err)
+	}
+	return &set, nil
+}
+
+// jwksKey returns the function that selects the key of a token from a JWKS: the key with
+// the key ID of the token, or the only key of a JWKS with one key if the token has none.
+// The signing method of the token must match the type of the key, and its algorithm if
+// the key names one, so that a public key cannot be used as an HMAC secret.
+func jwksKey(set *jose.JSONWebKeySet) gojwt.Keyfunc {
+	return func(t *gojwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		keys := set.Keys
+		if kid != "" {
+			keys = set.Key(kid)
+		}
+		if len(keys) != 1 {
+			return nil, fmt.Errorf("the JWKS has no single key with key ID %q", kid)
+		}
+		key := keys[0]
+		if key.Algorithm != "" && key.Algorithm != t.Method.Alg() {
+			return nil, fmt.Errorf("the token is signed with %s, but key %q is for %s", t.Method.Alg(), key.KeyID, key.Algorithm)
+		}
+
+		var matches bool
+		switch key.Key.(type) {
+		case []byte:
+			_, matches = t.Method.(*gojwt.SigningMethodHMAC)
+		case *rsa.PublicKey:
+			_, matches = t.Method.(*gojwt.SigningMethodRSA)
+			if _, pss := t.Method.(*gojwt.SigningMethodRSAPSS); pss {
+				matches = true
+			}
+		case *ecdsa.PublicKey:
+			_, matches = t.Method.(*gojwt.SigningMethodECDSA)
+		case ed25519.PublicKey:
+			_, matches = t.Method.(*gojwt.SigningMethodEd25519)
+		}
+		if !matches {
+			return nil, fmt.Errorf("the token is signed with %s, which does not match the %T of key %q", t.Method.Alg(), key.Key, key.KeyID)
+		}
+		return key.Key, nil
+	}
+}
+
+// readToken returns the token of the arguments, or of standard input when it is omitted or
+// "-". A "Bearer " prefix is removed, so that Authorization headers can be pasted.
+func readToken(args []string, stdin io.Reader) (string, error) {
+	if len(args) > 1 {
+		return "", fmt.Errorf("expected one token, got %d arguments", len(args))
+	}
+
+	value := ""
+	if len(args) == 1 && args[0] != "-" {
+		value = args[0]
+	} else {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the token: %w", err)
+		}
+		value = string(data)
+	}
+
+	value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "Bearer "))
+	if value == "" {
+		return "", fmt.Errorf("no token given")
+	}
+	return value, nil
+}
+
+// Ensure tokenClaims can be parsed as the claims of a token
+var _ gojwt.Claims = (*tokenClaims)(nil)
@@ -1,86 +1,95 @@
 // Copyright (c) 2025 A Bit of Help, Inc.
 
+// Command genjwt manages the JWTs of the service: it generates tokens, inspects and
+// verifies existing tokens, and prints snippets that send a token to the service.
+//
+// Tokens are signed and validated with the auth configuration of the service, loaded as
+// the service loads it (APP_ENV and environment variables), so that the tokens it
+// generates are accepted by a service running with the same configuration.
+//
+// Subcommands:
+//
+//	generate  Generate the tokens of the admin, editor, and viewer roles, or a custom token
+//	inspect   Decode a token without verifying it
+//	verify    Verify a token with the secret key of the service or against a JWKS
+//	snippet   Print a curl command or GraphQL headers that send a token
+//
+// Without a subcommand, genjwt runs generate. Tokens are read from the argument, or from
+// standard input when it is omitted or "-", so that the subcommands can be piped.
+//
+// The tokens and their claims are printed as text by default, or as JSON, YAML, or
+// a table with -output, so that the tool can be scripted in CI and runbooks. With
+// -quiet only the tokens are printed, one per line. The exit status is 0 on success,
+// 1 if a token could not be generated or is not valid, and 2 for invalid usage.
+//
+// Usage:
+//
+//	genjwt
+//	genjwt -output json
+//	TOKEN=$(genjwt -quiet -role editor)
+//	genjwt generate -subject ada -roles EDITOR -scopes family:read,child:add -expiry 1h -tenant acme
+//	genjwt inspect "$TOKEN"
+//	genjwt verify -jwks https://idp.example.com/.well-known/jwks.json "$TOKEN"
+//	genjwt generate -quiet -role viewer | genjwt snippet -format curl
 package main
 
 import (
-	"context"
 	"fmt"
-	"github.com/abitofhelp/servicelib/auth"
-	"go.uber.org/zap"
+	"io"
+	"os"
+	"strings"
 )
 
-func main() {
-	// Create a logger
-	logger, _ := zap.NewProduction()
-	defer logger.Sync()
-
-	// Create a context
-	ctx := context.Background()
-
-	// Create a configuration
-	config := auth.DefaultConfig()
-	config.JWT.SecretKey = "01234567890123456789012345678901"
-
-	// Create an auth instance
-	authInstance, err := auth.New(ctx, config, logger)
-	if err != nil {
-		logger.Fatal("Failed to create auth instance", zap.Error(err))
-	}
-
-	// Generate admin token with all scopes for all resources
-	adminScopes := []string{"READ", "WRITE", "DELETE", "CREATE"}
-	adminResources := []string{"FAMILY", "PARENT", "CHILD"}
-	adminToken, err := authInstance.GenerateToken(ctx, "admin", []string{"ADMIN"}, adminScopes, adminResources)
-	if err != nil {
-		logger.Fatal("Failed to generate admin token", zap.Error(err))
-		return
-	}
+// Exit statuses of the tool; they are stable so that scripts can rely on them
+const (
+	exitOK      = 0 // All tokens were generated, or the token is valid
+	exitFailure = 1 // A token could not be generated, or the token is not valid
+	exitUsage   = 2 // The command line is invalid
+)
 
-	fmt.Printf("\nAdmin Token: %s\n", adminToken)
+// command is a subcommand of the tool
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
 
-	// Generate editor token with all scopes for all resources
-	editorScopes := []string{"READ", "WRITE", "DELETE", "CREATE"}
-	editorResources := []string{"FAMILY", "PARENT", "CHILD"}
-	editorToken, err := authInstance.GenerateToken(ctx, "editor", []string{"EDITOR"}, editorScopes, editorResources)
-	if err != nil {
-		logger.Fatal("Failed to generate editor token", zap.Error(err))
-		return
-	}
+// commands are the subcommands of the tool, in usage order
+var commands = []command{
+	{name: "generate", summary: "generate the tokens of the admin, editor, and viewer roles, or a custom token", run: runGenerate},
+	{name: "inspect", summary: "decode a token without verifying it", run: runInspect},
+	{name: "verify", summary: "verify a token with the secret key of the service or against a JWKS", run: runVerify},
+	{name: "snippet", summary: "print a curl command or GraphQL headers that send a token", run: runSnippet},
+}
 
-	fmt.Printf("\nEditor Token: %s\n", editorToken)
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
 
-	// Generate viewer token with only READ scope for all resources
-	viewerScopes := []string{"READ"}
-	viewerResources := []string{"FAMILY", "PARENT", "CHILD"}
-	viewerToken, err := authInstance.GenerateToken(ctx, "viewer", []string{"VIEWER"}, viewerScopes, viewerResources)
-	if err != nil {
-		logger.Fatal("Failed to generate viewer token", zap.Error(err))
-		return
+// run runs the subcommand named by the first argument and returns its exit status.
+// Without a subcommand, the arguments are those of generate.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runGenerate(args, stdin, stdout, stderr)
 	}
-
-	fmt.Printf("\nViewer Token: %s\n", viewerToken)
-
-	// Validate admin token
-	adminClaims, err := authInstance.ValidateToken(ctx, adminToken)
-	if err != nil {
-		logger.Fatal("Failed to validate admin token", zap.Error(err))
-		return
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdin, stdout, stderr)
+		}
 	}
-	fmt.Printf("\nValid Admin Token, Claims: %+v\n", adminClaims)
-
-	// Validate editor token
-	editorClaims, err := authInstance.ValidateToken(ctx, editorToken)
-	if err != nil {
-		logger.Fatal("Failed to validate editor token", zap.Error(err))
-		return
+	if args[0] != "help" {
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
 	}
-	fmt.Printf("\nValid Editor Token, Claims: %+v\n", editorClaims)
+	usage(stderr)
+	return exitUsage
+}
 
-	// Validate viewer token
-	viewerClaims, err := authInstance.ValidateToken(ctx, viewerToken)
-	if err != nil {
-		logger.Fatal("Failed to validate viewer token", zap.Error(err))
-		return
+// usage writes the subcommands of the tool
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: genjwt <command> [flags] [token]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
 	}
-	fmt.Printf("\nValid Viewer Token, Claims: %+v\n", viewerClaims)
+	fmt.Fprintln(w, "\nRun genjwt <command> -h for the flags of a command.")
 }
@@ -0,0 +1,235 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/abitofhelp/servicelib/auth/jwt"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatTable = "table"
+)
+
+// formats lists the supported output formats
+var formats = []string{formatText, formatJSON, formatYAML, formatTable}
+
+// token is a generated token with its validated claims.
+// Its JSON and YAML representations are stable, so that scripts can rely on them.
+type token struct {
+	Name   string `json:"name" yaml:"name"`
+	Token  string `json:"token" yaml:"token"`
+	Claims claims `json:"claims" yaml:"claims"`
+
+	raw *jwt.Claims // Claims as returned by validation, for text output
+}
+
+// claims are the claims of a token, with times in RFC3339 format
+type claims struct {
+	Subject   string   `json:"subject" yaml:"subject"`
+	Roles     []string `json:"roles" yaml:"roles"`
+	Scopes    []string `json:"scopes" yaml:"scopes"`
+	Resources []string `json:"resources" yaml:"resources"`
+	Issuer    string   `json:"issuer" yaml:"issuer"`
+	Tenant    string   `json:"tenant,omitempty" yaml:"tenant,omitempty"`
+	IssuedAt  string   `json:"issuedAt,omitempty" yaml:"issuedAt,omitempty"`
+	ExpiresAt string   `json:"expiresAt,omitempty" yaml:"expiresAt,omitempty"`
+}
+
+// inspection is a token decoded without verification
+type inspection struct {
+	Header    map[string]interface{} `json:"header" yaml:"header"`
+	Claims    map[string]interface{} `json:"claims" yaml:"claims"`
+	IssuedAt  string                 `json:"issuedAt,omitempty" yaml:"issuedAt,omitempty"`
+	ExpiresAt string                 `json:"expiresAt,omitempty" yaml:"expiresAt,omitempty"`
+	Expired   bool                   `json:"expired" yaml:"expired"`
+}
+
+// verification is the result of verifying a valid token
+type verification struct {
+	Valid        bool   `json:"valid" yaml:"valid"`
+	VerifiedWith string `json:"verifiedWith" yaml:"verifiedWith"`
+	Claims       claims `json:"claims" yaml:"claims"`
+}
+
+// newToken creates the output representation of a token
+func newToken(name, value string, raw *jwt.Claims, tenant string) token {
+	return token{Name: strings.ToLower(name), Token: value, Claims: newClaims(raw, tenant), raw: raw}
+}
+
+// newClaims creates the output representation of the claims of a token
+func newClaims(raw *jwt.Claims, tenant string) claims {
+	c := claims{
+		Subject:   raw.UserID,
+		Roles:     nonNil(raw.Roles),
+		Scopes:    nonNil(raw.Scopes),
+		Resources: nonNil(raw.Resources),
+		Issuer:    raw.Issuer,
+		Tenant:    tenant,
+	}
+	if raw.IssuedAt != nil {
+		c.IssuedAt = raw.IssuedAt.UTC().Format(time.RFC3339)
+	}
+	if raw.ExpiresAt != nil {
+		c.ExpiresAt = raw.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return c
+}
+
+// title returns the name of the token as printed in text output, such as "Admin"
+func (t token) title() string {
+	return strings.ToUpper(t.Name[:1]) + t.Name[1:]
+}
+
+// nonNil returns an empty slice for nil, so that lists are never null in the output
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// validFormat reports whether the format is one of the supported formats
+func validFormat(format string, supported []string) bool {
+	for _, f := range supported {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTokens writes the tokens in the given format
+func writeTokens(w io.Writer, format string, tokens []token) error {
+	switch format {
+	case formatJSON, formatYAML:
+		return writeStructured(w, format, map[string][]token{"tokens": tokens})
+	case formatTable:
+		return writeTable(w, tokens)
+	default:
+		return writeText(w, tokens)
+	}
+}
+
+// writeStructured writes a value as indented JSON or YAML
+func writeStructured(w io.Writer, format string, value interface{}) error {
+	if format == formatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// writeInspection writes a decoded token in the given format
+func writeInspection(w io.Writer, format string, decoded inspection) error {
+	if format != formatText {
+		return writeStructured(w, format, decoded)
+	}
+
+	header, err := json.Marshal(decoded.Header)
+	if err != nil {
+		return err
+	}
+	body, err := json.MarshalIndent(decoded.Claims, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Header: %s\nClaims: %s\n", header, body); err != nil {
+		return err
+	}
+	if decoded.IssuedAt != "" {
+		if _, err := fmt.Fprintf(w, "Issued: %s\n", decoded.IssuedAt); err != nil {
+			return err
+		}
+	}
+	if decoded.ExpiresAt != "" {
+		status := "valid"
+		if decoded.Expired {
+			status = "expired"
+		}
+		if _, err := fmt.Fprintf(w, "Expires: %s (%s)\n", decoded.ExpiresAt, status); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(w, "Signature: not verified")
+	return err
+}
+
+// writeVerification writes the result of verifying a valid token in the given format
+func writeVerification(w io.Writer, format string, result verification) error {
+	if format != formatText {
+		return writeStructured(w, format, result)
+	}
+
+	c := result.Claims
+	if _, err := fmt.Fprintf(w, "Valid token, verified with %s\n", result.VerifiedWith); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Subject:\t%s\n", c.Subject)
+	fmt.Fprintf(tw, "Roles:\t%s\n", strings.Join(c.Roles, ", "))
+	fmt.Fprintf(tw, "Scopes:\t%s\n", strings.Join(c.Scopes, ", "))
+	if len(c.Resources) > 0 {
+		fmt.Fprintf(tw, "Resources:\t%s\n", strings.Join(c.Resources, ", "))
+	}
+	if c.Tenant != "" {
+		fmt.Fprintf(tw, "Tenant:\t%s\n", c.Tenant)
+	}
+	fmt.Fprintf(tw, "Issuer:\t%s\n", c.Issuer)
+	fmt.Fprintf(tw, "Issued:\t%s\n", c.IssuedAt)
+	fmt.Fprintf(tw, "Expires:\t%s\n", c.ExpiresAt)
+	return tw.Flush()
+}
+
+// writeText writes the tokens, then their claims, as free text
+func writeText(w io.Writer, tokens []token) error {
+	for _, t := range tokens {
+		if _, err := fmt.Fprintf(w, "\n%s Token: %s\n", t.title(), t.Token); err != nil {
+			return err
+		}
+	}
+	for _, t := range tokens {
+		if _, err := fmt.Fprintf(w, "\nValid %s Token, Claims: %+v\n", t.title(), t.raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTable writes one row per token
+func writeTable(w io.Writer, tokens []token) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSUBJECT\tROLES\tSCOPES\tEXPIRES\tTOKEN")
+	for _, t := range tokens {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			t.Name, t.Claims.Subject, strings.Join(t.Claims.Roles, ","), len(t.Claims.Scopes), t.Claims.ExpiresAt, t.Token)
+	}
+	return tw.Flush()
+}
+
+// writeQuiet writes only the tokens, one per line
+func writeQuiet(w io.Writer, tokens []token) error {
+	for _, t := range tokens {
+		if _, err := fmt.Fprintln(w, t.Token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRun_Output(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, run([]string{"-output", "json", "-role", "editor"}, nil, &stdout, &stderr), stderr.String())
+
+	var result struct {
+		Tokens []token `json:"tokens"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	require.Len(t, result.Tokens, 1)
+	assert.Equal(t, "editor", result.Tokens[0].Name)
+	assert.NotEmpty(t, result.Tokens[0].Token)
+	assert.Equal(t, "editor", result.Tokens[0].Claims.Subject)
+	assert.Equal(t, []string{"EDITOR"}, result.Tokens[0].Claims.Roles)
+	assert.Equal(t, editorScopes, result.Tokens[0].Claims.Scopes)
+	assert.Equal(t, []string{}, result.Tokens[0].Claims.Resources)
+
+	stdout.Reset()
+	require.Equal(t, exitOK, run([]string{"-output", "yaml"}, nil, &stdout, &stderr))
+	var yamlResult struct {
+		Tokens []token `yaml:"tokens"`
+	}
+	require.NoError(t, yaml.Unmarshal(stdout.Bytes(), &yamlResult))
+	require.Len(t, yamlResult.Tokens, 3)
+	assert.Equal(t, []string{"ADMIN"}, yamlResult.Tokens[0].Claims.Roles)
+
+	stdout.Reset()
+	require.Equal(t, exitOK, run([]string{"-output", "table"}, nil, &stdout, &stderr))
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "NAME"))
+	assert.True(t, strings.HasPrefix(lines[3], "viewer"))
+
+	stdout.Reset()
+	require.Equal(t, exitOK, run(nil, nil, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "Admin Token: ")
+	assert.Contains(t, stdout.String(), "Valid Viewer Token, Claims: ")
+}
+
+func TestRun_Quiet(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, run([]string{"-quiet", "-role", "VIEWER"}, nil, &stdout, &stderr))
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 1)
+	assert.Equal(t, 3, len(strings.Split(lines[0], ".")), "only the token is printed")
+	assert.Empty(t, stderr.String())
+}
+
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{"-output", "xml"},
+		{"-role", "owner"},
+		{"-unknown"},
+	} {
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, exitUsage, run(args, nil, &stdout, &stderr), args)
+		assert.Empty(t, stdout.String())
+		assert.NotEmpty(t, stderr.String())
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	authwrapper "github.com/abitofhelp/family-service/infrastructure/adapters/authwrapper"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/servicelib/auth"
+	"github.com/abitofhelp/servicelib/auth/jwt"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// service signs and validates tokens as the service does
+type service struct {
+	jwt  config.JWTConfig
+	keys *authwrapper.Keys
+	key  string // Secret key the tokens are signed with, as read by the keys
+}
+
+// tokenClaims are the claims of a token: the claims the service validates and the tenant
+type tokenClaims struct {
+	jwt.Claims
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+// loadService loads the auth configuration of the service and builds the auth service of
+// its secret key, which is read from the secret key file if one is configured
+func loadService(ctx context.Context, logger *zap.Logger) (*service, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the service configuration: %w", err)
+	}
+
+	s := &service{jwt: cfg.Auth.JWT}
+	authConfig := auth.DefaultConfig()
+	authConfig.JWT.Issuer = cfg.Auth.JWT.Issuer
+	authConfig.JWT.TokenDuration = cfg.Auth.JWT.TokenDuration
+	s.keys, err = authwrapper.NewKeys(ctx, cfg.Auth.JWT, func(ctx context.Context, secretKey string) (*auth.Auth, error) {
+		keyConfig := authConfig
+		keyConfig.JWT.SecretKey = secretKey
+		s.key = secretKey
+		return auth.New(ctx, keyConfig, logger)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth instance: %w", err)
+	}
+	return s, nil
+}
+
+// serviceEndpoint returns the URL of the GraphQL endpoint of the service on this host
+func serviceEndpoint() (string, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load the service configuration: %w", err)
+	}
+	return fmt.Sprintf("http://localhost:%s/graphql", cfg.Server.Port), nil
+}
+
+// sign signs a token for a spec that expires after the given duration, or after the token
+// duration of the service if it is zero
+func (s *service) sign(spec tokenSpec, expiry time.Duration) (string, error) {
+	if expiry <= 0 {
+		expiry = s.jwt.TokenDuration
+	}
+	now := time.Now()
+	claims := tokenClaims{
+		Claims: jwt.Claims{
+			UserID:    spec.subject,
+			Roles:     spec.roles,
+			Scopes:    spec.scopes,
+			Resources: spec.resources,
+			RegisteredClaims: gojwt.RegisteredClaims{
+				ExpiresAt: gojwt.NewNumericDate(now.Add(expiry)),
+				IssuedAt:  gojwt.NewNumericDate(now),
+				NotBefore: gojwt.NewNumericDate(now),
+				Issuer:    s.jwt.Issuer,
+				ID:        fmt.Sprintf("%s-%d", spec.subject, now.UnixNano()),
+			},
+		},
+		TenantID: spec.tenant,
+	}
+	return gojwt.NewWithClaims(gojwt.SigningMethodHS256, claims).SignedString([]byte(s.key))
+}
+
+// validate validates a token as the service does and returns its claims
+func (s *service) validate(ctx context.Context, value string) (*jwt.Claims, error) {
+	return s.keys.ValidateToken(ctx, value)
+}
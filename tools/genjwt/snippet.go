@@ -0,0 +1,94 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// Snippet formats
+const (
+	snippetCurl      = "curl"
+	snippetHeaders   = "headers"
+	snippetWebsocket = "websocket"
+)
+
+// snippetFormats lists the supported snippet formats
+var snippetFormats = []string{snippetCurl, snippetHeaders, snippetWebsocket}
+
+// runSnippet prints a snippet that sends a token to the service and returns the exit status
+func runSnippet(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("genjwt snippet", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", snippetCurl, "snippet format: curl (a curl command), headers (the HTTP headers of the GraphQL playground), or websocket (a connection_init message)")
+	url := flags.String("url", "", "URL of the GraphQL endpoint of the curl command (default the endpoint of the service on this host)")
+	query := flags.String("query", "{ __typename }", "GraphQL query of the curl command")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !validFormat(*format, snippetFormats) {
+		fmt.Fprintf(stderr, "invalid -format %q: must be one of %s\n", *format, strings.Join(snippetFormats, ", "))
+		return exitUsage
+	}
+	value, err := readToken(flags.Args(), stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	if *format == snippetCurl && *url == "" {
+		// Loading the configuration logs the file it reads, which is not part of a snippet
+		log.SetOutput(io.Discard)
+		if *url, err = serviceEndpoint(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitFailure
+		}
+	}
+	if err := writeSnippet(stdout, *format, value, *url, *query); err != nil {
+		fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// writeSnippet writes a snippet that sends a token in the given format
+func writeSnippet(w io.Writer, format, value, url, query string) error {
+	authorization := "Bearer " + value
+	switch format {
+	case snippetHeaders:
+		data, err := json.MarshalIndent(map[string]string{"Authorization": authorization}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	case snippetWebsocket:
+		data, err := json.Marshal(map[string]interface{}{
+			"type":    "connection_init",
+			"payload": map[string]string{"Authorization": authorization},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	default:
+		body, err := json.Marshal(map[string]string{"query": query})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "curl -s -X POST %s \\\n  -H %s \\\n  -H %s \\\n  -d %s\n",
+			shellQuote(url), shellQuote("Content-Type: application/json"), shellQuote("Authorization: "+authorization), shellQuote(string(body)))
+		return err
+	}
+}
+
+// shellQuote quotes a string for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"gopkg.in/yaml.v3"
+)
+
+// gqlgenConfig is the part of a gqlgen configuration file that the pipeline reads
+type gqlgenConfig struct {
+	Schema   []string `yaml:"schema"`
+	Resolver struct {
+		Dir              string `yaml:"dir"`
+		FilenameTemplate string `yaml:"filename_template"`
+	} `yaml:"resolver"`
+	Models map[string]struct {
+		Fields map[string]struct {
+			Resolver bool `yaml:"resolver"`
+		} `yaml:"fields"`
+	} `yaml:"models"`
+}
+
+// loadConfig reads a gqlgen configuration file
+func loadConfig(path string) (*gqlgenConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var cfg gqlgenConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid gqlgen configuration %s: %w", path, err)
+	}
+	if len(cfg.Schema) == 0 || cfg.Resolver.Dir == "" {
+		return nil, fmt.Errorf("invalid gqlgen configuration %s: schema and resolver.dir are required", path)
+	}
+	return &cfg, nil
+}
+
+// loadSchema parses and validates the schema files of a configuration
+func (c *gqlgenConfig) loadSchema() (*ast.Schema, error) {
+	var sources []*ast.Source
+	for _, pattern := range c.Schema {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema pattern %q: %w", pattern, err)
+		}
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			sources = append(sources, &ast.Source{Name: path, Input: string(data)})
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no schema files match %s", strings.Join(c.Schema, ", "))
+	}
+
+	schema, err := gqlparser.LoadSchema(sources...)
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// generatedResolverFiles returns the resolver files that gqlgen writes for the schema
+// files, following filename_template, so that they can be told apart from the
+// hand-written resolver files
+func (c *gqlgenConfig) generatedResolverFiles() ([]string, error) {
+	template := c.Resolver.FilenameTemplate
+	if template == "" {
+		template = "{name}.resolvers.go"
+	}
+	var files []string
+	for _, pattern := range c.Schema {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			files = append(files, filepath.Join(c.Resolver.Dir, strings.ReplaceAll(template, "{name}", name)))
+		}
+	}
+	return files, nil
+}
+
+// requiredFields returns the fields, as "Type.field", that must have resolvers: the
+// fields of the root operation types and the fields configured with resolver: true
+func (c *gqlgenConfig) requiredFields(schema *ast.Schema) []string {
+	var fields []string
+	for _, root := range []*ast.Definition{schema.Query, schema.Mutation, schema.Subscription} {
+		if root == nil {
+			continue
+		}
+		for _, field := range root.Fields {
+			if !strings.HasPrefix(field.Name, "__") {
+				fields = append(fields, root.Name+"."+field.Name)
+			}
+		}
+	}
+	for typeName, model := range c.Models {
+		for fieldName, field := range model.Fields {
+			if field.Resolver {
+				fields = append(fields, typeName+"."+fieldName)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// schemaFields returns every field of every type of a schema, as "Type.field"
+func schemaFields(schema *ast.Schema) map[string]bool {
+	fields := make(map[string]bool)
+	for _, def := range schema.Types {
+		for _, field := range def.Fields {
+			fields[def.Name+"."+field.Name] = true
+		}
+	}
+	return fields
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Command graphql-gen is the code generation pipeline of the GraphQL API.
+//
+// It runs these steps, and stops at the first that fails:
+//
+//  1. Lint: the schema is checked against the naming and nullability conventions of
+//     the API (see interface/adapters/graphql/schemalint), and the operations of the
+//     consumers registered in the contract registry are validated against it (see
+//     interface/adapters/graphql/contract), so that a change that would break a
+//     consumer is caught before code is generated
+//  2. Generate: gqlgen generates the executable schema and the models
+//  3. Adopt: gqlgen writes every resolver to the resolver file of the schema, but the
+//     resolvers of this service are written by hand in one file per type. The new
+//     declarations of the generated file are moved to stub_resolvers.go, where the
+//     resolvers of new fields return a NOT_IMPLEMENTED error instead of panicking,
+//     and the generated file is removed
+//  4. Check: every field of the root types, and every field configured with
+//     resolver: true, must have a resolver, and every resolver must resolve a field of
+//     the schema. The stubs are listed in stubs_gen.go, and the server logs them at
+//     startup
+//  5. Skeletons: with -skeletons, a skipped test is written to skeleton_test.go for
+//     each resolver that has no test
+//
+// Usage:
+//
+//	graphql-gen
+//	graphql-gen -lint-only
+//	graphql-gen -lint-only -contracts ""   # skip the consumer contracts
+//	graphql-gen -skeletons
+//
+// The command exits with status 1 if a step fails.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/contract"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/schemalint"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func main() {
+	configPath := flag.String("config", "interface/adapters/graphql/gqlgen.yml", "gqlgen configuration file")
+	gqlgen := flag.String("gqlgen", "go run github.com/99designs/gqlgen", "command that runs gqlgen")
+	lintOnly := flag.Bool("lint-only", false, "only lint the schema and check the consumer contracts")
+	contracts := flag.String("contracts", "contracts", "contract registry of the consumers; empty skips the contracts")
+	skipGenerate := flag.Bool("skip-generate", false, "do not run gqlgen; only check the resolvers")
+	skeletons := flag.Bool("skeletons", false, "write skipped tests for the resolvers that have no test")
+	flag.Parse()
+
+	if err := run(*configPath, *gqlgen, *contracts, *lintOnly, *skipGenerate, *skeletons); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run runs the pipeline
+func run(configPath, gqlgen, contracts string, lintOnly, skipGenerate, skeletons bool) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	// 1. Lint
+	schema, err := cfg.loadSchema()
+	if err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	if problems := schemalint.Lint(schema); len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Fprintln(os.Stderr, problem)
+		}
+		return fmt.Errorf("schema lint: %d problem(s); fix them or exempt them with @%s", len(problems), schemalint.DirectiveLintIgnore)
+	}
+	fmt.Println("schema lint: ok")
+	if contracts != "" {
+		if err := checkContracts(schema, contracts); err != nil {
+			return err
+		}
+	}
+	if lintOnly {
+		return nil
+	}
+
+	// 2. Generate
+	if !skipGenerate {
+		args := append(strings.Fields(gqlgen), "generate", "--config", configPath)
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("gqlgen failed: %w", err)
+		}
+		fmt.Println("gqlgen: ok")
+	}
+
+	// 3. Adopt
+	generatedFiles, err := cfg.generatedResolverFiles()
+	if err != nil {
+		return err
+	}
+	skip := map[string]bool{}
+	for _, path := range generatedFiles {
+		skip[filepath.Base(path)] = true
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		fields, err := adoptGeneratedResolvers(cfg.Resolver.Dir, path)
+		if err != nil {
+			return fmt.Errorf("failed to adopt %s: %w", path, err)
+		}
+		for _, field := range fields {
+			fmt.Printf("stub added: %s\n", field)
+		}
+	}
+
+	// 4. Check
+	methods, err := scanResolvers(cfg.Resolver.Dir, skip)
+	if err != nil {
+		return err
+	}
+	problems := checkCoverage(cfg.requiredFields(schema), schemaFields(schema), methods)
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	if err := writeStubList(filepath.Join(cfg.Resolver.Dir, stubsGenFile), methods); err != nil {
+		return err
+	}
+	stubs := 0
+	for _, m := range methods {
+		if m.Stub {
+			stubs++
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("resolver check: %d problem(s)", len(problems))
+	}
+	fmt.Printf("resolver check: ok (%d resolvers, %d stubs)\n", len(methods), stubs)
+
+	// 5. Skeletons
+	if skeletons {
+		count, err := writeSkeletons(cfg.Resolver.Dir, methods)
+		if err != nil {
+			return fmt.Errorf("failed to write test skeletons: %w", err)
+		}
+		fmt.Printf("test skeletons: %d written to %s\n", count, filepath.Join(cfg.Resolver.Dir, skeletonFile))
+	}
+	return nil
+}
+
+// checkCoverage returns the required fields that have no resolver and the resolvers
+// of fields that are not in the schema
+func checkCoverage(required []string, fields map[string]bool, methods []resolverMethod) []string {
+	resolved := make(map[string]bool)
+	var problems []string
+	for _, m := range methods {
+		resolved[m.Field] = true
+		if !fields[m.Field] {
+			problems = append(problems, fmt.Sprintf("%s.%s: resolves %s, which is not in the schema", m.Receiver, m.Method, m.Field))
+		}
+	}
+	for _, field := range required {
+		if !resolved[field] {
+			problems = append(problems, fmt.Sprintf("%s: has no resolver", field))
+		}
+	}
+	return problems
+}
+
+// checkContracts validates the operations of the consumers registered in a contract
+// registry against the schema
+func checkContracts(schema *ast.Schema, dir string) error {
+	registry, err := contract.LoadRegistry(dir)
+	if err != nil {
+		return err
+	}
+	violations := contract.Check(schema, registry)
+	if len(violations) > 0 {
+		for _, v := range violations {
+			fmt.Fprintln(os.Stderr, v)
+		}
+		return fmt.Errorf("consumer contracts: the schema breaks %s", strings.Join(contract.Consumers(violations), ", "))
+	}
+	fmt.Printf("consumer contracts: ok (%d consumers, %d operations)\n", len(registry.Consumers), registry.Operations())
+	return nil
+}
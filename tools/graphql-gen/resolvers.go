@@ -0,0 +1,417 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// stubFile is the file of the resolver package to which stubs for new fields are added
+const stubFile = "stub_resolvers.go"
+
+// stubsGenFile is the file of the resolver package that lists the stubbed fields
+const stubsGenFile = "stubs_gen.go"
+
+// resolverComment matches the doc comment that gqlgen writes for a resolver method
+var resolverComment = regexp.MustCompile(`is the resolver for the (\w+) field`)
+
+// resolverMethod is a method of a resolver type, such as queryResolver.GetFamily
+type resolverMethod struct {
+	Receiver string // Resolver type, such as "queryResolver"
+	Method   string // Go method name, such as "GetFamily"
+	Field    string // Schema field, such as "Query.getFamily"
+	Stub     bool   // Whether the method is a stub that is not implemented
+}
+
+// scanResolvers returns the resolver methods declared in the non-test Go files of a
+// resolver package. Files whose names are in skip are ignored.
+func scanResolvers(dir string, skip map[string]bool) ([]resolverMethod, error) {
+	files, err := parseDir(dir, skip)
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []resolverMethod
+	for _, file := range files {
+		for _, decl := range file.ast.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			receiver := receiverName(fn)
+			typeName := resolverTypeName(receiver)
+			if typeName == "" || fn.Body == nil {
+				continue
+			}
+
+			fieldName := lowerFirst(fn.Name.Name)
+			if fn.Doc != nil {
+				if m := resolverComment.FindStringSubmatch(fn.Doc.Text()); m != nil {
+					fieldName = m[1]
+				}
+			}
+			methods = append(methods, resolverMethod{
+				Receiver: receiver,
+				Method:   fn.Name.Name,
+				Field:    typeName + "." + fieldName,
+				Stub:     isStub(file.src[file.fset.Position(fn.Body.Pos()).Offset:file.fset.Position(fn.Body.End()).Offset]),
+			})
+		}
+	}
+
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Field < methods[j].Field })
+	return methods, nil
+}
+
+// isStub reports whether the body of a resolver method is a stub: a call of
+// notImplemented, or a panic of the kind gqlgen generates for new fields
+func isStub(body []byte) bool {
+	return bytes.Contains(body, []byte("notImplemented(")) ||
+		(bytes.Contains(body, []byte("panic(")) && bytes.Contains(body, []byte("not implemented")))
+}
+
+// parsedFile is a parsed Go source file
+type parsedFile struct {
+	name string
+	src  []byte
+	fset *token.FileSet
+	ast  *ast.File
+}
+
+// parseDir parses the non-test, non-generated Go files of a directory. Files whose
+// names are in skip are ignored.
+func parseDir(dir string, skip map[string]bool) ([]parsedFile, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []parsedFile
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if skip[name] || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parseFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// parseFile parses a Go source file with its comments
+func parseFile(path string) (parsedFile, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return parsedFile{}, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return parsedFile{}, err
+	}
+	return parsedFile{name: filepath.Base(path), src: src, fset: fset, ast: f}, nil
+}
+
+// receiverName returns the name of the receiver type of a method, without the pointer,
+// or "" for a function
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// resolverTypeName returns the schema type resolved by a resolver type, such as
+// "Query" for queryResolver, or "" if the type is not a resolver of a schema type
+func resolverTypeName(receiver string) string {
+	name := strings.TrimSuffix(receiver, "Resolver")
+	if name == receiver || name == "" || strings.ToLower(name[:1]) != name[:1] {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// lowerFirst returns a string with its first letter in lower case
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// declKey identifies a top-level declaration: a type, function, or method
+func declKey(decl ast.Decl) []string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return []string{receiverName(d) + "." + d.Name.Name}
+	case *ast.GenDecl:
+		var keys []string
+		for _, spec := range d.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				keys = append(keys, ts.Name.Name)
+			}
+		}
+		return keys
+	}
+	return nil
+}
+
+// adoptGeneratedResolvers moves the declarations of a resolver file generated by gqlgen
+// that are not declared in the hand-written files of the resolver package to the stub
+// file, and removes the generated file.
+//
+// gqlgen writes every resolver to the file of the schema, copying the bodies of the
+// resolvers it finds. The resolvers of this package are written by hand in one file per
+// type instead, so only new declarations are kept: resolvers of new fields, whose
+// generated bodies panic, become stubs that return notImplemented, and new resolver
+// types and their accessors are kept as they are.
+//
+// Returns the schema fields of the new stubs.
+func adoptGeneratedResolvers(dir, generatedPath string) ([]string, error) {
+	generated, err := parseFile(generatedPath)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := parseDir(dir, map[string]bool{filepath.Base(generatedPath): true})
+	if err != nil {
+		return nil, err
+	}
+	declared := make(map[string]bool)
+	var existing *parsedFile
+	for i, file := range files {
+		for _, decl := range file.ast.Decls {
+			for _, key := range declKey(decl) {
+				declared[key] = true
+			}
+		}
+		if file.name == stubFile {
+			existing = &files[i]
+		}
+	}
+
+	var decls []string
+	var fields []string
+	for _, decl := range generated.ast.Decls {
+		keys := declKey(decl)
+		if len(keys) == 0 || declared[keys[0]] {
+			continue
+		}
+		text, field, err := adoptDecl(generated, decl)
+		if err != nil {
+			return nil, err
+		}
+		decls = append(decls, text)
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	if len(decls) > 0 {
+		if err := writeStubFile(filepath.Join(dir, stubFile), existing, generated, decls); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.Remove(generatedPath); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// declStart returns the position of a declaration, including its doc comment
+func declStart(decl ast.Decl) token.Pos {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			return d.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			return d.Doc.Pos()
+		}
+	}
+	return decl.Pos()
+}
+
+// adoptDecl returns the source of a declaration of a generated resolver file. Resolver
+// methods that panic are rewritten as stubs, and their schema field is returned.
+func adoptDecl(file parsedFile, decl ast.Decl) (string, string, error) {
+	start := declStart(decl)
+	src := string(file.src[file.fset.Position(start).Offset:file.fset.Position(decl.End()).Offset])
+
+	fn, isFunc := decl.(*ast.FuncDecl)
+	if !isFunc || fn.Body == nil || resolverTypeName(receiverName(fn)) == "" {
+		return src, "", nil
+	}
+	body := file.src[file.fset.Position(fn.Body.Pos()).Offset:file.fset.Position(fn.Body.End()).Offset]
+	if !isStub(body) {
+		return src, "", nil
+	}
+	typeName := resolverTypeName(receiverName(fn))
+
+	fieldName := lowerFirst(fn.Name.Name)
+	if fn.Doc != nil {
+		if m := resolverComment.FindStringSubmatch(fn.Doc.Text()); m != nil {
+			fieldName = m[1]
+		}
+	}
+	field := typeName + "." + fieldName
+
+	var results []string
+	if fn.Type.Results != nil {
+		for _, result := range fn.Type.Results.List {
+			n := len(result.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				results = append(results, zeroValue(file, result.Type))
+			}
+		}
+	}
+	if len(results) == 0 {
+		return "", "", fmt.Errorf("%s: resolver %s returns no error", file.name, fn.Name.Name)
+	}
+	results[len(results)-1] = "notImplemented(" + strconv.Quote(field) + ")"
+
+	header := string(file.src[file.fset.Position(start).Offset:file.fset.Position(fn.Body.Pos()).Offset])
+	return header + "{\n\treturn " + strings.Join(results, ", ") + "\n}", field, nil
+}
+
+// zeroValue returns the source of the zero value of a type expression
+func zeroValue(file parsedFile, expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr, *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+		return "nil"
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return `""`
+		case "bool":
+			return "false"
+		case "error", "any":
+			return "nil"
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64", "byte", "rune":
+			return "0"
+		}
+	}
+	src := file.src[file.fset.Position(expr.Pos()).Offset:file.fset.Position(expr.End()).Offset]
+	return "*new(" + string(src) + ")"
+}
+
+// writeStubFile writes the stub file with the declarations of an existing stub file,
+// if any, followed by new declarations. The imports are those of the existing and
+// generated files that the declarations use.
+func writeStubFile(path string, existing *parsedFile, generated parsedFile, decls []string) error {
+	imports := make(map[string]string) // Import path by package name
+	var body []string
+	collect := func(file parsedFile) {
+		for _, spec := range file.ast.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			name := importPath[strings.LastIndex(importPath, "/")+1:]
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			imports[name] = strings.TrimSpace(string(file.src[file.fset.Position(spec.Pos()).Offset:file.fset.Position(spec.End()).Offset]))
+		}
+	}
+	collect(generated)
+	if existing != nil {
+		collect(*existing)
+		for _, decl := range existing.ast.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+				continue
+			}
+			start := declStart(decl)
+			body = append(body, string(existing.src[existing.fset.Position(start).Offset:existing.fset.Position(decl.End()).Offset]))
+		}
+	}
+	body = append(body, decls...)
+	code := strings.Join(body, "\n\n")
+
+	// Keep the imports whose package names are used
+	used := make(map[string]bool)
+	if f, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+code, 0); err == nil {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok {
+					used[ident.Name] = true
+				}
+			}
+			return true
+		})
+	} else {
+		return fmt.Errorf("failed to parse the stubs: %w", err)
+	}
+	var specs []string
+	for name, spec := range imports {
+		if used[name] {
+			specs = append(specs, spec)
+		}
+	}
+	sort.Strings(specs)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Copyright (c) 2025 A Bit of Help, Inc.\n\npackage resolver\n\n")
+	buf.WriteString("// Resolvers of schema fields that are not implemented yet. They were added by\n")
+	buf.WriteString("// tools/graphql-gen when the fields were added to the schema; move each one to the\n")
+	buf.WriteString("// file of its type when it is implemented.\n\n")
+	if len(specs) > 0 {
+		buf.WriteString("import (\n\t" + strings.Join(specs, "\n\t") + "\n)\n\n")
+	}
+	buf.WriteString(code + "\n")
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	return os.WriteFile(path, formatted, 0o644)
+}
+
+// writeStubList writes the file that lists the stubbed fields, which the server flags
+// at startup
+func writeStubList(path string, methods []resolverMethod) error {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by tools/graphql-gen. DO NOT EDIT.\n\npackage resolver\n\n")
+	buf.WriteString("// stubbedFields lists the schema fields whose resolvers are stubs\n")
+	buf.WriteString("var stubbedFields = []string{")
+	first := true
+	for _, m := range methods {
+		if !m.Stub {
+			continue
+		}
+		if first {
+			buf.WriteString("\n")
+			first = false
+		}
+		buf.WriteString("\t" + strconv.Quote(m.Field) + ",\n")
+	}
+	buf.WriteString("}\n")
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, formatted, 0o644)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const handWritten = `package resolver
+
+import "context"
+
+// GetFamily is the resolver for the getFamily field.
+func (r *queryResolver) GetFamily(ctx context.Context, id string) (*string, error) {
+	return nil, nil
+}
+
+// Query returns generated.QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type queryResolver struct{ *Resolver }
+`
+
+const generatedResolvers = `package resolver
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.75
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/model"
+)
+
+// GetFamily is the resolver for the getFamily field.
+func (r *queryResolver) GetFamily(ctx context.Context, id string) (*string, error) {
+	return nil, nil
+}
+
+// FamilyTree is the resolver for the familyTree field.
+func (r *queryResolver) FamilyTree(ctx context.Context, id string) ([]*model.Family, error) {
+	panic(fmt.Errorf("not implemented: FamilyTree - familyTree"))
+}
+
+// MemberCount is the resolver for the memberCount field.
+func (r *queryResolver) MemberCount(ctx context.Context) (int, error) {
+	panic(fmt.Errorf("not implemented: MemberCount - memberCount"))
+}
+
+// Query returns generated.QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type queryResolver struct{ *Resolver }
+`
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestAdoptGeneratedResolvers(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "query_resolvers.go"), handWritten)
+	generatedPath := filepath.Join(dir, "schema_resolvers.go")
+	writeFile(t, generatedPath, generatedResolvers)
+
+	fields, err := adoptGeneratedResolvers(dir, generatedPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Query.familyTree", "Query.memberCount"}, fields)
+	assert.NoFileExists(t, generatedPath)
+
+	stubs, err := os.ReadFile(filepath.Join(dir, stubFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(stubs), `return nil, notImplemented("Query.familyTree")`)
+	assert.Contains(t, string(stubs), `return 0, notImplemented("Query.memberCount")`)
+	assert.Contains(t, string(stubs), `"github.com/abitofhelp/family-service/interface/adapters/graphql/model"`)
+	assert.NotContains(t, string(stubs), `"fmt"`)
+	assert.NotContains(t, string(stubs), "GetFamily")
+
+	methods, err := scanResolvers(dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []resolverMethod{
+		{Receiver: "queryResolver", Method: "FamilyTree", Field: "Query.familyTree", Stub: true},
+		{Receiver: "queryResolver", Method: "GetFamily", Field: "Query.getFamily"},
+		{Receiver: "queryResolver", Method: "MemberCount", Field: "Query.memberCount", Stub: true},
+	}, methods)
+
+	// A second run keeps the existing stubs
+	writeFile(t, generatedPath, generatedResolvers)
+	fields, err = adoptGeneratedResolvers(dir, generatedPath)
+	require.NoError(t, err)
+	assert.Empty(t, fields)
+	methods, err = scanResolvers(dir, nil)
+	require.NoError(t, err)
+	assert.Len(t, methods, 3)
+}
+
+func TestWriteStubList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), stubsGenFile)
+	require.NoError(t, writeStubList(path, []resolverMethod{
+		{Field: "Query.familyTree", Stub: true},
+		{Field: "Query.getFamily"},
+	}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "var stubbedFields = []string{\n\t\"Query.familyTree\",\n}")
+}
+
+func TestCheckCoverage(t *testing.T) {
+	problems := checkCoverage(
+		[]string{"Query.getFamily", "Query.familyTree"},
+		map[string]bool{"Query.getFamily": true, "Query.familyTree": true},
+		[]resolverMethod{
+			{Receiver: "queryResolver", Method: "GetFamily", Field: "Query.getFamily"},
+			{Receiver: "queryResolver", Method: "OldField", Field: "Query.oldField"},
+		})
+	assert.Equal(t, []string{
+		"queryResolver.OldField: resolves Query.oldField, which is not in the schema",
+		"Query.familyTree: has no resolver",
+	}, problems)
+}
+
+func TestWriteSkeletons(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "query_test.go"), "package resolver\n\nimport \"testing\"\n\nfunc TestQueryResolver_GetFamily_Error(t *testing.T) {}\n")
+	methods := []resolverMethod{
+		{Receiver: "queryResolver", Method: "GetFamily", Field: "Query.getFamily"},
+		{Receiver: "queryResolver", Method: "FamilyTree", Field: "Query.familyTree", Stub: true},
+	}
+	path := filepath.Join(dir, skeletonFile)
+
+	count, err := writeSkeletons(dir, methods)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "func TestQueryResolver_FamilyTree(t *testing.T) {\n\tt.Skip(\"Query.familyTree has no test yet\")")
+	assert.NotContains(t, string(data), "GetFamily")
+
+	// Once every resolver is tested, the skeletons are removed
+	writeFile(t, filepath.Join(dir, "tree_test.go"), "package resolver\n\nimport \"testing\"\n\nfunc TestQueryResolver_FamilyTree(t *testing.T) {}\n")
+	count, err = writeSkeletons(dir, methods)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.NoFileExists(t, path)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// testedMethods returns the names of the resolver methods that have a test, going by
+// the names of the test functions in the _test.go files of a directory: a test of
+// GetFamily is named like TestQueryResolver_GetFamily or TestQueryResolver_GetFamily_Error.
+// The skeleton file itself is ignored.
+func testedMethods(dir, skeletonName string) (map[string]bool, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	tested := make(map[string]bool)
+	for _, path := range paths {
+		if filepath.Base(path) == skeletonName {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "Test") {
+				continue
+			}
+			for _, part := range strings.Split(fn.Name.Name, "_")[1:] {
+				tested[part] = true
+			}
+		}
+	}
+	return tested, nil
+}
+
+// skeletonName returns the name of the test of a resolver method, such as
+// TestQueryResolver_GetFamily
+func skeletonName(m resolverMethod) string {
+	return "Test" + strings.ToUpper(m.Receiver[:1]) + m.Receiver[1:] + "_" + m.Method
+}
+
+// skeletonFile is the file of the resolver package to which test skeletons are written
+const skeletonFile = "skeleton_test.go"
+
+// writeSkeletons writes a skipped test for each resolver method that has no test to the
+// skeleton file of a resolver package, so that untested resolvers show up as skipped
+// tests. To test a resolver, copy its
+// skeleton to a test file of the package and fill it in; it is left out of the
+// skeletons when they are generated again. If every resolver is tested, the skeleton
+// file is removed.
+//
+// Returns the number of skeletons written.
+func writeSkeletons(dir string, methods []resolverMethod) (int, error) {
+	path := filepath.Join(dir, skeletonFile)
+	tested, err := testedMethods(dir, skeletonFile)
+	if err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by tools/graphql-gen. DO NOT EDIT.\n\n")
+	buf.WriteString("package resolver\n\nimport \"testing\"\n")
+	count := 0
+	for _, m := range methods {
+		if tested[m.Method] {
+			continue
+		}
+		count++
+		fmt.Fprintf(&buf, "\nfunc %s(t *testing.T) {\n", skeletonName(m))
+		fmt.Fprintf(&buf, "\tt.Skip(%q)\n\n", m.Field+" has no test yet")
+		buf.WriteString("\t// Arrange: set up the mocks of the service and mapper of the resolver\n")
+		fmt.Fprintf(&buf, "\t// Act: call %s\n", m.Method)
+		buf.WriteString("\t// Assert: check the result, the error, and the expectations of the mocks\n")
+		buf.WriteString("}\n")
+	}
+
+	if count == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+		return 0, nil
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	return count, os.WriteFile(path, formatted, 0o644)
+}
@@ -0,0 +1,382 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/ports"
+	"github.com/abitofhelp/servicelib/errors/domain"
+	"go.uber.org/zap"
+)
+
+// Error codes reported by the importer itself; validation errors keep the code of the domain error
+const (
+	InvalidJSONCode   = "INVALID_JSON"
+	SaveFailedCode    = "SAVE_FAILED"
+	InvalidFamilyCode = "INVALID_FAMILY"
+)
+
+// Import statuses
+const (
+	ImportCompleted = "completed" // Every valid row was imported
+	ImportAborted   = "aborted"   // The error rate exceeded the threshold, so nothing was imported
+)
+
+// maxLineSize is the largest row the importer accepts
+const maxLineSize = 1 << 20
+
+// RowError is a problem with one row of an import file
+type RowError struct {
+	Row        int    `json:"row"`                 // Line number of the row, starting at 1
+	FamilyID   string `json:"family_id,omitempty"` // ID of the family in the row, if it could be read
+	Field      string `json:"field,omitempty"`     // Field of the family that is invalid, such as "Parents[0].BirthDate"
+	Code       string `json:"code"`                // Error code, such as "VALIDATION_ERROR"
+	Message    string `json:"message"`             // Description of the problem
+	Suggestion string `json:"suggestion"`          // How to fix the row
+}
+
+// ImportSummary summarizes an import run. It is recorded in the checkpoint file as the status of the job.
+type ImportSummary struct {
+	File         string         `json:"file"`           // Path of the import file
+	Status       string         `json:"status"`         // ImportCompleted or ImportAborted
+	Rows         int            `json:"rows"`           // Number of rows in the file, not counting blank lines
+	Valid        int            `json:"valid"`          // Number of rows that passed validation
+	Imported     int            `json:"imported"`       // Number of families written to the destination
+	Failed       int            `json:"failed"`         // Number of rows with at least one error
+	ErrorRate    float64        `json:"error_rate"`     // Percentage of rows that failed validation
+	MaxErrorRate float64        `json:"max_error_rate"` // Percentage above which the import is aborted
+	ErrorsByCode map[string]int `json:"errors_by_code"` // Number of errors of each code
+	FinishedAt   time.Time      `json:"finished_at"`
+}
+
+// ImportReport is the validation report of an import run
+type ImportReport struct {
+	Summary ImportSummary `json:"summary"`
+	Errors  []RowError    `json:"errors"`
+}
+
+// ImportOptions controls the behavior of an import run.
+type ImportOptions struct {
+	CheckpointPath string  // Path of the checkpoint file in which the summary is recorded (empty disables it)
+	ReportPath     string  // Path of the JSON validation report (empty disables it)
+	MaxErrorRate   float64 // Percentage of invalid rows above which nothing is imported
+	DryRun         bool    // Validate the file without writing to the destination
+}
+
+// Importer loads families from a JSON Lines file into a repository.
+//
+// Each line of the file is a family DTO. A bad row does not fail the file: every row is
+// validated first and its problems are recorded in the report, then the valid rows are
+// written, unless the percentage of invalid rows exceeds the threshold.
+type Importer struct {
+	dest   ports.FamilyRepository
+	opts   ImportOptions
+	logger *zap.Logger
+}
+
+// NewImporter creates a new Importer.
+func NewImporter(dest ports.FamilyRepository, opts ImportOptions, logger *zap.Logger) *Importer {
+	if dest == nil {
+		panic("destination repository cannot be nil")
+	}
+	if logger == nil {
+		panic("logger cannot be nil")
+	}
+	return &Importer{
+		dest:   dest,
+		opts:   opts,
+		logger: logger,
+	}
+}
+
+// Run imports the families in the file at the given path.
+//
+// The report is written, and the summary recorded in the checkpoint file, even when the
+// import is aborted or a write fails, so that the rows can be fixed and the file imported
+// again. Saves are upserts, so importing a file again is safe.
+//
+// Returns:
+//   - The validation report
+//   - An error if the file cannot be read, the import is aborted, or the report cannot be written
+func (im *Importer) Run(ctx context.Context, path string) (*ImportReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	report := &ImportReport{
+		Summary: ImportSummary{File: path, MaxErrorRate: im.opts.MaxErrorRate},
+		Errors:  []RowError{},
+	}
+	rows, err := im.validate(file, report)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &report.Summary
+	if summary.Rows > 0 {
+		summary.ErrorRate = 100 * float64(summary.Failed) / float64(summary.Rows)
+	}
+
+	var runErr error
+	if summary.ErrorRate > im.opts.MaxErrorRate {
+		summary.Status = ImportAborted
+		runErr = fmt.Errorf("%d of %d rows are invalid (%.1f%%), which exceeds the maximum error rate of %.1f%%",
+			summary.Failed, summary.Rows, summary.ErrorRate, im.opts.MaxErrorRate)
+	} else {
+		summary.Status = ImportCompleted
+		runErr = im.write(ctx, rows, report)
+	}
+
+	summary.ErrorsByCode = make(map[string]int)
+	for _, rowErr := range report.Errors {
+		summary.ErrorsByCode[rowErr.Code]++
+	}
+	summary.FinishedAt = time.Now().UTC()
+
+	if err := im.record(report); err != nil {
+		return report, err
+	}
+	return report, runErr
+}
+
+// importRow is a row that passed validation
+type importRow struct {
+	number int
+	family *entity.Family
+}
+
+// validate reads and validates every row of the file, adding the problems to the report
+func (im *Importer) validate(r io.Reader, report *ImportReport) ([]importRow, error) {
+	var rows []importRow
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		report.Summary.Rows++
+
+		fam, rowErrs := validateRow(number, []byte(line))
+		if len(rowErrs) > 0 {
+			report.Summary.Failed++
+			report.Errors = append(report.Errors, rowErrs...)
+			continue
+		}
+		report.Summary.Valid++
+		rows = append(rows, importRow{number: number, family: fam})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read import file: %w", err)
+	}
+	return rows, nil
+}
+
+// validateRow converts a row into a family, returning every problem found with it.
+// Parents and children are validated separately so that all of their problems are
+// reported at once; the family as a whole is only validated when they are valid.
+func validateRow(number int, line []byte) (*entity.Family, []RowError) {
+	var dto entity.FamilyDTO
+	if err := json.Unmarshal(line, &dto); err != nil {
+		return nil, []RowError{{
+			Row:        number,
+			Code:       InvalidJSONCode,
+			Message:    err.Error(),
+			Suggestion: suggestion("", InvalidJSONCode),
+		}}
+	}
+
+	var rowErrs []RowError
+	addError := func(prefix string, err error) {
+		field, code, message := errorDetails(err)
+		if prefix != "" {
+			field = strings.TrimSuffix(prefix+"."+field, ".")
+		}
+		rowErrs = append(rowErrs, RowError{
+			Row:        number,
+			FamilyID:   dto.ID,
+			Field:      field,
+			Code:       code,
+			Message:    message,
+			Suggestion: suggestion(field, code),
+		})
+	}
+
+	parents := make([]*entity.Parent, len(dto.Parents))
+	for i, pDTO := range dto.Parents {
+		p, err := entity.ParentFromDTO(pDTO)
+		if err != nil {
+			addError(fmt.Sprintf("Parents[%d]", i), err)
+			continue
+		}
+		parents[i] = p
+	}
+	children := make([]*entity.Child, len(dto.Children))
+	for i, cDTO := range dto.Children {
+		c, err := entity.ChildFromDTO(cDTO)
+		if err != nil {
+			addError(fmt.Sprintf("Children[%d]", i), err)
+			continue
+		}
+		children[i] = c
+	}
+	if len(rowErrs) > 0 {
+		return nil, rowErrs
+	}
+
+	fam, err := entity.NewFamily(dto.ID, entity.Status(dto.Status), parents, children)
+	if err != nil {
+		addError("", err)
+		return nil, rowErrs
+	}
+	if err := fam.SetExternalIDs(dto.ExternalIDs); err != nil {
+		addError("", err)
+		return nil, rowErrs
+	}
+	return fam, nil
+}
+
+// write saves the valid rows, recording the rows that cannot be saved in the report
+func (im *Importer) write(ctx context.Context, rows []importRow, report *ImportReport) error {
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if im.opts.DryRun {
+			im.logger.Debug("Dry run: would import family", zap.Int("row", row.number), zap.String("family_id", row.family.ID()))
+			continue
+		}
+
+		// Imports replace stored families, so that a file can be imported again
+		if err := im.dest.Save(ports.WithOverwrite(ctx), row.family); err != nil {
+			report.Summary.Failed++
+			report.Errors = append(report.Errors, RowError{
+				Row:        row.number,
+				FamilyID:   row.family.ID(),
+				Code:       SaveFailedCode,
+				Message:    err.Error(),
+				Suggestion: suggestion("", SaveFailedCode),
+			})
+			continue
+		}
+		report.Summary.Imported++
+	}
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+	return nil
+}
+
+// record writes the report and records the summary in the checkpoint file
+func (im *Importer) record(report *ImportReport) error {
+	if im.opts.ReportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode import report: %w", err)
+		}
+		if err := writeFileAtomic(im.opts.ReportPath, data); err != nil {
+			return fmt.Errorf("failed to write import report: %w", err)
+		}
+	}
+
+	if im.opts.CheckpointPath != "" {
+		cp, err := loadCheckpoint(im.opts.CheckpointPath)
+		if err != nil {
+			return err
+		}
+		summary := report.Summary
+		cp.Import = &summary
+		if err := cp.save(im.opts.CheckpointPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errorDetails returns the field, code, and message of an error returned by the domain
+func errorDetails(err error) (string, string, string) {
+	var ve *domain.ValidationError
+	if errors.As(err, &ve) {
+		return ve.Field, string(ve.GetCode()), ve.GetMessage()
+	}
+	var coded interface {
+		Code() string
+		Message() string
+	}
+	if errors.As(err, &coded) {
+		return "", coded.Code(), coded.Message()
+	}
+	return "", InvalidFamilyCode, err.Error()
+}
+
+// fieldSuggestions suggest how to fix an invalid value of a field, by the last element of its path
+var fieldSuggestions = map[string]string{
+	"ID":          "use a UUID, such as 123e4567-e89b-12d3-a456-426614174000",
+	"FirstName":   "use at least 2 characters, only letters, spaces, and hyphens",
+	"LastName":    "use at least 2 characters, only letters, spaces, and hyphens",
+	"BirthDate":   "use an RFC 3339 date in the past, such as 1980-01-31T00:00:00Z, that is at most 150 years ago",
+	"DeathDate":   "use an RFC 3339 date after the birth date and not in the future, or omit the field",
+	"Status":      "use SINGLE, MARRIED, DIVORCED, WIDOWED, or ABANDONED, consistent with the number of parents and children",
+	"Parents":     "give the family one or two distinct adult parents",
+	"Children":    "give each child a birth date after those of the parents and distinct from other children",
+	"ExternalIDs": "use external ID systems allowed by the service configuration, with non-empty IDs",
+}
+
+// codeSuggestions suggest how to fix a row by error code, when the field does not say enough
+var codeSuggestions = map[string]string{
+	InvalidJSONCode:           "make the line a single JSON object with the fields of a family",
+	SaveFailedCode:            "check that the destination is available, then import the file again",
+	"FAMILY_TOO_MANY_PARENTS": "remove parents so that the family has at most two",
+	"FAMILY_PARENT_EXISTS":    "remove the duplicate parent",
+	"FAMILY_PARENT_DUPLICATE": "remove the parent with the same name and birth date",
+	"FAMILY_CHILD_EXISTS":     "remove the duplicate child",
+}
+
+// suggestion returns how to fix a row with an error of the given code in the given field
+func suggestion(field, code string) string {
+	if s, ok := codeSuggestions[code]; ok {
+		return s
+	}
+	name := field
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if s, ok := fieldSuggestions[name]; ok {
+		return s
+	}
+	if s, ok := fieldSuggestions[strings.SplitN(field, "[", 2)[0]]; ok {
+		return s
+	}
+	return "correct the row and import the file again"
+}
+
+// writeFileAtomic writes a file by writing to a temporary file and renaming it
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// importRows are the rows of the import tests: two valid families, a row that is not JSON,
+// and a family with an invalid parent and an invalid child
+var importRows = []string{
+	`{"ID":"10000000-0000-4000-8000-000000000001","Status":"SINGLE","Parents":[{"ID":"20000000-0000-4000-8000-000000000001","FirstName":"John","LastName":"Doe","BirthDate":"1980-01-01T00:00:00Z"}]}`,
+	`{"ID":"10000000-0000-4000-8000-000000000002",`,
+	``,
+	`{"ID":"10000000-0000-4000-8000-000000000003","Status":"SINGLE","Parents":[{"ID":"20000000-0000-4000-8000-000000000003","FirstName":"J","LastName":"Doe","BirthDate":"1980-01-01T00:00:00Z"}],"Children":[{"ID":"30000000-0000-4000-8000-000000000003","FirstName":"Jane","LastName":"Doe","BirthDate":"2999-01-01T00:00:00Z"}]}`,
+	`{"ID":"10000000-0000-4000-8000-000000000004","Status":"SINGLE","Parents":[{"ID":"20000000-0000-4000-8000-000000000004","FirstName":"Mary","LastName":"Roe","BirthDate":"1975-06-15T00:00:00Z"}]}`,
+}
+
+// writeImportFile writes the import rows to a file
+func writeImportFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "families.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(importRows, "\n")+"\n"), 0o600))
+	return path
+}
+
+func TestImporter_Run(t *testing.T) {
+	dest := newMemRepo()
+	dir := t.TempDir()
+	opts := ImportOptions{
+		CheckpointPath: filepath.Join(dir, "checkpoint.json"),
+		ReportPath:     filepath.Join(dir, "report.json"),
+		MaxErrorRate:   100,
+	}
+
+	report, err := NewImporter(dest, opts, zaptest.NewLogger(t)).Run(context.Background(), writeImportFile(t))
+	require.NoError(t, err)
+
+	// The bad rows do not stop the valid ones
+	assert.Len(t, dest.families, 2)
+	summary := report.Summary
+	assert.Equal(t, ImportCompleted, summary.Status)
+	assert.Equal(t, 4, summary.Rows)
+	assert.Equal(t, 2, summary.Valid)
+	assert.Equal(t, 2, summary.Imported)
+	assert.Equal(t, 2, summary.Failed)
+	assert.Equal(t, 50.0, summary.ErrorRate)
+	assert.Equal(t, map[string]int{InvalidJSONCode: 1, "VALIDATION_ERROR": 2}, summary.ErrorsByCode)
+
+	// Every problem is reported with its line and field
+	require.Len(t, report.Errors, 3)
+	assert.Equal(t, 2, report.Errors[0].Row)
+	assert.Equal(t, InvalidJSONCode, report.Errors[0].Code)
+	assert.Equal(t, 4, report.Errors[1].Row)
+	assert.Equal(t, "Parents[0].FirstName", report.Errors[1].Field)
+	assert.Equal(t, "10000000-0000-4000-8000-000000000003", report.Errors[1].FamilyID)
+	assert.Equal(t, fieldSuggestions["FirstName"], report.Errors[1].Suggestion)
+	assert.Equal(t, "Children[0].BirthDate", report.Errors[2].Field)
+	assert.Equal(t, fieldSuggestions["BirthDate"], report.Errors[2].Suggestion)
+
+	// The report is written as JSON, and the summary is the status of the job
+	data, err := os.ReadFile(opts.ReportPath)
+	require.NoError(t, err)
+	var written ImportReport
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Len(t, written.Errors, 3)
+
+	cp, err := loadCheckpoint(opts.CheckpointPath)
+	require.NoError(t, err)
+	require.NotNil(t, cp.Import)
+	assert.Equal(t, ImportCompleted, cp.Import.Status)
+	assert.Equal(t, 2, cp.Import.Imported)
+}
+
+func TestImporter_Abort(t *testing.T) {
+	dest := newMemRepo()
+	cpPath := filepath.Join(t.TempDir(), "checkpoint.json")
+	opts := ImportOptions{CheckpointPath: cpPath, MaxErrorRate: 25}
+
+	report, err := NewImporter(dest, opts, zaptest.NewLogger(t)).Run(context.Background(), writeImportFile(t))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the maximum error rate of 25.0%")
+
+	// Nothing is imported, but the problems are still reported
+	assert.Empty(t, dest.families)
+	assert.Equal(t, ImportAborted, report.Summary.Status)
+	assert.Len(t, report.Errors, 3)
+
+	cp, err := loadCheckpoint(cpPath)
+	require.NoError(t, err)
+	assert.Equal(t, ImportAborted, cp.Import.Status)
+}
+
+func TestImporter_SaveFailed(t *testing.T) {
+	dest := newMemRepo()
+	dest.failOn = "10000000-0000-4000-8000-000000000004"
+
+	report, err := NewImporter(dest, ImportOptions{MaxErrorRate: 100}, zaptest.NewLogger(t)).Run(context.Background(), writeImportFile(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Summary.Imported)
+	assert.Equal(t, 3, report.Summary.Failed)
+	last := report.Errors[len(report.Errors)-1]
+	assert.Equal(t, 5, last.Row)
+	assert.Equal(t, SaveFailedCode, last.Code)
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/core/domain/query"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// memRepo is an in-memory family repository used by the migration tests
+type memRepo struct {
+	mu       sync.Mutex
+	families map[string]*entity.Family
+	failOn   string
+	mutate   bool
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{families: make(map[string]*entity.Family)}
+}
+
+func (r *memRepo) GetByID(ctx context.Context, id string) (*entity.Family, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fam, ok := r.families[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	if r.mutate {
+		dto := fam.ToDTO()
+		dto.Status = string(entity.Divorced)
+		return entity.FamilyFromDTO(dto)
+	}
+	return fam, nil
+}
+
+func (r *memRepo) Save(ctx context.Context, fam *entity.Family) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fam.ID() == r.failOn {
+		return errors.New("write failed")
+	}
+	r.families[fam.ID()] = fam
+	return nil
+}
+
+func (r *memRepo) GetAll(ctx context.Context) ([]*entity.Family, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]*entity.Family, 0, len(r.families))
+	for _, fam := range r.families {
+		result = append(result, fam)
+	}
+	return result, nil
+}
+
+func (r *memRepo) FindByParentID(ctx context.Context, parentID string) ([]*entity.Family, error) {
+	return nil, nil
+}
+
+func (r *memRepo) FindByChildID(ctx context.Context, childID string) (*entity.Family, error) {
+	return nil, nil
+}
+
+func (r *memRepo) FindByExternalID(ctx context.Context, system, externalID string) ([]*entity.Family, error) {
+	return nil, nil
+}
+
+func (r *memRepo) Find(ctx context.Context, filter query.Filter) ([]*entity.Family, error) {
+	return nil, nil
+}
+
+func (r *memRepo) CountFamilies(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *memRepo) CountParents(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *memRepo) CountChildren(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// seedFamily adds a single-parent family with the given ID to the repository
+func seedFamily(t *testing.T, repo *memRepo, id string) {
+	t.Helper()
+	parent, err := entity.NewParent(uuid.New().String(), "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := entity.NewFamily(id, entity.Single, []*entity.Parent{parent}, nil)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(context.Background(), fam))
+}
+
+var familyIDs = []string{
+	"10000000-0000-4000-8000-000000000001",
+	"10000000-0000-4000-8000-000000000002",
+	"10000000-0000-4000-8000-000000000003",
+}
+
+func TestMigrator_Run(t *testing.T) {
+	source, dest := newMemRepo(), newMemRepo()
+	for _, id := range familyIDs {
+		seedFamily(t, source, id)
+	}
+
+	m := NewMigrator(source, dest, Options{Verify: true}, zaptest.NewLogger(t))
+	summary, err := m.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Total)
+	assert.Equal(t, 3, summary.Migrated)
+	assert.Equal(t, 3, summary.Verified)
+	assert.Len(t, dest.families, 3)
+}
+
+func TestMigrator_Resume(t *testing.T) {
+	source, dest := newMemRepo(), newMemRepo()
+	for _, id := range familyIDs {
+		seedFamily(t, source, id)
+	}
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	// The first run fails on the second family
+	dest.failOn = familyIDs[1]
+	m := NewMigrator(source, dest, Options{CheckpointPath: checkpoint, Verify: true}, zaptest.NewLogger(t))
+	_, err := m.Run(context.Background())
+	require.Error(t, err)
+
+	cp, err := loadCheckpoint(checkpoint)
+	require.NoError(t, err)
+	assert.Equal(t, familyIDs[0], cp.LastID)
+
+	// The second run resumes after the checkpoint
+	dest.failOn = ""
+	summary, err := m.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.Equal(t, 2, summary.Migrated)
+	assert.Len(t, dest.families, 3)
+}
+
+func TestMigrator_ChecksumMismatch(t *testing.T) {
+	source, dest := newMemRepo(), newMemRepo()
+	seedFamily(t, source, familyIDs[0])
+	dest.mutate = true
+
+	m := NewMigrator(source, dest, Options{Verify: true}, zaptest.NewLogger(t))
+	_, err := m.Run(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+}
+
+func TestMigrator_DryRun(t *testing.T) {
+	source, dest := newMemRepo(), newMemRepo()
+	seedFamily(t, source, familyIDs[0])
+
+	m := NewMigrator(source, dest, Options{DryRun: true}, zaptest.NewLogger(t))
+	summary, err := m.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.Migrated)
+	assert.Empty(t, dest.families)
+}
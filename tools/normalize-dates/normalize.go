@@ -0,0 +1,104 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"context"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/integrity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/repair"
+	"go.uber.org/zap"
+)
+
+// Store is a repository whose families can be scanned and saved
+type Store interface {
+	integrity.Scanner
+	Save(ctx context.Context, fam *entity.Family) error
+}
+
+// Options controls a normalization run
+type Options struct {
+	DryRun bool // Report the families that would be normalized without saving them
+}
+
+// Entry describes a family whose stored dates are not in canonical form
+type Entry struct {
+	FamilyID   string   `json:"family_id"`
+	Changes    []string `json:"changes"`         // The date repairs, as "kind path: detail"
+	Normalized bool     `json:"normalized"`      // Whether the family was saved in canonical form
+	Error      string   `json:"error,omitempty"` // Why the family could not be saved
+}
+
+// Report is the outcome of a normalization run
+type Report struct {
+	Scanned    int     `json:"scanned"`
+	Pending    int     `json:"pending"`    // Families with dates that are not in canonical form
+	Normalized int     `json:"normalized"` // Families saved in canonical form
+	Failed     int     `json:"failed"`     // Families that could not be saved
+	Skipped    int     `json:"skipped"`    // Corrupt families, which are left to verify-integrity
+	Families   []Entry `json:"families"`
+}
+
+// dateChanges returns the repairs of a record that change a stored date
+func dateChanges(repairs []repair.Repair) []string {
+	var changes []string
+	for _, r := range repairs {
+		if r.Kind == repair.KindDateReformatted || r.Kind == repair.KindDateTruncated {
+			changes = append(changes, r.String())
+		}
+	}
+	return changes
+}
+
+// Normalize scans every stored family and saves, in canonical form, those whose birth or
+// death dates are stored as timestamps other than midnight UTC or in another layout.
+//
+// Families are decoded with read repair, which takes the calendar date of each stored
+// timestamp in the offset it was written with, so a date written as midnight in a time
+// zone west of UTC keeps its day rather than moving to the day before or after. Saving
+// the repaired family writes the date as midnight UTC.
+//
+// Families are saved after the scan, so that no write happens while the scan cursor is open.
+func Normalize(ctx context.Context, store Store, opts Options, logger *zap.Logger) (*Report, error) {
+	report := &Report{Families: []Entry{}}
+	var pending []*entity.Family
+
+	err := store.ScanFamilies(ctx, func(rec integrity.Record) error {
+		report.Scanned++
+		if rec.Family == nil {
+			report.Skipped++
+			return nil
+		}
+		changes := dateChanges(rec.Repairs)
+		if len(changes) == 0 {
+			return nil
+		}
+		report.Pending++
+		report.Families = append(report.Families, Entry{FamilyID: rec.FamilyID, Changes: changes})
+		pending = append(pending, rec.Family)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	for i, fam := range pending {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if err := store.Save(ctx, fam); err != nil {
+			logger.Warn("Failed to save normalized family", zap.String("family_id", fam.ID()), zap.Error(err))
+			report.Families[i].Error = err.Error()
+			report.Failed++
+			continue
+		}
+		report.Families[i].Normalized = true
+		report.Normalized++
+	}
+	return report, nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/family-service/core/domain/entity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/integrity"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/repair"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// stubStore returns fixed records and records the families it saves
+type stubStore struct {
+	records []integrity.Record
+	failOn  string
+	saved   []string
+}
+
+func (s *stubStore) ScanFamilies(ctx context.Context, fn func(integrity.Record) error) error {
+	for _, rec := range s.records {
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stubStore) Quarantine(ctx context.Context, familyID, reason string) error {
+	return nil
+}
+
+func (s *stubStore) Save(ctx context.Context, fam *entity.Family) error {
+	if fam.ID() == s.failOn {
+		return errors.New("write failed")
+	}
+	s.saved = append(s.saved, fam.ID())
+	return nil
+}
+
+func record(t *testing.T, repairs ...repair.Repair) integrity.Record {
+	id := uuid.New().String()
+	parent, err := entity.NewParent(uuid.New().String(), "John", "Doe", time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	fam, err := entity.NewFamily(id, entity.Single, []*entity.Parent{parent}, nil)
+	require.NoError(t, err)
+	return integrity.Record{FamilyID: id, Family: fam, Repairs: repairs}
+}
+
+func TestNormalize(t *testing.T) {
+	truncated := repair.Repair{Kind: repair.KindDateTruncated, Path: "parents[0].birthDate", Detail: "truncated"}
+	keyed := repair.Repair{Kind: repair.KindKeyNormalized, Path: "parents[0].firstName", Detail: "renamed"}
+
+	canonical := record(t)
+	otherRepair := record(t, keyed)
+	legacy := record(t, keyed, truncated)
+	failing := record(t, truncated)
+	corrupt := integrity.Record{FamilyID: "corrupt", DecodeErr: errors.New("bad json")}
+
+	store := &stubStore{
+		records: []integrity.Record{canonical, otherRepair, legacy, failing, corrupt},
+		failOn:  failing.FamilyID,
+	}
+
+	report, err := Normalize(context.Background(), store, Options{}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Scanned)
+	assert.Equal(t, 2, report.Pending)
+	assert.Equal(t, 1, report.Normalized)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, []string{legacy.FamilyID}, store.saved)
+
+	require.Len(t, report.Families, 2)
+	assert.Equal(t, Entry{FamilyID: legacy.FamilyID, Changes: []string{truncated.String()}, Normalized: true}, report.Families[0])
+	assert.Equal(t, "write failed", report.Families[1].Error)
+}
+
+func TestNormalize_DryRun(t *testing.T) {
+	legacy := record(t, repair.Repair{Kind: repair.KindDateReformatted, Path: "children[0].birthDate"})
+	store := &stubStore{records: []integrity.Record{legacy}}
+
+	report, err := Normalize(context.Background(), store, Options{DryRun: true}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Pending)
+	assert.Equal(t, 0, report.Normalized)
+	assert.Empty(t, store.saved)
+	assert.False(t, report.Families[0].Normalized)
+}
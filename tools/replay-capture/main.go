@@ -0,0 +1,128 @@
+// Copyright (c) 2025 A Bit of Help, Inc.
+
+// Command replay-capture replays the GraphQL operations captured by the service against a
+// test container.
+//
+// The captures are downloaded from the admin API (GET /admin/ops/captures). The command
+// builds the dependency injection container from the configuration selected by APP_ENV,
+// usually pointing at a disposable test database, and runs each captured operation against
+// its GraphQL server, as a caller with the roles and scopes of the capture. It reports the
+// operations whose errors differ from the captured ones.
+//
+// Usage:
+//
+//	APP_ENV=test replay-capture -captures captures.json -report replay-report.json
+//
+// The command exits with status 1 if any operation diverged, so it can check that a fix
+// makes the captured errors go away, or that a bug is reproduced.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/abitofhelp/family-service/cmd/server/graphql/di"
+	"github.com/abitofhelp/family-service/infrastructure/adapters/config"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/capture"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/generated"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/quarantine"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/resolver"
+	"github.com/abitofhelp/family-service/interface/adapters/graphql/session"
+	"github.com/abitofhelp/servicelib/graphql"
+	"github.com/abitofhelp/servicelib/logging"
+	"go.uber.org/zap"
+)
+
+// readCaptures reads the captures downloaded from the admin API
+func readCaptures(path string) ([]capture.Capture, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return capture.ReadCaptures(file)
+}
+
+// writeReport writes the results of the replay as indented JSON
+func writeReport(path string, results []capture.ReplayResult) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func main() {
+	capturesPath := flag.String("captures", "captures.json", "file of the captures downloaded from the admin API")
+	report := flag.String("report", "", "file to which the results are written (empty disables it)")
+	flag.Parse()
+
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	captures, err := readCaptures(*capturesPath)
+	if err != nil {
+		logger.Fatal("Failed to read captures", zap.String("captures", *capturesPath), zap.Error(err))
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Fatal("Failed to load configuration", zap.Error(err))
+	}
+	container, err := di.NewContainer(ctx, logger, cfg)
+	if err != nil {
+		logger.Fatal("Failed to create container", zap.Error(err))
+	}
+
+	// Serve the operations with the resolvers, directives, and error presenter of the
+	// service; HTTP middleware, such as authentication and rate limits, is left out
+	resolverInstance := resolver.NewResolver(container.GetFamilyApplicationService(), container.GetFamilyMapper(), container.GetAuthorizer())
+	resolverInstance.SetMajorityAge(cfg.Policy.Consent.MinorAge)
+	schema := generated.NewExecutableSchema(generated.Config{
+		Resolvers: resolverInstance,
+		Directives: generated.DirectiveRoot{
+			IsAuthorized: resolverInstance.IsAuthorized,
+		},
+	})
+	server := graphql.NewServer(schema, logging.NewContextLogger(logger), graphql.NewDefaultServerConfig())
+	server.Use(session.Extension{})
+	server.Use(quarantine.Extension{})
+
+	logger.Info("Replaying captured operations", zap.Int("operations", len(captures)))
+	results := capture.Replay(ctx, server, captures)
+
+	diverged := 0
+	for _, result := range results {
+		if !result.Diverged {
+			continue
+		}
+		diverged++
+		logger.Warn("Replayed operation diverged",
+			zap.Int64("id", result.ID),
+			zap.String("operation", result.Operation),
+			zap.Any("captured", result.Captured),
+			zap.Any("replayed", result.Replayed),
+			zap.String("error", result.Error))
+	}
+	if *report != "" {
+		if err := writeReport(*report, results); err != nil {
+			logger.Error("Failed to write report", zap.String("report", *report), zap.Error(err))
+		}
+	}
+
+	fmt.Printf("Replayed %d operations, %d diverged\n", len(results), diverged)
+	if err := container.Close(); err != nil {
+		logger.Error("Failed to close container", zap.Error(err))
+	}
+	if diverged > 0 {
+		os.Exit(1)
+	}
+}